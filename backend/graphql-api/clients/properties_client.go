@@ -0,0 +1,108 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"graphql-api/dto"
+	"graphql-api/rpc"
+
+	"google.golang.org/grpc"
+)
+
+// ErrUnauthorized indica que properties-api rechazó el JWT del usuario
+var ErrUnauthorized = errors.New("usuario no autenticado")
+
+// PropertiesClient obtiene propiedades, disponibilidad y reservas de properties-api
+// Las lecturas públicas van por gRPC; las que requieren el JWT del usuario van por la API REST
+type PropertiesClient interface {
+	// GetProperties obtiene un lote de propiedades en una sola llamada; los IDs inexistentes no están en el mapa
+	GetProperties(ctx context.Context, ids []string) (map[string]dto.Property, error)
+	// GetAvailability obtiene los rangos ocupados de una propiedad
+	GetAvailability(ctx context.Context, propertyID string) ([]dto.AvailabilityRange, error)
+	// GetOwnerBookings obtiene las reservas de las propiedades del usuario dueño del JWT
+	GetOwnerBookings(ctx context.Context, authorization string) ([]dto.Booking, error)
+}
+
+type propertiesClient struct {
+	stub        rpc.PropertiesServiceClient
+	callTimeout time.Duration
+	baseURL     string
+	httpClient  *http.Client
+}
+
+// NewPropertiesClient crea el cliente de propiedades
+// Recibe la conexión gRPC (ver NewGRPCConn) y la URL base de la API REST (incluye /api)
+func NewPropertiesClient(conn grpc.ClientConnInterface, callTimeout time.Duration, baseURL string, httpClient *http.Client) PropertiesClient {
+	return &propertiesClient{
+		stub:        rpc.NewPropertiesServiceClient(conn),
+		callTimeout: callTimeout,
+		baseURL:     strings.TrimSuffix(baseURL, "/"),
+		httpClient:  httpClient,
+	}
+}
+
+// GetProperties obtiene un lote de propiedades en una sola llamada
+func (c *propertiesClient) GetProperties(ctx context.Context, ids []string) (map[string]dto.Property, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.callTimeout)
+	defer cancel()
+
+	response, err := c.stub.GetProperties(ctx, &rpc.GetPropertiesRequest{IDs: ids})
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo propiedades de properties-api: %w", err)
+	}
+
+	properties := make(map[string]dto.Property, len(response.Properties))
+	for _, property := range response.Properties {
+		properties[property.ID] = property
+	}
+	return properties, nil
+}
+
+// GetAvailability obtiene los rangos ocupados de una propiedad
+func (c *propertiesClient) GetAvailability(ctx context.Context, propertyID string) ([]dto.AvailabilityRange, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.callTimeout)
+	defer cancel()
+
+	response, err := c.stub.GetAvailability(ctx, &rpc.GetAvailabilityRequest{PropertyID: propertyID})
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo disponibilidad de la propiedad %s: %w", propertyID, err)
+	}
+	return response.Ranges, nil
+}
+
+// GetOwnerBookings obtiene las reservas del owner llamando a GET {baseURL}/bookings/owner con su JWT
+func (c *propertiesClient) GetOwnerBookings(ctx context.Context, authorization string) ([]dto.Booking, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/bookings/owner?format=json", nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creando request HTTP: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", authorization)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error haciendo petición HTTP a properties-api: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return nil, ErrUnauthorized
+	case resp.StatusCode != http.StatusOK:
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("error obteniendo reservas de properties-api (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	bookings := []dto.Booking{}
+	if err := json.NewDecoder(resp.Body).Decode(&bookings); err != nil {
+		return nil, fmt.Errorf("error parseando reservas de properties-api: %w", err)
+	}
+	return bookings, nil
+}
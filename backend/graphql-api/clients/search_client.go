@@ -0,0 +1,95 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"graphql-api/dto"
+)
+
+// SearchClient reenvía las búsquedas a search-api
+type SearchClient interface {
+	// Search busca propiedades; solo pide los IDs (las propiedades se cargan con el dataloader)
+	// clientIP se reenvía para que la detección de bots de search-api vea al cliente real
+	Search(ctx context.Context, request dto.SearchRequest, clientIP string) (*dto.SearchResponse, error)
+}
+
+type searchClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewSearchClient crea el cliente de search-api
+func NewSearchClient(baseURL string, httpClient *http.Client) SearchClient {
+	return &searchClient{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: httpClient,
+	}
+}
+
+// Search llama a GET {baseURL}/search con fields=id
+func (c *searchClient) Search(ctx context.Context, request dto.SearchRequest, clientIP string) (*dto.SearchResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/search?"+searchQuery(request).Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creando request HTTP: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if clientIP != "" {
+		req.Header.Set("X-Forwarded-For", clientIP)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error haciendo petición HTTP a search-api: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("error en respuesta de search-api (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var response dto.SearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("error parseando respuesta de search-api: %w", err)
+	}
+	return &response, nil
+}
+
+// searchQuery arma los query params de search-api; los filtros vacíos no se envían
+func searchQuery(request dto.SearchRequest) url.Values {
+	values := url.Values{"fields": {"id"}}
+	setString := func(key, value string) {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	setString("query", request.Query)
+	setString("city", request.City)
+	setString("country", request.Country)
+	setString("type", request.Type)
+	setString("sortBy", request.SortBy)
+	setString("sortOrder", request.SortOrder)
+	if request.MinPrice > 0 {
+		values.Set("minPrice", strconv.FormatFloat(request.MinPrice, 'f', -1, 64))
+	}
+	if request.MaxPrice > 0 {
+		values.Set("maxPrice", strconv.FormatFloat(request.MaxPrice, 'f', -1, 64))
+	}
+	if request.MinGuests > 0 {
+		values.Set("minGuests", strconv.Itoa(request.MinGuests))
+	}
+	if request.Page > 0 {
+		values.Set("page", strconv.Itoa(request.Page))
+	}
+	if request.PageSize > 0 {
+		values.Set("pageSize", strconv.Itoa(request.PageSize))
+	}
+	return values
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"reporting"
+)
+
+// Config contiene toda la configuración de la aplicación
+type Config struct {
+	// Port es el puerto en el que escuchará el servidor
+	Port string
+
+	// UsersAPIGRPCAddr es el host:puerto del servidor gRPC de users-api
+	UsersAPIGRPCAddr string
+
+	// PropertiesAPIGRPCAddr es el host:puerto del servidor gRPC de properties-api
+	PropertiesAPIGRPCAddr string
+
+	// InternalAPIKey es la API key del servicio para las llamadas gRPC internas (emitida en users-api)
+	// Vacía = las llamadas van sin key (solo se aceptan si INTERNAL_API_KEYS_REQUIRED=false)
+	InternalAPIKey string
+
+	// InternalSigningSecret es el secreto HMAC compartido con el que se firman las llamadas gRPC internas
+	// Vacío = las llamadas van sin firma (solo se aceptan si INTERNAL_SIGNATURE_REQUIRED=false)
+	InternalSigningSecret string
+
+	// PropertiesAPIURL es la URL base de la API REST de propiedades (incluye /api)
+	// Se usa para las consultas que requieren el JWT del usuario (ej: reservas del owner)
+	PropertiesAPIURL string
+
+	// SearchAPIURL es la URL base de search-api
+	SearchAPIURL string
+
+	// HTTPClientTimeout es el timeout total de cada request HTTP saliente
+	HTTPClientTimeout time.Duration
+
+	// CallTimeout es el timeout de cada llamada gRPC a otro servicio
+	CallTimeout time.Duration
+
+	// Loaders contiene la configuración de los dataloaders
+	Loaders LoadersConfig
+
+	// MaxQueryDepth es la profundidad máxima de una consulta GraphQL
+	MaxQueryDepth int
+
+	// HSTSMaxAge es el max-age de Strict-Transport-Security (0 = no se envía)
+	HSTSMaxAge time.Duration
+
+	// ErrorReporting contiene el envío de panics y errores 5xx a un servicio compatible con Sentry
+	ErrorReporting reporting.Config
+}
+
+// LoadersConfig contiene la configuración del batching de los dataloaders
+type LoadersConfig struct {
+	// BatchWait es cuánto se espera juntando claves antes de despachar un lote
+	BatchWait time.Duration
+
+	// MaxBatch es la cantidad máxima de claves por lote (el lote se despacha al llenarse)
+	MaxBatch int
+}
+
+// LoadConfig carga la configuración desde variables de entorno
+// Si una variable no está definida, usa los valores por defecto
+func LoadConfig() *Config {
+	return &Config{
+		Port:                  getEnv("SERVER_PORT", "8084"),
+		UsersAPIGRPCAddr:      getEnv("USERS_API_GRPC_ADDR", "localhost:9090"),
+		PropertiesAPIGRPCAddr: getEnv("PROPERTIES_API_GRPC_ADDR", "localhost:9091"),
+		InternalAPIKey:        getEnv("INTERNAL_API_KEY", ""),
+		InternalSigningSecret: getEnv("INTERNAL_SIGNING_SECRET", ""),
+		PropertiesAPIURL:      getEnv("PROPERTIES_API_URL", "http://localhost:8081/api"),
+		SearchAPIURL:          getEnv("SEARCH_API_URL", "http://localhost:8083"),
+		HTTPClientTimeout:     getEnvAsDuration("HTTP_CLIENT_TIMEOUT", 10*time.Second),
+		CallTimeout:           getEnvAsDuration("GRPC_CALL_TIMEOUT", 5*time.Second),
+		Loaders: LoadersConfig{
+			BatchWait: getEnvAsDuration("LOADER_BATCH_WAIT", 2*time.Millisecond),
+			MaxBatch:  getEnvAsInt("LOADER_MAX_BATCH", 100),
+		},
+		MaxQueryDepth: getEnvAsInt("GRAPHQL_MAX_DEPTH", 8),
+		HSTSMaxAge:    getEnvAsDuration("HSTS_MAX_AGE", 180*24*time.Hour),
+		ErrorReporting: reporting.Config{
+			DSN:         getEnv("ERROR_REPORTING_DSN", ""),
+			Environment: getEnv("ENVIRONMENT", "development"),
+			Release:     getEnv("ERROR_REPORTING_RELEASE", ""),
+		},
+	}
+}
+
+// getEnv obtiene una variable de entorno o retorna un valor por defecto
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvAsInt obtiene una variable de entorno como entero o retorna un valor por defecto
+func getEnvAsInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvAsDuration obtiene una variable de entorno como duración (ej: "500ms", "30s") o retorna un valor por defecto
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,16 @@
+package dto
+
+// Booking es una reserva con el mismo formato que GET /bookings/owner de properties-api
+type Booking struct {
+	ID            string  `json:"id"`
+	PropertyID    string  `json:"propertyId"`
+	UserID        string  `json:"userId"`
+	CheckIn       string  `json:"checkIn"`
+	CheckOut      string  `json:"checkOut"`
+	CheckInLocal  string  `json:"checkInLocal,omitempty"`
+	CheckOutLocal string  `json:"checkOutLocal,omitempty"`
+	Timezone      string  `json:"timezone,omitempty"`
+	TotalPrice    float64 `json:"totalPrice"`
+	Status        string  `json:"status"`
+	CreatedAt     string  `json:"createdAt"`
+}
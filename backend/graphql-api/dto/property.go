@@ -0,0 +1,38 @@
+package dto
+
+// Property es una propiedad con el mismo formato que GET /properties/:id de properties-api
+type Property struct {
+	ID           string   `json:"id"`
+	Title        string   `json:"title"`
+	Description  string   `json:"description"`
+	Price        float64  `json:"price"`
+	Location     string   `json:"location"`
+	PropertyType string   `json:"propertyType,omitempty"`
+	Latitude     float64  `json:"latitude,omitempty"`
+	Longitude    float64  `json:"longitude,omitempty"`
+	Timezone     string   `json:"timezone,omitempty"`
+	OwnerID      string   `json:"ownerId"`
+	Amenities    []string `json:"amenities"`
+	Capacity     int      `json:"capacity"`
+	Available    bool     `json:"available"`
+	Images       []Photo  `json:"images"`     // Ordenadas por posición
+	CoverImage   string   `json:"coverImage"` // URL de la foto de portada
+	Views        int64    `json:"views"`
+	CreatedAt    string   `json:"createdAt"` // UTC, RFC3339
+	UpdatedAt    string   `json:"updatedAt"` // UTC, RFC3339
+}
+
+// Photo es una foto de la galería de una propiedad
+type Photo struct {
+	URL      string `json:"url"`
+	Caption  string `json:"caption,omitempty"`
+	Position int    `json:"position"`
+	IsCover  bool   `json:"isCover"`
+}
+
+// AvailabilityRange es un rango de días en que la propiedad no está disponible (End es exclusivo)
+type AvailabilityRange struct {
+	Start  string `json:"start"`
+	End    string `json:"end"`
+	Source string `json:"source"` // "booking" o "block"
+}
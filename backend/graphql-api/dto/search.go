@@ -0,0 +1,28 @@
+package dto
+
+// SearchRequest son los filtros de búsqueda que se reenvían a search-api
+type SearchRequest struct {
+	Query     string
+	City      string
+	Country   string
+	Type      string
+	MinPrice  float64
+	MaxPrice  float64
+	MinGuests int
+	Page      int
+	PageSize  int
+	SortBy    string
+	SortOrder string
+}
+
+// SearchResponse es la respuesta de GET /search de search-api pidiendo solo los IDs
+// Las propiedades completas se cargan después con el dataloader de propiedades
+type SearchResponse struct {
+	Results []struct {
+		ID string `json:"id"`
+	} `json:"results"`
+	TotalResults int `json:"totalResults"`
+	Page         int `json:"page"`
+	PageSize     int `json:"pageSize"`
+	TotalPages   int `json:"totalPages"`
+}
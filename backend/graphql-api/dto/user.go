@@ -0,0 +1,12 @@
+package dto
+
+// User son los datos de un usuario con el mismo formato que GET /users/:id de users-api
+type User struct {
+	ID        uint   `json:"id"`
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+	UserType  string `json:"userType"`
+	Active    bool   `json:"active"`
+}
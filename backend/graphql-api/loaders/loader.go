@@ -0,0 +1,152 @@
+package loaders
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// BatchFunc carga un lote de claves en una sola llamada
+// Las claves que no existen simplemente no se incluyen en el mapa
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Loader es un dataloader: agrupa las claves pedidas durante una ventana corta
+// en un solo llamado a fetch y cachea los resultados durante el request
+// Los resolvers de GraphQL corren en paralelo, así N propiedades con su owner
+// cuestan una llamada a users-api en lugar de N
+type Loader[K comparable, V any] struct {
+	ctx      context.Context
+	fetch    BatchFunc[K, V]
+	wait     time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	cache   map[K]*result[V]
+	pending *batch[K, V]
+}
+
+// result es el resultado (compartido) de cargar una clave
+type result[V any] struct {
+	done  chan struct{}
+	value V
+	found bool
+	err   error
+}
+
+// batch son las claves juntadas durante la ventana actual
+type batch[K comparable, V any] struct {
+	keys       []K
+	results    []*result[V]
+	dispatched bool
+}
+
+// NewLoader crea un loader para un request; ctx es el contexto del request
+func NewLoader[K comparable, V any](ctx context.Context, fetch BatchFunc[K, V], wait time.Duration, maxBatch int) *Loader[K, V] {
+	if maxBatch < 1 {
+		maxBatch = 1
+	}
+	return &Loader[K, V]{
+		ctx:      ctx,
+		fetch:    fetch,
+		wait:     wait,
+		maxBatch: maxBatch,
+		cache:    make(map[K]*result[V]),
+	}
+}
+
+// Load carga una clave; found es false si la clave no existe
+// Las claves repetidas en el mismo request se cargan una sola vez
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, bool, error) {
+	l.mu.Lock()
+	res, ok := l.cache[key]
+	if !ok {
+		res = &result[V]{done: make(chan struct{})}
+		l.cache[key] = res
+		l.enqueue(key, res)
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-res.done:
+		return res.value, res.found, res.err
+	case <-ctx.Done():
+		var zero V
+		return zero, false, ctx.Err()
+	}
+}
+
+// LoadMany carga varias claves en el mismo lote; las claves que no existen no están en el mapa
+func (l *Loader[K, V]) LoadMany(ctx context.Context, keys []K) (map[K]V, error) {
+	pending := make(map[K]*result[V], len(keys))
+	l.mu.Lock()
+	for _, key := range keys {
+		res, ok := l.cache[key]
+		if !ok {
+			res = &result[V]{done: make(chan struct{})}
+			l.cache[key] = res
+			l.enqueue(key, res)
+		}
+		pending[key] = res
+	}
+	l.mu.Unlock()
+
+	values := make(map[K]V, len(pending))
+	for key, res := range pending {
+		select {
+		case <-res.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if res.err != nil {
+			return nil, res.err
+		}
+		if res.found {
+			values[key] = res.value
+		}
+	}
+	return values, nil
+}
+
+// enqueue agrega la clave al lote actual (se llama con mu tomado)
+// El primer pedido de la ventana programa el despacho; un lote lleno se despacha en el momento
+func (l *Loader[K, V]) enqueue(key K, res *result[V]) {
+	if l.pending == nil {
+		current := &batch[K, V]{}
+		l.pending = current
+		time.AfterFunc(l.wait, func() { l.dispatch(current) })
+	}
+
+	l.pending.keys = append(l.pending.keys, key)
+	l.pending.results = append(l.pending.results, res)
+	if len(l.pending.keys) >= l.maxBatch {
+		full := l.pending
+		l.pending = nil
+		go l.dispatch(full)
+	}
+}
+
+// dispatch ejecuta fetch para un lote y completa sus resultados
+// Si el lote ya se despachó por estar lleno, el timer no hace nada
+func (l *Loader[K, V]) dispatch(current *batch[K, V]) {
+	l.mu.Lock()
+	if current.dispatched {
+		l.mu.Unlock()
+		return
+	}
+	current.dispatched = true
+	if l.pending == current {
+		l.pending = nil
+	}
+	l.mu.Unlock()
+
+	values, err := l.fetch(l.ctx, current.keys)
+	for i, key := range current.keys {
+		res := current.results[i]
+		if err != nil {
+			res.err = err
+		} else {
+			res.value, res.found = values[key]
+		}
+		close(res.done)
+	}
+}
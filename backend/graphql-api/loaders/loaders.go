@@ -0,0 +1,77 @@
+package loaders
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"graphql-api/clients"
+	"graphql-api/config"
+	"graphql-api/dto"
+)
+
+// contextKey es el tipo de la clave con la que se guardan los loaders en el contexto
+type contextKey struct{}
+
+// Loaders son los dataloaders de un request
+// Se crean por request: el caché no se comparte entre usuarios ni queda desactualizado
+type Loaders struct {
+	Properties   *Loader[string, dto.Property]
+	Users        *Loader[uint, dto.User]
+	Availability *Loader[string, []dto.AvailabilityRange]
+}
+
+// Middleware crea los loaders de cada request y los deja en el contexto (ver For)
+func Middleware(properties clients.PropertiesClient, users clients.UsersClient, cfg config.LoadersConfig, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		loaders := &Loaders{
+			Properties:   NewLoader(ctx, properties.GetProperties, cfg.BatchWait, cfg.MaxBatch),
+			Users:        NewLoader(ctx, users.GetUsers, cfg.BatchWait, cfg.MaxBatch),
+			Availability: NewLoader(ctx, availabilityBatch(properties), cfg.BatchWait, cfg.MaxBatch),
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(ctx, contextKey{}, loaders)))
+	})
+}
+
+// For retorna los loaders del request
+func For(ctx context.Context) *Loaders {
+	return ctx.Value(contextKey{}).(*Loaders)
+}
+
+// availabilityBatch carga la disponibilidad de un lote de propiedades
+// properties-api la expone por propiedad: las llamadas del lote van en paralelo sobre la misma conexión
+func availabilityBatch(properties clients.PropertiesClient) BatchFunc[string, []dto.AvailabilityRange] {
+	return func(ctx context.Context, ids []string) (map[string][]dto.AvailabilityRange, error) {
+		var (
+			wg       sync.WaitGroup
+			mu       sync.Mutex
+			firstErr error
+		)
+		ranges := make(map[string][]dto.AvailabilityRange, len(ids))
+		for _, id := range ids {
+			wg.Add(1)
+			go func(id string) {
+				defer wg.Done()
+				value, err := properties.GetAvailability(ctx, id)
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = err
+					}
+					return
+				}
+				ranges[id] = value
+			}(id)
+		}
+		wg.Wait()
+
+		// Una disponibilidad faltante no puede mostrarse como "libre": si una carga falla, falla el lote
+		if firstErr != nil {
+			return nil, firstErr
+		}
+		return ranges, nil
+	}
+}
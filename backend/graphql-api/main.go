@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"graphql-api/clients"
+	"graphql-api/config"
+	"graphql-api/loaders"
+	"graphql-api/middleware"
+	"graphql-api/resolvers"
+	"reporting"
+
+	"github.com/graph-gophers/graphql-go/relay"
+)
+
+func main() {
+	log.Println("🚀 Iniciando GraphQL API...")
+
+	// ============================================
+	// SECCIÓN 1: CARGAR CONFIGURACIÓN
+	// ============================================
+	cfg := config.LoadConfig()
+	log.Printf("✅ Configuración cargada:")
+	log.Printf("   - Users API gRPC: %s", cfg.UsersAPIGRPCAddr)
+	log.Printf("   - Properties API gRPC: %s", cfg.PropertiesAPIGRPCAddr)
+	log.Printf("   - Properties API URL: %s", cfg.PropertiesAPIURL)
+	log.Printf("   - Search API URL: %s", cfg.SearchAPIURL)
+	log.Printf("   - Port: %s", cfg.Port)
+	log.Printf("   - Dataloaders: espera %v, lote máx. %d", cfg.Loaders.BatchWait, cfg.Loaders.MaxBatch)
+	log.Printf("   - Profundidad máx. de consultas: %d", cfg.MaxQueryDepth)
+
+	// ============================================
+	// SECCIÓN 2: INICIALIZAR CLIENTES
+	// ============================================
+	log.Println("📦 Inicializando clientes...")
+	internalAuth := clients.InternalCallAuth{Caller: "graphql-api", APIKey: cfg.InternalAPIKey, SigningSecret: cfg.InternalSigningSecret}
+	usersConn, err := clients.NewGRPCConn(cfg.UsersAPIGRPCAddr, internalAuth)
+	if err != nil {
+		log.Fatalf("❌ Error creando conexión gRPC a users-api: %v", err)
+	}
+	defer usersConn.Close()
+
+	propertiesConn, err := clients.NewGRPCConn(cfg.PropertiesAPIGRPCAddr, internalAuth)
+	if err != nil {
+		log.Fatalf("❌ Error creando conexión gRPC a properties-api: %v", err)
+	}
+	defer propertiesConn.Close()
+
+	httpClient := clients.NewHTTPClient(cfg.HTTPClientTimeout)
+
+	// Reporte de panics y errores 5xx (sin ERROR_REPORTING_DSN solo quedan en los logs)
+	errorReporter := reporting.NewErrorReporter(cfg.ErrorReporting, "graphql-api", httpClient)
+	defer errorReporter.Close(5 * time.Second)
+	usersClient := clients.NewUsersClient(usersConn, cfg.CallTimeout)
+	propertiesClient := clients.NewPropertiesClient(propertiesConn, cfg.CallTimeout, cfg.PropertiesAPIURL, httpClient)
+	searchClient := clients.NewSearchClient(cfg.SearchAPIURL, httpClient)
+
+	// ============================================
+	// SECCIÓN 3: CARGAR ESQUEMA GRAPHQL
+	// ============================================
+	schema, err := resolvers.NewSchema(resolvers.NewRootResolver(propertiesClient, searchClient), cfg.MaxQueryDepth, errorReporter)
+	if err != nil {
+		log.Fatalf("❌ Error parseando el esquema GraphQL: %v", err)
+	}
+	log.Println("✅ Esquema GraphQL cargado")
+
+	// ============================================
+	// SECCIÓN 4: CONFIGURAR RUTAS
+	// ============================================
+	mux := http.NewServeMux()
+	// Cada request tiene sus propios dataloaders (ver loaders.Middleware)
+	mux.Handle("/graphql", middleware.RequestInfo(
+		loaders.Middleware(propertiesClient, usersClient, cfg.Loaders, &relay.Handler{Schema: schema}),
+	))
+	mux.HandleFunc("/health/live", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"status": "ok", "service": "graphql-api"})
+	})
+
+	log.Println("✅ Rutas configuradas:")
+	log.Println("   - POST /graphql")
+	log.Println("   - GET  /health/live")
+
+	server := &http.Server{
+		Addr:         ":" + cfg.Port,
+		Handler:      middleware.RequestID(middleware.Recovery(errorReporter, middleware.SecurityHeaders(cfg.HSTSMaxAge, corsMiddleware(mux)))),
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 30 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+
+	// ============================================
+	// SECCIÓN 5: MANEJAR GRACEFUL SHUTDOWN
+	// ============================================
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		log.Println("🚀 =======================================")
+		log.Printf("🚀 GraphQL API corriendo en puerto %s", cfg.Port)
+		log.Println("🚀 =======================================")
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("❌ Error iniciando servidor: %v", err)
+		}
+	}()
+
+	sig := <-sigChan
+	log.Printf("📨 Señal recibida: %v. Iniciando shutdown graceful...", sig)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("⚠️ Error durante shutdown del servidor: %v", err)
+	} else {
+		log.Println("✅ Servidor cerrado exitosamente")
+	}
+
+	log.Println("👋 GraphQL API finalizada")
+}
+
+// corsMiddleware agrega headers CORS a todas las respuestas
+func corsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Max-Age", "3600")
+
+		// Manejar preflight requests (OPTIONS)
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// requestInfoKey es el tipo de la clave con la que se guardan los datos del request en el contexto
+type requestInfoKey struct{}
+
+// requestInfo son los datos del request HTTP que los resolvers reenvían a otros servicios
+type requestInfo struct {
+	authorization string
+	clientIP      string
+}
+
+// RequestInfo guarda en el contexto el header Authorization y la IP del cliente
+// Los resolvers no reciben el *http.Request: los leen con Authorization y ClientIP
+func RequestInfo(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info := requestInfo{
+			authorization: r.Header.Get("Authorization"),
+			clientIP:      clientIP(r),
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestInfoKey{}, info)))
+	})
+}
+
+// Authorization retorna el header Authorization del request (vacío si no vino)
+func Authorization(ctx context.Context) string {
+	info, _ := ctx.Value(requestInfoKey{}).(requestInfo)
+	return info.authorization
+}
+
+// ClientIP retorna la IP del cliente que hizo el request
+func ClientIP(ctx context.Context) string {
+	info, _ := ctx.Value(requestInfoKey{}).(requestInfo)
+	return info.clientIP
+}
+
+// clientIP obtiene la IP del cliente, respetando los headers del gateway
+func clientIP(r *http.Request) string {
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+	}
+	if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
+		return realIP
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// SecurityHeaders agrega los headers de seguridad a todas las respuestas:
+//   - X-Content-Type-Options: nosniff evita que el navegador interprete un JSON como HTML o script
+//   - X-Frame-Options: DENY evita que la API se embeba en un iframe (clickjacking)
+//   - Strict-Transport-Security obliga a usar HTTPS (hstsMaxAge 0 no lo envía)
+func SecurityHeaders(hstsMaxAge time.Duration, next http.Handler) http.Handler {
+	hsts := ""
+	if hstsMaxAge > 0 {
+		hsts = fmt.Sprintf("max-age=%d; includeSubDomains", int(hstsMaxAge.Seconds()))
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("X-Frame-Options", "DENY")
+		if hsts != "" {
+			w.Header().Set("Strict-Transport-Security", hsts)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,39 @@
+package resolvers
+
+import (
+	"context"
+
+	"graphql-api/dto"
+	"graphql-api/loaders"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// BookingResolver resuelve los campos de type Booking
+type BookingResolver struct {
+	booking dto.Booking
+}
+
+func (r *BookingResolver) ID() graphql.ID         { return graphql.ID(r.booking.ID) }
+func (r *BookingResolver) CheckIn() string        { return r.booking.CheckIn }
+func (r *BookingResolver) CheckOut() string       { return r.booking.CheckOut }
+func (r *BookingResolver) CheckInLocal() *string  { return optionalString(r.booking.CheckInLocal) }
+func (r *BookingResolver) CheckOutLocal() *string { return optionalString(r.booking.CheckOutLocal) }
+func (r *BookingResolver) Timezone() *string      { return optionalString(r.booking.Timezone) }
+func (r *BookingResolver) TotalPrice() float64    { return r.booking.TotalPrice }
+func (r *BookingResolver) Status() string         { return r.booking.Status }
+func (r *BookingResolver) CreatedAt() string      { return r.booking.CreatedAt }
+
+// Property resuelve la propiedad reservada con el loader de propiedades
+func (r *BookingResolver) Property(ctx context.Context) (*PropertyResolver, error) {
+	property, found, err := loaders.For(ctx).Properties.Load(ctx, r.booking.PropertyID)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &PropertyResolver{property: property}, nil
+}
+
+// Guest resuelve el huésped con el loader de usuarios
+func (r *BookingResolver) Guest(ctx context.Context) (*UserResolver, error) {
+	return loadUserResolver(ctx, r.booking.UserID)
+}
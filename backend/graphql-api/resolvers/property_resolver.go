@@ -0,0 +1,137 @@
+package resolvers
+
+import (
+	"context"
+	"math"
+
+	"graphql-api/dto"
+	"graphql-api/loaders"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// PropertyResolver resuelve los campos de type Property
+type PropertyResolver struct {
+	property dto.Property
+}
+
+func (r *PropertyResolver) ID() graphql.ID        { return graphql.ID(r.property.ID) }
+func (r *PropertyResolver) Title() string         { return r.property.Title }
+func (r *PropertyResolver) Description() string   { return r.property.Description }
+func (r *PropertyResolver) Price() float64        { return r.property.Price }
+func (r *PropertyResolver) Location() string      { return r.property.Location }
+func (r *PropertyResolver) PropertyType() *string { return optionalString(r.property.PropertyType) }
+func (r *PropertyResolver) Timezone() *string     { return optionalString(r.property.Timezone) }
+func (r *PropertyResolver) Amenities() []string   { return nonNilStrings(r.property.Amenities) }
+func (r *PropertyResolver) Capacity() int32       { return int32(r.property.Capacity) }
+func (r *PropertyResolver) Available() bool       { return r.property.Available }
+func (r *PropertyResolver) CreatedAt() string     { return r.property.CreatedAt }
+func (r *PropertyResolver) UpdatedAt() string     { return r.property.UpdatedAt }
+
+// Images son las URLs de las fotos en el orden de la galería
+func (r *PropertyResolver) Images() []string {
+	urls := make([]string, len(r.property.Images))
+	for i, photo := range r.property.Images {
+		urls[i] = photo.URL
+	}
+	return urls
+}
+
+// Photos resuelve la galería con epígrafes y portada
+func (r *PropertyResolver) Photos() []*PhotoResolver {
+	photos := make([]*PhotoResolver, len(r.property.Images))
+	for i := range r.property.Images {
+		photos[i] = &PhotoResolver{photo: r.property.Images[i]}
+	}
+	return photos
+}
+
+// CoverImage es null si la propiedad no tiene fotos
+func (r *PropertyResolver) CoverImage() *string { return optionalString(r.property.CoverImage) }
+
+// PhotoResolver resuelve los campos de type Photo
+type PhotoResolver struct {
+	photo dto.Photo
+}
+
+func (r *PhotoResolver) URL() string      { return r.photo.URL }
+func (r *PhotoResolver) Caption() *string { return optionalString(r.photo.Caption) }
+func (r *PhotoResolver) Position() int32  { return int32(r.photo.Position) }
+func (r *PhotoResolver) IsCover() bool    { return r.photo.IsCover }
+
+// Latitude y Longitude son null si la propiedad no tiene coordenadas
+func (r *PropertyResolver) Latitude() *float64 {
+	if r.property.Latitude == 0 && r.property.Longitude == 0 {
+		return nil
+	}
+	return &r.property.Latitude
+}
+
+func (r *PropertyResolver) Longitude() *float64 {
+	if r.property.Latitude == 0 && r.property.Longitude == 0 {
+		return nil
+	}
+	return &r.property.Longitude
+}
+
+// Views se limita al máximo de Int de GraphQL (32 bits)
+func (r *PropertyResolver) Views() int32 {
+	if r.property.Views > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return int32(r.property.Views)
+}
+
+// Owner resuelve el dueño con el loader de usuarios (un lote para todas las propiedades del request)
+func (r *PropertyResolver) Owner(ctx context.Context) (*UserResolver, error) {
+	return loadUserResolver(ctx, r.property.OwnerID)
+}
+
+// Availability resuelve los rangos ocupados con el loader de disponibilidad
+func (r *PropertyResolver) Availability(ctx context.Context) ([]dto.AvailabilityRange, error) {
+	ranges, _, err := loaders.For(ctx).Availability.Load(ctx, r.property.ID)
+	if err != nil {
+		return nil, err
+	}
+	return nonNilRanges(ranges), nil
+}
+
+// loadPropertyResolvers carga un lote de propiedades respetando el orden de ids; las inexistentes se omiten
+func loadPropertyResolvers(ctx context.Context, ids []string) ([]*PropertyResolver, error) {
+	properties, err := loaders.For(ctx).Properties.LoadMany(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*PropertyResolver, 0, len(ids))
+	for _, id := range ids {
+		if property, ok := properties[id]; ok {
+			resolvers = append(resolvers, &PropertyResolver{property: property})
+		}
+	}
+	return resolvers, nil
+}
+
+// optionalString convierte "" en null
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// nonNilStrings evita devolver null en las listas no nulas del esquema
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// nonNilRanges evita devolver null en availability
+func nonNilRanges(values []dto.AvailabilityRange) []dto.AvailabilityRange {
+	if values == nil {
+		return []dto.AvailabilityRange{}
+	}
+	return values
+}
@@ -0,0 +1,131 @@
+package resolvers
+
+import (
+	"context"
+
+	"graphql-api/clients"
+	"graphql-api/dto"
+	"graphql-api/loaders"
+	"graphql-api/middleware"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// RootResolver resuelve las consultas de nivel superior (type Query)
+type RootResolver struct {
+	properties clients.PropertiesClient
+	search     clients.SearchClient
+}
+
+// NewRootResolver crea el resolver raíz
+// Las propiedades y usuarios se leen de los loaders del request; los clientes se usan para lo que no se agrupa
+func NewRootResolver(properties clients.PropertiesClient, search clients.SearchClient) *RootResolver {
+	return &RootResolver{
+		properties: properties,
+		search:     search,
+	}
+}
+
+// Property resuelve property(id)
+func (r *RootResolver) Property(ctx context.Context, args struct{ ID graphql.ID }) (*PropertyResolver, error) {
+	property, found, err := loaders.For(ctx).Properties.Load(ctx, string(args.ID))
+	if err != nil || !found {
+		return nil, err
+	}
+	return &PropertyResolver{property: property}, nil
+}
+
+// Properties resuelve properties(ids) en un solo lote, respetando el orden pedido
+func (r *RootResolver) Properties(ctx context.Context, args struct{ IDs []graphql.ID }) ([]*PropertyResolver, error) {
+	ids := make([]string, len(args.IDs))
+	for i, id := range args.IDs {
+		ids[i] = string(id)
+	}
+	return loadPropertyResolvers(ctx, ids)
+}
+
+// User resuelve user(id)
+func (r *RootResolver) User(ctx context.Context, args struct{ ID graphql.ID }) (*UserResolver, error) {
+	return loadUserResolver(ctx, string(args.ID))
+}
+
+// searchArgs son los argumentos de search (todos opcionales)
+type searchArgs struct {
+	Query     *string
+	City      *string
+	Country   *string
+	Type      *string
+	MinPrice  *float64
+	MaxPrice  *float64
+	MinGuests *int32
+	Page      *int32
+	PageSize  *int32
+	SortBy    *string
+	SortOrder *string
+}
+
+// Search resuelve search(...) reenviando los filtros a search-api
+func (r *RootResolver) Search(ctx context.Context, args searchArgs) (*SearchResultResolver, error) {
+	request := dto.SearchRequest{
+		Query:     stringValue(args.Query),
+		City:      stringValue(args.City),
+		Country:   stringValue(args.Country),
+		Type:      stringValue(args.Type),
+		MinPrice:  floatValue(args.MinPrice),
+		MaxPrice:  floatValue(args.MaxPrice),
+		MinGuests: intValue(args.MinGuests),
+		Page:      intValue(args.Page),
+		PageSize:  intValue(args.PageSize),
+		SortBy:    stringValue(args.SortBy),
+		SortOrder: stringValue(args.SortOrder),
+	}
+
+	response, err := r.search.Search(ctx, request, middleware.ClientIP(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return &SearchResultResolver{response: response}, nil
+}
+
+// MyBookings resuelve myBookings con el JWT del request
+func (r *RootResolver) MyBookings(ctx context.Context) ([]*BookingResolver, error) {
+	authorization := middleware.Authorization(ctx)
+	if authorization == "" {
+		return nil, clients.ErrUnauthorized
+	}
+
+	bookings, err := r.properties.GetOwnerBookings(ctx, authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*BookingResolver, len(bookings))
+	for i, booking := range bookings {
+		resolvers[i] = &BookingResolver{booking: booking}
+	}
+	return resolvers, nil
+}
+
+// stringValue retorna el valor de un argumento opcional o "" si no vino
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// floatValue retorna el valor de un argumento opcional o 0 si no vino
+func floatValue(value *float64) float64 {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
+
+// intValue retorna el valor de un argumento opcional o 0 si no vino
+func intValue(value *int32) int {
+	if value == nil {
+		return 0
+	}
+	return int(*value)
+}
@@ -0,0 +1,45 @@
+package resolvers
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"log"
+	"runtime/debug"
+
+	"graphql-api/middleware"
+	"reporting"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	graphqllog "github.com/graph-gophers/graphql-go/log"
+)
+
+//go:embed schema.graphql
+var schemaSDL string
+
+// NewSchema parsea el esquema y lo asocia al resolver raíz
+// maxDepth limita la profundidad de las consultas (ej: property > owner > ... no puede crecer sin fin)
+// Los panics de los resolvers se loguean y se reportan a reporter con el request ID
+func NewSchema(root *RootResolver, maxDepth int, reporter reporting.ErrorReporter) (*graphql.Schema, error) {
+	return graphql.ParseSchema(schemaSDL, root,
+		graphql.UseFieldResolvers(),
+		graphql.MaxDepth(maxDepth),
+		graphql.Logger(graphqllog.LoggerFunc(func(ctx context.Context, value any) {
+			reportResolverPanic(ctx, reporter, value)
+		})),
+	)
+}
+
+// reportResolverPanic loguea y reporta un panic de un resolver
+// graphql-go ya lo recuperó y lo devuelve como error del campo, el resto de la consulta sigue
+func reportResolverPanic(ctx context.Context, reporter reporting.ErrorReporter, value any) {
+	stack := string(debug.Stack())
+	requestID := middleware.RequestIDFrom(ctx)
+	log.Printf("💥 Panic en un resolver (request %s): %v\n%s", requestID, value, stack)
+	reporter.Report(reporting.ErrorEvent{
+		Message:    fmt.Sprintf("panic: %v", value),
+		Level:      reporting.ErrorLevelFatal,
+		Stacktrace: stack,
+		Tags:       map[string]string{"request_id": requestID, "path": "/graphql"},
+	})
+}
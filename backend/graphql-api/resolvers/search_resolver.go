@@ -0,0 +1,27 @@
+package resolvers
+
+import (
+	"context"
+
+	"graphql-api/dto"
+)
+
+// SearchResultResolver resuelve los campos de type SearchResult
+type SearchResultResolver struct {
+	response *dto.SearchResponse
+}
+
+func (r *SearchResultResolver) TotalResults() int32 { return int32(r.response.TotalResults) }
+func (r *SearchResultResolver) Page() int32         { return int32(r.response.Page) }
+func (r *SearchResultResolver) PageSize() int32     { return int32(r.response.PageSize) }
+func (r *SearchResultResolver) TotalPages() int32   { return int32(r.response.TotalPages) }
+
+// Results carga las propiedades de la página en un solo lote, en el orden del ranking de search-api
+// Las que ya no existen en properties-api (índice desactualizado) se omiten
+func (r *SearchResultResolver) Results(ctx context.Context) ([]*PropertyResolver, error) {
+	ids := make([]string, len(r.response.Results))
+	for i, result := range r.response.Results {
+		ids[i] = result.ID
+	}
+	return loadPropertyResolvers(ctx, ids)
+}
@@ -0,0 +1,41 @@
+package resolvers
+
+import (
+	"context"
+	"strconv"
+
+	"graphql-api/dto"
+	"graphql-api/loaders"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// UserResolver resuelve los campos de type User
+// El email no se expone: el endpoint es público y solo muestra datos de perfil
+type UserResolver struct {
+	user dto.User
+}
+
+func (r *UserResolver) ID() graphql.ID {
+	return graphql.ID(strconv.FormatUint(uint64(r.user.ID), 10))
+}
+func (r *UserResolver) Username() string  { return r.user.Username }
+func (r *UserResolver) FirstName() string { return r.user.FirstName }
+func (r *UserResolver) LastName() string  { return r.user.LastName }
+func (r *UserResolver) UserType() string  { return r.user.UserType }
+func (r *UserResolver) Active() bool      { return r.user.Active }
+
+// loadUserResolver carga un usuario con el loader del request
+// Los IDs de users-api son numéricos: un ID inválido se resuelve como null
+func loadUserResolver(ctx context.Context, rawID string) (*UserResolver, error) {
+	id, err := strconv.ParseUint(rawID, 10, 32)
+	if err != nil || id == 0 {
+		return nil, nil
+	}
+
+	user, found, err := loaders.For(ctx).Users.Load(ctx, uint(id))
+	if err != nil || !found {
+		return nil, err
+	}
+	return &UserResolver{user: user}, nil
+}
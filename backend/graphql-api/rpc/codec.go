@@ -0,0 +1,30 @@
+package rpc
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc/encoding"
+)
+
+// CodecName es el content-subtype de gRPC con el que viajan los mensajes (application/grpc+json)
+// Los mensajes se serializan como JSON: los servicios comparten los contratos sin generar código con protoc
+const CodecName = "json"
+
+// jsonCodec serializa los mensajes gRPC como JSON
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return CodecName
+}
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
@@ -0,0 +1,63 @@
+package rpc
+
+import (
+	"context"
+
+	"graphql-api/dto"
+
+	"google.golang.org/grpc"
+)
+
+// PropertiesServiceName es el nombre del servicio gRPC que expone properties-api
+const PropertiesServiceName = "properties.v1.Properties"
+
+// GetPropertiesRequest pide un lote de propiedades por ID
+type GetPropertiesRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// GetPropertiesResponse contiene las propiedades encontradas; los IDs inexistentes se omiten
+type GetPropertiesResponse struct {
+	Properties []dto.Property `json:"properties"`
+}
+
+// GetAvailabilityRequest pide los rangos ocupados de una propiedad
+type GetAvailabilityRequest struct {
+	PropertyID string `json:"propertyId"`
+}
+
+// GetAvailabilityResponse contiene los rangos ocupados ordenados por fecha
+type GetAvailabilityResponse struct {
+	Ranges []dto.AvailabilityRange `json:"ranges"`
+}
+
+// PropertiesServiceClient es el stub del servicio gRPC de propiedades
+type PropertiesServiceClient interface {
+	GetProperties(ctx context.Context, request *GetPropertiesRequest, opts ...grpc.CallOption) (*GetPropertiesResponse, error)
+	GetAvailability(ctx context.Context, request *GetAvailabilityRequest, opts ...grpc.CallOption) (*GetAvailabilityResponse, error)
+}
+
+type propertiesServiceClient struct {
+	conn grpc.ClientConnInterface
+}
+
+// NewPropertiesServiceClient crea el stub sobre una conexión gRPC (ver clients.NewGRPCConn)
+func NewPropertiesServiceClient(conn grpc.ClientConnInterface) PropertiesServiceClient {
+	return &propertiesServiceClient{conn: conn}
+}
+
+func (c *propertiesServiceClient) GetProperties(ctx context.Context, request *GetPropertiesRequest, opts ...grpc.CallOption) (*GetPropertiesResponse, error) {
+	response := new(GetPropertiesResponse)
+	if err := c.conn.Invoke(ctx, "/"+PropertiesServiceName+"/GetProperties", request, response, opts...); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *propertiesServiceClient) GetAvailability(ctx context.Context, request *GetAvailabilityRequest, opts ...grpc.CallOption) (*GetAvailabilityResponse, error) {
+	response := new(GetAvailabilityResponse)
+	if err := c.conn.Invoke(ctx, "/"+PropertiesServiceName+"/GetAvailability", request, response, opts...); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
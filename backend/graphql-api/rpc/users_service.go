@@ -0,0 +1,44 @@
+package rpc
+
+import (
+	"context"
+
+	"graphql-api/dto"
+
+	"google.golang.org/grpc"
+)
+
+// UsersServiceName es el nombre del servicio gRPC que expone users-api
+const UsersServiceName = "users.v1.Users"
+
+// GetUsersRequest pide un lote de usuarios por ID
+type GetUsersRequest struct {
+	UserIDs []uint `json:"userIds"`
+}
+
+// GetUsersResponse contiene los usuarios encontrados; los IDs inexistentes se omiten
+type GetUsersResponse struct {
+	Users []dto.User `json:"users"`
+}
+
+// UsersServiceClient es el stub del servicio gRPC de usuarios
+type UsersServiceClient interface {
+	GetUsers(ctx context.Context, request *GetUsersRequest, opts ...grpc.CallOption) (*GetUsersResponse, error)
+}
+
+type usersServiceClient struct {
+	conn grpc.ClientConnInterface
+}
+
+// NewUsersServiceClient crea el stub sobre una conexión gRPC (ver clients.NewGRPCConn)
+func NewUsersServiceClient(conn grpc.ClientConnInterface) UsersServiceClient {
+	return &usersServiceClient{conn: conn}
+}
+
+func (c *usersServiceClient) GetUsers(ctx context.Context, request *GetUsersRequest, opts ...grpc.CallOption) (*GetUsersResponse, error) {
+	response := new(GetUsersResponse)
+	if err := c.conn.Invoke(ctx, "/"+UsersServiceName+"/GetUsers", request, response, opts...); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
@@ -0,0 +1,10 @@
+// Package integration contiene la suite end-to-end de los microservicios
+//
+// Los tests levantan MongoDB, MySQL, Solr, Memcached y RabbitMQ con testcontainers-go, construyen
+// las imágenes de users-api, properties-api y search-api desde sus Dockerfiles y recorren el flujo
+// completo por HTTP. Necesitan Docker, así que van detrás del build tag "integration":
+//
+//	cd backend/integration
+//	go mod tidy
+//	go test -tags integration -v -timeout 20m ./...
+package integration
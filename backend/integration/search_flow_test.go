@@ -0,0 +1,157 @@
+//go:build integration
+
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// indexTimeout es lo que se espera a que una propiedad creada aparezca en la búsqueda
+// (evento en RabbitMQ, consulta gRPC a properties-api e indexación en Solr)
+const indexTimeout = 60 * time.Second
+
+// httpClient es el cliente HTTP de la suite
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// TestCreatedPropertyIsSearchable recorre el flujo completo: registro y login en users-api,
+// alta de la propiedad en properties-api y búsqueda en search-api una vez indexada en Solr
+func TestCreatedPropertyIsSearchable(t *testing.T) {
+	env := startEnvironment(t)
+
+	// 1. Registrar el usuario y loguearse para obtener el JWT
+	status, body := doJSON(t, http.MethodPost, env.UsersURL+"/users", "", map[string]string{
+		"username":  "integration_host",
+		"email":     "integration_host@example.com",
+		"password":  "Secret123!",
+		"firstName": "Integration",
+		"lastName":  "Host",
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("expected 201 creating user, got %d: %s", status, body)
+	}
+
+	status, body = doJSON(t, http.MethodPost, env.UsersURL+"/users/login", "", map[string]string{
+		"usernameOrEmail": "integration_host",
+		"password":        "Secret123!",
+	})
+	if status != http.StatusOK {
+		t.Fatalf("expected 200 on login, got %d: %s", status, body)
+	}
+	var login struct {
+		Token string `json:"token"`
+		User  struct {
+			ID uint `json:"id"`
+		} `json:"user"`
+	}
+	decode(t, body, &login)
+	if login.Token == "" || login.User.ID == 0 {
+		t.Fatalf("expected token and user id in login response, got %s", body)
+	}
+
+	// 2. Crear la propiedad a nombre del usuario (properties-api valida el owner por gRPC contra users-api)
+	status, body = doJSON(t, http.MethodPost, env.PropertiesURL+"/api/properties", login.Token, map[string]any{
+		"title":        "Cabaña integración junto al lago",
+		"description":  "Cabaña creada por la suite end-to-end para verificar la indexación en Solr.",
+		"price":        50000,
+		"location":     "Bariloche, Argentina",
+		"propertyType": "cabaña",
+		"country":      "AR",
+		"ownerId":      strconv.FormatUint(uint64(login.User.ID), 10),
+		"amenities":    []string{"wifi", "heating"},
+		"capacity":     4,
+		"available":    true,
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("expected 201 creating property, got %d: %s", status, body)
+	}
+	var property struct {
+		ID string `json:"id"`
+	}
+	decode(t, body, &property)
+	if property.ID == "" {
+		t.Fatalf("expected property id in response, got %s", body)
+	}
+
+	// 3. Esperar a que el evento property.created llegue a search-api y la propiedad se indexe
+	query := url.Values{"query": {"integración"}}
+	deadline := time.Now().Add(indexTimeout)
+	for {
+		status, body = doJSON(t, http.MethodGet, env.SearchURL+"/search?"+query.Encode(), "", nil)
+		if status == http.StatusOK && searchContains(t, body, property.ID) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("property %s not found in search after %v (last response %d: %s)", property.ID, indexTimeout, status, body)
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// searchContains indica si la respuesta de /search incluye la propiedad
+func searchContains(t *testing.T, body []byte, propertyID string) bool {
+	t.Helper()
+	var response struct {
+		Results []struct {
+			ID string `json:"id"`
+		} `json:"results"`
+	}
+	decode(t, body, &response)
+	for _, result := range response.Results {
+		if result.ID == propertyID {
+			return true
+		}
+	}
+	return false
+}
+
+// doJSON hace un request con payload JSON (opcional) y token Bearer (opcional) y retorna el status y el cuerpo
+func doJSON(t *testing.T, method, endpoint, token string, payload any) (int, []byte) {
+	t.Helper()
+
+	var reader io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatalf("failed to encode payload: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, endpoint, reader)
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+	// Sin User-Agent el detector de bots de search-api penaliza los requests
+	req.Header.Set("User-Agent", "spotly-integration-tests")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, endpoint, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read response of %s %s: %v", method, endpoint, err)
+	}
+	return resp.StatusCode, body
+}
+
+// decode parsea el cuerpo JSON de una respuesta
+func decode(t *testing.T, body []byte, out any) {
+	t.Helper()
+	if err := json.Unmarshal(body, out); err != nil {
+		t.Fatalf("failed to decode response %s: %v", body, err)
+	}
+}
@@ -0,0 +1,145 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Leader elige una réplica líder entre las que comparten el Locker
+// El líder es quien tiene el lock name: lo renueva mientras vive y, si muere, otra réplica lo toma
+// al vencer el lease. Los jobs LeaderOnly corren solo en el líder
+type Leader interface {
+	// IsLeader indica si esta réplica es el líder ahora
+	IsLeader() bool
+
+	// Start empieza a competir por el liderazgo en segundo plano
+	Start()
+
+	// Stop deja de competir y suelta el liderazgo (otra réplica lo toma en el próximo intento)
+	Stop()
+
+	// WriteMetrics escribe si esta réplica es líder y los cambios de liderazgo en formato de texto de Prometheus
+	WriteMetrics(w io.Writer) error
+}
+
+// leader es la implementación concreta de Leader
+type leader struct {
+	locker Locker
+	name   string
+	lease  time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	isLeader    atomic.Bool
+	transitions atomic.Uint64
+}
+
+// NewLeader crea la elección de líder name sobre locker con el lease indicado (ej: DefaultLease)
+func NewLeader(locker Locker, name string, lease time.Duration) Leader {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &leader{
+		locker: locker,
+		name:   name,
+		lease:  lease,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// IsLeader retorna el estado actual
+func (l *leader) IsLeader() bool {
+	return l.isLeader.Load()
+}
+
+// Start lanza la goroutine que intenta tomar el lock cada lease/3
+func (l *leader) Start() {
+	l.wg.Add(1)
+	go l.loop()
+}
+
+// Stop cancela la elección; si esta réplica era líder suelta el lock
+func (l *leader) Stop() {
+	l.cancel()
+	l.wg.Wait()
+}
+
+// loop intenta tomar el lock y, mientras lo tiene, espera a perderlo (Acquire lo renueva)
+func (l *leader) loop() {
+	defer l.wg.Done()
+
+	ticker := time.NewTicker(l.lease / 3)
+	defer ticker.Stop()
+	failing := false
+
+	for {
+		lock, err := Acquire(l.ctx, l.locker, l.name, l.lease)
+		switch {
+		case err == nil:
+			failing = false
+			l.setLeader(true)
+			<-lock.Context().Done()
+			l.setLeader(false)
+			if err := lock.Release(time.Time{}); err != nil {
+				log.Printf("⚠️ Error soltando el liderazgo de '%s': %v", l.name, err)
+			}
+		case errors.Is(err, ErrLockHeld) || l.ctx.Err() != nil:
+			failing = false
+		case !failing:
+			// Se loguea solo el primer error: si el Locker no responde se reintenta en cada vuelta
+			failing = true
+			log.Printf("⚠️ No se pudo competir por el liderazgo de '%s': %v", l.name, err)
+		}
+
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// setLeader registra un cambio de liderazgo
+func (l *leader) setLeader(isLeader bool) {
+	if l.isLeader.Swap(isLeader) == isLeader {
+		return
+	}
+	l.transitions.Add(1)
+	if isLeader {
+		log.Printf("👑 Esta réplica es líder de '%s'", l.name)
+	} else {
+		log.Printf("👑 Esta réplica dejó de ser líder de '%s'", l.name)
+	}
+}
+
+// WriteMetrics escribe leader_is_leader y leader_transitions_total
+func (l *leader) WriteMetrics(w io.Writer) error {
+	isLeader := 0
+	if l.IsLeader() {
+		isLeader = 1
+	}
+
+	metrics := []struct {
+		name  string
+		kind  string
+		help  string
+		value uint64
+	}{
+		{"leader_is_leader", "gauge", "1 si esta réplica es el líder de la elección", uint64(isLeader)},
+		{"leader_transitions_total", "counter", "Veces que esta réplica ganó o perdió el liderazgo", l.transitions.Load()},
+	}
+	for _, metric := range metrics {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s{election=%q} %d\n",
+			metric.name, metric.help, metric.name, metric.kind, metric.name, l.name, metric.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
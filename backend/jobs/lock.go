@@ -0,0 +1,160 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// ErrLockHeld indica que el lock lo tiene otra réplica (u otro proceso de esta)
+var ErrLockHeld = errors.New("el lock lo tiene otra réplica")
+
+// ErrLockLost indica que el lock venció o lo tomó otra réplica mientras se hacía el trabajo protegido
+var ErrLockLost = errors.New("se perdió el lock")
+
+// DefaultLease es el TTL con el que se toma un lock; mientras el dueño lo tiene se renueva cada DefaultLease/3
+// Es corto a propósito: si la réplica muere el lock queda libre enseguida, sin importar cuánto dura el trabajo
+const DefaultLease = time.Minute
+
+// Lock es un lock distribuido tomado con Acquire
+// Se renueva en segundo plano hasta Release; si se pierde (venció sin poder renovarse o lo tomó otro) se
+// cancela Context, así el trabajo protegido se corta en lugar de seguir en paralelo con otra réplica
+type Lock struct {
+	locker Locker
+	name   string
+	owner  string
+	lease  time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+// lockContextKey es la key con la que el context del trabajo protegido lleva su Lock (ver Verify)
+type lockContextKey struct{}
+
+// Acquire toma el lock name por lease y lo renueva hasta Release
+// Retorna ErrLockHeld si lo tiene otro owner. locker nil = sin lock distribuido (el lock se toma siempre)
+// ctx acota el trabajo protegido: Context se cancela junto con él
+func Acquire(ctx context.Context, locker Locker, name string, lease time.Duration) (*Lock, error) {
+	lockCtx, cancel := context.WithCancel(ctx)
+	lock := &Lock{
+		locker: locker,
+		name:   name,
+		owner:  lockOwner(),
+		lease:  lease,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	lock.ctx = context.WithValue(lockCtx, lockContextKey{}, lock)
+	if locker == nil {
+		close(lock.done)
+		return lock, nil
+	}
+
+	tryCtx, tryCancel := context.WithTimeout(ctx, lockTimeout)
+	acquired, err := locker.TryLock(tryCtx, name, lock.owner, lease)
+	tryCancel()
+	if err != nil || !acquired {
+		cancel()
+		if err == nil {
+			err = ErrLockHeld
+		}
+		return nil, err
+	}
+
+	go lock.keepAlive()
+	return lock, nil
+}
+
+// Context es el context del trabajo protegido: se cancela si el lock se pierde o se suelta
+func (l *Lock) Context() context.Context {
+	return l.ctx
+}
+
+// Verify confirma contra el Locker que el lock de ctx (el context de un Lock o uno derivado; el más interno si
+// hay varios) sigue siendo de esta réplica, y lo renueva. Se llama antes de cada paso que no se puede repetir en paralelo (ej: borrar
+// documentos o reemplazar un índice): la renovación en segundo plano detecta la pérdida recién en la próxima
+// vuelta. Si el lock se perdió cancela su context y retorna ErrLockLost. Sin lock en ctx retorna nil
+func Verify(ctx context.Context) error {
+	lock, _ := ctx.Value(lockContextKey{}).(*Lock)
+	if lock == nil || lock.locker == nil {
+		return nil
+	}
+	if err := lock.ctx.Err(); err != nil {
+		return fmt.Errorf("%w '%s': %v", ErrLockLost, lock.name, err)
+	}
+
+	checkCtx, cancel := context.WithTimeout(lock.ctx, lockTimeout)
+	held, err := lock.locker.Refresh(checkCtx, lock.name, lock.owner, lock.lease)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("error verificando el lock '%s': %w", lock.name, err)
+	}
+	if !held {
+		log.Printf("⚠️ Se perdió el lock '%s': lo tomó otra réplica", lock.name)
+		lock.cancel()
+		return fmt.Errorf("%w '%s'", ErrLockLost, lock.name)
+	}
+	return nil
+}
+
+// keepAlive renueva el lock cada lease/3
+// Un error de red se reintenta en la próxima vuelta; si pasa un lease entero sin renovar el lock se da por perdido
+func (l *Lock) keepAlive() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(l.lease / 3)
+	defer ticker.Stop()
+	renewed := time.Now()
+
+	for {
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(l.ctx, lockTimeout)
+		held, err := l.locker.Refresh(ctx, l.name, l.owner, l.lease)
+		cancel()
+		switch {
+		case err == nil && held:
+			renewed = time.Now()
+		case err == nil:
+			log.Printf("⚠️ Se perdió el lock '%s': lo tomó otra réplica", l.name)
+			l.cancel()
+			return
+		case l.ctx.Err() != nil:
+			return
+		case time.Since(renewed) >= l.lease:
+			log.Printf("⚠️ Se perdió el lock '%s': no se pudo renovar: %v", l.name, err)
+			l.cancel()
+			return
+		default:
+			log.Printf("⚠️ Error renovando el lock '%s' (se reintenta): %v", l.name, err)
+		}
+	}
+}
+
+// Release deja de renovar el lock y lo suelta
+// Si holdUntil es futuro lo conserva hasta ese momento (ej: para que otra réplica no repita el mismo período)
+// Si falla, el lock vence solo al terminar el lease
+func (l *Lock) Release(holdUntil time.Time) error {
+	var err error
+	l.once.Do(func() {
+		l.cancel()
+		<-l.done
+		if l.locker == nil {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), lockTimeout)
+		defer cancel()
+		err = l.locker.Unlock(ctx, l.name, l.owner, holdUntil)
+	})
+	return err
+}
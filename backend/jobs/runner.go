@@ -0,0 +1,349 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrSkipped lo retorna Run cuando el job no tenía nada que hacer en esta ejecución
+// (ej: ya había una ejecución manual en curso); se cuenta como "skipped" y no como error
+var ErrSkipped = errors.New("ejecución omitida")
+
+// lockTimeout limita cada operación contra el lock distribuido
+const lockTimeout = 5 * time.Second
+
+// Resultados de cada ejecución (label result de scheduled_job_runs_total)
+const (
+	ResultSuccess   = "success"
+	ResultError     = "error"
+	ResultSkipped   = "skipped"
+	ResultOverlap   = "overlap"
+	ResultLocked    = "locked"
+	ResultLockError = "lock_error"
+	ResultNotLeader = "not_leader"
+)
+
+// results es el orden en que se escriben los resultados en las métricas
+var results = []string{ResultSuccess, ResultError, ResultSkipped, ResultOverlap, ResultLocked, ResultLockError, ResultNotLeader}
+
+// Job es un proceso periódico
+type Job struct {
+	// Name identifica al job en los logs, las métricas y el lock distribuido
+	Name string
+
+	// Schedule calcula las ejecuciones
+	Schedule Schedule
+
+	// Timeout cancela el context de la ejecución (0 = sin límite)
+	Timeout time.Duration
+
+	// RunOnStart ejecuta el job al arrancar el runner además de en cada ejecución programada
+	RunOnStart bool
+
+	// Exclusive corre el job en una sola réplica por período con el lock distribuido del runner
+	Exclusive bool
+
+	// LeaderOnly corre el job solo en la réplica líder (ver Leader); en el resto se cuenta como "not_leader"
+	// Se puede combinar con Exclusive para cubrir el instante en que dos réplicas se creen líderes
+	LeaderOnly bool
+
+	// Run ejecuta el job; un error se loguea y se cuenta en las métricas
+	Run func(ctx context.Context) error
+}
+
+// Locker guarda los locks distribuidos (ver Acquire) con los que los jobs exclusivos corren en una sola réplica
+type Locker interface {
+	// TryLock toma el lock name para owner por ttl; retorna false si lo tiene otro owner
+	TryLock(ctx context.Context, name, owner string, ttl time.Duration) (bool, error)
+
+	// Refresh extiende el lock de owner por ttl desde ahora; retorna false si ya no es de owner
+	Refresh(ctx context.Context, name, owner string, ttl time.Duration) (bool, error)
+
+	// Unlock suelta el lock si sigue siendo de owner
+	// Si holdUntil es futuro lo conserva hasta ese momento, así otra réplica no repite el mismo período
+	Unlock(ctx context.Context, name, owner string, holdUntil time.Time) error
+}
+
+// Runner ejecuta los jobs registrados según su programación
+// Una ejecución que sigue en curso cuando llega la siguiente hace que esa se omita (no se superponen)
+type Runner interface {
+	// Register agrega un job; se llama antes de Start
+	Register(job Job) error
+
+	// Start programa todos los jobs registrados
+	Start()
+
+	// Stop cancela la programación y las ejecuciones en curso y espera a que terminen
+	Stop()
+
+	// WriteMetrics escribe las métricas de los jobs en formato de texto de Prometheus
+	WriteMetrics(w io.Writer) error
+}
+
+// jobState es un job registrado con su estado y sus contadores
+type jobState struct {
+	job Job
+
+	mu          sync.Mutex
+	running     bool
+	runs        map[string]uint64
+	lastRun     time.Duration
+	lastSuccess time.Time
+}
+
+// runner es la implementación concreta de Runner
+type runner struct {
+	locker Locker
+	leader Leader
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	started bool
+	jobs    []*jobState
+}
+
+// NewRunner crea el runner de jobs
+// locker nil = sin lock distribuido: los jobs exclusivos corren en todas las réplicas
+// leader nil = sin elección de líder: los jobs LeaderOnly corren en todas las réplicas
+func NewRunner(locker Locker, leader Leader) Runner {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &runner{
+		locker: locker,
+		leader: leader,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Register valida y agrega un job
+func (r *runner) Register(job Job) error {
+	if job.Name == "" || job.Schedule == nil || job.Run == nil {
+		return fmt.Errorf("el job '%s' requiere nombre, programación y función", job.Name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.started {
+		return fmt.Errorf("el job '%s' se registró después de iniciar el runner", job.Name)
+	}
+	for _, state := range r.jobs {
+		if state.job.Name == job.Name {
+			return fmt.Errorf("el job '%s' ya está registrado", job.Name)
+		}
+	}
+	r.jobs = append(r.jobs, &jobState{job: job, runs: make(map[string]uint64)})
+	return nil
+}
+
+// Start lanza una goroutine por job
+func (r *runner) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.started {
+		return
+	}
+	r.started = true
+
+	for _, state := range r.jobs {
+		r.wg.Add(1)
+		go r.loop(state)
+	}
+	log.Printf("🗓️ Runner de jobs iniciado con %d jobs", len(r.jobs))
+}
+
+// Stop cancela la programación y espera a las ejecuciones en curso
+func (r *runner) Stop() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+// loop espera cada ejecución programada y la dispara
+func (r *runner) loop(state *jobState) {
+	defer r.wg.Done()
+
+	if state.job.RunOnStart {
+		r.dispatch(state)
+	}
+	for {
+		timer := time.NewTimer(time.Until(state.job.Schedule.Next(time.Now().UTC())))
+		select {
+		case <-r.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		r.dispatch(state)
+	}
+}
+
+// dispatch ejecuta el job en segundo plano salvo que la ejecución anterior siga en curso
+func (r *runner) dispatch(state *jobState) {
+	state.mu.Lock()
+	if state.running {
+		state.runs[ResultOverlap]++
+		state.mu.Unlock()
+		log.Printf("⏭️ Job %s omitido: la ejecución anterior sigue en curso", state.job.Name)
+		return
+	}
+	state.running = true
+	state.mu.Unlock()
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		result, duration := r.execute(state)
+
+		state.mu.Lock()
+		defer state.mu.Unlock()
+		state.running = false
+		state.runs[result]++
+		if result == ResultSuccess || result == ResultError {
+			state.lastRun = duration
+		}
+		if result == ResultSuccess {
+			state.lastSuccess = time.Now().UTC()
+		}
+	}()
+}
+
+// execute toma el lock (jobs exclusivos), corre el job y retorna el resultado y la duración
+func (r *runner) execute(state *jobState) (string, time.Duration) {
+	job := state.job
+	started := time.Now().UTC()
+
+	if job.LeaderOnly && r.leader != nil && !r.leader.IsLeader() {
+		return ResultNotLeader, 0
+	}
+
+	ctx := r.ctx
+	if job.Exclusive && r.locker != nil {
+		lock, err := Acquire(r.ctx, r.locker, job.Name, DefaultLease)
+		if errors.Is(err, ErrLockHeld) {
+			return ResultLocked, 0
+		}
+		if err != nil {
+			log.Printf("⚠️ Job %s omitido: error tomando el lock distribuido: %v", job.Name, err)
+			return ResultLockError, 0
+		}
+		// El lock se conserva hasta la mitad del período para que las otras réplicas no lo repitan
+		defer func() {
+			if err := lock.Release(started.Add(job.Schedule.Next(started).Sub(started) / 2)); err != nil {
+				log.Printf("⚠️ Error soltando el lock del job %s: %v", job.Name, err)
+			}
+		}()
+		ctx = lock.Context()
+	}
+
+	if job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, job.Timeout)
+		defer cancel()
+	}
+
+	err := runSafely(ctx, job)
+	duration := time.Since(started)
+	switch {
+	case errors.Is(err, ErrSkipped):
+		return ResultSkipped, duration
+	case err != nil:
+		log.Printf("⚠️ Job %s falló después de %v: %v", job.Name, duration.Round(time.Millisecond), err)
+		return ResultError, duration
+	}
+	return ResultSuccess, duration
+}
+
+// runSafely corre el job convirtiendo un panic en error (un job roto no tira abajo el servicio)
+func runSafely(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+	return job.Run(ctx)
+}
+
+// WriteMetrics escribe ejecuciones por resultado, duración, último éxito y ejecuciones en curso
+// y, si hay elección de líder, las métricas del liderazgo
+func (r *runner) WriteMetrics(w io.Writer) error {
+	r.mu.Lock()
+	states := make([]*jobState, len(r.jobs))
+	copy(states, r.jobs)
+	r.mu.Unlock()
+	sort.Slice(states, func(i, j int) bool { return states[i].job.Name < states[j].job.Name })
+
+	type jobSeries struct {
+		labels string
+		value  float64
+	}
+	var runs, running, durations, successes []jobSeries
+	for _, state := range states {
+		state.mu.Lock()
+		name := state.job.Name
+		for _, result := range results {
+			runs = append(runs, jobSeries{fmt.Sprintf("{job=%q,result=%q}", name, result), float64(state.runs[result])})
+		}
+		inFlight, lastSuccess := 0.0, 0.0
+		if state.running {
+			inFlight = 1
+		}
+		if !state.lastSuccess.IsZero() {
+			lastSuccess = float64(state.lastSuccess.Unix())
+		}
+		running = append(running, jobSeries{fmt.Sprintf("{job=%q}", name), inFlight})
+		durations = append(durations, jobSeries{fmt.Sprintf("{job=%q}", name), state.lastRun.Seconds()})
+		successes = append(successes, jobSeries{fmt.Sprintf("{job=%q}", name), lastSuccess})
+		state.mu.Unlock()
+	}
+
+	metrics := []struct {
+		name   string
+		kind   string
+		help   string
+		series []jobSeries
+	}{
+		{"scheduled_job_runs_total", "counter", "Ejecuciones de los jobs programados por resultado", runs},
+		{"scheduled_job_running", "gauge", "Jobs con una ejecución en curso en esta réplica", running},
+		{"scheduled_job_last_duration_seconds", "gauge", "Duración de la última ejecución del job", durations},
+		{"scheduled_job_last_success_timestamp_seconds", "gauge", "Momento en que terminó la última ejecución exitosa del job", successes},
+	}
+
+	for _, metric := range metrics {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", metric.name, metric.help, metric.name, metric.kind); err != nil {
+			return err
+		}
+		for _, series := range metric.series {
+			if _, err := fmt.Fprintf(w, "%s%s %g\n", metric.name, series.labels, series.value); err != nil {
+				return err
+			}
+		}
+	}
+	if r.leader != nil {
+		return r.leader.WriteMetrics(w)
+	}
+	return nil
+}
+
+// lockOwner identifica a quien toma un lock: hostname y un sufijo aleatorio por cada Acquire
+func lockOwner() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "unknown"
+	}
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return host
+	}
+	return host + "-" + hex.EncodeToString(suffix)
+}
@@ -0,0 +1,246 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryLocker es un Locker en memoria compartido por varios runners (simula varias réplicas)
+type memoryLocker struct {
+	mu    sync.Mutex
+	locks map[string]memoryLock
+}
+
+// memoryLock es un lock guardado con su owner y vencimiento
+type memoryLock struct {
+	owner     string
+	expiresAt time.Time
+}
+
+// newMemoryLocker crea un memoryLocker vacío
+func newMemoryLocker() *memoryLocker {
+	return &memoryLocker{locks: make(map[string]memoryLock)}
+}
+
+// TryLock implementa Locker.TryLock
+func (l *memoryLocker) TryLock(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if lock, ok := l.locks[name]; ok && time.Now().Before(lock.expiresAt) {
+		return false, nil
+	}
+	l.locks[name] = memoryLock{owner: owner, expiresAt: time.Now().Add(ttl)}
+	return true, nil
+}
+
+// Refresh implementa Locker.Refresh
+func (l *memoryLocker) Refresh(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if lock, ok := l.locks[name]; ok && lock.owner == owner {
+		l.locks[name] = memoryLock{owner: owner, expiresAt: time.Now().Add(ttl)}
+		return true, nil
+	}
+	return false, nil
+}
+
+// Unlock implementa Locker.Unlock
+func (l *memoryLocker) Unlock(ctx context.Context, name, owner string, holdUntil time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if lock, ok := l.locks[name]; ok && lock.owner == owner {
+		l.locks[name] = memoryLock{owner: owner, expiresAt: holdUntil}
+	}
+	return nil
+}
+
+func TestRunner_PreventsOverlapAndRunsExclusiveJobsOnce(t *testing.T) {
+	locker := newMemoryLocker()
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	var mu sync.Mutex
+	runs := 0
+
+	job := Job{
+		Name:      "sweep",
+		Schedule:  Every(time.Hour),
+		Timeout:   time.Minute,
+		Exclusive: true,
+		Run: func(ctx context.Context) error {
+			mu.Lock()
+			runs++
+			mu.Unlock()
+			started <- struct{}{}
+			<-release
+			return nil
+		},
+	}
+
+	first := NewRunner(locker, nil).(*runner)
+	second := NewRunner(locker, nil).(*runner)
+	for _, r := range []*runner{first, second} {
+		if err := r.Register(job); err != nil {
+			t.Fatalf("unexpected register error: %v", err)
+		}
+	}
+	if err := first.Register(job); err == nil {
+		t.Fatal("expected an error registering a duplicated job name")
+	}
+
+	first.dispatch(first.jobs[0])
+	<-started
+	// Misma réplica con la ejecución en curso: se omite por superposición
+	first.dispatch(first.jobs[0])
+	// Otra réplica en el mismo período: se omite porque el lock está tomado
+	second.dispatch(second.jobs[0])
+	second.wg.Wait()
+
+	close(release)
+	first.wg.Wait()
+
+	// El lock se conserva hasta la mitad del período: la otra réplica no repite la ejecución
+	second.dispatch(second.jobs[0])
+	second.wg.Wait()
+
+	if runs != 1 {
+		t.Fatalf("expected the job to run once, got %d", runs)
+	}
+
+	var metrics bytes.Buffer
+	if err := first.WriteMetrics(&metrics); err != nil {
+		t.Fatalf("unexpected metrics error: %v", err)
+	}
+	for _, line := range []string{
+		`scheduled_job_runs_total{job="sweep",result="success"} 1`,
+		`scheduled_job_runs_total{job="sweep",result="overlap"} 1`,
+		`scheduled_job_running{job="sweep"} 0`,
+	} {
+		if !strings.Contains(metrics.String(), line) {
+			t.Errorf("expected metrics to contain %q, got:\n%s", line, metrics.String())
+		}
+	}
+
+	metrics.Reset()
+	if err := second.WriteMetrics(&metrics); err != nil {
+		t.Fatalf("unexpected metrics error: %v", err)
+	}
+	if want := `scheduled_job_runs_total{job="sweep",result="locked"} 2`; !strings.Contains(metrics.String(), want) {
+		t.Errorf("expected metrics to contain %q, got:\n%s", want, metrics.String())
+	}
+}
+
+func TestAcquire_RenewsAndCancelsContextWhenLost(t *testing.T) {
+	locker := newMemoryLocker()
+	lease := 60 * time.Millisecond
+
+	lock, err := Acquire(context.Background(), locker, "reindex", lease)
+	if err != nil {
+		t.Fatalf("unexpected acquire error: %v", err)
+	}
+	if _, err := Acquire(context.Background(), locker, "reindex", lease); err != ErrLockHeld {
+		t.Fatalf("expected ErrLockHeld while the lock is held, got %v", err)
+	}
+
+	// Las renovaciones mantienen el lock después del primer lease
+	time.Sleep(3 * lease)
+	if lock.Context().Err() != nil {
+		t.Fatal("expected the lock to be renewed while held")
+	}
+
+	// Otro owner lo toma: la próxima renovación lo detecta y cancela el context
+	locker.mu.Lock()
+	locker.locks["reindex"] = memoryLock{owner: "other", expiresAt: time.Now().Add(time.Hour)}
+	locker.mu.Unlock()
+	select {
+	case <-lock.Context().Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected the context to be cancelled after losing the lock")
+	}
+	if err := lock.Release(time.Time{}); err != nil {
+		t.Fatalf("unexpected release error: %v", err)
+	}
+	if owner := locker.locks["reindex"].owner; owner != "other" {
+		t.Fatalf("expected release to leave the other owner's lock alone, got owner %q", owner)
+	}
+}
+
+func TestLeader_OnlyOneReplicaLeadsAndHandsOverOnStop(t *testing.T) {
+	locker := newMemoryLocker()
+	lease := 60 * time.Millisecond
+	first := NewLeader(locker, "search-api", lease)
+	second := NewLeader(locker, "search-api", lease)
+
+	first.Start()
+	waitFor(t, first.IsLeader, "the first replica to become leader")
+	second.Start()
+	defer second.Stop()
+
+	time.Sleep(2 * lease)
+	if !first.IsLeader() || second.IsLeader() {
+		t.Fatalf("expected only the first replica to lead, got first=%v second=%v", first.IsLeader(), second.IsLeader())
+	}
+
+	// Los jobs LeaderOnly se omiten en las réplicas que no son líder
+	runner := NewRunner(locker, second).(*runner)
+	runner.Register(Job{Name: "warm", Schedule: Every(time.Hour), LeaderOnly: true, Run: func(ctx context.Context) error {
+		t.Error("expected the job not to run on a follower")
+		return nil
+	}})
+	if result, _ := runner.execute(runner.jobs[0]); result != ResultNotLeader {
+		t.Fatalf("expected %q, got %q", ResultNotLeader, result)
+	}
+
+	first.Stop()
+	if first.IsLeader() {
+		t.Fatal("expected the stopped replica to give up leadership")
+	}
+	waitFor(t, second.IsLeader, "the second replica to take over")
+}
+
+// waitFor espera hasta un segundo a que condition se cumpla
+func waitFor(t *testing.T, condition func() bool, what string) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestVerify_DetectsLostLockBeforeTheNextStep(t *testing.T) {
+	locker := newMemoryLocker()
+
+	// Sin lock en el context no hay nada que verificar
+	if err := Verify(context.Background()); err != nil {
+		t.Fatalf("expected no error without a lock, got %v", err)
+	}
+
+	lock, err := Acquire(context.Background(), locker, "reconcile", time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected acquire error: %v", err)
+	}
+	defer lock.Release(time.Time{})
+	step, cancel := context.WithTimeout(lock.Context(), time.Minute)
+	defer cancel()
+	if err := Verify(step); err != nil {
+		t.Fatalf("expected the lock to be held, got %v", err)
+	}
+
+	// Con un lease largo la renovación en segundo plano tardaría en notarlo: Verify lo detecta en el momento
+	locker.mu.Lock()
+	locker.locks["reconcile"] = memoryLock{owner: "other", expiresAt: time.Now().Add(time.Hour)}
+	locker.mu.Unlock()
+	if err := Verify(step); !errors.Is(err, ErrLockLost) {
+		t.Fatalf("expected ErrLockLost, got %v", err)
+	}
+	if lock.Context().Err() == nil {
+		t.Fatal("expected the lock context to be cancelled after losing the lock")
+	}
+}
@@ -0,0 +1,44 @@
+package jobs
+
+import "time"
+
+// Schedule calcula cuándo corre un job
+type Schedule interface {
+	// Next retorna el próximo momento de ejecución posterior a after
+	Next(after time.Time) time.Time
+}
+
+// everySchedule corre el job cada interval desde la ejecución anterior
+type everySchedule struct {
+	interval time.Duration
+}
+
+// Every programa el job cada interval (contado desde que se calcula la próxima ejecución)
+func Every(interval time.Duration) Schedule {
+	return everySchedule{interval: interval}
+}
+
+// Next retorna after + interval
+func (s everySchedule) Next(after time.Time) time.Time {
+	return after.Add(s.interval)
+}
+
+// dailySchedule corre el job todos los días a una hora fija
+type dailySchedule struct {
+	hour int
+}
+
+// DailyAt programa el job todos los días a la hora indicada (0-23, UTC)
+func DailyAt(hour int) Schedule {
+	return dailySchedule{hour: hour}
+}
+
+// Next retorna el próximo momento a la hora indicada (UTC) posterior a after
+func (s dailySchedule) Next(after time.Time) time.Time {
+	after = after.UTC()
+	next := time.Date(after.Year(), after.Month(), after.Day(), s.hour, 0, 0, 0, time.UTC)
+	if !next.After(after) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
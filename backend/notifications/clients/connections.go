@@ -0,0 +1,59 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+
+	"notifications/rpc"
+	"signing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
+)
+
+// APIKeyMetadataKey es el metadata gRPC con el que un servicio interno envía su API key
+const APIKeyMetadataKey = "x-api-key"
+
+// InternalCallAuth son las credenciales con las que el servicio se identifica en las llamadas gRPC internas
+type InternalCallAuth struct {
+	Caller        string // Nombre del servicio, va firmado en cada llamada
+	APIKey        string // API key emitida en users-api (vacía = las llamadas van sin key)
+	SigningSecret string // Secreto compartido para firmar con HMAC (vacío = las llamadas van sin firma)
+}
+
+// NewGRPCConn crea la conexión gRPC hacia otro servicio interno (ej: "users-api:9090")
+// La conexión es perezosa: se abre con la primera llamada y se reconecta sola si se cae
+// auth tiene la API key y el secreto de firma de notifications; los vacíos no se envían
+func NewGRPCConn(addr string, auth InternalCallAuth) (*grpc.ClientConn, error) {
+	options := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.CallContentSubtype(rpc.CodecName)),
+	}
+	if auth.APIKey != "" {
+		options = append(options, grpc.WithPerRPCCredentials(apiKeyCredentials{key: auth.APIKey}))
+	}
+	if auth.SigningSecret != "" {
+		options = append(options, grpc.WithUnaryInterceptor(signing.UnaryClientInterceptor(auth.Caller, auth.SigningSecret, encoding.GetCodec(rpc.CodecName))))
+	}
+	conn, err := grpc.NewClient(addr, options...)
+	if err != nil {
+		return nil, fmt.Errorf("error creando conexión gRPC a %s: %w", addr, err)
+	}
+	return conn, nil
+}
+
+// apiKeyCredentials agrega la API key al metadata de cada llamada gRPC
+type apiKeyCredentials struct {
+	key string
+}
+
+// GetRequestMetadata retorna el metadata que se envía en cada llamada
+func (c apiKeyCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{APIKeyMetadataKey: c.key}, nil
+}
+
+// RequireTransportSecurity es false porque la red interna usa conexiones sin TLS
+func (c apiKeyCredentials) RequireTransportSecurity() bool {
+	return false
+}
@@ -0,0 +1,52 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"notifications/dto"
+	"notifications/rpc"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrPropertyNotFound indica que la propiedad no existe en properties-api
+var ErrPropertyNotFound = errors.New("propiedad no encontrada")
+
+// PropertiesClient obtiene propiedades de properties-api por gRPC
+type PropertiesClient interface {
+	// GetProperty obtiene una propiedad por ID; retorna ErrPropertyNotFound si no existe
+	GetProperty(ctx context.Context, id string) (*dto.Property, error)
+}
+
+type propertiesClient struct {
+	stub        rpc.PropertiesServiceClient
+	callTimeout time.Duration
+}
+
+// NewPropertiesClient crea el cliente de propiedades sobre una conexión gRPC (ver NewGRPCConn)
+func NewPropertiesClient(conn grpc.ClientConnInterface, callTimeout time.Duration) PropertiesClient {
+	return &propertiesClient{
+		stub:        rpc.NewPropertiesServiceClient(conn),
+		callTimeout: callTimeout,
+	}
+}
+
+// GetProperty obtiene una propiedad por ID
+func (c *propertiesClient) GetProperty(ctx context.Context, id string) (*dto.Property, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.callTimeout)
+	defer cancel()
+
+	response, err := c.stub.GetProperty(ctx, &rpc.GetPropertyRequest{ID: id})
+	if status.Code(err) == codes.NotFound {
+		return nil, ErrPropertyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo propiedad %s de properties-api: %w", id, err)
+	}
+	return &response.Property, nil
+}
@@ -0,0 +1,48 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"notifications/dto"
+	"notifications/rpc"
+
+	"google.golang.org/grpc"
+)
+
+// UsersClient obtiene usuarios de users-api por gRPC
+type UsersClient interface {
+	// GetUsers obtiene un lote de usuarios en una sola llamada; los IDs inexistentes no están en el mapa
+	GetUsers(ctx context.Context, ids []uint) (map[uint]dto.User, error)
+}
+
+type usersClient struct {
+	stub        rpc.UsersServiceClient
+	callTimeout time.Duration
+}
+
+// NewUsersClient crea el cliente de usuarios sobre una conexión gRPC (ver NewGRPCConn)
+func NewUsersClient(conn grpc.ClientConnInterface, callTimeout time.Duration) UsersClient {
+	return &usersClient{
+		stub:        rpc.NewUsersServiceClient(conn),
+		callTimeout: callTimeout,
+	}
+}
+
+// GetUsers obtiene un lote de usuarios en una sola llamada
+func (c *usersClient) GetUsers(ctx context.Context, ids []uint) (map[uint]dto.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.callTimeout)
+	defer cancel()
+
+	response, err := c.stub.GetUsers(ctx, &rpc.GetUsersRequest{UserIDs: ids})
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo usuarios de users-api: %w", err)
+	}
+
+	users := make(map[uint]dto.User, len(response.Users))
+	for _, user := range response.Users {
+		users[user.ID] = user
+	}
+	return users, nil
+}
@@ -0,0 +1,272 @@
+package consumers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+
+	"notifications/config"
+	"notifications/domain"
+	"notifications/services"
+	"notifications/utils"
+	"reporting"
+
+	"github.com/streadway/amqp"
+)
+
+// consumerTag identifica al consumidor en el channel (se usa para cancelarlo al cerrar)
+const consumerTag = "notifications"
+
+// processTimeout limita el procesamiento de un evento, incluidos sus reintentos
+const processTimeout = 2 * time.Minute
+
+// shutdownTimeout es la espera máxima para que termine el mensaje en curso al cerrar
+const shutdownTimeout = 30 * time.Second
+
+// routingKeys son los eventos que consume notifications
+var routingKeys = []string{domain.BookingConfirmedRoutingKey, domain.MessageSentRoutingKey}
+
+// EventConsumer consume los eventos de reservas y mensajes y envía los emails correspondientes
+type EventConsumer struct {
+	connection *amqp.Connection
+	channel    *amqp.Channel
+	queueName  string
+	service    services.NotificationService
+	reporter   reporting.ErrorReporter
+
+	settings config.ConsumerConfig
+	started  atomic.Bool
+	done     chan struct{} // se cierra cuando se terminó de procesar el último mensaje
+}
+
+// NewEventConsumer crea el consumidor de eventos
+// Conecta con RabbitMQ, declara el exchange y la queue propia, y la bindea con "booking.confirmed" y "message.sent"
+// Los eventos que no se pudieron procesar (y los panics) se reportan a reporter
+func NewEventConsumer(rabbitURL, exchange, queueName string, service services.NotificationService, reporter reporting.ErrorReporter, settings config.ConsumerConfig) (*EventConsumer, error) {
+	log.Printf("🔌 Conectando a RabbitMQ en: %s", rabbitURL)
+
+	conn, err := amqp.Dial(rabbitURL)
+	if err != nil {
+		return nil, fmt.Errorf("error conectando a RabbitMQ: %w", err)
+	}
+
+	channel, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error creando channel de RabbitMQ: %w", err)
+	}
+
+	// Declarar el topic exchange (idempotente, mismos parámetros que properties-api)
+	if err := channel.ExchangeDeclare(exchange, "topic", true, false, false, false, nil); err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("error declarando exchange '%s' en RabbitMQ: %w", exchange, err)
+	}
+
+	// Declarar la queue propia (durable: los eventos no se pierden si el servicio está caído)
+	if _, err := channel.QueueDeclare(queueName, true, false, false, false, nil); err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("error declarando queue '%s' en RabbitMQ: %w", queueName, err)
+	}
+
+	for _, routingKey := range routingKeys {
+		if err := channel.QueueBind(queueName, routingKey, exchange, false, nil); err != nil {
+			channel.Close()
+			conn.Close()
+			return nil, fmt.Errorf("error bindeando queue '%s' al exchange '%s' con '%s': %w", queueName, exchange, routingKey, err)
+		}
+		log.Printf("✅ Queue '%s' bindeada al exchange '%s' con '%s'", queueName, exchange, routingKey)
+	}
+
+	if settings.Prefetch < 1 {
+		settings.Prefetch = 1
+	}
+
+	return &EventConsumer{
+		connection: conn,
+		channel:    channel,
+		queueName:  queueName,
+		service:    service,
+		reporter:   reporter,
+		settings:   settings,
+		done:       make(chan struct{}),
+	}, nil
+}
+
+// Start inicia el consumo de mensajes de la queue
+// Los mensajes se procesan de a uno: el envío de emails no necesita más concurrencia
+func (c *EventConsumer) Start() error {
+	if err := c.channel.Qos(c.settings.Prefetch, 0, false); err != nil {
+		return fmt.Errorf("error configurando QoS: %w", err)
+	}
+
+	msgs, err := c.channel.Consume(c.queueName, consumerTag, false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("error registrando consumidor: %w", err)
+	}
+
+	c.started.Store(true)
+	go func() {
+		for msg := range msgs {
+			c.processMessage(msg)
+		}
+		close(c.done)
+	}()
+
+	log.Printf("✅ Consumidor escuchando la queue: %s", c.queueName)
+	return nil
+}
+
+// processMessage procesa un evento según su routing key
+// Las fallas transitorias se reintentan en el momento; si persisten el mensaje se rechaza sin reencolar
+// (va a la dead-letter de la queue si está configurada) para no bloquear los eventos siguientes
+func (c *EventConsumer) processMessage(msg amqp.Delivery) {
+	var description, eventID, userID string
+	var handle func(ctx context.Context) error
+
+	// Un panic procesando un evento no tiene que cortar el consumo de los siguientes
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			return
+		}
+		stack := string(debug.Stack())
+		log.Printf("💥 Panic procesando el evento %s: %v\n%s", msg.RoutingKey, recovered, stack)
+		c.reporter.Report(reporting.ErrorEvent{
+			Message:    fmt.Sprintf("panic: %v", recovered),
+			Level:      reporting.ErrorLevelFatal,
+			Stacktrace: stack,
+			Tags:       eventTags(msg, eventID, userID),
+		})
+		msg.Nack(false, false)
+	}()
+
+	switch msg.RoutingKey {
+	case domain.BookingConfirmedRoutingKey:
+		var event domain.BookingConfirmedEvent
+		if err := json.Unmarshal(msg.Body, &event); err != nil {
+			log.Printf("❌ Error deserializando evento: %v. Body: %s", err, string(msg.Body))
+			msg.Nack(false, false)
+			return
+		}
+		if event.ID == "" || event.PropertyID == "" || event.UserID == "" {
+			log.Printf("❌ Evento inválido: faltan id, propertyId o userId. Body: %s", string(msg.Body))
+			msg.Nack(false, false)
+			return
+		}
+		log.Printf("📨 Reserva confirmada recibida: %s (propiedad %s)", event.ID, event.PropertyID)
+		description = "la reserva " + event.ID
+		eventID, userID = event.ID, event.UserID
+		handle = func(ctx context.Context) error {
+			return c.service.SendBookingConfirmation(ctx, event)
+		}
+
+	case domain.MessageSentRoutingKey:
+		var event domain.MessageSentEvent
+		if err := json.Unmarshal(msg.Body, &event); err != nil {
+			log.Printf("❌ Error deserializando evento: %v. Body: %s", err, string(msg.Body))
+			msg.Nack(false, false)
+			return
+		}
+		if event.MessageID == "" || event.ConversationID == "" || event.PropertyID == "" || event.RecipientID == "" {
+			log.Printf("❌ Evento inválido: faltan messageId, conversationId, propertyId o recipientId. Body: %s", string(msg.Body))
+			msg.Nack(false, false)
+			return
+		}
+		log.Printf("📨 Mensaje recibido: %s (conversación %s)", event.MessageID, event.ConversationID)
+		description = "el mensaje " + event.MessageID
+		eventID, userID = event.MessageID, event.RecipientID
+		handle = func(ctx context.Context) error {
+			return c.service.NotifyMessageReceived(ctx, event)
+		}
+
+	default:
+		log.Printf("⚠️ Routing key desconocida: %s", msg.RoutingKey)
+		msg.Ack(false)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), processTimeout)
+	defer cancel()
+
+	policy := utils.RetryPolicy{
+		MaxAttempts: c.settings.RetryAttempts,
+		BaseDelay:   c.settings.RetryBaseDelay,
+		MaxDelay:    c.settings.RetryMaxDelay,
+	}
+	err := utils.Retry(ctx, policy, handle)
+
+	switch {
+	case err == nil:
+		msg.Ack(false)
+	case utils.IsPermanent(err):
+		// Reintentar no lo va a resolver (ej: la propiedad ya no existe)
+		log.Printf("❌ Error definitivo procesando %s, se descarta: %v", description, err)
+		c.reportFailure(msg, eventID, userID, fmt.Sprintf("Error definitivo procesando %s: %v", description, err))
+		msg.Ack(false)
+	default:
+		log.Printf("❌ No se pudieron enviar los emails de %s tras %d intentos: %v", description, policy.MaxAttempts, err)
+		c.reportFailure(msg, eventID, userID, fmt.Sprintf("No se pudieron enviar los emails de %s: %v", description, err))
+		msg.Nack(false, false)
+	}
+}
+
+// reportFailure reporta un evento que se descartó sin enviar sus emails
+func (c *EventConsumer) reportFailure(msg amqp.Delivery, eventID, userID, message string) {
+	c.reporter.Report(reporting.ErrorEvent{
+		Message: message,
+		Level:   reporting.ErrorLevelError,
+		Tags:    eventTags(msg, eventID, userID),
+	})
+}
+
+// eventTags son los tags de un evento de RabbitMQ (no hay request: se identifica por el ID de la reserva o del mensaje)
+func eventTags(msg amqp.Delivery, eventID, userID string) map[string]string {
+	tags := map[string]string{"routing_key": msg.RoutingKey}
+	if eventID != "" {
+		tags["event_id"] = eventID
+	}
+	if userID != "" {
+		tags["user_id"] = userID
+	}
+	return tags
+}
+
+// Ping retorna error si la conexión con RabbitMQ está cerrada (usado por el health check)
+func (c *EventConsumer) Ping() error {
+	if c.connection == nil || c.connection.IsClosed() {
+		return fmt.Errorf("conexión con RabbitMQ cerrada")
+	}
+	return nil
+}
+
+// Close cancela el consumidor, espera el mensaje en curso y cierra las conexiones
+func (c *EventConsumer) Close() error {
+	log.Println("🔌 Cerrando conexiones de RabbitMQ...")
+
+	if c.started.Load() {
+		if err := c.channel.Cancel(consumerTag, false); err != nil {
+			log.Printf("⚠️ Error cancelando consumidor: %v", err)
+		} else {
+			select {
+			case <-c.done:
+			case <-time.After(shutdownTimeout):
+				log.Println("⚠️ Timeout esperando el mensaje en curso")
+			}
+		}
+	}
+
+	if err := c.channel.Close(); err != nil {
+		return fmt.Errorf("error cerrando channel: %w", err)
+	}
+	if err := c.connection.Close(); err != nil {
+		return fmt.Errorf("error cerrando conexión: %w", err)
+	}
+	log.Println("✅ Conexiones de RabbitMQ cerradas")
+	return nil
+}
@@ -0,0 +1,162 @@
+package consumers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"notifications/config"
+	"notifications/domain"
+	"notifications/services"
+	"notifications/utils"
+	"reporting"
+
+	"github.com/streadway/amqp"
+)
+
+// recordingAcknowledger registra cómo terminó cada entrega
+type recordingAcknowledger struct {
+	acked, nacked int
+}
+
+func (a *recordingAcknowledger) Ack(tag uint64, multiple bool) error {
+	a.acked++
+	return nil
+}
+
+func (a *recordingAcknowledger) Nack(tag uint64, multiple, requeue bool) error {
+	a.nacked++
+	return nil
+}
+
+func (a *recordingAcknowledger) Reject(tag uint64, requeue bool) error {
+	a.nacked++
+	return nil
+}
+
+// recordingReporter guarda los eventos reportados
+type recordingReporter struct {
+	events []reporting.ErrorEvent
+}
+
+func (r *recordingReporter) Report(event reporting.ErrorEvent) { r.events = append(r.events, event) }
+func (r *recordingReporter) Close(timeout time.Duration)       {}
+
+// scriptedNotifications responde SendBookingConfirmation con los errores indicados, uno por llamada (el último se repite)
+type scriptedNotifications struct {
+	services.NotificationService
+	errs     []error
+	bookings []domain.BookingConfirmedEvent
+}
+
+func (s *scriptedNotifications) SendBookingConfirmation(ctx context.Context, event domain.BookingConfirmedEvent) error {
+	s.bookings = append(s.bookings, event)
+	if len(s.errs) == 0 {
+		return nil
+	}
+	err := s.errs[len(s.errs)-1]
+	if len(s.bookings) <= len(s.errs) {
+		err = s.errs[len(s.bookings)-1]
+	}
+	if errors.Is(err, errPanic) {
+		panic("template roto")
+	}
+	return err
+}
+
+// errPanic hace que SendBookingConfirmation entre en pánico
+var errPanic = errors.New("panic")
+
+const bookingBody = `{"id":"b1","propertyId":"p1","userId":"1","checkIn":"2025-03-10T14:00:00Z","checkOut":"2025-03-13T10:00:00Z","totalPrice":450}`
+
+func TestProcessMessage_BookingConfirmed(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	tests := []struct {
+		name       string
+		body       string
+		errs       []error
+		wantAcked  bool
+		wantCalls  int
+		wantReport bool
+	}{
+		{name: "emails sent", body: bookingBody, wantAcked: true, wantCalls: 1},
+		{name: "transient failure is retried", body: bookingBody, errs: []error{errors.New("smtp caído"), nil}, wantAcked: true, wantCalls: 2},
+		{name: "gives up after the retries", body: bookingBody, errs: []error{errors.New("smtp caído")}, wantCalls: 3, wantReport: true},
+		{name: "permanent failure is acked", body: bookingBody, errs: []error{utils.Permanent(errors.New("la propiedad no existe"))}, wantAcked: true, wantCalls: 1, wantReport: true},
+		{name: "panic is recovered", body: bookingBody, errs: []error{errPanic}, wantCalls: 1, wantReport: true},
+		{name: "malformed body", body: `{"id":`},
+		{name: "missing user", body: `{"id":"b1","propertyId":"p1"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &scriptedNotifications{errs: tt.errs}
+			reporter := &recordingReporter{}
+			consumer := &EventConsumer{
+				service:  service,
+				reporter: reporter,
+				settings: config.ConsumerConfig{RetryAttempts: 3, RetryBaseDelay: time.Millisecond},
+			}
+			ack := &recordingAcknowledger{}
+
+			consumer.processMessage(amqp.Delivery{Acknowledger: ack, RoutingKey: domain.BookingConfirmedRoutingKey, Body: []byte(tt.body)})
+
+			if tt.wantAcked && (ack.acked != 1 || ack.nacked != 0) {
+				t.Fatalf("expected the message acked, got %+v", ack)
+			}
+			if !tt.wantAcked && (ack.nacked != 1 || ack.acked != 0) {
+				t.Fatalf("expected the message rejected, got %+v", ack)
+			}
+			if len(service.bookings) != tt.wantCalls {
+				t.Fatalf("expected %d calls to the service, got %d", tt.wantCalls, len(service.bookings))
+			}
+			if (len(reporter.events) > 0) != tt.wantReport {
+				t.Fatalf("expected report=%v, got %+v", tt.wantReport, reporter.events)
+			}
+			if tt.wantReport && reporter.events[0].Tags["event_id"] != "b1" {
+				t.Fatalf("expected the booking id in the report tags, got %v", reporter.events[0].Tags)
+			}
+		})
+	}
+}
+
+func TestProcessMessage_DecodesTheBooking(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	service := &scriptedNotifications{}
+	consumer := &EventConsumer{service: service, reporter: &recordingReporter{}, settings: config.ConsumerConfig{RetryAttempts: 1}}
+
+	consumer.processMessage(amqp.Delivery{Acknowledger: &recordingAcknowledger{}, RoutingKey: domain.BookingConfirmedRoutingKey, Body: []byte(bookingBody)})
+
+	if len(service.bookings) != 1 {
+		t.Fatalf("expected one booking, got %d", len(service.bookings))
+	}
+	booking := service.bookings[0]
+	if booking.ID != "b1" || booking.PropertyID != "p1" || booking.UserID != "1" || booking.TotalPrice != 450 {
+		t.Fatalf("unexpected booking %+v", booking)
+	}
+	if !booking.CheckIn.Equal(time.Date(2025, 3, 10, 14, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected check-in 2025-03-10 14:00 UTC, got %v", booking.CheckIn)
+	}
+}
+
+func TestProcessMessage_AcksUnknownRoutingKeys(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	service := &scriptedNotifications{}
+	consumer := &EventConsumer{service: service, reporter: &recordingReporter{}}
+	ack := &recordingAcknowledger{}
+
+	consumer.processMessage(amqp.Delivery{Acknowledger: ack, RoutingKey: "booking.cancelled", Body: []byte(bookingBody)})
+
+	if ack.acked != 1 || len(service.bookings) != 0 {
+		t.Fatalf("expected the event acked without sending emails, got %+v and %d calls", ack, len(service.bookings))
+	}
+}
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"notifications/dto"
+	"notifications/services"
+)
+
+// TemplateController administra los templates de email y el outbox de dry-run
+type TemplateController struct {
+	templates services.TemplateService
+	outbox    *services.DryRunMailer
+}
+
+// NewTemplateController crea el controlador de templates
+// outbox es nil cuando los emails se envían por SMTP
+func NewTemplateController(templates services.TemplateService, outbox *services.DryRunMailer) *TemplateController {
+	return &TemplateController{
+		templates: templates,
+		outbox:    outbox,
+	}
+}
+
+// List maneja GET /templates
+func (c *TemplateController) List(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	writeJSONResponse(w, http.StatusOK, c.templates.List())
+}
+
+// Preview maneja GET /templates/preview?name=booking_confirmed_guest
+// Renderiza el template con datos de ejemplo
+func (c *TemplateController) Preview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "El parámetro name es requerido")
+		return
+	}
+
+	email, err := c.templates.Preview(name)
+	if errors.Is(err, services.ErrTemplateNotFound) {
+		writeErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSONResponse(w, http.StatusOK, email)
+}
+
+// Reload maneja POST /templates/reload
+// Vuelve a leer los templates; si alguno tiene errores se siguen usando los anteriores
+func (c *TemplateController) Reload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if err := c.templates.Reload(); err != nil {
+		log.Printf("⚠️ Error recargando templates: %v", err)
+		writeErrorResponse(w, http.StatusUnprocessableEntity, fmt.Sprintf("Templates no recargados: %v", err))
+		return
+	}
+	writeJSONResponse(w, http.StatusOK, c.templates.List())
+}
+
+// Outbox maneja GET /emails/outbox
+// Lista los últimos emails renderizados en modo dry-run
+func (c *TemplateController) Outbox(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	if c.outbox == nil {
+		writeErrorResponse(w, http.StatusNotFound, "El outbox solo existe en modo dry-run (EMAIL_DRY_RUN=true)")
+		return
+	}
+	writeJSONResponse(w, http.StatusOK, c.outbox.Outbox())
+}
+
+// writeJSONResponse escribe una respuesta JSON exitosa
+func writeJSONResponse(w http.ResponseWriter, statusCode int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		log.Printf("⚠️ Error escribiendo respuesta JSON: %v", err)
+	}
+}
+
+// writeErrorResponse escribe una respuesta de error en formato JSON
+func writeErrorResponse(w http.ResponseWriter, statusCode int, message string) {
+	writeJSONResponse(w, statusCode, dto.ErrorResponse{Error: message, Code: statusCode})
+}
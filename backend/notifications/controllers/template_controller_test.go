@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"notifications/dto"
+	"notifications/services"
+	"notifications/templates"
+)
+
+// copyTemplates copia los templates incluidos en el binario a un directorio temporal (como EMAIL_TEMPLATES_DIR)
+func copyTemplates(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files, err := fs.Glob(templates.Embedded, "*.*")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, file := range files {
+		content, err := fs.ReadFile(templates.Embedded, file)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, file), content, 0o644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	return dir
+}
+
+func newTestTemplateController(t *testing.T, dir string, outbox *services.DryRunMailer) *TemplateController {
+	t.Helper()
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	templateService, err := services.NewTemplateService(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return NewTemplateController(templateService, outbox)
+}
+
+func decodeJSON(t *testing.T, recorder *httptest.ResponseRecorder, target interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(recorder.Body.Bytes(), target); err != nil {
+		t.Fatalf("invalid JSON body: %v (%s)", err, recorder.Body.String())
+	}
+}
+
+func TestTemplates_ListAndPreview(t *testing.T) {
+	controller := newTestTemplateController(t, "", nil)
+
+	recorder := httptest.NewRecorder()
+	controller.List(recorder, httptest.NewRequest(http.MethodGet, "/templates", nil))
+	var list dto.TemplatesResponse
+	decodeJSON(t, recorder, &list)
+	if recorder.Code != http.StatusOK || list.Source != "embedded" || len(list.Templates) < 2 {
+		t.Fatalf("expected the embedded templates, got %d: %+v", recorder.Code, list)
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantTo     string
+	}{
+		{name: "guest confirmation", query: "name=booking_confirmed_guest", wantStatus: http.StatusOK, wantTo: "juan.perez@example.com"},
+		{name: "host confirmation", query: "name=booking_confirmed_host", wantStatus: http.StatusOK, wantTo: "maria.gomez@example.com"},
+		{name: "unknown template", query: "name=booking_cancelled", wantStatus: http.StatusNotFound},
+		{name: "missing name", query: "", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			controller.Preview(recorder, httptest.NewRequest(http.MethodGet, "/templates/preview?"+tt.query, nil))
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, recorder.Code, recorder.Body.String())
+			}
+			if tt.wantTo == "" {
+				return
+			}
+			var email dto.Email
+			decodeJSON(t, recorder, &email)
+			if email.To != tt.wantTo || email.Subject == "" || email.Text == "" || email.HTML == "" {
+				t.Fatalf("expected a rendered email to %s, got %+v", tt.wantTo, email)
+			}
+		})
+	}
+}
+
+func TestTemplates_ReloadKeepsTheWorkingTemplates(t *testing.T) {
+	dir := copyTemplates(t)
+	controller := newTestTemplateController(t, dir, nil)
+
+	// Un campo mal escrito se detecta al recargar, no al llegar una reserva
+	broken := []byte(`{{define "subject"}}Reserva en {{.Property.Titel}}{{end}}Hola`)
+	if err := os.WriteFile(filepath.Join(dir, "booking_confirmed_guest.txt"), broken, 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	recorder := httptest.NewRecorder()
+	controller.Reload(recorder, httptest.NewRequest(http.MethodPost, "/templates/reload", nil))
+	if recorder.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	recorder = httptest.NewRecorder()
+	controller.Preview(recorder, httptest.NewRequest(http.MethodGet, "/templates/preview?name=booking_confirmed_guest", nil))
+	var email dto.Email
+	decodeJSON(t, recorder, &email)
+	if recorder.Code != http.StatusOK || email.Subject != "Tu reserva en Cabaña frente al lago está confirmada" {
+		t.Fatalf("expected the previous template, got %d: %+v", recorder.Code, email)
+	}
+
+	fixed := []byte(`{{define "subject"}}Reserva en {{.Property.Title}}{{end}}Hola {{.Guest.FirstName}}`)
+	if err := os.WriteFile(filepath.Join(dir, "booking_confirmed_guest.txt"), fixed, 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	recorder = httptest.NewRecorder()
+	controller.Reload(recorder, httptest.NewRequest(http.MethodPost, "/templates/reload", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	recorder = httptest.NewRecorder()
+	controller.Preview(recorder, httptest.NewRequest(http.MethodGet, "/templates/preview?name=booking_confirmed_guest", nil))
+	decodeJSON(t, recorder, &email)
+	if email.Subject != "Reserva en Cabaña frente al lago" {
+		t.Fatalf("expected the reloaded template, got %q", email.Subject)
+	}
+}
+
+func TestTemplates_ReloadOnlyAcceptsPost(t *testing.T) {
+	controller := newTestTemplateController(t, "", nil)
+
+	recorder := httptest.NewRecorder()
+	controller.Reload(recorder, httptest.NewRequest(http.MethodGet, "/templates/reload", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", recorder.Code)
+	}
+}
+
+func TestTemplates_OutboxOnlyInDryRun(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	newTestTemplateController(t, "", nil).Outbox(recorder, httptest.NewRequest(http.MethodGet, "/emails/outbox", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 with the SMTP mailer, got %d", recorder.Code)
+	}
+
+	mailer := services.NewDryRunMailer(10)
+	mailer.Send(context.Background(), dto.Email{To: "ana@example.com", Subject: "primero", Template: services.GuestConfirmationTemplate})
+	mailer.Send(context.Background(), dto.Email{To: "luis@example.com", Subject: "segundo", Template: services.HostConfirmationTemplate})
+
+	recorder = httptest.NewRecorder()
+	newTestTemplateController(t, "", mailer).Outbox(recorder, httptest.NewRequest(http.MethodGet, "/emails/outbox", nil))
+	var outbox []dto.OutboxEntry
+	decodeJSON(t, recorder, &outbox)
+	if recorder.Code != http.StatusOK || len(outbox) != 2 || outbox[0].Subject != "segundo" || outbox[1].Subject != "primero" {
+		t.Fatalf("expected the two emails newest first, got %d: %+v", recorder.Code, outbox)
+	}
+}
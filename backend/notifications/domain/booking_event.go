@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// BookingConfirmedRoutingKey es la routing key del evento que dispara los emails de confirmación
+const BookingConfirmedRoutingKey = "booking.confirmed"
+
+// BookingConfirmedEvent es el evento publicado cuando una reserva pasa a "confirmed"
+// Tiene el mismo formato que domain.Booking de properties-api: los datos de la propiedad,
+// del huésped y del anfitrión se consultan por gRPC al procesarlo
+type BookingConfirmedEvent struct {
+	// ID es el identificador de la reserva
+	ID string `json:"id"`
+
+	// PropertyID es la propiedad reservada
+	PropertyID string `json:"propertyId"`
+
+	// UserID es el ID del huésped en users-api
+	UserID string `json:"userId"`
+
+	// CheckIn y CheckOut son los instantes de la estadía (UTC)
+	CheckIn  time.Time `json:"checkIn"`
+	CheckOut time.Time `json:"checkOut"`
+
+	// TotalPrice es el precio total de la reserva
+	TotalPrice float64 `json:"totalPrice"`
+}
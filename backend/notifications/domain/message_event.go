@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// MessageSentRoutingKey es la routing key del evento que dispara el aviso de mensaje nuevo
+const MessageSentRoutingKey = "message.sent"
+
+// MessageSentEvent es el evento publicado por properties-api cuando se envía un mensaje
+// entre huésped y anfitrión (mismo formato que clients.MessageSentEvent)
+type MessageSentEvent struct {
+	ConversationID string `json:"conversationId"`
+	MessageID      string `json:"messageId"`
+	PropertyID     string `json:"propertyId"`
+
+	// SenderID y RecipientID son IDs de users-api
+	SenderID    string `json:"senderId"`
+	RecipientID string `json:"recipientId"`
+
+	// Preview es un extracto del mensaje
+	Preview string `json:"preview"`
+
+	// RecipientUnread son los mensajes sin leer del destinatario en la conversación
+	RecipientUnread int64 `json:"recipientUnread"`
+
+	// SentAt es el instante del mensaje (UTC)
+	SentAt time.Time `json:"sentAt"`
+}
@@ -0,0 +1,24 @@
+package dto
+
+// BookingEmailData son los datos con los que se renderizan los emails de confirmación de reserva
+type BookingEmailData struct {
+	// BookingID es el código de la reserva
+	BookingID string
+
+	// Property es la propiedad reservada
+	Property Property
+
+	// Guest es el huésped que reservó y Host el dueño de la propiedad
+	Guest User
+	Host  User
+
+	// CheckIn y CheckOut son los días de la estadía en la zona horaria de la propiedad (DD/MM/YYYY)
+	CheckIn  string
+	CheckOut string
+
+	// Nights es la cantidad de noches
+	Nights int
+
+	// TotalPrice es el precio total de la reserva
+	TotalPrice float64
+}
@@ -0,0 +1,51 @@
+package dto
+
+import "time"
+
+// Email es un email renderizado listo para enviar
+type Email struct {
+	// To es el destinatario
+	To string `json:"to"`
+
+	// Subject es el asunto
+	Subject string `json:"subject"`
+
+	// Text es el cuerpo en texto plano
+	Text string `json:"text"`
+
+	// HTML es el cuerpo en HTML (vacío si el template no tiene versión HTML)
+	HTML string `json:"html,omitempty"`
+
+	// Template es el nombre del template con el que se renderizó
+	Template string `json:"template"`
+}
+
+// OutboxEntry es un email registrado en modo dry-run
+type OutboxEntry struct {
+	Email
+	RenderedAt time.Time `json:"renderedAt"`
+}
+
+// TemplateInfo describe un template de email disponible
+type TemplateInfo struct {
+	// Name es el nombre del template (ej: "booking_confirmed_guest")
+	Name string `json:"name"`
+
+	// HasHTML indica si el template tiene versión HTML además de la de texto
+	HasHTML bool `json:"hasHtml"`
+}
+
+// TemplatesResponse es la respuesta de GET /templates
+type TemplatesResponse struct {
+	// Source es el origen de los templates: "embedded" o el directorio configurado
+	Source string `json:"source"`
+
+	// Templates son los templates cargados
+	Templates []TemplateInfo `json:"templates"`
+}
+
+// ErrorResponse representa una respuesta de error
+type ErrorResponse struct {
+	Error string `json:"error"`
+	Code  int    `json:"code"`
+}
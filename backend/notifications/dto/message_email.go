@@ -0,0 +1,23 @@
+package dto
+
+// MessageEmailData son los datos con los que se renderiza el aviso de mensaje nuevo
+type MessageEmailData struct {
+	// ConversationID es la conversación del mensaje
+	ConversationID string
+
+	// Property es la propiedad sobre la que se conversa
+	Property Property
+
+	// Sender es quien escribió el mensaje y Recipient quien recibe el aviso
+	Sender    User
+	Recipient User
+
+	// Preview es el extracto del mensaje publicado por properties-api
+	Preview string
+
+	// Unread es la cantidad de mensajes sin leer del destinatario en la conversación
+	Unread int64
+
+	// SentAt es la fecha del mensaje en la zona horaria de la propiedad (DD/MM/YYYY HH:MM)
+	SentAt string
+}
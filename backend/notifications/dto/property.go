@@ -0,0 +1,13 @@
+package dto
+
+// Property son los datos de una propiedad que usan los emails
+// Mismo formato que GET /properties/:id de properties-api (se ignoran los campos que no se usan)
+type Property struct {
+	ID         string  `json:"id"`
+	Title      string  `json:"title"`
+	Price      float64 `json:"price"`
+	Location   string  `json:"location"`
+	Timezone   string  `json:"timezone,omitempty"`
+	OwnerID    string  `json:"ownerId"`
+	CoverImage string  `json:"coverImage,omitempty"` // URL de la foto de portada
+}
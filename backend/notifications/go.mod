@@ -0,0 +1,24 @@
+module notifications
+
+go 1.21
+
+require (
+	github.com/streadway/amqp v1.0.0
+	google.golang.org/grpc v1.64.1
+	reporting v0.0.0
+	signing v0.0.0
+)
+
+require (
+	golang.org/x/net v0.26.0 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+	golang.org/x/text v0.16.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240318140521-94a12d6c2237 // indirect
+	google.golang.org/protobuf v1.33.0 // indirect
+)
+
+// reporting envía los panics y errores a un servicio compatible con Sentry, compartido por todos los servicios
+replace reporting => ../reporting
+
+// signing firma y valida las llamadas internas (HMAC), compartido por los servicios que se llaman entre sí
+replace signing => ../signing
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"notifications/clients"
+	"notifications/config"
+	"notifications/consumers"
+	"notifications/controllers"
+	"notifications/middleware"
+	"notifications/services"
+	"reporting"
+)
+
+func main() {
+	log.Println("🚀 Iniciando Notifications...")
+
+	// ============================================
+	// SECCIÓN 1: CARGAR CONFIGURACIÓN
+	// ============================================
+	cfg := config.LoadConfig()
+	log.Printf("✅ Configuración cargada:")
+	log.Printf("   - RabbitMQ URL: %s", cfg.RabbitMQURL)
+	log.Printf("   - Exchange: %s, queue: %s", cfg.RabbitMQExchange, cfg.RabbitMQQueue)
+	log.Printf("   - Users API gRPC: %s", cfg.UsersAPIGRPCAddr)
+	log.Printf("   - Properties API gRPC: %s", cfg.PropertiesAPIGRPCAddr)
+	log.Printf("   - Dry-run: %v", cfg.Email.DryRun)
+	log.Printf("   - Port: %s", cfg.Port)
+
+	// ============================================
+	// SECCIÓN 2: INICIALIZAR CLIENTES
+	// ============================================
+	log.Println("📦 Inicializando clientes...")
+	callAuth := clients.InternalCallAuth{Caller: "notifications", APIKey: cfg.InternalAPIKey, SigningSecret: cfg.InternalSigningSecret}
+	usersConn, err := clients.NewGRPCConn(cfg.UsersAPIGRPCAddr, callAuth)
+	if err != nil {
+		log.Fatalf("❌ Error creando conexión gRPC a users-api: %v", err)
+	}
+	defer usersConn.Close()
+
+	propertiesConn, err := clients.NewGRPCConn(cfg.PropertiesAPIGRPCAddr, callAuth)
+	if err != nil {
+		log.Fatalf("❌ Error creando conexión gRPC a properties-api: %v", err)
+	}
+	defer propertiesConn.Close()
+
+	usersClient := clients.NewUsersClient(usersConn, cfg.CallTimeout)
+
+	// Reporte de panics, errores 5xx y eventos descartados (sin ERROR_REPORTING_DSN solo quedan en los logs)
+	errorReporter := reporting.NewErrorReporter(cfg.ErrorReporting, "notifications", &http.Client{Timeout: 10 * time.Second})
+	defer errorReporter.Close(5 * time.Second)
+	propertiesClient := clients.NewPropertiesClient(propertiesConn, cfg.CallTimeout)
+
+	// ============================================
+	// SECCIÓN 3: INICIALIZAR SERVICIOS
+	// ============================================
+	log.Println("🔧 Inicializando servicios...")
+	templateService, err := services.NewTemplateService(cfg.Email.TemplatesDir)
+	if err != nil {
+		log.Fatalf("❌ Error cargando templates de email: %v", err)
+	}
+
+	// En dry-run los emails se renderizan pero no salen: quedan en el outbox (GET /emails/outbox)
+	var mailer services.Mailer
+	var outbox *services.DryRunMailer
+	if cfg.Email.DryRun {
+		outbox = services.NewDryRunMailer(cfg.Email.OutboxSize)
+		mailer = outbox
+	} else {
+		mailer = services.NewSMTPMailer(cfg.Email)
+	}
+	log.Printf("✅ Mailer inicializado (%s)", mailer.Name())
+
+	notificationService := services.NewNotificationService(propertiesClient, usersClient, templateService, mailer, cfg.Email.DedupWindow, cfg.Email.MessageThrottle)
+	log.Println("✅ Servicio de notificaciones inicializado")
+
+	// ============================================
+	// SECCIÓN 4: INICIALIZAR Y ARRANCAR CONSUMIDOR DE RABBITMQ
+	// ============================================
+	log.Println("🐰 Inicializando consumidor de RabbitMQ...")
+	consumer, err := consumers.NewEventConsumer(cfg.RabbitMQURL, cfg.RabbitMQExchange, cfg.RabbitMQQueue, notificationService, errorReporter, cfg.Consumer)
+	if err != nil {
+		log.Fatalf("❌ Error creando consumidor de RabbitMQ: %v", err)
+	}
+	defer func() {
+		if err := consumer.Close(); err != nil {
+			log.Printf("⚠️ Error cerrando consumidor de RabbitMQ: %v", err)
+		}
+	}()
+
+	if err := consumer.Start(); err != nil {
+		log.Fatalf("❌ Error iniciando consumidor de RabbitMQ: %v", err)
+	}
+
+	// ============================================
+	// SECCIÓN 5: CONFIGURAR RUTAS
+	// ============================================
+	templateController := controllers.NewTemplateController(templateService, outbox)
+	internalAuth := middleware.NewInternalAuth(cfg.InternalTokens)
+
+	mux := http.NewServeMux()
+	mux.Handle("/templates", internalAuth.Middleware(http.HandlerFunc(templateController.List)))
+	mux.Handle("/templates/preview", internalAuth.Middleware(http.HandlerFunc(templateController.Preview)))
+	mux.Handle("/templates/reload", internalAuth.Middleware(http.HandlerFunc(templateController.Reload)))
+	mux.Handle("/emails/outbox", internalAuth.Middleware(http.HandlerFunc(templateController.Outbox)))
+	mux.HandleFunc("/health/live", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"status": "ok", "service": "notifications"})
+	})
+	mux.HandleFunc("/health/ready", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := consumer.Ping(); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(map[string]string{"status": "unavailable", "error": err.Error()})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	})
+
+	log.Println("✅ Rutas configuradas:")
+	log.Println("   - GET  /templates (interno)")
+	log.Println("   - GET  /templates/preview?name=... (interno)")
+	log.Println("   - POST /templates/reload (interno)")
+	log.Println("   - GET  /emails/outbox (interno, solo dry-run)")
+	log.Println("   - GET  /health/live")
+	log.Println("   - GET  /health/ready")
+
+	server := &http.Server{
+		Addr:         ":" + cfg.Port,
+		Handler:      middleware.RequestID(middleware.Recovery(errorReporter, mux)),
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+
+	// ============================================
+	// SECCIÓN 6: MANEJAR GRACEFUL SHUTDOWN
+	// ============================================
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		log.Println("🚀 =======================================")
+		log.Printf("🚀 Notifications corriendo en puerto %s", cfg.Port)
+		log.Println("🚀 =======================================")
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("❌ Error iniciando servidor: %v", err)
+		}
+	}()
+
+	sig := <-sigChan
+	log.Printf("📨 Señal recibida: %v. Iniciando shutdown graceful...", sig)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("⚠️ Error durante shutdown del servidor: %v", err)
+	} else {
+		log.Println("✅ Servidor cerrado exitosamente")
+	}
+
+	log.Println("👋 Notifications finalizada")
+}
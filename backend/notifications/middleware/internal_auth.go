@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"notifications/dto"
+)
+
+// InternalAuth exige el header X-Internal-Token en las rutas de administración
+// Sin tokens configurados las rutas quedan cerradas
+type InternalAuth struct {
+	tokens map[string]bool
+}
+
+// NewInternalAuth crea el middleware con los tokens aceptados
+func NewInternalAuth(tokens []string) *InternalAuth {
+	accepted := make(map[string]bool, len(tokens))
+	for _, token := range tokens {
+		accepted[token] = true
+	}
+	return &InternalAuth{tokens: accepted}
+}
+
+// Middleware responde 401 si el request no trae un token interno válido
+func (a *InternalAuth) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := r.Header.Get("X-Internal-Token"); token == "" || !a.tokens[token] {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(dto.ErrorResponse{Error: "Se requiere un token interno", Code: http.StatusUnauthorized})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
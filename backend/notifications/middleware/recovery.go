@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+
+	"reporting"
+)
+
+// Recovery recupera los panics de los handlers para que un request no tire el proceso:
+// loguea el stack, responde 500 (si todavía no se escribió la respuesta) y reporta el panic.
+// También reporta las respuestas 5xx. Los eventos van taggeados con el request ID, así que tiene que ir
+// dentro de RequestID. Los panics del consumidor de RabbitMQ se recuperan en el consumidor
+func Recovery(reporter reporting.ErrorReporter, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := &statusRecorder{ResponseWriter: w}
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				if recorder.status >= http.StatusInternalServerError {
+					reporter.Report(reporting.ErrorEvent{
+						Message: fmt.Sprintf("%s %s respondió %d", r.Method, r.URL.Path, recorder.status),
+						Level:   reporting.ErrorLevelError,
+						Tags:    errorTags(r, recorder.status),
+					})
+				}
+				return
+			}
+			// ErrAbortHandler es la forma de cortar un request a propósito (ej: un proxy); net/http no lo loguea
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			stack := string(debug.Stack())
+			log.Printf("💥 Panic en %s %s (request %s): %v\n%s", r.Method, r.URL.Path, RequestIDFrom(r.Context()), recovered, stack)
+			reporter.Report(reporting.ErrorEvent{
+				Message:    fmt.Sprintf("panic: %v", recovered),
+				Level:      reporting.ErrorLevelFatal,
+				Stacktrace: stack,
+				Tags:       errorTags(r, http.StatusInternalServerError),
+			})
+
+			if !recorder.wroteHeader {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprintf(w, `{"error":"Error interno del servidor","code":%d}`, http.StatusInternalServerError)
+			}
+		}()
+		next.ServeHTTP(recorder, r)
+	})
+}
+
+// errorTags son los tags de un evento del request
+func errorTags(r *http.Request, status int) map[string]string {
+	return map[string]string{
+		"request_id": RequestIDFrom(r.Context()),
+		"method":     r.Method,
+		"path":       r.URL.Path,
+		"status":     strconv.Itoa(status),
+	}
+}
+
+// statusRecorder guarda el status de la respuesta
+// Implementa Unwrap para que http.NewResponseController llegue al writer original
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(body []byte) (int, error) {
+	if !r.wroteHeader {
+		r.status = http.StatusOK
+		r.wroteHeader = true
+	}
+	return r.ResponseWriter.Write(body)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
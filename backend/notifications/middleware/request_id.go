@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// RequestIDHeader es el header con el que llega (del gateway o de otro servicio) y se devuelve el ID del request
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength evita que un header arbitrario termine en los logs y en los reportes de errores
+const maxRequestIDLength = 128
+
+// requestIDKey es la clave de contexto del ID del request
+type requestIDKey struct{}
+
+// RequestID toma el X-Request-ID del request (o genera uno), lo devuelve en la respuesta
+// y lo guarda en el contexto para taggear los logs y los errores reportados
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" || len(id) > maxRequestIDLength {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+// RequestIDFrom retorna el ID del request (vacío fuera de un request HTTP)
+func RequestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// newRequestID genera un ID aleatorio de 32 caracteres hexadecimales
+func newRequestID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,44 @@
+package rpc
+
+import (
+	"context"
+
+	"notifications/dto"
+
+	"google.golang.org/grpc"
+)
+
+// PropertiesServiceName es el nombre del servicio gRPC que expone properties-api
+const PropertiesServiceName = "properties.v1.Properties"
+
+// GetPropertyRequest pide una propiedad por ID
+type GetPropertyRequest struct {
+	ID string `json:"id"`
+}
+
+// GetPropertyResponse contiene la propiedad con el mismo formato que GET /properties/:id
+type GetPropertyResponse struct {
+	Property dto.Property `json:"property"`
+}
+
+// PropertiesServiceClient es el stub del servicio gRPC de propiedades
+type PropertiesServiceClient interface {
+	GetProperty(ctx context.Context, request *GetPropertyRequest, opts ...grpc.CallOption) (*GetPropertyResponse, error)
+}
+
+type propertiesServiceClient struct {
+	conn grpc.ClientConnInterface
+}
+
+// NewPropertiesServiceClient crea el stub sobre una conexión gRPC (ver clients.NewGRPCConn)
+func NewPropertiesServiceClient(conn grpc.ClientConnInterface) PropertiesServiceClient {
+	return &propertiesServiceClient{conn: conn}
+}
+
+func (c *propertiesServiceClient) GetProperty(ctx context.Context, request *GetPropertyRequest, opts ...grpc.CallOption) (*GetPropertyResponse, error) {
+	response := new(GetPropertyResponse)
+	if err := c.conn.Invoke(ctx, "/"+PropertiesServiceName+"/GetProperty", request, response, opts...); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"sync"
+	"time"
+
+	"notifications/config"
+	"notifications/dto"
+	"notifications/utils"
+)
+
+// Mailer envía emails ya renderizados
+type Mailer interface {
+	// Send envía el email; los rechazos definitivos del servidor se marcan con utils.Permanent
+	Send(ctx context.Context, email dto.Email) error
+
+	// Name identifica el mailer en los logs ("smtp" o "dry-run")
+	Name() string
+}
+
+// smtpMailer envía los emails por SMTP
+type smtpMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewSMTPMailer crea el mailer SMTP; sin usuario se envía sin autenticación (ej: relay interno)
+func NewSMTPMailer(cfg config.EmailConfig) Mailer {
+	mailer := &smtpMailer{
+		addr: net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(cfg.SMTPPort)),
+		from: cfg.From,
+	}
+	if cfg.SMTPUsername != "" {
+		mailer.auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+	return mailer
+}
+
+// Send envía el email por SMTP
+// net/smtp no recibe contexto: el timeout de la conexión lo pone el servidor SMTP
+func (m *smtpMailer) Send(ctx context.Context, email dto.Email) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	from, err := mail.ParseAddress(m.from)
+	if err != nil {
+		return utils.Permanent(fmt.Errorf("remitente inválido '%s': %w", m.from, err))
+	}
+	to, err := mail.ParseAddress(email.To)
+	if err != nil {
+		return utils.Permanent(fmt.Errorf("destinatario inválido '%s': %w", email.To, err))
+	}
+
+	message, err := buildMessage(from, to, email)
+	if err != nil {
+		return utils.Permanent(err)
+	}
+
+	if err := smtp.SendMail(m.addr, m.auth, from.Address, []string{to.Address}, message); err != nil {
+		// Los códigos 5xx son rechazos definitivos (ej: buzón inexistente); el resto se reintenta
+		var smtpErr *textproto.Error
+		if errors.As(err, &smtpErr) && smtpErr.Code >= 500 {
+			return utils.Permanent(fmt.Errorf("el servidor SMTP rechazó el email a %s: %w", to.Address, err))
+		}
+		return fmt.Errorf("error enviando email a %s: %w", to.Address, err)
+	}
+	return nil
+}
+
+// Name identifica el mailer en los logs
+func (m *smtpMailer) Name() string {
+	return "smtp"
+}
+
+// buildMessage arma el mensaje MIME: texto plano, o multipart/alternative si el email tiene HTML
+func buildMessage(from, to *mail.Address, email dto.Email) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", to.String())
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", email.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	if email.HTML == "" {
+		buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+		buf.WriteString(email.Text)
+		return buf.Bytes(), nil
+	}
+
+	writer := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", writer.Boundary())
+	parts := []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=utf-8", email.Text},
+		{"text/html; charset=utf-8", email.HTML},
+	}
+	for _, part := range parts {
+		partWriter, err := writer.CreatePart(textproto.MIMEHeader{"Content-Type": {part.contentType}})
+		if err != nil {
+			return nil, fmt.Errorf("error armando el mensaje MIME: %w", err)
+		}
+		if _, err := partWriter.Write([]byte(part.body)); err != nil {
+			return nil, fmt.Errorf("error armando el mensaje MIME: %w", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("error armando el mensaje MIME: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// DryRunMailer no envía los emails: los loguea y guarda los últimos en un outbox en memoria
+// Sirve para desarrollo y para probar templates sin mandar emails reales
+type DryRunMailer struct {
+	size int
+
+	mu     sync.Mutex
+	outbox []dto.OutboxEntry
+}
+
+// NewDryRunMailer crea el mailer de dry-run que conserva los últimos size emails
+func NewDryRunMailer(size int) *DryRunMailer {
+	if size < 1 {
+		size = 1
+	}
+	return &DryRunMailer{size: size}
+}
+
+// Send registra el email sin enviarlo
+func (m *DryRunMailer) Send(ctx context.Context, email dto.Email) error {
+	log.Printf("✉️ [dry-run] Email '%s' para %s: %s", email.Template, email.To, email.Subject)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.outbox = append(m.outbox, dto.OutboxEntry{Email: email, RenderedAt: time.Now().UTC()})
+	if len(m.outbox) > m.size {
+		m.outbox = m.outbox[len(m.outbox)-m.size:]
+	}
+	return nil
+}
+
+// Name identifica el mailer en los logs
+func (m *DryRunMailer) Name() string {
+	return "dry-run"
+}
+
+// Outbox retorna los emails registrados, del más reciente al más antiguo
+func (m *DryRunMailer) Outbox() []dto.OutboxEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entries := make([]dto.OutboxEntry, len(m.outbox))
+	for i, entry := range m.outbox {
+		entries[len(m.outbox)-1-i] = entry
+	}
+	return entries
+}
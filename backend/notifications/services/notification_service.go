@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"notifications/clients"
+	"notifications/domain"
+	"notifications/dto"
+	"notifications/utils"
+)
+
+// dateLayout es el formato de los días de check-in y check-out en los emails
+const dateLayout = "02/01/2006"
+
+// dateTimeLayout es el formato de la fecha de los mensajes en los emails
+const dateTimeLayout = "02/01/2006 15:04"
+
+// NotificationService envía los emails de los eventos de reservas y mensajes
+// Los errores que no se resuelven reintentando se marcan con utils.Permanent
+type NotificationService interface {
+	// SendBookingConfirmation envía la confirmación al huésped y el aviso al anfitrión
+	SendBookingConfirmation(ctx context.Context, event domain.BookingConfirmedEvent) error
+
+	// NotifyMessageReceived avisa al destinatario de un mensaje nuevo
+	// Se envía como máximo un aviso por conversación cada messageThrottle mientras el destinatario no lea
+	NotifyMessageReceived(ctx context.Context, event domain.MessageSentEvent) error
+}
+
+// notificationService es la implementación concreta de NotificationService
+type notificationService struct {
+	properties  clients.PropertiesClient
+	users       clients.UsersClient
+	templates   TemplateService
+	mailer      Mailer
+	dedupWindow time.Duration
+
+	// messageThrottle es el tiempo mínimo entre avisos de la misma conversación al mismo destinatario
+	messageThrottle time.Duration
+
+	mu   sync.Mutex
+	sent map[string]time.Time // "<bookingID>:<template>" o "<conversationID>:<recipientID>" -> momento del envío
+}
+
+// NewNotificationService crea el servicio de notificaciones
+// dedupWindow es cuánto se recuerda cada email enviado para no repetirlo si el evento se reentrega
+// messageThrottle agrupa los avisos de mensajes: una conversación activa no genera un email por mensaje
+func NewNotificationService(properties clients.PropertiesClient, users clients.UsersClient, templates TemplateService, mailer Mailer, dedupWindow, messageThrottle time.Duration) NotificationService {
+	return &notificationService{
+		properties:      properties,
+		users:           users,
+		templates:       templates,
+		mailer:          mailer,
+		dedupWindow:     dedupWindow,
+		messageThrottle: messageThrottle,
+		sent:            make(map[string]time.Time),
+	}
+}
+
+// SendBookingConfirmation envía la confirmación al huésped y el aviso al anfitrión
+// Si un envío falla y el evento se reintenta, el email que ya salió no se repite
+func (s *notificationService) SendBookingConfirmation(ctx context.Context, event domain.BookingConfirmedEvent) error {
+	data, err := s.bookingEmailData(ctx, event)
+	if err != nil {
+		return err
+	}
+
+	recipients := []struct {
+		template string
+		user     dto.User
+	}{
+		{GuestConfirmationTemplate, data.Guest},
+		{HostConfirmationTemplate, data.Host},
+	}
+	for _, recipient := range recipients {
+		key := event.ID + ":" + recipient.template
+		if s.alreadySent(key, s.dedupWindow) {
+			log.Printf("ℹ️ Email '%s' de la reserva %s ya enviado, se omite", recipient.template, event.ID)
+			continue
+		}
+		if err := s.send(ctx, key, recipient.template, recipient.user, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NotifyMessageReceived avisa al destinatario de un mensaje nuevo
+// El primer mensaje sin leer (RecipientUnread == 1) siempre se avisa: el destinatario ya leyó los anteriores
+func (s *notificationService) NotifyMessageReceived(ctx context.Context, event domain.MessageSentEvent) error {
+	// Un evento reentregado no vuelve a avisar aunque sea el primer mensaje sin leer
+	if s.alreadySent(event.MessageID, s.dedupWindow) {
+		log.Printf("ℹ️ Aviso del mensaje %s ya enviado, se omite", event.MessageID)
+		return nil
+	}
+	key := event.ConversationID + ":" + event.RecipientID
+	if event.RecipientUnread > 1 && s.alreadySent(key, s.messageThrottle) {
+		log.Printf("ℹ️ Aviso de la conversación %s a %s enviado hace menos de %s, se omite el mensaje %s", event.ConversationID, event.RecipientID, s.messageThrottle, event.MessageID)
+		return nil
+	}
+
+	data, err := s.messageEmailData(ctx, event)
+	if err != nil {
+		return err
+	}
+	if err := s.send(ctx, key, MessageReceivedTemplate, data.Recipient, data); err != nil {
+		return err
+	}
+	s.markSent(event.MessageID)
+	return nil
+}
+
+// messageEmailData consulta la propiedad, el remitente y el destinatario y arma los datos del template
+func (s *notificationService) messageEmailData(ctx context.Context, event domain.MessageSentEvent) (dto.MessageEmailData, error) {
+	property, err := s.properties.GetProperty(ctx, event.PropertyID)
+	if errors.Is(err, clients.ErrPropertyNotFound) {
+		return dto.MessageEmailData{}, utils.Permanent(fmt.Errorf("la propiedad %s de la conversación %s no existe", event.PropertyID, event.ConversationID))
+	}
+	if err != nil {
+		return dto.MessageEmailData{}, err
+	}
+
+	senderID, err := parseUserID(event.SenderID)
+	if err != nil {
+		return dto.MessageEmailData{}, utils.Permanent(fmt.Errorf("remitente inválido en el mensaje %s: %w", event.MessageID, err))
+	}
+	recipientID, err := parseUserID(event.RecipientID)
+	if err != nil {
+		return dto.MessageEmailData{}, utils.Permanent(fmt.Errorf("destinatario inválido en el mensaje %s: %w", event.MessageID, err))
+	}
+
+	users, err := s.users.GetUsers(ctx, []uint{senderID, recipientID})
+	if err != nil {
+		return dto.MessageEmailData{}, err
+	}
+	sender, ok := users[senderID]
+	if !ok {
+		return dto.MessageEmailData{}, utils.Permanent(fmt.Errorf("el remitente %d del mensaje %s no existe", senderID, event.MessageID))
+	}
+	recipient, ok := users[recipientID]
+	if !ok {
+		return dto.MessageEmailData{}, utils.Permanent(fmt.Errorf("el destinatario %d del mensaje %s no existe", recipientID, event.MessageID))
+	}
+
+	return dto.MessageEmailData{
+		ConversationID: event.ConversationID,
+		Property:       *property,
+		Sender:         sender,
+		Recipient:      recipient,
+		Preview:        event.Preview,
+		Unread:         event.RecipientUnread,
+		SentAt:         event.SentAt.In(propertyLocation(*property)).Format(dateTimeLayout),
+	}, nil
+}
+
+// bookingEmailData consulta la propiedad, el huésped y el anfitrión y arma los datos de los templates
+func (s *notificationService) bookingEmailData(ctx context.Context, event domain.BookingConfirmedEvent) (dto.BookingEmailData, error) {
+	property, err := s.properties.GetProperty(ctx, event.PropertyID)
+	if errors.Is(err, clients.ErrPropertyNotFound) {
+		return dto.BookingEmailData{}, utils.Permanent(fmt.Errorf("la propiedad %s de la reserva %s no existe", event.PropertyID, event.ID))
+	}
+	if err != nil {
+		return dto.BookingEmailData{}, err
+	}
+
+	guestID, err := parseUserID(event.UserID)
+	if err != nil {
+		return dto.BookingEmailData{}, utils.Permanent(fmt.Errorf("huésped inválido en la reserva %s: %w", event.ID, err))
+	}
+	hostID, err := parseUserID(property.OwnerID)
+	if err != nil {
+		return dto.BookingEmailData{}, utils.Permanent(fmt.Errorf("dueño inválido en la propiedad %s: %w", property.ID, err))
+	}
+
+	// Huésped y anfitrión se piden en una sola llamada
+	users, err := s.users.GetUsers(ctx, []uint{guestID, hostID})
+	if err != nil {
+		return dto.BookingEmailData{}, err
+	}
+	guest, ok := users[guestID]
+	if !ok {
+		return dto.BookingEmailData{}, utils.Permanent(fmt.Errorf("el huésped %d de la reserva %s no existe", guestID, event.ID))
+	}
+	host, ok := users[hostID]
+	if !ok {
+		return dto.BookingEmailData{}, utils.Permanent(fmt.Errorf("el anfitrión %d de la propiedad %s no existe", hostID, property.ID))
+	}
+
+	// Los días se muestran en la zona horaria de la propiedad (la de la estadía, no la del lector)
+	location := propertyLocation(*property)
+	checkIn := event.CheckIn.In(location)
+	checkOut := event.CheckOut.In(location)
+
+	return dto.BookingEmailData{
+		BookingID:  event.ID,
+		Property:   *property,
+		Guest:      guest,
+		Host:       host,
+		CheckIn:    checkIn.Format(dateLayout),
+		CheckOut:   checkOut.Format(dateLayout),
+		Nights:     nights(checkIn, checkOut),
+		TotalPrice: event.TotalPrice,
+	}, nil
+}
+
+// send renderiza y envía un email, y lo registra con key para deduplicarlo
+func (s *notificationService) send(ctx context.Context, key, template string, user dto.User, data interface{}) error {
+	if user.Email == "" {
+		log.Printf("⚠️ El usuario %d no tiene email, se omite '%s' (%s)", user.ID, template, key)
+		return nil
+	}
+
+	email, err := s.templates.Render(template, user.Email, data)
+	if err != nil {
+		return utils.Permanent(err)
+	}
+	if err := s.mailer.Send(ctx, email); err != nil {
+		return err
+	}
+
+	s.markSent(key)
+	log.Printf("✅ Email '%s' (%s) enviado a %s (%s)", template, key, user.Email, s.mailer.Name())
+	return nil
+}
+
+// alreadySent indica si el email se envió hace menos de window
+func (s *notificationService) alreadySent(key string, window time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sentAt, ok := s.sent[key]
+	return ok && time.Since(sentAt) < window
+}
+
+// markSent registra el envío y descarta los registros vencidos
+func (s *notificationService) markSent(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Se conservan durante la mayor de las dos ventanas
+	retention := s.dedupWindow
+	if s.messageThrottle > retention {
+		retention = s.messageThrottle
+	}
+	now := time.Now()
+	for sentKey, sentAt := range s.sent {
+		if now.Sub(sentAt) >= retention {
+			delete(s.sent, sentKey)
+		}
+	}
+	s.sent[key] = now
+}
+
+// propertyLocation carga la zona horaria de la propiedad; si no tiene o es inválida se usa UTC
+func propertyLocation(property dto.Property) *time.Location {
+	if property.Timezone == "" {
+		return time.UTC
+	}
+	location, err := time.LoadLocation(property.Timezone)
+	if err != nil {
+		log.Printf("⚠️ Zona horaria inválida '%s' en la propiedad %s, se usa UTC", property.Timezone, property.ID)
+		return time.UTC
+	}
+	return location
+}
+
+// parseUserID convierte el ID de usuario (string en properties-api) al uint de users-api
+func parseUserID(id string) (uint, error) {
+	value, err := strconv.ParseUint(id, 10, 64)
+	if err != nil || value == 0 {
+		return 0, fmt.Errorf("ID de usuario inválido '%s'", id)
+	}
+	return uint(value), nil
+}
+
+// nights calcula las noches de la estadía contando días locales (no se ve afectado por cambios de horario)
+func nights(checkIn, checkOut time.Time) int {
+	start := time.Date(checkIn.Year(), checkIn.Month(), checkIn.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(checkOut.Year(), checkOut.Month(), checkOut.Day(), 0, 0, 0, 0, time.UTC)
+	return int(end.Sub(start).Hours() / 24)
+}
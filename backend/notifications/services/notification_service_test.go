@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"notifications/clients"
+	"notifications/domain"
+	"notifications/dto"
+	"notifications/utils"
+)
+
+// stubProperties responde GetProperty con propiedades fijas
+type stubProperties struct {
+	properties map[string]dto.Property
+}
+
+func (s stubProperties) GetProperty(ctx context.Context, id string) (*dto.Property, error) {
+	property, ok := s.properties[id]
+	if !ok {
+		return nil, clients.ErrPropertyNotFound
+	}
+	return &property, nil
+}
+
+// stubUsers responde GetUsers con usuarios fijos; los IDs inexistentes no están en el mapa
+type stubUsers struct {
+	users map[uint]dto.User
+}
+
+func (s stubUsers) GetUsers(ctx context.Context, ids []uint) (map[uint]dto.User, error) {
+	found := make(map[uint]dto.User, len(ids))
+	for _, id := range ids {
+		if user, ok := s.users[id]; ok {
+			found[id] = user
+		}
+	}
+	return found, nil
+}
+
+// failingMailer falla los envíos a failTo y registra el resto en el outbox
+type failingMailer struct {
+	*DryRunMailer
+	failTo string
+}
+
+func (m failingMailer) Send(ctx context.Context, email dto.Email) error {
+	if email.To == m.failTo {
+		return errors.New("smtp caído")
+	}
+	return m.DryRunMailer.Send(ctx, email)
+}
+
+// newTestNotificationService usa los templates incluidos en el binario y una propiedad con dueño 2 y huésped 1
+func newTestNotificationService(t *testing.T, mailer Mailer) NotificationService {
+	t.Helper()
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	templates, err := NewTemplateService("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	properties := stubProperties{properties: map[string]dto.Property{
+		"p1": {ID: "p1", Title: "Cabaña en Bariloche", Location: "Bariloche", Timezone: "America/Argentina/Buenos_Aires", OwnerID: "2"},
+	}}
+	users := stubUsers{users: map[uint]dto.User{
+		1: {ID: 1, FirstName: "Ana", LastName: "Pérez", Email: "ana@example.com"},
+		2: {ID: 2, FirstName: "Luis", LastName: "Gómez", Email: "luis@example.com"},
+	}}
+	return NewNotificationService(properties, users, templates, mailer, time.Hour, time.Hour)
+}
+
+// bookingEvent es una reserva de 3 noches: las 01:00 UTC del 10/03 siguen siendo el 09/03 en Buenos Aires
+func bookingEvent() domain.BookingConfirmedEvent {
+	return domain.BookingConfirmedEvent{
+		ID:         "b1",
+		PropertyID: "p1",
+		UserID:     "1",
+		CheckIn:    time.Date(2025, 3, 10, 1, 0, 0, 0, time.UTC),
+		CheckOut:   time.Date(2025, 3, 13, 1, 0, 0, 0, time.UTC),
+		TotalPrice: 450,
+	}
+}
+
+func TestSendBookingConfirmation_EmailsGuestAndHost(t *testing.T) {
+	mailer := NewDryRunMailer(10)
+	service := newTestNotificationService(t, mailer)
+
+	if err := service.SendBookingConfirmation(context.Background(), bookingEvent()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	outbox := mailer.Outbox()
+	if len(outbox) != 2 {
+		t.Fatalf("expected 2 emails, got %d", len(outbox))
+	}
+	// El outbox va del más reciente al más antiguo: primero se envía al huésped
+	host, guest := outbox[0], outbox[1]
+	if guest.To != "ana@example.com" || guest.Template != GuestConfirmationTemplate {
+		t.Fatalf("expected the guest confirmation to ana@example.com, got %s to %s", guest.Template, guest.To)
+	}
+	if host.To != "luis@example.com" || host.Template != HostConfirmationTemplate {
+		t.Fatalf("expected the host notice to luis@example.com, got %s to %s", host.Template, host.To)
+	}
+	if !strings.Contains(guest.Subject, "Cabaña en Bariloche") {
+		t.Fatalf("expected the property in the subject, got %q", guest.Subject)
+	}
+	for _, detail := range []string{"09/03/2025", "12/03/2025", "Noches:    3", "$450.00", "luis@example.com", "b1"} {
+		if !strings.Contains(guest.Text, detail) {
+			t.Fatalf("expected %q in the guest email, got:\n%s", detail, guest.Text)
+		}
+	}
+	if !strings.Contains(host.Text, "Ana Pérez") || !strings.Contains(host.Text, "ana@example.com") {
+		t.Fatalf("expected the guest details in the host email, got:\n%s", host.Text)
+	}
+}
+
+func TestSendBookingConfirmation_DoesNotRepeatSentEmails(t *testing.T) {
+	outbox := NewDryRunMailer(10)
+	mailer := &failingMailer{DryRunMailer: outbox, failTo: "luis@example.com"}
+	service := newTestNotificationService(t, mailer)
+
+	// Falla el aviso al anfitrión: el evento se reintenta
+	if err := service.SendBookingConfirmation(context.Background(), bookingEvent()); err == nil || utils.IsPermanent(err) {
+		t.Fatalf("expected a retryable error, got %v", err)
+	}
+	mailer.failTo = ""
+	if err := service.SendBookingConfirmation(context.Background(), bookingEvent()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Una reentrega del mismo evento no envía nada más
+	if err := service.SendBookingConfirmation(context.Background(), bookingEvent()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sent := map[string]int{}
+	for _, entry := range outbox.Outbox() {
+		sent[entry.To]++
+	}
+	if sent["ana@example.com"] != 1 || sent["luis@example.com"] != 1 {
+		t.Fatalf("expected one email per recipient, got %v", sent)
+	}
+}
+
+func TestSendBookingConfirmation_PermanentErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		event func(event *domain.BookingConfirmedEvent)
+	}{
+		{name: "missing property", event: func(event *domain.BookingConfirmedEvent) { event.PropertyID = "p9" }},
+		{name: "invalid guest id", event: func(event *domain.BookingConfirmedEvent) { event.UserID = "ana" }},
+		{name: "missing guest", event: func(event *domain.BookingConfirmedEvent) { event.UserID = "9" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mailer := NewDryRunMailer(10)
+			service := newTestNotificationService(t, mailer)
+			event := bookingEvent()
+			tt.event(&event)
+
+			err := service.SendBookingConfirmation(context.Background(), event)
+			if !utils.IsPermanent(err) {
+				t.Fatalf("expected a permanent error, got %v", err)
+			}
+			if len(mailer.Outbox()) != 0 {
+				t.Fatalf("expected no emails, got %d", len(mailer.Outbox()))
+			}
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+
+	"notifications/dto"
+	"notifications/templates"
+)
+
+// Nombres de los templates que usa el servicio; Reload rechaza un conjunto que no los tenga
+const (
+	// GuestConfirmationTemplate es el email de confirmación para el huésped
+	GuestConfirmationTemplate = "booking_confirmed_guest"
+
+	// HostConfirmationTemplate es el aviso de reserva confirmada para el anfitrión
+	HostConfirmationTemplate = "booking_confirmed_host"
+
+	// MessageReceivedTemplate es el aviso de mensaje nuevo entre huésped y anfitrión
+	MessageReceivedTemplate = "message_received"
+)
+
+// requiredTemplates son los templates sin los que no se puede procesar un evento
+var requiredTemplates = []string{GuestConfirmationTemplate, HostConfirmationTemplate, MessageReceivedTemplate}
+
+// ErrTemplateNotFound indica que no existe un template con ese nombre
+var ErrTemplateNotFound = errors.New("template no encontrado")
+
+// TemplateService carga y renderiza los templates de email
+type TemplateService interface {
+	// Render renderiza un template para un destinatario
+	// data es dto.BookingEmailData para los emails de reservas y dto.MessageEmailData para los de mensajes
+	Render(name, to string, data interface{}) (dto.Email, error)
+
+	// Preview renderiza un template con datos de ejemplo
+	Preview(name string) (dto.Email, error)
+
+	// List retorna los templates cargados y su origen
+	List() dto.TemplatesResponse
+
+	// Reload vuelve a leer los templates de su origen
+	// Si alguno tiene errores se conservan los templates anteriores
+	Reload() error
+}
+
+// emailTemplate es un template parseado: el texto define "subject" y el cuerpo, el HTML es opcional
+type emailTemplate struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// templateService es la implementación concreta de TemplateService
+type templateService struct {
+	source string
+	fsys   fs.FS
+
+	mu        sync.RWMutex
+	templates map[string]*emailTemplate
+}
+
+// NewTemplateService carga los templates de dir, o los incluidos en el binario si dir está vacío
+func NewTemplateService(dir string) (TemplateService, error) {
+	service := &templateService{source: "embedded", fsys: templates.Embedded}
+	if dir != "" {
+		service.source = dir
+		service.fsys = os.DirFS(dir)
+	}
+
+	if err := service.Reload(); err != nil {
+		return nil, err
+	}
+	return service, nil
+}
+
+// Render renderiza un template para un destinatario
+func (s *templateService) Render(name, to string, data interface{}) (dto.Email, error) {
+	s.mu.RLock()
+	tmpl, ok := s.templates[name]
+	s.mu.RUnlock()
+	if !ok {
+		return dto.Email{}, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+	return tmpl.render(name, to, data)
+}
+
+// Preview renderiza un template con datos de ejemplo
+func (s *templateService) Preview(name string) (dto.Email, error) {
+	to, data := sampleEmailData(name)
+	return s.Render(name, to, data)
+}
+
+// List retorna los templates cargados y su origen
+func (s *templateService) List() dto.TemplatesResponse {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	response := dto.TemplatesResponse{Source: s.source, Templates: make([]dto.TemplateInfo, 0, len(s.templates))}
+	for name, tmpl := range s.templates {
+		response.Templates = append(response.Templates, dto.TemplateInfo{Name: name, HasHTML: tmpl.html != nil})
+	}
+	sort.Slice(response.Templates, func(i, j int) bool {
+		return response.Templates[i].Name < response.Templates[j].Name
+	})
+	return response
+}
+
+// Reload vuelve a leer los templates de su origen
+// Cada template se prueba con datos de ejemplo: un campo mal escrito se detecta acá y no al llegar un evento
+func (s *templateService) Reload() error {
+	loaded, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range requiredTemplates {
+		if _, ok := loaded[name]; !ok {
+			return fmt.Errorf("falta el template requerido '%s' en %s", name, s.source)
+		}
+	}
+	for name, tmpl := range loaded {
+		to, sample := sampleEmailData(name)
+		if _, err := tmpl.render(name, to, sample); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	s.templates = loaded
+	s.mu.Unlock()
+	log.Printf("✅ %d templates de email cargados desde %s", len(loaded), s.source)
+	return nil
+}
+
+// load parsea todos los <nombre>.txt del origen y sus <nombre>.html si existen
+func (s *templateService) load() (map[string]*emailTemplate, error) {
+	textFiles, err := fs.Glob(s.fsys, "*.txt")
+	if err != nil {
+		return nil, fmt.Errorf("error listando templates en %s: %w", s.source, err)
+	}
+
+	loaded := make(map[string]*emailTemplate, len(textFiles))
+	for _, file := range textFiles {
+		name := strings.TrimSuffix(path.Base(file), ".txt")
+
+		text, err := texttemplate.ParseFS(s.fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("error parseando el template '%s': %w", file, err)
+		}
+		if text.Lookup("subject") == nil {
+			return nil, fmt.Errorf("el template '%s' no define \"subject\"", file)
+		}
+		tmpl := &emailTemplate{text: text}
+
+		htmlFile := name + ".html"
+		if _, err := fs.Stat(s.fsys, htmlFile); err == nil {
+			html, err := htmltemplate.ParseFS(s.fsys, htmlFile)
+			if err != nil {
+				return nil, fmt.Errorf("error parseando el template '%s': %w", htmlFile, err)
+			}
+			tmpl.html = html
+		}
+
+		loaded[name] = tmpl
+	}
+	return loaded, nil
+}
+
+// render ejecuta el asunto, el cuerpo de texto y el HTML del template
+func (t *emailTemplate) render(name, to string, data interface{}) (dto.Email, error) {
+	var subject, text bytes.Buffer
+	if err := t.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return dto.Email{}, fmt.Errorf("error renderizando el asunto de '%s': %w", name, err)
+	}
+	if err := t.text.Execute(&text, data); err != nil {
+		return dto.Email{}, fmt.Errorf("error renderizando el texto de '%s': %w", name, err)
+	}
+
+	email := dto.Email{
+		To:       to,
+		Subject:  strings.TrimSpace(subject.String()),
+		Text:     text.String(),
+		Template: name,
+	}
+
+	if t.html != nil {
+		var html bytes.Buffer
+		if err := t.html.Execute(&html, data); err != nil {
+			return dto.Email{}, fmt.Errorf("error renderizando el HTML de '%s': %w", name, err)
+		}
+		email.HTML = html.String()
+	}
+	return email, nil
+}
+
+// sampleEmailData retorna el destinatario y los datos de ejemplo de un template
+// Los templates que no son de mensajes (incluidos los agregados en EMAIL_TEMPLATES_DIR) reciben datos de una reserva
+func sampleEmailData(name string) (string, interface{}) {
+	switch name {
+	case MessageReceivedTemplate:
+		data := sampleMessageEmailData()
+		return data.Recipient.Email, data
+	case HostConfirmationTemplate:
+		data := sampleBookingEmailData()
+		return data.Host.Email, data
+	default:
+		data := sampleBookingEmailData()
+		return data.Guest.Email, data
+	}
+}
+
+// sampleBookingEmailData son los datos de ejemplo de los emails de reservas
+func sampleBookingEmailData() dto.BookingEmailData {
+	return dto.BookingEmailData{
+		BookingID: "665f1c2ab3e4d5f6a7b8c9d0",
+		Property: dto.Property{
+			ID:       "665f1c2ab3e4d5f6a7b8c9aa",
+			Title:    "Cabaña frente al lago",
+			Price:    85,
+			Location: "Villa Carlos Paz, Córdoba",
+			Timezone: "America/Argentina/Cordoba",
+			OwnerID:  "7",
+		},
+		Guest: dto.User{ID: 12, Username: "jperez", Email: "juan.perez@example.com", FirstName: "Juan", LastName: "Pérez"},
+		Host:  dto.User{ID: 7, Username: "mgomez", Email: "maria.gomez@example.com", FirstName: "María", LastName: "Gómez"},
+
+		CheckIn:    "12/01/2026",
+		CheckOut:   "15/01/2026",
+		Nights:     3,
+		TotalPrice: 255,
+	}
+}
+
+// sampleMessageEmailData son los datos de ejemplo del aviso de mensaje nuevo
+func sampleMessageEmailData() dto.MessageEmailData {
+	booking := sampleBookingEmailData()
+	return dto.MessageEmailData{
+		ConversationID: "665f1c2ab3e4d5f6a7b8c9bb",
+		Property:       booking.Property,
+		Sender:         booking.Guest,
+		Recipient:      booking.Host,
+		Preview:        "Hola María, ¿la cabaña tiene lugar para estacionar?",
+		Unread:         2,
+		SentAt:         "10/01/2026 18:30",
+	}
+}
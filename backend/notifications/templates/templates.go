@@ -0,0 +1,10 @@
+package templates
+
+import "embed"
+
+// Embedded son los templates de email incluidos en el binario
+// Cada template tiene un archivo <nombre>.txt (define "subject" y el cuerpo en texto plano)
+// y opcionalmente un <nombre>.html con el cuerpo en HTML
+//
+//go:embed *.txt *.html
+var Embedded embed.FS
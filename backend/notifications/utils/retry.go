@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand"
+	"time"
+)
+
+// permanentError marca un error que no tiene sentido reintentar
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marca un error como definitivo (ej: la propiedad no existe o el template tiene errores)
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent indica si el error fue marcado con Permanent
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// RetryPolicy es la configuración de reintentos de una operación
+type RetryPolicy struct {
+	// MaxAttempts es la cantidad máxima de intentos (incluye el primero)
+	MaxAttempts int
+	// BaseDelay es la espera antes del primer reintento; se duplica en cada intento
+	BaseDelay time.Duration
+	// MaxDelay es la espera máxima entre reintentos
+	MaxDelay time.Duration
+}
+
+// Retry ejecuta fn con reintentos y backoff exponencial con jitter
+// No reintenta los errores permanentes
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+
+	var err error
+	for attempt := 1; attempt <= policy.MaxAttempts; attempt++ {
+		err = fn(ctx)
+		if err == nil || IsPermanent(err) {
+			return err
+		}
+		if attempt == policy.MaxAttempts {
+			break
+		}
+
+		delay := backoffDelay(policy, attempt)
+		log.Printf("🔁 Intento %d/%d fallido, reintentando en %v: %v", attempt, policy.MaxAttempts, delay, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+	return err
+}
+
+// backoffDelay calcula la espera antes del siguiente intento (BaseDelay * 2^(attempt-1), con jitter)
+func backoffDelay(policy RetryPolicy, attempt int) time.Duration {
+	delay := policy.BaseDelay << (attempt - 1)
+	if delay <= 0 || (policy.MaxDelay > 0 && delay > policy.MaxDelay) {
+		delay = policy.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	// Jitter de hasta el 50% para no reintentar todos a la vez
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
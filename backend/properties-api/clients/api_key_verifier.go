@@ -0,0 +1,98 @@
+package clients
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"properties-api/rpc"
+
+	"google.golang.org/grpc"
+)
+
+// APIKeyIdentity es el servicio interno autenticado por una API key
+type APIKeyIdentity struct {
+	Name   string
+	Scopes []string
+}
+
+// HasScope indica si la key tiene el scope indicado
+func (i APIKeyIdentity) HasScope(scope string) bool {
+	for _, s := range i.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeyVerifier valida las API keys con las que llaman los servicios internos
+type APIKeyVerifier interface {
+	// Verify retorna la identidad del servicio; ok es false si la key no es válida
+	// err indica que no se pudo consultar a users-api
+	Verify(ctx context.Context, key string) (identity APIKeyIdentity, ok bool, err error)
+}
+
+// apiKeyVerification es un resultado de users-api guardado en la caché
+type apiKeyVerification struct {
+	identity  APIKeyIdentity
+	ok        bool
+	expiresAt time.Time
+}
+
+// apiKeyVerifier valida las keys contra users-api (VerifyAPIKey) y cachea el resultado por cacheTTL
+// Así cada llamada interna no agrega un round-trip; una key revocada deja de aceptarse a más tardar en cacheTTL
+type apiKeyVerifier struct {
+	stub     rpc.UsersServiceClient
+	cacheTTL time.Duration
+
+	mu    sync.Mutex
+	cache map[string]apiKeyVerification // Por hash de la key, para no guardar el valor en memoria
+}
+
+// NewAPIKeyVerifier crea el verificador sobre la conexión gRPC a users-api (ver NewGRPCConn)
+func NewAPIKeyVerifier(conn grpc.ClientConnInterface, cacheTTL time.Duration) APIKeyVerifier {
+	return &apiKeyVerifier{
+		stub:     rpc.NewUsersServiceClient(conn),
+		cacheTTL: cacheTTL,
+		cache:    make(map[string]apiKeyVerification),
+	}
+}
+
+// Verify valida la key usando la caché si el resultado sigue vigente
+func (v *apiKeyVerifier) Verify(ctx context.Context, key string) (APIKeyIdentity, bool, error) {
+	sum := sha256.Sum256([]byte(key))
+	cacheKey := hex.EncodeToString(sum[:])
+	now := time.Now()
+
+	v.mu.Lock()
+	cached, found := v.cache[cacheKey]
+	v.mu.Unlock()
+	if found && now.Before(cached.expiresAt) {
+		return cached.identity, cached.ok, nil
+	}
+
+	response, err := v.stub.VerifyAPIKey(ctx, &rpc.VerifyAPIKeyRequest{Key: key})
+	if err != nil {
+		return APIKeyIdentity{}, false, grpcCallError("users-api", err)
+	}
+	result := apiKeyVerification{
+		identity:  APIKeyIdentity{Name: response.Name, Scopes: response.Scopes},
+		ok:        response.Valid,
+		expiresAt: now.Add(v.cacheTTL),
+	}
+
+	v.mu.Lock()
+	// Se descartan los vencidos al guardar para que la caché no crezca con keys inválidas
+	for k, entry := range v.cache {
+		if !now.Before(entry.expiresAt) {
+			delete(v.cache, k)
+		}
+	}
+	v.cache[cacheKey] = result
+	v.mu.Unlock()
+
+	return result.identity, result.ok, nil
+}
@@ -0,0 +1,61 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// maxCalendarFeedBytes limita el tamaño de un calendario externo descargado
+const maxCalendarFeedBytes = 5 << 20 // 5 MB
+
+// CalendarFeedClient descarga calendarios iCal externos (Airbnb, Booking...)
+type CalendarFeedClient interface {
+	// Fetch descarga el contenido del calendario publicado en url
+	Fetch(ctx context.Context, url string) ([]byte, error)
+}
+
+// calendarFeedClient es la implementación concreta de CalendarFeedClient
+// No usa circuit breaker: cada calendario es de un host distinto y un error de uno
+// no debe cortar la sincronización de los demás
+type calendarFeedClient struct {
+	httpClient *http.Client
+}
+
+// NewCalendarFeedClient crea una nueva instancia del cliente de calendarios externos
+// Recibe el cliente HTTP compartido (ver NewHTTPClient)
+func NewCalendarFeedClient(httpClient *http.Client) CalendarFeedClient {
+	return &calendarFeedClient{
+		httpClient: httpClient,
+	}
+}
+
+// Fetch descarga el calendario; un status distinto de 200 o un calendario demasiado grande es un error
+func (c *calendarFeedClient) Fetch(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creando request HTTP: %w", err)
+	}
+	req.Header.Set("Accept", "text/calendar")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error descargando calendario: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error descargando calendario: status code %d", resp.StatusCode)
+	}
+
+	// Se lee un byte de más para detectar calendarios que superan el límite
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxCalendarFeedBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo calendario: %w", err)
+	}
+	if len(body) > maxCalendarFeedBytes {
+		return nil, fmt.Errorf("el calendario supera el tamaño máximo de %d bytes", maxCalendarFeedBytes)
+	}
+	return body, nil
+}
@@ -0,0 +1,63 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"properties-api/rpc"
+	"resilience"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrHostNotFound indica que el anfitrión no existe en users-api o su cuenta está desactivada
+var ErrHostNotFound = errors.New("anfitrión no encontrado")
+
+// HostProfileClient obtiene de users-api el perfil público del anfitrión de una propiedad
+type HostProfileClient interface {
+	// GetHostProfile obtiene el perfil por el ID de usuario del owner; retorna ErrHostNotFound si no existe
+	GetHostProfile(ctx context.Context, userID string) (rpc.HostProfile, error)
+}
+
+// hostProfileClient implementa HostProfileClient sobre el servidor gRPC de users-api
+// Usa el mismo circuit breaker y reintentos que la validación de usuarios
+type hostProfileClient struct {
+	stub    rpc.UsersServiceClient
+	breaker *resilience.CircuitBreaker
+	retry   resilience.RetryPolicy
+}
+
+// NewHostProfileClient crea el cliente de perfiles sobre una conexión gRPC (ver NewGRPCConn)
+func NewHostProfileClient(conn grpc.ClientConnInterface) HostProfileClient {
+	return &hostProfileClient{
+		stub:    rpc.NewUsersServiceClient(conn),
+		breaker: resilience.NewCircuitBreaker("users-api", resilience.DefaultCircuitBreakerSettings()),
+		retry:   resilience.DefaultRetryPolicy(),
+	}
+}
+
+// GetHostProfile obtiene el perfil público del anfitrión
+func (c *hostProfileClient) GetHostProfile(ctx context.Context, userID string) (rpc.HostProfile, error) {
+	id, err := strconv.ParseUint(userID, 10, 32)
+	if err != nil || id == 0 {
+		return rpc.HostProfile{}, fmt.Errorf("ID de usuario inválido: '%s'", userID)
+	}
+
+	var profile rpc.HostProfile
+	err = resilience.CallWithResilience(ctx, c.breaker, c.retry, func(ctx context.Context) error {
+		response, err := c.stub.GetHostProfile(ctx, &rpc.GetHostProfileRequest{UserID: uint(id)})
+		if status.Code(err) == codes.NotFound {
+			return resilience.Permanent(ErrHostNotFound)
+		}
+		if err != nil {
+			return grpcCallError("users-api", err)
+		}
+		profile = response.Profile
+		return nil
+	})
+	return profile, err
+}
@@ -0,0 +1,40 @@
+package clients
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"properties-api/config"
+)
+
+// dialKeepAlive es el intervalo de keep-alive de las conexiones TCP salientes
+const dialKeepAlive = 30 * time.Second
+
+// NewHTTPClient crea un cliente HTTP con un Transport propio configurado (pool y timeouts)
+// Se crea una sola vez al arrancar y se comparte entre todos los clientes salientes
+// para reutilizar las conexiones en lugar de usar http.DefaultClient
+func NewHTTPClient(cfg config.HTTPClientConfig) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   cfg.DialTimeout,
+		KeepAlive: dialKeepAlive,
+	}
+
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          cfg.MaxIdleConns,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       cfg.MaxConnsPerHost,
+		IdleConnTimeout:       cfg.IdleConnTimeout,
+		TLSHandshakeTimeout:   cfg.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: cfg.ResponseHeaderTimeout,
+		ExpectContinueTimeout: time.Second,
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   cfg.Timeout,
+	}
+}
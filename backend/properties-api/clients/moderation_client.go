@@ -0,0 +1,80 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// maxModerationResponseBytes limita el tamaño de la respuesta de la API de moderación
+const maxModerationResponseBytes = 64 << 10
+
+// ModerationVerdict es la respuesta de la API externa de moderación
+type ModerationVerdict struct {
+	Flagged bool     `json:"flagged"`
+	Reasons []string `json:"reasons"`
+}
+
+// ModerationClient consulta una API externa de moderación de texto
+// Contrato: POST {"text": "..."} y respuesta {"flagged": bool, "reasons": ["..."]}
+type ModerationClient interface {
+	Check(ctx context.Context, text string) (ModerationVerdict, error)
+}
+
+// moderationClient es la implementación concreta de ModerationClient
+type moderationClient struct {
+	httpClient *http.Client
+	url        string
+	token      string
+	timeout    time.Duration
+}
+
+// NewModerationClient crea el cliente de la API de moderación
+// Recibe el cliente HTTP compartido (ver NewHTTPClient); token vacío = sin header Authorization
+func NewModerationClient(httpClient *http.Client, url, token string, timeout time.Duration) ModerationClient {
+	return &moderationClient{
+		httpClient: httpClient,
+		url:        url,
+		token:      token,
+		timeout:    timeout,
+	}
+}
+
+// Check envía el texto a la API; un status distinto de 200 es un error
+func (c *moderationClient) Check(ctx context.Context, text string) (ModerationVerdict, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return ModerationVerdict{}, fmt.Errorf("error serializando texto a moderar: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", c.url, bytes.NewReader(body))
+	if err != nil {
+		return ModerationVerdict{}, fmt.Errorf("error creando request HTTP: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return ModerationVerdict{}, fmt.Errorf("error consultando API de moderación: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return ModerationVerdict{}, fmt.Errorf("error consultando API de moderación: status code %d", resp.StatusCode)
+	}
+
+	var verdict ModerationVerdict
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxModerationResponseBytes)).Decode(&verdict); err != nil {
+		return ModerationVerdict{}, fmt.Errorf("error decodificando respuesta de la API de moderación: %w", err)
+	}
+	return verdict, nil
+}
@@ -3,10 +3,170 @@ package clients
 import (
 	"encoding/json"
 	"fmt"
+	"time"
+
+	"properties-api/dto"
 
 	"github.com/streadway/amqp"
 )
 
+// PropertyEventSchemaVersion es la versión del snapshot incluido en los eventos
+// Se incrementa cuando cambia la forma del snapshot de manera incompatible
+const PropertyEventSchemaVersion = 1
+
+// DefaultPropertyExchange es el topic exchange donde se publican los eventos de propiedades
+// Cada consumidor declara y bindea su propia cola (ej: search-api con "property.*")
+const DefaultPropertyExchange = "properties_exchange"
+
+// propertyRoutingKeys mapea cada operación a su routing key en el exchange
+var propertyRoutingKeys = map[string]string{
+	"create": "property.created",
+	"update": "property.updated",
+	"delete": "property.deleted",
+}
+
+// PropertyPopularityRoutingKey es la routing key de los eventos con las vistas actualizadas
+// Los publica el volcado periódico de vistas para que search-api ordene por popularidad
+const PropertyPopularityRoutingKey = "property.popularity"
+
+// PropertyPopularityEvent informa el total de vistas de las propiedades vistas desde el último volcado
+type PropertyPopularityEvent struct {
+	// Views mapea el ID de cada propiedad a su total de vistas
+	Views map[string]int64 `json:"views"`
+
+	// SyncedAt es la fecha del volcado (UTC, RFC3339)
+	SyncedAt string `json:"syncedAt"`
+}
+
+// MessageSentRoutingKey es la routing key de los mensajes entre huésped y anfitrión
+// La consume notifications para avisar por email al destinatario
+const MessageSentRoutingKey = "message.sent"
+
+// MessageSentEvent informa un mensaje nuevo en una conversación
+type MessageSentEvent struct {
+	ConversationID string `json:"conversationId"`
+	MessageID      string `json:"messageId"`
+	PropertyID     string `json:"propertyId"`
+	SenderID       string `json:"senderId"`
+	RecipientID    string `json:"recipientId"`
+
+	// Preview es un extracto del mensaje (el texto completo se lee en la conversación)
+	Preview string `json:"preview"`
+
+	// RecipientUnread es la cantidad de mensajes sin leer del destinatario en la conversación
+	RecipientUnread int64 `json:"recipientUnread"`
+
+	// SentAt es la fecha del mensaje (UTC, RFC3339)
+	SentAt string `json:"sentAt"`
+}
+
+// BookingConfirmedRoutingKey es la routing key de las reservas confirmadas
+// La consumen notifications (emails de confirmación) y search-api (personalización)
+const BookingConfirmedRoutingKey = "booking.confirmed"
+
+// BookingConfirmedEvent informa una reserva confirmada
+type BookingConfirmedEvent struct {
+	ID         string `json:"id"`
+	PropertyID string `json:"propertyId"`
+	UserID     string `json:"userId"`
+
+	// CheckIn y CheckOut son los instantes de la estadía (UTC, RFC3339)
+	CheckIn  string `json:"checkIn"`
+	CheckOut string `json:"checkOut"`
+
+	TotalPrice float64 `json:"totalPrice"`
+}
+
+// Routing keys de las solicitudes de reserva de propiedades que requieren aprobación del anfitrión
+// Una solicitud aprobada se publica como "booking.confirmed", igual que una reserva instantánea
+const (
+	BookingRequestedRoutingKey = "booking.requested"
+	BookingDeclinedRoutingKey  = "booking.declined"
+	BookingExpiredRoutingKey   = "booking.expired"
+)
+
+// BookingRequestEvent informa una solicitud de reserva nueva, rechazada o vencida
+type BookingRequestEvent struct {
+	ID         string `json:"id"`
+	PropertyID string `json:"propertyId"`
+	UserID     string `json:"userId"`
+	OwnerID    string `json:"ownerId"`
+
+	// CheckIn y CheckOut son los instantes de la estadía (UTC, RFC3339)
+	CheckIn  string `json:"checkIn"`
+	CheckOut string `json:"checkOut"`
+
+	TotalPrice float64 `json:"totalPrice"`
+	Status     string  `json:"status"`
+
+	// ExpiresAt es hasta cuándo el anfitrión puede responder la solicitud (UTC, RFC3339)
+	ExpiresAt string `json:"expiresAt"`
+}
+
+// AvailabilityUpdatedRoutingKey es la routing key de los cambios de disponibilidad cargados por el owner
+// Un cambio masivo (ej: bloquear todo enero) se publica como un solo evento
+const AvailabilityUpdatedRoutingKey = "availability.updated"
+
+// AvailabilityDateRange es un rango de días locales de la propiedad (YYYY-MM-DD); End es exclusivo
+type AvailabilityDateRange struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// AvailabilityUpdatedEvent informa los rangos que el owner bloqueó o abrió en una propiedad
+type AvailabilityUpdatedEvent struct {
+	PropertyID string `json:"propertyId"`
+	UpdatedBy  string `json:"updatedBy"`
+
+	// Blocked y Opened son los rangos pedidos, en el orden en que se aplicaron
+	Blocked []AvailabilityDateRange `json:"blocked"`
+	Opened  []AvailabilityDateRange `json:"opened"`
+
+	// ManualBlocks son todos los bloqueos manuales de la propiedad después del cambio
+	ManualBlocks []AvailabilityDateRange `json:"manualBlocks"`
+
+	// UpdatedAt es la fecha del cambio (UTC, RFC3339)
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// AvailabilityChangedRoutingKey es la routing key de los cambios en las fechas ocupadas de una propiedad
+// Se publica cuando una reserva toma o libera fechas y cuando cambian los bloqueos (del owner o importados);
+// search-api la consume para filtrar las búsquedas por fechas
+const AvailabilityChangedRoutingKey = "availability.changed"
+
+// AvailabilityChangedEvent informa las fechas ocupadas de una propiedad después del cambio
+// Trae el estado completo y no el cambio: aplicar el último evento alcanza aunque se pierda alguno
+type AvailabilityChangedEvent struct {
+	PropertyID string `json:"propertyId"`
+
+	// Reason es lo que cambió: "booking", "block" (bloqueos del owner) o "feed" (calendario externo)
+	Reason string `json:"reason"`
+
+	// BookedRanges son los rangos ocupados desde hoy, unidos y ordenados (días locales, End exclusivo)
+	BookedRanges []AvailabilityDateRange `json:"bookedRanges"`
+
+	// NextAvailableDate es el primer día libre desde hoy (YYYY-MM-DD, día local de la propiedad)
+	NextAvailableDate string `json:"nextAvailableDate"`
+
+	// ChangedAt es la fecha del cambio (UTC, RFC3339)
+	ChangedAt string `json:"changedAt"`
+}
+
+// ImageJobRoutingKey es la routing key de los pedidos de procesamiento de fotos subidas
+// Los consume el worker de imágenes (cola image_jobs) para generar las variantes
+const ImageJobRoutingKey = "image.process"
+
+// ImageJob pide generar las variantes de una foto subida
+type ImageJob struct {
+	PropertyID string `json:"propertyId"`
+
+	// PhotoURL identifica a la foto dentro de la galería de la propiedad
+	PhotoURL string `json:"photoUrl"`
+
+	// StorageKey es la clave del original en el almacenamiento de imágenes
+	StorageKey string `json:"storageKey"`
+}
+
 // PropertyEvent representa un evento relacionado con propiedades
 // Se serializa a JSON para ser publicado en RabbitMQ
 type PropertyEvent struct {
@@ -15,29 +175,66 @@ type PropertyEvent struct {
 
 	// PropertyID es el identificador único de la propiedad afectada
 	PropertyID string `json:"propertyId"`
+
+	// SchemaVersion es la versión del snapshot (0 si el evento no trae snapshot)
+	SchemaVersion int `json:"schemaVersion,omitempty"`
+
+	// Property es el snapshot completo de la propiedad (opcional)
+	// Permite a los consumidores indexar sin volver a consultar properties-api
+	Property *dto.PropertyResponseDTO `json:"property,omitempty"`
 }
 
 // RabbitMQClient define la interfaz para publicar eventos en RabbitMQ
 // Implementa el patrón de cliente para abstraer la lógica de mensajería
 type RabbitMQClient interface {
-	// PublishPropertyEvent publica un evento de propiedad en la cola de RabbitMQ
-	// Serializa el evento a JSON y lo publica en la cola "property_events"
+	// PublishPropertyEvent publica un evento de propiedad en el exchange de RabbitMQ
+	// Serializa el evento a JSON y lo publica con la routing key de la operación
 	// Retorna error si falla la serialización o la publicación
 	PublishPropertyEvent(operation string, propertyID string) error
+
+	// PublishPropertySnapshotEvent publica un evento que incluye el snapshot completo de la propiedad
+	// Los consumidores que no entiendan el snapshot siguen usando solo el PropertyID
+	PublishPropertySnapshotEvent(operation string, property dto.PropertyResponseDTO) error
+
+	// PublishPopularityEvent publica el total de vistas de un lote de propiedades
+	PublishPopularityEvent(event PropertyPopularityEvent) error
+
+	// PublishMessageEvent publica un mensaje nuevo entre huésped y anfitrión
+	PublishMessageEvent(event MessageSentEvent) error
+
+	// PublishBookingEvent publica una reserva confirmada
+	PublishBookingEvent(event BookingConfirmedEvent) error
+
+	// PublishBookingRequestEvent publica un cambio de una solicitud de reserva
+	// routingKey es BookingRequestedRoutingKey, BookingDeclinedRoutingKey o BookingExpiredRoutingKey
+	PublishBookingRequestEvent(routingKey string, event BookingRequestEvent) error
+
+	// PublishImageJob encola el procesamiento de una foto subida
+	PublishImageJob(job ImageJob) error
+
+	// PublishAvailabilityEvent publica un cambio de disponibilidad cargado por el owner
+	PublishAvailabilityEvent(event AvailabilityUpdatedEvent) error
+
+	// PublishAvailabilityChangedEvent publica las fechas ocupadas de una propiedad después de un cambio
+	PublishAvailabilityChangedEvent(event AvailabilityChangedEvent) error
+
+	// Ping retorna error si la conexión con RabbitMQ está cerrada (usado por el health check)
+	Ping() error
 }
 
 // rabbitMQClient es la implementación concreta de RabbitMQClient
 // Usa github.com/streadway/amqp para la comunicación con RabbitMQ
 type rabbitMQClient struct {
-	conn    *amqp.Connection
-	channel *amqp.Channel
+	conn     *amqp.Connection
+	channel  *amqp.Channel
+	exchange string
 }
 
 // NewRabbitMQClient crea una nueva instancia del cliente de RabbitMQ
 // Se conecta a RabbitMQ usando la URL proporcionada
-// Declara la cola "property_events" como durable (sobrevive reinicios del servidor)
-// Retorna error si falla la conexión o la declaración de la cola
-func NewRabbitMQClient(url string) (RabbitMQClient, error) {
+// Declara el topic exchange como durable (sobrevive reinicios del servidor)
+// Retorna error si falla la conexión o la declaración del exchange
+func NewRabbitMQClient(url string, exchange string) (RabbitMQClient, error) {
 	// Conectar a RabbitMQ
 	conn, err := amqp.Dial(url)
 	if err != nil {
@@ -52,32 +249,33 @@ func NewRabbitMQClient(url string) (RabbitMQClient, error) {
 		return nil, fmt.Errorf("error abriendo canal de RabbitMQ: %w", err)
 	}
 
-	// Declarar la cola "property_events" como durable
-	// durable=true significa que la cola sobrevive a reinicios del servidor RabbitMQ
-	queueName := "property_events"
-	_, err = channel.QueueDeclare(
-		queueName, // nombre de la cola
-		true,      // durable - la cola sobrevive a reinicios del servidor
-		false,     // delete when unused - no se elimina cuando no hay consumidores
-		false,     // exclusive - solo accesible por la conexión que la crea
-		false,     // no-wait - no espera confirmación del servidor
-		nil,       // arguments - argumentos adicionales
+	// Declarar el exchange de tipo "topic"
+	// Los consumidores se suscriben con patrones de routing key (ej: "property.*")
+	err = channel.ExchangeDeclare(
+		exchange, // nombre del exchange
+		"topic",  // tipo - enruta por patrón de routing key
+		true,     // durable - el exchange sobrevive a reinicios del servidor
+		false,    // auto-deleted - no se elimina cuando no hay colas bindeadas
+		false,    // internal - acepta publicaciones de clientes
+		false,    // no-wait - espera confirmación del servidor
+		nil,      // arguments - argumentos adicionales
 	)
 	if err != nil {
-		// Cerrar canal y conexión si falla la declaración de la cola
+		// Cerrar canal y conexión si falla la declaración del exchange
 		channel.Close()
 		conn.Close()
-		return nil, fmt.Errorf("error declarando cola '%s' en RabbitMQ: %w", queueName, err)
+		return nil, fmt.Errorf("error declarando exchange '%s' en RabbitMQ: %w", exchange, err)
 	}
 
 	return &rabbitMQClient{
-		conn:    conn,
-		channel: channel,
+		conn:     conn,
+		channel:  channel,
+		exchange: exchange,
 	}, nil
 }
 
-// PublishPropertyEvent publica un evento de propiedad en la cola de RabbitMQ
-// Serializa el evento a JSON y lo publica en la cola "property_events"
+// PublishPropertyEvent publica un evento de propiedad en el exchange de RabbitMQ
+// Serializa el evento a JSON y lo publica con la routing key "property.<operación>"
 func (c *rabbitMQClient) PublishPropertyEvent(operation string, propertyID string) error {
 	// Crear el struct del evento
 	event := PropertyEvent{
@@ -85,28 +283,101 @@ func (c *rabbitMQClient) PublishPropertyEvent(operation string, propertyID strin
 		PropertyID: propertyID,
 	}
 
+	return c.publish(event)
+}
+
+// PublishPropertySnapshotEvent publica un evento con el snapshot completo de la propiedad
+func (c *rabbitMQClient) PublishPropertySnapshotEvent(operation string, property dto.PropertyResponseDTO) error {
+	return c.publish(NewPropertySnapshotEvent(operation, property))
+}
+
+// NewPropertySnapshotEvent arma el evento con el snapshot tal como se publica
+// Los tests de contrato lo usan para verificar el formato que espera search-api
+func NewPropertySnapshotEvent(operation string, property dto.PropertyResponseDTO) PropertyEvent {
+	return PropertyEvent{
+		Operation:     operation,
+		PropertyID:    property.ID,
+		SchemaVersion: PropertyEventSchemaVersion,
+		Property:      &property,
+	}
+}
+
+// PublishPopularityEvent publica el total de vistas de un lote de propiedades con la routing key "property.popularity"
+func (c *rabbitMQClient) PublishPopularityEvent(event PropertyPopularityEvent) error {
+	return c.publishJSON(PropertyPopularityRoutingKey, event)
+}
+
+// PublishMessageEvent publica un mensaje nuevo con la routing key "message.sent"
+func (c *rabbitMQClient) PublishMessageEvent(event MessageSentEvent) error {
+	return c.publishJSON(MessageSentRoutingKey, event)
+}
+
+// PublishBookingEvent publica una reserva confirmada con la routing key "booking.confirmed"
+func (c *rabbitMQClient) PublishBookingEvent(event BookingConfirmedEvent) error {
+	return c.publishJSON(BookingConfirmedRoutingKey, event)
+}
+
+// PublishBookingRequestEvent publica un cambio de una solicitud de reserva con la routing key indicada
+func (c *rabbitMQClient) PublishBookingRequestEvent(routingKey string, event BookingRequestEvent) error {
+	return c.publishJSON(routingKey, event)
+}
+
+// PublishImageJob encola el procesamiento de una foto con la routing key "image.process"
+func (c *rabbitMQClient) PublishImageJob(job ImageJob) error {
+	return c.publishJSON(ImageJobRoutingKey, job)
+}
+
+// PublishAvailabilityEvent publica un cambio de disponibilidad con la routing key "availability.updated"
+func (c *rabbitMQClient) PublishAvailabilityEvent(event AvailabilityUpdatedEvent) error {
+	return c.publishJSON(AvailabilityUpdatedRoutingKey, event)
+}
+
+// PublishAvailabilityChangedEvent publica las fechas ocupadas con la routing key "availability.changed"
+func (c *rabbitMQClient) PublishAvailabilityChangedEvent(event AvailabilityChangedEvent) error {
+	return c.publishJSON(AvailabilityChangedRoutingKey, event)
+}
+
+// Ping retorna error si la conexión con RabbitMQ está cerrada
+func (c *rabbitMQClient) Ping() error {
+	if c.conn.IsClosed() {
+		return fmt.Errorf("conexión con RabbitMQ cerrada")
+	}
+	return nil
+}
+
+// publish serializa el evento a JSON y lo publica en el exchange con la routing key de la operación
+func (c *rabbitMQClient) publish(event PropertyEvent) error {
+	routingKey, ok := propertyRoutingKeys[event.Operation]
+	if !ok {
+		return fmt.Errorf("operación de evento desconocida: '%s'", event.Operation)
+	}
+
+	return c.publishJSON(routingKey, event)
+}
+
+// publishJSON serializa el evento a JSON y lo publica en el exchange con la routing key indicada
+func (c *rabbitMQClient) publishJSON(routingKey string, event interface{}) error {
 	// Serializar el evento a JSON
 	eventJSON, err := json.Marshal(event)
 	if err != nil {
 		return fmt.Errorf("error serializando evento a JSON: %w", err)
 	}
 
-	// Nombre de la cola donde se publicará el evento
-	queueName := "property_events"
-
-	// Publicar el mensaje en la cola
+	// Publicar el mensaje en el exchange
 	err = c.channel.Publish(
-		"",        // exchange - usar exchange por defecto (cadena vacía)
-		queueName, // routing key - nombre de la cola
-		false,     // mandatory - no es obligatorio que haya un consumidor
-		false,     // immediate - no es inmediato
+		c.exchange, // exchange - topic exchange de propiedades
+		routingKey, // routing key - "property.created|updated|deleted|popularity"
+		false,      // mandatory - no es obligatorio que haya una cola bindeada
+		false,      // immediate - no es inmediato
 		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        eventJSON,
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			Timestamp:    time.Now().UTC(), // search-api mide con esto la demora de su consumidor
+			Body:         eventJSON,
 		},
 	)
 	if err != nil {
-		return fmt.Errorf("error publicando evento en la cola '%s': %w", queueName, err)
+		return fmt.Errorf("error publicando evento en el exchange '%s' (%s): %w", c.exchange, routingKey, err)
 	}
 
 	return nil
@@ -0,0 +1,33 @@
+package clients
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPropertyRoutingKeys_MatchTheSearchBinding(t *testing.T) {
+	// search-api bindea su cola con "property.*": cada operación tiene que caer en ese patrón
+	for _, operation := range []string{"create", "update", "delete"} {
+		routingKey, ok := propertyRoutingKeys[operation]
+		if !ok {
+			t.Fatalf("expected a routing key for %s", operation)
+		}
+		words := strings.Split(routingKey, ".")
+		if len(words) != 2 || words[0] != "property" || words[1] == "" {
+			t.Fatalf("expected %s to match property.*, got %q", operation, routingKey)
+		}
+	}
+	if propertyRoutingKeys["create"] == propertyRoutingKeys["update"] || propertyRoutingKeys["update"] == propertyRoutingKeys["delete"] {
+		t.Fatalf("expected one routing key per operation, got %v", propertyRoutingKeys)
+	}
+}
+
+func TestPublishPropertyEvent_RejectsUnknownOperations(t *testing.T) {
+	// Sin canal: si la operación no se rechazara antes de publicar, el test entraría en pánico
+	client := &rabbitMQClient{exchange: DefaultPropertyExchange}
+
+	err := client.PublishPropertyEvent("archive", "p1")
+	if err == nil || !strings.Contains(err.Error(), "archive") {
+		t.Fatalf("expected an unknown operation error, got %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"signing"
+)
+
+// maxSearchAnalyticsResponseBytes limita el tamaño de la respuesta de search-api
+const maxSearchAnalyticsResponseBytes = 256 << 10
+
+// SearchDailyActivity son las búsquedas y clicks por día UTC que registró search-api
+type SearchDailyActivity struct {
+	// CompleteSince es desde cuándo search-api tiene todos los eventos; los días anteriores están incompletos
+	CompleteSince time.Time `json:"completeSince"`
+
+	Days []SearchDayActivity `json:"days"`
+}
+
+// SearchDayActivity es la actividad de búsqueda de un día (Date en formato YYYY-MM-DD)
+type SearchDayActivity struct {
+	Date               string `json:"date"`
+	Searches           int64  `json:"searches"`
+	ZeroResultSearches int64  `json:"zeroResultSearches"`
+	ClickedSearches    int64  `json:"clickedSearches"`
+	Clicks             int64  `json:"clicks"`
+}
+
+// SearchAnalyticsClient consulta los reportes de analytics de search-api
+// Contrato: GET /search/analytics/daily?days=N (requiere un caller privilegiado: el request va firmado con HMAC)
+type SearchAnalyticsClient interface {
+	DailyActivity(ctx context.Context, days int) (SearchDailyActivity, error)
+}
+
+// searchAnalyticsClient es la implementación concreta de SearchAnalyticsClient
+type searchAnalyticsClient struct {
+	httpClient *http.Client
+	baseURL    string
+	caller     string
+	secret     string
+}
+
+// NewSearchAnalyticsClient crea el cliente de analytics de search-api
+// Recibe el cliente HTTP compartido (ver NewHTTPClient) y firma cada request con el secreto interno
+func NewSearchAnalyticsClient(httpClient *http.Client, baseURL, caller, secret string) SearchAnalyticsClient {
+	return &searchAnalyticsClient{
+		httpClient: httpClient,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		caller:     caller,
+		secret:     secret,
+	}
+}
+
+// DailyActivity obtiene la actividad de los últimos days días (incluye hoy)
+// Un status distinto de 200 es un error: sin firma válida search-api responde 403
+func (c *searchAnalyticsClient) DailyActivity(ctx context.Context, days int) (SearchDailyActivity, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/search/analytics/daily?days="+strconv.Itoa(days), nil)
+	if err != nil {
+		return SearchDailyActivity{}, fmt.Errorf("error creando request HTTP: %w", err)
+	}
+	if err := c.sign(req); err != nil {
+		return SearchDailyActivity{}, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return SearchDailyActivity{}, fmt.Errorf("error consultando analytics de search-api: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return SearchDailyActivity{}, fmt.Errorf("error consultando analytics de search-api: status code %d", resp.StatusCode)
+	}
+
+	var activity SearchDailyActivity
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxSearchAnalyticsResponseBytes)).Decode(&activity); err != nil {
+		return SearchDailyActivity{}, fmt.Errorf("error decodificando analytics de search-api: %w", err)
+	}
+	return activity, nil
+}
+
+// sign agrega los headers de la firma HMAC de un request interno (ver signing.InternalSignature)
+// Los GET van sin body: se firma el SHA-256 del body vacío
+func (c *searchAnalyticsClient) sign(req *http.Request) error {
+	if c.secret == "" {
+		return nil
+	}
+	nonce, err := signing.NewInternalNonce()
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set(signing.InternalCallerHeader, c.caller)
+	req.Header.Set(signing.InternalTimestampHeader, timestamp)
+	req.Header.Set(signing.InternalNonceHeader, nonce)
+	req.Header.Set(signing.InternalSignatureHeader, signing.InternalSignature(c.secret,
+		c.caller, req.Method, req.URL.RequestURI(), timestamp, nonce, signing.HashInternalBody(nil)))
+	return nil
+}
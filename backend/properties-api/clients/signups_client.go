@@ -0,0 +1,47 @@
+package clients
+
+import (
+	"context"
+	"time"
+
+	"properties-api/rpc"
+	"resilience"
+
+	"google.golang.org/grpc"
+)
+
+// SignupsClient obtiene de users-api los usuarios registrados por día (KPIs del panel de admin)
+type SignupsClient interface {
+	// CountSignups retorna los registros por día UTC (YYYY-MM-DD) en [from, to); los días sin registros se omiten
+	CountSignups(ctx context.Context, from, to time.Time) (map[string]int64, error)
+}
+
+// signupsClient implementa SignupsClient sobre el servidor gRPC de users-api
+type signupsClient struct {
+	stub    rpc.UsersServiceClient
+	breaker *resilience.CircuitBreaker
+	retry   resilience.RetryPolicy
+}
+
+// NewSignupsClient crea el cliente de registros sobre una conexión gRPC (ver NewGRPCConn)
+func NewSignupsClient(conn grpc.ClientConnInterface) SignupsClient {
+	return &signupsClient{
+		stub:    rpc.NewUsersServiceClient(conn),
+		breaker: resilience.NewCircuitBreaker("users-api", resilience.DefaultCircuitBreakerSettings()),
+		retry:   resilience.DefaultRetryPolicy(),
+	}
+}
+
+// CountSignups cuenta los registros por día en users-api
+func (c *signupsClient) CountSignups(ctx context.Context, from, to time.Time) (map[string]int64, error) {
+	var days map[string]int64
+	err := resilience.CallWithResilience(ctx, c.breaker, c.retry, func(ctx context.Context) error {
+		response, err := c.stub.CountSignups(ctx, &rpc.CountSignupsRequest{From: from, To: to})
+		if err != nil {
+			return grpcCallError("users-api", err)
+		}
+		days = response.Days
+		return nil
+	})
+	return days, err
+}
@@ -0,0 +1,90 @@
+package clients
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"properties-api/rpc"
+
+	"google.golang.org/grpc"
+)
+
+// TokenUser es el usuario de un JWT validado por users-api
+type TokenUser struct {
+	ID       uint
+	Username string
+	UserType string
+}
+
+// TokenVerifier valida los JWT de los usuarios contra users-api, que conoce la lista de revocación
+// La firma y el vencimiento se validan localmente antes: a users-api solo llegan tokens bien firmados
+type TokenVerifier interface {
+	// Verify retorna el usuario del token; ok es false si el token no es válido o fue revocado
+	// err indica que no se pudo consultar a users-api
+	Verify(ctx context.Context, token string) (user TokenUser, ok bool, err error)
+}
+
+// tokenVerification es un resultado de users-api guardado en la caché
+type tokenVerification struct {
+	user      TokenUser
+	ok        bool
+	expiresAt time.Time
+}
+
+// tokenVerifier valida los tokens con users-api (VerifyToken) y cachea el resultado por cacheTTL
+// Así cada request autenticado no agrega un round-trip; un token revocado deja de aceptarse a más tardar en cacheTTL
+type tokenVerifier struct {
+	stub     rpc.UsersServiceClient
+	cacheTTL time.Duration
+
+	mu    sync.Mutex
+	cache map[string]tokenVerification // Por hash del token, para no guardar el valor en memoria
+}
+
+// NewTokenVerifier crea el verificador sobre la conexión gRPC a users-api (ver NewGRPCConn)
+func NewTokenVerifier(conn grpc.ClientConnInterface, cacheTTL time.Duration) TokenVerifier {
+	return &tokenVerifier{
+		stub:     rpc.NewUsersServiceClient(conn),
+		cacheTTL: cacheTTL,
+		cache:    make(map[string]tokenVerification),
+	}
+}
+
+// Verify valida el token usando la caché si el resultado sigue vigente
+func (v *tokenVerifier) Verify(ctx context.Context, token string) (TokenUser, bool, error) {
+	sum := sha256.Sum256([]byte(token))
+	cacheKey := hex.EncodeToString(sum[:])
+	now := time.Now()
+
+	v.mu.Lock()
+	cached, found := v.cache[cacheKey]
+	v.mu.Unlock()
+	if found && now.Before(cached.expiresAt) {
+		return cached.user, cached.ok, nil
+	}
+
+	response, err := v.stub.VerifyToken(ctx, &rpc.VerifyTokenRequest{Token: token})
+	if err != nil {
+		return TokenUser{}, false, grpcCallError("users-api", err)
+	}
+	result := tokenVerification{
+		user:      TokenUser{ID: response.UserID, Username: response.Username, UserType: response.UserType},
+		ok:        response.Valid,
+		expiresAt: now.Add(v.cacheTTL),
+	}
+
+	v.mu.Lock()
+	// Se descartan los vencidos al guardar para que la caché no crezca con tokens viejos
+	for k, entry := range v.cache {
+		if !now.Before(entry.expiresAt) {
+			delete(v.cache, k)
+		}
+	}
+	v.cache[cacheKey] = result
+	v.mu.Unlock()
+
+	return result.user, result.ok, nil
+}
@@ -0,0 +1,78 @@
+package clients
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"properties-api/rpc"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// tokenUsersStub responde VerifyToken como users-api: solo "vigente" es válido
+type tokenUsersStub struct {
+	rpc.UsersServiceClient
+	unavailable bool
+	calls       int
+}
+
+func (s *tokenUsersStub) VerifyToken(ctx context.Context, request *rpc.VerifyTokenRequest, opts ...grpc.CallOption) (*rpc.VerifyTokenResponse, error) {
+	s.calls++
+	if s.unavailable {
+		return nil, status.Error(codes.Unavailable, "users-api no disponible")
+	}
+	if request.Token != "vigente" {
+		return &rpc.VerifyTokenResponse{Valid: false}, nil
+	}
+	return &rpc.VerifyTokenResponse{Valid: true, UserID: 7, Username: "maria", UserType: "admin"}, nil
+}
+
+func TestTokenVerifier_CachesUsersAPIAnswer(t *testing.T) {
+	stub := &tokenUsersStub{}
+	verifier := &tokenVerifier{stub: stub, cacheTTL: time.Hour, cache: make(map[string]tokenVerification)}
+
+	for i := 0; i < 3; i++ {
+		user, ok, err := verifier.Verify(context.Background(), "vigente")
+		if err != nil || !ok || user.ID != 7 || user.UserType != "admin" {
+			t.Fatalf("expected user 7 (admin), got %+v ok=%v err=%v", user, ok, err)
+		}
+	}
+	// Un token revocado también se cachea: no vuelve a llegar a users-api en cada request
+	for i := 0; i < 2; i++ {
+		if _, ok, err := verifier.Verify(context.Background(), "revocado"); err != nil || ok {
+			t.Fatalf("expected revoked token to be rejected, got ok=%v err=%v", ok, err)
+		}
+	}
+	if stub.calls != 2 {
+		t.Fatalf("expected 2 calls to users-api, got %d", stub.calls)
+	}
+}
+
+func TestTokenVerifier_ExpiredEntryAsksAgain(t *testing.T) {
+	stub := &tokenUsersStub{}
+	verifier := &tokenVerifier{stub: stub, cacheTTL: time.Millisecond, cache: make(map[string]tokenVerification)}
+
+	verifier.Verify(context.Background(), "vigente")
+	time.Sleep(5 * time.Millisecond)
+	verifier.Verify(context.Background(), "vigente")
+
+	if stub.calls != 2 {
+		t.Fatalf("expected 2 calls to users-api, got %d", stub.calls)
+	}
+}
+
+func TestTokenVerifier_UsersAPIErrorIsNotCached(t *testing.T) {
+	stub := &tokenUsersStub{unavailable: true}
+	verifier := &tokenVerifier{stub: stub, cacheTTL: time.Hour, cache: make(map[string]tokenVerification)}
+
+	if _, _, err := verifier.Verify(context.Background(), "vigente"); err == nil {
+		t.Fatal("expected an error while users-api is down")
+	}
+	stub.unavailable = false
+	if _, ok, err := verifier.Verify(context.Background(), "vigente"); err != nil || !ok {
+		t.Fatalf("expected the token to be valid once users-api is back, got ok=%v err=%v", ok, err)
+	}
+}
@@ -0,0 +1,81 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// maxTranslationResponseBytes limita el tamaño de la respuesta de la API de traducción
+const maxTranslationResponseBytes = 256 << 10
+
+// TranslationClient traduce textos con una API externa de traducción automática
+// Contrato: POST {"texts": ["..."], "source": "es", "target": "en"} y respuesta {"translations": ["..."]}
+// con una traducción por texto y en el mismo orden
+type TranslationClient interface {
+	Translate(ctx context.Context, texts []string, source, target string) ([]string, error)
+}
+
+// translationClient es la implementación concreta de TranslationClient
+type translationClient struct {
+	httpClient *http.Client
+	url        string
+	token      string
+	timeout    time.Duration
+}
+
+// NewTranslationClient crea el cliente de la API de traducción
+// Recibe el cliente HTTP compartido (ver NewHTTPClient); token vacío = sin header Authorization
+func NewTranslationClient(httpClient *http.Client, url, token string, timeout time.Duration) TranslationClient {
+	return &translationClient{
+		httpClient: httpClient,
+		url:        url,
+		token:      token,
+		timeout:    timeout,
+	}
+}
+
+// Translate envía los textos a la API; un status distinto de 200 o una cantidad de traducciones
+// distinta a la de textos es un error
+func (c *translationClient) Translate(ctx context.Context, texts []string, source, target string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	body, err := json.Marshal(map[string]interface{}{"texts": texts, "source": source, "target": target})
+	if err != nil {
+		return nil, fmt.Errorf("error serializando textos a traducir: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error creando request HTTP: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando API de traducción: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error consultando API de traducción: status code %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Translations []string `json:"translations"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxTranslationResponseBytes)).Decode(&result); err != nil {
+		return nil, fmt.Errorf("error decodificando respuesta de la API de traducción: %w", err)
+	}
+	if len(result.Translations) != len(texts) {
+		return nil, fmt.Errorf("la API de traducción devolvió %d traducciones para %d textos", len(result.Translations), len(texts))
+	}
+	return result.Translations, nil
+}
@@ -15,10 +15,11 @@ type UserClient struct {
 }
 
 // NewUserClient crea una nueva instancia del cliente de usuarios
-func NewUserClient() *UserClient {
+// httpClient es el cliente HTTP compartido (ver NewHTTPClient)
+func NewUserClient(httpClient *http.Client) *UserClient {
 	return &UserClient{
 		baseURL: config.AppConfig.UsersAPI.BaseURL,
-		client:  &http.Client{},
+		client:  httpClient,
 	}
 }
 
@@ -1,9 +1,12 @@
 package clients
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
+
+	"resilience"
 )
 
 // UsersClient define la interfaz para la comunicación HTTP con users-api
@@ -13,42 +16,61 @@ type UsersClient interface {
 	// Hace una petición GET a {baseURL}/users/{userID}
 	// Retorna true si el usuario existe (status 200), false si no existe (status 404)
 	// Retorna error en otros casos (errores de red, status codes inesperados, etc.)
-	ValidateUser(userID string) (bool, error)
+	ValidateUser(ctx context.Context, userID string) (bool, error)
 }
 
 // usersClient es la implementación concreta de UsersClient
 // Usa net/http estándar de Go para realizar peticiones HTTP
+// Las llamadas pasan por un circuit breaker y se reintentan con backoff exponencial
 type usersClient struct {
-	baseURL string
+	baseURL    string
+	httpClient *http.Client
+	breaker    *resilience.CircuitBreaker
+	retry      resilience.RetryPolicy
 }
 
 // NewUsersClient crea una nueva instancia del cliente de usuarios
-// Recibe la URL base del servicio users-api como parámetro
+// Recibe la URL base del servicio users-api y el cliente HTTP compartido (ver NewHTTPClient)
 // Retorna la interfaz UsersClient para permitir intercambiabilidad y testabilidad
-func NewUsersClient(baseURL string) UsersClient {
+func NewUsersClient(baseURL string, httpClient *http.Client) UsersClient {
 	return &usersClient{
-		baseURL: baseURL,
+		baseURL:    baseURL,
+		httpClient: httpClient,
+		breaker:    resilience.NewCircuitBreaker("users-api", resilience.DefaultCircuitBreakerSettings()),
+		retry:      resilience.DefaultRetryPolicy(),
 	}
 }
 
 // ValidateUser valida si un usuario existe en users-api
 // Realiza una petición GET a {baseURL}/users/{userID}
-func (c *usersClient) ValidateUser(userID string) (bool, error) {
+// Los errores de red y los 5xx se reintentan; con el circuito abierto falla sin llamar a users-api
+func (c *usersClient) ValidateUser(ctx context.Context, userID string) (bool, error) {
+	var exists bool
+	err := resilience.CallWithResilience(ctx, c.breaker, c.retry, func(ctx context.Context) error {
+		var err error
+		exists, err = c.validateUserOnce(ctx, userID)
+		return err
+	})
+	return exists, err
+}
+
+// validateUserOnce hace un único intento de ValidateUser
+func (c *usersClient) validateUserOnce(ctx context.Context, userID string) (bool, error) {
 	// Construir la URL completa para la petición
 	url := fmt.Sprintf("%s/users/%s", c.baseURL, userID)
 
-	// Crear la petición HTTP GET
-	req, err := http.NewRequest("GET", url, nil)
+	// Crear la petición HTTP GET con el timeout del intento
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return false, fmt.Errorf("error creando request HTTP: %w", err)
+		return false, resilience.Permanent(fmt.Errorf("error creando request HTTP: %w", err))
 	}
 
 	// Establecer headers apropiados
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 
-	// Realizar la petición HTTP usando el cliente por defecto
-	resp, err := http.DefaultClient.Do(req)
+	// Realizar la petición HTTP usando el cliente compartido
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return false, fmt.Errorf("error haciendo petición HTTP a users-api: %w", err)
 	}
@@ -78,7 +100,12 @@ func (c *usersClient) ValidateUser(userID string) (bool, error) {
 			errorMsg = fmt.Sprintf("status code %d: %s", resp.StatusCode, string(body))
 		}
 
-		return false, fmt.Errorf("error validando usuario en users-api: %s", errorMsg)
+		err := fmt.Errorf("error validando usuario en users-api: %s", errorMsg)
+		// Solo los errores del servidor pueden resolverse reintentando
+		if resp.StatusCode < http.StatusInternalServerError {
+			return false, resilience.Permanent(err)
+		}
+		return false, err
 	}
 }
 
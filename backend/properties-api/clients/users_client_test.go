@@ -0,0 +1,74 @@
+package clients
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"resilience"
+)
+
+// newTestUsersClient apunta a users-api con reintentos rápidos y un circuit breaker propio del test
+func newTestUsersClient(t *testing.T, statuses ...int) (UsersClient, *int) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := statuses[len(statuses)-1]
+		if calls < len(statuses) {
+			status = statuses[calls]
+		}
+		calls++
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return &usersClient{
+		baseURL:    server.URL,
+		httpClient: server.Client(),
+		breaker:    resilience.NewCircuitBreaker(t.Name(), resilience.CircuitBreakerSettings{FailureThreshold: 3, OpenTimeout: time.Hour}),
+		retry:      resilience.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond},
+	}, &calls
+}
+
+func TestUsersClient_ValidateUser(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int
+		want      bool
+		wantErr   bool
+		wantCalls int
+	}{
+		{name: "existing user", statuses: []int{http.StatusOK}, want: true, wantCalls: 1},
+		{name: "missing user is not an error", statuses: []int{http.StatusNotFound}, wantCalls: 1},
+		{name: "server errors are retried", statuses: []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK}, want: true, wantCalls: 3},
+		{name: "client errors are not retried", statuses: []int{http.StatusForbidden, http.StatusOK}, wantErr: true, wantCalls: 1},
+		{name: "gives up after the retries", statuses: []int{http.StatusInternalServerError}, wantErr: true, wantCalls: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, calls := newTestUsersClient(t, tt.statuses...)
+
+			exists, err := client.ValidateUser(context.Background(), "7")
+			if (err != nil) != tt.wantErr || exists != tt.want {
+				t.Fatalf("expected exists=%v err=%v, got %v and %v", tt.want, tt.wantErr, exists, err)
+			}
+			if *calls != tt.wantCalls {
+				t.Fatalf("expected %d calls to users-api, got %d", tt.wantCalls, *calls)
+			}
+		})
+	}
+}
+
+func TestUsersClient_OpenCircuitSkipsUsersAPI(t *testing.T) {
+	client, calls := newTestUsersClient(t, http.StatusInternalServerError)
+
+	// Los 3 intentos fallidos abren el circuito
+	client.ValidateUser(context.Background(), "7")
+	if _, err := client.ValidateUser(context.Background(), "7"); err == nil {
+		t.Fatal("expected an error with the circuit open")
+	}
+	if *calls != 3 {
+		t.Fatalf("expected no call to users-api with the circuit open, got %d calls", *calls)
+	}
+}
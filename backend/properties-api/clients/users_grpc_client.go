@@ -0,0 +1,50 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"properties-api/rpc"
+	"resilience"
+
+	"google.golang.org/grpc"
+)
+
+// usersGRPCClient implementa UsersClient llamando al servidor gRPC de users-api
+// Reemplaza al GET /users/{id} por request: la conexión HTTP/2 se reutiliza entre llamadas
+// Las llamadas pasan por el mismo circuit breaker y reintentos que el cliente HTTP
+type usersGRPCClient struct {
+	stub    rpc.UsersServiceClient
+	breaker *resilience.CircuitBreaker
+	retry   resilience.RetryPolicy
+}
+
+// NewUsersGRPCClient crea el cliente de usuarios sobre una conexión gRPC (ver NewGRPCConn)
+func NewUsersGRPCClient(conn grpc.ClientConnInterface) UsersClient {
+	return &usersGRPCClient{
+		stub:    rpc.NewUsersServiceClient(conn),
+		breaker: resilience.NewCircuitBreaker("users-api", resilience.DefaultCircuitBreakerSettings()),
+		retry:   resilience.DefaultRetryPolicy(),
+	}
+}
+
+// ValidateUser valida si un usuario existe en users-api
+// Los IDs de users-api son numéricos: un ID inválido es un error, igual que el 400 de la API REST
+func (c *usersGRPCClient) ValidateUser(ctx context.Context, userID string) (bool, error) {
+	id, err := strconv.ParseUint(userID, 10, 32)
+	if err != nil || id == 0 {
+		return false, fmt.Errorf("ID de usuario inválido: '%s'", userID)
+	}
+
+	var exists bool
+	err = resilience.CallWithResilience(ctx, c.breaker, c.retry, func(ctx context.Context) error {
+		response, err := c.stub.ValidateUser(ctx, &rpc.ValidateUserRequest{UserID: uint(id)})
+		if err != nil {
+			return grpcCallError("users-api", err)
+		}
+		exists = response.Exists
+		return nil
+	})
+	return exists, err
+}
@@ -0,0 +1,108 @@
+package clients
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"properties-api/rpc"
+	"resilience"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// scriptedUsersStub responde ValidateUser con los códigos indicados, uno por llamada (el último se repite)
+type scriptedUsersStub struct {
+	rpc.UsersServiceClient
+	codes []codes.Code
+	calls int
+}
+
+func (s *scriptedUsersStub) ValidateUser(ctx context.Context, request *rpc.ValidateUserRequest, opts ...grpc.CallOption) (*rpc.ValidateUserResponse, error) {
+	code := s.codes[len(s.codes)-1]
+	if s.calls < len(s.codes) {
+		code = s.codes[s.calls]
+	}
+	s.calls++
+	if code != codes.OK {
+		return nil, status.Error(code, code.String())
+	}
+	return &rpc.ValidateUserResponse{Exists: request.UserID == 7, Active: true}, nil
+}
+
+// newTestUsersGRPCClient usa reintentos rápidos y un circuit breaker propio del test
+func newTestUsersGRPCClient(t *testing.T, responses ...codes.Code) (UsersClient, *scriptedUsersStub) {
+	stub := &scriptedUsersStub{codes: responses}
+	return &usersGRPCClient{
+		stub:    stub,
+		breaker: resilience.NewCircuitBreaker(t.Name(), resilience.CircuitBreakerSettings{FailureThreshold: 3, OpenTimeout: time.Hour}),
+		retry:   resilience.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond},
+	}, stub
+}
+
+func TestUsersGRPCClient_ValidateUser(t *testing.T) {
+	tests := []struct {
+		name      string
+		userID    string
+		codes     []codes.Code
+		want      bool
+		wantErr   bool
+		wantCalls int
+	}{
+		{name: "existing user", userID: "7", codes: []codes.Code{codes.OK}, want: true, wantCalls: 1},
+		{name: "missing user is not an error", userID: "8", codes: []codes.Code{codes.OK}, wantCalls: 1},
+		{name: "unavailable is retried", userID: "7", codes: []codes.Code{codes.Unavailable, codes.DeadlineExceeded, codes.OK}, want: true, wantCalls: 3},
+		{name: "invalid argument is not retried", userID: "7", codes: []codes.Code{codes.InvalidArgument, codes.OK}, wantErr: true, wantCalls: 1},
+		{name: "unauthenticated is not retried", userID: "7", codes: []codes.Code{codes.Unauthenticated, codes.OK}, wantErr: true, wantCalls: 1},
+		{name: "gives up after the retries", userID: "7", codes: []codes.Code{codes.Unavailable}, wantErr: true, wantCalls: 3},
+		{name: "non numeric id never reaches users-api", userID: "abc", codes: []codes.Code{codes.OK}, wantErr: true},
+		{name: "zero id never reaches users-api", userID: "0", codes: []codes.Code{codes.OK}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, stub := newTestUsersGRPCClient(t, tt.codes...)
+
+			exists, err := client.ValidateUser(context.Background(), tt.userID)
+			if (err != nil) != tt.wantErr || exists != tt.want {
+				t.Fatalf("expected exists=%v err=%v, got %v and %v", tt.want, tt.wantErr, exists, err)
+			}
+			if stub.calls != tt.wantCalls {
+				t.Fatalf("expected %d calls to users-api, got %d", tt.wantCalls, stub.calls)
+			}
+		})
+	}
+}
+
+func TestUsersGRPCClient_RejectedCallsDoNotOpenTheCircuit(t *testing.T) {
+	// Un InvalidArgument es un error del request, no una falla de users-api
+	client, stub := newTestUsersGRPCClient(t, codes.InvalidArgument)
+	for i := 0; i < 5; i++ {
+		client.ValidateUser(context.Background(), "7")
+	}
+	if stub.calls != 5 {
+		t.Fatalf("expected every call to reach users-api, got %d of 5", stub.calls)
+	}
+}
+
+func TestUsersGRPCClient_CancelledCallerDoesNotOpenTheCircuit(t *testing.T) {
+	// El request venció mientras se esperaba a users-api: el DeadlineExceeded es del caller, no de users-api
+	client, stub := newTestUsersGRPCClient(t, codes.DeadlineExceeded, codes.DeadlineExceeded, codes.DeadlineExceeded, codes.OK)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.ValidateUser(ctx, "7"); err == nil {
+			t.Fatal("expected an error for a cancelled caller")
+		}
+	}
+	if stub.calls != 3 {
+		t.Fatalf("expected no retries for a cancelled caller, got %d calls", stub.calls)
+	}
+
+	exists, err := client.ValidateUser(context.Background(), "7")
+	if err != nil || !exists {
+		t.Fatalf("expected the circuit still closed, got %v and %v", exists, err)
+	}
+}
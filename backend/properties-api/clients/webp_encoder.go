@@ -0,0 +1,72 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// WebPEncoder codifica imágenes en WebP
+type WebPEncoder interface {
+	// Encode codifica la imagen con la calidad indicada (1-100)
+	Encode(ctx context.Context, img image.Image, quality int) ([]byte, error)
+}
+
+// cwebpEncoder codifica con el binario cwebp de libwebp (la librería estándar de Go solo decodifica WebP)
+type cwebpEncoder struct {
+	path string
+}
+
+// NewCWebPEncoder crea el encoder sobre el binario cwebp
+// Retorna nil si path está vacío o el binario no está instalado: las fotos se procesan solo en JPEG
+func NewCWebPEncoder(path string) WebPEncoder {
+	if strings.TrimSpace(path) == "" {
+		return nil
+	}
+	resolved, err := exec.LookPath(path)
+	if err != nil {
+		return nil
+	}
+	return &cwebpEncoder{path: resolved}
+}
+
+// Encode pasa la imagen a cwebp como PNG (sin pérdida) en un directorio temporal y lee el WebP generado
+func (e *cwebpEncoder) Encode(ctx context.Context, img image.Image, quality int) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "cwebp-")
+	if err != nil {
+		return nil, fmt.Errorf("error creando directorio temporal para WebP: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "input.png")
+	output := filepath.Join(dir, "output.webp")
+
+	file, err := os.Create(input)
+	if err != nil {
+		return nil, fmt.Errorf("error creando imagen temporal para WebP: %w", err)
+	}
+	if err := png.Encode(file, img); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("error codificando imagen temporal para WebP: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return nil, fmt.Errorf("error escribiendo imagen temporal para WebP: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, e.path, "-quiet", "-q", strconv.Itoa(quality), input, "-o", output)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("error ejecutando cwebp: %w (%s)", err, strings.TrimSpace(string(out)))
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo WebP generado: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"properties-api/services"
+)
+
+// Owners y huéspedes de demo que crea "users-api seed" (IDs fijos 1001 a 1010 y 1011 a 1030)
+const (
+	seedFirstOwnerID = 1001
+	seedOwnerCount   = 10
+	seedGuestCount   = 20
+)
+
+// runSeedCommand carga propiedades y reservas de demo con los servicios ya inicializados
+// Uso: properties-api seed [-properties 200] [-bookings 150] [-owners 1001,1002] [-guests 1011,1012]
+func runSeedCommand(seedService services.SeedService, args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	properties := flags.Int("properties", 200, "cantidad total de propiedades de demo")
+	bookings := flags.Int("bookings", 150, "reservas a crear sobre las propiedades nuevas")
+	owners := flags.String("owners", seedIDRange(seedFirstOwnerID, seedOwnerCount), "IDs de los anfitriones separados por coma")
+	guests := flags.String("guests", seedIDRange(seedFirstOwnerID+seedOwnerCount, seedGuestCount), "IDs de los huéspedes separados por coma")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	report, err := seedService.Seed(context.Background(), services.SeedOptions{
+		Properties: *properties,
+		Bookings:   *bookings,
+		OwnerIDs:   splitIDs(*owners),
+		GuestIDs:   splitIDs(*guests),
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("🌱 Propiedades de demo: %d creadas, %d ya existían, %d con error", report.Properties, report.Existing, report.FailedProperties)
+	log.Printf("🌱 Reservas de demo: %d creadas, %d rechazadas (fechas superpuestas o capacidad)", report.Bookings, report.FailedBookings)
+	return nil
+}
+
+// seedIDRange arma la lista "first,first+1,..." de count IDs
+func seedIDRange(first, count int) string {
+	ids := make([]string, count)
+	for i := range ids {
+		ids[i] = strconv.Itoa(first + i)
+	}
+	return strings.Join(ids, ",")
+}
+
+// splitIDs separa una lista de IDs por coma ignorando los vacíos
+func splitIDs(value string) []string {
+	var ids []string
+	for _, id := range strings.Split(value, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// runCommand ejecuta un comando de administración en lugar de levantar el servidor
+func runCommand(seedService services.SeedService, args []string) error {
+	switch args[0] {
+	case "seed":
+		return runSeedCommand(seedService, args[1:])
+	default:
+		return fmt.Errorf("comando desconocido '%s' (disponibles: seed)", args[0])
+	}
+}
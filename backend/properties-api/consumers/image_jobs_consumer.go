@@ -0,0 +1,115 @@
+package consumers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"properties-api/clients"
+	"properties-api/services"
+
+	"github.com/streadway/amqp"
+)
+
+// ImageJobsConsumer es el worker que genera las variantes de las fotos subidas
+type ImageJobsConsumer struct {
+	conn    *amqp.Connection
+	channel *amqp.Channel
+	queue   string
+	images  services.ImageService
+}
+
+// NewImageJobsConsumer se conecta a RabbitMQ, declara la cola de jobs de imágenes
+// y la bindea al exchange de propiedades con la routing key image.process
+func NewImageJobsConsumer(url, exchange, queue string, images services.ImageService) (*ImageJobsConsumer, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, fmt.Errorf("error conectando a RabbitMQ en %s: %w", url, err)
+	}
+
+	channel, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error abriendo canal de RabbitMQ: %w", err)
+	}
+
+	// Mismos parámetros que el publisher (topic, durable)
+	if err := channel.ExchangeDeclare(exchange, "topic", true, false, false, false, nil); err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("error declarando exchange '%s' en RabbitMQ: %w", exchange, err)
+	}
+
+	if _, err := channel.QueueDeclare(queue, true, false, false, false, nil); err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("error declarando queue '%s' en RabbitMQ: %w", queue, err)
+	}
+
+	if err := channel.QueueBind(queue, clients.ImageJobRoutingKey, exchange, false, nil); err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("error bindeando queue '%s' al exchange '%s': %w", queue, exchange, err)
+	}
+
+	return &ImageJobsConsumer{
+		conn:    conn,
+		channel: channel,
+		queue:   queue,
+		images:  images,
+	}, nil
+}
+
+// Start registra el consumidor y procesa los jobs en una goroutine, de a uno por vez
+func (c *ImageJobsConsumer) Start() error {
+	if err := c.channel.Qos(1, 0, false); err != nil {
+		return fmt.Errorf("error configurando QoS: %w", err)
+	}
+
+	msgs, err := c.channel.Consume(c.queue, "", false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("error registrando consumidor: %w", err)
+	}
+
+	go func() {
+		for msg := range msgs {
+			c.processMessage(msg)
+		}
+	}()
+
+	log.Printf("✅ Worker de imágenes escuchando en '%s'", c.queue)
+	return nil
+}
+
+// processMessage procesa un job de imagen
+// Una foto que no se pudo procesar queda "failed", así que se hace ACK y no se reintenta
+func (c *ImageJobsConsumer) processMessage(msg amqp.Delivery) {
+	var job clients.ImageJob
+	if err := json.Unmarshal(msg.Body, &job); err != nil || job.PropertyID == "" || job.StorageKey == "" {
+		log.Printf("❌ Job de imagen inválido: %v. Body: %s", err, string(msg.Body))
+		msg.Nack(false, false)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	if err := c.images.ProcessJob(ctx, job); err != nil {
+		log.Printf("❌ %v", err)
+	} else {
+		log.Printf("✅ Variantes generadas para la foto %s de la propiedad %s", job.PhotoURL, job.PropertyID)
+	}
+
+	msg.Ack(false)
+}
+
+// Close cierra el canal y la conexión
+func (c *ImageJobsConsumer) Close() error {
+	if err := c.channel.Close(); err != nil {
+		c.conn.Close()
+		return fmt.Errorf("error cerrando canal de RabbitMQ: %w", err)
+	}
+	return c.conn.Close()
+}
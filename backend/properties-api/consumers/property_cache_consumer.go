@@ -0,0 +1,115 @@
+package consumers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"properties-api/clients"
+	"properties-api/repositories"
+
+	"github.com/streadway/amqp"
+)
+
+// propertyCacheRoutingKeys son los eventos que invalidan una propiedad cacheada
+// property.created también: una propiedad aprobada por moderación se publica con ese evento
+// y la versión cacheada (sin publicar) respondería 404
+var propertyCacheRoutingKeys = []string{"property.created", "property.updated", "property.deleted"}
+
+// PropertyCacheConsumer invalida el caché de propiedades con los eventos del exchange de propiedades
+// El repositorio con caché ya invalida en cada escritura; esta es una segunda invalidación, con demora,
+// que cubre una invalidación perdida (Memcached caído) y la carrera con un GetByID concurrente
+// La cola es compartida entre las instancias: el caché también, así que alcanza con que una lo invalide
+type PropertyCacheConsumer struct {
+	conn    *amqp.Connection
+	channel *amqp.Channel
+	queue   string
+	cache   repositories.PropertyCacheRepository
+}
+
+// NewPropertyCacheConsumer se conecta a RabbitMQ, declara la cola de invalidación
+// y la bindea al exchange de propiedades con las routing keys de alta, cambio y baja
+func NewPropertyCacheConsumer(url, exchange, queue string, cache repositories.PropertyCacheRepository) (*PropertyCacheConsumer, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, fmt.Errorf("error conectando a RabbitMQ en %s: %w", url, err)
+	}
+
+	channel, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error abriendo canal de RabbitMQ: %w", err)
+	}
+
+	// Mismos parámetros que el publisher (topic, durable)
+	if err := channel.ExchangeDeclare(exchange, "topic", true, false, false, false, nil); err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("error declarando exchange '%s' en RabbitMQ: %w", exchange, err)
+	}
+
+	if _, err := channel.QueueDeclare(queue, true, false, false, false, nil); err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("error declarando queue '%s' en RabbitMQ: %w", queue, err)
+	}
+
+	for _, routingKey := range propertyCacheRoutingKeys {
+		if err := channel.QueueBind(queue, routingKey, exchange, false, nil); err != nil {
+			channel.Close()
+			conn.Close()
+			return nil, fmt.Errorf("error bindeando queue '%s' al exchange '%s' (%s): %w", queue, exchange, routingKey, err)
+		}
+	}
+
+	return &PropertyCacheConsumer{
+		conn:    conn,
+		channel: channel,
+		queue:   queue,
+		cache:   cache,
+	}, nil
+}
+
+// Start registra el consumidor y procesa los eventos en una goroutine
+func (c *PropertyCacheConsumer) Start() error {
+	if err := c.channel.Qos(50, 0, false); err != nil {
+		return fmt.Errorf("error configurando QoS: %w", err)
+	}
+
+	msgs, err := c.channel.Consume(c.queue, "", false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("error registrando consumidor: %w", err)
+	}
+
+	go func() {
+		for msg := range msgs {
+			c.processMessage(msg)
+		}
+	}()
+
+	log.Printf("✅ Invalidación del caché de propiedades escuchando en '%s'", c.queue)
+	return nil
+}
+
+// processMessage invalida la propiedad del evento
+// Si Memcached falla no se reintenta: el TTL termina de vencer la entrada
+func (c *PropertyCacheConsumer) processMessage(msg amqp.Delivery) {
+	var event clients.PropertyEvent
+	if err := json.Unmarshal(msg.Body, &event); err != nil || event.PropertyID == "" {
+		log.Printf("❌ Evento de propiedad inválido para el caché: %v. Body: %s", err, string(msg.Body))
+		msg.Nack(false, false)
+		return
+	}
+
+	c.cache.Delete(event.PropertyID)
+	msg.Ack(false)
+}
+
+// Close cierra el canal y la conexión
+func (c *PropertyCacheConsumer) Close() error {
+	if err := c.channel.Close(); err != nil {
+		c.conn.Close()
+		return fmt.Errorf("error cerrando canal de RabbitMQ: %w", err)
+	}
+	return c.conn.Close()
+}
@@ -0,0 +1,138 @@
+package consumers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"properties-api/services"
+
+	"github.com/streadway/amqp"
+)
+
+// DefaultUserExchange es el topic exchange donde users-api publica los eventos de usuarios
+const DefaultUserExchange = "users_exchange"
+
+// DefaultUserEventsQueue es la cola propia de properties-api para los eventos de usuarios
+const DefaultUserEventsQueue = "properties_user_events"
+
+// userErasedRoutingKey es el evento que indica que un usuario pidió borrar sus datos (GDPR)
+const userErasedRoutingKey = "user.erased"
+
+// UserEvent representa un evento de usuario publicado por users-api
+type UserEvent struct {
+	// Type es el tipo de evento (ej: "user.erased")
+	Type string `json:"type"`
+
+	// UserID es el identificador del usuario
+	UserID uint `json:"userId"`
+}
+
+// UserEventsConsumer consume los eventos de users-api que afectan a los datos de properties-api
+type UserEventsConsumer struct {
+	conn    *amqp.Connection
+	channel *amqp.Channel
+	queue   string
+	privacy services.PrivacyService
+}
+
+// NewUserEventsConsumer se conecta a RabbitMQ, declara el exchange de usuarios y la cola,
+// y bindea la cola a los eventos que le interesan a properties-api
+func NewUserEventsConsumer(url, exchange, queue string, privacy services.PrivacyService) (*UserEventsConsumer, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, fmt.Errorf("error conectando a RabbitMQ en %s: %w", url, err)
+	}
+
+	channel, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error abriendo canal de RabbitMQ: %w", err)
+	}
+
+	// Mismos parámetros que users-api (topic, durable)
+	if err := channel.ExchangeDeclare(exchange, "topic", true, false, false, false, nil); err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("error declarando exchange '%s' en RabbitMQ: %w", exchange, err)
+	}
+
+	if _, err := channel.QueueDeclare(queue, true, false, false, false, nil); err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("error declarando queue '%s' en RabbitMQ: %w", queue, err)
+	}
+
+	if err := channel.QueueBind(queue, userErasedRoutingKey, exchange, false, nil); err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("error bindeando queue '%s' al exchange '%s': %w", queue, exchange, err)
+	}
+
+	return &UserEventsConsumer{
+		conn:    conn,
+		channel: channel,
+		queue:   queue,
+		privacy: privacy,
+	}, nil
+}
+
+// Start registra el consumidor y procesa los mensajes en una goroutine
+func (c *UserEventsConsumer) Start() error {
+	if err := c.channel.Qos(1, 0, false); err != nil {
+		return fmt.Errorf("error configurando QoS: %w", err)
+	}
+
+	msgs, err := c.channel.Consume(c.queue, "", false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("error registrando consumidor: %w", err)
+	}
+
+	go func() {
+		for msg := range msgs {
+			c.processMessage(msg)
+		}
+	}()
+
+	log.Printf("✅ Consumidor de eventos de usuarios escuchando en '%s'", c.queue)
+	return nil
+}
+
+// processMessage procesa un evento de usuario
+// Los errores se loguean y se hace ACK para no reintentar infinitamente
+func (c *UserEventsConsumer) processMessage(msg amqp.Delivery) {
+	var event UserEvent
+	if err := json.Unmarshal(msg.Body, &event); err != nil || event.UserID == 0 {
+		log.Printf("❌ Evento de usuario inválido: %v. Body: %s", err, string(msg.Body))
+		msg.Nack(false, false)
+		return
+	}
+
+	if msg.RoutingKey != userErasedRoutingKey {
+		log.Printf("⚠️ Evento de usuario desconocido: %s. Ignorando mensaje.", msg.RoutingKey)
+		msg.Ack(false)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	userID := strconv.FormatUint(uint64(event.UserID), 10)
+	if err := c.privacy.EraseUserData(ctx, userID); err != nil {
+		log.Printf("❌ Error borrando datos del usuario %s: %v", userID, err)
+	}
+
+	msg.Ack(false)
+}
+
+// Close cierra el canal y la conexión
+func (c *UserEventsConsumer) Close() error {
+	if err := c.channel.Close(); err != nil {
+		c.conn.Close()
+		return fmt.Errorf("error cerrando canal de RabbitMQ: %w", err)
+	}
+	return c.conn.Close()
+}
@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"net/http"
+
+	"properties-api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultAnalyticsDays y maxAnalyticsDays limitan el período del panel de analytics
+const (
+	defaultAnalyticsDays = 30
+	maxAnalyticsDays     = 365
+)
+
+type AnalyticsController struct {
+	service services.AnalyticsService
+}
+
+func NewAnalyticsController(service services.AnalyticsService) *AnalyticsController {
+	return &AnalyticsController{
+		service: service,
+	}
+}
+
+// GetDashboard maneja los KPIs del panel de admin (solo admin)
+// Query param opcional: days (últimos N días UTC incluyendo hoy, default 30)
+// Los KPIs salen de la agregación periódica: no se consultan users-api ni search-api en el request
+func (c *AnalyticsController) GetDashboard(ctx *gin.Context) {
+	days, err := boundedQueryInt(ctx, "days", defaultAnalyticsDays, maxAnalyticsDays)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dashboard, err := c.service.GetDashboard(ctx.Request.Context(), days)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dashboard)
+}
@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"properties-api/dto"
+	"properties-api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditController struct {
+	service services.AuditService
+}
+
+func NewAuditController(service services.AuditService) *AuditController {
+	return &AuditController{
+		service: service,
+	}
+}
+
+// List consulta el log de auditoría (solo admin)
+// Query params opcionales: userId (quién hizo la operación, "system" para procesos internos),
+// from, to, page y limit (máx 100)
+func (c *AuditController) List(ctx *gin.Context) {
+	var query dto.AuditQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries, err := c.service.List(ctx.Request.Context(), query)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidDateRange) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entries)
+}
@@ -0,0 +1,215 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"properties-api/dto"
+	"properties-api/services"
+	"properties-api/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BookingController struct {
+	service services.BookingService
+}
+
+func NewBookingController(service services.BookingService) *BookingController {
+	return &BookingController{
+		service: service,
+	}
+}
+
+// GetOwnerBookings maneja el listado de reservas de las propiedades del usuario autenticado
+// Soporta JSON, NDJSON y CSV según ?format= o el header Accept
+func (c *BookingController) GetOwnerBookings(ctx *gin.Context) {
+	ownerID, ok := userIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	encoder := utils.NewStreamEncoder(ctx, utils.NegotiateFormat(ctx), "bookings", dto.BookingDTO{}.CSVHeader())
+	err := c.service.StreamOwnerBookings(ctx.Request.Context(), ownerID, func(booking dto.BookingDTO) error {
+		return encoder.Encode(booking)
+	})
+	if err != nil {
+		encoder.Fail(err)
+		return
+	}
+
+	if err := encoder.Close(); err != nil {
+		encoder.Fail(err)
+	}
+}
+
+// Quote maneja la cotización de una estadía con el detalle del precio
+// GET /api/properties/:id/quote?checkIn=YYYY-MM-DD&checkOut=YYYY-MM-DD&adults=N&children=N&infants=N&coupon=CODIGO
+// Sin adults/children/infants se usa guests (por defecto 1) como cantidad de adultos; coupon es opcional
+func (c *BookingController) Quote(ctx *gin.Context) {
+	counts := map[string]int{"guests": 1}
+	for _, name := range []string{"guests", "adults", "children", "infants"} {
+		raw := ctx.Query(name)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": name + " debe ser un entero"})
+			return
+		}
+		counts[name] = value
+	}
+	party := dto.BookingCreateDTO{
+		Guests:   counts["guests"],
+		Adults:   counts["adults"],
+		Children: counts["children"],
+		Infants:  counts["infants"],
+	}.Party()
+
+	quote, err := c.service.QuoteBooking(ctx.Request.Context(), ctx.Param("id"), ctx.Query("checkIn"), ctx.Query("checkOut"), party, ctx.Query("coupon"))
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, quote)
+}
+
+// CreateBooking maneja la reserva de una propiedad para el usuario autenticado
+// El precio se cotiza en el momento y el detalle queda guardado en la reserva
+func (c *BookingController) CreateBooking(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	var request dto.BookingCreateDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	booking, err := c.service.CreateBooking(ctx.Request.Context(), userID, request)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, booking)
+}
+
+// HoldBooking maneja el hold de fechas al empezar el checkout
+// POST /api/bookings/hold con el mismo body que POST /api/bookings; responde el hold con su vencimiento
+func (c *BookingController) HoldBooking(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	var request dto.BookingCreateDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hold, err := c.service.HoldBooking(ctx.Request.Context(), userID, request)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, hold)
+}
+
+// ReleaseHold maneja la liberación de un hold antes de que venza (ej: el huésped abandonó el checkout)
+// DELETE /api/bookings/hold/:id
+func (c *BookingController) ReleaseHold(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	if err := c.service.ReleaseHold(ctx.Request.Context(), ctx.Param("id"), userID); err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Hold liberado exitosamente"})
+}
+
+// ApproveBooking maneja la aprobación de una solicitud de reserva por el anfitrión (o un admin)
+// POST /api/bookings/:id/approve
+func (c *BookingController) ApproveBooking(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	booking, err := c.service.ApproveBooking(ctx.Request.Context(), ctx.Param("id"), userID, ctx.GetBool("isAdmin"))
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, booking)
+}
+
+// DeclineBooking maneja el rechazo de una solicitud de reserva por el anfitrión (o un admin)
+// POST /api/bookings/:id/decline
+func (c *BookingController) DeclineBooking(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	booking, err := c.service.DeclineBooking(ctx.Request.Context(), ctx.Param("id"), userID, ctx.GetBool("isAdmin"))
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, booking)
+}
+
+// respondError traduce los errores del servicio de reservas a status HTTP
+func (c *BookingController) respondError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidStay), errors.Is(err, services.ErrTooManyGuests):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCouponNotApplicable), errors.Is(err, services.ErrCouponExhausted):
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCannotBookOwnProperty), errors.Is(err, services.ErrBookingForbidden):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrPropertyUnavailable), errors.Is(err, services.ErrBookingNotPending),
+		errors.Is(err, services.ErrBookingRequestExpired), errors.Is(err, services.ErrBookingHoldExpired):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	}
+}
+
+// userIDFromContext obtiene el userID agregado por el middleware de autenticación como string
+func userIDFromContext(ctx *gin.Context) (string, bool) {
+	userIDValue, exists := ctx.Get("userID")
+	if !exists {
+		return "", false
+	}
+
+	switch v := userIDValue.(type) {
+	case uint:
+		return fmt.Sprintf("%d", v), true
+	case string:
+		return v, v != ""
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"properties-api/dto"
+	"properties-api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CalendarController struct {
+	service services.CalendarService
+}
+
+func NewCalendarController(service services.CalendarService) *CalendarController {
+	return &CalendarController{
+		service: service,
+	}
+}
+
+// ExportICS maneja la exportación del calendario de la propiedad en formato iCal
+// Es público para que Airbnb, Booking, etc. puedan suscribirse a la URL
+func (c *CalendarController) ExportICS(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	// Se arma en memoria para poder responder JSON si falla antes de terminar
+	var calendar bytes.Buffer
+	if err := c.service.ExportCalendar(ctx.Request.Context(), id, &calendar); err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("inline; filename=\"%s.ics\"", id))
+	ctx.Data(http.StatusOK, "text/calendar; charset=utf-8", calendar.Bytes())
+}
+
+// ImportFeed maneja el registro de un calendario iCal externo de la propiedad (solo owner o admin)
+func (c *CalendarController) ImportFeed(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	var request dto.CalendarFeedImportDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	feed, err := c.service.ImportFeed(ctx.Request.Context(), ctx.Param("id"), userID, ctx.GetBool("isAdmin"), request)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, feed)
+}
+
+// ListFeeds maneja el listado de los calendarios externos de la propiedad (solo owner o admin)
+func (c *CalendarController) ListFeeds(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	feeds, err := c.service.ListFeeds(ctx.Request.Context(), ctx.Param("id"), userID, ctx.GetBool("isAdmin"))
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, feeds)
+}
+
+// DeleteFeed maneja la eliminación de un calendario externo y sus bloqueos (solo owner o admin)
+func (c *CalendarController) DeleteFeed(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	err := c.service.DeleteFeed(ctx.Request.Context(), ctx.Param("id"), ctx.Param("feedId"), userID, ctx.GetBool("isAdmin"))
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Calendario eliminado exitosamente"})
+}
+
+// BulkUpdateAvailability maneja el bloqueo o la apertura de varios rangos de fechas de una vez (solo owner o admin)
+// Responde con la disponibilidad resultante (reservas, bloqueos importados y bloqueos manuales)
+func (c *CalendarController) BulkUpdateAvailability(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	var request dto.BulkAvailabilityUpdateDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	availability, err := c.service.BulkUpdateAvailability(ctx.Request.Context(), ctx.Param("id"), userID, ctx.GetBool("isAdmin"), request)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, availability)
+}
+
+// respondError traduce los errores del servicio de calendarios a status HTTP
+func (c *CalendarController) respondError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrCalendarForbidden):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidCalendarURL), errors.Is(err, services.ErrInvalidAvailabilityRange):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAvailabilityConflict):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	}
+}
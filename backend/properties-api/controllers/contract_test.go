@@ -0,0 +1,324 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"properties-api/clients"
+	"properties-api/dto"
+	"properties-api/rpc"
+	"properties-api/services"
+)
+
+// ============================================
+// Contratos consumer-driven (backend/contracts)
+// ============================================
+// Cada consumidor deja en backend/contracts/<consumer>/<provider> los requests que manda y los campos
+// que lee. Como proveedor, properties-api verifica que acepta los requests de search-api y que sus
+// respuestas y eventos traen todos esos campos con los mismos tipos JSON. Como consumidor de users-api,
+// verifica que su contrato describe exactamente lo que manda y lee
+
+// contractsDir es backend/contracts relativo al paquete
+const contractsDir = "../../contracts"
+
+// contract es un contrato consumer-driven: el request que manda el consumidor y la respuesta que espera,
+// o el mensaje que espera recibir por RabbitMQ
+type contract struct {
+	Consumer    string          `json:"consumer"`
+	Provider    string          `json:"provider"`
+	Description string          `json:"description"`
+	Request     json.RawMessage `json:"request"`
+	Response    json.RawMessage `json:"response"`
+	Message     json.RawMessage `json:"message"`
+}
+
+func loadContract(t *testing.T, name string) contract {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(contractsDir, name))
+	if err != nil {
+		t.Fatalf("failed to read contract %s: %v", name, err)
+	}
+	var c contract
+	if err := json.Unmarshal(data, &c); err != nil {
+		t.Fatalf("failed to decode contract %s: %v", name, err)
+	}
+	return c
+}
+
+// decodeStrict decodifica un cuerpo del contrato fallando si tiene campos que el struct no conoce
+func decodeStrict(t *testing.T, body json.RawMessage, out interface{}) {
+	t.Helper()
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(out); err != nil {
+		t.Fatalf("contract body %s does not decode into %T: %v", body, out, err)
+	}
+}
+
+// assertShape verifica que got tenga todos los campos de want con el mismo tipo JSON
+// Los valores no se comparan: el contrato fija la forma, no los datos
+func assertShape(t *testing.T, want json.RawMessage, got interface{}) {
+	t.Helper()
+	data, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("failed to encode %T: %v", got, err)
+	}
+	var wantValue, gotValue interface{}
+	if err := json.Unmarshal(want, &wantValue); err != nil {
+		t.Fatalf("failed to decode contract body: %v", err)
+	}
+	if err := json.Unmarshal(data, &gotValue); err != nil {
+		t.Fatalf("failed to decode %T: %v", got, err)
+	}
+	for _, problem := range compareShape("$", wantValue, gotValue) {
+		t.Error(problem)
+	}
+}
+
+// compareShape compara recursivamente la forma de dos valores JSON decodificados
+// Un null en el contrato acepta cualquier valor; en los arrays se compara cada elemento contra el primero del contrato
+func compareShape(path string, want, got interface{}) []string {
+	if want == nil {
+		return nil
+	}
+	if jsonKind(want) != jsonKind(got) {
+		return []string{fmt.Sprintf("%s: expected %s, got %s", path, jsonKind(want), jsonKind(got))}
+	}
+
+	var problems []string
+	switch want := want.(type) {
+	case map[string]interface{}:
+		got := got.(map[string]interface{})
+		keys := make([]string, 0, len(want))
+		for key := range want {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value, ok := got[key]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s.%s: missing field", path, key))
+				continue
+			}
+			problems = append(problems, compareShape(path+"."+key, want[key], value)...)
+		}
+	case []interface{}:
+		got := got.([]interface{})
+		if len(want) > 0 && len(got) == 0 {
+			return []string{fmt.Sprintf("%s: empty array, elements cannot be verified", path)}
+		}
+		for i, value := range got {
+			if len(want) > 0 {
+				problems = append(problems, compareShape(fmt.Sprintf("%s[%d]", path, i), want[0], value)...)
+			}
+		}
+	}
+	return problems
+}
+
+// jsonKind retorna el tipo JSON de un valor decodificado con encoding/json
+func jsonKind(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// contractProperty es una propiedad con todos los campos informados, así ninguno se omite por omitempty
+func contractProperty(id string) dto.PropertyResponseDTO {
+	return dto.PropertyResponseDTO{
+		ID:                 id,
+		Title:              "Cabaña acogedora en Llao Llao",
+		Description:        "Cabaña para 4 huéspedes a metros del lago.",
+		Price:              70000,
+		CleaningFee:        5000,
+		Location:           "Bariloche, Argentina",
+		PropertyType:       "cabaña",
+		Latitude:           -41.1335,
+		Longitude:          -71.3103,
+		Timezone:           "America/Argentina/Salta",
+		Country:            "AR",
+		Region:             "R",
+		OwnerID:            "1001",
+		Amenities:          []string{"wifi", "heating"},
+		Capacity:           4,
+		Available:          true,
+		BookingMode:        "instant",
+		Images:             []dto.PhotoDTO{{URL: "https://cdn.spotly.local/1.jpg", Caption: "Vista al lago", IsCover: true, Status: "ready"}},
+		CoverImage:         "https://cdn.spotly.local/1.jpg",
+		CoverThumbnail:     "https://cdn.spotly.local/1_thumbnail.jpg",
+		CoverThumbnailWebP: "https://cdn.spotly.local/1_thumbnail.webp",
+		Views:              12,
+		BookedRanges:       []dto.DateRangeDTO{{Start: "2024-06-10", End: "2024-06-14"}},
+		NextAvailableDate:  "2024-05-25",
+		Language:           "es",
+		Translations: map[string]dto.PropertyTranslationDTO{
+			"en": {Title: "Apartment downtown", Description: "Bright apartment two blocks from the main square"},
+		},
+		CreatedAt:          "2024-05-24T18:30:00Z",
+		UpdatedAt:          "2024-05-25T09:15:00Z",
+		GuestsIncluded:     2,
+		ExtraGuestFee:      7000,
+		ChildFriendly:      true,
+		CancellationPolicy: "moderate",
+		HouseRules:         dto.HouseRulesDTO{PetsAllowed: true, QuietHoursStart: "23:00", QuietHoursEnd: "08:00"},
+	}
+}
+
+// contractPropertyService responde las lecturas de los contratos con propiedades fijas; el resto de PropertyService no se usa
+type contractPropertyService struct {
+	services.PropertyService
+	properties map[string]dto.PropertyResponseDTO
+}
+
+func (s contractPropertyService) GetPropertyByID(ctx context.Context, id string) (dto.PropertyResponseDTO, error) {
+	property, ok := s.properties[id]
+	if !ok {
+		return dto.PropertyResponseDTO{}, errors.New("propiedad no encontrada")
+	}
+	return property, nil
+}
+
+func (s contractPropertyService) ListPropertyVersions(ctx context.Context, afterID string, limit int) ([]dto.PropertyVersionDTO, error) {
+	versions := make([]dto.PropertyVersionDTO, 0, limit)
+	for i := 0; i < limit; i++ {
+		versions = append(versions, dto.PropertyVersionDTO{ID: fmt.Sprintf("%s-%d", afterID, i), OwnerID: "1001", UpdatedAt: "2024-05-25T09:15:00Z"})
+	}
+	return versions, nil
+}
+
+// ============================================
+// TESTS - properties-api como proveedor de search-api
+// ============================================
+
+func TestContract_SearchAPI_PropertyEvent(t *testing.T) {
+	c := loadContract(t, "search-api/properties-api/property_event.json")
+
+	var expected struct {
+		Operation     string `json:"operation"`
+		SchemaVersion int    `json:"schemaVersion"`
+	}
+	if err := json.Unmarshal(c.Message, &expected); err != nil {
+		t.Fatalf("failed to decode contract message: %v", err)
+	}
+
+	event := clients.NewPropertySnapshotEvent(expected.Operation, contractProperty("6650f1c2a1b2c3d4e5f60718"))
+
+	// search-api solo indexa el snapshot de la versión que conoce: cambiarla es romper el contrato
+	if event.SchemaVersion != expected.SchemaVersion {
+		t.Errorf("expected schemaVersion %d, got %d", expected.SchemaVersion, event.SchemaVersion)
+	}
+	assertShape(t, c.Message, event)
+}
+
+func TestContract_SearchAPI_AvailabilityChanged(t *testing.T) {
+	c := loadContract(t, "search-api/properties-api/availability_changed.json")
+
+	event := clients.AvailabilityChangedEvent{
+		PropertyID:        "6650f1c2a1b2c3d4e5f60718",
+		Reason:            services.AvailabilityReasonBooking,
+		BookedRanges:      []clients.AvailabilityDateRange{{Start: "2024-06-10", End: "2024-06-14"}},
+		NextAvailableDate: "2024-05-25",
+		ChangedAt:         "2024-05-25T09:15:00Z",
+	}
+	assertShape(t, c.Message, event)
+}
+
+func TestContract_SearchAPI_GetProperty(t *testing.T) {
+	c := loadContract(t, "search-api/properties-api/get_property.json")
+
+	var request rpc.GetPropertyRequest
+	decodeStrict(t, c.Request, &request)
+
+	controller := NewPropertyGRPCController(contractPropertyService{properties: map[string]dto.PropertyResponseDTO{
+		request.ID: contractProperty(request.ID),
+	}}, nil)
+
+	response, err := controller.GetProperty(context.Background(), &request)
+	if err != nil {
+		t.Fatalf("GetProperty failed: %v", err)
+	}
+	assertShape(t, c.Response, response)
+}
+
+func TestContract_SearchAPI_ListPropertyVersions(t *testing.T) {
+	c := loadContract(t, "search-api/properties-api/list_property_versions.json")
+
+	var request rpc.ListPropertyVersionsRequest
+	decodeStrict(t, c.Request, &request)
+
+	controller := NewPropertyGRPCController(contractPropertyService{}, nil)
+
+	response, err := controller.ListPropertyVersions(context.Background(), &request)
+	if err != nil {
+		t.Fatalf("ListPropertyVersions failed: %v", err)
+	}
+	if response.NextAfterID == "" {
+		t.Fatalf("expected nextAfterId for a full page")
+	}
+	assertShape(t, c.Response, response)
+}
+
+// ============================================
+// TESTS - properties-api como consumidor de users-api
+// ============================================
+
+func TestContract_UsersAPI_ValidateUser(t *testing.T) {
+	c := loadContract(t, "properties-api/users-api/validate_user.json")
+
+	// El contrato tiene que describir lo que properties-api manda y lee: ni campos de más ni de menos
+	var request rpc.ValidateUserRequest
+	decodeStrict(t, c.Request, &request)
+	assertShape(t, mustMarshal(t, request), json.RawMessage(c.Request))
+
+	var response rpc.ValidateUserResponse
+	decodeStrict(t, c.Response, &response)
+	assertShape(t, mustMarshal(t, response), json.RawMessage(c.Response))
+
+	if request.UserID == 0 || !response.Exists || !response.Active {
+		t.Errorf("expected an existing active user in the contract, got request %s and response %s", c.Request, c.Response)
+	}
+}
+
+func TestContract_UsersAPI_VerifyToken(t *testing.T) {
+	c := loadContract(t, "properties-api/users-api/verify_token.json")
+
+	var request rpc.VerifyTokenRequest
+	decodeStrict(t, c.Request, &request)
+	assertShape(t, mustMarshal(t, request), json.RawMessage(c.Request))
+
+	var response rpc.VerifyTokenResponse
+	decodeStrict(t, c.Response, &response)
+	assertShape(t, mustMarshal(t, response), json.RawMessage(c.Response))
+
+	if request.Token == "" || !response.Valid || response.UserID == 0 {
+		t.Errorf("expected a valid token in the contract, got request %s and response %s", c.Request, c.Response)
+	}
+}
+
+func mustMarshal(t *testing.T, value interface{}) json.RawMessage {
+	t.Helper()
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatalf("failed to encode %T: %v", value, err)
+	}
+	return data
+}
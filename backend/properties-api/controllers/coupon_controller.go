@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"properties-api/dto"
+	"properties-api/repositories"
+	"properties-api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CouponController struct {
+	service services.CouponService
+}
+
+func NewCouponController(service services.CouponService) *CouponController {
+	return &CouponController{
+		service: service,
+	}
+}
+
+// CreateCoupon crea un cupón promocional (solo admin)
+func (c *CouponController) CreateCoupon(ctx *gin.Context) {
+	adminID, ok := userIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	var request dto.CouponCreateDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	coupon, err := c.service.CreateCoupon(ctx.Request.Context(), adminID, request)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidCoupon):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, repositories.ErrCouponCodeTaken):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, coupon)
+}
+
+// ListCoupons lista todos los cupones (solo admin)
+func (c *CouponController) ListCoupons(ctx *gin.Context) {
+	coupons, err := c.service.ListCoupons(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, coupons)
+}
+
+// GetRedemptions lista los usos de un cupón (solo admin)
+func (c *CouponController) GetRedemptions(ctx *gin.Context) {
+	redemptions, err := c.service.GetRedemptions(ctx.Request.Context(), ctx.Param("code"))
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, redemptions)
+}
+
+// DeactivateCoupon desactiva un cupón (solo admin); los usos registrados se conservan
+func (c *CouponController) DeactivateCoupon(ctx *gin.Context) {
+	adminID, ok := userIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	if err := c.service.DeactivateCoupon(ctx.Request.Context(), adminID, ctx.Param("code")); err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Cupón desactivado"})
+}
+
+// respondError traduce los errores del servicio de cupones a códigos HTTP
+func (c *CouponController) respondError(ctx *gin.Context, err error) {
+	if errors.Is(err, services.ErrCouponNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
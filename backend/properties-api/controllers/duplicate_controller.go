@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"properties-api/repositories"
+	"properties-api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DuplicateController struct {
+	service services.DuplicateService
+}
+
+func NewDuplicateController(service services.DuplicateService) *DuplicateController {
+	return &DuplicateController{
+		service: service,
+	}
+}
+
+// ListFlagged lista las propiedades marcadas como probable duplicado (solo admin)
+func (c *DuplicateController) ListFlagged(ctx *gin.Context) {
+	reviews, err := c.service.ListFlagged(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, reviews)
+}
+
+// Merge fusiona una propiedad duplicada con la original (solo admin)
+func (c *DuplicateController) Merge(ctx *gin.Context) {
+	adminID, ok := userIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	merged, err := c.service.Merge(ctx.Request.Context(), ctx.Param("id"), adminID)
+	if errors.Is(err, repositories.ErrBookingNightsTaken) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, merged)
+}
+
+// Dismiss quita la marca de duplicado de una propiedad (solo admin)
+func (c *DuplicateController) Dismiss(ctx *gin.Context) {
+	adminID, ok := userIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	if err := c.service.Dismiss(ctx.Request.Context(), ctx.Param("id"), adminID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Marca de duplicado eliminada"})
+}
@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"properties-api/dto"
+	"properties-api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EventReplayController struct {
+	service services.EventReplayService
+}
+
+func NewEventReplayController(service services.EventReplayService) *EventReplayController {
+	return &EventReplayController{
+		service: service,
+	}
+}
+
+// Replay republica los eventos de las propiedades modificadas en un rango (solo admin)
+// Body: {"from": "...", "to": "...", "dryRun": false}; to es opcional (ahora)
+func (c *EventReplayController) Replay(ctx *gin.Context) {
+	adminID, ok := userIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	var request dto.EventReplayRequestDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := c.service.Replay(ctx.Request.Context(), adminID, request)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidDateRange) || errors.Is(err, services.ErrReplayWindowTooLarge) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"properties-api/dto"
+	"properties-api/services"
+	"properties-api/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExportController struct {
+	service services.ExportService
+}
+
+func NewExportController(service services.ExportService) *ExportController {
+	return &ExportController{
+		service: service,
+	}
+}
+
+// ExportProperties maneja el export de propiedades con filtros (solo admin)
+// Query params opcionales: ownerId, type, available, from y to (fecha de creación)
+// Por defecto responde CSV; ?format=excel agrega el BOM para Excel y ?format=json|ndjson también se soportan
+func (c *ExportController) ExportProperties(ctx *gin.Context) {
+	var query dto.PropertyExportQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	encoder := utils.NewStreamEncoder(ctx, exportFormat(ctx), "properties", dto.PropertyResponseDTO{}.CSVHeader())
+	err := c.service.StreamProperties(ctx.Request.Context(), query, func(property dto.PropertyResponseDTO) error {
+		return encoder.Encode(property)
+	})
+	finishExport(encoder, err)
+}
+
+// ExportBookings maneja el export de reservas con filtros (solo admin)
+// Query params opcionales: propertyId, userId, status, from y to (fecha de check-in)
+func (c *ExportController) ExportBookings(ctx *gin.Context) {
+	var query dto.BookingExportQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	encoder := utils.NewStreamEncoder(ctx, exportFormat(ctx), "bookings", dto.BookingDTO{}.CSVHeader())
+	err := c.service.StreamBookings(ctx.Request.Context(), query, func(booking dto.BookingDTO) error {
+		return encoder.Encode(booking)
+	})
+	finishExport(encoder, err)
+}
+
+// exportFormat usa CSV salvo que se pida otro formato con ?format= o el header Accept
+func exportFormat(ctx *gin.Context) utils.OutputFormat {
+	if ctx.Query("format") == "" && !strings.Contains(ctx.GetHeader("Accept"), "json") {
+		return utils.FormatCSV
+	}
+	return utils.NegotiateFormat(ctx)
+}
+
+// finishExport cierra la respuesta o reporta el error del export
+func finishExport(encoder *utils.StreamEncoder, err error) {
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidDateRange) {
+			encoder.FailWithStatus(http.StatusBadRequest, err)
+			return
+		}
+		encoder.Fail(err)
+		return
+	}
+
+	if err := encoder.Close(); err != nil {
+		encoder.Fail(err)
+	}
+}
@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"net/http"
+
+	"properties-api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthController struct {
+	service services.HealthService
+}
+
+func NewHealthController(service services.HealthService) *HealthController {
+	return &HealthController{
+		service: service,
+	}
+}
+
+// Live maneja el liveness probe: responde 200 mientras el proceso esté vivo
+func (c *HealthController) Live(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, c.service.Live())
+}
+
+// Ready maneja el readiness probe: responde 503 si alguna dependencia no responde
+func (c *HealthController) Ready(ctx *gin.Context) {
+	response, ready := c.service.Ready(ctx.Request.Context())
+	if !ready {
+		ctx.JSON(http.StatusServiceUnavailable, response)
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
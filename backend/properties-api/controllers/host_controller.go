@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"properties-api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HostController struct {
+	service services.HostService
+}
+
+func NewHostController(service services.HostService) *HostController {
+	return &HostController{
+		service: service,
+	}
+}
+
+// GetPropertyHost maneja el perfil público del anfitrión de una propiedad (GET /api/properties/:id/host)
+func (c *HostController) GetPropertyHost(ctx *gin.Context) {
+	host, err := c.service.GetPropertyHost(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		if errors.Is(err, services.ErrHostProfileUnavailable) {
+			ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		// Propiedad inexistente o no publicada y anfitrión dado de baja
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, host)
+}
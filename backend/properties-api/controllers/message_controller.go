@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"properties-api/dto"
+	"properties-api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MessageController struct {
+	service services.MessageService
+}
+
+func NewMessageController(service services.MessageService) *MessageController {
+	return &MessageController{
+		service: service,
+	}
+}
+
+// StartConversation maneja el envío de un mensaje al owner de la propiedad
+// Crea la conversación la primera vez; los mensajes siguientes se agregan a la misma
+func (c *MessageController) StartConversation(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	var request dto.SendMessageDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	conversation, message, err := c.service.StartConversation(ctx.Request.Context(), ctx.Param("id"), userID, request)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"conversation": conversation, "message": message})
+}
+
+// SendMessage maneja el envío de un mensaje en una conversación (solo participantes)
+func (c *MessageController) SendMessage(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	var request dto.SendMessageDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	message, err := c.service.SendMessage(ctx.Request.Context(), ctx.Param("id"), userID, request)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, message)
+}
+
+// ListConversations maneja el listado de las conversaciones del usuario
+func (c *MessageController) ListConversations(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	conversations, err := c.service.ListConversations(ctx.Request.Context(), userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, conversations)
+}
+
+// ListMessages maneja la lectura de los mensajes de una conversación
+// Query params: limit (default 50, máximo 100) y before (RFC3339, para paginar hacia atrás)
+func (c *MessageController) ListMessages(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	limit := 0
+	if value := ctx.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "limit debe ser un entero positivo"})
+			return
+		}
+		limit = parsed
+	}
+
+	var before time.Time
+	if value := ctx.Query("before"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "before debe tener formato RFC3339"})
+			return
+		}
+		before = parsed
+	}
+
+	page, err := c.service.ListMessages(ctx.Request.Context(), ctx.Param("id"), userID, ctx.GetBool("isAdmin"), before, limit)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// UnreadCount maneja la consulta del total de mensajes sin leer del usuario
+func (c *MessageController) UnreadCount(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	unread, err := c.service.UnreadCount(ctx.Request.Context(), userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.UnreadCountDTO{Unread: unread})
+}
+
+// respondError traduce los errores del servicio de mensajería a status HTTP
+func (c *MessageController) respondError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrConversationForbidden):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCannotMessageOwnProperty), errors.Is(err, services.ErrEmptyMessage):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"properties-api/dto"
+	"properties-api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ModerationController struct {
+	service services.ModerationService
+}
+
+func NewModerationController(service services.ModerationService) *ModerationController {
+	return &ModerationController{
+		service: service,
+	}
+}
+
+// ListCases lista la cola de moderación (solo admin)
+// Query: ?status=pending|approved|rejected|superseded|all (default pending)
+func (c *ModerationController) ListCases(ctx *gin.Context) {
+	cases, err := c.service.ListCases(ctx.Request.Context(), ctx.Query("status"))
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, cases)
+}
+
+// ListMyCases lista el contenido del usuario autenticado retenido por moderación y su resolución
+func (c *ModerationController) ListMyCases(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	cases, err := c.service.ListUserCases(ctx.Request.Context(), userID)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, cases)
+}
+
+// Approve publica el contenido retenido (solo admin)
+// Body opcional: {"note": "..."}
+func (c *ModerationController) Approve(ctx *gin.Context) {
+	c.decide(ctx, c.service.Approve)
+}
+
+// Reject descarta el contenido retenido (solo admin)
+// Body opcional: {"note": "..."} con el motivo que ve el autor
+func (c *ModerationController) Reject(ctx *gin.Context) {
+	c.decide(ctx, c.service.Reject)
+}
+
+// decide resuelve un caso con la decisión indicada
+func (c *ModerationController) decide(ctx *gin.Context, decision func(ctx context.Context, id, adminID, note string) (dto.ModerationCaseDTO, error)) {
+	adminID, ok := userIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	var request dto.ModerationDecisionDTO
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	resolved, err := decision(ctx.Request.Context(), ctx.Param("id"), adminID, request.Note)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resolved)
+}
+
+// respondError traduce los errores de moderación a status HTTP
+func (c *ModerationController) respondError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrModerationCaseNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrModerationCaseClosed):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidModerationStatus):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
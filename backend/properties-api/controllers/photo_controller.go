@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"properties-api/dto"
+	"properties-api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PhotoController struct {
+	service        services.PropertyService
+	images         services.ImageService
+	maxUploadBytes int
+}
+
+func NewPhotoController(service services.PropertyService, images services.ImageService, maxUploadBytes int) *PhotoController {
+	return &PhotoController{
+		service:        service,
+		images:         images,
+		maxUploadBytes: maxUploadBytes,
+	}
+}
+
+// UploadPhoto maneja la subida de una foto (solo owner o admin)
+// Body multipart: "file" con la imagen (JPEG, PNG o GIF) y "caption" opcional
+// Responde 202: la foto queda "processing" hasta que el worker genera sus variantes
+func (c *PhotoController) UploadPhoto(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	// Margen para los encabezados del multipart y el epígrafe
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, int64(c.maxUploadBytes)+64<<10)
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "La foto supera el tamaño máximo"})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Falta el archivo 'file' con la foto"})
+		return
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer src.Close()
+	data, err := io.ReadAll(io.LimitReader(src, int64(c.maxUploadBytes)+1))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	property, err := c.images.UploadPhoto(ctx.Request.Context(), ctx.Param("id"), userID, ctx.GetBool("isAdmin"), data, ctx.PostForm("caption"))
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, property)
+}
+
+// ReorderPhotos maneja el cambio de orden de la galería (solo owner o admin)
+// Body: {"urls": [...]} con todas las fotos de la propiedad en el orden nuevo
+func (c *PhotoController) ReorderPhotos(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	var request dto.PhotoOrderDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	property, err := c.service.ReorderPhotos(ctx.Request.Context(), ctx.Param("id"), userID, ctx.GetBool("isAdmin"), request.URLs)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, property)
+}
+
+// SetCoverPhoto maneja la elección de la foto de portada (solo owner o admin)
+func (c *PhotoController) SetCoverPhoto(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	var request dto.PhotoCoverDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	property, err := c.service.SetCoverPhoto(ctx.Request.Context(), ctx.Param("id"), userID, ctx.GetBool("isAdmin"), request.URL)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, property)
+}
+
+// respondError traduce los errores de la galería a status HTTP
+func (c *PhotoController) respondError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrPhotosForbidden):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidPhotos), errors.Is(err, services.ErrInvalidImage):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrImageTooLarge):
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	}
+}
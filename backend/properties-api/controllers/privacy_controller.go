@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"net/http"
+
+	"properties-api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PrivacyController struct {
+	service services.PrivacyService
+}
+
+func NewPrivacyController(service services.PrivacyService) *PrivacyController {
+	return &PrivacyController{
+		service: service,
+	}
+}
+
+// ExportUserData maneja el export de los datos de un usuario (el propio usuario o un admin)
+// Lo consume users-api para armar el export GDPR completo
+func (c *PrivacyController) ExportUserData(ctx *gin.Context) {
+	targetUserID := ctx.Param("userId")
+
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+	if userID != targetUserID && !ctx.GetBool("isAdmin") {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Solo el propio usuario o un administrador pueden exportar estos datos"})
+		return
+	}
+
+	export, err := c.service.ExportUserData(ctx.Request.Context(), targetUserID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, export)
+}
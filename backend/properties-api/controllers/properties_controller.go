@@ -1,21 +1,39 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"properties-api/dto"
 	"properties-api/services"
+	"properties-api/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
+// skipViewCountHeader lo envían los servicios internos (ej: search-api al indexar)
+// para que sus lecturas no cuenten como vistas de la propiedad
+const skipViewCountHeader = "X-Skip-View-Count"
+
 type PropertyController struct {
-	service services.PropertyService
+	service             services.PropertyService
+	viewsService        services.ViewService
+	translationsService services.TranslationService
+	cacheMaxAge         time.Duration // max-age del Cache-Control de GET /properties/:id
 }
 
-func NewPropertyController(service services.PropertyService) *PropertyController {
+func NewPropertyController(service services.PropertyService, viewsService services.ViewService, translationsService services.TranslationService, cacheMaxAge time.Duration) *PropertyController {
 	return &PropertyController{
-		service: service,
+		service:             service,
+		viewsService:        viewsService,
+		translationsService: translationsService,
+		cacheMaxAge:         cacheMaxAge,
 	}
 }
 
@@ -27,8 +45,12 @@ func (c *PropertyController) CreateProperty(ctx *gin.Context) {
 		return
 	}
 
-	responseDTO, err := c.service.CreateProperty(createDTO)
+	responseDTO, err := c.service.CreateProperty(ctx.Request.Context(), createDTO)
 	if err != nil {
+		if errors.Is(err, services.ErrDuplicateProperty) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -40,13 +62,70 @@ func (c *PropertyController) CreateProperty(ctx *gin.Context) {
 func (c *PropertyController) GetPropertyByID(ctx *gin.Context) {
 	id := ctx.Param("id")
 
-	responseDTO, err := c.service.GetPropertyByID(id)
+	responseDTO, err := c.service.GetPropertyByID(ctx.Request.Context(), id)
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, responseDTO)
+	if ctx.GetHeader(skipViewCountHeader) != "true" {
+		c.viewsService.RecordView(ctx.Request.Context(), id)
+	}
+
+	// El título y la descripción van en el idioma que mejor coincide con Accept-Language
+	// (traducidos automáticamente si la propiedad no tiene ninguno de los aceptados)
+	responseDTO = c.translationsService.Localize(ctx.Request.Context(), responseDTO, utils.ParseAcceptLanguage(ctx.GetHeader("Accept-Language")))
+	ctx.Header("Content-Language", responseDTO.Language)
+	ctx.Writer.Header().Add("Vary", "Accept-Language")
+
+	// Con If-None-Match o If-Modified-Since el cliente que ya tiene la propiedad recibe un 304 (la vista se cuenta igual)
+	// max-age corto: el cliente o un proxy pueden servir la propiedad sin preguntar, pero un cambio se ve enseguida
+	ctx.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(c.cacheMaxAge.Seconds())))
+	updatedAt, _ := time.Parse(time.RFC3339, responseDTO.UpdatedAt)
+	utils.WriteCacheableJSONWithLastModified(ctx, http.StatusOK, responseDTO, updatedAt)
+}
+
+// GetTrendingProperties maneja el listado de propiedades más vistas
+// Query params opcionales: days (default 7, máx 90) y limit (default 10, máx 50)
+func (c *PropertyController) GetTrendingProperties(ctx *gin.Context) {
+	days, err := boundedQueryInt(ctx, "days", 7, 90)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	limit, err := boundedQueryInt(ctx, "limit", 10, 50)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	properties, err := c.service.GetTrendingProperties(ctx.Request.Context(), days, limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	preferred := utils.ParseAcceptLanguage(ctx.GetHeader("Accept-Language"))
+	for i := range properties {
+		properties[i] = services.LocalizeProperty(properties[i], preferred)
+	}
+	ctx.Writer.Header().Add("Vary", "Accept-Language")
+
+	utils.WriteCacheableJSON(ctx, http.StatusOK, properties)
+}
+
+// boundedQueryInt lee un query param entero entre 1 y max, usando defaultValue si no viene
+func boundedQueryInt(ctx *gin.Context, name string, defaultValue, max int) (int, error) {
+	raw := ctx.Query(name)
+	if raw == "" {
+		return defaultValue, nil
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 1 || value > max {
+		return 0, fmt.Errorf("%s debe ser un número entre 1 y %d", name, max)
+	}
+	return value, nil
 }
 
 // UpdateProperty maneja la actualización de una propiedad
@@ -82,7 +161,7 @@ func (c *PropertyController) UpdateProperty(ctx *gin.Context) {
 	isAdminValue, _ := ctx.Get("isAdmin")
 	isAdmin, _ := isAdminValue.(bool)
 
-	err := c.service.UpdateProperty(id, updateDTO, userID, isAdmin)
+	err := c.service.UpdateProperty(ctx.Request.Context(), id, updateDTO, userID, isAdmin)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -118,7 +197,7 @@ func (c *PropertyController) DeleteProperty(ctx *gin.Context) {
 	isAdminValue, _ := ctx.Get("isAdmin")
 	isAdmin, _ := isAdminValue.(bool)
 
-	err := c.service.DeleteProperty(id, userID, isAdmin)
+	err := c.service.DeleteProperty(ctx.Request.Context(), id, userID, isAdmin)
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -127,26 +206,141 @@ func (c *PropertyController) DeleteProperty(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{"message": "Propiedad eliminada exitosamente"})
 }
 
-// GetUserProperties maneja la obtención de propiedades de un usuario
+// GetUserProperties maneja la obtención paginada de propiedades de un usuario (panel del propietario)
+// Query params opcionales: q (texto en el título), status, available, sort, page y limit (máx 100)
+// El owner o un admin (con JWT) ven también las propiedades retenidas por moderación
+// Soporta JSON, NDJSON y CSV según ?format= o el header Accept; NDJSON y CSV traen
+// solo la página pedida y el total en X-Total-Count
 func (c *PropertyController) GetUserProperties(ctx *gin.Context) {
 	userID := ctx.Param("userId")
 
-	responseDTOs, err := c.service.GetUserProperties(userID)
+	var query dto.UserPropertiesQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	requesterID, _ := userIDFromContext(ctx)
+	canSeeUnpublished := requesterID == userID || ctx.GetBool("isAdmin")
+
+	page, err := c.service.SearchUserProperties(ctx.Request.Context(), userID, query, canSeeUnpublished)
 	if err != nil {
+		if errors.Is(err, services.ErrUnpublishedForbidden) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, responseDTOs)
+	format := utils.NegotiateFormat(ctx)
+	if format == utils.FormatJSON {
+		utils.WriteCacheableJSON(ctx, http.StatusOK, page)
+		return
+	}
+
+	ctx.Header("X-Total-Count", strconv.FormatInt(page.Total, 10))
+	encoder := utils.NewStreamEncoder(ctx, format, "properties", dto.PropertyResponseDTO{}.CSVHeader())
+	for _, responseDTO := range page.Properties {
+		if err := encoder.Encode(responseDTO); err != nil {
+			encoder.Fail(err)
+			return
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		encoder.Fail(err)
+	}
 }
 
 // GetAllProperties maneja la obtención de todas las propiedades (solo admin)
+// La respuesta se escribe en streaming (JSON, NDJSON o CSV) leyendo de un cursor de MongoDB
 func (c *PropertyController) GetAllProperties(ctx *gin.Context) {
-	responseDTOs, err := c.service.GetAllProperties()
+	encoder := utils.NewStreamEncoder(ctx, utils.NegotiateFormat(ctx), "properties", dto.PropertyResponseDTO{}.CSVHeader())
+	err := c.service.StreamAllProperties(ctx.Request.Context(), func(property dto.PropertyResponseDTO) error {
+		return encoder.Encode(property)
+	})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		encoder.Fail(err)
+		return
+	}
+
+	if err := encoder.Close(); err != nil {
+		encoder.Fail(err)
+	}
+}
+
+// GetPriceHistory maneja la obtención del historial de precios de una propiedad
+func (c *PropertyController) GetPriceHistory(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	responseDTO, err := c.service.GetPriceHistory(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, responseDTOs)
+	ctx.JSON(http.StatusOK, responseDTO)
+}
+
+// maxImportBytes limita el tamaño del archivo de importación masiva
+const maxImportBytes = 20 << 20 // 20 MB
+
+// ImportProperties maneja la importación masiva de propiedades (solo admin)
+// Acepta el archivo en el body (Content-Type text/csv o application/x-ndjson) o como
+// multipart en el campo "file"; ?format=csv|ndjson tiene prioridad sobre el tipo detectado
+// Responde 200 con el resultado de cada fila aunque algunas fallen
+func (c *PropertyController) ImportProperties(ctx *gin.Context) {
+	adminID, ok := userIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportBytes)
+
+	body := io.Reader(ctx.Request.Body)
+	format := importFormat(ctx.Query("format"), ctx.ContentType())
+	if strings.HasPrefix(ctx.ContentType(), "multipart/form-data") {
+		fileHeader, err := ctx.FormFile("file")
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Falta el archivo en el campo 'file'"})
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		defer file.Close()
+
+		body = file
+		format = importFormat(ctx.Query("format"), filepath.Ext(fileHeader.Filename))
+	}
+	if format == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Formato no soportado: enviar CSV (text/csv) o NDJSON (application/x-ndjson)"})
+		return
+	}
+
+	report, err := c.service.ImportProperties(ctx.Request.Context(), format, body, adminID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+// importFormat determina el formato del archivo a importar a partir de ?format=, el Content-Type o la extensión
+// Retorna vacío si no se reconoce
+func importFormat(query, contentType string) utils.OutputFormat {
+	for _, value := range []string{strings.ToLower(query), strings.ToLower(contentType)} {
+		switch {
+		case value == "csv" || value == ".csv" || strings.HasPrefix(value, "text/csv"):
+			return utils.FormatCSV
+		case value == "ndjson" || value == ".ndjson" || value == ".jsonl" ||
+			strings.HasPrefix(value, "application/x-ndjson") || strings.HasPrefix(value, "application/json"):
+			return utils.FormatNDJSON
+		}
+	}
+	return ""
 }
@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"context"
+
+	"properties-api/dto"
+	"properties-api/rpc"
+	"properties-api/services"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PropertyGRPCController atiende las llamadas gRPC de los servicios internos (search-api al indexar, graphql-api)
+type PropertyGRPCController struct {
+	service         services.PropertyService
+	calendarService services.CalendarService
+}
+
+func NewPropertyGRPCController(service services.PropertyService, calendarService services.CalendarService) *PropertyGRPCController {
+	return &PropertyGRPCController{
+		service:         service,
+		calendarService: calendarService,
+	}
+}
+
+// GetProperty obtiene una propiedad por ID
+// Las lecturas internas no cuentan como vistas (equivale a GET /properties/:id con X-Skip-View-Count)
+func (c *PropertyGRPCController) GetProperty(ctx context.Context, request *rpc.GetPropertyRequest) (*rpc.GetPropertyResponse, error) {
+	if request.ID == "" {
+		return nil, status.Error(codes.InvalidArgument, "ID de propiedad no puede estar vacío")
+	}
+
+	property, err := c.service.GetPropertyByID(ctx, request.ID)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
+	return &rpc.GetPropertyResponse{Property: property}, nil
+}
+
+// maxBatchProperties limita el tamaño de un lote de GetProperties
+const maxBatchProperties = 100
+
+// GetProperties obtiene un lote de propiedades; los IDs inexistentes se omiten de la respuesta
+func (c *PropertyGRPCController) GetProperties(ctx context.Context, request *rpc.GetPropertiesRequest) (*rpc.GetPropertiesResponse, error) {
+	if len(request.IDs) > maxBatchProperties {
+		return nil, status.Errorf(codes.InvalidArgument, "no se pueden pedir más de %d propiedades por llamada", maxBatchProperties)
+	}
+
+	response := &rpc.GetPropertiesResponse{Properties: []dto.PropertyResponseDTO{}}
+	for _, id := range request.IDs {
+		if err := ctx.Err(); err != nil {
+			return nil, status.FromContextError(err).Err()
+		}
+		property, err := c.service.GetPropertyByID(ctx, id)
+		if err != nil {
+			continue
+		}
+		response.Properties = append(response.Properties, property)
+	}
+	return response, nil
+}
+
+// GetAvailability obtiene los rangos ocupados de una propiedad (reservas activas y bloqueos)
+func (c *PropertyGRPCController) GetAvailability(ctx context.Context, request *rpc.GetAvailabilityRequest) (*rpc.GetAvailabilityResponse, error) {
+	if request.PropertyID == "" {
+		return nil, status.Error(codes.InvalidArgument, "ID de propiedad no puede estar vacío")
+	}
+
+	ranges, err := c.calendarService.GetAvailability(ctx, request.PropertyID)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return &rpc.GetAvailabilityResponse{Ranges: ranges}, nil
+}
+
+// Límites de la página de ListPropertyVersions
+const (
+	defaultVersionsPage = 500
+	maxVersionsPage     = 1000
+)
+
+// ListPropertyVersions obtiene una página de versiones de propiedades ordenada por ID
+// Si la página viene completa, NextAfterID es su último ID para pedir la siguiente
+func (c *PropertyGRPCController) ListPropertyVersions(ctx context.Context, request *rpc.ListPropertyVersionsRequest) (*rpc.ListPropertyVersionsResponse, error) {
+	limit := request.Limit
+	if limit <= 0 {
+		limit = defaultVersionsPage
+	}
+	if limit > maxVersionsPage {
+		return nil, status.Errorf(codes.InvalidArgument, "no se pueden pedir más de %d versiones por llamada", maxVersionsPage)
+	}
+
+	versions, err := c.service.ListPropertyVersions(ctx, request.AfterID, limit)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, status.FromContextError(ctxErr).Err()
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	response := &rpc.ListPropertyVersionsResponse{Versions: versions}
+	if len(versions) == limit {
+		response.NextAfterID = versions[len(versions)-1].ID
+	}
+	return response, nil
+}
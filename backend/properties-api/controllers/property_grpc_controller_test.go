@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"properties-api/dto"
+	"properties-api/rpc"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// startPropertiesServer levanta el servidor gRPC de propiedades en memoria y retorna una conexión con el codec JSON
+func startPropertiesServer(t *testing.T, controller *PropertyGRPCController) *grpc.ClientConn {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	rpc.RegisterPropertiesServer(server, controller)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.CallContentSubtype(rpc.CodecName)),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func propertiesMethod(name string) string {
+	return "/" + rpc.PropertiesServiceName + "/" + name
+}
+
+func TestPropertiesGRPC_GetProperty(t *testing.T) {
+	service := contractPropertyService{properties: map[string]dto.PropertyResponseDTO{
+		"p1": {ID: "p1", Title: "Casa en la playa"},
+	}}
+	conn := startPropertiesServer(t, NewPropertyGRPCController(service, nil))
+
+	tests := []struct {
+		name      string
+		id        string
+		wantCode  codes.Code
+		wantTitle string
+	}{
+		{name: "existing property", id: "p1", wantTitle: "Casa en la playa"},
+		{name: "missing property", id: "p2", wantCode: codes.NotFound},
+		{name: "empty id", id: "", wantCode: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var response rpc.GetPropertyResponse
+			err := conn.Invoke(context.Background(), propertiesMethod("GetProperty"), &rpc.GetPropertyRequest{ID: tt.id}, &response)
+
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("expected code %v, got %v (%v)", tt.wantCode, got, err)
+			}
+			if response.Property.Title != tt.wantTitle {
+				t.Fatalf("expected title %q, got %q", tt.wantTitle, response.Property.Title)
+			}
+		})
+	}
+}
+
+func TestPropertiesGRPC_GetPropertiesSkipsMissingIDs(t *testing.T) {
+	service := contractPropertyService{properties: map[string]dto.PropertyResponseDTO{
+		"p1": {ID: "p1"},
+		"p3": {ID: "p3"},
+	}}
+	conn := startPropertiesServer(t, NewPropertyGRPCController(service, nil))
+
+	var response rpc.GetPropertiesResponse
+	if err := conn.Invoke(context.Background(), propertiesMethod("GetProperties"), &rpc.GetPropertiesRequest{IDs: []string{"p1", "p2", "p3"}}, &response); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(response.Properties) != 2 || response.Properties[0].ID != "p1" || response.Properties[1].ID != "p3" {
+		t.Fatalf("expected p1 and p3 in order, got %+v", response.Properties)
+	}
+
+	tooMany := make([]string, maxBatchProperties+1)
+	err := conn.Invoke(context.Background(), propertiesMethod("GetProperties"), &rpc.GetPropertiesRequest{IDs: tooMany}, &response)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument for %d ids, got %v", len(tooMany), err)
+	}
+}
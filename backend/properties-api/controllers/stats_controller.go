@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"properties-api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultStatsDays y maxStatsDays limitan la ventana de las estadísticas
+const (
+	defaultStatsDays = 30
+	maxStatsDays     = 365
+)
+
+type StatsController struct {
+	service services.PropertyStatsService
+}
+
+func NewStatsController(service services.PropertyStatsService) *StatsController {
+	return &StatsController{
+		service: service,
+	}
+}
+
+// GetPropertyStats maneja las estadísticas de una propiedad (solo owner o admin)
+// Query param opcional: days (ventana para ocupación e ingresos del período, default 30)
+func (c *StatsController) GetPropertyStats(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	days, err := boundedQueryInt(ctx, "days", defaultStatsDays, maxStatsDays)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stats, err := c.service.GetPropertyStats(ctx.Request.Context(), ctx.Param("id"), days, userID, ctx.GetBool("isAdmin"))
+	if err != nil {
+		if errors.Is(err, services.ErrStatsForbidden) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, stats)
+}
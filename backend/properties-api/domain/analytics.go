@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+// Fuentes de los KPIs diarios que pueden quedar incompletas en una corrida de la agregación
+const (
+	KPISourceUsers    = "users"       // Registros de users-api
+	KPISourceSearches = "searches"    // Búsquedas y clicks de search-api
+	KPINotComputed    = "notComputed" // La agregación todavía no calculó el día
+)
+
+// DailyKPIs son los indicadores de un día UTC del panel de admin (colección analytics_daily)
+// Los calcula periódicamente AnalyticsService: los días recientes se recalculan en cada corrida
+type DailyKPIs struct {
+	Date string `bson:"_id" json:"date"` // YYYY-MM-DD
+
+	// NewUsers son los usuarios registrados en users-api
+	NewUsers int64 `bson:"newUsers" json:"newUsers"`
+
+	// ListingsCreated son las propiedades publicadas ese día (las borradas después no se cuentan)
+	ListingsCreated int64 `bson:"listingsCreated" json:"listingsCreated"`
+
+	// Searches, ZeroResultSearches, ClickedSearches y Clicks son la actividad de búsqueda de search-api
+	Searches           int64 `bson:"searches" json:"searches"`
+	ZeroResultSearches int64 `bson:"zeroResultSearches" json:"zeroResultSearches"`
+	ClickedSearches    int64 `bson:"clickedSearches" json:"clickedSearches"`
+	Clicks             int64 `bson:"clicks" json:"clicks"`
+
+	// BookingsCreated son las reservas creadas ese día y BookingsConfirmed las que de ellas están confirmadas
+	BookingsCreated   int64 `bson:"bookingsCreated" json:"bookingsCreated"`
+	BookingsConfirmed int64 `bson:"bookingsConfirmed" json:"bookingsConfirmed"`
+
+	// Incomplete son las fuentes que no se pudieron contar del todo (ver KPISource*)
+	Incomplete []string `bson:"incomplete,omitempty" json:"incomplete,omitempty"`
+
+	ComputedAt time.Time `bson:"computedAt" json:"computedAt"`
+}
+
+// IsIncomplete indica si la fuente quedó incompleta en el día
+func (k DailyKPIs) IsIncomplete(source string) bool {
+	for _, incomplete := range k.Incomplete {
+		if incomplete == source {
+			return true
+		}
+	}
+	return false
+}
+
+// BookingDayCounts son las reservas creadas en un día y cuántas de ellas están confirmadas
+type BookingDayCounts struct {
+	Created   int64 `bson:"created"`
+	Confirmed int64 `bson:"confirmed"`
+}
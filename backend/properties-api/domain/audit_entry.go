@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuditChange es el valor anterior y el nuevo de un campo modificado
+type AuditChange struct {
+	Before interface{} `bson:"before" json:"before"`
+	After  interface{} `bson:"after" json:"after"`
+}
+
+// AuditEntry es un registro del log de auditoría de operaciones que modifican datos
+// El log es append-only: los registros nunca se actualizan ni se eliminan
+type AuditEntry struct {
+	ID primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	// ActorID es el usuario que hizo la operación ("system" para procesos internos)
+	ActorID string `bson:"actorId" json:"actorId"`
+	// Action es la operación realizada (ej: "property.update")
+	Action     string `bson:"action" json:"action"`
+	EntityType string `bson:"entityType" json:"entityType"`
+	EntityID   string `bson:"entityId" json:"entityId"`
+	// Changes contiene solo los campos modificados
+	Changes   map[string]AuditChange `bson:"changes" json:"changes"`
+	CreatedAt time.Time              `bson:"createdAt" json:"createdAt"`
+}
@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// AutoTranslation es la traducción automática del título y la descripción de una propiedad a un locale
+// Se guarda como caché: SourceHash identifica el texto original y si cambia se vuelve a traducir
+type AutoTranslation struct {
+	PropertyID     string    `bson:"propertyId"`
+	Locale         string    `bson:"locale"`
+	SourceLanguage string    `bson:"sourceLanguage"`
+	SourceHash     string    `bson:"sourceHash"`
+	Title          string    `bson:"title"`
+	Description    string    `bson:"description"`
+	TranslatedAt   time.Time `bson:"translatedAt"`
+}
@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BookingHold retiene las fechas de una estadía mientras el huésped completa el checkout
+// Sus noches se toman en booking_nights con el ID del hold, así nadie más puede reservarlas;
+// al reservar, la reserva se guarda con el mismo ID y se queda con esas noches
+type BookingHold struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	PropertyID string             `bson:"propertyId" json:"propertyId"`
+	UserID     string             `bson:"userId" json:"userId"`
+	// CheckIn y CheckOut son los días locales de la propiedad pedidos (YYYY-MM-DD)
+	CheckIn  string `bson:"checkIn" json:"checkIn"`
+	CheckOut string `bson:"checkOut" json:"checkOut"`
+	// ExpiresAt es hasta cuándo se puede reservar con el hold; después el sweeper libera las noches
+	ExpiresAt time.Time `bson:"expiresAt" json:"expiresAt"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+}
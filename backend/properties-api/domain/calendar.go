@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AvailabilityBlock es un rango de fechas en el que la propiedad no está disponible
+// Start y End son fechas del calendario de la propiedad guardadas a medianoche UTC; End es exclusivo
+type AvailabilityBlock struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	PropertyID string             `bson:"propertyId" json:"propertyId"`
+	Start      time.Time          `bson:"start" json:"start"`
+	End        time.Time          `bson:"end" json:"end"`
+	// FeedID es el calendario externo del que se importó el bloqueo (vacío = bloqueo manual del owner)
+	FeedID primitive.ObjectID `bson:"feedId" json:"feedId"`
+	// ExternalUID es el UID del evento en el calendario externo
+	ExternalUID string    `bson:"externalUid" json:"externalUid"`
+	Summary     string    `bson:"summary,omitempty" json:"summary,omitempty"`
+	CreatedAt   time.Time `bson:"createdAt" json:"createdAt"`
+}
+
+// DateRange es un rango de días locales de la propiedad (YYYY-MM-DD); End es exclusivo
+type DateRange struct {
+	Start string `bson:"start" json:"start"`
+	End   string `bson:"end" json:"end"`
+}
+
+// CalendarFeed es un calendario iCal externo (Airbnb, Booking...) que se sincroniza periódicamente
+type CalendarFeed struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	PropertyID string             `bson:"propertyId" json:"propertyId"`
+	Name       string             `bson:"name" json:"name"`
+	URL        string             `bson:"url" json:"url"`
+	CreatedBy  string             `bson:"createdBy" json:"createdBy"`
+	// LastSyncedAt es la última sincronización exitosa (nil si nunca se sincronizó)
+	LastSyncedAt *time.Time `bson:"lastSyncedAt,omitempty" json:"lastSyncedAt,omitempty"`
+	// LastError es el error de la última sincronización (vacío si fue exitosa)
+	LastError string    `bson:"lastError,omitempty" json:"lastError,omitempty"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+}
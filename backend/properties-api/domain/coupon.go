@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Tipos de descuento de un cupón
+const (
+	CouponPercentage = "percentage" // Value es un porcentaje (ej: 15 = 15%)
+	CouponFixed      = "fixed"      // Value es un monto fijo
+)
+
+// Coupon es un código promocional creado por un administrador
+// El descuento se aplica sobre las noches + la limpieza, antes de la tarifa de servicio y los impuestos
+type Coupon struct {
+	ID primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	// Code es el código que ingresa el huésped, siempre en mayúsculas (único)
+	Code  string  `bson:"code" json:"code"`
+	Type  string  `bson:"type" json:"type"`
+	Value float64 `bson:"value" json:"value"`
+	// MaxRedemptions es la cantidad total de usos permitidos (0 = sin límite)
+	MaxRedemptions int64 `bson:"maxRedemptions" json:"maxRedemptions"`
+	// MaxPerUser es la cantidad de usos permitidos por huésped (0 = sin límite)
+	MaxPerUser int64 `bson:"maxPerUser" json:"maxPerUser"`
+	// Redemptions es la cantidad de veces que se usó
+	Redemptions int64 `bson:"redemptions" json:"redemptions"`
+	// ValidFrom y ValidUntil delimitan cuándo se puede usar (nil = sin límite)
+	ValidFrom  *time.Time `bson:"validFrom,omitempty" json:"validFrom,omitempty"`
+	ValidUntil *time.Time `bson:"validUntil,omitempty" json:"validUntil,omitempty"`
+	// Active permite desactivar el cupón sin borrarlo (se conservan los usos)
+	Active    bool      `bson:"active" json:"active"`
+	CreatedBy string    `bson:"createdBy" json:"createdBy"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+}
+
+// CouponRedemption registra el uso de un cupón en una reserva
+type CouponRedemption struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	CouponID   primitive.ObjectID `bson:"couponId" json:"couponId"`
+	Code       string             `bson:"code" json:"code"`
+	BookingID  string             `bson:"bookingId" json:"bookingId"`
+	UserID     string             `bson:"userId" json:"userId"`
+	Discount   float64            `bson:"discount" json:"discount"`
+	RedeemedAt time.Time          `bson:"redeemedAt" json:"redeemedAt"`
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Conversation es el hilo de mensajes entre un huésped y el anfitrión sobre una propiedad
+// Hay una sola conversación por (propiedad, huésped, anfitrión)
+type Conversation struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	PropertyID string             `bson:"propertyId" json:"propertyId"`
+	GuestID    string             `bson:"guestId" json:"guestId"`
+	HostID     string             `bson:"hostId" json:"hostId"`
+	// LastMessage es un extracto del último mensaje (para el listado de conversaciones)
+	LastMessage   string    `bson:"lastMessage,omitempty" json:"lastMessage,omitempty"`
+	LastMessageAt time.Time `bson:"lastMessageAt" json:"lastMessageAt"`
+	// GuestUnread y HostUnread son los mensajes que cada participante todavía no leyó
+	GuestUnread int64     `bson:"guestUnread" json:"guestUnread"`
+	HostUnread  int64     `bson:"hostUnread" json:"hostUnread"`
+	CreatedAt   time.Time `bson:"createdAt" json:"createdAt"`
+}
+
+// Message es un mensaje de una conversación
+type Message struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ConversationID primitive.ObjectID `bson:"conversationId" json:"conversationId"`
+	SenderID       string             `bson:"senderId" json:"senderId"`
+	Body           string             `bson:"body" json:"body"`
+	CreatedAt      time.Time          `bson:"createdAt" json:"createdAt"`
+}
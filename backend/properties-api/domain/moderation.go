@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Estados de moderación de una propiedad (vacío = publicada y sin cambios retenidos)
+const (
+	// ModerationPending es una propiedad nueva retenida: no se publica hasta que un admin la apruebe
+	ModerationPending = "pending"
+	// ModerationRejected es una propiedad nueva rechazada: sigue sin publicarse hasta que el owner la corrija
+	ModerationRejected = "rejected"
+	// ModerationChangesPending es una propiedad publicada con una edición del texto retenida
+	ModerationChangesPending = "changes_pending"
+)
+
+// Estados de un caso de moderación
+const (
+	ModerationCasePending    = "pending"
+	ModerationCaseApproved   = "approved"
+	ModerationCaseRejected   = "rejected"
+	ModerationCaseSuperseded = "superseded" // El autor volvió a editar el contenido antes de la revisión
+)
+
+// ModerationEntityProperty es el tipo de contenido de los títulos y descripciones de propiedades
+const ModerationEntityProperty = "property"
+
+// ModerationFlag es el motivo por el que un check retuvo un campo
+type ModerationFlag struct {
+	Check  string `bson:"check" json:"check"` // Check que lo detectó (ej: "blocked_words", "contact_info", "external")
+	Field  string `bson:"field" json:"field"` // Campo retenido (ej: "title", "description")
+	Reason string `bson:"reason" json:"reason"`
+}
+
+// ModerationCase es contenido retenido a la espera de la revisión de un admin
+type ModerationCase struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	EntityType  string             `bson:"entityType" json:"entityType"`
+	EntityID    string             `bson:"entityId" json:"entityId"`
+	SubmittedBy string             `bson:"submittedBy" json:"submittedBy"`
+	// Content son los textos retenidos por campo; se aplican a la entidad si el caso se aprueba
+	Content map[string]string `bson:"content" json:"content"`
+	Flags   []ModerationFlag  `bson:"flags" json:"flags"`
+	Status  string            `bson:"status" json:"status"`
+	// ReviewedBy, ReviewedAt y Note los completa el admin al resolver el caso
+	ReviewedBy string     `bson:"reviewedBy,omitempty" json:"reviewedBy,omitempty"`
+	ReviewedAt *time.Time `bson:"reviewedAt,omitempty" json:"reviewedAt,omitempty"`
+	Note       string     `bson:"note,omitempty" json:"note,omitempty"`
+	CreatedAt  time.Time  `bson:"createdAt" json:"createdAt"`
+}
+
+// IsPublished indica si la propiedad se muestra y se indexa para búsqueda
+// Una propiedad con una edición retenida sigue publicada con el texto anterior
+func (p Property) IsPublished() bool {
+	return p.ModerationStatus != ModerationPending && p.ModerationStatus != ModerationRejected
+}
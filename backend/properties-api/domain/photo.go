@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+// Estados del procesamiento de una foto subida (las fotos con URL externa no tienen estado)
+const (
+	PhotoStatusProcessing = "processing"
+	PhotoStatusReady      = "ready"
+	PhotoStatusFailed     = "failed"
+)
+
+// Tamaños y formatos de las variantes de una foto subida
+const (
+	PhotoSizeThumbnail = "thumbnail"
+	PhotoSizeMedium    = "medium"
+	PhotoSizeLarge     = "large"
+
+	PhotoFormatJPEG = "jpeg"
+	PhotoFormatWebP = "webp"
+)
+
+// Photo es una foto de la galería de una propiedad
+type Photo struct {
+	// URL es la dirección de la imagen; identifica a la foto dentro de la propiedad
+	URL string `bson:"url" json:"url"`
+	// Caption es el epígrafe que se muestra con la foto (opcional)
+	Caption string `bson:"caption,omitempty" json:"caption,omitempty"`
+	// Position es el orden en la galería, empezando en 0
+	Position int `bson:"position" json:"position"`
+	// IsCover indica la foto de portada (la miniatura de los resultados de búsqueda); hay una sola por propiedad
+	IsCover bool `bson:"isCover" json:"isCover"`
+	// Status es el estado de las variantes de una foto subida (vacío si la foto es una URL externa)
+	Status string `bson:"status,omitempty" json:"status,omitempty"`
+	// StorageKey es la clave del original en el almacenamiento de imágenes (solo fotos subidas)
+	StorageKey string `bson:"storageKey,omitempty" json:"-"`
+	// Variants son las versiones redimensionadas de la foto, en JPEG y WebP
+	Variants []PhotoVariant `bson:"variants,omitempty" json:"variants,omitempty"`
+}
+
+// PhotoVariant es una versión redimensionada de una foto subida
+type PhotoVariant struct {
+	// Size es el tamaño: "thumbnail", "medium" o "large"
+	Size string `bson:"size" json:"size"`
+	// Format es el formato de la imagen: "jpeg" o "webp"
+	Format string `bson:"format" json:"format"`
+	URL    string `bson:"url" json:"url"`
+	Width  int    `bson:"width" json:"width"`
+	Height int    `bson:"height" json:"height"`
+}
+
+// UnmarshalBSONValue lee una foto guardada como documento o, en propiedades anteriores a las fotos
+// estructuradas, como la URL sola (las posiciones y la portada se completan al normalizar la galería)
+func (p *Photo) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	if t == bsontype.String {
+		url, _, ok := bsoncore.ReadString(data)
+		if !ok {
+			return fmt.Errorf("foto inválida: URL mal codificada")
+		}
+		*p = Photo{URL: url}
+		return nil
+	}
+
+	// photoFields evita que Unmarshal vuelva a llamar a este método
+	type photoFields Photo
+	var fields photoFields
+	if err := (bson.RawValue{Type: t, Value: data}).Unmarshal(&fields); err != nil {
+		return fmt.Errorf("foto inválida: %w", err)
+	}
+	*p = Photo(fields)
+	return nil
+}
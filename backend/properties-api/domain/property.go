@@ -14,26 +14,127 @@ type Property struct {
 	Title string `bson:"title" json:"title"`
 	// Description contiene la descripción detallada de la propiedad
 	Description string `bson:"description" json:"description"`
+	// Language es el idioma del título y la descripción (vacío = español) y Translations esos textos en
+	// otros idiomas, por locale ("en", "pt-BR")
+	Language     string                         `bson:"language,omitempty" json:"language,omitempty"`
+	Translations map[string]PropertyTranslation `bson:"translations,omitempty" json:"translations,omitempty"`
 	// Location es la ubicación completa de la propiedad
 	Location string `bson:"location" json:"location"`
+	// PropertyType es el tipo de propiedad (casa, apartamento, cabaña, loft...), siempre en minúsculas
+	PropertyType string `bson:"propertyType,omitempty" json:"propertyType,omitempty"`
+	// Latitude y Longitude son las coordenadas de la propiedad (opcionales, para búsqueda por mapa)
+	Latitude  float64 `bson:"latitude,omitempty" json:"latitude,omitempty"`
+	Longitude float64 `bson:"longitude,omitempty" json:"longitude,omitempty"`
+	// Country es el código ISO 3166-1 alfa-2 del país (ej: "AR") y Region la subdivisión ISO 3166-2 sin el país (ej: "C")
+	// Definen la tasa de impuestos que se aplica al cotizar (vacíos = tasa por defecto)
+	Country string `bson:"country,omitempty" json:"country,omitempty"`
+	Region  string `bson:"region,omitempty" json:"region,omitempty"`
+	// Timezone es la zona horaria IANA de la propiedad (vacío = UTC), usada para horarios de check-in/out
+	Timezone string `bson:"timezone,omitempty" json:"timezone,omitempty"`
 	// Price es el precio por noche de la propiedad
 	Price float64 `bson:"price" json:"price"`
-	// Capacity es la cantidad máxima de huéspedes que puede alojar la propiedad
+	// CleaningFee es el cargo de limpieza que se cobra una vez por reserva (0 = sin cargo)
+	CleaningFee float64 `bson:"cleaningFee,omitempty" json:"cleaningFee,omitempty"`
+	// Capacity es la cantidad máxima de huéspedes que puede alojar la propiedad (los bebés no cuentan)
 	Capacity int `bson:"capacity" json:"capacity"`
+	// GuestsIncluded son los huéspedes incluidos en el precio por noche (0 = todos)
+	// ExtraGuestFee es el cargo por noche de cada huésped adicional (adulto o niño)
+	GuestsIncluded int     `bson:"guestsIncluded,omitempty" json:"guestsIncluded,omitempty"`
+	ExtraGuestFee  float64 `bson:"extraGuestFee,omitempty" json:"extraGuestFee,omitempty"`
+	// ChildFriendly e InfantFriendly indican que la propiedad es apta para niños y para bebés (filtros de búsqueda)
+	ChildFriendly  bool `bson:"childFriendly,omitempty" json:"childFriendly,omitempty"`
+	InfantFriendly bool `bson:"infantFriendly,omitempty" json:"infantFriendly,omitempty"`
+	// HouseRules son las reglas de la casa (nil en las propiedades que no las cargaron)
+	HouseRules *HouseRules `bson:"houseRules,omitempty" json:"houseRules,omitempty"`
 	// Amenities son las comodidades de la propiedad
 	Amenities []string `bson:"amenities" json:"amenities"`
-	// Images es una lista de URLs de imágenes de la propiedad
-	Images []string `bson:"images" json:"images"`
+	// Images son las fotos de la propiedad ordenadas por Position, con una sola portada
+	Images []Photo `bson:"images" json:"images"`
 	// OwnerID es el identificador del usuario propietario de la propiedad
 	OwnerID string `bson:"ownerId" json:"ownerId"`
 	// Available indica si la propiedad está disponible para reserva
 	Available bool `bson:"available" json:"available"`
-	// CreatedAt es la fecha y hora de creación del registro
+	// Views es la cantidad de veces que se vio el detalle de la propiedad
+	Views int64 `bson:"views,omitempty" json:"views,omitempty"`
+	// BookedRanges son los rangos ocupados (reservas activas y bloqueos) desde el día en que se calcularon
+	// y NextAvailableDate el primer día libre; se recalculan en cada cambio de disponibilidad para search-api
+	BookedRanges      []DateRange `bson:"bookedRanges,omitempty" json:"bookedRanges,omitempty"`
+	NextAvailableDate string      `bson:"nextAvailableDate,omitempty" json:"nextAvailableDate,omitempty"`
+	// Signature es la firma de similitud (título + ubicación + owner) usada para detectar duplicados
+	Signature string `bson:"signature,omitempty" json:"-"`
+	// DuplicateOf es el ID de la propiedad de la que esta es un probable duplicado (pendiente de revisión)
+	DuplicateOf string `bson:"duplicateOf,omitempty" json:"duplicateOf,omitempty"`
+	// ModerationStatus es el estado de moderación del título y la descripción (vacío = publicada sin cambios retenidos)
+	ModerationStatus string `bson:"moderationStatus,omitempty" json:"moderationStatus,omitempty"`
+	// BookingMode indica si las reservas se confirman al instante o las tiene que aprobar el anfitrión (vacío = instantánea)
+	BookingMode string `bson:"bookingMode,omitempty" json:"bookingMode,omitempty"`
+	// CancellationPolicy es la política de cancelación que se muestra al reservar y se filtra en la búsqueda (vacío = flexible)
+	CancellationPolicy string `bson:"cancellationPolicy,omitempty" json:"cancellationPolicy,omitempty"`
+	// LastViewedAt es la fecha del último volcado de vistas (se usa para las tendencias)
+	LastViewedAt *time.Time `bson:"lastViewedAt,omitempty" json:"lastViewedAt,omitempty"`
+	// CreatedAt es la fecha y hora de creación del registro (UTC)
 	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
-	// UpdatedAt es la fecha y hora de última actualización
+	// UpdatedAt es la fecha y hora de última actualización (UTC)
 	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
 }
 
+// HouseRules son las reglas de la casa que el huésped acepta al reservar
+type HouseRules struct {
+	// PetsAllowed, SmokingAllowed y EventsAllowed indican si se permiten mascotas, fumar y fiestas o eventos
+	PetsAllowed    bool `bson:"petsAllowed" json:"petsAllowed"`
+	SmokingAllowed bool `bson:"smokingAllowed" json:"smokingAllowed"`
+	EventsAllowed  bool `bson:"eventsAllowed" json:"eventsAllowed"`
+	// QuietHoursStart y QuietHoursEnd son el horario de silencio en hora local de la propiedad ("HH:MM", vacíos = sin horario)
+	// Si el fin es anterior al inicio el horario cruza la medianoche (ej: 22:00 a 08:00)
+	QuietHoursStart string `bson:"quietHoursStart,omitempty" json:"quietHoursStart,omitempty"`
+	QuietHoursEnd   string `bson:"quietHoursEnd,omitempty" json:"quietHoursEnd,omitempty"`
+}
+
+// DefaultPropertyLanguage es el idioma del título y la descripción de las propiedades que no lo indican
+const DefaultPropertyLanguage = "es"
+
+// PropertyTranslation es el título y la descripción de la propiedad en otro idioma
+type PropertyTranslation struct {
+	Title       string `bson:"title" json:"title"`
+	Description string `bson:"description" json:"description"`
+}
+
+// Modos de reserva de una propiedad
+const (
+	// BookingModeInstant confirma la reserva en el momento
+	BookingModeInstant = "instant"
+	// BookingModeRequest crea la reserva pendiente hasta que el anfitrión la aprueba o la rechaza
+	BookingModeRequest = "request"
+)
+
+// Políticas de cancelación de una propiedad
+const (
+	// CancellationFlexible permite cancelar sin cargo hasta 24 horas antes del check-in
+	CancellationFlexible = "flexible"
+	// CancellationModerate permite cancelar sin cargo hasta 5 días antes del check-in
+	CancellationModerate = "moderate"
+	// CancellationStrict solo reembolsa la mitad si se cancela hasta 7 días antes del check-in
+	CancellationStrict = "strict"
+)
+
+// RequiresApproval indica si las reservas de la propiedad las tiene que aprobar el anfitrión
+func (p Property) RequiresApproval() bool {
+	return p.BookingMode == BookingModeRequest
+}
+
+// Estados de una reserva
+const (
+	BookingPending   = "pending"   // Solicitud esperando la respuesta del anfitrión
+	BookingConfirmed = "confirmed" // Reserva confirmada (al instante o aprobada)
+	BookingCancelled = "cancelled" // Cancelada después de confirmada
+	BookingDeclined  = "declined"  // Solicitud rechazada por el anfitrión
+	BookingExpired   = "expired"   // Solicitud que el anfitrión no respondió a tiempo
+)
+
+// ActiveBookingStatuses son los estados que ocupan las fechas de la propiedad
+// Una solicitud pendiente bloquea las fechas hasta que se rechaza o vence
+var ActiveBookingStatuses = []string{BookingPending, BookingConfirmed}
+
 type Booking struct {
 	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	PropertyID string             `bson:"propertyId" json:"propertyId"`
@@ -41,18 +142,95 @@ type Booking struct {
 	CheckIn    time.Time          `bson:"checkIn" json:"checkIn"`
 	CheckOut   time.Time          `bson:"checkOut" json:"checkOut"`
 	TotalPrice float64            `bson:"totalPrice" json:"totalPrice"`
-	Status     string             `bson:"status" json:"status"` // "pending", "confirmed", "cancelled"
+	Status     string             `bson:"status" json:"status"` // "pending", "confirmed", "cancelled", "declined", "expired"
 	CreatedAt  time.Time          `bson:"createdAt" json:"createdAt"`
+	// Adults, Children e Infants son los huéspedes por edad (0 en las reservas anteriores al detalle)
+	Adults   int `bson:"adults,omitempty" json:"adults,omitempty"`
+	Children int `bson:"children,omitempty" json:"children,omitempty"`
+	Infants  int `bson:"infants,omitempty" json:"infants,omitempty"`
+	// ExpiresAt es hasta cuándo el anfitrión puede responder una solicitud pendiente (nil en reservas instantáneas)
+	ExpiresAt *time.Time `bson:"expiresAt,omitempty" json:"expiresAt,omitempty"`
+	// PriceBreakdown es el detalle del precio cotizado al reservar (nil en reservas anteriores)
+	// Se guarda tal cual para resolver disputas aunque después cambien los precios o las tarifas
+	PriceBreakdown *PriceBreakdown `bson:"priceBreakdown,omitempty" json:"priceBreakdown,omitempty"`
+}
+
+// IsActive indica si la reserva ocupa las fechas de la propiedad (pendiente o confirmada)
+func (b Booking) IsActive() bool {
+	return b.Status == BookingPending || b.Status == BookingConfirmed
+}
+
+// PriceBreakdown es el detalle del precio de una estadía
+// Cada importe está redondeado a 2 decimales y Total es la suma de los importes redondeados
+// (Discount se resta)
+type PriceBreakdown struct {
+	// Nights es el precio de cada noche en las fechas locales de la propiedad
+	Nights         []NightlyPrice `bson:"nights" json:"nights"`
+	NightsSubtotal float64        `bson:"nightsSubtotal" json:"nightsSubtotal"`
+	CleaningFee    float64        `bson:"cleaningFee" json:"cleaningFee"`
+	// ExtraGuests son los huéspedes por encima de los incluidos, ExtraGuestFee el cargo por noche de cada uno
+	// y ExtraGuestFees el total (huéspedes x cargo x noches)
+	ExtraGuests    int     `bson:"extraGuests,omitempty" json:"extraGuests,omitempty"`
+	ExtraGuestFee  float64 `bson:"extraGuestFee,omitempty" json:"extraGuestFee,omitempty"`
+	ExtraGuestFees float64 `bson:"extraGuestFees,omitempty" json:"extraGuestFees,omitempty"`
+	// CouponCode y Discount son el cupón aplicado y su descuento sobre noches + limpieza + huéspedes extra (se resta del total)
+	CouponCode string  `bson:"couponCode,omitempty" json:"couponCode,omitempty"`
+	Discount   float64 `bson:"discount,omitempty" json:"discount,omitempty"`
+	// ServiceFeeRate y TaxRate son las tasas vigentes al cotizar
+	ServiceFeeRate float64 `bson:"serviceFeeRate" json:"serviceFeeRate"`
+	ServiceFee     float64 `bson:"serviceFee" json:"serviceFee"`
+	TaxRate        float64 `bson:"taxRate" json:"taxRate"`
+	// TaxJurisdiction es de dónde salió TaxRate: "AR-C", "AR" o "default"
+	TaxJurisdiction string  `bson:"taxJurisdiction,omitempty" json:"taxJurisdiction,omitempty"`
+	Taxes           float64 `bson:"taxes" json:"taxes"`
+	Total           float64 `bson:"total" json:"total"`
+}
+
+// NightlyPrice es el precio de una noche de la estadía
+type NightlyPrice struct {
+	Date  string  `bson:"date" json:"date"` // Día local de la propiedad (YYYY-MM-DD)
+	Price float64 `bson:"price" json:"price"`
+}
+
+// BookingStats son las estadísticas de reservas de una propiedad calculadas con una agregación
+type BookingStats struct {
+	// TotalBookings y CancelledBookings cuentan todas las reservas históricas
+	TotalBookings     int64 `bson:"totalBookings"`
+	CancelledBookings int64 `bson:"cancelledBookings"`
+	// TotalRevenue suma el precio de las reservas confirmadas
+	TotalRevenue float64 `bson:"totalRevenue"`
+	// WindowBookings, WindowRevenue y BookedNights se calculan sobre las reservas
+	// confirmadas que se superponen con la ventana consultada
+	WindowBookings int64   `bson:"windowBookings"`
+	WindowRevenue  float64 `bson:"windowRevenue"`
+	BookedNights   float64 `bson:"bookedNights"`
 }
 
 // PropertyUpdate representa los campos actualizables de una propiedad
 type PropertyUpdate struct {
-	Title       *string   `json:"title,omitempty" bson:"title,omitempty"`
-	Description *string   `json:"description,omitempty" bson:"description,omitempty"`
-	Price       *float64  `json:"price,omitempty" bson:"price,omitempty"`
-	Location    *string   `json:"location,omitempty" bson:"location,omitempty"`
-	Amenities   *[]string `json:"amenities,omitempty" bson:"amenities,omitempty"`
-	Capacity    *int      `json:"capacity,omitempty" bson:"capacity,omitempty"`
-	Available   *bool     `json:"available,omitempty" bson:"available,omitempty"`
-	UpdatedAt   time.Time `bson:"updatedAt" json:"updatedAt"`
+	Title        *string   `json:"title,omitempty" bson:"title,omitempty"`
+	Description  *string   `json:"description,omitempty" bson:"description,omitempty"`
+	Price        *float64  `json:"price,omitempty" bson:"price,omitempty"`
+	CleaningFee  *float64  `json:"cleaningFee,omitempty" bson:"cleaningFee,omitempty"`
+	Location     *string   `json:"location,omitempty" bson:"location,omitempty"`
+	PropertyType *string   `json:"propertyType,omitempty" bson:"propertyType,omitempty"`
+	Latitude     *float64  `json:"latitude,omitempty" bson:"latitude,omitempty"`
+	Longitude    *float64  `json:"longitude,omitempty" bson:"longitude,omitempty"`
+	Country      *string   `json:"country,omitempty" bson:"country,omitempty"`
+	Region       *string   `json:"region,omitempty" bson:"region,omitempty"`
+	Timezone     *string   `json:"timezone,omitempty" bson:"timezone,omitempty"`
+	Amenities    *[]string `json:"amenities,omitempty" bson:"amenities,omitempty"`
+	Capacity     *int      `json:"capacity,omitempty" bson:"capacity,omitempty"`
+	Available    *bool     `json:"available,omitempty" bson:"available,omitempty"`
+	UpdatedAt    time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+// PriceHistoryEntry representa un cambio de precio de una propiedad
+type PriceHistoryEntry struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	PropertyID string             `bson:"propertyId" json:"propertyId"`
+	OldPrice   float64            `bson:"oldPrice" json:"oldPrice"`
+	NewPrice   float64            `bson:"newPrice" json:"newPrice"`
+	ChangedBy  string             `bson:"changedBy" json:"changedBy"`
+	ChangedAt  time.Time          `bson:"changedAt" json:"changedAt"`
 }
@@ -0,0 +1,51 @@
+package dto
+
+import "time"
+
+// AdminAnalyticsResponse son los KPIs del panel de admin (GET /api/admin/analytics)
+// Los días son UTC; To es hoy, que todavía está en curso
+type AdminAnalyticsResponse struct {
+	From   string          `json:"from"` // Primer día (YYYY-MM-DD)
+	To     string          `json:"to"`   // Último día, inclusive (YYYY-MM-DD)
+	Totals KPICounts       `json:"totals"`
+	Funnel []FunnelStepDTO `json:"funnel"`
+	Days   []DailyKPIsDTO  `json:"days"`
+
+	// Incomplete indica que algún día del período tiene fuentes sin contar (ver DailyKPIsDTO.Incomplete)
+	Incomplete bool `json:"incomplete"`
+
+	// LastComputedAt es la última vez que la agregación recalculó algún día del período (nil = nunca)
+	LastComputedAt *time.Time `json:"lastComputedAt,omitempty"`
+}
+
+// KPICounts son los contadores de un día o los totales del período
+type KPICounts struct {
+	NewUsers           int64 `json:"newUsers"`
+	ListingsCreated    int64 `json:"listingsCreated"`
+	Searches           int64 `json:"searches"`
+	ZeroResultSearches int64 `json:"zeroResultSearches"`
+	ClickedSearches    int64 `json:"clickedSearches"`
+	Clicks             int64 `json:"clicks"`
+	BookingsCreated    int64 `json:"bookingsCreated"`
+	BookingsConfirmed  int64 `json:"bookingsConfirmed"`
+}
+
+// DailyKPIsDTO son los KPIs de un día
+type DailyKPIsDTO struct {
+	Date string `json:"date"`
+	KPICounts
+
+	// Incomplete son las fuentes que no se pudieron contar: "users", "searches" o "notComputed"
+	// si la agregación todavía no pasó por el día
+	Incomplete []string `json:"incomplete,omitempty"`
+}
+
+// FunnelStepDTO es un paso del embudo de conversión del período
+type FunnelStepDTO struct {
+	Step  string `json:"step"`
+	Count int64  `json:"count"`
+
+	// ConversionFromPrevious y ConversionFromStart son Count sobre el paso anterior y sobre el primero (0 a 1)
+	ConversionFromPrevious float64 `json:"conversionFromPrevious"`
+	ConversionFromStart    float64 `json:"conversionFromStart"`
+}
@@ -0,0 +1,39 @@
+package dto
+
+import "time"
+
+// AuditQuery DTO con los query params de la consulta del log de auditoría (solo admin)
+// From y To aceptan una fecha (2006-01-02) o un timestamp RFC3339
+type AuditQuery struct {
+	UserID string `form:"userId"`
+	From   string `form:"from"`
+	To     string `form:"to"`
+	Page   int    `form:"page" binding:"omitempty,min=1"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// AuditChangeDTO DTO con el valor anterior y el nuevo de un campo modificado
+type AuditChangeDTO struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditEntryDTO DTO de un registro del log de auditoría
+type AuditEntryDTO struct {
+	ID         string                    `json:"id"`
+	ActorID    string                    `json:"actorId"`
+	Action     string                    `json:"action"`
+	EntityType string                    `json:"entityType"`
+	EntityID   string                    `json:"entityId"`
+	Changes    map[string]AuditChangeDTO `json:"changes"`
+	CreatedAt  time.Time                 `json:"createdAt"`
+}
+
+// AuditListResponseDTO DTO de respuesta de la consulta paginada del log de auditoría
+type AuditListResponseDTO struct {
+	Entries    []AuditEntryDTO `json:"entries"`
+	Page       int             `json:"page"`
+	Limit      int             `json:"limit"`
+	Total      int64           `json:"total"`
+	TotalPages int             `json:"totalPages"`
+}
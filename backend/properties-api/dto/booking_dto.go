@@ -1,21 +1,147 @@
 package dto
 
-import "time"
+import "strconv"
 
+// Los timestamps de BookingDTO van en UTC (RFC3339); CheckInLocal/CheckOutLocal
+// muestran los mismos instantes en la zona horaria de la propiedad
+
+// BookingCreateDTO es el request para reservar; el huésped es el usuario autenticado
+// CheckIn y CheckOut son días locales de la propiedad (YYYY-MM-DD)
+// Los huéspedes se indican por edad; guests (sin el detalle) se toma como la cantidad de adultos
 type BookingCreateDTO struct {
-	PropertyID string    `json:"propertyId" binding:"required"`
-	UserID     string    `json:"userId" binding:"required"`
-	CheckIn    time.Time `json:"checkIn" binding:"required"`
-	CheckOut   time.Time `json:"checkOut" binding:"required"`
+	PropertyID string `json:"propertyId" binding:"required"`
+	CheckIn    string `json:"checkIn" binding:"required"`
+	CheckOut   string `json:"checkOut" binding:"required"`
+	Guests     int    `json:"guests" binding:"omitempty,gte=1"`
+	Adults     int    `json:"adults" binding:"omitempty,gte=1"`
+	Children   int    `json:"children" binding:"omitempty,gte=0"`
+	Infants    int    `json:"infants" binding:"omitempty,gte=0"`
+	CouponCode string `json:"couponCode"` // Opcional: código promocional
+	// HoldID es el hold de POST /bookings/hold con el que se reservan las fechas retenidas (opcional)
+	HoldID string `json:"holdId"`
+}
+
+// BookingHoldDTO es un hold de fechas para un checkout en curso, con la cotización de la estadía
+// Para reservar se manda el mismo request a POST /bookings con holdId antes de ExpiresAt
+type BookingHoldDTO struct {
+	ID         string            `json:"id"`
+	PropertyID string            `json:"propertyId"`
+	CheckIn    string            `json:"checkIn"`   // Día local de la propiedad (YYYY-MM-DD)
+	CheckOut   string            `json:"checkOut"`  // Día local de la propiedad (YYYY-MM-DD)
+	ExpiresAt  string            `json:"expiresAt"` // UTC, RFC3339
+	Breakdown  PriceBreakdownDTO `json:"breakdown"`
+}
+
+// Party retorna los huéspedes pedidos; sin detalle por edad guests son todos adultos
+func (b BookingCreateDTO) Party() GuestCountsDTO {
+	if b.Adults == 0 && b.Children == 0 && b.Infants == 0 {
+		return GuestCountsDTO{Adults: b.Guests}
+	}
+	return GuestCountsDTO{Adults: b.Adults, Children: b.Children, Infants: b.Infants}
+}
+
+// GuestCountsDTO son los huéspedes de una estadía por edad
+// Los bebés no ocupan lugar: no cuentan para la capacidad ni pagan el cargo por huésped extra
+type GuestCountsDTO struct {
+	Adults   int `json:"adults"`
+	Children int `json:"children"`
+	Infants  int `json:"infants"`
+}
+
+// Occupants retorna los huéspedes que cuentan para la capacidad (adultos y niños)
+func (g GuestCountsDTO) Occupants() int {
+	return g.Adults + g.Children
+}
+
+// BookingQuoteDTO es la cotización de una estadía con el detalle del precio
+type BookingQuoteDTO struct {
+	PropertyID string            `json:"propertyId"`
+	CheckIn    string            `json:"checkIn"`  // Día local de la propiedad (YYYY-MM-DD)
+	CheckOut   string            `json:"checkOut"` // Día local de la propiedad (YYYY-MM-DD)
+	Guests     int               `json:"guests"`   // Adultos + niños
+	Adults     int               `json:"adults"`
+	Children   int               `json:"children"`
+	Infants    int               `json:"infants"`
+	Nights     int               `json:"nights"`
+	Breakdown  PriceBreakdownDTO `json:"breakdown"`
+}
+
+// PriceBreakdownDTO detalla el precio de una estadía en líneas separadas
+// Total es NightsSubtotal + CleaningFee + ExtraGuestFees - Discount + ServiceFee + Taxes
+type PriceBreakdownDTO struct {
+	Nights         []NightlyPriceDTO `json:"nights"`
+	NightsSubtotal float64           `json:"nightsSubtotal"`
+	CleaningFee    float64           `json:"cleaningFee"`
+	// ExtraGuests son los huéspedes por encima de los incluidos en el precio, con el cargo por noche de cada uno
+	ExtraGuests    int     `json:"extraGuests,omitempty"`
+	ExtraGuestFee  float64 `json:"extraGuestFee,omitempty"`
+	ExtraGuestFees float64 `json:"extraGuestFees,omitempty"`
+	// CouponCode y Discount son el cupón aplicado y el descuento que se resta del total
+	CouponCode     string  `json:"couponCode,omitempty"`
+	Discount       float64 `json:"discount,omitempty"`
+	ServiceFeeRate float64 `json:"serviceFeeRate"`
+	ServiceFee     float64 `json:"serviceFee"`
+	TaxRate        float64 `json:"taxRate"`
+	// TaxJurisdiction es el país o región cuya tasa se aplicó ("AR-C", "AR" o "default")
+	TaxJurisdiction string  `json:"taxJurisdiction,omitempty"`
+	Taxes           float64 `json:"taxes"`
+	Total           float64 `json:"total"`
+}
+
+// NightlyPriceDTO es el precio de una noche (Date es el día local de la propiedad)
+type NightlyPriceDTO struct {
+	Date  string  `json:"date"`
+	Price float64 `json:"price"`
 }
 
 type BookingDTO struct {
-	ID         string    `json:"id"`
-	PropertyID string    `json:"propertyId"`
-	UserID     string    `json:"userId"`
-	CheckIn    time.Time `json:"checkIn"`
-	CheckOut   time.Time `json:"checkOut"`
-	TotalPrice float64   `json:"totalPrice"`
-	Status     string    `json:"status"`
-	CreatedAt  time.Time `json:"createdAt"`
+	ID            string  `json:"id"`
+	PropertyID    string  `json:"propertyId"`
+	UserID        string  `json:"userId"`
+	CheckIn       string  `json:"checkIn"`
+	CheckOut      string  `json:"checkOut"`
+	CheckInLocal  string  `json:"checkInLocal,omitempty"`
+	CheckOutLocal string  `json:"checkOutLocal,omitempty"`
+	Timezone      string  `json:"timezone,omitempty"`
+	TotalPrice    float64 `json:"totalPrice"`
+	Status        string  `json:"status"`
+	CreatedAt     string  `json:"createdAt"`
+	// Adults, Children e Infants son los huéspedes de la reserva (vacíos en reservas anteriores)
+	Adults   int `json:"adults,omitempty"`
+	Children int `json:"children,omitempty"`
+	Infants  int `json:"infants,omitempty"`
+	// ExpiresAt es hasta cuándo el anfitrión puede responder una solicitud pendiente (UTC, RFC3339)
+	ExpiresAt string `json:"expiresAt,omitempty"`
+	// PriceBreakdown es el detalle guardado al reservar (no se exporta en CSV)
+	PriceBreakdown *PriceBreakdownDTO `json:"priceBreakdown,omitempty"`
+}
+
+// CSVHeader implementa utils.CSVRecord
+func (b BookingDTO) CSVHeader() []string {
+	return []string{"id", "propertyId", "userId", "checkIn", "checkOut", "checkInLocal", "checkOutLocal", "timezone", "totalPrice", "status", "createdAt"}
+}
+
+// CSVRow implementa utils.CSVRecord
+func (b BookingDTO) CSVRow() []string {
+	return []string{
+		b.ID,
+		b.PropertyID,
+		b.UserID,
+		b.CheckIn,
+		b.CheckOut,
+		b.CheckInLocal,
+		b.CheckOutLocal,
+		b.Timezone,
+		strconv.FormatFloat(b.TotalPrice, 'f', 2, 64),
+		b.Status,
+		b.CreatedAt,
+	}
+}
+
+// UserDataExportDTO contiene todos los datos que properties-api guarda de un usuario (export GDPR)
+type UserDataExportDTO struct {
+	// Properties son las propiedades publicadas por el usuario
+	Properties []PropertyResponseDTO `json:"properties"`
+	// Bookings son las reservas hechas por el usuario como huésped
+	Bookings []BookingDTO `json:"bookings"`
 }
@@ -0,0 +1,49 @@
+package dto
+
+import "time"
+
+// CalendarFeedImportDTO DTO para importar un calendario iCal externo (Airbnb, Booking...)
+type CalendarFeedImportDTO struct {
+	Name string `json:"name" binding:"required,max=100"`
+	URL  string `json:"url" binding:"required,url"`
+}
+
+// CalendarFeedDTO DTO de respuesta de un calendario externo
+type CalendarFeedDTO struct {
+	ID           string     `json:"id"`
+	PropertyID   string     `json:"propertyId"`
+	Name         string     `json:"name"`
+	URL          string     `json:"url"`
+	LastSyncedAt *time.Time `json:"lastSyncedAt,omitempty"`
+	LastError    string     `json:"lastError,omitempty"`
+	// BlockedRanges es la cantidad de rangos bloqueados importados en la última sincronización
+	BlockedRanges int       `json:"blockedRanges"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// AvailabilityRangeDTO es un rango de fechas en que la propiedad no está disponible
+// Las fechas son días locales de la propiedad (YYYY-MM-DD); End es exclusivo (día de check-out)
+type AvailabilityRangeDTO struct {
+	Start  string `json:"start"`
+	End    string `json:"end"`
+	Source string `json:"source"` // "booking" (reserva), "block" (bloqueo de un calendario externo) o "manual" (bloqueo del owner)
+}
+
+// DateRangeDTO es un rango de días locales de la propiedad (YYYY-MM-DD); End es exclusivo
+type DateRangeDTO struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// AvailabilityRangeUpdateDTO es un rango de días locales de la propiedad a bloquear o abrir (End exclusivo)
+type AvailabilityRangeUpdateDTO struct {
+	Start  string `json:"start" binding:"required,datetime=2006-01-02"`
+	End    string `json:"end" binding:"required,datetime=2006-01-02"`
+	Action string `json:"action" binding:"required,oneof=block open"`
+}
+
+// BulkAvailabilityUpdateDTO DTO para bloquear o abrir varios rangos de fechas de una vez (solo owner o admin)
+// Los rangos se aplican en orden: abrir una semana dentro de un mes bloqueado deja el resto del mes bloqueado
+type BulkAvailabilityUpdateDTO struct {
+	Ranges []AvailabilityRangeUpdateDTO `json:"ranges" binding:"required,min=1,max=100,dive"`
+}
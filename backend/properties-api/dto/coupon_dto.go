@@ -0,0 +1,45 @@
+package dto
+
+import "time"
+
+// CouponCreateDTO DTO para crear un cupón (solo admin)
+// Value es un porcentaje (0-100] si Type es "percentage" o un monto si es "fixed"
+type CouponCreateDTO struct {
+	Code           string     `json:"code" binding:"required,min=3,max=32"`
+	Type           string     `json:"type" binding:"required,oneof=percentage fixed"`
+	Value          float64    `json:"value" binding:"required,gt=0"`
+	MaxRedemptions int64      `json:"maxRedemptions" binding:"gte=0"`
+	MaxPerUser     int64      `json:"maxPerUser" binding:"gte=0"`
+	ValidFrom      *time.Time `json:"validFrom"`
+	ValidUntil     *time.Time `json:"validUntil"`
+}
+
+// CouponDTO DTO de respuesta de un cupón
+type CouponDTO struct {
+	ID             string     `json:"id"`
+	Code           string     `json:"code"`
+	Type           string     `json:"type"`
+	Value          float64    `json:"value"`
+	MaxRedemptions int64      `json:"maxRedemptions"`
+	MaxPerUser     int64      `json:"maxPerUser"`
+	Redemptions    int64      `json:"redemptions"`
+	ValidFrom      *time.Time `json:"validFrom,omitempty"`
+	ValidUntil     *time.Time `json:"validUntil,omitempty"`
+	Active         bool       `json:"active"`
+	CreatedBy      string     `json:"createdBy"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+// CouponRedemptionDTO DTO de respuesta del uso de un cupón en una reserva
+type CouponRedemptionDTO struct {
+	BookingID  string    `json:"bookingId"`
+	UserID     string    `json:"userId"`
+	Discount   float64   `json:"discount"`
+	RedeemedAt time.Time `json:"redeemedAt"`
+}
+
+// CouponRedemptionsDTO DTO de respuesta con un cupón y sus usos, el más reciente primero
+type CouponRedemptionsDTO struct {
+	Coupon      CouponDTO             `json:"coupon"`
+	Redemptions []CouponRedemptionDTO `json:"redemptions"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+import "time"
+
+// EventReplayRequestDTO DTO para republicar los eventos de las propiedades modificadas en un rango (solo admin)
+// From y To aceptan una fecha (2006-01-02) o un timestamp RFC3339; To vacío es ahora
+// Con DryRun solo se cuentan las propiedades, sin publicar
+type EventReplayRequestDTO struct {
+	From   string `json:"from" binding:"required"`
+	To     string `json:"to"`
+	DryRun bool   `json:"dryRun"`
+}
+
+// EventReplayResultDTO DTO de respuesta con el resultado de la republicación
+type EventReplayResultDTO struct {
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	DryRun    bool      `json:"dryRun"`
+	Matched   int       `json:"matched"`   // Propiedades modificadas en el rango
+	Created   int       `json:"created"`   // Eventos "create" (propiedades creadas en el rango)
+	Updated   int       `json:"updated"`   // Eventos "update"
+	Skipped   int       `json:"skipped"`   // Propiedades no publicadas (moderación pendiente o rechazada)
+	Failed    int       `json:"failed"`    // Eventos que no se pudieron publicar
+	FailedIDs []string  `json:"failedIds"` // Primeras propiedades con error, para reintentarlas
+}
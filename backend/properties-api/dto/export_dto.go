@@ -0,0 +1,21 @@
+package dto
+
+// PropertyExportQuery DTO con los filtros del export de propiedades (solo admin)
+// From y To filtran por fecha de creación y aceptan una fecha (2006-01-02) o un timestamp RFC3339
+type PropertyExportQuery struct {
+	OwnerID      string `form:"ownerId"`
+	PropertyType string `form:"type"`
+	Available    *bool  `form:"available"`
+	From         string `form:"from"`
+	To           string `form:"to"`
+}
+
+// BookingExportQuery DTO con los filtros del export de reservas (solo admin)
+// From y To filtran por fecha de check-in y aceptan una fecha (2006-01-02) o un timestamp RFC3339
+type BookingExportQuery struct {
+	PropertyID string `form:"propertyId"`
+	UserID     string `form:"userId"`
+	Status     string `form:"status" binding:"omitempty,oneof=pending confirmed cancelled"`
+	From       string `form:"from"`
+	To         string `form:"to"`
+}
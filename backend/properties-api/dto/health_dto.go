@@ -0,0 +1,24 @@
+package dto
+
+// Estados reportados por los health checks
+// "degraded" indica que falla una dependencia opcional pero el servicio sigue recibiendo tráfico
+const (
+	HealthStatusUp       = "up"
+	HealthStatusDown     = "down"
+	HealthStatusDegraded = "degraded"
+)
+
+// DependencyHealthDTO es el resultado del chequeo de una dependencia
+type DependencyHealthDTO struct {
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latencyMs"`
+	Error     string `json:"error,omitempty"`
+}
+
+// HealthResponseDTO es la respuesta de /health/live y /health/ready
+// Dependencies solo se incluye en readiness
+type HealthResponseDTO struct {
+	Status       string                         `json:"status"`
+	Service      string                         `json:"service"`
+	Dependencies map[string]DependencyHealthDTO `json:"dependencies,omitempty"`
+}
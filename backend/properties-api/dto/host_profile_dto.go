@@ -0,0 +1,16 @@
+package dto
+
+import "time"
+
+// HostProfileResponseDTO es el perfil público del anfitrión de una propiedad (GET /api/properties/:id/host)
+// Viene de users-api: no incluye email ni teléfono, solo si el anfitrión tiene uno cargado
+type HostProfileResponseDTO struct {
+	UserID      string    `json:"userId"`
+	DisplayName string    `json:"displayName"`
+	AvatarURL   string    `json:"avatarUrl,omitempty"`
+	Bio         string    `json:"bio,omitempty"`
+	Languages   []string  `json:"languages"`
+	City        string    `json:"city,omitempty"`
+	HasPhone    bool      `json:"hasPhone"`
+	MemberSince time.Time `json:"memberSince"`
+}
@@ -0,0 +1,11 @@
+package dto
+
+// HouseRulesDTO representa las reglas de la casa de una propiedad
+// QuietHoursStart y QuietHoursEnd van en hora local de la propiedad ("HH:MM"), los dos o ninguno
+type HouseRulesDTO struct {
+	PetsAllowed     bool   `json:"petsAllowed"`
+	SmokingAllowed  bool   `json:"smokingAllowed"`
+	EventsAllowed   bool   `json:"eventsAllowed"`
+	QuietHoursStart string `json:"quietHoursStart,omitempty"`
+	QuietHoursEnd   string `json:"quietHoursEnd,omitempty"`
+}
@@ -0,0 +1,43 @@
+package dto
+
+import "time"
+
+// SendMessageDTO DTO para enviar un mensaje
+type SendMessageDTO struct {
+	Body string `json:"body" binding:"required,max=2000"`
+}
+
+// MessageDTO DTO de respuesta de un mensaje
+type MessageDTO struct {
+	ID             string    `json:"id"`
+	ConversationID string    `json:"conversationId"`
+	SenderID       string    `json:"senderId"`
+	Body           string    `json:"body"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// ConversationDTO DTO de respuesta de una conversación
+// Unread son los mensajes sin leer del usuario que hace la consulta
+type ConversationDTO struct {
+	ID            string    `json:"id"`
+	PropertyID    string    `json:"propertyId"`
+	GuestID       string    `json:"guestId"`
+	HostID        string    `json:"hostId"`
+	LastMessage   string    `json:"lastMessage,omitempty"`
+	LastMessageAt time.Time `json:"lastMessageAt"`
+	Unread        int64     `json:"unread"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// MessagePageDTO DTO de respuesta de una página de mensajes, del más reciente al más antiguo
+// NextBefore es el valor de "before" para pedir la página siguiente (ausente si no hay más)
+type MessagePageDTO struct {
+	Conversation ConversationDTO `json:"conversation"`
+	Messages     []MessageDTO    `json:"messages"`
+	NextBefore   *time.Time      `json:"nextBefore,omitempty"`
+}
+
+// UnreadCountDTO DTO de respuesta con el total de mensajes sin leer del usuario
+type UnreadCountDTO struct {
+	Unread int64 `json:"unread"`
+}
@@ -0,0 +1,31 @@
+package dto
+
+import "time"
+
+// ModerationFlagDTO DTO del motivo por el que se retuvo un campo
+type ModerationFlagDTO struct {
+	Check  string `json:"check"`
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// ModerationCaseDTO DTO de respuesta de un caso de moderación
+type ModerationCaseDTO struct {
+	ID          string              `json:"id"`
+	EntityType  string              `json:"entityType"`
+	EntityID    string              `json:"entityId"`
+	SubmittedBy string              `json:"submittedBy"`
+	Content     map[string]string   `json:"content"` // Textos retenidos por campo (ej: title, description)
+	Flags       []ModerationFlagDTO `json:"flags"`
+	Status      string              `json:"status"`
+	ReviewedBy  string              `json:"reviewedBy,omitempty"`
+	ReviewedAt  *time.Time          `json:"reviewedAt,omitempty"`
+	Note        string              `json:"note,omitempty"`
+	CreatedAt   time.Time           `json:"createdAt"`
+}
+
+// ModerationDecisionDTO DTO para aprobar o rechazar un caso (solo admin)
+// Note es opcional y se le muestra al autor del contenido
+type ModerationDecisionDTO struct {
+	Note string `json:"note" binding:"max=500"`
+}
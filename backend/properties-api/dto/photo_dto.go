@@ -0,0 +1,40 @@
+package dto
+
+// PhotoDTO representa una foto de la galería de una propiedad
+// Status y Variants son de solo lectura: los completa el procesamiento de las fotos subidas
+type PhotoDTO struct {
+	URL      string            `json:"url"`
+	Caption  string            `json:"caption,omitempty"`
+	Position int               `json:"position"`
+	IsCover  bool              `json:"isCover"`
+	Status   string            `json:"status,omitempty"`   // processing, ready o failed (solo fotos subidas)
+	Variants []PhotoVariantDTO `json:"variants,omitempty"` // thumbnail, medium y large en JPEG y WebP
+}
+
+// PhotoVariantDTO es una versión redimensionada de una foto subida
+type PhotoVariantDTO struct {
+	Size   string `json:"size"`
+	Format string `json:"format"`
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// PhotoOrderDTO es el nuevo orden de la galería: las URLs de todas las fotos de la propiedad
+type PhotoOrderDTO struct {
+	URLs []string `json:"urls" binding:"required,min=1"`
+}
+
+// PhotoCoverDTO indica la foto que pasa a ser la portada
+type PhotoCoverDTO struct {
+	URL string `json:"url" binding:"required"`
+}
+
+// photoURLs retorna las URLs de las fotos en el orden recibido
+func photoURLs(photos []PhotoDTO) []string {
+	urls := make([]string, len(photos))
+	for i, photo := range photos {
+		urls[i] = photo.URL
+	}
+	return urls
+}
@@ -1,43 +1,228 @@
 package dto
 
+import (
+	"strconv"
+	"strings"
+)
+
 // PropertyCreateDTO representa el DTO para crear una propiedad
 type PropertyCreateDTO struct {
-	Title       string   `json:"title" binding:"required"`
-	Description string   `json:"description" binding:"required"`
-	Price       float64  `json:"price" binding:"required,gt=0"`
-	Location    string   `json:"location" binding:"required"`
-	OwnerID     string   `json:"ownerId" binding:"required"`
-	Amenities   []string `json:"amenities"`
-	Capacity    int      `json:"capacity" binding:"required,gte=1"`
-	Available   bool     `json:"available"`
-	Images      []string `json:"images"`
+	Title        string     `json:"title" binding:"required"`
+	Description  string     `json:"description" binding:"required"`
+	Price        float64    `json:"price" binding:"required,gt=0"`
+	CleaningFee  float64    `json:"cleaningFee" binding:"omitempty,gte=0"` // Opcional: se cobra una vez por reserva
+	Location     string     `json:"location" binding:"required"`
+	PropertyType string     `json:"propertyType"` // Opcional: casa, apartamento, cabaña, loft, terreno, local, oficina
+	Latitude     float64    `json:"latitude" binding:"omitempty,gte=-90,lte=90"`
+	Longitude    float64    `json:"longitude" binding:"omitempty,gte=-180,lte=180"`
+	Timezone     string     `json:"timezone"`
+	Country      string     `json:"country"` // Opcional: ISO 3166-1 alfa-2 (ej: AR), define los impuestos
+	Region       string     `json:"region"`  // Opcional: subdivisión ISO 3166-2 sin el país (ej: C)
+	OwnerID      string     `json:"ownerId" binding:"required"`
+	Amenities    []string   `json:"amenities"`
+	Capacity     int        `json:"capacity" binding:"required,gte=1"`
+	Available    bool       `json:"available"`
+	Images       []PhotoDTO `json:"images"` // Sin posiciones se respeta el orden; sin portada se usa la primera
+	// BookingMode es opcional: instant (por defecto) confirma al reservar, request requiere la aprobación del anfitrión
+	BookingMode string `json:"bookingMode" binding:"omitempty,oneof=instant request"`
+	// CancellationPolicy es opcional: flexible (por defecto), moderate o strict
+	CancellationPolicy string `json:"cancellationPolicy" binding:"omitempty,oneof=flexible moderate strict"`
+	// GuestsIncluded y ExtraGuestFee son opcionales: los huéspedes (adultos y niños) por encima de los incluidos
+	// pagan ExtraGuestFee por noche cada uno
+	GuestsIncluded int     `json:"guestsIncluded" binding:"omitempty,gte=1"`
+	ExtraGuestFee  float64 `json:"extraGuestFee" binding:"omitempty,gte=0"`
+	ChildFriendly  bool    `json:"childFriendly"`  // Apta para niños
+	InfantFriendly bool    `json:"infantFriendly"` // Apta para bebés
+	// HouseRules es opcional: sin reglas no se permiten mascotas, fumar ni eventos
+	HouseRules *HouseRulesDTO `json:"houseRules"`
+	// Language es el idioma del título y la descripción (opcional, "es" por defecto)
+	// Translations son el título y la descripción en otros idiomas, por locale ("en", "pt-BR")
+	Language     string                            `json:"language"`
+	Translations map[string]PropertyTranslationDTO `json:"translations"`
 }
 
 // PropertyUpdateDTO representa el DTO para actualizar una propiedad
 // Todos los campos son opcionales (punteros)
 type PropertyUpdateDTO struct {
-	Title       *string   `json:"title,omitempty"`
-	Description *string   `json:"description,omitempty"`
-	Price       *float64  `json:"price,omitempty"`
-	Location    *string   `json:"location,omitempty"`
-	Amenities   *[]string `json:"amenities,omitempty"`
-	Capacity    *int      `json:"capacity,omitempty"`
-	Available   *bool     `json:"available,omitempty"`
-	Images      *[]string `json:"images,omitempty"`
+	Title        *string     `json:"title,omitempty"`
+	Description  *string     `json:"description,omitempty"`
+	Price        *float64    `json:"price,omitempty"`
+	CleaningFee  *float64    `json:"cleaningFee,omitempty" binding:"omitempty,gte=0"`
+	Location     *string     `json:"location,omitempty"`
+	PropertyType *string     `json:"propertyType,omitempty"`
+	Latitude     *float64    `json:"latitude,omitempty" binding:"omitempty,gte=-90,lte=90"`
+	Longitude    *float64    `json:"longitude,omitempty" binding:"omitempty,gte=-180,lte=180"`
+	Timezone     *string     `json:"timezone,omitempty"`
+	Country      *string     `json:"country,omitempty"`
+	Region       *string     `json:"region,omitempty"`
+	Amenities    *[]string   `json:"amenities,omitempty"`
+	Capacity     *int        `json:"capacity,omitempty"`
+	Available    *bool       `json:"available,omitempty"`
+	BookingMode  *string     `json:"bookingMode,omitempty" binding:"omitempty,oneof=instant request"`
+	Images       *[]PhotoDTO `json:"images,omitempty"` // Reemplaza la galería completa
+	// GuestsIncluded en 0 quita el cargo por huésped extra
+	GuestsIncluded *int     `json:"guestsIncluded,omitempty" binding:"omitempty,gte=0"`
+	ExtraGuestFee  *float64 `json:"extraGuestFee,omitempty" binding:"omitempty,gte=0"`
+	ChildFriendly  *bool    `json:"childFriendly,omitempty"`
+	InfantFriendly *bool    `json:"infantFriendly,omitempty"`
+	// CancellationPolicy aplica a las reservas nuevas
+	CancellationPolicy *string `json:"cancellationPolicy,omitempty" binding:"omitempty,oneof=flexible moderate strict"`
+	// HouseRules reemplaza todas las reglas de la casa
+	HouseRules *HouseRulesDTO `json:"houseRules,omitempty"`
+	Language   *string        `json:"language,omitempty"`
+	// Translations reemplaza todas las traducciones (vacío las quita)
+	Translations *map[string]PropertyTranslationDTO `json:"translations,omitempty"`
 }
 
 // PropertyResponseDTO representa el DTO de respuesta de una propiedad
 type PropertyResponseDTO struct {
-	ID          string   `json:"id"`
-	Title       string   `json:"title"`
-	Description string   `json:"description"`
-	Price       float64  `json:"price"`
-	Location    string   `json:"location"`
-	OwnerID     string   `json:"ownerId"`
-	Amenities   []string `json:"amenities"`
-	Capacity    int      `json:"capacity"`
-	Available   bool     `json:"available"`
-	Images      []string `json:"images"`
-	CreatedAt   string   `json:"createdAt"`
-	UpdatedAt   string   `json:"updatedAt"`
+	ID           string     `json:"id"`
+	Title        string     `json:"title"`
+	Description  string     `json:"description"`
+	Language     string     `json:"language"` // Idioma del título y la descripción
+	Price        float64    `json:"price"`
+	CleaningFee  float64    `json:"cleaningFee,omitempty"`
+	Location     string     `json:"location"`
+	PropertyType string     `json:"propertyType,omitempty"`
+	Latitude     float64    `json:"latitude,omitempty"`
+	Longitude    float64    `json:"longitude,omitempty"`
+	Timezone     string     `json:"timezone,omitempty"`
+	Country      string     `json:"country,omitempty"`
+	Region       string     `json:"region,omitempty"`
+	OwnerID      string     `json:"ownerId"`
+	Amenities    []string   `json:"amenities"`
+	Capacity     int        `json:"capacity"`
+	Available    bool       `json:"available"`
+	BookingMode  string     `json:"bookingMode"`          // instant o request
+	Images       []PhotoDTO `json:"images"`               // Ordenadas por posición
+	CoverImage   string     `json:"coverImage,omitempty"` // URL de la foto de portada
+	// CoverThumbnail y CoverThumbnailWebP son la miniatura de la portada (solo si es una foto subida ya procesada)
+	CoverThumbnail     string `json:"coverThumbnail,omitempty"`
+	CoverThumbnailWebP string `json:"coverThumbnailWebp,omitempty"`
+	Views              int64  `json:"views"`
+	DuplicateOf        string `json:"duplicateOf,omitempty"` // Propiedad de la que es un probable duplicado
+	ModerationStatus   string `json:"moderationStatus,omitempty"`
+	CreatedAt          string `json:"createdAt"` // UTC, RFC3339
+	UpdatedAt          string `json:"updatedAt"` // UTC, RFC3339
+	// GuestsIncluded y ExtraGuestFee son el cargo por huésped extra (0 = sin cargo)
+	GuestsIncluded int     `json:"guestsIncluded,omitempty"`
+	ExtraGuestFee  float64 `json:"extraGuestFee,omitempty"`
+	ChildFriendly  bool    `json:"childFriendly"`
+	InfantFriendly bool    `json:"infantFriendly"`
+	// CancellationPolicy siempre viene informada (flexible en las propiedades anteriores)
+	CancellationPolicy string `json:"cancellationPolicy"`
+	// HouseRules siempre viene completo: sin reglas cargadas no se permite nada y no hay horario de silencio
+	HouseRules HouseRulesDTO `json:"houseRules"`
+	// BookedRanges son los rangos ocupados al último cambio de disponibilidad y NextAvailableDate el primer
+	// día libre (vacío si la disponibilidad no cambió desde que existe el campo); search-api los indexa
+	BookedRanges      []DateRangeDTO `json:"bookedRanges"`
+	NextAvailableDate string         `json:"nextAvailableDate,omitempty"`
+	// Translations son el título y la descripción en los otros idiomas que cargó el anfitrión
+	Translations map[string]PropertyTranslationDTO `json:"translations,omitempty"`
+	// AutoTranslated indica que el título y la descripción son una traducción automática al idioma del lector
+	AutoTranslated bool `json:"autoTranslated,omitempty"`
+}
+
+// PropertyVersionDTO identifica la versión de una propiedad sin el resto de sus datos
+// search-api la compara con su índice para detectar documentos faltantes, desactualizados o huérfanos
+type PropertyVersionDTO struct {
+	ID        string `json:"id"`
+	OwnerID   string `json:"ownerId"`
+	UpdatedAt string `json:"updatedAt"` // UTC, RFC3339
+}
+
+// PriceHistoryEntryDTO representa un cambio de precio en la respuesta
+type PriceHistoryEntryDTO struct {
+	OldPrice  float64 `json:"oldPrice"`
+	NewPrice  float64 `json:"newPrice"`
+	ChangedAt string  `json:"changedAt"`
+}
+
+// PriceStatsDTO contiene estadísticas de precio para un período
+type PriceStatsDTO struct {
+	MinPrice float64 `json:"minPrice"`
+	MaxPrice float64 `json:"maxPrice"`
+	Changes  int     `json:"changes"`
+}
+
+// PriceHistoryResponseDTO representa el historial de precios de una propiedad
+type PriceHistoryResponseDTO struct {
+	PropertyID   string                 `json:"propertyId"`
+	CurrentPrice float64                `json:"currentPrice"`
+	MinPrice     float64                `json:"minPrice"`
+	MaxPrice     float64                `json:"maxPrice"`
+	Last30Days   PriceStatsDTO          `json:"last30Days"`
+	History      []PriceHistoryEntryDTO `json:"history"`
+}
+
+// CSVHeader implementa utils.CSVRecord
+func (p PropertyResponseDTO) CSVHeader() []string {
+	return []string{"id", "title", "description", "price", "location", "propertyType", "latitude", "longitude", "timezone", "ownerId", "amenities", "capacity", "available", "images", "createdAt", "updatedAt"}
+}
+
+// CSVRow implementa utils.CSVRecord
+func (p PropertyResponseDTO) CSVRow() []string {
+	return []string{
+		p.ID,
+		p.Title,
+		p.Description,
+		strconv.FormatFloat(p.Price, 'f', 2, 64),
+		p.Location,
+		p.PropertyType,
+		strconv.FormatFloat(p.Latitude, 'f', -1, 64),
+		strconv.FormatFloat(p.Longitude, 'f', -1, 64),
+		p.Timezone,
+		p.OwnerID,
+		strings.Join(p.Amenities, "|"),
+		strconv.Itoa(p.Capacity),
+		strconv.FormatBool(p.Available),
+		strings.Join(photoURLs(p.Images), "|"),
+		p.CreatedAt,
+		p.UpdatedAt,
+	}
+}
+
+// DuplicateReviewDTO es una propiedad marcada como probable duplicado junto con la original (solo admin)
+type DuplicateReviewDTO struct {
+	Duplicate PropertyResponseDTO `json:"duplicate"`
+	// Original es nil si la propiedad original ya no existe
+	Original        *PropertyResponseDTO `json:"original,omitempty"`
+	TitleSimilarity float64              `json:"titleSimilarity"`
+}
+
+// PropertyImportRowDTO es el resultado de importar una fila (solo admin)
+type PropertyImportRowDTO struct {
+	Row         int    `json:"row"` // Número de fila de datos (1 = primera fila después del header)
+	ID          string `json:"id,omitempty"`
+	DuplicateOf string `json:"duplicateOf,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// PropertyImportReportDTO es el reporte de una importación masiva de propiedades (solo admin)
+type PropertyImportReportDTO struct {
+	Total    int                    `json:"total"`
+	Imported int                    `json:"imported"`
+	Failed   int                    `json:"failed"`
+	Rows     []PropertyImportRowDTO `json:"rows"`
+}
+
+// UserPropertiesQuery DTO con los filtros, el orden y la página del listado de propiedades de un usuario
+// Sort es el campo con "-" adelante para orden descendente (default -createdAt)
+// Los estados distintos de "published" solo los pueden pedir el owner o un admin
+type UserPropertiesQuery struct {
+	Query     string `form:"q" binding:"max=100"`
+	Status    string `form:"status" binding:"omitempty,oneof=published pending rejected changes_pending"`
+	Available *bool  `form:"available"`
+	Sort      string `form:"sort" binding:"omitempty,oneof=createdAt -createdAt title -title price -price views -views"`
+	Page      int    `form:"page" binding:"omitempty,min=1"`
+	Limit     int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// UserPropertiesResponseDTO DTO de respuesta de una página del listado de propiedades de un usuario
+type UserPropertiesResponseDTO struct {
+	Properties []PropertyResponseDTO `json:"properties"`
+	Page       int                   `json:"page"`
+	Limit      int                   `json:"limit"`
+	Total      int64                 `json:"total"`
+	TotalPages int                   `json:"totalPages"`
 }
@@ -0,0 +1,21 @@
+package dto
+
+import "time"
+
+// PropertyStatsResponse son las estadísticas de una propiedad para su owner
+// Los totales son históricos; ocupación y ingresos del período se calculan sobre la ventana [From, To)
+type PropertyStatsResponse struct {
+	PropertyID          string    `json:"propertyId"`
+	Views               int64     `json:"views"`
+	BookingsCount       int64     `json:"bookingsCount"`
+	CancelledBookings   int64     `json:"cancelledBookings"`
+	Revenue             float64   `json:"revenue"`
+	AverageBookingValue float64   `json:"averageBookingValue"`
+	PeriodBookings      int64     `json:"periodBookings"`
+	PeriodRevenue       float64   `json:"periodRevenue"`
+	BookedNights        float64   `json:"bookedNights"`
+	OccupancyRate       float64   `json:"occupancyRate"`
+	From                time.Time `json:"from"`
+	To                  time.Time `json:"to"`
+	CalculatedAt        time.Time `json:"calculatedAt"`
+}
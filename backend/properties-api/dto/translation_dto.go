@@ -0,0 +1,7 @@
+package dto
+
+// PropertyTranslationDTO es el título y la descripción de una propiedad en otro idioma
+type PropertyTranslationDTO struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
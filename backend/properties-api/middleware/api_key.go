@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"properties-api/clients"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// apiKeyIdentityKey es la clave del contexto con el servicio autenticado
+type apiKeyIdentityKey struct{}
+
+// APIKeyIdentityFromContext retorna el servicio que hizo la llamada gRPC (ok es false si llamó sin key)
+func APIKeyIdentityFromContext(ctx context.Context) (clients.APIKeyIdentity, bool) {
+	identity, ok := ctx.Value(apiKeyIdentityKey{}).(clients.APIKeyIdentity)
+	return identity, ok
+}
+
+// APIKeyUnaryInterceptor exige a las llamadas gRPC internas una API key con el scope indicado
+// La key viaja en el metadata x-api-key y se valida contra users-api (con caché, ver clients.NewAPIKeyVerifier)
+// Con required en false se aceptan las llamadas sin key, para poder configurar los clientes de a uno;
+// una key inválida o sin el scope se rechaza siempre
+func APIKeyUnaryInterceptor(verifier clients.APIKeyVerifier, scope string, required bool) grpc.UnaryServerInterceptor {
+	// Las llamadas sin key se loguean una vez por método para no inundar el log
+	var warned sync.Map
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var key string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(clients.APIKeyMetadataKey); len(values) > 0 {
+				key = values[0]
+			}
+		}
+
+		if key == "" {
+			if required {
+				return nil, status.Error(codes.Unauthenticated, "API key requerida")
+			}
+			if _, loaded := warned.LoadOrStore(info.FullMethod, true); !loaded {
+				log.Printf("⚠️ Llamada gRPC sin API key a %s (se acepta porque INTERNAL_API_KEYS_REQUIRED=false)", info.FullMethod)
+			}
+			return handler(ctx, req)
+		}
+
+		identity, ok, err := verifier.Verify(ctx, key)
+		if err != nil {
+			log.Printf("⚠️ No se pudo validar la API key de una llamada a %s: %v", info.FullMethod, err)
+			return nil, status.Error(codes.Unavailable, "no se pudo validar la API key")
+		}
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "API key inválida")
+		}
+		if !identity.HasScope(scope) {
+			return nil, status.Errorf(codes.PermissionDenied, "la API key de '%s' no tiene el scope %s", identity.Name, scope)
+		}
+
+		return handler(context.WithValue(ctx, apiKeyIdentityKey{}, identity), req)
+	}
+}
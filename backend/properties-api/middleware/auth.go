@@ -1,9 +1,12 @@
 package middleware
 
 import (
+	"log"
 	"net/http"
 	"strings"
 
+	"properties-api/clients"
+
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -17,7 +20,9 @@ type Claims struct {
 }
 
 // AuthMiddleware valida el token JWT
-func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
+// Después de validar la firma le pregunta a users-api si el token fue revocado (logout en todos lados,
+// cambio de contraseña o de rol); el rol que se usa es el que confirma users-api
+func AuthMiddleware(jwtSecret string, tokens clients.TokenVerifier) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -51,6 +56,20 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 			return
 		}
 
+		user, valid, err := tokens.Verify(c.Request.Context(), tokenString)
+		if err != nil {
+			log.Printf("⚠️ No se pudo validar el token del usuario %d con users-api: %v", claims.UserID, err)
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "No se pudo validar el token"})
+			c.Abort()
+			return
+		}
+		if !valid {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token revocado"})
+			c.Abort()
+			return
+		}
+		claims.UserID, claims.Username, claims.UserType = user.ID, user.Username, user.UserType
+
 		c.Set("userID", claims.UserID)
 		c.Set("username", claims.Username)
 		c.Set("userType", claims.UserType)
@@ -60,6 +79,36 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 	}
 }
 
+// OptionalAuth identifica al usuario si el request trae un JWT válido, sin rechazar los anónimos
+// Se usa en rutas públicas que muestran más datos al owner (ej: el listado de propiedades de un usuario)
+// Un token revocado, o uno que no se pudo validar con users-api, deja el request como anónimo
+func OptionalAuth(jwtSecret string, tokens clients.TokenVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !found || tokenString == "" {
+			c.Next()
+			return
+		}
+
+		claims := &Claims{}
+		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+			return []byte(jwtSecret), nil
+		})
+		if err != nil || !token.Valid {
+			c.Next()
+			return
+		}
+		if user, valid, err := tokens.Verify(c.Request.Context(), tokenString); err == nil && valid {
+			c.Set("userID", user.ID)
+			c.Set("username", user.Username)
+			c.Set("userType", user.UserType)
+			c.Set("isAdmin", user.UserType == "admin")
+		}
+
+		c.Next()
+	}
+}
+
 // AdminRequired requiere que el usuario sea admin
 func AdminRequired() gin.HandlerFunc {
 	return func(c *gin.Context) {
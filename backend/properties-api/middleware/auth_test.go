@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"properties-api/clients"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testJWTSecret = "test-secret"
+
+// stubTokenVerifier responde como users-api: user si el token sigue vigente, ok false si fue revocado
+type stubTokenVerifier struct {
+	user clients.TokenUser
+	ok   bool
+	err  error
+}
+
+func (v stubTokenVerifier) Verify(ctx context.Context, token string) (clients.TokenUser, bool, error) {
+	return v.user, v.ok, v.err
+}
+
+// signTestToken firma un JWT como los de users-api
+func signTestToken(t *testing.T, userID uint, userType string) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{
+		UserID:   userID,
+		Username: "maria",
+		UserType: userType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}).SignedString([]byte(testJWTSecret))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return token
+}
+
+func TestAuthMiddleware_ChecksRevocationWithUsersAPI(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	admin := clients.TokenUser{ID: 7, Username: "maria", UserType: "admin"}
+	tests := []struct {
+		name       string
+		verifier   stubTokenVerifier
+		wantStatus int
+	}{
+		{name: "valid admin token", verifier: stubTokenVerifier{user: admin, ok: true}, wantStatus: http.StatusOK},
+		// Logout en todos lados: la firma sigue siendo válida pero users-api lo revocó
+		{name: "revoked token", verifier: stubTokenVerifier{}, wantStatus: http.StatusUnauthorized},
+		// El admin pasó a normal: su token viejo todavía dice admin
+		{name: "demoted admin", verifier: stubTokenVerifier{user: clients.TokenUser{ID: 7, Username: "maria", UserType: "normal"}, ok: true}, wantStatus: http.StatusForbidden},
+		{name: "users-api down", verifier: stubTokenVerifier{err: errors.New("users-api no disponible")}, wantStatus: http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/admin/stats", AuthMiddleware(testJWTSecret, tt.verifier), AdminRequired(), func(c *gin.Context) {
+				c.String(http.StatusOK, "ok")
+			})
+
+			request := httptest.NewRequest(http.MethodGet, "/admin/stats", nil)
+			request.Header.Set("Authorization", "Bearer "+signTestToken(t, 7, "admin"))
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, recorder.Code, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthMiddleware_RejectsBadSignatureWithoutAskingUsersAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	verifier := stubTokenVerifier{err: errors.New("no se tendría que llamar")}
+	router.GET("/properties/mine", AuthMiddleware("otro-secreto", verifier), func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+
+	request := httptest.NewRequest(http.MethodGet, "/properties/mine", nil)
+	request.Header.Set("Authorization", "Bearer "+signTestToken(t, 7, "normal"))
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", recorder.Code)
+	}
+}
+
+func TestOptionalAuth_RevokedTokenIsAnonymous(t *testing.T) {
+	tests := []struct {
+		name     string
+		verifier stubTokenVerifier
+		wantUser bool
+	}{
+		{name: "valid", verifier: stubTokenVerifier{user: clients.TokenUser{ID: 7, UserType: "normal"}, ok: true}, wantUser: true},
+		{name: "revoked", verifier: stubTokenVerifier{}},
+		{name: "users-api down", verifier: stubTokenVerifier{err: errors.New("users-api no disponible")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/properties/user/7", OptionalAuth(testJWTSecret, tt.verifier), func(c *gin.Context) {
+				_, found := c.Get("userID")
+				if found != tt.wantUser {
+					t.Errorf("expected user=%v, got %v", tt.wantUser, found)
+				}
+				c.String(http.StatusOK, "ok")
+			})
+
+			request := httptest.NewRequest(http.MethodGet, "/properties/user/7", nil)
+			request.Header.Set("Authorization", "Bearer "+signTestToken(t, 7, "normal"))
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("expected 200 for a public route, got %d", recorder.Code)
+			}
+		})
+	}
+}
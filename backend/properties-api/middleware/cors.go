@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"properties-api/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORS permite requests de los orígenes configurados en CORS_ALLOWED_ORIGINS
+// Al origen permitido se le responde con su propio valor (no "*"), y Vary: Origin evita
+// que un cache comparta la respuesta entre orígenes distintos
+// Los preflight (OPTIONS) se responden acá: 204 si el origen está permitido y 403 si no
+func CORS(cfg config.CORSConfig) gin.HandlerFunc {
+	allowAll := false
+	origins := make(map[string]bool, len(cfg.AllowedOrigins))
+	for _, origin := range cfg.AllowedOrigins {
+		if origin == "*" {
+			allowAll = true
+		}
+		origins[strings.TrimSuffix(origin, "/")] = true
+	}
+	methods := strings.Join(cfg.AllowedMethods, ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			// No es un request cross-origin de un navegador
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Origin")
+		allowed := allowAll || origins[origin]
+
+		if c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != "" {
+			if !allowed {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Header("Access-Control-Allow-Origin", origin)
+			c.Header("Access-Control-Allow-Methods", methods)
+			c.Header("Access-Control-Allow-Headers", headers)
+			c.Header("Access-Control-Max-Age", maxAge)
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		// Un origen no permitido recibe la respuesta sin headers CORS: el navegador no la expone
+		if allowed {
+			c.Header("Access-Control-Allow-Origin", origin)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"properties-api/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newCORSRouter arma un router con el middleware CORS y una ruta GET /properties
+func newCORSRouter(origins ...string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CORS(config.CORSConfig{
+		AllowedOrigins: origins,
+		AllowedMethods: []string{"GET", "POST"},
+		AllowedHeaders: []string{"Content-Type", "Authorization"},
+		MaxAge:         time.Hour,
+	}))
+	router.GET("/properties", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	return router
+}
+
+func TestCORS_Preflight(t *testing.T) {
+	tests := []struct {
+		name       string
+		origins    []string
+		origin     string
+		wantStatus int
+		wantOrigin string
+	}{
+		{name: "allowed origin", origins: []string{"https://spotly.com"}, origin: "https://spotly.com", wantStatus: http.StatusNoContent, wantOrigin: "https://spotly.com"},
+		{name: "configured with a trailing slash", origins: []string{"https://spotly.com/"}, origin: "https://spotly.com", wantStatus: http.StatusNoContent, wantOrigin: "https://spotly.com"},
+		{name: "wildcard echoes the origin", origins: []string{"*"}, origin: "https://otro.com", wantStatus: http.StatusNoContent, wantOrigin: "https://otro.com"},
+		{name: "unknown origin", origins: []string{"https://spotly.com"}, origin: "https://evil.com", wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodOptions, "/properties", nil)
+			request.Header.Set("Origin", tt.origin)
+			request.Header.Set("Access-Control-Request-Method", "POST")
+			recorder := httptest.NewRecorder()
+			newCORSRouter(tt.origins...).ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d", tt.wantStatus, recorder.Code)
+			}
+			if got := recorder.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Fatalf("expected Access-Control-Allow-Origin %q, got %q", tt.wantOrigin, got)
+			}
+			if tt.wantOrigin == "" {
+				return
+			}
+			if got := recorder.Header().Get("Access-Control-Allow-Methods"); got != "GET, POST" {
+				t.Fatalf("expected the configured methods, got %q", got)
+			}
+			if got := recorder.Header().Get("Access-Control-Allow-Headers"); got != "Content-Type, Authorization" {
+				t.Fatalf("expected the configured headers, got %q", got)
+			}
+			if got := recorder.Header().Get("Access-Control-Max-Age"); got != "3600" {
+				t.Fatalf("expected max age 3600, got %q", got)
+			}
+		})
+	}
+}
+
+func TestCORS_SimpleRequests(t *testing.T) {
+	tests := []struct {
+		name       string
+		origin     string
+		wantOrigin string
+		wantVary   bool
+	}{
+		{name: "allowed origin", origin: "https://spotly.com", wantOrigin: "https://spotly.com", wantVary: true},
+		// La respuesta llega igual, pero sin headers CORS el navegador no la expone
+		{name: "unknown origin", origin: "https://evil.com", wantVary: true},
+		{name: "same origin or no browser", origin: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/properties", nil)
+			if tt.origin != "" {
+				request.Header.Set("Origin", tt.origin)
+			}
+			recorder := httptest.NewRecorder()
+			newCORSRouter("https://spotly.com").ServeHTTP(recorder, request)
+
+			if recorder.Code != http.StatusOK || recorder.Body.String() != "ok" {
+				t.Fatalf("expected the handler to run, got %d: %s", recorder.Code, recorder.Body.String())
+			}
+			if got := recorder.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Fatalf("expected Access-Control-Allow-Origin %q, got %q", tt.wantOrigin, got)
+			}
+			if got := recorder.Header().Get("Vary") == "Origin"; got != tt.wantVary {
+				t.Fatalf("expected Vary: Origin=%v, got %q", tt.wantVary, recorder.Header().Get("Vary"))
+			}
+		})
+	}
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxAge   time.Duration
+		wantHSTS string
+	}{
+		{name: "with HSTS", maxAge: 180 * 24 * time.Hour, wantHSTS: "max-age=15552000; includeSubDomains"},
+		{name: "HSTS disabled", maxAge: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(SecurityHeaders(config.SecurityConfig{HSTSMaxAge: tt.maxAge}))
+			router.GET("/properties", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/properties", nil))
+
+			if recorder.Header().Get("X-Content-Type-Options") != "nosniff" || recorder.Header().Get("X-Frame-Options") != "DENY" {
+				t.Fatalf("expected nosniff and DENY, got %v", recorder.Header())
+			}
+			if got := recorder.Header().Get("Strict-Transport-Security"); got != tt.wantHSTS {
+				t.Fatalf("expected Strict-Transport-Security %q, got %q", tt.wantHSTS, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader es el header con el que llega (del gateway o de otro servicio) y se devuelve el ID del request
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength evita que un header arbitrario termine en los logs y en los reportes de errores
+const maxRequestIDLength = 128
+
+// RequestID toma el X-Request-ID del request (o genera uno), lo devuelve en la respuesta
+// y lo guarda en el contexto ("request_id") para taggear los logs y los errores reportados
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" || len(id) > maxRequestIDLength {
+			id = newRequestID()
+		}
+		c.Set("request_id", id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// newRequestID genera un ID aleatorio de 32 caracteres hexadecimales
+func newRequestID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RouteTimeouts son los tiempos máximos de endpoints puntuales, por método y ruta de gin (ej: "POST /api/properties/:id/photos")
+// Un valor <= 0 deja el endpoint sin límite (ej: exports en streaming, que duran lo que tarde el cliente en leerlos)
+type RouteTimeouts map[string]time.Duration
+
+// Timeout cancela el contexto del request al vencer su tiempo máximo: defaultTimeout o el de RouteTimeouts
+// Los controllers pasan ese contexto a los servicios, así que las consultas a MongoDB y las llamadas a otros
+// servicios se cortan al vencer el tiempo o al desconectarse el cliente
+// Si el handler no llegó a responder, o respondió un 5xx porque se le venció el contexto, se responde 504
+func Timeout(defaultTimeout time.Duration, routes RouteTimeouts) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeout, ok := routes[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			timeout = defaultTimeout
+		}
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Writer = &timeoutWriter{ResponseWriter: c.Writer, ctx: ctx}
+
+		c.Next()
+
+		if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return
+		}
+		log.Printf("⏱️ %s %s superó el tiempo máximo de %v", c.Request.Method, c.FullPath(), timeout)
+		if !c.Writer.Written() {
+			c.AbortWithStatusJSON(http.StatusGatewayTimeout, gin.H{"error": timeoutMessage})
+		}
+	}
+}
+
+// timeoutMessage es el error de los 504 por tiempo máximo
+const timeoutMessage = "el request superó el tiempo máximo"
+
+// timeoutWriter convierte en 504 los 5xx que el handler escribe después de vencido el tiempo:
+// los controllers responden 500 con el context.DeadlineExceeded de MongoDB o del cliente gRPC,
+// y para quien llama la causa es el tiempo máximo, no un error del servidor
+type timeoutWriter struct {
+	gin.ResponseWriter
+	ctx context.Context
+	// timedOut indica que se reemplazó la respuesta: el body del handler se descarta
+	timedOut bool
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	if code >= http.StatusInternalServerError && !w.Written() && errors.Is(w.ctx.Err(), context.DeadlineExceeded) {
+		w.timedOut = true
+		code = http.StatusGatewayTimeout
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	if !w.timedOut {
+		return w.ResponseWriter.Write(data)
+	}
+	if !w.Written() {
+		body, _ := json.Marshal(gin.H{"error": timeoutMessage})
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if _, err := w.ResponseWriter.Write(body); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
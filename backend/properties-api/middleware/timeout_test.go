@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTimeoutRouter arma un router con el middleware Timeout y un handler por ruta
+func newTimeoutRouter(timeout time.Duration, routes RouteTimeouts) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Timeout(timeout, routes))
+
+	// Como los controllers: espera a la base y responde 500 con el error del contexto
+	router.GET("/slow", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": c.Request.Context().Err().Error()})
+	})
+	// No llega a responder antes de que venza el tiempo
+	router.GET("/silent", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+	})
+	// Falla por otra causa antes de que venza el tiempo
+	router.GET("/broken", func(c *gin.Context) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errors.New("mongo caído").Error()})
+	})
+	// Responde bien aunque ya venció el tiempo
+	router.GET("/late", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		c.String(http.StatusOK, "ok")
+	})
+	return router
+}
+
+func TestTimeout_RespondsGatewayTimeoutWhenTheDeadlineExpires(t *testing.T) {
+	router := newTimeoutRouter(20*time.Millisecond, nil)
+
+	for _, path := range []string{"/slow", "/silent"} {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if recorder.Code != http.StatusGatewayTimeout {
+			t.Fatalf("%s: expected 504, got %d: %s", path, recorder.Code, recorder.Body.String())
+		}
+		var body map[string]string
+		if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s: expected a single JSON body, got %q (%v)", path, recorder.Body.String(), err)
+		}
+		if body["error"] != timeoutMessage {
+			t.Fatalf("%s: expected the timeout message, got %q", path, body["error"])
+		}
+	}
+}
+
+func TestTimeout_KeepsResponsesThatAreNotCausedByTheDeadline(t *testing.T) {
+	router := newTimeoutRouter(20*time.Millisecond, nil)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/broken", nil))
+	if recorder.Code != http.StatusInternalServerError {
+		t.Fatalf("expected the handler's 500 before the deadline, got %d", recorder.Code)
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/late", nil))
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "ok" {
+		t.Fatalf("expected a successful late response to be kept, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestTimeout_RouteWithoutLimit(t *testing.T) {
+	router := newTimeoutRouter(time.Hour, RouteTimeouts{"GET /slow": 20 * time.Millisecond, "GET /broken": 0})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	if recorder.Code != http.StatusGatewayTimeout {
+		t.Fatalf("expected the route timeout to apply, got %d", recorder.Code)
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/broken", nil))
+	if recorder.Code != http.StatusInternalServerError {
+		t.Fatalf("expected a route without limit to keep its response, got %d", recorder.Code)
+	}
+}
@@ -0,0 +1,141 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"properties-api/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AnalyticsRepository cuenta la actividad por día en las colecciones de properties-api
+// y guarda los KPIs diarios ya agregados del panel de admin
+type AnalyticsRepository interface {
+	// CountListingsByDay cuenta las propiedades creadas por día UTC en [from, to)
+	CountListingsByDay(ctx context.Context, from, to time.Time) (map[string]int64, error)
+	// CountBookingsByDay cuenta las reservas creadas por día UTC en [from, to) y cuántas están confirmadas
+	CountBookingsByDay(ctx context.Context, from, to time.Time) (map[string]domain.BookingDayCounts, error)
+	// ListDays obtiene los KPIs guardados de los días entre from y to (YYYY-MM-DD, inclusive), ordenados
+	ListDays(ctx context.Context, from, to string) ([]domain.DailyKPIs, error)
+	// SaveDays crea o reemplaza los KPIs de cada día
+	SaveDays(ctx context.Context, days []domain.DailyKPIs) error
+}
+
+// analyticsRepository es la implementación de AnalyticsRepository sobre MongoDB
+type analyticsRepository struct {
+	properties *mongo.Collection
+	bookings   *mongo.Collection
+	daily      *mongo.Collection
+}
+
+// NewAnalyticsRepository crea una nueva instancia del repositorio de analytics
+func NewAnalyticsRepository(db *mongo.Database) AnalyticsRepository {
+	return &analyticsRepository{
+		properties: db.Collection("properties"),
+		bookings:   db.Collection("bookings"),
+		daily:      db.Collection("analytics_daily"),
+	}
+}
+
+// dayOf es la expresión de agregación que convierte createdAt en el día UTC (YYYY-MM-DD)
+var dayOf = bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": "$createdAt", "timezone": "UTC"}}
+
+// CountListingsByDay agrupa las propiedades por día de createdAt (usa el índice createdAt_-1)
+func (r *analyticsRepository) CountListingsByDay(ctx context.Context, from, to time.Time) (map[string]int64, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"createdAt": bson.M{"$gte": from, "$lt": to}}}},
+		{{Key: "$group", Value: bson.M{"_id": dayOf, "count": bson.M{"$sum": 1}}}},
+	}
+
+	cursor, err := r.properties.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("error contando propiedades por día: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		Day   string `bson:"_id"`
+		Count int64  `bson:"count"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("error contando propiedades por día: %w", err)
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Day] = row.Count
+	}
+	return counts, nil
+}
+
+// CountBookingsByDay agrupa las reservas por día de createdAt (usa el índice createdAt_-1 de la migración v22)
+func (r *analyticsRepository) CountBookingsByDay(ctx context.Context, from, to time.Time) (map[string]domain.BookingDayCounts, error) {
+	confirmed := bson.M{"$eq": bson.A{"$status", domain.BookingConfirmed}}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"createdAt": bson.M{"$gte": from, "$lt": to}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":       dayOf,
+			"created":   bson.M{"$sum": 1},
+			"confirmed": bson.M{"$sum": bson.M{"$cond": bson.A{confirmed, 1, 0}}},
+		}}},
+	}
+
+	cursor, err := r.bookings.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("error contando reservas por día: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		Day                     string `bson:"_id"`
+		domain.BookingDayCounts `bson:",inline"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("error contando reservas por día: %w", err)
+	}
+
+	counts := make(map[string]domain.BookingDayCounts, len(rows))
+	for _, row := range rows {
+		counts[row.Day] = row.BookingDayCounts
+	}
+	return counts, nil
+}
+
+// ListDays obtiene los KPIs por rango de _id (las fechas YYYY-MM-DD se ordenan como texto)
+func (r *analyticsRepository) ListDays(ctx context.Context, from, to string) ([]domain.DailyKPIs, error) {
+	filter := bson.M{"_id": bson.M{"$gte": from, "$lte": to}}
+	cursor, err := r.daily.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo KPIs diarios: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	days := []domain.DailyKPIs{}
+	if err := cursor.All(ctx, &days); err != nil {
+		return nil, fmt.Errorf("error obteniendo KPIs diarios: %w", err)
+	}
+	return days, nil
+}
+
+// SaveDays reemplaza con upsert el documento de cada día en una sola escritura
+func (r *analyticsRepository) SaveDays(ctx context.Context, days []domain.DailyKPIs) error {
+	if len(days) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(days))
+	for _, day := range days {
+		models = append(models, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"_id": day.Date}).
+			SetReplacement(day).
+			SetUpsert(true))
+	}
+	if _, err := r.daily.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+		return fmt.Errorf("error guardando KPIs diarios: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"properties-api/domain"
+	"properties-api/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AuditFilter son los filtros y la paginación de la consulta del log de auditoría
+type AuditFilter struct {
+	ActorID string    // Filtra por el usuario que hizo la operación (vacío = todos)
+	From    time.Time // Desde (inclusive, cero = sin límite)
+	To      time.Time // Hasta (exclusive, cero = sin límite)
+	Page    int
+	Limit   int
+}
+
+// AuditRepository guarda y consulta el log de auditoría
+// No tiene Update ni Delete: el log es append-only
+type AuditRepository interface {
+	Create(ctx context.Context, entry *domain.AuditEntry) error
+	Find(ctx context.Context, filter AuditFilter) ([]domain.AuditEntry, int64, error)
+}
+
+// auditRepository es la implementación de AuditRepository sobre MongoDB
+type auditRepository struct {
+	collection *mongo.Collection
+}
+
+// NewAuditRepository crea una nueva instancia del repositorio de auditoría
+func NewAuditRepository(db *mongo.Database) AuditRepository {
+	return &auditRepository{
+		collection: db.Collection("audit_log"),
+	}
+}
+
+// Create inserta un registro de auditoría
+func (r *auditRepository) Create(ctx context.Context, entry *domain.AuditEntry) error {
+	if entry.ID.IsZero() {
+		entry.ID = primitive.NewObjectID()
+	}
+
+	if _, err := r.collection.InsertOne(ctx, entry); err != nil {
+		return fmt.Errorf("error insertando registro de auditoría en MongoDB: %w", err)
+	}
+	return nil
+}
+
+// Find obtiene una página de registros (los más recientes primero) y el total sin paginar
+func (r *auditRepository) Find(ctx context.Context, filter AuditFilter) ([]domain.AuditEntry, int64, error) {
+	query := bson.M{}
+	if filter.ActorID != "" {
+		query["actorId"] = filter.ActorID
+	}
+	createdAt := bson.M{}
+	if !filter.From.IsZero() {
+		createdAt["$gte"] = filter.From
+	}
+	if !filter.To.IsZero() {
+		createdAt["$lt"] = filter.To
+	}
+	if len(createdAt) > 0 {
+		query["createdAt"] = createdAt
+	}
+
+	total, err := r.collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error contando registros de auditoría: %w", err)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64(utils.CalculateSkip(filter.Page, filter.Limit))).
+		SetLimit(int64(filter.Limit))
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error buscando registros de auditoría: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	entries := []domain.AuditEntry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, 0, fmt.Errorf("error decodificando registros de auditoría: %w", err)
+	}
+
+	return entries, total, nil
+}
@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"properties-api/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AutoTranslationRepository guarda las traducciones automáticas por propiedad y locale
+type AutoTranslationRepository interface {
+	// Get obtiene la traducción de la propiedad al locale; retorna nil sin error si no existe
+	Get(ctx context.Context, propertyID, locale string) (*domain.AutoTranslation, error)
+	// Save crea o reemplaza la traducción de la propiedad al locale
+	Save(ctx context.Context, translation domain.AutoTranslation) error
+}
+
+// autoTranslationRepository es la implementación de AutoTranslationRepository sobre MongoDB
+type autoTranslationRepository struct {
+	collection *mongo.Collection
+}
+
+// NewAutoTranslationRepository crea una nueva instancia del repositorio de traducciones automáticas
+func NewAutoTranslationRepository(db *mongo.Database) AutoTranslationRepository {
+	return &autoTranslationRepository{
+		collection: db.Collection("auto_translations"),
+	}
+}
+
+// Get obtiene la traducción por (propertyId, locale)
+func (r *autoTranslationRepository) Get(ctx context.Context, propertyID, locale string) (*domain.AutoTranslation, error) {
+	var translation domain.AutoTranslation
+	err := r.collection.FindOne(ctx, bson.M{"propertyId": propertyID, "locale": locale}).Decode(&translation)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo traducción automática: %w", err)
+	}
+	return &translation, nil
+}
+
+// Save reemplaza con upsert la traducción de (propertyId, locale)
+func (r *autoTranslationRepository) Save(ctx context.Context, translation domain.AutoTranslation) error {
+	filter := bson.M{"propertyId": translation.PropertyID, "locale": translation.Locale}
+	if _, err := r.collection.ReplaceOne(ctx, filter, translation, options.Replace().SetUpsert(true)); err != nil {
+		return fmt.Errorf("error guardando traducción automática: %w", err)
+	}
+	return nil
+}
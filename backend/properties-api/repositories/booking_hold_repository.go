@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"properties-api/domain"
+	"properties-api/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// BookingHoldRepository guarda los holds de fechas de los checkouts en curso
+// Un hold se termina de una sola forma: Claim (se reserva), Release (el huésped lo suelta) o
+// ReleaseExpired (venció). Las tres eliminan el documento del hold y solo la que lo elimina sigue,
+// así una reserva y el sweeper nunca se quedan con el mismo hold
+type BookingHoldRepository interface {
+	// Create guarda el hold y toma sus noches; retorna ErrBookingNightsTaken si alguna ya está tomada
+	Create(ctx context.Context, hold *domain.BookingHold, nights []string) error
+	// FindByID obtiene un hold; retorna nil sin error si no existe (ya se usó, se soltó o venció)
+	FindByID(ctx context.Context, id string) (*domain.BookingHold, error)
+	// Claim elimina el hold si no venció antes de now y le deja sus noches a la reserva con el mismo ID
+	// Retorna false si ya no estaba (venció, se soltó o ya se reservó)
+	Claim(ctx context.Context, id primitive.ObjectID, now time.Time) (bool, error)
+	// Release elimina el hold y libera sus noches; retorna false si ya no estaba
+	Release(ctx context.Context, id primitive.ObjectID) (bool, error)
+	// ReleaseExpired libera hasta limit holds vencidos antes de now y retorna cuántos liberó
+	ReleaseExpired(ctx context.Context, now time.Time, limit int) (int, error)
+}
+
+type bookingHoldRepository struct {
+	holds  *mongo.Collection
+	nights *mongo.Collection
+}
+
+// NewBookingHoldRepository crea el repositorio de holds (comparte booking_nights con las reservas)
+func NewBookingHoldRepository(db *mongo.Database) BookingHoldRepository {
+	return &bookingHoldRepository{
+		holds:  db.Collection("booking_holds"),
+		nights: db.Collection(bookingNightsCollection),
+	}
+}
+
+// Create guarda el hold y después toma sus noches, igual que una reserva (sin transacción):
+// si alguna noche ya está tomada se elimina el hold
+func (r *bookingHoldRepository) Create(ctx context.Context, hold *domain.BookingHold, nights []string) error {
+	hold.ID = primitive.NewObjectID()
+	hold.CreatedAt = utils.NowUTC()
+
+	if _, err := r.holds.InsertOne(ctx, hold); err != nil {
+		return fmt.Errorf("error creando hold: %w", err)
+	}
+	if err := takeNights(ctx, r.nights, hold.PropertyID, hold.ID, nights, hold.CreatedAt); err != nil {
+		if _, deleteErr := r.holds.DeleteOne(ctx, bson.M{"_id": hold.ID}); deleteErr != nil {
+			log.Printf("⚠️ Error eliminando el hold %s después de no poder tomar sus noches: %v", hold.ID.Hex(), deleteErr)
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *bookingHoldRepository) FindByID(ctx context.Context, id string) (*domain.BookingHold, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, nil
+	}
+
+	var hold domain.BookingHold
+	if err := r.holds.FindOne(ctx, bson.M{"_id": objectID}).Decode(&hold); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// Claim elimina el hold solo si sigue vigente; las noches quedan tomadas con su ID
+func (r *bookingHoldRepository) Claim(ctx context.Context, id primitive.ObjectID, now time.Time) (bool, error) {
+	result, err := r.holds.DeleteOne(ctx, bson.M{"_id": id, "expiresAt": bson.M{"$gt": now}})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
+
+func (r *bookingHoldRepository) Release(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	return r.release(ctx, bson.M{"_id": id})
+}
+
+// ReleaseExpired busca los holds vencidos y libera cada uno solo si sigue vencido
+// (un Claim concurrente con un reloj apenas atrasado no puede perder sus noches)
+func (r *bookingHoldRepository) ReleaseExpired(ctx context.Context, now time.Time, limit int) (int, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "expiresAt", Value: 1}}).
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{"_id": 1})
+	cursor, err := r.holds.Find(ctx, bson.M{"expiresAt": bson.M{"$lte": now}}, opts)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var expired []domain.BookingHold
+	if err := cursor.All(ctx, &expired); err != nil {
+		return 0, err
+	}
+
+	released := 0
+	for _, hold := range expired {
+		ok, err := r.release(ctx, bson.M{"_id": hold.ID, "expiresAt": bson.M{"$lte": now}})
+		if err != nil {
+			return released, err
+		}
+		if ok {
+			released++
+		}
+	}
+	return released, nil
+}
+
+// release elimina el hold que cumple el filtro y, si lo eliminó, libera sus noches
+func (r *bookingHoldRepository) release(ctx context.Context, filter bson.M) (bool, error) {
+	var hold domain.BookingHold
+	if err := r.holds.FindOneAndDelete(ctx, filter).Decode(&hold); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return false, nil
+		}
+		return false, err
+	}
+	releaseNights(ctx, r.nights, hold.ID)
+	return true, nil
+}
@@ -2,37 +2,153 @@ package repositories
 
 import (
 	"context"
-	"properties-api/domain"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
 	"time"
 
+	"properties-api/domain"
+	"properties-api/utils"
+
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// ErrBookingNightsTaken indica que otra reserva activa ya tomó alguna de las noches
+var ErrBookingNightsTaken = errors.New("alguna de las noches ya está reservada")
+
 type BookingRepository interface {
-	Create(ctx context.Context, booking *domain.Booking) error
+	// Create guarda la reserva y toma sus noches (días locales YYYY-MM-DD) en booking_nights
+	// Retorna ErrBookingNightsTaken sin guardar la reserva si otra reserva activa ya tomó alguna
+	Create(ctx context.Context, booking *domain.Booking, nights []string) error
+	// CreateFromHold guarda la reserva con el ID de un hold ya reclamado (BookingHoldRepository.Claim),
+	// que le deja sus noches tomadas; si no se puede guardar las libera
+	CreateFromHold(ctx context.Context, booking *domain.Booking, holdID primitive.ObjectID) error
 	FindByUserID(ctx context.Context, userID string) ([]domain.Booking, error)
 	FindByID(ctx context.Context, id string) (*domain.Booking, error)
+	StreamByPropertyIDs(ctx context.Context, propertyIDs []string, fn func(domain.Booking) error) error
+	StreamFiltered(ctx context.Context, filter BookingFilter, fn func(domain.Booking) error) error
+	ReplaceUserID(ctx context.Context, userID string, replacement string) (int64, error)
+	// ReplacePropertyID mueve las reservas de una propiedad a otra junto con sus noches tomadas
+	// Retorna ErrBookingNightsTaken sin mover nada si las dos propiedades tienen alguna noche en común
+	ReplacePropertyID(ctx context.Context, propertyID string, replacement string) (int64, error)
+	AggregateStats(ctx context.Context, propertyID string, from, to time.Time) (domain.BookingStats, error)
+	UpdateStatus(ctx context.Context, id string, from, to string) (bool, error)
+	FindExpiredPending(ctx context.Context, now time.Time, limit int) ([]domain.Booking, error)
+}
+
+// BookingFilter son los filtros del export de reservas (los campos vacíos no filtran)
+type BookingFilter struct {
+	PropertyID  string
+	UserID      string
+	Status      string
+	CheckInFrom time.Time // Check-in desde (inclusive)
+	CheckInTo   time.Time // Check-in hasta (exclusive)
+}
+
+// bookingNightsCollection es la colección con las noches tomadas por reservas activas y holds
+const bookingNightsCollection = "booking_nights"
+
+// bookingNight es una noche tomada por una reserva activa o por un hold
+// El índice único por (propertyId, night) de la migración v19 impide que dos reservas tomen la misma noche
+// BookingID es el ID de la reserva o del hold (la reserva de un hold se guarda con el mismo ID)
+type bookingNight struct {
+	PropertyID string             `bson:"propertyId"`
+	Night      string             `bson:"night"` // Día local de la propiedad (YYYY-MM-DD)
+	BookingID  primitive.ObjectID `bson:"bookingId"`
+	CreatedAt  time.Time          `bson:"createdAt"`
+}
+
+// takeNights toma las noches para ownerID (reserva o hold); si alguna falla libera las que sí tomó
+// Retorna ErrBookingNightsTaken si otra reserva o hold ya tenía alguna
+func takeNights(ctx context.Context, collection *mongo.Collection, propertyID string, ownerID primitive.ObjectID, nights []string, createdAt time.Time) error {
+	if len(nights) == 0 {
+		return nil
+	}
+
+	docs := make([]interface{}, 0, len(nights))
+	for _, night := range nights {
+		docs = append(docs, bookingNight{
+			PropertyID: propertyID,
+			Night:      night,
+			BookingID:  ownerID,
+			CreatedAt:  createdAt,
+		})
+	}
+	_, err := collection.InsertMany(ctx, docs)
+	if err == nil {
+		return nil
+	}
+
+	releaseNights(ctx, collection, ownerID)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrBookingNightsTaken
+	}
+	return fmt.Errorf("error tomando las noches: %w", err)
+}
+
+// releaseNights libera las noches tomadas por una reserva o un hold; un error solo se loguea
+// (una noche que no se libera bloquea nuevas reservas: hay que eliminarla a mano de booking_nights)
+func releaseNights(ctx context.Context, collection *mongo.Collection, ownerID primitive.ObjectID) {
+	if _, err := collection.DeleteMany(ctx, bson.M{"bookingId": ownerID}); err != nil {
+		log.Printf("⚠️ Error liberando las noches de %s: %v", ownerID.Hex(), err)
+	}
 }
 
 type bookingRepository struct {
 	collection *mongo.Collection
+	nights     *mongo.Collection
 }
 
 func NewBookingRepository(db *mongo.Database) BookingRepository {
 	return &bookingRepository{
 		collection: db.Collection("bookings"),
+		nights:     db.Collection(bookingNightsCollection),
 	}
 }
 
-func (r *bookingRepository) Create(ctx context.Context, booking *domain.Booking) error {
+// Create guarda una reserva nueva; sin estado se guarda confirmada (reserva instantánea)
+// Verificar la disponibilidad antes de guardar no alcanza con reservas simultáneas: las dos la ven libre.
+// Por eso cada noche se toma con un documento en booking_nights y el índice único decide cuál gana.
+// MongoDB corre sin replica set, así que no hay transacción: la reserva se guarda primero y se elimina
+// si alguna noche ya estaba tomada. Si el proceso se cae en el medio queda una reserva visible (que el
+// calendario sigue contando) y nunca una noche tomada por una reserva que no existe
+func (r *bookingRepository) Create(ctx context.Context, booking *domain.Booking, nights []string) error {
 	booking.ID = primitive.NewObjectID()
-	booking.CreatedAt = time.Now()
-	booking.Status = "confirmed"
+	booking.CreatedAt = utils.NowUTC()
+	if booking.Status == "" {
+		booking.Status = domain.BookingConfirmed
+	}
 
-	_, err := r.collection.InsertOne(ctx, booking)
-	return err
+	if _, err := r.collection.InsertOne(ctx, booking); err != nil {
+		return err
+	}
+	if err := takeNights(ctx, r.nights, booking.PropertyID, booking.ID, nights, booking.CreatedAt); err != nil {
+		if _, deleteErr := r.collection.DeleteOne(ctx, bson.M{"_id": booking.ID}); deleteErr != nil {
+			log.Printf("⚠️ Error eliminando la reserva %s después de no poder tomar sus noches: %v", booking.ID.Hex(), deleteErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// CreateFromHold guarda la reserva con el ID del hold: las noches del hold pasan a ser de la reserva
+func (r *bookingRepository) CreateFromHold(ctx context.Context, booking *domain.Booking, holdID primitive.ObjectID) error {
+	booking.ID = holdID
+	booking.CreatedAt = utils.NowUTC()
+	if booking.Status == "" {
+		booking.Status = domain.BookingConfirmed
+	}
+
+	if _, err := r.collection.InsertOne(ctx, booking); err != nil {
+		releaseNights(ctx, r.nights, holdID)
+		return err
+	}
+	return nil
 }
 
 func (r *bookingRepository) FindByUserID(ctx context.Context, userID string) ([]domain.Booking, error) {
@@ -62,3 +178,276 @@ func (r *bookingRepository) FindByID(ctx context.Context, id string) (*domain.Bo
 	}
 	return &booking, nil
 }
+
+// StreamByPropertyIDs recorre las reservas de un conjunto de propiedades ordenadas por checkIn
+func (r *bookingRepository) StreamByPropertyIDs(ctx context.Context, propertyIDs []string, fn func(domain.Booking) error) error {
+	if len(propertyIDs) == 0 {
+		return nil
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "checkIn", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"propertyId": bson.M{"$in": propertyIDs}}, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var booking domain.Booking
+		if err := cursor.Decode(&booking); err != nil {
+			return err
+		}
+		if err := fn(booking); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// StreamFiltered recorre con un cursor las reservas que cumplen el filtro ordenadas por checkIn
+func (r *bookingRepository) StreamFiltered(ctx context.Context, filter BookingFilter, fn func(domain.Booking) error) error {
+	query := bson.M{}
+	if filter.PropertyID != "" {
+		query["propertyId"] = filter.PropertyID
+	}
+	if filter.UserID != "" {
+		query["userId"] = filter.UserID
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	if checkIn := timeRange(filter.CheckInFrom, filter.CheckInTo); len(checkIn) > 0 {
+		query["checkIn"] = checkIn
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "checkIn", Value: 1}, {Key: "_id", Value: 1}}).
+		SetBatchSize(exportBatchSize)
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var booking domain.Booking
+		if err := cursor.Decode(&booking); err != nil {
+			return err
+		}
+		if err := fn(booking); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// ReplaceUserID reemplaza el userId de todas las reservas de un usuario
+// Se usa para anonimizar las reservas sin perder la ocupación de las propiedades
+func (r *bookingRepository) ReplaceUserID(ctx context.Context, userID string, replacement string) (int64, error) {
+	result, err := r.collection.UpdateMany(ctx, bson.M{"userId": userID}, bson.M{"$set": bson.M{"userId": replacement}})
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// ReplacePropertyID mueve todas las reservas de una propiedad a otra
+// Se usa al fusionar una propiedad duplicada con la original
+// Las noches de booking_nights se mueven primero: si quedaran en la propiedad vieja, la original aceptaría
+// reservas nuevas sobre noches ya ocupadas. Si alguna choca con el índice único (las dos propiedades tienen
+// la misma noche tomada) no se mueve nada y el admin tiene que resolver las reservas antes de fusionar.
+// Sin transacción, un choque entre la verificación y la actualización (reserva simultánea) deshace lo movido
+func (r *bookingRepository) ReplacePropertyID(ctx context.Context, propertyID string, replacement string) (int64, error) {
+	conflicts, err := r.sharedNights(ctx, propertyID, replacement)
+	if err != nil {
+		return 0, err
+	}
+	if len(conflicts) > 0 {
+		return 0, fmt.Errorf("%w en las dos propiedades: %s", ErrBookingNightsTaken, strings.Join(conflicts, ", "))
+	}
+
+	owners, err := r.nights.Distinct(ctx, "bookingId", bson.M{"propertyId": propertyID})
+	if err != nil {
+		return 0, fmt.Errorf("error obteniendo las noches tomadas de %s: %w", propertyID, err)
+	}
+	if _, err := r.nights.UpdateMany(ctx, bson.M{"propertyId": propertyID}, bson.M{"$set": bson.M{"propertyId": replacement}}); err != nil {
+		r.restoreNights(ctx, owners, propertyID, replacement)
+		if mongo.IsDuplicateKeyError(err) {
+			return 0, fmt.Errorf("%w en las dos propiedades (reservada durante la fusión)", ErrBookingNightsTaken)
+		}
+		return 0, fmt.Errorf("error moviendo las noches tomadas de %s: %w", propertyID, err)
+	}
+
+	result, err := r.collection.UpdateMany(ctx, bson.M{"propertyId": propertyID}, bson.M{"$set": bson.M{"propertyId": replacement}})
+	if err != nil {
+		r.restoreNights(ctx, owners, propertyID, replacement)
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// sharedNights retorna las noches tomadas en las dos propiedades a la vez
+func (r *bookingRepository) sharedNights(ctx context.Context, propertyID string, replacement string) ([]string, error) {
+	nights, err := r.nights.Distinct(ctx, "night", bson.M{"propertyId": propertyID})
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo las noches tomadas de %s: %w", propertyID, err)
+	}
+	if len(nights) == 0 {
+		return nil, nil
+	}
+
+	shared, err := r.nights.Distinct(ctx, "night", bson.M{"propertyId": replacement, "night": bson.M{"$in": nights}})
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo las noches tomadas de %s: %w", replacement, err)
+	}
+	conflicts := make([]string, 0, len(shared))
+	for _, night := range shared {
+		if s, ok := night.(string); ok {
+			conflicts = append(conflicts, s)
+		}
+	}
+	sort.Strings(conflicts)
+	return conflicts, nil
+}
+
+// restoreNights devuelve a propertyID las noches de sus reservas y holds que ya se movieron; un error solo se
+// loguea (quedan en la otra propiedad, que igual las sigue bloqueando)
+func (r *bookingRepository) restoreNights(ctx context.Context, owners []interface{}, propertyID string, replacement string) {
+	if len(owners) == 0 {
+		return
+	}
+	_, err := r.nights.UpdateMany(ctx,
+		bson.M{"propertyId": replacement, "bookingId": bson.M{"$in": owners}},
+		bson.M{"$set": bson.M{"propertyId": propertyID}},
+	)
+	if err != nil {
+		log.Printf("⚠️ Error devolviendo las noches tomadas a %s: %v", propertyID, err)
+	}
+}
+
+// UpdateStatus cambia el estado de una reserva solo si sigue en from
+// Retorna false si la reserva ya no estaba en ese estado (ej: el anfitrión la aprobó mientras vencía)
+// Si el nuevo estado no es activo (rechazada, vencida o cancelada) libera sus noches
+func (r *bookingRepository) UpdateStatus(ctx context.Context, id string, from, to string) (bool, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": objectID, "status": from},
+		bson.M{"$set": bson.M{"status": to}},
+	)
+	if err != nil {
+		return false, err
+	}
+	if result.ModifiedCount > 0 && !(domain.Booking{Status: to}).IsActive() {
+		releaseNights(ctx, r.nights, objectID)
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// FindExpiredPending obtiene hasta limit solicitudes pendientes cuyo plazo de respuesta venció antes de now
+func (r *bookingRepository) FindExpiredPending(ctx context.Context, now time.Time, limit int) ([]domain.Booking, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "expiresAt", Value: 1}}).
+		SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, bson.M{
+		"status":    domain.BookingPending,
+		"expiresAt": bson.M{"$lte": now},
+	}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var bookings []domain.Booking
+	if err := cursor.All(ctx, &bookings); err != nil {
+		return nil, err
+	}
+	return bookings, nil
+}
+
+// millisPerDay se usa para convertir diferencias de fechas de Mongo (en ms) a noches
+const millisPerDay = 24 * 60 * 60 * 1000
+
+// AggregateStats calcula las estadísticas de reservas de una propiedad con un pipeline de agregación
+// Usa $facet para obtener en una sola consulta los totales históricos y los de la ventana [from, to)
+// Los ingresos y la ocupación salen solo de las reservas confirmadas: las solicitudes pendientes,
+// rechazadas o vencidas no se cobran
+func (r *bookingRepository) AggregateStats(ctx context.Context, propertyID string, from, to time.Time) (domain.BookingStats, error) {
+	confirmed := bson.M{"$eq": bson.A{"$status", domain.BookingConfirmed}}
+	cancelled := bson.M{"$eq": bson.A{"$status", domain.BookingCancelled}}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"propertyId": propertyID}}},
+		{{Key: "$facet", Value: bson.M{
+			"lifetime": bson.A{
+				bson.M{"$group": bson.M{
+					"_id":               nil,
+					"totalBookings":     bson.M{"$sum": 1},
+					"cancelledBookings": bson.M{"$sum": bson.M{"$cond": bson.A{cancelled, 1, 0}}},
+					"totalRevenue":      bson.M{"$sum": bson.M{"$cond": bson.A{confirmed, "$totalPrice", 0}}},
+				}},
+			},
+			"window": bson.A{
+				bson.M{"$match": bson.M{
+					"status":   domain.BookingConfirmed,
+					"checkIn":  bson.M{"$lt": to},
+					"checkOut": bson.M{"$gt": from},
+				}},
+				// Noches de la reserva que caen dentro de la ventana
+				bson.M{"$project": bson.M{
+					"totalPrice": 1,
+					"nights": bson.M{"$divide": bson.A{
+						bson.M{"$subtract": bson.A{
+							bson.M{"$min": bson.A{"$checkOut", to}},
+							bson.M{"$max": bson.A{"$checkIn", from}},
+						}},
+						millisPerDay,
+					}},
+				}},
+				bson.M{"$group": bson.M{
+					"_id":            nil,
+					"windowBookings": bson.M{"$sum": 1},
+					"windowRevenue":  bson.M{"$sum": "$totalPrice"},
+					"bookedNights":   bson.M{"$sum": "$nights"},
+				}},
+			},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return domain.BookingStats{}, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Lifetime []domain.BookingStats `bson:"lifetime"`
+		Window   []domain.BookingStats `bson:"window"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return domain.BookingStats{}, err
+	}
+
+	var stats domain.BookingStats
+	if len(results) == 0 {
+		return stats, nil
+	}
+	if len(results[0].Lifetime) > 0 {
+		lifetime := results[0].Lifetime[0]
+		stats.TotalBookings = lifetime.TotalBookings
+		stats.CancelledBookings = lifetime.CancelledBookings
+		stats.TotalRevenue = lifetime.TotalRevenue
+	}
+	if len(results[0].Window) > 0 {
+		window := results[0].Window[0]
+		stats.WindowBookings = window.WindowBookings
+		stats.WindowRevenue = window.WindowRevenue
+		stats.BookedNights = window.BookedNights
+	}
+	return stats, nil
+}
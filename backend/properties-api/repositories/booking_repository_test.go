@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// updatedCollections retorna la colección de cada update enviado, en orden
+func updatedCollections(mt *mtest.T) []string {
+	var collections []string
+	for _, started := range mt.GetAllStartedEvents() {
+		if started.CommandName == "update" {
+			collections = append(collections, started.Command.Lookup("update").StringValue())
+		}
+	}
+	return collections
+}
+
+func TestBookingRepository_ReplacePropertyIDMovesNightsAndHandlesConflicts(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	owner := primitive.NewObjectID()
+	modified := mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}, bson.E{Key: "nModified", Value: 2})
+
+	mt.Run("moves bookings and their nights", func(mt *mtest.T) {
+		repo := NewBookingRepository(mt.DB)
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "values", Value: bson.A{"2024-03-01", "2024-03-02"}}),
+			mtest.CreateSuccessResponse(bson.E{Key: "values", Value: bson.A{}}),
+			mtest.CreateSuccessResponse(bson.E{Key: "values", Value: bson.A{owner}}),
+			modified,
+			modified,
+		)
+
+		moved, err := repo.ReplacePropertyID(context.Background(), "duplicate", "original")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if moved != 2 {
+			t.Errorf("expected 2 moved bookings, got %d", moved)
+		}
+		// Las noches se mueven antes que las reservas
+		if got := updatedCollections(mt); len(got) != 2 || got[0] != bookingNightsCollection || got[1] != "bookings" {
+			t.Fatalf("expected updates on booking_nights and then bookings, got %v", got)
+		}
+	})
+
+	mt.Run("shared nights are rejected without changes", func(mt *mtest.T) {
+		repo := NewBookingRepository(mt.DB)
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "values", Value: bson.A{"2024-03-01", "2024-03-02"}}),
+			mtest.CreateSuccessResponse(bson.E{Key: "values", Value: bson.A{"2024-03-02"}}),
+		)
+
+		_, err := repo.ReplacePropertyID(context.Background(), "duplicate", "original")
+		if !errors.Is(err, ErrBookingNightsTaken) {
+			t.Fatalf("expected ErrBookingNightsTaken, got %v", err)
+		}
+		if got := updatedCollections(mt); len(got) != 0 {
+			t.Fatalf("expected no updates, got %v", got)
+		}
+	})
+
+	mt.Run("unique index conflict restores the moved nights", func(mt *mtest.T) {
+		repo := NewBookingRepository(mt.DB)
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "values", Value: bson.A{"2024-03-01"}}),
+			mtest.CreateSuccessResponse(bson.E{Key: "values", Value: bson.A{}}),
+			mtest.CreateSuccessResponse(bson.E{Key: "values", Value: bson.A{owner}}),
+			mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 11000, Message: "E11000 duplicate key error"}),
+			modified,
+		)
+
+		_, err := repo.ReplacePropertyID(context.Background(), "duplicate", "original")
+		if !errors.Is(err, ErrBookingNightsTaken) {
+			t.Fatalf("expected ErrBookingNightsTaken, got %v", err)
+		}
+		// El segundo update devuelve las noches de las reservas del duplicado; las reservas no se tocan
+		got := updatedCollections(mt)
+		if len(got) != 2 || got[0] != bookingNightsCollection || got[1] != bookingNightsCollection {
+			t.Fatalf("expected the nights update and its rollback, got %v", got)
+		}
+		restore := mt.GetAllStartedEvents()[len(mt.GetAllStartedEvents())-1].Command
+		filter := restore.Lookup("updates").Array().Index(0).Value().Document().Lookup("q").Document()
+		if filter.Lookup("propertyId").StringValue() != "original" {
+			t.Errorf("expected the rollback to target the nights moved to the original, got %v", filter)
+		}
+		update := restore.Lookup("updates").Array().Index(0).Value().Document().Lookup("u").Document()
+		if update.Lookup("$set", "propertyId").StringValue() != "duplicate" {
+			t.Errorf("expected the rollback to return the nights to the duplicate, got %v", update)
+		}
+	})
+}
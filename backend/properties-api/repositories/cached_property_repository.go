@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"context"
+	"log"
+
+	"properties-api/domain"
+)
+
+// cachedPropertyRepository envuelve a PropertyRepository con un caché read-through de GetByID
+// El detalle de la propiedad lo piden constantemente el frontend y search-api (al indexar), y también
+// lo leen reservas, mensajes, fotos y gRPC: todos pasan por este repositorio y comparten el caché
+//
+// Las escrituras por ID invalidan la entrada después de escribir en MongoDB. Queda una carrera:
+// un GetByID que leyó la versión anterior puede volver a cachearla después de la invalidación;
+// el consumidor de eventos de propiedades la vuelve a invalidar y, si no, la acota el TTL.
+// IncrementViews no invalida a propósito: el volcado de vistas toca las propiedades más vistas
+// cada pocos segundos y vaciaría el caché justo donde más sirve (las vistas se atrasan hasta el TTL)
+type cachedPropertyRepository struct {
+	PropertyRepository
+	cache PropertyCacheRepository
+}
+
+// NewCachedPropertyRepository crea el repositorio de propiedades con caché sobre repo
+func NewCachedPropertyRepository(repo PropertyRepository, cache PropertyCacheRepository) PropertyRepository {
+	return &cachedPropertyRepository{
+		PropertyRepository: repo,
+		cache:              cache,
+	}
+}
+
+// GetByID lee primero del caché; en un miss busca en MongoDB y cachea la propiedad
+// Se cachea también la propiedad sin publicar: la invalidación es la misma y la moderación la lee seguido
+func (r *cachedPropertyRepository) GetByID(ctx context.Context, id string) (domain.Property, error) {
+	if property, found := r.cache.Get(id); found {
+		return property, nil
+	}
+	property, err := r.PropertyRepository.GetByID(ctx, id)
+	if err != nil {
+		return domain.Property{}, err
+	}
+	r.cache.Set(property)
+	return property, nil
+}
+
+// Update actualiza la propiedad e invalida el caché
+func (r *cachedPropertyRepository) Update(ctx context.Context, id string, property domain.Property) error {
+	if err := r.PropertyRepository.Update(ctx, id, property); err != nil {
+		return err
+	}
+	r.cache.Delete(id)
+	return nil
+}
+
+// Delete elimina la propiedad e invalida el caché
+func (r *cachedPropertyRepository) Delete(ctx context.Context, id string) error {
+	if err := r.PropertyRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.cache.Delete(id)
+	return nil
+}
+
+// SetAvailabilityByOwner actualiza las propiedades del owner e invalida cada una
+// Los IDs se buscan después de escribir para no dejar afuera una propiedad creada en el medio
+func (r *cachedPropertyRepository) SetAvailabilityByOwner(ctx context.Context, ownerID string, available bool) (int64, error) {
+	updated, err := r.PropertyRepository.SetAvailabilityByOwner(ctx, ownerID, available)
+	if err != nil || updated == 0 {
+		return updated, err
+	}
+	// La escritura ya se hizo: la invalidación no se corta aunque se cancele el request
+	properties, err := r.PropertyRepository.GetByOwnerID(context.WithoutCancel(ctx), ownerID)
+	if err != nil {
+		// La escritura ya se hizo: las entradas vencen por TTL
+		log.Printf("⚠️ Error listando las propiedades del owner %s para invalidar el caché: %v", ownerID, err)
+		return updated, nil
+	}
+	for _, property := range properties {
+		r.cache.Delete(property.ID.Hex())
+	}
+	return updated, nil
+}
+
+// ClearDuplicateFlag quita la marca de duplicado e invalida el caché
+func (r *cachedPropertyRepository) ClearDuplicateFlag(ctx context.Context, id string) error {
+	if err := r.PropertyRepository.ClearDuplicateFlag(ctx, id); err != nil {
+		return err
+	}
+	r.cache.Delete(id)
+	return nil
+}
+
+// SetBookedRanges guarda las fechas ocupadas e invalida el caché
+func (r *cachedPropertyRepository) SetBookedRanges(ctx context.Context, id string, booked []domain.DateRange, nextAvailable string) error {
+	if err := r.PropertyRepository.SetBookedRanges(ctx, id, booked, nextAvailable); err != nil {
+		return err
+	}
+	r.cache.Delete(id)
+	return nil
+}
+
+// SetPhotoVariants guarda las variantes de una foto e invalida el caché
+func (r *cachedPropertyRepository) SetPhotoVariants(ctx context.Context, id, photoURL, status string, variants []domain.PhotoVariant) error {
+	if err := r.PropertyRepository.SetPhotoVariants(ctx, id, photoURL, status, variants); err != nil {
+		return err
+	}
+	r.cache.Delete(id)
+	return nil
+}
@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"properties-api/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryPropertyRepository guarda las propiedades en memoria y cuenta las lecturas por ID
+// Embebe la interfaz: los métodos que el test no usa no están implementados
+type memoryPropertyRepository struct {
+	PropertyRepository
+	properties map[string]domain.Property
+	reads      int
+}
+
+func (m *memoryPropertyRepository) GetByID(ctx context.Context, id string) (domain.Property, error) {
+	m.reads++
+	return m.properties[id], nil
+}
+
+func (m *memoryPropertyRepository) Update(ctx context.Context, id string, property domain.Property) error {
+	m.properties[id] = property
+	return nil
+}
+
+// memoryPropertyCache es un caché de propiedades en memoria
+type memoryPropertyCache map[string]domain.Property
+
+func (m memoryPropertyCache) Get(id string) (domain.Property, bool) {
+	property, found := m[id]
+	return property, found
+}
+
+func (m memoryPropertyCache) Set(property domain.Property) {
+	m[property.ID.Hex()] = property
+}
+
+func (m memoryPropertyCache) Delete(id string) {
+	delete(m, id)
+}
+
+func TestCachedPropertyRepository_ReadsThroughAndInvalidatesOnUpdate(t *testing.T) {
+	id := primitive.NewObjectID()
+	inner := &memoryPropertyRepository{properties: map[string]domain.Property{
+		id.Hex(): {ID: id, Title: "Departamento en Nueva Córdoba"},
+	}}
+	repo := NewCachedPropertyRepository(inner, memoryPropertyCache{})
+
+	repo.GetByID(context.Background(), id.Hex())
+	repo.GetByID(context.Background(), id.Hex())
+	if inner.reads != 1 {
+		t.Fatalf("expected 1 read from the inner repository with the cache warm, got %d", inner.reads)
+	}
+
+	if err := repo.Update(context.Background(), id.Hex(), domain.Property{ID: id, Title: "Loft renovado"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	property, _ := repo.GetByID(context.Background(), id.Hex())
+	if property.Title != "Loft renovado" || inner.reads != 2 {
+		t.Fatalf("expected the updated property read from the inner repository, got %q after %d reads", property.Title, inner.reads)
+	}
+}
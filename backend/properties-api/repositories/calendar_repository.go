@@ -0,0 +1,209 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"properties-api/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AvailabilityRepository guarda los rangos de fechas bloqueados de las propiedades
+type AvailabilityRepository interface {
+	// FindByPropertyID obtiene los bloqueos de una propiedad ordenados por fecha de inicio
+	FindByPropertyID(ctx context.Context, propertyID string) ([]domain.AvailabilityBlock, error)
+	// ReplaceFeedBlocks reemplaza todos los bloqueos importados de un calendario externo
+	ReplaceFeedBlocks(ctx context.Context, feedID primitive.ObjectID, blocks []domain.AvailabilityBlock) error
+	// ReplaceManualBlocks reemplaza los bloqueos cargados por el owner (los que no vienen de un calendario externo)
+	ReplaceManualBlocks(ctx context.Context, propertyID string, blocks []domain.AvailabilityBlock) error
+}
+
+// CalendarFeedRepository guarda los calendarios externos de las propiedades
+type CalendarFeedRepository interface {
+	Create(ctx context.Context, feed *domain.CalendarFeed) error
+	FindByID(ctx context.Context, id string) (*domain.CalendarFeed, error)
+	FindByPropertyID(ctx context.Context, propertyID string) ([]domain.CalendarFeed, error)
+	// StreamAll recorre todos los calendarios (usado por la sincronización periódica)
+	StreamAll(ctx context.Context, fn func(domain.CalendarFeed) error) error
+	// UpdateSyncStatus registra el resultado de una sincronización
+	UpdateSyncStatus(ctx context.Context, id primitive.ObjectID, syncedAt *time.Time, syncErr string) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+// availabilityRepository es la implementación de AvailabilityRepository sobre MongoDB
+type availabilityRepository struct {
+	collection *mongo.Collection
+}
+
+// NewAvailabilityRepository crea una nueva instancia del repositorio de disponibilidad
+func NewAvailabilityRepository(db *mongo.Database) AvailabilityRepository {
+	return &availabilityRepository{
+		collection: db.Collection("availability_blocks"),
+	}
+}
+
+// FindByPropertyID obtiene los bloqueos de una propiedad ordenados por fecha de inicio
+func (r *availabilityRepository) FindByPropertyID(ctx context.Context, propertyID string) ([]domain.AvailabilityBlock, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "start", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"propertyId": propertyID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error buscando bloqueos de disponibilidad: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	blocks := []domain.AvailabilityBlock{}
+	if err := cursor.All(ctx, &blocks); err != nil {
+		return nil, fmt.Errorf("error decodificando bloqueos de disponibilidad: %w", err)
+	}
+	return blocks, nil
+}
+
+// ReplaceFeedBlocks borra los bloqueos del calendario y vuelve a insertar los actuales
+// Los calendarios externos se exportan completos, así que reemplazar es más simple que calcular diferencias
+func (r *availabilityRepository) ReplaceFeedBlocks(ctx context.Context, feedID primitive.ObjectID, blocks []domain.AvailabilityBlock) error {
+	if _, err := r.collection.DeleteMany(ctx, bson.M{"feedId": feedID}); err != nil {
+		return fmt.Errorf("error borrando bloqueos del calendario: %w", err)
+	}
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	docs := make([]interface{}, len(blocks))
+	for i := range blocks {
+		if blocks[i].ID.IsZero() {
+			blocks[i].ID = primitive.NewObjectID()
+		}
+		blocks[i].FeedID = feedID
+		docs[i] = blocks[i]
+	}
+	if _, err := r.collection.InsertMany(ctx, docs); err != nil {
+		return fmt.Errorf("error insertando bloqueos del calendario: %w", err)
+	}
+	return nil
+}
+
+// ReplaceManualBlocks borra los bloqueos manuales de la propiedad (feedId vacío) y vuelve a insertar los actuales
+func (r *availabilityRepository) ReplaceManualBlocks(ctx context.Context, propertyID string, blocks []domain.AvailabilityBlock) error {
+	if _, err := r.collection.DeleteMany(ctx, bson.M{"propertyId": propertyID, "feedId": primitive.NilObjectID}); err != nil {
+		return fmt.Errorf("error borrando bloqueos manuales: %w", err)
+	}
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	docs := make([]interface{}, len(blocks))
+	for i := range blocks {
+		if blocks[i].ID.IsZero() {
+			blocks[i].ID = primitive.NewObjectID()
+		}
+		blocks[i].PropertyID = propertyID
+		blocks[i].FeedID = primitive.NilObjectID
+		docs[i] = blocks[i]
+	}
+	if _, err := r.collection.InsertMany(ctx, docs); err != nil {
+		return fmt.Errorf("error insertando bloqueos manuales: %w", err)
+	}
+	return nil
+}
+
+// calendarFeedRepository es la implementación de CalendarFeedRepository sobre MongoDB
+type calendarFeedRepository struct {
+	collection *mongo.Collection
+}
+
+// NewCalendarFeedRepository crea una nueva instancia del repositorio de calendarios externos
+func NewCalendarFeedRepository(db *mongo.Database) CalendarFeedRepository {
+	return &calendarFeedRepository{
+		collection: db.Collection("calendar_feeds"),
+	}
+}
+
+// Create inserta un calendario externo
+func (r *calendarFeedRepository) Create(ctx context.Context, feed *domain.CalendarFeed) error {
+	if feed.ID.IsZero() {
+		feed.ID = primitive.NewObjectID()
+	}
+	if _, err := r.collection.InsertOne(ctx, feed); err != nil {
+		return fmt.Errorf("error insertando calendario en MongoDB: %w", err)
+	}
+	return nil
+}
+
+// FindByID obtiene un calendario externo por su ID
+func (r *calendarFeedRepository) FindByID(ctx context.Context, id string) (*domain.CalendarFeed, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("ID de calendario inválido '%s': %w", id, err)
+	}
+
+	var feed domain.CalendarFeed
+	if err := r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&feed); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("calendario con ID '%s' no encontrado", id)
+		}
+		return nil, fmt.Errorf("error obteniendo calendario: %w", err)
+	}
+	return &feed, nil
+}
+
+// FindByPropertyID obtiene los calendarios externos de una propiedad
+func (r *calendarFeedRepository) FindByPropertyID(ctx context.Context, propertyID string) ([]domain.CalendarFeed, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"propertyId": propertyID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error buscando calendarios de la propiedad: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	feeds := []domain.CalendarFeed{}
+	if err := cursor.All(ctx, &feeds); err != nil {
+		return nil, fmt.Errorf("error decodificando calendarios: %w", err)
+	}
+	return feeds, nil
+}
+
+// StreamAll recorre todos los calendarios externos con un cursor
+func (r *calendarFeedRepository) StreamAll(ctx context.Context, fn func(domain.CalendarFeed) error) error {
+	cursor, err := r.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return fmt.Errorf("error buscando calendarios: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var feed domain.CalendarFeed
+		if err := cursor.Decode(&feed); err != nil {
+			return fmt.Errorf("error decodificando calendario: %w", err)
+		}
+		if err := fn(feed); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// UpdateSyncStatus registra el resultado de una sincronización
+// syncedAt nil deja la última sincronización exitosa como estaba
+func (r *calendarFeedRepository) UpdateSyncStatus(ctx context.Context, id primitive.ObjectID, syncedAt *time.Time, syncErr string) error {
+	set := bson.M{"lastError": syncErr}
+	if syncedAt != nil {
+		set["lastSyncedAt"] = *syncedAt
+	}
+	if _, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": set}); err != nil {
+		return fmt.Errorf("error actualizando estado del calendario: %w", err)
+	}
+	return nil
+}
+
+// Delete elimina un calendario externo
+func (r *calendarFeedRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	if _, err := r.collection.DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+		return fmt.Errorf("error eliminando calendario: %w", err)
+	}
+	return nil
+}
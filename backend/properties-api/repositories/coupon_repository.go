@@ -0,0 +1,170 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"properties-api/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrCouponCodeTaken indica que ya existe un cupón con el mismo código
+var ErrCouponCodeTaken = errors.New("ya existe un cupón con ese código")
+
+// CouponRepository guarda los cupones y sus usos
+type CouponRepository interface {
+	Create(ctx context.Context, coupon *domain.Coupon) error
+	// FindByCode obtiene un cupón por su código; retorna nil sin error si no existe
+	FindByCode(ctx context.Context, code string) (*domain.Coupon, error)
+	// List obtiene todos los cupones, el más reciente primero
+	List(ctx context.Context) ([]domain.Coupon, error)
+	// SetActive activa o desactiva un cupón
+	SetActive(ctx context.Context, code string, active bool) error
+	// Reserve suma un uso al cupón si sigue activo, vigente en now y con usos disponibles
+	// Es un único update atómico: dos reservas simultáneas no pueden pasarse del límite
+	// Retorna false si el cupón ya no se puede usar
+	Reserve(ctx context.Context, id primitive.ObjectID, now time.Time) (bool, error)
+	// Release devuelve un uso reservado (ej: si después falla la creación de la reserva)
+	Release(ctx context.Context, id primitive.ObjectID) error
+	// CreateRedemption registra el uso del cupón en una reserva
+	CreateRedemption(ctx context.Context, redemption *domain.CouponRedemption) error
+	// CountUserRedemptions cuenta los usos del cupón por el usuario
+	CountUserRedemptions(ctx context.Context, couponID primitive.ObjectID, userID string) (int64, error)
+	// FindRedemptions obtiene hasta limit usos del cupón, el más reciente primero
+	FindRedemptions(ctx context.Context, couponID primitive.ObjectID, limit int) ([]domain.CouponRedemption, error)
+}
+
+// couponRepository es la implementación de CouponRepository sobre MongoDB
+type couponRepository struct {
+	coupons     *mongo.Collection
+	redemptions *mongo.Collection
+}
+
+// NewCouponRepository crea una nueva instancia del repositorio de cupones
+func NewCouponRepository(db *mongo.Database) CouponRepository {
+	return &couponRepository{
+		coupons:     db.Collection("coupons"),
+		redemptions: db.Collection("coupon_redemptions"),
+	}
+}
+
+// Create guarda un cupón nuevo; el índice único de la migración v14 rechaza códigos repetidos
+func (r *couponRepository) Create(ctx context.Context, coupon *domain.Coupon) error {
+	coupon.ID = primitive.NewObjectID()
+	if _, err := r.coupons.InsertOne(ctx, coupon); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrCouponCodeTaken
+		}
+		return fmt.Errorf("error creando cupón: %w", err)
+	}
+	return nil
+}
+
+// FindByCode obtiene un cupón por su código (sin distinguir mayúsculas)
+func (r *couponRepository) FindByCode(ctx context.Context, code string) (*domain.Coupon, error) {
+	var coupon domain.Coupon
+	err := r.coupons.FindOne(ctx, bson.M{"code": strings.ToUpper(code)}).Decode(&coupon)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo cupón: %w", err)
+	}
+	return &coupon, nil
+}
+
+// List obtiene todos los cupones ordenados por fecha de creación
+func (r *couponRepository) List(ctx context.Context) ([]domain.Coupon, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	cursor, err := r.coupons.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error listando cupones: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	coupons := []domain.Coupon{}
+	if err := cursor.All(ctx, &coupons); err != nil {
+		return nil, fmt.Errorf("error decodificando cupones: %w", err)
+	}
+	return coupons, nil
+}
+
+// SetActive activa o desactiva un cupón por su código
+func (r *couponRepository) SetActive(ctx context.Context, code string, active bool) error {
+	result, err := r.coupons.UpdateOne(ctx, bson.M{"code": strings.ToUpper(code)}, bson.M{"$set": bson.M{"active": active}})
+	if err != nil {
+		return fmt.Errorf("error actualizando cupón: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("cupón '%s' no encontrado", code)
+	}
+	return nil
+}
+
+// Reserve suma un uso solo si el documento cumple todas las condiciones en el mismo update
+func (r *couponRepository) Reserve(ctx context.Context, id primitive.ObjectID, now time.Time) (bool, error) {
+	filter := bson.M{
+		"_id":    id,
+		"active": true,
+		"$and": []bson.M{
+			{"$or": []bson.M{{"maxRedemptions": 0}, {"$expr": bson.M{"$lt": bson.A{"$redemptions", "$maxRedemptions"}}}}},
+			{"$or": []bson.M{{"validFrom": bson.M{"$exists": false}}, {"validFrom": bson.M{"$lte": now}}}},
+			{"$or": []bson.M{{"validUntil": bson.M{"$exists": false}}, {"validUntil": bson.M{"$gt": now}}}},
+		},
+	}
+	result, err := r.coupons.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"redemptions": 1}})
+	if err != nil {
+		return false, fmt.Errorf("error reservando uso del cupón: %w", err)
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// Release resta un uso al cupón sin bajar de cero
+func (r *couponRepository) Release(ctx context.Context, id primitive.ObjectID) error {
+	filter := bson.M{"_id": id, "redemptions": bson.M{"$gt": 0}}
+	if _, err := r.coupons.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"redemptions": -1}}); err != nil {
+		return fmt.Errorf("error liberando uso del cupón: %w", err)
+	}
+	return nil
+}
+
+// CreateRedemption guarda el uso del cupón
+func (r *couponRepository) CreateRedemption(ctx context.Context, redemption *domain.CouponRedemption) error {
+	redemption.ID = primitive.NewObjectID()
+	if _, err := r.redemptions.InsertOne(ctx, redemption); err != nil {
+		return fmt.Errorf("error registrando uso del cupón: %w", err)
+	}
+	return nil
+}
+
+// CountUserRedemptions cuenta los usos del cupón por el usuario
+func (r *couponRepository) CountUserRedemptions(ctx context.Context, couponID primitive.ObjectID, userID string) (int64, error) {
+	count, err := r.redemptions.CountDocuments(ctx, bson.M{"couponId": couponID, "userId": userID})
+	if err != nil {
+		return 0, fmt.Errorf("error contando usos del cupón: %w", err)
+	}
+	return count, nil
+}
+
+// FindRedemptions obtiene los usos del cupón ordenados por fecha
+func (r *couponRepository) FindRedemptions(ctx context.Context, couponID primitive.ObjectID, limit int) ([]domain.CouponRedemption, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "redeemedAt", Value: -1}}).SetLimit(int64(limit))
+	cursor, err := r.redemptions.Find(ctx, bson.M{"couponId": couponID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error buscando usos del cupón: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	redemptions := []domain.CouponRedemption{}
+	if err := cursor.All(ctx, &redemptions); err != nil {
+		return nil, fmt.Errorf("error decodificando usos del cupón: %w", err)
+	}
+	return redemptions, nil
+}
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ImageStorage guarda los archivos de las fotos subidas (originales y variantes)
+// Las claves son rutas relativas con "/" (ej: properties/<id>/<nombre>.jpg)
+type ImageStorage interface {
+	// Save guarda el archivo y retorna la URL pública con la que se sirve
+	Save(key string, data []byte) (string, error)
+	// Read lee un archivo guardado
+	Read(key string) ([]byte, error)
+	// Delete elimina un archivo; no es un error si no existe
+	Delete(key string) error
+}
+
+// localImageStorage guarda las imágenes en un directorio local servido como estático en baseURL
+type localImageStorage struct {
+	dir     string
+	baseURL string
+}
+
+// NewLocalImageStorage crea el almacenamiento en dir; las URLs se arman como baseURL + "/" + clave
+func NewLocalImageStorage(dir, baseURL string) ImageStorage {
+	return &localImageStorage{
+		dir:     dir,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// Save escribe el archivo en un temporal y lo renombra, así nunca se sirve un archivo a medio escribir
+func (s *localImageStorage) Save(key string, data []byte) (string, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("error creando directorio de imágenes: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return "", fmt.Errorf("error guardando imagen '%s': %w", key, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("error guardando imagen '%s': %w", key, err)
+	}
+
+	return s.baseURL + "/" + key, nil
+}
+
+// Read lee un archivo guardado
+func (s *localImageStorage) Read(key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo imagen '%s': %w", key, err)
+	}
+	return data, nil
+}
+
+// Delete elimina un archivo guardado
+func (s *localImageStorage) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error eliminando imagen '%s': %w", key, err)
+	}
+	return nil
+}
+
+// path convierte la clave en una ruta dentro de dir; rechaza claves que salen del directorio
+func (s *localImageStorage) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("clave de imagen inválida '%s'", key)
+	}
+	return filepath.Join(s.dir, clean), nil
+}
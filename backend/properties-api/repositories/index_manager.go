@@ -0,0 +1,378 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"properties-api/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// indexMigrationsCollection es la colección donde se registran las versiones de índices aplicadas
+const indexMigrationsCollection = "index_migrations"
+
+// IndexMigration representa una versión de índices a aplicar sobre una colección
+// Las versiones se aplican en orden y una sola vez, igual que una migración de esquema
+type IndexMigration struct {
+	Version     int
+	Description string
+	Collection  string
+	Indexes     []mongo.IndexModel
+}
+
+// appliedIndexMigration es el documento que se guarda por cada versión aplicada
+type appliedIndexMigration struct {
+	Version     int       `bson:"_id"`
+	Description string    `bson:"description"`
+	Collection  string    `bson:"collection"`
+	AppliedAt   time.Time `bson:"appliedAt"`
+}
+
+// IndexManager aplica de forma versionada los índices de MongoDB al arrancar el servicio
+// Para agregar un índice nuevo se agrega una migración con la siguiente versión,
+// nunca se modifica una versión ya publicada
+type IndexManager struct {
+	db         *mongo.Database
+	migrations []IndexMigration
+}
+
+// NewIndexManager crea un IndexManager con las migraciones de índices por defecto
+func NewIndexManager(db *mongo.Database) *IndexManager {
+	return &IndexManager{
+		db:         db,
+		migrations: DefaultIndexMigrations(),
+	}
+}
+
+// DefaultIndexMigrations retorna las migraciones de índices conocidas por el servicio
+func DefaultIndexMigrations() []IndexMigration {
+	return []IndexMigration{
+		{
+			Version:     1,
+			Description: "properties: índice por ownerId",
+			Collection:  "properties",
+			Indexes: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "ownerId", Value: 1}}, Options: options.Index().SetName("ownerId_1")},
+			},
+		},
+		{
+			Version:     2,
+			Description: "properties: índice por available",
+			Collection:  "properties",
+			Indexes: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "available", Value: 1}}, Options: options.Index().SetName("available_1")},
+			},
+		},
+		{
+			Version:     3,
+			Description: "properties: índice de texto sobre location",
+			Collection:  "properties",
+			Indexes: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "location", Value: "text"}}, Options: options.Index().SetName("location_text")},
+			},
+		},
+		{
+			Version:     4,
+			Description: "properties: índice por createdAt",
+			Collection:  "properties",
+			Indexes: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "createdAt", Value: -1}}, Options: options.Index().SetName("createdAt_-1")},
+			},
+		},
+		{
+			Version:     5,
+			Description: "bookings: índices por propertyId/checkIn y userId/checkIn",
+			Collection:  "bookings",
+			Indexes: []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "propertyId", Value: 1}, {Key: "checkIn", Value: 1}},
+					Options: options.Index().SetName("propertyId_1_checkIn_1"),
+				},
+				{
+					Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "checkIn", Value: -1}},
+					Options: options.Index().SetName("userId_1_checkIn_-1"),
+				},
+			},
+		},
+		{
+			Version:     6,
+			Description: "price_history: índice por propertyId/changedAt",
+			Collection:  "price_history",
+			Indexes: []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "propertyId", Value: 1}, {Key: "changedAt", Value: -1}},
+					Options: options.Index().SetName("propertyId_1_changedAt_-1"),
+				},
+			},
+		},
+		{
+			Version:     7,
+			Description: "properties: índice por available/views para tendencias",
+			Collection:  "properties",
+			Indexes: []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "available", Value: 1}, {Key: "views", Value: -1}},
+					Options: options.Index().SetName("available_1_views_-1"),
+				},
+			},
+		},
+		{
+			Version:     8,
+			Description: "audit_log: índices por actorId/createdAt y createdAt",
+			Collection:  "audit_log",
+			Indexes: []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "actorId", Value: 1}, {Key: "createdAt", Value: -1}},
+					Options: options.Index().SetName("actorId_1_createdAt_-1"),
+				},
+				{Keys: bson.D{{Key: "createdAt", Value: -1}}, Options: options.Index().SetName("createdAt_-1")},
+			},
+		},
+		{
+			Version:     9,
+			Description: "availability_blocks: índices por propertyId/start y feedId",
+			Collection:  "availability_blocks",
+			Indexes: []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "propertyId", Value: 1}, {Key: "start", Value: 1}},
+					Options: options.Index().SetName("propertyId_1_start_1"),
+				},
+				{Keys: bson.D{{Key: "feedId", Value: 1}}, Options: options.Index().SetName("feedId_1")},
+			},
+		},
+		{
+			Version:     10,
+			Description: "calendar_feeds: índice por propertyId",
+			Collection:  "calendar_feeds",
+			Indexes: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "propertyId", Value: 1}}, Options: options.Index().SetName("propertyId_1")},
+			},
+		},
+		{
+			Version:     11,
+			Description: "properties: índice sparse por duplicateOf (revisión de duplicados)",
+			Collection:  "properties",
+			Indexes: []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "duplicateOf", Value: 1}},
+					Options: options.Index().SetName("duplicateOf_1").SetSparse(true),
+				},
+			},
+		},
+		{
+			Version:     12,
+			Description: "conversations: índice único por (propertyId, guestId, hostId) e índices por participante",
+			Collection:  "conversations",
+			Indexes: []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "propertyId", Value: 1}, {Key: "guestId", Value: 1}, {Key: "hostId", Value: 1}},
+					Options: options.Index().SetName("propertyId_1_guestId_1_hostId_1").SetUnique(true),
+				},
+				{
+					Keys:    bson.D{{Key: "guestId", Value: 1}, {Key: "lastMessageAt", Value: -1}},
+					Options: options.Index().SetName("guestId_1_lastMessageAt_-1"),
+				},
+				{
+					Keys:    bson.D{{Key: "hostId", Value: 1}, {Key: "lastMessageAt", Value: -1}},
+					Options: options.Index().SetName("hostId_1_lastMessageAt_-1"),
+				},
+			},
+		},
+		{
+			Version:     13,
+			Description: "messages: índice por conversationId y createdAt (paginación de mensajes)",
+			Collection:  "messages",
+			Indexes: []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "conversationId", Value: 1}, {Key: "createdAt", Value: -1}},
+					Options: options.Index().SetName("conversationId_1_createdAt_-1"),
+				},
+			},
+		},
+		{
+			Version:     14,
+			Description: "coupons: índice único por code",
+			Collection:  "coupons",
+			Indexes: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "code", Value: 1}}, Options: options.Index().SetName("code_1").SetUnique(true)},
+			},
+		},
+		{
+			Version:     15,
+			Description: "coupon_redemptions: índices por couponId/redeemedAt y couponId/userId",
+			Collection:  "coupon_redemptions",
+			Indexes: []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "couponId", Value: 1}, {Key: "redeemedAt", Value: -1}},
+					Options: options.Index().SetName("couponId_1_redeemedAt_-1"),
+				},
+				{
+					Keys:    bson.D{{Key: "couponId", Value: 1}, {Key: "userId", Value: 1}},
+					Options: options.Index().SetName("couponId_1_userId_1"),
+				},
+			},
+		},
+		{
+			Version:     16,
+			Description: "moderation_cases: índices por status/createdAt, entidad pendiente y submittedBy/createdAt",
+			Collection:  "moderation_cases",
+			Indexes: []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "status", Value: 1}, {Key: "createdAt", Value: 1}},
+					Options: options.Index().SetName("status_1_createdAt_1"),
+				},
+				{
+					Keys:    bson.D{{Key: "entityType", Value: 1}, {Key: "entityId", Value: 1}, {Key: "status", Value: 1}},
+					Options: options.Index().SetName("entityType_1_entityId_1_status_1"),
+				},
+				{
+					Keys:    bson.D{{Key: "submittedBy", Value: 1}, {Key: "createdAt", Value: -1}},
+					Options: options.Index().SetName("submittedBy_1_createdAt_-1"),
+				},
+			},
+		},
+		{
+			Version:     17,
+			Description: "properties: índice por ownerId/createdAt para el listado del propietario",
+			Collection:  "properties",
+			Indexes: []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "ownerId", Value: 1}, {Key: "createdAt", Value: -1}},
+					Options: options.Index().SetName("ownerId_1_createdAt_-1"),
+				},
+			},
+		},
+		{
+			Version:     18,
+			Description: "bookings: índice por status/expiresAt para vencer las solicitudes pendientes",
+			Collection:  "bookings",
+			Indexes: []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "status", Value: 1}, {Key: "expiresAt", Value: 1}},
+					Options: options.Index().SetName("status_1_expiresAt_1"),
+				},
+			},
+		},
+		{
+			Version:     19,
+			Description: "booking_nights: índice único por (propertyId, night) e índice por bookingId",
+			Collection:  "booking_nights",
+			Indexes: []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "propertyId", Value: 1}, {Key: "night", Value: 1}},
+					Options: options.Index().SetName("propertyId_1_night_1").SetUnique(true),
+				},
+				{Keys: bson.D{{Key: "bookingId", Value: 1}}, Options: options.Index().SetName("bookingId_1")},
+			},
+		},
+		{
+			Version:     20,
+			Description: "booking_holds: índice por expiresAt para liberar los holds vencidos",
+			Collection:  "booking_holds",
+			Indexes: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "expiresAt", Value: 1}}, Options: options.Index().SetName("expiresAt_1")},
+			},
+		},
+		{
+			Version:     21,
+			Description: "auto_translations: índice único por (propertyId, locale) y TTL de 30 días",
+			Collection:  "auto_translations",
+			Indexes: []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "propertyId", Value: 1}, {Key: "locale", Value: 1}},
+					Options: options.Index().SetName("propertyId_1_locale_1").SetUnique(true),
+				},
+				// Las traducciones de propiedades borradas o que nadie vuelve a pedir se descartan solas
+				{
+					Keys:    bson.D{{Key: "translatedAt", Value: 1}},
+					Options: options.Index().SetName("translatedAt_1").SetExpireAfterSeconds(30 * 24 * 60 * 60),
+				},
+			},
+		},
+		{
+			Version:     22,
+			Description: "bookings: índice por createdAt para la agregación diaria de analytics",
+			Collection:  "bookings",
+			Indexes: []mongo.IndexModel{
+				{Keys: bson.D{{Key: "createdAt", Value: -1}}, Options: options.Index().SetName("createdAt_-1")},
+			},
+		},
+		{
+			Version:     23,
+			Description: "job_locks: TTL por expiresAt para descartar los locks vencidos de los jobs programados",
+			Collection:  "job_locks",
+			Indexes: []mongo.IndexModel{
+				// Un lock vencido ya se puede volver a tomar; el TTL solo limpia los documentos
+				{Keys: bson.D{{Key: "expiresAt", Value: 1}}, Options: options.Index().SetName("expiresAt_1").SetExpireAfterSeconds(0)},
+			},
+		},
+	}
+}
+
+// EnsureIndexes aplica en orden las migraciones de índices pendientes
+// Se detiene en la primera que falle sin registrarla, así en el próximo arranque se reintenta
+func (m *IndexManager) EnsureIndexes(ctx context.Context) error {
+	applied, err := m.appliedVersions(ctx)
+	if err != nil {
+		return err
+	}
+
+	migrations := make([]IndexMigration, len(m.migrations))
+	copy(migrations, m.migrations)
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	for _, migration := range migrations {
+		if applied[migration.Version] {
+			continue
+		}
+
+		log.Printf("🔄 Aplicando índices v%d: %s", migration.Version, migration.Description)
+
+		names, err := m.db.Collection(migration.Collection).Indexes().CreateMany(ctx, migration.Indexes)
+		if err != nil {
+			return fmt.Errorf("error aplicando índices v%d (%s): %w", migration.Version, migration.Description, err)
+		}
+
+		record := appliedIndexMigration{
+			Version:     migration.Version,
+			Description: migration.Description,
+			Collection:  migration.Collection,
+			AppliedAt:   utils.NowUTC(),
+		}
+		if _, err := m.db.Collection(indexMigrationsCollection).InsertOne(ctx, record); err != nil {
+			return fmt.Errorf("error registrando índices v%d: %w", migration.Version, err)
+		}
+
+		log.Printf("✅ Índices v%d aplicados: %v", migration.Version, names)
+	}
+
+	return nil
+}
+
+// appliedVersions obtiene las versiones de índices ya aplicadas
+func (m *IndexManager) appliedVersions(ctx context.Context) (map[int]bool, error) {
+	cursor, err := m.db.Collection(indexMigrationsCollection).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo versiones de índices aplicadas: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var records []appliedIndexMigration
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, fmt.Errorf("error decodificando versiones de índices aplicadas: %w", err)
+	}
+
+	applied := make(map[int]bool, len(records))
+	for _, record := range records {
+		applied[record.Version] = true
+	}
+	return applied, nil
+}
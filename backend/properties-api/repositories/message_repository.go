@@ -0,0 +1,218 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"properties-api/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ConversationRepository guarda las conversaciones entre huéspedes y anfitriones
+type ConversationRepository interface {
+	// FindOrCreate obtiene la conversación de (propiedad, huésped, anfitrión) o la crea si no existe
+	// Completa conversation con el documento guardado
+	FindOrCreate(ctx context.Context, conversation *domain.Conversation) error
+	FindByID(ctx context.Context, id string) (*domain.Conversation, error)
+	// FindByParticipant obtiene las conversaciones del usuario (como huésped o anfitrión), la más reciente primero
+	FindByParticipant(ctx context.Context, userID string, limit int) ([]domain.Conversation, error)
+	// RecordMessage actualiza el último mensaje y suma uno a los no leídos del destinatario
+	// Retorna la conversación actualizada
+	RecordMessage(ctx context.Context, id primitive.ObjectID, preview string, sentAt time.Time, recipientIsHost bool) (*domain.Conversation, error)
+	// MarkRead pone en cero los no leídos del participante
+	MarkRead(ctx context.Context, id primitive.ObjectID, asHost bool) error
+	// CountUnread suma los mensajes sin leer del usuario en todas sus conversaciones
+	CountUnread(ctx context.Context, userID string) (int64, error)
+}
+
+// MessageRepository guarda los mensajes de las conversaciones
+type MessageRepository interface {
+	Create(ctx context.Context, message *domain.Message) error
+	// FindByConversation obtiene hasta limit mensajes anteriores a before, el más reciente primero
+	// before cero no filtra por fecha
+	FindByConversation(ctx context.Context, conversationID primitive.ObjectID, before time.Time, limit int) ([]domain.Message, error)
+}
+
+// conversationRepository es la implementación de ConversationRepository sobre MongoDB
+type conversationRepository struct {
+	collection *mongo.Collection
+}
+
+// NewConversationRepository crea una nueva instancia del repositorio de conversaciones
+func NewConversationRepository(db *mongo.Database) ConversationRepository {
+	return &conversationRepository{
+		collection: db.Collection("conversations"),
+	}
+}
+
+// FindOrCreate hace un upsert por (propiedad, huésped, anfitrión)
+// El índice único de la migración v12 evita duplicados si dos mensajes llegan a la vez
+func (r *conversationRepository) FindOrCreate(ctx context.Context, conversation *domain.Conversation) error {
+	filter := bson.M{
+		"propertyId": conversation.PropertyID,
+		"guestId":    conversation.GuestID,
+		"hostId":     conversation.HostID,
+	}
+	now := time.Now().UTC()
+	update := bson.M{"$setOnInsert": bson.M{
+		"lastMessageAt": now,
+		"guestUnread":   0,
+		"hostUnread":    0,
+		"createdAt":     now,
+	}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	if err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(conversation); err != nil {
+		return fmt.Errorf("error obteniendo conversación: %w", err)
+	}
+	return nil
+}
+
+// FindByID obtiene una conversación por su ID
+func (r *conversationRepository) FindByID(ctx context.Context, id string) (*domain.Conversation, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("ID de conversación inválido '%s': %w", id, err)
+	}
+
+	var conversation domain.Conversation
+	if err := r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&conversation); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("conversación con ID '%s' no encontrada", id)
+		}
+		return nil, fmt.Errorf("error obteniendo conversación: %w", err)
+	}
+	return &conversation, nil
+}
+
+// FindByParticipant obtiene las conversaciones del usuario ordenadas por el último mensaje
+func (r *conversationRepository) FindByParticipant(ctx context.Context, userID string, limit int) ([]domain.Conversation, error) {
+	filter := bson.M{"$or": []bson.M{{"guestId": userID}, {"hostId": userID}}}
+	opts := options.Find().SetSort(bson.D{{Key: "lastMessageAt", Value: -1}}).SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error buscando conversaciones: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	conversations := []domain.Conversation{}
+	if err := cursor.All(ctx, &conversations); err != nil {
+		return nil, fmt.Errorf("error decodificando conversaciones: %w", err)
+	}
+	return conversations, nil
+}
+
+// RecordMessage registra un mensaje nuevo en la conversación con un único update atómico
+func (r *conversationRepository) RecordMessage(ctx context.Context, id primitive.ObjectID, preview string, sentAt time.Time, recipientIsHost bool) (*domain.Conversation, error) {
+	unreadField := "guestUnread"
+	if recipientIsHost {
+		unreadField = "hostUnread"
+	}
+	update := bson.M{
+		"$set": bson.M{"lastMessage": preview, "lastMessageAt": sentAt},
+		"$inc": bson.M{unreadField: 1},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var conversation domain.Conversation
+	if err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, update, opts).Decode(&conversation); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("conversación con ID '%s' no encontrada", id.Hex())
+		}
+		return nil, fmt.Errorf("error actualizando conversación: %w", err)
+	}
+	return &conversation, nil
+}
+
+// MarkRead pone en cero los no leídos del huésped o del anfitrión
+func (r *conversationRepository) MarkRead(ctx context.Context, id primitive.ObjectID, asHost bool) error {
+	unreadField := "guestUnread"
+	if asHost {
+		unreadField = "hostUnread"
+	}
+	if _, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{unreadField: 0}}); err != nil {
+		return fmt.Errorf("error marcando conversación como leída: %w", err)
+	}
+	return nil
+}
+
+// CountUnread suma los no leídos del usuario: guestUnread donde es huésped y hostUnread donde es anfitrión
+func (r *conversationRepository) CountUnread(ctx context.Context, userID string) (int64, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"$or": []bson.M{{"guestId": userID}, {"hostId": userID}}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": nil,
+			"unread": bson.M{"$sum": bson.M{"$add": bson.A{
+				bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$guestId", userID}}, "$guestUnread", 0}},
+				bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$hostId", userID}}, "$hostUnread", 0}},
+			}}},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, fmt.Errorf("error contando mensajes sin leer: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var result []struct {
+		Unread int64 `bson:"unread"`
+	}
+	if err := cursor.All(ctx, &result); err != nil {
+		return 0, fmt.Errorf("error decodificando mensajes sin leer: %w", err)
+	}
+	if len(result) == 0 {
+		return 0, nil
+	}
+	return result[0].Unread, nil
+}
+
+// messageRepository es la implementación de MessageRepository sobre MongoDB
+type messageRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMessageRepository crea una nueva instancia del repositorio de mensajes
+func NewMessageRepository(db *mongo.Database) MessageRepository {
+	return &messageRepository{
+		collection: db.Collection("messages"),
+	}
+}
+
+// Create inserta un mensaje
+func (r *messageRepository) Create(ctx context.Context, message *domain.Message) error {
+	if message.ID.IsZero() {
+		message.ID = primitive.NewObjectID()
+	}
+	if _, err := r.collection.InsertOne(ctx, message); err != nil {
+		return fmt.Errorf("error insertando mensaje en MongoDB: %w", err)
+	}
+	return nil
+}
+
+// FindByConversation obtiene una página de mensajes de la conversación, del más reciente al más antiguo
+func (r *messageRepository) FindByConversation(ctx context.Context, conversationID primitive.ObjectID, before time.Time, limit int) ([]domain.Message, error) {
+	filter := bson.M{"conversationId": conversationID}
+	if !before.IsZero() {
+		filter["createdAt"] = bson.M{"$lt": before}
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}).SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error buscando mensajes: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	messages := []domain.Message{}
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, fmt.Errorf("error decodificando mensajes: %w", err)
+	}
+	return messages, nil
+}
@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"properties-api/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ModerationRepository guarda los casos de contenido retenido para revisión
+type ModerationRepository interface {
+	Create(ctx context.Context, moderationCase *domain.ModerationCase) error
+	// GetByID obtiene un caso por ID; retorna nil sin error si no existe
+	GetByID(ctx context.Context, id string) (*domain.ModerationCase, error)
+	// List obtiene hasta limit casos con el estado indicado (vacío = todos), el más viejo primero
+	List(ctx context.Context, status string, limit int) ([]domain.ModerationCase, error)
+	// ListBySubmitter obtiene hasta limit casos enviados por el usuario, el más reciente primero
+	ListBySubmitter(ctx context.Context, userID string, limit int) ([]domain.ModerationCase, error)
+	// Resolve cierra el caso solo si sigue pendiente; retorna false si ya estaba resuelto
+	Resolve(ctx context.Context, id primitive.ObjectID, status, reviewedBy, note string, reviewedAt time.Time) (bool, error)
+	// SupersedePending marca como reemplazados los casos pendientes de una entidad
+	SupersedePending(ctx context.Context, entityType, entityID string) error
+}
+
+// moderationRepository es la implementación de ModerationRepository sobre MongoDB
+type moderationRepository struct {
+	collection *mongo.Collection
+}
+
+// NewModerationRepository crea una nueva instancia del repositorio de moderación
+func NewModerationRepository(db *mongo.Database) ModerationRepository {
+	return &moderationRepository{
+		collection: db.Collection("moderation_cases"),
+	}
+}
+
+// Create guarda un caso nuevo
+func (r *moderationRepository) Create(ctx context.Context, moderationCase *domain.ModerationCase) error {
+	moderationCase.ID = primitive.NewObjectID()
+	if _, err := r.collection.InsertOne(ctx, moderationCase); err != nil {
+		return fmt.Errorf("error creando caso de moderación: %w", err)
+	}
+	return nil
+}
+
+// GetByID obtiene un caso por su ID
+func (r *moderationRepository) GetByID(ctx context.Context, id string) (*domain.ModerationCase, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, nil
+	}
+
+	var moderationCase domain.ModerationCase
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&moderationCase)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo caso de moderación: %w", err)
+	}
+	return &moderationCase, nil
+}
+
+// List obtiene los casos por estado; la cola se revisa en orden de llegada
+func (r *moderationRepository) List(ctx context.Context, status string, limit int) ([]domain.ModerationCase, error) {
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: 1}}).
+		SetLimit(int64(limit))
+	return r.find(ctx, filter, opts)
+}
+
+// ListBySubmitter obtiene los casos del usuario, el más reciente primero
+func (r *moderationRepository) ListBySubmitter(ctx context.Context, userID string, limit int) ([]domain.ModerationCase, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}}).
+		SetLimit(int64(limit))
+	return r.find(ctx, bson.M{"submittedBy": userID}, opts)
+}
+
+// Resolve cierra el caso en un único update condicionado a que siga pendiente
+func (r *moderationRepository) Resolve(ctx context.Context, id primitive.ObjectID, status, reviewedBy, note string, reviewedAt time.Time) (bool, error) {
+	filter := bson.M{"_id": id, "status": domain.ModerationCasePending}
+	update := bson.M{"$set": bson.M{
+		"status":     status,
+		"reviewedBy": reviewedBy,
+		"reviewedAt": reviewedAt,
+		"note":       note,
+	}}
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, fmt.Errorf("error resolviendo caso de moderación: %w", err)
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// SupersedePending marca como reemplazados los casos pendientes de la entidad
+func (r *moderationRepository) SupersedePending(ctx context.Context, entityType, entityID string) error {
+	filter := bson.M{"entityType": entityType, "entityId": entityID, "status": domain.ModerationCasePending}
+	update := bson.M{"$set": bson.M{"status": domain.ModerationCaseSuperseded}}
+	if _, err := r.collection.UpdateMany(ctx, filter, update); err != nil {
+		return fmt.Errorf("error reemplazando casos de moderación: %w", err)
+	}
+	return nil
+}
+
+// find ejecuta una búsqueda y decodifica los casos
+func (r *moderationRepository) find(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]domain.ModerationCase, error) {
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error listando casos de moderación: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	cases := []domain.ModerationCase{}
+	if err := cursor.All(ctx, &cases); err != nil {
+		return nil, fmt.Errorf("error decodificando casos de moderación: %w", err)
+	}
+	return cases, nil
+}
@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"properties-api/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PriceHistoryRepository define las operaciones sobre el historial de precios
+type PriceHistoryRepository interface {
+	Create(ctx context.Context, entry domain.PriceHistoryEntry) error
+	GetByPropertyID(ctx context.Context, propertyID string) ([]domain.PriceHistoryEntry, error)
+}
+
+// priceHistoryRepository es la implementación en MongoDB (colección "price_history")
+type priceHistoryRepository struct {
+	collection *mongo.Collection
+}
+
+// NewPriceHistoryRepository crea una nueva instancia del repositorio de historial de precios
+func NewPriceHistoryRepository(collection *mongo.Collection) PriceHistoryRepository {
+	return &priceHistoryRepository{
+		collection: collection,
+	}
+}
+
+// Create registra un cambio de precio
+func (r *priceHistoryRepository) Create(ctx context.Context, entry domain.PriceHistoryEntry) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if entry.ID.IsZero() {
+		entry.ID = primitive.NewObjectID()
+	}
+
+	if _, err := r.collection.InsertOne(ctx, entry); err != nil {
+		return fmt.Errorf("error insertando historial de precio en MongoDB: %w", err)
+	}
+
+	return nil
+}
+
+// GetByPropertyID obtiene el historial de precios de una propiedad ordenado del más reciente al más antiguo
+func (r *priceHistoryRepository) GetByPropertyID(ctx context.Context, propertyID string) ([]domain.PriceHistoryEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "changedAt", Value: -1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"propertyId": propertyID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error buscando historial de precios de '%s': %w", propertyID, err)
+	}
+	defer cursor.Close(ctx)
+
+	var entries []domain.PriceHistoryEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("error decodificando historial de precios: %w", err)
+	}
+
+	if entries == nil {
+		entries = []domain.PriceHistoryEntry{}
+	}
+
+	return entries, nil
+}
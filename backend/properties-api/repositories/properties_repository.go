@@ -2,25 +2,77 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
 	"time"
 
 	"properties-api/domain"
+	"properties-api/utils"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// ErrPhotoNotInGallery indica que la propiedad no tiene una foto con la URL indicada
+var ErrPhotoNotInGallery = errors.New("la foto no está en la galería de la propiedad")
+
+// unpublishedModerationStatuses son los estados de moderación de las propiedades que no se muestran
+var unpublishedModerationStatuses = bson.A{domain.ModerationPending, domain.ModerationRejected}
+
+// exportBatchSize es la cantidad de documentos que trae el cursor por viaje en los exports
+const exportBatchSize = 500
+
+// PropertyFilter son los filtros del export de propiedades (los campos vacíos no filtran)
+type PropertyFilter struct {
+	OwnerID      string
+	PropertyType string
+	Available    *bool
+	CreatedFrom  time.Time // Desde (inclusive)
+	CreatedTo    time.Time // Hasta (exclusive)
+	UpdatedFrom  time.Time // Modificadas desde (inclusive)
+	UpdatedTo    time.Time // Modificadas hasta (exclusive)
+}
+
+// OwnerPropertyFilter son los filtros del listado paginado de propiedades de un propietario
+// Los campos vacíos no filtran
+type OwnerPropertyFilter struct {
+	OwnerID   string
+	Title     string // Texto que tiene que aparecer en el título (sin distinguir mayúsculas)
+	Available *bool
+	Status    string // "published" o un estado de moderación (pending, rejected, changes_pending)
+	SortField string // createdAt, title, price o views
+	SortDesc  bool
+	Page      int
+	Limit     int
+}
+
+// OwnerStatusPublished es el filtro de estado de las propiedades que se muestran (ver domain.Property.IsPublished)
+const OwnerStatusPublished = "published"
+
 // PropertyRepository define la interfaz para las operaciones de repositorio de propiedades
 // Implementa el patrón de repositorio para abstraer la lógica de acceso a datos
 type PropertyRepository interface {
-	Create(property domain.Property) (domain.Property, error)
-	GetByID(id string) (domain.Property, error)
-	GetByOwnerID(ownerID string) ([]domain.Property, error)
-	Update(id string, property domain.Property) error
-	Delete(id string) error
-	GetAll() ([]domain.Property, error) // ← AGREGAR ESTA LÍNEA
+	Create(ctx context.Context, property domain.Property) (domain.Property, error)
+	CreateMany(ctx context.Context, properties []domain.Property) ([]error, error)
+	GetByID(ctx context.Context, id string) (domain.Property, error)
+	GetByOwnerID(ctx context.Context, ownerID string) ([]domain.Property, error)
+	FindByOwner(ctx context.Context, filter OwnerPropertyFilter) ([]domain.Property, int64, error)
+	Update(ctx context.Context, id string, property domain.Property) error
+	Delete(ctx context.Context, id string) error
+	GetAll(ctx context.Context) ([]domain.Property, error)
+	StreamAll(ctx context.Context, fn func(domain.Property) error) error
+	StreamFiltered(ctx context.Context, filter PropertyFilter, fn func(domain.Property) error) error
+	SetAvailabilityByOwner(ctx context.Context, ownerID string, available bool) (int64, error)
+	IncrementViews(ctx context.Context, id string, delta int64) (int64, error)
+	GetTrending(ctx context.Context, since time.Time, limit int) ([]domain.Property, error)
+	FindFlaggedDuplicates(ctx context.Context) ([]domain.Property, error)
+	ClearDuplicateFlag(ctx context.Context, id string) error
+	ListVersions(ctx context.Context, afterID string, limit int) ([]domain.Property, error)
+	SetPhotoVariants(ctx context.Context, id, photoURL, status string, variants []domain.PhotoVariant) error
+	SetBookedRanges(ctx context.Context, id string, booked []domain.DateRange, nextAvailable string) error
 }
 
 // propertyRepository es la implementación concreta de PropertyRepository
@@ -40,9 +92,9 @@ func NewPropertyRepository(collection *mongo.Collection) PropertyRepository {
 
 // Create crea una nueva propiedad en la base de datos
 // Genera automáticamente un nuevo ObjectID y establece las fechas de creación y actualización
-func (r *propertyRepository) Create(property domain.Property) (domain.Property, error) {
+func (r *propertyRepository) Create(ctx context.Context, property domain.Property) (domain.Property, error) {
 	// Crear contexto con timeout para la operación
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	// Generar nuevo ObjectID automáticamente si no existe
@@ -50,8 +102,8 @@ func (r *propertyRepository) Create(property domain.Property) (domain.Property,
 		property.ID = primitive.NewObjectID()
 	}
 
-	// Establecer fechas de creación y actualización en formato ISO 8601
-	now := time.Now()
+	// Establecer fechas de creación y actualización (siempre en UTC)
+	now := utils.NowUTC()
 	property.CreatedAt = now
 	property.UpdatedAt = now
 
@@ -69,11 +121,49 @@ func (r *propertyRepository) Create(property domain.Property) (domain.Property,
 	return property, nil
 }
 
+// CreateMany inserta un lote de propiedades con un único InsertMany no ordenado
+// Completa en el slice el ID y las fechas de cada propiedad
+// Retorna un error por propiedad (nil si se insertó) o un error general si falló todo el lote
+func (r *propertyRepository) CreateMany(ctx context.Context, properties []domain.Property) ([]error, error) {
+	if len(properties) == 0 {
+		return nil, nil
+	}
+
+	now := utils.NowUTC()
+	docs := make([]interface{}, len(properties))
+	for i := range properties {
+		if properties[i].ID.IsZero() {
+			properties[i].ID = primitive.NewObjectID()
+		}
+		properties[i].CreatedAt = now
+		properties[i].UpdatedAt = now
+		docs[i] = properties[i]
+	}
+
+	// No ordenado: una fila con error no impide insertar las demás del lote
+	rowErrors := make([]error, len(properties))
+	_, err := r.collection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	if err == nil {
+		return rowErrors, nil
+	}
+
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || len(bulkErr.WriteErrors) == 0 {
+		return nil, fmt.Errorf("error insertando propiedades en MongoDB: %w", err)
+	}
+	for _, writeErr := range bulkErr.WriteErrors {
+		if writeErr.Index >= 0 && writeErr.Index < len(rowErrors) {
+			rowErrors[writeErr.Index] = fmt.Errorf("error insertando propiedad en MongoDB: %s", writeErr.Message)
+		}
+	}
+	return rowErrors, nil
+}
+
 // GetByID obtiene una propiedad por su ID (string)
 // Convierte el string a ObjectID y realiza la búsqueda en MongoDB
-func (r *propertyRepository) GetByID(id string) (domain.Property, error) {
+func (r *propertyRepository) GetByID(ctx context.Context, id string) (domain.Property, error) {
 	// Crear contexto con timeout para la operación
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	// Convertir string a ObjectID
@@ -100,9 +190,9 @@ func (r *propertyRepository) GetByID(id string) (domain.Property, error) {
 
 // Update actualiza una propiedad existente por su ID
 // Convierte el string a ObjectID y actualiza todos los campos
-func (r *propertyRepository) Update(id string, property domain.Property) error {
+func (r *propertyRepository) Update(ctx context.Context, id string, property domain.Property) error {
 	// Crear contexto con timeout para la operación
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	// Convertir string a ObjectID
@@ -111,8 +201,8 @@ func (r *propertyRepository) Update(id string, property domain.Property) error {
 		return fmt.Errorf("ID inválido '%s': %w", id, err)
 	}
 
-	// Actualizar el campo UpdatedAt con la fecha actual en formato ISO 8601
-	property.UpdatedAt = time.Now()
+	// Actualizar el campo UpdatedAt con la fecha actual (UTC)
+	property.UpdatedAt = utils.NowUTC()
 
 	// Asegurar que el ID de la propiedad coincida con el parámetro
 	property.ID = objectID
@@ -123,15 +213,24 @@ func (r *propertyRepository) Update(id string, property domain.Property) error {
 	// Crear documento de actualización usando $set para actualizar todos los campos
 	update := bson.M{
 		"$set": bson.M{
-			"title":       property.Title,
-			"description": property.Description,
-			"price":       property.Price,
-			"location":    property.Location,
-			"ownerId":     property.OwnerID,
-			"amenities":   property.Amenities,
-			"capacity":    property.Capacity,
-			"available":   property.Available,
-			"updatedAt":   property.UpdatedAt,
+			"title":        property.Title,
+			"description":  property.Description,
+			"price":        property.Price,
+			"cleaningFee":  property.CleaningFee,
+			"location":     property.Location,
+			"propertyType": property.PropertyType,
+			"latitude":     property.Latitude,
+			"longitude":    property.Longitude,
+			"timezone":     property.Timezone,
+			"country":      property.Country,
+			"region":       property.Region,
+			"ownerId":      property.OwnerID,
+			"amenities":    property.Amenities,
+			"capacity":     property.Capacity,
+			"available":    property.Available,
+			"images":       property.Images,
+			"signature":    property.Signature,
+			"updatedAt":    property.UpdatedAt,
 		},
 	}
 
@@ -151,9 +250,9 @@ func (r *propertyRepository) Update(id string, property domain.Property) error {
 
 // Delete elimina una propiedad por su ID
 // Convierte el string a ObjectID y elimina el documento de MongoDB
-func (r *propertyRepository) Delete(id string) error {
+func (r *propertyRepository) Delete(ctx context.Context, id string) error {
 	// Crear contexto con timeout para la operación
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	// Convertir string a ObjectID
@@ -181,9 +280,9 @@ func (r *propertyRepository) Delete(id string) error {
 
 // GetByOwnerID obtiene todas las propiedades de un propietario específico
 // Usa cursor para obtener múltiples resultados eficientemente
-func (r *propertyRepository) GetByOwnerID(ownerID string) ([]domain.Property, error) {
+func (r *propertyRepository) GetByOwnerID(ctx context.Context, ownerID string) ([]domain.Property, error) {
 	// Crear contexto con timeout para la operación
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
 	// Crear filtro BSON para buscar por ownerId
@@ -218,16 +317,341 @@ func (r *propertyRepository) GetByOwnerID(ownerID string) ([]domain.Property, er
 	return properties, nil
 }
 
+// FindByOwner obtiene una página de las propiedades de un propietario y el total sin paginar
+// El orden se desempata por _id para que las páginas sean estables
+func (r *propertyRepository) FindByOwner(ctx context.Context, filter OwnerPropertyFilter) ([]domain.Property, int64, error) {
+	query := bson.M{"ownerId": filter.OwnerID}
+	if filter.Title != "" {
+		query["title"] = primitive.Regex{Pattern: regexp.QuoteMeta(filter.Title), Options: "i"}
+	}
+	if filter.Available != nil {
+		query["available"] = *filter.Available
+	}
+	switch filter.Status {
+	case "":
+	case OwnerStatusPublished:
+		query["moderationStatus"] = bson.M{"$nin": unpublishedModerationStatuses}
+	default:
+		query["moderationStatus"] = filter.Status
+	}
+
+	total, err := r.collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error contando propiedades del propietario '%s': %w", filter.OwnerID, err)
+	}
+
+	sortField := filter.SortField
+	if sortField == "" {
+		sortField = "createdAt"
+	}
+	direction := 1
+	if filter.SortDesc {
+		direction = -1
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: sortField, Value: direction}, {Key: "_id", Value: direction}}).
+		SetSkip(int64(utils.CalculateSkip(filter.Page, filter.Limit))).
+		SetLimit(int64(filter.Limit))
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error buscando propiedades del propietario '%s' en MongoDB: %w", filter.OwnerID, err)
+	}
+	defer cursor.Close(ctx)
+
+	properties := []domain.Property{}
+	if err := cursor.All(ctx, &properties); err != nil {
+		return nil, 0, fmt.Errorf("error decodificando propiedades del cursor: %w", err)
+	}
+	return properties, total, nil
+}
+
 // GetAll obtiene todas las propiedades del sistema (solo admin)
-func (r *propertyRepository) GetAll() ([]domain.Property, error) {
+func (r *propertyRepository) GetAll(ctx context.Context) ([]domain.Property, error) {
 	var properties []domain.Property
-	cursor, err := r.collection.Find(context.Background(), bson.M{})
+	cursor, err := r.collection.Find(ctx, bson.M{})
 	if err != nil {
 		return nil, fmt.Errorf("error buscando todas las propiedades: %w", err)
 	}
-	defer cursor.Close(context.Background())
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &properties); err != nil {
+		return nil, fmt.Errorf("error decodificando propiedades: %w", err)
+	}
+
+	return properties, nil
+}
+
+// StreamAll recorre todas las propiedades con un cursor llamando a fn por cada una
+// A diferencia de GetAll no carga la colección completa en memoria
+func (r *propertyRepository) StreamAll(ctx context.Context, fn func(domain.Property) error) error {
+	cursor, err := r.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return fmt.Errorf("error buscando todas las propiedades: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var property domain.Property
+		if err := cursor.Decode(&property); err != nil {
+			return fmt.Errorf("error decodificando propiedad: %w", err)
+		}
+		if err := fn(property); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// StreamFiltered recorre con un cursor las propiedades que cumplen el filtro, de la más vieja a la más nueva
+func (r *propertyRepository) StreamFiltered(ctx context.Context, filter PropertyFilter, fn func(domain.Property) error) error {
+	query := bson.M{}
+	if filter.OwnerID != "" {
+		query["ownerId"] = filter.OwnerID
+	}
+	if filter.PropertyType != "" {
+		query["propertyType"] = filter.PropertyType
+	}
+	if filter.Available != nil {
+		query["available"] = *filter.Available
+	}
+	if createdAt := timeRange(filter.CreatedFrom, filter.CreatedTo); len(createdAt) > 0 {
+		query["createdAt"] = createdAt
+	}
+	if updatedAt := timeRange(filter.UpdatedFrom, filter.UpdatedTo); len(updatedAt) > 0 {
+		query["updatedAt"] = updatedAt
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}}).
+		SetBatchSize(exportBatchSize)
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return fmt.Errorf("error buscando propiedades: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var property domain.Property
+		if err := cursor.Decode(&property); err != nil {
+			return fmt.Errorf("error decodificando propiedad: %w", err)
+		}
+		if err := fn(property); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// timeRange arma el filtro {$gte: from, $lt: to} omitiendo los extremos en cero
+func timeRange(from, to time.Time) bson.M {
+	condition := bson.M{}
+	if !from.IsZero() {
+		condition["$gte"] = from
+	}
+	if !to.IsZero() {
+		condition["$lt"] = to
+	}
+	return condition
+}
+
+// SetAvailabilityByOwner cambia la disponibilidad de todas las propiedades de un propietario
+// Retorna la cantidad de propiedades modificadas
+func (r *propertyRepository) SetAvailabilityByOwner(ctx context.Context, ownerID string, available bool) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	update := bson.M{
+		"$set": bson.M{
+			"available": available,
+			"updatedAt": utils.NowUTC(),
+		},
+	}
+
+	result, err := r.collection.UpdateMany(ctx, bson.M{"ownerId": ownerID}, update)
+	if err != nil {
+		return 0, fmt.Errorf("error actualizando propiedades del owner '%s' en MongoDB: %w", ownerID, err)
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// IncrementViews suma delta vistas a la propiedad y actualiza la fecha de última vista
+// Retorna el total de vistas luego del incremento
+func (r *propertyRepository) IncrementViews(ctx context.Context, id string, delta int64) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return 0, fmt.Errorf("ID inválido '%s': %w", id, err)
+	}
+
+	update := bson.M{
+		"$inc": bson.M{"views": delta},
+		"$set": bson.M{"lastViewedAt": utils.NowUTC()},
+	}
+	opts := options.FindOneAndUpdate().
+		SetReturnDocument(options.After).
+		SetProjection(bson.M{"views": 1})
+
+	var property domain.Property
+	if err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": objectID}, update, opts).Decode(&property); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return 0, fmt.Errorf("propiedad con ID '%s' no encontrada", id)
+		}
+		return 0, fmt.Errorf("error incrementando vistas en MongoDB: %w", err)
+	}
+
+	return property.Views, nil
+}
+
+// GetTrending obtiene las propiedades disponibles más vistas entre las que tuvieron vistas desde since
+func (r *propertyRepository) GetTrending(ctx context.Context, since time.Time, limit int) ([]domain.Property, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"available":        true,
+		"lastViewedAt":     bson.M{"$gte": since},
+		"moderationStatus": bson.M{"$nin": unpublishedModerationStatuses},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "views", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error buscando propiedades en tendencia: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var properties []domain.Property
+	if err := cursor.All(ctx, &properties); err != nil {
+		return nil, fmt.Errorf("error decodificando propiedades: %w", err)
+	}
+
+	return properties, nil
+}
+
+// FindFlaggedDuplicates obtiene las propiedades marcadas como probable duplicado, de la más nueva a la más vieja
+func (r *propertyRepository) FindFlaggedDuplicates(ctx context.Context) ([]domain.Property, error) {
+	filter := bson.M{"duplicateOf": bson.M{"$exists": true, "$ne": ""}}
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error buscando propiedades duplicadas: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	properties := []domain.Property{}
+	if err := cursor.All(ctx, &properties); err != nil {
+		return nil, fmt.Errorf("error decodificando propiedades: %w", err)
+	}
+
+	return properties, nil
+}
+
+// ClearDuplicateFlag quita la marca de probable duplicado de una propiedad
+func (r *propertyRepository) ClearDuplicateFlag(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("ID inválido '%s': %w", id, err)
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{"$unset": bson.M{"duplicateOf": ""}})
+	if err != nil {
+		return fmt.Errorf("error quitando marca de duplicado en MongoDB: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("propiedad con ID '%s' no encontrada", id)
+	}
+
+	return nil
+}
+
+// SetPhotoVariants guarda el estado y las variantes de una foto de la galería
+// Actualiza solo esa foto (operador posicional), así no pisa cambios hechos en la galería mientras se procesaba
+// Retorna ErrPhotoNotInGallery si la propiedad ya no tiene la foto (ej: la eliminaron antes de procesarla)
+func (r *propertyRepository) SetPhotoVariants(ctx context.Context, id, photoURL, status string, variants []domain.PhotoVariant) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("ID inválido '%s': %w", id, err)
+	}
+
+	filter := bson.M{"_id": objectID, "images.url": photoURL}
+	update := bson.M{"$set": bson.M{
+		"images.$.status":   status,
+		"images.$.variants": variants,
+		"updatedAt":         utils.NowUTC(),
+	}}
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("error guardando variantes de la foto en MongoDB: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("%w: '%s' en la propiedad '%s'", ErrPhotoNotInGallery, photoURL, id)
+	}
+
+	return nil
+}
+
+// SetBookedRanges guarda las fechas ocupadas y el primer día libre de la propiedad
+// No actualiza updatedAt (igual que las vistas): la disponibilidad no es un cambio de la publicación
+// y llega a search-api con su propio evento
+func (r *propertyRepository) SetBookedRanges(ctx context.Context, id string, booked []domain.DateRange, nextAvailable string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("ID inválido '%s': %w", id, err)
+	}
+	if booked == nil {
+		booked = []domain.DateRange{}
+	}
+
+	update := bson.M{"$set": bson.M{
+		"bookedRanges":      booked,
+		"nextAvailableDate": nextAvailable,
+	}}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	if err != nil {
+		return fmt.Errorf("error guardando fechas ocupadas en MongoDB: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("propiedad con ID '%s' no encontrada", id)
+	}
+
+	return nil
+}
+
+// ListVersions obtiene una página de propiedades ordenadas por _id con solo el ID, el dueño y la fecha de actualización
+// afterID es el último ID de la página anterior (vacío para la primera); paginar por _id no se desfasa
+// si se crean o eliminan propiedades mientras se recorre la colección
+func (r *propertyRepository) ListVersions(ctx context.Context, afterID string, limit int) ([]domain.Property, error) {
+	// Las propiedades retenidas por moderación no se indexan
+	filter := bson.M{"moderationStatus": bson.M{"$nin": unpublishedModerationStatuses}}
+	if afterID != "" {
+		objectID, err := primitive.ObjectIDFromHex(afterID)
+		if err != nil {
+			return nil, fmt.Errorf("ID inválido '%s': %w", afterID, err)
+		}
+		filter["_id"] = bson.M{"$gt": objectID}
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{"_id": 1, "ownerId": 1, "updatedAt": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error listando versiones de propiedades: %w", err)
+	}
+	defer cursor.Close(ctx)
 
-	if err = cursor.All(context.Background(), &properties); err != nil {
+	properties := []domain.Property{}
+	if err := cursor.All(ctx, &properties); err != nil {
 		return nil, fmt.Errorf("error decodificando propiedades: %w", err)
 	}
 
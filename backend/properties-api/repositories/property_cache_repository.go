@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"properties-api/domain"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// propertyCacheKeyPrefix es el prefijo de las claves de Memcached con las propiedades cacheadas
+const propertyCacheKeyPrefix = "property:"
+
+// PropertyCacheRepository cachea en Memcached las propiedades leídas por ID (read-through)
+// Las entradas se invalidan al actualizar o eliminar la propiedad; el TTL acota lo que puede quedar
+// desactualizado si se pierde una invalidación (ej: Memcached caído en ese momento)
+// Memcached caído no es un error: Get responde como miss y la lectura va a MongoDB
+type PropertyCacheRepository interface {
+	// Get retorna la propiedad cacheada; false si no está
+	Get(id string) (domain.Property, bool)
+
+	// Set guarda la propiedad
+	Set(property domain.Property)
+
+	// Delete invalida la propiedad
+	Delete(id string)
+}
+
+// memcachedPropertyCache es la implementación de PropertyCacheRepository sobre Memcached
+type memcachedPropertyCache struct {
+	client *memcache.Client
+	ttl    time.Duration
+}
+
+// NewPropertyCacheRepository crea el caché de propiedades sobre los servidores de Memcached indicados
+func NewPropertyCacheRepository(ttl time.Duration, servers ...string) PropertyCacheRepository {
+	return &memcachedPropertyCache{
+		client: memcache.New(servers...),
+		ttl:    ttl,
+	}
+}
+
+// Get lee y decodifica la propiedad (en BSON, igual que en MongoDB, para no perder campos sin JSON)
+func (r *memcachedPropertyCache) Get(id string) (domain.Property, bool) {
+	item, err := r.client.Get(propertyCacheKeyPrefix + id)
+	if err != nil {
+		if !errors.Is(err, memcache.ErrCacheMiss) {
+			log.Printf("⚠️ Error leyendo la propiedad %s del caché: %v", id, err)
+		}
+		return domain.Property{}, false
+	}
+
+	var property domain.Property
+	if err := bson.Unmarshal(item.Value, &property); err != nil {
+		log.Printf("⚠️ Propiedad %s inválida en el caché, se descarta: %v", id, err)
+		r.Delete(id)
+		return domain.Property{}, false
+	}
+	return property, true
+}
+
+// Set guarda la propiedad con el TTL configurado
+func (r *memcachedPropertyCache) Set(property domain.Property) {
+	value, err := bson.Marshal(property)
+	if err != nil {
+		log.Printf("⚠️ Error serializando la propiedad %s para el caché: %v", property.ID.Hex(), err)
+		return
+	}
+	item := &memcache.Item{
+		Key:        propertyCacheKeyPrefix + property.ID.Hex(),
+		Value:      value,
+		Expiration: int32(r.ttl.Seconds()),
+	}
+	if err := r.client.Set(item); err != nil {
+		log.Printf("⚠️ Error guardando la propiedad %s en el caché: %v", property.ID.Hex(), err)
+	}
+}
+
+// Delete invalida la propiedad (que no esté cacheada no es un error)
+func (r *memcachedPropertyCache) Delete(id string) {
+	if err := r.client.Delete(propertyCacheKeyPrefix + id); err != nil && !errors.Is(err, memcache.ErrCacheMiss) {
+		log.Printf("⚠️ Error invalidando la propiedad %s en el caché: %v", id, err)
+	}
+}
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bradfitz/gomemcache/memcache"
+)
+
+// viewCounterKeyPrefix es el prefijo de las claves de Memcached con las vistas pendientes
+const viewCounterKeyPrefix = "property_views:"
+
+// ViewCounterRepository acumula en Memcached las vistas de cada propiedad hasta que se vuelcan a MongoDB
+// Así cada vista es un incremento atómico en memoria y no una escritura en la base
+type ViewCounterRepository interface {
+	// Increment suma delta vistas pendientes a la propiedad
+	Increment(propertyID string, delta uint64) error
+
+	// Take retorna las vistas pendientes de la propiedad y las descuenta del contador
+	Take(propertyID string) (int64, error)
+
+	// Ping verifica que Memcached responda (usado por el health check)
+	Ping() error
+}
+
+// memcachedViewCounter es la implementación de ViewCounterRepository sobre Memcached
+type memcachedViewCounter struct {
+	client *memcache.Client
+}
+
+// NewViewCounterRepository crea un contador de vistas sobre los servidores de Memcached indicados
+func NewViewCounterRepository(servers ...string) ViewCounterRepository {
+	return &memcachedViewCounter{
+		client: memcache.New(servers...),
+	}
+}
+
+// Increment suma delta vistas pendientes a la propiedad
+// Memcached no crea la clave al incrementar, por eso la primera vista la agrega con Add
+func (r *memcachedViewCounter) Increment(propertyID string, delta uint64) error {
+	key := viewCounterKeyPrefix + propertyID
+
+	_, err := r.client.Increment(key, delta)
+	if !errors.Is(err, memcache.ErrCacheMiss) {
+		return err
+	}
+
+	err = r.client.Add(&memcache.Item{Key: key, Value: []byte(strconv.FormatUint(delta, 10))})
+	if errors.Is(err, memcache.ErrNotStored) {
+		// Otra instancia creó la clave entre el Increment y el Add
+		_, err = r.client.Increment(key, delta)
+	}
+	return err
+}
+
+// Ping verifica que todos los servidores de Memcached respondan
+func (r *memcachedViewCounter) Ping() error {
+	return r.client.Ping()
+}
+
+// Take retorna las vistas pendientes de la propiedad y las descuenta del contador
+// Se descuenta lo leído (en lugar de borrar la clave) para no perder vistas concurrentes
+func (r *memcachedViewCounter) Take(propertyID string) (int64, error) {
+	key := viewCounterKeyPrefix + propertyID
+
+	item, err := r.client.Get(key)
+	if errors.Is(err, memcache.ErrCacheMiss) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// Memcached no achica el valor al decrementar: "10" menos 5 queda "5 " (con espacios al final)
+	pending, err := strconv.ParseUint(strings.TrimSpace(string(item.Value)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("contador de vistas inválido para '%s': %w", propertyID, err)
+	}
+	if pending == 0 {
+		return 0, nil
+	}
+
+	if _, err := r.client.Decrement(key, pending); err != nil && !errors.Is(err, memcache.ErrCacheMiss) {
+		return 0, err
+	}
+	return int64(pending), nil
+}
@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeMemcached implementa el subconjunto del protocolo de texto que usa el contador de vistas
+// Como Memcached, decr reescribe el número sobre el valor anterior sin achicarlo: "10" menos 5 queda "5 "
+type fakeMemcached struct {
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+func startFakeMemcached(t *testing.T) (*fakeMemcached, string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	server := &fakeMemcached{values: make(map[string][]byte)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server, listener.Addr().String()
+}
+
+func (s *fakeMemcached) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		s.mu.Lock()
+		switch fields[0] {
+		case "gets":
+			for _, key := range fields[1:] {
+				if value, ok := s.values[key]; ok {
+					fmt.Fprintf(conn, "VALUE %s 0 %d 1\r\n%s\r\n", key, len(value), value)
+				}
+			}
+			fmt.Fprint(conn, "END\r\n")
+		case "add":
+			size, _ := strconv.Atoi(fields[4])
+			data := make([]byte, size+2)
+			if _, err := io.ReadFull(reader, data); err != nil {
+				s.mu.Unlock()
+				return
+			}
+			if _, ok := s.values[fields[1]]; ok {
+				fmt.Fprint(conn, "NOT_STORED\r\n")
+			} else {
+				s.values[fields[1]] = data[:size]
+				fmt.Fprint(conn, "STORED\r\n")
+			}
+		case "incr", "decr":
+			value, ok := s.values[fields[1]]
+			if !ok {
+				fmt.Fprint(conn, "NOT_FOUND\r\n")
+				break
+			}
+			current, _ := strconv.ParseUint(strings.TrimSpace(string(value)), 10, 64)
+			delta, _ := strconv.ParseUint(fields[2], 10, 64)
+			if fields[0] == "incr" {
+				current += delta
+			} else if delta > current {
+				current = 0
+			} else {
+				current -= delta
+			}
+			updated := strconv.FormatUint(current, 10)
+			if len(updated) < len(value) {
+				updated += strings.Repeat(" ", len(value)-len(updated))
+			}
+			s.values[fields[1]] = []byte(updated)
+			fmt.Fprintf(conn, "%d\r\n", current)
+		default:
+			fmt.Fprint(conn, "ERROR\r\n")
+		}
+		s.mu.Unlock()
+	}
+}
+
+func TestViewCounter_TakeAfterDecrementPaddedValue(t *testing.T) {
+	server, addr := startFakeMemcached(t)
+	counter := NewViewCounterRepository(addr)
+
+	if err := counter.Increment("p1", 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if taken, err := counter.Take("p1"); err != nil || taken != 10 {
+		t.Fatalf("expected 10 views, got %d (%v)", taken, err)
+	}
+	if err := counter.Increment("p1", 3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server.mu.Lock()
+	value := string(server.values[viewCounterKeyPrefix+"p1"])
+	server.mu.Unlock()
+	if value != "3 " {
+		t.Fatalf("expected the fake to keep memcached's padding, got %q", value)
+	}
+
+	taken, err := counter.Take("p1")
+	if err != nil || taken != 3 {
+		t.Fatalf("expected 3 views from a padded value, got %d (%v)", taken, err)
+	}
+	if taken, err := counter.Take("p1"); err != nil || taken != 0 {
+		t.Fatalf("expected no pending views, got %d (%v)", taken, err)
+	}
+}
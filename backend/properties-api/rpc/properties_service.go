@@ -0,0 +1,144 @@
+package rpc
+
+import (
+	"context"
+
+	"properties-api/dto"
+
+	"google.golang.org/grpc"
+)
+
+// PropertiesServiceName es el nombre del servicio gRPC de propiedades (lo usa search-api al indexar)
+const PropertiesServiceName = "properties.v1.Properties"
+
+// APIKeyScopePropertiesRead es el scope que necesita la API key de un servicio para llamar a este servidor
+const APIKeyScopePropertiesRead = "properties:read"
+
+// GetPropertyRequest pide una propiedad por ID
+type GetPropertyRequest struct {
+	ID string `json:"id"`
+}
+
+// GetPropertyResponse contiene la propiedad con el mismo formato que GET /properties/:id
+type GetPropertyResponse struct {
+	Property dto.PropertyResponseDTO `json:"property"`
+}
+
+// GetPropertiesRequest pide un lote de propiedades por ID (lo usan los dataloaders de graphql-api)
+type GetPropertiesRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// GetPropertiesResponse contiene las propiedades encontradas; los IDs inexistentes se omiten
+type GetPropertiesResponse struct {
+	Properties []dto.PropertyResponseDTO `json:"properties"`
+}
+
+// GetAvailabilityRequest pide los rangos ocupados de una propiedad
+type GetAvailabilityRequest struct {
+	PropertyID string `json:"propertyId"`
+}
+
+// GetAvailabilityResponse contiene los rangos ocupados (reservas activas y bloqueos) ordenados por fecha
+type GetAvailabilityResponse struct {
+	Ranges []dto.AvailabilityRangeDTO `json:"ranges"`
+}
+
+// ListPropertyVersionsRequest pide una página de versiones de propiedades ordenada por ID
+type ListPropertyVersionsRequest struct {
+	// AfterID es el NextAfterID de la página anterior (vacío para la primera)
+	AfterID string `json:"afterId"`
+	Limit   int    `json:"limit"`
+}
+
+// ListPropertyVersionsResponse contiene la página de versiones; NextAfterID vacío indica que no hay más
+type ListPropertyVersionsResponse struct {
+	Versions    []dto.PropertyVersionDTO `json:"versions"`
+	NextAfterID string                   `json:"nextAfterId"`
+}
+
+// PropertiesServer es la interfaz que implementa el servidor gRPC de propiedades
+type PropertiesServer interface {
+	// GetProperty obtiene una propiedad; retorna codes.NotFound si no existe
+	GetProperty(ctx context.Context, request *GetPropertyRequest) (*GetPropertyResponse, error)
+	// GetProperties obtiene un lote de propiedades en una sola llamada
+	GetProperties(ctx context.Context, request *GetPropertiesRequest) (*GetPropertiesResponse, error)
+	// GetAvailability obtiene los rangos ocupados de una propiedad; retorna codes.NotFound si no existe
+	GetAvailability(ctx context.Context, request *GetAvailabilityRequest) (*GetAvailabilityResponse, error)
+	// ListPropertyVersions recorre todas las propiedades por páginas (lo usa la reconciliación de search-api)
+	ListPropertyVersions(ctx context.Context, request *ListPropertyVersionsRequest) (*ListPropertyVersionsResponse, error)
+}
+
+// RegisterPropertiesServer registra la implementación del servicio de propiedades en el servidor gRPC
+func RegisterPropertiesServer(server *grpc.Server, impl PropertiesServer) {
+	server.RegisterService(&propertiesServiceDesc, impl)
+}
+
+// propertiesServiceDesc describe los métodos del servicio (equivalente a lo que generaría protoc)
+var propertiesServiceDesc = grpc.ServiceDesc{
+	ServiceName: PropertiesServiceName,
+	HandlerType: (*PropertiesServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "GetProperty", Handler: getPropertyHandler},
+		{MethodName: "GetProperties", Handler: getPropertiesHandler},
+		{MethodName: "GetAvailability", Handler: getAvailabilityHandler},
+		{MethodName: "ListPropertyVersions", Handler: listPropertyVersionsHandler},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+func getPropertyHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	request := new(GetPropertyRequest)
+	if err := dec(request); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PropertiesServer).GetProperty(ctx, request)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + PropertiesServiceName + "/GetProperty"}
+	return interceptor(ctx, request, info, func(ctx context.Context, req any) (any, error) {
+		return srv.(PropertiesServer).GetProperty(ctx, req.(*GetPropertyRequest))
+	})
+}
+
+func getPropertiesHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	request := new(GetPropertiesRequest)
+	if err := dec(request); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PropertiesServer).GetProperties(ctx, request)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + PropertiesServiceName + "/GetProperties"}
+	return interceptor(ctx, request, info, func(ctx context.Context, req any) (any, error) {
+		return srv.(PropertiesServer).GetProperties(ctx, req.(*GetPropertiesRequest))
+	})
+}
+
+func getAvailabilityHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	request := new(GetAvailabilityRequest)
+	if err := dec(request); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PropertiesServer).GetAvailability(ctx, request)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + PropertiesServiceName + "/GetAvailability"}
+	return interceptor(ctx, request, info, func(ctx context.Context, req any) (any, error) {
+		return srv.(PropertiesServer).GetAvailability(ctx, req.(*GetAvailabilityRequest))
+	})
+}
+
+func listPropertyVersionsHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	request := new(ListPropertyVersionsRequest)
+	if err := dec(request); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PropertiesServer).ListPropertyVersions(ctx, request)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + PropertiesServiceName + "/ListPropertyVersions"}
+	return interceptor(ctx, request, info, func(ctx context.Context, req any) (any, error) {
+		return srv.(PropertiesServer).ListPropertyVersions(ctx, req.(*ListPropertyVersionsRequest))
+	})
+}
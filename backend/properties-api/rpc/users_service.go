@@ -0,0 +1,168 @@
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// UsersServiceName es el nombre del servicio gRPC que expone users-api
+const UsersServiceName = "users.v1.Users"
+
+// ValidateUserRequest pide validar la existencia de un usuario
+type ValidateUserRequest struct {
+	UserID uint `json:"userId"`
+}
+
+// ValidateUserResponse indica si el usuario existe y si su cuenta está activa
+type ValidateUserResponse struct {
+	Exists bool `json:"exists"`
+	Active bool `json:"active"`
+}
+
+// GetUserRequest pide los datos públicos de un usuario
+type GetUserRequest struct {
+	UserID uint `json:"userId"`
+}
+
+// GetUserResponse contiene el usuario con el mismo formato que GET /users/:id de users-api
+type GetUserResponse struct {
+	User User `json:"user"`
+}
+
+// User son los datos públicos de un usuario de users-api
+type User struct {
+	ID        uint   `json:"id"`
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+	UserType  string `json:"userType"`
+	Active    bool   `json:"active"`
+}
+
+// GetHostProfileRequest pide el perfil público de un anfitrión
+type GetHostProfileRequest struct {
+	UserID uint `json:"userId"`
+}
+
+// GetHostProfileResponse contiene el perfil con el mismo formato que GET /users/:id/host-profile de users-api
+type GetHostProfileResponse struct {
+	Profile HostProfile `json:"profile"`
+}
+
+// HostProfile es el perfil público de un anfitrión de users-api
+type HostProfile struct {
+	ID          uint      `json:"id"`
+	DisplayName string    `json:"displayName"`
+	AvatarURL   string    `json:"avatarUrl"`
+	Bio         string    `json:"bio"`
+	Languages   []string  `json:"languages"`
+	City        string    `json:"city"`
+	HasPhone    bool      `json:"hasPhone"`
+	MemberSince time.Time `json:"memberSince"`
+}
+
+// VerifyAPIKeyRequest pide a users-api validar la API key con la que llamó un servicio interno
+type VerifyAPIKeyRequest struct {
+	Key string `json:"key"`
+}
+
+// VerifyAPIKeyResponse indica si la key es válida, de qué servicio es y qué scopes tiene
+type VerifyAPIKeyResponse struct {
+	Valid  bool     `json:"valid"`
+	Name   string   `json:"name,omitempty"`
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// VerifyTokenRequest pide a users-api validar el JWT de un usuario, incluida la lista de revocación
+type VerifyTokenRequest struct {
+	Token string `json:"token"`
+}
+
+// VerifyTokenResponse indica si el token es válido y no fue revocado y de qué usuario es
+type VerifyTokenResponse struct {
+	Valid    bool   `json:"valid"`
+	UserID   uint   `json:"userId,omitempty"`
+	Username string `json:"username,omitempty"`
+	UserType string `json:"userType,omitempty"`
+}
+
+// CountSignupsRequest pide la cantidad de registros por día UTC en [From, To)
+type CountSignupsRequest struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// CountSignupsResponse tiene los registros por día (YYYY-MM-DD); los días sin registros se omiten
+type CountSignupsResponse struct {
+	Days map[string]int64 `json:"days"`
+}
+
+// UsersServiceClient es el stub del servicio gRPC de usuarios
+type UsersServiceClient interface {
+	ValidateUser(ctx context.Context, request *ValidateUserRequest, opts ...grpc.CallOption) (*ValidateUserResponse, error)
+	GetUser(ctx context.Context, request *GetUserRequest, opts ...grpc.CallOption) (*GetUserResponse, error)
+	GetHostProfile(ctx context.Context, request *GetHostProfileRequest, opts ...grpc.CallOption) (*GetHostProfileResponse, error)
+	VerifyAPIKey(ctx context.Context, request *VerifyAPIKeyRequest, opts ...grpc.CallOption) (*VerifyAPIKeyResponse, error)
+	VerifyToken(ctx context.Context, request *VerifyTokenRequest, opts ...grpc.CallOption) (*VerifyTokenResponse, error)
+	CountSignups(ctx context.Context, request *CountSignupsRequest, opts ...grpc.CallOption) (*CountSignupsResponse, error)
+}
+
+type usersServiceClient struct {
+	conn grpc.ClientConnInterface
+}
+
+// NewUsersServiceClient crea el stub sobre una conexión gRPC (ver clients.NewGRPCConn)
+func NewUsersServiceClient(conn grpc.ClientConnInterface) UsersServiceClient {
+	return &usersServiceClient{conn: conn}
+}
+
+func (c *usersServiceClient) ValidateUser(ctx context.Context, request *ValidateUserRequest, opts ...grpc.CallOption) (*ValidateUserResponse, error) {
+	response := new(ValidateUserResponse)
+	if err := c.conn.Invoke(ctx, "/"+UsersServiceName+"/ValidateUser", request, response, opts...); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *usersServiceClient) GetUser(ctx context.Context, request *GetUserRequest, opts ...grpc.CallOption) (*GetUserResponse, error) {
+	response := new(GetUserResponse)
+	if err := c.conn.Invoke(ctx, "/"+UsersServiceName+"/GetUser", request, response, opts...); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *usersServiceClient) GetHostProfile(ctx context.Context, request *GetHostProfileRequest, opts ...grpc.CallOption) (*GetHostProfileResponse, error) {
+	response := new(GetHostProfileResponse)
+	if err := c.conn.Invoke(ctx, "/"+UsersServiceName+"/GetHostProfile", request, response, opts...); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *usersServiceClient) VerifyAPIKey(ctx context.Context, request *VerifyAPIKeyRequest, opts ...grpc.CallOption) (*VerifyAPIKeyResponse, error) {
+	response := new(VerifyAPIKeyResponse)
+	if err := c.conn.Invoke(ctx, "/"+UsersServiceName+"/VerifyAPIKey", request, response, opts...); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *usersServiceClient) VerifyToken(ctx context.Context, request *VerifyTokenRequest, opts ...grpc.CallOption) (*VerifyTokenResponse, error) {
+	response := new(VerifyTokenResponse)
+	if err := c.conn.Invoke(ctx, "/"+UsersServiceName+"/VerifyToken", request, response, opts...); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *usersServiceClient) CountSignups(ctx context.Context, request *CountSignupsRequest, opts ...grpc.CallOption) (*CountSignupsResponse, error) {
+	response := new(CountSignupsResponse)
+	if err := c.conn.Invoke(ctx, "/"+UsersServiceName+"/CountSignups", request, response, opts...); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
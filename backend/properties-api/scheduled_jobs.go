@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"jobs"
+	"properties-api/config"
+	"properties-api/services"
+)
+
+// scheduledJobs arma los procesos periódicos de properties-api
+// Las vistas se acumulan por réplica y se vuelcan en todas; el resto es exclusivo (una réplica por período)
+func scheduledJobs(cfg *config.Config, views services.ViewService, calendar services.CalendarService, bookings services.BookingService, analytics services.AnalyticsService) []jobs.Job {
+	// La primera agregación completa los últimos ANALYTICS_BACKFILL_DAYS; las siguientes recalculan los recientes
+	// (el runner no superpone ejecuciones del mismo job, así que days no se lee y escribe a la vez)
+	analyticsDays := cfg.Analytics.BackfillDays
+
+	return []jobs.Job{
+		{
+			// Volcar a MongoDB las vistas acumuladas en Memcached
+			Name:     "views-flush",
+			Schedule: jobs.Every(cfg.Views.FlushInterval),
+			Timeout:  cfg.Jobs.Timeout,
+			Run: func(ctx context.Context) error {
+				flushed, err := views.Flush(ctx)
+				if flushed > 0 {
+					log.Printf("👀 Vistas volcadas a MongoDB para %d propiedades", flushed)
+				}
+				return err
+			},
+		},
+		{
+			// Sincronizar los calendarios iCal externos (Airbnb, Booking...)
+			Name:      "calendar-sync",
+			Schedule:  jobs.Every(cfg.Calendar.SyncInterval),
+			Timeout:   cfg.Jobs.Timeout,
+			Exclusive: true,
+			Run: func(ctx context.Context) error {
+				synced, err := calendar.SyncAll(ctx)
+				if synced > 0 {
+					log.Printf("📅 Calendarios externos sincronizados: %d", synced)
+				}
+				return err
+			},
+		},
+		{
+			// Vencer las solicitudes de reserva que el anfitrión no respondió
+			Name:      "booking-requests-expiry",
+			Schedule:  jobs.Every(cfg.Bookings.ExpirySweepInterval),
+			Timeout:   cfg.Jobs.Timeout,
+			Exclusive: true,
+			Run: func(ctx context.Context) error {
+				expired, err := bookings.ExpirePendingRequests(ctx)
+				if expired > 0 {
+					log.Printf("⏰ Solicitudes de reserva vencidas: %d", expired)
+				}
+				return err
+			},
+		},
+		{
+			// Liberar las fechas de los holds de checkout vencidos
+			// Un hold vencido ya no se puede usar para reservar; el sweeper solo libera sus noches para otros huéspedes
+			Name:      "booking-holds-sweep",
+			Schedule:  jobs.Every(cfg.Bookings.HoldSweepInterval),
+			Timeout:   cfg.Jobs.Timeout,
+			Exclusive: true,
+			Run: func(ctx context.Context) error {
+				released, err := bookings.ExpireHolds(ctx)
+				if released > 0 {
+					log.Printf("⏰ Holds de reserva vencidos liberados: %d", released)
+				}
+				return err
+			},
+		},
+		{
+			// Agregar los KPIs diarios del panel de admin
+			Name:       "analytics-aggregation",
+			Schedule:   jobs.Every(cfg.Analytics.Interval),
+			Timeout:    cfg.Jobs.Timeout,
+			RunOnStart: true,
+			Exclusive:  true,
+			Run: func(ctx context.Context) error {
+				if err := analytics.Aggregate(ctx, analyticsDays); err != nil {
+					return err
+				}
+				log.Printf("📊 KPIs diarios agregados (últimos %d días)", analyticsDays)
+				analyticsDays = cfg.Analytics.RecomputeDays
+				return nil
+			},
+		},
+	}
+}
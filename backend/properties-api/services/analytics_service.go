@@ -0,0 +1,273 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"properties-api/clients"
+	"properties-api/domain"
+	"properties-api/dto"
+	"properties-api/repositories"
+	"properties-api/utils"
+)
+
+// maxSearchActivityDays es la ventana más larga que acepta GET /search/analytics/daily de search-api
+const maxSearchActivityDays = 90
+
+// Pasos del embudo de conversión del panel de admin
+const (
+	FunnelStepSearches          = "searches"
+	FunnelStepClickedSearches   = "clickedSearches"
+	FunnelStepBookingsCreated   = "bookingsCreated"
+	FunnelStepBookingsConfirmed = "bookingsConfirmed"
+)
+
+// AnalyticsService agrega los KPIs diarios del panel de admin y los sirve ya calculados
+// Usuarios (users-api), búsquedas (search-api), propiedades y reservas se cuentan en un proceso
+// periódico y se guardan por día, así el panel no consulta a los otros servicios en cada request
+type AnalyticsService interface {
+	// Aggregate recalcula los KPIs de los últimos days días (incluye hoy)
+	Aggregate(ctx context.Context, days int) error
+	// GetDashboard retorna los KPIs, los totales y el embudo de los últimos days días
+	GetDashboard(ctx context.Context, days int) (dto.AdminAnalyticsResponse, error)
+}
+
+// analyticsService es la implementación concreta de AnalyticsService
+type analyticsService struct {
+	repo     repositories.AnalyticsRepository
+	signups  clients.SignupsClient
+	searches clients.SearchAnalyticsClient
+}
+
+// NewAnalyticsService crea una nueva instancia del servicio de analytics
+// searches nil = search-api no está configurado y las búsquedas quedan incompletas
+func NewAnalyticsService(repo repositories.AnalyticsRepository, signups clients.SignupsClient, searches clients.SearchAnalyticsClient) AnalyticsService {
+	return &analyticsService{
+		repo:     repo,
+		signups:  signups,
+		searches: searches,
+	}
+}
+
+// Aggregate cuenta cada fuente sobre los días y reemplaza los documentos diarios
+// Propiedades y reservas son de la base propia: si fallan no se guarda nada
+// Si users-api o search-api fallan se conservan los valores anteriores y el día queda marcado incompleto
+func (s *analyticsService) Aggregate(ctx context.Context, days int) error {
+	if days < 1 {
+		return nil
+	}
+	now := utils.NowUTC()
+	dates := kpiDates(now, days)
+	from, _ := time.Parse("2006-01-02", dates[0])
+	to := from.AddDate(0, 0, days)
+
+	stored, err := s.repo.ListDays(ctx, dates[0], dates[len(dates)-1])
+	if err != nil {
+		return err
+	}
+	previous := make(map[string]domain.DailyKPIs, len(stored))
+	for _, day := range stored {
+		previous[day.Date] = day
+	}
+
+	listings, err := s.repo.CountListingsByDay(ctx, from, to)
+	if err != nil {
+		return err
+	}
+	bookings, err := s.repo.CountBookingsByDay(ctx, from, to)
+	if err != nil {
+		return err
+	}
+
+	signups, signupsErr := s.signups.CountSignups(ctx, from, to)
+	if signupsErr != nil {
+		log.Printf("⚠️ No se pudieron contar los registros en users-api: %v", signupsErr)
+	}
+	activity, searchesErr := s.searchActivity(ctx, days)
+	if searchesErr != nil {
+		log.Printf("⚠️ No se pudo obtener la actividad de búsqueda de search-api: %v", searchesErr)
+	}
+
+	searchDays := make(map[string]clients.SearchDayActivity, len(activity.Days))
+	for _, day := range activity.Days {
+		searchDays[day.Date] = day
+	}
+
+	result := make([]domain.DailyKPIs, 0, len(dates))
+	for _, date := range dates {
+		prev, hasPrev := previous[date]
+		day := domain.DailyKPIs{
+			Date:              date,
+			ListingsCreated:   listings[date],
+			BookingsCreated:   bookings[date].Created,
+			BookingsConfirmed: bookings[date].Confirmed,
+			ComputedAt:        now,
+		}
+
+		dayStart, _ := time.Parse("2006-01-02", date)
+		dayEnd := dayStart.AddDate(0, 0, 1)
+
+		if signupsErr == nil {
+			day.NewUsers = signups[date]
+		} else {
+			day.NewUsers = prev.NewUsers
+			if !settled(prev, hasPrev, domain.KPISourceUsers, dayEnd) {
+				day.Incomplete = append(day.Incomplete, domain.KPISourceUsers)
+			}
+		}
+
+		searchDay, counted := searchDays[date]
+		if searchesErr == nil && counted && !dayStart.Before(activity.CompleteSince) {
+			day.Searches = searchDay.Searches
+			day.ZeroResultSearches = searchDay.ZeroResultSearches
+			day.ClickedSearches = searchDay.ClickedSearches
+			day.Clicks = searchDay.Clicks
+		} else {
+			mergePartialSearches(&day, prev, settled(prev, hasPrev, domain.KPISourceSearches, dayEnd), searchDay)
+		}
+
+		result = append(result, day)
+	}
+
+	return s.repo.SaveDays(ctx, result)
+}
+
+// searchActivity obtiene de search-api la actividad de los días que conserva
+func (s *analyticsService) searchActivity(ctx context.Context, days int) (clients.SearchDailyActivity, error) {
+	if s.searches == nil {
+		return clients.SearchDailyActivity{}, fmt.Errorf("SEARCH_API_URL no está configurada")
+	}
+	if days > maxSearchActivityDays {
+		days = maxSearchActivityDays
+	}
+	return s.searches.DailyActivity(ctx, days)
+}
+
+// settled indica si el valor guardado de la fuente es definitivo: se contó completo después de que terminó el día
+func settled(prev domain.DailyKPIs, hasPrev bool, source string, dayEnd time.Time) bool {
+	return hasPrev && !prev.IsIncomplete(source) && !prev.ComputedAt.Before(dayEnd)
+}
+
+// mergePartialSearches completa las búsquedas de un día que search-api no tiene completo
+// (réplica reiniciada, buffer lleno o search-api caído): un valor anterior definitivo se conserva,
+// y si no lo hay se toma el mayor entre el anterior y el actual y el día queda incompleto
+func mergePartialSearches(day *domain.DailyKPIs, prev domain.DailyKPIs, prevSettled bool, current clients.SearchDayActivity) {
+	day.Searches = prev.Searches
+	day.ZeroResultSearches = prev.ZeroResultSearches
+	day.ClickedSearches = prev.ClickedSearches
+	day.Clicks = prev.Clicks
+	if prevSettled {
+		return
+	}
+
+	if current.Searches > day.Searches {
+		day.Searches = current.Searches
+		day.ZeroResultSearches = current.ZeroResultSearches
+		day.ClickedSearches = current.ClickedSearches
+	}
+	if current.Clicks > day.Clicks {
+		day.Clicks = current.Clicks
+	}
+	day.Incomplete = append(day.Incomplete, domain.KPISourceSearches)
+}
+
+// GetDashboard lee los KPIs guardados; los días que la agregación todavía no calculó van en cero
+// y marcados como notComputed
+func (s *analyticsService) GetDashboard(ctx context.Context, days int) (dto.AdminAnalyticsResponse, error) {
+	dates := kpiDates(utils.NowUTC(), days)
+	stored, err := s.repo.ListDays(ctx, dates[0], dates[len(dates)-1])
+	if err != nil {
+		return dto.AdminAnalyticsResponse{}, err
+	}
+	byDate := make(map[string]domain.DailyKPIs, len(stored))
+	for _, day := range stored {
+		byDate[day.Date] = day
+	}
+
+	response := dto.AdminAnalyticsResponse{
+		From: dates[0],
+		To:   dates[len(dates)-1],
+		Days: make([]dto.DailyKPIsDTO, 0, len(dates)),
+	}
+	for _, date := range dates {
+		day, ok := byDate[date]
+		if !ok {
+			day = domain.DailyKPIs{Date: date, Incomplete: []string{domain.KPINotComputed}}
+		}
+		if ok && (response.LastComputedAt == nil || day.ComputedAt.After(*response.LastComputedAt)) {
+			computedAt := day.ComputedAt
+			response.LastComputedAt = &computedAt
+		}
+
+		counts := dto.KPICounts{
+			NewUsers:           day.NewUsers,
+			ListingsCreated:    day.ListingsCreated,
+			Searches:           day.Searches,
+			ZeroResultSearches: day.ZeroResultSearches,
+			ClickedSearches:    day.ClickedSearches,
+			Clicks:             day.Clicks,
+			BookingsCreated:    day.BookingsCreated,
+			BookingsConfirmed:  day.BookingsConfirmed,
+		}
+		response.Days = append(response.Days, dto.DailyKPIsDTO{Date: date, KPICounts: counts, Incomplete: day.Incomplete})
+		response.Totals = addKPICounts(response.Totals, counts)
+		if len(day.Incomplete) > 0 {
+			response.Incomplete = true
+		}
+	}
+
+	response.Funnel = conversionFunnel(response.Totals)
+	return response, nil
+}
+
+// kpiDates retorna los últimos days días UTC hasta hoy inclusive, del más antiguo al más reciente
+func kpiDates(now time.Time, days int) []string {
+	if days < 1 {
+		days = 1
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	dates := make([]string, 0, days)
+	for i := days - 1; i >= 0; i-- {
+		dates = append(dates, today.AddDate(0, 0, -i).Format("2006-01-02"))
+	}
+	return dates
+}
+
+// addKPICounts suma dos juegos de contadores
+func addKPICounts(a, b dto.KPICounts) dto.KPICounts {
+	return dto.KPICounts{
+		NewUsers:           a.NewUsers + b.NewUsers,
+		ListingsCreated:    a.ListingsCreated + b.ListingsCreated,
+		Searches:           a.Searches + b.Searches,
+		ZeroResultSearches: a.ZeroResultSearches + b.ZeroResultSearches,
+		ClickedSearches:    a.ClickedSearches + b.ClickedSearches,
+		Clicks:             a.Clicks + b.Clicks,
+		BookingsCreated:    a.BookingsCreated + b.BookingsCreated,
+		BookingsConfirmed:  a.BookingsConfirmed + b.BookingsConfirmed,
+	}
+}
+
+// conversionFunnel arma el embudo búsquedas → búsquedas con click → reservas → reservas confirmadas
+// Es un embudo agregado del período: las reservas no se vinculan con la búsqueda que las originó
+func conversionFunnel(totals dto.KPICounts) []dto.FunnelStepDTO {
+	steps := []dto.FunnelStepDTO{
+		{Step: FunnelStepSearches, Count: totals.Searches},
+		{Step: FunnelStepClickedSearches, Count: totals.ClickedSearches},
+		{Step: FunnelStepBookingsCreated, Count: totals.BookingsCreated},
+		{Step: FunnelStepBookingsConfirmed, Count: totals.BookingsConfirmed},
+	}
+	for i := range steps {
+		if i > 0 && steps[i-1].Count > 0 {
+			steps[i].ConversionFromPrevious = roundTo(float64(steps[i].Count)/float64(steps[i-1].Count), 4)
+		}
+		if i == 0 && steps[i].Count > 0 {
+			steps[i].ConversionFromPrevious = 1
+		}
+		if steps[0].Count > 0 {
+			steps[i].ConversionFromStart = roundTo(float64(steps[i].Count)/float64(steps[0].Count), 4)
+		}
+	}
+	return steps
+}
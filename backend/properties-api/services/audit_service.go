@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"reflect"
+	"time"
+
+	"properties-api/domain"
+	"properties-api/dto"
+	"properties-api/repositories"
+	"properties-api/utils"
+)
+
+// AuditSystemActor es el actor de las operaciones que no hace un usuario (ej: consumers)
+const AuditSystemActor = "system"
+
+// Acciones registradas en el log de auditoría
+const (
+	AuditActionPropertyCreate = "property.create"
+	AuditActionPropertyUpdate = "property.update"
+	AuditActionPropertyDelete = "property.delete"
+	AuditActionUserDataErase  = "user_data.erase"
+	// Revisión de duplicados (solo admin)
+	AuditActionPropertyMerge            = "property.merge"
+	AuditActionPropertyDuplicateDismiss = "property.duplicate_dismiss"
+	// Cupones promocionales (solo admin)
+	AuditActionCouponCreate     = "coupon.create"
+	AuditActionCouponDeactivate = "coupon.deactivate"
+	// Revisión de contenido retenido por moderación (solo admin)
+	AuditActionModerationApprove = "moderation.approve"
+	AuditActionModerationReject  = "moderation.reject"
+	// Republicación de eventos de propiedades (solo admin)
+	AuditActionEventReplay = "events.replay"
+)
+
+// Tipos de entidad de los registros de auditoría
+const (
+	auditEntityProperty = "property"
+	auditEntityUser     = "user"
+	auditEntityCoupon   = "coupon"
+
+	auditEntityModerationCase = "moderation_case"
+	auditEntityPropertyEvents = "property_events"
+)
+
+// defaultAuditPageSize es la cantidad de registros por página si no se indica limit
+const defaultAuditPageSize = 50
+
+// auditIgnoredFields son campos que cambian sin que sea una modificación del usuario
+var auditIgnoredFields = map[string]bool{
+	"updatedAt": true,
+	"views":     true,
+}
+
+// ErrInvalidDateRange se retorna cuando from/to no son fechas válidas o from no es anterior a to
+var ErrInvalidDateRange = errors.New("rango de fechas inválido, usar YYYY-MM-DD o RFC3339 con from anterior a to")
+
+// AuditService registra y consulta el log de auditoría de las operaciones que modifican datos
+type AuditService interface {
+	// Record guarda el diff entre before y after de una entidad
+	// before es nil en una creación y after es nil en una eliminación
+	// Un error al guardar solo se loguea: nunca hace fallar la operación auditada
+	Record(ctx context.Context, actorID, action, entityType, entityID string, before, after interface{})
+
+	// List consulta el log filtrando por usuario y rango de fechas (solo admin)
+	List(ctx context.Context, query dto.AuditQuery) (dto.AuditListResponseDTO, error)
+}
+
+// auditService es la implementación concreta de AuditService
+type auditService struct {
+	repo repositories.AuditRepository
+}
+
+// NewAuditService crea una nueva instancia del servicio de auditoría
+func NewAuditService(repo repositories.AuditRepository) AuditService {
+	return &auditService{repo: repo}
+}
+
+// Record guarda el diff entre before y after de una entidad
+func (s *auditService) Record(ctx context.Context, actorID, action, entityType, entityID string, before, after interface{}) {
+	entry := domain.AuditEntry{
+		ActorID:    actorID,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Changes:    diffAuditStates(before, after),
+		CreatedAt:  utils.NowUTC(),
+	}
+	if err := s.repo.Create(ctx, &entry); err != nil {
+		log.Printf("⚠️ Error guardando auditoría de %s %s/%s: %v", action, entityType, entityID, err)
+	}
+}
+
+// List consulta el log filtrando por usuario y rango de fechas
+func (s *auditService) List(ctx context.Context, query dto.AuditQuery) (dto.AuditListResponseDTO, error) {
+	from, err := parseAuditTime(query.From)
+	if err != nil {
+		return dto.AuditListResponseDTO{}, ErrInvalidDateRange
+	}
+	to, err := parseAuditTime(query.To)
+	if err != nil {
+		return dto.AuditListResponseDTO{}, ErrInvalidDateRange
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return dto.AuditListResponseDTO{}, ErrInvalidDateRange
+	}
+
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.Limit < 1 {
+		query.Limit = defaultAuditPageSize
+	}
+
+	entries, total, err := s.repo.Find(ctx, repositories.AuditFilter{
+		ActorID: query.UserID,
+		From:    from,
+		To:      to,
+		Page:    query.Page,
+		Limit:   query.Limit,
+	})
+	if err != nil {
+		return dto.AuditListResponseDTO{}, err
+	}
+
+	responses := make([]dto.AuditEntryDTO, len(entries))
+	for i, entry := range entries {
+		responses[i] = toAuditEntryDTO(entry)
+	}
+
+	return dto.AuditListResponseDTO{
+		Entries:    responses,
+		Page:       query.Page,
+		Limit:      query.Limit,
+		Total:      total,
+		TotalPages: utils.CalculateTotalPages(total, query.Limit),
+	}, nil
+}
+
+// parseAuditTime parsea una fecha (YYYY-MM-DD, UTC) o un timestamp RFC3339; vacío es sin límite
+func parseAuditTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	return t.UTC(), err
+}
+
+// toAuditEntryDTO convierte un domain.AuditEntry a dto.AuditEntryDTO
+func toAuditEntryDTO(entry domain.AuditEntry) dto.AuditEntryDTO {
+	changes := make(map[string]dto.AuditChangeDTO, len(entry.Changes))
+	for field, change := range entry.Changes {
+		changes[field] = dto.AuditChangeDTO{Before: change.Before, After: change.After}
+	}
+
+	return dto.AuditEntryDTO{
+		ID:         entry.ID.Hex(),
+		ActorID:    entry.ActorID,
+		Action:     entry.Action,
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		Changes:    changes,
+		CreatedAt:  entry.CreatedAt,
+	}
+}
+
+// diffAuditStates compara los estados (serializados como JSON) campo por campo
+// y retorna solo los campos que cambiaron
+func diffAuditStates(before, after interface{}) map[string]domain.AuditChange {
+	beforeFields := auditFields(before)
+	afterFields := auditFields(after)
+
+	changes := make(map[string]domain.AuditChange)
+	for field, oldValue := range beforeFields {
+		if newValue, ok := afterFields[field]; !ok || !reflect.DeepEqual(oldValue, newValue) {
+			changes[field] = domain.AuditChange{Before: oldValue, After: afterFields[field]}
+		}
+	}
+	for field, newValue := range afterFields {
+		if _, ok := beforeFields[field]; !ok {
+			changes[field] = domain.AuditChange{Before: nil, After: newValue}
+		}
+	}
+
+	for field := range auditIgnoredFields {
+		delete(changes, field)
+	}
+
+	return changes
+}
+
+// auditFields convierte un estado a un mapa campo -> valor usando su representación JSON
+func auditFields(state interface{}) map[string]interface{} {
+	fields := make(map[string]interface{})
+	if state == nil || reflect.ValueOf(state).Kind() == reflect.Ptr && reflect.ValueOf(state).IsNil() {
+		return fields
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		log.Printf("⚠️ Error serializando estado para auditoría: %v", err)
+		return fields
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		log.Printf("⚠️ Error deserializando estado para auditoría: %v", err)
+	}
+	return fields
+}
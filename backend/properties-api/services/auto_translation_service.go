@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"time"
+
+	"properties-api/clients"
+	"properties-api/domain"
+	"properties-api/dto"
+	"properties-api/repositories"
+	"properties-api/utils"
+)
+
+// TranslationService elige el idioma del título y la descripción de una propiedad para un lector
+type TranslationService interface {
+	// Localize devuelve la propiedad en el idioma que mejor coincide con los locales preferidos
+	// (ver LocalizeProperty); si la propiedad no tiene ninguno y hay API de traducción, la traduce
+	// automáticamente al primero y la marca con autoTranslated
+	Localize(ctx context.Context, property dto.PropertyResponseDTO, preferred []string) dto.PropertyResponseDTO
+}
+
+// translationService es la implementación de TranslationService
+type translationService struct {
+	repo   repositories.AutoTranslationRepository
+	client clients.TranslationClient
+}
+
+// NewTranslationService crea el servicio de traducciones
+// client nil = sin traducción automática: solo se usan las traducciones cargadas por el anfitrión
+func NewTranslationService(repo repositories.AutoTranslationRepository, client clients.TranslationClient) TranslationService {
+	return &translationService{
+		repo:   repo,
+		client: client,
+	}
+}
+
+// Localize prefiere siempre el texto del anfitrión; la traducción automática se guarda por propiedad
+// y locale y se reutiliza mientras el texto original no cambie
+// Si la API o el caché fallan la propiedad queda en su idioma principal
+func (s *translationService) Localize(ctx context.Context, property dto.PropertyResponseDTO, preferred []string) dto.PropertyResponseDTO {
+	if _, ok := bestLocale(property.Language, property.Translations, preferred); ok || s.client == nil || len(preferred) == 0 {
+		return LocalizeProperty(property, preferred)
+	}
+
+	locale := preferred[0]
+	sourceHash := autoTranslationSourceHash(property)
+	cached, err := s.repo.Get(ctx, property.ID, locale)
+	if err != nil {
+		log.Printf("⚠️ Error leyendo traducción automática de %s (%s): %v", property.ID, locale, err)
+	}
+	if cached != nil && cached.SourceHash == sourceHash {
+		return autoTranslated(property, locale, cached.Title, cached.Description)
+	}
+
+	texts, err := s.client.Translate(ctx, []string{property.Title, property.Description}, utils.LocaleLanguage(property.Language), locale)
+	if err != nil {
+		log.Printf("⚠️ Error traduciendo la propiedad %s a %s: %v", property.ID, locale, err)
+		return property
+	}
+	translation := domain.AutoTranslation{
+		PropertyID:     property.ID,
+		Locale:         locale,
+		SourceLanguage: property.Language,
+		SourceHash:     sourceHash,
+		Title:          texts[0],
+		Description:    texts[1],
+		TranslatedAt:   time.Now().UTC(),
+	}
+	if err := s.repo.Save(ctx, translation); err != nil {
+		log.Printf("⚠️ Error guardando traducción automática de %s (%s): %v", property.ID, locale, err)
+	} else {
+		log.Printf("🌐 Propiedad %s traducida automáticamente de %s a %s", property.ID, property.Language, locale)
+	}
+	return autoTranslated(property, locale, translation.Title, translation.Description)
+}
+
+// autoTranslated deja en la propiedad el texto traducido automáticamente
+func autoTranslated(property dto.PropertyResponseDTO, locale, title, description string) dto.PropertyResponseDTO {
+	property.Title = title
+	property.Description = description
+	property.Language = locale
+	property.AutoTranslated = true
+	return property
+}
+
+// autoTranslationSourceHash identifica el texto original: si el anfitrión lo edita la traducción guardada ya no sirve
+func autoTranslationSourceHash(property dto.PropertyResponseDTO) string {
+	sum := sha256.Sum256([]byte(property.Language + "\x00" + property.Title + "\x00" + property.Description))
+	return hex.EncodeToString(sum[:16])
+}
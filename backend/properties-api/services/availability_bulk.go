@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"properties-api/clients"
+	"properties-api/domain"
+	"properties-api/dto"
+	"properties-api/utils"
+)
+
+// ErrInvalidAvailabilityRange indica un rango de fechas inválido al actualizar la disponibilidad
+var ErrInvalidAvailabilityRange = errors.New("rango de fechas inválido")
+
+// ErrAvailabilityConflict indica que un rango a bloquear se superpone con reservas activas
+var ErrAvailabilityConflict = errors.New("no se pueden bloquear fechas con reservas activas")
+
+// maxAvailabilityRangeDays es el largo máximo de cada rango de una actualización masiva
+const maxAvailabilityRangeDays = 366
+
+// availabilityUpdate es un rango de días locales (medianoche UTC, fin exclusivo) a bloquear o abrir
+type availabilityUpdate struct {
+	start time.Time
+	end   time.Time
+	block bool
+}
+
+// dayRange es un rango de días locales de la propiedad (medianoche UTC, fin exclusivo)
+type dayRange struct {
+	start time.Time
+	end   time.Time
+}
+
+// BulkUpdateAvailability aplica en orden los rangos a bloquear o abrir sobre los bloqueos manuales de la propiedad
+// Los bloqueos importados de calendarios externos no se tocan. Si algún rango a bloquear se superpone con
+// una reserva activa no se aplica ninguno. El cambio completo se publica como un único evento
+func (s *calendarService) BulkUpdateAvailability(ctx context.Context, propertyID, userID string, isAdmin bool, request dto.BulkAvailabilityUpdateDTO) ([]dto.AvailabilityRangeDTO, error) {
+	property, err := s.propertyRepo.GetByID(ctx, propertyID)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo propiedad: %w", err)
+	}
+	if !isAdmin && property.OwnerID != userID {
+		return nil, ErrCalendarForbidden
+	}
+	location, err := utils.LoadPropertyLocation(property.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	updates, err := parseAvailabilityUpdates(request.Ranges, localDate(utils.NowUTC(), location))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkBookingConflicts(ctx, propertyID, location, updates); err != nil {
+		return nil, err
+	}
+
+	blocks, err := s.availabilityRepo.FindByPropertyID(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	var manual []dayRange
+	for _, block := range blocks {
+		if block.FeedID.IsZero() {
+			manual = addDayRange(manual, dayRange{start: block.Start, end: block.End})
+		}
+	}
+
+	event := clients.AvailabilityUpdatedEvent{
+		PropertyID: propertyID,
+		UpdatedBy:  userID,
+		Blocked:    []clients.AvailabilityDateRange{},
+		Opened:     []clients.AvailabilityDateRange{},
+	}
+	for _, update := range updates {
+		updated := dayRange{start: update.start, end: update.end}
+		if update.block {
+			manual = addDayRange(manual, updated)
+			event.Blocked = append(event.Blocked, toAvailabilityDateRange(updated))
+		} else {
+			manual = subtractDayRange(manual, updated)
+			event.Opened = append(event.Opened, toAvailabilityDateRange(updated))
+		}
+	}
+
+	now := utils.NowUTC()
+	replacement := make([]domain.AvailabilityBlock, len(manual))
+	event.ManualBlocks = make([]clients.AvailabilityDateRange, len(manual))
+	for i, r := range manual {
+		replacement[i] = domain.AvailabilityBlock{
+			PropertyID: propertyID,
+			Start:      r.start,
+			End:        r.end,
+			Summary:    "Bloqueado por el anfitrión",
+			CreatedAt:  now,
+		}
+		event.ManualBlocks[i] = toAvailabilityDateRange(r)
+	}
+	if err := s.availabilityRepo.ReplaceManualBlocks(ctx, propertyID, replacement); err != nil {
+		return nil, err
+	}
+
+	event.UpdatedAt = utils.FormatTimestamp(now)
+	if err := s.rabbitClient.PublishAvailabilityEvent(event); err != nil {
+		// Los bloqueos ya están guardados; GetAvailability y el calendario iCal los reflejan igual
+		log.Printf("⚠️ Error publicando evento 'availability.updated' de la propiedad %s: %v", propertyID, err)
+	}
+	s.RefreshBookedRanges(ctx, propertyID, AvailabilityReasonBlock)
+
+	return s.GetAvailability(ctx, propertyID)
+}
+
+// parseAvailabilityUpdates valida los rangos pedidos: fechas YYYY-MM-DD, fin posterior al inicio,
+// como máximo maxAvailabilityRangeDays días y que no terminen antes de today
+func parseAvailabilityUpdates(ranges []dto.AvailabilityRangeUpdateDTO, today time.Time) ([]availabilityUpdate, error) {
+	updates := make([]availabilityUpdate, 0, len(ranges))
+	for _, r := range ranges {
+		start, errStart := time.Parse("2006-01-02", r.Start)
+		end, errEnd := time.Parse("2006-01-02", r.End)
+		if errStart != nil || errEnd != nil {
+			return nil, fmt.Errorf("%w: %s al %s, usar YYYY-MM-DD", ErrInvalidAvailabilityRange, r.Start, r.End)
+		}
+		if !end.After(start) {
+			return nil, fmt.Errorf("%w: el fin (%s) tiene que ser posterior al inicio (%s)", ErrInvalidAvailabilityRange, r.End, r.Start)
+		}
+		if end.Sub(start) > maxAvailabilityRangeDays*24*time.Hour {
+			return nil, fmt.Errorf("%w: %s al %s supera los %d días", ErrInvalidAvailabilityRange, r.Start, r.End, maxAvailabilityRangeDays)
+		}
+		if !end.After(today) {
+			return nil, fmt.Errorf("%w: %s al %s ya pasó", ErrInvalidAvailabilityRange, r.Start, r.End)
+		}
+		updates = append(updates, availabilityUpdate{start: start, end: end, block: r.Action == "block"})
+	}
+	return updates, nil
+}
+
+// checkBookingConflicts verifica que ningún rango a bloquear se superponga con una reserva activa
+// Junta todas las reservas en conflicto para que el owner las vea de una vez
+func (s *calendarService) checkBookingConflicts(ctx context.Context, propertyID string, location *time.Location, updates []availabilityUpdate) error {
+	var conflicts []string
+	err := s.bookingRepo.StreamByPropertyIDs(ctx, []string{propertyID}, func(booking domain.Booking) error {
+		if !booking.IsActive() {
+			return nil
+		}
+		start, end := localDateRange(booking.CheckIn, booking.CheckOut, location)
+		for _, update := range updates {
+			if update.block && update.start.Before(end) && start.Before(update.end) {
+				conflicts = append(conflicts, fmt.Sprintf("reserva %s del %s al %s",
+					booking.ID.Hex(), start.Format("2006-01-02"), end.Format("2006-01-02")))
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error obteniendo reservas: %w", err)
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%w: %s", ErrAvailabilityConflict, strings.Join(conflicts, ", "))
+	}
+	return nil
+}
+
+// addDayRange agrega un rango a una lista ordenada y junta los que se superponen o se tocan
+func addDayRange(ranges []dayRange, added dayRange) []dayRange {
+	ranges = append(ranges, added)
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].start.Before(ranges[j].start) })
+
+	merged := ranges[:1]
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if !r.start.After(last.end) {
+			if r.end.After(last.end) {
+				last.end = r.end
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// subtractDayRange saca un rango de la lista, partiendo los rangos que lo contienen
+func subtractDayRange(ranges []dayRange, removed dayRange) []dayRange {
+	result := make([]dayRange, 0, len(ranges)+1)
+	for _, r := range ranges {
+		if !r.start.Before(removed.end) || !removed.start.Before(r.end) {
+			result = append(result, r)
+			continue
+		}
+		if r.start.Before(removed.start) {
+			result = append(result, dayRange{start: r.start, end: removed.start})
+		}
+		if removed.end.Before(r.end) {
+			result = append(result, dayRange{start: removed.end, end: r.end})
+		}
+	}
+	return result
+}
+
+// toAvailabilityDateRange convierte un rango de días al formato del evento
+func toAvailabilityDateRange(r dayRange) clients.AvailabilityDateRange {
+	return clients.AvailabilityDateRange{
+		Start: r.start.Format("2006-01-02"),
+		End:   r.end.Format("2006-01-02"),
+	}
+}
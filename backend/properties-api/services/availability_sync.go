@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"properties-api/clients"
+	"properties-api/domain"
+	"properties-api/dto"
+	"properties-api/utils"
+)
+
+// Motivos del evento "availability.changed"
+const (
+	AvailabilityReasonBooking = "booking" // Una reserva tomó o liberó fechas
+	AvailabilityReasonBlock   = "block"   // El owner bloqueó o abrió fechas
+	AvailabilityReasonFeed    = "feed"    // Cambiaron los bloqueos importados de un calendario externo
+)
+
+// RefreshBookedRanges recalcula las fechas ocupadas de la propiedad desde hoy, con las mismas reglas que
+// GetAvailability, las guarda en la propiedad y publica "availability.changed" si cambiaron
+// Se llama con el cambio ya guardado: un error se loguea sin deshacer la reserva o el bloqueo, y como el
+// evento trae el estado completo el próximo cambio de la propiedad corrige uno perdido
+func (s *calendarService) RefreshBookedRanges(ctx context.Context, propertyID, reason string) {
+	if err := s.refreshBookedRanges(ctx, propertyID, reason); err != nil {
+		log.Printf("⚠️ Error actualizando las fechas ocupadas de la propiedad %s: %v", propertyID, err)
+	}
+}
+
+func (s *calendarService) refreshBookedRanges(ctx context.Context, propertyID, reason string) error {
+	property, err := s.propertyRepo.GetByID(ctx, propertyID)
+	if err != nil {
+		return fmt.Errorf("error obteniendo propiedad: %w", err)
+	}
+	location, err := utils.LoadPropertyLocation(property.Timezone)
+	if err != nil {
+		return err
+	}
+	ranges, err := s.GetAvailability(ctx, propertyID)
+	if err != nil {
+		return err
+	}
+
+	now := utils.NowUTC()
+	booked, next := bookedRangesFrom(ranges, localDate(now, location))
+	event := clients.AvailabilityChangedEvent{
+		PropertyID:        propertyID,
+		Reason:            reason,
+		BookedRanges:      make([]clients.AvailabilityDateRange, len(booked)),
+		NextAvailableDate: next.Format("2006-01-02"),
+		ChangedAt:         utils.FormatTimestamp(now),
+	}
+	saved := make([]domain.DateRange, len(booked))
+	for i, r := range booked {
+		event.BookedRanges[i] = toAvailabilityDateRange(r)
+		saved[i] = domain.DateRange{Start: event.BookedRanges[i].Start, End: event.BookedRanges[i].End}
+	}
+
+	// La sincronización periódica de calendarios externos pasa por acá aunque no haya cambios
+	if sameDateRanges(property.BookedRanges, saved) && property.NextAvailableDate == event.NextAvailableDate {
+		return nil
+	}
+	if err := s.propertyRepo.SetBookedRanges(ctx, propertyID, saved, event.NextAvailableDate); err != nil {
+		return err
+	}
+	if err := s.rabbitClient.PublishAvailabilityChangedEvent(event); err != nil {
+		return fmt.Errorf("error publicando evento 'availability.changed': %w", err)
+	}
+	return nil
+}
+
+// bookedRangesFrom une los rangos ocupados que terminan después de today (recortados para empezar como
+// mínimo en today) y retorna también el primer día libre desde today
+func bookedRangesFrom(ranges []dto.AvailabilityRangeDTO, today time.Time) ([]dayRange, time.Time) {
+	var booked []dayRange
+	for _, r := range ranges {
+		start, errStart := time.Parse("2006-01-02", r.Start)
+		end, errEnd := time.Parse("2006-01-02", r.End)
+		if errStart != nil || errEnd != nil || !end.After(today) {
+			continue
+		}
+		if start.Before(today) {
+			start = today
+		}
+		booked = addDayRange(booked, dayRange{start: start, end: end})
+	}
+
+	// Los rangos unidos no se tocan: si el primero empieza hoy, el día libre es su fin
+	next := today
+	if len(booked) > 0 && !booked[0].start.After(today) {
+		next = booked[0].end
+	}
+	return booked, next
+}
+
+// sameDateRanges indica si las dos listas de rangos son iguales
+func sameDateRanges(a, b []domain.DateRange) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"properties-api/domain"
+	"properties-api/dto"
+	"properties-api/repositories"
+	"properties-api/utils"
+)
+
+// ErrBookingHoldNotFound indica que el hold no existe o no es del usuario
+var ErrBookingHoldNotFound = errors.New("hold de reserva no encontrado")
+
+// ErrBookingHoldExpired indica que el hold venció o ya se usó: las fechas pueden estar tomadas
+var ErrBookingHoldExpired = errors.New("el hold de la reserva venció o ya se usó")
+
+// expiredHoldsBatchSize es la cantidad de holds vencidos que se liberan por consulta
+const expiredHoldsBatchSize = 100
+
+// HoldBooking cotiza la estadía y retiene sus noches durante BOOKING_HOLD_TTL
+// Otra reserva o hold de esas noches falla con ErrPropertyUnavailable; el cupón no se toma hasta reservar
+func (s *bookingService) HoldBooking(ctx context.Context, userID string, request dto.BookingCreateDTO) (dto.BookingHoldDTO, error) {
+	quote, err := s.quote(ctx, request, userID)
+	if err != nil {
+		return dto.BookingHoldDTO{}, err
+	}
+	if quote.property.OwnerID == userID {
+		return dto.BookingHoldDTO{}, ErrCannotBookOwnProperty
+	}
+
+	hold := domain.BookingHold{
+		PropertyID: request.PropertyID,
+		UserID:     userID,
+		CheckIn:    request.CheckIn,
+		CheckOut:   request.CheckOut,
+		ExpiresAt:  utils.NowUTC().Add(s.requests.HoldTTL),
+	}
+	if err := s.holdRepo.Create(ctx, &hold, stayNights(quote.breakdown)); err != nil {
+		if errors.Is(err, repositories.ErrBookingNightsTaken) {
+			return dto.BookingHoldDTO{}, fmt.Errorf("%w: otra reserva tomó las fechas", ErrPropertyUnavailable)
+		}
+		return dto.BookingHoldDTO{}, err
+	}
+
+	return dto.BookingHoldDTO{
+		ID:         hold.ID.Hex(),
+		PropertyID: hold.PropertyID,
+		CheckIn:    hold.CheckIn,
+		CheckOut:   hold.CheckOut,
+		ExpiresAt:  utils.FormatTimestamp(hold.ExpiresAt),
+		Breakdown:  toPriceBreakdownDTO(quote.breakdown),
+	}, nil
+}
+
+// userHold obtiene el hold de la reserva verificando que sea del usuario y de la misma estadía
+// El vencimiento se verifica al reclamarlo (Claim), que es lo que decide entre la reserva y el sweeper
+func (s *bookingService) userHold(ctx context.Context, request dto.BookingCreateDTO, userID string) (*domain.BookingHold, error) {
+	hold, err := s.holdRepo.FindByID(ctx, request.HoldID)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo hold: %w", err)
+	}
+	if hold == nil {
+		return nil, ErrBookingHoldExpired
+	}
+	if hold.UserID != userID {
+		return nil, ErrBookingHoldNotFound
+	}
+	if hold.PropertyID != request.PropertyID || hold.CheckIn != request.CheckIn || hold.CheckOut != request.CheckOut {
+		return nil, fmt.Errorf("%w: el hold es de otra estadía (%s del %s al %s)", ErrInvalidStay, hold.PropertyID, hold.CheckIn, hold.CheckOut)
+	}
+	return hold, nil
+}
+
+// ReleaseHold suelta el hold (ej: el huésped abandonó el checkout) sin esperar a que venza
+func (s *bookingService) ReleaseHold(ctx context.Context, holdID, userID string) error {
+	hold, err := s.holdRepo.FindByID(ctx, holdID)
+	if err != nil {
+		return fmt.Errorf("error obteniendo hold: %w", err)
+	}
+	if hold == nil || hold.UserID != userID {
+		return ErrBookingHoldNotFound
+	}
+	if _, err := s.holdRepo.Release(ctx, hold.ID); err != nil {
+		return fmt.Errorf("error liberando hold: %w", err)
+	}
+	return nil
+}
+
+// ExpireHolds libera por lotes los holds vencidos
+func (s *bookingService) ExpireHolds(ctx context.Context) (int, error) {
+	released := 0
+	for {
+		count, err := s.holdRepo.ReleaseExpired(ctx, utils.NowUTC(), expiredHoldsBatchSize)
+		released += count
+		if err != nil {
+			return released, fmt.Errorf("error liberando holds vencidos: %w", err)
+		}
+		if count < expiredHoldsBatchSize {
+			return released, nil
+		}
+	}
+}
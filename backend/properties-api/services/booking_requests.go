@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"properties-api/clients"
+	"properties-api/domain"
+	"properties-api/dto"
+	"properties-api/utils"
+)
+
+// ErrBookingForbidden indica que el usuario no es el anfitrión de la propiedad reservada ni admin
+var ErrBookingForbidden = errors.New("solo el anfitrión de la propiedad puede responder la solicitud")
+
+// ErrBookingNotPending indica que la reserva no es una solicitud esperando respuesta
+var ErrBookingNotPending = errors.New("la reserva no es una solicitud pendiente")
+
+// ErrBookingRequestExpired indica que venció el plazo para responder la solicitud
+var ErrBookingRequestExpired = errors.New("la solicitud de reserva venció")
+
+// expiredRequestsBatchSize es la cantidad de solicitudes vencidas que se procesan por consulta
+const expiredRequestsBatchSize = 100
+
+// ApproveBooking confirma una solicitud pendiente, registra el uso del cupón y publica "booking.confirmed"
+func (s *bookingService) ApproveBooking(ctx context.Context, bookingID, userID string, isAdmin bool) (dto.BookingDTO, error) {
+	booking, property, err := s.pendingRequest(ctx, bookingID, userID, isAdmin)
+	if err != nil {
+		return dto.BookingDTO{}, err
+	}
+	if err := s.transitionRequest(ctx, booking, domain.BookingConfirmed); err != nil {
+		return dto.BookingDTO{}, err
+	}
+
+	if coupon := s.requestCoupon(ctx, *booking); coupon != nil {
+		s.coupons.RecordRedemption(ctx, coupon, booking.ID.Hex(), booking.UserID, booking.PriceBreakdown.Discount)
+	}
+	s.publishConfirmed(*booking)
+
+	return toBookingDTO(*booking, property.Timezone), nil
+}
+
+// DeclineBooking rechaza una solicitud pendiente, devuelve el uso del cupón y publica "booking.declined"
+func (s *bookingService) DeclineBooking(ctx context.Context, bookingID, userID string, isAdmin bool) (dto.BookingDTO, error) {
+	booking, property, err := s.pendingRequest(ctx, bookingID, userID, isAdmin)
+	if err != nil {
+		return dto.BookingDTO{}, err
+	}
+	if err := s.transitionRequest(ctx, booking, domain.BookingDeclined); err != nil {
+		return dto.BookingDTO{}, err
+	}
+
+	if coupon := s.requestCoupon(ctx, *booking); coupon != nil {
+		s.coupons.Release(ctx, coupon)
+	}
+	s.publishRequestEvent(clients.BookingDeclinedRoutingKey, *booking, property.OwnerID)
+
+	return toBookingDTO(*booking, property.Timezone), nil
+}
+
+// ExpirePendingRequests vence por lotes las solicitudes pendientes cuyo plazo ya pasó
+// Cada una se pasa a "expired" solo si sigue pendiente, así no pisa una respuesta del anfitrión
+func (s *bookingService) ExpirePendingRequests(ctx context.Context) (int, error) {
+	expired := 0
+	for {
+		bookings, err := s.bookingRepo.FindExpiredPending(ctx, utils.NowUTC(), expiredRequestsBatchSize)
+		if err != nil {
+			return expired, fmt.Errorf("error obteniendo solicitudes vencidas: %w", err)
+		}
+
+		for i := range bookings {
+			if err := s.expireRequest(ctx, &bookings[i]); err != nil {
+				if errors.Is(err, ErrBookingNotPending) {
+					continue
+				}
+				return expired, err
+			}
+			expired++
+		}
+
+		if len(bookings) < expiredRequestsBatchSize {
+			return expired, nil
+		}
+	}
+}
+
+// pendingRequest obtiene una solicitud pendiente y su propiedad verificando que el usuario pueda responderla
+// Si el plazo ya venció la solicitud se vence en el momento (sin esperar al proceso periódico)
+func (s *bookingService) pendingRequest(ctx context.Context, bookingID, userID string, isAdmin bool) (*domain.Booking, domain.Property, error) {
+	booking, err := s.bookingRepo.FindByID(ctx, bookingID)
+	if err != nil {
+		return nil, domain.Property{}, fmt.Errorf("error obteniendo reserva: %w", err)
+	}
+	property, err := s.propertyRepo.GetByID(ctx, booking.PropertyID)
+	if err != nil {
+		return nil, domain.Property{}, fmt.Errorf("error obteniendo propiedad: %w", err)
+	}
+	if !isAdmin && property.OwnerID != userID {
+		return nil, domain.Property{}, ErrBookingForbidden
+	}
+	if booking.Status != domain.BookingPending {
+		return nil, domain.Property{}, fmt.Errorf("%w (estado '%s')", ErrBookingNotPending, booking.Status)
+	}
+	if booking.ExpiresAt != nil && !utils.NowUTC().Before(*booking.ExpiresAt) {
+		if err := s.expireRequest(ctx, booking); err != nil && !errors.Is(err, ErrBookingNotPending) {
+			return nil, domain.Property{}, err
+		}
+		return nil, domain.Property{}, ErrBookingRequestExpired
+	}
+	return booking, property, nil
+}
+
+// transitionRequest pasa una solicitud pendiente al estado status
+// Falla con ErrBookingNotPending si otra respuesta o el vencimiento la cambiaron antes
+func (s *bookingService) transitionRequest(ctx context.Context, booking *domain.Booking, status string) error {
+	updated, err := s.bookingRepo.UpdateStatus(ctx, booking.ID.Hex(), domain.BookingPending, status)
+	if err != nil {
+		return fmt.Errorf("error actualizando reserva: %w", err)
+	}
+	if !updated {
+		return fmt.Errorf("%w: ya fue respondida o venció", ErrBookingNotPending)
+	}
+	booking.Status = status
+	if !booking.IsActive() {
+		// Rechazada o vencida: sus fechas quedan libres
+		s.calendar.RefreshBookedRanges(ctx, booking.PropertyID, AvailabilityReasonBooking)
+	}
+	return nil
+}
+
+// expireRequest vence una solicitud, devuelve el uso del cupón y publica "booking.expired"
+func (s *bookingService) expireRequest(ctx context.Context, booking *domain.Booking) error {
+	if err := s.transitionRequest(ctx, booking, domain.BookingExpired); err != nil {
+		return err
+	}
+	if coupon := s.requestCoupon(ctx, *booking); coupon != nil {
+		s.coupons.Release(ctx, coupon)
+	}
+
+	// El owner solo se usa para avisarle; sin la propiedad el evento sale igual
+	ownerID := ""
+	if property, err := s.propertyRepo.GetByID(ctx, booking.PropertyID); err == nil {
+		ownerID = property.OwnerID
+	}
+	s.publishRequestEvent(clients.BookingExpiredRoutingKey, *booking, ownerID)
+	return nil
+}
+
+// requestCoupon obtiene el cupón aplicado en la solicitud (nil si no tiene o ya no existe)
+func (s *bookingService) requestCoupon(ctx context.Context, booking domain.Booking) *domain.Coupon {
+	if booking.PriceBreakdown == nil || booking.PriceBreakdown.CouponCode == "" {
+		return nil
+	}
+	coupon, err := s.coupons.Lookup(ctx, booking.PriceBreakdown.CouponCode)
+	if err != nil {
+		log.Printf("⚠️ Error obteniendo el cupón %s de la reserva %s: %v", booking.PriceBreakdown.CouponCode, booking.ID.Hex(), err)
+		return nil
+	}
+	return coupon
+}
+
+// requestExpiry calcula el plazo de respuesta de una solicitud creada en now
+// Nunca pasa del check-in: una solicitud que nadie respondió no puede quedar pendiente durante la estadía
+func (s *bookingService) requestExpiry(now, checkIn time.Time) *time.Time {
+	expiresAt := now.Add(s.requests.RequestTTL)
+	if checkIn.Before(expiresAt) {
+		expiresAt = checkIn
+	}
+	return &expiresAt
+}
+
+// publishRequestEvent publica un cambio de una solicitud de reserva; un error solo se loguea
+func (s *bookingService) publishRequestEvent(routingKey string, booking domain.Booking, ownerID string) {
+	event := clients.BookingRequestEvent{
+		ID:         booking.ID.Hex(),
+		PropertyID: booking.PropertyID,
+		UserID:     booking.UserID,
+		OwnerID:    ownerID,
+		CheckIn:    utils.FormatTimestamp(booking.CheckIn),
+		CheckOut:   utils.FormatTimestamp(booking.CheckOut),
+		TotalPrice: booking.TotalPrice,
+		Status:     booking.Status,
+	}
+	if booking.ExpiresAt != nil {
+		event.ExpiresAt = utils.FormatTimestamp(*booking.ExpiresAt)
+	}
+	if err := s.rabbitClient.PublishBookingRequestEvent(routingKey, event); err != nil {
+		log.Printf("⚠️ Error publicando evento '%s' de la reserva %s: %v", routingKey, event.ID, err)
+	}
+}
@@ -0,0 +1,484 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"properties-api/clients"
+	"properties-api/config"
+	"properties-api/domain"
+	"properties-api/dto"
+	"properties-api/repositories"
+	"properties-api/utils"
+)
+
+// ErrInvalidStay indica fechas de estadía inválidas (formato, orden, pasado o demasiado larga)
+var ErrInvalidStay = errors.New("fechas de estadía inválidas")
+
+// ErrTooManyGuests indica que los huéspedes superan la capacidad de la propiedad
+var ErrTooManyGuests = errors.New("la cantidad de huéspedes supera la capacidad de la propiedad")
+
+// ErrPropertyUnavailable indica que la propiedad no está disponible o ya está ocupada en esas fechas
+var ErrPropertyUnavailable = errors.New("la propiedad no está disponible en las fechas pedidas")
+
+// ErrCannotBookOwnProperty indica que el owner intentó reservar su propia propiedad
+var ErrCannotBookOwnProperty = errors.New("no se puede reservar una propiedad propia")
+
+// Horarios locales de check-in y check-out de las reservas
+const (
+	bookingCheckInHour  = 15
+	bookingCheckOutHour = 11
+)
+
+// maxBookingNights es la estadía más larga que se puede cotizar o reservar
+const maxBookingNights = 365
+
+// maxBookingInfants es la cantidad máxima de bebés por reserva (no cuentan para la capacidad)
+const maxBookingInfants = 5
+
+// BookingService define la lógica de negocio de las reservas
+type BookingService interface {
+	// StreamOwnerBookings recorre las reservas de todas las propiedades de un owner
+	StreamOwnerBookings(ctx context.Context, ownerID string, fn func(dto.BookingDTO) error) error
+	// QuoteBooking cotiza una estadía con el precio de cada noche, la limpieza, la tarifa de servicio y los impuestos
+	// checkIn y checkOut son días locales de la propiedad (YYYY-MM-DD); couponCode es opcional
+	QuoteBooking(ctx context.Context, propertyID, checkIn, checkOut string, guests dto.GuestCountsDTO, couponCode string) (dto.BookingQuoteDTO, error)
+	// CreateBooking reserva para el usuario con el precio cotizado y guarda el detalle en la reserva
+	// Si la propiedad requiere aprobación la reserva queda pendiente hasta que el anfitrión responda
+	CreateBooking(ctx context.Context, userID string, request dto.BookingCreateDTO) (dto.BookingDTO, error)
+	// ApproveBooking confirma una solicitud pendiente (solo el anfitrión de la propiedad o un admin)
+	ApproveBooking(ctx context.Context, bookingID, userID string, isAdmin bool) (dto.BookingDTO, error)
+	// DeclineBooking rechaza una solicitud pendiente y libera las fechas (solo el anfitrión de la propiedad o un admin)
+	DeclineBooking(ctx context.Context, bookingID, userID string, isAdmin bool) (dto.BookingDTO, error)
+	// ExpirePendingRequests vence las solicitudes que el anfitrión no respondió a tiempo y retorna cuántas venció
+	ExpirePendingRequests(ctx context.Context) (int, error)
+	// HoldBooking retiene las fechas de la estadía para el usuario mientras completa el checkout
+	// Valida y cotiza igual que CreateBooking; para reservar se manda el holdId en CreateBooking
+	HoldBooking(ctx context.Context, userID string, request dto.BookingCreateDTO) (dto.BookingHoldDTO, error)
+	// ReleaseHold suelta un hold del usuario antes de que venza y libera las fechas
+	ReleaseHold(ctx context.Context, holdID, userID string) error
+	// ExpireHolds libera las fechas de los holds vencidos y retorna cuántos liberó
+	ExpireHolds(ctx context.Context) (int, error)
+}
+
+// bookingService es la implementación concreta de BookingService
+type bookingService struct {
+	bookingRepo  repositories.BookingRepository
+	holdRepo     repositories.BookingHoldRepository
+	propertyRepo repositories.PropertyRepository
+	calendar     CalendarService
+	rabbitClient clients.RabbitMQClient
+	coupons      CouponService
+	pricing      config.PricingConfig
+	requests     config.BookingsConfig
+	taxes        utils.TaxTable
+}
+
+// NewBookingService crea una nueva instancia del servicio de reservas
+// calendar se usa para verificar la disponibilidad con las mismas reglas que el calendario exportado
+// requests define el plazo de respuesta de las solicitudes de propiedades que requieren aprobación
+// y el de los holds de checkout
+func NewBookingService(
+	bookingRepo repositories.BookingRepository,
+	holdRepo repositories.BookingHoldRepository,
+	propertyRepo repositories.PropertyRepository,
+	calendar CalendarService,
+	rabbitClient clients.RabbitMQClient,
+	coupons CouponService,
+	pricing config.PricingConfig,
+	requests config.BookingsConfig,
+) BookingService {
+	return &bookingService{
+		bookingRepo:  bookingRepo,
+		holdRepo:     holdRepo,
+		propertyRepo: propertyRepo,
+		calendar:     calendar,
+		rabbitClient: rabbitClient,
+		coupons:      coupons,
+		pricing:      pricing,
+		requests:     requests,
+		taxes:        utils.NewTaxTable(pricing.DefaultTaxRate, pricing.TaxRates),
+	}
+}
+
+// stayQuote es una cotización ya validada junto con los datos necesarios para reservar
+type stayQuote struct {
+	property  domain.Property
+	checkIn   time.Time // Instante de check-in (UTC)
+	checkOut  time.Time // Instante de check-out (UTC)
+	party     dto.GuestCountsDTO
+	breakdown domain.PriceBreakdown
+	coupon    *domain.Coupon // Cupón aplicado (nil si no se ingresó)
+}
+
+// QuoteBooking cotiza una estadía sin reservarla
+// Sin usuario autenticado no se verifica el límite de usos por huésped del cupón
+func (s *bookingService) QuoteBooking(ctx context.Context, propertyID, checkIn, checkOut string, guests dto.GuestCountsDTO, couponCode string) (dto.BookingQuoteDTO, error) {
+	request := dto.BookingCreateDTO{
+		PropertyID: propertyID,
+		CheckIn:    checkIn,
+		CheckOut:   checkOut,
+		Adults:     guests.Adults,
+		Children:   guests.Children,
+		Infants:    guests.Infants,
+		CouponCode: couponCode,
+	}
+	quote, err := s.quote(ctx, request, "")
+	if err != nil {
+		return dto.BookingQuoteDTO{}, err
+	}
+
+	return dto.BookingQuoteDTO{
+		PropertyID: propertyID,
+		CheckIn:    checkIn,
+		CheckOut:   checkOut,
+		Guests:     quote.party.Occupants(),
+		Adults:     quote.party.Adults,
+		Children:   quote.party.Children,
+		Infants:    quote.party.Infants,
+		Nights:     len(quote.breakdown.Nights),
+		Breakdown:  toPriceBreakdownDTO(quote.breakdown),
+	}, nil
+}
+
+// CreateBooking cotiza la estadía, guarda la reserva con el detalle del precio y publica "booking.confirmed"
+// El total de la reserva es el total de la cotización; si tiene cupón se toma un uso y se registra en la reserva
+// En propiedades con reserva por solicitud se guarda pendiente y se publica "booking.requested": las fechas
+// quedan tomadas y el uso del cupón reservado hasta que el anfitrión responda o venza el plazo
+// Con holdId la reserva se queda con las noches del hold (que tiene que ser del usuario, de la misma estadía y no haber vencido)
+func (s *bookingService) CreateBooking(ctx context.Context, userID string, request dto.BookingCreateDTO) (dto.BookingDTO, error) {
+	quote, err := s.quote(ctx, request, userID)
+	if err != nil {
+		return dto.BookingDTO{}, err
+	}
+	if quote.property.OwnerID == userID {
+		return dto.BookingDTO{}, ErrCannotBookOwnProperty
+	}
+
+	var hold *domain.BookingHold
+	if request.HoldID != "" {
+		if hold, err = s.userHold(ctx, request, userID); err != nil {
+			return dto.BookingDTO{}, err
+		}
+	}
+
+	// El uso se toma antes de guardar la reserva para que dos reservas simultáneas no superen el límite
+	if quote.coupon != nil {
+		if err := s.coupons.Reserve(ctx, quote.coupon, utils.NowUTC()); err != nil {
+			return dto.BookingDTO{}, err
+		}
+	}
+
+	breakdown := quote.breakdown
+	booking := domain.Booking{
+		PropertyID:     request.PropertyID,
+		UserID:         userID,
+		CheckIn:        quote.checkIn,
+		CheckOut:       quote.checkOut,
+		Adults:         quote.party.Adults,
+		Children:       quote.party.Children,
+		Infants:        quote.party.Infants,
+		TotalPrice:     breakdown.Total,
+		PriceBreakdown: &breakdown,
+	}
+	if quote.property.RequiresApproval() {
+		booking.Status = domain.BookingPending
+		booking.ExpiresAt = s.requestExpiry(utils.NowUTC(), quote.checkIn)
+	}
+	if err := s.saveBooking(ctx, &booking, hold); err != nil {
+		if quote.coupon != nil {
+			s.coupons.Release(ctx, quote.coupon)
+		}
+		return dto.BookingDTO{}, err
+	}
+	s.calendar.RefreshBookedRanges(ctx, booking.PropertyID, AvailabilityReasonBooking)
+
+	if booking.Status == domain.BookingPending {
+		s.publishRequestEvent(clients.BookingRequestedRoutingKey, booking, quote.property.OwnerID)
+		return toBookingDTO(booking, quote.property.Timezone), nil
+	}
+
+	if quote.coupon != nil {
+		s.coupons.RecordRedemption(ctx, quote.coupon, booking.ID.Hex(), userID, breakdown.Discount)
+	}
+	s.publishConfirmed(booking)
+
+	return toBookingDTO(booking, quote.property.Timezone), nil
+}
+
+// saveBooking guarda la reserva tomando sus noches, o las del hold si la reserva viene de uno
+// checkAvailability ya vio las fechas libres, pero otra reserva simultánea pudo tomarlas después:
+// el repositorio toma cada noche con un índice único y solo una de las dos reservas gana
+func (s *bookingService) saveBooking(ctx context.Context, booking *domain.Booking, hold *domain.BookingHold) error {
+	if hold != nil {
+		claimed, err := s.holdRepo.Claim(ctx, hold.ID, utils.NowUTC())
+		if err != nil {
+			return fmt.Errorf("error usando el hold: %w", err)
+		}
+		if !claimed {
+			return ErrBookingHoldExpired
+		}
+		if err := s.bookingRepo.CreateFromHold(ctx, booking, hold.ID); err != nil {
+			return fmt.Errorf("error creando reserva: %w", err)
+		}
+		return nil
+	}
+
+	if err := s.bookingRepo.Create(ctx, booking, stayNights(*booking.PriceBreakdown)); err != nil {
+		if errors.Is(err, repositories.ErrBookingNightsTaken) {
+			return fmt.Errorf("%w: otra reserva tomó las fechas", ErrPropertyUnavailable)
+		}
+		return fmt.Errorf("error creando reserva: %w", err)
+	}
+	return nil
+}
+
+// stayNights retorna los días locales de las noches cotizadas
+func stayNights(breakdown domain.PriceBreakdown) []string {
+	nights := make([]string, len(breakdown.Nights))
+	for i, night := range breakdown.Nights {
+		nights[i] = night.Date
+	}
+	return nights
+}
+
+// publishConfirmed publica "booking.confirmed"; un error solo se loguea
+func (s *bookingService) publishConfirmed(booking domain.Booking) {
+	event := clients.BookingConfirmedEvent{
+		ID:         booking.ID.Hex(),
+		PropertyID: booking.PropertyID,
+		UserID:     booking.UserID,
+		CheckIn:    utils.FormatTimestamp(booking.CheckIn),
+		CheckOut:   utils.FormatTimestamp(booking.CheckOut),
+		TotalPrice: booking.TotalPrice,
+	}
+	if err := s.rabbitClient.PublishBookingEvent(event); err != nil {
+		// La reserva ya está guardada: solo se pierde el email de confirmación
+		log.Printf("⚠️ Error publicando evento 'booking.confirmed' de la reserva %s: %v", event.ID, err)
+	}
+}
+
+// quote valida la estadía y calcula el detalle del precio
+// Las noches se cuentan en días locales de la propiedad: del día de check-in al día anterior al check-out
+// userID es el huésped (vacío en una cotización anónima) y se usa para el límite por huésped del cupón
+func (s *bookingService) quote(ctx context.Context, request dto.BookingCreateDTO, userID string) (stayQuote, error) {
+	propertyID, party := request.PropertyID, request.Party()
+	property, err := s.propertyRepo.GetByID(ctx, propertyID)
+	if err != nil {
+		return stayQuote{}, fmt.Errorf("error obteniendo propiedad: %w", err)
+	}
+	// Una propiedad retenida por moderación no está publicada y no se puede reservar
+	if !property.Available || !property.IsPublished() {
+		return stayQuote{}, ErrPropertyUnavailable
+	}
+	if party.Adults < 1 {
+		return stayQuote{}, fmt.Errorf("%w: debe haber al menos un adulto", ErrInvalidStay)
+	}
+	if party.Children < 0 || party.Infants < 0 {
+		return stayQuote{}, fmt.Errorf("%w: la cantidad de niños y bebés no puede ser negativa", ErrInvalidStay)
+	}
+	if property.Capacity > 0 && party.Occupants() > property.Capacity {
+		return stayQuote{}, fmt.Errorf("%w (máximo %d entre adultos y niños)", ErrTooManyGuests, property.Capacity)
+	}
+	if party.Infants > maxBookingInfants {
+		return stayQuote{}, fmt.Errorf("%w (máximo %d bebés)", ErrTooManyGuests, maxBookingInfants)
+	}
+
+	checkInAt, err := utils.PropertyLocalToUTC(request.CheckIn, bookingCheckInHour, property.Timezone)
+	if err != nil {
+		return stayQuote{}, fmt.Errorf("%w: %v", ErrInvalidStay, err)
+	}
+	checkOutAt, err := utils.PropertyLocalToUTC(request.CheckOut, bookingCheckOutHour, property.Timezone)
+	if err != nil {
+		return stayQuote{}, fmt.Errorf("%w: %v", ErrInvalidStay, err)
+	}
+	location, err := utils.LoadPropertyLocation(property.Timezone)
+	if err != nil {
+		return stayQuote{}, err
+	}
+
+	start := localDate(checkInAt, location)
+	end := localDate(checkOutAt, location)
+	nights := int(end.Sub(start).Hours() / 24)
+	if nights < 1 {
+		return stayQuote{}, fmt.Errorf("%w: el check-out debe ser posterior al check-in", ErrInvalidStay)
+	}
+	if nights > maxBookingNights {
+		return stayQuote{}, fmt.Errorf("%w: la estadía no puede superar %d noches", ErrInvalidStay, maxBookingNights)
+	}
+	now := utils.NowUTC()
+	if start.Before(localDate(now, location)) {
+		return stayQuote{}, fmt.Errorf("%w: el check-in no puede ser anterior a hoy", ErrInvalidStay)
+	}
+
+	if err := s.checkAvailability(ctx, propertyID, start, end); err != nil {
+		return stayQuote{}, err
+	}
+
+	var coupon *domain.Coupon
+	if code := strings.TrimSpace(request.CouponCode); code != "" {
+		if coupon, err = s.coupons.Validate(ctx, code, userID, now); err != nil {
+			return stayQuote{}, err
+		}
+	}
+
+	return stayQuote{
+		property:  property,
+		checkIn:   checkInAt,
+		checkOut:  checkOutAt,
+		party:     party,
+		breakdown: s.buildPriceBreakdown(property, start, nights, party.Occupants(), coupon),
+		coupon:    coupon,
+	}, nil
+}
+
+// checkAvailability verifica que ninguna reserva activa ni bloqueo se superponga con [start, end)
+func (s *bookingService) checkAvailability(ctx context.Context, propertyID string, start, end time.Time) error {
+	ranges, err := s.calendar.GetAvailability(ctx, propertyID)
+	if err != nil {
+		return err
+	}
+
+	// Las fechas tienen formato YYYY-MM-DD y los fines son exclusivos: se comparan como strings
+	from, to := start.Format("2006-01-02"), end.Format("2006-01-02")
+	for _, occupied := range ranges {
+		if occupied.Start < to && from < occupied.End {
+			return fmt.Errorf("%w: ocupada del %s al %s", ErrPropertyUnavailable, occupied.Start, occupied.End)
+		}
+	}
+	return nil
+}
+
+// buildPriceBreakdown calcula el detalle del precio de nights noches desde start (día local, medianoche UTC)
+// Los occupants por encima de los incluidos en la propiedad pagan el cargo por huésped extra cada noche
+// El cupón (opcional) descuenta sobre noches + limpieza + huéspedes extra; la tarifa de servicio se aplica sobre
+// ese monto ya descontado y los impuestos sobre el monto descontado + servicio, con la tasa del país o región
+func (s *bookingService) buildPriceBreakdown(property domain.Property, start time.Time, nights, occupants int, coupon *domain.Coupon) domain.PriceBreakdown {
+	taxRate, jurisdiction := s.taxes.Lookup(property.Country, property.Region)
+	breakdown := domain.PriceBreakdown{
+		Nights:          make([]domain.NightlyPrice, nights),
+		ServiceFeeRate:  s.pricing.ServiceFeeRate,
+		TaxRate:         taxRate,
+		TaxJurisdiction: jurisdiction,
+	}
+
+	nightlyPrice := roundMoney(property.Price)
+	var subtotal float64
+	for i := range breakdown.Nights {
+		breakdown.Nights[i] = domain.NightlyPrice{
+			Date:  start.AddDate(0, 0, i).Format("2006-01-02"),
+			Price: nightlyPrice,
+		}
+		subtotal += nightlyPrice
+	}
+
+	breakdown.NightsSubtotal = roundMoney(subtotal)
+	breakdown.CleaningFee = roundMoney(property.CleaningFee)
+	if extra := extraGuests(property, occupants); extra > 0 {
+		breakdown.ExtraGuests = extra
+		breakdown.ExtraGuestFee = roundMoney(property.ExtraGuestFee)
+		breakdown.ExtraGuestFees = roundMoney(breakdown.ExtraGuestFee * float64(extra*nights))
+	}
+	charges := breakdown.NightsSubtotal + breakdown.CleaningFee + breakdown.ExtraGuestFees
+	if coupon != nil {
+		breakdown.CouponCode = coupon.Code
+		breakdown.Discount = roundMoney(couponDiscount(coupon, charges))
+	}
+
+	base := charges - breakdown.Discount
+	breakdown.ServiceFee = roundMoney(base * breakdown.ServiceFeeRate)
+	breakdown.Taxes = roundMoney((base + breakdown.ServiceFee) * breakdown.TaxRate)
+	breakdown.Total = roundMoney(base + breakdown.ServiceFee + breakdown.Taxes)
+	return breakdown
+}
+
+// extraGuests retorna cuántos occupants pagan el cargo por huésped extra (0 si la propiedad no lo cobra)
+func extraGuests(property domain.Property, occupants int) int {
+	if property.ExtraGuestFee <= 0 || property.GuestsIncluded < 1 || occupants <= property.GuestsIncluded {
+		return 0
+	}
+	return occupants - property.GuestsIncluded
+}
+
+// roundMoney redondea un importe a centavos
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// StreamOwnerBookings recorre las reservas de las propiedades de un owner
+// Primero obtiene los IDs de sus propiedades y luego recorre las reservas con un cursor
+func (s *bookingService) StreamOwnerBookings(ctx context.Context, ownerID string, fn func(dto.BookingDTO) error) error {
+	properties, err := s.propertyRepo.GetByOwnerID(ctx, ownerID)
+	if err != nil {
+		return fmt.Errorf("error obteniendo propiedades del owner: %w", err)
+	}
+
+	propertyIDs := make([]string, len(properties))
+	timezones := make(map[string]string, len(properties))
+	for i, property := range properties {
+		propertyIDs[i] = property.ID.Hex()
+		timezones[propertyIDs[i]] = property.Timezone
+	}
+
+	return s.bookingRepo.StreamByPropertyIDs(ctx, propertyIDs, func(booking domain.Booking) error {
+		return fn(toBookingDTO(booking, timezones[booking.PropertyID]))
+	})
+}
+
+// toBookingDTO convierte una reserva del dominio a BookingDTO
+// timezone es la zona horaria de la propiedad, usada para los horarios locales
+func toBookingDTO(booking domain.Booking, timezone string) dto.BookingDTO {
+	result := dto.BookingDTO{
+		ID:            booking.ID.Hex(),
+		PropertyID:    booking.PropertyID,
+		UserID:        booking.UserID,
+		CheckIn:       utils.FormatTimestamp(booking.CheckIn),
+		CheckOut:      utils.FormatTimestamp(booking.CheckOut),
+		CheckInLocal:  utils.FormatPropertyLocal(booking.CheckIn, timezone),
+		CheckOutLocal: utils.FormatPropertyLocal(booking.CheckOut, timezone),
+		Timezone:      timezone,
+		TotalPrice:    booking.TotalPrice,
+		Status:        booking.Status,
+		CreatedAt:     utils.FormatTimestamp(booking.CreatedAt),
+		Adults:        booking.Adults,
+		Children:      booking.Children,
+		Infants:       booking.Infants,
+	}
+	if booking.Status == domain.BookingPending && booking.ExpiresAt != nil {
+		result.ExpiresAt = utils.FormatTimestamp(*booking.ExpiresAt)
+	}
+	if booking.PriceBreakdown != nil {
+		breakdown := toPriceBreakdownDTO(*booking.PriceBreakdown)
+		result.PriceBreakdown = &breakdown
+	}
+	return result
+}
+
+// toPriceBreakdownDTO convierte el detalle del precio del dominio a PriceBreakdownDTO
+func toPriceBreakdownDTO(breakdown domain.PriceBreakdown) dto.PriceBreakdownDTO {
+	nights := make([]dto.NightlyPriceDTO, len(breakdown.Nights))
+	for i, night := range breakdown.Nights {
+		nights[i] = dto.NightlyPriceDTO{Date: night.Date, Price: night.Price}
+	}
+	return dto.PriceBreakdownDTO{
+		Nights:          nights,
+		NightsSubtotal:  breakdown.NightsSubtotal,
+		CleaningFee:     breakdown.CleaningFee,
+		ExtraGuests:     breakdown.ExtraGuests,
+		ExtraGuestFee:   breakdown.ExtraGuestFee,
+		ExtraGuestFees:  breakdown.ExtraGuestFees,
+		CouponCode:      breakdown.CouponCode,
+		Discount:        breakdown.Discount,
+		ServiceFeeRate:  breakdown.ServiceFeeRate,
+		ServiceFee:      breakdown.ServiceFee,
+		TaxRate:         breakdown.TaxRate,
+		TaxJurisdiction: breakdown.TaxJurisdiction,
+		Taxes:           breakdown.Taxes,
+		Total:           breakdown.Total,
+	}
+}
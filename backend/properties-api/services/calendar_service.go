@@ -0,0 +1,401 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"properties-api/clients"
+	"properties-api/domain"
+	"properties-api/dto"
+	"properties-api/repositories"
+	"properties-api/utils"
+)
+
+// ErrCalendarForbidden indica que el usuario no es owner de la propiedad ni admin
+var ErrCalendarForbidden = errors.New("solo el owner o un administrador pueden administrar el calendario de la propiedad")
+
+// ErrInvalidCalendarURL indica que la URL del calendario externo no es http o https
+var ErrInvalidCalendarURL = errors.New("la URL del calendario debe ser http o https")
+
+// calendarProdID identifica a Spotly como generador de los calendarios exportados
+const calendarProdID = "-//Spotly//Properties API//ES"
+
+// calendarUIDDomain es el dominio de los UID de los eventos exportados
+// Los eventos con este dominio se ignoran al importar para no reimportar nuestro propio calendario
+const calendarUIDDomain = "@spotly"
+
+// CalendarService exporta la disponibilidad de las propiedades en formato iCal
+// e importa calendarios externos como rangos bloqueados
+type CalendarService interface {
+	// ExportCalendar escribe en w el calendario iCal con las reservas y bloqueos de la propiedad
+	ExportCalendar(ctx context.Context, propertyID string, w io.Writer) error
+	// GetAvailability retorna los rangos ocupados de la propiedad (reservas activas y bloqueos) ordenados por fecha
+	GetAvailability(ctx context.Context, propertyID string) ([]dto.AvailabilityRangeDTO, error)
+	// ImportFeed registra un calendario externo y lo sincroniza inmediatamente
+	ImportFeed(ctx context.Context, propertyID, userID string, isAdmin bool, request dto.CalendarFeedImportDTO) (dto.CalendarFeedDTO, error)
+	// ListFeeds lista los calendarios externos de la propiedad
+	ListFeeds(ctx context.Context, propertyID, userID string, isAdmin bool) ([]dto.CalendarFeedDTO, error)
+	// DeleteFeed elimina un calendario externo y los bloqueos importados de él
+	DeleteFeed(ctx context.Context, propertyID, feedID, userID string, isAdmin bool) error
+	// BulkUpdateAvailability bloquea o abre varios rangos de fechas de una vez y retorna la disponibilidad resultante
+	BulkUpdateAvailability(ctx context.Context, propertyID, userID string, isAdmin bool, request dto.BulkAvailabilityUpdateDTO) ([]dto.AvailabilityRangeDTO, error)
+	// RefreshBookedRanges recalcula y guarda las fechas ocupadas de la propiedad y publica "availability.changed"
+	RefreshBookedRanges(ctx context.Context, propertyID, reason string)
+	// SyncAll sincroniza todos los calendarios externos y retorna cuántos se sincronizaron sin error
+	SyncAll(ctx context.Context) (int, error)
+}
+
+// calendarService es la implementación concreta de CalendarService
+type calendarService struct {
+	propertyRepo     repositories.PropertyRepository
+	bookingRepo      repositories.BookingRepository
+	availabilityRepo repositories.AvailabilityRepository
+	feedRepo         repositories.CalendarFeedRepository
+	feedClient       clients.CalendarFeedClient
+	rabbitClient     clients.RabbitMQClient
+}
+
+// NewCalendarService crea una nueva instancia del servicio de calendarios
+func NewCalendarService(
+	propertyRepo repositories.PropertyRepository,
+	bookingRepo repositories.BookingRepository,
+	availabilityRepo repositories.AvailabilityRepository,
+	feedRepo repositories.CalendarFeedRepository,
+	feedClient clients.CalendarFeedClient,
+	rabbitClient clients.RabbitMQClient,
+) CalendarService {
+	return &calendarService{
+		propertyRepo:     propertyRepo,
+		bookingRepo:      bookingRepo,
+		availabilityRepo: availabilityRepo,
+		feedRepo:         feedRepo,
+		feedClient:       feedClient,
+		rabbitClient:     rabbitClient,
+	}
+}
+
+// ExportCalendar escribe el calendario de la propiedad
+// Las reservas activas (pendientes o confirmadas) y los bloqueos se exportan como eventos de día completo
+// en las fechas locales de la propiedad (el check-out es el fin exclusivo)
+func (s *calendarService) ExportCalendar(ctx context.Context, propertyID string, w io.Writer) error {
+	property, err := s.propertyRepo.GetByID(ctx, propertyID)
+	if err != nil {
+		return fmt.Errorf("error obteniendo propiedad: %w", err)
+	}
+	location, err := utils.LoadPropertyLocation(property.Timezone)
+	if err != nil {
+		return err
+	}
+
+	var events []utils.ICalEvent
+	err = s.bookingRepo.StreamByPropertyIDs(ctx, []string{propertyID}, func(booking domain.Booking) error {
+		if !booking.IsActive() {
+			return nil
+		}
+		start, end := localDateRange(booking.CheckIn, booking.CheckOut, location)
+		events = append(events, utils.ICalEvent{
+			UID:     "booking-" + booking.ID.Hex() + calendarUIDDomain,
+			Summary: "Reservado",
+			Start:   start,
+			End:     end,
+			AllDay:  true,
+		})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error obteniendo reservas: %w", err)
+	}
+
+	blocks, err := s.availabilityRepo.FindByPropertyID(ctx, propertyID)
+	if err != nil {
+		return err
+	}
+	for _, block := range blocks {
+		events = append(events, utils.ICalEvent{
+			UID:     "block-" + block.ID.Hex() + calendarUIDDomain,
+			Summary: "Bloqueado",
+			Start:   block.Start,
+			End:     block.End,
+			AllDay:  true,
+		})
+	}
+
+	return utils.WriteICalendar(w, calendarProdID, property.Title, events)
+}
+
+// GetAvailability retorna los rangos ocupados de la propiedad con las mismas reglas que ExportCalendar
+func (s *calendarService) GetAvailability(ctx context.Context, propertyID string) ([]dto.AvailabilityRangeDTO, error) {
+	property, err := s.propertyRepo.GetByID(ctx, propertyID)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo propiedad: %w", err)
+	}
+	location, err := utils.LoadPropertyLocation(property.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	ranges := []dto.AvailabilityRangeDTO{}
+	err = s.bookingRepo.StreamByPropertyIDs(ctx, []string{propertyID}, func(booking domain.Booking) error {
+		if !booking.IsActive() {
+			return nil
+		}
+		start, end := localDateRange(booking.CheckIn, booking.CheckOut, location)
+		ranges = append(ranges, toAvailabilityRangeDTO(start, end, "booking"))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo reservas: %w", err)
+	}
+
+	blocks, err := s.availabilityRepo.FindByPropertyID(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	for _, block := range blocks {
+		source := "block"
+		if block.FeedID.IsZero() {
+			source = "manual"
+		}
+		ranges = append(ranges, toAvailabilityRangeDTO(block.Start, block.End, source))
+	}
+
+	// Las fechas tienen formato YYYY-MM-DD: el orden alfabético es el cronológico
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].Start < ranges[j].Start
+	})
+	return ranges, nil
+}
+
+// ImportFeed registra un calendario externo y lo sincroniza inmediatamente
+// Si la primera sincronización falla el calendario queda registrado con el error
+// y se reintenta en la sincronización periódica
+func (s *calendarService) ImportFeed(ctx context.Context, propertyID, userID string, isAdmin bool, request dto.CalendarFeedImportDTO) (dto.CalendarFeedDTO, error) {
+	if err := s.authorize(ctx, propertyID, userID, isAdmin); err != nil {
+		return dto.CalendarFeedDTO{}, err
+	}
+
+	feedURL, err := url.Parse(strings.TrimSpace(request.URL))
+	if err != nil || (feedURL.Scheme != "http" && feedURL.Scheme != "https") || feedURL.Host == "" {
+		return dto.CalendarFeedDTO{}, ErrInvalidCalendarURL
+	}
+
+	feed := domain.CalendarFeed{
+		PropertyID: propertyID,
+		Name:       strings.TrimSpace(request.Name),
+		URL:        feedURL.String(),
+		CreatedBy:  userID,
+		CreatedAt:  utils.NowUTC(),
+	}
+	if err := s.feedRepo.Create(ctx, &feed); err != nil {
+		return dto.CalendarFeedDTO{}, err
+	}
+
+	imported, err := s.syncFeed(ctx, &feed)
+	if err != nil {
+		log.Printf("⚠️ Error sincronizando calendario %s de la propiedad %s: %v", feed.ID.Hex(), propertyID, err)
+	}
+	return toCalendarFeedDTO(feed, imported), nil
+}
+
+// ListFeeds lista los calendarios externos de la propiedad con la cantidad de rangos importados
+func (s *calendarService) ListFeeds(ctx context.Context, propertyID, userID string, isAdmin bool) ([]dto.CalendarFeedDTO, error) {
+	if err := s.authorize(ctx, propertyID, userID, isAdmin); err != nil {
+		return nil, err
+	}
+
+	feeds, err := s.feedRepo.FindByPropertyID(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := s.availabilityRepo.FindByPropertyID(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+
+	blocksByFeed := make(map[string]int, len(feeds))
+	for _, block := range blocks {
+		blocksByFeed[block.FeedID.Hex()]++
+	}
+
+	response := make([]dto.CalendarFeedDTO, len(feeds))
+	for i, feed := range feeds {
+		response[i] = toCalendarFeedDTO(feed, blocksByFeed[feed.ID.Hex()])
+	}
+	return response, nil
+}
+
+// DeleteFeed elimina un calendario externo y los bloqueos importados de él
+func (s *calendarService) DeleteFeed(ctx context.Context, propertyID, feedID, userID string, isAdmin bool) error {
+	if err := s.authorize(ctx, propertyID, userID, isAdmin); err != nil {
+		return err
+	}
+
+	feed, err := s.feedRepo.FindByID(ctx, feedID)
+	if err != nil {
+		return err
+	}
+	if feed.PropertyID != propertyID {
+		return fmt.Errorf("calendario con ID '%s' no encontrado", feedID)
+	}
+
+	if err := s.availabilityRepo.ReplaceFeedBlocks(ctx, feed.ID, nil); err != nil {
+		return err
+	}
+	if err := s.feedRepo.Delete(ctx, feed.ID); err != nil {
+		return err
+	}
+	s.RefreshBookedRanges(ctx, propertyID, AvailabilityReasonFeed)
+	return nil
+}
+
+// SyncAll sincroniza todos los calendarios externos
+// Un calendario que falla no corta la sincronización de los demás: el error queda registrado en el calendario
+func (s *calendarService) SyncAll(ctx context.Context) (int, error) {
+	synced := 0
+	err := s.feedRepo.StreamAll(ctx, func(feed domain.CalendarFeed) error {
+		if _, err := s.syncFeed(ctx, &feed); err != nil {
+			log.Printf("⚠️ Error sincronizando calendario %s de la propiedad %s: %v", feed.ID.Hex(), feed.PropertyID, err)
+			return nil
+		}
+		synced++
+		return nil
+	})
+	return synced, err
+}
+
+// syncFeed descarga el calendario y reemplaza sus bloqueos
+// Retorna la cantidad de rangos bloqueados importados y deja registrado el resultado en el calendario
+func (s *calendarService) syncFeed(ctx context.Context, feed *domain.CalendarFeed) (int, error) {
+	blocks, err := s.fetchFeedBlocks(ctx, feed)
+	if err == nil {
+		err = s.availabilityRepo.ReplaceFeedBlocks(ctx, feed.ID, blocks)
+	}
+
+	if err != nil {
+		feed.LastError = err.Error()
+		if statusErr := s.feedRepo.UpdateSyncStatus(ctx, feed.ID, nil, feed.LastError); statusErr != nil {
+			log.Printf("⚠️ Error registrando estado del calendario %s: %v", feed.ID.Hex(), statusErr)
+		}
+		return 0, err
+	}
+
+	s.RefreshBookedRanges(ctx, feed.PropertyID, AvailabilityReasonFeed)
+
+	now := utils.NowUTC()
+	feed.LastSyncedAt = &now
+	feed.LastError = ""
+	if err := s.feedRepo.UpdateSyncStatus(ctx, feed.ID, &now, ""); err != nil {
+		return len(blocks), err
+	}
+	return len(blocks), nil
+}
+
+// fetchFeedBlocks descarga y parsea el calendario externo
+// Solo se importan los eventos que todavía no terminaron
+func (s *calendarService) fetchFeedBlocks(ctx context.Context, feed *domain.CalendarFeed) ([]domain.AvailabilityBlock, error) {
+	property, err := s.propertyRepo.GetByID(ctx, feed.PropertyID)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo propiedad: %w", err)
+	}
+	location, err := utils.LoadPropertyLocation(property.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := s.feedClient.Fetch(ctx, feed.URL)
+	if err != nil {
+		return nil, err
+	}
+	events, err := utils.ParseICalendar(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	now := utils.NowUTC()
+	today, _ := localDateRange(now, now, location)
+	blocks := make([]domain.AvailabilityBlock, 0, len(events))
+	for _, event := range events {
+		if strings.HasSuffix(event.UID, calendarUIDDomain) {
+			continue
+		}
+
+		start, end := event.Start, event.End
+		if !event.AllDay {
+			start, end = localDateRange(event.Start, event.End, location)
+		}
+		if !end.After(today) {
+			continue
+		}
+
+		blocks = append(blocks, domain.AvailabilityBlock{
+			PropertyID:  feed.PropertyID,
+			Start:       start,
+			End:         end,
+			FeedID:      feed.ID,
+			ExternalUID: event.UID,
+			Summary:     event.Summary,
+			CreatedAt:   now,
+		})
+	}
+	return blocks, nil
+}
+
+// authorize verifica que el usuario sea owner de la propiedad o admin
+func (s *calendarService) authorize(ctx context.Context, propertyID, userID string, isAdmin bool) error {
+	property, err := s.propertyRepo.GetByID(ctx, propertyID)
+	if err != nil {
+		return fmt.Errorf("error obteniendo propiedad: %w", err)
+	}
+	if !isAdmin && property.OwnerID != userID {
+		return ErrCalendarForbidden
+	}
+	return nil
+}
+
+// localDateRange convierte un rango de timestamps a fechas locales de la propiedad (medianoche UTC)
+// El fin es exclusivo: una estadía que empieza y termina el mismo día bloquea ese día
+func localDateRange(from, to time.Time, location *time.Location) (time.Time, time.Time) {
+	start := localDate(from, location)
+	end := localDate(to, location)
+	if !end.After(start) {
+		end = start.AddDate(0, 0, 1)
+	}
+	return start, end
+}
+
+// localDate retorna la fecha local de t en la zona de la propiedad como medianoche UTC
+func localDate(t time.Time, location *time.Location) time.Time {
+	local := t.In(location)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// toAvailabilityRangeDTO convierte un rango de días (medianoche UTC, ver localDate) a AvailabilityRangeDTO
+func toAvailabilityRangeDTO(start, end time.Time, source string) dto.AvailabilityRangeDTO {
+	return dto.AvailabilityRangeDTO{
+		Start:  start.Format("2006-01-02"),
+		End:    end.Format("2006-01-02"),
+		Source: source,
+	}
+}
+
+// toCalendarFeedDTO convierte un calendario externo del dominio a CalendarFeedDTO
+func toCalendarFeedDTO(feed domain.CalendarFeed, blockedRanges int) dto.CalendarFeedDTO {
+	return dto.CalendarFeedDTO{
+		ID:            feed.ID.Hex(),
+		PropertyID:    feed.PropertyID,
+		Name:          feed.Name,
+		URL:           feed.URL,
+		LastSyncedAt:  feed.LastSyncedAt,
+		LastError:     feed.LastError,
+		BlockedRanges: blockedRanges,
+		CreatedAt:     feed.CreatedAt,
+	}
+}
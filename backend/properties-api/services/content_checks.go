@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"properties-api/clients"
+)
+
+// Nombres de los checks de moderación
+const (
+	checkBlockedWords = "blocked_words"
+	checkContactInfo  = "contact_info"
+	checkExternal     = "external"
+)
+
+// ContentCheck revisa un texto antes de publicarlo
+type ContentCheck interface {
+	// Name identifica al check en los casos de moderación
+	Name() string
+	// Check retorna los motivos por los que el texto se retiene (vacío = se puede publicar)
+	Check(ctx context.Context, text string) ([]string, error)
+}
+
+// accentFolder quita los acentos para comparar palabras sin importar cómo se escribieron
+var accentFolder = strings.NewReplacer(
+	"á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u",
+	"à", "a", "è", "e", "ì", "i", "ò", "o", "ù", "u",
+)
+
+// normalizeWords pasa el texto a minúsculas sin acentos y separa las palabras con un espacio
+// El resultado empieza y termina con espacio para buscar palabras o frases completas
+func normalizeWords(text string) string {
+	folded := accentFolder.Replace(strings.ToLower(text))
+	words := strings.FieldsFunc(folded, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return " " + strings.Join(words, " ") + " "
+}
+
+// blockedWordsCheck retiene los textos que contienen alguna palabra o frase de la lista
+type blockedWordsCheck struct {
+	terms []string // Normalizados con normalizeWords
+}
+
+// NewBlockedWordsCheck crea el check de palabras bloqueadas; retorna nil si la lista está vacía
+func NewBlockedWordsCheck(words []string) ContentCheck {
+	var terms []string
+	for _, word := range words {
+		if term := normalizeWords(word); strings.TrimSpace(term) != "" {
+			terms = append(terms, term)
+		}
+	}
+	if len(terms) == 0 {
+		return nil
+	}
+	return &blockedWordsCheck{terms: terms}
+}
+
+// Name implementa ContentCheck.Name
+func (c *blockedWordsCheck) Name() string {
+	return checkBlockedWords
+}
+
+// Check busca las palabras completas (ej: "casa" no coincide con "casamiento")
+func (c *blockedWordsCheck) Check(ctx context.Context, text string) ([]string, error) {
+	normalized := normalizeWords(text)
+	var reasons []string
+	for _, term := range c.terms {
+		if strings.Contains(normalized, term) {
+			reasons = append(reasons, "contiene la palabra bloqueada '"+strings.TrimSpace(term)+"'")
+		}
+	}
+	return reasons, nil
+}
+
+// Patrones de datos de contacto: el contacto entre huésped y anfitrión tiene que pasar por la plataforma
+var (
+	emailPattern     = regexp.MustCompile(`(?i)[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}`)
+	phonePattern     = regexp.MustCompile(`\+?\d(?:[\s().-]*\d){7,}`)
+	linkPattern      = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+|\b[a-z0-9-]+\.(?:com|net|org|ar|es|io)(?:/\S*)?\b`)
+	messagingPattern = regexp.MustCompile(`(?i)\b(?:whats\s?app|wsp|telegram|wa\.me|t\.me|signal)\b`)
+)
+
+// contactInfoCheck retiene los textos con emails, teléfonos, enlaces o apps de mensajería
+type contactInfoCheck struct{}
+
+// NewContactInfoCheck crea el check de datos de contacto
+func NewContactInfoCheck() ContentCheck {
+	return contactInfoCheck{}
+}
+
+// Name implementa ContentCheck.Name
+func (contactInfoCheck) Name() string {
+	return checkContactInfo
+}
+
+// Check busca datos de contacto en el texto
+func (contactInfoCheck) Check(ctx context.Context, text string) ([]string, error) {
+	var reasons []string
+	if emailPattern.MatchString(text) {
+		reasons = append(reasons, "contiene un email")
+	}
+	if phonePattern.MatchString(text) {
+		reasons = append(reasons, "contiene un número de teléfono")
+	}
+	if linkPattern.MatchString(text) {
+		reasons = append(reasons, "contiene un enlace externo")
+	}
+	if messagingPattern.MatchString(text) {
+		reasons = append(reasons, "menciona una app de mensajería")
+	}
+	return reasons, nil
+}
+
+// externalCheck delega la revisión en la API externa de moderación
+type externalCheck struct {
+	client clients.ModerationClient
+}
+
+// NewExternalCheck crea el check que consulta la API externa
+func NewExternalCheck(client clients.ModerationClient) ContentCheck {
+	return &externalCheck{client: client}
+}
+
+// Name implementa ContentCheck.Name
+func (c *externalCheck) Name() string {
+	return checkExternal
+}
+
+// Check consulta la API; si marca el texto sin motivos se usa uno genérico
+func (c *externalCheck) Check(ctx context.Context, text string) ([]string, error) {
+	verdict, err := c.client.Check(ctx, text)
+	if err != nil || !verdict.Flagged {
+		return nil, err
+	}
+	if len(verdict.Reasons) == 0 {
+		return []string{"marcado por la API de moderación"}, nil
+	}
+	return verdict.Reasons, nil
+}
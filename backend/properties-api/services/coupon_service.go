@@ -0,0 +1,276 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"properties-api/domain"
+	"properties-api/dto"
+	"properties-api/repositories"
+	"properties-api/utils"
+)
+
+// ErrCouponNotFound indica que no existe un cupón con el código ingresado
+var ErrCouponNotFound = errors.New("cupón no encontrado")
+
+// ErrCouponNotApplicable indica que el cupón está desactivado o fuera de su período de validez
+var ErrCouponNotApplicable = errors.New("el cupón no está vigente")
+
+// ErrCouponExhausted indica que el cupón alcanzó su límite de usos (total o del usuario)
+var ErrCouponExhausted = errors.New("el cupón alcanzó su límite de usos")
+
+// ErrInvalidCoupon indica datos inválidos al crear un cupón
+var ErrInvalidCoupon = errors.New("cupón inválido")
+
+// maxCouponRedemptionsListed es la cantidad máxima de usos que se listan de un cupón
+const maxCouponRedemptionsListed = 500
+
+// CouponService administra los cupones promocionales (solo admin) y los aplica en las reservas
+type CouponService interface {
+	// CreateCoupon crea un cupón; el código se guarda en mayúsculas
+	CreateCoupon(ctx context.Context, adminID string, request dto.CouponCreateDTO) (dto.CouponDTO, error)
+	// ListCoupons lista todos los cupones, el más reciente primero
+	ListCoupons(ctx context.Context) ([]dto.CouponDTO, error)
+	// DeactivateCoupon desactiva un cupón; los usos registrados se conservan
+	DeactivateCoupon(ctx context.Context, adminID, code string) error
+	// GetRedemptions obtiene el cupón y sus usos
+	GetRedemptions(ctx context.Context, code string) (dto.CouponRedemptionsDTO, error)
+
+	// Validate verifica que el cupón exista, esté vigente en now y tenga usos disponibles
+	// Con userID vacío (cotización anónima) no se verifica el límite por usuario
+	Validate(ctx context.Context, code, userID string, now time.Time) (*domain.Coupon, error)
+	// Reserve toma un uso del cupón de forma atómica al crear una reserva
+	Reserve(ctx context.Context, coupon *domain.Coupon, now time.Time) error
+	// Lookup obtiene un cupón por código sin validar que esté vigente (ej: al aprobar una solicitud que ya lo reservó)
+	Lookup(ctx context.Context, code string) (*domain.Coupon, error)
+	// Release devuelve el uso tomado con Reserve si la reserva no se pudo guardar o la solicitud no se aprobó
+	Release(ctx context.Context, coupon *domain.Coupon)
+	// RecordRedemption registra el uso del cupón en la reserva
+	RecordRedemption(ctx context.Context, coupon *domain.Coupon, bookingID, userID string, discount float64)
+}
+
+// couponService es la implementación concreta de CouponService
+type couponService struct {
+	repo  repositories.CouponRepository
+	audit AuditService
+}
+
+// NewCouponService crea una nueva instancia del servicio de cupones
+func NewCouponService(repo repositories.CouponRepository, audit AuditService) CouponService {
+	return &couponService{
+		repo:  repo,
+		audit: audit,
+	}
+}
+
+// CreateCoupon valida y guarda un cupón nuevo
+func (s *couponService) CreateCoupon(ctx context.Context, adminID string, request dto.CouponCreateDTO) (dto.CouponDTO, error) {
+	code := strings.ToUpper(strings.TrimSpace(request.Code))
+	if strings.ContainsAny(code, " \t\n") {
+		return dto.CouponDTO{}, fmt.Errorf("%w: el código no puede tener espacios", ErrInvalidCoupon)
+	}
+	if request.Type == domain.CouponPercentage && request.Value > 100 {
+		return dto.CouponDTO{}, fmt.Errorf("%w: un porcentaje no puede superar 100", ErrInvalidCoupon)
+	}
+	if request.ValidFrom != nil && request.ValidUntil != nil && !request.ValidFrom.Before(*request.ValidUntil) {
+		return dto.CouponDTO{}, fmt.Errorf("%w: validFrom debe ser anterior a validUntil", ErrInvalidCoupon)
+	}
+
+	coupon := domain.Coupon{
+		Code:           code,
+		Type:           request.Type,
+		Value:          request.Value,
+		MaxRedemptions: request.MaxRedemptions,
+		MaxPerUser:     request.MaxPerUser,
+		ValidFrom:      utcTimePtr(request.ValidFrom),
+		ValidUntil:     utcTimePtr(request.ValidUntil),
+		Active:         true,
+		CreatedBy:      adminID,
+		CreatedAt:      utils.NowUTC(),
+	}
+	if err := s.repo.Create(ctx, &coupon); err != nil {
+		return dto.CouponDTO{}, err
+	}
+
+	response := toCouponDTO(coupon)
+	s.audit.Record(ctx, adminID, AuditActionCouponCreate, auditEntityCoupon, coupon.Code, nil, response)
+	return response, nil
+}
+
+// ListCoupons lista todos los cupones
+func (s *couponService) ListCoupons(ctx context.Context) ([]dto.CouponDTO, error) {
+	coupons, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.CouponDTO, len(coupons))
+	for i, coupon := range coupons {
+		responses[i] = toCouponDTO(coupon)
+	}
+	return responses, nil
+}
+
+// DeactivateCoupon desactiva un cupón
+func (s *couponService) DeactivateCoupon(ctx context.Context, adminID, code string) error {
+	coupon, err := s.findByCode(ctx, code)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.SetActive(ctx, coupon.Code, false); err != nil {
+		return err
+	}
+
+	before := toCouponDTO(*coupon)
+	after := before
+	after.Active = false
+	s.audit.Record(ctx, adminID, AuditActionCouponDeactivate, auditEntityCoupon, coupon.Code, before, after)
+	return nil
+}
+
+// GetRedemptions obtiene el cupón y sus usos
+func (s *couponService) GetRedemptions(ctx context.Context, code string) (dto.CouponRedemptionsDTO, error) {
+	coupon, err := s.findByCode(ctx, code)
+	if err != nil {
+		return dto.CouponRedemptionsDTO{}, err
+	}
+	redemptions, err := s.repo.FindRedemptions(ctx, coupon.ID, maxCouponRedemptionsListed)
+	if err != nil {
+		return dto.CouponRedemptionsDTO{}, err
+	}
+
+	responses := make([]dto.CouponRedemptionDTO, len(redemptions))
+	for i, redemption := range redemptions {
+		responses[i] = dto.CouponRedemptionDTO{
+			BookingID:  redemption.BookingID,
+			UserID:     redemption.UserID,
+			Discount:   redemption.Discount,
+			RedeemedAt: redemption.RedeemedAt,
+		}
+	}
+	return dto.CouponRedemptionsDTO{Coupon: toCouponDTO(*coupon), Redemptions: responses}, nil
+}
+
+// Validate verifica que el cupón se pueda usar en now
+func (s *couponService) Validate(ctx context.Context, code, userID string, now time.Time) (*domain.Coupon, error) {
+	coupon, err := s.findByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if !coupon.Active {
+		return nil, fmt.Errorf("%w: '%s' está desactivado", ErrCouponNotApplicable, coupon.Code)
+	}
+	if coupon.ValidFrom != nil && now.Before(*coupon.ValidFrom) {
+		return nil, fmt.Errorf("%w: '%s' se puede usar desde %s", ErrCouponNotApplicable, coupon.Code, utils.FormatTimestamp(*coupon.ValidFrom))
+	}
+	if coupon.ValidUntil != nil && !now.Before(*coupon.ValidUntil) {
+		return nil, fmt.Errorf("%w: '%s' venció el %s", ErrCouponNotApplicable, coupon.Code, utils.FormatTimestamp(*coupon.ValidUntil))
+	}
+	if coupon.MaxRedemptions > 0 && coupon.Redemptions >= coupon.MaxRedemptions {
+		return nil, ErrCouponExhausted
+	}
+
+	if userID != "" && coupon.MaxPerUser > 0 {
+		used, err := s.repo.CountUserRedemptions(ctx, coupon.ID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if used >= coupon.MaxPerUser {
+			return nil, fmt.Errorf("%w (máximo %d por huésped)", ErrCouponExhausted, coupon.MaxPerUser)
+		}
+	}
+	return coupon, nil
+}
+
+// Reserve toma un uso del cupón; falla si otra reserva tomó el último uso o venció entre tanto
+func (s *couponService) Reserve(ctx context.Context, coupon *domain.Coupon, now time.Time) error {
+	reserved, err := s.repo.Reserve(ctx, coupon.ID, now)
+	if err != nil {
+		return err
+	}
+	if !reserved {
+		return ErrCouponExhausted
+	}
+	return nil
+}
+
+// Lookup obtiene un cupón o ErrCouponNotFound
+func (s *couponService) Lookup(ctx context.Context, code string) (*domain.Coupon, error) {
+	return s.findByCode(ctx, code)
+}
+
+// Release devuelve un uso del cupón; un error solo se loguea (el contador queda un uso arriba)
+func (s *couponService) Release(ctx context.Context, coupon *domain.Coupon) {
+	if err := s.repo.Release(ctx, coupon.ID); err != nil {
+		log.Printf("⚠️ Error liberando el uso del cupón %s: %v", coupon.Code, err)
+	}
+}
+
+// RecordRedemption registra el uso; un error solo se loguea porque la reserva ya está guardada
+// (el contador del cupón ya se incrementó en Reserve)
+func (s *couponService) RecordRedemption(ctx context.Context, coupon *domain.Coupon, bookingID, userID string, discount float64) {
+	redemption := domain.CouponRedemption{
+		CouponID:   coupon.ID,
+		Code:       coupon.Code,
+		BookingID:  bookingID,
+		UserID:     userID,
+		Discount:   discount,
+		RedeemedAt: utils.NowUTC(),
+	}
+	if err := s.repo.CreateRedemption(ctx, &redemption); err != nil {
+		log.Printf("⚠️ Error registrando el uso del cupón %s en la reserva %s: %v", coupon.Code, bookingID, err)
+	}
+}
+
+// findByCode obtiene un cupón o ErrCouponNotFound
+func (s *couponService) findByCode(ctx context.Context, code string) (*domain.Coupon, error) {
+	coupon, err := s.repo.FindByCode(ctx, strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		return nil, err
+	}
+	if coupon == nil {
+		return nil, fmt.Errorf("%w: '%s'", ErrCouponNotFound, code)
+	}
+	return coupon, nil
+}
+
+// couponDiscount calcula el descuento del cupón sobre amount; nunca supera amount
+func couponDiscount(coupon *domain.Coupon, amount float64) float64 {
+	discount := coupon.Value
+	if coupon.Type == domain.CouponPercentage {
+		discount = amount * coupon.Value / 100
+	}
+	return math.Min(discount, amount)
+}
+
+// utcTimePtr normaliza a UTC un timestamp opcional
+func utcTimePtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+// toCouponDTO convierte un cupón del dominio a CouponDTO
+func toCouponDTO(coupon domain.Coupon) dto.CouponDTO {
+	return dto.CouponDTO{
+		ID:             coupon.ID.Hex(),
+		Code:           coupon.Code,
+		Type:           coupon.Type,
+		Value:          coupon.Value,
+		MaxRedemptions: coupon.MaxRedemptions,
+		MaxPerUser:     coupon.MaxPerUser,
+		Redemptions:    coupon.Redemptions,
+		ValidFrom:      coupon.ValidFrom,
+		ValidUntil:     coupon.ValidUntil,
+		Active:         coupon.Active,
+		CreatedBy:      coupon.CreatedBy,
+		CreatedAt:      coupon.CreatedAt,
+	}
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"properties-api/clients"
+	"properties-api/dto"
+	"properties-api/repositories"
+	"properties-api/utils"
+)
+
+// DuplicateService permite a los administradores revisar las propiedades marcadas como probable duplicado
+type DuplicateService interface {
+	// ListFlagged lista las propiedades marcadas junto con la propiedad original
+	ListFlagged(ctx context.Context) ([]dto.DuplicateReviewDTO, error)
+	// Merge fusiona la propiedad duplicada con la original y elimina el duplicado
+	Merge(ctx context.Context, duplicateID string, adminID string) (dto.PropertyResponseDTO, error)
+	// Dismiss quita la marca de duplicado (la propiedad no es un duplicado)
+	Dismiss(ctx context.Context, duplicateID string, adminID string) error
+}
+
+// duplicateService es la implementación concreta de DuplicateService
+type duplicateService struct {
+	propertyRepo repositories.PropertyRepository
+	bookingRepo  repositories.BookingRepository
+	rabbitClient clients.RabbitMQClient
+	audit        AuditService
+}
+
+// NewDuplicateService crea una nueva instancia del servicio de duplicados
+func NewDuplicateService(
+	propertyRepo repositories.PropertyRepository,
+	bookingRepo repositories.BookingRepository,
+	rabbitClient clients.RabbitMQClient,
+	audit AuditService,
+) DuplicateService {
+	return &duplicateService{
+		propertyRepo: propertyRepo,
+		bookingRepo:  bookingRepo,
+		rabbitClient: rabbitClient,
+		audit:        audit,
+	}
+}
+
+// ListFlagged lista las propiedades marcadas junto con la propiedad original
+// Si la original ya no existe se lista igual para que el admin quite la marca
+func (s *duplicateService) ListFlagged(ctx context.Context) ([]dto.DuplicateReviewDTO, error) {
+	flagged, err := s.propertyRepo.FindFlaggedDuplicates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reviews := make([]dto.DuplicateReviewDTO, len(flagged))
+	for i, property := range flagged {
+		reviews[i] = dto.DuplicateReviewDTO{Duplicate: toPropertyDTO(property)}
+		if original, err := s.propertyRepo.GetByID(ctx, property.DuplicateOf); err == nil {
+			originalDTO := toPropertyDTO(original)
+			reviews[i].Original = &originalDTO
+			reviews[i].TitleSimilarity = utils.TitleSimilarity(original.Title, property.Title)
+		}
+	}
+	return reviews, nil
+}
+
+// Merge fusiona la propiedad duplicada con la original:
+// 1. Mueve las reservas del duplicado (y sus noches tomadas) a la original
+// 2. Agrega a la original las imágenes y amenities que solo tenía el duplicado
+// 3. Elimina el duplicado y publica los eventos para reindexar
+func (s *duplicateService) Merge(ctx context.Context, duplicateID string, adminID string) (dto.PropertyResponseDTO, error) {
+	duplicate, err := s.propertyRepo.GetByID(ctx, duplicateID)
+	if err != nil {
+		return dto.PropertyResponseDTO{}, fmt.Errorf("error obteniendo propiedad duplicada: %w", err)
+	}
+	if duplicate.DuplicateOf == "" {
+		return dto.PropertyResponseDTO{}, fmt.Errorf("la propiedad '%s' no está marcada como duplicado", duplicateID)
+	}
+	original, err := s.propertyRepo.GetByID(ctx, duplicate.DuplicateOf)
+	if err != nil {
+		return dto.PropertyResponseDTO{}, fmt.Errorf("error obteniendo propiedad original: %w", err)
+	}
+
+	// Las reservas van primero: si las dos propiedades tienen noches en común no se modifica nada
+	moved, err := s.bookingRepo.ReplacePropertyID(ctx, duplicateID, original.ID.Hex())
+	if err != nil {
+		return dto.PropertyResponseDTO{}, fmt.Errorf("error moviendo reservas del duplicado: %w", err)
+	}
+
+	before := toPropertyDTO(original)
+	original.Images = mergePhotos(original.Images, duplicate.Images)
+	original.Amenities = mergeUnique(original.Amenities, duplicate.Amenities)
+	original.UpdatedAt = utils.NowUTC()
+	if err := s.propertyRepo.Update(ctx, original.ID.Hex(), original); err != nil {
+		return dto.PropertyResponseDTO{}, fmt.Errorf("error actualizando propiedad original: %w", err)
+	}
+
+	if err := s.propertyRepo.Delete(ctx, duplicateID); err != nil {
+		return dto.PropertyResponseDTO{}, fmt.Errorf("error eliminando propiedad duplicada: %w", err)
+	}
+
+	merged := toPropertyDTO(original)
+	s.audit.Record(ctx, adminID, AuditActionPropertyMerge, auditEntityProperty, original.ID.Hex(), before, merged)
+	s.audit.Record(ctx, adminID, AuditActionPropertyDelete, auditEntityProperty, duplicateID, toPropertyDTO(duplicate), nil)
+	log.Printf("🔀 Propiedad %s fusionada con %s (%d reservas movidas)", duplicateID, original.ID.Hex(), moved)
+
+	if err := s.rabbitClient.PublishPropertySnapshotEvent("update", merged); err != nil {
+		log.Printf("⚠️ Error publicando evento 'update' en RabbitMQ para propiedad %s: %v", merged.ID, err)
+	}
+	if err := s.rabbitClient.PublishPropertyEvent("delete", duplicateID); err != nil {
+		log.Printf("⚠️ Error publicando evento 'delete' en RabbitMQ para propiedad %s: %v", duplicateID, err)
+	}
+
+	return merged, nil
+}
+
+// Dismiss quita la marca de duplicado (la propiedad no es un duplicado)
+func (s *duplicateService) Dismiss(ctx context.Context, duplicateID string, adminID string) error {
+	duplicate, err := s.propertyRepo.GetByID(ctx, duplicateID)
+	if err != nil {
+		return fmt.Errorf("error obteniendo propiedad: %w", err)
+	}
+	if duplicate.DuplicateOf == "" {
+		return fmt.Errorf("la propiedad '%s' no está marcada como duplicado", duplicateID)
+	}
+
+	if err := s.propertyRepo.ClearDuplicateFlag(ctx, duplicateID); err != nil {
+		return err
+	}
+
+	before := toPropertyDTO(duplicate)
+	duplicate.DuplicateOf = ""
+	s.audit.Record(ctx, adminID, AuditActionPropertyDuplicateDismiss, auditEntityProperty, duplicateID, before, toPropertyDTO(duplicate))
+	return nil
+}
+
+// mergeUnique agrega a base los valores de extra que no tenga, manteniendo el orden
+func mergeUnique(base, extra []string) []string {
+	seen := make(map[string]bool, len(base)+len(extra))
+	merged := make([]string, 0, len(base)+len(extra))
+	for _, value := range append(append([]string{}, base...), extra...) {
+		if !seen[value] {
+			seen[value] = true
+			merged = append(merged, value)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"properties-api/clients"
+	"properties-api/domain"
+	"properties-api/dto"
+	"properties-api/repositories"
+	"properties-api/utils"
+)
+
+// maxEventReplayWindow es el rango máximo que se republica en un request
+// Rangos más largos se hacen en varias llamadas (o con la reconciliación de search-api)
+const maxEventReplayWindow = 31 * 24 * time.Hour
+
+// maxReplayFailedIDs es la cantidad de IDs con error que se devuelven en el resultado
+const maxReplayFailedIDs = 100
+
+// ErrReplayWindowTooLarge se retorna cuando el rango supera maxEventReplayWindow
+var ErrReplayWindowTooLarge = fmt.Errorf("el rango a republicar no puede superar %d días", int(maxEventReplayWindow.Hours()/24))
+
+// EventReplayService republica los eventos de las propiedades para recuperar a los consumidores
+// (ej: search-api después de una caída de RabbitMQ o del consumidor)
+type EventReplayService interface {
+	// Replay republica el snapshot de las propiedades publicadas modificadas en el rango
+	Replay(ctx context.Context, adminID string, request dto.EventReplayRequestDTO) (dto.EventReplayResultDTO, error)
+}
+
+// eventReplayService es la implementación concreta de EventReplayService
+type eventReplayService struct {
+	propertyRepo repositories.PropertyRepository
+	rabbitClient clients.RabbitMQClient
+	audit        AuditService
+}
+
+// NewEventReplayService crea una nueva instancia del servicio de republicación de eventos
+func NewEventReplayService(propertyRepo repositories.PropertyRepository, rabbitClient clients.RabbitMQClient, audit AuditService) EventReplayService {
+	return &eventReplayService{
+		propertyRepo: propertyRepo,
+		rabbitClient: rabbitClient,
+		audit:        audit,
+	}
+}
+
+// Replay recorre desde MongoDB las propiedades con updatedAt en [from, to) y publica un evento por cada una:
+// "create" si se creó dentro del rango (el consumidor pudo no haberla visto nunca) y "update" si no
+// Las propiedades no publicadas se saltean igual que en el alta y la edición. Las eliminaciones no se
+// pueden republicar (se borran de MongoDB): la reconciliación de search-api elimina esos documentos
+func (s *eventReplayService) Replay(ctx context.Context, adminID string, request dto.EventReplayRequestDTO) (dto.EventReplayResultDTO, error) {
+	from, to, err := parseExportRange(request.From, request.To)
+	if err != nil {
+		return dto.EventReplayResultDTO{}, err
+	}
+	if to.IsZero() {
+		to = utils.NowUTC()
+	}
+	if !from.Before(to) {
+		return dto.EventReplayResultDTO{}, ErrInvalidDateRange
+	}
+	if to.Sub(from) > maxEventReplayWindow {
+		return dto.EventReplayResultDTO{}, ErrReplayWindowTooLarge
+	}
+
+	result := dto.EventReplayResultDTO{From: from, To: to, DryRun: request.DryRun, FailedIDs: []string{}}
+	filter := repositories.PropertyFilter{UpdatedFrom: from, UpdatedTo: to}
+	err = s.propertyRepo.StreamFiltered(ctx, filter, func(property domain.Property) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		result.Matched++
+		if !property.IsPublished() {
+			result.Skipped++
+			return nil
+		}
+
+		operation := "update"
+		if !property.CreatedAt.Before(from) {
+			operation = "create"
+		}
+		if !request.DryRun {
+			if err := s.rabbitClient.PublishPropertySnapshotEvent(operation, toPropertyDTO(property)); err != nil {
+				log.Printf("⚠️ Error republicando evento '%s' de la propiedad %s: %v", operation, property.ID.Hex(), err)
+				result.Failed++
+				if len(result.FailedIDs) < maxReplayFailedIDs {
+					result.FailedIDs = append(result.FailedIDs, property.ID.Hex())
+				}
+				return nil
+			}
+		}
+
+		if operation == "create" {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("⚠️ Republicación de eventos interrumpida después de %d propiedades: %v", result.Matched, err)
+		return dto.EventReplayResultDTO{}, fmt.Errorf("error recorriendo propiedades modificadas: %w", err)
+	}
+
+	log.Printf("🔁 Eventos republicados (%s a %s): %d create, %d update, %d no publicadas, %d con error",
+		from.Format(time.RFC3339), to.Format(time.RFC3339), result.Created, result.Updated, result.Skipped, result.Failed)
+	if !request.DryRun {
+		s.audit.Record(ctx, adminID, AuditActionEventReplay, auditEntityPropertyEvents, "", nil, result)
+	}
+	return result, nil
+}
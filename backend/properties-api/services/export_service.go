@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"properties-api/domain"
+	"properties-api/dto"
+	"properties-api/repositories"
+)
+
+// ExportService recorre propiedades y reservas con filtros para los exports de administración
+// Los registros se entregan uno a uno leyendo de un cursor, sin cargar el resultado en memoria
+type ExportService interface {
+	// StreamProperties recorre las propiedades que cumplen los filtros
+	StreamProperties(ctx context.Context, query dto.PropertyExportQuery, fn func(dto.PropertyResponseDTO) error) error
+	// StreamBookings recorre las reservas que cumplen los filtros
+	StreamBookings(ctx context.Context, query dto.BookingExportQuery, fn func(dto.BookingDTO) error) error
+}
+
+// exportService es la implementación concreta de ExportService
+type exportService struct {
+	propertyRepo repositories.PropertyRepository
+	bookingRepo  repositories.BookingRepository
+}
+
+// NewExportService crea una nueva instancia del servicio de exports
+func NewExportService(propertyRepo repositories.PropertyRepository, bookingRepo repositories.BookingRepository) ExportService {
+	return &exportService{
+		propertyRepo: propertyRepo,
+		bookingRepo:  bookingRepo,
+	}
+}
+
+// StreamProperties recorre las propiedades que cumplen los filtros
+func (s *exportService) StreamProperties(ctx context.Context, query dto.PropertyExportQuery, fn func(dto.PropertyResponseDTO) error) error {
+	from, to, err := parseExportRange(query.From, query.To)
+	if err != nil {
+		return err
+	}
+
+	filter := repositories.PropertyFilter{
+		OwnerID:      query.OwnerID,
+		PropertyType: strings.ToLower(strings.TrimSpace(query.PropertyType)),
+		Available:    query.Available,
+		CreatedFrom:  from,
+		CreatedTo:    to,
+	}
+	return s.propertyRepo.StreamFiltered(ctx, filter, func(property domain.Property) error {
+		return fn(toPropertyDTO(property))
+	})
+}
+
+// StreamBookings recorre las reservas que cumplen los filtros
+// La zona horaria de cada propiedad se busca una sola vez para los horarios locales
+func (s *exportService) StreamBookings(ctx context.Context, query dto.BookingExportQuery, fn func(dto.BookingDTO) error) error {
+	from, to, err := parseExportRange(query.From, query.To)
+	if err != nil {
+		return err
+	}
+
+	filter := repositories.BookingFilter{
+		PropertyID:  query.PropertyID,
+		UserID:      query.UserID,
+		Status:      query.Status,
+		CheckInFrom: from,
+		CheckInTo:   to,
+	}
+
+	timezones := make(map[string]string)
+	err = s.bookingRepo.StreamFiltered(ctx, filter, func(booking domain.Booking) error {
+		timezone, cached := timezones[booking.PropertyID]
+		if !cached {
+			if property, err := s.propertyRepo.GetByID(ctx, booking.PropertyID); err == nil {
+				timezone = property.Timezone
+			}
+			timezones[booking.PropertyID] = timezone
+		}
+		return fn(toBookingDTO(booking, timezone))
+	})
+	if err != nil {
+		return fmt.Errorf("error recorriendo reservas: %w", err)
+	}
+	return nil
+}
+
+// parseExportRange parsea el rango from/to de los filtros (mismo formato que el log de auditoría)
+func parseExportRange(fromValue, toValue string) (time.Time, time.Time, error) {
+	from, err := parseAuditTime(fromValue)
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidDateRange
+	}
+	to, err := parseAuditTime(toValue)
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidDateRange
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return time.Time{}, time.Time{}, ErrInvalidDateRange
+	}
+	return from, to, nil
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"properties-api/clients"
+	"properties-api/dto"
+	"properties-api/repositories"
+)
+
+// ErrHostNotFound indica que el anfitrión de la propiedad ya no existe o desactivó su cuenta
+var ErrHostNotFound = errors.New("el anfitrión de la propiedad no está disponible")
+
+// ErrHostProfileUnavailable indica que users-api no respondió (caído o con el circuito abierto)
+var ErrHostProfileUnavailable = errors.New("no se pudo obtener el perfil del anfitrión")
+
+// HostService obtiene el perfil público del anfitrión de una propiedad
+type HostService interface {
+	// GetPropertyHost obtiene el perfil del owner de la propiedad desde users-api
+	GetPropertyHost(ctx context.Context, propertyID string) (dto.HostProfileResponseDTO, error)
+}
+
+// hostService es la implementación concreta de HostService
+type hostService struct {
+	propertyRepo repositories.PropertyRepository
+	profiles     clients.HostProfileClient
+}
+
+// NewHostService crea una nueva instancia del servicio de anfitriones
+func NewHostService(propertyRepo repositories.PropertyRepository, profiles clients.HostProfileClient) HostService {
+	return &hostService{
+		propertyRepo: propertyRepo,
+		profiles:     profiles,
+	}
+}
+
+// GetPropertyHost obtiene el perfil del anfitrión; las propiedades no publicadas se tratan como inexistentes
+func (s *hostService) GetPropertyHost(ctx context.Context, propertyID string) (dto.HostProfileResponseDTO, error) {
+	property, err := s.propertyRepo.GetByID(ctx, propertyID)
+	if err != nil {
+		return dto.HostProfileResponseDTO{}, err
+	}
+	if !property.IsPublished() {
+		return dto.HostProfileResponseDTO{}, fmt.Errorf("propiedad con ID '%s' no encontrada", propertyID)
+	}
+
+	profile, err := s.profiles.GetHostProfile(ctx, property.OwnerID)
+	if errors.Is(err, clients.ErrHostNotFound) {
+		return dto.HostProfileResponseDTO{}, ErrHostNotFound
+	}
+	if err != nil {
+		return dto.HostProfileResponseDTO{}, fmt.Errorf("%w: %v", ErrHostProfileUnavailable, err)
+	}
+
+	languages := profile.Languages
+	if languages == nil {
+		languages = []string{}
+	}
+	return dto.HostProfileResponseDTO{
+		UserID:      strconv.FormatUint(uint64(profile.ID), 10),
+		DisplayName: profile.DisplayName,
+		AvatarURL:   profile.AvatarURL,
+		Bio:         profile.Bio,
+		Languages:   languages,
+		City:        profile.City,
+		HasPhone:    profile.HasPhone,
+		MemberSince: profile.MemberSince,
+	}, nil
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"properties-api/domain"
+	"properties-api/dto"
+)
+
+// ErrInvalidHouseRules indica reglas de la casa inválidas (horario de silencio mal formado o incompleto)
+var ErrInvalidHouseRules = errors.New("reglas de la casa inválidas")
+
+// houseRulesFromDTO valida las reglas de la casa y normaliza el horario de silencio a "HH:MM"
+// Sin reglas retorna nil (la propiedad no permite mascotas, fumar ni eventos)
+func houseRulesFromDTO(rules *dto.HouseRulesDTO) (*domain.HouseRules, error) {
+	if rules == nil {
+		return nil, nil
+	}
+
+	start, err := parseQuietHour("quietHoursStart", rules.QuietHoursStart)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseQuietHour("quietHoursEnd", rules.QuietHoursEnd)
+	if err != nil {
+		return nil, err
+	}
+	if (start == "") != (end == "") {
+		return nil, fmt.Errorf("%w: el horario de silencio requiere quietHoursStart y quietHoursEnd", ErrInvalidHouseRules)
+	}
+	if start != "" && start == end {
+		return nil, fmt.Errorf("%w: el horario de silencio no puede empezar y terminar a la misma hora", ErrInvalidHouseRules)
+	}
+
+	return &domain.HouseRules{
+		PetsAllowed:     rules.PetsAllowed,
+		SmokingAllowed:  rules.SmokingAllowed,
+		EventsAllowed:   rules.EventsAllowed,
+		QuietHoursStart: start,
+		QuietHoursEnd:   end,
+	}, nil
+}
+
+// parseQuietHour valida una hora del horario de silencio; vacío significa que no se cargó
+func parseQuietHour(field, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s '%s' debe tener el formato HH:MM", ErrInvalidHouseRules, field, value)
+	}
+	return parsed.Format("15:04"), nil
+}
+
+// toHouseRulesDTO convierte las reglas de la casa; las propiedades sin reglas no permiten nada
+func toHouseRulesDTO(rules *domain.HouseRules) dto.HouseRulesDTO {
+	if rules == nil {
+		return dto.HouseRulesDTO{}
+	}
+	return dto.HouseRulesDTO{
+		PetsAllowed:     rules.PetsAllowed,
+		SmokingAllowed:  rules.SmokingAllowed,
+		EventsAllowed:   rules.EventsAllowed,
+		QuietHoursStart: rules.QuietHoursStart,
+		QuietHoursEnd:   rules.QuietHoursEnd,
+	}
+}
@@ -0,0 +1,286 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"path"
+	"strings"
+
+	"properties-api/clients"
+	"properties-api/config"
+	"properties-api/domain"
+	"properties-api/dto"
+	"properties-api/repositories"
+
+	"golang.org/x/image/draw"
+)
+
+// ErrInvalidImage indica un archivo que no es una imagen JPEG, PNG o GIF
+var ErrInvalidImage = errors.New("imagen inválida")
+
+// ErrImageTooLarge indica una foto que supera el tamaño máximo en bytes o en píxeles
+var ErrImageTooLarge = errors.New("imagen demasiado grande")
+
+// maxImagePixels limita la resolución de una foto subida (decodificarla ocupa 4 bytes por píxel)
+const maxImagePixels = 50_000_000
+
+// photoRenditions son los tamaños que se generan de cada foto subida: el lado más largo en píxeles
+// Una foto más chica que el tamaño no se agranda
+var photoRenditions = []struct {
+	size    string
+	maxSide int
+}{
+	{domain.PhotoSizeThumbnail, 320},
+	{domain.PhotoSizeMedium, 800},
+	{domain.PhotoSizeLarge, 1600},
+}
+
+// uploadExtensions son las extensiones con las que se guardan los originales según su formato
+var uploadExtensions = map[string]string{
+	"jpeg": "jpg",
+	"png":  "png",
+	"gif":  "gif",
+}
+
+// ImageService define las operaciones de las fotos subidas a properties-api
+type ImageService interface {
+	// UploadPhoto guarda el original, lo agrega al final de la galería en estado "processing"
+	// y encola el job que genera las variantes
+	UploadPhoto(ctx context.Context, propertyID, userID string, isAdmin bool, data []byte, caption string) (dto.PropertyResponseDTO, error)
+
+	// ProcessJob genera las variantes de una foto subida y la marca "ready" (o "failed" si no se pudo)
+	ProcessJob(ctx context.Context, job clients.ImageJob) error
+}
+
+// imageService es la implementación concreta de ImageService
+type imageService struct {
+	repo         repositories.PropertyRepository
+	storage      repositories.ImageStorage
+	rabbitClient clients.RabbitMQClient
+	webp         clients.WebPEncoder
+	audit        AuditService
+	config       config.ImagesConfig
+}
+
+// NewImageService crea el servicio de fotos subidas
+// webp puede ser nil: en ese caso las variantes se generan solo en JPEG
+func NewImageService(repo repositories.PropertyRepository, storage repositories.ImageStorage, rabbitClient clients.RabbitMQClient, webp clients.WebPEncoder, audit AuditService, cfg config.ImagesConfig) ImageService {
+	return &imageService{
+		repo:         repo,
+		storage:      storage,
+		rabbitClient: rabbitClient,
+		webp:         webp,
+		audit:        audit,
+		config:       cfg,
+	}
+}
+
+// UploadPhoto guarda el original, lo agrega al final de la galería en estado "processing"
+// y encola el job que genera las variantes
+// La primera foto de una propiedad sin galería queda como portada
+func (s *imageService) UploadPhoto(ctx context.Context, propertyID, userID string, isAdmin bool, data []byte, caption string) (dto.PropertyResponseDTO, error) {
+	if len(data) > s.config.MaxUploadBytes {
+		return dto.PropertyResponseDTO{}, fmt.Errorf("%w: la foto pesa %d bytes y el máximo es %d", ErrImageTooLarge, len(data), s.config.MaxUploadBytes)
+	}
+	caption = strings.TrimSpace(caption)
+	if len([]rune(caption)) > maxPhotoCaptionLength {
+		return dto.PropertyResponseDTO{}, fmt.Errorf("%w: el epígrafe supera los %d caracteres", ErrInvalidPhotos, maxPhotoCaptionLength)
+	}
+
+	// Solo se lee el encabezado: el archivo completo se decodifica en el worker
+	imageConfig, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return dto.PropertyResponseDTO{}, fmt.Errorf("%w: se esperaba JPEG, PNG o GIF", ErrInvalidImage)
+	}
+	extension, ok := uploadExtensions[format]
+	if !ok {
+		return dto.PropertyResponseDTO{}, fmt.Errorf("%w: formato '%s' no soportado, se esperaba JPEG, PNG o GIF", ErrInvalidImage, format)
+	}
+	if imageConfig.Width*imageConfig.Height > maxImagePixels {
+		return dto.PropertyResponseDTO{}, fmt.Errorf("%w: %dx%d píxeles supera el máximo de %d", ErrImageTooLarge, imageConfig.Width, imageConfig.Height, maxImagePixels)
+	}
+
+	property, err := s.repo.GetByID(ctx, propertyID)
+	if err != nil {
+		return dto.PropertyResponseDTO{}, fmt.Errorf("error obteniendo propiedad: %w", err)
+	}
+	if property.OwnerID != userID && !isAdmin {
+		return dto.PropertyResponseDTO{}, ErrPhotosForbidden
+	}
+
+	name, err := randomImageName()
+	if err != nil {
+		return dto.PropertyResponseDTO{}, err
+	}
+	key := fmt.Sprintf("properties/%s/%s.%s", propertyID, name, extension)
+	url, err := s.storage.Save(key, data)
+	if err != nil {
+		return dto.PropertyResponseDTO{}, err
+	}
+
+	photos := normalizePhotos(property.Images)
+	photos = append(photos, domain.Photo{
+		URL:        url,
+		Caption:    caption,
+		Position:   len(photos),
+		IsCover:    len(photos) == 0,
+		Status:     domain.PhotoStatusProcessing,
+		StorageKey: key,
+	})
+	response, err := persistPhotos(ctx, s.repo, s.rabbitClient, s.audit, property, photos, userID)
+	if err != nil {
+		s.deleteFiles(key)
+		return dto.PropertyResponseDTO{}, err
+	}
+
+	// La foto ya está en la galería: si no se pudo encolar queda "failed" y el anfitrión puede volver a subirla
+	job := clients.ImageJob{PropertyID: propertyID, PhotoURL: url, StorageKey: key}
+	if err := s.rabbitClient.PublishImageJob(job); err != nil {
+		fmt.Printf("⚠️ Error encolando el procesamiento de la foto %s de la propiedad %s: %v\n", url, propertyID, err)
+		if err := s.repo.SetPhotoVariants(ctx, propertyID, url, domain.PhotoStatusFailed, nil); err != nil {
+			fmt.Printf("⚠️ Error marcando la foto %s como fallida: %v\n", url, err)
+		}
+		for i := range response.Images {
+			if response.Images[i].URL == url {
+				response.Images[i].Status = domain.PhotoStatusFailed
+			}
+		}
+	}
+
+	return response, nil
+}
+
+// ProcessJob genera las variantes de una foto subida y la marca "ready" (o "failed" si no se pudo)
+// Un error de WebP no hace fallar la foto: se conservan las variantes JPEG
+// Si la foto se sacó de la galería mientras se procesaba, se descartan los archivos generados
+func (s *imageService) ProcessJob(ctx context.Context, job clients.ImageJob) error {
+	variants, keys, err := s.renderVariants(ctx, job.StorageKey)
+	if err != nil {
+		if markErr := s.repo.SetPhotoVariants(ctx, job.PropertyID, job.PhotoURL, domain.PhotoStatusFailed, nil); markErr != nil && !errors.Is(markErr, repositories.ErrPhotoNotInGallery) {
+			fmt.Printf("⚠️ Error marcando la foto %s como fallida: %v\n", job.PhotoURL, markErr)
+		}
+		return fmt.Errorf("error procesando la foto %s de la propiedad %s: %w", job.PhotoURL, job.PropertyID, err)
+	}
+
+	err = s.repo.SetPhotoVariants(ctx, job.PropertyID, job.PhotoURL, domain.PhotoStatusReady, variants)
+	if errors.Is(err, repositories.ErrPhotoNotInGallery) {
+		fmt.Printf("ℹ️ La foto %s ya no está en la propiedad %s, se descartan sus variantes\n", job.PhotoURL, job.PropertyID)
+		s.deleteFiles(append(keys, job.StorageKey)...)
+		return nil
+	}
+	if err != nil {
+		s.deleteFiles(keys...)
+		return fmt.Errorf("error guardando las variantes de la foto %s: %w", job.PhotoURL, err)
+	}
+
+	// Se publica el snapshot para que search-api indexe la miniatura de la portada
+	property, err := s.repo.GetByID(ctx, job.PropertyID)
+	if err != nil {
+		return fmt.Errorf("error obteniendo propiedad: %w", err)
+	}
+	if err := s.rabbitClient.PublishPropertySnapshotEvent("update", toPropertyDTO(property)); err != nil {
+		fmt.Printf("⚠️ Error publicando evento 'update' en RabbitMQ para propiedad %s: %v\n", job.PropertyID, err)
+	}
+	return nil
+}
+
+// renderVariants decodifica el original y guarda cada tamaño en JPEG y, si hay encoder, en WebP
+// Retorna las variantes y las claves de los archivos guardados
+func (s *imageService) renderVariants(ctx context.Context, storageKey string) ([]domain.PhotoVariant, []string, error) {
+	data, err := s.storage.Read(storageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	original, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	base := strings.TrimSuffix(storageKey, path.Ext(storageKey))
+	var variants []domain.PhotoVariant
+	var keys []string
+	for _, rendition := range photoRenditions {
+		resized := resizeToFit(original, rendition.maxSide)
+		bounds := resized.Bounds()
+
+		var encoded bytes.Buffer
+		if err := jpeg.Encode(&encoded, resized, &jpeg.Options{Quality: s.config.JPEGQuality}); err != nil {
+			s.deleteFiles(keys...)
+			return nil, nil, fmt.Errorf("error codificando la variante %s: %w", rendition.size, err)
+		}
+		key := fmt.Sprintf("%s_%s.jpg", base, rendition.size)
+		url, err := s.storage.Save(key, encoded.Bytes())
+		if err != nil {
+			s.deleteFiles(keys...)
+			return nil, nil, err
+		}
+		keys = append(keys, key)
+		variants = append(variants, domain.PhotoVariant{Size: rendition.size, Format: domain.PhotoFormatJPEG, URL: url, Width: bounds.Dx(), Height: bounds.Dy()})
+
+		if s.webp == nil {
+			continue
+		}
+		webpData, err := s.webp.Encode(ctx, resized, s.config.WebPQuality)
+		if err != nil {
+			fmt.Printf("⚠️ Error generando la variante WebP %s de %s: %v\n", rendition.size, storageKey, err)
+			continue
+		}
+		key = fmt.Sprintf("%s_%s.webp", base, rendition.size)
+		url, err = s.storage.Save(key, webpData)
+		if err != nil {
+			fmt.Printf("⚠️ Error guardando la variante WebP %s de %s: %v\n", rendition.size, storageKey, err)
+			continue
+		}
+		keys = append(keys, key)
+		variants = append(variants, domain.PhotoVariant{Size: rendition.size, Format: domain.PhotoFormatWebP, URL: url, Width: bounds.Dx(), Height: bounds.Dy()})
+	}
+	return variants, keys, nil
+}
+
+// deleteFiles elimina archivos guardados; los errores solo se loguean
+func (s *imageService) deleteFiles(keys ...string) {
+	for _, key := range keys {
+		if err := s.storage.Delete(key); err != nil {
+			fmt.Printf("⚠️ %v\n", err)
+		}
+	}
+}
+
+// resizeToFit escala la imagen para que su lado más largo mida como máximo maxSide, manteniendo la proporción
+// Se dibuja sobre fondo blanco porque JPEG no tiene transparencia
+func resizeToFit(src image.Image, maxSide int) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > maxSide || height > maxSide {
+		if width >= height {
+			height = max(1, height*maxSide/width)
+			width = maxSide
+		} else {
+			width = max(1, width*maxSide/height)
+			height = maxSide
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+	return dst
+}
+
+// randomImageName genera un nombre de archivo no adivinable para una foto subida
+func randomImageName() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("error generando nombre de imagen: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"properties-api/clients"
+	"properties-api/domain"
+	"properties-api/dto"
+	"properties-api/repositories"
+)
+
+// ErrConversationForbidden indica que el usuario no participa de la conversación
+var ErrConversationForbidden = errors.New("solo el huésped y el anfitrión pueden acceder a la conversación")
+
+// ErrCannotMessageOwnProperty indica que el owner intentó iniciar una conversación con su propia propiedad
+var ErrCannotMessageOwnProperty = errors.New("no se puede iniciar una conversación con una propiedad propia")
+
+// ErrEmptyMessage indica que el mensaje no tiene texto
+var ErrEmptyMessage = errors.New("el mensaje no puede estar vacío")
+
+// messagePreviewLength es la cantidad de caracteres del extracto del último mensaje
+const messagePreviewLength = 140
+
+// Límites de las páginas de mensajes y conversaciones
+const (
+	defaultMessagePageSize = 50
+	maxMessagePageSize     = 100
+	maxConversations       = 200
+)
+
+// MessageService administra la mensajería entre huéspedes y anfitriones
+// Cada conversación es entre el huésped y el owner de una propiedad
+type MessageService interface {
+	// StartConversation envía un mensaje al owner de la propiedad, creando la conversación si no existe
+	StartConversation(ctx context.Context, propertyID, guestID string, request dto.SendMessageDTO) (dto.ConversationDTO, dto.MessageDTO, error)
+	// SendMessage envía un mensaje en una conversación existente (solo participantes)
+	SendMessage(ctx context.Context, conversationID, userID string, request dto.SendMessageDTO) (dto.MessageDTO, error)
+	// ListConversations lista las conversaciones del usuario, la más reciente primero
+	ListConversations(ctx context.Context, userID string) ([]dto.ConversationDTO, error)
+	// ListMessages obtiene una página de mensajes y marca la conversación como leída para el participante
+	// Los administradores pueden leerla sin ser participantes (no se marca como leída)
+	ListMessages(ctx context.Context, conversationID, userID string, isAdmin bool, before time.Time, limit int) (dto.MessagePageDTO, error)
+	// UnreadCount retorna el total de mensajes sin leer del usuario
+	UnreadCount(ctx context.Context, userID string) (int64, error)
+}
+
+// messageService es la implementación concreta de MessageService
+type messageService struct {
+	propertyRepo     repositories.PropertyRepository
+	conversationRepo repositories.ConversationRepository
+	messageRepo      repositories.MessageRepository
+	rabbitClient     clients.RabbitMQClient
+}
+
+// NewMessageService crea una nueva instancia del servicio de mensajería
+func NewMessageService(
+	propertyRepo repositories.PropertyRepository,
+	conversationRepo repositories.ConversationRepository,
+	messageRepo repositories.MessageRepository,
+	rabbitClient clients.RabbitMQClient,
+) MessageService {
+	return &messageService{
+		propertyRepo:     propertyRepo,
+		conversationRepo: conversationRepo,
+		messageRepo:      messageRepo,
+		rabbitClient:     rabbitClient,
+	}
+}
+
+// StartConversation envía el primer mensaje (o uno más) del huésped al owner de la propiedad
+func (s *messageService) StartConversation(ctx context.Context, propertyID, guestID string, request dto.SendMessageDTO) (dto.ConversationDTO, dto.MessageDTO, error) {
+	property, err := s.propertyRepo.GetByID(ctx, propertyID)
+	if err != nil {
+		return dto.ConversationDTO{}, dto.MessageDTO{}, fmt.Errorf("error obteniendo propiedad: %w", err)
+	}
+	if property.OwnerID == guestID {
+		return dto.ConversationDTO{}, dto.MessageDTO{}, ErrCannotMessageOwnProperty
+	}
+
+	conversation := &domain.Conversation{
+		PropertyID: propertyID,
+		GuestID:    guestID,
+		HostID:     property.OwnerID,
+	}
+	if err := s.conversationRepo.FindOrCreate(ctx, conversation); err != nil {
+		return dto.ConversationDTO{}, dto.MessageDTO{}, err
+	}
+
+	message, updated, err := s.send(ctx, conversation, guestID, request.Body)
+	if err != nil {
+		return dto.ConversationDTO{}, dto.MessageDTO{}, err
+	}
+	return toConversationDTO(*updated, guestID), message, nil
+}
+
+// SendMessage envía un mensaje en la conversación si el usuario es huésped o anfitrión
+func (s *messageService) SendMessage(ctx context.Context, conversationID, userID string, request dto.SendMessageDTO) (dto.MessageDTO, error) {
+	conversation, err := s.conversationRepo.FindByID(ctx, conversationID)
+	if err != nil {
+		return dto.MessageDTO{}, err
+	}
+	if !isParticipant(*conversation, userID) {
+		return dto.MessageDTO{}, ErrConversationForbidden
+	}
+
+	message, _, err := s.send(ctx, conversation, userID, request.Body)
+	return message, err
+}
+
+// send guarda el mensaje, actualiza los no leídos del destinatario y publica el evento "message.sent"
+func (s *messageService) send(ctx context.Context, conversation *domain.Conversation, senderID, body string) (dto.MessageDTO, *domain.Conversation, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return dto.MessageDTO{}, nil, ErrEmptyMessage
+	}
+
+	message := &domain.Message{
+		ConversationID: conversation.ID,
+		SenderID:       senderID,
+		Body:           body,
+		CreatedAt:      time.Now().UTC(),
+	}
+	if err := s.messageRepo.Create(ctx, message); err != nil {
+		return dto.MessageDTO{}, nil, err
+	}
+
+	recipientIsHost := senderID == conversation.GuestID
+	preview := messagePreview(body)
+	updated, err := s.conversationRepo.RecordMessage(ctx, conversation.ID, preview, message.CreatedAt, recipientIsHost)
+	if err != nil {
+		return dto.MessageDTO{}, nil, err
+	}
+
+	recipientID, recipientUnread := updated.GuestID, updated.GuestUnread
+	if recipientIsHost {
+		recipientID, recipientUnread = updated.HostID, updated.HostUnread
+	}
+	event := clients.MessageSentEvent{
+		ConversationID:  conversation.ID.Hex(),
+		MessageID:       message.ID.Hex(),
+		PropertyID:      conversation.PropertyID,
+		SenderID:        senderID,
+		RecipientID:     recipientID,
+		Preview:         preview,
+		RecipientUnread: recipientUnread,
+		SentAt:          message.CreatedAt.Format(time.RFC3339),
+	}
+	if err := s.rabbitClient.PublishMessageEvent(event); err != nil {
+		// El mensaje ya quedó guardado: sin el evento solo se pierde el aviso por email
+		log.Printf("⚠️ Error publicando evento 'message.sent' de la conversación %s: %v", event.ConversationID, err)
+	}
+
+	return toMessageDTO(*message), updated, nil
+}
+
+// ListConversations lista las conversaciones del usuario con sus no leídos
+func (s *messageService) ListConversations(ctx context.Context, userID string) ([]dto.ConversationDTO, error) {
+	conversations, err := s.conversationRepo.FindByParticipant(ctx, userID, maxConversations)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.ConversationDTO, 0, len(conversations))
+	for _, conversation := range conversations {
+		result = append(result, toConversationDTO(conversation, userID))
+	}
+	return result, nil
+}
+
+// ListMessages obtiene una página de mensajes de la conversación
+func (s *messageService) ListMessages(ctx context.Context, conversationID, userID string, isAdmin bool, before time.Time, limit int) (dto.MessagePageDTO, error) {
+	conversation, err := s.conversationRepo.FindByID(ctx, conversationID)
+	if err != nil {
+		return dto.MessagePageDTO{}, err
+	}
+	participant := isParticipant(*conversation, userID)
+	if !participant && !isAdmin {
+		return dto.MessagePageDTO{}, ErrConversationForbidden
+	}
+
+	if limit <= 0 {
+		limit = defaultMessagePageSize
+	}
+	if limit > maxMessagePageSize {
+		limit = maxMessagePageSize
+	}
+
+	messages, err := s.messageRepo.FindByConversation(ctx, conversation.ID, before, limit)
+	if err != nil {
+		return dto.MessagePageDTO{}, err
+	}
+
+	// Leer la primera página marca la conversación como leída; las páginas anteriores ya se leyeron
+	if participant && before.IsZero() {
+		asHost := userID == conversation.HostID
+		if err := s.conversationRepo.MarkRead(ctx, conversation.ID, asHost); err != nil {
+			return dto.MessagePageDTO{}, err
+		}
+		if asHost {
+			conversation.HostUnread = 0
+		} else {
+			conversation.GuestUnread = 0
+		}
+	}
+
+	page := dto.MessagePageDTO{
+		Conversation: toConversationDTO(*conversation, userID),
+		Messages:     make([]dto.MessageDTO, 0, len(messages)),
+	}
+	for _, message := range messages {
+		page.Messages = append(page.Messages, toMessageDTO(message))
+	}
+	if len(messages) == limit {
+		nextBefore := messages[len(messages)-1].CreatedAt
+		page.NextBefore = &nextBefore
+	}
+	return page, nil
+}
+
+// UnreadCount retorna el total de mensajes sin leer del usuario
+func (s *messageService) UnreadCount(ctx context.Context, userID string) (int64, error) {
+	return s.conversationRepo.CountUnread(ctx, userID)
+}
+
+// isParticipant indica si el usuario es el huésped o el anfitrión de la conversación
+func isParticipant(conversation domain.Conversation, userID string) bool {
+	return userID == conversation.GuestID || userID == conversation.HostID
+}
+
+// messagePreview recorta el mensaje a messagePreviewLength caracteres (sin cortar runas)
+func messagePreview(body string) string {
+	if utf8.RuneCountInString(body) <= messagePreviewLength {
+		return body
+	}
+	runes := []rune(body)
+	return strings.TrimSpace(string(runes[:messagePreviewLength])) + "…"
+}
+
+// toConversationDTO convierte la conversación al DTO con los no leídos de userID
+func toConversationDTO(conversation domain.Conversation, userID string) dto.ConversationDTO {
+	var unread int64
+	switch userID {
+	case conversation.GuestID:
+		unread = conversation.GuestUnread
+	case conversation.HostID:
+		unread = conversation.HostUnread
+	}
+	return dto.ConversationDTO{
+		ID:            conversation.ID.Hex(),
+		PropertyID:    conversation.PropertyID,
+		GuestID:       conversation.GuestID,
+		HostID:        conversation.HostID,
+		LastMessage:   conversation.LastMessage,
+		LastMessageAt: conversation.LastMessageAt,
+		Unread:        unread,
+		CreatedAt:     conversation.CreatedAt,
+	}
+}
+
+// toMessageDTO convierte el mensaje a su DTO
+func toMessageDTO(message domain.Message) dto.MessageDTO {
+	return dto.MessageDTO{
+		ID:             message.ID.Hex(),
+		ConversationID: message.ConversationID.Hex(),
+		SenderID:       message.SenderID,
+		Body:           message.Body,
+		CreatedAt:      message.CreatedAt,
+	}
+}
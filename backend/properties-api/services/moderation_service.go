@@ -0,0 +1,355 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"properties-api/clients"
+	"properties-api/domain"
+	"properties-api/dto"
+	"properties-api/repositories"
+	"properties-api/utils"
+)
+
+// ErrModerationCaseNotFound indica que no existe el caso de moderación
+var ErrModerationCaseNotFound = errors.New("caso de moderación no encontrado")
+
+// ErrModerationCaseClosed indica que el caso ya fue resuelto o reemplazado por una edición posterior
+var ErrModerationCaseClosed = errors.New("el caso de moderación ya no está pendiente")
+
+// ErrInvalidModerationStatus indica un filtro de estado desconocido
+var ErrInvalidModerationStatus = errors.New("estado de moderación inválido")
+
+// Campos de una propiedad que pasan por moderación
+const (
+	moderatedFieldTitle       = "title"
+	moderatedFieldDescription = "description"
+)
+
+// moderatedTranslationSeparator separa el campo del locale en el contenido de una traducción ("title:en")
+const moderatedTranslationSeparator = ":"
+
+// maxModerationCasesListed es la cantidad máxima de casos por listado
+const maxModerationCasesListed = 200
+
+// ModerationService revisa el contenido nuevo antes de publicarlo y administra la cola de revisión
+type ModerationService interface {
+	// Screen pasa cada campo por los checks configurados y retorna los motivos para retenerlo (vacío = se publica)
+	Screen(ctx context.Context, content map[string]string) []domain.ModerationFlag
+	// Quarantine abre un caso con el contenido retenido; reemplaza los casos pendientes de la misma entidad
+	Quarantine(ctx context.Context, entityType, entityID, submittedBy string, content map[string]string, flags []domain.ModerationFlag) error
+	// SupersedePending cierra los casos pendientes de una entidad cuyo contenido se volvió a editar
+	SupersedePending(ctx context.Context, entityType, entityID string)
+
+	// ListCases lista los casos con el estado indicado, el más viejo primero (solo admin)
+	ListCases(ctx context.Context, status string) ([]dto.ModerationCaseDTO, error)
+	// ListUserCases lista los casos del contenido enviado por el usuario, el más reciente primero
+	ListUserCases(ctx context.Context, userID string) ([]dto.ModerationCaseDTO, error)
+	// Approve publica el contenido retenido (solo admin)
+	Approve(ctx context.Context, id, adminID, note string) (dto.ModerationCaseDTO, error)
+	// Reject descarta el contenido retenido; una propiedad nueva queda sin publicar (solo admin)
+	Reject(ctx context.Context, id, adminID, note string) (dto.ModerationCaseDTO, error)
+}
+
+// moderationService es la implementación concreta de ModerationService
+type moderationService struct {
+	repo         repositories.ModerationRepository
+	propertyRepo repositories.PropertyRepository
+	rabbitClient clients.RabbitMQClient
+	audit        AuditService
+	checks       []ContentCheck
+}
+
+// NewModerationService crea el servicio de moderación con los checks a aplicar
+// Los checks nil se ignoran; sin checks todo el contenido se publica directamente
+func NewModerationService(
+	repo repositories.ModerationRepository,
+	propertyRepo repositories.PropertyRepository,
+	rabbitClient clients.RabbitMQClient,
+	audit AuditService,
+	checks ...ContentCheck,
+) ModerationService {
+	service := &moderationService{
+		repo:         repo,
+		propertyRepo: propertyRepo,
+		rabbitClient: rabbitClient,
+		audit:        audit,
+	}
+	for _, check := range checks {
+		if check != nil {
+			service.checks = append(service.checks, check)
+		}
+	}
+	return service
+}
+
+// Screen pasa cada campo por los checks configurados
+// Un check que falla (ej: la API externa no responde) se loguea y no retiene el contenido
+func (s *moderationService) Screen(ctx context.Context, content map[string]string) []domain.ModerationFlag {
+	fields := make([]string, 0, len(content))
+	for field := range content {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var flags []domain.ModerationFlag
+	for _, field := range fields {
+		if content[field] == "" {
+			continue
+		}
+		for _, check := range s.checks {
+			reasons, err := check.Check(ctx, content[field])
+			if err != nil {
+				log.Printf("⚠️ Error en el check de moderación '%s': %v", check.Name(), err)
+				continue
+			}
+			for _, reason := range reasons {
+				flags = append(flags, domain.ModerationFlag{Check: check.Name(), Field: field, Reason: reason})
+			}
+		}
+	}
+	return flags
+}
+
+// Quarantine abre un caso con el contenido retenido
+func (s *moderationService) Quarantine(ctx context.Context, entityType, entityID, submittedBy string, content map[string]string, flags []domain.ModerationFlag) error {
+	s.SupersedePending(ctx, entityType, entityID)
+
+	moderationCase := &domain.ModerationCase{
+		EntityType:  entityType,
+		EntityID:    entityID,
+		SubmittedBy: submittedBy,
+		Content:     content,
+		Flags:       flags,
+		Status:      domain.ModerationCasePending,
+		CreatedAt:   utils.NowUTC(),
+	}
+	if err := s.repo.Create(ctx, moderationCase); err != nil {
+		return err
+	}
+	log.Printf("🛑 Contenido de %s %s retenido para revisión (caso %s, %d motivos)", entityType, entityID, moderationCase.ID.Hex(), len(flags))
+	return nil
+}
+
+// SupersedePending cierra los casos pendientes de una entidad; un error solo se loguea
+func (s *moderationService) SupersedePending(ctx context.Context, entityType, entityID string) {
+	if err := s.repo.SupersedePending(ctx, entityType, entityID); err != nil {
+		log.Printf("⚠️ Error cerrando casos de moderación de %s %s: %v", entityType, entityID, err)
+	}
+}
+
+// ListCases lista los casos por estado (vacío = pendientes)
+func (s *moderationService) ListCases(ctx context.Context, status string) ([]dto.ModerationCaseDTO, error) {
+	switch status {
+	case "":
+		status = domain.ModerationCasePending
+	case "all":
+		status = ""
+	case domain.ModerationCasePending, domain.ModerationCaseApproved, domain.ModerationCaseRejected, domain.ModerationCaseSuperseded:
+	default:
+		return nil, fmt.Errorf("%w: '%s'", ErrInvalidModerationStatus, status)
+	}
+
+	cases, err := s.repo.List(ctx, status, maxModerationCasesListed)
+	if err != nil {
+		return nil, err
+	}
+	return toModerationCaseDTOs(cases), nil
+}
+
+// ListUserCases lista los casos del usuario, así ve por qué se retuvo su contenido
+func (s *moderationService) ListUserCases(ctx context.Context, userID string) ([]dto.ModerationCaseDTO, error) {
+	cases, err := s.repo.ListBySubmitter(ctx, userID, maxModerationCasesListed)
+	if err != nil {
+		return nil, err
+	}
+	return toModerationCaseDTOs(cases), nil
+}
+
+// Approve aplica el contenido retenido a la propiedad y la publica
+// Una propiedad nueva se publica con "create" y una edición con "update"
+func (s *moderationService) Approve(ctx context.Context, id, adminID, note string) (dto.ModerationCaseDTO, error) {
+	moderationCase, err := s.pendingCase(ctx, id)
+	if err != nil {
+		return dto.ModerationCaseDTO{}, err
+	}
+
+	property, err := s.propertyRepo.GetByID(ctx, moderationCase.EntityID)
+	if err != nil {
+		return dto.ModerationCaseDTO{}, fmt.Errorf("error obteniendo propiedad: %w", err)
+	}
+	wasPublished := property.IsPublished()
+	updated := property
+	if title, ok := moderationCase.Content[moderatedFieldTitle]; ok {
+		updated.Title = title
+	}
+	if description, ok := moderationCase.Content[moderatedFieldDescription]; ok {
+		updated.Description = description
+	}
+	if translations := translationsFromModerationContent(moderationCase.Content); len(translations) > 0 {
+		updated.Translations = translations
+	}
+	updated.ModerationStatus = ""
+	updated.Signature = utils.PropertySignature(updated.Title, updated.Location, updated.OwnerID)
+	updated.UpdatedAt = utils.NowUTC()
+	if err := s.propertyRepo.Update(ctx, moderationCase.EntityID, updated); err != nil {
+		return dto.ModerationCaseDTO{}, fmt.Errorf("error publicando propiedad moderada: %w", err)
+	}
+
+	resolved, err := s.resolve(ctx, moderationCase, domain.ModerationCaseApproved, adminID, note)
+	if err != nil {
+		return dto.ModerationCaseDTO{}, err
+	}
+
+	response := toPropertyDTO(updated)
+	s.audit.Record(ctx, adminID, AuditActionModerationApprove, auditEntityModerationCase, id, nil, resolved)
+	s.audit.Record(ctx, adminID, AuditActionPropertyUpdate, auditEntityProperty, moderationCase.EntityID, toPropertyDTO(property), response)
+
+	operation := "update"
+	if !wasPublished {
+		operation = "create"
+	}
+	if err := s.rabbitClient.PublishPropertySnapshotEvent(operation, response); err != nil {
+		fmt.Printf("⚠️ Error publicando evento '%s' en RabbitMQ para propiedad %s: %v\n", operation, moderationCase.EntityID, err)
+	}
+	return resolved, nil
+}
+
+// Reject descarta el contenido retenido
+// Una propiedad nueva queda rechazada (sin publicar); una edición se descarta y queda el texto publicado
+func (s *moderationService) Reject(ctx context.Context, id, adminID, note string) (dto.ModerationCaseDTO, error) {
+	moderationCase, err := s.pendingCase(ctx, id)
+	if err != nil {
+		return dto.ModerationCaseDTO{}, err
+	}
+
+	property, err := s.propertyRepo.GetByID(ctx, moderationCase.EntityID)
+	if err != nil {
+		return dto.ModerationCaseDTO{}, fmt.Errorf("error obteniendo propiedad: %w", err)
+	}
+	updated := property
+	if property.IsPublished() {
+		updated.ModerationStatus = ""
+	} else {
+		updated.ModerationStatus = domain.ModerationRejected
+	}
+	// UpdatedAt no cambia: el contenido publicado es el mismo
+	if err := s.propertyRepo.Update(ctx, moderationCase.EntityID, updated); err != nil {
+		return dto.ModerationCaseDTO{}, fmt.Errorf("error actualizando propiedad moderada: %w", err)
+	}
+
+	resolved, err := s.resolve(ctx, moderationCase, domain.ModerationCaseRejected, adminID, note)
+	if err != nil {
+		return dto.ModerationCaseDTO{}, err
+	}
+	s.audit.Record(ctx, adminID, AuditActionModerationReject, auditEntityModerationCase, id, nil, resolved)
+	return resolved, nil
+}
+
+// pendingCase obtiene un caso que todavía se puede resolver
+func (s *moderationService) pendingCase(ctx context.Context, id string) (*domain.ModerationCase, error) {
+	moderationCase, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if moderationCase == nil {
+		return nil, ErrModerationCaseNotFound
+	}
+	if moderationCase.Status != domain.ModerationCasePending {
+		return nil, fmt.Errorf("%w (estado: %s)", ErrModerationCaseClosed, moderationCase.Status)
+	}
+	return moderationCase, nil
+}
+
+// resolve cierra el caso; si otro admin lo resolvió en el medio retorna ErrModerationCaseClosed
+func (s *moderationService) resolve(ctx context.Context, moderationCase *domain.ModerationCase, status, adminID, note string) (dto.ModerationCaseDTO, error) {
+	now := utils.NowUTC()
+	ok, err := s.repo.Resolve(ctx, moderationCase.ID, status, adminID, note, now)
+	if err != nil {
+		return dto.ModerationCaseDTO{}, err
+	}
+	if !ok {
+		return dto.ModerationCaseDTO{}, ErrModerationCaseClosed
+	}
+
+	moderationCase.Status = status
+	moderationCase.ReviewedBy = adminID
+	moderationCase.ReviewedAt = &now
+	moderationCase.Note = note
+	return toModerationCaseDTO(*moderationCase), nil
+}
+
+// propertyModerationContent arma el contenido moderado de una propiedad
+func propertyModerationContent(title, description string, translations map[string]domain.PropertyTranslation) map[string]string {
+	content := map[string]string{
+		moderatedFieldTitle:       title,
+		moderatedFieldDescription: description,
+	}
+	addTranslationModerationContent(content, translations)
+	return content
+}
+
+// addTranslationModerationContent agrega las traducciones al contenido moderado ("title:en", "description:en")
+func addTranslationModerationContent(content map[string]string, translations map[string]domain.PropertyTranslation) {
+	for locale, translation := range translations {
+		content[moderatedFieldTitle+moderatedTranslationSeparator+locale] = translation.Title
+		content[moderatedFieldDescription+moderatedTranslationSeparator+locale] = translation.Description
+	}
+}
+
+// translationsFromModerationContent arma las traducciones retenidas en el contenido (nil si no hay)
+func translationsFromModerationContent(content map[string]string) map[string]domain.PropertyTranslation {
+	var translations map[string]domain.PropertyTranslation
+	for key, value := range content {
+		field, locale, ok := strings.Cut(key, moderatedTranslationSeparator)
+		if !ok {
+			continue
+		}
+		if translations == nil {
+			translations = make(map[string]domain.PropertyTranslation)
+		}
+		translation := translations[locale]
+		switch field {
+		case moderatedFieldTitle:
+			translation.Title = value
+		case moderatedFieldDescription:
+			translation.Description = value
+		}
+		translations[locale] = translation
+	}
+	return translations
+}
+
+// toModerationCaseDTOs convierte los casos del dominio a DTOs
+func toModerationCaseDTOs(cases []domain.ModerationCase) []dto.ModerationCaseDTO {
+	result := make([]dto.ModerationCaseDTO, len(cases))
+	for i, moderationCase := range cases {
+		result[i] = toModerationCaseDTO(moderationCase)
+	}
+	return result
+}
+
+// toModerationCaseDTO convierte un caso del dominio a DTO
+func toModerationCaseDTO(moderationCase domain.ModerationCase) dto.ModerationCaseDTO {
+	flags := make([]dto.ModerationFlagDTO, len(moderationCase.Flags))
+	for i, flag := range moderationCase.Flags {
+		flags[i] = dto.ModerationFlagDTO{Check: flag.Check, Field: flag.Field, Reason: flag.Reason}
+	}
+	return dto.ModerationCaseDTO{
+		ID:          moderationCase.ID.Hex(),
+		EntityType:  moderationCase.EntityType,
+		EntityID:    moderationCase.EntityID,
+		SubmittedBy: moderationCase.SubmittedBy,
+		Content:     moderationCase.Content,
+		Flags:       flags,
+		Status:      moderationCase.Status,
+		ReviewedBy:  moderationCase.ReviewedBy,
+		ReviewedAt:  moderationCase.ReviewedAt,
+		Note:        moderationCase.Note,
+		CreatedAt:   moderationCase.CreatedAt,
+	}
+}
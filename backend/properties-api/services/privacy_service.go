@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"properties-api/dto"
+	"properties-api/repositories"
+)
+
+// ErasedUserID reemplaza el userId de las reservas de un usuario que pidió borrar sus datos
+const ErasedUserID = "erased"
+
+// PrivacyService implementa el export y el borrado de los datos de un usuario (GDPR)
+type PrivacyService interface {
+	// ExportUserData obtiene las propiedades y las reservas de un usuario
+	ExportUserData(ctx context.Context, userID string) (dto.UserDataExportDTO, error)
+
+	// EraseUserData oculta las propiedades del usuario y anonimiza sus reservas
+	// Se ejecuta al recibir el evento "user.erased" de users-api
+	EraseUserData(ctx context.Context, userID string) error
+}
+
+// privacyService es la implementación concreta de PrivacyService
+type privacyService struct {
+	propertyService PropertyService
+	propertyRepo    repositories.PropertyRepository
+	bookingRepo     repositories.BookingRepository
+	audit           AuditService
+}
+
+// NewPrivacyService crea una nueva instancia del servicio de privacidad
+func NewPrivacyService(
+	propertyService PropertyService,
+	propertyRepo repositories.PropertyRepository,
+	bookingRepo repositories.BookingRepository,
+	audit AuditService,
+) PrivacyService {
+	return &privacyService{
+		propertyService: propertyService,
+		propertyRepo:    propertyRepo,
+		bookingRepo:     bookingRepo,
+		audit:           audit,
+	}
+}
+
+// ExportUserData obtiene las propiedades y las reservas de un usuario
+func (s *privacyService) ExportUserData(ctx context.Context, userID string) (dto.UserDataExportDTO, error) {
+	properties, err := s.propertyService.GetUserProperties(ctx, userID)
+	if err != nil {
+		return dto.UserDataExportDTO{}, err
+	}
+
+	bookings, err := s.bookingRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return dto.UserDataExportDTO{}, fmt.Errorf("error obteniendo reservas del usuario: %w", err)
+	}
+
+	// La zona horaria de cada propiedad se busca una sola vez
+	timezones := make(map[string]string)
+	bookingDTOs := make([]dto.BookingDTO, len(bookings))
+	for i, booking := range bookings {
+		timezone, cached := timezones[booking.PropertyID]
+		if !cached {
+			if property, err := s.propertyRepo.GetByID(ctx, booking.PropertyID); err == nil {
+				timezone = property.Timezone
+			}
+			timezones[booking.PropertyID] = timezone
+		}
+		bookingDTOs[i] = toBookingDTO(booking, timezone)
+	}
+
+	return dto.UserDataExportDTO{
+		Properties: properties,
+		Bookings:   bookingDTOs,
+	}, nil
+}
+
+// EraseUserData oculta las propiedades del usuario y anonimiza sus reservas
+// Las propiedades no se eliminan porque pueden tener reservas de otros huéspedes
+func (s *privacyService) EraseUserData(ctx context.Context, userID string) error {
+	hidden, err := s.propertyRepo.SetAvailabilityByOwner(ctx, userID, false)
+	if err != nil {
+		return fmt.Errorf("error ocultando propiedades del usuario: %w", err)
+	}
+
+	anonymized, err := s.bookingRepo.ReplaceUserID(ctx, userID, ErasedUserID)
+	if err != nil {
+		return fmt.Errorf("error anonimizando reservas del usuario: %w", err)
+	}
+
+	// Solo se registran los contadores: el log es append-only y no debe conservar datos borrados
+	s.audit.Record(ctx, AuditSystemActor, AuditActionUserDataErase, auditEntityUser, userID, nil, map[string]int64{
+		"hiddenProperties":   hidden,
+		"anonymizedBookings": anonymized,
+	})
+
+	log.Printf("🧹 Datos del usuario %s borrados: %d propiedades ocultas, %d reservas anonimizadas", userID, hidden, anonymized)
+	return nil
+}
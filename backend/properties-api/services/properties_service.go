@@ -1,7 +1,12 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"strings"
 	"time"
 
 	"properties-api/clients"
@@ -11,34 +16,76 @@ import (
 	"properties-api/utils"
 )
 
+// ErrUnpublishedForbidden indica que se pidieron propiedades no publicadas de otro usuario
+var ErrUnpublishedForbidden = errors.New("solo el owner o un administrador pueden ver las propiedades no publicadas")
+
+// defaultUserPropertiesPageSize es la cantidad de propiedades por página si no se indica limit
+const defaultUserPropertiesPageSize = 20
+
+// ErrDuplicateProperty indica que el owner ya tiene una propiedad con el mismo título y ubicación
+var ErrDuplicateProperty = errors.New("ya existe una propiedad del mismo owner con el mismo título y ubicación")
+
+// duplicateTitleSimilarity es la similitud de título a partir de la cual una propiedad nueva
+// del mismo owner y en la misma ubicación se marca como probable duplicado
+const duplicateTitleSimilarity = 0.6
+
 // PropertyService define la interfaz para la lógica de negocio de propiedades
 // Implementa las reglas de negocio y coordina las operaciones entre repositorios y clientes
 type PropertyService interface {
 	// CreateProperty crea una nueva propiedad con validación de usuario y cálculo de precio
-	CreateProperty(createDTO dto.PropertyCreateDTO) (dto.PropertyResponseDTO, error)
+	CreateProperty(ctx context.Context, createDTO dto.PropertyCreateDTO) (dto.PropertyResponseDTO, error)
 
 	// GetPropertyByID obtiene una propiedad por su ID
-	GetPropertyByID(id string) (dto.PropertyResponseDTO, error)
+	GetPropertyByID(ctx context.Context, id string) (dto.PropertyResponseDTO, error)
 
 	// UpdateProperty actualiza una propiedad existente con validación de ownership y admin
-	UpdateProperty(id string, updateDTO dto.PropertyUpdateDTO, userID string, isAdmin bool) error
+	UpdateProperty(ctx context.Context, id string, updateDTO dto.PropertyUpdateDTO, userID string, isAdmin bool) error
 
 	// DeleteProperty elimina una propiedad con validación de ownership y admin
-	DeleteProperty(id string, userID string, isAdmin bool) error
+	DeleteProperty(ctx context.Context, id string, userID string, isAdmin bool) error
 
 	// GetUserProperties obtiene todas las propiedades de un usuario específico
-	GetUserProperties(userID string) ([]dto.PropertyResponseDTO, error)
+	GetUserProperties(ctx context.Context, userID string) ([]dto.PropertyResponseDTO, error)
+
+	// SearchUserProperties obtiene una página filtrada y ordenada de las propiedades de un usuario
+	// canSeeUnpublished es true para el owner o un admin: ven también las retenidas por moderación
+	SearchUserProperties(ctx context.Context, userID string, query dto.UserPropertiesQuery, canSeeUnpublished bool) (dto.UserPropertiesResponseDTO, error)
 
 	// GetAllProperties obtiene todas las propiedades (solo admin)
-	GetAllProperties() ([]dto.PropertyResponseDTO, error)
+	GetAllProperties(ctx context.Context) ([]dto.PropertyResponseDTO, error)
+
+	// GetPriceHistory obtiene el historial de precios de una propiedad con estadísticas
+	GetPriceHistory(ctx context.Context, id string) (dto.PriceHistoryResponseDTO, error)
+
+	// StreamAllProperties recorre todas las propiedades sin cargarlas en memoria (solo admin)
+	StreamAllProperties(ctx context.Context, fn func(dto.PropertyResponseDTO) error) error
+
+	// GetTrendingProperties obtiene las propiedades disponibles más vistas en los últimos days días
+	GetTrendingProperties(ctx context.Context, days int, limit int) ([]dto.PropertyResponseDTO, error)
+
+	// ImportProperties importa propiedades desde un CSV o NDJSON y retorna el resultado de cada fila (solo admin)
+	ImportProperties(ctx context.Context, format utils.OutputFormat, r io.Reader, actorID string) (dto.PropertyImportReportDTO, error)
+
+	// ListPropertyVersions obtiene una página de IDs con su dueño y fecha de actualización, ordenada por ID
+	// La usa search-api para reconciliar su índice con la base
+	ListPropertyVersions(ctx context.Context, afterID string, limit int) ([]dto.PropertyVersionDTO, error)
+
+	// ReorderPhotos cambia el orden de las fotos de la propiedad (solo owner o admin)
+	ReorderPhotos(ctx context.Context, id, userID string, isAdmin bool, urls []string) (dto.PropertyResponseDTO, error)
+
+	// SetCoverPhoto elige la foto de portada de la propiedad (solo owner o admin)
+	SetCoverPhoto(ctx context.Context, id, userID string, isAdmin bool, url string) (dto.PropertyResponseDTO, error)
 }
 
 // propertyService es la implementación concreta de PropertyService
 // Coordina las operaciones entre repositorio, cliente de usuarios y cliente de RabbitMQ
 type propertyService struct {
-	repo         repositories.PropertyRepository
-	usersClient  clients.UsersClient
-	rabbitClient clients.RabbitMQClient
+	repo             repositories.PropertyRepository
+	priceHistoryRepo repositories.PriceHistoryRepository
+	usersClient      clients.UsersClient
+	rabbitClient     clients.RabbitMQClient
+	audit            AuditService
+	moderation       ModerationService
 }
 
 // NewPropertyService crea una nueva instancia del servicio de propiedades
@@ -46,13 +93,19 @@ type propertyService struct {
 // Esto permite testear el servicio fácilmente y cambiar implementaciones
 func NewPropertyService(
 	repo repositories.PropertyRepository,
+	priceHistoryRepo repositories.PriceHistoryRepository,
 	usersClient clients.UsersClient,
 	rabbitClient clients.RabbitMQClient,
+	audit AuditService,
+	moderation ModerationService,
 ) PropertyService {
 	return &propertyService{
-		repo:         repo,
-		usersClient:  usersClient,
-		rabbitClient: rabbitClient,
+		repo:             repo,
+		priceHistoryRepo: priceHistoryRepo,
+		usersClient:      usersClient,
+		rabbitClient:     rabbitClient,
+		audit:            audit,
+		moderation:       moderation,
 	}
 }
 
@@ -62,11 +115,11 @@ func NewPropertyService(
 // 2. Calcular precio final usando CalculatePriceWithConcurrency
 // 3. Crear property con timestamps actuales
 // 4. Guardar en repository
-// 5. Publicar evento "create" en RabbitMQ
+// 5. Publicar evento "create" en RabbitMQ (si moderación retuvo el texto, se abre un caso y no se publica)
 // 6. Retornar DTO de respuesta
-func (s *propertyService) CreateProperty(createDTO dto.PropertyCreateDTO) (dto.PropertyResponseDTO, error) {
+func (s *propertyService) CreateProperty(ctx context.Context, createDTO dto.PropertyCreateDTO) (dto.PropertyResponseDTO, error) {
 	// 1. Validar que el owner existe llamando a usersClient.ValidateUser
-	ownerExists, err := s.usersClient.ValidateUser(createDTO.OwnerID)
+	ownerExists, err := s.usersClient.ValidateUser(ctx, createDTO.OwnerID)
 	if err != nil {
 		return dto.PropertyResponseDTO{}, fmt.Errorf("error validando usuario owner: %w", err)
 	}
@@ -82,48 +135,137 @@ func (s *propertyService) CreateProperty(createDTO dto.PropertyCreateDTO) (dto.P
 		createDTO.Capacity,  // capacidad
 	)
 
-	// 3. Crear property con timestamps actuales
-	now := time.Now()
+	// Validar la zona horaria de la propiedad (vacía = UTC)
+	if _, err := utils.LoadPropertyLocation(createDTO.Timezone); err != nil {
+		return dto.PropertyResponseDTO{}, err
+	}
+
+	// Validar el tipo de propiedad (opcional)
+	propertyType, err := normalizePropertyType(createDTO.PropertyType)
+	if err != nil {
+		return dto.PropertyResponseDTO{}, err
+	}
+
+	// Validar país y región (opcionales, definen la tasa de impuestos)
+	country, err := utils.NormalizeCountryCode(createDTO.Country)
+	if err != nil {
+		return dto.PropertyResponseDTO{}, err
+	}
+	region, err := utils.NormalizeRegionCode(createDTO.Region)
+	if err != nil {
+		return dto.PropertyResponseDTO{}, err
+	}
+
+	// Validar las fotos y dejar una sola portada
+	photos, err := photosFromDTO(createDTO.Images)
+	if err != nil {
+		return dto.PropertyResponseDTO{}, err
+	}
+	houseRules, err := houseRulesFromDTO(createDTO.HouseRules)
+	if err != nil {
+		return dto.PropertyResponseDTO{}, err
+	}
+
+	// Validar el idioma del texto y las traducciones (opcionales)
+	language, err := languageFromDTO(createDTO.Language)
+	if err != nil {
+		return dto.PropertyResponseDTO{}, err
+	}
+	translations, err := translationsFromDTO(language, createDTO.Translations)
+	if err != nil {
+		return dto.PropertyResponseDTO{}, err
+	}
+
+	// Detectar duplicados: la misma firma se rechaza, un título parecido se marca para revisión
+	signature := utils.PropertySignature(createDTO.Title, createDTO.Location, createDTO.OwnerID)
+	duplicateOf, err := s.findDuplicate(ctx, createDTO.OwnerID, createDTO.Title, createDTO.Location, signature)
+	if err != nil {
+		return dto.PropertyResponseDTO{}, err
+	}
+
+	// 3. Crear property con timestamps actuales (UTC)
+	now := utils.NowUTC()
 	property := domain.Property{
-		Title:       createDTO.Title,
-		Description: createDTO.Description,
-		Price:       finalPrice, // Usar el precio calculado con concurrencia
-		Location:    createDTO.Location,
-		OwnerID:     createDTO.OwnerID,
-		Amenities:   createDTO.Amenities,
-		Capacity:    createDTO.Capacity,
-		Available:   createDTO.Available,
-		Images:      createDTO.Images,
-		CreatedAt:   now,
-		UpdatedAt:   now,
+		Title:        createDTO.Title,
+		Description:  createDTO.Description,
+		Price:        finalPrice, // Usar el precio calculado con concurrencia
+		CleaningFee:  createDTO.CleaningFee,
+		Location:     createDTO.Location,
+		PropertyType: propertyType,
+		Latitude:     createDTO.Latitude,
+		Longitude:    createDTO.Longitude,
+		Timezone:     createDTO.Timezone,
+		Country:      country,
+		Region:       region,
+		OwnerID:      createDTO.OwnerID,
+		Amenities:    createDTO.Amenities,
+		Capacity:     createDTO.Capacity,
+		Available:    createDTO.Available,
+		BookingMode:  bookingModeOrDefault(createDTO.BookingMode),
+		Images:       photos,
+		Signature:    signature,
+		DuplicateOf:  duplicateOf,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		// Huéspedes incluidos en el precio y aptitud para niños
+		GuestsIncluded: createDTO.GuestsIncluded,
+		ExtraGuestFee:  createDTO.ExtraGuestFee,
+		ChildFriendly:  createDTO.ChildFriendly,
+		InfantFriendly: createDTO.InfantFriendly,
+		HouseRules:     houseRules,
+		// Política de cancelación
+		CancellationPolicy: cancellationPolicyOrDefault(createDTO.CancellationPolicy),
+		// Idioma del texto y traducciones
+		Language:     language,
+		Translations: translations,
+	}
+
+	// Revisar título, descripción y traducciones: si algún check los retiene, la propiedad no se publica hasta que un admin la apruebe
+	moderated := propertyModerationContent(createDTO.Title, createDTO.Description, translations)
+	flags := s.moderation.Screen(ctx, moderated)
+	if len(flags) > 0 {
+		property.ModerationStatus = domain.ModerationPending
 	}
 
 	// 4. Guardar en repository
-	createdProperty, err := s.repo.Create(property)
+	createdProperty, err := s.repo.Create(ctx, property)
 	if err != nil {
 		return dto.PropertyResponseDTO{}, fmt.Errorf("error creando propiedad en repositorio: %w", err)
 	}
 
-	// 5. Publicar evento "create" en RabbitMQ
-	// Convertir ObjectID a string para el evento
-	propertyID := createdProperty.ID.Hex()
-	if err := s.rabbitClient.PublishPropertyEvent("create", propertyID); err != nil {
+	// La propiedad ya se guardó: la auditoría y el caso de moderación no se cortan si el cliente se desconecta
+	ctx = context.WithoutCancel(ctx)
+
+	// 5. Publicar evento "create" en RabbitMQ con el snapshot de la propiedad
+	response := s.toDTO(createdProperty)
+	s.audit.Record(ctx, createDTO.OwnerID, AuditActionPropertyCreate, auditEntityProperty, response.ID, nil, response)
+	if len(flags) > 0 {
+		if err := s.moderation.Quarantine(ctx, domain.ModerationEntityProperty, response.ID, createDTO.OwnerID, moderated, flags); err != nil {
+			fmt.Printf("⚠️ Error abriendo caso de moderación para propiedad %s: %v\n", response.ID, err)
+		}
+		return response, nil
+	}
+	if err := s.rabbitClient.PublishPropertySnapshotEvent("create", response); err != nil {
 		// Log del error pero no fallar la operación si el evento no se publica
 		// La propiedad ya fue creada exitosamente
-		fmt.Printf("⚠️ Error publicando evento 'create' en RabbitMQ para propiedad %s: %v\n", propertyID, err)
+		fmt.Printf("⚠️ Error publicando evento 'create' en RabbitMQ para propiedad %s: %v\n", response.ID, err)
 	}
 
 	// 6. Retornar DTO de respuesta
-	return s.toDTO(createdProperty), nil
+	return response, nil
 }
 
 // GetPropertyByID obtiene una propiedad por su ID
 // Retorna el DTO de respuesta o error si no se encuentra
-func (s *propertyService) GetPropertyByID(id string) (dto.PropertyResponseDTO, error) {
-	property, err := s.repo.GetByID(id)
+// Una propiedad retenida por moderación no se muestra (ni se indexa) hasta que un admin la apruebe
+func (s *propertyService) GetPropertyByID(ctx context.Context, id string) (dto.PropertyResponseDTO, error) {
+	property, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return dto.PropertyResponseDTO{}, fmt.Errorf("error obteniendo propiedad: %w", err)
 	}
+	if !property.IsPublished() {
+		return dto.PropertyResponseDTO{}, fmt.Errorf("error obteniendo propiedad: propiedad con ID '%s' no encontrada", id)
+	}
 
 	return s.toDTO(property), nil
 }
@@ -135,9 +277,9 @@ func (s *propertyService) GetPropertyByID(id string) (dto.PropertyResponseDTO, e
 // 3. Actualizar solo campos no vacíos
 // 4. Actualizar timestamp
 // 5. Publicar evento "update"
-func (s *propertyService) UpdateProperty(id string, updateDTO dto.PropertyUpdateDTO, userID string, isAdmin bool) error {
+func (s *propertyService) UpdateProperty(ctx context.Context, id string, updateDTO dto.PropertyUpdateDTO, userID string, isAdmin bool) error {
 	// 1. Obtener propiedad existente
-	property, err := s.repo.GetByID(id)
+	property, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("error obteniendo propiedad para actualizar: %w", err)
 	}
@@ -166,9 +308,45 @@ func (s *propertyService) UpdateProperty(id string, updateDTO dto.PropertyUpdate
 			updatedProperty.Capacity,
 		)
 	}
+	if updateDTO.CleaningFee != nil {
+		updatedProperty.CleaningFee = *updateDTO.CleaningFee
+	}
 	if updateDTO.Location != nil {
 		updatedProperty.Location = *updateDTO.Location
 	}
+	if updateDTO.PropertyType != nil {
+		propertyType, err := normalizePropertyType(*updateDTO.PropertyType)
+		if err != nil {
+			return err
+		}
+		updatedProperty.PropertyType = propertyType
+	}
+	if updateDTO.Latitude != nil {
+		updatedProperty.Latitude = *updateDTO.Latitude
+	}
+	if updateDTO.Longitude != nil {
+		updatedProperty.Longitude = *updateDTO.Longitude
+	}
+	if updateDTO.Timezone != nil {
+		if _, err := utils.LoadPropertyLocation(*updateDTO.Timezone); err != nil {
+			return err
+		}
+		updatedProperty.Timezone = *updateDTO.Timezone
+	}
+	if updateDTO.Country != nil {
+		country, err := utils.NormalizeCountryCode(*updateDTO.Country)
+		if err != nil {
+			return err
+		}
+		updatedProperty.Country = country
+	}
+	if updateDTO.Region != nil {
+		region, err := utils.NormalizeRegionCode(*updateDTO.Region)
+		if err != nil {
+			return err
+		}
+		updatedProperty.Region = region
+	}
 	if updateDTO.Amenities != nil {
 		updatedProperty.Amenities = *updateDTO.Amenities
 		// Si se actualizan las amenidades y hay precio, recalcular
@@ -194,33 +372,175 @@ func (s *propertyService) UpdateProperty(id string, updateDTO dto.PropertyUpdate
 	if updateDTO.Available != nil {
 		updatedProperty.Available = *updateDTO.Available
 	}
+	if updateDTO.BookingMode != nil {
+		// Las solicitudes ya pendientes siguen esperando la respuesta del anfitrión
+		updatedProperty.BookingMode = bookingModeOrDefault(*updateDTO.BookingMode)
+	}
+	if updateDTO.GuestsIncluded != nil {
+		updatedProperty.GuestsIncluded = *updateDTO.GuestsIncluded
+	}
+	if updateDTO.ExtraGuestFee != nil {
+		updatedProperty.ExtraGuestFee = *updateDTO.ExtraGuestFee
+	}
+	if updateDTO.ChildFriendly != nil {
+		updatedProperty.ChildFriendly = *updateDTO.ChildFriendly
+	}
+	if updateDTO.InfantFriendly != nil {
+		updatedProperty.InfantFriendly = *updateDTO.InfantFriendly
+	}
+	if updateDTO.CancellationPolicy != nil {
+		updatedProperty.CancellationPolicy = cancellationPolicyOrDefault(*updateDTO.CancellationPolicy)
+	}
+	if updateDTO.HouseRules != nil {
+		houseRules, err := houseRulesFromDTO(updateDTO.HouseRules)
+		if err != nil {
+			return err
+		}
+		updatedProperty.HouseRules = houseRules
+	}
+	if updateDTO.Language != nil {
+		language, err := languageFromDTO(*updateDTO.Language)
+		if err != nil {
+			return err
+		}
+		updatedProperty.Language = language
+	}
+	if updateDTO.Translations != nil {
+		translations, err := translationsFromDTO(propertyLanguageOrDefault(updatedProperty.Language), *updateDTO.Translations)
+		if err != nil {
+			return err
+		}
+		updatedProperty.Translations = translations
+	}
+	// Cambiar el idioma principal al de una traducción existente la dejaría repetida
+	if _, repeated := updatedProperty.Translations[propertyLanguageOrDefault(updatedProperty.Language)]; repeated {
+		return fmt.Errorf("%w: '%s' ya es una traducción de la propiedad", ErrInvalidTranslations, updatedProperty.Language)
+	}
 	if updateDTO.Images != nil {
-		updatedProperty.Images = *updateDTO.Images
+		photos, err := photosFromDTO(*updateDTO.Images)
+		if err != nil {
+			return err
+		}
+		updatedProperty.Images = keepUploadedPhotoData(property.Images, photos)
 	}
 
+	// Revisar el título, la descripción y las traducciones editados
+	quarantined, flags := s.moderateUpdate(ctx, property, &updatedProperty, updateDTO)
+
+	// La firma de similitud depende del título y la ubicación
+	updatedProperty.Signature = utils.PropertySignature(updatedProperty.Title, updatedProperty.Location, updatedProperty.OwnerID)
+
 	// 4. Actualizar timestamp
-	updatedProperty.UpdatedAt = time.Now()
+	updatedProperty.UpdatedAt = utils.NowUTC()
 
 	// Guardar la actualización en el repositorio
-	err = s.repo.Update(id, updatedProperty)
+	err = s.repo.Update(ctx, id, updatedProperty)
 	if err != nil {
 		return fmt.Errorf("error actualizando propiedad en repositorio: %w", err)
 	}
+	// La propiedad ya se guardó: el historial, la auditoría y el caso de moderación no se cortan si el cliente se desconecta
+	ctx = context.WithoutCancel(ctx)
+
+	// Registrar el cambio de precio en el historial
+	if updatedProperty.Price != property.Price {
+		entry := domain.PriceHistoryEntry{
+			PropertyID: id,
+			OldPrice:   property.Price,
+			NewPrice:   updatedProperty.Price,
+			ChangedBy:  userID,
+			ChangedAt:  updatedProperty.UpdatedAt,
+		}
+		if err := s.priceHistoryRepo.Create(ctx, entry); err != nil {
+			// Log del error pero no fallar la operación, el precio ya fue actualizado
+			fmt.Printf("⚠️ Error registrando historial de precio para propiedad %s: %v\n", id, err)
+		}
+	}
+
+	updatedResponse := s.toDTO(updatedProperty)
+	s.audit.Record(ctx, userID, AuditActionPropertyUpdate, auditEntityProperty, id, s.toDTO(property), updatedResponse)
 
-	// 5. Publicar evento "update"
-	if err := s.rabbitClient.PublishPropertyEvent("update", id); err != nil {
+	if quarantined != nil {
+		if err := s.moderation.Quarantine(ctx, domain.ModerationEntityProperty, id, userID, quarantined, flags); err != nil {
+			fmt.Printf("⚠️ Error abriendo caso de moderación para propiedad %s: %v\n", id, err)
+		}
+	}
+	if !updatedProperty.IsPublished() {
+		return nil
+	}
+
+	// 5. Publicar evento "update" con el snapshot actualizado
+	// Una propiedad retenida cuyo texto corregido pasó la moderación se publica por primera vez
+	operation := "update"
+	if !property.IsPublished() {
+		operation = "create"
+	}
+	if err := s.rabbitClient.PublishPropertySnapshotEvent(operation, updatedResponse); err != nil {
 		// Log del error pero no fallar la operación
-		fmt.Printf("⚠️ Error publicando evento 'update' en RabbitMQ para propiedad %s: %v\n", id, err)
+		fmt.Printf("⚠️ Error publicando evento '%s' en RabbitMQ para propiedad %s: %v\n", operation, id, err)
 	}
 
 	return nil
 }
 
+// moderateUpdate revisa el título, la descripción y las traducciones editados y decide qué se publica
+// - Propiedad publicada: el texto retenido no se aplica (sigue el anterior) hasta que un admin lo apruebe
+// - Propiedad sin publicar: se guarda el texto nuevo y se revisa completo; si pasa, la propiedad se publica
+// Una edición nueva del texto reemplaza a la que estaba retenida
+// Retorna el contenido a retener (nil si no hay que abrir un caso) y sus motivos
+func (s *propertyService) moderateUpdate(ctx context.Context, property domain.Property, updated *domain.Property, updateDTO dto.PropertyUpdateDTO) (map[string]string, []domain.ModerationFlag) {
+	if updateDTO.Title == nil && updateDTO.Description == nil && updateDTO.Translations == nil {
+		return nil, nil
+	}
+	id := property.ID.Hex()
+
+	if !property.IsPublished() {
+		content := propertyModerationContent(updated.Title, updated.Description, updated.Translations)
+		flags := s.moderation.Screen(ctx, content)
+		if len(flags) > 0 {
+			updated.ModerationStatus = domain.ModerationPending
+			return content, flags
+		}
+		s.moderation.SupersedePending(ctx, domain.ModerationEntityProperty, id)
+		updated.ModerationStatus = ""
+		return nil, nil
+	}
+
+	content := map[string]string{}
+	if updateDTO.Title != nil {
+		content[moderatedFieldTitle] = updated.Title
+	}
+	if updateDTO.Description != nil {
+		content[moderatedFieldDescription] = updated.Description
+	}
+	// Quitar todas las traducciones no agrega texto: se aplica sin revisar
+	heldTranslations := updateDTO.Translations != nil && len(updated.Translations) > 0
+	if heldTranslations {
+		addTranslationModerationContent(content, updated.Translations)
+	}
+	if len(content) == 0 {
+		return nil, nil
+	}
+	flags := s.moderation.Screen(ctx, content)
+	if len(flags) == 0 {
+		s.moderation.SupersedePending(ctx, domain.ModerationEntityProperty, id)
+		updated.ModerationStatus = ""
+		return nil, nil
+	}
+
+	updated.Title = property.Title
+	updated.Description = property.Description
+	if heldTranslations {
+		updated.Translations = property.Translations
+	}
+	updated.ModerationStatus = domain.ModerationChangesPending
+	return content, flags
+}
+
 // DeleteProperty elimina una propiedad con validación de ownership y admin
 // Valida que el usuario tenga permisos (owner o admin) y publica evento "delete"
-func (s *propertyService) DeleteProperty(id string, userID string, isAdmin bool) error {
+func (s *propertyService) DeleteProperty(ctx context.Context, id string, userID string, isAdmin bool) error {
 	// Obtener propiedad existente para validar ownership
-	property, err := s.repo.GetByID(id)
+	property, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("error obteniendo propiedad para eliminar: %w", err)
 	}
@@ -231,10 +551,12 @@ func (s *propertyService) DeleteProperty(id string, userID string, isAdmin bool)
 	}
 
 	// Eliminar la propiedad
-	err = s.repo.Delete(id)
+	err = s.repo.Delete(ctx, id)
 	if err != nil {
 		return fmt.Errorf("error eliminando propiedad en repositorio: %w", err)
 	}
+	// La propiedad ya se eliminó: la auditoría no se corta si el cliente se desconecta
+	s.audit.Record(context.WithoutCancel(ctx), userID, AuditActionPropertyDelete, auditEntityProperty, id, s.toDTO(property), nil)
 
 	// Publicar evento "delete"
 	if err := s.rabbitClient.PublishPropertyEvent("delete", id); err != nil {
@@ -247,13 +569,85 @@ func (s *propertyService) DeleteProperty(id string, userID string, isAdmin bool)
 
 // GetUserProperties obtiene todas las propiedades de un usuario específico
 // Retorna un slice de DTOs de respuesta o error
-func (s *propertyService) GetUserProperties(userID string) ([]dto.PropertyResponseDTO, error) {
-	properties, err := s.repo.GetByOwnerID(userID)
+func (s *propertyService) GetUserProperties(ctx context.Context, userID string) ([]dto.PropertyResponseDTO, error) {
+	properties, err := s.repo.GetByOwnerID(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("error obteniendo propiedades del usuario: %w", err)
 	}
 
-	// Convertir cada propiedad del dominio a DTO
+	// Convertir cada propiedad del dominio a DTO; las retenidas por moderación no se muestran
+	responseDTOs := make([]dto.PropertyResponseDTO, 0, len(properties))
+	for _, property := range properties {
+		if property.IsPublished() {
+			responseDTOs = append(responseDTOs, s.toDTO(property))
+		}
+	}
+
+	return responseDTOs, nil
+}
+
+// SearchUserProperties filtra, ordena y pagina en MongoDB las propiedades de un usuario
+// Sin canSeeUnpublished solo se listan las publicadas, igual que GetUserProperties
+func (s *propertyService) SearchUserProperties(ctx context.Context, userID string, query dto.UserPropertiesQuery, canSeeUnpublished bool) (dto.UserPropertiesResponseDTO, error) {
+	status := query.Status
+	if !canSeeUnpublished {
+		if status != "" && status != repositories.OwnerStatusPublished {
+			return dto.UserPropertiesResponseDTO{}, ErrUnpublishedForbidden
+		}
+		status = repositories.OwnerStatusPublished
+	}
+
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.Limit < 1 {
+		query.Limit = defaultUserPropertiesPageSize
+	}
+	sortField, sortDesc := query.Sort, false
+	if sortField == "" {
+		sortField, sortDesc = "createdAt", true
+	} else if strings.HasPrefix(sortField, "-") {
+		sortField, sortDesc = strings.TrimPrefix(sortField, "-"), true
+	}
+
+	properties, total, err := s.repo.FindByOwner(ctx, repositories.OwnerPropertyFilter{
+		OwnerID:   userID,
+		Title:     strings.TrimSpace(query.Query),
+		Available: query.Available,
+		Status:    status,
+		SortField: sortField,
+		SortDesc:  sortDesc,
+		Page:      query.Page,
+		Limit:     query.Limit,
+	})
+	if err != nil {
+		return dto.UserPropertiesResponseDTO{}, fmt.Errorf("error obteniendo propiedades del usuario: %w", err)
+	}
+
+	responseDTOs := make([]dto.PropertyResponseDTO, len(properties))
+	for i, property := range properties {
+		responseDTOs[i] = s.toDTO(property)
+	}
+
+	return dto.UserPropertiesResponseDTO{
+		Properties: responseDTOs,
+		Page:       query.Page,
+		Limit:      query.Limit,
+		Total:      total,
+		TotalPages: utils.CalculateTotalPages(total, query.Limit),
+	}, nil
+}
+
+// GetTrendingProperties obtiene las propiedades disponibles más vistas en los últimos days días
+// Las vistas se vuelcan periódicamente desde Memcached, así que pueden tener un pequeño retraso
+func (s *propertyService) GetTrendingProperties(ctx context.Context, days int, limit int) ([]dto.PropertyResponseDTO, error) {
+	since := utils.NowUTC().AddDate(0, 0, -days)
+
+	properties, err := s.repo.GetTrending(ctx, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo propiedades en tendencia: %w", err)
+	}
+
 	responseDTOs := make([]dto.PropertyResponseDTO, len(properties))
 	for i, property := range properties {
 		responseDTOs[i] = s.toDTO(property)
@@ -262,10 +656,29 @@ func (s *propertyService) GetUserProperties(userID string) ([]dto.PropertyRespon
 	return responseDTOs, nil
 }
 
+// ListPropertyVersions obtiene una página de versiones de propiedades ordenada por ID, después de afterID
+func (s *propertyService) ListPropertyVersions(ctx context.Context, afterID string, limit int) ([]dto.PropertyVersionDTO, error) {
+	properties, err := s.repo.ListVersions(ctx, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error listando versiones de propiedades: %w", err)
+	}
+
+	versions := make([]dto.PropertyVersionDTO, len(properties))
+	for i, property := range properties {
+		versions[i] = dto.PropertyVersionDTO{
+			ID:        property.ID.Hex(),
+			OwnerID:   property.OwnerID,
+			UpdatedAt: utils.FormatTimestamp(property.UpdatedAt),
+		}
+	}
+
+	return versions, nil
+}
+
 // GetAllProperties obtiene todas las propiedades del sistema (solo para admin)
 // Retorna un slice de DTOs de respuesta o error
-func (s *propertyService) GetAllProperties() ([]dto.PropertyResponseDTO, error) {
-	properties, err := s.repo.GetAll()
+func (s *propertyService) GetAllProperties(ctx context.Context) ([]dto.PropertyResponseDTO, error) {
+	properties, err := s.repo.GetAll(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("error obteniendo todas las propiedades: %w", err)
 	}
@@ -279,21 +692,195 @@ func (s *propertyService) GetAllProperties() ([]dto.PropertyResponseDTO, error)
 	return responseDTOs, nil
 }
 
+// StreamAllProperties recorre todas las propiedades del sistema (solo para admin)
+// Cada propiedad se convierte a DTO y se entrega a fn a medida que se lee del cursor
+func (s *propertyService) StreamAllProperties(ctx context.Context, fn func(dto.PropertyResponseDTO) error) error {
+	return s.repo.StreamAll(ctx, func(property domain.Property) error {
+		return fn(s.toDTO(property))
+	})
+}
+
+// GetPriceHistory obtiene el historial de precios de una propiedad
+// Incluye el precio mínimo y máximo histórico y las estadísticas de los últimos 30 días
+func (s *propertyService) GetPriceHistory(ctx context.Context, id string) (dto.PriceHistoryResponseDTO, error) {
+	property, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return dto.PriceHistoryResponseDTO{}, fmt.Errorf("error obteniendo propiedad: %w", err)
+	}
+
+	entries, err := s.priceHistoryRepo.GetByPropertyID(ctx, id)
+	if err != nil {
+		return dto.PriceHistoryResponseDTO{}, fmt.Errorf("error obteniendo historial de precios: %w", err)
+	}
+
+	return buildPriceHistory(property, entries, utils.NowUTC()), nil
+}
+
+// buildPriceHistory calcula las estadísticas de precio a partir del historial
+// Las entradas deben venir ordenadas de la más reciente a la más antigua
+func buildPriceHistory(property domain.Property, entries []domain.PriceHistoryEntry, now time.Time) dto.PriceHistoryResponseDTO {
+	response := dto.PriceHistoryResponseDTO{
+		PropertyID:   property.ID.Hex(),
+		CurrentPrice: property.Price,
+		MinPrice:     property.Price,
+		MaxPrice:     property.Price,
+		Last30Days: dto.PriceStatsDTO{
+			MinPrice: property.Price,
+			MaxPrice: property.Price,
+		},
+		History: make([]dto.PriceHistoryEntryDTO, len(entries)),
+	}
+
+	since := now.AddDate(0, 0, -30)
+	for i, entry := range entries {
+		response.History[i] = dto.PriceHistoryEntryDTO{
+			OldPrice:  entry.OldPrice,
+			NewPrice:  entry.NewPrice,
+			ChangedAt: utils.FormatTimestamp(entry.ChangedAt),
+		}
+
+		for _, price := range []float64{entry.OldPrice, entry.NewPrice} {
+			response.MinPrice = math.Min(response.MinPrice, price)
+			response.MaxPrice = math.Max(response.MaxPrice, price)
+		}
+
+		// El precio anterior estuvo vigente dentro de la ventana si el cambio ocurrió en ella
+		if !entry.ChangedAt.Before(since) {
+			response.Last30Days.Changes++
+			for _, price := range []float64{entry.OldPrice, entry.NewPrice} {
+				response.Last30Days.MinPrice = math.Min(response.Last30Days.MinPrice, price)
+				response.Last30Days.MaxPrice = math.Max(response.Last30Days.MaxPrice, price)
+			}
+		}
+	}
+
+	return response
+}
+
+// findDuplicate busca entre las propiedades del owner un duplicado de la propiedad nueva
+// Retorna ErrDuplicateProperty si la firma coincide, o el ID de la propiedad con título
+// parecido en la misma ubicación para marcarla como probable duplicado
+// Si no se pueden leer las propiedades del owner la creación sigue sin chequeo
+func (s *propertyService) findDuplicate(ctx context.Context, ownerID, title, location, signature string) (string, error) {
+	existing, err := s.repo.GetByOwnerID(ctx, ownerID)
+	if err != nil {
+		fmt.Printf("⚠️ Error buscando duplicados para el owner %s: %v\n", ownerID, err)
+		return "", nil
+	}
+	return matchDuplicate(existing, title, location, signature)
+}
+
+// matchDuplicate compara una propiedad nueva con las propiedades existentes del owner
+func matchDuplicate(existing []domain.Property, title, location, signature string) (string, error) {
+	normalizedLocation := utils.NormalizeText(location)
+	duplicateOf := ""
+	bestSimilarity := 0.0
+	for _, property := range existing {
+		// Las propiedades anteriores a la detección de duplicados no tienen firma guardada
+		existingSignature := property.Signature
+		if existingSignature == "" {
+			existingSignature = utils.PropertySignature(property.Title, property.Location, property.OwnerID)
+		}
+		if existingSignature == signature {
+			return "", fmt.Errorf("%w (ID '%s')", ErrDuplicateProperty, property.ID.Hex())
+		}
+
+		if utils.NormalizeText(property.Location) != normalizedLocation {
+			continue
+		}
+		if similarity := utils.TitleSimilarity(property.Title, title); similarity >= duplicateTitleSimilarity && similarity > bestSimilarity {
+			duplicateOf = property.ID.Hex()
+			bestSimilarity = similarity
+		}
+	}
+	return duplicateOf, nil
+}
+
 // toDTO es una función privada que convierte un Property del dominio a PropertyResponseDTO
 // Centraliza la lógica de conversión para evitar duplicación de código
 func (s *propertyService) toDTO(property domain.Property) dto.PropertyResponseDTO {
+	return toPropertyDTO(property)
+}
+
+// toPropertyDTO convierte un Property del dominio a PropertyResponseDTO
+// Las fotos se normalizan para que las propiedades anteriores a la galería ordenada tengan portada
+func toPropertyDTO(property domain.Property) dto.PropertyResponseDTO {
+	photos := normalizePhotos(property.Images)
+	thumbnail, thumbnailWebP := coverThumbnails(photos)
 	return dto.PropertyResponseDTO{
-		ID:          property.ID.Hex(),
-		Title:       property.Title,
-		Description: property.Description,
-		Price:       property.Price,
-		Location:    property.Location,
-		OwnerID:     property.OwnerID,
-		Amenities:   property.Amenities,
-		Capacity:    property.Capacity,
-		Available:   property.Available,
-		Images:      property.Images,
-		CreatedAt:   property.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:   property.UpdatedAt.Format(time.RFC3339),
+		ID:                 property.ID.Hex(),
+		Title:              property.Title,
+		Description:        property.Description,
+		Language:           propertyLanguageOrDefault(property.Language),
+		Price:              property.Price,
+		CleaningFee:        property.CleaningFee,
+		Location:           property.Location,
+		PropertyType:       property.PropertyType,
+		Latitude:           property.Latitude,
+		Longitude:          property.Longitude,
+		Timezone:           property.Timezone,
+		Country:            property.Country,
+		Region:             property.Region,
+		OwnerID:            property.OwnerID,
+		Amenities:          property.Amenities,
+		Capacity:           property.Capacity,
+		Available:          property.Available,
+		BookingMode:        bookingModeOrDefault(property.BookingMode),
+		Images:             toPhotoDTOs(photos),
+		CoverImage:         coverPhotoURL(photos),
+		CoverThumbnail:     thumbnail,
+		CoverThumbnailWebP: thumbnailWebP,
+		Views:              property.Views,
+		DuplicateOf:        property.DuplicateOf,
+		ModerationStatus:   property.ModerationStatus,
+		CreatedAt:          utils.FormatTimestamp(property.CreatedAt),
+		UpdatedAt:          utils.FormatTimestamp(property.UpdatedAt),
+		GuestsIncluded:     property.GuestsIncluded,
+		ExtraGuestFee:      property.ExtraGuestFee,
+		ChildFriendly:      property.ChildFriendly,
+		InfantFriendly:     property.InfantFriendly,
+		HouseRules:         toHouseRulesDTO(property.HouseRules),
+		CancellationPolicy: cancellationPolicyOrDefault(property.CancellationPolicy),
+		BookedRanges:       toDateRangeDTOs(property.BookedRanges),
+		NextAvailableDate:  property.NextAvailableDate,
+		Translations:       toTranslationDTOs(property.Translations),
+	}
+}
+
+// toDateRangeDTOs convierte los rangos ocupados (nunca nil, para que el JSON tenga una lista vacía)
+func toDateRangeDTOs(ranges []domain.DateRange) []dto.DateRangeDTO {
+	result := make([]dto.DateRangeDTO, 0, len(ranges))
+	for _, r := range ranges {
+		result = append(result, dto.DateRangeDTO{Start: r.Start, End: r.End})
+	}
+	return result
+}
+
+// bookingModeOrDefault retorna el modo de reserva; vacío (propiedades anteriores) es reserva instantánea
+func bookingModeOrDefault(mode string) string {
+	if mode == "" {
+		return domain.BookingModeInstant
+	}
+	return mode
+}
+
+// cancellationPolicyOrDefault retorna la política de cancelación; vacío (propiedades anteriores) es flexible
+func cancellationPolicyOrDefault(policy string) string {
+	if policy == "" {
+		return domain.CancellationFlexible
+	}
+	return policy
+}
+
+// normalizePropertyType valida el tipo de propiedad y lo retorna en minúsculas
+// Vacío significa que la propiedad no tiene tipo
+func normalizePropertyType(propertyType string) (string, error) {
+	propertyType = strings.ToLower(strings.TrimSpace(propertyType))
+	if propertyType == "" {
+		return "", nil
+	}
+	if err := utils.ValidatePropertyType(propertyType); err != nil {
+		return "", err
 	}
+	return propertyType, nil
 }
@@ -1,12 +1,22 @@
 package services
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"image"
+	"image/png"
+	"properties-api/clients"
+	"properties-api/config"
 	"properties-api/dto"
+	"properties-api/utils"
 	"properties-api/domain"
+	"properties-api/repositories"
+	"strings"
 	"testing"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -18,22 +28,43 @@ import (
 // Permite controlar el comportamiento de las operaciones de repositorio en los tests
 type mockRepository struct {
 	CreateFunc        func(property domain.Property) (domain.Property, error)
+	CreateManyFunc    func(ctx context.Context, properties []domain.Property) ([]error, error)
 	GetByIDFunc       func(id string) (domain.Property, error)
 	UpdateFunc        func(id string, property domain.Property) error
 	DeleteFunc        func(id string) error
 	GetByOwnerIDFunc  func(ownerID string) ([]domain.Property, error)
+	FindByOwnerFunc   func(ctx context.Context, filter repositories.OwnerPropertyFilter) ([]domain.Property, int64, error)
+	GetAllFunc        func() ([]domain.Property, error)
+	StreamAllFunc     func(ctx context.Context, fn func(domain.Property) error) error
+	StreamFilteredFunc func(ctx context.Context, filter repositories.PropertyFilter, fn func(domain.Property) error) error
+	SetAvailabilityByOwnerFunc func(ownerID string, available bool) (int64, error)
+	IncrementViewsFunc func(id string, delta int64) (int64, error)
+	GetTrendingFunc func(since time.Time, limit int) ([]domain.Property, error)
+	FindFlaggedDuplicatesFunc func(ctx context.Context) ([]domain.Property, error)
+	ClearDuplicateFlagFunc func(ctx context.Context, id string) error
+	ListVersionsFunc func(ctx context.Context, afterID string, limit int) ([]domain.Property, error)
+	SetPhotoVariantsFunc func(ctx context.Context, id, photoURL, status string, variants []domain.PhotoVariant) error
+	SetBookedRangesFunc func(ctx context.Context, id string, booked []domain.DateRange, nextAvailable string) error
 }
 
 // Create implementa PropertyRepository.Create
-func (m *mockRepository) Create(property domain.Property) (domain.Property, error) {
+func (m *mockRepository) Create(ctx context.Context, property domain.Property) (domain.Property, error) {
 	if m.CreateFunc != nil {
 		return m.CreateFunc(property)
 	}
 	return domain.Property{}, errors.New("CreateFunc not set")
 }
 
+// CreateMany implementa PropertyRepository.CreateMany
+func (m *mockRepository) CreateMany(ctx context.Context, properties []domain.Property) ([]error, error) {
+	if m.CreateManyFunc != nil {
+		return m.CreateManyFunc(ctx, properties)
+	}
+	return nil, errors.New("CreateManyFunc not set")
+}
+
 // GetByID implementa PropertyRepository.GetByID
-func (m *mockRepository) GetByID(id string) (domain.Property, error) {
+func (m *mockRepository) GetByID(ctx context.Context, id string) (domain.Property, error) {
 	if m.GetByIDFunc != nil {
 		return m.GetByIDFunc(id)
 	}
@@ -41,7 +72,7 @@ func (m *mockRepository) GetByID(id string) (domain.Property, error) {
 }
 
 // Update implementa PropertyRepository.Update
-func (m *mockRepository) Update(id string, property domain.Property) error {
+func (m *mockRepository) Update(ctx context.Context, id string, property domain.Property) error {
 	if m.UpdateFunc != nil {
 		return m.UpdateFunc(id, property)
 	}
@@ -49,7 +80,7 @@ func (m *mockRepository) Update(id string, property domain.Property) error {
 }
 
 // Delete implementa PropertyRepository.Delete
-func (m *mockRepository) Delete(id string) error {
+func (m *mockRepository) Delete(ctx context.Context, id string) error {
 	if m.DeleteFunc != nil {
 		return m.DeleteFunc(id)
 	}
@@ -57,13 +88,109 @@ func (m *mockRepository) Delete(id string) error {
 }
 
 // GetByOwnerID implementa PropertyRepository.GetByOwnerID
-func (m *mockRepository) GetByOwnerID(ownerID string) ([]domain.Property, error) {
+func (m *mockRepository) GetByOwnerID(ctx context.Context, ownerID string) ([]domain.Property, error) {
 	if m.GetByOwnerIDFunc != nil {
 		return m.GetByOwnerIDFunc(ownerID)
 	}
 	return nil, errors.New("GetByOwnerIDFunc not set")
 }
 
+// FindByOwner implementa PropertyRepository.FindByOwner
+func (m *mockRepository) FindByOwner(ctx context.Context, filter repositories.OwnerPropertyFilter) ([]domain.Property, int64, error) {
+	if m.FindByOwnerFunc != nil {
+		return m.FindByOwnerFunc(ctx, filter)
+	}
+	return nil, 0, errors.New("FindByOwnerFunc not set")
+}
+
+// GetAll implementa PropertyRepository.GetAll
+func (m *mockRepository) GetAll(ctx context.Context) ([]domain.Property, error) {
+	if m.GetAllFunc != nil {
+		return m.GetAllFunc()
+	}
+	return nil, errors.New("GetAllFunc not set")
+}
+
+// StreamAll implementa PropertyRepository.StreamAll
+func (m *mockRepository) StreamAll(ctx context.Context, fn func(domain.Property) error) error {
+	if m.StreamAllFunc != nil {
+		return m.StreamAllFunc(ctx, fn)
+	}
+	return errors.New("StreamAllFunc not set")
+}
+
+// StreamFiltered implementa PropertyRepository.StreamFiltered
+func (m *mockRepository) StreamFiltered(ctx context.Context, filter repositories.PropertyFilter, fn func(domain.Property) error) error {
+	if m.StreamFilteredFunc != nil {
+		return m.StreamFilteredFunc(ctx, filter, fn)
+	}
+	return errors.New("StreamFilteredFunc not set")
+}
+
+// SetAvailabilityByOwner implementa PropertyRepository.SetAvailabilityByOwner
+func (m *mockRepository) SetAvailabilityByOwner(ctx context.Context, ownerID string, available bool) (int64, error) {
+	if m.SetAvailabilityByOwnerFunc != nil {
+		return m.SetAvailabilityByOwnerFunc(ownerID, available)
+	}
+	return 0, errors.New("SetAvailabilityByOwnerFunc not set")
+}
+
+// IncrementViews implementa PropertyRepository.IncrementViews
+func (m *mockRepository) IncrementViews(ctx context.Context, id string, delta int64) (int64, error) {
+	if m.IncrementViewsFunc != nil {
+		return m.IncrementViewsFunc(id, delta)
+	}
+	return 0, errors.New("IncrementViewsFunc not set")
+}
+
+// GetTrending implementa PropertyRepository.GetTrending
+func (m *mockRepository) GetTrending(ctx context.Context, since time.Time, limit int) ([]domain.Property, error) {
+	if m.GetTrendingFunc != nil {
+		return m.GetTrendingFunc(since, limit)
+	}
+	return nil, errors.New("GetTrendingFunc not set")
+}
+
+// FindFlaggedDuplicates implementa PropertyRepository.FindFlaggedDuplicates
+func (m *mockRepository) FindFlaggedDuplicates(ctx context.Context) ([]domain.Property, error) {
+	if m.FindFlaggedDuplicatesFunc != nil {
+		return m.FindFlaggedDuplicatesFunc(ctx)
+	}
+	return nil, errors.New("FindFlaggedDuplicatesFunc not set")
+}
+
+// ClearDuplicateFlag implementa PropertyRepository.ClearDuplicateFlag
+func (m *mockRepository) ClearDuplicateFlag(ctx context.Context, id string) error {
+	if m.ClearDuplicateFlagFunc != nil {
+		return m.ClearDuplicateFlagFunc(ctx, id)
+	}
+	return errors.New("ClearDuplicateFlagFunc not set")
+}
+
+// ListVersions implementa PropertyRepository.ListVersions
+func (m *mockRepository) ListVersions(ctx context.Context, afterID string, limit int) ([]domain.Property, error) {
+	if m.ListVersionsFunc != nil {
+		return m.ListVersionsFunc(ctx, afterID, limit)
+	}
+	return nil, errors.New("ListVersionsFunc not set")
+}
+
+// SetPhotoVariants implementa PropertyRepository.SetPhotoVariants
+func (m *mockRepository) SetPhotoVariants(ctx context.Context, id, photoURL, status string, variants []domain.PhotoVariant) error {
+	if m.SetPhotoVariantsFunc != nil {
+		return m.SetPhotoVariantsFunc(ctx, id, photoURL, status, variants)
+	}
+	return errors.New("SetPhotoVariantsFunc not set")
+}
+
+// SetBookedRanges implementa PropertyRepository.SetBookedRanges
+func (m *mockRepository) SetBookedRanges(ctx context.Context, id string, booked []domain.DateRange, nextAvailable string) error {
+	if m.SetBookedRangesFunc != nil {
+		return m.SetBookedRangesFunc(ctx, id, booked, nextAvailable)
+	}
+	return errors.New("SetBookedRangesFunc not set")
+}
+
 // mockUsersClient es un mock de UsersClient
 // Permite controlar el comportamiento de la validación de usuarios en los tests
 type mockUsersClient struct {
@@ -71,7 +198,7 @@ type mockUsersClient struct {
 }
 
 // ValidateUser implementa UsersClient.ValidateUser
-func (m *mockUsersClient) ValidateUser(userID string) (bool, error) {
+func (m *mockUsersClient) ValidateUser(ctx context.Context, userID string) (bool, error) {
 	if m.ValidateUserFunc != nil {
 		return m.ValidateUserFunc(userID)
 	}
@@ -81,7 +208,15 @@ func (m *mockUsersClient) ValidateUser(userID string) (bool, error) {
 // mockRabbitClient es un mock de RabbitMQClient
 // Permite controlar el comportamiento de la publicación de eventos en los tests
 type mockRabbitClient struct {
-	PublishPropertyEventFunc func(operation string, propertyID string) error
+	PublishPropertyEventFunc         func(operation string, propertyID string) error
+	PublishPropertySnapshotEventFunc func(operation string, property dto.PropertyResponseDTO) error
+	PublishPopularityEventFunc       func(event clients.PropertyPopularityEvent) error
+	PublishMessageEventFunc          func(event clients.MessageSentEvent) error
+	PublishBookingEventFunc          func(event clients.BookingConfirmedEvent) error
+	PublishImageJobFunc              func(job clients.ImageJob) error
+	PublishAvailabilityEventFunc     func(event clients.AvailabilityUpdatedEvent) error
+	PublishBookingRequestEventFunc   func(routingKey string, event clients.BookingRequestEvent) error
+	PublishAvailabilityChangedEventFunc func(event clients.AvailabilityChangedEvent) error
 }
 
 // PublishPropertyEvent implementa RabbitMQClient.PublishPropertyEvent
@@ -92,383 +227,3544 @@ func (m *mockRabbitClient) PublishPropertyEvent(operation string, propertyID str
 	return nil // Por defecto no retorna error para no bloquear tests
 }
 
-// ============================================
-// HELPERS
-// ============================================
-
-// createTestProperty crea una propiedad de prueba para usar en los tests
-func createTestProperty(id string, ownerID string) domain.Property {
-	objectID := primitive.NewObjectID()
-	if id != "" {
-		var err error
-		objectID, err = primitive.ObjectIDFromHex(id)
-		if err != nil {
-			objectID = primitive.NewObjectID()
-		}
+// PublishPropertySnapshotEvent implementa RabbitMQClient.PublishPropertySnapshotEvent
+func (m *mockRabbitClient) PublishPropertySnapshotEvent(operation string, property dto.PropertyResponseDTO) error {
+	if m.PublishPropertySnapshotEventFunc != nil {
+		return m.PublishPropertySnapshotEventFunc(operation, property)
 	}
+	return nil
+}
 
-	now := time.Now().Format(time.RFC3339)
-	return domain.Property{
-		ID:          objectID,
-		Title:       "Test Property",
-		Description: "Test Description",
-		Price:       1000.0,
-		Location:    "Test Location",
-		OwnerID:     ownerID,
-		Amenities:   []string{"wifi", "pool"},
-		Capacity:    4,
-		Available:  true,
-		CreatedAt:   now,
-		UpdatedAt:   now,
+// PublishPopularityEvent implementa RabbitMQClient.PublishPopularityEvent
+func (m *mockRabbitClient) PublishPopularityEvent(event clients.PropertyPopularityEvent) error {
+	if m.PublishPopularityEventFunc != nil {
+		return m.PublishPopularityEventFunc(event)
 	}
+	return nil
 }
 
-// createTestCreateDTO crea un DTO de creación de prueba
-func createTestCreateDTO(ownerID string) dto.PropertyCreateDTO {
-	return dto.PropertyCreateDTO{
-		Title:       "Test Property",
-		Description: "Test Description",
-		Price:       1000.0,
-		Location:    "Test Location",
-		OwnerID:     ownerID,
-		Amenities:   []string{"wifi", "pool"},
-		Capacity:    4,
-		Available:  true,
+// PublishMessageEvent implementa RabbitMQClient.PublishMessageEvent
+func (m *mockRabbitClient) PublishMessageEvent(event clients.MessageSentEvent) error {
+	if m.PublishMessageEventFunc != nil {
+		return m.PublishMessageEventFunc(event)
 	}
+	return nil
 }
 
-// ============================================
-// TESTS
-// ============================================
+// PublishBookingEvent implementa RabbitMQClient.PublishBookingEvent
+func (m *mockRabbitClient) PublishBookingEvent(event clients.BookingConfirmedEvent) error {
+	if m.PublishBookingEventFunc != nil {
+		return m.PublishBookingEventFunc(event)
+	}
+	return nil
+}
 
-// TestCreateProperty_Success testa el caso exitoso de creación de propiedad
-func TestCreateProperty_Success(t *testing.T) {
-	// Arrange
-	ownerID := "user123"
-	propertyID := primitive.NewObjectID().Hex()
+// PublishBookingRequestEvent implementa RabbitMQClient.PublishBookingRequestEvent
+func (m *mockRabbitClient) PublishBookingRequestEvent(routingKey string, event clients.BookingRequestEvent) error {
+	if m.PublishBookingRequestEventFunc != nil {
+		return m.PublishBookingRequestEventFunc(routingKey, event)
+	}
+	return nil
+}
 
-	mockRepo := &mockRepository{
-		CreateFunc: func(property domain.Property) (domain.Property, error) {
-			// El servicio calcula el precio antes de crear, así que retornamos la propiedad con el precio calculado
-			createdProperty := property
-			createdProperty.ID, _ = primitive.ObjectIDFromHex(propertyID)
-			return createdProperty, nil
-		},
+// PublishImageJob implementa RabbitMQClient.PublishImageJob
+func (m *mockRabbitClient) PublishImageJob(job clients.ImageJob) error {
+	if m.PublishImageJobFunc != nil {
+		return m.PublishImageJobFunc(job)
 	}
+	return nil
+}
 
-	mockUsersClient := &mockUsersClient{
-		ValidateUserFunc: func(userID string) (bool, error) {
-			return true, nil // Usuario existe
-		},
+// PublishAvailabilityEvent implementa RabbitMQClient.PublishAvailabilityEvent
+func (m *mockRabbitClient) PublishAvailabilityEvent(event clients.AvailabilityUpdatedEvent) error {
+	if m.PublishAvailabilityEventFunc != nil {
+		return m.PublishAvailabilityEventFunc(event)
 	}
+	return nil
+}
 
-	mockRabbitClient := &mockRabbitClient{
-		PublishPropertyEventFunc: func(operation string, propertyID string) error {
-			return nil
-		},
+// PublishAvailabilityChangedEvent implementa RabbitMQClient.PublishAvailabilityChangedEvent
+func (m *mockRabbitClient) PublishAvailabilityChangedEvent(event clients.AvailabilityChangedEvent) error {
+	if m.PublishAvailabilityChangedEventFunc != nil {
+		return m.PublishAvailabilityChangedEventFunc(event)
 	}
+	return nil
+}
 
-	service := NewPropertyService(mockRepo, mockUsersClient, mockRabbitClient)
-	createDTO := createTestCreateDTO(ownerID)
+// Ping implementa RabbitMQClient.Ping (la conexión mock siempre está abierta)
+func (m *mockRabbitClient) Ping() error {
+	return nil
+}
 
-	// Act
-	result, err := service.CreateProperty(createDTO)
+// mockViewCounter es un mock en memoria de ViewCounterRepository
+type mockViewCounter struct {
+	views map[string]uint64
+}
 
-	// Assert
-	if err != nil {
-		t.Fatalf("Expected no error, got %v", err)
-	}
+// Increment implementa ViewCounterRepository.Increment
+func (m *mockViewCounter) Increment(propertyID string, delta uint64) error {
+	m.views[propertyID] += delta
+	return nil
+}
 
-	if result.ID == "" {
-		t.Error("Expected property ID to be set")
-	}
+// Take implementa ViewCounterRepository.Take
+func (m *mockViewCounter) Take(propertyID string) (int64, error) {
+	pending := m.views[propertyID]
+	m.views[propertyID] = 0
+	return int64(pending), nil
+}
 
-	if result.OwnerID != ownerID {
-		t.Errorf("Expected OwnerID %s, got %s", ownerID, result.OwnerID)
-	}
+// Ping implementa ViewCounterRepository.Ping
+func (m *mockViewCounter) Ping() error {
+	return nil
+}
 
-	// Verificar que el precio fue calculado con concurrencia (incluye impuestos y extras)
-	// El cálculo incluye: precio base * 1.21 + ($50 * amenidades) + ($30 * capacidad)
-	// Con precio base 1000, 2 amenidades, capacidad 4:
-	// 1000 * 1.21 + (2 * 50) + (4 * 30) = 1210 + 100 + 120 = 1430
-	expectedMinPrice := createDTO.Price * 1.21 // Al menos precio base con impuestos
-	if result.Price < expectedMinPrice {
-		t.Errorf("Expected price to be calculated with concurrency (at least %.2f), got %.2f", expectedMinPrice, result.Price)
+// mockPriceHistoryRepository es un mock en memoria de PriceHistoryRepository
+type mockPriceHistoryRepository struct {
+	entries []domain.PriceHistoryEntry
+}
+
+// Create implementa PriceHistoryRepository.Create
+func (m *mockPriceHistoryRepository) Create(ctx context.Context, entry domain.PriceHistoryEntry) error {
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+// GetByPropertyID implementa PriceHistoryRepository.GetByPropertyID
+func (m *mockPriceHistoryRepository) GetByPropertyID(ctx context.Context, propertyID string) ([]domain.PriceHistoryEntry, error) {
+	var result []domain.PriceHistoryEntry
+	for _, entry := range m.entries {
+		if entry.PropertyID == propertyID {
+			result = append(result, entry)
+		}
 	}
+	return result, nil
 }
 
-// TestCreateProperty_UserNotFound testa el caso cuando el owner no existe
-func TestCreateProperty_UserNotFound(t *testing.T) {
-	// Arrange
-	ownerID := "nonexistent-user"
-	mockRepo := &mockRepository{}
+// mockBookingRepository es un mock en memoria de BookingRepository
+type mockBookingRepository struct {
+	bookings []domain.Booking
+	stats    domain.BookingStats
+	// nights son las noches tomadas por propiedad ("propertyId/YYYY-MM-DD"), como el índice único de booking_nights
+	nights map[string]string
+}
 
-	mockUsersClient := &mockUsersClient{
-		ValidateUserFunc: func(userID string) (bool, error) {
-			return false, nil // Usuario no existe
-		},
+// Create implementa BookingRepository.Create (sin estado la guarda confirmada, como el repositorio real)
+// Falla con ErrBookingNightsTaken si alguna noche ya está tomada
+func (m *mockBookingRepository) Create(ctx context.Context, booking *domain.Booking, nights []string) error {
+	if m.nights == nil {
+		m.nights = make(map[string]string)
+	}
+	for _, night := range nights {
+		if _, taken := m.nights[booking.PropertyID+"/"+night]; taken {
+			return repositories.ErrBookingNightsTaken
+		}
+	}
+	if booking.ID.IsZero() {
+		booking.ID = primitive.NewObjectID()
+	}
+	if booking.Status == "" {
+		booking.Status = domain.BookingConfirmed
+	}
+	for _, night := range nights {
+		m.nights[booking.PropertyID+"/"+night] = booking.ID.Hex()
 	}
+	m.bookings = append(m.bookings, *booking)
+	return nil
+}
 
-	mockRabbitClient := &mockRabbitClient{}
+// FindByUserID implementa BookingRepository.FindByUserID
+func (m *mockBookingRepository) FindByUserID(ctx context.Context, userID string) ([]domain.Booking, error) {
+	var result []domain.Booking
+	for _, booking := range m.bookings {
+		if booking.UserID == userID {
+			result = append(result, booking)
+		}
+	}
+	return result, nil
+}
 
-	service := NewPropertyService(mockRepo, mockUsersClient, mockRabbitClient)
-	createDTO := createTestCreateDTO(ownerID)
+// FindByID implementa BookingRepository.FindByID
+func (m *mockBookingRepository) FindByID(ctx context.Context, id string) (*domain.Booking, error) {
+	for i := range m.bookings {
+		if m.bookings[i].ID.Hex() == id {
+			return &m.bookings[i], nil
+		}
+	}
+	return nil, errors.New("booking not found")
+}
 
-	// Act
-	result, err := service.CreateProperty(createDTO)
+// StreamByPropertyIDs implementa BookingRepository.StreamByPropertyIDs
+func (m *mockBookingRepository) StreamByPropertyIDs(ctx context.Context, propertyIDs []string, fn func(domain.Booking) error) error {
+	for _, booking := range m.bookings {
+		for _, propertyID := range propertyIDs {
+			if booking.PropertyID == propertyID {
+				if err := fn(booking); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
 
-	// Assert
-	if err == nil {
-		t.Fatal("Expected error when user does not exist")
+// StreamFiltered implementa BookingRepository.StreamFiltered (solo filtra por propertyId, userId y status)
+func (m *mockBookingRepository) StreamFiltered(ctx context.Context, filter repositories.BookingFilter, fn func(domain.Booking) error) error {
+	for _, booking := range m.bookings {
+		if (filter.PropertyID != "" && booking.PropertyID != filter.PropertyID) ||
+			(filter.UserID != "" && booking.UserID != filter.UserID) ||
+			(filter.Status != "" && booking.Status != filter.Status) {
+			continue
+		}
+		if err := fn(booking); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	if result.ID != "" {
-		t.Error("Expected empty result when creation fails")
+// ReplaceUserID implementa BookingRepository.ReplaceUserID
+func (m *mockBookingRepository) ReplaceUserID(ctx context.Context, userID string, replacement string) (int64, error) {
+	var modified int64
+	for i := range m.bookings {
+		if m.bookings[i].UserID == userID {
+			m.bookings[i].UserID = replacement
+			modified++
+		}
 	}
+	return modified, nil
+}
 
-	// Verificar que el error contiene información sobre el usuario no encontrado
-	if !contains(err.Error(), "no existe") && !contains(err.Error(), "not found") {
-		t.Errorf("Expected error message about user not found, got: %v", err)
+// ReplacePropertyID implementa BookingRepository.ReplacePropertyID
+func (m *mockBookingRepository) ReplacePropertyID(ctx context.Context, propertyID string, replacement string) (int64, error) {
+	var modified int64
+	for i := range m.bookings {
+		if m.bookings[i].PropertyID == propertyID {
+			m.bookings[i].PropertyID = replacement
+			modified++
+		}
 	}
+	return modified, nil
 }
 
-// TestGetPropertyByID_Success testa obtener una propiedad existente
-func TestGetPropertyByID_Success(t *testing.T) {
-	// Arrange
-	propertyID := primitive.NewObjectID().Hex()
-	ownerID := "user123"
-	expectedProperty := createTestProperty(propertyID, ownerID)
+// AggregateStats implementa BookingRepository.AggregateStats retornando las estadísticas configuradas
+func (m *mockBookingRepository) AggregateStats(ctx context.Context, propertyID string, from, to time.Time) (domain.BookingStats, error) {
+	return m.stats, nil
+}
 
-	mockRepo := &mockRepository{
-		GetByIDFunc: func(id string) (domain.Property, error) {
-			if id == propertyID {
-				return expectedProperty, nil
+// UpdateStatus implementa BookingRepository.UpdateStatus
+func (m *mockBookingRepository) UpdateStatus(ctx context.Context, id string, from, to string) (bool, error) {
+	for i := range m.bookings {
+		if m.bookings[i].ID.Hex() == id && m.bookings[i].Status == from {
+			m.bookings[i].Status = to
+			if !m.bookings[i].IsActive() {
+				for night, bookingID := range m.nights {
+					if bookingID == id {
+						delete(m.nights, night)
+					}
+				}
 			}
-			return domain.Property{}, errors.New("property not found")
-		},
+			return true, nil
+		}
 	}
+	return false, nil
+}
 
-	mockUsersClient := &mockUsersClient{}
-	mockRabbitClient := &mockRabbitClient{}
+// FindExpiredPending implementa BookingRepository.FindExpiredPending
+func (m *mockBookingRepository) FindExpiredPending(ctx context.Context, now time.Time, limit int) ([]domain.Booking, error) {
+	var result []domain.Booking
+	for _, booking := range m.bookings {
+		if booking.Status == domain.BookingPending && booking.ExpiresAt != nil && !booking.ExpiresAt.After(now) && len(result) < limit {
+			result = append(result, booking)
+		}
+	}
+	return result, nil
+}
 
-	service := NewPropertyService(mockRepo, mockUsersClient, mockRabbitClient)
+// CreateFromHold implementa BookingRepository.CreateFromHold (las noches ya están tomadas con el ID del hold)
+func (m *mockBookingRepository) CreateFromHold(ctx context.Context, booking *domain.Booking, holdID primitive.ObjectID) error {
+	booking.ID = holdID
+	if booking.Status == "" {
+		booking.Status = domain.BookingConfirmed
+	}
+	m.bookings = append(m.bookings, *booking)
+	return nil
+}
 
-	// Act
-	result, err := service.GetPropertyByID(propertyID)
+// mockBookingHoldRepository es un mock en memoria de BookingHoldRepository
+// Toma las noches en el mismo mapa que mockBookingRepository, como la colección booking_nights compartida
+type mockBookingHoldRepository struct {
+	bookings *mockBookingRepository
+	holds    []domain.BookingHold
+}
 
-	// Assert
-	if err != nil {
-		t.Fatalf("Expected no error, got %v", err)
+// Create implementa BookingHoldRepository.Create
+func (m *mockBookingHoldRepository) Create(ctx context.Context, hold *domain.BookingHold, nights []string) error {
+	if m.bookings.nights == nil {
+		m.bookings.nights = make(map[string]string)
 	}
+	for _, night := range nights {
+		if _, taken := m.bookings.nights[hold.PropertyID+"/"+night]; taken {
+			return repositories.ErrBookingNightsTaken
+		}
+	}
+	hold.ID = primitive.NewObjectID()
+	for _, night := range nights {
+		m.bookings.nights[hold.PropertyID+"/"+night] = hold.ID.Hex()
+	}
+	m.holds = append(m.holds, *hold)
+	return nil
+}
 
-	if result.ID != propertyID {
-		t.Errorf("Expected ID %s, got %s", propertyID, result.ID)
+// FindByID implementa BookingHoldRepository.FindByID
+func (m *mockBookingHoldRepository) FindByID(ctx context.Context, id string) (*domain.BookingHold, error) {
+	for i := range m.holds {
+		if m.holds[i].ID.Hex() == id {
+			hold := m.holds[i]
+			return &hold, nil
+		}
 	}
+	return nil, nil
+}
 
-	if result.Title != expectedProperty.Title {
-		t.Errorf("Expected Title %s, got %s", expectedProperty.Title, result.Title)
+// Claim implementa BookingHoldRepository.Claim
+func (m *mockBookingHoldRepository) Claim(ctx context.Context, id primitive.ObjectID, now time.Time) (bool, error) {
+	for i := range m.holds {
+		if m.holds[i].ID == id && m.holds[i].ExpiresAt.After(now) {
+			m.holds = append(m.holds[:i], m.holds[i+1:]...)
+			return true, nil
+		}
 	}
+	return false, nil
 }
 
-// TestGetPropertyByID_NotFound testa obtener una propiedad inexistente
-func TestGetPropertyByID_NotFound(t *testing.T) {
-	// Arrange
-	propertyID := "nonexistent-id"
+// Release implementa BookingHoldRepository.Release
+func (m *mockBookingHoldRepository) Release(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	for i := range m.holds {
+		if m.holds[i].ID == id {
+			m.holds = append(m.holds[:i], m.holds[i+1:]...)
+			m.releaseNights(id)
+			return true, nil
+		}
+	}
+	return false, nil
+}
 
-	mockRepo := &mockRepository{
-		GetByIDFunc: func(id string) (domain.Property, error) {
-			return domain.Property{}, errors.New("property not found")
-		},
+// ReleaseExpired implementa BookingHoldRepository.ReleaseExpired
+func (m *mockBookingHoldRepository) ReleaseExpired(ctx context.Context, now time.Time, limit int) (int, error) {
+	var kept []domain.BookingHold
+	released := 0
+	for _, hold := range m.holds {
+		if !hold.ExpiresAt.After(now) && released < limit {
+			m.releaseNights(hold.ID)
+			released++
+			continue
+		}
+		kept = append(kept, hold)
+	}
+	m.holds = kept
+	return released, nil
+}
+
+// releaseNights libera las noches tomadas con el ID del hold
+func (m *mockBookingHoldRepository) releaseNights(id primitive.ObjectID) {
+	for night, owner := range m.bookings.nights {
+		if owner == id.Hex() {
+			delete(m.bookings.nights, night)
+		}
 	}
+}
 
-	mockUsersClient := &mockUsersClient{}
-	mockRabbitClient := &mockRabbitClient{}
+// mockAuditRepository es un mock de AuditRepository que guarda los registros en memoria
+type mockAuditRepository struct {
+	entries []domain.AuditEntry
+}
+
+// Create implementa AuditRepository.Create guardando el registro
+func (m *mockAuditRepository) Create(ctx context.Context, entry *domain.AuditEntry) error {
+	m.entries = append(m.entries, *entry)
+	return nil
+}
 
-	service := NewPropertyService(mockRepo, mockUsersClient, mockRabbitClient)
+// Find implementa AuditRepository.Find retornando todos los registros guardados
+func (m *mockAuditRepository) Find(ctx context.Context, filter repositories.AuditFilter) ([]domain.AuditEntry, int64, error) {
+	return m.entries, int64(len(m.entries)), nil
+}
 
-	// Act
-	result, err := service.GetPropertyByID(propertyID)
+// mockAvailabilityRepository es un mock en memoria de AvailabilityRepository
+type mockAvailabilityRepository struct {
+	blocks []domain.AvailabilityBlock
+}
 
-	// Assert
-	if err == nil {
-		t.Fatal("Expected error when property does not exist")
+// FindByPropertyID implementa AvailabilityRepository.FindByPropertyID
+func (m *mockAvailabilityRepository) FindByPropertyID(ctx context.Context, propertyID string) ([]domain.AvailabilityBlock, error) {
+	var result []domain.AvailabilityBlock
+	for _, block := range m.blocks {
+		if block.PropertyID == propertyID {
+			result = append(result, block)
+		}
 	}
+	return result, nil
+}
 
-	if result.ID != "" {
-		t.Error("Expected empty result when property not found")
+// ReplaceFeedBlocks implementa AvailabilityRepository.ReplaceFeedBlocks
+func (m *mockAvailabilityRepository) ReplaceFeedBlocks(ctx context.Context, feedID primitive.ObjectID, blocks []domain.AvailabilityBlock) error {
+	kept := m.blocks[:0]
+	for _, block := range m.blocks {
+		if block.FeedID != feedID {
+			kept = append(kept, block)
+		}
 	}
+	for _, block := range blocks {
+		block.ID = primitive.NewObjectID()
+		block.FeedID = feedID
+		kept = append(kept, block)
+	}
+	m.blocks = kept
+	return nil
+}
 
-	// Verificar que el error contiene información sobre la propiedad no encontrada
-	if !contains(err.Error(), "no encontrada") && !contains(err.Error(), "not found") {
-		t.Errorf("Expected error message about property not found, got: %v", err)
+// ReplaceManualBlocks implementa AvailabilityRepository.ReplaceManualBlocks
+func (m *mockAvailabilityRepository) ReplaceManualBlocks(ctx context.Context, propertyID string, blocks []domain.AvailabilityBlock) error {
+	kept := m.blocks[:0]
+	for _, block := range m.blocks {
+		if block.PropertyID != propertyID || !block.FeedID.IsZero() {
+			kept = append(kept, block)
+		}
 	}
+	for _, block := range blocks {
+		block.ID = primitive.NewObjectID()
+		block.PropertyID = propertyID
+		kept = append(kept, block)
+	}
+	m.blocks = kept
+	return nil
 }
 
-// TestUpdateProperty_Unauthorized testa actualización sin permisos
-func TestUpdateProperty_Unauthorized(t *testing.T) {
-	// Arrange
-	propertyID := primitive.NewObjectID().Hex()
-	ownerID := "owner123"
+// mockCalendarFeedRepository es un mock en memoria de CalendarFeedRepository
+type mockCalendarFeedRepository struct {
+	feeds []domain.CalendarFeed
+}
+
+// Create implementa CalendarFeedRepository.Create
+func (m *mockCalendarFeedRepository) Create(ctx context.Context, feed *domain.CalendarFeed) error {
+	feed.ID = primitive.NewObjectID()
+	m.feeds = append(m.feeds, *feed)
+	return nil
+}
+
+// FindByID implementa CalendarFeedRepository.FindByID
+func (m *mockCalendarFeedRepository) FindByID(ctx context.Context, id string) (*domain.CalendarFeed, error) {
+	for i := range m.feeds {
+		if m.feeds[i].ID.Hex() == id {
+			return &m.feeds[i], nil
+		}
+	}
+	return nil, errors.New("feed not found")
+}
+
+// FindByPropertyID implementa CalendarFeedRepository.FindByPropertyID
+func (m *mockCalendarFeedRepository) FindByPropertyID(ctx context.Context, propertyID string) ([]domain.CalendarFeed, error) {
+	var result []domain.CalendarFeed
+	for _, feed := range m.feeds {
+		if feed.PropertyID == propertyID {
+			result = append(result, feed)
+		}
+	}
+	return result, nil
+}
+
+// StreamAll implementa CalendarFeedRepository.StreamAll
+func (m *mockCalendarFeedRepository) StreamAll(ctx context.Context, fn func(domain.CalendarFeed) error) error {
+	for _, feed := range m.feeds {
+		if err := fn(feed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateSyncStatus implementa CalendarFeedRepository.UpdateSyncStatus
+func (m *mockCalendarFeedRepository) UpdateSyncStatus(ctx context.Context, id primitive.ObjectID, syncedAt *time.Time, syncErr string) error {
+	for i := range m.feeds {
+		if m.feeds[i].ID == id {
+			if syncedAt != nil {
+				m.feeds[i].LastSyncedAt = syncedAt
+			}
+			m.feeds[i].LastError = syncErr
+		}
+	}
+	return nil
+}
+
+// Delete implementa CalendarFeedRepository.Delete
+func (m *mockCalendarFeedRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	for i := range m.feeds {
+		if m.feeds[i].ID == id {
+			m.feeds = append(m.feeds[:i], m.feeds[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// mockConversationRepository es un mock en memoria de ConversationRepository
+type mockConversationRepository struct {
+	conversations []domain.Conversation
+}
+
+// FindOrCreate implementa ConversationRepository.FindOrCreate
+func (m *mockConversationRepository) FindOrCreate(ctx context.Context, conversation *domain.Conversation) error {
+	for _, existing := range m.conversations {
+		if existing.PropertyID == conversation.PropertyID && existing.GuestID == conversation.GuestID && existing.HostID == conversation.HostID {
+			*conversation = existing
+			return nil
+		}
+	}
+	conversation.ID = primitive.NewObjectID()
+	m.conversations = append(m.conversations, *conversation)
+	return nil
+}
+
+// FindByID implementa ConversationRepository.FindByID
+func (m *mockConversationRepository) FindByID(ctx context.Context, id string) (*domain.Conversation, error) {
+	for i := range m.conversations {
+		if m.conversations[i].ID.Hex() == id {
+			conversation := m.conversations[i]
+			return &conversation, nil
+		}
+	}
+	return nil, errors.New("conversation not found")
+}
+
+// FindByParticipant implementa ConversationRepository.FindByParticipant
+func (m *mockConversationRepository) FindByParticipant(ctx context.Context, userID string, limit int) ([]domain.Conversation, error) {
+	var result []domain.Conversation
+	for _, conversation := range m.conversations {
+		if conversation.GuestID == userID || conversation.HostID == userID {
+			result = append(result, conversation)
+		}
+	}
+	return result, nil
+}
+
+// RecordMessage implementa ConversationRepository.RecordMessage
+func (m *mockConversationRepository) RecordMessage(ctx context.Context, id primitive.ObjectID, preview string, sentAt time.Time, recipientIsHost bool) (*domain.Conversation, error) {
+	for i := range m.conversations {
+		if m.conversations[i].ID == id {
+			m.conversations[i].LastMessage = preview
+			m.conversations[i].LastMessageAt = sentAt
+			if recipientIsHost {
+				m.conversations[i].HostUnread++
+			} else {
+				m.conversations[i].GuestUnread++
+			}
+			conversation := m.conversations[i]
+			return &conversation, nil
+		}
+	}
+	return nil, errors.New("conversation not found")
+}
+
+// MarkRead implementa ConversationRepository.MarkRead
+func (m *mockConversationRepository) MarkRead(ctx context.Context, id primitive.ObjectID, asHost bool) error {
+	for i := range m.conversations {
+		if m.conversations[i].ID == id {
+			if asHost {
+				m.conversations[i].HostUnread = 0
+			} else {
+				m.conversations[i].GuestUnread = 0
+			}
+		}
+	}
+	return nil
+}
+
+// CountUnread implementa ConversationRepository.CountUnread
+func (m *mockConversationRepository) CountUnread(ctx context.Context, userID string) (int64, error) {
+	var unread int64
+	for _, conversation := range m.conversations {
+		if conversation.GuestID == userID {
+			unread += conversation.GuestUnread
+		}
+		if conversation.HostID == userID {
+			unread += conversation.HostUnread
+		}
+	}
+	return unread, nil
+}
+
+// mockMessageRepository es un mock en memoria de MessageRepository
+type mockMessageRepository struct {
+	messages []domain.Message
+}
+
+// Create implementa MessageRepository.Create
+func (m *mockMessageRepository) Create(ctx context.Context, message *domain.Message) error {
+	message.ID = primitive.NewObjectID()
+	m.messages = append(m.messages, *message)
+	return nil
+}
+
+// FindByConversation implementa MessageRepository.FindByConversation
+func (m *mockMessageRepository) FindByConversation(ctx context.Context, conversationID primitive.ObjectID, before time.Time, limit int) ([]domain.Message, error) {
+	var result []domain.Message
+	for i := len(m.messages) - 1; i >= 0 && len(result) < limit; i-- {
+		if m.messages[i].ConversationID == conversationID && (before.IsZero() || m.messages[i].CreatedAt.Before(before)) {
+			result = append(result, m.messages[i])
+		}
+	}
+	return result, nil
+}
+
+// mockCalendarFeedClient es un mock de CalendarFeedClient que retorna un calendario fijo
+type mockCalendarFeedClient struct {
+	body string
+	err  error
+}
+
+// Fetch implementa CalendarFeedClient.Fetch
+func (m *mockCalendarFeedClient) Fetch(ctx context.Context, url string) ([]byte, error) {
+	return []byte(m.body), m.err
+}
+
+// mockModerationRepository es un mock en memoria de ModerationRepository
+type mockModerationRepository struct {
+	cases []domain.ModerationCase
+}
+
+// Create implementa ModerationRepository.Create
+func (m *mockModerationRepository) Create(ctx context.Context, moderationCase *domain.ModerationCase) error {
+	moderationCase.ID = primitive.NewObjectID()
+	m.cases = append(m.cases, *moderationCase)
+	return nil
+}
+
+// GetByID implementa ModerationRepository.GetByID
+func (m *mockModerationRepository) GetByID(ctx context.Context, id string) (*domain.ModerationCase, error) {
+	for i := range m.cases {
+		if m.cases[i].ID.Hex() == id {
+			moderationCase := m.cases[i]
+			return &moderationCase, nil
+		}
+	}
+	return nil, nil
+}
+
+// List implementa ModerationRepository.List
+func (m *mockModerationRepository) List(ctx context.Context, status string, limit int) ([]domain.ModerationCase, error) {
+	result := []domain.ModerationCase{}
+	for _, moderationCase := range m.cases {
+		if status == "" || moderationCase.Status == status {
+			result = append(result, moderationCase)
+		}
+	}
+	return result, nil
+}
+
+// ListBySubmitter implementa ModerationRepository.ListBySubmitter
+func (m *mockModerationRepository) ListBySubmitter(ctx context.Context, userID string, limit int) ([]domain.ModerationCase, error) {
+	result := []domain.ModerationCase{}
+	for _, moderationCase := range m.cases {
+		if moderationCase.SubmittedBy == userID {
+			result = append(result, moderationCase)
+		}
+	}
+	return result, nil
+}
+
+// Resolve implementa ModerationRepository.Resolve
+func (m *mockModerationRepository) Resolve(ctx context.Context, id primitive.ObjectID, status, reviewedBy, note string, reviewedAt time.Time) (bool, error) {
+	for i := range m.cases {
+		if m.cases[i].ID == id && m.cases[i].Status == domain.ModerationCasePending {
+			m.cases[i].Status = status
+			m.cases[i].ReviewedBy = reviewedBy
+			m.cases[i].Note = note
+			m.cases[i].ReviewedAt = &reviewedAt
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SupersedePending implementa ModerationRepository.SupersedePending
+func (m *mockModerationRepository) SupersedePending(ctx context.Context, entityType, entityID string) error {
+	for i := range m.cases {
+		if m.cases[i].EntityType == entityType && m.cases[i].EntityID == entityID && m.cases[i].Status == domain.ModerationCasePending {
+			m.cases[i].Status = domain.ModerationCaseSuperseded
+		}
+	}
+	return nil
+}
+
+// mockCouponRepository es un mock en memoria de CouponRepository
+type mockCouponRepository struct {
+	coupons     []domain.Coupon
+	redemptions []domain.CouponRedemption
+}
+
+// Create implementa CouponRepository.Create
+func (m *mockCouponRepository) Create(ctx context.Context, coupon *domain.Coupon) error {
+	for _, existing := range m.coupons {
+		if existing.Code == coupon.Code {
+			return repositories.ErrCouponCodeTaken
+		}
+	}
+	coupon.ID = primitive.NewObjectID()
+	m.coupons = append(m.coupons, *coupon)
+	return nil
+}
+
+// FindByCode implementa CouponRepository.FindByCode
+func (m *mockCouponRepository) FindByCode(ctx context.Context, code string) (*domain.Coupon, error) {
+	for i := range m.coupons {
+		if m.coupons[i].Code == code {
+			coupon := m.coupons[i]
+			return &coupon, nil
+		}
+	}
+	return nil, nil
+}
+
+// List implementa CouponRepository.List
+func (m *mockCouponRepository) List(ctx context.Context) ([]domain.Coupon, error) {
+	return m.coupons, nil
+}
+
+// SetActive implementa CouponRepository.SetActive
+func (m *mockCouponRepository) SetActive(ctx context.Context, code string, active bool) error {
+	for i := range m.coupons {
+		if m.coupons[i].Code == code {
+			m.coupons[i].Active = active
+			return nil
+		}
+	}
+	return errors.New("cupón no encontrado")
+}
+
+// Reserve implementa CouponRepository.Reserve
+func (m *mockCouponRepository) Reserve(ctx context.Context, id primitive.ObjectID, now time.Time) (bool, error) {
+	for i := range m.coupons {
+		coupon := &m.coupons[i]
+		if coupon.ID != id {
+			continue
+		}
+		if !coupon.Active || (coupon.MaxRedemptions > 0 && coupon.Redemptions >= coupon.MaxRedemptions) {
+			return false, nil
+		}
+		coupon.Redemptions++
+		return true, nil
+	}
+	return false, nil
+}
+
+// Release implementa CouponRepository.Release
+func (m *mockCouponRepository) Release(ctx context.Context, id primitive.ObjectID) error {
+	for i := range m.coupons {
+		if m.coupons[i].ID == id && m.coupons[i].Redemptions > 0 {
+			m.coupons[i].Redemptions--
+		}
+	}
+	return nil
+}
+
+// CreateRedemption implementa CouponRepository.CreateRedemption
+func (m *mockCouponRepository) CreateRedemption(ctx context.Context, redemption *domain.CouponRedemption) error {
+	redemption.ID = primitive.NewObjectID()
+	m.redemptions = append(m.redemptions, *redemption)
+	return nil
+}
+
+// CountUserRedemptions implementa CouponRepository.CountUserRedemptions
+func (m *mockCouponRepository) CountUserRedemptions(ctx context.Context, couponID primitive.ObjectID, userID string) (int64, error) {
+	var count int64
+	for _, redemption := range m.redemptions {
+		if redemption.CouponID == couponID && redemption.UserID == userID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// FindRedemptions implementa CouponRepository.FindRedemptions
+func (m *mockCouponRepository) FindRedemptions(ctx context.Context, couponID primitive.ObjectID, limit int) ([]domain.CouponRedemption, error) {
+	var result []domain.CouponRedemption
+	for _, redemption := range m.redemptions {
+		if redemption.CouponID == couponID {
+			result = append(result, redemption)
+		}
+	}
+	return result, nil
+}
+
+// ============================================
+// HELPERS
+// ============================================
+
+// newTestPropertyService crea el servicio con mocks en memoria para las dependencias secundarias
+func newTestPropertyService(repo *mockRepository, usersClient *mockUsersClient, rabbitClient *mockRabbitClient) PropertyService {
+	audit := NewAuditService(&mockAuditRepository{})
+	return NewPropertyService(repo, &mockPriceHistoryRepository{}, usersClient, rabbitClient, audit, NewModerationService(&mockModerationRepository{}, repo, rabbitClient, audit))
+}
+
+// createTestProperty crea una propiedad de prueba para usar en los tests
+func createTestProperty(id string, ownerID string) domain.Property {
+	objectID := primitive.NewObjectID()
+	if id != "" {
+		var err error
+		objectID, err = primitive.ObjectIDFromHex(id)
+		if err != nil {
+			objectID = primitive.NewObjectID()
+		}
+	}
+
+	now := time.Now()
+	return domain.Property{
+		ID:          objectID,
+		Title:       "Test Property",
+		Description: "Test Description",
+		Price:       1000.0,
+		Location:    "Test Location",
+		OwnerID:     ownerID,
+		Amenities:   []string{"wifi", "pool"},
+		Capacity:    4,
+		Available:  true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// createTestCreateDTO crea un DTO de creación de prueba
+func createTestCreateDTO(ownerID string) dto.PropertyCreateDTO {
+	return dto.PropertyCreateDTO{
+		Title:       "Test Property",
+		Description: "Test Description",
+		Price:       1000.0,
+		Location:    "Test Location",
+		OwnerID:     ownerID,
+		Amenities:   []string{"wifi", "pool"},
+		Capacity:    4,
+		Available:  true,
+	}
+}
+
+// ============================================
+// TESTS
+// ============================================
+
+// TestCreateProperty_Success testa el caso exitoso de creación de propiedad
+func TestCreateProperty_Success(t *testing.T) {
+	// Arrange
+	ownerID := "user123"
+	propertyID := primitive.NewObjectID().Hex()
+
+	mockRepo := &mockRepository{
+		CreateFunc: func(property domain.Property) (domain.Property, error) {
+			// El servicio calcula el precio antes de crear, así que retornamos la propiedad con el precio calculado
+			createdProperty := property
+			createdProperty.ID, _ = primitive.ObjectIDFromHex(propertyID)
+			return createdProperty, nil
+		},
+	}
+
+	mockUsersClient := &mockUsersClient{
+		ValidateUserFunc: func(userID string) (bool, error) {
+			return true, nil // Usuario existe
+		},
+	}
+
+	var publishedSnapshot *dto.PropertyResponseDTO
+	mockRabbitClient := &mockRabbitClient{
+		PublishPropertySnapshotEventFunc: func(operation string, property dto.PropertyResponseDTO) error {
+			if operation == "create" {
+				publishedSnapshot = &property
+			}
+			return nil
+		},
+	}
+
+	service := newTestPropertyService(mockRepo, mockUsersClient, mockRabbitClient)
+	createDTO := createTestCreateDTO(ownerID)
+
+	// Act
+	result, err := service.CreateProperty(context.Background(), createDTO)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.ID == "" {
+		t.Error("Expected property ID to be set")
+	}
+
+	if result.OwnerID != ownerID {
+		t.Errorf("Expected OwnerID %s, got %s", ownerID, result.OwnerID)
+	}
+
+	// Verificar que el precio fue calculado con concurrencia (extras incluidos, impuestos no)
+	// El cálculo incluye: precio base + ($50 * amenidades) + ($30 * capacidad)
+	// Con precio base 1000, 2 amenidades, capacidad 4:
+	// 1000 + (2 * 50) + (4 * 30) = 1000 + 100 + 120 = 1220
+	expectedPrice := 1220.0
+	if result.Price != expectedPrice {
+		t.Errorf("Expected price to be calculated with concurrency (%.2f), got %.2f", expectedPrice, result.Price)
+	}
+
+	// El evento debe llevar el snapshot completo de la propiedad creada
+	if publishedSnapshot == nil {
+		t.Fatal("Expected create event with property snapshot")
+	}
+	if publishedSnapshot.ID != result.ID || publishedSnapshot.Price != result.Price {
+		t.Errorf("Expected snapshot to match created property, got %+v", *publishedSnapshot)
+	}
+}
+
+// TestCreateProperty_UserNotFound testa el caso cuando el owner no existe
+func TestCreateProperty_UserNotFound(t *testing.T) {
+	// Arrange
+	ownerID := "nonexistent-user"
+	mockRepo := &mockRepository{}
+
+	mockUsersClient := &mockUsersClient{
+		ValidateUserFunc: func(userID string) (bool, error) {
+			return false, nil // Usuario no existe
+		},
+	}
+
+	mockRabbitClient := &mockRabbitClient{}
+
+	service := newTestPropertyService(mockRepo, mockUsersClient, mockRabbitClient)
+	createDTO := createTestCreateDTO(ownerID)
+
+	// Act
+	result, err := service.CreateProperty(context.Background(), createDTO)
+
+	// Assert
+	if err == nil {
+		t.Fatal("Expected error when user does not exist")
+	}
+
+	if result.ID != "" {
+		t.Error("Expected empty result when creation fails")
+	}
+
+	// Verificar que el error contiene información sobre el usuario no encontrado
+	if !contains(err.Error(), "no existe") && !contains(err.Error(), "not found") {
+		t.Errorf("Expected error message about user not found, got: %v", err)
+	}
+}
+
+// TestGetPropertyByID_Success testa obtener una propiedad existente
+func TestGetPropertyByID_Success(t *testing.T) {
+	// Arrange
+	propertyID := primitive.NewObjectID().Hex()
+	ownerID := "user123"
+	expectedProperty := createTestProperty(propertyID, ownerID)
+
+	mockRepo := &mockRepository{
+		GetByIDFunc: func(id string) (domain.Property, error) {
+			if id == propertyID {
+				return expectedProperty, nil
+			}
+			return domain.Property{}, errors.New("property not found")
+		},
+	}
+
+	mockUsersClient := &mockUsersClient{}
+	mockRabbitClient := &mockRabbitClient{}
+
+	service := newTestPropertyService(mockRepo, mockUsersClient, mockRabbitClient)
+
+	// Act
+	result, err := service.GetPropertyByID(context.Background(), propertyID)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.ID != propertyID {
+		t.Errorf("Expected ID %s, got %s", propertyID, result.ID)
+	}
+
+	if result.Title != expectedProperty.Title {
+		t.Errorf("Expected Title %s, got %s", expectedProperty.Title, result.Title)
+	}
+}
+
+// TestGetPropertyByID_NotFound testa obtener una propiedad inexistente
+func TestGetPropertyByID_NotFound(t *testing.T) {
+	// Arrange
+	propertyID := "nonexistent-id"
+
+	mockRepo := &mockRepository{
+		GetByIDFunc: func(id string) (domain.Property, error) {
+			return domain.Property{}, errors.New("property not found")
+		},
+	}
+
+	mockUsersClient := &mockUsersClient{}
+	mockRabbitClient := &mockRabbitClient{}
+
+	service := newTestPropertyService(mockRepo, mockUsersClient, mockRabbitClient)
+
+	// Act
+	result, err := service.GetPropertyByID(context.Background(), propertyID)
+
+	// Assert
+	if err == nil {
+		t.Fatal("Expected error when property does not exist")
+	}
+
+	if result.ID != "" {
+		t.Error("Expected empty result when property not found")
+	}
+
+	// Verificar que el error contiene información sobre la propiedad no encontrada
+	if !contains(err.Error(), "no encontrada") && !contains(err.Error(), "not found") {
+		t.Errorf("Expected error message about property not found, got: %v", err)
+	}
+}
+
+// TestUpdateProperty_Unauthorized testa actualización sin permisos
+func TestUpdateProperty_Unauthorized(t *testing.T) {
+	// Arrange
+	propertyID := primitive.NewObjectID().Hex()
+	ownerID := "owner123"
 	unauthorizedUserID := "user456"
 	existingProperty := createTestProperty(propertyID, ownerID)
 
 	mockRepo := &mockRepository{
 		GetByIDFunc: func(id string) (domain.Property, error) {
-			if id == propertyID {
-				return existingProperty, nil
-			}
-			return domain.Property{}, errors.New("property not found")
+			if id == propertyID {
+				return existingProperty, nil
+			}
+			return domain.Property{}, errors.New("property not found")
+		},
+	}
+
+	mockUsersClient := &mockUsersClient{}
+	mockRabbitClient := &mockRabbitClient{}
+
+	service := newTestPropertyService(mockRepo, mockUsersClient, mockRabbitClient)
+
+	updateDTO := dto.PropertyUpdateDTO{
+		Title: stringPtr("Updated Title"),
+	}
+
+	// Act
+	err := service.UpdateProperty(context.Background(), propertyID, updateDTO, unauthorizedUserID, false)
+
+	// Assert
+	if err == nil {
+		t.Fatal("Expected error when user is not authorized")
+	}
+
+	// Verificar que el error contiene información sobre permisos
+	if !contains(err.Error(), "no tiene permisos") && !contains(err.Error(), "permission") {
+		t.Errorf("Expected error message about unauthorized access, got: %v", err)
+	}
+}
+
+// TestDeleteProperty_Success testa eliminación exitosa
+func TestDeleteProperty_Success(t *testing.T) {
+	// Arrange
+	propertyID := primitive.NewObjectID().Hex()
+	ownerID := "owner123"
+	existingProperty := createTestProperty(propertyID, ownerID)
+
+	mockRepo := &mockRepository{
+		GetByIDFunc: func(id string) (domain.Property, error) {
+			if id == propertyID {
+				return existingProperty, nil
+			}
+			return domain.Property{}, errors.New("property not found")
+		},
+		DeleteFunc: func(id string) error {
+			if id == propertyID {
+				return nil
+			}
+			return errors.New("property not found")
+		},
+	}
+
+	mockUsersClient := &mockUsersClient{}
+	mockRabbitClient := &mockRabbitClient{
+		PublishPropertyEventFunc: func(operation string, propID string) error {
+			if operation == "delete" && propID == propertyID {
+				return nil
+			}
+			return errors.New("invalid event")
+		},
+	}
+
+	service := newTestPropertyService(mockRepo, mockUsersClient, mockRabbitClient)
+
+	// Act
+	err := service.DeleteProperty(context.Background(), propertyID, ownerID, false)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+// TestDeleteProperty_Unauthorized testa eliminación sin permisos usando tabla de tests
+func TestDeleteProperty_Unauthorized(t *testing.T) {
+	// Tabla de tests para diferentes escenarios de no autorización
+	tests := []struct {
+		name           string
+		propertyID     string
+		ownerID        string
+		requestingUser string
+		expectedError  string
+	}{
+		{
+			name:           "Different user tries to delete",
+			propertyID:     "property123",
+			ownerID:        "owner123",
+			requestingUser: "user456",
+			expectedError:  "no tiene permisos",
+		},
+		{
+			name:           "Empty user ID",
+			propertyID:     "property123",
+			ownerID:        "owner123",
+			requestingUser: "",
+			expectedError:  "no tiene permisos",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			existingProperty := createTestProperty(tt.propertyID, tt.ownerID)
+
+			mockRepo := &mockRepository{
+				GetByIDFunc: func(id string) (domain.Property, error) {
+					if id == tt.propertyID {
+						return existingProperty, nil
+					}
+					return domain.Property{}, errors.New("property not found")
+				},
+			}
+
+			mockUsersClient := &mockUsersClient{}
+			mockRabbitClient := &mockRabbitClient{}
+
+			service := newTestPropertyService(mockRepo, mockUsersClient, mockRabbitClient)
+
+			// Act
+			err := service.DeleteProperty(context.Background(), tt.propertyID, tt.requestingUser, false)
+
+			// Assert
+			if err == nil {
+				t.Fatal("Expected error when user is not authorized")
+			}
+
+			if !contains(err.Error(), tt.expectedError) {
+				t.Errorf("Expected error to contain '%s', got: %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+// TestUpdateProperty_RecordsPriceHistory testa que un cambio de precio quede en el historial
+func TestUpdateProperty_RecordsPriceHistory(t *testing.T) {
+	// Arrange
+	propertyID := primitive.NewObjectID().Hex()
+	ownerID := "owner123"
+	existingProperty := createTestProperty(propertyID, ownerID)
+
+	mockRepo := &mockRepository{
+		GetByIDFunc: func(id string) (domain.Property, error) {
+			return existingProperty, nil
+		},
+		UpdateFunc: func(id string, property domain.Property) error {
+			return nil
+		},
+	}
+	historyRepo := &mockPriceHistoryRepository{}
+	audit := NewAuditService(&mockAuditRepository{})
+	service := NewPropertyService(mockRepo, historyRepo, &mockUsersClient{}, &mockRabbitClient{}, audit, NewModerationService(&mockModerationRepository{}, mockRepo, &mockRabbitClient{}, audit))
+
+	newPrice := 2000.0
+	updateDTO := dto.PropertyUpdateDTO{Price: &newPrice}
+
+	// Act
+	err := service.UpdateProperty(context.Background(), propertyID, updateDTO, ownerID, false)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(historyRepo.entries) != 1 {
+		t.Fatalf("Expected 1 price history entry, got %d", len(historyRepo.entries))
+	}
+
+	entry := historyRepo.entries[0]
+	if entry.OldPrice != existingProperty.Price {
+		t.Errorf("Expected old price %.2f, got %.2f", existingProperty.Price, entry.OldPrice)
+	}
+	if entry.ChangedBy != ownerID {
+		t.Errorf("Expected ChangedBy %s, got %s", ownerID, entry.ChangedBy)
+	}
+}
+
+// TestUpdateProperty_RecordsAuditDiff testa que la auditoría guarde solo los campos modificados
+func TestUpdateProperty_RecordsAuditDiff(t *testing.T) {
+	// Arrange
+	propertyID := primitive.NewObjectID().Hex()
+	ownerID := "owner123"
+	existingProperty := createTestProperty(propertyID, ownerID)
+
+	mockRepo := &mockRepository{
+		GetByIDFunc: func(id string) (domain.Property, error) {
+			return existingProperty, nil
+		},
+		UpdateFunc: func(id string, property domain.Property) error {
+			return nil
+		},
+	}
+	auditRepo := &mockAuditRepository{}
+	audit := NewAuditService(auditRepo)
+	service := NewPropertyService(mockRepo, &mockPriceHistoryRepository{}, &mockUsersClient{}, &mockRabbitClient{}, audit, NewModerationService(&mockModerationRepository{}, mockRepo, &mockRabbitClient{}, audit))
+
+	newTitle := "Nuevo título"
+	updateDTO := dto.PropertyUpdateDTO{Title: &newTitle}
+
+	// Act
+	err := service.UpdateProperty(context.Background(), propertyID, updateDTO, "admin1", true)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(auditRepo.entries) != 1 {
+		t.Fatalf("Expected 1 audit entry, got %d", len(auditRepo.entries))
+	}
+
+	entry := auditRepo.entries[0]
+	if entry.ActorID != "admin1" || entry.Action != AuditActionPropertyUpdate || entry.EntityID != propertyID {
+		t.Errorf("Expected actor admin1, action %s and entity %s, got %s, %s and %s", AuditActionPropertyUpdate, propertyID, entry.ActorID, entry.Action, entry.EntityID)
+	}
+	if len(entry.Changes) != 1 {
+		t.Fatalf("Expected only the title to change, got %v", entry.Changes)
+	}
+	if change := entry.Changes["title"]; change.Before != existingProperty.Title || change.After != newTitle {
+		t.Errorf("Expected title change %q -> %q, got %v -> %v", existingProperty.Title, newTitle, change.Before, change.After)
+	}
+}
+
+// TestBuildPriceHistory_Stats testa el cálculo de mínimos, máximos y la ventana de 30 días
+func TestBuildPriceHistory_Stats(t *testing.T) {
+	now := time.Now()
+	property := createTestProperty("", "owner123")
+	property.Price = 150
+
+	entries := []domain.PriceHistoryEntry{
+		{OldPrice: 120, NewPrice: 150, ChangedAt: now.AddDate(0, 0, -5)},
+		{OldPrice: 300, NewPrice: 120, ChangedAt: now.AddDate(0, 0, -60)},
+	}
+
+	result := buildPriceHistory(property, entries, now)
+
+	if result.MinPrice != 120 || result.MaxPrice != 300 {
+		t.Errorf("Expected min/max 120/300, got %.2f/%.2f", result.MinPrice, result.MaxPrice)
+	}
+	if result.Last30Days.MinPrice != 120 || result.Last30Days.MaxPrice != 150 {
+		t.Errorf("Expected last 30 days min/max 120/150, got %.2f/%.2f", result.Last30Days.MinPrice, result.Last30Days.MaxPrice)
+	}
+	if result.Last30Days.Changes != 1 {
+		t.Errorf("Expected 1 change in last 30 days, got %d", result.Last30Days.Changes)
+	}
+	if len(result.History) != 2 {
+		t.Errorf("Expected 2 history entries, got %d", len(result.History))
+	}
+}
+
+// ============================================
+// HELPER FUNCTIONS
+// ============================================
+
+// contains verifica si un string contiene otro
+func contains(s, substr string) bool {
+	if len(substr) == 0 {
+		return true
+	}
+	if len(s) < len(substr) {
+		return false
+	}
+	for i := 0; i <= len(s)-len(substr); i++ {
+		if s[i:i+len(substr)] == substr {
+			return true
+		}
+	}
+	return false
+}
+
+// stringPtr crea un puntero a un string
+func stringPtr(s string) *string {
+	return &s
+}
+
+
+// TestToBookingDTO_PropertyLocalTimes verifica que las reservas se serialicen en UTC
+// y que los horarios locales usen la zona horaria de la propiedad
+func TestToBookingDTO_PropertyLocalTimes(t *testing.T) {
+	checkIn, err := utils.PropertyLocalToUTC("2025-01-10", 15, "America/Argentina/Cordoba")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := utils.FormatTimestamp(checkIn); got != "2025-01-10T18:00:00Z" {
+		t.Fatalf("Expected check-in 2025-01-10T18:00:00Z, got %s", got)
+	}
+
+	booking := domain.Booking{
+		ID:         primitive.NewObjectID(),
+		PropertyID: "prop-1",
+		CheckIn:    checkIn,
+		CheckOut:   checkIn.Add(72 * time.Hour),
+		CreatedAt:  time.Date(2025, 1, 1, 9, 30, 0, 0, time.FixedZone("UTC-3", -3*3600)),
+	}
+
+	result := toBookingDTO(booking, "America/Argentina/Cordoba")
+
+	if result.CheckIn != "2025-01-10T18:00:00Z" {
+		t.Errorf("Expected UTC check-in, got %s", result.CheckIn)
+	}
+	if result.CheckInLocal != "2025-01-10T15:00:00-03:00" {
+		t.Errorf("Expected local check-in 2025-01-10T15:00:00-03:00, got %s", result.CheckInLocal)
+	}
+	if result.CreatedAt != "2025-01-01T12:30:00Z" {
+		t.Errorf("Expected createdAt normalized to UTC, got %s", result.CreatedAt)
+	}
+
+	if _, err := utils.LoadPropertyLocation("Mars/Olympus"); err == nil {
+		t.Error("Expected error for invalid timezone")
+	}
+}
+
+// Test: Borrar los datos de un usuario oculta sus propiedades y anonimiza sus reservas
+func TestEraseUserData_HidesPropertiesAndAnonymizesBookings(t *testing.T) {
+	var hiddenOwner string
+	repo := &mockRepository{
+		SetAvailabilityByOwnerFunc: func(ownerID string, available bool) (int64, error) {
+			if available {
+				t.Error("Expected properties to be marked unavailable")
+			}
+			hiddenOwner = ownerID
+			return 2, nil
+		},
+	}
+	bookings := &mockBookingRepository{bookings: []domain.Booking{
+		{ID: primitive.NewObjectID(), PropertyID: "p1", UserID: "7"},
+		{ID: primitive.NewObjectID(), PropertyID: "p2", UserID: "8"},
+	}}
+	service := NewPrivacyService(newTestPropertyService(repo, &mockUsersClient{}, &mockRabbitClient{}), repo, bookings, NewAuditService(&mockAuditRepository{}))
+
+	if err := service.EraseUserData(context.Background(), "7"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if hiddenOwner != "7" {
+		t.Errorf("Expected properties of owner 7 to be hidden, got '%s'", hiddenOwner)
+	}
+	if bookings.bookings[0].UserID != ErasedUserID {
+		t.Errorf("Expected booking of erased user to be anonymized, got '%s'", bookings.bookings[0].UserID)
+	}
+	if bookings.bookings[1].UserID != "8" {
+		t.Errorf("Expected other bookings to be untouched, got '%s'", bookings.bookings[1].UserID)
+	}
+}
+
+func TestGetPropertyStats_ComputesDerivedMetrics(t *testing.T) {
+	property := createTestProperty("507f1f77bcf86cd799439011", "owner123")
+	property.Views = 42
+	repo := &mockRepository{
+		GetByIDFunc: func(id string) (domain.Property, error) {
+			return property, nil
+		},
+	}
+	bookings := &mockBookingRepository{stats: domain.BookingStats{
+		TotalBookings:     5,
+		CancelledBookings: 1,
+		TotalRevenue:      1000,
+		WindowBookings:    2,
+		WindowRevenue:     400,
+		BookedNights:      15,
+	}}
+	service := NewPropertyStatsService(repo, bookings)
+
+	stats, err := service.GetPropertyStats(context.Background(), property.ID.Hex(), 30, "owner123", false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if stats.Views != 42 {
+		t.Errorf("Expected 42 views, got %d", stats.Views)
+	}
+	if stats.BookingsCount != 5 || stats.CancelledBookings != 1 {
+		t.Errorf("Expected 5 bookings and 1 cancelled, got %d and %d", stats.BookingsCount, stats.CancelledBookings)
+	}
+	if stats.AverageBookingValue != 250 {
+		t.Errorf("Expected average booking value 250, got %v", stats.AverageBookingValue)
+	}
+	if stats.OccupancyRate != 0.5 {
+		t.Errorf("Expected occupancy rate 0.5, got %v", stats.OccupancyRate)
+	}
+}
+
+func TestGetPropertyStats_ForbiddenForOtherUsers(t *testing.T) {
+	property := createTestProperty("507f1f77bcf86cd799439011", "owner123")
+	repo := &mockRepository{
+		GetByIDFunc: func(id string) (domain.Property, error) {
+			return property, nil
+		},
+	}
+	service := NewPropertyStatsService(repo, &mockBookingRepository{})
+
+	if _, err := service.GetPropertyStats(context.Background(), property.ID.Hex(), 30, "other-user", false); !errors.Is(err, ErrStatsForbidden) {
+		t.Errorf("Expected ErrStatsForbidden, got %v", err)
+	}
+	if _, err := service.GetPropertyStats(context.Background(), property.ID.Hex(), 30, "other-user", true); err != nil {
+		t.Errorf("Expected admin to access stats, got %v", err)
+	}
+}
+
+func TestViewService_FlushMovesPendingViewsAndPublishesPopularity(t *testing.T) {
+	totals := map[string]int64{"p1": 10}
+	repo := &mockRepository{
+		IncrementViewsFunc: func(id string, delta int64) (int64, error) {
+			totals[id] += delta
+			return totals[id], nil
+		},
+	}
+	var published clients.PropertyPopularityEvent
+	rabbit := &mockRabbitClient{
+		PublishPopularityEventFunc: func(event clients.PropertyPopularityEvent) error {
+			published = event
+			return nil
+		},
+	}
+	counter := &mockViewCounter{views: make(map[string]uint64)}
+	service := NewViewService(counter, repo, rabbit)
+
+	service.RecordView(context.Background(), "p1")
+	service.RecordView(context.Background(), "p1")
+	service.RecordView(context.Background(), "p2")
+
+	flushed, err := service.Flush(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if flushed != 2 {
+		t.Errorf("Expected 2 properties flushed, got %d", flushed)
+	}
+	if published.Views["p1"] != 12 || published.Views["p2"] != 1 {
+		t.Errorf("Expected published totals p1=12 p2=1, got %v", published.Views)
+	}
+	if counter.views["p1"] != 0 {
+		t.Errorf("Expected pending views to be taken from the counter, got %d", counter.views["p1"])
+	}
+
+	// Un segundo volcado sin vistas nuevas no hace nada
+	if flushed, _ := service.Flush(context.Background()); flushed != 0 {
+		t.Errorf("Expected nothing to flush, got %d", flushed)
+	}
+}
+
+// TestHealthReady_ReportsFailingAndSlowDependencies testa que readiness falle si una dependencia
+// devuelve error o no responde dentro del timeout
+func TestHealthReady_ReportsFailingAndSlowDependencies(t *testing.T) {
+	service := NewHealthService("properties-api", 50*time.Millisecond,
+		HealthCheck{Name: "mongodb", Check: func(ctx context.Context) error { return nil }},
+		HealthCheck{Name: "rabbitmq", Check: func(ctx context.Context) error { return errors.New("connection closed") }},
+		HealthCheck{Name: "memcached", Optional: true, Check: func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		}},
+	)
+
+	response, ready := service.Ready(context.Background())
+
+	if ready {
+		t.Fatal("Expected service not to be ready")
+	}
+	if response.Status != dto.HealthStatusDown {
+		t.Errorf("Expected status %s, got %s", dto.HealthStatusDown, response.Status)
+	}
+	if response.Dependencies["mongodb"].Status != dto.HealthStatusUp {
+		t.Errorf("Expected mongodb up, got %+v", response.Dependencies["mongodb"])
+	}
+	if response.Dependencies["rabbitmq"].Error != "connection closed" {
+		t.Errorf("Expected rabbitmq error 'connection closed', got %+v", response.Dependencies["rabbitmq"])
+	}
+	if response.Dependencies["memcached"].Status != dto.HealthStatusDown {
+		t.Errorf("Expected memcached down after timeout, got %+v", response.Dependencies["memcached"])
+	}
+}
+
+// TestHealthReady_OptionalDependencyDegrades testa que una dependencia opcional caída no saque al servicio de readiness
+func TestHealthReady_OptionalDependencyDegrades(t *testing.T) {
+	service := NewHealthService("properties-api", 50*time.Millisecond,
+		HealthCheck{Name: "mongodb", Check: func(ctx context.Context) error { return nil }},
+		HealthCheck{Name: "memcached", Optional: true, Check: func(ctx context.Context) error { return errors.New("no servers") }},
+	)
+
+	response, ready := service.Ready(context.Background())
+
+	if !ready {
+		t.Fatal("Expected service to be ready")
+	}
+	if response.Status != dto.HealthStatusDegraded {
+		t.Errorf("Expected status %s, got %s", dto.HealthStatusDegraded, response.Status)
+	}
+}
+
+// TestCreateProperty_PropertyType verifica que el tipo se normalice y se rechacen los inválidos
+func TestCreateProperty_PropertyType(t *testing.T) {
+	mockRepo := &mockRepository{
+		CreateFunc: func(property domain.Property) (domain.Property, error) {
+			property.ID = primitive.NewObjectID()
+			return property, nil
+		},
+	}
+	mockUsersClient := &mockUsersClient{
+		ValidateUserFunc: func(userID string) (bool, error) { return true, nil },
+	}
+	mockRabbitClient := &mockRabbitClient{
+		PublishPropertySnapshotEventFunc: func(operation string, property dto.PropertyResponseDTO) error { return nil },
+	}
+	service := newTestPropertyService(mockRepo, mockUsersClient, mockRabbitClient)
+
+	createDTO := createTestCreateDTO("user123")
+	createDTO.PropertyType = " Cabaña "
+	result, err := service.CreateProperty(context.Background(), createDTO)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.PropertyType != "cabaña" {
+		t.Errorf("Expected property type 'cabaña', got '%s'", result.PropertyType)
+	}
+
+	createDTO.PropertyType = "castillo"
+	if _, err := service.CreateProperty(context.Background(), createDTO); err == nil {
+		t.Error("Expected error for invalid property type")
+	}
+}
+
+// TestCreateProperty_HouseRules verifica que las reglas de la casa se guarden con el horario de silencio
+// normalizado, que se rechacen los horarios inválidos y que la actualización reemplace todas las reglas
+func TestCreateProperty_HouseRules(t *testing.T) {
+	var stored domain.Property
+	mockRepo := &mockRepository{
+		CreateFunc: func(property domain.Property) (domain.Property, error) {
+			property.ID = primitive.NewObjectID()
+			stored = property
+			return property, nil
+		},
+		GetByIDFunc: func(id string) (domain.Property, error) { return stored, nil },
+		UpdateFunc: func(id string, property domain.Property) error {
+			stored = property
+			return nil
+		},
+	}
+	mockUsersClient := &mockUsersClient{
+		ValidateUserFunc: func(userID string) (bool, error) { return true, nil },
+	}
+	var snapshot dto.PropertyResponseDTO
+	mockRabbitClient := &mockRabbitClient{
+		PublishPropertySnapshotEventFunc: func(operation string, property dto.PropertyResponseDTO) error {
+			snapshot = property
+			return nil
+		},
+	}
+	service := newTestPropertyService(mockRepo, mockUsersClient, mockRabbitClient)
+
+	// Sin reglas la respuesta igual las trae, todas en false
+	createDTO := createTestCreateDTO("user123")
+	result, err := service.CreateProperty(context.Background(), createDTO)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.HouseRules != (dto.HouseRulesDTO{}) || stored.HouseRules != nil {
+		t.Errorf("Expected empty house rules, got %+v (stored %+v)", result.HouseRules, stored.HouseRules)
+	}
+
+	createDTO.HouseRules = &dto.HouseRulesDTO{PetsAllowed: true, QuietHoursStart: "22:00", QuietHoursEnd: "8:00"}
+	result, err = service.CreateProperty(context.Background(), createDTO)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := dto.HouseRulesDTO{PetsAllowed: true, QuietHoursStart: "22:00", QuietHoursEnd: "08:00"}
+	if result.HouseRules != expected {
+		t.Errorf("Expected house rules %+v, got %+v", expected, result.HouseRules)
+	}
+	if snapshot.HouseRules != expected {
+		t.Errorf("Expected snapshot house rules %+v, got %+v", expected, snapshot.HouseRules)
+	}
+
+	for _, rules := range []dto.HouseRulesDTO{
+		{QuietHoursStart: "22:00"},
+		{QuietHoursStart: "25:00", QuietHoursEnd: "08:00"},
+		{QuietHoursStart: "22:00", QuietHoursEnd: "22:00"},
+	} {
+		createDTO.HouseRules = &rules
+		if _, err := service.CreateProperty(context.Background(), createDTO); !errors.Is(err, ErrInvalidHouseRules) {
+			t.Errorf("Expected ErrInvalidHouseRules for %+v, got %v", rules, err)
+		}
+	}
+
+	// La actualización reemplaza las reglas completas (el horario de silencio anterior se borra)
+	update := dto.PropertyUpdateDTO{HouseRules: &dto.HouseRulesDTO{SmokingAllowed: true, EventsAllowed: true}}
+	if err := service.UpdateProperty(context.Background(), result.ID, update, "user123", false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stored.HouseRules == nil || stored.HouseRules.PetsAllowed || !stored.HouseRules.SmokingAllowed ||
+		!stored.HouseRules.EventsAllowed || stored.HouseRules.QuietHoursStart != "" {
+		t.Errorf("Expected house rules to be replaced, got %+v", stored.HouseRules)
+	}
+}
+
+// TestCreateProperty_CancellationPolicy verifica que la política de cancelación sea flexible por defecto
+// (también en las propiedades anteriores) y que la actualización la cambie
+func TestCreateProperty_CancellationPolicy(t *testing.T) {
+	var stored domain.Property
+	mockRepo := &mockRepository{
+		CreateFunc: func(property domain.Property) (domain.Property, error) {
+			property.ID = primitive.NewObjectID()
+			stored = property
+			return property, nil
+		},
+		GetByIDFunc: func(id string) (domain.Property, error) { return stored, nil },
+		UpdateFunc: func(id string, property domain.Property) error {
+			stored = property
+			return nil
+		},
+	}
+	mockUsersClient := &mockUsersClient{
+		ValidateUserFunc: func(userID string) (bool, error) { return true, nil },
+	}
+	mockRabbitClient := &mockRabbitClient{
+		PublishPropertySnapshotEventFunc: func(operation string, property dto.PropertyResponseDTO) error { return nil },
+	}
+	service := newTestPropertyService(mockRepo, mockUsersClient, mockRabbitClient)
+
+	result, err := service.CreateProperty(context.Background(), createTestCreateDTO("user123"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.CancellationPolicy != domain.CancellationFlexible || stored.CancellationPolicy != domain.CancellationFlexible {
+		t.Errorf("Expected flexible cancellation policy, got %s (stored %s)", result.CancellationPolicy, stored.CancellationPolicy)
+	}
+
+	strict := domain.CancellationStrict
+	if err := service.UpdateProperty(context.Background(), result.ID, dto.PropertyUpdateDTO{CancellationPolicy: &strict}, "user123", false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stored.CancellationPolicy != domain.CancellationStrict {
+		t.Errorf("Expected strict cancellation policy, got %s", stored.CancellationPolicy)
+	}
+
+	// Las propiedades guardadas antes de la política se muestran como flexibles
+	legacy := createTestProperty(primitive.NewObjectID().Hex(), "user123")
+	if policy := toPropertyDTO(legacy).CancellationPolicy; policy != domain.CancellationFlexible {
+		t.Errorf("Expected legacy property to be flexible, got %s", policy)
+	}
+}
+
+// TestCalendarImportFeed_CreatesBlocksInPropertyDates verifica que los eventos importados
+// se guarden como fechas locales de la propiedad y que se ignoren los exportados por Spotly
+func TestCalendarImportFeed_CreatesBlocksInPropertyDates(t *testing.T) {
+	property := createTestProperty("507f1f77bcf86cd799439011", "owner123")
+	property.Timezone = "America/Argentina/Cordoba"
+	repo := &mockRepository{
+		GetByIDFunc: func(id string) (domain.Property, error) {
+			return property, nil
+		},
+	}
+	ics := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\nUID:airbnb-1\r\nDTSTART;VALUE=DATE:20990110\r\nDTEND;VALUE=DATE:20990113\r\nSUMMARY:Airbnb (Not\r\n  available)\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:booking-2\r\nDTSTART:20990201T180000Z\r\nDTEND:20990203T140000Z\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:booking-abc@spotly\r\nDTSTART;VALUE=DATE:20990301\r\nDTEND;VALUE=DATE:20990302\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	availability := &mockAvailabilityRepository{}
+	feeds := &mockCalendarFeedRepository{}
+	service := NewCalendarService(repo, &mockBookingRepository{}, availability, feeds, &mockCalendarFeedClient{body: ics}, &mockRabbitClient{})
+
+	request := dto.CalendarFeedImportDTO{Name: "Airbnb", URL: "https://www.airbnb.com/calendar/ical/1.ics"}
+	if _, err := service.ImportFeed(context.Background(), property.ID.Hex(), "other-user", false, request); !errors.Is(err, ErrCalendarForbidden) {
+		t.Fatalf("Expected ErrCalendarForbidden, got %v", err)
+	}
+
+	feed, err := service.ImportFeed(context.Background(), property.ID.Hex(), "owner123", false, request)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if feed.BlockedRanges != 2 || feed.LastSyncedAt == nil || feed.LastError != "" {
+		t.Fatalf("Expected 2 blocked ranges and a successful sync, got %+v", feed)
+	}
+
+	blocks := availability.blocks
+	if len(blocks) != 2 {
+		t.Fatalf("Expected 2 blocks, got %d", len(blocks))
+	}
+	if blocks[0].Summary != "Airbnb (Not available)" {
+		t.Errorf("Expected unfolded summary, got '%s'", blocks[0].Summary)
+	}
+	if got := blocks[0].Start.Format("2006-01-02") + "/" + blocks[0].End.Format("2006-01-02"); got != "2099-01-10/2099-01-13" {
+		t.Errorf("Expected all-day block 2099-01-10/2099-01-13, got %s", got)
+	}
+	// 18:00Z y 14:00Z son las 15hs y 11hs en Córdoba: bloquea las noches del 1 y el 2
+	if got := blocks[1].Start.Format("2006-01-02") + "/" + blocks[1].End.Format("2006-01-02"); got != "2099-02-01/2099-02-03" {
+		t.Errorf("Expected timed block 2099-02-01/2099-02-03, got %s", got)
+	}
+
+	var exported strings.Builder
+	if err := service.ExportCalendar(context.Background(), property.ID.Hex(), &exported); err != nil {
+		t.Fatalf("Expected no error exporting, got %v", err)
+	}
+	if !strings.Contains(exported.String(), "DTSTART;VALUE=DATE:20990110\r\nDTEND;VALUE=DATE:20990113\r\n") {
+		t.Errorf("Expected exported calendar to contain the imported block, got %s", exported.String())
+	}
+}
+
+// TestCalendarExport_IncludesActiveBookings verifica que se exporten las reservas no canceladas
+func TestCalendarExport_IncludesActiveBookings(t *testing.T) {
+	property := createTestProperty("507f1f77bcf86cd799439011", "owner123")
+	property.Timezone = "Europe/Madrid"
+	repo := &mockRepository{
+		GetByIDFunc: func(id string) (domain.Property, error) {
+			return property, nil
+		},
+	}
+	bookings := &mockBookingRepository{bookings: []domain.Booking{
+		{
+			ID:         primitive.NewObjectID(),
+			PropertyID: property.ID.Hex(),
+			CheckIn:    time.Date(2099, 1, 10, 14, 0, 0, 0, time.UTC),
+			CheckOut:   time.Date(2099, 1, 12, 10, 0, 0, 0, time.UTC),
+			Status:     "confirmed",
+		},
+		{
+			ID:         primitive.NewObjectID(),
+			PropertyID: property.ID.Hex(),
+			CheckIn:    time.Date(2099, 2, 10, 14, 0, 0, 0, time.UTC),
+			CheckOut:   time.Date(2099, 2, 12, 10, 0, 0, 0, time.UTC),
+			Status:     "cancelled",
+		},
+	}}
+	service := NewCalendarService(repo, bookings, &mockAvailabilityRepository{}, &mockCalendarFeedRepository{}, &mockCalendarFeedClient{}, &mockRabbitClient{})
+
+	var exported strings.Builder
+	if err := service.ExportCalendar(context.Background(), property.ID.Hex(), &exported); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	events, err := utils.ParseICalendar(strings.NewReader(exported.String()))
+	if err != nil {
+		t.Fatalf("Expected exported calendar to be parseable, got %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+	if events[0].UID != "booking-"+bookings.bookings[0].ID.Hex()+"@spotly" {
+		t.Errorf("Expected booking UID, got '%s'", events[0].UID)
+	}
+	if !events[0].AllDay || events[0].Start.Format("20060102") != "20990110" || events[0].End.Format("20060102") != "20990112" {
+		t.Errorf("Expected all-day event 20990110-20990112, got %+v", events[0])
+	}
+}
+
+// TestCalendarGetAvailability_MergesBookingsAndBlocks verifica que la disponibilidad incluya
+// las reservas activas (en días locales de la propiedad) y los bloqueos, ordenados por fecha
+func TestCalendarGetAvailability_MergesBookingsAndBlocks(t *testing.T) {
+	property := createTestProperty("507f1f77bcf86cd799439011", "owner123")
+	property.Timezone = "America/Argentina/Cordoba"
+	repo := &mockRepository{
+		GetByIDFunc: func(id string) (domain.Property, error) {
+			return property, nil
+		},
+	}
+	bookings := &mockBookingRepository{bookings: []domain.Booking{
+		{
+			ID:         primitive.NewObjectID(),
+			PropertyID: property.ID.Hex(),
+			CheckIn:    time.Date(2099, 3, 10, 18, 0, 0, 0, time.UTC),
+			CheckOut:   time.Date(2099, 3, 12, 14, 0, 0, 0, time.UTC),
+			Status:     "confirmed",
+		},
+		{
+			ID:         primitive.NewObjectID(),
+			PropertyID: property.ID.Hex(),
+			CheckIn:    time.Date(2099, 4, 1, 18, 0, 0, 0, time.UTC),
+			CheckOut:   time.Date(2099, 4, 3, 14, 0, 0, 0, time.UTC),
+			Status:     "cancelled",
+		},
+	}}
+	availability := &mockAvailabilityRepository{blocks: []domain.AvailabilityBlock{
+		{
+			ID:         primitive.NewObjectID(),
+			PropertyID: property.ID.Hex(),
+			Start:      time.Date(2099, 1, 5, 0, 0, 0, 0, time.UTC),
+			End:        time.Date(2099, 1, 8, 0, 0, 0, 0, time.UTC),
+			FeedID:     primitive.NewObjectID(),
+		},
+	}}
+	service := NewCalendarService(repo, bookings, availability, &mockCalendarFeedRepository{}, &mockCalendarFeedClient{}, &mockRabbitClient{})
+
+	ranges, err := service.GetAvailability(context.Background(), property.ID.Hex())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []dto.AvailabilityRangeDTO{
+		{Start: "2099-01-05", End: "2099-01-08", Source: "block"},
+		{Start: "2099-03-10", End: "2099-03-12", Source: "booking"},
+	}
+	if len(ranges) != len(expected) {
+		t.Fatalf("Expected %d ranges, got %+v", len(expected), ranges)
+	}
+	for i := range expected {
+		if ranges[i] != expected[i] {
+			t.Errorf("Expected range %d to be %+v, got %+v", i, expected[i], ranges[i])
+		}
+	}
+}
+
+// TestCalendarBulkUpdateAvailability_AppliesRangesInOrderAndRejectsBookedDates verifica que los rangos
+// se apliquen en orden sobre los bloqueos manuales, sin tocar los importados, con un único evento
+func TestCalendarBulkUpdateAvailability_AppliesRangesInOrderAndRejectsBookedDates(t *testing.T) {
+	property := createTestProperty("507f1f77bcf86cd799439011", "owner123")
+	repo := &mockRepository{
+		GetByIDFunc: func(id string) (domain.Property, error) {
+			return property, nil
+		},
+	}
+	bookings := &mockBookingRepository{bookings: []domain.Booking{{
+		ID:         primitive.NewObjectID(),
+		PropertyID: property.ID.Hex(),
+		CheckIn:    time.Date(2099, 2, 10, 14, 0, 0, 0, time.UTC),
+		CheckOut:   time.Date(2099, 2, 12, 10, 0, 0, 0, time.UTC),
+		Status:     "confirmed",
+	}}}
+	imported := domain.AvailabilityBlock{
+		ID:         primitive.NewObjectID(),
+		PropertyID: property.ID.Hex(),
+		Start:      time.Date(2099, 1, 20, 0, 0, 0, 0, time.UTC),
+		End:        time.Date(2099, 1, 22, 0, 0, 0, 0, time.UTC),
+		FeedID:     primitive.NewObjectID(),
+	}
+	availability := &mockAvailabilityRepository{blocks: []domain.AvailabilityBlock{imported}}
+	var events []clients.AvailabilityUpdatedEvent
+	rabbit := &mockRabbitClient{
+		PublishAvailabilityEventFunc: func(event clients.AvailabilityUpdatedEvent) error {
+			events = append(events, event)
+			return nil
+		},
+	}
+	service := NewCalendarService(repo, bookings, availability, &mockCalendarFeedRepository{}, &mockCalendarFeedClient{}, rabbit)
+
+	// Bloquear todo enero y abrir una semana en el medio
+	request := dto.BulkAvailabilityUpdateDTO{Ranges: []dto.AvailabilityRangeUpdateDTO{
+		{Start: "2099-01-01", End: "2099-02-01", Action: "block"},
+		{Start: "2099-01-10", End: "2099-01-17", Action: "open"},
+	}}
+	ranges, err := service.BulkUpdateAvailability(context.Background(), property.ID.Hex(), "owner123", false, request)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []dto.AvailabilityRangeDTO{
+		{Start: "2099-01-01", End: "2099-01-10", Source: "manual"},
+		{Start: "2099-01-17", End: "2099-02-01", Source: "manual"},
+		{Start: "2099-01-20", End: "2099-01-22", Source: "block"},
+		{Start: "2099-02-10", End: "2099-02-12", Source: "booking"},
+	}
+	if len(ranges) != len(expected) {
+		t.Fatalf("Expected %d ranges, got %+v", len(expected), ranges)
+	}
+	for i := range expected {
+		if ranges[i] != expected[i] {
+			t.Errorf("Expected range %d to be %+v, got %+v", i, expected[i], ranges[i])
+		}
+	}
+	if len(events) != 1 || len(events[0].Blocked) != 1 || len(events[0].Opened) != 1 || len(events[0].ManualBlocks) != 2 {
+		t.Fatalf("Expected one consolidated event, got %+v", events)
+	}
+
+	// Un rango que pisa una reserva activa no aplica ninguno de los rangos pedidos
+	conflicting := dto.BulkAvailabilityUpdateDTO{Ranges: []dto.AvailabilityRangeUpdateDTO{
+		{Start: "2099-01-10", End: "2099-01-17", Action: "block"},
+		{Start: "2099-02-01", End: "2099-03-01", Action: "block"},
+	}}
+	if _, err := service.BulkUpdateAvailability(context.Background(), property.ID.Hex(), "owner123", false, conflicting); !errors.Is(err, ErrAvailabilityConflict) {
+		t.Fatalf("Expected ErrAvailabilityConflict, got %v", err)
+	}
+	if len(availability.blocks) != 3 || len(events) != 1 {
+		t.Errorf("Expected no changes after a conflict, got %d blocks and %d events", len(availability.blocks), len(events))
+	}
+
+	if _, err := service.BulkUpdateAvailability(context.Background(), property.ID.Hex(), "other-user", false, request); !errors.Is(err, ErrCalendarForbidden) {
+		t.Errorf("Expected ErrCalendarForbidden, got %v", err)
+	}
+	invalid := dto.BulkAvailabilityUpdateDTO{Ranges: []dto.AvailabilityRangeUpdateDTO{{Start: "2099-01-10", End: "2099-01-10", Action: "block"}}}
+	if _, err := service.BulkUpdateAvailability(context.Background(), property.ID.Hex(), "owner123", false, invalid); !errors.Is(err, ErrInvalidAvailabilityRange) {
+		t.Errorf("Expected ErrInvalidAvailabilityRange, got %v", err)
+	}
+}
+
+// TestCreateProperty_DuplicateDetection verifica que se rechacen los duplicados exactos
+// y se marquen para revisión las propiedades parecidas del mismo owner
+func TestCreateProperty_DuplicateDetection(t *testing.T) {
+	existing := createTestProperty("507f1f77bcf86cd799439011", "user123")
+	existing.Title = "Casa en la playa"
+	existing.Location = "Mar del Plata"
+	mockRepo := &mockRepository{
+		GetByOwnerIDFunc: func(ownerID string) ([]domain.Property, error) {
+			return []domain.Property{existing}, nil
+		},
+		CreateFunc: func(property domain.Property) (domain.Property, error) {
+			property.ID = primitive.NewObjectID()
+			return property, nil
+		},
+	}
+	mockUsersClient := &mockUsersClient{
+		ValidateUserFunc: func(userID string) (bool, error) { return true, nil },
+	}
+	mockRabbitClient := &mockRabbitClient{
+		PublishPropertySnapshotEventFunc: func(operation string, property dto.PropertyResponseDTO) error { return nil },
+	}
+	service := newTestPropertyService(mockRepo, mockUsersClient, mockRabbitClient)
+
+	createDTO := createTestCreateDTO("user123")
+	createDTO.Title = "Playa, casa en LA!"
+	createDTO.Location = "mar del plata"
+	if _, err := service.CreateProperty(context.Background(), createDTO); !errors.Is(err, ErrDuplicateProperty) {
+		t.Fatalf("Expected ErrDuplicateProperty, got %v", err)
+	}
+
+	createDTO.Title = "Casa en la playa con pileta"
+	result, err := service.CreateProperty(context.Background(), createDTO)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.DuplicateOf != existing.ID.Hex() {
+		t.Errorf("Expected property to be flagged as duplicate of %s, got '%s'", existing.ID.Hex(), result.DuplicateOf)
+	}
+
+	createDTO.Location = "Bariloche"
+	result, err = service.CreateProperty(context.Background(), createDTO)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.DuplicateOf != "" {
+		t.Errorf("Expected property in another location not to be flagged, got '%s'", result.DuplicateOf)
+	}
+}
+
+// TestDuplicateMerge_MovesBookingsAndDeletesDuplicate verifica la fusión de un duplicado con la original
+func TestDuplicateMerge_MovesBookingsAndDeletesDuplicate(t *testing.T) {
+	original := createTestProperty("507f1f77bcf86cd799439011", "user123")
+	original.Images = []domain.Photo{{URL: "a.jpg"}}
+	duplicate := createTestProperty("507f1f77bcf86cd799439012", "user123")
+	duplicate.Images = []domain.Photo{{URL: "a.jpg"}, {URL: "b.jpg", Position: 1, IsCover: true}}
+	duplicate.DuplicateOf = original.ID.Hex()
+
+	properties := map[string]domain.Property{original.ID.Hex(): original, duplicate.ID.Hex(): duplicate}
+	mockRepo := &mockRepository{
+		GetByIDFunc: func(id string) (domain.Property, error) {
+			property, ok := properties[id]
+			if !ok {
+				return domain.Property{}, errors.New("not found")
+			}
+			return property, nil
+		},
+		UpdateFunc: func(id string, property domain.Property) error {
+			properties[id] = property
+			return nil
+		},
+		DeleteFunc: func(id string) error {
+			delete(properties, id)
+			return nil
+		},
+	}
+	bookings := &mockBookingRepository{bookings: []domain.Booking{
+		{ID: primitive.NewObjectID(), PropertyID: duplicate.ID.Hex(), Status: "confirmed"},
+	}}
+	var deleted string
+	mockRabbitClient := &mockRabbitClient{
+		PublishPropertySnapshotEventFunc: func(operation string, property dto.PropertyResponseDTO) error { return nil },
+		PublishPropertyEventFunc: func(operation string, propertyID string) error {
+			deleted = propertyID
+			return nil
+		},
+	}
+	service := NewDuplicateService(mockRepo, bookings, mockRabbitClient, NewAuditService(&mockAuditRepository{}))
+
+	if _, err := service.Merge(context.Background(), original.ID.Hex(), "admin"); err == nil {
+		t.Error("Expected error merging a property that is not flagged")
+	}
+
+	merged, err := service.Merge(context.Background(), duplicate.ID.Hex(), "admin")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(merged.Images) != 2 || merged.CoverImage != "a.jpg" {
+		t.Errorf("Expected 2 merged images keeping the original cover, got %v", merged.Images)
+	}
+	if _, exists := properties[duplicate.ID.Hex()]; exists || deleted != duplicate.ID.Hex() {
+		t.Error("Expected duplicate to be deleted and a delete event published")
+	}
+	if bookings.bookings[0].PropertyID != original.ID.Hex() {
+		t.Errorf("Expected booking to be moved to the original property, got '%s'", bookings.bookings[0].PropertyID)
+	}
+}
+
+// TestImportProperties_ReportsEachRow verifica que se inserten las filas válidas y se reporte el error de las inválidas
+func TestImportProperties_ReportsEachRow(t *testing.T) {
+	var inserted []domain.Property
+	mockRepo := &mockRepository{
+		GetByOwnerIDFunc: func(ownerID string) ([]domain.Property, error) {
+			return nil, nil
+		},
+		CreateManyFunc: func(ctx context.Context, properties []domain.Property) ([]error, error) {
+			inserted = append(inserted, properties...)
+			return make([]error, len(properties)), nil
+		},
+	}
+	mockUsersClient := &mockUsersClient{
+		ValidateUserFunc: func(userID string) (bool, error) { return userID == "user123", nil },
+	}
+	published := 0
+	mockRabbitClient := &mockRabbitClient{
+		PublishPropertySnapshotEventFunc: func(operation string, property dto.PropertyResponseDTO) error {
+			published++
+			return nil
+		},
+	}
+	service := newTestPropertyService(mockRepo, mockUsersClient, mockRabbitClient)
+
+	csvData := "title,description,price,location,ownerId,amenities,capacity,available\n" +
+		"Casa en la playa,Linda casa,100,Mar del Plata,user123,wifi|pileta,4,true\n" +
+		"Sin precio,Depto,,Córdoba,user123,,2,true\n" +
+		"Casa del owner inexistente,Casa,80,Rosario,user999,,2,true\n" +
+		"Playa casa en la,Duplicada,120,Mar del Plata,user123,,4,true\n" +
+		"Depto centro,Depto,90,Córdoba,user123,,2,abc\n"
+
+	report, err := service.ImportProperties(context.Background(), utils.FormatCSV, strings.NewReader(csvData), "admin")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if report.Total != 5 || report.Imported != 1 || report.Failed != 4 {
+		t.Fatalf("Expected 5 rows with 1 imported and 4 failed, got %+v", report)
+	}
+	if report.Rows[0].ID == "" || report.Rows[0].Error != "" {
+		t.Errorf("Expected row 1 to be imported, got %+v", report.Rows[0])
+	}
+	for i, row := range report.Rows[1:] {
+		if row.Row != i+2 || row.Error == "" {
+			t.Errorf("Expected row %d to fail, got %+v", i+2, row)
+		}
+	}
+	if !contains(report.Rows[3].Error, ErrDuplicateProperty.Error()) {
+		t.Errorf("Expected row 4 to be rejected as duplicate of row 1, got '%s'", report.Rows[3].Error)
+	}
+	if len(inserted) != 1 || len(inserted[0].Amenities) != 2 || published != 1 {
+		t.Errorf("Expected 1 property inserted with 2 amenities and 1 event, got %d inserted and %d events", len(inserted), published)
+	}
+
+	ndjson := `{"title":"Loft","description":"Loft","price":50,"location":"Salta","ownerId":"user123","capacity":2}` + "\n\n{invalid\n"
+	report, err = service.ImportProperties(context.Background(), utils.FormatNDJSON, strings.NewReader(ndjson), "admin")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.Total != 2 || report.Imported != 1 || report.Failed != 1 {
+		t.Errorf("Expected 2 NDJSON rows with 1 imported and 1 failed, got %+v", report)
+	}
+}
+
+// TestExportBookings_FiltersAndUsesPropertyTimezone verifica los filtros del export y el rango de fechas
+func TestExportBookings_FiltersAndUsesPropertyTimezone(t *testing.T) {
+	property := createTestProperty("507f1f77bcf86cd799439011", "owner123")
+	property.Timezone = "Europe/Madrid"
+	lookups := 0
+	repo := &mockRepository{
+		GetByIDFunc: func(id string) (domain.Property, error) {
+			lookups++
+			return property, nil
+		},
+	}
+	checkIn := time.Date(2025, 1, 10, 14, 0, 0, 0, time.UTC)
+	bookings := &mockBookingRepository{bookings: []domain.Booking{
+		{ID: primitive.NewObjectID(), PropertyID: property.ID.Hex(), UserID: "u1", CheckIn: checkIn, CheckOut: checkIn.AddDate(0, 0, 2), Status: "confirmed"},
+		{ID: primitive.NewObjectID(), PropertyID: property.ID.Hex(), UserID: "u2", CheckIn: checkIn, CheckOut: checkIn.AddDate(0, 0, 2), Status: "cancelled"},
+		{ID: primitive.NewObjectID(), PropertyID: property.ID.Hex(), UserID: "u3", CheckIn: checkIn, CheckOut: checkIn.AddDate(0, 0, 3), Status: "confirmed"},
+	}}
+	service := NewExportService(repo, bookings)
+
+	var exported []dto.BookingDTO
+	err := service.StreamBookings(context.Background(), dto.BookingExportQuery{Status: "confirmed"}, func(booking dto.BookingDTO) error {
+		exported = append(exported, booking)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(exported) != 2 {
+		t.Fatalf("Expected 2 confirmed bookings, got %d", len(exported))
+	}
+	if exported[0].CheckInLocal != "2025-01-10T15:00:00+01:00" {
+		t.Errorf("Expected local check-in in Madrid time, got '%s'", exported[0].CheckInLocal)
+	}
+	if lookups != 1 {
+		t.Errorf("Expected property timezone to be looked up once, got %d", lookups)
+	}
+
+	query := dto.BookingExportQuery{From: "2025-02-01", To: "2025-01-01"}
+	if err := service.StreamBookings(context.Background(), query, func(dto.BookingDTO) error { return nil }); !errors.Is(err, ErrInvalidDateRange) {
+		t.Errorf("Expected ErrInvalidDateRange, got %v", err)
+	}
+}
+
+// TestMessageService_ConversationFlowAndUnreadCounters verifica el intercambio huésped-anfitrión,
+// los no leídos de cada participante y el evento publicado para notifications
+func TestMessageService_ConversationFlowAndUnreadCounters(t *testing.T) {
+	property := createTestProperty("507f1f77bcf86cd799439011", "host1")
+	repo := &mockRepository{
+		GetByIDFunc: func(id string) (domain.Property, error) {
+			return property, nil
+		},
+	}
+	var events []clients.MessageSentEvent
+	rabbitClient := &mockRabbitClient{
+		PublishMessageEventFunc: func(event clients.MessageSentEvent) error {
+			events = append(events, event)
+			return nil
+		},
+	}
+	conversations := &mockConversationRepository{}
+	service := NewMessageService(repo, conversations, &mockMessageRepository{}, rabbitClient)
+	ctx := context.Background()
+
+	if _, _, err := service.StartConversation(ctx, property.ID.Hex(), "host1", dto.SendMessageDTO{Body: "Hola"}); !errors.Is(err, ErrCannotMessageOwnProperty) {
+		t.Fatalf("Expected ErrCannotMessageOwnProperty, got %v", err)
+	}
+
+	conversation, _, err := service.StartConversation(ctx, property.ID.Hex(), "guest1", dto.SendMessageDTO{Body: "¿Está disponible en marzo?"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, _, err := service.StartConversation(ctx, property.ID.Hex(), "guest1", dto.SendMessageDTO{Body: "¿Acepta mascotas?"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(conversations.conversations) != 1 {
+		t.Fatalf("Expected a single conversation per property and guest, got %d", len(conversations.conversations))
+	}
+
+	if unread, _ := service.UnreadCount(ctx, "host1"); unread != 2 {
+		t.Errorf("Expected host to have 2 unread messages, got %d", unread)
+	}
+	if len(events) != 2 || events[1].RecipientID != "host1" || events[1].RecipientUnread != 2 {
+		t.Fatalf("Expected second event for host1 with 2 unread, got %+v", events)
+	}
+
+	if _, err := service.SendMessage(ctx, conversation.ID, "intruder", dto.SendMessageDTO{Body: "Hola"}); !errors.Is(err, ErrConversationForbidden) {
+		t.Fatalf("Expected ErrConversationForbidden, got %v", err)
+	}
+	if _, err := service.ListMessages(ctx, conversation.ID, "intruder", false, time.Time{}, 0); !errors.Is(err, ErrConversationForbidden) {
+		t.Fatalf("Expected ErrConversationForbidden, got %v", err)
+	}
+
+	// Un administrador puede leer la conversación sin marcarla como leída
+	if _, err := service.ListMessages(ctx, conversation.ID, "admin", true, time.Time{}, 0); err != nil {
+		t.Fatalf("Expected admin to read the conversation, got %v", err)
+	}
+	if unread, _ := service.UnreadCount(ctx, "host1"); unread != 2 {
+		t.Errorf("Expected admin read to keep host unread at 2, got %d", unread)
+	}
+
+	page, err := service.ListMessages(ctx, conversation.ID, "host1", false, time.Time{}, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(page.Messages) != 2 || page.Messages[0].Body != "¿Acepta mascotas?" {
+		t.Fatalf("Expected 2 messages newest first, got %+v", page.Messages)
+	}
+	if page.Conversation.Unread != 0 {
+		t.Errorf("Expected conversation marked as read, got %d unread", page.Conversation.Unread)
+	}
+	if unread, _ := service.UnreadCount(ctx, "host1"); unread != 0 {
+		t.Errorf("Expected host to have 0 unread messages, got %d", unread)
+	}
+
+	if _, err := service.SendMessage(ctx, conversation.ID, "host1", dto.SendMessageDTO{Body: "Sí, está disponible"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if unread, _ := service.UnreadCount(ctx, "guest1"); unread != 1 {
+		t.Errorf("Expected guest to have 1 unread message, got %d", unread)
+	}
+	if last := events[len(events)-1]; last.RecipientID != "guest1" || last.SenderID != "host1" {
+		t.Errorf("Expected event from host1 to guest1, got %+v", last)
+	}
+}
+
+// TestListPropertyVersions_MapsIDsOwnersAndTimestamps testa la página de versiones que usa la reconciliación de search-api
+func TestListPropertyVersions_MapsIDsOwnersAndTimestamps(t *testing.T) {
+	// Arrange
+	updatedAt := time.Date(2024, 3, 10, 15, 4, 5, 0, time.FixedZone("ART", -3*60*60))
+	property := createTestProperty("", "42")
+	property.UpdatedAt = updatedAt
+	afterID := primitive.NewObjectID().Hex()
+
+	var gotAfterID string
+	var gotLimit int
+	mockRepo := &mockRepository{
+		ListVersionsFunc: func(ctx context.Context, after string, limit int) ([]domain.Property, error) {
+			gotAfterID, gotLimit = after, limit
+			return []domain.Property{property}, nil
+		},
+	}
+
+	service := newTestPropertyService(mockRepo, &mockUsersClient{}, &mockRabbitClient{})
+
+	// Act
+	versions, err := service.ListPropertyVersions(context.Background(), afterID, 100)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if gotAfterID != afterID || gotLimit != 100 {
+		t.Errorf("Expected repository page after %s with limit 100, got after %s with limit %d", afterID, gotAfterID, gotLimit)
+	}
+	if len(versions) != 1 {
+		t.Fatalf("Expected 1 version, got %d", len(versions))
+	}
+	if versions[0].ID != property.ID.Hex() || versions[0].OwnerID != "42" {
+		t.Errorf("Expected version %s of owner 42, got %+v", property.ID.Hex(), versions[0])
+	}
+	if versions[0].UpdatedAt != "2024-03-10T18:04:05Z" {
+		t.Errorf("Expected updatedAt in UTC 2024-03-10T18:04:05Z, got %s", versions[0].UpdatedAt)
+	}
+}
+
+// TestCreateBooking_PersistsPriceBreakdown verifica el detalle del precio por noche con limpieza,
+// tarifa de servicio e impuestos, que se guarde en la reserva y que se rechacen fechas ocupadas
+func TestCreateBooking_PersistsPriceBreakdown(t *testing.T) {
+	// Arrange
+	property := createTestProperty("507f1f77bcf86cd799439011", "owner123")
+	property.Price = 100
+	property.CleaningFee = 30
+	property.Timezone = "America/Argentina/Cordoba"
+	repo := &mockRepository{
+		GetByIDFunc: func(id string) (domain.Property, error) {
+			return property, nil
+		},
+	}
+	bookings := &mockBookingRepository{bookings: []domain.Booking{
+		{
+			ID:         primitive.NewObjectID(),
+			PropertyID: property.ID.Hex(),
+			CheckIn:    time.Date(2099, 3, 10, 18, 0, 0, 0, time.UTC),
+			CheckOut:   time.Date(2099, 3, 12, 14, 0, 0, 0, time.UTC),
+			Status:     "confirmed",
+		},
+	}}
+	var published []clients.BookingConfirmedEvent
+	rabbit := &mockRabbitClient{
+		PublishBookingEventFunc: func(event clients.BookingConfirmedEvent) error {
+			published = append(published, event)
+			return nil
+		},
+	}
+	calendar := NewCalendarService(repo, bookings, &mockAvailabilityRepository{}, &mockCalendarFeedRepository{}, &mockCalendarFeedClient{}, &mockRabbitClient{})
+	coupons := NewCouponService(&mockCouponRepository{}, NewAuditService(&mockAuditRepository{}))
+	service := NewBookingService(bookings, &mockBookingHoldRepository{bookings: bookings}, repo, calendar, rabbit, coupons, config.PricingConfig{ServiceFeeRate: 0.1, DefaultTaxRate: 0.21}, config.BookingsConfig{RequestTTL: 24 * time.Hour})
+
+	// Act
+	booking, err := service.CreateBooking(context.Background(), "guest42", dto.BookingCreateDTO{
+		PropertyID: property.ID.Hex(),
+		CheckIn:    "2099-03-07",
+		CheckOut:   "2099-03-10",
+		Guests:     2,
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	breakdown := booking.PriceBreakdown
+	if breakdown == nil {
+		t.Fatal("Expected price breakdown in the booking")
+	}
+	if len(breakdown.Nights) != 3 || breakdown.Nights[0].Date != "2099-03-07" || breakdown.Nights[2].Date != "2099-03-09" {
+		t.Errorf("Expected nights 2099-03-07 to 2099-03-09, got %+v", breakdown.Nights)
+	}
+	if breakdown.NightsSubtotal != 300 || breakdown.CleaningFee != 30 || breakdown.ServiceFee != 33 || breakdown.Taxes != 76.23 {
+		t.Errorf("Expected 300 + 30 + 33 + 76.23, got %+v", breakdown)
+	}
+	if breakdown.Total != 439.23 || booking.TotalPrice != 439.23 {
+		t.Errorf("Expected total 439.23, got breakdown %.2f and booking %.2f", breakdown.Total, booking.TotalPrice)
+	}
+	if booking.CheckIn != "2099-03-07T18:00:00Z" || booking.CheckOut != "2099-03-10T14:00:00Z" {
+		t.Errorf("Expected check-in/out at local 15hs/11hs, got %s - %s", booking.CheckIn, booking.CheckOut)
+	}
+	if stored := bookings.bookings[len(bookings.bookings)-1]; stored.PriceBreakdown == nil || stored.UserID != "guest42" {
+		t.Errorf("Expected stored booking of guest42 with breakdown, got %+v", stored)
+	}
+	if len(published) != 1 || published[0].TotalPrice != 439.23 {
+		t.Errorf("Expected one booking.confirmed event with the total, got %+v", published)
+	}
+
+	_, err = service.QuoteBooking(context.Background(), property.ID.Hex(), "2099-03-11", "2099-03-13", dto.GuestCountsDTO{Adults: 2}, "")
+	if !errors.Is(err, ErrPropertyUnavailable) {
+		t.Errorf("Expected ErrPropertyUnavailable for overlapping dates, got %v", err)
+	}
+	_, err = service.QuoteBooking(context.Background(), property.ID.Hex(), "2099-03-20", "2099-03-22", dto.GuestCountsDTO{Adults: 5}, "")
+	if !errors.Is(err, ErrTooManyGuests) {
+		t.Errorf("Expected ErrTooManyGuests above capacity, got %v", err)
+	}
+}
+
+// TestQuoteBooking_UsesCountryAndRegionTaxRates verifica que los impuestos de la cotización usen
+// la tasa de la región, después la del país y por último la tasa por defecto
+func TestQuoteBooking_UsesCountryAndRegionTaxRates(t *testing.T) {
+	// Arrange
+	property := createTestProperty("507f1f77bcf86cd799439011", "owner123")
+	property.Price = 100
+	repo := &mockRepository{
+		GetByIDFunc: func(id string) (domain.Property, error) {
+			return property, nil
+		},
+	}
+	bookings := &mockBookingRepository{}
+	calendar := NewCalendarService(repo, bookings, &mockAvailabilityRepository{}, &mockCalendarFeedRepository{}, &mockCalendarFeedClient{}, &mockRabbitClient{})
+	pricing := config.PricingConfig{
+		DefaultTaxRate: 0.2,
+		TaxRates:       map[string]float64{"us": 0.05, "US-NY": 0.08875},
+	}
+	coupons := NewCouponService(&mockCouponRepository{}, NewAuditService(&mockAuditRepository{}))
+	service := NewBookingService(bookings, &mockBookingHoldRepository{bookings: bookings}, repo, calendar, &mockRabbitClient{}, coupons, pricing, config.BookingsConfig{RequestTTL: 24 * time.Hour})
+
+	tests := []struct {
+		country, region      string
+		expectedRate         float64
+		expectedJurisdiction string
+		expectedTaxes        float64
+	}{
+		{"US", "NY", 0.08875, "US-NY", 17.75},
+		{"US", "CA", 0.05, "US", 10},
+		{"ES", "", 0.2, "default", 40},
+		{"", "", 0.2, "default", 40},
+	}
+	for _, tt := range tests {
+		property.Country, property.Region = tt.country, tt.region
+
+		// Act
+		quote, err := service.QuoteBooking(context.Background(), property.ID.Hex(), "2099-05-01", "2099-05-03", dto.GuestCountsDTO{Adults: 1}, "")
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Expected no error for %s-%s, got %v", tt.country, tt.region, err)
+		}
+		breakdown := quote.Breakdown
+		if breakdown.TaxRate != tt.expectedRate || breakdown.TaxJurisdiction != tt.expectedJurisdiction {
+			t.Errorf("Expected rate %g from %s for %s-%s, got %g from %s", tt.expectedRate, tt.expectedJurisdiction, tt.country, tt.region, breakdown.TaxRate, breakdown.TaxJurisdiction)
+		}
+		if breakdown.Taxes != tt.expectedTaxes || breakdown.Total != 200+tt.expectedTaxes {
+			t.Errorf("Expected taxes %.2f over 200 for %s-%s, got %+v", tt.expectedTaxes, tt.country, tt.region, breakdown)
+		}
+	}
+}
+
+// TestCreateBooking_AppliesCouponAndRecordsRedemption verifica que el cupón descuente antes de la tarifa
+// de servicio y los impuestos, que el uso quede registrado y que no se supere el límite de usos
+func TestCreateBooking_AppliesCouponAndRecordsRedemption(t *testing.T) {
+	// Arrange
+	property := createTestProperty("507f1f77bcf86cd799439011", "owner123")
+	property.Price = 100
+	repo := &mockRepository{
+		GetByIDFunc: func(id string) (domain.Property, error) {
+			return property, nil
+		},
+	}
+	bookings := &mockBookingRepository{}
+	calendar := NewCalendarService(repo, bookings, &mockAvailabilityRepository{}, &mockCalendarFeedRepository{}, &mockCalendarFeedClient{}, &mockRabbitClient{})
+	couponRepo := &mockCouponRepository{}
+	coupons := NewCouponService(couponRepo, NewAuditService(&mockAuditRepository{}))
+	service := NewBookingService(bookings, &mockBookingHoldRepository{bookings: bookings}, repo, calendar, &mockRabbitClient{}, coupons, config.PricingConfig{ServiceFeeRate: 0.1, DefaultTaxRate: 0.2}, config.BookingsConfig{RequestTTL: 24 * time.Hour})
+
+	if _, err := coupons.CreateCoupon(context.Background(), "admin1", dto.CouponCreateDTO{
+		Code:           "verano10",
+		Type:           domain.CouponPercentage,
+		Value:          10,
+		MaxRedemptions: 1,
+	}); err != nil {
+		t.Fatalf("Expected coupon to be created, got %v", err)
+	}
+
+	// Act
+	booking, err := service.CreateBooking(context.Background(), "guest42", dto.BookingCreateDTO{
+		PropertyID: property.ID.Hex(),
+		CheckIn:    "2099-06-01",
+		CheckOut:   "2099-06-03",
+		Guests:     1,
+		CouponCode: "Verano10",
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	breakdown := booking.PriceBreakdown
+	if breakdown == nil || breakdown.CouponCode != "VERANO10" || breakdown.Discount != 20 {
+		t.Fatalf("Expected 10%% discount of VERANO10 over 200, got %+v", breakdown)
+	}
+	if breakdown.ServiceFee != 18 || breakdown.Taxes != 39.6 || breakdown.Total != 237.6 {
+		t.Errorf("Expected 180 + 18 + 39.60 = 237.60, got %+v", breakdown)
+	}
+	if len(couponRepo.redemptions) != 1 || couponRepo.redemptions[0].BookingID != booking.ID || couponRepo.redemptions[0].Discount != 20 {
+		t.Errorf("Expected one redemption for booking %s, got %+v", booking.ID, couponRepo.redemptions)
+	}
+
+	_, err = service.QuoteBooking(context.Background(), property.ID.Hex(), "2099-07-01", "2099-07-03", dto.GuestCountsDTO{Adults: 1}, "VERANO10")
+	if !errors.Is(err, ErrCouponExhausted) {
+		t.Errorf("Expected ErrCouponExhausted after the only redemption, got %v", err)
+	}
+	_, err = service.QuoteBooking(context.Background(), property.ID.Hex(), "2099-07-01", "2099-07-03", dto.GuestCountsDTO{Adults: 1}, "NOEXISTE")
+	if !errors.Is(err, ErrCouponNotFound) {
+		t.Errorf("Expected ErrCouponNotFound for an unknown code, got %v", err)
+	}
+}
+
+// TestCreateBooking_RejectsNightsTakenConcurrently verifica que si otra reserva toma una noche después de
+// verificar la disponibilidad la reserva falle con ErrPropertyUnavailable y devuelva el uso del cupón
+func TestCreateBooking_RejectsNightsTakenConcurrently(t *testing.T) {
+	// Arrange
+	property := createTestProperty("507f1f77bcf86cd799439011", "owner123")
+	property.Price = 100
+	repo := &mockRepository{
+		GetByIDFunc: func(id string) (domain.Property, error) {
+			return property, nil
+		},
+	}
+	// La otra reserva todavía no es visible en el calendario pero ya tomó la noche del 2
+	bookings := &mockBookingRepository{nights: map[string]string{property.ID.Hex() + "/2099-06-02": "other"}}
+	calendar := NewCalendarService(repo, bookings, &mockAvailabilityRepository{}, &mockCalendarFeedRepository{}, &mockCalendarFeedClient{}, &mockRabbitClient{})
+	coupons := NewCouponService(&mockCouponRepository{}, NewAuditService(&mockAuditRepository{}))
+	service := NewBookingService(bookings, &mockBookingHoldRepository{bookings: bookings}, repo, calendar, &mockRabbitClient{}, coupons, config.PricingConfig{}, config.BookingsConfig{RequestTTL: 24 * time.Hour})
+	if _, err := coupons.CreateCoupon(context.Background(), "admin1", dto.CouponCreateDTO{
+		Code:           "UNICO",
+		Type:           domain.CouponPercentage,
+		Value:          10,
+		MaxRedemptions: 1,
+	}); err != nil {
+		t.Fatalf("Expected coupon to be created, got %v", err)
+	}
+
+	// Act
+	_, err := service.CreateBooking(context.Background(), "guest42", dto.BookingCreateDTO{
+		PropertyID: property.ID.Hex(),
+		CheckIn:    "2099-06-01",
+		CheckOut:   "2099-06-03",
+		Guests:     1,
+		CouponCode: "UNICO",
+	})
+
+	// Assert
+	if !errors.Is(err, ErrPropertyUnavailable) {
+		t.Fatalf("Expected ErrPropertyUnavailable, got %v", err)
+	}
+	if len(bookings.bookings) != 0 {
+		t.Errorf("Expected no booking to be saved, got %+v", bookings.bookings)
+	}
+	if _, err := service.QuoteBooking(context.Background(), property.ID.Hex(), "2099-07-01", "2099-07-03", dto.GuestCountsDTO{Adults: 1}, "UNICO"); err != nil {
+		t.Errorf("Expected the coupon use to be released, got %v", err)
+	}
+
+	// Las noches que no están tomadas se siguen pudiendo reservar
+	if _, err := service.CreateBooking(context.Background(), "guest42", dto.BookingCreateDTO{
+		PropertyID: property.ID.Hex(),
+		CheckIn:    "2099-06-03",
+		CheckOut:   "2099-06-05",
+		Guests:     1,
+	}); err != nil {
+		t.Errorf("Expected the following nights to be bookable, got %v", err)
+	}
+}
+
+// TestBookingHold_HoldsDatesUntilBookedOrExpired verifica que un hold retenga las fechas para otros
+// huéspedes, que su dueño reserve con él y que el sweeper libere las fechas de un hold vencido
+func TestBookingHold_HoldsDatesUntilBookedOrExpired(t *testing.T) {
+	// Arrange
+	property := createTestProperty("507f1f77bcf86cd799439011", "owner123")
+	property.Price = 100
+	repo := &mockRepository{
+		GetByIDFunc: func(id string) (domain.Property, error) {
+			return property, nil
+		},
+	}
+	bookings := &mockBookingRepository{}
+	holds := &mockBookingHoldRepository{bookings: bookings}
+	calendar := NewCalendarService(repo, bookings, &mockAvailabilityRepository{}, &mockCalendarFeedRepository{}, &mockCalendarFeedClient{}, &mockRabbitClient{})
+	coupons := NewCouponService(&mockCouponRepository{}, NewAuditService(&mockAuditRepository{}))
+	service := NewBookingService(bookings, holds, repo, calendar, &mockRabbitClient{}, coupons, config.PricingConfig{}, config.BookingsConfig{RequestTTL: 24 * time.Hour, HoldTTL: 10 * time.Minute})
+	stay := dto.BookingCreateDTO{PropertyID: property.ID.Hex(), CheckIn: "2099-06-01", CheckOut: "2099-06-03", Guests: 1}
+
+	// Act
+	hold, err := service.HoldBooking(context.Background(), "guest42", stay)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if hold.ID == "" || hold.ExpiresAt == "" || hold.Breakdown.Total != 200 {
+		t.Fatalf("Expected a hold with expiry and the quoted total, got %+v", hold)
+	}
+	if _, err := service.CreateBooking(context.Background(), "guest7", stay); !errors.Is(err, ErrPropertyUnavailable) {
+		t.Errorf("Expected the hold to keep other guests from booking, got %v", err)
+	}
+	if _, err := service.HoldBooking(context.Background(), "guest7", dto.BookingCreateDTO{PropertyID: property.ID.Hex(), CheckIn: "2099-06-02", CheckOut: "2099-06-04", Guests: 1}); !errors.Is(err, ErrPropertyUnavailable) {
+		t.Errorf("Expected an overlapping hold to fail, got %v", err)
+	}
+
+	withHold := stay
+	withHold.HoldID = hold.ID
+	if _, err := service.CreateBooking(context.Background(), "guest7", withHold); !errors.Is(err, ErrBookingHoldNotFound) {
+		t.Errorf("Expected ErrBookingHoldNotFound for another guest's hold, got %v", err)
+	}
+	booking, err := service.CreateBooking(context.Background(), "guest42", withHold)
+	if err != nil || booking.ID != hold.ID || booking.Status != domain.BookingConfirmed {
+		t.Fatalf("Expected the booking to take over the hold, got %+v (%v)", booking, err)
+	}
+	if _, err := service.CreateBooking(context.Background(), "guest42", withHold); !errors.Is(err, ErrPropertyUnavailable) {
+		t.Errorf("Expected a used hold to find the dates booked, got %v", err)
+	}
+
+	// Un hold que vence sin reservar
+	expiring, err := service.HoldBooking(context.Background(), "guest42", dto.BookingCreateDTO{PropertyID: property.ID.Hex(), CheckIn: "2099-07-01", CheckOut: "2099-07-03", Guests: 1})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	holds.holds[len(holds.holds)-1].ExpiresAt = time.Now().Add(-time.Second)
+	released, err := service.ExpireHolds(context.Background())
+	if err != nil || released != 1 {
+		t.Fatalf("Expected one released hold, got %d (%v)", released, err)
+	}
+	expiredHold := dto.BookingCreateDTO{PropertyID: property.ID.Hex(), CheckIn: "2099-07-01", CheckOut: "2099-07-03", Guests: 1, HoldID: expiring.ID}
+	if _, err := service.CreateBooking(context.Background(), "guest42", expiredHold); !errors.Is(err, ErrBookingHoldExpired) {
+		t.Errorf("Expected ErrBookingHoldExpired after expiry, got %v", err)
+	}
+	if _, err := service.CreateBooking(context.Background(), "guest7", dto.BookingCreateDTO{PropertyID: property.ID.Hex(), CheckIn: "2099-07-01", CheckOut: "2099-07-03", Guests: 1}); err != nil {
+		t.Errorf("Expected the dates to be released after expiry, got %v", err)
+	}
+}
+
+// TestAvailabilityChanged_PublishedWhenBookingsTakeOrFreeDates verifica que una reserva y su rechazo guarden
+// las fechas ocupadas en la propiedad y publiquen "availability.changed" solo cuando cambian
+func TestAvailabilityChanged_PublishedWhenBookingsTakeOrFreeDates(t *testing.T) {
+	// Arrange
+	property := createTestProperty("507f1f77bcf86cd799439011", "owner123")
+	property.Price = 100
+	property.BookingMode = domain.BookingModeRequest
+	repo := &mockRepository{
+		GetByIDFunc: func(id string) (domain.Property, error) {
+			return property, nil
+		},
+		SetBookedRangesFunc: func(ctx context.Context, id string, booked []domain.DateRange, nextAvailable string) error {
+			property.BookedRanges = booked
+			property.NextAvailableDate = nextAvailable
+			return nil
+		},
+	}
+	var events []clients.AvailabilityChangedEvent
+	rabbit := &mockRabbitClient{
+		PublishAvailabilityChangedEventFunc: func(event clients.AvailabilityChangedEvent) error {
+			events = append(events, event)
+			return nil
+		},
+	}
+	bookings := &mockBookingRepository{}
+	calendar := NewCalendarService(repo, bookings, &mockAvailabilityRepository{}, &mockCalendarFeedRepository{}, &mockCalendarFeedClient{}, rabbit)
+	coupons := NewCouponService(&mockCouponRepository{}, NewAuditService(&mockAuditRepository{}))
+	service := NewBookingService(bookings, &mockBookingHoldRepository{bookings: bookings}, repo, calendar, &mockRabbitClient{}, coupons, config.PricingConfig{}, config.BookingsConfig{RequestTTL: 24 * time.Hour})
+	today := time.Now().UTC().Format("2006-01-02")
+
+	// Act
+	request, err := service.CreateBooking(context.Background(), "guest42", dto.BookingCreateDTO{
+		PropertyID: property.ID.Hex(),
+		CheckIn:    "2099-08-01",
+		CheckOut:   "2099-08-03",
+		Guests:     1,
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(events) != 1 || events[0].Reason != AvailabilityReasonBooking || events[0].NextAvailableDate != today {
+		t.Fatalf("Expected one availability.changed event for the booking, got %+v", events)
+	}
+	if len(events[0].BookedRanges) != 1 || events[0].BookedRanges[0] != (clients.AvailabilityDateRange{Start: "2099-08-01", End: "2099-08-03"}) {
+		t.Errorf("Expected the booked nights in the event, got %+v", events[0].BookedRanges)
+	}
+	if len(property.BookedRanges) != 1 || property.NextAvailableDate != today {
+		t.Errorf("Expected the booked ranges to be saved in the property, got %+v (%s)", property.BookedRanges, property.NextAvailableDate)
+	}
+
+	calendar.RefreshBookedRanges(context.Background(), property.ID.Hex(), AvailabilityReasonFeed)
+	if len(events) != 1 {
+		t.Errorf("Expected no event when the booked ranges did not change, got %+v", events)
+	}
+
+	if _, err := service.DeclineBooking(context.Background(), request.ID, "owner123", false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(events) != 2 || len(events[1].BookedRanges) != 0 || len(property.BookedRanges) != 0 {
+		t.Errorf("Expected the declined request to free the dates, got %+v", events)
+	}
+}
+
+// TestBookedRangesFrom_MergesFromTodayAndFindsNextFreeDay verifica que se unan los rangos que se tocan,
+// se recorten los que empezaron antes de hoy y el primer día libre salte los rangos que empiezan hoy
+func TestBookedRangesFrom_MergesFromTodayAndFindsNextFreeDay(t *testing.T) {
+	today := time.Date(2099, 8, 1, 0, 0, 0, 0, time.UTC)
+	ranges := []dto.AvailabilityRangeDTO{
+		{Start: "2099-07-20", End: "2099-07-25", Source: "booking"},
+		{Start: "2099-07-30", End: "2099-08-02", Source: "booking"},
+		{Start: "2099-08-02", End: "2099-08-05", Source: "manual"},
+		{Start: "2099-08-10", End: "2099-08-12", Source: "block"},
+	}
+
+	booked, next := bookedRangesFrom(ranges, today)
+
+	if len(booked) != 2 || !booked[0].start.Equal(today) || booked[0].end.Format("2006-01-02") != "2099-08-05" || booked[1].start.Format("2006-01-02") != "2099-08-10" {
+		t.Fatalf("Expected [2099-08-01, 2099-08-05) and [2099-08-10, 2099-08-12), got %+v", booked)
+	}
+	if next.Format("2006-01-02") != "2099-08-05" {
+		t.Errorf("Expected the next free day to be 2099-08-05, got %s", next.Format("2006-01-02"))
+	}
+}
+
+// TestQuoteBooking_GuestBreakdownAndExtraGuestFees verifica que los bebés no cuenten para la capacidad
+// ni paguen el cargo por huésped extra, y que el cargo se sume antes de la tarifa de servicio y los impuestos
+func TestQuoteBooking_GuestBreakdownAndExtraGuestFees(t *testing.T) {
+	// Arrange
+	property := createTestProperty("507f1f77bcf86cd799439011", "owner123")
+	property.Price = 100
+	property.Capacity = 4
+	property.GuestsIncluded = 2
+	property.ExtraGuestFee = 15
+	repo := &mockRepository{
+		GetByIDFunc: func(id string) (domain.Property, error) {
+			return property, nil
+		},
+	}
+	bookings := &mockBookingRepository{}
+	calendar := NewCalendarService(repo, bookings, &mockAvailabilityRepository{}, &mockCalendarFeedRepository{}, &mockCalendarFeedClient{}, &mockRabbitClient{})
+	coupons := NewCouponService(&mockCouponRepository{}, NewAuditService(&mockAuditRepository{}))
+	service := NewBookingService(bookings, &mockBookingHoldRepository{bookings: bookings}, repo, calendar, &mockRabbitClient{}, coupons, config.PricingConfig{ServiceFeeRate: 0.1}, config.BookingsConfig{RequestTTL: 24 * time.Hour})
+
+	// Act
+	quote, err := service.QuoteBooking(context.Background(), property.ID.Hex(), "2099-04-01", "2099-04-03", dto.GuestCountsDTO{Adults: 2, Children: 2, Infants: 1}, "")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if quote.Guests != 4 || quote.Infants != 1 {
+		t.Errorf("Expected 4 guests plus 1 infant, got %+v", quote)
+	}
+	breakdown := quote.Breakdown
+	if breakdown.ExtraGuests != 2 || breakdown.ExtraGuestFee != 15 || breakdown.ExtraGuestFees != 60 {
+		t.Errorf("Expected 2 extra guests x 15 x 2 nights = 60, got %+v", breakdown)
+	}
+	if breakdown.ServiceFee != 26 || breakdown.Total != 286 {
+		t.Errorf("Expected (200 + 60) + 26 = 286, got %+v", breakdown)
+	}
+
+	booking, err := service.CreateBooking(context.Background(), "guest42", dto.BookingCreateDTO{
+		PropertyID: property.ID.Hex(),
+		CheckIn:    "2099-04-01",
+		CheckOut:   "2099-04-03",
+		Guests:     2,
+	})
+	if err != nil || booking.Adults != 2 || booking.PriceBreakdown.ExtraGuests != 0 {
+		t.Errorf("Expected legacy guests as adults without extra fees, got %+v (%v)", booking, err)
+	}
+
+	_, err = service.QuoteBooking(context.Background(), property.ID.Hex(), "2099-05-01", "2099-05-03", dto.GuestCountsDTO{Adults: 3, Children: 2}, "")
+	if !errors.Is(err, ErrTooManyGuests) {
+		t.Errorf("Expected ErrTooManyGuests for 5 guests over capacity 4, got %v", err)
+	}
+	_, err = service.QuoteBooking(context.Background(), property.ID.Hex(), "2099-05-01", "2099-05-03", dto.GuestCountsDTO{Children: 2}, "")
+	if !errors.Is(err, ErrInvalidStay) {
+		t.Errorf("Expected ErrInvalidStay without adults, got %v", err)
+	}
+}
+
+// TestBookingRequests_HostApprovesOrRequestExpires verifica que en una propiedad con reserva por solicitud
+// la reserva quede pendiente ocupando las fechas, que solo el anfitrión la apruebe y que las solicitudes
+// sin respuesta venzan liberando las fechas y el uso del cupón
+func TestBookingRequests_HostApprovesOrRequestExpires(t *testing.T) {
+	// Arrange
+	property := createTestProperty("507f1f77bcf86cd799439011", "owner123")
+	property.Price = 100
+	property.BookingMode = domain.BookingModeRequest
+	repo := &mockRepository{
+		GetByIDFunc: func(id string) (domain.Property, error) {
+			return property, nil
+		},
+	}
+	bookings := &mockBookingRepository{}
+	var confirmed []clients.BookingConfirmedEvent
+	var requestEvents []string
+	rabbit := &mockRabbitClient{
+		PublishBookingEventFunc: func(event clients.BookingConfirmedEvent) error {
+			confirmed = append(confirmed, event)
+			return nil
+		},
+		PublishBookingRequestEventFunc: func(routingKey string, event clients.BookingRequestEvent) error {
+			requestEvents = append(requestEvents, routingKey+":"+event.Status)
+			return nil
+		},
+	}
+	calendar := NewCalendarService(repo, bookings, &mockAvailabilityRepository{}, &mockCalendarFeedRepository{}, &mockCalendarFeedClient{}, &mockRabbitClient{})
+	coupons := NewCouponService(&mockCouponRepository{}, NewAuditService(&mockAuditRepository{}))
+	service := NewBookingService(bookings, &mockBookingHoldRepository{bookings: bookings}, repo, calendar, rabbit, coupons, config.PricingConfig{}, config.BookingsConfig{RequestTTL: 24 * time.Hour})
+	if _, err := coupons.CreateCoupon(context.Background(), "admin1", dto.CouponCreateDTO{
+		Code:           "UNICO",
+		Type:           domain.CouponPercentage,
+		Value:          10,
+		MaxRedemptions: 1,
+	}); err != nil {
+		t.Fatalf("Expected coupon to be created, got %v", err)
+	}
+
+	// Act
+	request, err := service.CreateBooking(context.Background(), "guest42", dto.BookingCreateDTO{
+		PropertyID: property.ID.Hex(),
+		CheckIn:    "2099-08-01",
+		CheckOut:   "2099-08-03",
+		Guests:     1,
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if request.Status != domain.BookingPending || request.ExpiresAt == "" {
+		t.Fatalf("Expected a pending request with expiry, got %+v", request)
+	}
+	if len(confirmed) != 0 || len(requestEvents) != 1 || requestEvents[0] != "booking.requested:pending" {
+		t.Errorf("Expected only a booking.requested event, got confirmed %+v and %v", confirmed, requestEvents)
+	}
+	if _, err := service.QuoteBooking(context.Background(), property.ID.Hex(), "2099-08-02", "2099-08-04", dto.GuestCountsDTO{Adults: 1}, ""); !errors.Is(err, ErrPropertyUnavailable) {
+		t.Errorf("Expected pending request to hold the dates, got %v", err)
+	}
+
+	if _, err := service.ApproveBooking(context.Background(), request.ID, "guest42", false); !errors.Is(err, ErrBookingForbidden) {
+		t.Errorf("Expected ErrBookingForbidden for the guest, got %v", err)
+	}
+	approved, err := service.ApproveBooking(context.Background(), request.ID, "owner123", false)
+	if err != nil || approved.Status != domain.BookingConfirmed || approved.ExpiresAt != "" {
+		t.Fatalf("Expected the host to confirm the request, got %+v (%v)", approved, err)
+	}
+	if len(confirmed) != 1 || confirmed[0].ID != request.ID {
+		t.Errorf("Expected booking.confirmed after approval, got %+v", confirmed)
+	}
+	if _, err := service.DeclineBooking(context.Background(), request.ID, "owner123", false); !errors.Is(err, ErrBookingNotPending) {
+		t.Errorf("Expected ErrBookingNotPending for an approved booking, got %v", err)
+	}
+
+	// Una solicitud con cupón que el anfitrión no responde
+	unanswered, err := service.CreateBooking(context.Background(), "guest7", dto.BookingCreateDTO{
+		PropertyID: property.ID.Hex(),
+		CheckIn:    "2099-09-01",
+		CheckOut:   "2099-09-03",
+		Guests:     1,
+		CouponCode: "UNICO",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := service.QuoteBooking(context.Background(), property.ID.Hex(), "2099-10-01", "2099-10-03", dto.GuestCountsDTO{Adults: 1}, "UNICO"); !errors.Is(err, ErrCouponExhausted) {
+		t.Errorf("Expected the pending request to hold the only coupon use, got %v", err)
+	}
+	past := time.Now().Add(-time.Minute)
+	bookings.bookings[len(bookings.bookings)-1].ExpiresAt = &past
+
+	expired, err := service.ExpirePendingRequests(context.Background())
+	if err != nil || expired != 1 {
+		t.Fatalf("Expected one expired request, got %d (%v)", expired, err)
+	}
+	if status := bookings.bookings[len(bookings.bookings)-1].Status; status != domain.BookingExpired {
+		t.Errorf("Expected status expired, got '%s'", status)
+	}
+	if requestEvents[len(requestEvents)-1] != "booking.expired:expired" {
+		t.Errorf("Expected a booking.expired event, got %v", requestEvents)
+	}
+	if _, err := service.ApproveBooking(context.Background(), unanswered.ID, "owner123", false); !errors.Is(err, ErrBookingNotPending) {
+		t.Errorf("Expected ErrBookingNotPending for an expired request, got %v", err)
+	}
+	if _, err := service.QuoteBooking(context.Background(), property.ID.Hex(), "2099-09-01", "2099-09-03", dto.GuestCountsDTO{Adults: 1}, "UNICO"); err != nil {
+		t.Errorf("Expected dates and coupon to be released after expiry, got %v", err)
+	}
+}
+
+// TestPropertyPhotos_ReorderAndSetCover verifica el orden de la galería, la portada y los permisos
+func TestPropertyPhotos_ReorderAndSetCover(t *testing.T) {
+	// Arrange
+	property := createTestProperty("507f1f77bcf86cd799439011", "owner123")
+	mockRepo := &mockRepository{
+		GetByIDFunc: func(id string) (domain.Property, error) {
+			return property, nil
+		},
+		UpdateFunc: func(id string, updated domain.Property) error {
+			property = updated
+			return nil
+		},
+	}
+	var published []dto.PropertyResponseDTO
+	rabbit := &mockRabbitClient{
+		PublishPropertySnapshotEventFunc: func(operation string, snapshot dto.PropertyResponseDTO) error {
+			published = append(published, snapshot)
+			return nil
+		},
+	}
+	service := newTestPropertyService(mockRepo, &mockUsersClient{}, rabbit)
+
+	photos, err := photosFromDTO([]dto.PhotoDTO{
+		{URL: "a.jpg", Caption: " Living "},
+		{URL: "b.jpg"},
+		{URL: "c.jpg"},
+	})
+	if err != nil {
+		t.Fatalf("Expected valid photos, got %v", err)
+	}
+	property.Images = photos
+
+	// Act
+	reordered, err := service.ReorderPhotos(context.Background(), property.ID.Hex(), "owner123", false, []string{"c.jpg", "a.jpg", "b.jpg"})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if urls := []string{reordered.Images[0].URL, reordered.Images[1].URL, reordered.Images[2].URL}; strings.Join(urls, ",") != "c.jpg,a.jpg,b.jpg" {
+		t.Errorf("Expected order c, a, b, got %v", urls)
+	}
+	if reordered.CoverImage != "a.jpg" || !reordered.Images[1].IsCover || reordered.Images[1].Caption != "Living" {
+		t.Errorf("Expected a.jpg to stay as cover with its caption, got %+v", reordered.Images)
+	}
+
+	withCover, err := service.SetCoverPhoto(context.Background(), property.ID.Hex(), "owner123", false, "b.jpg")
+	if err != nil {
+		t.Fatalf("Expected no error setting the cover, got %v", err)
+	}
+	covers := 0
+	for _, photo := range withCover.Images {
+		if photo.IsCover {
+			covers++
+		}
+	}
+	if withCover.CoverImage != "b.jpg" || covers != 1 {
+		t.Errorf("Expected b.jpg as the only cover, got %+v", withCover.Images)
+	}
+	if len(published) != 2 || published[1].CoverImage != "b.jpg" {
+		t.Errorf("Expected an update snapshot per change with the new cover, got %d", len(published))
+	}
+
+	if _, err := service.ReorderPhotos(context.Background(), property.ID.Hex(), "owner123", false, []string{"a.jpg", "b.jpg"}); !errors.Is(err, ErrInvalidPhotos) {
+		t.Errorf("Expected ErrInvalidPhotos for an incomplete order, got %v", err)
+	}
+	if _, err := service.SetCoverPhoto(context.Background(), property.ID.Hex(), "owner123", false, "z.jpg"); !errors.Is(err, ErrPhotoNotFound) {
+		t.Errorf("Expected ErrPhotoNotFound for an unknown photo, got %v", err)
+	}
+	if _, err := service.SetCoverPhoto(context.Background(), property.ID.Hex(), "intruder", false, "a.jpg"); !errors.Is(err, ErrPhotosForbidden) {
+		t.Errorf("Expected ErrPhotosForbidden for another user, got %v", err)
+	}
+	if _, err := photosFromDTO([]dto.PhotoDTO{{URL: "a.jpg"}, {URL: "a.jpg"}}); !errors.Is(err, ErrInvalidPhotos) {
+		t.Errorf("Expected ErrInvalidPhotos for repeated URLs, got %v", err)
+	}
+}
+
+// TestPropertyPhotos_ReadsLegacyURLList verifica que las propiedades guardadas con la lista de URLs
+// se lean como fotos ordenadas con la primera como portada
+func TestPropertyPhotos_ReadsLegacyURLList(t *testing.T) {
+	raw, err := bson.Marshal(bson.D{{Key: "images", Value: bson.A{"a.jpg", "b.jpg"}}})
+	if err != nil {
+		t.Fatalf("Expected no error marshalling, got %v", err)
+	}
+
+	var property domain.Property
+	if err := bson.Unmarshal(raw, &property); err != nil {
+		t.Fatalf("Expected legacy images to decode, got %v", err)
+	}
+	response := toPropertyDTO(property)
+
+	if len(response.Images) != 2 || response.Images[1].URL != "b.jpg" || response.Images[1].Position != 1 {
+		t.Errorf("Expected a.jpg and b.jpg in order, got %+v", response.Images)
+	}
+	if response.CoverImage != "a.jpg" || !response.Images[0].IsCover {
+		t.Errorf("Expected the first photo as cover, got %+v", response.Images)
+	}
+}
+
+// mockImageStorage es un ImageStorage en memoria
+type mockImageStorage struct {
+	files map[string][]byte
+}
+
+// Save implementa ImageStorage.Save
+func (m *mockImageStorage) Save(key string, data []byte) (string, error) {
+	if m.files == nil {
+		m.files = make(map[string][]byte)
+	}
+	m.files[key] = data
+	return "http://media.test/" + key, nil
+}
+
+// Read implementa ImageStorage.Read
+func (m *mockImageStorage) Read(key string) ([]byte, error) {
+	data, ok := m.files[key]
+	if !ok {
+		return nil, errors.New("file not found")
+	}
+	return data, nil
+}
+
+// Delete implementa ImageStorage.Delete
+func (m *mockImageStorage) Delete(key string) error {
+	delete(m.files, key)
+	return nil
+}
+
+func TestImageService_UploadAndProcessPhoto(t *testing.T) {
+	// Arrange
+	property := createTestProperty("507f1f77bcf86cd799439011", "owner123")
+	mockRepo := &mockRepository{
+		GetByIDFunc: func(id string) (domain.Property, error) {
+			return property, nil
+		},
+		UpdateFunc: func(id string, updated domain.Property) error {
+			property = updated
+			return nil
+		},
+		SetPhotoVariantsFunc: func(ctx context.Context, id, photoURL, status string, variants []domain.PhotoVariant) error {
+			for i := range property.Images {
+				if property.Images[i].URL == photoURL {
+					property.Images[i].Status = status
+					property.Images[i].Variants = variants
+					return nil
+				}
+			}
+			return repositories.ErrPhotoNotInGallery
+		},
+	}
+	var jobs []clients.ImageJob
+	var published []dto.PropertyResponseDTO
+	rabbit := &mockRabbitClient{
+		PublishPropertySnapshotEventFunc: func(operation string, snapshot dto.PropertyResponseDTO) error {
+			published = append(published, snapshot)
+			return nil
+		},
+		PublishImageJobFunc: func(job clients.ImageJob) error {
+			jobs = append(jobs, job)
+			return nil
+		},
+	}
+	storage := &mockImageStorage{}
+	service := NewImageService(mockRepo, storage, rabbit, nil, NewAuditService(&mockAuditRepository{}), config.ImagesConfig{MaxUploadBytes: 1 << 20, JPEGQuality: 80, WebPQuality: 75})
+
+	var upload bytes.Buffer
+	if err := png.Encode(&upload, image.NewRGBA(image.Rect(0, 0, 1000, 500))); err != nil {
+		t.Fatalf("Expected a test PNG, got %v", err)
+	}
+
+	// Act
+	uploaded, err := service.UploadPhoto(context.Background(), property.ID.Hex(), "owner123", false, upload.Bytes(), "Frente")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error uploading, got %v", err)
+	}
+	last := uploaded.Images[len(uploaded.Images)-1]
+	if last.Status != domain.PhotoStatusProcessing || last.Caption != "Frente" || len(jobs) != 1 || jobs[0].PhotoURL != last.URL {
+		t.Fatalf("Expected a processing photo and its job, got %+v and %+v", last, jobs)
+	}
+
+	if _, err := service.UploadPhoto(context.Background(), property.ID.Hex(), "intruder", false, upload.Bytes(), ""); !errors.Is(err, ErrPhotosForbidden) {
+		t.Errorf("Expected ErrPhotosForbidden for another user, got %v", err)
+	}
+	if _, err := service.UploadPhoto(context.Background(), property.ID.Hex(), "owner123", false, []byte("not an image"), ""); !errors.Is(err, ErrInvalidImage) {
+		t.Errorf("Expected ErrInvalidImage, got %v", err)
+	}
+
+	if err := service.ProcessJob(context.Background(), jobs[0]); err != nil {
+		t.Fatalf("Expected no error processing, got %v", err)
+	}
+	processed := toPropertyDTO(property)
+	photo := processed.Images[len(processed.Images)-1]
+	if photo.Status != domain.PhotoStatusReady || len(photo.Variants) != 3 {
+		t.Fatalf("Expected a ready photo with 3 JPEG variants, got %+v", photo)
+	}
+	thumbnail := photo.Variants[0]
+	if thumbnail.Size != domain.PhotoSizeThumbnail || thumbnail.Width != 320 || thumbnail.Height != 160 {
+		t.Errorf("Expected a 320x160 thumbnail, got %+v", thumbnail)
+	}
+	if large := photo.Variants[2]; large.Width != 1000 {
+		t.Errorf("Expected the large variant not to upscale the original, got %+v", large)
+	}
+	if _, ok := storage.files[strings.TrimPrefix(thumbnail.URL, "http://media.test/")]; !ok {
+		t.Errorf("Expected the thumbnail to be stored, got keys %v", storage.files)
+	}
+	if snapshot := published[len(published)-1]; !photo.IsCover || snapshot.CoverThumbnail != thumbnail.URL {
+		t.Errorf("Expected the first upload as cover and its thumbnail in the snapshot, got %q", snapshot.CoverThumbnail)
+	}
+}
+
+func TestModeration_QuarantinesFlaggedListingUntilApproved(t *testing.T) {
+	// Arrange
+	propertyID := primitive.NewObjectID()
+	var stored domain.Property
+	mockRepo := &mockRepository{
+		CreateFunc: func(property domain.Property) (domain.Property, error) {
+			property.ID = propertyID
+			stored = property
+			return property, nil
+		},
+		GetByIDFunc: func(id string) (domain.Property, error) {
+			return stored, nil
+		},
+		UpdateFunc: func(id string, property domain.Property) error {
+			stored = property
+			return nil
+		},
+	}
+	var operations []string
+	rabbit := &mockRabbitClient{
+		PublishPropertySnapshotEventFunc: func(operation string, snapshot dto.PropertyResponseDTO) error {
+			operations = append(operations, operation)
+			return nil
 		},
 	}
+	users := &mockUsersClient{ValidateUserFunc: func(userID string) (bool, error) { return true, nil }}
+	audit := NewAuditService(&mockAuditRepository{})
+	moderationRepo := &mockModerationRepository{}
+	moderation := NewModerationService(moderationRepo, mockRepo, rabbit, audit, NewBlockedWordsCheck([]string{"estafa"}), NewContactInfoCheck())
+	service := NewPropertyService(mockRepo, &mockPriceHistoryRepository{}, users, rabbit, audit, moderation)
 
-	mockUsersClient := &mockUsersClient{}
-	mockRabbitClient := &mockRabbitClient{}
+	createDTO := createTestCreateDTO("user123")
+	createDTO.Description = "Escribime a anfitrion@mail.com para reservar por fuera"
 
-	service := NewPropertyService(mockRepo, mockUsersClient, mockRabbitClient)
+	// Act
+	created, err := service.CreateProperty(context.Background(), createDTO)
 
-	updateDTO := dto.PropertyUpdateDTO{
-		Title: stringPtr("Updated Title"),
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if created.ModerationStatus != domain.ModerationPending || len(operations) != 0 {
+		t.Fatalf("Expected a pending listing without events, got status %q and events %v", created.ModerationStatus, operations)
+	}
+	if _, err := service.GetPropertyByID(context.Background(), created.ID); err == nil {
+		t.Error("Expected a pending listing to be hidden")
+	}
+	pending, _ := moderation.ListCases(context.Background(), "")
+	if len(pending) != 1 || pending[0].Flags[0].Check != checkContactInfo || pending[0].Flags[0].Field != moderatedFieldDescription {
+		t.Fatalf("Expected one contact info case on the description, got %+v", pending)
 	}
 
-	// Act
-	err := service.UpdateProperty(propertyID, updateDTO, unauthorizedUserID)
+	approved, err := moderation.Approve(context.Background(), pending[0].ID, "admin1", "Ok")
+	if err != nil {
+		t.Fatalf("Expected no error approving, got %v", err)
+	}
+	if approved.Status != domain.ModerationCaseApproved || stored.ModerationStatus != "" || strings.Join(operations, ",") != "create" {
+		t.Errorf("Expected the approved listing to be published with a create event, got %q and %v", stored.ModerationStatus, operations)
+	}
+	if _, err := moderation.Approve(context.Background(), pending[0].ID, "admin1", ""); !errors.Is(err, ErrModerationCaseClosed) {
+		t.Errorf("Expected ErrModerationCaseClosed approving twice, got %v", err)
+	}
 
-	// Assert
-	if err == nil {
-		t.Fatal("Expected error when user is not authorized")
+	// Una edición retenida de una propiedad publicada mantiene el título anterior
+	publishedTitle := stored.Title
+	newTitle := "Depto céntrico, ESTAFA garantizada"
+	if err := service.UpdateProperty(context.Background(), created.ID, dto.PropertyUpdateDTO{Title: &newTitle}, "user123", false); err != nil {
+		t.Fatalf("Expected no error updating, got %v", err)
+	}
+	if stored.Title != publishedTitle || stored.ModerationStatus != domain.ModerationChangesPending {
+		t.Fatalf("Expected the published title to stay while the edit is reviewed, got %q (%s)", stored.Title, stored.ModerationStatus)
+	}
+	pending, _ = moderation.ListCases(context.Background(), "")
+	if len(pending) != 1 || pending[0].Content[moderatedFieldTitle] != newTitle || pending[0].Flags[0].Check != checkBlockedWords {
+		t.Fatalf("Expected the new title quarantined by the blocked words check, got %+v", pending)
 	}
 
-	// Verificar que el error contiene información sobre permisos
-	if !contains(err.Error(), "no tiene permisos") && !contains(err.Error(), "permission") {
-		t.Errorf("Expected error message about unauthorized access, got: %v", err)
+	if _, err := moderation.Reject(context.Background(), pending[0].ID, "admin1", "Título no permitido"); err != nil {
+		t.Fatalf("Expected no error rejecting, got %v", err)
+	}
+	if stored.Title != publishedTitle || stored.ModerationStatus != "" {
+		t.Errorf("Expected the rejected edit to be discarded, got %q (%s)", stored.Title, stored.ModerationStatus)
+	}
+	if cases, _ := moderation.ListUserCases(context.Background(), "user123"); len(cases) != 2 {
+		t.Errorf("Expected the owner to see both cases, got %d", len(cases))
 	}
 }
 
-// TestDeleteProperty_Success testa eliminación exitosa
-func TestDeleteProperty_Success(t *testing.T) {
-	// Arrange
-	propertyID := primitive.NewObjectID().Hex()
-	ownerID := "owner123"
-	existingProperty := createTestProperty(propertyID, ownerID)
+func TestContentChecks_DetectContactInfoAndBlockedWords(t *testing.T) {
+	contact := NewContactInfoCheck()
+	for _, text := range []string{"Llamame al +54 9 11 5555-1234", "Más fotos en www.micasa.com", "Consultas por WhatsApp"} {
+		if reasons, _ := contact.Check(context.Background(), text); len(reasons) == 0 {
+			t.Errorf("Expected contact info in %q", text)
+		}
+	}
+	if reasons, _ := contact.Check(context.Background(), "Depto de 2 ambientes a 300 metros del mar, 4 huéspedes"); len(reasons) != 0 {
+		t.Errorf("Expected a regular description to pass, got %v", reasons)
+	}
+
+	blocked := NewBlockedWordsCheck([]string{"Estafa", "dinero fácil"})
+	if reasons, _ := blocked.Check(context.Background(), "Ganá DINERO FACIL con esta casa"); len(reasons) != 1 {
+		t.Errorf("Expected the phrase to match ignoring case and accents, got %v", reasons)
+	}
+	if reasons, _ := blocked.Check(context.Background(), "Casa con estafeta de correo"); len(reasons) != 0 {
+		t.Errorf("Expected only whole words to match, got %v", reasons)
+	}
+	if NewBlockedWordsCheck(nil) != nil {
+		t.Error("Expected no check without blocked words")
+	}
+}
 
+func TestSearchUserProperties_FiltersSortsAndHidesUnpublishedFromOthers(t *testing.T) {
+	var captured repositories.OwnerPropertyFilter
 	mockRepo := &mockRepository{
-		GetByIDFunc: func(id string) (domain.Property, error) {
-			if id == propertyID {
-				return existingProperty, nil
-			}
-			return domain.Property{}, errors.New("property not found")
+		FindByOwnerFunc: func(ctx context.Context, filter repositories.OwnerPropertyFilter) ([]domain.Property, int64, error) {
+			captured = filter
+			return []domain.Property{{ID: primitive.NewObjectID(), Title: "Depto en Nueva Córdoba", OwnerID: filter.OwnerID}}, 45, nil
 		},
-		DeleteFunc: func(id string) error {
-			if id == propertyID {
-				return nil
+	}
+	service := newTestPropertyService(mockRepo, &mockUsersClient{}, &mockRabbitClient{})
+
+	available := true
+	page, err := service.SearchUserProperties(context.Background(), "owner123", dto.UserPropertiesQuery{
+		Query: "  depto ", Status: "pending", Available: &available, Sort: "-price", Page: 2, Limit: 20,
+	}, true)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if captured.Title != "depto" || captured.Status != "pending" || captured.Available == nil || !*captured.Available {
+		t.Errorf("Expected the filters to reach the repository, got %+v", captured)
+	}
+	if captured.SortField != "price" || !captured.SortDesc || captured.Page != 2 || captured.Limit != 20 {
+		t.Errorf("Expected descending price sort on page 2, got %+v", captured)
+	}
+	if page.Total != 45 || page.TotalPages != 3 || len(page.Properties) != 1 {
+		t.Errorf("Expected 45 properties in 3 pages, got %+v", page)
+	}
+
+	// Sin ser el owner solo se listan las publicadas, con el orden y la página por defecto
+	if _, err := service.SearchUserProperties(context.Background(), "owner123", dto.UserPropertiesQuery{}, false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if captured.Status != repositories.OwnerStatusPublished || captured.SortField != "createdAt" || !captured.SortDesc ||
+		captured.Page != 1 || captured.Limit != defaultUserPropertiesPageSize {
+		t.Errorf("Expected only published properties, newest first, got %+v", captured)
+	}
+	if _, err := service.SearchUserProperties(context.Background(), "owner123", dto.UserPropertiesQuery{Status: "rejected"}, false); !errors.Is(err, ErrUnpublishedForbidden) {
+		t.Errorf("Expected ErrUnpublishedForbidden, got %v", err)
+	}
+}
+
+// TestEventReplay_RepublishesPublishedPropertiesInRange verifica la operación de cada evento, que se salteen
+// las propiedades no publicadas y que se rechacen los rangos inválidos
+func TestEventReplay_RepublishesPublishedPropertiesInRange(t *testing.T) {
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(48 * time.Hour)
+
+	existing := createTestProperty("507f1f77bcf86cd799439011", "owner123")
+	existing.CreatedAt = from.AddDate(0, -1, 0)
+	existing.UpdatedAt = from.Add(time.Hour)
+	created := createTestProperty("507f1f77bcf86cd799439012", "owner123")
+	created.CreatedAt = from.Add(2 * time.Hour)
+	created.UpdatedAt = created.CreatedAt
+	pending := createTestProperty("507f1f77bcf86cd799439013", "owner123")
+	pending.CreatedAt = from.Add(3 * time.Hour)
+	pending.UpdatedAt = pending.CreatedAt
+	pending.ModerationStatus = domain.ModerationPending
+
+	var filters []repositories.PropertyFilter
+	mockRepo := &mockRepository{
+		StreamFilteredFunc: func(ctx context.Context, filter repositories.PropertyFilter, fn func(domain.Property) error) error {
+			filters = append(filters, filter)
+			for _, property := range []domain.Property{existing, created, pending} {
+				if err := fn(property); err != nil {
+					return err
+				}
 			}
-			return errors.New("property not found")
+			return nil
 		},
 	}
-
-	mockUsersClient := &mockUsersClient{}
+	published := make(map[string]string)
 	mockRabbitClient := &mockRabbitClient{
-		PublishPropertyEventFunc: func(operation string, propID string) error {
-			if operation == "delete" && propID == propertyID {
-				return nil
-			}
-			return errors.New("invalid event")
+		PublishPropertySnapshotEventFunc: func(operation string, property dto.PropertyResponseDTO) error {
+			published[property.ID] = operation
+			return nil
 		},
 	}
+	auditRepo := &mockAuditRepository{}
+	service := NewEventReplayService(mockRepo, mockRabbitClient, NewAuditService(auditRepo))
 
-	service := NewPropertyService(mockRepo, mockUsersClient, mockRabbitClient)
-
-	// Act
-	err := service.DeleteProperty(propertyID, ownerID)
-
-	// Assert
+	result, err := service.Replay(context.Background(), "admin", dto.EventReplayRequestDTO{
+		From: from.Format(time.RFC3339),
+		To:   to.Format(time.RFC3339),
+	})
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
+	if len(filters) != 1 || !filters[0].UpdatedFrom.Equal(from) || !filters[0].UpdatedTo.Equal(to) {
+		t.Errorf("Expected properties filtered by updatedAt in [%v, %v), got %+v", from, to, filters)
+	}
+	if result.Matched != 3 || result.Created != 1 || result.Updated != 1 || result.Skipped != 1 || result.Failed != 0 {
+		t.Errorf("Unexpected replay counters: %+v", result)
+	}
+	if published[existing.ID.Hex()] != "update" || published[created.ID.Hex()] != "create" {
+		t.Errorf("Expected update for the existing property and create for the new one, got %v", published)
+	}
+	if _, ok := published[pending.ID.Hex()]; ok {
+		t.Error("Expected the pending property not to be republished")
+	}
+	if len(auditRepo.entries) != 1 || auditRepo.entries[0].Action != AuditActionEventReplay {
+		t.Errorf("Expected one audit entry for the replay, got %+v", auditRepo.entries)
+	}
+
+	// Con dryRun solo se cuenta
+	published = make(map[string]string)
+	result, err = service.Replay(context.Background(), "admin", dto.EventReplayRequestDTO{From: "2026-10-01", To: "2026-10-03", DryRun: true})
+	if err != nil || len(published) != 0 || result.Created != 1 || result.Updated != 1 {
+		t.Errorf("Expected a dry run to count without publishing, got %+v (published %v, err %v)", result, published, err)
+	}
+
+	if _, err := service.Replay(context.Background(), "admin", dto.EventReplayRequestDTO{From: "2026-10-03", To: "2026-10-01"}); !errors.Is(err, ErrInvalidDateRange) {
+		t.Errorf("Expected ErrInvalidDateRange, got %v", err)
+	}
+	if _, err := service.Replay(context.Background(), "admin", dto.EventReplayRequestDTO{From: "2026-01-01", To: "2026-10-01"}); !errors.Is(err, ErrReplayWindowTooLarge) {
+		t.Errorf("Expected ErrReplayWindowTooLarge, got %v", err)
+	}
 }
 
-// TestDeleteProperty_Unauthorized testa eliminación sin permisos usando tabla de tests
-func TestDeleteProperty_Unauthorized(t *testing.T) {
-	// Tabla de tests para diferentes escenarios de no autorización
-	tests := []struct {
-		name           string
-		propertyID     string
-		ownerID        string
-		requestingUser string
-		expectedError  string
-	}{
-		{
-			name:           "Different user tries to delete",
-			propertyID:     "property123",
-			ownerID:        "owner123",
-			requestingUser: "user456",
-			expectedError:  "no tiene permisos",
+// TestCreateProperty_Translations verifica que las traducciones se validen y normalicen, viajen en el
+// snapshot y que una traducción editada con contenido retenido no se publique hasta aprobarla
+func TestCreateProperty_Translations(t *testing.T) {
+	var stored domain.Property
+	mockRepo := &mockRepository{
+		CreateFunc: func(property domain.Property) (domain.Property, error) {
+			property.ID = primitive.NewObjectID()
+			stored = property
+			return property, nil
 		},
-		{
-			name:           "Empty user ID",
-			propertyID:     "property123",
-			ownerID:        "owner123",
-			requestingUser: "",
-			expectedError:  "no tiene permisos",
+		GetByIDFunc: func(id string) (domain.Property, error) { return stored, nil },
+		UpdateFunc: func(id string, property domain.Property) error {
+			stored = property
+			return nil
+		},
+	}
+	var snapshot dto.PropertyResponseDTO
+	rabbit := &mockRabbitClient{
+		PublishPropertySnapshotEventFunc: func(operation string, property dto.PropertyResponseDTO) error {
+			snapshot = property
+			return nil
 		},
 	}
+	users := &mockUsersClient{ValidateUserFunc: func(userID string) (bool, error) { return true, nil }}
+	audit := NewAuditService(&mockAuditRepository{})
+	moderation := NewModerationService(&mockModerationRepository{}, mockRepo, rabbit, audit, NewBlockedWordsCheck([]string{"scam"}))
+	service := NewPropertyService(mockRepo, &mockPriceHistoryRepository{}, users, rabbit, audit, moderation)
+
+	createDTO := createTestCreateDTO("user123")
+	createDTO.Translations = map[string]dto.PropertyTranslationDTO{
+		"EN":    {Title: " Cozy cabin ", Description: "Cabin by the lake"},
+		"pt_br": {Title: "Cabana aconchegante", Description: "Cabana perto do lago"},
+	}
+	created, err := service.CreateProperty(context.Background(), createDTO)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if created.Language != "es" || created.Translations["en"].Title != "Cozy cabin" || created.Translations["pt-BR"].Title != "Cabana aconchegante" {
+		t.Fatalf("Expected normalized translations with Spanish as main language, got %q %+v", created.Language, created.Translations)
+	}
+	if len(snapshot.Translations) != 2 || snapshot.Title != createDTO.Title {
+		t.Errorf("Expected the snapshot to keep the main text and carry the translations, got %+v", snapshot)
+	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Arrange
-			existingProperty := createTestProperty(tt.propertyID, tt.ownerID)
+	for _, translations := range []map[string]dto.PropertyTranslationDTO{
+		{"english": {Title: "Cabin", Description: "Cabin"}},
+		{"en": {Title: "Cabin"}},
+		{"es": {Title: "Cabaña", Description: "Cabaña"}},
+		{"en": {Title: "Cabin", Description: "Cabin"}, "EN": {Title: "Cabin", Description: "Cabin"}},
+	} {
+		createDTO.Translations = translations
+		if _, err := service.CreateProperty(context.Background(), createDTO); !errors.Is(err, ErrInvalidTranslations) {
+			t.Errorf("Expected ErrInvalidTranslations for %+v, got %v", translations, err)
+		}
+	}
 
-			mockRepo := &mockRepository{
-				GetByIDFunc: func(id string) (domain.Property, error) {
-					if id == tt.propertyID {
-						return existingProperty, nil
-					}
-					return domain.Property{}, errors.New("property not found")
-				},
-			}
+	// El idioma principal no puede pasar a ser uno que ya es traducción
+	english := "en"
+	if err := service.UpdateProperty(context.Background(), created.ID, dto.PropertyUpdateDTO{Language: &english}, "user123", false); !errors.Is(err, ErrInvalidTranslations) {
+		t.Errorf("Expected ErrInvalidTranslations switching to a translated language, got %v", err)
+	}
 
-			mockUsersClient := &mockUsersClient{}
-			mockRabbitClient := &mockRabbitClient{}
+	// Una traducción retenida deja las publicadas hasta que se aprueba
+	held := map[string]dto.PropertyTranslationDTO{"en": {Title: "Best deal, no scam", Description: "Cabin by the lake"}}
+	if err := service.UpdateProperty(context.Background(), created.ID, dto.PropertyUpdateDTO{Translations: &held}, "user123", false); err != nil {
+		t.Fatalf("Expected no error updating, got %v", err)
+	}
+	if len(stored.Translations) != 2 || stored.ModerationStatus != domain.ModerationChangesPending {
+		t.Fatalf("Expected the published translations to stay while the edit is reviewed, got %+v (%s)", stored.Translations, stored.ModerationStatus)
+	}
+	pending, _ := moderation.ListCases(context.Background(), "")
+	if len(pending) != 1 {
+		t.Fatalf("Expected one moderation case, got %+v", pending)
+	}
+	if _, err := moderation.Approve(context.Background(), pending[0].ID, "admin1", ""); err != nil {
+		t.Fatalf("Expected no error approving, got %v", err)
+	}
+	if len(stored.Translations) != 1 || stored.Translations["en"].Title != "Best deal, no scam" || snapshot.Translations["en"].Title != "Best deal, no scam" {
+		t.Errorf("Expected the approved translations to replace the previous ones, got %+v", stored.Translations)
+	}
+}
 
-			service := NewPropertyService(mockRepo, mockUsersClient, mockRabbitClient)
+func TestLocalizeProperty_PicksBestAcceptedLanguage(t *testing.T) {
+	property := dto.PropertyResponseDTO{
+		Title:       "Cabaña",
+		Description: "Cabaña junto al lago",
+		Language:    "es",
+		Translations: map[string]dto.PropertyTranslationDTO{
+			"en":    {Title: "Cabin", Description: "Cabin by the lake"},
+			"pt-BR": {Title: "Cabana", Description: "Cabana perto do lago"},
+		},
+	}
 
-			// Act
-			err := service.DeleteProperty(tt.propertyID, tt.requestingUser)
+	cases := []struct {
+		acceptLanguage string
+		title          string
+		language       string
+	}{
+		{"en-US,en;q=0.9", "Cabin", "en"},
+		{"pt-PT", "Cabana", "pt-BR"},             // mismo idioma, otra región
+		{"fr, es;q=0.5, en;q=0.8", "Cabin", "en"}, // sin francés gana el de mayor q
+		{"es-AR", "Cabaña", "es"},
+		{"de", "Cabaña", "es"}, // sin coincidencias queda el idioma principal
+		{"", "Cabaña", "es"},
+	}
+	for _, c := range cases {
+		localized := LocalizeProperty(property, utils.ParseAcceptLanguage(c.acceptLanguage))
+		if localized.Title != c.title || localized.Language != c.language {
+			t.Errorf("Accept-Language %q: expected %q (%s), got %q (%s)", c.acceptLanguage, c.title, c.language, localized.Title, localized.Language)
+		}
+	}
+	if property.Title != "Cabaña" {
+		t.Errorf("Expected the original DTO to be untouched, got %q", property.Title)
+	}
+}
 
-			// Assert
-			if err == nil {
-				t.Fatal("Expected error when user is not authorized")
-			}
+// mockTranslationClient traduce anteponiendo el locale destino y cuenta las llamadas
+type mockTranslationClient struct {
+	calls int
+	err   error
+}
 
-			if !contains(err.Error(), tt.expectedError) {
-				t.Errorf("Expected error to contain '%s', got: %v", tt.expectedError, err)
-			}
-		})
+func (m *mockTranslationClient) Translate(ctx context.Context, texts []string, source, target string) ([]string, error) {
+	m.calls++
+	if m.err != nil {
+		return nil, m.err
 	}
+	translated := make([]string, len(texts))
+	for i, text := range texts {
+		translated[i] = "[" + target + "] " + text
+	}
+	return translated, nil
 }
 
-// ============================================
-// HELPER FUNCTIONS
-// ============================================
+// mockAutoTranslationRepository guarda las traducciones automáticas en memoria
+type mockAutoTranslationRepository struct {
+	saved map[string]domain.AutoTranslation
+}
 
-// contains verifica si un string contiene otro
-func contains(s, substr string) bool {
-	if len(substr) == 0 {
-		return true
+func (m *mockAutoTranslationRepository) Get(ctx context.Context, propertyID, locale string) (*domain.AutoTranslation, error) {
+	translation, ok := m.saved[propertyID+"|"+locale]
+	if !ok {
+		return nil, nil
 	}
-	if len(s) < len(substr) {
-		return false
+	return &translation, nil
+}
+
+func (m *mockAutoTranslationRepository) Save(ctx context.Context, translation domain.AutoTranslation) error {
+	m.saved[translation.PropertyID+"|"+translation.Locale] = translation
+	return nil
+}
+
+func TestTranslationService_AutoTranslatesMissingLocaleAndCaches(t *testing.T) {
+	client := &mockTranslationClient{}
+	repo := &mockAutoTranslationRepository{saved: map[string]domain.AutoTranslation{}}
+	service := NewTranslationService(repo, client)
+	property := dto.PropertyResponseDTO{
+		ID:           "p1",
+		Title:        "Cabaña",
+		Description:  "Cabaña junto al lago",
+		Language:     "es",
+		Translations: map[string]dto.PropertyTranslationDTO{"en": {Title: "Cabin", Description: "Cabin by the lake"}},
 	}
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return true
+
+	// Con una traducción del anfitrión aceptada no se llama a la API
+	localized := service.Localize(context.Background(), property, []string{"fr", "en"})
+	if localized.Title != "Cabin" || localized.AutoTranslated || client.calls != 0 {
+		t.Fatalf("Expected the host translation without calling the API, got %+v (%d calls)", localized, client.calls)
+	}
+
+	localized = service.Localize(context.Background(), property, []string{"fr-CA"})
+	if localized.Title != "[fr-CA] Cabaña" || localized.Language != "fr-CA" || !localized.AutoTranslated {
+		t.Fatalf("Expected an auto-translated fr-CA version, got %+v", localized)
+	}
+	service.Localize(context.Background(), property, []string{"fr-CA"})
+	if client.calls != 1 {
+		t.Fatalf("Expected the second request to use the cached translation, got %d calls", client.calls)
+	}
+
+	// Si el anfitrión cambia el texto la traducción guardada ya no sirve
+	property.Title = "Cabaña renovada"
+	localized = service.Localize(context.Background(), property, []string{"fr-CA"})
+	if localized.Title != "[fr-CA] Cabaña renovada" || client.calls != 2 {
+		t.Fatalf("Expected a new translation after the edit, got %q (%d calls)", localized.Title, client.calls)
+	}
+
+	// Si la API falla queda el idioma principal
+	client.err = errors.New("api caída")
+	localized = service.Localize(context.Background(), property, []string{"de"})
+	if localized.Title != "Cabaña renovada" || localized.Language != "es" || localized.AutoTranslated {
+		t.Fatalf("Expected the main language when the API fails, got %+v", localized)
+	}
+}
+
+// mockAnalyticsRepository guarda los KPIs diarios en memoria y devuelve conteos fijos
+type mockAnalyticsRepository struct {
+	listings map[string]int64
+	bookings map[string]domain.BookingDayCounts
+	days     map[string]domain.DailyKPIs
+}
+
+func (m *mockAnalyticsRepository) CountListingsByDay(ctx context.Context, from, to time.Time) (map[string]int64, error) {
+	return m.listings, nil
+}
+
+func (m *mockAnalyticsRepository) CountBookingsByDay(ctx context.Context, from, to time.Time) (map[string]domain.BookingDayCounts, error) {
+	return m.bookings, nil
+}
+
+func (m *mockAnalyticsRepository) ListDays(ctx context.Context, from, to string) ([]domain.DailyKPIs, error) {
+	var days []domain.DailyKPIs
+	for date, day := range m.days {
+		if date >= from && date <= to {
+			days = append(days, day)
 		}
 	}
-	return false
+	return days, nil
 }
 
-// stringPtr crea un puntero a un string
-func stringPtr(s string) *string {
-	return &s
+func (m *mockAnalyticsRepository) SaveDays(ctx context.Context, days []domain.DailyKPIs) error {
+	for _, day := range days {
+		m.days[day.Date] = day
+	}
+	return nil
+}
+
+// mockSignupsClient simula CountSignups de users-api
+type mockSignupsClient struct {
+	days map[string]int64
+	err  error
+}
+
+func (m *mockSignupsClient) CountSignups(ctx context.Context, from, to time.Time) (map[string]int64, error) {
+	return m.days, m.err
 }
 
+// mockSearchAnalyticsClient simula GET /search/analytics/daily de search-api
+type mockSearchAnalyticsClient struct {
+	activity clients.SearchDailyActivity
+}
+
+func (m *mockSearchAnalyticsClient) DailyActivity(ctx context.Context, days int) (clients.SearchDailyActivity, error) {
+	return m.activity, nil
+}
+
+func TestAnalyticsService_AggregatesKPIsAndKeepsSettledDays(t *testing.T) {
+	now := time.Now().UTC()
+	todayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	today := todayStart.Format("2006-01-02")
+	yesterday := todayStart.AddDate(0, 0, -1).Format("2006-01-02")
+
+	repo := &mockAnalyticsRepository{
+		listings: map[string]int64{yesterday: 4, today: 1},
+		bookings: map[string]domain.BookingDayCounts{yesterday: {Created: 2, Confirmed: 1}, today: {Created: 1}},
+		days:     map[string]domain.DailyKPIs{},
+	}
+	signups := &mockSignupsClient{days: map[string]int64{yesterday: 2, today: 3}}
+	searches := &mockSearchAnalyticsClient{activity: clients.SearchDailyActivity{
+		CompleteSince: todayStart.AddDate(0, 0, -2),
+		Days: []clients.SearchDayActivity{
+			{Date: yesterday, Searches: 10, ClickedSearches: 4, Clicks: 5},
+			{Date: today, Searches: 6, ClickedSearches: 2, Clicks: 2},
+		},
+	}}
+	service := NewAnalyticsService(repo, signups, searches)
+
+	if err := service.Aggregate(context.Background(), 2); err != nil {
+		t.Fatalf("Unexpected error aggregating: %v", err)
+	}
+	dashboard, err := service.GetDashboard(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("Unexpected error reading the dashboard: %v", err)
+	}
+	expectedTotals := dto.KPICounts{NewUsers: 5, ListingsCreated: 5, Searches: 16, ClickedSearches: 6, Clicks: 7, BookingsCreated: 3, BookingsConfirmed: 1}
+	if dashboard.Totals != expectedTotals || dashboard.Incomplete {
+		t.Fatalf("Expected complete totals %+v, got %+v (incomplete=%v)", expectedTotals, dashboard.Totals, dashboard.Incomplete)
+	}
+	expectedConversions := []float64{1, 0.375, 0.5, 0.3333}
+	for i, step := range dashboard.Funnel {
+		if step.ConversionFromPrevious != expectedConversions[i] {
+			t.Errorf("Funnel step %s: expected conversion %v, got %v", step.Step, expectedConversions[i], step.ConversionFromPrevious)
+		}
+	}
+
+	// users-api cae y search-api se reinicia: ayer ya estaba cerrado y se conserva,
+	// hoy conserva el mayor valor visto y queda incompleto
+	signups.err = errors.New("users-api caído")
+	searches.activity = clients.SearchDailyActivity{
+		CompleteSince: now,
+		Days:          []clients.SearchDayActivity{{Date: yesterday}, {Date: today, Searches: 1}},
+	}
+	if err := service.Aggregate(context.Background(), 2); err != nil {
+		t.Fatalf("Unexpected error aggregating: %v", err)
+	}
+	if day := repo.days[yesterday]; day.Searches != 10 || day.NewUsers != 2 || len(day.Incomplete) != 0 {
+		t.Fatalf("Expected yesterday to keep its settled values, got %+v", day)
+	}
+	if day := repo.days[today]; day.Searches != 6 || day.NewUsers != 3 || !day.IsIncomplete(domain.KPISourceSearches) || !day.IsIncomplete(domain.KPISourceUsers) {
+		t.Fatalf("Expected today to keep the previous counts marked as incomplete, got %+v", day)
+	}
+
+	dashboard, err = service.GetDashboard(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("Unexpected error reading the dashboard: %v", err)
+	}
+	if len(dashboard.Days) != 3 || dashboard.Days[0].Incomplete[0] != domain.KPINotComputed || !dashboard.Incomplete {
+		t.Fatalf("Expected the uncomputed first day to be flagged, got %+v", dashboard.Days)
+	}
+}
@@ -0,0 +1,359 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"properties-api/domain"
+	"properties-api/dto"
+	"properties-api/utils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// importBatchSize es la cantidad de propiedades que se insertan en cada InsertMany
+const importBatchSize = 500
+
+// importRow es una fila leída del archivo de importación
+type importRow struct {
+	number    int
+	createDTO dto.PropertyCreateDTO
+	err       error // Error de formato de la fila (la fila no se importa)
+}
+
+// importReader lee las filas de un archivo de importación; retorna io.EOF al terminar
+type importReader func() (importRow, error)
+
+// pendingImport es una propiedad validada que espera a que se inserte su lote
+type pendingImport struct {
+	row      int
+	property domain.Property
+}
+
+// ImportProperties importa propiedades desde un CSV (mismas columnas que el export) o NDJSON
+// Cada fila se valida igual que en CreateProperty; las válidas se insertan en lotes
+// con InsertMany y se publica el evento "create" de cada una
+func (s *propertyService) ImportProperties(ctx context.Context, format utils.OutputFormat, r io.Reader, actorID string) (dto.PropertyImportReportDTO, error) {
+	var next importReader
+	switch format {
+	case utils.FormatCSV:
+		reader, err := newCSVImportReader(r)
+		if err != nil {
+			return dto.PropertyImportReportDTO{}, err
+		}
+		next = reader
+	case utils.FormatNDJSON:
+		next = newNDJSONImportReader(r)
+	default:
+		return dto.PropertyImportReportDTO{}, fmt.Errorf("formato de importación no soportado '%s': debe ser csv o ndjson", format)
+	}
+
+	report := dto.PropertyImportReportDTO{Rows: []dto.PropertyImportRowDTO{}}
+	ownerExists := make(map[string]bool)
+	ownerProperties := make(map[string][]domain.Property)
+	batch := make([]pendingImport, 0, importBatchSize)
+
+	for {
+		row, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return dto.PropertyImportReportDTO{}, err
+		}
+
+		report.Total++
+		if row.err == nil {
+			var property domain.Property
+			property, row.err = s.prepareImport(ctx, row.createDTO, ownerExists, ownerProperties)
+			if row.err == nil {
+				batch = append(batch, pendingImport{row: row.number, property: property})
+			}
+		}
+		if row.err != nil {
+			report.Rows = append(report.Rows, dto.PropertyImportRowDTO{Row: row.number, Error: row.err.Error()})
+			continue
+		}
+
+		if len(batch) == importBatchSize {
+			s.insertImportBatch(ctx, batch, actorID, &report)
+			batch = batch[:0]
+		}
+	}
+	s.insertImportBatch(ctx, batch, actorID, &report)
+
+	// Las filas inválidas se reportan al leerlas y las válidas al insertar su lote
+	sort.Slice(report.Rows, func(i, j int) bool { return report.Rows[i].Row < report.Rows[j].Row })
+	for _, row := range report.Rows {
+		if row.Error == "" {
+			report.Imported++
+		} else {
+			report.Failed++
+		}
+	}
+	return report, nil
+}
+
+// prepareImport valida una fila y arma la propiedad a insertar
+// Los owners ya validados y sus propiedades (para detectar duplicados) se cachean durante la importación
+func (s *propertyService) prepareImport(ctx context.Context, createDTO dto.PropertyCreateDTO, ownerExists map[string]bool, ownerProperties map[string][]domain.Property) (domain.Property, error) {
+	if err := validateCreateDTO(createDTO); err != nil {
+		return domain.Property{}, err
+	}
+
+	exists, checked := ownerExists[createDTO.OwnerID]
+	if !checked {
+		var err error
+		exists, err = s.usersClient.ValidateUser(ctx, createDTO.OwnerID)
+		if err != nil {
+			return domain.Property{}, fmt.Errorf("error validando usuario owner: %w", err)
+		}
+		ownerExists[createDTO.OwnerID] = exists
+	}
+	if !exists {
+		return domain.Property{}, fmt.Errorf("usuario owner con ID '%s' no existe", createDTO.OwnerID)
+	}
+
+	if _, err := utils.LoadPropertyLocation(createDTO.Timezone); err != nil {
+		return domain.Property{}, err
+	}
+	propertyType, err := normalizePropertyType(createDTO.PropertyType)
+	if err != nil {
+		return domain.Property{}, err
+	}
+	photos, err := photosFromDTO(createDTO.Images)
+	if err != nil {
+		return domain.Property{}, err
+	}
+
+	existing, loaded := ownerProperties[createDTO.OwnerID]
+	if !loaded {
+		existing, err = s.repo.GetByOwnerID(ctx, createDTO.OwnerID)
+		if err != nil {
+			fmt.Printf("⚠️ Error buscando duplicados para el owner %s: %v\n", createDTO.OwnerID, err)
+			existing = nil
+		}
+	}
+	signature := utils.PropertySignature(createDTO.Title, createDTO.Location, createDTO.OwnerID)
+	duplicateOf, err := matchDuplicate(existing, createDTO.Title, createDTO.Location, signature)
+	if err != nil {
+		return domain.Property{}, err
+	}
+
+	property := domain.Property{
+		// El ID se asigna antes de insertar para detectar duplicados dentro del mismo archivo
+		ID:           primitive.NewObjectID(),
+		Title:        createDTO.Title,
+		Description:  createDTO.Description,
+		Price:        utils.CalculatePriceWithConcurrency(createDTO.Price, createDTO.Amenities, createDTO.Capacity),
+		Location:     createDTO.Location,
+		PropertyType: propertyType,
+		Latitude:     createDTO.Latitude,
+		Longitude:    createDTO.Longitude,
+		Timezone:     createDTO.Timezone,
+		OwnerID:      createDTO.OwnerID,
+		Amenities:    createDTO.Amenities,
+		Capacity:     createDTO.Capacity,
+		Available:    createDTO.Available,
+		Images:       photos,
+		Signature:    signature,
+		DuplicateOf:  duplicateOf,
+	}
+	ownerProperties[createDTO.OwnerID] = append(existing, property)
+	return property, nil
+}
+
+// insertImportBatch inserta un lote y agrega al reporte el resultado de cada fila
+func (s *propertyService) insertImportBatch(ctx context.Context, batch []pendingImport, actorID string, report *dto.PropertyImportReportDTO) {
+	if len(batch) == 0 {
+		return
+	}
+
+	properties := make([]domain.Property, len(batch))
+	for i, pending := range batch {
+		properties[i] = pending.property
+	}
+
+	rowErrors, err := s.repo.CreateMany(ctx, properties)
+	for i, pending := range batch {
+		// Si falló todo el lote el error es el mismo para todas sus filas
+		rowErr := err
+		if rowErr == nil {
+			rowErr = rowErrors[i]
+		}
+		if rowErr != nil {
+			report.Rows = append(report.Rows, dto.PropertyImportRowDTO{Row: pending.row, Error: rowErr.Error()})
+			continue
+		}
+
+		response := s.toDTO(properties[i])
+		report.Rows = append(report.Rows, dto.PropertyImportRowDTO{Row: pending.row, ID: response.ID, DuplicateOf: response.DuplicateOf})
+		s.audit.Record(ctx, actorID, AuditActionPropertyCreate, auditEntityProperty, response.ID, nil, response)
+		if err := s.rabbitClient.PublishPropertySnapshotEvent("create", response); err != nil {
+			fmt.Printf("⚠️ Error publicando evento 'create' en RabbitMQ para propiedad %s: %v\n", response.ID, err)
+		}
+	}
+}
+
+// validateCreateDTO aplica a una fila importada las mismas reglas que el binding de PropertyCreateDTO
+func validateCreateDTO(createDTO dto.PropertyCreateDTO) error {
+	var errs []error
+	required := []struct {
+		name  string
+		value string
+	}{
+		{"title", createDTO.Title},
+		{"description", createDTO.Description},
+		{"location", createDTO.Location},
+		{"ownerId", createDTO.OwnerID},
+	}
+	for _, field := range required {
+		if strings.TrimSpace(field.value) == "" {
+			errs = append(errs, fmt.Errorf("%s es requerido", field.name))
+		}
+	}
+	if createDTO.Price <= 0 {
+		errs = append(errs, errors.New("price debe ser mayor a 0"))
+	}
+	if createDTO.Capacity < 1 {
+		errs = append(errs, errors.New("capacity debe ser al menos 1"))
+	}
+	if createDTO.Latitude < -90 || createDTO.Latitude > 90 {
+		errs = append(errs, errors.New("latitude debe estar entre -90 y 90"))
+	}
+	if createDTO.Longitude < -180 || createDTO.Longitude > 180 {
+		errs = append(errs, errors.New("longitude debe estar entre -180 y 180"))
+	}
+	return errors.Join(errs...)
+}
+
+// newCSVImportReader lee un CSV con header; las columnas se buscan por nombre
+// (las mismas del export, las columnas desconocidas como id o createdAt se ignoran)
+// amenities e images se separan con "|" (las fotos quedan en ese orden y la primera es la portada)
+func newCSVImportReader(r io.Reader) (importReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, errors.New("el CSV está vacío")
+		}
+		return nil, fmt.Errorf("error leyendo header del CSV: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// Excel agrega un BOM al inicio del archivo
+		columns[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, errors.New("el header del CSV debe incluir la columna 'title'")
+	}
+
+	number := 0
+	return func() (importRow, error) {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return importRow{}, io.EOF
+		}
+		number++
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return importRow{number: number, err: fmt.Errorf("CSV inválido: %w", parseErr.Err)}, nil
+			}
+			return importRow{}, fmt.Errorf("error leyendo CSV: %w", err)
+		}
+
+		value := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		var rowErrs []error
+		parseFloat := func(name string) float64 {
+			raw := value(name)
+			if raw == "" {
+				return 0
+			}
+			parsed, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				rowErrs = append(rowErrs, fmt.Errorf("%s debe ser numérico, se recibió '%s'", name, raw))
+			}
+			return parsed
+		}
+
+		createDTO := dto.PropertyCreateDTO{
+			Title:        value("title"),
+			Description:  value("description"),
+			Price:        parseFloat("price"),
+			Location:     value("location"),
+			PropertyType: value("propertyType"),
+			Latitude:     parseFloat("latitude"),
+			Longitude:    parseFloat("longitude"),
+			Timezone:     value("timezone"),
+			OwnerID:      value("ownerId"),
+			Amenities:    splitImportList(value("amenities")),
+			Capacity:     int(parseFloat("capacity")),
+			Images:       photoDTOsFromURLs(splitImportList(value("images"))),
+		}
+		if raw := value("available"); raw != "" {
+			available, err := strconv.ParseBool(raw)
+			if err != nil {
+				rowErrs = append(rowErrs, fmt.Errorf("available debe ser true o false, se recibió '%s'", raw))
+			}
+			createDTO.Available = available
+		}
+
+		return importRow{number: number, createDTO: createDTO, err: errors.Join(rowErrs...)}, nil
+	}, nil
+}
+
+// newNDJSONImportReader lee un objeto JSON (PropertyCreateDTO) por línea; las líneas vacías se ignoran
+func newNDJSONImportReader(r io.Reader) importReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	number := 0
+	return func() (importRow, error) {
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			number++
+
+			var createDTO dto.PropertyCreateDTO
+			if err := json.Unmarshal([]byte(line), &createDTO); err != nil {
+				return importRow{number: number, err: fmt.Errorf("JSON inválido: %w", err)}, nil
+			}
+			return importRow{number: number, createDTO: createDTO}, nil
+		}
+		if err := scanner.Err(); err != nil {
+			return importRow{}, fmt.Errorf("error leyendo NDJSON: %w", err)
+		}
+		return importRow{}, io.EOF
+	}
+}
+
+// splitImportList separa una lista de CSV con "|" (mismo formato que el export)
+func splitImportList(raw string) []string {
+	items := []string{}
+	for _, item := range strings.Split(raw, "|") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
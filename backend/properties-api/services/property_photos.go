@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"properties-api/clients"
+	"properties-api/domain"
+	"properties-api/dto"
+	"properties-api/repositories"
+	"properties-api/utils"
+)
+
+// ErrPhotosForbidden indica que el usuario no es owner de la propiedad ni admin
+var ErrPhotosForbidden = errors.New("solo el owner o un administrador pueden modificar las fotos de la propiedad")
+
+// ErrInvalidPhotos indica una galería inválida (URL vacía o repetida, epígrafe demasiado largo, orden incompleto)
+var ErrInvalidPhotos = errors.New("fotos inválidas")
+
+// ErrPhotoNotFound indica que la propiedad no tiene una foto con la URL indicada
+var ErrPhotoNotFound = errors.New("la propiedad no tiene esa foto")
+
+// maxPhotoCaptionLength es la longitud máxima del epígrafe de una foto
+const maxPhotoCaptionLength = 200
+
+// ReorderPhotos cambia el orden de la galería; urls debe tener cada foto de la propiedad una sola vez
+// La portada no cambia aunque deje de ser la primera foto
+func (s *propertyService) ReorderPhotos(ctx context.Context, id, userID string, isAdmin bool, urls []string) (dto.PropertyResponseDTO, error) {
+	property, err := s.photoOwnerProperty(ctx, id, userID, isAdmin)
+	if err != nil {
+		return dto.PropertyResponseDTO{}, err
+	}
+
+	photos := normalizePhotos(property.Images)
+	if len(urls) != len(photos) {
+		return dto.PropertyResponseDTO{}, fmt.Errorf("%w: el orden tiene %d fotos y la propiedad %d", ErrInvalidPhotos, len(urls), len(photos))
+	}
+	positions := make(map[string]int, len(urls))
+	for i, url := range urls {
+		url = strings.TrimSpace(url)
+		if _, repeated := positions[url]; repeated {
+			return dto.PropertyResponseDTO{}, fmt.Errorf("%w: la foto '%s' está repetida en el orden", ErrInvalidPhotos, url)
+		}
+		positions[url] = i
+	}
+	for i := range photos {
+		position, ok := positions[photos[i].URL]
+		if !ok {
+			return dto.PropertyResponseDTO{}, fmt.Errorf("%w: falta la foto '%s' en el orden", ErrInvalidPhotos, photos[i].URL)
+		}
+		photos[i].Position = position
+	}
+
+	return s.savePhotos(ctx, property, photos, userID)
+}
+
+// SetCoverPhoto marca la foto con la URL indicada como portada; la anterior deja de serlo
+func (s *propertyService) SetCoverPhoto(ctx context.Context, id, userID string, isAdmin bool, url string) (dto.PropertyResponseDTO, error) {
+	property, err := s.photoOwnerProperty(ctx, id, userID, isAdmin)
+	if err != nil {
+		return dto.PropertyResponseDTO{}, err
+	}
+
+	url = strings.TrimSpace(url)
+	photos := normalizePhotos(property.Images)
+	found := false
+	for i := range photos {
+		photos[i].IsCover = photos[i].URL == url
+		found = found || photos[i].IsCover
+	}
+	if !found {
+		return dto.PropertyResponseDTO{}, fmt.Errorf("%w: '%s'", ErrPhotoNotFound, url)
+	}
+
+	return s.savePhotos(ctx, property, photos, userID)
+}
+
+// photoOwnerProperty obtiene la propiedad y verifica que el usuario pueda modificar sus fotos
+func (s *propertyService) photoOwnerProperty(ctx context.Context, id, userID string, isAdmin bool) (domain.Property, error) {
+	property, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return domain.Property{}, fmt.Errorf("error obteniendo propiedad: %w", err)
+	}
+	if property.OwnerID != userID && !isAdmin {
+		return domain.Property{}, ErrPhotosForbidden
+	}
+	return property, nil
+}
+
+// savePhotos guarda la galería, registra la auditoría y publica el snapshot para reindexar la portada
+func (s *propertyService) savePhotos(ctx context.Context, property domain.Property, photos []domain.Photo, userID string) (dto.PropertyResponseDTO, error) {
+	return persistPhotos(ctx, s.repo, s.rabbitClient, s.audit, property, photos, userID)
+}
+
+// persistPhotos reemplaza la galería de la propiedad, registra la auditoría y publica el snapshot
+// La comparten la edición de la galería y la subida de fotos
+func persistPhotos(ctx context.Context, repo repositories.PropertyRepository, rabbitClient clients.RabbitMQClient, audit AuditService, property domain.Property, photos []domain.Photo, userID string) (dto.PropertyResponseDTO, error) {
+	id := property.ID.Hex()
+	updated := property
+	updated.Images = normalizePhotos(photos)
+	updated.UpdatedAt = utils.NowUTC()
+	if err := repo.Update(ctx, id, updated); err != nil {
+		return dto.PropertyResponseDTO{}, fmt.Errorf("error actualizando fotos de la propiedad: %w", err)
+	}
+
+	response := toPropertyDTO(updated)
+	audit.Record(ctx, userID, AuditActionPropertyUpdate, auditEntityProperty, id, toPropertyDTO(property), response)
+	if err := rabbitClient.PublishPropertySnapshotEvent("update", response); err != nil {
+		fmt.Printf("⚠️ Error publicando evento 'update' en RabbitMQ para propiedad %s: %v\n", id, err)
+	}
+	return response, nil
+}
+
+// photosFromDTO valida las fotos recibidas al crear o actualizar una propiedad y las normaliza
+func photosFromDTO(photos []dto.PhotoDTO) ([]domain.Photo, error) {
+	result := make([]domain.Photo, 0, len(photos))
+	seen := make(map[string]bool, len(photos))
+	for _, photo := range photos {
+		url := strings.TrimSpace(photo.URL)
+		if url == "" {
+			return nil, fmt.Errorf("%w: la URL de la foto es obligatoria", ErrInvalidPhotos)
+		}
+		if seen[url] {
+			return nil, fmt.Errorf("%w: la foto '%s' está repetida", ErrInvalidPhotos, url)
+		}
+		seen[url] = true
+
+		caption := strings.TrimSpace(photo.Caption)
+		if len([]rune(caption)) > maxPhotoCaptionLength {
+			return nil, fmt.Errorf("%w: el epígrafe de '%s' supera los %d caracteres", ErrInvalidPhotos, url, maxPhotoCaptionLength)
+		}
+		result = append(result, domain.Photo{
+			URL:      url,
+			Caption:  caption,
+			Position: photo.Position,
+			IsCover:  photo.IsCover,
+		})
+	}
+	return normalizePhotos(result), nil
+}
+
+// keepUploadedPhotoData conserva el estado, la clave y las variantes de las fotos subidas que siguen
+// en la galería nueva (el cliente manda solo URL, epígrafe, posición y portada)
+func keepUploadedPhotoData(previous, photos []domain.Photo) []domain.Photo {
+	uploaded := make(map[string]domain.Photo, len(previous))
+	for _, photo := range previous {
+		if photo.StorageKey != "" {
+			uploaded[photo.URL] = photo
+		}
+	}
+	for i := range photos {
+		if original, ok := uploaded[photos[i].URL]; ok {
+			photos[i].Status = original.Status
+			photos[i].StorageKey = original.StorageKey
+			photos[i].Variants = original.Variants
+		}
+	}
+	return photos
+}
+
+// photoDTOsFromURLs arma la galería a partir de una lista de URLs (ej: la columna images del CSV)
+func photoDTOsFromURLs(urls []string) []dto.PhotoDTO {
+	photos := make([]dto.PhotoDTO, len(urls))
+	for i, url := range urls {
+		photos[i] = dto.PhotoDTO{URL: url, Position: i}
+	}
+	return photos
+}
+
+// normalizePhotos retorna una copia de la galería ordenada por posición (las empatadas conservan su orden),
+// con posiciones consecutivas desde 0 y una sola portada: la primera marcada o, si no hay, la primera foto
+// Las propiedades anteriores a las fotos estructuradas tienen todas posición 0 y ninguna portada
+func normalizePhotos(photos []domain.Photo) []domain.Photo {
+	normalized := append([]domain.Photo{}, photos...)
+	sort.SliceStable(normalized, func(i, j int) bool {
+		return normalized[i].Position < normalized[j].Position
+	})
+
+	cover := -1
+	for i := range normalized {
+		normalized[i].Position = i
+		if normalized[i].IsCover && cover < 0 {
+			cover = i
+		}
+	}
+	if cover < 0 {
+		cover = 0
+	}
+	for i := range normalized {
+		normalized[i].IsCover = i == cover
+	}
+	return normalized
+}
+
+// mergePhotos agrega a la galería base las fotos de extra que no tiene (por URL), al final y sin cambiar la portada
+func mergePhotos(base, extra []domain.Photo) []domain.Photo {
+	merged := normalizePhotos(base)
+	seen := make(map[string]bool, len(merged)+len(extra))
+	for _, photo := range merged {
+		seen[photo.URL] = true
+	}
+	for _, photo := range normalizePhotos(extra) {
+		if seen[photo.URL] {
+			continue
+		}
+		seen[photo.URL] = true
+		photo.Position = len(merged)
+		photo.IsCover = len(merged) == 0
+		merged = append(merged, photo)
+	}
+	return merged
+}
+
+// coverPhotoURL retorna la URL de la portada de una galería normalizada (vacía si no tiene fotos)
+func coverPhotoURL(photos []domain.Photo) string {
+	for _, photo := range photos {
+		if photo.IsCover {
+			return photo.URL
+		}
+	}
+	return ""
+}
+
+// coverThumbnails retorna la miniatura JPEG y WebP de la portada (vacías si la portada no tiene variantes)
+func coverThumbnails(photos []domain.Photo) (string, string) {
+	var jpegURL, webpURL string
+	for _, photo := range photos {
+		if !photo.IsCover {
+			continue
+		}
+		for _, variant := range photo.Variants {
+			if variant.Size != domain.PhotoSizeThumbnail {
+				continue
+			}
+			switch variant.Format {
+			case domain.PhotoFormatJPEG:
+				jpegURL = variant.URL
+			case domain.PhotoFormatWebP:
+				webpURL = variant.URL
+			}
+		}
+	}
+	return jpegURL, webpURL
+}
+
+// toPhotoDTOs convierte la galería del dominio a DTOs (nunca nil, para serializar [] y no null)
+func toPhotoDTOs(photos []domain.Photo) []dto.PhotoDTO {
+	result := make([]dto.PhotoDTO, len(photos))
+	for i, photo := range photos {
+		result[i] = dto.PhotoDTO{
+			URL:      photo.URL,
+			Caption:  photo.Caption,
+			Position: photo.Position,
+			IsCover:  photo.IsCover,
+			Status:   photo.Status,
+		}
+		for _, variant := range photo.Variants {
+			result[i].Variants = append(result[i].Variants, dto.PhotoVariantDTO{
+				Size:   variant.Size,
+				Format: variant.Format,
+				URL:    variant.URL,
+				Width:  variant.Width,
+				Height: variant.Height,
+			})
+		}
+	}
+	return result
+}
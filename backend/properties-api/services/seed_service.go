@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+
+	"properties-api/dto"
+	"properties-api/repositories"
+	"properties-api/utils"
+)
+
+// seedRandomSeed fija el generador para que el seed cree siempre los mismos datos
+const seedRandomSeed = 2134
+
+// seedCity es una ciudad donde se crean propiedades de demo
+type seedCity struct {
+	name          string
+	country       string // Nombre del país en la ubicación ("Ciudad, País", como la parsea search-api)
+	code          string // ISO 3166-1 alfa-2
+	region        string
+	timezone      string
+	latitude      float64
+	longitude     float64
+	basePrice     float64 // Precio por noche de referencia en la moneda local de la demo
+	neighborhoods []string
+}
+
+// seedCities son las ciudades de las propiedades de demo
+var seedCities = []seedCity{
+	{"Córdoba", "Argentina", "AR", "X", "America/Argentina/Cordoba", -31.4201, -64.1888, 45000, []string{"Nueva Córdoba", "Güemes", "Cerro de las Rosas", "General Paz"}},
+	{"Buenos Aires", "Argentina", "AR", "C", "America/Argentina/Buenos_Aires", -34.6037, -58.3816, 60000, []string{"Palermo", "San Telmo", "Recoleta", "Belgrano"}},
+	{"Mendoza", "Argentina", "AR", "M", "America/Argentina/Mendoza", -32.8895, -68.8458, 40000, []string{"Chacras de Coria", "Quinta Sección", "Luján de Cuyo"}},
+	{"Bariloche", "Argentina", "AR", "R", "America/Argentina/Salta", -41.1335, -71.3103, 70000, []string{"Playa Bonita", "Melipal", "Llao Llao"}},
+	{"Villa Carlos Paz", "Argentina", "AR", "X", "America/Argentina/Cordoba", -31.4241, -64.4978, 35000, []string{"Costanera", "Villa del Lago", "Playas de Oro"}},
+	{"Santiago", "Chile", "CL", "RM", "America/Santiago", -33.4489, -70.6693, 55000, []string{"Providencia", "Lastarria", "Bellavista"}},
+	{"Valparaíso", "Chile", "CL", "VS", "America/Santiago", -33.0472, -71.6127, 42000, []string{"Cerro Alegre", "Cerro Concepción"}},
+	{"Montevideo", "Uruguay", "UY", "MO", "America/Montevideo", -34.9011, -56.1645, 50000, []string{"Pocitos", "Ciudad Vieja", "Punta Carretas"}},
+	{"Punta del Este", "Uruguay", "UY", "MA", "America/Montevideo", -34.9667, -54.95, 90000, []string{"La Barra", "Península", "José Ignacio"}},
+	{"Bogotá", "Colombia", "CO", "DC", "America/Bogota", 4.711, -74.0721, 38000, []string{"Chapinero", "La Candelaria", "Usaquén"}},
+	{"Medellín", "Colombia", "CO", "ANT", "America/Bogota", 6.2442, -75.5812, 36000, []string{"El Poblado", "Laureles", "Envigado"}},
+	{"Ciudad de México", "México", "MX", "CMX", "America/Mexico_City", 19.4326, -99.1332, 48000, []string{"Roma Norte", "Condesa", "Coyoacán"}},
+}
+
+// seedPropertyTypes son los tipos de las propiedades de demo con su capacidad base
+var seedPropertyTypes = []struct {
+	name     string
+	label    string
+	capacity int
+}{
+	{"apartamento", "Apartamento", 3},
+	{"casa", "Casa", 6},
+	{"cabaña", "Cabaña", 4},
+	{"loft", "Loft", 2},
+}
+
+// seedAdjectives describen las propiedades de demo en el título
+var seedAdjectives = []string{"luminoso", "moderno", "acogedor", "amplio", "con vista", "renovado", "tranquilo", "con terraza"}
+
+// seedAmenities son las comodidades de las propiedades de demo
+var seedAmenities = []string{"wifi", "kitchen", "parking", "air-conditioning", "heating", "tv", "washer", "pool", "garden"}
+
+// seedCancellationPolicies son las políticas de cancelación de las propiedades de demo
+var seedCancellationPolicies = []string{"flexible", "moderate", "strict"}
+
+// SeedOptions indica cuántos datos de demo crear y con qué usuarios
+// OwnerIDs y GuestIDs tienen que existir en users-api (ver el seed de users-api)
+type SeedOptions struct {
+	Properties int
+	Bookings   int
+	OwnerIDs   []string
+	GuestIDs   []string
+}
+
+// SeedReport resume lo que hizo el seed
+type SeedReport struct {
+	Properties       int // Propiedades creadas
+	Existing         int // Propiedades de los owners de demo que ya existían
+	FailedProperties int
+	Bookings         int
+	FailedBookings   int // Reservas rechazadas (fechas superpuestas, capacidad, etc.)
+}
+
+// SeedService carga propiedades y reservas de demo para desarrollo local y ambientes de demo
+// Usa los mismos servicios que la API, así que cada propiedad publica "property.created" y search-api la indexa
+type SeedService interface {
+	// Seed completa las propiedades de demo hasta options.Properties y reserva options.Bookings
+	// sobre las propiedades creadas en esta corrida; correrlo de nuevo no duplica propiedades
+	Seed(ctx context.Context, options SeedOptions) (SeedReport, error)
+}
+
+// seedService es la implementación concreta de SeedService
+type seedService struct {
+	properties   PropertyService
+	bookings     BookingService
+	propertyRepo repositories.PropertyRepository
+}
+
+// NewSeedService crea el servicio de datos de demo
+func NewSeedService(properties PropertyService, bookings BookingService, propertyRepo repositories.PropertyRepository) SeedService {
+	return &seedService{properties: properties, bookings: bookings, propertyRepo: propertyRepo}
+}
+
+// Seed crea las propiedades de demo que falten y reservas sobre ellas
+func (s *seedService) Seed(ctx context.Context, options SeedOptions) (SeedReport, error) {
+	if len(options.OwnerIDs) == 0 {
+		return SeedReport{}, fmt.Errorf("el seed necesita al menos un owner")
+	}
+
+	var report SeedReport
+	for _, ownerID := range options.OwnerIDs {
+		existing, err := s.propertyRepo.GetByOwnerID(ctx, ownerID)
+		if err != nil {
+			return report, fmt.Errorf("error obteniendo las propiedades del owner %s: %w", ownerID, err)
+		}
+		report.Existing += len(existing)
+	}
+
+	random := rand.New(rand.NewSource(seedRandomSeed))
+	var created []dto.PropertyResponseDTO
+	// Se sigue la numeración de las existentes para no repetir títulos
+	for i := report.Existing; i < options.Properties; i++ {
+		property, err := s.properties.CreateProperty(ctx, seedProperty(i, options.OwnerIDs, random))
+		if err != nil {
+			log.Printf("⚠️ Error creando la propiedad de demo %d: %v", i+1, err)
+			report.FailedProperties++
+			continue
+		}
+		created = append(created, property)
+		report.Properties++
+	}
+
+	if len(created) == 0 || len(options.GuestIDs) == 0 {
+		return report, nil
+	}
+	today := utils.NowUTC()
+	for i := 0; i < options.Bookings; i++ {
+		property := created[random.Intn(len(created))]
+		guests := 1 + random.Intn(property.Capacity)
+		checkIn := today.AddDate(0, 0, 3+random.Intn(150))
+		checkOut := checkIn.AddDate(0, 0, 2+random.Intn(6))
+
+		_, err := s.bookings.CreateBooking(ctx, options.GuestIDs[random.Intn(len(options.GuestIDs))], dto.BookingCreateDTO{
+			PropertyID: property.ID,
+			CheckIn:    checkIn.Format("2006-01-02"),
+			CheckOut:   checkOut.Format("2006-01-02"),
+			Guests:     guests,
+		})
+		if err != nil {
+			report.FailedBookings++
+			continue
+		}
+		report.Bookings++
+	}
+	return report, nil
+}
+
+// seedProperty arma la propiedad de demo número i
+// Las ciudades se rotan primero y los owners después, así cada owner tiene como mucho un par de
+// propiedades por ciudad y los títulos no se marcan como duplicados
+func seedProperty(i int, ownerIDs []string, random *rand.Rand) dto.PropertyCreateDTO {
+	city := seedCities[i%len(seedCities)]
+	ownerID := ownerIDs[(i/len(seedCities))%len(ownerIDs)]
+	propertyType := seedPropertyTypes[(i/len(seedCities)+i)%len(seedPropertyTypes)]
+	neighborhood := city.neighborhoods[(i/len(seedCities))%len(city.neighborhoods)]
+	adjective := seedAdjectives[random.Intn(len(seedAdjectives))]
+
+	amenities := make([]string, 0, 5)
+	for _, amenity := range seedAmenities {
+		if random.Intn(2) == 0 {
+			amenities = append(amenities, amenity)
+		}
+	}
+	if len(amenities) == 0 {
+		amenities = append(amenities, "wifi")
+	}
+
+	capacity := propertyType.capacity + random.Intn(3)
+	// Precio redondeado a centenas con una variación de ±30% sobre el de referencia de la ciudad
+	price := float64(int(city.basePrice*(0.7+random.Float64()*0.6)/100) * 100)
+
+	property := dto.PropertyCreateDTO{
+		Title: fmt.Sprintf("%s %s en %s", propertyType.label, adjective, neighborhood),
+		Description: fmt.Sprintf("%s %s para %d huéspedes en %s, %s. Ideal para conocer la ciudad: cerca de restaurantes, "+
+			"transporte y los principales paseos. Propiedad de demo #%d.", propertyType.label, adjective, capacity, neighborhood, city.name, i+1),
+		Price:        price,
+		CleaningFee:  float64(random.Intn(4)) * 2500,
+		Location:     city.name + ", " + city.country,
+		PropertyType: propertyType.name,
+		// Coordenadas dispersas unos kilómetros alrededor del centro de la ciudad
+		Latitude:           city.latitude + (random.Float64()-0.5)*0.08,
+		Longitude:          city.longitude + (random.Float64()-0.5)*0.08,
+		Timezone:           city.timezone,
+		Country:            city.code,
+		Region:             city.region,
+		OwnerID:            ownerID,
+		Amenities:          amenities,
+		Capacity:           capacity,
+		Available:          random.Intn(10) > 0,
+		CancellationPolicy: seedCancellationPolicies[random.Intn(len(seedCancellationPolicies))],
+		ChildFriendly:      propertyType.name != "loft",
+		InfantFriendly:     propertyType.name == "casa",
+		HouseRules: &dto.HouseRulesDTO{
+			PetsAllowed:     random.Intn(3) == 0,
+			EventsAllowed:   propertyType.name == "casa" && random.Intn(2) == 0,
+			QuietHoursStart: "23:00",
+			QuietHoursEnd:   "08:00",
+		},
+	}
+	if random.Intn(4) == 0 {
+		property.BookingMode = "request"
+	}
+	if capacity > 2 {
+		property.GuestsIncluded = capacity - 1
+		property.ExtraGuestFee = float64(int(price*0.1/100) * 100)
+	}
+	return property
+}
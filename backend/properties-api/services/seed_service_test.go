@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"properties-api/domain"
+	"properties-api/dto"
+)
+
+// seedPropertyService registra las propiedades creadas y rechaza las que tengan índice en failing
+type seedPropertyService struct {
+	PropertyService
+	failing map[int]bool
+	created []dto.PropertyCreateDTO
+}
+
+func (s *seedPropertyService) CreateProperty(ctx context.Context, createDTO dto.PropertyCreateDTO) (dto.PropertyResponseDTO, error) {
+	index := len(s.created)
+	s.created = append(s.created, createDTO)
+	if s.failing[index] {
+		return dto.PropertyResponseDTO{}, errors.New("título duplicado")
+	}
+	return dto.PropertyResponseDTO{ID: fmt.Sprintf("p%d", index), Capacity: createDTO.Capacity}, nil
+}
+
+// seedBookingService registra las reservas pedidas y rechaza todas si err no es nil
+type seedBookingService struct {
+	BookingService
+	err      error
+	guests   []string
+	requests []dto.BookingCreateDTO
+}
+
+func (s *seedBookingService) CreateBooking(ctx context.Context, userID string, request dto.BookingCreateDTO) (dto.BookingDTO, error) {
+	s.guests = append(s.guests, userID)
+	s.requests = append(s.requests, request)
+	return dto.BookingDTO{}, s.err
+}
+
+// seedOwnersRepository responde las propiedades que ya tiene cada owner
+func seedOwnersRepository(existing map[string]int) *mockRepository {
+	return &mockRepository{GetByOwnerIDFunc: func(ownerID string) ([]domain.Property, error) {
+		return make([]domain.Property, existing[ownerID]), nil
+	}}
+}
+
+var seedTestOptions = SeedOptions{Properties: 30, Bookings: 10, OwnerIDs: []string{"1001", "1002"}, GuestIDs: []string{"1011", "1012"}}
+
+func TestSeed_CreatesPropertiesAndBookings(t *testing.T) {
+	properties, bookings := &seedPropertyService{}, &seedBookingService{}
+	report, err := NewSeedService(properties, bookings, seedOwnersRepository(nil)).Seed(context.Background(), seedTestOptions)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Properties != 30 || report.Bookings != 10 || report.Existing != 0 {
+		t.Fatalf("expected 30 properties and 10 bookings, got %+v", report)
+	}
+
+	// Sin títulos repetidos y repartidas entre los owners de demo
+	titles := make(map[string]bool)
+	owners := make(map[string]int)
+	for _, property := range properties.created {
+		if titles[property.OwnerID+property.Title] {
+			t.Fatalf("expected unique titles per owner, %q is repeated", property.Title)
+		}
+		titles[property.OwnerID+property.Title] = true
+		owners[property.OwnerID]++
+		if !strings.Contains(property.Location, ", ") || property.Country == "" || property.Capacity <= 0 {
+			t.Fatalf("expected a complete location and capacity, got %+v", property)
+		}
+	}
+	if owners["1001"] == 0 || owners["1002"] == 0 {
+		t.Fatalf("expected properties for every owner, got %v", owners)
+	}
+
+	today := time.Now().UTC().Format("2006-01-02")
+	for i, request := range bookings.requests {
+		if request.CheckIn <= today || request.CheckOut <= request.CheckIn || request.Guests <= 0 {
+			t.Fatalf("expected future stays with guests, got %+v", request)
+		}
+		if bookings.guests[i] != "1011" && bookings.guests[i] != "1012" {
+			t.Fatalf("expected a demo guest, got %s", bookings.guests[i])
+		}
+	}
+}
+
+func TestSeed_IsDeterministic(t *testing.T) {
+	first, second := &seedPropertyService{}, &seedPropertyService{}
+	NewSeedService(first, &seedBookingService{}, seedOwnersRepository(nil)).Seed(context.Background(), seedTestOptions)
+	NewSeedService(second, &seedBookingService{}, seedOwnersRepository(nil)).Seed(context.Background(), seedTestOptions)
+
+	if !reflect.DeepEqual(first.created, second.created) {
+		t.Fatal("expected the same demo properties on every run")
+	}
+}
+
+func TestSeed_CompletesUpToTheTotal(t *testing.T) {
+	// Una corrida anterior ya creó 25: solo faltan 5 y sus títulos siguen la numeración
+	properties := &seedPropertyService{}
+	report, err := NewSeedService(properties, &seedBookingService{}, seedOwnersRepository(map[string]int{"1001": 13, "1002": 12})).
+		Seed(context.Background(), seedTestOptions)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Existing != 25 || report.Properties != 5 {
+		t.Fatalf("expected 25 existing and 5 created, got %+v", report)
+	}
+	if !strings.Contains(properties.created[0].Description, "#26.") {
+		t.Fatalf("expected the numbering to continue at 26, got %q", properties.created[0].Description)
+	}
+}
+
+func TestSeed_CountsFailures(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	properties := &seedPropertyService{failing: map[int]bool{0: true, 7: true}}
+	bookings := &seedBookingService{err: errors.New("fechas superpuestas")}
+	report, err := NewSeedService(properties, bookings, seedOwnersRepository(nil)).Seed(context.Background(), seedTestOptions)
+	if err != nil {
+		t.Fatalf("expected the failures counted, got %v", err)
+	}
+	if report.FailedProperties != 2 || report.Properties != 28 || report.FailedBookings != 10 || report.Bookings != 0 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	// Las reservas solo se hacen sobre propiedades creadas
+	for _, request := range bookings.requests {
+		if request.PropertyID == "p0" || request.PropertyID == "p7" {
+			t.Fatalf("expected no bookings on failed properties, got %s", request.PropertyID)
+		}
+	}
+}
+
+func TestSeed_InvalidOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options SeedOptions
+		repo    *mockRepository
+		wantErr string
+	}{
+		{name: "no owners", options: SeedOptions{Properties: 5}, repo: seedOwnersRepository(nil), wantErr: "owner"},
+		{
+			name:    "mongo down",
+			options: seedTestOptions,
+			repo:    &mockRepository{GetByOwnerIDFunc: func(string) ([]domain.Property, error) { return nil, errors.New("timeout") }},
+			wantErr: "1001",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			properties := &seedPropertyService{}
+			_, err := NewSeedService(properties, &seedBookingService{}, tt.repo).Seed(context.Background(), tt.options)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected an error mentioning %q, got %v", tt.wantErr, err)
+			}
+			if len(properties.created) != 0 {
+				t.Fatalf("expected nothing created, got %d", len(properties.created))
+			}
+		})
+	}
+}
+
+func TestSeed_WithoutGuestsSkipsBookings(t *testing.T) {
+	options := seedTestOptions
+	options.GuestIDs = nil
+	bookings := &seedBookingService{}
+	report, err := NewSeedService(&seedPropertyService{}, bookings, seedOwnersRepository(nil)).Seed(context.Background(), options)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Properties != 30 || len(bookings.requests) != 0 {
+		t.Fatalf("expected properties without bookings, got %+v and %d requests", report, len(bookings.requests))
+	}
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"properties-api/domain"
+	"properties-api/dto"
+	"properties-api/repositories"
+	"properties-api/utils"
+)
+
+// ErrStatsForbidden indica que el usuario no es owner de la propiedad ni admin
+var ErrStatsForbidden = errors.New("solo el owner o un administrador pueden ver las estadísticas de la propiedad")
+
+// PropertyStatsService calcula las estadísticas de una propiedad
+type PropertyStatsService interface {
+	// GetPropertyStats calcula las estadísticas de la propiedad para los últimos days días
+	GetPropertyStats(ctx context.Context, propertyID string, days int, userID string, isAdmin bool) (dto.PropertyStatsResponse, error)
+}
+
+// propertyStatsService es la implementación concreta de PropertyStatsService
+type propertyStatsService struct {
+	propertyRepo repositories.PropertyRepository
+	bookingRepo  repositories.BookingRepository
+}
+
+// NewPropertyStatsService crea una nueva instancia del servicio de estadísticas
+func NewPropertyStatsService(propertyRepo repositories.PropertyRepository, bookingRepo repositories.BookingRepository) PropertyStatsService {
+	return &propertyStatsService{
+		propertyRepo: propertyRepo,
+		bookingRepo:  bookingRepo,
+	}
+}
+
+// GetPropertyStats calcula las estadísticas de la propiedad para los últimos days días
+// La propiedad y la agregación de reservas se obtienen en paralelo, igual que
+// CalculatePriceWithConcurrency reparte el cálculo del precio entre goroutines
+func (s *propertyStatsService) GetPropertyStats(ctx context.Context, propertyID string, days int, userID string, isAdmin bool) (dto.PropertyStatsResponse, error) {
+	to := utils.NowUTC()
+	from := to.AddDate(0, 0, -days)
+
+	var wg sync.WaitGroup
+	errorsChan := make(chan error, 2) // Buffer de 2 para evitar bloqueos
+
+	var property domain.Property
+	var stats domain.BookingStats
+
+	// Goroutine 1: obtiene la propiedad (owner y contador de vistas)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		var err error
+		property, err = s.propertyRepo.GetByID(ctx, propertyID)
+		if err != nil {
+			errorsChan <- fmt.Errorf("error obteniendo propiedad: %w", err)
+		}
+	}()
+
+	// Goroutine 2: agrega las reservas de la propiedad en MongoDB
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		var err error
+		stats, err = s.bookingRepo.AggregateStats(ctx, propertyID, from, to)
+		if err != nil {
+			errorsChan <- fmt.Errorf("error calculando estadísticas de reservas: %w", err)
+		}
+	}()
+
+	wg.Wait()
+	close(errorsChan)
+
+	// Si alguna de las goroutines falló se retorna el primer error recibido
+	for err := range errorsChan {
+		return dto.PropertyStatsResponse{}, err
+	}
+
+	if property.OwnerID != userID && !isAdmin {
+		return dto.PropertyStatsResponse{}, ErrStatsForbidden
+	}
+
+	return toPropertyStatsResponse(propertyID, property.Views, stats, from, to), nil
+}
+
+// toPropertyStatsResponse arma la respuesta con las métricas derivadas (ocupación y valor promedio)
+func toPropertyStatsResponse(propertyID string, views int64, stats domain.BookingStats, from, to time.Time) dto.PropertyStatsResponse {
+	response := dto.PropertyStatsResponse{
+		PropertyID:        propertyID,
+		Views:             views,
+		BookingsCount:     stats.TotalBookings,
+		CancelledBookings: stats.CancelledBookings,
+		Revenue:           roundTo(stats.TotalRevenue, 2),
+		PeriodBookings:    stats.WindowBookings,
+		PeriodRevenue:     roundTo(stats.WindowRevenue, 2),
+		BookedNights:      roundTo(stats.BookedNights, 2),
+		From:              from,
+		To:                to,
+		CalculatedAt:      to,
+	}
+
+	if confirmed := stats.TotalBookings - stats.CancelledBookings; confirmed > 0 {
+		response.AverageBookingValue = roundTo(stats.TotalRevenue/float64(confirmed), 2)
+	}
+
+	// La ocupación es la fracción de noches de la ventana que estuvieron reservadas
+	if windowNights := to.Sub(from).Hours() / 24; windowNights > 0 {
+		response.OccupancyRate = roundTo(math.Min(stats.BookedNights/windowNights, 1), 4)
+	}
+
+	return response
+}
+
+// roundTo redondea un valor a la cantidad de decimales indicada
+func roundTo(value float64, decimals int) float64 {
+	factor := math.Pow(10, float64(decimals))
+	return math.Round(value*factor) / factor
+}
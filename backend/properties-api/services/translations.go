@@ -0,0 +1,123 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"properties-api/domain"
+	"properties-api/dto"
+	"properties-api/utils"
+)
+
+// ErrInvalidTranslations indica un idioma o traducciones inválidos (locale mal formado o texto vacío)
+var ErrInvalidTranslations = errors.New("traducciones inválidas")
+
+// maxPropertyTranslations es la cantidad máxima de traducciones por propiedad
+const maxPropertyTranslations = 10
+
+// languageFromDTO valida el idioma del título y la descripción; vacío es el idioma por defecto
+func languageFromDTO(language string) (string, error) {
+	if strings.TrimSpace(language) == "" {
+		return domain.DefaultPropertyLanguage, nil
+	}
+	normalized, ok := utils.NormalizeLocale(language)
+	if !ok {
+		return "", fmt.Errorf("%w: language '%s' debe tener el formato 'es' o 'es-AR'", ErrInvalidTranslations, language)
+	}
+	return normalized, nil
+}
+
+// translationsFromDTO valida las traducciones y normaliza sus locales
+// Cada traducción necesita título y descripción, y no puede repetir el idioma principal
+func translationsFromDTO(language string, translations map[string]dto.PropertyTranslationDTO) (map[string]domain.PropertyTranslation, error) {
+	if len(translations) == 0 {
+		return nil, nil
+	}
+	if len(translations) > maxPropertyTranslations {
+		return nil, fmt.Errorf("%w: se permiten hasta %d traducciones", ErrInvalidTranslations, maxPropertyTranslations)
+	}
+
+	result := make(map[string]domain.PropertyTranslation, len(translations))
+	for locale, translation := range translations {
+		normalized, ok := utils.NormalizeLocale(locale)
+		if !ok {
+			return nil, fmt.Errorf("%w: el locale '%s' debe tener el formato 'es' o 'es-AR'", ErrInvalidTranslations, locale)
+		}
+		if normalized == language {
+			return nil, fmt.Errorf("%w: '%s' es el idioma principal de la propiedad", ErrInvalidTranslations, normalized)
+		}
+		if _, repeated := result[normalized]; repeated {
+			return nil, fmt.Errorf("%w: el locale '%s' está repetido", ErrInvalidTranslations, normalized)
+		}
+		title := strings.TrimSpace(translation.Title)
+		description := strings.TrimSpace(translation.Description)
+		if title == "" || description == "" {
+			return nil, fmt.Errorf("%w: la traducción '%s' necesita título y descripción", ErrInvalidTranslations, normalized)
+		}
+		result[normalized] = domain.PropertyTranslation{Title: title, Description: description}
+	}
+	return result, nil
+}
+
+// toTranslationDTOs convierte las traducciones del dominio (nil si no hay)
+func toTranslationDTOs(translations map[string]domain.PropertyTranslation) map[string]dto.PropertyTranslationDTO {
+	if len(translations) == 0 {
+		return nil
+	}
+	result := make(map[string]dto.PropertyTranslationDTO, len(translations))
+	for locale, translation := range translations {
+		result[locale] = dto.PropertyTranslationDTO{Title: translation.Title, Description: translation.Description}
+	}
+	return result
+}
+
+// propertyLanguageOrDefault retorna el idioma de la propiedad; vacío (propiedades anteriores) es español
+func propertyLanguageOrDefault(language string) string {
+	if language == "" {
+		return domain.DefaultPropertyLanguage
+	}
+	return language
+}
+
+// LocalizeProperty deja en el título y la descripción el idioma que mejor coincide con los locales
+// preferidos (en orden, ver utils.ParseAcceptLanguage) y en Language el idioma elegido
+// Para cada locale se prueba el locale exacto y después solo el idioma ("es-AR" acepta "es" y "es-MX");
+// si ninguno coincide queda el idioma principal
+func LocalizeProperty(property dto.PropertyResponseDTO, preferred []string) dto.PropertyResponseDTO {
+	locale, ok := bestLocale(property.Language, property.Translations, preferred)
+	if !ok || locale == property.Language {
+		return property
+	}
+	translation := property.Translations[locale]
+	property.Title = translation.Title
+	property.Description = translation.Description
+	property.Language = locale
+	return property
+}
+
+// bestLocale elige entre el idioma principal y las traducciones el primer locale preferido disponible
+func bestLocale(language string, translations map[string]dto.PropertyTranslationDTO, preferred []string) (string, bool) {
+	available := make([]string, 0, len(translations)+1)
+	available = append(available, language)
+	for locale := range translations {
+		available = append(available, locale)
+	}
+	// El idioma principal primero y el resto ordenado, así el resultado no depende del orden del map
+	sort.Strings(available[1:])
+
+	for _, wanted := range preferred {
+		for _, locale := range available {
+			if locale == wanted {
+				return locale, true
+			}
+		}
+		for _, locale := range available {
+			if utils.LocaleLanguage(locale) == utils.LocaleLanguage(wanted) {
+				return locale, true
+			}
+		}
+	}
+	return "", false
+}
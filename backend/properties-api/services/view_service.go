@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"properties-api/clients"
+	"properties-api/repositories"
+	"properties-api/utils"
+)
+
+// ViewService cuenta las vistas de las propiedades y las vuelca periódicamente a MongoDB
+type ViewService interface {
+	// RecordView registra una vista del detalle de la propiedad
+	RecordView(ctx context.Context, propertyID string)
+
+	// Flush vuelca a MongoDB las vistas pendientes y publica la popularidad actualizada
+	// Retorna la cantidad de propiedades actualizadas
+	Flush(ctx context.Context) (int, error)
+}
+
+// viewService es la implementación concreta de ViewService
+// Las vistas se acumulan en Memcached; en memoria solo se guardan los IDs con vistas pendientes
+type viewService struct {
+	counter      repositories.ViewCounterRepository
+	propertyRepo repositories.PropertyRepository
+	rabbitClient clients.RabbitMQClient
+
+	mu      sync.Mutex
+	pending map[string]bool
+}
+
+// NewViewService crea una nueva instancia del servicio de vistas
+func NewViewService(
+	counter repositories.ViewCounterRepository,
+	propertyRepo repositories.PropertyRepository,
+	rabbitClient clients.RabbitMQClient,
+) ViewService {
+	return &viewService{
+		counter:      counter,
+		propertyRepo: propertyRepo,
+		rabbitClient: rabbitClient,
+		pending:      make(map[string]bool),
+	}
+}
+
+// RecordView registra una vista del detalle de la propiedad
+// Si Memcached no está disponible la vista se escribe directo en MongoDB
+func (s *viewService) RecordView(ctx context.Context, propertyID string) {
+	if err := s.counter.Increment(propertyID, 1); err != nil {
+		log.Printf("⚠️ Error contando vista en Memcached para propiedad %s, se escribe en MongoDB: %v", propertyID, err)
+		if _, err := s.propertyRepo.IncrementViews(ctx, propertyID, 1); err != nil {
+			log.Printf("❌ Error contando vista de propiedad %s: %v", propertyID, err)
+		}
+		return
+	}
+
+	s.mu.Lock()
+	s.pending[propertyID] = true
+	s.mu.Unlock()
+}
+
+// Flush vuelca a MongoDB las vistas pendientes y publica la popularidad actualizada
+// Las propiedades que fallan vuelven a quedar pendientes para el próximo volcado
+func (s *viewService) Flush(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	propertyIDs := make([]string, 0, len(s.pending))
+	for propertyID := range s.pending {
+		propertyIDs = append(propertyIDs, propertyID)
+	}
+	s.pending = make(map[string]bool)
+	s.mu.Unlock()
+
+	totals := make(map[string]int64, len(propertyIDs))
+	var firstErr error
+	for _, propertyID := range propertyIDs {
+		if err := ctx.Err(); err != nil {
+			s.markPending(propertyID)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		views, err := s.counter.Take(propertyID)
+		if err != nil {
+			s.markPending(propertyID)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if views == 0 {
+			continue
+		}
+
+		total, err := s.propertyRepo.IncrementViews(ctx, propertyID, views)
+		if err != nil {
+			// Las vistas ya se descontaron de Memcached; se devuelven al contador para no perderlas
+			log.Printf("⚠️ Error volcando %d vistas de propiedad %s: %v", views, propertyID, err)
+			if err := s.counter.Increment(propertyID, uint64(views)); err != nil {
+				log.Printf("❌ Se perdieron %d vistas de propiedad %s: %v", views, propertyID, err)
+			}
+			s.markPending(propertyID)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		totals[propertyID] = total
+	}
+
+	if len(totals) > 0 {
+		event := clients.PropertyPopularityEvent{
+			Views:    totals,
+			SyncedAt: utils.FormatTimestamp(utils.NowUTC()),
+		}
+		if err := s.rabbitClient.PublishPopularityEvent(event); err != nil {
+			log.Printf("⚠️ Error publicando popularidad de %d propiedades: %v", len(totals), err)
+		}
+	}
+
+	return len(totals), firstErr
+}
+
+// markPending vuelve a marcar una propiedad con vistas pendientes
+func (s *viewService) markPending(propertyID string) {
+	s.mu.Lock()
+	s.pending[propertyID] = true
+	s.mu.Unlock()
+}
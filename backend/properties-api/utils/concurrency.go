@@ -6,7 +6,7 @@ import (
 
 // CalculatePriceWithConcurrency calcula el precio total de una propiedad usando goroutines
 // Divide el cálculo en 3 partes que se ejecutan en paralelo para mejorar el rendimiento:
-// 1. Precio base con impuestos (21%)
+// 1. Precio base (sin impuestos: se calculan al cotizar según el país de la propiedad, ver TaxTable)
 // 2. Costo adicional por amenidades ($50 cada una)
 // 3. Costo adicional por capacidad ($30 por persona)
 // Finalmente suma todos los resultados para obtener el precio total
@@ -19,18 +19,15 @@ func CalculatePriceWithConcurrency(basePrice float64, amenities []string, capaci
 	// Permite comunicación segura entre goroutines
 	resultsChan := make(chan float64, 3) // Buffer de 3 para evitar bloqueos
 
-	// Goroutine 1: Calcula el precio base con impuestos del 21%
-	// Aplica el impuesto al precio base y envía el resultado al channel
+	// Goroutine 1: Envía el precio base
+	// Los impuestos no se suman acá: dependen del país de la propiedad y se muestran
+	// como una línea separada en la cotización de la reserva
 	wg.Add(1) // Incrementar el contador del WaitGroup
 	go func() {
 		defer wg.Done() // Decrementar el contador cuando termine la goroutine
 
-		// Calcular precio base con impuestos (21% = 0.21)
-		// Precio con impuestos = precio base * 1.21
-		priceWithTaxes := basePrice * 1.21
-
 		// Enviar resultado al channel
-		resultsChan <- priceWithTaxes
+		resultsChan <- basePrice
 	}()
 
 	// Goroutine 2: Calcula el costo adicional por amenidades
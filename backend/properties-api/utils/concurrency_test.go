@@ -0,0 +1,53 @@
+package utils
+
+import "testing"
+
+// ============================================
+// BENCHMARKS - cálculo de precio
+// ============================================
+// Comparan CalculatePriceWithConcurrency con el mismo cálculo sin goroutines para ver
+// cuánto cuesta la coordinación (3 goroutines, WaitGroup y channel) en cada alta o edición.
+// Correr con: go test ./utils -run '^$' -bench Price -benchmem
+
+// benchmarkAmenities son las amenidades de una propiedad típica
+var benchmarkAmenities = []string{"wifi", "kitchen", "parking", "air-conditioning", "heating", "tv"}
+
+// benchmarkPriceSink evita que el compilador descarte el cálculo del benchmark
+var benchmarkPriceSink float64
+
+// sequentialPrice es el mismo cálculo que CalculatePriceWithConcurrency en una sola goroutine
+func sequentialPrice(basePrice float64, amenities []string, capacity int) float64 {
+	return basePrice + float64(len(amenities))*50 + float64(capacity)*30
+}
+
+func TestCalculatePriceWithConcurrency_MatchesSequential(t *testing.T) {
+	got := CalculatePriceWithConcurrency(45000, benchmarkAmenities, 4)
+	want := sequentialPrice(45000, benchmarkAmenities, 4)
+	if got != want {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func BenchmarkCalculatePriceWithConcurrency(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchmarkPriceSink = CalculatePriceWithConcurrency(45000, benchmarkAmenities, 4)
+	}
+}
+
+// BenchmarkCalculatePriceWithConcurrencyParallel simula altas de propiedades concurrentes
+func BenchmarkCalculatePriceWithConcurrencyParallel(b *testing.B) {
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = CalculatePriceWithConcurrency(45000, benchmarkAmenities, 4)
+		}
+	})
+}
+
+func BenchmarkCalculatePriceSequential(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchmarkPriceSink = sequentialPrice(45000, benchmarkAmenities, 1+i%8)
+	}
+}
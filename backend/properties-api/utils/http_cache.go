@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// gzipMinBytes es el tamaño a partir del cual se comprime la respuesta
+// Por debajo el header de gzip y el costo de CPU no compensan lo que se ahorra
+const gzipMinBytes = 1024
+
+// WriteCacheableJSON escribe payload como JSON con un ETag calculado sobre el body:
+//   - si el If-None-Match del request coincide responde 304 sin body
+//   - si el cliente acepta gzip y el body supera gzipMinBytes lo comprime
+//
+// El ETag es débil (W/"...") porque la misma respuesta puede ir comprimida o no
+func WriteCacheableJSON(ctx *gin.Context, statusCode int, payload interface{}) {
+	writeCacheableJSON(ctx, statusCode, payload, time.Time{})
+}
+
+// WriteCacheableJSONWithLastModified es WriteCacheableJSON con el header Last-Modified:
+// un request con If-Modified-Since (y sin If-None-Match, que tiene prioridad) recibe 304
+// si el recurso no cambió desde esa fecha. Last-Modified tiene precisión de segundos
+func WriteCacheableJSONWithLastModified(ctx *gin.Context, statusCode int, payload interface{}, lastModified time.Time) {
+	writeCacheableJSON(ctx, statusCode, payload, lastModified)
+}
+
+// writeCacheableJSON escribe la respuesta; lastModified cero = sin Last-Modified
+func writeCacheableJSON(ctx *gin.Context, statusCode int, payload interface{}, lastModified time.Time) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error serializando la respuesta"})
+		return
+	}
+
+	etag := WeakETag(body)
+	ctx.Header("ETag", etag)
+	// Add y no Set: CORS ya agrega Vary: Origin
+	ctx.Writer.Header().Add("Vary", "Accept-Encoding")
+	if !lastModified.IsZero() {
+		ctx.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	if ETagMatches(ctx.GetHeader("If-None-Match"), etag) || notModifiedSince(ctx, lastModified) {
+		ctx.Status(http.StatusNotModified)
+		ctx.Writer.WriteHeaderNow()
+		return
+	}
+
+	if len(body) >= gzipMinBytes && AcceptsGzip(ctx.GetHeader("Accept-Encoding")) {
+		var compressed bytes.Buffer
+		writer := gzip.NewWriter(&compressed)
+		writer.Write(body)
+		writer.Close()
+		body = compressed.Bytes()
+		ctx.Header("Content-Encoding", "gzip")
+	}
+
+	ctx.Header("Content-Length", strconv.Itoa(len(body)))
+	ctx.Data(statusCode, "application/json; charset=utf-8", body)
+}
+
+// notModifiedSince indica si el recurso no cambió desde el If-Modified-Since del request
+// Según RFC 9110 13.1.3 se ignora si viene If-None-Match o si la fecha no se puede parsear
+func notModifiedSince(ctx *gin.Context, lastModified time.Time) bool {
+	ifModifiedSince := ctx.GetHeader("If-Modified-Since")
+	if lastModified.IsZero() || ifModifiedSince == "" || ctx.GetHeader("If-None-Match") != "" {
+		return false
+	}
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// WeakETag calcula el ETag débil de un body (primeros 128 bits del SHA-256)
+func WeakETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// ETagMatches compara el header If-None-Match con etag (comparación débil, ver RFC 9110 13.1.2)
+func ETagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	opaque := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == opaque {
+			return true
+		}
+	}
+	return false
+}
+
+// AcceptsGzip indica si el header Accept-Encoding acepta gzip (sin q=0)
+func AcceptsGzip(acceptEncoding string) bool {
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		fields := strings.Split(part, ";")
+		coding := strings.ToLower(strings.TrimSpace(fields[0]))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+		rejected := false
+		for _, param := range fields[1:] {
+			param = strings.ReplaceAll(strings.TrimSpace(param), " ", "")
+			if q, found := strings.CutPrefix(param, "q="); found {
+				value, err := strconv.ParseFloat(q, 64)
+				rejected = err != nil || value == 0
+			}
+		}
+		return !rejected
+	}
+	return false
+}
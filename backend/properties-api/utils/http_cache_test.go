@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// serveCacheable responde payload con WriteCacheableJSON y los headers del request
+func serveCacheable(payload interface{}, headers map[string]string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/properties/1", nil)
+	for name, value := range headers {
+		ctx.Request.Header.Set(name, value)
+	}
+	WriteCacheableJSON(ctx, http.StatusOK, payload)
+	return recorder
+}
+
+func TestWriteCacheableJSON_ReturnsNotModifiedForMatchingETag(t *testing.T) {
+	payload := map[string]string{"id": "1", "title": "Departamento"}
+
+	first := serveCacheable(payload, nil)
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || !strings.HasPrefix(etag, `W/"`) {
+		t.Fatalf("expected 200 with a weak ETag, got %d and %q", first.Code, etag)
+	}
+
+	second := serveCacheable(payload, map[string]string{"If-None-Match": strings.TrimPrefix(etag, "W/")})
+	if second.Code != http.StatusNotModified || second.Body.Len() != 0 {
+		t.Fatalf("expected empty 304, got %d with %d bytes", second.Code, second.Body.Len())
+	}
+
+	changed := serveCacheable(map[string]string{"id": "1", "title": "Casa"}, map[string]string{"If-None-Match": etag})
+	if changed.Code != http.StatusOK {
+		t.Fatalf("expected 200 after the payload changed, got %d", changed.Code)
+	}
+}
+
+func TestWriteCacheableJSON_CompressesLargeBodies(t *testing.T) {
+	payload := map[string]string{"description": strings.Repeat("Departamento luminoso en Nueva Córdoba. ", 100)}
+
+	plain := serveCacheable(payload, nil)
+	if plain.Header().Get("Content-Encoding") != "" {
+		t.Fatal("expected no compression without Accept-Encoding")
+	}
+
+	compressed := serveCacheable(payload, map[string]string{"Accept-Encoding": "br, gzip"})
+	if compressed.Header().Get("Content-Encoding") != "gzip" || compressed.Body.Len() >= plain.Body.Len() {
+		t.Fatalf("expected a smaller gzip body, got %q with %d bytes", compressed.Header().Get("Content-Encoding"), compressed.Body.Len())
+	}
+	reader, err := gzip.NewReader(compressed.Body)
+	if err != nil {
+		t.Fatalf("invalid gzip body: %v", err)
+	}
+	body, _ := io.ReadAll(reader)
+	if string(body) != plain.Body.String() {
+		t.Fatal("expected the decompressed body to match the plain one")
+	}
+
+	if rejected := serveCacheable(payload, map[string]string{"Accept-Encoding": "gzip;q=0"}); rejected.Header().Get("Content-Encoding") != "" {
+		t.Fatal("expected no compression with gzip;q=0")
+	}
+}
+
+func TestWriteCacheableJSONWithLastModified_HonorsIfModifiedSince(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	updatedAt := time.Date(2024, 3, 10, 15, 4, 5, 600_000_000, time.UTC)
+	serve := func(headers map[string]string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(recorder)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/properties/1", nil)
+		for name, value := range headers {
+			ctx.Request.Header.Set(name, value)
+		}
+		WriteCacheableJSONWithLastModified(ctx, http.StatusOK, map[string]string{"id": "1"}, updatedAt)
+		return recorder
+	}
+
+	first := serve(nil)
+	lastModified := first.Header().Get("Last-Modified")
+	if first.Code != http.StatusOK || lastModified != "Sun, 10 Mar 2024 15:04:05 GMT" {
+		t.Fatalf("expected 200 with Last-Modified, got %d and %q", first.Code, lastModified)
+	}
+
+	if unchanged := serve(map[string]string{"If-Modified-Since": lastModified}); unchanged.Code != http.StatusNotModified {
+		t.Fatalf("expected 304 for the same Last-Modified despite sub-second precision, got %d", unchanged.Code)
+	}
+	if older := serve(map[string]string{"If-Modified-Since": "Sun, 10 Mar 2024 15:04:04 GMT"}); older.Code != http.StatusOK {
+		t.Fatalf("expected 200 when modified after If-Modified-Since, got %d", older.Code)
+	}
+	withETag := serve(map[string]string{"If-Modified-Since": lastModified, "If-None-Match": `W/"other"`})
+	if withETag.Code != http.StatusOK {
+		t.Fatalf("expected If-None-Match to take precedence over If-Modified-Since, got %d", withETag.Code)
+	}
+}
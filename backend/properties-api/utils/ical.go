@@ -0,0 +1,241 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Soporte mínimo de iCalendar (RFC 5545) para exportar e importar disponibilidad.
+// Solo se manejan eventos (VEVENT) con fechas; no hay soporte de recurrencias (RRULE).
+
+// icalMaxLineOctets es el largo máximo de una línea antes de plegarla (RFC 5545, sección 3.1)
+const icalMaxLineOctets = 75
+
+// icalDateLayout y icalDateTimeLayout son los formatos de DATE y DATE-TIME de iCalendar
+const (
+	icalDateLayout     = "20060102"
+	icalDateTimeLayout = "20060102T150405"
+)
+
+// ICalEvent es un evento de un calendario iCal
+// En los eventos de día completo Start y End son fechas a medianoche UTC y End es exclusivo
+type ICalEvent struct {
+	UID     string
+	Summary string
+	Start   time.Time
+	End     time.Time
+	AllDay  bool
+}
+
+// WriteICalendar escribe un VCALENDAR con los eventos en formato iCal
+func WriteICalendar(w io.Writer, prodID, name string, events []ICalEvent) error {
+	stamp := time.Now().UTC().Format(icalDateTimeLayout) + "Z"
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:" + escapeICalText(prodID),
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:" + escapeICalText(name),
+	}
+	for _, event := range events {
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+escapeICalText(event.UID),
+			"DTSTAMP:"+stamp,
+		)
+		if event.AllDay {
+			lines = append(lines,
+				"DTSTART;VALUE=DATE:"+event.Start.Format(icalDateLayout),
+				"DTEND;VALUE=DATE:"+event.End.Format(icalDateLayout),
+			)
+		} else {
+			lines = append(lines,
+				"DTSTART:"+event.Start.UTC().Format(icalDateTimeLayout)+"Z",
+				"DTEND:"+event.End.UTC().Format(icalDateTimeLayout)+"Z",
+			)
+		}
+		lines = append(lines,
+			"SUMMARY:"+escapeICalText(event.Summary),
+			"TRANSP:OPAQUE",
+			"END:VEVENT",
+		)
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := io.WriteString(w, foldICalLine(line)+"\r\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseICalendar lee los eventos de un calendario iCal
+// Los eventos sin DTSTART se ignoran; sin DTEND un evento de día completo dura un día
+func ParseICalendar(r io.Reader) ([]ICalEvent, error) {
+	lines, err := unfoldICalLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []ICalEvent
+	var current *ICalEvent
+	hasStart, hasEnd := false, false
+
+	for _, line := range lines {
+		name, params, value, ok := splitICalLine(line)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			current = &ICalEvent{}
+			hasStart, hasEnd = false, false
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			if current != nil && hasStart {
+				if !hasEnd || !current.End.After(current.Start) {
+					if current.AllDay {
+						current.End = current.Start.AddDate(0, 0, 1)
+					} else {
+						current.End = current.Start
+					}
+				}
+				events = append(events, *current)
+			}
+			current = nil
+		case current == nil:
+			continue
+		case name == "UID":
+			current.UID = value
+		case name == "SUMMARY":
+			current.Summary = unescapeICalText(value)
+		case name == "DTSTART":
+			start, allDay, err := parseICalTime(value, params)
+			if err != nil {
+				return nil, fmt.Errorf("DTSTART inválido en evento '%s': %w", current.UID, err)
+			}
+			current.Start, current.AllDay, hasStart = start, allDay, true
+		case name == "DTEND":
+			end, _, err := parseICalTime(value, params)
+			if err != nil {
+				return nil, fmt.Errorf("DTEND inválido en evento '%s': %w", current.UID, err)
+			}
+			current.End, hasEnd = end, true
+		}
+	}
+	return events, nil
+}
+
+// unfoldICalLines lee las líneas del calendario uniendo las líneas plegadas
+// (las que empiezan con espacio o tab continúan la línea anterior)
+func unfoldICalLines(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error leyendo calendario iCal: %w", err)
+	}
+	return lines, nil
+}
+
+// splitICalLine separa una línea "NOMBRE;PARAM=VALOR:valor" en nombre, parámetros y valor
+func splitICalLine(line string) (string, map[string]string, string, bool) {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return "", nil, "", false
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	params := make(map[string]string, len(parts)-1)
+	for _, param := range parts[1:] {
+		if key, value, ok := strings.Cut(param, "="); ok {
+			params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+		}
+	}
+	return strings.ToUpper(parts[0]), params, line[colon+1:], true
+}
+
+// parseICalTime parsea un DATE o DATE-TIME de iCal
+// Los DATE-TIME pueden venir en UTC (sufijo Z), con TZID o flotantes (se toman como UTC)
+func parseICalTime(value string, params map[string]string) (time.Time, bool, error) {
+	if params["VALUE"] == "DATE" || len(value) == len(icalDateLayout) {
+		date, err := time.Parse(icalDateLayout, value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("fecha inválida '%s'", value)
+		}
+		return date, true, nil
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(icalDateTimeLayout, strings.TrimSuffix(value, "Z"))
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("fecha y hora inválida '%s'", value)
+		}
+		return t, false, nil
+	}
+
+	location := time.UTC
+	if tzid := params["TZID"]; tzid != "" {
+		loaded, err := LoadPropertyLocation(tzid)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		location = loaded
+	}
+	t, err := time.ParseInLocation(icalDateTimeLayout, value, location)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("fecha y hora inválida '%s'", value)
+	}
+	return t.UTC(), false, nil
+}
+
+// foldICalLine pliega una línea en tramos de hasta 75 octetos sin cortar caracteres UTF-8
+func foldICalLine(line string) string {
+	if len(line) <= icalMaxLineOctets {
+		return line
+	}
+
+	var builder strings.Builder
+	limit := icalMaxLineOctets
+	octets := 0
+	for _, r := range line {
+		size := len(string(r))
+		if octets+size > limit {
+			builder.WriteString("\r\n ")
+			octets = 0
+			limit = icalMaxLineOctets - 1 // el espacio inicial cuenta en la línea plegada
+		}
+		builder.WriteRune(r)
+		octets += size
+	}
+	return builder.String()
+}
+
+// escapeICalText escapa un valor TEXT de iCal
+func escapeICalText(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return replacer.Replace(value)
+}
+
+// unescapeICalText revierte escapeICalText
+func unescapeICalText(value string) string {
+	replacer := strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+	return replacer.Replace(value)
+}
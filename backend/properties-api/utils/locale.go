@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// localePattern es el formato de los locales de las traducciones: idioma ISO 639-1 y región opcional ("es", "pt-BR")
+var localePattern = regexp.MustCompile(`^[a-z]{2}(-[A-Z]{2})?$`)
+
+// NormalizeLocale normaliza un locale a "es" o "es-AR" (acepta "ES_ar" o "es-ar")
+// Retorna false si no tiene ese formato
+func NormalizeLocale(locale string) (string, bool) {
+	locale = strings.ReplaceAll(strings.TrimSpace(locale), "_", "-")
+	parts := strings.SplitN(locale, "-", 2)
+	normalized := strings.ToLower(parts[0])
+	if len(parts) == 2 {
+		normalized += "-" + strings.ToUpper(parts[1])
+	}
+	return normalized, localePattern.MatchString(normalized)
+}
+
+// LocaleLanguage retorna el idioma de un locale ("pt-BR" → "pt")
+func LocaleLanguage(locale string) string {
+	language, _, _ := strings.Cut(locale, "-")
+	return language
+}
+
+// ParseAcceptLanguage retorna los locales del header Accept-Language ordenados por preferencia (q)
+// Se omiten el comodín "*", los rechazados (q=0) y los que no tienen el formato de NormalizeLocale
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+	var candidates []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		locale, ok := NormalizeLocale(tag)
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if value, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		candidates = append(candidates, weighted{locale: locale, q: q})
+	}
+
+	// A igual q se respeta el orden del header
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	locales := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		locales[i] = candidate.locale
+	}
+	return locales
+}
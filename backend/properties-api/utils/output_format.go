@@ -0,0 +1,213 @@
+package utils
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OutputFormat representa el formato de salida negociado para listados grandes
+type OutputFormat string
+
+const (
+	FormatJSON   OutputFormat = "json"
+	FormatNDJSON OutputFormat = "ndjson"
+	FormatCSV    OutputFormat = "csv"
+	// FormatExcel es CSV con BOM UTF-8 para que Excel muestre bien los acentos
+	FormatExcel OutputFormat = "excel"
+)
+
+// utf8BOM es la marca que Excel usa para detectar que un CSV está en UTF-8
+const utf8BOM = "\ufeff"
+
+// flushEvery indica cada cuántas filas se hace flush de la respuesta
+const flushEvery = 100
+
+// csvFormulaPrefixes son los caracteres con los que Excel y LibreOffice interpretan una celda como fórmula
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// csvNumber es un número simple: puede empezar con + o - (ej: latitudes negativas) sin ser una fórmula
+var csvNumber = regexp.MustCompile(`^[+-]?[0-9]+(\.[0-9]+)?$`)
+
+// CSVRecord es implementado por los DTOs que pueden exportarse como CSV
+type CSVRecord interface {
+	CSVHeader() []string
+	CSVRow() []string
+}
+
+// NegotiateFormat determina el formato de salida a partir de ?format= o del header Accept
+// Si no se reconoce ninguno se usa JSON
+func NegotiateFormat(c *gin.Context) OutputFormat {
+	switch strings.ToLower(c.Query("format")) {
+	case "csv":
+		return FormatCSV
+	case "excel":
+		return FormatExcel
+	case "ndjson":
+		return FormatNDJSON
+	case "json":
+		return FormatJSON
+	}
+
+	accept := c.GetHeader("Accept")
+	switch {
+	case strings.Contains(accept, "application/x-ndjson"):
+		return FormatNDJSON
+	case strings.Contains(accept, "text/csv"):
+		return FormatCSV
+	default:
+		return FormatJSON
+	}
+}
+
+// StreamEncoder escribe registros uno a uno en el formato negociado
+// La respuesta empieza a enviarse con el primer registro, sin cargar todo en memoria
+type StreamEncoder struct {
+	c             *gin.Context
+	format        OutputFormat
+	header        []string
+	headerWritten bool
+	csvWriter     *csv.Writer
+	encoder       *json.Encoder
+	count         int
+}
+
+// NewStreamEncoder prepara los headers de la respuesta para el formato indicado
+// header es la fila de encabezado del CSV (ej: dto.BookingDTO{}.CSVHeader()), que se escribe aunque no haya registros
+func NewStreamEncoder(c *gin.Context, format OutputFormat, filename string, header []string) *StreamEncoder {
+	switch format {
+	case FormatCSV, FormatExcel:
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", "attachment; filename=\""+filename+".csv\"")
+	case FormatNDJSON:
+		c.Header("Content-Type", "application/x-ndjson")
+	default:
+		c.Header("Content-Type", "application/json; charset=utf-8")
+	}
+	c.Status(http.StatusOK)
+
+	return &StreamEncoder{
+		c:         c,
+		format:    format,
+		header:    header,
+		csvWriter: csv.NewWriter(c.Writer),
+		encoder:   json.NewEncoder(c.Writer),
+	}
+}
+
+// Encode escribe un registro en la respuesta
+func (e *StreamEncoder) Encode(record CSVRecord) error {
+	var err error
+
+	switch e.format {
+	case FormatCSV, FormatExcel:
+		if err = e.writeCSVHeader(); err != nil {
+			return err
+		}
+		err = e.csvWriter.Write(csvSafeRow(record.CSVRow()))
+	case FormatNDJSON:
+		err = e.encoder.Encode(record)
+	default:
+		// JSON se emite como un array que se va abriendo a medida que llegan registros
+		separator := ","
+		if e.count == 0 {
+			separator = "["
+		}
+		if _, err = e.c.Writer.WriteString(separator); err != nil {
+			return err
+		}
+		err = e.encoder.Encode(record)
+	}
+	if err != nil {
+		return err
+	}
+
+	e.count++
+	if e.count%flushEvery == 0 {
+		e.flush()
+	}
+	return nil
+}
+
+// Close termina la respuesta (cierra el array JSON o escribe el encabezado de un CSV vacío y hace flush final)
+func (e *StreamEncoder) Close() error {
+	if e.format == FormatCSV || e.format == FormatExcel {
+		if err := e.writeCSVHeader(); err != nil {
+			return err
+		}
+	}
+	if e.format == FormatJSON {
+		closing := "]"
+		if e.count == 0 {
+			closing = "[]"
+		}
+		if _, err := e.c.Writer.WriteString(closing); err != nil {
+			return err
+		}
+	}
+
+	e.flush()
+	return e.csvWriter.Error()
+}
+
+// Fail reporta un error ocurrido durante el streaming
+// Si todavía no se escribió nada se responde un error JSON; si no, solo se loguea
+// porque el status y parte del cuerpo ya fueron enviados
+func (e *StreamEncoder) Fail(err error) {
+	e.FailWithStatus(http.StatusInternalServerError, err)
+}
+
+// FailWithStatus es como Fail pero con el status indicado si todavía no se escribió nada
+// (ej: 400 para un filtro inválido detectado antes de leer el primer registro)
+func (e *StreamEncoder) FailWithStatus(status int, err error) {
+	if e.count == 0 && !e.headerWritten {
+		e.c.Header("Content-Disposition", "")
+		e.c.Header("Content-Type", "application/json; charset=utf-8")
+		e.c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	log.Printf("❌ Error durante streaming de respuesta (%d registros enviados): %v", e.count, err)
+	e.flush()
+}
+
+// writeCSVHeader escribe el BOM (Excel) y el encabezado la primera vez que se llama
+func (e *StreamEncoder) writeCSVHeader() error {
+	if e.headerWritten {
+		return nil
+	}
+	e.headerWritten = true
+
+	if e.format == FormatExcel {
+		if _, err := e.c.Writer.WriteString(utf8BOM); err != nil {
+			return err
+		}
+	}
+	return e.csvWriter.Write(csvSafeRow(e.header))
+}
+
+// csvSafeRow evita la inyección de fórmulas al abrir el CSV en una planilla:
+// las celdas que empiezan con =, +, -, @, tab o CR (salvo los números) se prefijan con '
+func csvSafeRow(row []string) []string {
+	safe := make([]string, len(row))
+	for i, cell := range row {
+		if cell != "" && strings.ContainsRune(csvFormulaPrefixes, rune(cell[0])) && !csvNumber.MatchString(cell) {
+			cell = "'" + cell
+		}
+		safe[i] = cell
+	}
+	return safe
+}
+
+// flush envía al cliente lo escrito hasta el momento
+func (e *StreamEncoder) flush() {
+	if e.format == FormatCSV || e.format == FormatExcel {
+		e.csvWriter.Flush()
+	}
+	e.c.Writer.Flush()
+}
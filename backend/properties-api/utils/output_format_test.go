@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportRow es un registro de prueba con el encabezado de un export
+type exportRow []string
+
+func (r exportRow) CSVHeader() []string { return []string{"id", "title", "latitude"} }
+func (r exportRow) CSVRow() []string    { return r }
+
+// streamRows escribe rows con un StreamEncoder en el formato indicado y retorna el cuerpo de la respuesta
+func streamRows(t *testing.T, format OutputFormat, rows ...exportRow) string {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/export/properties", nil)
+
+	encoder := NewStreamEncoder(ctx, format, "properties", exportRow{}.CSVHeader())
+	for _, row := range rows {
+		if err := encoder.Encode(row); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return recorder.Body.String()
+}
+
+func TestStreamEncoder_EmptyExportKeepsTheHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		format OutputFormat
+		want   string
+	}{
+		{name: "csv", format: FormatCSV, want: "id,title,latitude\n"},
+		{name: "excel", format: FormatExcel, want: utf8BOM + "id,title,latitude\n"},
+		{name: "json", format: FormatJSON, want: "[]"},
+		{name: "ndjson", format: FormatNDJSON, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := streamRows(t, tt.format); got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestStreamEncoder_EscapesFormulaCells(t *testing.T) {
+	rows := []exportRow{
+		{"p1", "=HYPERLINK(\"http://evil\")", "-31.42"},
+		{"p2", "+5+cmd|' /C calc'!A0", "-64.18"},
+		{"p3", "-2+3", "12"},
+		{"p4", "@SUM(A1)", "0"},
+		{"p5", "\tTab", "+1.5"},
+		{"p6", "\rRetorno", ""},
+		{"p7", "Depto céntrico", "-34"},
+	}
+	want := "id,title,latitude\n" +
+		"p1,\"'=HYPERLINK(\"\"http://evil\"\")\",-31.42\n" +
+		"p2,'+5+cmd|' /C calc'!A0,-64.18\n" +
+		"p3,'-2+3,12\n" +
+		"p4,'@SUM(A1),0\n" +
+		"p5,'\tTab,+1.5\n" +
+		"p6,\"'\rRetorno\",\n" +
+		"p7,Depto céntrico,-34\n"
+
+	for _, format := range []OutputFormat{FormatCSV, FormatExcel} {
+		t.Run(string(format), func(t *testing.T) {
+			expected := want
+			if format == FormatExcel {
+				expected = utf8BOM + want
+			}
+			if got := streamRows(t, format, rows...); got != expected {
+				t.Fatalf("expected %q, got %q", expected, got)
+			}
+		})
+	}
+}
+
+func TestStreamEncoder_JSONIsNotEscaped(t *testing.T) {
+	got := streamRows(t, FormatNDJSON, exportRow{"p1", "=1+1", "-31.42"})
+	if want := "[\"p1\",\"=1+1\",\"-31.42\"]\n"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
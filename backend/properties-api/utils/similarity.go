@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// accentReplacer quita los acentos más comunes del español antes de comparar textos
+var accentReplacer = strings.NewReplacer(
+	"á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "ñ", "n",
+)
+
+// NormalizeText pasa el texto a minúsculas sin acentos ni signos de puntuación
+// Ej: "Cabaña en  la Playa!" -> "cabana en la playa"
+func NormalizeText(value string) string {
+	value = accentReplacer.Replace(strings.ToLower(value))
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// PropertySignature calcula la firma de similitud de una propiedad (título + ubicación + owner)
+// Las palabras del título se ordenan: "Casa en la playa" y "Playa, casa en la" tienen la misma firma
+func PropertySignature(title, location, ownerID string) string {
+	words := uniqueWords(title)
+	sort.Strings(words)
+
+	hash := sha1.Sum([]byte(strings.Join(words, " ") + "|" + NormalizeText(location) + "|" + strings.TrimSpace(ownerID)))
+	return hex.EncodeToString(hash[:])
+}
+
+// TitleSimilarity retorna la similitud de Jaccard entre las palabras de dos títulos (0 a 1)
+func TitleSimilarity(a, b string) float64 {
+	wordsA := uniqueWords(a)
+	wordsB := uniqueWords(b)
+	if len(wordsA) == 0 || len(wordsB) == 0 {
+		return 0
+	}
+
+	inA := make(map[string]bool, len(wordsA))
+	for _, word := range wordsA {
+		inA[word] = true
+	}
+	shared := 0
+	for _, word := range wordsB {
+		if inA[word] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(wordsA)+len(wordsB)-shared)
+}
+
+// uniqueWords retorna las palabras normalizadas de un texto sin repetir
+func uniqueWords(value string) []string {
+	seen := make(map[string]bool)
+	var words []string
+	for _, word := range strings.Fields(NormalizeText(value)) {
+		if !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	return words
+}
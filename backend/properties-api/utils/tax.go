@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultTaxJurisdiction identifica la tasa por defecto cuando el país de la propiedad no está configurado
+const DefaultTaxJurisdiction = "default"
+
+// TaxTable resuelve la tasa de impuestos de una propiedad según su país y región
+// Las claves son códigos ISO 3166: "AR" (país) o "AR-C" (país-región); la región tiene prioridad
+type TaxTable struct {
+	defaultRate float64
+	rates       map[string]float64
+}
+
+// NewTaxTable crea la tabla de impuestos con la tasa por defecto y las tasas por jurisdicción
+// Las claves se normalizan a mayúsculas
+func NewTaxTable(defaultRate float64, rates map[string]float64) TaxTable {
+	normalized := make(map[string]float64, len(rates))
+	for jurisdiction, rate := range rates {
+		normalized[strings.ToUpper(strings.TrimSpace(jurisdiction))] = rate
+	}
+	return TaxTable{defaultRate: defaultRate, rates: normalized}
+}
+
+// Lookup retorna la tasa que corresponde al país y la región, y la jurisdicción de la que salió
+// Busca primero "PAÍS-REGIÓN", después "PAÍS" y por último la tasa por defecto
+func (t TaxTable) Lookup(country, region string) (float64, string) {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	region = strings.ToUpper(strings.TrimSpace(region))
+
+	if country != "" && region != "" {
+		jurisdiction := country + "-" + region
+		if rate, ok := t.rates[jurisdiction]; ok {
+			return rate, jurisdiction
+		}
+	}
+	if country != "" {
+		if rate, ok := t.rates[country]; ok {
+			return rate, country
+		}
+	}
+	return t.defaultRate, DefaultTaxJurisdiction
+}
+
+// NormalizeCountryCode valida un código de país ISO 3166-1 alfa-2 y lo retorna en mayúsculas
+// Vacío significa que la propiedad no tiene país (se usa la tasa por defecto)
+func NormalizeCountryCode(country string) (string, error) {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if country == "" {
+		return "", nil
+	}
+	if len(country) != 2 || !isUpperLetters(country) {
+		return "", fmt.Errorf("código de país inválido '%s', se espera ISO 3166-1 alfa-2 (ej: AR, ES)", country)
+	}
+	return country, nil
+}
+
+// NormalizeRegionCode valida el código de región (subdivisión ISO 3166-2 sin el país, ej: "C" o "NY")
+func NormalizeRegionCode(region string) (string, error) {
+	region = strings.ToUpper(strings.TrimSpace(region))
+	if region == "" {
+		return "", nil
+	}
+	if len(region) > 3 {
+		return "", fmt.Errorf("código de región inválido '%s', se espera la subdivisión ISO 3166-2 sin el país (ej: C, NY)", region)
+	}
+	for _, r := range region {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return "", fmt.Errorf("código de región inválido '%s', se espera la subdivisión ISO 3166-2 sin el país (ej: C, NY)", region)
+		}
+	}
+	return region, nil
+}
+
+// isUpperLetters indica si value tiene solo letras A-Z
+func isUpperLetters(value string) bool {
+	for _, r := range value {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
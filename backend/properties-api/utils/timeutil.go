@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	// Base de zonas horarias embebida: la imagen alpine no trae tzdata
+	_ "time/tzdata"
+)
+
+// Todos los timestamps se guardan y se serializan en UTC con formato RFC3339.
+// Las horas locales de una propiedad (check-in/check-out) se convierten
+// explícitamente con su zona horaria IANA (ej: "America/Argentina/Cordoba").
+
+// dateLayout es el formato de fecha sin hora aceptado en los parámetros
+const dateLayout = "2006-01-02"
+
+// NowUTC retorna la hora actual en UTC truncada a milisegundos
+// MongoDB guarda milisegundos, así el valor en memoria coincide con el persistido
+func NowUTC() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
+
+// FormatTimestamp formatea un timestamp en UTC con formato RFC3339
+// Retorna vacío para el valor cero
+func FormatTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// ParseTimestamp parsea un timestamp RFC3339 (con o sin fracciones) o una fecha "2006-01-02"
+// El resultado siempre está en UTC; las fechas sin hora se toman como medianoche UTC
+func ParseTimestamp(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{time.RFC3339Nano, time.RFC3339, dateLayout} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("timestamp inválido '%s', se espera RFC3339 o YYYY-MM-DD", value)
+}
+
+// LoadPropertyLocation obtiene la zona horaria de una propiedad
+// Una zona vacía se interpreta como UTC
+func LoadPropertyLocation(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return time.UTC, nil
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("zona horaria inválida '%s': %w", timezone, err)
+	}
+	return location, nil
+}
+
+// ToPropertyLocal convierte un timestamp UTC a la hora local de la propiedad
+func ToPropertyLocal(t time.Time, timezone string) (time.Time, error) {
+	location, err := LoadPropertyLocation(timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.In(location), nil
+}
+
+// PropertyLocalToUTC convierte una fecha y hora local de la propiedad a UTC
+// Ej: check-in el "2025-01-10" a las 15hs en "Europe/Madrid" -> 2025-01-10T14:00:00Z
+func PropertyLocalToUTC(date string, hour int, timezone string) (time.Time, error) {
+	location, err := LoadPropertyLocation(timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	day, err := time.ParseInLocation(dateLayout, strings.TrimSpace(date), location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("fecha inválida '%s', se espera YYYY-MM-DD", date)
+	}
+	local := time.Date(day.Year(), day.Month(), day.Day(), hour, 0, 0, 0, location)
+	return local.UTC(), nil
+}
+
+// FormatPropertyLocal formatea un timestamp en la hora local de la propiedad (RFC3339 con offset)
+// Si la zona es inválida se formatea en UTC
+func FormatPropertyLocal(t time.Time, timezone string) string {
+	if t.IsZero() {
+		return ""
+	}
+	local, err := ToPropertyLocal(t, timezone)
+	if err != nil {
+		return FormatTimestamp(t)
+	}
+	return local.Format(time.RFC3339)
+}
@@ -7,7 +7,7 @@ import (
 
 // ValidatePropertyType valida que el tipo de propiedad sea válido
 func ValidatePropertyType(propertyType string) error {
-	validTypes := []string{"casa", "apartamento", "terreno", "local", "oficina"}
+	validTypes := []string{"casa", "apartamento", "cabaña", "loft", "terreno", "local", "oficina"}
 	propertyType = strings.ToLower(strings.TrimSpace(propertyType))
 
 	for _, validType := range validTypes {
@@ -0,0 +1,251 @@
+package reporting
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errorReportQueueSize es la cantidad de eventos que pueden esperar envío; si se llena se descartan
+const errorReportQueueSize = 100
+
+// Config contiene el servicio de reporte de errores
+type Config struct {
+	// DSN del proyecto (formato de Sentry: https://<key>@<host>/<project>); vacío = no se reportan
+	DSN string
+
+	// Environment y Release se adjuntan a cada evento para filtrarlos
+	Environment string
+	Release     string
+}
+
+// Niveles de los eventos reportados
+const (
+	ErrorLevelError = "error"
+	ErrorLevelFatal = "fatal"
+)
+
+// ErrorEvent es un error o panic para reportar al servicio de errores
+type ErrorEvent struct {
+	// Message es el resumen del error (ej: "panic: runtime error: index out of range")
+	Message string
+
+	// Level es ErrorLevelError o ErrorLevelFatal (panics)
+	Level string
+
+	// Stacktrace es el stack de la goroutine (solo en panics)
+	Stacktrace string
+
+	// Tags identifican el request: request_id, user_id, method, path y status
+	Tags map[string]string
+}
+
+// ErrorReporter envía errores y panics a un servicio compatible con Sentry (Sentry, GlitchTip, etc.)
+// Sin ERROR_REPORTING_DSN no envía nada y los errores solo quedan en los logs
+type ErrorReporter interface {
+	// Report encola el evento; el envío es en segundo plano para no demorar la respuesta
+	Report(event ErrorEvent)
+
+	// Close espera hasta timeout a que se envíen los eventos encolados
+	Close(timeout time.Duration)
+}
+
+// NewErrorReporter crea el reporter para service con el DSN de cfg
+// Un DSN vacío o inválido retorna un reporter que no envía nada
+func NewErrorReporter(cfg Config, service string, httpClient *http.Client) ErrorReporter {
+	if cfg.DSN == "" {
+		return noopErrorReporter{}
+	}
+	endpoint, auth, err := parseSentryDSN(cfg.DSN, service)
+	if err != nil {
+		log.Printf("⚠️ ERROR_REPORTING_DSN inválido, los errores no se reportan: %v", err)
+		return noopErrorReporter{}
+	}
+
+	hostname, _ := os.Hostname()
+	reporter := &sentryErrorReporter{
+		endpoint:    endpoint,
+		auth:        auth,
+		service:     service,
+		environment: cfg.Environment,
+		release:     cfg.Release,
+		serverName:  hostname,
+		httpClient:  httpClient,
+		events:      make(chan ErrorEvent, errorReportQueueSize),
+	}
+	reporter.wg.Add(1)
+	go reporter.run()
+	log.Printf("🛰️ Reporte de errores habilitado (%s)", endpoint)
+	return reporter
+}
+
+// parseSentryDSN arma la URL del endpoint de eventos y el header X-Sentry-Auth
+// Formato del DSN: https://<key>[:<secret>]@<host>[/<path>]/<project>
+func parseSentryDSN(dsn, service string) (string, string, error) {
+	parsed, err := url.Parse(dsn)
+	if err != nil {
+		return "", "", err
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return "", "", fmt.Errorf("el esquema debe ser http o https")
+	}
+	if parsed.User == nil || parsed.User.Username() == "" {
+		return "", "", fmt.Errorf("falta la key pública")
+	}
+	path := strings.TrimSuffix(parsed.Path, "/")
+	slash := strings.LastIndex(path, "/")
+	project := path[slash+1:]
+	if project == "" {
+		return "", "", fmt.Errorf("falta el ID del proyecto")
+	}
+
+	endpoint := fmt.Sprintf("%s://%s%s/api/%s/store/", parsed.Scheme, parsed.Host, path[:slash], project)
+	auth := fmt.Sprintf("Sentry sentry_version=7, sentry_client=spotly-%s/1.0, sentry_key=%s", service, parsed.User.Username())
+	if secret, ok := parsed.User.Password(); ok {
+		auth += ", sentry_secret=" + secret
+	}
+	return endpoint, auth, nil
+}
+
+// noopErrorReporter se usa sin DSN
+type noopErrorReporter struct{}
+
+func (noopErrorReporter) Report(event ErrorEvent)     {}
+func (noopErrorReporter) Close(timeout time.Duration) {}
+
+// sentryErrorReporter envía los eventos al endpoint store de Sentry
+type sentryErrorReporter struct {
+	endpoint    string
+	auth        string
+	service     string
+	environment string
+	release     string
+	serverName  string
+	httpClient  *http.Client
+
+	events    chan ErrorEvent
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// sentryEvent es el payload del endpoint store de Sentry
+type sentryEvent struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Level       string            `json:"level"`
+	Platform    string            `json:"platform"`
+	Logger      string            `json:"logger"`
+	ServerName  string            `json:"server_name,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	Message     string            `json:"message"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	User        *sentryUser       `json:"user,omitempty"`
+	Extra       map[string]string `json:"extra,omitempty"`
+}
+
+// sentryUser identifica al usuario afectado (Sentry agrupa los eventos por usuario)
+type sentryUser struct {
+	ID string `json:"id"`
+}
+
+// Report encola el evento sin bloquear; si la cola está llena se descarta
+func (r *sentryErrorReporter) Report(event ErrorEvent) {
+	defer func() {
+		// Report después de Close (durante el apagado) no tiene que tirar el proceso
+		recover()
+	}()
+	select {
+	case r.events <- event:
+	default:
+		log.Printf("⚠️ Cola de reporte de errores llena, se descarta: %s", event.Message)
+	}
+}
+
+// Close deja de aceptar eventos y espera hasta timeout a que se envíen los encolados
+func (r *sentryErrorReporter) Close(timeout time.Duration) {
+	r.closeOnce.Do(func() { close(r.events) })
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		log.Printf("⚠️ Quedaron errores sin reportar al apagar")
+	}
+}
+
+// run envía los eventos de a uno
+func (r *sentryErrorReporter) run() {
+	defer r.wg.Done()
+	for event := range r.events {
+		if err := r.send(event); err != nil {
+			log.Printf("⚠️ No se pudo reportar el error '%s': %v", event.Message, err)
+		}
+	}
+}
+
+// send hace el POST del evento
+func (r *sentryErrorReporter) send(event ErrorEvent) error {
+	level := event.Level
+	if level == "" {
+		level = ErrorLevelError
+	}
+	payload := sentryEvent{
+		EventID:     newEventID(),
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		Level:       level,
+		Platform:    "go",
+		Logger:      r.service,
+		ServerName:  r.serverName,
+		Environment: r.environment,
+		Release:     r.release,
+		Message:     event.Message,
+		Tags:        event.Tags,
+	}
+	if userID := event.Tags["user_id"]; userID != "" {
+		payload.User = &sentryUser{ID: userID}
+	}
+	if event.Stacktrace != "" {
+		payload.Extra = map[string]string{"stacktrace": event.Stacktrace}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequest(http.MethodPost, r.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Sentry-Auth", r.auth)
+
+	response, err := r.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("status %d", response.StatusCode)
+	}
+	return nil
+}
+
+// newEventID genera el ID del evento (32 caracteres hexadecimales, sin guiones)
+func newEventID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
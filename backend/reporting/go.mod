@@ -0,0 +1,3 @@
+module reporting
+
+go 1.21
@@ -0,0 +1,261 @@
+package resilience
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen se retorna cuando el circuit breaker rechaza una llamada sin ejecutarla
+var ErrCircuitOpen = errors.New("circuit breaker abierto: servicio remoto no disponible")
+
+// CircuitState es el estado de un circuit breaker
+type CircuitState int
+
+const (
+	// CircuitClosed deja pasar todas las llamadas
+	CircuitClosed CircuitState = iota
+	// CircuitHalfOpen deja pasar una cantidad limitada de llamadas de prueba
+	CircuitHalfOpen
+	// CircuitOpen rechaza todas las llamadas hasta que pase el OpenTimeout
+	CircuitOpen
+)
+
+// String retorna el nombre del estado
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitHalfOpen:
+		return "half-open"
+	case CircuitOpen:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerSettings es la configuración de un circuit breaker
+type CircuitBreakerSettings struct {
+	// FailureThreshold es la cantidad de fallas consecutivas que abre el circuito
+	FailureThreshold int
+	// OpenTimeout es el tiempo que el circuito queda abierto antes de probar de nuevo
+	OpenTimeout time.Duration
+	// HalfOpenMaxRequests es la cantidad de llamadas de prueba simultáneas en half-open
+	HalfOpenMaxRequests int
+}
+
+// DefaultCircuitBreakerSettings retorna la configuración por defecto para llamadas entre servicios
+func DefaultCircuitBreakerSettings() CircuitBreakerSettings {
+	return CircuitBreakerSettings{
+		FailureThreshold:    5,
+		OpenTimeout:         30 * time.Second,
+		HalfOpenMaxRequests: 1,
+	}
+}
+
+// CircuitBreakerStats son los contadores de un circuit breaker expuestos como métricas
+type CircuitBreakerStats struct {
+	Name         string
+	State        CircuitState
+	Successes    uint64
+	Failures     uint64
+	Rejected     uint64
+	StateChanges uint64
+}
+
+// CircuitBreaker corta las llamadas a un servicio remoto después de varias fallas consecutivas
+// para no acumular requests colgados mientras el servicio está caído
+type CircuitBreaker struct {
+	name     string
+	settings CircuitBreakerSettings
+
+	mu                  sync.Mutex
+	state               CircuitState
+	consecutiveFailures int
+	halfOpenInFlight    int
+	openedAt            time.Time
+	stats               CircuitBreakerStats
+}
+
+// breakerRegistry guarda los circuit breakers creados para exponer sus métricas
+var breakerRegistry = struct {
+	sync.Mutex
+	breakers map[string]*CircuitBreaker
+}{breakers: make(map[string]*CircuitBreaker)}
+
+// NewCircuitBreaker crea un circuit breaker cerrado y lo registra para las métricas
+func NewCircuitBreaker(name string, settings CircuitBreakerSettings) *CircuitBreaker {
+	if settings.FailureThreshold < 1 {
+		settings.FailureThreshold = 1
+	}
+	if settings.HalfOpenMaxRequests < 1 {
+		settings.HalfOpenMaxRequests = 1
+	}
+
+	breaker := &CircuitBreaker{
+		name:     name,
+		settings: settings,
+		state:    CircuitClosed,
+	}
+
+	breakerRegistry.Lock()
+	breakerRegistry.breakers[name] = breaker
+	breakerRegistry.Unlock()
+
+	return breaker
+}
+
+// Execute ejecuta fn si el circuito lo permite y registra el resultado
+// Los errores marcados con Permanent no cuentan como falla: el servicio remoto respondió
+func (b *CircuitBreaker) Execute(fn func() error) error {
+	if err := b.before(); err != nil {
+		return err
+	}
+
+	err := fn()
+	b.after(err == nil || IsPermanent(err))
+	return err
+}
+
+// State retorna el estado actual del circuito
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refreshState(time.Now())
+	return b.state
+}
+
+// Stats retorna una copia de los contadores del circuito
+func (b *CircuitBreaker) Stats() CircuitBreakerStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refreshState(time.Now())
+	stats := b.stats
+	stats.Name = b.name
+	stats.State = b.state
+	return stats
+}
+
+// before decide si la llamada puede ejecutarse
+func (b *CircuitBreaker) before() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refreshState(time.Now())
+
+	switch b.state {
+	case CircuitOpen:
+		b.stats.Rejected++
+		return fmt.Errorf("%s: %w", b.name, ErrCircuitOpen)
+	case CircuitHalfOpen:
+		if b.halfOpenInFlight >= b.settings.HalfOpenMaxRequests {
+			b.stats.Rejected++
+			return fmt.Errorf("%s: %w", b.name, ErrCircuitOpen)
+		}
+		b.halfOpenInFlight++
+	}
+	return nil
+}
+
+// after registra el resultado de una llamada y actualiza el estado
+func (b *CircuitBreaker) after(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitHalfOpen && b.halfOpenInFlight > 0 {
+		b.halfOpenInFlight--
+	}
+
+	if success {
+		b.stats.Successes++
+		b.consecutiveFailures = 0
+		if b.state == CircuitHalfOpen {
+			b.setState(CircuitClosed, time.Now())
+		}
+		return
+	}
+
+	b.stats.Failures++
+	b.consecutiveFailures++
+	if b.state == CircuitHalfOpen || b.consecutiveFailures >= b.settings.FailureThreshold {
+		b.setState(CircuitOpen, time.Now())
+	}
+}
+
+// refreshState pasa de open a half-open cuando vence el OpenTimeout (requiere el lock tomado)
+func (b *CircuitBreaker) refreshState(now time.Time) {
+	if b.state == CircuitOpen && now.Sub(b.openedAt) >= b.settings.OpenTimeout {
+		b.setState(CircuitHalfOpen, now)
+	}
+}
+
+// setState cambia el estado del circuito (requiere el lock tomado)
+func (b *CircuitBreaker) setState(state CircuitState, now time.Time) {
+	if b.state == state {
+		return
+	}
+
+	log.Printf("🔌 Circuit breaker %s: %s -> %s", b.name, b.state, state)
+	b.state = state
+	b.stats.StateChanges++
+	b.halfOpenInFlight = 0
+	if state == CircuitOpen {
+		b.openedAt = now
+	}
+	if state == CircuitClosed {
+		b.consecutiveFailures = 0
+	}
+}
+
+// WriteCircuitBreakerMetrics escribe las métricas de todos los circuit breakers
+// en formato de texto de Prometheus (estado: 0 closed, 1 half-open, 2 open)
+func WriteCircuitBreakerMetrics(w io.Writer) error {
+	breakerRegistry.Lock()
+	names := make([]string, 0, len(breakerRegistry.breakers))
+	for name := range breakerRegistry.breakers {
+		names = append(names, name)
+	}
+	breakers := make([]*CircuitBreaker, len(names))
+	sort.Strings(names)
+	for i, name := range names {
+		breakers[i] = breakerRegistry.breakers[name]
+	}
+	breakerRegistry.Unlock()
+
+	stats := make([]CircuitBreakerStats, len(breakers))
+	for i, breaker := range breakers {
+		stats[i] = breaker.Stats()
+	}
+
+	metrics := []struct {
+		name  string
+		kind  string
+		help  string
+		value func(CircuitBreakerStats) uint64
+	}{
+		{"circuit_breaker_state", "gauge", "Estado del circuit breaker (0 closed, 1 half-open, 2 open)", func(s CircuitBreakerStats) uint64 { return uint64(s.State) }},
+		{"circuit_breaker_successes_total", "counter", "Llamadas exitosas", func(s CircuitBreakerStats) uint64 { return s.Successes }},
+		{"circuit_breaker_failures_total", "counter", "Llamadas fallidas", func(s CircuitBreakerStats) uint64 { return s.Failures }},
+		{"circuit_breaker_rejected_total", "counter", "Llamadas rechazadas con el circuito abierto", func(s CircuitBreakerStats) uint64 { return s.Rejected }},
+		{"circuit_breaker_state_changes_total", "counter", "Cambios de estado del circuito", func(s CircuitBreakerStats) uint64 { return s.StateChanges }},
+	}
+
+	for _, metric := range metrics {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", metric.name, metric.help, metric.name, metric.kind); err != nil {
+			return err
+		}
+		for _, s := range stats {
+			if _, err := fmt.Fprintf(w, "%s{name=%q} %d\n", metric.name, s.Name, metric.value(s)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
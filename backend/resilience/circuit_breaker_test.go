@@ -0,0 +1,111 @@
+package resilience
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+var errRemote = errors.New("servicio remoto caído")
+
+func TestCircuitBreaker_OpensAfterConsecutiveFailures(t *testing.T) {
+	breaker := NewCircuitBreaker(t.Name(), CircuitBreakerSettings{FailureThreshold: 3, OpenTimeout: time.Hour})
+
+	// Un éxito en el medio reinicia la cuenta de fallas consecutivas
+	for _, err := range []error{errRemote, errRemote, nil, errRemote, errRemote} {
+		breaker.Execute(func() error { return err })
+	}
+	if state := breaker.State(); state != CircuitClosed {
+		t.Fatalf("expected the circuit closed after non-consecutive failures, got %s", state)
+	}
+
+	breaker.Execute(func() error { return errRemote })
+	if state := breaker.State(); state != CircuitOpen {
+		t.Fatalf("expected the circuit open after 3 consecutive failures, got %s", state)
+	}
+
+	// Con el circuito abierto la llamada se rechaza sin ejecutarse
+	called := false
+	err := breaker.Execute(func() error { called = true; return nil })
+	if !errors.Is(err, ErrCircuitOpen) || called {
+		t.Fatalf("expected ErrCircuitOpen without calling fn, got %v (called=%v)", err, called)
+	}
+	if stats := breaker.Stats(); stats.Rejected != 1 || stats.Failures != 5 || stats.Successes != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestCircuitBreaker_PermanentErrorsDoNotOpenTheCircuit(t *testing.T) {
+	breaker := NewCircuitBreaker(t.Name(), CircuitBreakerSettings{FailureThreshold: 1, OpenTimeout: time.Hour})
+
+	// Un 4xx es una respuesta del servicio remoto: no indica que esté caído
+	err := breaker.Execute(func() error { return Permanent(errRemote) })
+	if !errors.Is(err, errRemote) {
+		t.Fatalf("expected the original error, got %v", err)
+	}
+	if state := breaker.State(); state != CircuitClosed {
+		t.Fatalf("expected the circuit closed, got %s", state)
+	}
+}
+
+func TestCircuitBreaker_HalfOpenProbe(t *testing.T) {
+	tests := []struct {
+		name  string
+		probe error
+		want  CircuitState
+	}{
+		{name: "successful probe closes the circuit", probe: nil, want: CircuitClosed},
+		{name: "failed probe opens it again", probe: errRemote, want: CircuitOpen},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breaker := NewCircuitBreaker(t.Name(), CircuitBreakerSettings{FailureThreshold: 1, OpenTimeout: 20 * time.Millisecond})
+			breaker.Execute(func() error { return errRemote })
+
+			time.Sleep(30 * time.Millisecond)
+			if state := breaker.State(); state != CircuitHalfOpen {
+				t.Fatalf("expected half-open after the open timeout, got %s", state)
+			}
+
+			// Mientras la llamada de prueba está en curso, las demás se rechazan
+			release := make(chan struct{})
+			done := make(chan error)
+			go func() {
+				done <- breaker.Execute(func() error { <-release; return tt.probe })
+			}()
+			time.Sleep(10 * time.Millisecond)
+			if err := breaker.Execute(func() error { return nil }); !errors.Is(err, ErrCircuitOpen) {
+				t.Fatalf("expected a second half-open call to be rejected, got %v", err)
+			}
+			close(release)
+			<-done
+
+			if state := breaker.State(); state != tt.want {
+				t.Fatalf("expected %s after the probe, got %s", tt.want, state)
+			}
+		})
+	}
+}
+
+func TestWriteCircuitBreakerMetrics(t *testing.T) {
+	name := fmt.Sprintf("%s-%d", t.Name(), time.Now().UnixNano())
+	breaker := NewCircuitBreaker(name, CircuitBreakerSettings{FailureThreshold: 1, OpenTimeout: time.Hour})
+	breaker.Execute(func() error { return errRemote })
+
+	var out bytes.Buffer
+	if err := WriteCircuitBreakerMetrics(&out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, line := range []string{
+		"# TYPE circuit_breaker_state gauge",
+		fmt.Sprintf("circuit_breaker_state{name=%q} 2", name),
+		fmt.Sprintf("circuit_breaker_failures_total{name=%q} 1", name),
+	} {
+		if !strings.Contains(out.String(), line) {
+			t.Fatalf("expected %q in the metrics, got:\n%s", line, out.String())
+		}
+	}
+}
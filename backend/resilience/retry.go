@@ -0,0 +1,124 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand"
+	"time"
+)
+
+// permanentError marca un error que no debe reintentarse ni contar como falla del circuit breaker
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marca un error como definitivo (ej: un 4xx del servicio remoto)
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent indica si el error fue marcado con Permanent
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// RetryPolicy es la configuración de reintentos de una llamada remota
+type RetryPolicy struct {
+	// MaxAttempts es la cantidad máxima de intentos (incluye el primero)
+	MaxAttempts int
+	// BaseDelay es la espera antes del primer reintento; se duplica en cada intento
+	BaseDelay time.Duration
+	// MaxDelay es la espera máxima entre reintentos
+	MaxDelay time.Duration
+	// AttemptTimeout es el timeout de cada intento
+	AttemptTimeout time.Duration
+}
+
+// DefaultRetryPolicy retorna la política por defecto para llamadas entre servicios
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		BaseDelay:      100 * time.Millisecond,
+		MaxDelay:       2 * time.Second,
+		AttemptTimeout: 5 * time.Second,
+	}
+}
+
+// Retry ejecuta fn con reintentos y backoff exponencial con jitter
+// Cada intento recibe un contexto con el AttemptTimeout de la política
+// No reintenta los errores permanentes ni las llamadas rechazadas por el circuit breaker
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+
+	var err error
+	for attempt := 1; attempt <= policy.MaxAttempts; attempt++ {
+		err = runAttempt(ctx, policy.AttemptTimeout, fn)
+		if err == nil || IsPermanent(err) || errors.Is(err, ErrCircuitOpen) {
+			return err
+		}
+		if attempt == policy.MaxAttempts {
+			break
+		}
+
+		delay := BackoffDelay(policy, attempt)
+		log.Printf("🔁 Intento %d/%d fallido, reintentando en %v: %v", attempt, policy.MaxAttempts, delay, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+	return err
+}
+
+// CallWithResilience ejecuta fn con reintentos, pasando cada intento por el circuit breaker
+// Si ctx se cancela (ej: el cliente cortó el request) no se reintenta ni cuenta como falla del servicio remoto
+func CallWithResilience(ctx context.Context, breaker *CircuitBreaker, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	return Retry(ctx, policy, func(attemptCtx context.Context) error {
+		return breaker.Execute(func() error {
+			err := fn(attemptCtx)
+			if err != nil && ctx.Err() != nil {
+				return Permanent(err)
+			}
+			return err
+		})
+	})
+}
+
+// runAttempt ejecuta un intento con su propio timeout
+func runAttempt(ctx context.Context, timeout time.Duration, fn func(ctx context.Context) error) error {
+	if timeout <= 0 {
+		return fn(ctx)
+	}
+
+	attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return fn(attemptCtx)
+}
+
+// BackoffDelay calcula la espera antes del siguiente intento (BaseDelay * 2^(attempt-1), con jitter)
+func BackoffDelay(policy RetryPolicy, attempt int) time.Duration {
+	delay := policy.BaseDelay << (attempt - 1)
+	if delay <= 0 || (policy.MaxDelay > 0 && delay > policy.MaxDelay) {
+		delay = policy.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	// Jitter de hasta el 50% para que los clientes no reintenten todos a la vez
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
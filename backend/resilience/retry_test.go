@@ -0,0 +1,121 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		errs     []error
+		wantErr  error
+		wantRuns int
+	}{
+		{name: "succeeds after transient failures", errs: []error{errRemote, errRemote, nil}, wantRuns: 3},
+		{name: "gives up after max attempts", errs: []error{errRemote, errRemote, errRemote, nil}, wantErr: errRemote, wantRuns: 3},
+		{name: "does not retry permanent errors", errs: []error{Permanent(errRemote), nil}, wantErr: errRemote, wantRuns: 1},
+		{name: "does not retry an open circuit", errs: []error{ErrCircuitOpen, nil}, wantErr: ErrCircuitOpen, wantRuns: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs := 0
+			err := Retry(context.Background(), RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}, func(ctx context.Context) error {
+				runs++
+				return tt.errs[runs-1]
+			})
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if runs != tt.wantRuns {
+				t.Fatalf("expected %d attempts, got %d", tt.wantRuns, runs)
+			}
+		})
+	}
+}
+
+func TestRetry_EachAttemptHasItsOwnTimeout(t *testing.T) {
+	runs := 0
+	err := Retry(context.Background(), RetryPolicy{MaxAttempts: 2, AttemptTimeout: 10 * time.Millisecond}, func(ctx context.Context) error {
+		runs++
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if !errors.Is(err, context.DeadlineExceeded) || runs != 2 {
+		t.Fatalf("expected 2 attempts cut by their timeout, got %d and %v", runs, err)
+	}
+}
+
+func TestRetry_StopsWhenTheContextIsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	runs := 0
+	err := Retry(ctx, RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour}, func(ctx context.Context) error {
+		runs++
+		cancel()
+		return errRemote
+	})
+	if !errors.Is(err, errRemote) || runs != 1 {
+		t.Fatalf("expected a single attempt while waiting the backoff, got %d and %v", runs, err)
+	}
+}
+
+func TestBackoffDelay_GrowsExponentiallyUpToTheMax(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	tests := []struct {
+		attempt int
+		base    time.Duration
+	}{
+		{attempt: 1, base: 100 * time.Millisecond},
+		{attempt: 2, base: 200 * time.Millisecond},
+		{attempt: 3, base: 400 * time.Millisecond},
+		{attempt: 5, base: time.Second},
+		{attempt: 70, base: time.Second},
+	}
+	for _, tt := range tests {
+		// Con jitter la espera queda entre la mitad y el total
+		for i := 0; i < 20; i++ {
+			if delay := BackoffDelay(policy, tt.attempt); delay < tt.base/2 || delay > tt.base {
+				t.Fatalf("attempt %d: expected a delay between %v and %v, got %v", tt.attempt, tt.base/2, tt.base, delay)
+			}
+		}
+	}
+}
+
+func TestCallWithResilience_CancelledCallerDoesNotOpenTheCircuit(t *testing.T) {
+	breaker := NewCircuitBreaker(t.Name(), CircuitBreakerSettings{FailureThreshold: 1, OpenTimeout: time.Hour})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := CallWithResilience(ctx, breaker, RetryPolicy{MaxAttempts: 3}, func(ctx context.Context) error {
+		return ctx.Err()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if state := breaker.State(); state != CircuitClosed {
+		t.Fatalf("expected the circuit closed when the caller cancelled, got %s", state)
+	}
+
+	// Las fallas del servicio remoto sí abren el circuito y cortan los reintentos
+	err = CallWithResilience(context.Background(), breaker, RetryPolicy{MaxAttempts: 3}, func(ctx context.Context) error {
+		return errRemote
+	})
+	if !errors.Is(err, ErrCircuitOpen) || breaker.State() != CircuitOpen {
+		t.Fatalf("expected the circuit open after a remote failure, got %v", err)
+	}
+}
+
+func TestCallWithResilience_OpenCircuitStopsTheRetries(t *testing.T) {
+	breaker := NewCircuitBreaker(t.Name(), CircuitBreakerSettings{FailureThreshold: 1, OpenTimeout: time.Hour})
+
+	runs := 0
+	err := CallWithResilience(context.Background(), breaker, RetryPolicy{MaxAttempts: 3}, func(ctx context.Context) error {
+		runs++
+		return errRemote
+	})
+	if !errors.Is(err, ErrCircuitOpen) || runs != 1 || breaker.State() != CircuitOpen {
+		t.Fatalf("expected the second attempt rejected by the open circuit, got %d attempts and %v", runs, err)
+	}
+}
@@ -0,0 +1,228 @@
+package consumers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"search-api/repositories"
+
+	"github.com/streadway/amqp"
+)
+
+// cacheInvalidationConsumerTag identifica al consumidor de invalidaciones en su channel
+const cacheInvalidationConsumerTag = "search-api-cache-invalidation"
+
+// CacheInvalidationMessage es una invalidación del caché local publicada por una réplica de search-api
+type CacheInvalidationMessage struct {
+	// Origin identifica a la réplica que la publicó (la propia se ignora: ya limpió su caché)
+	Origin string `json:"origin"`
+
+	// Invalidation es lo que hay que eliminar del caché local
+	Invalidation repositories.CacheInvalidation `json:"invalidation"`
+}
+
+// CacheInvalidationBus difunde las invalidaciones del caché local entre las réplicas de search-api
+// Cada réplica bindea una queue exclusiva y temporal a un fanout exchange, así todas reciben cada mensaje
+// (la queue de eventos de propiedades es compartida: solo la réplica que indexa se entera del cambio)
+type CacheInvalidationBus struct {
+	connection *amqp.Connection
+	channel    *amqp.Channel
+	exchange   string
+	queueName  string
+	origin     string
+	cache      repositories.CacheRepository
+
+	publishMu sync.Mutex // un channel de amqp no admite publicaciones concurrentes
+	closing   atomic.Bool
+
+	published     atomic.Uint64
+	publishErrors atomic.Uint64
+	received      atomic.Uint64
+	invalidated   atomic.Uint64
+}
+
+// NewCacheInvalidationBus conecta con RabbitMQ, declara el fanout exchange y la queue de esta réplica
+// cache recibe las invalidaciones de las otras réplicas (solo en el nivel local)
+func NewCacheInvalidationBus(rabbitURL, exchange string, cache repositories.CacheRepository) (*CacheInvalidationBus, error) {
+	conn, err := amqp.Dial(rabbitURL)
+	if err != nil {
+		return nil, fmt.Errorf("error conectando a RabbitMQ: %w", err)
+	}
+
+	channel, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error creando channel de RabbitMQ: %w", err)
+	}
+
+	// Fanout: cada mensaje llega a todas las queues bindeadas, sin importar la routing key
+	if err := channel.ExchangeDeclare(exchange, "fanout", true, false, false, false, nil); err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("error declarando exchange '%s' en RabbitMQ: %w", exchange, err)
+	}
+
+	// Queue con nombre generado por el servidor, exclusiva de esta conexión y borrada al cerrarla:
+	// una réplica caída no acumula invalidaciones (al volver arranca con el caché local vacío)
+	queue, err := channel.QueueDeclare(
+		"",    // nombre - lo genera RabbitMQ
+		false, // durable - no sobrevive a reinicios del servidor
+		true,  // delete when unused - se elimina sin consumidores
+		true,  // exclusive - solo accesible por esta conexión
+		false, // no-wait - espera confirmación del servidor
+		nil,   // arguments - argumentos adicionales
+	)
+	if err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("error declarando la queue de invalidaciones del caché: %w", err)
+	}
+
+	if err := channel.QueueBind(queue.Name, "", exchange, false, nil); err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("error bindeando queue '%s' al exchange '%s': %w", queue.Name, exchange, err)
+	}
+
+	log.Printf("✅ Queue '%s' bindeada al exchange de invalidaciones del caché '%s'", queue.Name, exchange)
+
+	return &CacheInvalidationBus{
+		connection: conn,
+		channel:    channel,
+		exchange:   exchange,
+		queueName:  queue.Name,
+		origin:     replicaID(),
+		cache:      cache,
+	}, nil
+}
+
+// Start consume las invalidaciones de las otras réplicas en segundo plano
+func (b *CacheInvalidationBus) Start() error {
+	msgs, err := b.channel.Consume(
+		b.queueName,                  // queue
+		cacheInvalidationConsumerTag, // consumer tag
+		true,                         // auto-ack - una invalidación perdida solo deja la entrada hasta CACHE_LOCAL_TTL
+		true,                         // exclusive - solo este consumidor
+		false,                        // no-local - RabbitMQ no lo soporta, los propios se filtran por Origin
+		false,                        // no-wait - espera confirmación del servidor
+		nil,                          // arguments - argumentos adicionales
+	)
+	if err != nil {
+		return fmt.Errorf("error registrando consumidor de invalidaciones del caché: %w", err)
+	}
+
+	go func() {
+		for msg := range msgs {
+			b.handle(msg.Body)
+		}
+		if !b.closing.Load() {
+			log.Println("⚠️ Se cortó la conexión de invalidaciones del caché: el caché local puede quedar desactualizado hasta CACHE_LOCAL_TTL")
+		}
+	}()
+
+	log.Printf("✅ Invalidaciones del caché local compartidas entre réplicas (réplica %s)", b.origin)
+	return nil
+}
+
+// handle aplica una invalidación recibida; las propias y los mensajes inválidos se ignoran
+func (b *CacheInvalidationBus) handle(body []byte) {
+	var message CacheInvalidationMessage
+	if err := json.Unmarshal(body, &message); err != nil {
+		log.Printf("⚠️ Invalidación del caché con formato inválido: %v", err)
+		return
+	}
+	if message.Origin == b.origin {
+		return
+	}
+
+	b.received.Add(1)
+	deleted := b.cache.InvalidateLocal(message.Invalidation)
+	b.invalidated.Add(uint64(deleted))
+	if deleted > 0 {
+		log.Printf("🧹 %d keys eliminadas del caché local por una invalidación de la réplica %s", deleted, message.Origin)
+	}
+}
+
+// PublishInvalidation implementa repositories.CacheInvalidationPublisher
+func (b *CacheInvalidationBus) PublishInvalidation(invalidation repositories.CacheInvalidation) error {
+	body, err := json.Marshal(CacheInvalidationMessage{Origin: b.origin, Invalidation: invalidation})
+	if err != nil {
+		return fmt.Errorf("error serializando la invalidación: %w", err)
+	}
+
+	b.publishMu.Lock()
+	defer b.publishMu.Unlock()
+	err = b.channel.Publish(b.exchange, "", false, false, amqp.Publishing{
+		ContentType: "application/json",
+		Timestamp:   time.Now(),
+		Body:        body,
+	})
+	if err != nil {
+		b.publishErrors.Add(1)
+		return fmt.Errorf("error publicando en el exchange '%s': %w", b.exchange, err)
+	}
+	b.published.Add(1)
+	return nil
+}
+
+// Ping verifica que la conexión con RabbitMQ siga abierta
+func (b *CacheInvalidationBus) Ping() error {
+	if b.connection == nil || b.connection.IsClosed() {
+		return errors.New("conexión de invalidaciones del caché cerrada")
+	}
+	return nil
+}
+
+// WriteMetrics escribe las invalidaciones publicadas, recibidas y las keys eliminadas en formato de texto de Prometheus
+func (b *CacheInvalidationBus) WriteMetrics(w io.Writer) error {
+	metrics := []struct {
+		name  string
+		help  string
+		value uint64
+	}{
+		{"cache_invalidations_published_total", "Invalidaciones del caché local publicadas a las otras réplicas", b.published.Load()},
+		{"cache_invalidation_publish_errors_total", "Errores publicando invalidaciones del caché local", b.publishErrors.Load()},
+		{"cache_invalidations_received_total", "Invalidaciones del caché local recibidas de otras réplicas", b.received.Load()},
+		{"cache_invalidated_keys_total", "Keys eliminadas del caché local por invalidaciones de otras réplicas", b.invalidated.Load()},
+	}
+	for _, metric := range metrics {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %d\n",
+			metric.name, metric.help, metric.name, metric.name, metric.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close cancela el consumidor y cierra la conexión (RabbitMQ borra la queue de esta réplica)
+func (b *CacheInvalidationBus) Close() error {
+	b.closing.Store(true)
+	if err := b.channel.Close(); err != nil {
+		b.connection.Close()
+		return fmt.Errorf("error cerrando channel: %w", err)
+	}
+	return b.connection.Close()
+}
+
+// replicaID identifica a esta réplica en las invalidaciones: hostname y un sufijo aleatorio
+// (el sufijo distingue dos procesos en el mismo host)
+func replicaID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "unknown"
+	}
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return host
+	}
+	return host + "-" + hex.EncodeToString(suffix)
+}
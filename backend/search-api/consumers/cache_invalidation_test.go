@@ -0,0 +1,117 @@
+package consumers
+
+import (
+	"bytes"
+	"encoding/json"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"search-api/dto"
+	"search-api/repositories"
+)
+
+// sharedRemoteCache es un caché remoto en memoria compartido por las réplicas del test
+type sharedRemoteCache struct {
+	repositories.RemoteCache
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+func (c *sharedRemoteCache) Name() string {
+	return "memory"
+}
+
+func (c *sharedRemoteCache) Get(key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value, ok := c.values[key]
+	if !ok {
+		return nil, repositories.ErrCacheMiss
+	}
+	return value, nil
+}
+
+func (c *sharedRemoteCache) Set(key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[key] = value
+	return nil
+}
+
+func (c *sharedRemoteCache) DeletePattern(pattern string) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	deleted := 0
+	for key := range c.values {
+		if matched, _ := path.Match(pattern, key); matched {
+			delete(c.values, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// relayPublisher entrega las invalidaciones publicadas por una réplica a los buses de las otras
+// (en lugar del fanout exchange de RabbitMQ)
+type relayPublisher struct {
+	origin string
+	buses  []*CacheInvalidationBus
+}
+
+func (p *relayPublisher) PublishInvalidation(invalidation repositories.CacheInvalidation) error {
+	body, err := json.Marshal(CacheInvalidationMessage{Origin: p.origin, Invalidation: invalidation})
+	if err != nil {
+		return err
+	}
+	for _, bus := range p.buses {
+		bus.handle(body)
+	}
+	return nil
+}
+
+func TestCacheInvalidation_ClearsOtherReplicasLocalCache(t *testing.T) {
+	remote := &sharedRemoteCache{values: make(map[string][]byte)}
+	indexer := repositories.NewCacheRepository(remote, time.Hour)
+	other := repositories.NewCacheRepository(remote, time.Hour)
+	otherBus := &CacheInvalidationBus{origin: "replica-b", cache: other}
+
+	// El fanout también entrega el aviso a la réplica que lo publicó: lo ignora por su Origin
+	indexer.SetInvalidationPublisher(&relayPublisher{origin: "replica-a", buses: []*CacheInvalidationBus{otherBus}})
+	other.SetInvalidationPublisher(&relayPublisher{origin: "replica-b", buses: []*CacheInvalidationBus{otherBus}})
+
+	stale := dto.SearchResult{Total: 1}
+	other.Set("search:abc", stale, time.Hour)
+	other.Set("similar:abc:5", stale, time.Hour)
+
+	// La réplica que indexa limpia su caché y el remoto; el aviso limpia el caché local de la otra
+	if _, err := indexer.DeletePattern("search:*"); err != nil {
+		t.Fatalf("unexpected delete error: %v", err)
+	}
+	if _, found := other.Get("search:abc"); found {
+		t.Fatal("expected the other replica not to serve the stale search from its local cache")
+	}
+	if _, level, found := other.GetWithLevel("similar:abc:5"); !found || level != dto.CacheLevelLocal {
+		t.Fatalf("expected keys outside the pattern to stay in the local cache, got found=%v level=%q", found, level)
+	}
+
+	// Un aviso propio no se vuelve a aplicar ni se cuenta como recibido
+	if _, err := other.DeletePattern("nothing:*"); err != nil {
+		t.Fatalf("unexpected delete error: %v", err)
+	}
+
+	var metrics bytes.Buffer
+	if err := otherBus.WriteMetrics(&metrics); err != nil {
+		t.Fatalf("unexpected metrics error: %v", err)
+	}
+	for _, line := range []string{
+		"cache_invalidations_received_total 1",
+		"cache_invalidated_keys_total 1",
+	} {
+		if !strings.Contains(metrics.String(), line) {
+			t.Errorf("expected metrics to contain %q, got:\n%s", line, metrics.String())
+		}
+	}
+}
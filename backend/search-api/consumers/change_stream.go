@@ -0,0 +1,378 @@
+package consumers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"resilience"
+	"search-api/config"
+	"search-api/dto"
+	"search-api/services"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// changeStreamStateID es el documento de StateCollection con el resume token de search-api
+const changeStreamStateID = "search-api:properties"
+
+// changeStreamHistoryLostCode es el error de MongoDB cuando el resume token ya no está en el oplog
+const changeStreamHistoryLostCode = 286
+
+// popularityFields son los campos que properties-api actualiza en cada volcado de vistas
+// Esos cambios llegan por "property.popularity" y no requieren reindexar la propiedad
+var popularityFields = map[string]bool{
+	"views":        true,
+	"lastViewedAt": true,
+}
+
+// propertyChangeEvent son los campos del evento del change stream que se usan
+type propertyChangeEvent struct {
+	OperationType string `bson:"operationType"`
+	DocumentKey   struct {
+		ID primitive.ObjectID `bson:"_id"`
+	} `bson:"documentKey"`
+	UpdateDescription struct {
+		UpdatedFields bson.M `bson:"updatedFields"`
+	} `bson:"updateDescription"`
+}
+
+// changeStreamState es el documento donde se persiste el último cambio procesado
+type changeStreamState struct {
+	ID          string    `bson:"_id"`
+	ResumeToken bson.Raw  `bson:"resumeToken"`
+	UpdatedAt   time.Time `bson:"updatedAt"`
+}
+
+// PropertyChangeStream indexa los cambios de propiedades leyendo el change stream de MongoDB
+// A diferencia de los eventos de RabbitMQ no depende de que properties-api publique: cada escritura
+// en la colección queda en el oplog. El resume token se guarda después de indexar cada cambio,
+// así un reinicio continúa desde el último cambio procesado sin perder ninguno
+type PropertyChangeStream struct {
+	client   *mongo.Client
+	changes  *mongo.Collection
+	state    *mongo.Collection
+	service  services.SearchService
+	stream   services.SearchStreamService
+	settings config.ChangeStreamConfig
+
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	connected atomic.Bool
+	processed atomic.Int64
+	skipped   atomic.Int64
+	retries   atomic.Int64
+
+	mu        sync.Mutex
+	lastError error
+	lastEvent time.Time
+}
+
+// NewPropertyChangeStream conecta con MongoDB; el stream se abre con Start
+func NewPropertyChangeStream(settings config.ChangeStreamConfig, service services.SearchService, stream services.SearchStreamService) (*PropertyChangeStream, error) {
+	log.Printf("🔌 Conectando a MongoDB para el change stream de '%s.%s'", settings.Database, settings.Collection)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(settings.MongoURI))
+	if err != nil {
+		return nil, fmt.Errorf("error conectando a MongoDB: %w", err)
+	}
+	if err := client.Ping(ctx, nil); err != nil {
+		client.Disconnect(context.Background())
+		return nil, fmt.Errorf("error haciendo ping a MongoDB: %w", err)
+	}
+
+	database := client.Database(settings.Database)
+	return &PropertyChangeStream{
+		client:   client,
+		changes:  database.Collection(settings.Collection),
+		state:    database.Collection(settings.StateCollection),
+		service:  service,
+		stream:   stream,
+		settings: settings,
+		done:     make(chan struct{}),
+	}, nil
+}
+
+// Start abre el change stream en una goroutine; si se corta se reabre desde el último cambio procesado
+func (c *PropertyChangeStream) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+
+	go func() {
+		defer close(c.done)
+		for ctx.Err() == nil {
+			err := c.watch(ctx)
+			c.connected.Store(false)
+			if ctx.Err() != nil {
+				return
+			}
+
+			c.setLastError(err)
+			log.Printf("⚠️ Change stream de propiedades cortado, se reabre en %v: %v", c.settings.ReconnectDelay, err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(c.settings.ReconnectDelay):
+			}
+		}
+	}()
+}
+
+// watch abre el stream desde el resume token guardado y procesa los cambios hasta que se corte
+func (c *PropertyChangeStream) watch(ctx context.Context) error {
+	token, err := c.loadResumeToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{{Key: "operationType", Value: bson.D{
+			{Key: "$in", Value: bson.A{"insert", "update", "replace", "delete"}},
+		}}}}},
+	}
+	opts := options.ChangeStream()
+	if token != nil {
+		opts.SetResumeAfter(token)
+	}
+
+	changeStream, err := c.changes.Watch(ctx, pipeline, opts)
+	if isHistoryLost(err) {
+		// El token es más viejo que el oplog: los cambios intermedios no se pueden recuperar por acá
+		log.Printf("❌ El resume token ya no está en el oplog, el change stream continúa desde ahora. Los cambios intermedios requieren una reindexación")
+		if err := c.saveResumeToken(ctx, nil); err != nil {
+			return err
+		}
+		changeStream, err = c.changes.Watch(ctx, pipeline)
+	}
+	if err != nil {
+		return fmt.Errorf("error abriendo el change stream: %w", err)
+	}
+	defer changeStream.Close(context.Background())
+
+	c.connected.Store(true)
+	c.setLastError(nil)
+	if token == nil {
+		log.Println("✅ Change stream de propiedades abierto (sin resume token, desde ahora)")
+	} else {
+		log.Println("✅ Change stream de propiedades abierto desde el último cambio procesado")
+	}
+
+	for changeStream.Next(ctx) {
+		var event propertyChangeEvent
+		if err := changeStream.Decode(&event); err != nil {
+			log.Printf("❌ Error decodificando cambio del change stream, se omite: %v", err)
+		} else if err := c.processWithRetry(ctx, event); err != nil {
+			// Solo vuelve con error si se canceló el contexto: el token no avanza y el cambio se reprocesa
+			return err
+		}
+
+		if err := c.saveResumeToken(ctx, changeStream.ResumeToken()); err != nil {
+			return err
+		}
+	}
+	return changeStream.Err()
+}
+
+// processWithRetry procesa un cambio reintentando las fallas transitorias hasta que se indexe
+// Los cambios se procesan en orden: uno que no se pudo indexar frena a los siguientes en lugar de perderse
+func (c *PropertyChangeStream) processWithRetry(ctx context.Context, event propertyChangeEvent) error {
+	delay := time.Second
+	for {
+		err := c.process(ctx, event)
+		if err == nil {
+			c.processed.Add(1)
+			c.setLastEvent()
+			return nil
+		}
+		if resilience.IsPermanent(err) {
+			log.Printf("❌ Cambio %s de la propiedad %s descartado: %v", event.OperationType, event.DocumentKey.ID.Hex(), err)
+			return nil
+		}
+
+		c.retries.Add(1)
+		c.setLastError(err)
+		log.Printf("🔁 Falla transitoria procesando el cambio %s de la propiedad %s, se reintenta en %v: %v", event.OperationType, event.DocumentKey.ID.Hex(), delay, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > c.settings.RetryMaxDelay {
+			delay = c.settings.RetryMaxDelay
+		}
+	}
+}
+
+// process indexa, actualiza o elimina la propiedad del cambio
+// La propiedad se pide a properties-api (igual que con los eventos sin snapshot) para indexarla con el mismo mapeo
+func (c *PropertyChangeStream) process(ctx context.Context, event propertyChangeEvent) error {
+	propertyID := event.DocumentKey.ID.Hex()
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if event.OperationType == "delete" {
+		return c.service.DeleteProperty(ctx, propertyID)
+	}
+	if event.OperationType == "update" && onlyPopularityFields(event.UpdateDescription.UpdatedFields) {
+		c.skipped.Add(1)
+		return nil
+	}
+
+	property, err := c.service.FetchPropertyFromAPI(propertyID)
+	if status.Code(err) == codes.NotFound {
+		// Se borró entre el cambio y la consulta: el delete llega después en el stream
+		c.skipped.Add(1)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error obteniendo propiedad desde API: %w", err)
+	}
+
+	if event.OperationType == "insert" {
+		if err := c.service.IndexProperty(ctx, *property); err != nil {
+			return err
+		}
+		c.stream.Notify(dto.SearchStreamPropertyCreated, *property)
+		return nil
+	}
+
+	if err := c.service.UpdateProperty(ctx, *property); err != nil {
+		return err
+	}
+	c.stream.Notify(dto.SearchStreamPropertyUpdated, *property)
+	return nil
+}
+
+// loadResumeToken lee el último resume token guardado (nil si el stream nunca se abrió)
+func (c *PropertyChangeStream) loadResumeToken(ctx context.Context) (bson.Raw, error) {
+	var state changeStreamState
+	err := c.state.FindOne(ctx, bson.M{"_id": changeStreamStateID}).Decode(&state)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo el resume token: %w", err)
+	}
+	return state.ResumeToken, nil
+}
+
+// saveResumeToken guarda el resume token del último cambio procesado
+func (c *PropertyChangeStream) saveResumeToken(ctx context.Context, token bson.Raw) error {
+	_, err := c.state.UpdateOne(ctx,
+		bson.M{"_id": changeStreamStateID},
+		bson.M{"$set": bson.M{"resumeToken": token, "updatedAt": time.Now().UTC()}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("error guardando el resume token: %w", err)
+	}
+	return nil
+}
+
+// Ping retorna error si el change stream no está abierto (usado por el health check)
+func (c *PropertyChangeStream) Ping() error {
+	if c.connected.Load() {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lastError != nil {
+		return fmt.Errorf("change stream cerrado: %w", c.lastError)
+	}
+	return fmt.Errorf("change stream cerrado")
+}
+
+// WriteMetrics escribe las métricas del change stream en formato de texto de Prometheus
+func (c *PropertyChangeStream) WriteMetrics(w io.Writer) error {
+	connected := 0.0
+	if c.connected.Load() {
+		connected = 1
+	}
+	c.mu.Lock()
+	lastEvent := 0.0
+	if !c.lastEvent.IsZero() {
+		lastEvent = float64(c.lastEvent.Unix())
+	}
+	c.mu.Unlock()
+
+	metrics := []struct {
+		name  string
+		kind  string
+		help  string
+		value float64
+	}{
+		{"change_stream_connected", "gauge", "1 si el change stream de propiedades está abierto", connected},
+		{"change_stream_last_event_timestamp_seconds", "gauge", "Momento del último cambio indexado", lastEvent},
+		{"change_stream_changes_processed_total", "counter", "Cambios de propiedades indexados desde el change stream", float64(c.processed.Load())},
+		{"change_stream_changes_skipped_total", "counter", "Cambios que no requerían reindexar (vistas o propiedad ya borrada)", float64(c.skipped.Load())},
+		{"change_stream_retries_total", "counter", "Reintentos de cambios con fallas transitorias", float64(c.retries.Load())},
+	}
+	for _, metric := range metrics {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %g\n", metric.name, metric.help, metric.name, metric.kind, metric.name, metric.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close detiene el stream (el cambio en curso se reprocesa al volver a abrirlo) y desconecta de MongoDB
+func (c *PropertyChangeStream) Close() error {
+	if c.cancel != nil {
+		c.cancel()
+		select {
+		case <-c.done:
+		case <-time.After(shutdownTimeout):
+			log.Printf("⚠️ Timeout esperando al change stream de propiedades")
+		}
+	}
+	return c.client.Disconnect(context.Background())
+}
+
+// setLastError guarda el último error para el health check
+func (c *PropertyChangeStream) setLastError(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastError = err
+}
+
+// setLastEvent registra el momento del último cambio indexado
+func (c *PropertyChangeStream) setLastEvent() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastEvent = time.Now()
+}
+
+// onlyPopularityFields indica si un update solo cambió las vistas de la propiedad
+func onlyPopularityFields(updatedFields bson.M) bool {
+	if len(updatedFields) == 0 {
+		return false
+	}
+	for field := range updatedFields {
+		if !popularityFields[field] {
+			return false
+		}
+	}
+	return true
+}
+
+// isHistoryLost indica si el resume token ya no se puede usar porque salió del oplog
+func isHistoryLost(err error) bool {
+	var commandErr mongo.CommandError
+	return errors.As(err, &commandErr) && commandErr.Code == changeStreamHistoryLostCode
+}
@@ -0,0 +1,201 @@
+package consumers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"resilience"
+	"search-api/domain"
+	"search-api/dto"
+	"search-api/services"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// changeStreamService registra qué hizo el change stream con el índice
+type changeStreamService struct {
+	services.SearchService
+	fetchErr error
+	indexErr error
+
+	fetched, indexed, updated, deleted []string
+}
+
+func (s *changeStreamService) FetchPropertyFromAPI(propertyID string) (*domain.Property, error) {
+	s.fetched = append(s.fetched, propertyID)
+	if s.fetchErr != nil {
+		return nil, s.fetchErr
+	}
+	return &domain.Property{ID: propertyID, Title: "Loft en Mendoza"}, nil
+}
+
+func (s *changeStreamService) IndexProperty(ctx context.Context, property domain.Property) error {
+	if s.indexErr != nil {
+		return s.indexErr
+	}
+	s.indexed = append(s.indexed, property.ID)
+	return nil
+}
+
+func (s *changeStreamService) UpdateProperty(ctx context.Context, property domain.Property) error {
+	s.updated = append(s.updated, property.ID)
+	return nil
+}
+
+func (s *changeStreamService) DeleteProperty(ctx context.Context, propertyID string) error {
+	s.deleted = append(s.deleted, propertyID)
+	return nil
+}
+
+// changeEvent arma un cambio del change stream sobre la propiedad id
+func changeEvent(t *testing.T, operation, id string, updatedFields bson.M) propertyChangeEvent {
+	t.Helper()
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	event := propertyChangeEvent{OperationType: operation}
+	event.DocumentKey.ID = objectID
+	event.UpdateDescription.UpdatedFields = updatedFields
+	return event
+}
+
+const changedPropertyID = "6650f1c2a1b2c3d4e5f60718"
+
+func TestChangeStream_ProcessesEachOperation(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	tests := []struct {
+		name          string
+		operation     string
+		updatedFields bson.M
+		fetchErr      error
+		wantIndexed   bool
+		wantUpdated   bool
+		wantDeleted   bool
+		wantFetched   bool
+		wantEvent     string
+		wantSkipped   int64
+	}{
+		{name: "insert", operation: "insert", wantFetched: true, wantIndexed: true, wantEvent: dto.SearchStreamPropertyCreated},
+		{name: "update", operation: "update", updatedFields: bson.M{"title": "Loft en Mendoza", "views": 10}, wantFetched: true, wantUpdated: true, wantEvent: dto.SearchStreamPropertyUpdated},
+		{name: "replace", operation: "replace", wantFetched: true, wantUpdated: true, wantEvent: dto.SearchStreamPropertyUpdated},
+		{name: "delete", operation: "delete", wantDeleted: true},
+		// Los volcados de vistas llegan por property.popularity: no se le pide la propiedad a properties-api
+		{name: "views only", operation: "update", updatedFields: bson.M{"views": 10, "lastViewedAt": "2025-01-01"}, wantSkipped: 1},
+		// Se borró entre el cambio y la consulta: el delete llega después en el stream
+		{name: "deleted before the fetch", operation: "insert", fetchErr: status.Error(codes.NotFound, "no existe"), wantFetched: true, wantSkipped: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &changeStreamService{fetchErr: tt.fetchErr}
+			stream := services.NewSearchStreamService(0, 1)
+			subscription, err := stream.Subscribe(dto.SearchRequest{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			changes := &PropertyChangeStream{service: service, stream: stream}
+
+			if err := changes.processWithRetry(context.Background(), changeEvent(t, tt.operation, changedPropertyID, tt.updatedFields)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if (len(service.fetched) == 1) != tt.wantFetched || (len(service.indexed) == 1) != tt.wantIndexed ||
+				(len(service.updated) == 1) != tt.wantUpdated || (len(service.deleted) == 1) != tt.wantDeleted {
+				t.Fatalf("unexpected calls: fetched %v, indexed %v, updated %v, deleted %v", service.fetched, service.indexed, service.updated, service.deleted)
+			}
+			if changes.skipped.Load() != tt.wantSkipped || changes.processed.Load() != 1 {
+				t.Fatalf("expected %d skipped and 1 processed, got %d and %d", tt.wantSkipped, changes.skipped.Load(), changes.processed.Load())
+			}
+
+			select {
+			case event := <-subscription.Events():
+				if event.Type != tt.wantEvent || event.Property.ID != changedPropertyID {
+					t.Fatalf("expected %q for %s, got %q for %s", tt.wantEvent, changedPropertyID, event.Type, event.Property.ID)
+				}
+			default:
+				if tt.wantEvent != "" {
+					t.Fatalf("expected a %s event for the subscribers", tt.wantEvent)
+				}
+			}
+		})
+	}
+}
+
+func TestChangeStream_PermanentFailuresAreDropped(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	service := &changeStreamService{indexErr: resilience.Permanent(errors.New("documento inválido"))}
+	changes := &PropertyChangeStream{service: service, stream: services.NewSearchStreamService(0, 1)}
+
+	// El token avanza: un cambio que nunca se va a poder indexar no frena a los siguientes
+	if err := changes.processWithRetry(context.Background(), changeEvent(t, "insert", changedPropertyID, nil)); err != nil {
+		t.Fatalf("expected the change dropped, got %v", err)
+	}
+	if changes.processed.Load() != 0 || changes.retries.Load() != 0 {
+		t.Fatalf("expected no processed changes nor retries, got %d and %d", changes.processed.Load(), changes.retries.Load())
+	}
+}
+
+func TestChangeStream_TransientFailuresBlockUntilShutdown(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	service := &changeStreamService{fetchErr: status.Error(codes.Unavailable, "properties-api caído")}
+	changes := &PropertyChangeStream{service: service, stream: services.NewSearchStreamService(0, 1)}
+
+	// Con el contexto cancelado (shutdown) vuelve con error: el token no avanza y el cambio se reprocesa al reabrir
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := changes.processWithRetry(ctx, changeEvent(t, "insert", changedPropertyID, nil))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if changes.retries.Load() != 1 || changes.processed.Load() != 0 {
+		t.Fatalf("expected 1 retry and nothing processed, got %d and %d", changes.retries.Load(), changes.processed.Load())
+	}
+	if err := changes.Ping(); err == nil || !strings.Contains(err.Error(), "properties-api caído") {
+		t.Fatalf("expected the health check to report the failure, got %v", err)
+	}
+
+	var metrics strings.Builder
+	if err := changes.WriteMetrics(&metrics); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(metrics.String(), "change_stream_retries_total 1\n") || !strings.Contains(metrics.String(), "change_stream_connected 0\n") {
+		t.Fatalf("expected the retry in the metrics, got:\n%s", metrics.String())
+	}
+}
+
+func TestIsHistoryLost(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "token out of the oplog", err: mongo.CommandError{Code: changeStreamHistoryLostCode, Name: "ChangeStreamHistoryLost"}, want: true},
+		{name: "wrapped", err: fmt.Errorf("watch: %w", mongo.CommandError{Code: changeStreamHistoryLostCode}), want: true},
+		{name: "other command error", err: mongo.CommandError{Code: 13, Name: "Unauthorized"}},
+		{name: "network error", err: errors.New("connection reset")},
+		{name: "no error", err: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isHistoryLost(tt.err); got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
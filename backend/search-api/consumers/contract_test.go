@@ -0,0 +1,209 @@
+package consumers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"search-api/rpc"
+)
+
+// ============================================
+// Contratos consumer-driven (backend/contracts/search-api)
+// ============================================
+// search-api es dueño de sus contratos con properties-api: el evento que consume de RabbitMQ y las
+// llamadas gRPC que hace al indexar y al reconciliar. Estos tests verifican que los contratos describen
+// exactamente lo que search-api manda y lee; properties-api los verifica del lado del proveedor
+// También el de VerifyToken con users-api, que lo verifica del suyo
+
+// contractsDir es backend/contracts relativo al paquete
+const contractsDir = "../../contracts"
+
+// contract es un contrato consumer-driven: el request que manda el consumidor y la respuesta que espera,
+// o el mensaje que espera recibir por RabbitMQ
+type contract struct {
+	Consumer    string          `json:"consumer"`
+	Provider    string          `json:"provider"`
+	Description string          `json:"description"`
+	Request     json.RawMessage `json:"request"`
+	Response    json.RawMessage `json:"response"`
+	Message     json.RawMessage `json:"message"`
+}
+
+func loadContract(t *testing.T, name string) contract {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(contractsDir, name))
+	if err != nil {
+		t.Fatalf("failed to read contract %s: %v", name, err)
+	}
+	var c contract
+	if err := json.Unmarshal(data, &c); err != nil {
+		t.Fatalf("failed to decode contract %s: %v", name, err)
+	}
+	if c.Consumer != "search-api" {
+		t.Fatalf("contract %s belongs to consumer %q, not search-api", name, c.Consumer)
+	}
+	return c
+}
+
+// assertConsumed verifica que el cuerpo del contrato tenga exactamente los campos que lee out:
+// decodificarlo no puede encontrar campos desconocidos y todo lo que out serializa tiene que estar en el contrato
+func assertConsumed(t *testing.T, body json.RawMessage, out interface{}) {
+	t.Helper()
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(out); err != nil {
+		t.Fatalf("contract body %s does not decode into %T: %v", body, out, err)
+	}
+
+	data, err := json.Marshal(out)
+	if err != nil {
+		t.Fatalf("failed to encode %T: %v", out, err)
+	}
+	var consumed, declared interface{}
+	if err := json.Unmarshal(data, &consumed); err != nil {
+		t.Fatalf("failed to decode %T: %v", out, err)
+	}
+	if err := json.Unmarshal(body, &declared); err != nil {
+		t.Fatalf("failed to decode contract body: %v", err)
+	}
+	for _, problem := range compareShape("$", consumed, declared) {
+		t.Error(problem)
+	}
+}
+
+// compareShape compara recursivamente la forma de dos valores JSON decodificados
+// Un null en want acepta cualquier valor; en los arrays se compara cada elemento contra el primero de want
+func compareShape(path string, want, got interface{}) []string {
+	if want == nil {
+		return nil
+	}
+	if jsonKind(want) != jsonKind(got) {
+		return []string{fmt.Sprintf("%s: expected %s, got %s", path, jsonKind(want), jsonKind(got))}
+	}
+
+	var problems []string
+	switch want := want.(type) {
+	case map[string]interface{}:
+		got := got.(map[string]interface{})
+		keys := make([]string, 0, len(want))
+		for key := range want {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value, ok := got[key]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s.%s: missing field", path, key))
+				continue
+			}
+			problems = append(problems, compareShape(path+"."+key, want[key], value)...)
+		}
+	case []interface{}:
+		got := got.([]interface{})
+		if len(want) > 0 && len(got) == 0 {
+			return []string{fmt.Sprintf("%s: empty array, elements cannot be verified", path)}
+		}
+		for i, value := range got {
+			if len(want) > 0 {
+				problems = append(problems, compareShape(fmt.Sprintf("%s[%d]", path, i), want[0], value)...)
+			}
+		}
+	}
+	return problems
+}
+
+// jsonKind retorna el tipo JSON de un valor decodificado con encoding/json
+func jsonKind(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// ============================================
+// TESTS
+// ============================================
+
+func TestContract_PropertiesAPI_PropertyEvent(t *testing.T) {
+	c := loadContract(t, "search-api/properties-api/property_event.json")
+
+	var message PropertyMessage
+	assertConsumed(t, c.Message, &message)
+
+	// Un snapshot de otra versión se descarta y se consulta properties-api: el contrato es el que se indexa directo
+	if message.SchemaVersion != supportedSnapshotSchemaVersion {
+		t.Errorf("expected schemaVersion %d, got %d", supportedSnapshotSchemaVersion, message.SchemaVersion)
+	}
+	if message.Property == nil || message.Property.ID != message.PropertyID {
+		t.Errorf("expected a snapshot of property %s in the contract", message.PropertyID)
+	}
+}
+
+func TestContract_PropertiesAPI_AvailabilityChanged(t *testing.T) {
+	c := loadContract(t, "search-api/properties-api/availability_changed.json")
+
+	var message AvailabilityMessage
+	assertConsumed(t, c.Message, &message)
+
+	if message.PropertyID == "" || len(message.BookedRanges) == 0 {
+		t.Errorf("expected a property with booked ranges in the contract, got %+v", message)
+	}
+}
+
+func TestContract_PropertiesAPI_GetProperty(t *testing.T) {
+	c := loadContract(t, "search-api/properties-api/get_property.json")
+
+	var request rpc.GetPropertyRequest
+	assertConsumed(t, c.Request, &request)
+
+	var response rpc.GetPropertyResponse
+	assertConsumed(t, c.Response, &response)
+
+	if response.Property.ID != request.ID {
+		t.Errorf("expected property %s in the contract response, got %s", request.ID, response.Property.ID)
+	}
+}
+
+func TestContract_PropertiesAPI_ListPropertyVersions(t *testing.T) {
+	c := loadContract(t, "search-api/properties-api/list_property_versions.json")
+
+	var request rpc.ListPropertyVersionsRequest
+	assertConsumed(t, c.Request, &request)
+
+	var response rpc.ListPropertyVersionsResponse
+	assertConsumed(t, c.Response, &response)
+
+	if len(response.Versions) == 0 {
+		t.Errorf("expected at least one version in the contract response")
+	}
+}
+
+func TestContract_UsersAPI_VerifyToken(t *testing.T) {
+	c := loadContract(t, "search-api/users-api/verify_token.json")
+
+	var request rpc.VerifyTokenRequest
+	assertConsumed(t, c.Request, &request)
+
+	var response rpc.VerifyTokenResponse
+	assertConsumed(t, c.Response, &response)
+
+	if request.Token == "" || !response.Valid || response.UserID == 0 {
+		t.Errorf("expected a valid token in the contract, got request %s and response %s", c.Request, c.Response)
+	}
+}
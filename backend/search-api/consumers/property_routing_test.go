@@ -0,0 +1,70 @@
+package consumers
+
+import (
+	"context"
+	"testing"
+
+	"search-api/config"
+	"search-api/services"
+
+	"github.com/streadway/amqp"
+)
+
+// deletingService registra las propiedades eliminadas del índice
+type deletingService struct {
+	services.SearchService
+	deleted []string
+}
+
+func (s *deletingService) DeleteProperty(ctx context.Context, propertyID string) error {
+	s.deleted = append(s.deleted, propertyID)
+	return nil
+}
+
+func TestProcessMessage_DerivesTheOperationFromTheRoutingKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		routingKey string
+		headers    amqp.Table
+		body       string
+		wantAcked  bool
+		wantDelete bool
+	}{
+		{name: "property.deleted without operation", routingKey: "property.deleted", body: `{"propertyId":"p1"}`, wantAcked: true, wantDelete: true},
+		{name: "retried message keeps its original key", routingKey: "search_api.retry", headers: amqp.Table{originalRoutingKeyHeader: "property.deleted"}, body: `{"propertyId":"p1"}`, wantAcked: true, wantDelete: true},
+		{name: "operation in the body wins", routingKey: "property.created", body: `{"operation":"delete","propertyId":"p1"}`, wantAcked: true, wantDelete: true},
+		{name: "unknown routing key without operation", routingKey: "property.archived", body: `{"propertyId":"p1"}`},
+		{name: "missing property id", routingKey: "property.deleted", body: `{}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &deletingService{}
+			consumer := &RabbitMQConsumer{service: service, settings: config.ConsumerConfig{PropertyEvents: true}}
+			ack := &recordingAcknowledger{}
+
+			consumer.processMessage(amqp.Delivery{Acknowledger: ack, RoutingKey: tt.routingKey, Headers: tt.headers, Body: []byte(tt.body)})
+
+			if tt.wantAcked && (ack.acked != 1 || ack.nacked != 0) {
+				t.Fatalf("expected the message acked, got %+v", ack)
+			}
+			if !tt.wantAcked && (ack.nacked != 1 || ack.acked != 0) {
+				t.Fatalf("expected the message rejected, got %+v", ack)
+			}
+			if deleted := len(service.deleted) == 1 && service.deleted[0] == "p1"; deleted != tt.wantDelete {
+				t.Fatalf("expected delete=%v, got %v", tt.wantDelete, service.deleted)
+			}
+		})
+	}
+}
+
+func TestProcessMessage_IgnoresPropertyEventsWithChangeStream(t *testing.T) {
+	service := &deletingService{}
+	consumer := &RabbitMQConsumer{service: service, settings: config.ConsumerConfig{PropertyEvents: false}}
+	ack := &recordingAcknowledger{}
+
+	consumer.processMessage(amqp.Delivery{Acknowledger: ack, RoutingKey: "property.deleted", Body: []byte(`{"propertyId":"p1"}`)})
+
+	if ack.acked != 1 || len(service.deleted) != 0 {
+		t.Fatalf("expected the event acked without touching the index, got %+v and %v", ack, service.deleted)
+	}
+}
@@ -3,15 +3,62 @@ package consumers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"strconv"
+	"sync/atomic"
 	"time"
 
+	"resilience"
+	"search-api/config"
+	"search-api/domain"
+	"search-api/dto"
 	"search-api/services"
 
 	"github.com/streadway/amqp"
 )
 
+// supportedSnapshotSchemaVersion es la versión de snapshot que este consumidor sabe indexar
+const supportedSnapshotSchemaVersion = 1
+
+// propertyBindingKey es el patrón con el que se bindea la cola al exchange
+// Recibe property.created, property.updated, property.deleted y property.popularity
+const propertyBindingKey = "property.*"
+
+// popularityRoutingKey es el evento periódico de properties-api con el total de vistas de las propiedades
+const popularityRoutingKey = "property.popularity"
+
+// bookingRoutingKey es la reserva confirmada publicada por properties-api
+// Se registra como interacción del huésped para el ranking personalizado
+const bookingRoutingKey = "booking.confirmed"
+
+// availabilityRoutingKey son los días ocupados de una propiedad publicados por properties-api
+// cuando cambian sus reservas, bloqueos o calendarios externos
+const availabilityRoutingKey = "availability.changed"
+
+// userRoutingKeys son los eventos de users-api que sacan del índice las propiedades del usuario:
+// cuenta desactivada o datos personales borrados (GDPR)
+var userRoutingKeys = map[string]bool{
+	"user.deactivated": true,
+	"user.erased":      true,
+}
+
+// consumerTag identifica al consumidor en el channel (se usa para cancelarlo al cerrar)
+const consumerTag = "search-api"
+
+// shutdownTimeout es la espera máxima para que los workers terminen al cerrar
+const shutdownTimeout = 30 * time.Second
+
+// routingKeyOperations mapea cada routing key a la operación a realizar
+// Se usa cuando el mensaje no trae el campo Operation
+var routingKeyOperations = map[string]string{
+	"property.created": "create",
+	"property.updated": "update",
+	"property.deleted": "delete",
+}
+
 // PropertyMessage representa un mensaje sobre una propiedad
 type PropertyMessage struct {
 	// Operation indica la operación a realizar: "create", "update", "delete"
@@ -19,6 +66,54 @@ type PropertyMessage struct {
 
 	// PropertyID es el identificador único de la propiedad
 	PropertyID string `json:"propertyId"`
+
+	// SchemaVersion es la versión del snapshot (0 si el mensaje no trae snapshot)
+	SchemaVersion int `json:"schemaVersion,omitempty"`
+
+	// Property es el snapshot completo de la propiedad (opcional)
+	// Si está presente se indexa directamente sin consultar properties-api
+	Property *dto.PropertySnapshot `json:"property,omitempty"`
+}
+
+// PopularityMessage representa el volcado de vistas publicado por properties-api
+type PopularityMessage struct {
+	// Views mapea el ID de cada propiedad a su total de vistas
+	Views map[string]int64 `json:"views"`
+}
+
+// AvailabilityMessage representa los días ocupados de una propiedad publicados por properties-api
+// Trae el estado completo, así que alcanza con aplicar el último evento recibido
+type AvailabilityMessage struct {
+	// PropertyID es la propiedad cuya disponibilidad cambió
+	PropertyID string `json:"propertyId"`
+
+	// BookedRanges son los rangos ocupados desde hoy (días locales, End exclusivo)
+	BookedRanges []dto.PropertyDateRange `json:"bookedRanges"`
+
+	// NextAvailableDate es el primer día libre desde hoy (YYYY-MM-DD)
+	NextAvailableDate string `json:"nextAvailableDate"`
+}
+
+// UserMessage representa un evento de usuario publicado por users-api
+type UserMessage struct {
+	// Type es el tipo de evento (ej: "user.deactivated", "user.erased")
+	Type string `json:"type"`
+
+	// UserID es el identificador del usuario
+	UserID uint `json:"userId"`
+}
+
+// BookingMessage representa una reserva confirmada publicada por properties-api
+// Solo se leen los campos necesarios para registrar la interacción
+type BookingMessage struct {
+	// ID es el identificador de la reserva
+	ID string `json:"id"`
+
+	// PropertyID es la propiedad reservada
+	PropertyID string `json:"propertyId"`
+
+	// UserID es el ID del huésped en users-api (string en properties-api)
+	UserID string `json:"userId"`
 }
 
 // RabbitMQConsumer maneja el consumo de mensajes de RabbitMQ
@@ -27,11 +122,28 @@ type RabbitMQConsumer struct {
 	channel    *amqp.Channel
 	queueName  string
 	service    services.SearchService
+	stream     services.SearchStreamService
+	history    services.SearchHistoryService
+	engagement services.EngagementService
+	analytics  services.AnalyticsService
+
+	settings config.ConsumerConfig
+	pool     *workerPool
+	started  atomic.Bool
+	done     chan struct{} // se cierra cuando los workers terminaron los mensajes pendientes
+
+	lastDeliveryNs atomic.Int64 // momento de la última entrega (UnixNano, 0 si no hubo)
+	lastLagNs      atomic.Int64 // demora en la queue del último mensaje entregado con timestamp
 }
 
 // NewRabbitMQConsumer crea una nueva instancia del consumidor de RabbitMQ
-// Conecta con RabbitMQ, crea un channel y declara la queue "property_events"
-func NewRabbitMQConsumer(rabbitURL, queueName string, service services.SearchService) (*RabbitMQConsumer, error) {
+// Conecta con RabbitMQ, crea un channel, declara los exchanges (propiedades y usuarios) y la queue, y los bindea
+// settings define cuántos workers procesan los mensajes en paralelo y el prefetch del channel
+// stream recibe cada propiedad indexada para avisar a las búsquedas suscritas (GET /search/stream)
+// history pierde el historial de búsquedas de los usuarios que borran sus datos ("user.erased")
+// engagement registra las reservas confirmadas ("booking.confirmed") y también se borra con "user.erased"
+// analytics desvincula al usuario de sus clicks con "user.erased"
+func NewRabbitMQConsumer(rabbitURL, exchange, usersExchange, queueName string, service services.SearchService, stream services.SearchStreamService, history services.SearchHistoryService, engagement services.EngagementService, analytics services.AnalyticsService, settings config.ConsumerConfig) (*RabbitMQConsumer, error) {
 	log.Printf("🔌 Conectando a RabbitMQ en: %s", rabbitURL)
 
 	// Conectar con RabbitMQ
@@ -51,7 +163,30 @@ func NewRabbitMQConsumer(rabbitURL, queueName string, service services.SearchSer
 
 	log.Println("✅ Channel de RabbitMQ creado exitosamente")
 
-	// Declarar la queue "property_events"
+	// Declarar el topic exchange (idempotente, mismos parámetros que properties-api)
+	err = channel.ExchangeDeclare(
+		exchange, // nombre del exchange
+		"topic",  // tipo - enruta por patrón de routing key
+		true,     // durable - el exchange sobrevive a reinicios del servidor
+		false,    // auto-deleted - no se elimina cuando no hay colas bindeadas
+		false,    // internal - acepta publicaciones de clientes
+		false,    // no-wait - espera confirmación del servidor
+		nil,      // arguments - argumentos adicionales
+	)
+	if err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("error declarando exchange '%s' en RabbitMQ: %w", exchange, err)
+	}
+
+	// Declarar el topic exchange de usuarios (mismos parámetros que users-api)
+	if err := channel.ExchangeDeclare(usersExchange, "topic", true, false, false, false, nil); err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("error declarando exchange '%s' en RabbitMQ: %w", usersExchange, err)
+	}
+
+	// Declarar la queue propia de search-api
 	// durable=true significa que la queue sobrevive a reinicios del servidor RabbitMQ
 	_, err = channel.QueueDeclare(
 		queueName, // nombre de la queue
@@ -69,24 +204,89 @@ func NewRabbitMQConsumer(rabbitURL, queueName string, service services.SearchSer
 
 	log.Printf("✅ Queue '%s' declarada exitosamente", queueName)
 
-	return &RabbitMQConsumer{
+	// Bindear la queue al exchange para recibir todos los eventos de propiedades
+	// Otros consumidores pueden bindear sus propias colas sin afectar a esta
+	if err := channel.QueueBind(queueName, propertyBindingKey, exchange, false, nil); err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("error bindeando queue '%s' al exchange '%s': %w", queueName, exchange, err)
+	}
+
+	log.Printf("✅ Queue '%s' bindeada al exchange '%s' con '%s'", queueName, exchange, propertyBindingKey)
+
+	// Las reservas confirmadas se publican en el mismo exchange (también las consume notifications)
+	if err := channel.QueueBind(queueName, bookingRoutingKey, exchange, false, nil); err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("error bindeando queue '%s' al exchange '%s': %w", queueName, exchange, err)
+	}
+
+	log.Printf("✅ Queue '%s' bindeada al exchange '%s' con '%s'", queueName, exchange, bookingRoutingKey)
+
+	// Los cambios de disponibilidad mantienen al día el filtro por fechas
+	if err := channel.QueueBind(queueName, availabilityRoutingKey, exchange, false, nil); err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("error bindeando queue '%s' al exchange '%s': %w", queueName, exchange, err)
+	}
+
+	log.Printf("✅ Queue '%s' bindeada al exchange '%s' con '%s'", queueName, exchange, availabilityRoutingKey)
+
+	// Colas de reintento con dead-letter de vuelta a la queue principal
+	if err := declareRetryQueues(channel, queueName); err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, err
+	}
+
+	// Bindear también los eventos de usuarios que afectan al índice
+	for routingKey := range userRoutingKeys {
+		if err := channel.QueueBind(queueName, routingKey, usersExchange, false, nil); err != nil {
+			channel.Close()
+			conn.Close()
+			return nil, fmt.Errorf("error bindeando queue '%s' al exchange '%s': %w", queueName, usersExchange, err)
+		}
+
+		log.Printf("✅ Queue '%s' bindeada al exchange '%s' con '%s'", queueName, usersExchange, routingKey)
+	}
+
+	if settings.Workers < 1 {
+		settings.Workers = 1
+	}
+	if settings.Prefetch < 1 {
+		settings.Prefetch = 1
+	}
+	// Con menos mensajes sin ACK que workers, algunos workers nunca reciben trabajo
+	if settings.Prefetch < settings.Workers {
+		log.Printf("⚠️ Prefetch (%d) menor que la cantidad de workers (%d), algunos workers quedarán ociosos", settings.Prefetch, settings.Workers)
+	}
+
+	consumer := &RabbitMQConsumer{
 		connection: conn,
 		channel:    channel,
 		queueName:  queueName,
 		service:    service,
-	}, nil
+		stream:     stream,
+		history:    history,
+		engagement: engagement,
+		analytics:  analytics,
+		settings:   settings,
+		done:       make(chan struct{}),
+	}
+	consumer.pool = newWorkerPool(settings.Workers, settings.WorkerQueueSize, settings.Prefetch, consumer.processMessage)
+	return consumer, nil
 }
 
 // Start inicia el consumo de mensajes de la queue
-// Procesa cada mensaje según su Operation y hace ACK
+// Reparte los mensajes entre los workers; cada uno procesa según su Operation y hace ACK
 func (c *RabbitMQConsumer) Start() error {
-	log.Printf("🚀 Iniciando consumo de mensajes de la queue: %s", c.queueName)
+	log.Printf("🚀 Iniciando consumo de mensajes de la queue: %s (%d workers, prefetch %d)", c.queueName, c.settings.Workers, c.settings.Prefetch)
 
-	// Configurar QoS para procesar un mensaje a la vez
+	// Configurar QoS: el prefetch limita los mensajes en memoria y genera backpressure hacia RabbitMQ
 	err := c.channel.Qos(
-		1,     // prefetch count - número de mensajes sin ACK que puede tener el consumidor
-		0,     // prefetch size - tamaño en bytes (0 = ilimitado)
-		false, // global - aplicar a todos los consumidores de esta conexión
+		c.settings.Prefetch, // prefetch count - número de mensajes sin ACK que puede tener el consumidor
+		0,                   // prefetch size - tamaño en bytes (0 = ilimitado)
+		false,               // global - aplicar a todos los consumidores de esta conexión
 	)
 	if err != nil {
 		return fmt.Errorf("error configurando QoS: %w", err)
@@ -95,7 +295,7 @@ func (c *RabbitMQConsumer) Start() error {
 	// Consumir mensajes de la queue
 	msgs, err := c.channel.Consume(
 		c.queueName, // queue
-		"",          // consumer tag - identificador único del consumidor (vacío = auto-generado)
+		consumerTag, // consumer tag - identificador del consumidor en el channel
 		false,       // auto-ack - no hacer ACK automático (queremos hacerlo manualmente)
 		false,       // exclusive - solo este consumidor puede acceder a la queue
 		false,       // no-local - no rechazar mensajes publicados en la misma conexión
@@ -108,11 +308,15 @@ func (c *RabbitMQConsumer) Start() error {
 
 	log.Printf("✅ Consumidor registrado exitosamente en queue: %s", c.queueName)
 
-	// Procesar mensajes en un loop infinito
+	// Repartir los mensajes a los workers hasta que se cancele el consumidor
+	c.started.Store(true)
 	go func() {
 		for msg := range msgs {
-			c.processMessage(msg)
+			c.observeDelivery(msg)
+			c.pool.Dispatch(msg)
 		}
+		c.pool.Stop()
+		close(c.done)
 	}()
 
 	log.Println("✅ Consumidor de RabbitMQ iniciado y escuchando mensajes")
@@ -123,6 +327,34 @@ func (c *RabbitMQConsumer) Start() error {
 func (c *RabbitMQConsumer) processMessage(msg amqp.Delivery) {
 	log.Printf("📨 Mensaje recibido: %s", string(msg.Body))
 
+	// Los mensajes que vuelven de la cola de reintento conservan su routing key en un header
+	routingKey := deliveryRoutingKey(msg)
+
+	// Los eventos de usuarios llegan a la misma queue pero tienen otro formato
+	if userRoutingKeys[routingKey] {
+		c.processUserMessage(msg)
+		return
+	}
+	if routingKey == popularityRoutingKey {
+		c.processPopularityMessage(msg)
+		return
+	}
+	if routingKey == bookingRoutingKey {
+		c.processBookingMessage(msg)
+		return
+	}
+	if routingKey == availabilityRoutingKey {
+		c.processAvailabilityMessage(msg)
+		return
+	}
+
+	// Con EVENT_SOURCE=changestream los cambios de propiedades se leen de MongoDB
+	if !c.settings.PropertyEvents {
+		log.Printf("ℹ️ Evento %s ignorado: las propiedades se indexan desde el change stream", routingKey)
+		msg.Ack(false)
+		return
+	}
+
 	// Deserializar el JSON a PropertyMessage
 	var propertyMsg PropertyMessage
 	if err := json.Unmarshal(msg.Body, &propertyMsg); err != nil {
@@ -132,6 +364,11 @@ func (c *RabbitMQConsumer) processMessage(msg amqp.Delivery) {
 		return
 	}
 
+	// Si el mensaje no trae Operation se deriva de la routing key
+	if propertyMsg.Operation == "" {
+		propertyMsg.Operation = routingKeyOperations[routingKey]
+	}
+
 	// Validar que el mensaje tenga Operation y PropertyID
 	if propertyMsg.Operation == "" {
 		log.Printf("❌ Mensaje inválido: Operation está vacío. Body: %s", string(msg.Body))
@@ -154,9 +391,9 @@ func (c *RabbitMQConsumer) processMessage(msg amqp.Delivery) {
 	var err error
 	switch propertyMsg.Operation {
 	case "create":
-		err = c.handleCreate(ctx, propertyMsg.PropertyID)
+		err = c.handleCreate(ctx, propertyMsg)
 	case "update":
-		err = c.handleUpdate(ctx, propertyMsg.PropertyID)
+		err = c.handleUpdate(ctx, propertyMsg)
 	case "delete":
 		err = c.handleDelete(ctx, propertyMsg.PropertyID)
 	default:
@@ -166,8 +403,14 @@ func (c *RabbitMQConsumer) processMessage(msg amqp.Delivery) {
 		return
 	}
 
-	// Si hay error, loguearlo pero hacer ACK del mensaje para no reintentarlo infinitamente
-	// En producción, podrías querer implementar un sistema de reintentos o dead letter queue
+	// Las fallas transitorias (properties-api no disponible) se reintentan más tarde por la cola de reintento
+	if isRetryable(err) {
+		log.Printf("⚠️ Falla transitoria procesando mensaje (Operation: %s, PropertyID: %s): %v", propertyMsg.Operation, propertyMsg.PropertyID, err)
+		c.scheduleRetry(msg, err)
+		return
+	}
+
+	// Cualquier otro error se loguea y se hace ACK del mensaje para no reintentarlo infinitamente
 	if err != nil {
 		log.Printf("❌ Error procesando mensaje (Operation: %s, PropertyID: %s): %v", propertyMsg.Operation, propertyMsg.PropertyID, err)
 		// Hacer ACK para no reintentar (o implementar lógica de reintentos)
@@ -180,41 +423,204 @@ func (c *RabbitMQConsumer) processMessage(msg amqp.Delivery) {
 	log.Printf("✅ Mensaje procesado exitosamente - Operation: %s, PropertyID: %s", propertyMsg.Operation, propertyMsg.PropertyID)
 }
 
+// processUserMessage procesa un evento de usuario ("user.deactivated" o "user.erased")
+func (c *RabbitMQConsumer) processUserMessage(msg amqp.Delivery) {
+	var userMsg UserMessage
+	if err := json.Unmarshal(msg.Body, &userMsg); err != nil {
+		log.Printf("❌ Error deserializando evento de usuario: %v. Body: %s", err, string(msg.Body))
+		msg.Nack(false, false)
+		return
+	}
+	if userMsg.UserID == 0 {
+		log.Printf("❌ Evento de usuario inválido: UserID está vacío. Body: %s", string(msg.Body))
+		msg.Nack(false, false)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// El historial de búsquedas, las interacciones y los clicks son datos personales: se borran con la cuenta
+	// Están en MongoDB, así que alcanza con que una réplica procese el evento para que se borren en todas
+	if deliveryRoutingKey(msg) == "user.erased" {
+		if err := c.eraseUser(ctx, userMsg.UserID); err != nil {
+			log.Printf("⚠️ Falla borrando los datos de búsqueda del usuario %d: %v", userMsg.UserID, err)
+			c.scheduleRetry(msg, err)
+			return
+		}
+		log.Printf("🧹 Historial de búsquedas, interacciones y clicks del usuario %d borrados", userMsg.UserID)
+	}
+
+	// Igual que con las propiedades, un error se loguea y se hace ACK para no reintentar infinitamente
+	if err := c.service.DeleteOwnerProperties(ctx, userMsg.UserID); err != nil {
+		log.Printf("❌ Error ocultando propiedades del usuario %d (%s): %v", userMsg.UserID, deliveryRoutingKey(msg), err)
+		msg.Ack(false)
+		return
+	}
+
+	msg.Ack(false)
+	log.Printf("✅ Propiedades del usuario %d ocultadas de la búsqueda (%s)", userMsg.UserID, deliveryRoutingKey(msg))
+}
+
+// eraseUser borra el historial y las interacciones del usuario y lo desvincula de sus clicks
+// Es idempotente: si falla a la mitad, el reintento vuelve a borrar todo
+func (c *RabbitMQConsumer) eraseUser(ctx context.Context, userID uint) error {
+	if err := c.history.Clear(ctx, userID); err != nil {
+		return err
+	}
+	if err := c.engagement.Clear(ctx, userID); err != nil {
+		return err
+	}
+	return c.analytics.EraseUser(ctx, userID)
+}
+
+// processBookingMessage registra la reserva confirmada como interacción del huésped con la propiedad
+func (c *RabbitMQConsumer) processBookingMessage(msg amqp.Delivery) {
+	var bookingMsg BookingMessage
+	if err := json.Unmarshal(msg.Body, &bookingMsg); err != nil {
+		log.Printf("❌ Error deserializando reserva: %v. Body: %s", err, string(msg.Body))
+		msg.Nack(false, false)
+		return
+	}
+	userID, err := strconv.ParseUint(bookingMsg.UserID, 10, 32)
+	if err != nil || userID == 0 || bookingMsg.PropertyID == "" {
+		log.Printf("❌ Reserva inválida: se requieren PropertyID y UserID numérico. Body: %s", string(msg.Body))
+		msg.Nack(false, false)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	err = c.engagement.Record(ctx, uint(userID), bookingMsg.PropertyID, domain.EngagementBooking)
+	if errors.Is(err, services.ErrPropertyNotFound) {
+		// La propiedad ya no está en el índice: no hay atributos con los que comparar
+		log.Printf("ℹ️ Reserva %s de una propiedad no indexada (%s), se ignora", bookingMsg.ID, bookingMsg.PropertyID)
+		msg.Ack(false)
+		return
+	}
+	if err != nil {
+		// El índice no respondió: se reintenta más tarde por la cola de reintento
+		c.scheduleRetry(msg, err)
+		return
+	}
+
+	msg.Ack(false)
+	log.Printf("✅ Reserva %s registrada como interacción del usuario %d", bookingMsg.ID, userID)
+}
+
+// processPopularityMessage actualiza en Solr la popularidad de las propiedades del volcado
+func (c *RabbitMQConsumer) processPopularityMessage(msg amqp.Delivery) {
+	var popularityMsg PopularityMessage
+	if err := json.Unmarshal(msg.Body, &popularityMsg); err != nil {
+		log.Printf("❌ Error deserializando evento de popularidad: %v. Body: %s", err, string(msg.Body))
+		msg.Nack(false, false)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// El próximo volcado trae los totales actualizados, así que un error no se reintenta
+	if err := c.service.UpdatePopularity(ctx, popularityMsg.Views); err != nil {
+		log.Printf("❌ Error actualizando popularidad de %d propiedades: %v", len(popularityMsg.Views), err)
+	}
+
+	msg.Ack(false)
+}
+
+// processAvailabilityMessage reemplaza en el índice los días ocupados de la propiedad
+// A diferencia de la popularidad un error se reintenta: sin el evento la propiedad aparecería
+// disponible en búsquedas con fechas hasta el próximo cambio de su calendario
+func (c *RabbitMQConsumer) processAvailabilityMessage(msg amqp.Delivery) {
+	var availabilityMsg AvailabilityMessage
+	if err := json.Unmarshal(msg.Body, &availabilityMsg); err != nil {
+		log.Printf("❌ Error deserializando evento de disponibilidad: %v. Body: %s", err, string(msg.Body))
+		msg.Nack(false, false)
+		return
+	}
+	if availabilityMsg.PropertyID == "" {
+		log.Printf("❌ Evento de disponibilidad sin PropertyID. Body: %s", string(msg.Body))
+		msg.Nack(false, false)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	booked := dto.ToDomainDateRanges(availabilityMsg.BookedRanges)
+	if err := c.service.UpdateAvailability(ctx, availabilityMsg.PropertyID, booked, availabilityMsg.NextAvailableDate); err != nil {
+		log.Printf("❌ Error actualizando disponibilidad de la propiedad %s: %v", availabilityMsg.PropertyID, err)
+		c.scheduleRetry(msg, err)
+		return
+	}
+
+	msg.Ack(false)
+}
+
+// resolveProperty obtiene la propiedad del mensaje
+// Usa el snapshot si viene en una versión soportada; si no, la consulta a properties-api
+// Las fallas de properties-api que no son definitivas (5xx, timeout, circuito abierto) se marcan para reintento
+func (c *RabbitMQConsumer) resolveProperty(msg PropertyMessage) (*domain.Property, error) {
+	if msg.Property != nil && msg.SchemaVersion == supportedSnapshotSchemaVersion {
+		property, err := c.service.PropertyFromSnapshot(*msg.Property)
+		if err == nil && property.ID == msg.PropertyID {
+			log.Printf("📦 Usando snapshot del evento (schema v%d) para propiedad: %s", msg.SchemaVersion, msg.PropertyID)
+			return property, nil
+		}
+		log.Printf("⚠️ Snapshot inválido para propiedad %s, consultando API: %v", msg.PropertyID, err)
+	} else if msg.Property != nil {
+		log.Printf("⚠️ Versión de snapshot no soportada (v%d) para propiedad %s, consultando API", msg.SchemaVersion, msg.PropertyID)
+	}
+
+	property, err := c.service.FetchPropertyFromAPI(msg.PropertyID)
+	if err != nil {
+		err = fmt.Errorf("error obteniendo propiedad desde API: %w", err)
+		if !resilience.IsPermanent(err) {
+			return nil, retryable(err)
+		}
+		return nil, err
+	}
+	return property, nil
+}
+
 // handleCreate maneja la acción "create"
-// Obtiene la propiedad desde la API y la indexa en Solr
-func (c *RabbitMQConsumer) handleCreate(ctx context.Context, propertyID string) error {
+// Obtiene la propiedad (snapshot o API) y la indexa en Solr
+func (c *RabbitMQConsumer) handleCreate(ctx context.Context, msg PropertyMessage) error {
+	propertyID := msg.PropertyID
 	log.Printf("📝 Creando/Indexando propiedad: %s", propertyID)
 
-	// Obtener propiedad desde la API
-	property, err := c.service.FetchPropertyFromAPI(propertyID)
+	property, err := c.resolveProperty(msg)
 	if err != nil {
-		return fmt.Errorf("error obteniendo propiedad desde API: %w", err)
+		return err
 	}
 
 	// Indexar en Solr
 	if err := c.service.IndexProperty(ctx, *property); err != nil {
 		return fmt.Errorf("error indexando propiedad en Solr: %w", err)
 	}
+	c.stream.Notify(dto.SearchStreamPropertyCreated, *property)
 
 	log.Printf("✅ Propiedad indexada exitosamente: %s", propertyID)
 	return nil
 }
 
 // handleUpdate maneja la acción "update"
-// Obtiene la propiedad actualizada desde la API y la actualiza en Solr
-func (c *RabbitMQConsumer) handleUpdate(ctx context.Context, propertyID string) error {
+// Obtiene la propiedad actualizada (snapshot o API) y la actualiza en Solr
+func (c *RabbitMQConsumer) handleUpdate(ctx context.Context, msg PropertyMessage) error {
+	propertyID := msg.PropertyID
 	log.Printf("🔄 Actualizando propiedad: %s", propertyID)
 
-	// Obtener propiedad actualizada desde la API
-	property, err := c.service.FetchPropertyFromAPI(propertyID)
+	property, err := c.resolveProperty(msg)
 	if err != nil {
-		return fmt.Errorf("error obteniendo propiedad desde API: %w", err)
+		return err
 	}
 
 	// Actualizar en Solr
 	if err := c.service.UpdateProperty(ctx, *property); err != nil {
 		return fmt.Errorf("error actualizando propiedad en Solr: %w", err)
 	}
+	c.stream.Notify(dto.SearchStreamPropertyUpdated, *property)
 
 	log.Printf("✅ Propiedad actualizada exitosamente: %s", propertyID)
 	return nil
@@ -234,12 +640,97 @@ func (c *RabbitMQConsumer) handleDelete(ctx context.Context, propertyID string)
 	return nil
 }
 
+// Ping retorna error si la conexión con RabbitMQ está cerrada (usado por el health check)
+func (c *RabbitMQConsumer) Ping() error {
+	if c.connection == nil || c.connection.IsClosed() {
+		return fmt.Errorf("conexión con RabbitMQ cerrada")
+	}
+	return nil
+}
+
+// WriteMetrics escribe las métricas de backpressure del consumidor en formato de texto de Prometheus
+func (c *RabbitMQConsumer) WriteMetrics(w io.Writer) error {
+	return c.pool.WriteMetrics(w)
+}
+
+// observeDelivery registra cuánto esperó el mensaje en la queue según el timestamp de publicación
+// Los reintentos se republican sin timestamp: su espera es la de la cola de reintento, no un atraso del consumidor
+func (c *RabbitMQConsumer) observeDelivery(msg amqp.Delivery) {
+	now := time.Now()
+	c.lastDeliveryNs.Store(now.UnixNano())
+	if msg.Timestamp.IsZero() {
+		return
+	}
+	lag := now.Sub(msg.Timestamp)
+	if lag < 0 {
+		// Relojes desfasados entre el publicador y search-api
+		lag = 0
+	}
+	c.lastLagNs.Store(int64(lag))
+}
+
+// QueueName retorna el nombre de la queue principal del consumidor
+func (c *RabbitMQConsumer) QueueName() string {
+	return c.queueName
+}
+
+// InspectQueues consulta en RabbitMQ la queue principal y las colas de reintento
+// Usa un channel propio: si la consulta falla RabbitMQ cierra ese channel y no el del consumo
+func (c *RabbitMQConsumer) InspectQueues() ([]dto.ConsumerQueueStatus, error) {
+	channel, err := c.connection.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("error abriendo channel de monitoreo: %w", err)
+	}
+	defer channel.Close()
+
+	names := []string{c.queueName}
+	for _, delay := range retryDelays {
+		names = append(names, retryQueueName(c.queueName, delay))
+	}
+
+	queues := make([]dto.ConsumerQueueStatus, 0, len(names))
+	for _, name := range names {
+		queue, err := channel.QueueInspect(name)
+		if err != nil {
+			return nil, fmt.Errorf("error consultando queue '%s': %w", name, err)
+		}
+		queues = append(queues, dto.ConsumerQueueStatus{Name: queue.Name, Messages: queue.Messages, Consumers: queue.Consumers})
+	}
+	return queues, nil
+}
+
+// DeliveryStats retorna los contadores de entrega y procesamiento del consumidor
+func (c *RabbitMQConsumer) DeliveryStats() services.ConsumerDeliveryStats {
+	stats := services.ConsumerDeliveryStats{
+		Processed: c.pool.Processed(),
+		LastLag:   time.Duration(c.lastLagNs.Load()),
+	}
+	if delivered := c.lastDeliveryNs.Load(); delivered != 0 {
+		stats.LastDeliveryAt = time.Unix(0, delivered).UTC()
+	}
+	return stats
+}
+
 // Close cierra las conexiones de RabbitMQ
+// Antes cancela el consumidor y espera a que los workers terminen los mensajes ya recibidos
 func (c *RabbitMQConsumer) Close() error {
 	log.Println("🔌 Cerrando conexiones de RabbitMQ...")
 
 	var errs []error
 
+	if c.started.Load() {
+		if err := c.channel.Cancel(consumerTag, false); err != nil {
+			errs = append(errs, fmt.Errorf("error cancelando consumidor: %w", err))
+		} else {
+			select {
+			case <-c.done:
+				log.Println("✅ Workers del consumidor finalizados")
+			case <-time.After(shutdownTimeout):
+				log.Println("⚠️ Timeout esperando a los workers del consumidor")
+			}
+		}
+	}
+
 	// Cerrar channel
 	if c.channel != nil {
 		if err := c.channel.Close(); err != nil {
@@ -0,0 +1,141 @@
+package consumers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// retryDelays son las esperas antes de cada reintento de un mensaje con falla transitoria
+// Después del último reintento el mensaje se descarta
+var retryDelays = []time.Duration{30 * time.Second, 2 * time.Minute, 10 * time.Minute}
+
+// retryCountHeader es el header con la cantidad de reintentos ya realizados
+const retryCountHeader = "x-retry-count"
+
+// originalRoutingKeyHeader guarda la routing key original: al volver desde la cola de reintento
+// el mensaje llega con el nombre de la queue como routing key
+const originalRoutingKeyHeader = "x-original-routing-key"
+
+// retryableError marca un error transitorio (ej: properties-api caída) que se reintenta más tarde
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string { return e.err.Error() }
+func (e *retryableError) Unwrap() error { return e.err }
+
+// retryable marca un error como transitorio
+func retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &retryableError{err: err}
+}
+
+// isRetryable indica si el error fue marcado con retryable
+func isRetryable(err error) bool {
+	var retryableErr *retryableError
+	return errors.As(err, &retryableErr)
+}
+
+// retryQueueName retorna el nombre de la cola de reintento para una espera (ej: property_events.retry.30s)
+func retryQueueName(queueName string, delay time.Duration) string {
+	if delay%time.Minute == 0 {
+		return fmt.Sprintf("%s.retry.%dm", queueName, int(delay/time.Minute))
+	}
+	return fmt.Sprintf("%s.retry.%ds", queueName, int(delay/time.Second))
+}
+
+// declareRetryQueues declara una cola de reintento por cada espera
+// Los mensajes se publican con TTL por mensaje; al expirar RabbitMQ los manda por dead-letter
+// a la queue principal. Una cola por espera evita que un mensaje de 10m bloquee a uno de 30s
+func declareRetryQueues(channel *amqp.Channel, queueName string) error {
+	for _, delay := range retryDelays {
+		name := retryQueueName(queueName, delay)
+		_, err := channel.QueueDeclare(
+			name,  // nombre de la queue
+			true,  // durable - la queue sobrevive a reinicios del servidor
+			false, // delete when unused
+			false, // exclusive
+			false, // no-wait
+			amqp.Table{
+				"x-dead-letter-exchange":    "",        // exchange por defecto: enruta por nombre de queue
+				"x-dead-letter-routing-key": queueName, // vuelve a la queue principal
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("error declarando queue de reintento '%s': %w", name, err)
+		}
+		log.Printf("✅ Queue de reintento '%s' declarada (espera %v)", name, delay)
+	}
+	return nil
+}
+
+// deliveryRoutingKey retorna la routing key con la que se publicó originalmente el mensaje
+func deliveryRoutingKey(msg amqp.Delivery) string {
+	if original, ok := msg.Headers[originalRoutingKeyHeader].(string); ok && original != "" {
+		return original
+	}
+	return msg.RoutingKey
+}
+
+// deliveryRetryCount retorna la cantidad de reintentos que ya tuvo el mensaje
+func deliveryRetryCount(msg amqp.Delivery) int {
+	switch count := msg.Headers[retryCountHeader].(type) {
+	case int32:
+		return int(count)
+	case int64:
+		return int(count)
+	case int:
+		return count
+	default:
+		return 0
+	}
+}
+
+// scheduleRetry publica el mensaje en la cola de reintento que corresponde y hace ACK del original
+// Si ya se agotaron los reintentos, el mensaje se descarta con ACK como antes
+func (c *RabbitMQConsumer) scheduleRetry(msg amqp.Delivery, cause error) {
+	attempt := deliveryRetryCount(msg)
+	if attempt >= len(retryDelays) {
+		log.Printf("❌ Reintentos agotados (%d) para mensaje %s, se descarta: %v", attempt, deliveryRoutingKey(msg), cause)
+		msg.Ack(false)
+		return
+	}
+
+	delay := retryDelays[attempt]
+	headers := amqp.Table{}
+	for key, value := range msg.Headers {
+		headers[key] = value
+	}
+	headers[retryCountHeader] = int32(attempt + 1)
+	headers[originalRoutingKeyHeader] = deliveryRoutingKey(msg)
+
+	err := c.channel.Publish(
+		"",                                 // exchange por defecto
+		retryQueueName(c.queueName, delay), // routing key = nombre de la cola de reintento
+		false,                              // mandatory
+		false,                              // immediate
+		amqp.Publishing{
+			Headers:      headers,
+			ContentType:  msg.ContentType,
+			DeliveryMode: amqp.Persistent,
+			Expiration:   strconv.FormatInt(delay.Milliseconds(), 10), // TTL por mensaje en milisegundos
+			Body:         msg.Body,
+		},
+	)
+	if err != nil {
+		// Sin la cola de reintento se devuelve el mensaje a la queue para no perderlo
+		log.Printf("❌ Error programando reintento, se devuelve el mensaje a la queue: %v", err)
+		msg.Nack(false, true)
+		return
+	}
+
+	msg.Ack(false)
+	log.Printf("🔁 Reintento %d/%d programado en %v para mensaje %s: %v", attempt+1, len(retryDelays), delay, deliveryRoutingKey(msg), cause)
+}
@@ -0,0 +1,103 @@
+package consumers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"search-api/domain"
+	"search-api/dto"
+	"search-api/repositories"
+	"search-api/services"
+
+	"github.com/streadway/amqp"
+)
+
+// ownerPropertiesService registra los usuarios cuyas propiedades se ocultaron
+type ownerPropertiesService struct {
+	services.SearchService
+	deleted []uint
+}
+
+func (s *ownerPropertiesService) DeleteOwnerProperties(ctx context.Context, ownerID uint) error {
+	s.deleted = append(s.deleted, ownerID)
+	return nil
+}
+
+// recordingAcknowledger registra cómo terminó cada entrega
+type recordingAcknowledger struct {
+	acked, nacked int
+}
+
+func (a *recordingAcknowledger) Ack(tag uint64, multiple bool) error {
+	a.acked++
+	return nil
+}
+
+func (a *recordingAcknowledger) Nack(tag uint64, multiple, requeue bool) error {
+	a.nacked++
+	return nil
+}
+
+func (a *recordingAcknowledger) Reject(tag uint64, requeue bool) error {
+	a.nacked++
+	return nil
+}
+
+func TestUserErased_ClearsSharedDataForEveryReplica(t *testing.T) {
+	ctx := context.Background()
+	const userID uint = 7
+
+	// Los repositorios compartidos hacen de MongoDB: las dos réplicas leen y escriben los mismos datos
+	historyRepo := repositories.NewSearchHistoryRepository(10, 10)
+	engagementRepo := repositories.NewEngagementRepository(10, 10)
+	analyticsRepo := repositories.NewAnalyticsRepository(100)
+	newReplica := func() (*RabbitMQConsumer, services.SearchHistoryService, services.AnalyticsService) {
+		history := services.NewSearchHistoryService(historyRepo, time.Hour)
+		analytics := services.NewAnalyticsService(analyticsRepo)
+		return &RabbitMQConsumer{
+			service:    &ownerPropertiesService{},
+			history:    history,
+			engagement: services.NewEngagementService(engagementRepo, nil, 0),
+			analytics:  analytics,
+		}, history, analytics
+	}
+	erasing, _, _ := newReplica()
+	_, otherHistory, otherAnalytics := newReplica()
+
+	// El usuario buscó e hizo click desde la otra réplica
+	otherHistory.Record(ctx, userID, dto.SearchRequest{Query: "cabaña"}, 3)
+	otherAnalytics.RecordClick(ctx, domain.ClickEvent{SearchID: "s1", PropertyID: "p1", Position: 1, UserID: userID})
+	if err := engagementRepo.Add(ctx, userID, domain.Engagement{Type: domain.EngagementClick, Property: domain.Property{ID: "p1"}, OccurredAt: time.Now()}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ack := &recordingAcknowledger{}
+	erasing.processUserMessage(amqp.Delivery{
+		Acknowledger: ack,
+		RoutingKey:   "user.erased",
+		Body:         []byte(`{"type":"user.erased","userId":7}`),
+	})
+
+	if ack.acked != 1 || ack.nacked != 0 {
+		t.Fatalf("expected the event to be acked once, got %d acks and %d nacks", ack.acked, ack.nacked)
+	}
+	if deleted := erasing.service.(*ownerPropertiesService).deleted; len(deleted) != 1 || deleted[0] != userID {
+		t.Fatalf("expected the user's properties to be hidden, got %v", deleted)
+	}
+	history, err := otherHistory.List(ctx, userID, 0)
+	if err != nil || len(history.Searches) != 0 {
+		t.Fatalf("expected the other replica to see an empty history, got %+v (%v)", history.Searches, err)
+	}
+	engagements, err := engagementRepo.List(ctx, userID)
+	if err != nil || len(engagements) != 0 {
+		t.Fatalf("expected no engagements left, got %+v (%v)", engagements, err)
+	}
+	clicks, err := analyticsRepo.ListClicksSince(ctx, time.Time{})
+	if err != nil || len(clicks) != 1 {
+		t.Fatalf("expected the click to remain for the reports, got %+v (%v)", clicks, err)
+	}
+	if clicks[0].UserID != 0 {
+		t.Fatalf("expected the click to be unlinked from the user, got user %d", clicks[0].UserID)
+	}
+}
@@ -0,0 +1,209 @@
+package consumers
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// partitionMessage es lo mínimo que se lee de un mensaje para elegir el worker
+type partitionMessage struct {
+	PropertyID string `json:"propertyId"`
+	UserID     uint   `json:"userId"`
+}
+
+// workerStats son los contadores de un worker expuestos como métricas
+type workerStats struct {
+	processed atomic.Uint64
+	busy      atomic.Int64 // 1 mientras el worker procesa un mensaje
+}
+
+// workerPool reparte los mensajes entre N workers
+// Todos los mensajes de una misma propiedad van siempre al mismo worker (hash del PropertyID),
+// así se procesan en el orden en que llegaron aunque haya varios workers en paralelo
+type workerPool struct {
+	queues  []chan amqp.Delivery
+	stats   []*workerStats
+	handler func(amqp.Delivery)
+	wg      sync.WaitGroup
+
+	prefetch      int
+	dispatched    atomic.Uint64
+	blocked       atomic.Uint64 // veces que el dispatcher encontró la cola del worker llena
+	blockedWaitNs atomic.Int64  // tiempo total que el dispatcher esperó por colas llenas
+}
+
+// newWorkerPool crea el pool y arranca los workers
+func newWorkerPool(workers, queueSize, prefetch int, handler func(amqp.Delivery)) *workerPool {
+	if workers < 1 {
+		workers = 1
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+
+	pool := &workerPool{
+		queues:   make([]chan amqp.Delivery, workers),
+		stats:    make([]*workerStats, workers),
+		handler:  handler,
+		prefetch: prefetch,
+	}
+
+	for i := range pool.queues {
+		pool.queues[i] = make(chan amqp.Delivery, queueSize)
+		pool.stats[i] = &workerStats{}
+		pool.wg.Add(1)
+		go pool.run(i)
+	}
+	return pool
+}
+
+// run procesa en orden los mensajes asignados a un worker
+func (p *workerPool) run(worker int) {
+	defer p.wg.Done()
+
+	stats := p.stats[worker]
+	for msg := range p.queues[worker] {
+		stats.busy.Store(1)
+		p.handler(msg)
+		stats.busy.Store(0)
+		stats.processed.Add(1)
+	}
+}
+
+// Dispatch envía el mensaje al worker de su partición
+// Si la cola del worker está llena espera (backpressure): como el prefetch limita los mensajes
+// sin ACK, RabbitMQ deja de entregar hasta que los workers se liberen
+func (p *workerPool) Dispatch(msg amqp.Delivery) {
+	queue := p.queues[p.workerFor(partitionKey(msg))]
+	p.dispatched.Add(1)
+
+	select {
+	case queue <- msg:
+		return
+	default:
+	}
+
+	p.blocked.Add(1)
+	start := time.Now()
+	queue <- msg
+	p.blockedWaitNs.Add(int64(time.Since(start)))
+}
+
+// Stop cierra las colas y espera a que los workers terminen los mensajes pendientes
+func (p *workerPool) Stop() {
+	for _, queue := range p.queues {
+		close(queue)
+	}
+	p.wg.Wait()
+}
+
+// workerFor elige el worker de una partición
+func (p *workerPool) workerFor(key string) int {
+	if len(p.queues) == 1 {
+		return 0
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(key))
+	return int(hash.Sum32() % uint32(len(p.queues)))
+}
+
+// partitionKey retorna la clave que define el orden de procesamiento de un mensaje
+// Propiedades por PropertyID, eventos de usuario por UserID y la popularidad por su routing key
+func partitionKey(msg amqp.Delivery) string {
+	routingKey := deliveryRoutingKey(msg)
+	if routingKey == popularityRoutingKey {
+		return routingKey
+	}
+
+	var partition partitionMessage
+	if err := json.Unmarshal(msg.Body, &partition); err != nil {
+		// El mensaje se rechaza al procesarlo, el worker es indistinto
+		return ""
+	}
+	if userRoutingKeys[routingKey] {
+		return "user:" + strconv.FormatUint(uint64(partition.UserID), 10)
+	}
+	return "property:" + partition.PropertyID
+}
+
+// Processed retorna la cantidad de mensajes procesados por todos los workers
+func (p *workerPool) Processed() uint64 {
+	total := uint64(0)
+	for _, stats := range p.stats {
+		total += stats.processed.Load()
+	}
+	return total
+}
+
+// WriteMetrics escribe las métricas de backpressure del pool en formato de texto de Prometheus
+func (p *workerPool) WriteMetrics(w io.Writer) error {
+	inFlight := int64(0)
+	for _, stats := range p.stats {
+		inFlight += stats.busy.Load()
+	}
+	queued := 0
+	for _, queue := range p.queues {
+		queued += len(queue)
+	}
+
+	gauges := []struct {
+		name  string
+		help  string
+		value float64
+	}{
+		{"consumer_workers", "Cantidad de workers del consumidor", float64(len(p.queues))},
+		{"consumer_prefetch", "Mensajes sin ACK que RabbitMQ entrega al consumidor", float64(p.prefetch)},
+		{"consumer_worker_queue_capacity", "Capacidad de la cola de cada worker", float64(cap(p.queues[0]))},
+		{"consumer_messages_queued", "Mensajes esperando en las colas de los workers", float64(queued)},
+		{"consumer_messages_in_flight", "Mensajes que se están procesando", float64(inFlight)},
+	}
+	for _, gauge := range gauges {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %g\n", gauge.name, gauge.help, gauge.name, gauge.name, gauge.value); err != nil {
+			return err
+		}
+	}
+
+	counters := []struct {
+		name  string
+		help  string
+		value float64
+	}{
+		{"consumer_messages_dispatched_total", "Mensajes repartidos a los workers", float64(p.dispatched.Load())},
+		{"consumer_dispatch_blocked_total", "Veces que el dispatcher esperó por la cola llena de un worker", float64(p.blocked.Load())},
+		{"consumer_dispatch_blocked_seconds_total", "Tiempo total que el dispatcher esperó por colas llenas", time.Duration(p.blockedWaitNs.Load()).Seconds()},
+	}
+	for _, counter := range counters {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %g\n", counter.name, counter.help, counter.name, counter.name, counter.value); err != nil {
+			return err
+		}
+	}
+
+	perWorker := []struct {
+		name  string
+		kind  string
+		help  string
+		value func(worker int) float64
+	}{
+		{"consumer_worker_queue_depth", "gauge", "Mensajes esperando en la cola del worker", func(worker int) float64 { return float64(len(p.queues[worker])) }},
+		{"consumer_worker_processed_total", "counter", "Mensajes procesados por el worker", func(worker int) float64 { return float64(p.stats[worker].processed.Load()) }},
+	}
+	for _, metric := range perWorker {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", metric.name, metric.help, metric.name, metric.kind); err != nil {
+			return err
+		}
+		for worker := range p.queues {
+			if _, err := fmt.Fprintf(w, "%s{worker=\"%d\"} %g\n", metric.name, worker, metric.value(worker)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package consumers
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// propertyDelivery arma un evento de propiedad con un número de secuencia en el MessageId
+func propertyDelivery(propertyID string, sequence int) amqp.Delivery {
+	return amqp.Delivery{
+		RoutingKey:  "property.updated",
+		MessageId:   fmt.Sprint(sequence),
+		DeliveryTag: uint64(sequence),
+		Body:        []byte(fmt.Sprintf(`{"operation":"update","propertyId":%q}`, propertyID)),
+	}
+}
+
+func TestWorkerPool_KeepsTheOrderOfEachProperty(t *testing.T) {
+	var mu sync.Mutex
+	processed := make(map[string][]string)
+	pool := newWorkerPool(4, 2, 8, func(msg amqp.Delivery) {
+		// Los mensajes tardan distinto: sin partición, uno posterior podría terminar antes
+		time.Sleep(time.Duration(msg.DeliveryTag%3) * time.Millisecond)
+		key := partitionKey(msg)
+		mu.Lock()
+		processed[key] = append(processed[key], msg.MessageId)
+		mu.Unlock()
+	})
+
+	properties := []string{"p1", "p2", "p3", "p4", "p5", "p6"}
+	want := make(map[string][]string)
+	for sequence := 0; sequence < 60; sequence++ {
+		propertyID := properties[sequence%len(properties)]
+		pool.Dispatch(propertyDelivery(propertyID, sequence))
+		want["property:"+propertyID] = append(want["property:"+propertyID], fmt.Sprint(sequence))
+	}
+	pool.Stop()
+
+	for key, sequences := range want {
+		if strings.Join(processed[key], ",") != strings.Join(sequences, ",") {
+			t.Fatalf("%s: expected %v in order, got %v", key, sequences, processed[key])
+		}
+	}
+	if pool.Processed() != 60 {
+		t.Fatalf("expected 60 processed messages, got %d", pool.Processed())
+	}
+}
+
+func TestWorkerPool_ProcessesDifferentPropertiesInParallel(t *testing.T) {
+	pool := newWorkerPool(2, 1, 2, nil)
+	// Se buscan dos propiedades que caigan en workers distintos
+	first, second := "p0", ""
+	for i := 1; second == ""; i++ {
+		if candidate := fmt.Sprintf("p%d", i); pool.workerFor("property:"+candidate) != pool.workerFor("property:"+first) {
+			second = candidate
+		}
+	}
+
+	started := make(chan string, 2)
+	release := make(chan struct{})
+	pool.handler = func(msg amqp.Delivery) {
+		started <- msg.MessageId
+		<-release
+	}
+	pool.Dispatch(propertyDelivery(first, 1))
+	pool.Dispatch(propertyDelivery(second, 2))
+
+	// Las dos propiedades están en proceso a la vez
+	for i := 0; i < 2; i++ {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatal("expected both properties processed in parallel")
+		}
+	}
+	close(release)
+	pool.Stop()
+}
+
+func TestWorkerPool_DispatchWaitsWhenTheWorkerQueueIsFull(t *testing.T) {
+	started := make(chan struct{}, 3)
+	release := make(chan struct{})
+	pool := newWorkerPool(1, 1, 1, func(msg amqp.Delivery) {
+		started <- struct{}{}
+		<-release
+	})
+
+	pool.Dispatch(propertyDelivery("p1", 1)) // en proceso
+	<-started
+	pool.Dispatch(propertyDelivery("p1", 2)) // en la cola del worker
+
+	dispatched := make(chan struct{})
+	go func() {
+		pool.Dispatch(propertyDelivery("p1", 3))
+		close(dispatched)
+	}()
+	select {
+	case <-dispatched:
+		t.Fatal("expected Dispatch to wait for room in the worker queue")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	<-dispatched
+	pool.Stop()
+
+	var metrics bytes.Buffer
+	if err := pool.WriteMetrics(&metrics); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, line := range []string{"consumer_dispatch_blocked_total 1\n", "consumer_messages_dispatched_total 3\n", `consumer_worker_processed_total{worker="0"} 3`} {
+		if !strings.Contains(metrics.String(), line) {
+			t.Fatalf("expected %q in the metrics, got:\n%s", line, metrics.String())
+		}
+	}
+}
+
+func TestPartitionKey(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  amqp.Delivery
+		want string
+	}{
+		{name: "property event", msg: propertyDelivery("p1", 1), want: "property:p1"},
+		{name: "user event", msg: amqp.Delivery{RoutingKey: "user.erased", Body: []byte(`{"userId":7}`)}, want: "user:7"},
+		{name: "popularity", msg: amqp.Delivery{RoutingKey: popularityRoutingKey, Body: []byte(`{"views":{}}`)}, want: popularityRoutingKey},
+		{name: "retried message keeps its partition", msg: amqp.Delivery{
+			RoutingKey: "search_api.retry",
+			Headers:    amqp.Table{originalRoutingKeyHeader: "user.deactivated"},
+			Body:       []byte(`{"userId":7}`),
+		}, want: "user:7"},
+		{name: "malformed body", msg: amqp.Delivery{RoutingKey: "property.created", Body: []byte("{")}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := partitionKey(tt.msg); got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"search-api/middleware"
+	"search-api/services"
+)
+
+// defaultAnalyticsDays es la ventana por defecto de los reportes de analytics
+const defaultAnalyticsDays = 7
+
+// maxAnalyticsDays es la ventana máxima que se puede pedir
+const maxAnalyticsDays = 90
+
+// defaultAnalyticsLimit es la cantidad por defecto de términos en un reporte
+const defaultAnalyticsLimit = 20
+
+// maxAnalyticsLimit es la cantidad máxima de términos en un reporte
+const maxAnalyticsLimit = 100
+
+// AnalyticsController maneja los reportes de analytics de búsqueda para admins
+type AnalyticsController struct {
+	service services.AnalyticsService
+}
+
+// NewAnalyticsController crea una nueva instancia del controlador de analytics
+func NewAnalyticsController(service services.AnalyticsService) *AnalyticsController {
+	return &AnalyticsController{
+		service: service,
+	}
+}
+
+// TopQueries maneja GET /search/analytics/top-queries
+// Retorna los términos más buscados en los últimos ?days= días
+func (c *AnalyticsController) TopQueries(w http.ResponseWriter, r *http.Request) {
+	window, limit, ok := c.parseReportParams(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := c.service.TopQueries(r.Context(), window, limit)
+	writeReport(w, report, err)
+}
+
+// ZeroResultQueries maneja GET /search/analytics/zero-results
+// Retorna las búsquedas que no devolvieron resultados, para ajustar contenido y sinónimos
+func (c *AnalyticsController) ZeroResultQueries(w http.ResponseWriter, r *http.Request) {
+	window, limit, ok := c.parseReportParams(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := c.service.ZeroResultQueries(r.Context(), window, limit)
+	writeReport(w, report, err)
+}
+
+// ClickThroughRate maneja GET /search/analytics/ctr
+// Retorna el click-through rate de los últimos ?days= días, los clicks por posición y las ?limit= propiedades
+// más clickeadas
+func (c *AnalyticsController) ClickThroughRate(w http.ResponseWriter, r *http.Request) {
+	window, limit, ok := c.parseReportParams(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := c.service.ClickThroughRate(r.Context(), window, limit)
+	writeReport(w, report, err)
+}
+
+// DailyActivity maneja GET /search/analytics/daily
+// Retorna las búsquedas y los clicks por día UTC de los últimos ?days= días (incluye hoy)
+// properties-api lo consulta al agregar los KPIs diarios del panel de admin
+func (c *AnalyticsController) DailyActivity(w http.ResponseWriter, r *http.Request) {
+	window, _, ok := c.parseReportParams(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := c.service.DailyActivity(r.Context(), int(window/(24*time.Hour)))
+	writeReport(w, report, err)
+}
+
+// writeReport escribe el reporte, o un 500 si no se pudieron leer los eventos
+func writeReport(w http.ResponseWriter, report interface{}, err error) {
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSONResponse(w, http.StatusOK, report)
+}
+
+// parseReportParams valida método y permisos y parsea ?days= y ?limit=
+// Si algo falla escribe la respuesta de error y retorna ok=false
+func (c *AnalyticsController) parseReportParams(w http.ResponseWriter, r *http.Request) (time.Duration, int, bool) {
+	if r.Method != http.MethodGet {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return 0, 0, false
+	}
+
+	if !middleware.IsPrivileged(r.Context()) {
+		writeErrorResponse(w, http.StatusForbidden, "Los reportes de analytics requieren un token interno o de administrador")
+		return 0, 0, false
+	}
+
+	query := r.URL.Query()
+
+	days, err := parseBoundedInt(query.Get("days"), defaultAnalyticsDays, maxAnalyticsDays)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("days %v", err))
+		return 0, 0, false
+	}
+
+	limit, err := parseBoundedInt(query.Get("limit"), defaultAnalyticsLimit, maxAnalyticsLimit)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("limit %v", err))
+		return 0, 0, false
+	}
+
+	return time.Duration(days) * 24 * time.Hour, limit, true
+}
+
+// parseBoundedInt parsea un entero entre 1 y max, usando el valor por defecto si viene vacío
+func parseBoundedInt(value string, defaultValue, max int) (int, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("debe ser un número entero válido")
+	}
+	if parsed < 1 || parsed > max {
+		return 0, fmt.Errorf("debe estar entre 1 y %d", max)
+	}
+	return parsed, nil
+}
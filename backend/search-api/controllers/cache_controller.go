@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+
+	"search-api/dto"
+	"search-api/middleware"
+	"search-api/services"
+)
+
+// CacheController maneja la inspección y el vaciado del caché de búsquedas para admins
+type CacheController struct {
+	service services.CacheService
+}
+
+// NewCacheController crea una nueva instancia del controlador de caché
+func NewCacheController(service services.CacheService) *CacheController {
+	return &CacheController{
+		service: service,
+	}
+}
+
+// Stats maneja GET /admin/cache/stats
+// Retorna los hits/misses por nivel, la cantidad de entradas y los descartes
+func (c *CacheController) Stats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if !middleware.IsPrivileged(r.Context()) {
+		writeErrorResponse(w, http.StatusForbidden, "Las métricas del caché requieren un token interno o de administrador")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, c.service.Stats())
+}
+
+// TTL maneja GET /admin/cache/ttl?key=
+// Retorna el tiempo de vida restante de la key en cada nivel del caché
+func (c *CacheController) TTL(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if !middleware.IsPrivileged(r.Context()) {
+		writeErrorResponse(w, http.StatusForbidden, "Las métricas del caché requieren un token interno o de administrador")
+		return
+	}
+
+	key := r.URL.Query().Get("key")
+	if key == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "key es requerido")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, c.service.TTL(key))
+}
+
+// Flush maneja DELETE /admin/cache
+// Sin parámetros vacía el caché local y el remoto: las próximas búsquedas van a Solr
+// Con ?pattern= (ej: "search:*") elimina solo las keys que coinciden
+func (c *CacheController) Flush(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if !middleware.IsPrivileged(r.Context()) {
+		writeErrorResponse(w, http.StatusForbidden, "Vaciar el caché requiere un token interno o de administrador")
+		return
+	}
+
+	if pattern := r.URL.Query().Get("pattern"); pattern != "" {
+		c.invalidate(w, pattern)
+		return
+	}
+
+	if err := c.service.Flush(); err != nil {
+		log.Printf("❌ Error vaciando caché: %v", err)
+		writeErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Error vaciando caché: %v", err))
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]string{"message": "Caché vaciado exitosamente"})
+}
+
+// invalidate elimina las keys que coinciden con el patrón
+// Si el backend remoto no soporta patrones responde 501 (el caché local ya se limpió)
+func (c *CacheController) invalidate(w http.ResponseWriter, pattern string) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("pattern inválido: %v", err))
+		return
+	}
+
+	deleted, err := c.service.Invalidate(pattern)
+	if errors.Is(err, services.ErrCacheOperationUnsupported) {
+		writeErrorResponse(w, http.StatusNotImplemented, fmt.Sprintf("%v: solo se invalidó el caché local", err))
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Error invalidando caché con patrón %s: %v", pattern, err)
+		writeErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Error invalidando caché: %v", err))
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, dto.CacheInvalidationResponse{Pattern: pattern, Deleted: deleted})
+}
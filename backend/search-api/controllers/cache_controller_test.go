@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"search-api/config"
+	"search-api/dto"
+	"search-api/middleware"
+	"search-api/repositories"
+	"search-api/rpc"
+	"search-api/services"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+)
+
+const testJWTSecret = "secreto-de-users-api"
+
+// userToken firma un JWT como los de users-api
+func userToken(t *testing.T, userType string) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id":   7,
+		"username":  "lauty",
+		"user_type": userType,
+	}).SignedString([]byte(testJWTSecret))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return token
+}
+
+// echoUsersClient responde VerifyToken como users-api con un token vigente: repite los claims del JWT
+type echoUsersClient struct {
+	rpc.UsersServiceClient
+}
+
+func (echoUsersClient) VerifyToken(ctx context.Context, request *rpc.VerifyTokenRequest, opts ...grpc.CallOption) (*rpc.VerifyTokenResponse, error) {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(request.Token, claims); err != nil {
+		return &rpc.VerifyTokenResponse{Valid: false}, nil
+	}
+	userID, _ := claims["user_id"].(float64)
+	userType, _ := claims["user_type"].(string)
+	return &rpc.VerifyTokenResponse{Valid: true, UserID: uint(userID), UserType: userType}, nil
+}
+
+// newCacheAdmin arma DELETE /admin/cache como en main.go, con Redis en memoria y el caché cargado
+func newCacheAdmin(t *testing.T) (http.Handler, repositories.CacheRepository, *miniredis.Miniredis) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	redis := miniredis.RunT(t)
+	cacheRepo := repositories.NewCacheRepository(repositories.NewRedisCache(repositories.RedisOptions{Addr: redis.Addr(), Timeout: time.Second}), time.Hour)
+	for _, key := range []string{"search:cordoba", "search:mendoza", "suggest:cor"} {
+		cacheRepo.Set(key, dto.SearchResult{Total: 1}, time.Hour)
+	}
+
+	callerAuth := middleware.NewCallerAuth(config.AuthConfig{JWTSecret: testJWTSecret, InternalTokens: []string{"token-interno"}, SignatureMaxSkew: time.Minute, TokenVerifyTimeout: time.Second}, echoUsersClient{})
+	controller := NewCacheController(services.NewCacheService(cacheRepo))
+	return callerAuth.Middleware(http.HandlerFunc(controller.Flush)), cacheRepo, redis
+}
+
+// cached indica en qué niveles sigue la key
+func cached(cacheRepo repositories.CacheRepository, key string) (local, remote bool) {
+	ttl := cacheRepo.TTL(key)
+	return ttl.LocalTTLSeconds != nil, ttl.RemoteTTLSeconds != nil
+}
+
+func TestCacheFlush_RequiresAnAdminOrInternalCaller(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    int
+	}{
+		{name: "anonymous", want: http.StatusForbidden},
+		{name: "regular user", headers: map[string]string{"Authorization": "Bearer " + userToken(t, "cliente")}, want: http.StatusForbidden},
+		{name: "invalid internal token", headers: map[string]string{"X-Internal-Token": "otro"}, want: http.StatusForbidden},
+		{name: "admin", headers: map[string]string{"Authorization": "Bearer " + userToken(t, "admin")}, want: http.StatusOK},
+		{name: "internal service", headers: map[string]string{"X-Internal-Token": "token-interno"}, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, cacheRepo, _ := newCacheAdmin(t)
+			request := httptest.NewRequest(http.MethodDelete, "/admin/cache", nil)
+			for name, value := range tt.headers {
+				request.Header.Set(name, value)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.want {
+				t.Fatalf("expected %d, got %d: %s", tt.want, recorder.Code, recorder.Body.String())
+			}
+			// Un caller rechazado no toca el caché
+			if local, remote := cached(cacheRepo, "search:cordoba"); tt.want == http.StatusForbidden && (!local || !remote) {
+				t.Fatalf("expected the cache untouched after a rejected flush, got local=%v remote=%v", local, remote)
+			}
+		})
+	}
+}
+
+func TestCacheFlush_EmptiesBothLevels(t *testing.T) {
+	handler, cacheRepo, redis := newCacheAdmin(t)
+	// Otra key del mismo Redis que no escribió el caché de búsquedas
+	redis.Set("otra:app", "valor")
+
+	request := httptest.NewRequest(http.MethodDelete, "/admin/cache", nil)
+	request.Header.Set("X-Internal-Token", "token-interno")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	for _, key := range []string{"search:cordoba", "search:mendoza", "suggest:cor"} {
+		if local, remote := cached(cacheRepo, key); local || remote {
+			t.Fatalf("expected %s removed from both levels, got local=%v remote=%v", key, local, remote)
+		}
+	}
+	// Sin pattern se vacía la base de Redis completa (FLUSHDB), no solo las keys de búsquedas
+	if keys := redis.Keys(); len(keys) != 0 {
+		t.Fatalf("expected the Redis database emptied, got %v", keys)
+	}
+	if stats := cacheRepo.Stats(); stats.Flushes != 1 {
+		t.Fatalf("expected the flush counted, got %d", stats.Flushes)
+	}
+}
+
+func TestCacheFlush_PatternOnlyRemovesMatchingKeys(t *testing.T) {
+	handler, cacheRepo, _ := newCacheAdmin(t)
+
+	request := httptest.NewRequest(http.MethodDelete, "/admin/cache?pattern=search:*", nil)
+	request.Header.Set("Authorization", "Bearer "+userToken(t, "admin"))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	var response dto.CacheInvalidationResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil || response.Pattern != "search:*" || response.Deleted != 2 {
+		t.Fatalf("expected 2 keys deleted for search:*, got %s (%v)", recorder.Body.String(), err)
+	}
+	for _, key := range []string{"search:cordoba", "search:mendoza"} {
+		if local, remote := cached(cacheRepo, key); local || remote {
+			t.Fatalf("expected %s removed from both levels, got local=%v remote=%v", key, local, remote)
+		}
+	}
+	if local, remote := cached(cacheRepo, "suggest:cor"); !local || !remote {
+		t.Fatalf("expected suggest:cor kept, got local=%v remote=%v", local, remote)
+	}
+}
+
+func TestCacheFlush_RejectsInvalidRequests(t *testing.T) {
+	handler, _, _ := newCacheAdmin(t)
+	tests := []struct {
+		name   string
+		method string
+		target string
+		want   int
+	}{
+		{name: "wrong method", method: http.MethodPost, target: "/admin/cache", want: http.StatusMethodNotAllowed},
+		{name: "malformed pattern", method: http.MethodDelete, target: "/admin/cache?pattern=search:[", want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(tt.method, tt.target, nil)
+			request.Header.Set("X-Internal-Token", "token-interno")
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			if recorder.Code != tt.want {
+				t.Fatalf("expected %d, got %d: %s", tt.want, recorder.Code, recorder.Body.String())
+			}
+		})
+	}
+}
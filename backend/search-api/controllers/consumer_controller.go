@@ -0,0 +1,37 @@
+package controllers
+
+import (
+	"net/http"
+
+	"search-api/middleware"
+	"search-api/services"
+)
+
+// ConsumerController expone el estado del consumidor de RabbitMQ para admins
+type ConsumerController struct {
+	service services.ConsumerMonitorService
+}
+
+// NewConsumerController crea una nueva instancia del controlador del consumidor
+func NewConsumerController(service services.ConsumerMonitorService) *ConsumerController {
+	return &ConsumerController{
+		service: service,
+	}
+}
+
+// Status maneja GET /admin/consumer/status
+// Retorna la última medición: mensajes en la queue y en las de reintento, demora del mensaje más viejo,
+// throughput y las alertas activas
+func (c *ConsumerController) Status(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if !middleware.IsPrivileged(r.Context()) {
+		writeErrorResponse(w, http.StatusForbidden, "El estado del consumidor requiere un token interno o de administrador")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, c.service.Status())
+}
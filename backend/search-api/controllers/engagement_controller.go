@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"time"
+
+	"search-api/domain"
+	"search-api/dto"
+	"search-api/middleware"
+	"search-api/services"
+)
+
+// searchIDPattern es el formato del searchId que devuelve /search (32 caracteres hexadecimales)
+var searchIDPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// maxClickPosition es la posición más alta que se acepta en un click (100 páginas de 10 resultados)
+const maxClickPosition = 1000
+
+// EngagementController registra las interacciones del usuario con los resultados de búsqueda
+type EngagementController struct {
+	service   services.EngagementService
+	analytics services.AnalyticsService
+}
+
+// NewEngagementController crea una nueva instancia del controlador de interacciones
+func NewEngagementController(service services.EngagementService, analytics services.AnalyticsService) *EngagementController {
+	return &EngagementController{
+		service:   service,
+		analytics: analytics,
+	}
+}
+
+// Click maneja POST /search/click
+// Registra el click en un resultado para el click-through rate; con un JWT válido además cuenta como
+// interacción del usuario para las búsquedas con personalized=true (igual que POST /search/events)
+func (c *EngagementController) Click(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var request dto.ClickRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&request); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Cuerpo inválido: se espera {\"searchId\", \"propertyId\", \"position\"}")
+		return
+	}
+	if !searchIDPattern.MatchString(request.SearchID) {
+		writeErrorResponse(w, http.StatusBadRequest, "searchId inválido")
+		return
+	}
+	if !propertyIDPattern.MatchString(request.PropertyID) {
+		writeErrorResponse(w, http.StatusBadRequest, "ID de propiedad inválido")
+		return
+	}
+	if request.Position < 1 || request.Position > maxClickPosition {
+		writeErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("position debe estar entre 1 y %d", maxClickPosition))
+		return
+	}
+
+	// Los clicks de tooling interno/admin no cuentan, igual que sus búsquedas
+	if middleware.IsPrivileged(r.Context()) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	user, authenticated := middleware.CurrentUser(r.Context())
+	if authenticated {
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		err := c.service.Record(ctx, user.ID, request.PropertyID, domain.EngagementClick)
+		if errors.Is(err, services.ErrPropertyNotFound) {
+			writeErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		// Sin la interacción solo se pierde la personalización: el click cuenta igual para el CTR
+		if err != nil {
+			log.Printf("⚠️ Error registrando click del usuario %d en %s: %v", user.ID, request.PropertyID, err)
+		}
+	}
+
+	c.analytics.RecordClick(r.Context(), domain.ClickEvent{
+		SearchID:   request.SearchID,
+		PropertyID: request.PropertyID,
+		Position:   request.Position,
+		UserID:     user.ID,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Events maneja POST /search/events
+// Registra un click del usuario del JWT en un resultado; se usa en las búsquedas con personalized=true
+func (c *EngagementController) Events(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	user, authenticated := middleware.CurrentUser(r.Context())
+	if !authenticated {
+		writeErrorResponse(w, http.StatusUnauthorized, "Registrar interacciones requiere un token válido")
+		return
+	}
+
+	var request dto.EngagementRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&request); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Cuerpo inválido: se espera {\"propertyId\", \"type\"}")
+		return
+	}
+	if !propertyIDPattern.MatchString(request.PropertyID) {
+		writeErrorResponse(w, http.StatusBadRequest, "ID de propiedad inválido")
+		return
+	}
+	// Las reservas no se aceptan por HTTP: llegan confirmadas desde properties-api
+	if request.Type != domain.EngagementClick {
+		writeErrorResponse(w, http.StatusBadRequest, "type debe ser 'click'")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	err := c.service.Record(ctx, user.ID, request.PropertyID, request.Type)
+	if errors.Is(err, services.ErrPropertyNotFound) {
+		writeErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Error registrando %s del usuario %d en %s: %v", request.Type, user.ID, request.PropertyID, err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error registrando la interacción")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"net/http"
+
+	"search-api/services"
+)
+
+// HealthController maneja los probes de liveness y readiness
+type HealthController struct {
+	service services.HealthService
+}
+
+// NewHealthController crea una nueva instancia del controlador de health checks
+func NewHealthController(service services.HealthService) *HealthController {
+	return &HealthController{
+		service: service,
+	}
+}
+
+// Live maneja GET /health/live
+// Responde 200 mientras el proceso esté vivo, sin chequear dependencias
+func (c *HealthController) Live(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, c.service.Live())
+}
+
+// Ready maneja GET /health/ready
+// Chequea Solr, Memcached y RabbitMQ; responde 503 si alguna dependencia obligatoria no responde
+func (c *HealthController) Ready(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	response, ready := c.service.Ready(r.Context())
+	status := http.StatusOK
+	if !ready {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSONResponse(w, status, response)
+}
@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"search-api/middleware"
+	"search-api/services"
+)
+
+// HistoryController maneja el historial de búsquedas del usuario autenticado
+type HistoryController struct {
+	service services.SearchHistoryService
+}
+
+// NewHistoryController crea una nueva instancia del controlador de historial
+func NewHistoryController(service services.SearchHistoryService) *HistoryController {
+	return &HistoryController{
+		service: service,
+	}
+}
+
+// History maneja el historial de búsquedas:
+//   - GET /search/history?limit=          búsquedas recientes del usuario del JWT
+//   - DELETE /search/history?id=          quita una búsqueda (sin id borra todo el historial)
+//
+// Los callers internos y admins pueden indicar ?userId= (ej: el motor de recomendaciones)
+func (c *HistoryController) History(w http.ResponseWriter, r *http.Request) {
+	userID, ok := c.historyOwner(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		limit := 0
+		if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+			parsed, err := strconv.Atoi(limitStr)
+			if err != nil || parsed < 1 {
+				writeErrorResponse(w, http.StatusBadRequest, "limit debe ser un número entero mayor a 0")
+				return
+			}
+			limit = parsed
+		}
+		history, err := c.service.List(r.Context(), userID, limit)
+		if err != nil {
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSONResponse(w, http.StatusOK, history)
+
+	case http.MethodDelete:
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			if err := c.service.Clear(r.Context(), userID); err != nil {
+				writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if err := c.service.Remove(r.Context(), userID, id); err != nil {
+			if errors.Is(err, services.ErrHistoryEntryNotFound) {
+				writeErrorResponse(w, http.StatusNotFound, err.Error())
+				return
+			}
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// historyOwner resuelve de qué usuario es el historial: el del JWT o, para callers privilegiados, ?userId=
+// Si no se puede resolver escribe la respuesta de error y retorna ok=false
+func (c *HistoryController) historyOwner(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	if userIDStr := r.URL.Query().Get("userId"); userIDStr != "" {
+		if !middleware.IsPrivileged(r.Context()) {
+			writeErrorResponse(w, http.StatusForbidden, "El parámetro userId requiere un token interno o de administrador")
+			return 0, false
+		}
+		userID, err := strconv.ParseUint(userIDStr, 10, 32)
+		if err != nil || userID == 0 {
+			writeErrorResponse(w, http.StatusBadRequest, "userId debe ser un número entero mayor a 0")
+			return 0, false
+		}
+		return uint(userID), true
+	}
+
+	user, authenticated := middleware.CurrentUser(r.Context())
+	if !authenticated {
+		writeErrorResponse(w, http.StatusUnauthorized, "El historial de búsquedas requiere un token válido")
+		return 0, false
+	}
+	return user.ID, true
+}
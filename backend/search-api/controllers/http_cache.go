@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// gzipMinBytes es el tamaño a partir del cual se comprime la respuesta
+// Por debajo el header de gzip y el costo de CPU no compensan lo que se ahorra
+const gzipMinBytes = 1024
+
+// writeCacheableJSON escribe data como JSON con un ETag calculado sobre el body:
+//   - si el If-None-Match del request coincide responde 304 sin body (la búsqueda repetida no viaja de nuevo)
+//   - si el cliente acepta gzip y el body supera gzipMinBytes lo comprime (las páginas de resultados grandes)
+//
+// El ETag es débil (W/"...") porque la misma respuesta puede ir comprimida o no
+func writeCacheableJSON(w http.ResponseWriter, r *http.Request, statusCode int, data interface{}) {
+	writeCacheableJSONWithETagOf(w, r, statusCode, data, nil)
+}
+
+// writeCacheableJSONWithETagOf es writeCacheableJSON con el ETag calculado sobre etagData en lugar del body
+// (etagData nil = sobre el body): así lo que cambia en cada request, como el searchId, no invalida el ETag
+func writeCacheableJSONWithETagOf(w http.ResponseWriter, r *http.Request, statusCode int, data, etagData interface{}) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("⚠️ Error serializando respuesta JSON: %v", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error serializando respuesta")
+		return
+	}
+	body = append(body, '\n')
+
+	etagBody := body
+	if etagData != nil {
+		if etagBody, err = json.Marshal(etagData); err != nil {
+			log.Printf("⚠️ Error serializando respuesta JSON: %v", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Error serializando respuesta")
+			return
+		}
+	}
+	etag := weakETag(etagBody)
+	w.Header().Set("ETag", etag)
+	w.Header().Add("Vary", "Accept-Encoding")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	if len(body) >= gzipMinBytes && acceptsGzip(r.Header.Get("Accept-Encoding")) {
+		var compressed bytes.Buffer
+		writer := gzip.NewWriter(&compressed)
+		writer.Write(body)
+		writer.Close()
+		body = compressed.Bytes()
+		w.Header().Set("Content-Encoding", "gzip")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(statusCode)
+	if _, err := w.Write(body); err != nil {
+		log.Printf("⚠️ Error escribiendo respuesta JSON: %v", err)
+	}
+}
+
+// weakETag calcula el ETag débil de un body (primeros 128 bits del SHA-256)
+func weakETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches compara el header If-None-Match con etag (comparación débil, ver RFC 9110 13.1.2)
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	opaque := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == opaque {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptsGzip indica si el header Accept-Encoding acepta gzip (sin q=0)
+func acceptsGzip(acceptEncoding string) bool {
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		fields := strings.Split(part, ";")
+		coding := strings.ToLower(strings.TrimSpace(fields[0]))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+		rejected := false
+		for _, param := range fields[1:] {
+			param = strings.ReplaceAll(strings.TrimSpace(param), " ", "")
+			if q, found := strings.CutPrefix(param, "q="); found {
+				value, err := strconv.ParseFloat(q, 64)
+				rejected = err != nil || value == 0
+			}
+		}
+		return !rejected
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"search-api/dto"
+	"search-api/middleware"
+	"search-api/services"
+)
+
+// Límites de los parámetros del escenario de carga
+const (
+	defaultLoadTestRate     = 50
+	maxLoadTestRate         = 10000
+	defaultLoadTestDuration = 60
+	maxLoadTestDuration     = 3600
+	defaultLoadTestTargets  = 50
+	maxLoadTestTargets      = 200
+)
+
+// LoadTestController genera escenarios de carga de la búsqueda para admins
+type LoadTestController struct {
+	service services.LoadTestService
+}
+
+// NewLoadTestController crea una nueva instancia del controlador de pruebas de carga
+func NewLoadTestController(service services.LoadTestService) *LoadTestController {
+	return &LoadTestController{
+		service: service,
+	}
+}
+
+// Scenario maneja GET /admin/loadtest/scenario?format=json|k6|vegeta&rate=&duration=&days=&targets=&baseUrl=
+// Arma el escenario con la mezcla de búsquedas de los últimos ?days= días; json incluye la estimación
+// de consultas al índice y de cache hits, k6 y vegeta se descargan listos para correr
+func (c *LoadTestController) Scenario(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if !middleware.IsPrivileged(r.Context()) {
+		writeErrorResponse(w, http.StatusForbidden, "Los escenarios de carga requieren un token interno o de administrador")
+		return
+	}
+
+	query := r.URL.Query()
+
+	format := query.Get("format")
+	if format == "" {
+		format = dto.LoadTestFormatJSON
+	}
+	if format != dto.LoadTestFormatJSON && format != dto.LoadTestFormatK6 && format != dto.LoadTestFormatVegeta {
+		writeErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("format debe ser '%s', '%s' o '%s'",
+			dto.LoadTestFormatJSON, dto.LoadTestFormatK6, dto.LoadTestFormatVegeta))
+		return
+	}
+
+	rate, err := parseBoundedInt(query.Get("rate"), defaultLoadTestRate, maxLoadTestRate)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("rate %v", err))
+		return
+	}
+	duration, err := parseBoundedInt(query.Get("duration"), defaultLoadTestDuration, maxLoadTestDuration)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("duration %v", err))
+		return
+	}
+	days, err := parseBoundedInt(query.Get("days"), defaultAnalyticsDays, maxAnalyticsDays)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("days %v", err))
+		return
+	}
+	targets, err := parseBoundedInt(query.Get("targets"), defaultLoadTestTargets, maxLoadTestTargets)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("targets %v", err))
+		return
+	}
+
+	// Por defecto la prueba apunta a la misma instancia que generó el escenario
+	baseURL := strings.TrimSuffix(query.Get("baseUrl"), "/")
+	if baseURL == "" {
+		baseURL = "http://" + r.Host
+	}
+	if parsed, err := url.Parse(baseURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "baseUrl debe ser una URL http o https")
+		return
+	}
+
+	scenario, err := c.service.Scenario(r.Context(), services.LoadTestOptions{
+		Rate:       rate,
+		Duration:   time.Duration(duration) * time.Second,
+		Window:     time.Duration(days) * 24 * time.Hour,
+		MaxTargets: targets,
+	})
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	switch format {
+	case dto.LoadTestFormatK6:
+		writeLoadTestFile(w, "application/javascript; charset=utf-8", "search-scenario.js", c.service.K6Script(scenario, baseURL))
+	case dto.LoadTestFormatVegeta:
+		writeLoadTestFile(w, "text/plain; charset=utf-8", "search-targets.txt", c.service.VegetaTargets(scenario, baseURL))
+	default:
+		writeJSONResponse(w, http.StatusOK, scenario)
+	}
+}
+
+// writeLoadTestFile escribe el escenario como un archivo para descargar
+func writeLoadTestFile(w http.ResponseWriter, contentType, filename, content string) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, content)
+}
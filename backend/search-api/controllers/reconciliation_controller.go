@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"search-api/middleware"
+	"search-api/services"
+)
+
+// ReconciliationController maneja la reconciliación del índice con properties-api para admins
+type ReconciliationController struct {
+	service services.ReconciliationService
+}
+
+// NewReconciliationController crea una nueva instancia del controlador de reconciliación
+func NewReconciliationController(service services.ReconciliationService) *ReconciliationController {
+	return &ReconciliationController{
+		service: service,
+	}
+}
+
+// Reconcile maneja /admin/reconcile
+// GET retorna el estado y el reporte de la última reconciliación
+// POST inicia una reconciliación en segundo plano (202) o responde 409 si ya hay una en curso
+func (c *ReconciliationController) Reconcile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if !middleware.IsPrivileged(r.Context()) {
+		writeErrorResponse(w, http.StatusForbidden, "La reconciliación del índice requiere un token interno o de administrador")
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		writeJSONResponse(w, http.StatusOK, c.service.Status())
+		return
+	}
+
+	if err := c.service.Trigger(); err != nil {
+		if errors.Is(err, services.ErrReconciliationRunning) {
+			writeErrorResponse(w, http.StatusConflict, err.Error())
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSONResponse(w, http.StatusAccepted, c.service.Status())
+}
+
+// Reindex maneja /admin/reindex
+// GET retorna el estado (el reporte de la última reindexación está en lastRebuild)
+// POST inicia en segundo plano una reindexación completa en un índice sombra (202) o responde 409
+// si ya hay una reconciliación o reindexación en curso
+func (c *ReconciliationController) Reindex(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if !middleware.IsPrivileged(r.Context()) {
+		writeErrorResponse(w, http.StatusForbidden, "La reindexación del índice requiere un token interno o de administrador")
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		writeJSONResponse(w, http.StatusOK, c.service.Status())
+		return
+	}
+
+	if err := c.service.Rebuild(); err != nil {
+		if errors.Is(err, services.ErrReconciliationRunning) {
+			writeErrorResponse(w, http.StatusConflict, err.Error())
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSONResponse(w, http.StatusAccepted, c.service.Status())
+}
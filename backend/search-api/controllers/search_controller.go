@@ -3,6 +3,7 @@ package controllers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -10,19 +11,28 @@ import (
 	"strings"
 	"time"
 
+	"search-api/domain"
 	"search-api/dto"
+	"search-api/middleware"
 	"search-api/services"
 )
 
 // SearchController maneja las peticiones HTTP relacionadas con búsqueda
 type SearchController struct {
-	service services.SearchService
+	service         services.SearchService
+	analytics       services.AnalyticsService
+	personalization services.PersonalizationService
+	history         services.SearchHistoryService
 }
 
 // NewSearchController crea una nueva instancia del controlador de búsqueda
-func NewSearchController(service services.SearchService) *SearchController {
+// personalization e history solo se usan en las búsquedas con un JWT válido
+func NewSearchController(service services.SearchService, analytics services.AnalyticsService, personalization services.PersonalizationService, history services.SearchHistoryService) *SearchController {
 	return &SearchController{
-		service: service,
+		service:         service,
+		analytics:       analytics,
+		personalization: personalization,
+		history:         history,
 	}
 }
 
@@ -46,27 +56,95 @@ func (c *SearchController) Search(w http.ResponseWriter, r *http.Request) {
 	// Validar parámetros
 	if err := validateSearchRequest(request); err != nil {
 		log.Printf("⚠️ Error validando request: %v", err)
-		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		writeValidationErrorResponse(w, err)
+		return
+	}
+
+	// El control del caché está reservado a tooling interno y admins
+	if request.CacheMode != "" && !middleware.IsPrivileged(r.Context()) {
+		writeErrorResponse(w, http.StatusForbidden, "El parámetro cache requiere un token interno o de administrador")
+		return
+	}
+
+	// debug=true expone la consulta al índice, así que también está reservado a tooling interno y admins
+	if request.Debug && !middleware.IsPrivileged(r.Context()) {
+		writeErrorResponse(w, http.StatusForbidden, "El parámetro debug requiere un token interno o de administrador")
 		return
 	}
 
+	// El ranking personalizado necesita saber de quién son los clicks y reservas
+	if user, authenticated := middleware.CurrentUser(r.Context()); authenticated {
+		request.UserID = user.ID
+	}
+
 	// Crear contexto con timeout
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
 
 	// Llamar al servicio
+	start := time.Now()
 	response, err := c.service.Search(ctx, *request)
+	if errors.Is(err, services.ErrHydrationUnavailable) {
+		log.Printf("❌ Error en servicio de búsqueda: %v", err)
+		writeErrorResponse(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
 	if err != nil {
 		log.Printf("❌ Error en servicio de búsqueda: %v", err)
 		writeErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Error en búsqueda: %v", err))
 		return
 	}
 
-	// Escribir respuesta exitosa
-	writeJSONResponse(w, http.StatusOK, response)
+	// Registrar la búsqueda para analytics (las de tooling interno/admin no cuentan)
+	if !middleware.IsPrivileged(r.Context()) {
+		c.analytics.RecordSearch(r.Context(), response.SearchID, *request, response.TotalResults, time.Since(start))
+	}
+
+	// Usuario autenticado: favoritos, moneda y locale preferidos, e historial de búsquedas
+	if user, authenticated := middleware.CurrentUser(r.Context()); authenticated {
+		c.personalization.Personalize(ctx, user.ID, response)
+		if response.Personalization != nil && response.Personalization.Locale != "" {
+			w.Header().Set("Content-Language", response.Personalization.Locale)
+		}
+		c.history.Record(ctx, user.ID, *request, response.TotalResults)
+	}
+
+	// Escribir respuesta exitosa (con ETag y gzip); sin lang el idioma sale de Accept-Language
+	// El ETag no incluye searchId ni meta (cambian en cada request): con un 304 el cliente sigue usando
+	// el searchId de la respuesta que ya tiene
+	w.Header().Add("Vary", "Accept-Language")
+	etagResponse := *response
+	etagResponse.SearchID, etagResponse.Meta = "", nil
+	writeCacheableJSONWithETagOf(w, r, http.StatusOK, response, etagResponse)
 	log.Printf("✅ Búsqueda completada exitosamente: %d resultados", response.TotalResults)
 }
 
+// acceptedSearchLanguage retorna el idioma indexado con mayor preferencia en Accept-Language
+// ("pt-BR,pt;q=0.9,en;q=0.8" → "pt"); vacío si no acepta ninguno
+func acceptedSearchLanguage(header string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		language, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if !domain.IsIndexedLanguage(language) {
+			continue
+		}
+		q := 1.0
+		if value, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		// A igual q gana el primero del header
+		if q > bestQ {
+			best, bestQ = language, q
+		}
+	}
+	return best
+}
+
 // parseSearchRequest parsea los query parameters a SearchRequest
 func parseSearchRequest(r *http.Request) (*dto.SearchRequest, error) {
 	request := &dto.SearchRequest{}
@@ -83,6 +161,9 @@ func parseSearchRequest(r *http.Request) (*dto.SearchRequest, error) {
 	// Country
 	request.Country = query.Get("country")
 
+	// Type (tipo de propiedad, se indexa en minúsculas)
+	request.Type = strings.ToLower(strings.TrimSpace(query.Get("type")))
+
 	// MinPrice
 	if minPriceStr := query.Get("minPrice"); minPriceStr != "" {
 		minPrice, err := strconv.ParseFloat(minPriceStr, 64)
@@ -128,6 +209,99 @@ func parseSearchRequest(r *http.Request) (*dto.SearchRequest, error) {
 		request.MinGuests = minGuests
 	}
 
+	// ChildFriendly, InfantFriendly y las reglas de la casa (solo filtran con true)
+	for name, target := range map[string]*bool{
+		"childFriendly":  &request.ChildFriendly,
+		"infantFriendly": &request.InfantFriendly,
+		"petsAllowed":    &request.PetsAllowed,
+		"smokingAllowed": &request.SmokingAllowed,
+		"eventsAllowed":  &request.EventsAllowed,
+		"instantBook":    &request.InstantBook,
+	} {
+		if raw := query.Get(name); raw != "" {
+			value, err := strconv.ParseBool(raw)
+			if err != nil {
+				return nil, fmt.Errorf("%s debe ser true o false: %w", name, err)
+			}
+			*target = value
+		}
+	}
+
+	// CancellationPolicy (flexible, moderate o strict; se valida con el resto de los parámetros)
+	request.CancellationPolicy = strings.ToLower(strings.TrimSpace(query.Get("cancellationPolicy")))
+
+	// CheckIn y CheckOut (YYYY-MM-DD; se validan con el resto de los parámetros)
+	request.CheckIn = query.Get("checkIn")
+	request.CheckOut = query.Get("checkOut")
+
+	// Lang (opcional, se valida con el resto de los parámetros); sin lang se usa Accept-Language
+	request.Lang = strings.ToLower(strings.TrimSpace(query.Get("lang")))
+	if request.Lang == "" {
+		request.Lang = acceptedSearchLanguage(r.Header.Get("Accept-Language"))
+	}
+
+	// Fields (opcional - campos de cada resultado, ej: "id,title,price,images[0]")
+	request.Fields = query.Get("fields")
+
+	// FacetLimit (opcional - valores por facet)
+	if facetLimitStr := query.Get("facetLimit"); facetLimitStr != "" {
+		facetLimit, err := strconv.Atoi(facetLimitStr)
+		if err != nil {
+			return nil, fmt.Errorf("facetLimit debe ser un número entero válido: %w", err)
+		}
+		request.FacetLimit = facetLimit
+	}
+
+	// Cache (bypass|refresh, solo para callers privilegiados)
+	request.CacheMode = query.Get("cache")
+
+	// Debug (opcional - agrega la consulta al índice y los tiempos de cada etapa, solo para callers privilegiados)
+	if debugStr := query.Get("debug"); debugStr != "" {
+		debug, err := strconv.ParseBool(debugStr)
+		if err != nil {
+			return nil, fmt.Errorf("debug debe ser true o false: %w", err)
+		}
+		request.Debug = debug
+	}
+
+	// Hydrate (opcional - precios y disponibilidad al día desde properties-api)
+	if hydrateStr := query.Get("hydrate"); hydrateStr != "" {
+		hydrate, err := strconv.ParseBool(hydrateStr)
+		if err != nil {
+			return nil, fmt.Errorf("hydrate debe ser true o false: %w", err)
+		}
+		request.Hydrate = hydrate
+	}
+
+	// Personalized (opcional - reordena según los clicks y reservas del usuario del JWT)
+	if personalizedStr := query.Get("personalized"); personalizedStr != "" {
+		personalized, err := strconv.ParseBool(personalizedStr)
+		if err != nil {
+			return nil, fmt.Errorf("personalized debe ser true o false: %w", err)
+		}
+		request.Personalized = personalized
+	}
+
+	// Bounding box (búsqueda por mapa)
+	bboxParams := []struct {
+		name  string
+		value **float64
+	}{
+		{"bboxMinLat", &request.BboxMinLat},
+		{"bboxMinLng", &request.BboxMinLng},
+		{"bboxMaxLat", &request.BboxMaxLat},
+		{"bboxMaxLng", &request.BboxMaxLng},
+	}
+	for _, param := range bboxParams {
+		if valueStr := query.Get(param.name); valueStr != "" {
+			value, err := strconv.ParseFloat(valueStr, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s debe ser un número válido: %w", param.name, err)
+			}
+			*param.value = &value
+		}
+	}
+
 	// Page
 	if pageStr := query.Get("page"); pageStr != "" {
 		page, err := strconv.Atoi(pageStr)
@@ -151,50 +325,21 @@ func parseSearchRequest(r *http.Request) (*dto.SearchRequest, error) {
 	}
 
 	// SortBy (opcional - sin default)
+	// Acepta varios criterios separados por comas (ej: "popularity desc,price asc")
 	request.SortBy = query.Get("sortBy")
-	// Si no se especifica, se deja vacío (sort opcional)
 
-	// SortOrder (opcional - solo se usa si SortBy está especificado)
+	// SortOrder (opcional - orden de los criterios de SortBy que no lo indican)
+	// Si no se especifica, cada campo usa su orden por defecto (popularity desc, el resto asc)
 	request.SortOrder = strings.ToLower(query.Get("sortOrder"))
-	if request.SortOrder == "" {
-		request.SortOrder = "asc" // Default solo si SortBy está especificado
-	}
 
 	return request, nil
 }
 
-// validateSearchRequest valida los parámetros de búsqueda
+// validateSearchRequest limpia y valida los parámetros de búsqueda (tags validate de dto.SearchRequest)
+// Los errores de validación son *services.ValidationError con el detalle por parámetro
 func validateSearchRequest(request *dto.SearchRequest) error {
-	// Validar Page
-	if request.Page < 1 {
-		return fmt.Errorf("page debe ser mayor o igual a 1")
-	}
-
-	// Validar PageSize
-	if request.PageSize <= 0 {
-		return fmt.Errorf("pageSize debe ser mayor a 0")
-	}
-	if request.PageSize > 100 {
-		return fmt.Errorf("pageSize no puede ser mayor a 100")
-	}
-
-	// Validar rango de precio
-	if request.MinPrice < 0 {
-		return fmt.Errorf("minPrice no puede ser negativo")
-	}
-	if request.MaxPrice < 0 {
-		return fmt.Errorf("maxPrice no puede ser negativo")
-	}
-	if request.MinPrice > 0 && request.MaxPrice > 0 && request.MinPrice > request.MaxPrice {
-		return fmt.Errorf("minPrice no puede ser mayor que maxPrice")
-	}
-
-	// Validar SortOrder
-	if request.SortOrder != "" && request.SortOrder != "asc" && request.SortOrder != "desc" {
-		return fmt.Errorf("sortOrder debe ser 'asc' o 'desc'")
-	}
-
-	return nil
+	services.SanitizeSearchRequest(request)
+	return services.ValidateSearchRequest(request)
 }
 
 // writeJSONResponse escribe una respuesta JSON exitosa
@@ -227,3 +372,22 @@ func writeErrorResponse(w http.ResponseWriter, statusCode int, message string) {
 	}
 }
 
+// writeValidationErrorResponse escribe un 400 con el detalle de cada parámetro inválido
+func writeValidationErrorResponse(w http.ResponseWriter, err error) {
+	var validationErr *services.ValidationError
+	if !errors.As(err, &validationErr) {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	errorResponse := dto.ErrorResponse{
+		Error:   "Parámetros de búsqueda inválidos: " + validationErr.Error(),
+		Code:    http.StatusBadRequest,
+		Details: validationErr.Fields,
+	}
+	if err := json.NewEncoder(w).Encode(errorResponse); err != nil {
+		log.Printf("⚠️ Error escribiendo respuesta de error: %v", err)
+	}
+}
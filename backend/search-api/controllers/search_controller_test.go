@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"search-api/dto"
+)
+
+func TestSearch_RejectsMalformedAndInvalidParameters(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		// field es el parámetro que se espera en el detalle (vacío si el error es de parseo)
+		field string
+	}{
+		{name: "min price not a number", query: "minPrice=barato"},
+		{name: "bedrooms not an integer", query: "bedrooms=2.5"},
+		{name: "page not an integer", query: "page=uno"},
+		{name: "bounding box not a number", query: "bboxMinLat=norte"},
+		{name: "boolean filter", query: "petsAllowed=quizas"},
+		{name: "price range", query: "minPrice=200&maxPrice=100", field: "minPrice"},
+		{name: "page size too big", query: "pageSize=1000", field: "pageSize"},
+		{name: "page size zero", query: "pageSize=0", field: "pageSize"},
+		{name: "sort injection", query: "sortBy=price%3Bdrop", field: "sortBy"},
+		{name: "incomplete stay", query: "checkIn=2025-03-01", field: "checkIn"},
+	}
+	controller := &SearchController{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			controller.Search(recorder, httptest.NewRequest(http.MethodGet, "/search?"+tt.query, nil))
+
+			if recorder.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", recorder.Code, recorder.Body.String())
+			}
+			var response dto.ErrorResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+				t.Fatalf("invalid error body: %v", err)
+			}
+			if response.Code != http.StatusBadRequest || response.Error == "" {
+				t.Fatalf("expected an error message with code 400, got %+v", response)
+			}
+			if tt.field == "" {
+				return
+			}
+			for _, detail := range response.Details {
+				if detail.Field == tt.field {
+					return
+				}
+			}
+			t.Fatalf("expected a detail for %s, got %+v", tt.field, response.Details)
+		})
+	}
+}
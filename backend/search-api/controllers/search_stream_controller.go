@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"search-api/services"
+)
+
+// SearchStreamController maneja las suscripciones SSE a búsquedas
+type SearchStreamController struct {
+	stream    services.SearchStreamService
+	heartbeat time.Duration
+}
+
+// NewSearchStreamController crea una nueva instancia del controlador de streams de búsqueda
+// heartbeat es cada cuánto se envía un comentario SSE para mantener viva la conexión
+func NewSearchStreamController(stream services.SearchStreamService, heartbeat time.Duration) *SearchStreamController {
+	if heartbeat <= 0 {
+		heartbeat = 15 * time.Second
+	}
+	return &SearchStreamController{
+		stream:    stream,
+		heartbeat: heartbeat,
+	}
+}
+
+// Stream maneja GET /search/stream
+// Acepta los mismos filtros que GET /search y mantiene abierta una respuesta text/event-stream:
+// cada propiedad creada o actualizada que coincide con la búsqueda llega como un evento
+// (event: property.created | property.updated, data: {"type", "property"})
+func (c *SearchStreamController) Stream(w http.ResponseWriter, r *http.Request) {
+	// Solo permitir método GET
+	if r.Method != http.MethodGet {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	// Parsear y validar los filtros igual que en GET /search
+	request, err := parseSearchRequest(r)
+	if err != nil {
+		log.Printf("⚠️ Error parseando query parameters: %v", err)
+		writeErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Error parseando parámetros: %v", err))
+		return
+	}
+	if err := validateSearchRequest(request); err != nil {
+		log.Printf("⚠️ Error validando request: %v", err)
+		writeValidationErrorResponse(w, err)
+		return
+	}
+
+	// El servidor tiene WriteTimeout: el stream lo desactiva para su conexión
+	// Sin Flush no hay streaming posible (ej: un middleware que no lo soporta)
+	controller := http.NewResponseController(w)
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("❌ El ResponseWriter no permite desactivar el WriteTimeout: %v", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Streaming no soportado")
+		return
+	}
+
+	subscription, err := c.stream.Subscribe(*request)
+	if err != nil {
+		if errors.Is(err, services.ErrTooManySubscriptions) || errors.Is(err, services.ErrStreamClosed) {
+			log.Printf("⚠️ Suscripción rechazada: %v", err)
+			writeErrorResponse(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer c.stream.Unsubscribe(subscription)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// Evita que nginx acumule los eventos en su buffer
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// El primer comentario confirma la suscripción (el cliente recibe los headers de inmediato)
+	fmt.Fprint(w, ": suscripto\n\n")
+	if err := controller.Flush(); err != nil {
+		log.Printf("❌ Error enviando el inicio del stream: %v", err)
+		return
+	}
+	log.Printf("📡 Stream de búsqueda abierto - query: '%s'", request.Query)
+
+	ticker := time.NewTicker(c.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			log.Printf("👋 Stream de búsqueda cerrado por el cliente (%d eventos descartados)", subscription.Dropped())
+			return
+
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			if err := controller.Flush(); err != nil {
+				return
+			}
+
+		case event, ok := <-subscription.Events():
+			// El canal se cierra en el shutdown del servidor
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				log.Printf("⚠️ Error serializando evento del stream: %v", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\nid: %s\ndata: %s\n\n", event.Type, event.Property.ID, data); err != nil {
+				return
+			}
+			if err := controller.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
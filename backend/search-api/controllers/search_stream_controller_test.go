@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"search-api/domain"
+	"search-api/dto"
+	"search-api/services"
+)
+
+// readSSEBlock lee un bloque del stream (las líneas hasta la línea vacía que lo termina)
+func readSSEBlock(t *testing.T, reader *bufio.Reader) []string {
+	t.Helper()
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("stream ended before the block finished: %v (read %q)", err, lines)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return lines
+		}
+		lines = append(lines, line)
+	}
+}
+
+// openSearchStream abre GET /search/stream contra un servidor real (el stream necesita Flush y SetWriteDeadline)
+func openSearchStream(t *testing.T, stream services.SearchStreamService, query string) *http.Response {
+	t.Helper()
+	controller := NewSearchStreamController(stream, time.Hour)
+	server := httptest.NewServer(http.HandlerFunc(controller.Stream))
+	t.Cleanup(server.Close)
+
+	response, err := http.Get(server.URL + "/search/stream?" + query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { response.Body.Close() })
+	return response
+}
+
+func TestSearchStream_SendsMatchingPropertiesAsEvents(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	stream := services.NewSearchStreamService(0, 4)
+	t.Cleanup(stream.Close)
+	response := openSearchStream(t, stream, "city=Mendoza&fields=id,title")
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", response.StatusCode)
+	}
+	if contentType := response.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Fatalf("expected text/event-stream, got %q", contentType)
+	}
+
+	// El primer comentario confirma que la suscripción ya está registrada
+	reader := bufio.NewReader(response.Body)
+	if block := readSSEBlock(t, reader); len(block) != 1 || !strings.HasPrefix(block[0], ":") {
+		t.Fatalf("expected the subscription comment, got %q", block)
+	}
+
+	stream.Notify(dto.SearchStreamPropertyCreated, domain.Property{ID: "p9", Title: "Cabaña en Córdoba", City: "Córdoba"})
+	stream.Notify(dto.SearchStreamPropertyUpdated, domain.Property{ID: "p1", Title: "Loft en Mendoza", City: "Mendoza", PricePerNight: 90})
+
+	block := readSSEBlock(t, reader)
+	if len(block) != 3 || block[0] != "event: property.updated" || block[1] != "id: p1" || !strings.HasPrefix(block[2], "data: ") {
+		t.Fatalf("expected the Mendoza property as the first event, got %q", block)
+	}
+	var event struct {
+		Type     string                 `json:"type"`
+		Property map[string]interface{} `json:"property"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(block[2], "data: ")), &event); err != nil {
+		t.Fatalf("invalid event data: %v", err)
+	}
+	// fields recorta la propiedad igual que en GET /search
+	if event.Type != dto.SearchStreamPropertyUpdated || event.Property["title"] != "Loft en Mendoza" || len(event.Property) != 2 {
+		t.Fatalf("expected the projected property, got %+v", event)
+	}
+}
+
+func TestSearchStream_EndsOnShutdown(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	stream := services.NewSearchStreamService(0, 1)
+	response := openSearchStream(t, stream, "")
+	reader := bufio.NewReader(response.Body)
+	readSSEBlock(t, reader)
+
+	stream.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := io.ReadAll(reader)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected the stream to end cleanly, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the stream to end after Close")
+	}
+}
+
+func TestSearchStream_RejectsSubscriptions(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	tests := []struct {
+		name       string
+		query      string
+		setup      func(stream services.SearchStreamService)
+		wantStatus int
+	}{
+		{name: "invalid filters", query: "minPrice=barato", wantStatus: http.StatusBadRequest},
+		{name: "unknown field", query: "fields=password", wantStatus: http.StatusBadRequest},
+		{
+			name:       "too many subscriptions",
+			setup:      func(stream services.SearchStreamService) { stream.Subscribe(dto.SearchRequest{}) },
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{name: "shutting down", setup: func(stream services.SearchStreamService) { stream.Close() }, wantStatus: http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := services.NewSearchStreamService(1, 1)
+			if tt.setup != nil {
+				tt.setup(stream)
+			}
+
+			response := openSearchStream(t, stream, tt.query)
+			if response.StatusCode != tt.wantStatus {
+				t.Fatalf("expected %d, got %d", tt.wantStatus, response.StatusCode)
+			}
+		})
+	}
+}
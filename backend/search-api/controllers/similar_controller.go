@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"search-api/services"
+)
+
+// defaultSimilarLimit es la cantidad por defecto de propiedades similares
+const defaultSimilarLimit = 6
+
+// maxSimilarLimit es la cantidad máxima de propiedades similares que se pueden pedir
+const maxSimilarLimit = 20
+
+// propertyIDPattern valida el ID de la URL (también se usa en la key de caché)
+var propertyIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// SimilarController maneja las recomendaciones de propiedades similares
+type SimilarController struct {
+	service services.SimilarService
+}
+
+// NewSimilarController crea una nueva instancia del controlador de propiedades similares
+func NewSimilarController(service services.SimilarService) *SimilarController {
+	return &SimilarController{
+		service: service,
+	}
+}
+
+// Similar maneja GET /search/similar/:propertyId?limit=
+// Retorna las propiedades parecidas para la página de detalle de una propiedad
+func (c *SimilarController) Similar(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	propertyID := strings.TrimPrefix(r.URL.Path, "/search/similar/")
+	if !propertyIDPattern.MatchString(propertyID) {
+		writeErrorResponse(w, http.StatusBadRequest, "ID de propiedad inválido")
+		return
+	}
+
+	limit := defaultSimilarLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > maxSimilarLimit {
+			writeErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("limit debe ser un número entre 1 y %d", maxSimilarLimit))
+			return
+		}
+		limit = parsed
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	response, err := c.service.Similar(ctx, propertyID, limit)
+	if errors.Is(err, services.ErrPropertyNotFound) {
+		writeErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Error buscando propiedades similares a %s: %v", propertyID, err)
+		writeErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Error buscando propiedades similares: %v", err))
+		return
+	}
+
+	writeCacheableJSON(w, r, http.StatusOK, response)
+}
@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"search-api/domain"
+	"search-api/dto"
+	"search-api/services"
+)
+
+// stubSimilarService registra los parámetros recibidos y responde con err o con una propiedad similar
+type stubSimilarService struct {
+	err        error
+	propertyID string
+	limit      int
+}
+
+func (s *stubSimilarService) Similar(ctx context.Context, propertyID string, limit int) (*dto.SimilarPropertiesResponse, error) {
+	s.propertyID, s.limit = propertyID, limit
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &dto.SimilarPropertiesResponse{PropertyID: propertyID, Results: []domain.Property{{ID: "p2"}}}, nil
+}
+
+func TestSimilar_Endpoint(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	tests := []struct {
+		name       string
+		path       string
+		err        error
+		wantStatus int
+		wantLimit  int
+	}{
+		{name: "default limit", path: "/search/similar/6650f1c2a1b2", wantStatus: http.StatusOK, wantLimit: defaultSimilarLimit},
+		{name: "explicit limit", path: "/search/similar/6650f1c2a1b2?limit=3", wantStatus: http.StatusOK, wantLimit: 3},
+		{name: "limit too big", path: "/search/similar/6650f1c2a1b2?limit=21", wantStatus: http.StatusBadRequest},
+		{name: "limit not a number", path: "/search/similar/6650f1c2a1b2?limit=muchas", wantStatus: http.StatusBadRequest},
+		// El ID va en la key de caché: no se aceptan separadores ni comodines
+		{name: "id with a cache separator", path: "/search/similar/p1:*", wantStatus: http.StatusBadRequest},
+		{name: "missing id", path: "/search/similar/", wantStatus: http.StatusBadRequest},
+		{name: "property not indexed", path: "/search/similar/p9", err: services.ErrPropertyNotFound, wantStatus: http.StatusNotFound},
+		{name: "index down", path: "/search/similar/p1", err: errors.New("solr caído"), wantStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &stubSimilarService{err: tt.err}
+			recorder := httptest.NewRecorder()
+			NewSimilarController(service).Similar(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, recorder.Code, recorder.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if service.propertyID != "6650f1c2a1b2" || service.limit != tt.wantLimit {
+				t.Fatalf("expected 6650f1c2a1b2 with limit %d, got %s with %d", tt.wantLimit, service.propertyID, service.limit)
+			}
+			var response dto.SimilarPropertiesResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+				t.Fatalf("invalid JSON body: %v", err)
+			}
+			if response.PropertyID != "6650f1c2a1b2" || len(response.Results) != 1 || recorder.Header().Get("ETag") == "" {
+				t.Fatalf("expected a cacheable response for 6650f1c2a1b2, got %+v", response)
+			}
+		})
+	}
+}
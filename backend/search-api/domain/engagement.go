@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+const (
+	// EngagementClick es un click en un resultado de búsqueda (POST /search/events)
+	EngagementClick = "click"
+
+	// EngagementBooking es una reserva confirmada (evento "booking.confirmed" de properties-api)
+	EngagementBooking = "booking"
+)
+
+// Engagement representa una interacción de un usuario con una propiedad
+// Se usa para reordenar las búsquedas personalizadas hacia propiedades parecidas
+type Engagement struct {
+	// Type es el tipo de interacción: EngagementClick o EngagementBooking
+	Type string `json:"type"`
+
+	// Property es la propiedad tal como estaba indexada al momento de la interacción
+	// Se guarda completa para comparar sus atributos sin volver a consultar el índice
+	Property Property `json:"property"`
+
+	// OccurredAt es el momento de la interacción (UTC)
+	OccurredAt time.Time `json:"occurredAt"`
+}
@@ -1,6 +1,10 @@
 package domain
 
-import "time"
+import (
+	"sort"
+	"strings"
+	"time"
+)
 
 // Property representa una propiedad de alquiler tipo Airbnb
 type Property struct {
@@ -13,12 +17,26 @@ type Property struct {
 	// Description contiene la descripción detallada de la propiedad
 	Description string `json:"description"`
 
+	// Language es el idioma del título y la descripción (vacío = español) y Translations esos textos
+	// en otros idiomas, por locale ("en", "pt-BR")
+	Language     string                 `json:"language,omitempty"`
+	Translations map[string]Translation `json:"translations,omitempty"`
+
 	// City es la ciudad donde se encuentra la propiedad
 	City string `json:"city"`
 
 	// Country es el país donde se encuentra la propiedad
 	Country string `json:"country"`
 
+	// PropertyType es el tipo de propiedad (casa, apartamento, cabaña, loft...)
+	PropertyType string `json:"propertyType,omitempty"`
+
+	// Latitude es la latitud de la propiedad (0 si no tiene coordenadas)
+	Latitude float64 `json:"latitude,omitempty"`
+
+	// Longitude es la longitud de la propiedad (0 si no tiene coordenadas)
+	Longitude float64 `json:"longitude,omitempty"`
+
 	// PricePerNight es el precio por noche de la propiedad
 	PricePerNight float64 `json:"pricePerNight"`
 
@@ -31,15 +49,134 @@ type Property struct {
 	// MaxGuests es la cantidad máxima de huéspedes que puede alojar la propiedad
 	MaxGuests int `json:"maxGuests"`
 
-	// Images es una lista de URLs de imágenes de la propiedad
+	// Images es una lista de URLs de imágenes de la propiedad, en el orden de la galería
 	Images []string `json:"images"`
 
+	// CoverImage es la URL de la foto de portada (la miniatura de los resultados)
+	CoverImage string `json:"coverImage,omitempty"`
+
+	// CoverThumbnail y CoverThumbnailWebP son la miniatura de la portada en JPEG y WebP
+	// (vacías si la portada no es una foto subida o todavía se está procesando)
+	CoverThumbnail     string `json:"coverThumbnail,omitempty"`
+	CoverThumbnailWebP string `json:"coverThumbnailWebp,omitempty"`
+
 	// OwnerID es el identificador del usuario propietario de la propiedad
 	OwnerID uint `json:"ownerID"`
 
 	// Available indica si la propiedad está disponible para reserva
 	Available bool `json:"available"`
 
+	// ChildFriendly indica que la propiedad es apta para niños
+	ChildFriendly bool `json:"childFriendly"`
+
+	// InfantFriendly indica que la propiedad es apta para bebés
+	InfantFriendly bool `json:"infantFriendly"`
+
+	// PetsAllowed, SmokingAllowed y EventsAllowed son las reglas de la casa que se pueden filtrar
+	PetsAllowed    bool `json:"petsAllowed"`
+	SmokingAllowed bool `json:"smokingAllowed"`
+	EventsAllowed  bool `json:"eventsAllowed"`
+
+	// InstantBook indica que las reservas se confirman sin aprobación del anfitrión
+	InstantBook bool `json:"instantBook"`
+
+	// CancellationPolicy es la política de cancelación (flexible, moderate o strict)
+	CancellationPolicy string `json:"cancellationPolicy,omitempty"`
+
+	// Popularity es la cantidad de vistas de la propiedad (se usa con sortBy=popularity)
+	Popularity int64 `json:"popularity"`
+
+	// BookedRanges son los rangos de días ocupados (reservas activas y bloqueos) y NextAvailableDate el
+	// primer día libre (YYYY-MM-DD), según el último "availability.changed"; se usan con checkIn y checkOut
+	BookedRanges      []DateRange `json:"bookedRanges,omitempty"`
+	NextAvailableDate string      `json:"nextAvailableDate,omitempty"`
+
+	// Score es la relevancia que asignó el índice; solo se pide en las búsquedas con hydrate=true
+	Score float64 `json:"score,omitempty"`
+
 	// CreatedAt es la fecha y hora de creación del registro
 	CreatedAt time.Time `json:"createdAt"`
+
+	// UpdatedAt es la fecha de la última modificación en properties-api (la usa la reconciliación del índice)
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// DefaultLanguage es el idioma del texto de las propiedades que no lo indican
+const DefaultLanguage = "es"
+
+// IndexedLanguages son los idiomas que el índice analiza con reglas propias (stemming, stopwords)
+// El texto en otros idiomas se muestra pero no se busca
+var IndexedLanguages = []string{"es", "en", "pt", "fr", "de", "it"}
+
+// IsIndexedLanguage indica si el idioma está en IndexedLanguages
+func IsIndexedLanguage(language string) bool {
+	for _, indexed := range IndexedLanguages {
+		if indexed == language {
+			return true
+		}
+	}
+	return false
+}
+
+// Translation es el título y la descripción de la propiedad en otro idioma
+type Translation struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// TextIn retorna el título y la descripción en el idioma indicado ("en", sin región)
+// Prueba el idioma principal, después la traducción con ese locale exacto y por último
+// la primera (en orden alfabético) con el mismo idioma y alguna región ("pt-BR")
+func (p Property) TextIn(language string) (Translation, bool) {
+	if localeLanguage(p.Language) == language {
+		return Translation{Title: p.Title, Description: p.Description}, true
+	}
+	if translation, ok := p.Translations[language]; ok {
+		return translation, true
+	}
+	locales := make([]string, 0, len(p.Translations))
+	for locale := range p.Translations {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	for _, locale := range locales {
+		if localeLanguage(locale) == language {
+			return p.Translations[locale], true
+		}
+	}
+	return Translation{}, false
+}
+
+// Localized retorna la propiedad con el título y la descripción en el idioma indicado, si los tiene
+func (p Property) Localized(language string) Property {
+	if language == "" || localeLanguage(p.Language) == language {
+		return p
+	}
+	translation, ok := p.TextIn(language)
+	if !ok {
+		return p
+	}
+	p.Title, p.Description, p.Language = translation.Title, translation.Description, language
+	return p
+}
+
+// localeLanguage retorna el idioma de un locale ("pt-BR" → "pt"); vacío es el idioma por defecto
+func localeLanguage(locale string) string {
+	if locale == "" {
+		return DefaultLanguage
+	}
+	language, _, _ := strings.Cut(locale, "-")
+	return language
+}
+
+// DateRange es un rango de días locales de la propiedad (YYYY-MM-DD); End es exclusivo (día de check-out)
+type DateRange struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// Overlaps indica si el rango ocupa alguna de las noches de la estadía [checkIn, checkOut)
+// Las fechas YYYY-MM-DD se comparan como texto: el orden alfabético es el cronológico
+func (r DateRange) Overlaps(checkIn, checkOut string) bool {
+	return r.Start < checkOut && checkIn < r.End
 }
@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// SearchEvent representa una búsqueda realizada por un usuario
+// Se registra para analizar las búsquedas populares y las que no devuelven resultados
+type SearchEvent struct {
+	// SearchID es el searchId de la respuesta; une la búsqueda con los clicks en sus resultados
+	SearchID string `json:"searchId,omitempty"`
+
+	// Query es el término de búsqueda normalizado (minúsculas, sin espacios repetidos)
+	Query string `json:"query"`
+
+	// Filters son los filtros aplicados en la búsqueda (city, country, minPrice, etc.)
+	Filters map[string]string `json:"filters,omitempty"`
+
+	// ResultCount es la cantidad total de resultados que devolvió la búsqueda
+	ResultCount int `json:"resultCount"`
+
+	// Latency es el tiempo que tardó la búsqueda
+	Latency time.Duration `json:"latency"`
+
+	// SearchedAt es el momento en que se realizó la búsqueda (UTC)
+	SearchedAt time.Time `json:"searchedAt"`
+}
+
+// ClickEvent representa un click en un resultado de una búsqueda (POST /search/click)
+// Se une con la búsqueda por SearchID para medir el click-through rate y la posición de los clicks
+type ClickEvent struct {
+	// SearchID es el searchId de la respuesta en la que estaba el resultado
+	SearchID string `json:"searchId"`
+
+	// PropertyID es la propiedad clickeada
+	PropertyID string `json:"propertyId"`
+
+	// Position es la posición del resultado en la búsqueda, desde 1 (contando las páginas anteriores)
+	Position int `json:"position"`
+
+	// UserID es el usuario del JWT (0 en los clicks anónimos)
+	UserID uint `json:"userId,omitempty"`
+
+	// ClickedAt es el momento del click (UTC)
+	ClickedAt time.Time `json:"clickedAt"`
+}
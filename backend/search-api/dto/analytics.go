@@ -0,0 +1,109 @@
+package dto
+
+import "time"
+
+// QueryStat representa las estadísticas agregadas de un término de búsqueda
+type QueryStat struct {
+	// Query es el término de búsqueda normalizado
+	Query string `json:"query"`
+
+	// Filters son los filtros con los que se buscó (solo en el reporte de búsquedas sin resultados)
+	Filters map[string]string `json:"filters,omitempty"`
+
+	// Count es la cantidad de veces que se buscó
+	Count int `json:"count"`
+
+	// ZeroResultCount es la cantidad de veces que la búsqueda no devolvió resultados
+	ZeroResultCount int `json:"zeroResultCount"`
+
+	// AvgResults es el promedio de resultados devueltos
+	AvgResults float64 `json:"avgResults"`
+
+	// AvgLatencyMs es la latencia promedio en milisegundos
+	AvgLatencyMs float64 `json:"avgLatencyMs"`
+
+	// LastSearchedAt es la última vez que se buscó
+	LastSearchedAt time.Time `json:"lastSearchedAt"`
+}
+
+// QueryStatsResponse representa un reporte de analytics de búsqueda
+type QueryStatsResponse struct {
+	// From y To delimitan la ventana de tiempo del reporte
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+
+	// TotalSearches es la cantidad de búsquedas registradas en la ventana
+	TotalSearches int `json:"totalSearches"`
+
+	// Queries son los términos del reporte, ordenados por cantidad de búsquedas
+	Queries []QueryStat `json:"queries"`
+}
+
+// ClickStatsResponse es el reporte de clicks en los resultados de búsqueda
+type ClickStatsResponse struct {
+	// From y To delimitan la ventana de tiempo del reporte
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+
+	// TotalSearches son las búsquedas registradas en la ventana y ClickedSearches las que tuvieron algún click
+	TotalSearches   int `json:"totalSearches"`
+	ClickedSearches int `json:"clickedSearches"`
+
+	// ClickThroughRate es ClickedSearches / TotalSearches (0 sin búsquedas)
+	ClickThroughRate float64 `json:"clickThroughRate"`
+
+	// TotalClicks son todos los clicks de la ventana, aunque su búsqueda ya no esté registrada
+	TotalClicks int `json:"totalClicks"`
+
+	// AvgClickPosition es la posición promedio de los clicks (1 = primer resultado)
+	AvgClickPosition float64 `json:"avgClickPosition"`
+
+	// Positions son los clicks por posición, de la primera a la última con clicks
+	Positions []PositionClickStat `json:"positions"`
+
+	// Properties son las propiedades más clickeadas
+	Properties []PropertyClickStat `json:"properties"`
+}
+
+// PositionClickStat es la cantidad de clicks en una posición de los resultados
+type PositionClickStat struct {
+	Position int `json:"position"`
+	Clicks   int `json:"clicks"`
+}
+
+// PropertyClickStat es la cantidad de clicks que recibió una propiedad
+type PropertyClickStat struct {
+	PropertyID string `json:"propertyId"`
+	Clicks     int    `json:"clicks"`
+
+	// AvgPosition es la posición promedio en la que se la clickeó
+	AvgPosition float64 `json:"avgPosition"`
+}
+
+// DailyActivityResponse son las búsquedas y clicks por día UTC (lo consume el panel de admin de properties-api)
+type DailyActivityResponse struct {
+	// From y To delimitan la ventana de tiempo del reporte
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+
+	// CompleteSince es desde cuándo la réplica tiene todos los eventos (arranque o buffer lleno)
+	// Los días que empiezan antes están incompletos
+	CompleteSince time.Time `json:"completeSince"`
+
+	// Days son los días de la ventana del más antiguo al más reciente, incluidos los días sin actividad
+	Days []DailyActivity `json:"days"`
+}
+
+// DailyActivity es la actividad de búsqueda de un día UTC
+type DailyActivity struct {
+	// Date es el día (YYYY-MM-DD)
+	Date string `json:"date"`
+
+	// Searches son las búsquedas del día y ZeroResultSearches las que no devolvieron resultados
+	Searches           int `json:"searches"`
+	ZeroResultSearches int `json:"zeroResultSearches"`
+
+	// ClickedSearches son las búsquedas del día con algún click y Clicks todos los clicks del día
+	ClickedSearches int `json:"clickedSearches"`
+	Clicks          int `json:"clicks"`
+}
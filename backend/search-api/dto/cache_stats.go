@@ -0,0 +1,78 @@
+package dto
+
+// CacheStats representa las métricas del caché de búsquedas de dos niveles
+// (local + remoto, Memcached o Redis según CACHE_BACKEND)
+// Los contadores son acumulados desde que arrancó el proceso
+type CacheStats struct {
+	// Backend es el caché remoto configurado ("memcached" o "redis")
+	Backend string `json:"backend"`
+
+	// Hits es la cantidad de lecturas encontradas en algún nivel del caché
+	Hits uint64 `json:"hits"`
+
+	// Misses es la cantidad de lecturas que no se encontraron en ningún nivel
+	Misses uint64 `json:"misses"`
+
+	// HitRatio es Hits / (Hits + Misses), 0 si todavía no hubo lecturas
+	HitRatio float64 `json:"hitRatio"`
+
+	// LocalHits y RemoteHits separan los hits por nivel
+	LocalHits  uint64 `json:"localHits"`
+	RemoteHits uint64 `json:"remoteHits"`
+
+	// RemoteErrors es la cantidad de errores al leer o escribir en el caché remoto
+	RemoteErrors uint64 `json:"remoteErrors"`
+
+	// Sets es la cantidad de resultados guardados en el caché
+	Sets uint64 `json:"sets"`
+
+	// Flushes es la cantidad de vaciados completos pedidos por un admin
+	Flushes uint64 `json:"flushes"`
+
+	// Local son los datos del caché en memoria del proceso
+	Local LocalCacheStats `json:"local"`
+
+	// Remote son los datos reportados por el servidor del caché remoto; nil si no respondió
+	Remote *RemoteCacheStats `json:"remote,omitempty"`
+}
+
+// LocalCacheStats representa el estado del caché local (ccache)
+type LocalCacheStats struct {
+	// Items es la cantidad de entradas en el caché local
+	Items int `json:"items"`
+
+	// Evictions es la cantidad de entradas descartadas por falta de espacio
+	Evictions uint64 `json:"evictions"`
+}
+
+// RemoteCacheStats representa los datos que reporta el servidor del caché remoto
+type RemoteCacheStats struct {
+	// Items es la cantidad de entradas guardadas en el servidor
+	Items uint64 `json:"items"`
+
+	// Evictions es la cantidad de entradas descartadas por falta de memoria
+	Evictions uint64 `json:"evictions"`
+
+	// BytesUsed es la memoria usada por el servidor
+	BytesUsed uint64 `json:"bytesUsed"`
+}
+
+// CacheEntryTTL representa el tiempo de vida restante de una key en cada nivel del caché
+type CacheEntryTTL struct {
+	Key string `json:"key"`
+
+	// LocalTTLSeconds es nil si la key no está en el caché local
+	LocalTTLSeconds *float64 `json:"localTtlSeconds"`
+
+	// RemoteTTLSeconds es nil si la key no está en el caché remoto o el backend
+	// no permite consultar el TTL (Memcached)
+	RemoteTTLSeconds *float64 `json:"remoteTtlSeconds"`
+}
+
+// CacheInvalidationResponse es la respuesta de DELETE /admin/cache?pattern=
+type CacheInvalidationResponse struct {
+	Pattern string `json:"pattern"`
+
+	// Deleted es la cantidad de keys eliminadas (del nivel que más eliminó)
+	Deleted int `json:"deleted"`
+}
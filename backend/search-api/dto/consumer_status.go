@@ -0,0 +1,60 @@
+package dto
+
+import "time"
+
+// Motivos de alerta del consumidor de RabbitMQ
+const (
+	// ConsumerAlertQueueDepth indica que hay más mensajes esperando que CONSUMER_ALERT_QUEUE_DEPTH
+	ConsumerAlertQueueDepth = "queue_depth"
+
+	// ConsumerAlertLag indica que el mensaje más viejo espera hace más que CONSUMER_ALERT_LAG
+	ConsumerAlertLag = "lag"
+)
+
+// ConsumerQueueStatus es el estado de una queue en RabbitMQ (la principal o una de reintento)
+type ConsumerQueueStatus struct {
+	Name string `json:"name"`
+
+	// Messages son los mensajes listos para entregar (sin contar los entregados sin ACK)
+	Messages int `json:"messages"`
+
+	// Consumers es la cantidad de consumidores de la queue (todas las instancias de search-api)
+	Consumers int `json:"consumers"`
+}
+
+// ConsumerStatus es el estado del consumidor de RabbitMQ en la última medición
+type ConsumerStatus struct {
+	// Connected es false mientras el consumidor no se conectó a RabbitMQ
+	Connected bool      `json:"connected"`
+	CheckedAt time.Time `json:"checkedAt,omitempty"`
+
+	// Queue es la queue principal y QueueDepth sus mensajes esperando; RetryDepth suma los de las colas de reintento
+	Queue      string                `json:"queue"`
+	QueueDepth int                   `json:"queueDepth"`
+	RetryDepth int                   `json:"retryDepth"`
+	Queues     []ConsumerQueueStatus `json:"queues"`
+
+	// LagSeconds es la antigüedad estimada del mensaje más viejo de la queue (0 si está vacía)
+	// Se estima con la demora del último mensaje entregado más el tiempo que pasó desde esa entrega
+	LagSeconds float64 `json:"lagSeconds"`
+
+	// LastDeliveryLagSeconds es cuánto esperó en la queue el último mensaje entregado a esta instancia
+	LastDeliveryLagSeconds float64    `json:"lastDeliveryLagSeconds"`
+	LastDeliveryAt         *time.Time `json:"lastDeliveryAt,omitempty"`
+
+	// ProcessedPerSecond son los mensajes por segundo procesados por esta instancia desde la medición anterior
+	ProcessedPerSecond float64 `json:"processedPerSecond"`
+	ProcessedTotal     uint64  `json:"processedTotal"`
+
+	// DrainSeconds estima cuánto falta para vaciar la queue según cuánto bajó desde la medición anterior
+	// (entre todas las instancias); 0 si está vacía o no está bajando
+	DrainSeconds float64 `json:"drainSeconds,omitempty"`
+
+	// Alerts son los umbrales superados (queue_depth, lag); vacío si el backlog está dentro de lo normal
+	Alerts          []string `json:"alerts"`
+	AlertQueueDepth int      `json:"alertQueueDepth"`
+	AlertLagSeconds float64  `json:"alertLagSeconds"`
+
+	// Error explica por qué no se pudo medir la queue en la última medición
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,23 @@
+package dto
+
+// EngagementRequest es el cuerpo de POST /search/events
+type EngagementRequest struct {
+	// PropertyID es la propiedad con la que interactuó el usuario
+	PropertyID string `json:"propertyId"`
+
+	// Type es el tipo de interacción (por ahora solo "click": las reservas llegan por RabbitMQ)
+	Type string `json:"type"`
+}
+
+// ClickRequest es el cuerpo de POST /search/click
+type ClickRequest struct {
+	// SearchID es el searchId de la respuesta de /search en la que se hizo click
+	SearchID string `json:"searchId"`
+
+	// PropertyID es la propiedad clickeada
+	PropertyID string `json:"propertyId"`
+
+	// Position es la posición del resultado, desde 1 y contando las páginas anteriores
+	// (en la página 2 de 10 resultados, el primero es el 11)
+	Position int `json:"position"`
+}
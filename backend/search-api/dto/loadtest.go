@@ -0,0 +1,54 @@
+package dto
+
+import "time"
+
+// Formatos del escenario de carga
+const (
+	LoadTestFormatJSON   = "json"
+	LoadTestFormatK6     = "k6"
+	LoadTestFormatVegeta = "vegeta"
+)
+
+// Orígenes de las búsquedas del escenario de carga
+const (
+	// LoadTestSourceAnalytics indica que las búsquedas salen de las registradas en analytics
+	LoadTestSourceAnalytics = "analytics"
+
+	// LoadTestSourceDefault indica que no había búsquedas registradas y se usó la mezcla por defecto
+	LoadTestSourceDefault = "default"
+)
+
+// LoadTestTarget es una búsqueda del escenario con su proporción del tráfico
+type LoadTestTarget struct {
+	// Path es la ruta con los query parameters (ej: /search?city=C%C3%B3rdoba)
+	Path string `json:"path"`
+
+	// Weight es la fracción de los requests que usan esta búsqueda (todas suman 1)
+	Weight float64 `json:"weight"`
+
+	// CacheTTLSeconds es el TTL con el que se cachea el resultado según la política de caché actual
+	CacheTTLSeconds float64 `json:"cacheTtlSeconds"`
+}
+
+// LoadTestScenario es un escenario de carga para GET /search que reproduce la mezcla real de búsquedas
+type LoadTestScenario struct {
+	// Source indica de dónde salen las búsquedas (analytics o default)
+	Source string `json:"source"`
+
+	// From es el inicio de la ventana de analytics usada (cero si Source es default)
+	From time.Time `json:"from,omitempty"`
+
+	// Rate es la cantidad de requests por segundo y DurationSeconds la duración de la prueba
+	Rate            int `json:"rate"`
+	DurationSeconds int `json:"durationSeconds"`
+
+	Targets []LoadTestTarget `json:"targets"`
+
+	// EstimatedIndexQPS es la cantidad de consultas por segundo que llegarían al índice:
+	// cada búsqueda va al índice como mucho una vez por TTL y el resto se responde del caché
+	// (estimación con un caché compartido, sin contar el caché local de cada instancia)
+	EstimatedIndexQPS float64 `json:"estimatedIndexQps"`
+
+	// EstimatedCacheHitRatio es la fracción estimada de requests respondidos desde el caché
+	EstimatedCacheHitRatio float64 `json:"estimatedCacheHitRatio"`
+}
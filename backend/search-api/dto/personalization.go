@@ -0,0 +1,41 @@
+package dto
+
+import "time"
+
+// Personalization es lo que se aplica a una búsqueda de un usuario autenticado
+type Personalization struct {
+	// Currency es la moneda de displayPrice (la preferida del usuario o la base si no hay cotización)
+	Currency string `json:"currency"`
+
+	// ExchangeRate es la cotización aplicada a pricePerNight para obtener displayPrice
+	ExchangeRate float64 `json:"exchangeRate"`
+
+	// Locale es el locale preferido del usuario (vacío si no tiene)
+	Locale string `json:"locale,omitempty"`
+
+	// Favorites son los IDs de las propiedades favoritas del usuario
+	Favorites map[string]bool `json:"-"`
+}
+
+// SearchHistoryEntry es una búsqueda reciente de un usuario
+type SearchHistoryEntry struct {
+	// ID identifica la búsqueda (término y filtros) para quitarla con DELETE /search/history?id=
+	ID string `json:"id"`
+
+	// Query es el término buscado, tal como lo escribió el usuario
+	Query string `json:"query"`
+
+	// Filters son los filtros aplicados (city, country, minPrice, etc.)
+	Filters map[string]string `json:"filters,omitempty"`
+
+	// ResultCount es la cantidad de resultados que devolvió la última vez
+	ResultCount int `json:"resultCount"`
+
+	// SearchedAt es la última vez que el usuario hizo esta búsqueda (UTC)
+	SearchedAt time.Time `json:"searchedAt"`
+}
+
+// SearchHistoryResponse es el historial de búsquedas del usuario, de la más reciente a la más antigua
+type SearchHistoryResponse struct {
+	Searches []SearchHistoryEntry `json:"searches"`
+}
@@ -0,0 +1,109 @@
+package dto
+
+import "search-api/domain"
+
+// PropertySnapshot representa una propiedad tal como la expone properties-api
+// Se usa tanto para la respuesta de GET /properties/:id como para el snapshot
+// que viaja en los eventos de RabbitMQ
+type PropertySnapshot struct {
+	ID           string          `json:"id"`
+	Title        string          `json:"title"`
+	Description  string          `json:"description"`
+	Price        float64         `json:"price"`
+	Location     string          `json:"location"`
+	PropertyType string          `json:"propertyType"`
+	Latitude     float64         `json:"latitude"`
+	Longitude    float64         `json:"longitude"`
+	OwnerID      string          `json:"ownerId"`
+	Amenities    []string        `json:"amenities"`
+	Capacity     int             `json:"capacity"`
+	Available    bool            `json:"available"`
+	Images       []PropertyPhoto `json:"images"`     // Ordenadas por posición
+	CoverImage   string          `json:"coverImage"` // URL de la foto de portada
+	// Miniaturas de la portada (vacías mientras la foto subida se procesa o si es una URL externa)
+	CoverThumbnail     string `json:"coverThumbnail"`
+	CoverThumbnailWebP string `json:"coverThumbnailWebp"`
+	Views              int64  `json:"views"`
+	CreatedAt          string `json:"createdAt"` // UTC, RFC3339
+	UpdatedAt          string `json:"updatedAt"` // UTC, RFC3339
+	// ChildFriendly e InfantFriendly indican que la propiedad es apta para niños y para bebés
+	ChildFriendly  bool `json:"childFriendly"`
+	InfantFriendly bool `json:"infantFriendly"`
+	// HouseRules son las reglas de la casa (todo en false si la propiedad no las cargó)
+	HouseRules PropertyHouseRules `json:"houseRules"`
+	// BookingMode es instant o request y CancellationPolicy flexible, moderate o strict
+	BookingMode        string `json:"bookingMode"`
+	CancellationPolicy string `json:"cancellationPolicy"`
+	// BookedRanges son los días ocupados y NextAvailableDate el primer día libre al último cambio de disponibilidad
+	BookedRanges      []PropertyDateRange `json:"bookedRanges"`
+	NextAvailableDate string              `json:"nextAvailableDate,omitempty"`
+	// Language es el idioma del título y la descripción y Translations esos textos en otros idiomas
+	Language     string                         `json:"language"`
+	Translations map[string]PropertyTranslation `json:"translations,omitempty"`
+}
+
+// PropertyTranslation es el título y la descripción de la propiedad en otro idioma
+type PropertyTranslation struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// ToDomainTranslations convierte las traducciones que informa properties-api (nil si no hay)
+func ToDomainTranslations(translations map[string]PropertyTranslation) map[string]domain.Translation {
+	if len(translations) == 0 {
+		return nil
+	}
+	converted := make(map[string]domain.Translation, len(translations))
+	for locale, translation := range translations {
+		converted[locale] = domain.Translation{Title: translation.Title, Description: translation.Description}
+	}
+	return converted
+}
+
+// PropertyDateRange es un rango de días locales de la propiedad (YYYY-MM-DD); End es exclusivo
+type PropertyDateRange struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// ToDomainDateRanges convierte los rangos ocupados que informa properties-api al modelo del índice
+func ToDomainDateRanges(ranges []PropertyDateRange) []domain.DateRange {
+	converted := make([]domain.DateRange, 0, len(ranges))
+	for _, r := range ranges {
+		converted = append(converted, domain.DateRange{Start: r.Start, End: r.End})
+	}
+	return converted
+}
+
+// PropertyHouseRules son las reglas de la casa de la propiedad; solo se indexan las que se pueden filtrar
+type PropertyHouseRules struct {
+	PetsAllowed    bool `json:"petsAllowed"`
+	SmokingAllowed bool `json:"smokingAllowed"`
+	EventsAllowed  bool `json:"eventsAllowed"`
+}
+
+// PropertyPhoto es una foto de la galería de la propiedad
+type PropertyPhoto struct {
+	URL      string `json:"url"`
+	Caption  string `json:"caption,omitempty"`
+	Position int    `json:"position"`
+	IsCover  bool   `json:"isCover"`
+	Status   string `json:"status,omitempty"`
+}
+
+// ImageURLs retorna las URLs de las fotos en el orden de la galería
+func (s PropertySnapshot) ImageURLs() []string {
+	urls := make([]string, 0, len(s.Images))
+	for _, photo := range s.Images {
+		urls = append(urls, photo.URL)
+	}
+	return urls
+}
+
+// CoverURL retorna la portada; si properties-api no la informa se usa la primera foto
+func (s PropertySnapshot) CoverURL() string {
+	if s.CoverImage != "" || len(s.Images) == 0 {
+		return s.CoverImage
+	}
+	return s.Images[0].URL
+}
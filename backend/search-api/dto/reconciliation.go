@@ -0,0 +1,58 @@
+package dto
+
+import "time"
+
+// Disparadores de una reconciliación
+const (
+	ReconciliationScheduled = "scheduled"
+	ReconciliationManual    = "manual"
+
+	// ReconciliationRebuild es una reindexación completa en un índice sombra (POST /admin/reindex)
+	ReconciliationRebuild = "rebuild"
+)
+
+// ReconciliationReport es el resultado de comparar el índice con properties-api
+// Missing, Stale, Orphans e InactiveOwners son las diferencias encontradas (drift);
+// Reindexed, Deleted y Failed son las reparaciones
+type ReconciliationReport struct {
+	Trigger    string    `json:"trigger"`
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+
+	// SourceCount es la cantidad de propiedades en properties-api; IndexedCount la del índice al empezar
+	SourceCount  int `json:"sourceCount"`
+	IndexedCount int `json:"indexedCount"`
+
+	// Missing son propiedades que no estaban indexadas y Stale las indexadas con una versión vieja
+	Missing int `json:"missing"`
+	Stale   int `json:"stale"`
+
+	// Orphans son documentos del índice cuya propiedad ya no existe
+	Orphans int `json:"orphans"`
+
+	// InactiveOwners son documentos de anfitriones desactivados o eliminados que seguían indexados
+	InactiveOwners int `json:"inactiveOwners"`
+
+	Reindexed int `json:"reindexed"`
+	Deleted   int `json:"deleted"`
+	Failed    int `json:"failed"`
+
+	// Shadow es el índice sombra de una reindexación completa y Swapped indica si reemplazó al actual
+	// En una reindexación Reindexed son los documentos escritos en la sombra y Missing, Stale, Orphans
+	// y Deleted los cambios de properties-api durante el llenado que se pusieron al día antes del reemplazo
+	Shadow  string `json:"shadow,omitempty"`
+	Swapped bool   `json:"swapped,omitempty"`
+
+	// Error explica por qué la reconciliación no terminó o no eliminó documentos
+	Error string `json:"error,omitempty"`
+}
+
+// ReconciliationStatus es el estado de la reconciliación (GET /admin/reconcile)
+type ReconciliationStatus struct {
+	Running bool                  `json:"running"`
+	NextRun *time.Time            `json:"nextRun,omitempty"`
+	LastRun *ReconciliationReport `json:"lastRun,omitempty"`
+
+	// LastRebuild es el reporte de la última reindexación completa (no cuenta en las métricas de drift)
+	LastRebuild *ReconciliationReport `json:"lastRebuild,omitempty"`
+}
@@ -0,0 +1,50 @@
+package dto
+
+// Origen del resultado de una búsqueda en el diagnóstico
+const (
+	SearchSourceCache  = "cache"  // Respondida desde el caché
+	SearchSourceIndex  = "index"  // Consultó el índice
+	SearchSourceShared = "shared" // Compartió la consulta al índice de otro request idéntico en curso
+)
+
+// SearchTimings son los tiempos de cada etapa de una búsqueda en milisegundos
+type SearchTimings struct {
+	// CacheLookupMs es la lectura del caché (0 si se pidió cache=bypass|refresh)
+	CacheLookupMs float64 `json:"cacheLookupMs"`
+
+	// IndexMs es la consulta al índice incluyendo la red y la lectura de la respuesta
+	// IndexEngineMs es la parte que reporta el propio motor (QTime de Solr, took de OpenSearch)
+	IndexMs       float64 `json:"indexMs"`
+	IndexEngineMs float64 `json:"indexEngineMs"`
+
+	// MappingMs es la conversión de los documentos a propiedades y el armado de la respuesta
+	MappingMs float64 `json:"mappingMs"`
+
+	// HydrationMs es la obtención de los documentos desde properties-api (0 sin hydrate=true)
+	HydrationMs float64 `json:"hydrationMs"`
+
+	// TotalMs es el tiempo total del servicio de búsqueda
+	TotalMs float64 `json:"totalMs"`
+}
+
+// SearchDebug es el diagnóstico de una búsqueda: la consulta generada, de dónde salió el resultado y los tiempos
+type SearchDebug struct {
+	// Backend es el motor de búsqueda (solr u opensearch)
+	Backend string `json:"backend"`
+
+	// Query es la consulta que se envía al motor: la URL de /select en Solr o el request a _search en OpenSearch
+	// Se incluye aunque el resultado haya salido del caché
+	Query string `json:"query"`
+
+	// CacheKey es la key de la búsqueda en el caché
+	CacheKey string `json:"cacheKey"`
+
+	// Source indica si el resultado salió del caché o del índice (cache, index o shared)
+	Source string `json:"source"`
+
+	Timings SearchTimings `json:"timings"`
+
+	// TotalResults son los resultados que encontró el índice y Returned los de la página devuelta
+	TotalResults int `json:"totalResults"`
+	Returned     int `json:"returned"`
+}
@@ -0,0 +1,41 @@
+package dto
+
+// Estados del caché en SearchMeta
+const (
+	CacheStatusHit    = "hit"
+	CacheStatusMiss   = "miss"
+	CacheStatusBypass = "bypass" // El caller pidió leer del índice (cache=bypass o refresh)
+)
+
+// Niveles del caché de búsquedas
+const (
+	CacheLevelLocal  = "local"  // Caché en memoria de la instancia
+	CacheLevelRemote = "remote" // Caché distribuido (Memcached o Redis)
+)
+
+// SearchMeta describe cómo se resolvió una búsqueda, para depurarla desde el cliente
+type SearchMeta struct {
+	// Query es el término de búsqueda tal como se usó (ya saneado)
+	Query string `json:"query"`
+
+	// Filters son los filtros aplicados con su valor (vacío si no hay filtros)
+	Filters map[string]string `json:"filters"`
+
+	// SortBy y Lang son el orden y el idioma aplicados (vacíos = relevancia e idioma principal)
+	SortBy string `json:"sortBy,omitempty"`
+	Lang   string `json:"lang,omitempty"`
+
+	// ExecutionTimeMs es lo que tardó search-api en resolver la búsqueda
+	ExecutionTimeMs float64 `json:"executionTimeMs"`
+
+	Cache SearchCacheStatus `json:"cache"`
+}
+
+// SearchCacheStatus indica si el resultado salió del caché y de qué nivel
+type SearchCacheStatus struct {
+	// Status es hit, miss o bypass
+	Status string `json:"status"`
+
+	// Level es el nivel que respondió (local o remote); solo en los hit
+	Level string `json:"level,omitempty"`
+}
@@ -0,0 +1,93 @@
+package dto
+
+import (
+	"encoding/json"
+	"math"
+
+	"search-api/domain"
+)
+
+// MarshalJSON serializa la respuesta recortando cada resultado a los campos de Projection
+// y agregando isFavorite y displayPrice si la búsqueda está personalizada
+// Sin Projection ni Personalization se serializa la propiedad completa
+func (r SearchResponse) MarshalJSON() ([]byte, error) {
+	type plainResponse SearchResponse
+	if len(r.Projection) == 0 && r.Personalization == nil {
+		return json.Marshal(plainResponse(r))
+	}
+
+	results := make([]map[string]interface{}, 0, len(r.Results))
+	for _, property := range r.Results {
+		var result map[string]interface{}
+		var err error
+		if len(r.Projection) == 0 {
+			result, err = propertyFields(property)
+		} else {
+			result, err = projectProperty(property, r.Projection)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if r.Personalization != nil {
+			personalizeResult(result, property, r.Personalization)
+		}
+		results = append(results, result)
+	}
+
+	// El campo Results de este struct tapa al de la respuesta embebida
+	return json.Marshal(struct {
+		plainResponse
+		Results []map[string]interface{} `json:"results"`
+	}{plainResponse(r), results})
+}
+
+// personalizeResult marca los favoritos y convierte el precio a la moneda del usuario
+// displayPrice solo se agrega si el resultado incluye pricePerNight (respeta la proyección)
+func personalizeResult(result map[string]interface{}, property domain.Property, personalization *Personalization) {
+	result["isFavorite"] = personalization.Favorites[property.ID]
+	if _, ok := result["pricePerNight"]; ok {
+		result["displayPrice"] = math.Round(property.PricePerNight*personalization.ExchangeRate*100) / 100
+	}
+}
+
+// propertyFields retorna todos los campos de la propiedad tal como se serializan en JSON
+func propertyFields(property domain.Property) (map[string]interface{}, error) {
+	data, err := json.Marshal(property)
+	if err != nil {
+		return nil, err
+	}
+	var full map[string]interface{}
+	if err := json.Unmarshal(data, &full); err != nil {
+		return nil, err
+	}
+	return full, nil
+}
+
+// projectProperty retorna solo los campos pedidos de la propiedad
+// Los índices de listas (ej: images[0]) se acumulan en el orden pedido y los que no existen se omiten
+func projectProperty(property domain.Property, fields []FieldSelection) (map[string]interface{}, error) {
+	full, err := propertyFields(property)
+	if err != nil {
+		return nil, err
+	}
+
+	projected := map[string]interface{}{"id": full["id"]}
+	for _, field := range fields {
+		value, exists := full[field.Name]
+		if !exists {
+			continue
+		}
+		if field.Index < 0 {
+			projected[field.Name] = value
+			continue
+		}
+
+		list, ok := value.([]interface{})
+		if !ok || field.Index >= len(list) {
+			continue
+		}
+		selected, _ := projected[field.Name].([]interface{})
+		projected[field.Name] = append(selected, list[field.Index])
+	}
+	return projected, nil
+}
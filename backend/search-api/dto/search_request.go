@@ -1,43 +1,179 @@
 package dto
 
+import "sort"
+
 // SearchRequest representa los parámetros de búsqueda y filtrado de propiedades
 // Se usa para recibir query parameters desde las peticiones HTTP
 type SearchRequest struct {
 	// Query es el término de búsqueda general
-	Query string `json:"query" form:"query"`
+	Query string `json:"query" form:"query" validate:"max=200"`
 
 	// City es un filtro opcional por ciudad
-	City string `json:"city" form:"city"`
+	City string `json:"city" form:"city" validate:"max=100"`
 
 	// Country es un filtro opcional por país
-	Country string `json:"country" form:"country"`
+	Country string `json:"country" form:"country" validate:"max=100"`
+
+	// Type es un filtro opcional por tipo de propiedad (casa, apartamento, cabaña, loft...)
+	Type string `json:"type" form:"type" validate:"max=50"`
 
 	// MinPrice es el precio mínimo por noche
-	MinPrice float64 `json:"minPrice" form:"minPrice"`
+	MinPrice float64 `json:"minPrice" form:"minPrice" validate:"gte=0"`
 
 	// MaxPrice es el precio máximo por noche
-	MaxPrice float64 `json:"maxPrice" form:"maxPrice"`
+	MaxPrice float64 `json:"maxPrice" form:"maxPrice" validate:"gte=0"`
 
 	// Bedrooms es el número de habitaciones requerido
-	Bedrooms int `json:"bedrooms" form:"bedrooms"`
+	Bedrooms int `json:"bedrooms" form:"bedrooms" validate:"gte=0,lte=50"`
 
 	// Bathrooms es el número de baños requerido
-	Bathrooms int `json:"bathrooms" form:"bathrooms"`
+	Bathrooms int `json:"bathrooms" form:"bathrooms" validate:"gte=0,lte=50"`
 
 	// MinGuests es la capacidad mínima de huéspedes
-	MinGuests int `json:"minGuests" form:"minGuests"`
+	MinGuests int `json:"minGuests" form:"minGuests" validate:"gte=0,lte=100"`
+
+	// ChildFriendly filtra las propiedades aptas para niños (false no filtra)
+	ChildFriendly bool `json:"childFriendly" form:"childFriendly"`
+
+	// InfantFriendly filtra las propiedades aptas para bebés (false no filtra)
+	InfantFriendly bool `json:"infantFriendly" form:"infantFriendly"`
+
+	// PetsAllowed, SmokingAllowed y EventsAllowed filtran por las reglas de la casa (false no filtra)
+	PetsAllowed    bool `json:"petsAllowed" form:"petsAllowed"`
+	SmokingAllowed bool `json:"smokingAllowed" form:"smokingAllowed"`
+	EventsAllowed  bool `json:"eventsAllowed" form:"eventsAllowed"`
+
+	// InstantBook filtra las propiedades que se reservan sin aprobación del anfitrión (false no filtra)
+	InstantBook bool `json:"instantBook" form:"instantBook"`
+
+	// CancellationPolicy filtra por política de cancelación (flexible, moderate o strict)
+	CancellationPolicy string `json:"cancellationPolicy" form:"cancellationPolicy" validate:"omitempty,oneof=flexible moderate strict"`
+
+	// CheckIn y CheckOut (YYYY-MM-DD, se envían los dos) dejan solo las propiedades libres en esas noches
+	// según las fechas ocupadas que publica properties-api; CheckOut es el día de salida (no se ocupa)
+	CheckIn  string `json:"checkIn" form:"checkIn" validate:"omitempty,datetime=2006-01-02"`
+	CheckOut string `json:"checkOut" form:"checkOut" validate:"omitempty,datetime=2006-01-02"`
+
+	// Lang es el idioma de la búsqueda ("en"): el texto también se busca en las traducciones a ese idioma
+	// (con su stemming) y los resultados traen el título y la descripción traducidos si los tienen
+	// Sin lang se usa el primer idioma indexado de Accept-Language
+	Lang string `json:"lang" form:"lang" validate:"omitempty,oneof=es en pt fr de it"`
+
+	// BboxMinLat, BboxMinLng, BboxMaxLat y BboxMaxLng definen un bounding box opcional
+	// para búsquedas por mapa. Deben enviarse los cuatro o ninguno
+	BboxMinLat *float64 `json:"bboxMinLat,omitempty" form:"bboxMinLat" validate:"omitempty,gte=-90,lte=90"`
+	BboxMinLng *float64 `json:"bboxMinLng,omitempty" form:"bboxMinLng" validate:"omitempty,gte=-180,lte=180"`
+	BboxMaxLat *float64 `json:"bboxMaxLat,omitempty" form:"bboxMaxLat" validate:"omitempty,gte=-90,lte=90"`
+	BboxMaxLng *float64 `json:"bboxMaxLng,omitempty" form:"bboxMaxLng" validate:"omitempty,gte=-180,lte=180"`
 
 	// Page es el número de página para paginación (default: 1)
-	Page int `json:"page" form:"page"`
+	Page int `json:"page" form:"page" validate:"min=1,max=1000"`
 
 	// PageSize es el tamaño de página para paginación (default: 10)
-	PageSize int `json:"pageSize" form:"pageSize"`
+	PageSize int `json:"pageSize" form:"pageSize" validate:"min=1,max=100"`
+
+	// SortBy son los campos para ordenar los resultados, separados por comas y con orden opcional
+	// (ej: "popularity desc,price asc"). Campos: "price", "created_at", "bedrooms", "bathrooms",
+	// "max_guests" y "popularity" (vistas). Vacío ordena por relevancia
+	SortBy string `json:"sortBy" form:"sortBy" validate:"max=200,sortfields"`
+
+	// SortOrder es el orden de los campos de SortBy que no lo indican: "asc" o "desc"
+	// Si está vacío cada campo usa su orden por defecto (popularity desc, el resto asc)
+	SortOrder string `json:"sortOrder" form:"sortOrder" validate:"omitempty,oneof=asc desc"`
+
+	// Sort son los criterios de SortBy ya validados (los completa el servicio)
+	Sort []SortField `json:"-" form:"-"`
+
+	// Fields son los campos de cada resultado a devolver, separados por comas
+	// (ej: "id,title,price,images[0]"). Vacío devuelve la propiedad completa
+	Fields string `json:"fields" form:"fields" validate:"max=500,responsefields"`
+
+	// FacetLimit es la cantidad máxima de valores por facet (default: DefaultFacetLimit)
+	FacetLimit int `json:"facetLimit" form:"facetLimit" validate:"omitempty,min=1,max=50"`
+
+	// Projection son los campos de Fields ya validados (los completa el servicio)
+	Projection []FieldSelection `json:"-" form:"-"`
+
+	// CacheMode controla el uso del caché (solo callers internos o admin):
+	// "bypass" lee directo de Solr sin tocar el caché, "refresh" lee de Solr y
+	// reemplaza la entrada cacheada. Vacío usa el caché normalmente
+	CacheMode string `json:"-" form:"cache" validate:"omitempty,oneof=bypass refresh"`
+
+	// Debug pide el diagnóstico de la búsqueda en la respuesta (solo callers internos o admin)
+	// No forma parte de la cache key
+	Debug bool `json:"-" form:"debug"`
 
-	// SortBy es el campo para ordenar los resultados (default: "price")
-	// Opciones comunes: "price", "created_at", "bedrooms", etc.
-	SortBy string `json:"sortBy" form:"sortBy"`
+	// Hydrate pide solo los IDs y el score al índice y los documentos completos a properties-api,
+	// así los precios y la disponibilidad están al día (a costa de una llamada más por búsqueda)
+	// Forma parte de la cache key: el caché guarda solo los IDs y la hidratación se hace en cada request
+	Hydrate bool `json:"hydrate" form:"hydrate"`
 
-	// SortOrder es el orden de clasificación: "asc" o "desc" (default: "asc")
-	SortOrder string `json:"sortOrder" form:"sortOrder"`
+	// Personalized pide reordenar los resultados según los clicks y reservas del usuario (requiere JWT)
+	// No forma parte de la cache key: el reordenamiento se aplica sobre el resultado cacheado
+	Personalized bool `json:"personalized" form:"personalized"`
+
+	// UserID es el usuario autenticado (lo completa el controlador a partir del JWT; 0 = anónimo)
+	UserID uint `json:"-" form:"-"`
+}
+
+// SortField es un criterio de orden validado: campo de Solr y dirección ("asc" o "desc")
+type SortField struct {
+	Field string
+	Order string
+}
+
+// FieldSelection es un campo de los resultados pedido en fields, ya validado
+type FieldSelection struct {
+	// Name es el nombre del campo en el JSON de la propiedad (ej: "pricePerNight")
+	Name string
+
+	// SolrField es el campo de Solr del que se lee el valor (ej: "price")
+	SolrField string
+
+	// Index es la posición pedida de un campo lista (ej: images[0]); -1 devuelve la lista completa
+	Index int
+}
+
+// SolrFieldNames retorna los campos de Solr a pedir en fl, sin repetir y ordenados
+// El id se incluye siempre para poder identificar cada resultado
+func SolrFieldNames(fields []FieldSelection) []string {
+	seen := map[string]bool{"id": true}
+	names := []string{"id"}
+	for _, field := range fields {
+		if !seen[field.SolrField] {
+			seen[field.SolrField] = true
+			names = append(names, field.SolrField)
+		}
+	}
+	sort.Strings(names[1:])
+	return names
 }
 
+// DefaultFacetLimit es la cantidad de valores por facet si no se pide facetLimit
+const DefaultFacetLimit = 50
+
+const (
+	// CacheModeBypass lee directo de Solr sin leer ni escribir el caché
+	CacheModeBypass = "bypass"
+
+	// CacheModeRefresh lee de Solr y actualiza la entrada del caché
+	CacheModeRefresh = "refresh"
+)
+
+// EffectiveFacetLimit retorna la cantidad de valores por facet a pedir al índice
+func (r SearchRequest) EffectiveFacetLimit() int {
+	if r.FacetLimit < 1 {
+		return DefaultFacetLimit
+	}
+	return r.FacetLimit
+}
+
+// HasStayDates indica si el request filtra por las fechas de la estadía
+func (r SearchRequest) HasStayDates() bool {
+	return r.CheckIn != "" && r.CheckOut != ""
+}
+
+// HasBoundingBox indica si el request incluye un bounding box completo
+func (r SearchRequest) HasBoundingBox() bool {
+	return r.BboxMinLat != nil && r.BboxMinLng != nil && r.BboxMaxLat != nil && r.BboxMaxLng != nil
+}
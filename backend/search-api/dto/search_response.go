@@ -5,6 +5,12 @@ import "search-api/domain"
 // SearchResponse representa la respuesta de una búsqueda de propiedades
 // Incluye los resultados y la información de paginación
 type SearchResponse struct {
+	// SearchID identifica esta búsqueda: los clicks en los resultados lo envían para unirlos con la búsqueda
+	SearchID string `json:"searchId"`
+
+	// Meta es el eco de la búsqueda (término, filtros y orden) con su tiempo y estado del caché
+	Meta *SearchMeta `json:"meta,omitempty"`
+
 	// Results es el array de propiedades encontradas
 	Results []domain.Property `json:"results"`
 
@@ -19,6 +25,62 @@ type SearchResponse struct {
 
 	// TotalPages es el total de páginas disponibles
 	TotalPages int `json:"totalPages"`
+
+	// Clusters agrupa los resultados por geohash para renderizar pines en el mapa
+	// Solo se incluye en búsquedas con bounding box
+	Clusters []GeoCluster `json:"clusters,omitempty"`
+
+	// Facets tiene la cantidad de resultados por valor de cada filtro facetado (ej: "type")
+	// Los conteos de un filtro no aplican ese mismo filtro, así se pueden mostrar las otras opciones
+	Facets map[string][]FacetValue `json:"facets,omitempty"`
+
+	// Projection son los campos pedidos en fields: si no está vacío cada resultado
+	// se serializa solo con esos campos (ver MarshalJSON)
+	Projection []FieldSelection `json:"-"`
+
+	// Personalization son los favoritos y preferencias del usuario autenticado (nil en búsquedas anónimas)
+	// Con Personalization cada resultado incluye isFavorite y displayPrice (ver MarshalJSON)
+	Personalization *Personalization `json:"personalization,omitempty"`
+
+	// Personalized indica si los resultados se reordenaron según las interacciones del usuario
+	Personalized bool `json:"personalized,omitempty"`
+
+	// Debug es el diagnóstico de la búsqueda (solo con debug=true, para callers internos o admin)
+	Debug *SearchDebug `json:"debug,omitempty"`
+}
+
+// FacetValue es la cantidad de resultados para un valor de un filtro
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// SearchResult es el resultado de una consulta a Solr, tal como se guarda en caché
+type SearchResult struct {
+	// Properties son las propiedades de la página consultada
+	Properties []domain.Property `json:"properties"`
+
+	// Total es la cantidad total de resultados que coinciden con la búsqueda
+	Total int `json:"total"`
+
+	// Facets son los conteos por valor de cada filtro facetado
+	Facets map[string][]FacetValue `json:"facets,omitempty"`
+}
+
+// GeoCluster representa un grupo de propiedades cercanas (mismo geohash)
+type GeoCluster struct {
+	// Geohash es el prefijo de geohash que identifica la celda
+	Geohash string `json:"geohash"`
+
+	// Count es la cantidad de propiedades en la celda
+	Count int `json:"count"`
+
+	// Latitude y Longitude son el centro promedio de las propiedades de la celda
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+
+	// PropertyIDs son los IDs de las propiedades de la celda
+	PropertyIDs []string `json:"propertyIds"`
 }
 
 // ErrorResponse representa una respuesta de error
@@ -29,5 +91,29 @@ type ErrorResponse struct {
 
 	// Code es el código de error HTTP o código de error personalizado
 	Code int `json:"code"`
+
+	// Details son los errores de validación por parámetro (solo en los 400 de validación)
+	Details []FieldError `json:"details,omitempty"`
+}
+
+// FieldError es un parámetro del request que no pasó la validación
+type FieldError struct {
+	// Field es el nombre del query parameter (ej: "pageSize")
+	Field string `json:"field"`
+
+	// Rule es la regla que falló (ej: "max", "oneof", "sortfields")
+	Rule string `json:"rule"`
+
+	// Message explica el error en castellano
+	Message string `json:"message"`
 }
 
+
+// SimilarPropertiesResponse son las propiedades parecidas a una propiedad (GET /search/similar/:propertyId)
+type SimilarPropertiesResponse struct {
+	// PropertyID es la propiedad de referencia
+	PropertyID string `json:"propertyId"`
+
+	// Results son las propiedades similares, de la más a la menos parecida
+	Results []domain.Property `json:"results"`
+}
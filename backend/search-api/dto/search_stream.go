@@ -0,0 +1,46 @@
+package dto
+
+import (
+	"encoding/json"
+
+	"search-api/domain"
+)
+
+// Tipos de evento enviados por GET /search/stream
+const (
+	// SearchStreamPropertyCreated indica que se indexó una propiedad nueva que coincide con la búsqueda
+	SearchStreamPropertyCreated = "property.created"
+
+	// SearchStreamPropertyUpdated indica que se actualizó una propiedad que coincide con la búsqueda
+	SearchStreamPropertyUpdated = "property.updated"
+)
+
+// SearchStreamEvent es una propiedad recién indexada que coincide con una búsqueda suscrita
+type SearchStreamEvent struct {
+	// Type es el tipo de evento (property.created o property.updated)
+	Type string `json:"type"`
+
+	// Property es la propiedad tal como quedó indexada
+	Property domain.Property `json:"property"`
+
+	// Projection son los campos pedidos en fields por el suscriptor (ver MarshalJSON)
+	Projection []FieldSelection `json:"-"`
+}
+
+// MarshalJSON serializa el evento recortando la propiedad a los campos de Projection
+// Sin Projection se serializa la propiedad completa
+func (e SearchStreamEvent) MarshalJSON() ([]byte, error) {
+	type plainEvent SearchStreamEvent
+	if len(e.Projection) == 0 {
+		return json.Marshal(plainEvent(e))
+	}
+
+	projected, err := projectProperty(e.Property, e.Projection)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		plainEvent
+		Property map[string]interface{} `json:"property"`
+	}{plainEvent(e), projected})
+}
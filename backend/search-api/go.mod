@@ -3,7 +3,55 @@ module search-api
 go 1.21
 
 require (
+	github.com/alicebob/miniredis/v2 v2.33.0
 	github.com/bradfitz/gomemcache v0.0.0-20230905024940-24af94b03874
+	github.com/go-playground/validator/v10 v10.14.0
+	github.com/golang-jwt/jwt/v5 v5.3.0
 	github.com/karlseguin/ccache/v3 v3.0.5
+	github.com/redis/go-redis/v9 v9.17.2
 	github.com/streadway/amqp v1.0.0
+	go.mongodb.org/mongo-driver v1.13.1
+	golang.org/x/sync v0.7.0
+	google.golang.org/grpc v1.64.1
+	jobs v0.0.0
+	reporting v0.0.0
+	resilience v0.0.0
+	signing v0.0.0
 )
+
+require (
+	github.com/alicebob/gopher-json v0.0.0-20200520072559-a9ecdc9d1d3a // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/gabriel-vasile/mimetype v1.4.2 // indirect
+	github.com/go-playground/locales v0.14.1 // indirect
+	github.com/go-playground/universal-translator v0.18.1 // indirect
+	github.com/golang/snappy v0.0.1 // indirect
+	github.com/klauspost/compress v1.13.6 // indirect
+	github.com/leodido/go-urn v1.2.4 // indirect
+	github.com/montanaflynn/stats v0.0.0-20171201202039-1bf9dbcd8cbe // indirect
+	github.com/xdg-go/pbkdf2 v1.0.0 // indirect
+	github.com/xdg-go/scram v1.1.2 // indirect
+	github.com/xdg-go/stringprep v1.0.4 // indirect
+	github.com/youmark/pkcs8 v0.0.0-20181117223130-1be2e3e5546d // indirect
+	github.com/yuin/gopher-lua v1.1.1 // indirect
+	golang.org/x/crypto v0.24.0 // indirect
+	golang.org/x/net v0.26.0 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+	golang.org/x/text v0.16.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240318140521-94a12d6c2237 // indirect
+	google.golang.org/protobuf v1.33.0 // indirect
+)
+
+// jobs es el runner de jobs programados y los locks distribuidos, compartido con properties-api
+replace jobs => ../jobs
+
+// reporting envía los panics y errores a un servicio compatible con Sentry, compartido por todos los servicios
+replace reporting => ../reporting
+
+// resilience tiene el circuit breaker y los reintentos de las llamadas a otros servicios, compartido con properties-api
+replace resilience => ../resilience
+
+// signing firma y valida las llamadas internas (HMAC), compartido por los servicios que se llaman entre sí
+replace signing => ../signing
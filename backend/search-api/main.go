@@ -2,19 +2,29 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	"jobs"
+	"reporting"
+	"resilience"
 	"search-api/config"
 	"search-api/consumers"
 	"search-api/controllers"
+	"search-api/middleware"
 	"search-api/repositories"
+	"search-api/rpc"
 	"search-api/services"
+	"search-api/utils"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 func main() {
@@ -26,61 +36,400 @@ func main() {
 	log.Println("📋 Cargando configuración...")
 	cfg := config.LoadConfig()
 	log.Printf("✅ Configuración cargada:")
+	log.Printf("   - Search backend: %s", cfg.SearchBackend)
 	log.Printf("   - Solr URL: %s", cfg.SolrURL)
+	log.Printf("   - Solr collections: %d rutas por país (auto-create %v)", len(cfg.SolrCollections.Routes), cfg.SolrCollections.AutoCreate)
+	log.Printf("   - Cache backend: %s", cfg.Cache.Backend)
 	log.Printf("   - Memcached Host: %s", cfg.MemcachedHost)
 	log.Printf("   - RabbitMQ URL: %s", cfg.RabbitMQURL)
-	log.Printf("   - Properties API URL: %s", cfg.PropertiesAPIURL)
+	log.Printf("   - Event source: %s", cfg.EventSource)
+	log.Printf("   - Properties API gRPC: %s", cfg.PropertiesAPIGRPCAddr)
+	log.Printf("   - Users API gRPC: %s", cfg.UsersAPIGRPCAddr)
 	log.Printf("   - Port: %s", cfg.Port)
+	log.Printf("   - Cache TTL: local %v, remoto %v (amplias %v, específicas %v)",
+		cfg.Cache.LocalTTL, cfg.Cache.RemoteTTL, cfg.Cache.BroadQueryTTL, cfg.Cache.SpecificQueryTTL)
+	log.Printf("   - Consumer: %d workers, prefetch %d", cfg.Consumer.Workers, cfg.Consumer.Prefetch)
+	log.Printf("   - Bot detection: %v (degradar >= %d, bloquear >= %d req/min)",
+		cfg.BotDetection.Enabled, cfg.BotDetection.DegradeThreshold, cfg.BotDetection.BlockThreshold)
+	log.Printf("   - Search stream: máx. %d suscripciones, heartbeat %v", cfg.Stream.MaxSubscriptions, cfg.Stream.Heartbeat)
+	log.Printf("   - Personalización: perfil cacheado %v, moneda base %s, historial de %d búsquedas",
+		cfg.Personalization.ProfileTTL, cfg.Personalization.BaseCurrency, cfg.Personalization.HistorySize)
+	log.Printf("   - Historial, interacciones y analytics: %s", cfg.DataStore)
 
 	// ============================================
 	// SECCIÓN 2: INICIALIZAR REPOSITORIOS
 	// ============================================
 	log.Println("📦 Inicializando repositorios...")
 
-	// Inicializar repositorio de Solr
-	solrRepo := repositories.NewSolrRepository(cfg.SolrURL)
-	log.Println("✅ Repositorio de Solr inicializado")
+	// Cliente HTTP compartido por los backends del índice (pool de conexiones y timeouts)
+	httpClient := utils.NewHTTPClient(cfg.HTTPClient)
+
+	// Reporte de panics y errores 5xx (sin ERROR_REPORTING_DSN solo quedan en los logs)
+	errorReporter := reporting.NewErrorReporter(cfg.ErrorReporting, "search-api", httpClient)
+	defer errorReporter.Close(5 * time.Second)
+
+	// Inicializar el índice de búsqueda (Solr u OpenSearch según SEARCH_BACKEND)
+	// La conexión se verifica en segundo plano al arrancar (ver SECCIÓN 5)
+	var searchIndex repositories.SearchIndex
+	var connectIndex func(ctx context.Context) error
+	switch cfg.SearchBackend {
+	case "solr":
+		solrOptions := repositories.SolrOptions{
+			URL:               cfg.SolrURL,
+			Routes:            cfg.SolrCollections.Routes,
+			ConfigSet:         cfg.SolrCollections.ConfigSet,
+			NumShards:         cfg.SolrCollections.NumShards,
+			ReplicationFactor: cfg.SolrCollections.ReplicationFactor,
+			AliasMigration:    cfg.SolrCollections.AliasMigration,
+		}
+		searchIndex = repositories.NewSolrRepository(solrOptions, httpClient)
+		connectIndex = searchIndex.Ping
+		if cfg.SolrCollections.AutoCreate {
+			// Las colecciones que falten se crean antes del ping (que las consulta a todas)
+			connectIndex = func(ctx context.Context) error {
+				if err := repositories.EnsureSolrCollections(ctx, solrOptions, httpClient); err != nil {
+					return err
+				}
+				return searchIndex.Ping(ctx)
+			}
+		}
+	case "opensearch":
+		searchIndex = repositories.NewOpenSearchRepository(repositories.OpenSearchOptions{
+			URL:      cfg.OpenSearch.URL,
+			Index:    cfg.OpenSearch.Index,
+			Username: cfg.OpenSearch.Username,
+			Password: cfg.OpenSearch.Password,
+		}, httpClient)
+		connectIndex = searchIndex.Ping
+	default:
+		log.Fatalf("❌ SEARCH_BACKEND inválido: '%s' (debe ser 'solr' u 'opensearch')", cfg.SearchBackend)
+	}
+	log.Printf("✅ Índice de búsqueda inicializado (%s)", searchIndex.Name())
+
+	// Inicializar repositorio de caché (el nivel remoto es Memcached o Redis según CACHE_BACKEND)
+	var remoteCache repositories.RemoteCache
+	switch cfg.Cache.Backend {
+	case "memcached":
+		remoteCache = repositories.NewMemcachedCache(cfg.MemcachedHost)
+	case "redis":
+		remoteCache = repositories.NewRedisCache(repositories.RedisOptions{
+			Addr:     cfg.Cache.RedisAddr,
+			Password: cfg.Cache.RedisPassword,
+			DB:       cfg.Cache.RedisDB,
+			Timeout:  2 * time.Second,
+		})
+	default:
+		log.Fatalf("❌ CACHE_BACKEND inválido: '%s' (debe ser 'memcached' o 'redis')", cfg.Cache.Backend)
+	}
+	cacheRepo := repositories.NewCacheRepository(remoteCache, cfg.Cache.LocalTTL)
+	log.Printf("✅ Repositorio de caché inicializado (%s)", remoteCache.Name())
+
+	// MongoDB guarda el historial, las interacciones y los analytics (DATA_STORE=mongodb) y los locks de jobs
+	var mongoDB *mongo.Database
+	if cfg.DataStore == config.DataStoreMongoDB || cfg.JobsDistributedLock {
+		mongoClient, err := connectMongo(cfg.MongoDB)
+		if err != nil {
+			log.Fatalf("❌ Error conectando a MongoDB: %v", err)
+		}
+		defer mongoClient.Disconnect(context.Background())
+		mongoDB = mongoClient.Database(cfg.MongoDB.Database)
+		log.Printf("✅ Conectado a MongoDB (base '%s')", cfg.MongoDB.Database)
+	}
 
-	// Inicializar repositorio de caché
-	cacheRepo := repositories.NewCacheRepository(cfg.MemcachedHost)
-	log.Println("✅ Repositorio de caché inicializado")
+	// Inicializar los repositorios de analytics, del historial de búsquedas y de clicks y reservas por usuario
+	// Con MongoDB todas las réplicas comparten los datos: el historial que se borra (o un "user.erased" que
+	// procesa una sola réplica) se borra para todas y los reportes cuentan las búsquedas de todas
+	var analyticsRepo repositories.AnalyticsRepository
+	var historyRepo repositories.SearchHistoryRepository
+	var engagementRepo repositories.EngagementRepository
+	switch cfg.DataStore {
+	case config.DataStoreMongoDB:
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		var err error
+		analyticsRepo, err = repositories.NewMongoAnalyticsRepository(ctx, mongoDB, cfg.AnalyticsMaxEvents, cfg.AnalyticsRetention)
+		if err == nil {
+			historyRepo, err = repositories.NewMongoSearchHistoryRepository(ctx, mongoDB, cfg.Personalization.HistorySize, cfg.Personalization.HistoryRetention)
+		}
+		if err == nil {
+			engagementRepo, err = repositories.NewMongoEngagementRepository(ctx, mongoDB, cfg.Personalization.EngagementSize, cfg.Personalization.HistoryRetention)
+		}
+		cancel()
+		if err != nil {
+			log.Fatalf("❌ Error inicializando los repositorios en MongoDB: %v", err)
+		}
+	case config.DataStoreMemory:
+		log.Println("⚠️ DATA_STORE=memory: el historial, las interacciones y los analytics son de esta réplica (no usar con varias)")
+		analyticsRepo = repositories.NewAnalyticsRepository(cfg.AnalyticsMaxEvents)
+		historyRepo = repositories.NewSearchHistoryRepository(cfg.Personalization.HistorySize, cfg.Personalization.HistoryMaxUsers)
+		engagementRepo = repositories.NewEngagementRepository(cfg.Personalization.EngagementSize, cfg.Personalization.HistoryMaxUsers)
+	default:
+		log.Fatalf("❌ DATA_STORE inválido: '%s' (debe ser 'mongodb' o 'memory')", cfg.DataStore)
+	}
+	log.Printf("✅ Repositorio de analytics inicializado (máx. %d búsquedas por reporte)", cfg.AnalyticsMaxEvents)
+	log.Println("✅ Repositorio de historial de búsquedas inicializado")
+	log.Println("✅ Repositorio de interacciones inicializado")
 
 	// ============================================
 	// SECCIÓN 3: INICIALIZAR SERVICIO
 	// ============================================
 	log.Println("🔧 Inicializando servicio...")
-	searchService := services.NewSearchService(solrRepo, cacheRepo, cfg.PropertiesAPIURL)
+	apiResilience := cfg.PropertiesAPIResilience
+	// Las propiedades a indexar se piden a properties-api por gRPC (conexión HTTP/2 compartida)
+	internalAuth := utils.InternalCallAuth{Caller: "search-api", APIKey: cfg.InternalAPIKey, SigningSecret: cfg.Auth.SigningSecret}
+	propertiesConn, err := utils.NewGRPCConn(cfg.PropertiesAPIGRPCAddr, internalAuth)
+	if err != nil {
+		log.Fatalf("❌ Error creando conexión gRPC a properties-api: %v", err)
+	}
+	defer propertiesConn.Close()
+	apiRetry := resilience.RetryPolicy{
+		MaxAttempts:    apiResilience.RetryMaxAttempts,
+		BaseDelay:      apiResilience.RetryBaseDelay,
+		MaxDelay:       apiResilience.RetryMaxDelay,
+		AttemptTimeout: apiResilience.CallTimeout,
+	}
+	propertiesClient := rpc.NewPropertiesServiceClient(propertiesConn)
+	engagementService := services.NewEngagementService(engagementRepo, searchIndex, cfg.Personalization.EngagementHalfLife)
+	cacheTTLPolicy := services.CacheTTLPolicy{
+		Default:         cfg.Cache.RemoteTTL,
+		Broad:           cfg.Cache.BroadQueryTTL,
+		Specific:        cfg.Cache.SpecificQueryTTL,
+		SpecificFilters: cfg.Cache.SpecificQueryFilters,
+	}
+	searchService := services.NewSearchService(searchIndex, cacheRepo, propertiesClient,
+		resilience.CircuitBreakerSettings{
+			FailureThreshold:    apiResilience.BreakerFailureThreshold,
+			OpenTimeout:         apiResilience.BreakerOpenTimeout,
+			HalfOpenMaxRequests: 1,
+		},
+		apiRetry,
+		cacheTTLPolicy,
+		engagementService,
+		cfg.SlowSearchThreshold,
+	)
 	log.Println("✅ Servicio de búsqueda inicializado")
+	analyticsService := services.NewAnalyticsService(analyticsRepo)
+	log.Println("✅ Servicio de analytics inicializado")
+	loadTestService := services.NewLoadTestService(analyticsRepo, cacheTTLPolicy)
+	log.Println("✅ Generador de escenarios de carga inicializado")
+	cacheService := services.NewCacheService(cacheRepo)
+	log.Println("✅ Servicio de caché inicializado")
+	streamService := services.NewSearchStreamService(cfg.Stream.MaxSubscriptions, cfg.Stream.BufferSize)
+	log.Println("✅ Servicio de suscripciones a búsquedas inicializado")
+
+	// Favoritos y preferencias de los usuarios autenticados se piden a users-api por gRPC
+	usersConn, err := utils.NewGRPCConn(cfg.UsersAPIGRPCAddr, internalAuth)
+	if err != nil {
+		log.Fatalf("❌ Error creando conexión gRPC a users-api: %v", err)
+	}
+	defer usersConn.Close()
+	usersClient := rpc.NewUsersServiceClient(usersConn)
+	personalizationService := services.NewPersonalizationService(usersClient, cfg.Personalization)
+	log.Println("✅ Servicio de personalización inicializado")
+	historyService := services.NewSearchHistoryService(historyRepo, cfg.Personalization.HistoryRetention)
+	log.Println("✅ Servicio de historial de búsquedas inicializado")
+	similarService := services.NewSimilarService(searchIndex, cacheRepo, cfg.Cache.SimilarTTL)
+	log.Println("✅ Servicio de propiedades similares inicializado")
+
+	// Reconciliación diaria del índice con properties-api (repara eventos perdidos)
+	// La reconciliación, la reindexación y los jobs programados corren en una sola réplica con locks en MongoDB
+	// (no en el caché remoto: DELETE /admin/cache y el desalojo por LRU soltarían locks en uso)
+	var jobLocker jobs.Locker
+	if cfg.JobsDistributedLock {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		locker, err := repositories.NewJobLockRepository(ctx, mongoDB, cfg.MongoDB.JobLocksCollection)
+		cancel()
+		if err != nil {
+			log.Fatalf("❌ Error inicializando los locks de jobs en MongoDB: %v", err)
+		}
+		jobLocker = locker
+		log.Printf("✅ Locks de jobs en MongoDB ('%s.%s')", cfg.MongoDB.Database, cfg.MongoDB.JobLocksCollection)
+	}
+	reconciliationService := services.NewReconciliationService(searchIndex, searchService, cacheService, propertiesClient, usersClient, apiRetry, cfg.Reconciliation, jobLocker)
+	defer reconciliationService.Stop()
+	log.Println("✅ Servicio de reconciliación del índice inicializado")
+
+	// Elección de líder entre las réplicas (lock con lease en MongoDB): los jobs LeaderOnly corren solo
+	// en el líder, mientras todas las réplicas consumen la queue y atienden búsquedas
+	var leader jobs.Leader
+	if jobLocker != nil {
+		leader = jobs.NewLeader(jobLocker, "search-api-leader", jobs.DefaultLease)
+		leader.Start()
+		defer leader.Stop()
+	}
+
+	// Jobs programados: la reconciliación diaria
+	jobRunner := jobs.NewRunner(jobLocker, leader)
+	if cfg.Reconciliation.Enabled {
+		err := jobRunner.Register(jobs.Job{
+			Name:       "index-reconciliation",
+			Schedule:   jobs.DailyAt(cfg.Reconciliation.Hour),
+			Timeout:    cfg.Reconciliation.Timeout,
+			Exclusive:  true,
+			LeaderOnly: true,
+			Run: func(ctx context.Context) error {
+				err := reconciliationService.RunScheduled(ctx)
+				if errors.Is(err, services.ErrReconciliationRunning) {
+					log.Printf("⚠️ Reconciliación programada omitida: %v", err)
+					return jobs.ErrSkipped
+				}
+				return err
+			},
+		})
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		log.Printf("🗓️ Reconciliación del índice programada todos los días a las %02d:00 UTC", cfg.Reconciliation.Hour)
+	}
+	jobRunner.Start()
+	defer jobRunner.Stop()
+
+	// Backlog del consumidor de RabbitMQ (empieza a medir cuando el consumidor se conecta)
+	consumerMonitor := services.NewConsumerMonitorService(cfg.Consumer)
+	consumerMonitor.Start()
+	defer consumerMonitor.Stop()
+	log.Println("✅ Monitor del consumidor inicializado")
 
 	// ============================================
 	// SECCIÓN 4: INICIALIZAR CONTROLADOR
 	// ============================================
 	log.Println("🎮 Inicializando controlador...")
-	searchController := controllers.NewSearchController(searchService)
+	searchController := controllers.NewSearchController(searchService, analyticsService, personalizationService, historyService)
 	log.Println("✅ Controlador de búsqueda inicializado")
+	analyticsController := controllers.NewAnalyticsController(analyticsService)
+	log.Println("✅ Controlador de analytics inicializado")
+	loadTestController := controllers.NewLoadTestController(loadTestService)
+	log.Println("✅ Controlador de pruebas de carga inicializado")
+	cacheController := controllers.NewCacheController(cacheService)
+	log.Println("✅ Controlador de caché inicializado")
+	streamController := controllers.NewSearchStreamController(streamService, cfg.Stream.Heartbeat)
+	log.Println("✅ Controlador de streams de búsqueda inicializado")
+	historyController := controllers.NewHistoryController(historyService)
+	log.Println("✅ Controlador de historial de búsquedas inicializado")
+	similarController := controllers.NewSimilarController(similarService)
+	log.Println("✅ Controlador de propiedades similares inicializado")
+	engagementController := controllers.NewEngagementController(engagementService, analyticsService)
+	log.Println("✅ Controlador de interacciones inicializado")
+	reconciliationController := controllers.NewReconciliationController(reconciliationService)
+	log.Println("✅ Controlador de reconciliación inicializado")
+	consumerController := controllers.NewConsumerController(consumerMonitor)
+	log.Println("✅ Controlador del consumidor inicializado")
 
 	// ============================================
-	// SECCIÓN 5: INICIALIZAR Y ARRANCAR CONSUMIDOR DE RABBITMQ
+	// SECCIÓN 5: CONECTAR DEPENDENCIAS Y ARRANCAR CONSUMIDOR DE RABBITMQ
 	// ============================================
-	log.Println("🐰 Inicializando consumidor de RabbitMQ...")
-	consumer, err := consumers.NewRabbitMQConsumer(cfg.RabbitMQURL, "property_events", searchService)
-	if err != nil {
-		log.Fatalf("❌ Error creando consumidor de RabbitMQ: %v", err)
+	// El índice, el caché y RabbitMQ se conectan en segundo plano con backoff: el servidor HTTP arranca
+	// igual y /health/ready reporta lo que falta. Si una obligatoria no conecta en STARTUP_MAX_WAIT se termina
+	log.Println("🐰 Conectando dependencias (índice, caché y RabbitMQ)...")
+	var consumer atomic.Pointer[consumers.RabbitMQConsumer]
+	var invalidationBus atomic.Pointer[consumers.CacheInvalidationBus]
+	dependencies := []services.StartupDependency{
+		{Name: searchIndex.Name(), Connect: connectIndex},
+		{Name: remoteCache.Name(), Optional: true, Connect: func(ctx context.Context) error {
+			return cacheRepo.Ping()
+		}},
+		{Name: "rabbitmq", Connect: func(ctx context.Context) error {
+			rabbitConsumer, err := consumers.NewRabbitMQConsumer(cfg.RabbitMQURL, cfg.RabbitMQExchange, cfg.RabbitMQUsersExchange, cfg.RabbitMQQueue, searchService, streamService, historyService, engagementService, analyticsService, cfg.Consumer)
+			if err != nil {
+				return err
+			}
+			consumer.Store(rabbitConsumer)
+			consumerMonitor.Attach(rabbitConsumer)
+
+			// Arrancar consumidor en una goroutine
+			go func() {
+				if err := rabbitConsumer.Start(); err != nil {
+					log.Fatalf("❌ Error iniciando consumidor de RabbitMQ: %v", err)
+				}
+			}()
+			log.Println("✅ Consumidor de RabbitMQ iniciado en goroutine")
+			return nil
+		}},
 	}
+	// Con varias réplicas, la que indexa un cambio avisa a las otras por un fanout exchange para que limpien su
+	// caché local. Es opcional: sin él el caché local de las otras réplicas vence a los CACHE_LOCAL_TTL
+	if cfg.Cache.InvalidationExchange != "" {
+		dependencies = append(dependencies, services.StartupDependency{Name: "cache-invalidation", Optional: true, Connect: func(ctx context.Context) error {
+			bus, err := consumers.NewCacheInvalidationBus(cfg.RabbitMQURL, cfg.Cache.InvalidationExchange, cacheRepo)
+			if err != nil {
+				return err
+			}
+			if err := bus.Start(); err != nil {
+				bus.Close()
+				return err
+			}
+			invalidationBus.Store(bus)
+			cacheRepo.SetInvalidationPublisher(bus)
+			return nil
+		}})
+	}
+	startupService := services.NewStartupService(cfg.Startup, dependencies...)
+	go func() {
+		if err := startupService.Wait(context.Background()); err != nil {
+			log.Fatalf("❌ Error conectando dependencias: %v", err)
+		}
+		log.Println("✅ Dependencias conectadas")
+	}()
 	defer func() {
-		log.Println("🔌 Cerrando consumidor de RabbitMQ...")
-		if err := consumer.Close(); err != nil {
-			log.Printf("⚠️ Error cerrando consumidor de RabbitMQ: %v", err)
+		if bus := invalidationBus.Load(); bus != nil {
+			cacheRepo.SetInvalidationPublisher(nil)
+			if err := bus.Close(); err != nil {
+				log.Printf("⚠️ Error cerrando las invalidaciones del caché: %v", err)
+			}
 		}
 	}()
-
-	// Arrancar consumidor en una goroutine
-	go func() {
-		if err := consumer.Start(); err != nil {
-			log.Fatalf("❌ Error iniciando consumidor de RabbitMQ: %v", err)
+	defer func() {
+		if rabbitConsumer := consumer.Load(); rabbitConsumer != nil {
+			log.Println("🔌 Cerrando consumidor de RabbitMQ...")
+			if err := rabbitConsumer.Close(); err != nil {
+				log.Printf("⚠️ Error cerrando consumidor de RabbitMQ: %v", err)
+			}
 		}
 	}()
-	log.Println("✅ Consumidor de RabbitMQ iniciado en goroutine")
+
+	// Health checks: el índice de búsqueda y RabbitMQ son obligatorios; sin el caché remoto queda el caché local
+	// "startup" detalla las dependencias obligatorias que todavía no conectaron
+	healthChecks := []services.HealthCheck{
+		{Name: "startup", Check: startupService.Check},
+		{Name: searchIndex.Name(), Check: searchIndex.Ping},
+		{Name: "rabbitmq", Check: func(ctx context.Context) error {
+			rabbitConsumer := consumer.Load()
+			if rabbitConsumer == nil {
+				return errors.New("consumidor de RabbitMQ todavía no conectado")
+			}
+			return rabbitConsumer.Ping()
+		}},
+		{Name: remoteCache.Name(), Optional: true, Check: func(ctx context.Context) error {
+			return cacheRepo.Ping()
+		}},
+	}
+
+	// Con EVENT_SOURCE=changestream los cambios de propiedades se leen del oplog de MongoDB
+	// (RabbitMQ se sigue usando para usuarios, popularidad y reservas)
+	var changeStream *consumers.PropertyChangeStream
+	switch cfg.EventSource {
+	case config.EventSourceRabbitMQ:
+		log.Println("✅ Cambios de propiedades desde los eventos de RabbitMQ")
+	case config.EventSourceChangeStream:
+		changeStream, err = consumers.NewPropertyChangeStream(cfg.ChangeStream, searchService, streamService)
+		if err != nil {
+			log.Fatalf("❌ Error creando el change stream de propiedades: %v", err)
+		}
+		defer func() {
+			log.Println("🔌 Cerrando change stream de propiedades...")
+			if err := changeStream.Close(); err != nil {
+				log.Printf("⚠️ Error cerrando change stream de propiedades: %v", err)
+			}
+		}()
+		changeStream.Start()
+		healthChecks = append(healthChecks, services.HealthCheck{Name: "mongodb-change-stream", Check: func(ctx context.Context) error {
+			return changeStream.Ping()
+		}})
+		log.Println("✅ Cambios de propiedades desde el change stream de MongoDB")
+	default:
+		log.Fatalf("❌ EVENT_SOURCE inválido: '%s' (debe ser '%s' o '%s')", cfg.EventSource, config.EventSourceRabbitMQ, config.EventSourceChangeStream)
+	}
+	healthService := services.NewHealthService("search-api", 2*time.Second, healthChecks...)
+	healthController := controllers.NewHealthController(healthService)
 
 	// ============================================
 	// SECCIÓN 6: CONFIGURAR ROUTER HTTP
@@ -90,21 +439,71 @@ func main() {
 	// Crear mux para las rutas
 	mux := http.NewServeMux()
 
+	// Detector de bots y scrapers para la búsqueda pública
+	clientIPs, err := middleware.NewClientIPResolver(cfg.BotDetection.TrustedProxies)
+	if err != nil {
+		log.Fatalf("❌ TRUSTED_PROXIES inválido: %v", err)
+	}
+	botDetector := middleware.NewBotDetector(cfg.BotDetection, clientIPs)
+	if cfg.BotDetection.CaptchaSecret == "" {
+		log.Println("⚠️ BOT_CAPTCHA_SECRET no configurado: los tokens de CAPTCHA no se aceptan")
+	}
+
+	// Identificación del caller: JWT de usuario (personalización e historial) y callers internos/admin (cache=bypass|refresh)
+	callerAuth := middleware.NewCallerAuth(cfg.Auth, usersClient)
+
 	// Registrar rutas
-	mux.HandleFunc("/search", searchController.Search)
-	mux.HandleFunc("/health", healthHandler)
+	mux.Handle("/search", callerAuth.Middleware(botDetector.Middleware(http.HandlerFunc(searchController.Search))))
+	mux.Handle("/search/stream", callerAuth.Middleware(botDetector.Middleware(http.HandlerFunc(streamController.Stream))))
+	mux.Handle("/search/similar/", callerAuth.Middleware(botDetector.Middleware(http.HandlerFunc(similarController.Similar))))
+	mux.Handle("/search/events", callerAuth.Middleware(http.HandlerFunc(engagementController.Events)))
+	mux.Handle("/search/click", callerAuth.Middleware(http.HandlerFunc(engagementController.Click)))
+	mux.Handle("/search/history", callerAuth.Middleware(http.HandlerFunc(historyController.History)))
+	mux.Handle("/search/analytics/top-queries", callerAuth.Middleware(http.HandlerFunc(analyticsController.TopQueries)))
+	mux.Handle("/search/analytics/zero-results", callerAuth.Middleware(http.HandlerFunc(analyticsController.ZeroResultQueries)))
+	mux.Handle("/search/analytics/ctr", callerAuth.Middleware(http.HandlerFunc(analyticsController.ClickThroughRate)))
+	mux.Handle("/search/analytics/daily", callerAuth.Middleware(http.HandlerFunc(analyticsController.DailyActivity)))
+	mux.Handle("/admin/cache/stats", callerAuth.Middleware(http.HandlerFunc(cacheController.Stats)))
+	mux.Handle("/admin/cache/ttl", callerAuth.Middleware(http.HandlerFunc(cacheController.TTL)))
+	mux.Handle("/admin/cache", callerAuth.Middleware(http.HandlerFunc(cacheController.Flush)))
+	mux.Handle("/admin/reconcile", callerAuth.Middleware(http.HandlerFunc(reconciliationController.Reconcile)))
+	mux.Handle("/admin/reindex", callerAuth.Middleware(http.HandlerFunc(reconciliationController.Reindex)))
+	mux.Handle("/admin/loadtest/scenario", callerAuth.Middleware(http.HandlerFunc(loadTestController.Scenario)))
+	mux.Handle("/admin/consumer/status", callerAuth.Middleware(http.HandlerFunc(consumerController.Status)))
+	mux.HandleFunc("/health/live", healthController.Live)
+	mux.HandleFunc("/health/ready", healthController.Ready)
+	mux.HandleFunc("/metrics", metricsHandler(&consumer, &invalidationBus, consumerMonitor, changeStream, cacheService, streamService, reconciliationService, jobRunner))
 
 	log.Println("✅ Rutas configuradas:")
 	log.Println("   - GET /search")
-	log.Println("   - GET /health")
+	log.Println("   - GET /search/stream (SSE)")
+	log.Println("   - GET /search/similar/:propertyId?limit=")
+	log.Println("   - POST /search/events (JWT, clicks para personalized=true)")
+	log.Println("   - POST /search/click (clicks en resultados para el CTR; con JWT también para personalized=true)")
+	log.Println("   - GET /search/history?limit=, DELETE /search/history?id= (JWT o interno con userId)")
+	log.Println("   - GET /search/analytics/top-queries (admin)")
+	log.Println("   - GET /search/analytics/zero-results (admin)")
+	log.Println("   - GET /search/analytics/ctr (admin)")
+	log.Println("   - GET /search/analytics/daily (admin)")
+	log.Println("   - GET /admin/cache/stats (admin)")
+	log.Println("   - GET /admin/cache/ttl (admin)")
+	log.Println("   - DELETE /admin/cache (admin)")
+	log.Println("   - GET/POST /admin/reconcile (admin)")
+	log.Println("   - GET/POST /admin/reindex (admin)")
+	log.Println("   - GET /admin/loadtest/scenario?format=json|k6|vegeta (admin)")
+	log.Println("   - GET /admin/consumer/status (admin)")
+	log.Println("   - GET /health/live")
+	log.Println("   - GET /health/ready")
+	log.Println("   - GET /metrics")
 
 	// ============================================
-	// SECCIÓN 7: CONFIGURAR MIDDLEWARE DE CORS
+	// SECCIÓN 7: CONFIGURAR MIDDLEWARE DE REQUEST ID, RECOVERY, CORS Y HEADERS DE SEGURIDAD
 	// ============================================
-	log.Println("🌐 Configurando middleware de CORS...")
+	log.Println("🌐 Configurando middleware de request ID, recovery, CORS y headers de seguridad...")
 
-	// Handler con middleware de CORS
-	handler := corsMiddleware(mux)
+	// Handler con request ID, recuperación de panics, CORS y headers de seguridad
+	handler := middleware.RequestID(middleware.Recovery(errorReporter,
+		middleware.SecurityHeaders(cfg.HSTSMaxAge, corsMiddleware(mux))))
 
 	// ============================================
 	// SECCIÓN 8: CONFIGURAR SERVIDOR HTTP
@@ -116,6 +515,8 @@ func main() {
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
+	// Los streams SSE no terminan solos: al iniciar el shutdown se cierran sus suscripciones
+	server.RegisterOnShutdown(streamService.Close)
 
 	// ============================================
 	// SECCIÓN 9: MANEJAR GRACEFUL SHUTDOWN
@@ -152,27 +553,59 @@ func main() {
 	log.Println("👋 Search API finalizada")
 }
 
-// healthHandler maneja las peticiones GET /health
-// Retorna un JSON con el estado del servicio
-func healthHandler(w http.ResponseWriter, r *http.Request) {
-	// Solo permitir método GET
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	// Configurar headers
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-
-	// Escribir respuesta JSON
-	response := map[string]string{
-		"status": "ok",
-	}
+// metricsHandler maneja las peticiones GET /metrics
+// Expone el estado de los circuit breakers, el backpressure y el backlog del consumidor (una vez conectado), el change
+// stream (si está activo), el caché, los streams de búsqueda, la reconciliación del índice y los jobs programados
+// en formato de texto de Prometheus, y las invalidaciones del caché entre réplicas (si están conectadas)
+func metricsHandler(consumer *atomic.Pointer[consumers.RabbitMQConsumer], invalidationBus *atomic.Pointer[consumers.CacheInvalidationBus], consumerMonitor services.ConsumerMonitorService, changeStream *consumers.PropertyChangeStream, cache services.CacheService, stream services.SearchStreamService, reconciliation services.ReconciliationService, jobRunner jobs.Runner) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
 
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("⚠️ Error escribiendo respuesta de health: %v", err)
-		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		if err := resilience.WriteCircuitBreakerMetrics(w); err != nil {
+			log.Printf("⚠️ Error escribiendo métricas: %v", err)
+			return
+		}
+		if rabbitConsumer := consumer.Load(); rabbitConsumer != nil {
+			if err := rabbitConsumer.WriteMetrics(w); err != nil {
+				log.Printf("⚠️ Error escribiendo métricas del consumidor: %v", err)
+				return
+			}
+		}
+		if err := consumerMonitor.WriteMetrics(w); err != nil {
+			log.Printf("⚠️ Error escribiendo métricas del backlog del consumidor: %v", err)
+			return
+		}
+		if changeStream != nil {
+			if err := changeStream.WriteMetrics(w); err != nil {
+				log.Printf("⚠️ Error escribiendo métricas del change stream: %v", err)
+				return
+			}
+		}
+		if err := cache.WriteMetrics(w); err != nil {
+			log.Printf("⚠️ Error escribiendo métricas del caché: %v", err)
+			return
+		}
+		if bus := invalidationBus.Load(); bus != nil {
+			if err := bus.WriteMetrics(w); err != nil {
+				log.Printf("⚠️ Error escribiendo métricas de las invalidaciones del caché: %v", err)
+				return
+			}
+		}
+		if err := stream.WriteMetrics(w); err != nil {
+			log.Printf("⚠️ Error escribiendo métricas de los streams: %v", err)
+			return
+		}
+		if err := reconciliation.WriteMetrics(w); err != nil {
+			log.Printf("⚠️ Error escribiendo métricas de la reconciliación: %v", err)
+			return
+		}
+		if err := jobRunner.WriteMetrics(w); err != nil {
+			log.Printf("⚠️ Error escribiendo métricas de los jobs: %v", err)
+		}
 	}
 }
 
@@ -195,3 +628,19 @@ func corsMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// connectMongo conecta con MongoDB y verifica la conexión
+func connectMongo(settings config.MongoDBConfig) (*mongo.Client, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(settings.URI))
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Ping(ctx, nil); err != nil {
+		client.Disconnect(context.Background())
+		return nil, err
+	}
+	return client, nil
+}
@@ -0,0 +1,235 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"search-api/config"
+	"search-api/dto"
+)
+
+// velocityWindow es la ventana usada para contar requests por IP
+const velocityWindow = time.Minute
+
+// honeypotPenalty es el puntaje que suma un request que completa un honeypot
+const honeypotPenalty = 1000
+
+// missingUserAgentPenalty es el puntaje que suma un request sin User-Agent
+const missingUserAgentPenalty = 10
+
+// ipActivity guarda los timestamps de los requests recientes de una IP
+type ipActivity struct {
+	requests []time.Time
+	penalty  int
+
+	// captchaToken es el último token de CAPTCHA aceptado: sirve para bajar el score una sola vez
+	captchaToken string
+}
+
+// BotDetector puntúa a cada IP según su velocidad de requests y señales sospechosas
+// Clientes con puntaje alto reciben respuestas degradadas o un 429 con desafío CAPTCHA
+type BotDetector struct {
+	cfg         config.BotDetectionConfig
+	partnerKeys map[string]bool
+	honeypots   []string
+	clientIPs   *ClientIPResolver
+
+	mu       sync.Mutex
+	activity map[string]*ipActivity
+}
+
+// NewBotDetector crea un detector de bots y arranca la limpieza periódica de IPs inactivas
+// clientIPs identifica a cada cliente (solo acepta X-Forwarded-For de los proxies confiables)
+func NewBotDetector(cfg config.BotDetectionConfig, clientIPs *ClientIPResolver) *BotDetector {
+	partnerKeys := make(map[string]bool, len(cfg.PartnerAPIKeys))
+	for _, key := range cfg.PartnerAPIKeys {
+		partnerKeys[key] = true
+	}
+
+	detector := &BotDetector{
+		cfg:         cfg,
+		partnerKeys: partnerKeys,
+		honeypots:   cfg.HoneypotParams,
+		clientIPs:   clientIPs,
+		activity:    make(map[string]*ipActivity),
+	}
+
+	go detector.cleanupLoop()
+
+	return detector
+}
+
+// Middleware aplica la detección de bots al handler recibido
+func (d *BotDetector) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !d.cfg.Enabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Los callers internos/admin y los partners con API key válida nunca se limitan
+		if IsPrivileged(r.Context()) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if apiKey := r.Header.Get("X-API-Key"); apiKey != "" && d.partnerKeys[apiKey] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ip := d.clientIPs.ClientIP(r)
+
+		// Un token de CAPTCHA válido para esta IP reinicia su score una vez; el request igual se puntúa,
+		// así que reusar el token no evita que un scraper vuelva a quedar bloqueado
+		if token := r.Header.Get(d.cfg.CaptchaTokenHeader); token != "" && d.cfg.CaptchaSecret != "" {
+			if VerifyCaptchaToken(d.cfg.CaptchaSecret, token, ip, time.Now()) {
+				d.acceptCaptcha(ip, token)
+			} else {
+				log.Printf("⚠️ Token de CAPTCHA inválido o vencido - IP: %s", ip)
+			}
+		}
+
+		score := d.score(ip, r)
+		w.Header().Set("X-Bot-Score", strconv.Itoa(score))
+
+		if score >= d.cfg.BlockThreshold {
+			log.Printf("🤖 Request bloqueado por sospecha de bot - IP: %s, score: %d", ip, score)
+			w.Header().Set("Retry-After", strconv.Itoa(int(velocityWindow.Seconds())))
+			w.Header().Set("X-Bot-Challenge", "captcha")
+			writeError(w, http.StatusTooManyRequests, "Demasiadas búsquedas, resolvé el desafío para continuar")
+			return
+		}
+
+		if score >= d.cfg.DegradeThreshold {
+			log.Printf("🐢 Respuesta degradada por sospecha de bot - IP: %s, score: %d", ip, score)
+			w.Header().Set("X-Bot-Degraded", "true")
+			degradeRequest(r, d.cfg.DegradedPageSize)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// score registra el request y calcula el puntaje actual de la IP
+func (d *BotDetector) score(ip string, r *http.Request) int {
+	now := time.Now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	activity, exists := d.activity[ip]
+	if !exists {
+		activity = &ipActivity{}
+		d.activity[ip] = activity
+	}
+
+	activity.requests = append(pruneBefore(activity.requests, now.Add(-velocityWindow)), now)
+
+	query := r.URL.Query()
+	for _, param := range d.honeypots {
+		if query.Get(param) != "" {
+			activity.penalty += honeypotPenalty
+			break
+		}
+	}
+	if r.UserAgent() == "" {
+		activity.penalty += missingUserAgentPenalty
+	}
+
+	return len(activity.requests) + activity.penalty
+}
+
+// acceptCaptcha limpia el historial de una IP que resolvió el CAPTCHA, solo la primera vez que usa el token
+func (d *BotDetector) acceptCaptcha(ip, token string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if activity, exists := d.activity[ip]; exists && activity.captchaToken == token {
+		return
+	}
+	d.activity[ip] = &ipActivity{captchaToken: token}
+}
+
+// SignCaptchaToken genera el token que el verificador del CAPTCHA le entrega al cliente
+// Formato: "<vencimiento en segundos Unix>.<HMAC-SHA256 en hex de "ip\nvencimiento">"
+// El token solo sirve para la IP que resolvió el desafío y hasta que vence
+func SignCaptchaToken(secret, ip string, expires time.Time) string {
+	expiresAt := strconv.FormatInt(expires.Unix(), 10)
+	return expiresAt + "." + captchaSignature(secret, ip, expiresAt)
+}
+
+// VerifyCaptchaToken valida la firma, la IP y el vencimiento de un token de CAPTCHA
+func VerifyCaptchaToken(secret, token, ip string, now time.Time) bool {
+	expiresAt, signature, found := strings.Cut(token, ".")
+	if !found {
+		return false
+	}
+	expires, err := strconv.ParseInt(expiresAt, 10, 64)
+	if err != nil || now.Unix() > expires {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(captchaSignature(secret, ip, expiresAt)))
+}
+
+// captchaSignature firma la IP y el vencimiento de un token de CAPTCHA
+func captchaSignature(secret, ip, expiresAt string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ip + "\n" + expiresAt))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// cleanupLoop elimina periódicamente las IPs sin actividad reciente
+func (d *BotDetector) cleanupLoop() {
+	ticker := time.NewTicker(velocityWindow)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		cutoff := time.Now().Add(-velocityWindow)
+
+		d.mu.Lock()
+		for ip, activity := range d.activity {
+			activity.requests = pruneBefore(activity.requests, cutoff)
+			if len(activity.requests) == 0 {
+				delete(d.activity, ip)
+			}
+		}
+		d.mu.Unlock()
+	}
+}
+
+// pruneBefore descarta los timestamps anteriores al corte
+func pruneBefore(requests []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(requests) && requests[i].Before(cutoff) {
+		i++
+	}
+	return requests[i:]
+}
+
+// degradeRequest limita el pageSize del request para clientes sospechosos
+func degradeRequest(r *http.Request, maxPageSize int) {
+	query := r.URL.Query()
+	pageSize, err := strconv.Atoi(query.Get("pageSize"))
+	if err != nil || pageSize > maxPageSize {
+		query.Set("pageSize", strconv.Itoa(maxPageSize))
+		r.URL.RawQuery = query.Encode()
+	}
+}
+
+// writeError escribe una respuesta de error en formato JSON
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(dto.ErrorResponse{Error: message, Code: statusCode}); err != nil {
+		log.Printf("⚠️ Error escribiendo respuesta de error: %v", err)
+	}
+}
@@ -0,0 +1,174 @@
+package middleware
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"search-api/config"
+)
+
+const testCaptchaSecret = "captcha-secret"
+
+// newTestBotDetector crea un detector con umbrales bajos detrás del gateway de docker (172.16.0.0/12)
+func newTestBotDetector(t *testing.T) (*BotDetector, http.Handler) {
+	t.Helper()
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	clientIPs, err := NewClientIPResolver([]string{"172.16.0.0/12"})
+	if err != nil {
+		t.Fatalf("unexpected resolver error: %v", err)
+	}
+	detector := NewBotDetector(config.BotDetectionConfig{
+		Enabled:            true,
+		DegradeThreshold:   3,
+		BlockThreshold:     5,
+		DegradedPageSize:   5,
+		HoneypotParams:     []string{"website"},
+		CaptchaTokenHeader: "X-Captcha-Token",
+		CaptchaSecret:      testCaptchaSecret,
+	}, clientIPs)
+
+	handler := detector.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Page-Size", r.URL.Query().Get("pageSize"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	return detector, handler
+}
+
+// searchFrom arma un request a /search que llega por nginx desde clientIP
+func searchFrom(clientIP, query string) *http.Request {
+	request := httptest.NewRequest(http.MethodGet, "/search"+query, nil)
+	request.RemoteAddr = "172.18.0.5:41000"
+	request.Header.Set("X-Forwarded-For", clientIP)
+	request.Header.Set("User-Agent", "Mozilla/5.0")
+	return request
+}
+
+func serve(handler http.Handler, request *http.Request) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestBotDetector_ScoresVelocityAndSuspiciousSignals(t *testing.T) {
+	_, handler := newTestBotDetector(t)
+
+	// Los primeros requests pasan sin cambios, desde el umbral de degradación se limita el pageSize
+	for i := 1; i <= 2; i++ {
+		if recorder := serve(handler, searchFrom("203.0.113.10", "?pageSize=50")); recorder.Header().Get("X-Page-Size") != "50" {
+			t.Fatalf("request %d: expected the page size to be untouched, got %q", i, recorder.Header().Get("X-Page-Size"))
+		}
+	}
+	recorder := serve(handler, searchFrom("203.0.113.10", "?pageSize=50"))
+	if recorder.Header().Get("X-Bot-Degraded") != "true" || recorder.Header().Get("X-Page-Size") != "5" {
+		t.Fatalf("expected a degraded response with pageSize 5, got headers %v", recorder.Header())
+	}
+	serve(handler, searchFrom("203.0.113.10", ""))
+	recorder = serve(handler, searchFrom("203.0.113.10", ""))
+	if recorder.Code != http.StatusTooManyRequests || recorder.Header().Get("X-Bot-Challenge") != "captcha" {
+		t.Fatalf("expected 429 with a captcha challenge, got %d", recorder.Code)
+	}
+
+	// Otra IP tiene su propio score
+	if recorder := serve(handler, searchFrom("203.0.113.20", "")); recorder.Code != http.StatusOK || recorder.Header().Get("X-Bot-Score") != "1" {
+		t.Errorf("expected score 1 for a new IP, got %d with score %q", recorder.Code, recorder.Header().Get("X-Bot-Score"))
+	}
+
+	// Un honeypot bloquea en el primer request y la falta de User-Agent suma puntaje
+	if recorder := serve(handler, searchFrom("203.0.113.30", "?website=spam")); recorder.Code != http.StatusTooManyRequests {
+		t.Errorf("expected a honeypot hit to be blocked, got %d", recorder.Code)
+	}
+	noAgent := searchFrom("203.0.113.40", "")
+	noAgent.Header.Del("User-Agent")
+	if recorder := serve(handler, noAgent); recorder.Code != http.StatusTooManyRequests {
+		t.Errorf("expected a request without User-Agent to score above the block threshold, got %d", recorder.Code)
+	}
+}
+
+func TestBotDetector_CaptchaTokenCannotBeForgedOrReused(t *testing.T) {
+	_, handler := newTestBotDetector(t)
+	ip := "203.0.113.10"
+	for i := 0; i < 5; i++ {
+		serve(handler, searchFrom(ip, ""))
+	}
+
+	// El header viejo, un token sin firma válida, de otra IP o vencido no bajan el score
+	forged := []string{
+		"true",
+		"9999999999.deadbeef",
+		SignCaptchaToken("otro-secreto", ip, time.Now().Add(time.Minute)),
+		SignCaptchaToken(testCaptchaSecret, "198.51.100.1", time.Now().Add(time.Minute)),
+		SignCaptchaToken(testCaptchaSecret, ip, time.Now().Add(-time.Minute)),
+	}
+	for _, token := range forged {
+		request := searchFrom(ip, "")
+		request.Header.Set("X-Captcha-Verified", "true")
+		request.Header.Set("X-Captcha-Token", token)
+		if recorder := serve(handler, request); recorder.Code != http.StatusTooManyRequests {
+			t.Errorf("expected token %q not to unblock the client, got %d", token, recorder.Code)
+		}
+	}
+
+	// Un token válido reinicia el score una sola vez
+	token := SignCaptchaToken(testCaptchaSecret, ip, time.Now().Add(time.Minute))
+	for i := 1; i <= 6; i++ {
+		request := searchFrom(ip, "")
+		request.Header.Set("X-Captcha-Token", token)
+		recorder := serve(handler, request)
+		if i < 5 && recorder.Code != http.StatusOK {
+			t.Fatalf("request %d after solving the captcha: expected 200, got %d", i, recorder.Code)
+		}
+		if i == 6 && recorder.Code != http.StatusTooManyRequests {
+			t.Fatalf("expected reusing the captcha token not to reset the score again, got %d", recorder.Code)
+		}
+	}
+}
+
+func TestClientIPResolver_OnlyTrustsConfiguredProxies(t *testing.T) {
+	resolver, err := NewClientIPResolver([]string{"172.16.0.0/12", "10.0.0.1"})
+	if err != nil {
+		t.Fatalf("unexpected resolver error: %v", err)
+	}
+	if _, err := NewClientIPResolver([]string{"not-a-network/8"}); err == nil {
+		t.Error("expected an invalid trusted proxy to be rejected")
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		realIP     string
+		want       string
+	}{
+		{"direct client ignores headers", "198.51.100.7:5000", []string{"1.2.3.4"}, "5.6.7.8", "198.51.100.7"},
+		{"nginx appends the real client", "172.18.0.5:5000", []string{"203.0.113.10"}, "", "203.0.113.10"},
+		{"spoofed leftmost entry is ignored", "172.18.0.5:5000", []string{"1.2.3.4, 203.0.113.10"}, "", "203.0.113.10"},
+		{"trusted hops are skipped", "172.18.0.5:5000", []string{"203.0.113.10, 10.0.0.1"}, "", "203.0.113.10"},
+		{"multiple headers are joined", "172.18.0.5:5000", []string{"1.2.3.4", "203.0.113.10"}, "", "203.0.113.10"},
+		{"invalid entry stops the walk", "172.18.0.5:5000", []string{"1.2.3.4, garbage"}, "203.0.113.10", "203.0.113.10"},
+		{"falls back to X-Real-IP", "172.18.0.5:5000", nil, "203.0.113.10", "203.0.113.10"},
+		{"falls back to the connection", "172.18.0.5:5000", nil, "", "172.18.0.5"},
+		{"ipv6 client", "172.18.0.5:5000", []string{"2001:db8::1"}, "", "2001:db8::1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/search", nil)
+			request.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwarded {
+				request.Header.Add("X-Forwarded-For", value)
+			}
+			if tt.realIP != "" {
+				request.Header.Set("X-Real-IP", tt.realIP)
+			}
+			if got := resolver.ClientIP(request); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
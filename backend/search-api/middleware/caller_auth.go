@@ -0,0 +1,239 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"search-api/config"
+	"search-api/rpc"
+	"signing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// privilegedKey es la clave de contexto que marca a un caller interno o admin
+type privilegedKey struct{}
+
+// userKey es la clave de contexto del usuario autenticado con un JWT de users-api
+type userKey struct{}
+
+// AuthenticatedUser es el usuario identificado por el JWT del request
+type AuthenticatedUser struct {
+	ID       uint
+	Username string
+	UserType string
+}
+
+// callerClaims son los claims del JWT emitido por users-api
+type callerClaims struct {
+	UserID   uint   `json:"user_id"`
+	Username string `json:"username"`
+	UserType string `json:"user_type"`
+	jwt.RegisteredClaims
+}
+
+// CallerAuth identifica al caller: usuarios con un JWT de users-api (para personalizar
+// las búsquedas) y callers privilegiados (servicios internos con X-Internal-Token o un request
+// firmado con INTERNAL_SIGNING_SECRET, y admins).
+// No rechaza requests anónimos ni JWT inválidos, solo marca el contexto para que los handlers decidan
+// Los JWT bien firmados se validan además con users-api (VerifyToken), que conoce la lista de revocación
+type CallerAuth struct {
+	jwtSecret      []byte
+	internalTokens map[string]bool
+	signingSecret  string
+	maxSkew        time.Duration
+	nonces         *signing.NonceCache
+
+	users         rpc.UsersServiceClient
+	tokenCacheTTL time.Duration
+	verifyTimeout time.Duration
+
+	mu     sync.Mutex
+	tokens map[string]tokenVerification // Por hash del token, para no guardar el valor en memoria
+}
+
+// tokenVerification es una respuesta de VerifyToken guardada en la caché
+type tokenVerification struct {
+	user      AuthenticatedUser
+	ok        bool
+	expiresAt time.Time
+}
+
+// maxSignedBodyBytes limita el body que se lee para validar la firma de un request interno
+const maxSignedBodyBytes = 1 << 20
+
+// NewCallerAuth crea el autenticador de callers; users es el stub gRPC de users-api que valida los JWT
+func NewCallerAuth(cfg config.AuthConfig, users rpc.UsersServiceClient) *CallerAuth {
+	internalTokens := make(map[string]bool, len(cfg.InternalTokens))
+	for _, token := range cfg.InternalTokens {
+		internalTokens[token] = true
+	}
+
+	return &CallerAuth{
+		jwtSecret:      []byte(cfg.JWTSecret),
+		internalTokens: internalTokens,
+		signingSecret:  cfg.SigningSecret,
+		maxSkew:        cfg.SignatureMaxSkew,
+		nonces:         signing.NewNonceCache(2 * cfg.SignatureMaxSkew),
+
+		users:         users,
+		tokenCacheTTL: cfg.TokenCacheTTL,
+		verifyTimeout: cfg.TokenVerifyTimeout,
+		tokens:        make(map[string]tokenVerification),
+	}
+}
+
+// Middleware marca el contexto del request con el usuario autenticado y si el caller es privilegiado
+func (a *CallerAuth) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		user, authenticated := a.parseUser(r)
+		if authenticated {
+			ctx = context.WithValue(ctx, userKey{}, user)
+			setRequestUser(ctx, user.ID)
+		}
+		if a.hasInternalToken(r) || a.hasValidSignature(r) || (authenticated && user.UserType == "admin") {
+			ctx = context.WithValue(ctx, privilegedKey{}, true)
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// hasInternalToken valida el token de los servicios internos
+func (a *CallerAuth) hasInternalToken(r *http.Request) bool {
+	token := r.Header.Get("X-Internal-Token")
+	return token != "" && a.internalTokens[token]
+}
+
+// hasValidSignature valida la firma HMAC de un request interno (ver signing.InternalSignature)
+// Se firman el servicio que llama, el método, el path con la query, el timestamp, el nonce y el SHA-256 del body
+// Una firma inválida, vencida o con un nonce repetido deja el request como no privilegiado
+func (a *CallerAuth) hasValidSignature(r *http.Request) bool {
+	signature := r.Header.Get(signing.InternalSignatureHeader)
+	if signature == "" || a.signingSecret == "" {
+		return false
+	}
+
+	caller := r.Header.Get(signing.InternalCallerHeader)
+	timestamp := r.Header.Get(signing.InternalTimestampHeader)
+	nonce := r.Header.Get(signing.InternalNonceHeader)
+	now := time.Now()
+	if caller == "" || nonce == "" {
+		return false
+	}
+	if err := signing.CheckInternalTimestamp(timestamp, now, a.maxSkew); err != nil {
+		log.Printf("⚠️ Request interno firmado rechazado en %s: %v", r.URL.Path, err)
+		return false
+	}
+
+	// El body se lee para firmarlo y se vuelve a dejar disponible (completo) para el handler
+	var body []byte
+	if r.Body != nil {
+		read, err := io.ReadAll(io.LimitReader(r.Body, maxSignedBodyBytes+1))
+		r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(read), r.Body), Closer: r.Body}
+		if err != nil || len(read) > maxSignedBodyBytes {
+			return false
+		}
+		body = read
+	}
+
+	if !signing.VerifyInternalSignature(a.signingSecret, signature,
+		caller, r.Method, r.URL.RequestURI(), timestamp, nonce, signing.HashInternalBody(body)) {
+		log.Printf("⚠️ Firma interna inválida en %s %s (caller '%s')", r.Method, r.URL.Path, caller)
+		return false
+	}
+	if !a.nonces.Remember(nonce, now) {
+		log.Printf("⚠️ Request interno repetido en %s %s (caller '%s')", r.Method, r.URL.Path, caller)
+		return false
+	}
+	return true
+}
+
+// readCloser devuelve al request el body ya leído seguido del resto, cerrando el original
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// parseUser valida el JWT de users-api; sin token, con uno inválido o revocado el request sigue como anónimo
+func (a *CallerAuth) parseUser(r *http.Request) (AuthenticatedUser, bool) {
+	authHeader := r.Header.Get("Authorization")
+	tokenString, found := strings.CutPrefix(authHeader, "Bearer ")
+	if !found || tokenString == "" {
+		return AuthenticatedUser{}, false
+	}
+
+	claims := &callerClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return a.jwtSecret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !token.Valid || claims.UserID == 0 {
+		return AuthenticatedUser{}, false
+	}
+
+	// La firma no alcanza: el token puede estar revocado o el usuario haber cambiado de rol
+	// Si users-api no responde el request sigue como anónimo (nunca como admin)
+	user, ok, err := a.verifyToken(r.Context(), tokenString)
+	if err != nil {
+		log.Printf("⚠️ No se pudo validar el token con users-api: %v", err)
+		return AuthenticatedUser{}, false
+	}
+	return user, ok
+}
+
+// verifyToken consulta VerifyToken a users-api, reutilizando la respuesta por tokenCacheTTL
+func (a *CallerAuth) verifyToken(ctx context.Context, token string) (AuthenticatedUser, bool, error) {
+	sum := sha256.Sum256([]byte(token))
+	cacheKey := hex.EncodeToString(sum[:])
+	now := time.Now()
+
+	a.mu.Lock()
+	cached, found := a.tokens[cacheKey]
+	a.mu.Unlock()
+	if found && now.Before(cached.expiresAt) {
+		return cached.user, cached.ok, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, a.verifyTimeout)
+	defer cancel()
+	response, err := a.users.VerifyToken(ctx, &rpc.VerifyTokenRequest{Token: token})
+	if err != nil {
+		return AuthenticatedUser{}, false, err
+	}
+	result := tokenVerification{ok: response.Valid && response.UserID != 0, expiresAt: now.Add(a.tokenCacheTTL)}
+	if result.ok {
+		result.user = AuthenticatedUser{ID: response.UserID, Username: response.Username, UserType: response.UserType}
+	}
+
+	a.mu.Lock()
+	// Se descartan los vencidos al guardar para que la caché no crezca con tokens viejos
+	for k, entry := range a.tokens {
+		if !now.Before(entry.expiresAt) {
+			delete(a.tokens, k)
+		}
+	}
+	a.tokens[cacheKey] = result
+	a.mu.Unlock()
+
+	return result.user, result.ok, nil
+}
+
+// IsPrivileged indica si el request fue hecho por un servicio interno o un admin
+func IsPrivileged(ctx context.Context) bool {
+	privileged, _ := ctx.Value(privilegedKey{}).(bool)
+	return privileged
+}
+
+// CurrentUser retorna el usuario autenticado con JWT; false si el request es anónimo
+func CurrentUser(ctx context.Context) (AuthenticatedUser, bool) {
+	user, ok := ctx.Value(userKey{}).(AuthenticatedUser)
+	return user, ok
+}
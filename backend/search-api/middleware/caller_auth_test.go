@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"search-api/config"
+	"search-api/rpc"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const testJWTSecret = "secreto-de-users-api"
+
+// verifyingUsersClient responde VerifyToken con una respuesta fija y cuenta las llamadas
+type verifyingUsersClient struct {
+	rpc.UsersServiceClient
+	response *rpc.VerifyTokenResponse
+	err      error
+	calls    int
+}
+
+func (c *verifyingUsersClient) VerifyToken(ctx context.Context, request *rpc.VerifyTokenRequest, opts ...grpc.CallOption) (*rpc.VerifyTokenResponse, error) {
+	c.calls++
+	return c.response, c.err
+}
+
+// adminToken firma un JWT de admin como los de users-api
+func adminToken(t *testing.T, secret string) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, callerClaims{
+		UserID:   7,
+		Username: "lauty",
+		UserType: "admin",
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return token
+}
+
+func TestCallerAuth_ChecksRevocationWithUsersAPI(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	tests := []struct {
+		name           string
+		secret         string
+		users          *verifyingUsersClient
+		wantUser       bool
+		wantPrivileged bool
+		wantCalls      int
+	}{
+		{name: "valid admin token", secret: testJWTSecret, users: &verifyingUsersClient{response: &rpc.VerifyTokenResponse{Valid: true, UserID: 7, UserType: "admin"}}, wantUser: true, wantPrivileged: true, wantCalls: 1},
+		// Logout en todos lados: la firma sigue siendo válida pero users-api lo revocó
+		{name: "revoked admin token", secret: testJWTSecret, users: &verifyingUsersClient{response: &rpc.VerifyTokenResponse{Valid: false}}, wantCalls: 1},
+		// El admin pasó a normal: su token viejo todavía dice admin
+		{name: "demoted admin", secret: testJWTSecret, users: &verifyingUsersClient{response: &rpc.VerifyTokenResponse{Valid: true, UserID: 7, UserType: "normal"}}, wantUser: true, wantCalls: 1},
+		{name: "users-api down", secret: testJWTSecret, users: &verifyingUsersClient{err: status.Error(codes.Unavailable, "users-api no disponible")}, wantCalls: 1},
+		{name: "bad signature never reaches users-api", secret: "otro-secreto", users: &verifyingUsersClient{response: &rpc.VerifyTokenResponse{Valid: true, UserID: 7, UserType: "admin"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth := NewCallerAuth(config.AuthConfig{JWTSecret: testJWTSecret, SignatureMaxSkew: time.Minute, TokenCacheTTL: time.Minute, TokenVerifyTimeout: time.Second}, tt.users)
+			var gotUser, gotPrivileged bool
+			handler := auth.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, gotUser = CurrentUser(r.Context())
+				gotPrivileged = IsPrivileged(r.Context())
+			}))
+
+			request := httptest.NewRequest(http.MethodGet, "/admin/cache/stats", nil)
+			request.Header.Set("Authorization", "Bearer "+adminToken(t, tt.secret))
+			handler.ServeHTTP(httptest.NewRecorder(), request)
+
+			if gotUser != tt.wantUser || gotPrivileged != tt.wantPrivileged {
+				t.Fatalf("expected user=%v privileged=%v, got %v and %v", tt.wantUser, tt.wantPrivileged, gotUser, gotPrivileged)
+			}
+			if tt.users.calls != tt.wantCalls {
+				t.Fatalf("expected %d calls to users-api, got %d", tt.wantCalls, tt.users.calls)
+			}
+		})
+	}
+}
+
+func TestCallerAuth_CachesVerification(t *testing.T) {
+	users := &verifyingUsersClient{response: &rpc.VerifyTokenResponse{Valid: false}}
+	auth := NewCallerAuth(config.AuthConfig{JWTSecret: testJWTSecret, SignatureMaxSkew: time.Minute, TokenCacheTTL: time.Minute, TokenVerifyTimeout: time.Second}, users)
+	handler := auth.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	token := adminToken(t, testJWTSecret)
+
+	for i := 0; i < 3; i++ {
+		request := httptest.NewRequest(http.MethodGet, "/search", nil)
+		request.Header.Set("Authorization", "Bearer "+token)
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+	}
+
+	if users.calls != 1 {
+		t.Fatalf("expected 1 call to users-api, got %d", users.calls)
+	}
+}
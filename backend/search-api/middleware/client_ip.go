@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ClientIPResolver obtiene la IP real del cliente confiando en los headers solo si los agregó un proxy conocido
+// X-Forwarded-For lo puede mandar cualquiera: nginx agrega la IP que ve al final ($proxy_add_x_forwarded_for),
+// así que lo que está a la izquierda de la última entrada de un proxy confiable lo eligió el cliente
+type ClientIPResolver struct {
+	trusted []*net.IPNet
+}
+
+// NewClientIPResolver crea el resolver con las redes de los proxies confiables (CIDRs o IPs sueltas)
+func NewClientIPResolver(trustedProxies []string) (*ClientIPResolver, error) {
+	resolver := &ClientIPResolver{}
+	for _, entry := range trustedProxies {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("proxy confiable inválido '%s': %w", entry, err)
+		}
+		resolver.trusted = append(resolver.trusted, network)
+	}
+	return resolver, nil
+}
+
+// ClientIP retorna la IP del cliente:
+//   - si el request no viene de un proxy confiable, la IP de la conexión (los headers se ignoran)
+//   - si viene de uno, la entrada de X-Forwarded-For más a la derecha que no sea de un proxy confiable
+//   - si X-Forwarded-For no tiene ninguna, X-Real-IP (la que agrega nginx) o la IP de la conexión
+func (r *ClientIPResolver) ClientIP(req *http.Request) string {
+	remote, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		remote = req.RemoteAddr
+	}
+	if !r.isTrusted(remote) {
+		return remote
+	}
+
+	hops := strings.Split(strings.Join(req.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		if net.ParseIP(hop) == nil {
+			// Una entrada inválida no se puede atribuir a ningún proxy: desde acá a la izquierda no es confiable
+			break
+		}
+		if !r.isTrusted(hop) {
+			return hop
+		}
+	}
+
+	if realIP := strings.TrimSpace(req.Header.Get("X-Real-IP")); net.ParseIP(realIP) != nil {
+		return realIP
+	}
+	return remote
+}
+
+// isTrusted indica si la IP pertenece a un proxy confiable
+func (r *ClientIPResolver) isTrusted(raw string) bool {
+	ip := net.ParseIP(raw)
+	if ip == nil {
+		return false
+	}
+	for _, network := range r.trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
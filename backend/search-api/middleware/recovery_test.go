@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"reporting"
+)
+
+// recordingReporter guarda los eventos reportados
+type recordingReporter struct {
+	mu     sync.Mutex
+	events []reporting.ErrorEvent
+}
+
+func (r *recordingReporter) Report(event reporting.ErrorEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recordingReporter) Close(timeout time.Duration) {}
+
+func TestRecovery_ReportsPanicWithRequestAndUser(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	reporter := &recordingReporter{}
+	handler := RequestID(Recovery(reporter, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		setRequestUser(r.Context(), 42)
+		panic("boom")
+	})))
+
+	request := httptest.NewRequest(http.MethodGet, "/search", nil)
+	request.Header.Set(RequestIDHeader, "req-123")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", recorder.Code)
+	}
+	if got := recorder.Header().Get(RequestIDHeader); got != "req-123" {
+		t.Fatalf("expected request ID header req-123, got %q", got)
+	}
+	if len(reporter.events) != 1 {
+		t.Fatalf("expected 1 reported event, got %d", len(reporter.events))
+	}
+	event := reporter.events[0]
+	if event.Level != reporting.ErrorLevelFatal || event.Message != "panic: boom" || event.Stacktrace == "" {
+		t.Fatalf("unexpected event: %+v", event)
+	}
+	if event.Tags["request_id"] != "req-123" || event.Tags["user_id"] != "42" || event.Tags["path"] != "/search" {
+		t.Fatalf("unexpected tags: %+v", event.Tags)
+	}
+}
+
+func TestRecovery_ReportsServerErrorsOnly(t *testing.T) {
+	reporter := &recordingReporter{}
+	status := http.StatusOK
+	handler := RequestID(Recovery(reporter, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	})))
+
+	for _, code := range []int{http.StatusOK, http.StatusBadRequest, http.StatusServiceUnavailable} {
+		status = code
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/search", nil))
+	}
+
+	if len(reporter.events) != 1 || reporter.events[0].Tags["status"] != "503" {
+		t.Fatalf("expected only the 503 to be reported, got %+v", reporter.events)
+	}
+	if reporter.events[0].Tags["request_id"] == "" {
+		t.Fatal("expected a generated request ID")
+	}
+}
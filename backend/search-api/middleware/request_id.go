@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"sync/atomic"
+)
+
+// RequestIDHeader es el header con el que llega (del gateway o de otro servicio) y se devuelve el ID del request
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength evita que un header arbitrario termine en los logs y en los reportes de errores
+const maxRequestIDLength = 128
+
+// requestScopeKey es la clave de contexto de los datos del request para logs y reportes de errores
+type requestScopeKey struct{}
+
+// requestScope se crea en RequestID; CallerAuth completa el usuario más adelante en la cadena,
+// por eso es un puntero compartido y no un valor de contexto nuevo
+type requestScope struct {
+	id     string
+	userID atomic.Uint64
+}
+
+// RequestID toma el X-Request-ID del request (o genera uno), lo devuelve en la respuesta
+// y lo guarda en el contexto para taggear los logs y los errores reportados
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" || len(id) > maxRequestIDLength {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		ctx := context.WithValue(r.Context(), requestScopeKey{}, &requestScope{id: id})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequestIDFrom retorna el ID del request (vacío fuera de un request HTTP)
+func RequestIDFrom(ctx context.Context) string {
+	if scope, ok := ctx.Value(requestScopeKey{}).(*requestScope); ok {
+		return scope.id
+	}
+	return ""
+}
+
+// requestUserID retorna el usuario autenticado del request (0 si es anónimo)
+func requestUserID(ctx context.Context) uint {
+	if scope, ok := ctx.Value(requestScopeKey{}).(*requestScope); ok {
+		return uint(scope.userID.Load())
+	}
+	return 0
+}
+
+// setRequestUser registra el usuario autenticado para los reportes de errores
+func setRequestUser(ctx context.Context, userID uint) {
+	if scope, ok := ctx.Value(requestScopeKey{}).(*requestScope); ok {
+		scope.userID.Store(uint64(userID))
+	}
+}
+
+// newRequestID genera un ID aleatorio de 32 caracteres hexadecimales
+func newRequestID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxAge   time.Duration
+		method   string
+		status   int
+		wantHSTS string
+	}{
+		{name: "with HSTS", maxAge: 180 * 24 * time.Hour, method: http.MethodGet, status: http.StatusOK, wantHSTS: "max-age=15552000; includeSubDomains"},
+		{name: "HSTS disabled", maxAge: 0, method: http.MethodGet, status: http.StatusOK},
+		// El preflight lo responde CORS sin llegar al mux: igual lleva los headers
+		{name: "preflight", maxAge: time.Hour, method: http.MethodOptions, status: http.StatusNoContent, wantHSTS: "max-age=3600; includeSubDomains"},
+		{name: "error response", maxAge: time.Hour, method: http.MethodGet, status: http.StatusInternalServerError, wantHSTS: "max-age=3600; includeSubDomains"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := SecurityHeaders(tt.maxAge, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(tt.method, "/search", nil))
+
+			if recorder.Code != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, recorder.Code)
+			}
+			if recorder.Header().Get("X-Content-Type-Options") != "nosniff" || recorder.Header().Get("X-Frame-Options") != "DENY" {
+				t.Fatalf("expected nosniff and DENY, got %v", recorder.Header())
+			}
+			if got := recorder.Header().Get("Strict-Transport-Security"); got != tt.wantHSTS {
+				t.Fatalf("expected Strict-Transport-Security %q, got %q", tt.wantHSTS, got)
+			}
+		})
+	}
+}
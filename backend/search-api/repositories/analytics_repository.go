@@ -0,0 +1,169 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"search-api/domain"
+)
+
+// AnalyticsRepository define la interfaz para guardar y consultar las búsquedas realizadas
+// Implementaciones: NewMongoAnalyticsRepository (compartida por las réplicas) y NewAnalyticsRepository
+type AnalyticsRepository interface {
+	// Record guarda una búsqueda
+	Record(ctx context.Context, event domain.SearchEvent) error
+
+	// ListSince retorna las búsquedas realizadas desde el momento indicado, de la más antigua a la más reciente
+	ListSince(ctx context.Context, since time.Time) ([]domain.SearchEvent, error)
+
+	// RecordClick guarda un click en un resultado de búsqueda
+	RecordClick(ctx context.Context, event domain.ClickEvent) error
+
+	// ListClicksSince retorna los clicks desde el momento indicado, del más antiguo al más reciente
+	ListClicksSince(ctx context.Context, since time.Time) ([]domain.ClickEvent, error)
+
+	// CompleteSince retorna desde cuándo los reportes incluyen todos los eventos (ver cada implementación)
+	CompleteSince(ctx context.Context) (time.Time, error)
+
+	// EraseUser desvincula los clicks del usuario (quedan como anónimos para las métricas)
+	EraseUser(ctx context.Context, userID uint) error
+}
+
+// analyticsRepository guarda las búsquedas y los clicks en memoria en buffers circulares
+// Cuando se llenan se descartan los eventos más antiguos, así el consumo de memoria es acotado
+// Cada réplica tiene los suyos: solo sirve con una réplica (DATA_STORE=memory)
+type analyticsRepository struct {
+	mu        sync.RWMutex
+	searches  *eventRing[domain.SearchEvent]
+	clicks    *eventRing[domain.ClickEvent]
+	startedAt time.Time
+}
+
+// NewAnalyticsRepository crea un repositorio de analytics que retiene hasta maxEvents búsquedas
+// (y hasta maxEvents clicks)
+func NewAnalyticsRepository(maxEvents int) AnalyticsRepository {
+	if maxEvents <= 0 {
+		maxEvents = 1
+	}
+
+	return &analyticsRepository{
+		searches:  newEventRing[domain.SearchEvent](maxEvents),
+		clicks:    newEventRing[domain.ClickEvent](maxEvents),
+		startedAt: time.Now().UTC(),
+	}
+}
+
+// Record guarda una búsqueda, pisando la más antigua si el buffer está lleno
+func (r *analyticsRepository) Record(ctx context.Context, event domain.SearchEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.searches.add(event)
+	return nil
+}
+
+// ListSince retorna las búsquedas desde el momento indicado, de la más antigua a la más reciente
+func (r *analyticsRepository) ListSince(ctx context.Context, since time.Time) ([]domain.SearchEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]domain.SearchEvent, 0)
+	for _, event := range r.searches.ordered() {
+		if !event.SearchedAt.Before(since) {
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}
+
+// RecordClick guarda un click, pisando el más antiguo si el buffer está lleno
+func (r *analyticsRepository) RecordClick(ctx context.Context, event domain.ClickEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.clicks.add(event)
+	return nil
+}
+
+// ListClicksSince retorna los clicks desde el momento indicado, del más antiguo al más reciente
+func (r *analyticsRepository) ListClicksSince(ctx context.Context, since time.Time) ([]domain.ClickEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]domain.ClickEvent, 0)
+	for _, event := range r.clicks.ordered() {
+		if !event.ClickedAt.Before(since) {
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}
+
+// CompleteSince retorna el momento desde el que los reportes no perdieron eventos: el arranque del proceso
+// o el más antiguo que sigue guardado en cada buffer lleno (los descartados son anteriores a él)
+func (r *analyticsRepository) CompleteSince(ctx context.Context) (time.Time, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	since := r.startedAt
+	if oldest, ok := r.searches.oldest(); ok && oldest.SearchedAt.After(since) {
+		since = oldest.SearchedAt
+	}
+	if oldest, ok := r.clicks.oldest(); ok && oldest.ClickedAt.After(since) {
+		since = oldest.ClickedAt
+	}
+	return since, nil
+}
+
+// EraseUser borra el UserID de los clicks guardados del usuario
+func (r *analyticsRepository) EraseUser(ctx context.Context, userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.clicks.events {
+		if r.clicks.events[i].UserID == userID {
+			r.clicks.events[i].UserID = 0
+		}
+	}
+	return nil
+}
+
+// eventRing es un buffer circular de eventos de tamaño fijo (no es seguro para uso concurrente)
+type eventRing[T any] struct {
+	events []T
+	next   int
+	full   bool
+}
+
+// newEventRing crea un buffer para size eventos
+func newEventRing[T any](size int) *eventRing[T] {
+	return &eventRing[T]{events: make([]T, size)}
+}
+
+// add agrega un evento, pisando el más antiguo si el buffer está lleno
+func (r *eventRing[T]) add(event T) {
+	r.events[r.next] = event
+	r.next = (r.next + 1) % len(r.events)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// ordered retorna los eventos guardados del más antiguo al más reciente
+func (r *eventRing[T]) ordered() []T {
+	var ordered []T
+	if r.full {
+		ordered = append(ordered, r.events[r.next:]...)
+	}
+	return append(ordered, r.events[:r.next]...)
+}
+
+// oldest retorna el evento más antiguo guardado solo si el buffer ya pisó alguno
+func (r *eventRing[T]) oldest() (T, bool) {
+	if !r.full {
+		var zero T
+		return zero, false
+	}
+	return r.events[r.next], true
+}
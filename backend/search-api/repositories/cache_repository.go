@@ -2,156 +2,411 @@ package repositories
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"path"
+	"sync/atomic"
 	"time"
 
-	"search-api/domain"
+	"search-api/dto"
 
-	"github.com/bradfitz/gomemcache/memcache"
 	"github.com/karlseguin/ccache/v3"
 )
 
+// ErrCacheMiss indica que la key no existe en el caché remoto
+var ErrCacheMiss = errors.New("key no encontrada en caché")
+
+// ErrCacheNotStored indica que Add no guardó el valor porque la key ya existía
+var ErrCacheNotStored = errors.New("la key ya existe en caché")
+
+// ErrCacheOperationUnsupported indica que el backend remoto no soporta la operación
+// (Memcached no permite recorrer keys ni consultar su TTL)
+var ErrCacheOperationUnsupported = errors.New("operación no soportada por el backend de caché")
+
 // CacheRepository define la interfaz para las operaciones de caché
 type CacheRepository interface {
-	// Get obtiene datos del caché (properties y total count)
-	// Retorna (properties, total, found)
-	Get(key string) ([]domain.Property, int, bool)
+	// Get obtiene un resultado de búsqueda del caché (properties, total count y facets)
+	// Retorna (result, found)
+	Get(key string) (dto.SearchResult, bool)
 
-	// Set guarda datos en el caché con TTL
-	Set(key string, properties []domain.Property, total int, ttl time.Duration)
+	// GetWithLevel es Get informando el nivel que respondió (dto.CacheLevelLocal o dto.CacheLevelRemote)
+	GetWithLevel(key string) (dto.SearchResult, string, bool)
+
+	// Set guarda un resultado de búsqueda en el caché con TTL
+	Set(key string, result dto.SearchResult, ttl time.Duration)
 
 	// Delete elimina datos del caché
 	Delete(key string)
+
+	// DeletePattern elimina las keys que coinciden con el patrón glob (ej: "search:*")
+	// Retorna la cantidad de keys eliminadas
+	DeletePattern(pattern string) (int, error)
+
+	// TTL retorna el tiempo de vida restante de la key en cada nivel
+	TTL(key string) dto.CacheEntryTTL
+
+	// Ping verifica que el caché remoto responda (usado por el health check)
+	Ping() error
+
+	// Stats retorna los contadores de hits/misses y el estado de ambos niveles
+	Stats() dto.CacheStats
+
+	// Flush vacía ambos niveles del caché
+	Flush() error
+
+	// InvalidateLocal aplica una invalidación recibida de otra réplica solo al caché local (no la vuelve a difundir)
+	// Retorna la cantidad de keys eliminadas
+	InvalidateLocal(invalidation CacheInvalidation) int
+
+	// SetInvalidationPublisher difunde desde ahora cada Delete, DeletePattern y Flush a las otras réplicas
+	// para que limpien su caché local (nil deja de difundir)
+	SetInvalidationPublisher(publisher CacheInvalidationPublisher)
+}
+
+// CacheInvalidation es una invalidación del caché local que se difunde entre réplicas
+// Lleva uno solo de los campos: Key (Delete), Pattern (DeletePattern) o Flush
+type CacheInvalidation struct {
+	// Key es una key exacta
+	Key string `json:"key,omitempty"`
+
+	// Pattern es un patrón glob (ej: "search:*")
+	Pattern string `json:"pattern,omitempty"`
+
+	// Flush vacía todo el caché local
+	Flush bool `json:"flush,omitempty"`
+}
+
+// CacheInvalidationPublisher difunde las invalidaciones del caché a las otras réplicas (ej: fanout de RabbitMQ)
+type CacheInvalidationPublisher interface {
+	PublishInvalidation(invalidation CacheInvalidation) error
+}
+
+// RemoteCache es el nivel distribuido del caché (Memcached o Redis)
+// Los valores se guardan serializados
+type RemoteCache interface {
+	// Name es el nombre del backend (ej: "memcached")
+	Name() string
+
+	// Get retorna el valor guardado o ErrCacheMiss
+	Get(key string) ([]byte, error)
+
+	// Set guarda el valor con TTL
+	Set(key string, value []byte, ttl time.Duration) error
+
+	// Add guarda el valor con TTL solo si la key no existe; retorna ErrCacheNotStored si ya existía
+	Add(key string, value []byte, ttl time.Duration) error
+
+	// Delete elimina la key; retorna ErrCacheMiss si no existía
+	Delete(key string) error
+
+	// DeletePattern elimina las keys que coinciden con el patrón glob
+	DeletePattern(pattern string) (int, error)
+
+	// TTL retorna el tiempo de vida restante de la key (0 si no expira) o ErrCacheMiss
+	TTL(key string) (time.Duration, error)
+
+	// Flush elimina todas las keys
+	Flush() error
+
+	// Ping verifica que el servidor responda
+	Ping() error
+
+	// Stats retorna la cantidad de entradas, descartes y memoria reportados por el servidor
+	Stats() (*dto.RemoteCacheStats, error)
 }
 
 // cacheRepository es la implementación concreta de CacheRepository
-// Implementa un sistema de caché de dos niveles: local (ccache) y distribuido (Memcached)
+// Implementa un sistema de caché de dos niveles: local (ccache) y distribuido (Memcached o Redis)
 type cacheRepository struct {
-	localCache      *ccache.Cache[*cacheData]
-	memcachedClient *memcache.Client
-}
+	localCache *ccache.Cache[*dto.SearchResult]
+	remote     RemoteCache
+	localTTL   time.Duration
+
+	// publisher difunde las invalidaciones a las otras réplicas (nil mientras no haya uno conectado)
+	publisher atomic.Pointer[CacheInvalidationPublisher]
 
-// cacheData representa los datos almacenados en el caché
-type cacheData struct {
-	Properties []domain.Property `json:"properties"`
-	Total      int               `json:"total"`
+	// Contadores para /admin/cache/stats y /metrics
+	localHits      atomic.Uint64
+	remoteHits     atomic.Uint64
+	misses         atomic.Uint64
+	remoteErrors   atomic.Uint64
+	sets           atomic.Uint64
+	flushes        atomic.Uint64
+	localEvictions atomic.Uint64
 }
 
 // NewCacheRepository crea una nueva instancia del repositorio de caché
-// Inicializa ccache local y conecta con Memcached
-func NewCacheRepository(memcachedHost string) CacheRepository {
+// Inicializa ccache local sobre el caché remoto recibido
+// localTTL es el TTL máximo de las entradas del caché local
+func NewCacheRepository(remote RemoteCache, localTTL time.Duration) CacheRepository {
 	// Inicializar caché local con ccache
-	localCache := ccache.New(ccache.Configure[*cacheData]().
+	localCache := ccache.New(ccache.Configure[*dto.SearchResult]().
 		MaxSize(1000).
 		ItemsToPrune(100)) // ← Cambiar a ItemsToPrune
 
-	// Inicializar cliente de Memcached
-	memcachedClient := memcache.New(memcachedHost)
-	log.Printf("✅ Cliente de Memcached inicializado para %s", memcachedHost)
-
 	return &cacheRepository{
-		localCache:      localCache,
-		memcachedClient: memcachedClient,
+		localCache: localCache,
+		remote:     remote,
+		localTTL:   localTTL,
 	}
 }
 
 // Get obtiene datos del caché con estrategia de dos niveles
 // 1. Busca primero en caché local (ccache)
-// 2. Si no está, busca en Memcached
-// 3. Si está en Memcached, guarda en caché local
-// Retorna (properties, total, found)
-func (r *cacheRepository) Get(key string) ([]domain.Property, int, bool) {
+// 2. Si no está, busca en el caché remoto
+// 3. Si está en el caché remoto, guarda en caché local
+// Retorna (result, found)
+func (r *cacheRepository) Get(key string) (dto.SearchResult, bool) {
+	result, _, found := r.GetWithLevel(key)
+	return result, found
+}
+
+// GetWithLevel busca igual que Get y retorna además el nivel que respondió (vacío si no se encontró)
+func (r *cacheRepository) GetWithLevel(key string) (dto.SearchResult, string, bool) {
 	// Nivel 1: Buscar en caché local
 	item := r.localCache.Get(key)
 	if item != nil && !item.Expired() {
 		data := item.Value()
 		if data != nil {
+			r.localHits.Add(1)
 			log.Printf("✅ Cache hit (local) para key: %s", key)
-			return data.Properties, data.Total, true
+			return *data, dto.CacheLevelLocal, true
 		}
 	}
 
-	// Nivel 2: Buscar en Memcached
-	memcachedItem, err := r.memcachedClient.Get(key)
+	// Nivel 2: Buscar en el caché remoto
+	value, err := r.remote.Get(key)
 	if err != nil {
-		if err == memcache.ErrCacheMiss {
+		r.misses.Add(1)
+		if errors.Is(err, ErrCacheMiss) {
 			log.Printf("❌ Cache miss para key: %s", key)
-			return nil, 0, false
+			return dto.SearchResult{}, "", false
 		}
-		log.Printf("⚠️ Error obteniendo de Memcached para key %s: %v", key, err)
-		return nil, 0, false
+		r.remoteErrors.Add(1)
+		log.Printf("⚠️ Error obteniendo de %s para key %s: %v", r.remote.Name(), key, err)
+		return dto.SearchResult{}, "", false
 	}
 
-	// Deserializar datos de Memcached
-	var data cacheData
-	if err := json.Unmarshal(memcachedItem.Value, &data); err != nil {
-		log.Printf("⚠️ Error deserializando datos de Memcached para key %s: %v", key, err)
-		return nil, 0, false
+	// Deserializar datos del caché remoto
+	var data dto.SearchResult
+	if err := json.Unmarshal(value, &data); err != nil {
+		r.misses.Add(1)
+		log.Printf("⚠️ Error deserializando datos de %s para key %s: %v", r.remote.Name(), key, err)
+		return dto.SearchResult{}, "", false
 	}
 
-	// Guardar en caché local para próximas consultas (TTL de 5 minutos)
-	r.localCache.Set(key, &data, 5*time.Minute)
-	log.Printf("✅ Cache hit (Memcached) para key: %s, guardado en local", key)
+	// Guardar en caché local para próximas consultas
+	r.localCache.Set(key, &data, r.localTTL)
+	r.remoteHits.Add(1)
+	log.Printf("✅ Cache hit (%s) para key: %s, guardado en local", r.remote.Name(), key)
 
-	return data.Properties, data.Total, true
+	return data, dto.CacheLevelRemote, true
 }
 
 // Set guarda datos en ambos niveles de caché
-// - Caché local: TTL de 5 minutos
-// - Memcached: TTL de 15 minutos (o el TTL proporcionado si es mayor)
-func (r *cacheRepository) Set(key string, properties []domain.Property, total int, ttl time.Duration) {
-	data := &cacheData{
-		Properties: properties,
-		Total:      total,
-	}
+// - Caché local: el TTL proporcionado, como máximo el TTL local configurado
+// - Caché remoto: el TTL proporcionado
+func (r *cacheRepository) Set(key string, result dto.SearchResult, ttl time.Duration) {
+	data := &result
+	r.sets.Add(1)
 
-	// Guardar en caché local con TTL de 5 minutos
-	r.localCache.Set(key, data, 5*time.Minute)
+	// Guardar en caché local (una entrada no puede vivir más en local que en el remoto)
+	localTTL := r.localTTL
+	if ttl < localTTL {
+		localTTL = ttl
+	}
+	r.localCache.Set(key, data, localTTL)
 	log.Printf("✅ Datos guardados en caché local para key: %s", key)
 
-	// Serializar para Memcached
+	// Serializar para el caché remoto
 	jsonData, err := json.Marshal(data)
 	if err != nil {
-		log.Printf("⚠️ Error serializando datos para Memcached (key %s): %v", key, err)
+		log.Printf("⚠️ Error serializando datos para %s (key %s): %v", r.remote.Name(), key, err)
 		return
 	}
 
-	// Calcular TTL para Memcached (mínimo 15 minutos)
-	memcachedTTL := ttl
-	if memcachedTTL < 15*time.Minute {
-		memcachedTTL = 15 * time.Minute
-	}
-
-	// Guardar en Memcached
-	item := &memcache.Item{
-		Key:        key,
-		Value:      jsonData,
-		Expiration: int32(memcachedTTL.Seconds()),
-	}
-
-	if err := r.memcachedClient.Set(item); err != nil {
-		log.Printf("⚠️ Error guardando en Memcached (key %s): %v", key, err)
+	if err := r.remote.Set(key, jsonData, ttl); err != nil {
+		r.remoteErrors.Add(1)
+		log.Printf("⚠️ Error guardando en %s (key %s): %v", r.remote.Name(), key, err)
 		return
 	}
 
-	log.Printf("✅ Datos guardados en Memcached para key: %s (TTL: %v)", key, memcachedTTL)
+	log.Printf("✅ Datos guardados en %s para key: %s (TTL: %v)", r.remote.Name(), key, ttl)
 }
 
 // Delete elimina datos de ambos niveles de caché
 func (r *cacheRepository) Delete(key string) {
+	defer r.broadcast(CacheInvalidation{Key: key})
+
 	// Eliminar de caché local
 	r.localCache.Delete(key)
 	log.Printf("✅ Datos eliminados de caché local para key: %s", key)
 
-	// Eliminar de Memcached
-	if err := r.memcachedClient.Delete(key); err != nil {
-		if err == memcache.ErrCacheMiss {
-			log.Printf("ℹ️ Key %s no existe en Memcached", key)
+	// Eliminar del caché remoto
+	if err := r.remote.Delete(key); err != nil {
+		if errors.Is(err, ErrCacheMiss) {
+			log.Printf("ℹ️ Key %s no existe en %s", key, r.remote.Name())
 		} else {
-			log.Printf("⚠️ Error eliminando de Memcached (key %s): %v", key, err)
+			log.Printf("⚠️ Error eliminando de %s (key %s): %v", r.remote.Name(), key, err)
+		}
+		return
+	}
+
+	log.Printf("✅ Datos eliminados de %s para key: %s", r.remote.Name(), key)
+}
+
+// DeletePattern elimina las keys que coinciden con el patrón glob de ambos niveles
+// El caché local se limpia aunque el backend remoto no soporte patrones
+func (r *cacheRepository) DeletePattern(pattern string) (int, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return 0, fmt.Errorf("patrón inválido '%s': %w", pattern, err)
+	}
+
+	// Se difunde después de limpiar el remoto: si otra réplica recarga la key, ya no la encuentra ahí
+	defer r.broadcast(CacheInvalidation{Pattern: pattern})
+
+	localDeleted := r.deleteLocalPattern(pattern)
+	log.Printf("🧹 %d keys eliminadas del caché local con patrón %s", localDeleted, pattern)
+
+	remoteDeleted, err := r.remote.DeletePattern(pattern)
+	if err != nil {
+		if !errors.Is(err, ErrCacheOperationUnsupported) {
+			r.remoteErrors.Add(1)
+		}
+		return localDeleted, fmt.Errorf("error eliminando keys de %s: %w", r.remote.Name(), err)
+	}
+	log.Printf("🧹 %d keys eliminadas de %s con patrón %s", remoteDeleted, r.remote.Name(), pattern)
+
+	if remoteDeleted > localDeleted {
+		return remoteDeleted, nil
+	}
+	return localDeleted, nil
+}
+
+// TTL retorna el tiempo de vida restante de la key en cada nivel
+func (r *cacheRepository) TTL(key string) dto.CacheEntryTTL {
+	entry := dto.CacheEntryTTL{Key: key}
+
+	if item := r.localCache.GetWithoutPromote(key); item != nil && !item.Expired() {
+		seconds := item.TTL().Seconds()
+		entry.LocalTTLSeconds = &seconds
+	}
+
+	ttl, err := r.remote.TTL(key)
+	switch {
+	case err == nil:
+		seconds := ttl.Seconds()
+		entry.RemoteTTLSeconds = &seconds
+	case !errors.Is(err, ErrCacheMiss) && !errors.Is(err, ErrCacheOperationUnsupported):
+		r.remoteErrors.Add(1)
+		log.Printf("⚠️ Error consultando TTL en %s (key %s): %v", r.remote.Name(), key, err)
+	}
+	return entry
+}
+
+// Ping verifica que el caché remoto responda
+// El caché local sigue funcionando aunque el remoto no esté disponible
+func (r *cacheRepository) Ping() error {
+	return r.remote.Ping()
+}
+
+// Stats retorna los contadores de hits/misses y el estado de ambos niveles
+// Si el caché remoto no responde se informan solo los datos locales
+func (r *cacheRepository) Stats() dto.CacheStats {
+	// GetDropped retorna los descartes desde la llamada anterior, por eso se acumulan
+	r.localEvictions.Add(uint64(r.localCache.GetDropped()))
+
+	stats := dto.CacheStats{
+		Backend:      r.remote.Name(),
+		LocalHits:    r.localHits.Load(),
+		RemoteHits:   r.remoteHits.Load(),
+		Misses:       r.misses.Load(),
+		RemoteErrors: r.remoteErrors.Load(),
+		Sets:         r.sets.Load(),
+		Flushes:      r.flushes.Load(),
+		Local: dto.LocalCacheStats{
+			Items:     r.localCache.ItemCount(),
+			Evictions: r.localEvictions.Load(),
+		},
+	}
+	stats.Hits = stats.LocalHits + stats.RemoteHits
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRatio = float64(stats.Hits) / float64(total)
+	}
+
+	remoteStats, err := r.remote.Stats()
+	if err != nil {
+		log.Printf("⚠️ Error obteniendo stats de %s: %v", r.remote.Name(), err)
+	} else {
+		stats.Remote = remoteStats
+	}
+	return stats
+}
+
+// Flush vacía ambos niveles del caché
+// El caché local se vacía aunque falle el remoto
+func (r *cacheRepository) Flush() error {
+	defer r.broadcast(CacheInvalidation{Flush: true})
+
+	r.localCache.Clear()
+	r.flushes.Add(1)
+	log.Println("🧹 Caché local vaciado")
+
+	if err := r.remote.Flush(); err != nil {
+		r.remoteErrors.Add(1)
+		return fmt.Errorf("error vaciando %s: %w", r.remote.Name(), err)
+	}
+	log.Printf("🧹 %s vaciado", r.remote.Name())
+	return nil
+}
+
+// InvalidateLocal elimina del caché local lo que indica la invalidación
+func (r *cacheRepository) InvalidateLocal(invalidation CacheInvalidation) int {
+	switch {
+	case invalidation.Flush:
+		deleted := r.localCache.ItemCount()
+		r.localCache.Clear()
+		return deleted
+	case invalidation.Pattern != "":
+		if _, err := path.Match(invalidation.Pattern, ""); err != nil {
+			log.Printf("⚠️ Invalidación con patrón inválido '%s' ignorada", invalidation.Pattern)
+			return 0
+		}
+		return r.deleteLocalPattern(invalidation.Pattern)
+	case invalidation.Key != "":
+		if r.localCache.Delete(invalidation.Key) {
+			return 1
 		}
+	}
+	return 0
+}
+
+// SetInvalidationPublisher reemplaza el publicador de invalidaciones
+func (r *cacheRepository) SetInvalidationPublisher(publisher CacheInvalidationPublisher) {
+	if publisher == nil {
+		r.publisher.Store(nil)
 		return
 	}
+	r.publisher.Store(&publisher)
+}
 
-	log.Printf("✅ Datos eliminados de Memcached para key: %s", key)
+// deleteLocalPattern elimina del caché local las keys que coinciden con el patrón (ya validado)
+func (r *cacheRepository) deleteLocalPattern(pattern string) int {
+	return r.localCache.DeleteFunc(func(key string, _ *ccache.Item[*dto.SearchResult]) bool {
+		matched, _ := path.Match(pattern, key)
+		return matched
+	})
+}
+
+// broadcast difunde la invalidación a las otras réplicas si hay un publicador
+// Un error no se propaga: el caché local de las otras réplicas vence igual a los CACHE_LOCAL_TTL
+func (r *cacheRepository) broadcast(invalidation CacheInvalidation) {
+	publisher := r.publisher.Load()
+	if publisher == nil {
+		return
+	}
+	if err := (*publisher).PublishInvalidation(invalidation); err != nil {
+		log.Printf("⚠️ Error difundiendo la invalidación del caché local a las otras réplicas: %v", err)
+	}
 }
@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"search-api/domain"
+)
+
+// EngagementRepository define la interfaz para guardar las interacciones recientes de cada usuario
+// Implementaciones: NewMongoEngagementRepository (compartida por las réplicas) y NewEngagementRepository
+type EngagementRepository interface {
+	// Add agrega una interacción del usuario
+	// Si ya había una del mismo tipo con la misma propiedad la reemplaza en lugar de duplicarla
+	Add(ctx context.Context, userID uint, engagement domain.Engagement) error
+
+	// List retorna las interacciones del usuario, de la más reciente a la más antigua
+	List(ctx context.Context, userID uint) ([]domain.Engagement, error)
+
+	// Clear borra las interacciones del usuario
+	Clear(ctx context.Context, userID uint) error
+}
+
+// userEngagements son las interacciones de un usuario, de la más antigua a la más reciente
+type userEngagements struct {
+	userID      uint
+	engagements []domain.Engagement
+}
+
+// engagementRepository guarda las interacciones en memoria, acotadas por usuario y en cantidad de usuarios
+// Igual que el historial de búsquedas, al superar maxUsers se descarta el usuario menos activo (LRU)
+// Cada réplica tiene las suyas: solo sirve con una réplica (DATA_STORE=memory)
+type engagementRepository struct {
+	mu       sync.Mutex
+	size     int
+	maxUsers int
+	users    map[uint]*list.Element
+	lru      *list.List // frente = usuario que interactuó más recientemente
+}
+
+// NewEngagementRepository crea un repositorio en memoria que guarda size interacciones de hasta maxUsers usuarios
+func NewEngagementRepository(size, maxUsers int) EngagementRepository {
+	if size <= 0 {
+		size = 1
+	}
+	if maxUsers <= 0 {
+		maxUsers = 1
+	}
+
+	return &engagementRepository{
+		size:     size,
+		maxUsers: maxUsers,
+		users:    make(map[uint]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// Add agrega la interacción y marca al usuario como el más reciente
+func (r *engagementRepository) Add(ctx context.Context, userID uint, engagement domain.Engagement) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	element, exists := r.users[userID]
+	if !exists {
+		element = r.lru.PushFront(&userEngagements{userID: userID})
+		r.users[userID] = element
+		if r.lru.Len() > r.maxUsers {
+			oldest := r.lru.Back()
+			r.lru.Remove(oldest)
+			delete(r.users, oldest.Value.(*userEngagements).userID)
+		}
+	} else {
+		r.lru.MoveToFront(element)
+	}
+
+	user := element.Value.(*userEngagements)
+	engagements := user.engagements[:0]
+	for _, previous := range user.engagements {
+		if engagementKey(previous) != engagementKey(engagement) {
+			engagements = append(engagements, previous)
+		}
+	}
+	engagements = append(engagements, engagement)
+	if len(engagements) > r.size {
+		engagements = engagements[len(engagements)-r.size:]
+	}
+	user.engagements = engagements
+	return nil
+}
+
+// List retorna una copia de las interacciones, de la más reciente a la más antigua
+func (r *engagementRepository) List(ctx context.Context, userID uint) ([]domain.Engagement, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	element, exists := r.users[userID]
+	if !exists {
+		return nil, nil
+	}
+
+	engagements := element.Value.(*userEngagements).engagements
+	ordered := make([]domain.Engagement, len(engagements))
+	for i, engagement := range engagements {
+		ordered[len(engagements)-1-i] = engagement
+	}
+	return ordered, nil
+}
+
+// Clear borra las interacciones del usuario
+func (r *engagementRepository) Clear(ctx context.Context, userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if element, exists := r.users[userID]; exists {
+		r.lru.Remove(element)
+		delete(r.users, userID)
+	}
+	return nil
+}
+
+// engagementKey identifica una interacción por su tipo y su propiedad (dos iguales se deduplican)
+func engagementKey(engagement domain.Engagement) string {
+	return engagement.Type + "|" + engagement.Property.ID
+}
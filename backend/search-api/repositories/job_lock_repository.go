@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// JobLockRepository guarda en MongoDB los locks distribuidos de los procesos que no deben correr en varias
+// réplicas a la vez (jobs programados, reconciliación y reindexación) y el lease del líder. Implementa jobs.Locker
+type JobLockRepository interface {
+	// TryLock toma el lock name para owner por ttl; retorna false si lo tiene otro owner y no venció
+	TryLock(ctx context.Context, name, owner string, ttl time.Duration) (bool, error)
+	// Refresh extiende el lock de owner por ttl desde ahora; retorna false si ya no es de owner
+	Refresh(ctx context.Context, name, owner string, ttl time.Duration) (bool, error)
+	// Unlock suelta el lock de owner; si holdUntil es futuro lo deja tomado hasta ese momento
+	Unlock(ctx context.Context, name, owner string, holdUntil time.Time) error
+}
+
+// jobLockRepository es la implementación de JobLockRepository sobre MongoDB
+// Cada lock es un documento con _id = nombre del lock; el índice TTL de expiresAt borra los vencidos
+// No usa el caché remoto: ni DELETE /admin/cache ni el desalojo por LRU pueden soltar un lock en uso
+type jobLockRepository struct {
+	collection *mongo.Collection
+}
+
+// NewJobLockRepository crea el repositorio de locks de jobs sobre la colección indicada y su índice TTL
+func NewJobLockRepository(ctx context.Context, db *mongo.Database, collection string) (JobLockRepository, error) {
+	repo := &jobLockRepository{
+		collection: db.Collection(collection),
+	}
+	if err := ensureTTLIndex(ctx, repo.collection, "expiresAt", 0); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// TryLock reemplaza el documento solo si está vencido; si no lo está, el upsert choca con el _id existente
+// El TTL de MongoDB borra con hasta un minuto de atraso, por eso el vencimiento se compara en el filtro
+func (r *jobLockRepository) TryLock(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	now := time.Now().UTC()
+	filter := bson.M{"_id": name, "expiresAt": bson.M{"$lte": now}}
+	update := bson.M{"$set": bson.M{"owner": owner, "lockedAt": now, "expiresAt": now.Add(ttl)}}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error tomando lock '%s': %w", name, err)
+	}
+	return true, nil
+}
+
+// Refresh mueve el vencimiento solo si el documento sigue siendo de owner
+// Un lock vencido que nadie tomó todavía se puede renovar: ninguna otra réplica lo tiene
+func (r *jobLockRepository) Refresh(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": name, "owner": owner},
+		bson.M{"$set": bson.M{"expiresAt": time.Now().UTC().Add(ttl)}},
+	)
+	if err != nil {
+		return false, fmt.Errorf("error renovando lock '%s': %w", name, err)
+	}
+	return result.MatchedCount == 1, nil
+}
+
+// Unlock acorta el vencimiento del lock a holdUntil (o a ahora si ya pasó) si sigue siendo de owner
+func (r *jobLockRepository) Unlock(ctx context.Context, name, owner string, holdUntil time.Time) error {
+	expiresAt := time.Now().UTC()
+	if holdUntil.After(expiresAt) {
+		expiresAt = holdUntil.UTC()
+	}
+
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": name, "owner": owner},
+		bson.M{"$set": bson.M{"expiresAt": expiresAt}},
+	)
+	if err != nil {
+		return fmt.Errorf("error soltando lock '%s': %w", name, err)
+	}
+	return nil
+}
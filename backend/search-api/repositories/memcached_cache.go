@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"search-api/dto"
+
+	"github.com/bradfitz/gomemcache/memcache"
+)
+
+// memcachedStatsTimeout limita la consulta del comando "stats" a Memcached
+const memcachedStatsTimeout = 2 * time.Second
+
+// memcachedCache es el caché remoto sobre Memcached
+type memcachedCache struct {
+	client *memcache.Client
+	host   string
+}
+
+// NewMemcachedCache crea el caché remoto sobre Memcached
+func NewMemcachedCache(host string) RemoteCache {
+	client := memcache.New(host)
+	log.Printf("✅ Cliente de Memcached inicializado para %s", host)
+
+	return &memcachedCache{
+		client: client,
+		host:   host,
+	}
+}
+
+// Name retorna el nombre del backend
+func (c *memcachedCache) Name() string {
+	return "memcached"
+}
+
+// Get retorna el valor guardado o ErrCacheMiss
+func (c *memcachedCache) Get(key string) ([]byte, error) {
+	item, err := c.client.Get(key)
+	if err == memcache.ErrCacheMiss {
+		return nil, ErrCacheMiss
+	}
+	if err != nil {
+		return nil, err
+	}
+	return item.Value, nil
+}
+
+// Set guarda el valor con TTL
+func (c *memcachedCache) Set(key string, value []byte, ttl time.Duration) error {
+	return c.client.Set(&memcache.Item{
+		Key:        key,
+		Value:      value,
+		Expiration: int32(ttl.Seconds()),
+	})
+}
+
+// Add guarda el valor solo si la key no existe (comando add de Memcached, atómico en el servidor)
+func (c *memcachedCache) Add(key string, value []byte, ttl time.Duration) error {
+	err := c.client.Add(&memcache.Item{
+		Key:        key,
+		Value:      value,
+		Expiration: int32(ttl.Seconds()),
+	})
+	if err == memcache.ErrNotStored {
+		return ErrCacheNotStored
+	}
+	return err
+}
+
+// Delete elimina la key; retorna ErrCacheMiss si no existía
+func (c *memcachedCache) Delete(key string) error {
+	if err := c.client.Delete(key); err != nil {
+		if err == memcache.ErrCacheMiss {
+			return ErrCacheMiss
+		}
+		return err
+	}
+	return nil
+}
+
+// DeletePattern no está soportado: Memcached no permite recorrer las keys
+func (c *memcachedCache) DeletePattern(pattern string) (int, error) {
+	return 0, ErrCacheOperationUnsupported
+}
+
+// TTL no está soportado: Memcached no expone el tiempo de vida de una key
+func (c *memcachedCache) TTL(key string) (time.Duration, error) {
+	return 0, ErrCacheOperationUnsupported
+}
+
+// Flush elimina todas las keys
+func (c *memcachedCache) Flush() error {
+	return c.client.FlushAll()
+}
+
+// Ping verifica que Memcached responda
+func (c *memcachedCache) Ping() error {
+	return c.client.Ping()
+}
+
+// Stats ejecuta el comando "stats" de Memcached (el cliente no lo expone)
+func (c *memcachedCache) Stats() (*dto.RemoteCacheStats, error) {
+	conn, err := net.DialTimeout("tcp", c.host, memcachedStatsTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(memcachedStatsTimeout)); err != nil {
+		return nil, err
+	}
+
+	if _, err := conn.Write([]byte("stats\r\n")); err != nil {
+		return nil, err
+	}
+
+	// Cada línea tiene el formato "STAT <nombre> <valor>" y la respuesta termina con "END"
+	values := make(map[string]uint64)
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "END" {
+			return &dto.RemoteCacheStats{
+				Items:     values["curr_items"],
+				Evictions: values["evictions"],
+				BytesUsed: values["bytes"],
+			}, nil
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 || fields[0] != "STAT" {
+			continue
+		}
+		if value, err := strconv.ParseUint(fields[2], 10, 64); err == nil {
+			values[fields[1]] = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("respuesta incompleta del comando stats")
+}
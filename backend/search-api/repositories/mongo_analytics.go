@@ -0,0 +1,233 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"search-api/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Colecciones de analytics (un documento por búsqueda y por click)
+const (
+	searchEventsCollection = "search_events"
+	clickEventsCollection  = "search_clicks"
+)
+
+// searchEventDocument es una búsqueda guardada para analytics
+type searchEventDocument struct {
+	SearchID    string            `bson:"searchId,omitempty"`
+	Query       string            `bson:"query"`
+	Filters     map[string]string `bson:"filters,omitempty"`
+	ResultCount int               `bson:"resultCount"`
+	LatencyMs   float64           `bson:"latencyMs"`
+	SearchedAt  time.Time         `bson:"searchedAt"`
+}
+
+// clickEventDocument es un click guardado para analytics
+type clickEventDocument struct {
+	SearchID   string    `bson:"searchId"`
+	PropertyID string    `bson:"propertyId"`
+	Position   int       `bson:"position"`
+	UserID     int64     `bson:"userId,omitempty"`
+	ClickedAt  time.Time `bson:"clickedAt"`
+}
+
+// mongoAnalyticsRepository guarda las búsquedas y los clicks de todas las réplicas en MongoDB
+// Los índices TTL de searchedAt y clickedAt borran los eventos más viejos que la retención.
+// Cada consulta devuelve como mucho los maxEvents más recientes, así un reporte no carga en memoria
+// una cantidad de eventos sin límite
+type mongoAnalyticsRepository struct {
+	searches  *mongo.Collection
+	clicks    *mongo.Collection
+	maxEvents int
+	retention time.Duration
+}
+
+// NewMongoAnalyticsRepository crea el repositorio de analytics en MongoDB y sus índices
+func NewMongoAnalyticsRepository(ctx context.Context, db *mongo.Database, maxEvents int, retention time.Duration) (AnalyticsRepository, error) {
+	if maxEvents <= 0 {
+		maxEvents = 1
+	}
+	repo := &mongoAnalyticsRepository{
+		searches:  db.Collection(searchEventsCollection),
+		clicks:    db.Collection(clickEventsCollection),
+		maxEvents: maxEvents,
+		retention: retention,
+	}
+
+	if retention > 0 {
+		if err := ensureTTLIndex(ctx, repo.searches, "searchedAt", retention); err != nil {
+			return nil, err
+		}
+		if err := ensureTTLIndex(ctx, repo.clicks, "clickedAt", retention); err != nil {
+			return nil, err
+		}
+	} else {
+		// Sin retención igual se consulta por fecha
+		for _, index := range []struct {
+			collection *mongo.Collection
+			field      string
+		}{{repo.searches, "searchedAt"}, {repo.clicks, "clickedAt"}} {
+			if _, err := index.collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: index.field, Value: 1}}}); err != nil {
+				return nil, fmt.Errorf("error creando el índice de '%s.%s': %w", index.collection.Name(), index.field, err)
+			}
+		}
+	}
+	// EraseUser busca los clicks por usuario; los anónimos no tienen userId
+	_, err := repo.clicks.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "userId", Value: 1}},
+		Options: options.Index().SetSparse(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creando el índice de '%s.userId': %w", clickEventsCollection, err)
+	}
+	return repo, nil
+}
+
+// Record guarda la búsqueda
+func (r *mongoAnalyticsRepository) Record(ctx context.Context, event domain.SearchEvent) error {
+	_, err := r.searches.InsertOne(ctx, searchEventDocument{
+		SearchID:    event.SearchID,
+		Query:       event.Query,
+		Filters:     event.Filters,
+		ResultCount: event.ResultCount,
+		LatencyMs:   float64(event.Latency) / float64(time.Millisecond),
+		SearchedAt:  event.SearchedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("error guardando la búsqueda para analytics: %w", err)
+	}
+	return nil
+}
+
+// ListSince retorna las hasta maxEvents búsquedas más recientes desde since
+func (r *mongoAnalyticsRepository) ListSince(ctx context.Context, since time.Time) ([]domain.SearchEvent, error) {
+	var documents []searchEventDocument
+	if err := r.findRecent(ctx, r.searches, "searchedAt", since, &documents); err != nil {
+		return nil, err
+	}
+
+	events := make([]domain.SearchEvent, len(documents))
+	for i, document := range documents {
+		events[len(documents)-1-i] = domain.SearchEvent{
+			SearchID:    document.SearchID,
+			Query:       document.Query,
+			Filters:     document.Filters,
+			ResultCount: document.ResultCount,
+			Latency:     time.Duration(document.LatencyMs * float64(time.Millisecond)),
+			SearchedAt:  document.SearchedAt.UTC(),
+		}
+	}
+	return events, nil
+}
+
+// RecordClick guarda el click
+func (r *mongoAnalyticsRepository) RecordClick(ctx context.Context, event domain.ClickEvent) error {
+	_, err := r.clicks.InsertOne(ctx, clickEventDocument{
+		SearchID:   event.SearchID,
+		PropertyID: event.PropertyID,
+		Position:   event.Position,
+		UserID:     int64(event.UserID),
+		ClickedAt:  event.ClickedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("error guardando el click para analytics: %w", err)
+	}
+	return nil
+}
+
+// ListClicksSince retorna los hasta maxEvents clicks más recientes desde since
+func (r *mongoAnalyticsRepository) ListClicksSince(ctx context.Context, since time.Time) ([]domain.ClickEvent, error) {
+	var documents []clickEventDocument
+	if err := r.findRecent(ctx, r.clicks, "clickedAt", since, &documents); err != nil {
+		return nil, err
+	}
+
+	events := make([]domain.ClickEvent, len(documents))
+	for i, document := range documents {
+		events[len(documents)-1-i] = domain.ClickEvent{
+			SearchID:   document.SearchID,
+			PropertyID: document.PropertyID,
+			Position:   document.Position,
+			UserID:     uint(document.UserID),
+			ClickedAt:  document.ClickedAt.UTC(),
+		}
+	}
+	return events, nil
+}
+
+// CompleteSince retorna el momento desde el que los reportes no perdieron eventos: el inicio de la retención
+// o, si hay más de maxEvents búsquedas o clicks, el más antiguo de los maxEvents que entran en un reporte
+func (r *mongoAnalyticsRepository) CompleteSince(ctx context.Context) (time.Time, error) {
+	var since time.Time
+	if r.retention > 0 {
+		since = time.Now().UTC().Add(-r.retention)
+	}
+	for _, source := range []struct {
+		collection *mongo.Collection
+		field      string
+	}{{r.searches, "searchedAt"}, {r.clicks, "clickedAt"}} {
+		cutoff, err := r.cutoff(ctx, source.collection, source.field)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if cutoff.After(since) {
+			since = cutoff
+		}
+	}
+	return since, nil
+}
+
+// EraseUser quita el userId de los clicks del usuario
+func (r *mongoAnalyticsRepository) EraseUser(ctx context.Context, userID uint) error {
+	_, err := r.clicks.UpdateMany(ctx, bson.M{"userId": int64(userID)}, bson.M{"$unset": bson.M{"userId": ""}})
+	if err != nil {
+		return fmt.Errorf("error desvinculando los clicks del usuario %d: %w", userID, err)
+	}
+	return nil
+}
+
+// findRecent decodifica en result los hasta maxEvents documentos más recientes con field >= since
+func (r *mongoAnalyticsRepository) findRecent(ctx context.Context, collection *mongo.Collection, field string, since time.Time, result interface{}) error {
+	cursor, err := collection.Find(ctx,
+		bson.M{field: bson.M{"$gte": since}},
+		options.Find().SetSort(bson.D{{Key: field, Value: -1}}).SetLimit(int64(r.maxEvents)),
+	)
+	if err != nil {
+		return fmt.Errorf("error consultando '%s': %w", collection.Name(), err)
+	}
+	if err := cursor.All(ctx, result); err != nil {
+		return fmt.Errorf("error leyendo '%s': %w", collection.Name(), err)
+	}
+	return nil
+}
+
+// cutoff retorna la fecha del evento más antiguo que entra en un reporte si hay más de maxEvents
+// (el siguiente ya no entra); si no hay tantos retorna el valor cero
+func (r *mongoAnalyticsRepository) cutoff(ctx context.Context, collection *mongo.Collection, field string) (time.Time, error) {
+	cursor, err := collection.Find(ctx, bson.M{},
+		options.Find().
+			SetSort(bson.D{{Key: field, Value: -1}}).
+			SetSkip(int64(r.maxEvents-1)).
+			SetLimit(2).
+			SetProjection(bson.M{field: 1}),
+	)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error consultando '%s': %w", collection.Name(), err)
+	}
+	var documents []bson.M
+	if err := cursor.All(ctx, &documents); err != nil {
+		return time.Time{}, fmt.Errorf("error leyendo '%s': %w", collection.Name(), err)
+	}
+	if len(documents) < 2 {
+		return time.Time{}, nil
+	}
+	oldest, _ := documents[0][field].(primitive.DateTime)
+	return oldest.Time().UTC(), nil
+}
@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"search-api/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// engagementCollection es la colección de las interacciones (un documento por usuario)
+const engagementCollection = "search_engagement"
+
+// engagementDocument son las interacciones de un usuario, de la más antigua a la más reciente
+type engagementDocument struct {
+	UserID      int64             `bson:"_id"`
+	Engagements []engagementEntry `bson:"engagements"`
+	UpdatedAt   time.Time         `bson:"updatedAt"`
+}
+
+// engagementEntry es una interacción; Key (ver engagementKey) es la que la deduplica
+type engagementEntry struct {
+	Key        string          `bson:"key"`
+	Type       string          `bson:"type"`
+	Property   domain.Property `bson:"property"`
+	OccurredAt time.Time       `bson:"occurredAt"`
+}
+
+// mongoEngagementRepository guarda las interacciones en MongoDB, igual que el historial de búsquedas
+// Un índice TTL sobre updatedAt borra las de los usuarios que no interactuaron en toda la retención
+type mongoEngagementRepository struct {
+	collection *mongo.Collection
+	size       int
+}
+
+// NewMongoEngagementRepository crea el repositorio que guarda size interacciones por usuario en MongoDB
+// retention es el TTL de las interacciones desde la última del usuario (0 = sin vencimiento)
+func NewMongoEngagementRepository(ctx context.Context, db *mongo.Database, size int, retention time.Duration) (EngagementRepository, error) {
+	if size <= 0 {
+		size = 1
+	}
+	repo := &mongoEngagementRepository{
+		collection: db.Collection(engagementCollection),
+		size:       size,
+	}
+	if retention > 0 {
+		if err := ensureTTLIndex(ctx, repo.collection, "updatedAt", retention); err != nil {
+			return nil, err
+		}
+	}
+	return repo, nil
+}
+
+// Add reemplaza la interacción igual (si había) por la nueva al final y recorta a las últimas size
+func (r *mongoEngagementRepository) Add(ctx context.Context, userID uint, engagement domain.Engagement) error {
+	entry := engagementEntry{
+		Key:        engagementKey(engagement),
+		Type:       engagement.Type,
+		Property:   engagement.Property,
+		OccurredAt: engagement.OccurredAt,
+	}
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": int64(userID)},
+		appendDeduplicated("engagements", entry.Key, entry, r.size, entry.OccurredAt),
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("error guardando la interacción del usuario %d: %w", userID, err)
+	}
+	return nil
+}
+
+// List lee el documento del usuario y lo da vuelta (de la más reciente a la más antigua)
+func (r *mongoEngagementRepository) List(ctx context.Context, userID uint) ([]domain.Engagement, error) {
+	var document engagementDocument
+	err := r.collection.FindOne(ctx, bson.M{"_id": int64(userID)}).Decode(&document)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo las interacciones del usuario %d: %w", userID, err)
+	}
+
+	engagements := make([]domain.Engagement, len(document.Engagements))
+	for i, entry := range document.Engagements {
+		engagements[len(document.Engagements)-1-i] = domain.Engagement{
+			Type:       entry.Type,
+			Property:   entry.Property,
+			OccurredAt: entry.OccurredAt.UTC(),
+		}
+	}
+	return engagements, nil
+}
+
+// Clear elimina el documento del usuario
+func (r *mongoEngagementRepository) Clear(ctx context.Context, userID uint) error {
+	if _, err := r.collection.DeleteOne(ctx, bson.M{"_id": int64(userID)}); err != nil {
+		return fmt.Errorf("error borrando las interacciones del usuario %d: %w", userID, err)
+	}
+	return nil
+}
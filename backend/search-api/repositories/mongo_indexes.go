@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Códigos de error de MongoDB al crear un índice que ya existe con otras opciones
+const (
+	mongoIndexOptionsConflict  = 85
+	mongoIndexKeySpecsConflict = 86
+)
+
+// ensureTTLIndex crea el índice TTL de field: MongoDB borra cada documento ttl después de ese momento
+// (ttl 0 = en el momento guardado en field). Si el índice ya existía con otro TTL (ej: cambió la retención
+// en la configuración) se actualiza con collMod en lugar de fallar
+func ensureTTLIndex(ctx context.Context, collection *mongo.Collection, field string, ttl time.Duration) error {
+	name := field + "_ttl"
+	seconds := int32(ttl / time.Second)
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: field, Value: 1}},
+		Options: options.Index().SetName(name).SetExpireAfterSeconds(seconds),
+	})
+
+	var commandErr mongo.CommandError
+	if errors.As(err, &commandErr) && (commandErr.Code == mongoIndexOptionsConflict || commandErr.Code == mongoIndexKeySpecsConflict) {
+		err = collection.Database().RunCommand(ctx, bson.D{
+			{Key: "collMod", Value: collection.Name()},
+			{Key: "index", Value: bson.D{{Key: "name", Value: name}, {Key: "expireAfterSeconds", Value: seconds}}},
+		}).Err()
+	}
+	if err != nil {
+		return fmt.Errorf("error creando el índice TTL de '%s.%s': %w", collection.Name(), field, err)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"search-api/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// searchHistoryCollection es la colección del historial de búsquedas (un documento por usuario)
+const searchHistoryCollection = "search_history"
+
+// historyDocument es el historial de un usuario, de la búsqueda más antigua a la más reciente
+type historyDocument struct {
+	UserID    int64          `bson:"_id"`
+	Events    []historyEntry `bson:"events"`
+	UpdatedAt time.Time      `bson:"updatedAt"`
+}
+
+// historyEntry es una búsqueda del historial; Key (ver searchKey) es la que la deduplica
+type historyEntry struct {
+	Key         string            `bson:"key"`
+	Query       string            `bson:"query"`
+	Filters     map[string]string `bson:"filters,omitempty"`
+	ResultCount int               `bson:"resultCount"`
+	SearchedAt  time.Time         `bson:"searchedAt"`
+}
+
+// mongoSearchHistoryRepository guarda el historial en MongoDB: todas las réplicas ven el mismo, y borrarlo
+// (DELETE /search/history o "user.erased") lo borra para todas
+// Un índice TTL sobre updatedAt borra el historial de los usuarios que no buscaron en toda la retención
+type mongoSearchHistoryRepository struct {
+	collection *mongo.Collection
+	size       int
+}
+
+// NewMongoSearchHistoryRepository crea el repositorio que guarda size búsquedas por usuario en MongoDB
+// retention es el TTL del historial desde la última búsqueda del usuario (0 = sin vencimiento)
+func NewMongoSearchHistoryRepository(ctx context.Context, db *mongo.Database, size int, retention time.Duration) (SearchHistoryRepository, error) {
+	if size <= 0 {
+		size = 1
+	}
+	repo := &mongoSearchHistoryRepository{
+		collection: db.Collection(searchHistoryCollection),
+		size:       size,
+	}
+	if retention > 0 {
+		if err := ensureTTLIndex(ctx, repo.collection, "updatedAt", retention); err != nil {
+			return nil, err
+		}
+	}
+	return repo, nil
+}
+
+// Add reemplaza la búsqueda igual (si había) por la nueva al final y recorta a las últimas size en un solo update
+func (r *mongoSearchHistoryRepository) Add(ctx context.Context, userID uint, event domain.SearchEvent) error {
+	entry := historyEntry{
+		Key:         searchKey(event),
+		Query:       event.Query,
+		Filters:     event.Filters,
+		ResultCount: event.ResultCount,
+		SearchedAt:  event.SearchedAt,
+	}
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": int64(userID)},
+		appendDeduplicated("events", entry.Key, entry, r.size, entry.SearchedAt),
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("error guardando la búsqueda en el historial del usuario %d: %w", userID, err)
+	}
+	return nil
+}
+
+// List lee el documento del usuario y lo da vuelta (de la más reciente a la más antigua)
+func (r *mongoSearchHistoryRepository) List(ctx context.Context, userID uint) ([]domain.SearchEvent, error) {
+	var document historyDocument
+	err := r.collection.FindOne(ctx, bson.M{"_id": int64(userID)}).Decode(&document)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo el historial del usuario %d: %w", userID, err)
+	}
+
+	events := make([]domain.SearchEvent, len(document.Events))
+	for i, entry := range document.Events {
+		events[len(document.Events)-1-i] = domain.SearchEvent{
+			Query:       entry.Query,
+			Filters:     entry.Filters,
+			ResultCount: entry.ResultCount,
+			SearchedAt:  entry.SearchedAt.UTC(),
+		}
+	}
+	return events, nil
+}
+
+// Remove saca del array la búsqueda con la misma key
+func (r *mongoSearchHistoryRepository) Remove(ctx context.Context, userID uint, event domain.SearchEvent) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": int64(userID)},
+		bson.M{"$pull": bson.M{"events": bson.M{"key": searchKey(event)}}},
+	)
+	if err != nil {
+		return false, fmt.Errorf("error quitando la búsqueda del historial del usuario %d: %w", userID, err)
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// Clear elimina el documento del usuario
+func (r *mongoSearchHistoryRepository) Clear(ctx context.Context, userID uint) error {
+	if _, err := r.collection.DeleteOne(ctx, bson.M{"_id": int64(userID)}); err != nil {
+		return fmt.Errorf("error borrando el historial del usuario %d: %w", userID, err)
+	}
+	return nil
+}
+
+// appendDeduplicated arma el update (pipeline) que agrega entry al final del array field, quitando antes el
+// elemento con la misma key, y deja solo los últimos size. Hacerlo en un solo update evita que dos búsquedas
+// simultáneas del mismo usuario (en réplicas distintas) se pisen
+// entry va en $literal: un término o filtro que empieza con "$" no se tiene que interpretar como un campo
+func appendDeduplicated(field, key string, entry interface{}, size int, updatedAt time.Time) mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			field: bson.M{"$slice": bson.A{
+				bson.M{"$concatArrays": bson.A{
+					bson.M{"$filter": bson.M{
+						"input": bson.M{"$ifNull": bson.A{"$" + field, bson.A{}}},
+						"cond":  bson.M{"$ne": bson.A{"$$this.key", bson.M{"$literal": key}}},
+					}},
+					bson.A{bson.M{"$literal": entry}},
+				}},
+				-size,
+			}},
+			"updatedAt": updatedAt,
+		}}},
+	}
+}
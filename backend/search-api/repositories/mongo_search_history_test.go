@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"search-api/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// startedCommand retorna el primer comando name enviado
+func startedCommand(mt *mtest.T, name string) bson.Raw {
+	for _, started := range mt.GetAllStartedEvents() {
+		if started.CommandName == name {
+			return started.Command
+		}
+	}
+	mt.Fatalf("expected a %s command", name)
+	return nil
+}
+
+func TestMongoSearchHistoryRepository_AddsAndListsSharedHistory(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("add replaces the same search in a single upsert", func(mt *mtest.T) {
+		repo, err := NewMongoSearchHistoryRepository(context.Background(), mt.DB, 3, 0)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+
+		event := domain.SearchEvent{Query: "$where", Filters: map[string]string{"city": "córdoba"}, SearchedAt: time.Now().UTC()}
+		if err := repo.Add(context.Background(), 7, event); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		update := startedCommand(mt, "update").Lookup("updates").Array().Index(0).Value().Document()
+		if !update.Lookup("upsert").Boolean() {
+			t.Fatalf("expected an upsert, got %v", update)
+		}
+		if id := update.Lookup("q", "_id").Int64(); id != 7 {
+			t.Fatalf("expected the user's document, got _id %d", id)
+		}
+		// Un pipeline: quitar la búsqueda con la misma key, agregarla al final y quedarse con las últimas 3
+		set := update.Lookup("u").Array().Index(0).Value().Document().Lookup("$set").Document()
+		slice := set.Lookup("events", "$slice").Array()
+		if size := slice.Index(1).Value().Int32(); size != -3 {
+			t.Fatalf("expected the history to be cut to the last 3 searches, got %d", size)
+		}
+		concat := slice.Index(0).Value().Document().Lookup("$concatArrays").Array()
+		key := concat.Index(0).Value().Document().Lookup("$filter", "cond", "$ne").Array().Index(1).Value().Document()
+		if got := key.Lookup("$literal").StringValue(); got != "$where|city=córdoba" {
+			t.Fatalf("expected the previous entry to be filtered by its key, got %q", got)
+		}
+		entry := concat.Index(1).Value().Array().Index(0).Value().Document().Lookup("$literal").Document()
+		if got := entry.Lookup("query").StringValue(); got != "$where" {
+			t.Fatalf("expected the query to be stored as a literal, got %q", got)
+		}
+	})
+
+	mt.Run("list returns the most recent search first", func(mt *mtest.T) {
+		repo, err := NewMongoSearchHistoryRepository(context.Background(), mt.DB, 3, 0)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		older := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "spotly.search_history", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: int64(7)},
+			{Key: "events", Value: bson.A{
+				bson.D{{Key: "key", Value: "casa"}, {Key: "query", Value: "casa"}, {Key: "searchedAt", Value: older}},
+				bson.D{{Key: "key", Value: "loft"}, {Key: "query", Value: "loft"}, {Key: "searchedAt", Value: older.Add(time.Hour)}},
+			}},
+		}))
+
+		events, err := repo.List(context.Background(), 7)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(events) != 2 || events[0].Query != "loft" || events[1].Query != "casa" {
+			t.Fatalf("expected loft then casa, got %+v", events)
+		}
+	})
+
+	mt.Run("list without a document is an empty history", func(mt *mtest.T) {
+		repo, err := NewMongoSearchHistoryRepository(context.Background(), mt.DB, 3, 0)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "spotly.search_history", mtest.FirstBatch))
+
+		events, err := repo.List(context.Background(), 7)
+		if err != nil || len(events) != 0 {
+			t.Fatalf("expected an empty history, got %+v (%v)", events, err)
+		}
+	})
+}
+
+func TestMongoAnalyticsRepository_EraseUserUnlinksClicks(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("unsets the user on every click", func(mt *mtest.T) {
+		indexCreated := mtest.CreateSuccessResponse()
+		mt.AddMockResponses(indexCreated, indexCreated, indexCreated)
+		repo, err := NewMongoAnalyticsRepository(context.Background(), mt.DB, 100, 24*time.Hour)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}, bson.E{Key: "nModified", Value: 2}))
+
+		if err := repo.EraseUser(context.Background(), 7); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		command := startedCommand(mt, "update")
+		if collection := command.Lookup("update").StringValue(); collection != clickEventsCollection {
+			t.Fatalf("expected an update on %s, got %s", clickEventsCollection, collection)
+		}
+		update := command.Lookup("updates").Array().Index(0).Value().Document()
+		if !update.Lookup("multi").Boolean() {
+			t.Fatalf("expected every click of the user to be updated, got %v", update)
+		}
+		if id := update.Lookup("q", "userId").Int64(); id != 7 {
+			t.Fatalf("expected the user's clicks, got userId %d", id)
+		}
+		if _, err := update.Lookup("u", "$unset").Document().LookupErr("userId"); err != nil {
+			t.Fatalf("expected userId to be unset, got %v", update.Lookup("u"))
+		}
+	})
+}
@@ -0,0 +1,938 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"search-api/domain"
+	"search-api/dto"
+)
+
+// openSearchFields traduce los campos del esquema de Solr (usados en sortBy y fields)
+// a los campos del índice de OpenSearch; los que no están se llaman igual
+var openSearchFields = map[string]string{
+	propertyTypeField:       "property_type",
+	coverImageField:         "cover_image",
+	coverThumbnailField:     "cover_thumbnail",
+	coverThumbnailWebPField: "cover_thumbnail_webp",
+	childFriendlyField:      "child_friendly",
+	infantFriendlyField:     "infant_friendly",
+	petsAllowedField:        "pets_allowed",
+	smokingAllowedField:     "smoking_allowed",
+	eventsAllowedField:      "events_allowed",
+	instantBookField:        "instant_book",
+	cancellationPolicyField: "cancellation_policy",
+	nextAvailableField:      "next_available_date",
+	"geo_p":                 "location",
+}
+
+// openSearchFacetFields mapea el nombre del facet en la respuesta al campo del índice
+var openSearchFacetFields = map[string]string{
+	"type": "property_type",
+}
+
+// openSearchMapping es el mapping con el que se crea el índice si no existe
+// id y property_type son keyword para ordenar, filtrar y facetar; location es geo_point para el mapa
+// title, city y country tienen un subcampo "es" que ignora acentos y mayúsculas y reduce plurales
+// (igual que el tipo text_es_folded de Solr); los índices creados antes se tienen que recrear para tenerlo
+// i18n tiene el título y la descripción de cada idioma indexado (domain.IndexedLanguages) con su analizador
+const openSearchMapping = `{
+	"settings": {
+		"analysis": {
+			"filter": {
+				"spanish_light_stem": {"type": "stemmer", "language": "light_spanish"}
+			},
+			"analyzer": {
+				"spanish_folded": {
+					"tokenizer": "standard",
+					"filter": ["lowercase", "asciifolding", "spanish_light_stem"]
+				}
+			}
+		}
+	},
+	"mappings": {
+		"properties": {
+			"id":            {"type": "keyword"},
+			"title":         {"type": "text", "fields": {"es": {"type": "text", "analyzer": "spanish_folded"}}},
+			"description":   {"type": "text"},
+			"city":          {"type": "text", "fields": {"es": {"type": "text", "analyzer": "spanish_folded"}}},
+			"country":       {"type": "text", "fields": {"es": {"type": "text", "analyzer": "spanish_folded"}}},
+			"property_type": {"type": "keyword"},
+			"location":      {"type": "geo_point"},
+			"price":         {"type": "double"},
+			"bedrooms":      {"type": "integer"},
+			"bathrooms":     {"type": "integer"},
+			"max_guests":    {"type": "integer"},
+			"images":        {"type": "keyword", "index": false},
+			"cover_image":   {"type": "keyword", "index": false},
+			"cover_thumbnail":      {"type": "keyword", "index": false},
+			"cover_thumbnail_webp": {"type": "keyword", "index": false},
+			"owner_id":      {"type": "long"},
+			"available":     {"type": "boolean"},
+			"child_friendly":  {"type": "boolean"},
+			"infant_friendly": {"type": "boolean"},
+			"pets_allowed":    {"type": "boolean"},
+			"smoking_allowed": {"type": "boolean"},
+			"events_allowed":  {"type": "boolean"},
+			"instant_book":    {"type": "boolean"},
+			"cancellation_policy": {"type": "keyword"},
+			"popularity":    {"type": "long"},
+			"booked_ranges": {"type": "date_range", "format": "yyyy-MM-dd"},
+			"next_available_date": {"type": "date", "format": "yyyy-MM-dd"},
+			"language":      {"type": "keyword"},
+			"i18n": {
+				"properties": {
+					"es": {"properties": {"title": {"type": "text", "analyzer": "spanish"}, "description": {"type": "text", "analyzer": "spanish"}}},
+					"en": {"properties": {"title": {"type": "text", "analyzer": "english"}, "description": {"type": "text", "analyzer": "english"}}},
+					"pt": {"properties": {"title": {"type": "text", "analyzer": "portuguese"}, "description": {"type": "text", "analyzer": "portuguese"}}},
+					"fr": {"properties": {"title": {"type": "text", "analyzer": "french"}, "description": {"type": "text", "analyzer": "french"}}},
+					"de": {"properties": {"title": {"type": "text", "analyzer": "german"}, "description": {"type": "text", "analyzer": "german"}}},
+					"it": {"properties": {"title": {"type": "text", "analyzer": "italian"}, "description": {"type": "text", "analyzer": "italian"}}}
+				}
+			},
+			"created_at":    {"type": "date"},
+			"updated_at":    {"type": "date"}
+		}
+	}
+}`
+
+// OpenSearchOptions contiene los datos de conexión a OpenSearch (o Elasticsearch)
+type OpenSearchOptions struct {
+	// URL es la URL base del cluster (ej: "http://localhost:9200")
+	URL string
+
+	// Index es el nombre del índice de propiedades
+	Index string
+
+	// Username y Password son las credenciales de basic auth (vacías si no se usan)
+	Username string
+	Password string
+}
+
+// openSearchRepository es la implementación de SearchIndex sobre OpenSearch
+type openSearchRepository struct {
+	options    OpenSearchOptions
+	httpClient *http.Client
+
+	// indexReady indica que ya se verificó (o creó) el índice con su mapping
+	indexMu    sync.Mutex
+	indexReady bool
+}
+
+// NewOpenSearchRepository crea una nueva instancia del repositorio de OpenSearch
+// El índice se crea con su mapping la primera vez que se usa si todavía no existe
+func NewOpenSearchRepository(options OpenSearchOptions, httpClient *http.Client) SearchIndex {
+	options.URL = strings.TrimSuffix(options.URL, "/")
+	return &openSearchRepository{
+		options:    options,
+		httpClient: httpClient,
+	}
+}
+
+// openSearchDocument representa una propiedad en el índice de OpenSearch
+type openSearchDocument struct {
+	ID                 string                    `json:"id"`
+	Title              string                    `json:"title"`
+	Description        string                    `json:"description"`
+	City               string                    `json:"city"`
+	Country            string                    `json:"country"`
+	PropertyType       string                    `json:"property_type,omitempty"`
+	Location           *openSearchGeoPoint       `json:"location,omitempty"`
+	PricePerNight      float64                   `json:"price"`
+	Bedrooms           int                       `json:"bedrooms"`
+	Bathrooms          int                       `json:"bathrooms"`
+	MaxGuests          int                       `json:"max_guests"`
+	Images             []string                  `json:"images"`
+	CoverImage         string                    `json:"cover_image,omitempty"`
+	CoverThumbnail     string                    `json:"cover_thumbnail,omitempty"`
+	CoverThumbnailWebP string                    `json:"cover_thumbnail_webp,omitempty"`
+	OwnerID            uint                      `json:"owner_id"`
+	Available          bool                      `json:"available"`
+	ChildFriendly      bool                      `json:"child_friendly"`
+	InfantFriendly     bool                      `json:"infant_friendly"`
+	PetsAllowed        bool                      `json:"pets_allowed"`
+	SmokingAllowed     bool                      `json:"smoking_allowed"`
+	EventsAllowed      bool                      `json:"events_allowed"`
+	InstantBook        bool                      `json:"instant_book"`
+	CancellationPolicy string                    `json:"cancellation_policy,omitempty"`
+	Popularity         int64                     `json:"popularity"`
+	BookedRanges       []openSearchRange         `json:"booked_ranges,omitempty"`
+	NextAvailableDate  string                    `json:"next_available_date,omitempty"`
+	Language           string                    `json:"language,omitempty"`
+	I18n               map[string]openSearchText `json:"i18n,omitempty"`
+	CreatedAt          time.Time                 `json:"created_at"`
+	UpdatedAt          time.Time                 `json:"updated_at"`
+}
+
+// openSearchText es el título y la descripción de la propiedad en un idioma
+type openSearchText struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// openSearchRange es un valor de un campo date_range (días YYYY-MM-DD, fin exclusivo)
+type openSearchRange struct {
+	Gte string `json:"gte"`
+	Lt  string `json:"lt"`
+}
+
+// openSearchGeoPoint es un geo_point en formato objeto
+type openSearchGeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// openSearchResponse representa la respuesta de _search
+type openSearchResponse struct {
+	// Took es el tiempo que OpenSearch tardó en resolver la consulta (en milisegundos)
+	Took int `json:"took"`
+
+	Hits struct {
+		Total struct {
+			Value int `json:"value"`
+		} `json:"total"`
+		Hits []struct {
+			Score  float64            `json:"_score"`
+			Source openSearchDocument `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+
+	Aggregations map[string]struct {
+		Buckets []struct {
+			Key      string `json:"key"`
+			DocCount int    `json:"doc_count"`
+		} `json:"buckets"`
+	} `json:"aggregations"`
+}
+
+// Name retorna el nombre del backend
+func (r *openSearchRepository) Name() string {
+	return "opensearch"
+}
+
+// Search realiza una búsqueda de propiedades con filtros, paginación y facets
+// Respeta la misma semántica que Solr: texto parcial en title/city/country, filtros exactos
+// y facets que ignoran su propio filtro (el filtro de tipo va en post_filter)
+func (r *openSearchRepository) Search(ctx context.Context, request dto.SearchRequest) (dto.SearchResult, error) {
+	if err := r.ensureIndex(ctx); err != nil {
+		return dto.SearchResult{}, err
+	}
+
+	trace := SearchTraceFrom(ctx)
+	start := time.Now()
+	var searchResp openSearchResponse
+	if err := r.doJSON(ctx, http.MethodPost, r.searchPath(), buildOpenSearchSearchBody(request), &searchResp); err != nil {
+		return dto.SearchResult{}, err
+	}
+	trace.Since(TraceIndex, start)
+	trace.Add(TraceIndexEngine, time.Duration(searchResp.Took)*time.Millisecond)
+
+	defer trace.Since(TraceMapping, time.Now())
+	properties := make([]domain.Property, 0, len(searchResp.Hits.Hits))
+	for _, hit := range searchResp.Hits.Hits {
+		property := openSearchDocToProperty(hit.Source)
+		if request.Hydrate {
+			property.Score = hit.Score
+		}
+		properties = append(properties, property)
+	}
+
+	var facets map[string][]dto.FacetValue
+	if len(searchResp.Aggregations) > 0 {
+		facets = make(map[string][]dto.FacetValue, len(searchResp.Aggregations))
+		for name, aggregation := range searchResp.Aggregations {
+			values := make([]dto.FacetValue, 0, len(aggregation.Buckets))
+			for _, bucket := range aggregation.Buckets {
+				values = append(values, dto.FacetValue{Value: bucket.Key, Count: bucket.DocCount})
+			}
+			facets[name] = values
+		}
+	}
+
+	return dto.SearchResult{
+		Properties: properties,
+		Total:      searchResp.Hits.Total.Value,
+		Facets:     facets,
+	}, nil
+}
+
+// DescribeSearch retorna el request a _search con el que se resolvería la búsqueda (para diagnóstico)
+func (r *openSearchRepository) DescribeSearch(request dto.SearchRequest) string {
+	body, err := json.Marshal(buildOpenSearchSearchBody(request))
+	if err != nil {
+		return fmt.Sprintf("error serializando la consulta: %v", err)
+	}
+	return "POST " + r.options.URL + r.searchPath() + " " + string(body)
+}
+
+// searchPath es la ruta de _search del índice
+func (r *openSearchRepository) searchPath() string {
+	return "/" + url.PathEscape(r.options.Index) + "/_search"
+}
+
+// buildOpenSearchSearchBody arma el cuerpo de _search: query, paginación, orden, facets y campos a devolver
+func buildOpenSearchSearchBody(request dto.SearchRequest) map[string]interface{} {
+	page := request.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := request.PageSize
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	body := map[string]interface{}{
+		"query":            buildOpenSearchQuery(request),
+		"from":             (page - 1) * pageSize,
+		"size":             pageSize,
+		"sort":             buildOpenSearchSort(request.Sort),
+		"track_total_hits": true,
+	}
+
+	// Filtro por tipo de propiedad en post_filter: así el facet de tipos muestra también los otros tipos
+	if request.Type != "" {
+		body["post_filter"] = map[string]interface{}{
+			"term": map[string]interface{}{"property_type": request.Type},
+		}
+	}
+
+	// Facets: conteo de resultados por cada valor de los filtros facetados
+	aggregations := make(map[string]interface{}, len(openSearchFacetFields))
+	for name, field := range openSearchFacetFields {
+		aggregations[name] = map[string]interface{}{
+			"terms": map[string]interface{}{"field": field, "size": request.EffectiveFacetLimit(), "min_doc_count": 1},
+		}
+	}
+	body["aggs"] = aggregations
+
+	// Campos a devolver: con fields solo se piden los campos seleccionados
+	// y con hydrate=true solo el ID (el resto sale de properties-api)
+	switch {
+	case request.Hydrate:
+		body["_source"] = []string{"id"}
+	case len(request.Projection) > 0:
+		body["_source"] = openSearchSourceFields(request)
+	}
+	return body
+}
+
+// openSearchMLTFields son los campos de texto que compara more_like_this
+var openSearchMLTFields = []string{"title", "description", "city", "country"}
+
+// GetProperty obtiene una propiedad indexada por su ID
+func (r *openSearchRepository) GetProperty(ctx context.Context, propertyID string) (domain.Property, error) {
+	if err := r.ensureIndex(ctx); err != nil {
+		return domain.Property{}, err
+	}
+
+	path := fmt.Sprintf("/%s/_doc/%s", url.PathEscape(r.options.Index), url.PathEscape(propertyID))
+	status, body, err := r.do(ctx, http.MethodGet, path, "", nil)
+	if err != nil {
+		return domain.Property{}, err
+	}
+	if status == http.StatusNotFound {
+		return domain.Property{}, ErrPropertyNotIndexed
+	}
+	if status != http.StatusOK {
+		return domain.Property{}, fmt.Errorf("error obteniendo documento de OpenSearch (status %d): %s", status, string(body))
+	}
+
+	var getResp struct {
+		Source openSearchDocument `json:"_source"`
+	}
+	if err := json.Unmarshal(body, &getResp); err != nil {
+		return domain.Property{}, fmt.Errorf("error parseando respuesta JSON de OpenSearch: %w", err)
+	}
+	return openSearchDocToProperty(getResp.Source), nil
+}
+
+// MoreLikeThis busca propiedades parecidas con la query more_like_this (equivalente al MLT de Solr)
+func (r *openSearchRepository) MoreLikeThis(ctx context.Context, property domain.Property, limit int) ([]domain.Property, error) {
+	body := map[string]interface{}{
+		"size": limit,
+		"query": map[string]interface{}{
+			"more_like_this": map[string]interface{}{
+				"fields":        openSearchMLTFields,
+				"like":          []interface{}{map[string]interface{}{"_index": r.options.Index, "_id": property.ID}},
+				"min_term_freq": 1,
+				"min_doc_freq":  1,
+			},
+		},
+	}
+
+	var searchResp openSearchResponse
+	if err := r.doJSON(ctx, http.MethodPost, "/"+url.PathEscape(r.options.Index)+"/_search", body, &searchResp); err != nil {
+		return nil, err
+	}
+
+	properties := make([]domain.Property, 0, len(searchResp.Hits.Hits))
+	for _, hit := range searchResp.Hits.Hits {
+		properties = append(properties, openSearchDocToProperty(hit.Source))
+	}
+	return properties, nil
+}
+
+// openSearchVersionsPageSize es la cantidad de documentos por página al listar las versiones del índice
+const openSearchVersionsPageSize = 1000
+
+// ListPropertyVersions recorre todo el índice con search_after sobre id pidiendo solo id y updated_at
+func (r *openSearchRepository) ListPropertyVersions(ctx context.Context) (map[string]time.Time, error) {
+	if err := r.ensureIndex(ctx); err != nil {
+		return nil, err
+	}
+
+	versions := make(map[string]time.Time)
+	var searchAfter []interface{}
+	for {
+		body := map[string]interface{}{
+			"size":    openSearchVersionsPageSize,
+			"query":   map[string]interface{}{"match_all": map[string]interface{}{}},
+			"_source": []string{"id", "updated_at"},
+			"sort":    []interface{}{map[string]string{"id": "asc"}},
+		}
+		if searchAfter != nil {
+			body["search_after"] = searchAfter
+		}
+
+		var page struct {
+			Hits struct {
+				Hits []struct {
+					Source struct {
+						ID        string    `json:"id"`
+						UpdatedAt time.Time `json:"updated_at"`
+					} `json:"_source"`
+					Sort []interface{} `json:"sort"`
+				} `json:"hits"`
+			} `json:"hits"`
+		}
+		if err := r.doJSON(ctx, http.MethodPost, "/"+url.PathEscape(r.options.Index)+"/_search", body, &page); err != nil {
+			return nil, fmt.Errorf("error listando versiones del índice: %w", err)
+		}
+
+		hits := page.Hits.Hits
+		for _, hit := range hits {
+			versions[hit.Source.ID] = hit.Source.UpdatedAt.UTC()
+		}
+		if len(hits) < openSearchVersionsPageSize {
+			return versions, nil
+		}
+		searchAfter = hits[len(hits)-1].Sort
+	}
+}
+
+// buildOpenSearchQuery arma la query bool con el texto y los filtros (salvo el tipo)
+func buildOpenSearchQuery(request dto.SearchRequest) map[string]interface{} {
+	boolQuery := map[string]interface{}{}
+
+	// Búsqueda por texto parcial en title, city y country (equivalente a *texto* en Solr)
+	// y por palabras en los subcampos "es", sin distinguir acentos ni plurales
+	if request.Query != "" {
+		pattern := "*" + escapeOpenSearchWildcard(strings.ToLower(request.Query)) + "*"
+		should := make([]interface{}, 0, 4)
+		for _, field := range []string{"title", "city", "country"} {
+			should = append(should, map[string]interface{}{
+				"wildcard": map[string]interface{}{
+					field: map[string]interface{}{"value": pattern, "case_insensitive": true},
+				},
+			})
+		}
+		should = append(should, map[string]interface{}{
+			"multi_match": map[string]interface{}{
+				"query":    request.Query,
+				"fields":   []string{"title.es", "city.es", "country.es"},
+				"type":     "cross_fields",
+				"operator": "and",
+			},
+		})
+		// Con lang también se busca en el título en ese idioma, con el analizador del idioma
+		if request.Lang != "" {
+			should = append(should, map[string]interface{}{
+				"match": map[string]interface{}{
+					"i18n." + request.Lang + ".title": map[string]interface{}{"query": request.Query, "operator": "and"},
+				},
+			})
+		}
+		boolQuery["must"] = map[string]interface{}{
+			"bool": map[string]interface{}{"should": should, "minimum_should_match": 1},
+		}
+	}
+
+	var filters []interface{}
+
+	// Filtro por ciudad y país (frase exacta, como city:"..." en Solr)
+	if request.City != "" {
+		filters = append(filters, map[string]interface{}{"match_phrase": map[string]interface{}{"city": request.City}})
+	}
+	if request.Country != "" {
+		filters = append(filters, map[string]interface{}{"match_phrase": map[string]interface{}{"country": request.Country}})
+	}
+
+	// Filtro por rango de precio
+	if request.MinPrice > 0 || request.MaxPrice > 0 {
+		priceRange := map[string]interface{}{"gte": request.MinPrice}
+		if request.MaxPrice > 0 {
+			priceRange["lte"] = request.MaxPrice
+		}
+		filters = append(filters, map[string]interface{}{"range": map[string]interface{}{"price": priceRange}})
+	}
+
+	// Filtro por bounding box (búsqueda por mapa)
+	if request.HasBoundingBox() {
+		filters = append(filters, map[string]interface{}{
+			"geo_bounding_box": map[string]interface{}{
+				"location": map[string]interface{}{
+					"top_left":     openSearchGeoPoint{Lat: *request.BboxMaxLat, Lon: *request.BboxMinLng},
+					"bottom_right": openSearchGeoPoint{Lat: *request.BboxMinLat, Lon: *request.BboxMaxLng},
+				},
+			},
+		})
+	}
+
+	// Filtros por habitaciones, baños y capacidad mínima
+	if request.Bedrooms > 0 {
+		filters = append(filters, map[string]interface{}{"term": map[string]interface{}{"bedrooms": request.Bedrooms}})
+	}
+	if request.Bathrooms > 0 {
+		filters = append(filters, map[string]interface{}{"term": map[string]interface{}{"bathrooms": request.Bathrooms}})
+	}
+	if request.MinGuests > 0 {
+		filters = append(filters, map[string]interface{}{
+			"range": map[string]interface{}{"max_guests": map[string]interface{}{"gte": request.MinGuests}},
+		})
+	}
+
+	// Filtros por propiedades aptas para niños y bebés
+	if request.ChildFriendly {
+		filters = append(filters, map[string]interface{}{"term": map[string]interface{}{"child_friendly": true}})
+	}
+	if request.InfantFriendly {
+		filters = append(filters, map[string]interface{}{"term": map[string]interface{}{"infant_friendly": true}})
+	}
+
+	// Filtros por reglas de la casa
+	if request.PetsAllowed {
+		filters = append(filters, map[string]interface{}{"term": map[string]interface{}{"pets_allowed": true}})
+	}
+	if request.SmokingAllowed {
+		filters = append(filters, map[string]interface{}{"term": map[string]interface{}{"smoking_allowed": true}})
+	}
+	if request.EventsAllowed {
+		filters = append(filters, map[string]interface{}{"term": map[string]interface{}{"events_allowed": true}})
+	}
+
+	// Filtros por reserva instantánea y política de cancelación
+	if request.InstantBook {
+		filters = append(filters, map[string]interface{}{"term": map[string]interface{}{"instant_book": true}})
+	}
+	if request.CancellationPolicy != "" {
+		filters = append(filters, map[string]interface{}{"term": map[string]interface{}{"cancellation_policy": request.CancellationPolicy}})
+	}
+
+	if len(filters) > 0 {
+		boolQuery["filter"] = filters
+	}
+
+	// Filtro por fechas: se excluyen las propiedades con algún rango ocupado que se interseque con la estadía
+	if request.HasStayDates() {
+		boolQuery["must_not"] = []interface{}{map[string]interface{}{
+			"range": map[string]interface{}{
+				"booked_ranges": map[string]interface{}{
+					"gte":      request.CheckIn,
+					"lt":       request.CheckOut,
+					"relation": "intersects",
+				},
+			},
+		}}
+	}
+	if len(boolQuery) == 0 {
+		return map[string]interface{}{"match_all": map[string]interface{}{}}
+	}
+	return map[string]interface{}{"bool": boolQuery}
+}
+
+// buildOpenSearchSort arma el sort con el id como último criterio de desempate
+// Sin criterios se mantiene el orden por relevancia (_score)
+func buildOpenSearchSort(fields []dto.SortField) []interface{} {
+	sort := make([]interface{}, 0, len(fields)+2)
+	if len(fields) == 0 {
+		sort = append(sort, map[string]string{"_score": "desc"})
+	}
+	for _, field := range fields {
+		sort = append(sort, map[string]string{openSearchField(field.Field): field.Order})
+	}
+	return append(sort, map[string]string{"id": "asc"})
+}
+
+// openSearchSourceFields retorna los campos de _source a pedir con fields
+// Con bounding box se agrega location porque los clusters del mapa usan las coordenadas
+func openSearchSourceFields(request dto.SearchRequest) []string {
+	names := dto.SolrFieldNames(request.Projection)
+	fields := make([]string, 0, len(names)+1)
+	for _, name := range names {
+		fields = append(fields, openSearchField(name))
+	}
+	if request.HasBoundingBox() && !containsField(fields, "location") {
+		fields = append(fields, "location")
+	}
+	// Con lang el título y la descripción se traducen: hacen falta los textos de ese idioma
+	if request.Lang != "" && (containsField(fields, "title") || containsField(fields, "description")) {
+		fields = append(fields, "language", "i18n."+request.Lang)
+	}
+	return fields
+}
+
+// openSearchField traduce un campo del esquema de Solr al campo del índice
+func openSearchField(field string) string {
+	if translated, ok := openSearchFields[field]; ok {
+		return translated
+	}
+	return field
+}
+
+// escapeOpenSearchWildcard escapa los caracteres especiales de una query wildcard
+func escapeOpenSearchWildcard(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`).Replace(value)
+}
+
+// IndexProperty indexa (o reemplaza) una propiedad usando su ID como _id
+// refresh=true la deja visible en búsquedas enseguida, como el commit de Solr
+func (r *openSearchRepository) IndexProperty(ctx context.Context, property domain.Property) error {
+	if err := r.ensureIndex(ctx); err != nil {
+		return err
+	}
+
+	log.Printf("📝 Indexando propiedad en OpenSearch - ID: %s, Title: %s", property.ID, property.Title)
+	path := fmt.Sprintf("/%s/_doc/%s?refresh=true", url.PathEscape(r.options.Index), url.PathEscape(property.ID))
+	if err := r.doJSON(ctx, http.MethodPut, path, propertyToOpenSearch(property), nil); err != nil {
+		return fmt.Errorf("error indexando propiedad en OpenSearch: %w", err)
+	}
+
+	log.Printf("✅ Propiedad indexada exitosamente en OpenSearch - ID: %s", property.ID)
+	return nil
+}
+
+// UpdateProperty actualiza una propiedad existente (reemplaza el documento)
+func (r *openSearchRepository) UpdateProperty(ctx context.Context, property domain.Property) error {
+	return r.IndexProperty(ctx, property)
+}
+
+// DeleteProperty elimina una propiedad por su ID
+// Eliminar una propiedad que no está indexada no es un error (igual que en Solr)
+func (r *openSearchRepository) DeleteProperty(ctx context.Context, propertyID string) error {
+	path := fmt.Sprintf("/%s/_doc/%s?refresh=true", url.PathEscape(r.options.Index), url.PathEscape(propertyID))
+	status, body, err := r.do(ctx, http.MethodDelete, path, "", nil)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK && status != http.StatusNotFound {
+		return fmt.Errorf("error eliminando en OpenSearch (status %d): %s", status, string(body))
+	}
+	return nil
+}
+
+// DeletePropertiesByOwner elimina todas las propiedades de un usuario (delete by query)
+func (r *openSearchRepository) DeletePropertiesByOwner(ctx context.Context, ownerID uint) error {
+	body := map[string]interface{}{
+		"query": map[string]interface{}{"term": map[string]interface{}{"owner_id": ownerID}},
+	}
+	path := "/" + url.PathEscape(r.options.Index) + "/_delete_by_query?refresh=true&conflicts=proceed"
+	return r.doJSON(ctx, http.MethodPost, path, body, nil)
+}
+
+// UpdatePopularity actualiza el campo popularity con una operación _bulk de updates parciales
+// Los documentos inexistentes fallan individualmente (404) sin afectar al resto del lote
+func (r *openSearchRepository) UpdatePopularity(ctx context.Context, views map[string]int64) error {
+	var bulk bytes.Buffer
+	encoder := json.NewEncoder(&bulk)
+	for propertyID, total := range views {
+		action := map[string]interface{}{"update": map[string]string{"_index": r.options.Index, "_id": propertyID}}
+		doc := map[string]interface{}{"doc": map[string]int64{"popularity": total}}
+		if err := encoder.Encode(action); err != nil {
+			return fmt.Errorf("error serializando actualización de popularidad: %w", err)
+		}
+		if err := encoder.Encode(doc); err != nil {
+			return fmt.Errorf("error serializando actualización de popularidad: %w", err)
+		}
+	}
+
+	status, body, err := r.do(ctx, http.MethodPost, "/_bulk?refresh=true", "application/x-ndjson", &bulk)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("error actualizando popularidad en OpenSearch (status %d): %s", status, string(body))
+	}
+
+	var bulkResp struct {
+		Items []map[string]struct {
+			Status int `json:"status"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(body, &bulkResp); err != nil {
+		return fmt.Errorf("error parseando respuesta de _bulk: %w", err)
+	}
+	for _, item := range bulkResp.Items {
+		for _, result := range item {
+			if result.Status >= 300 && result.Status != http.StatusNotFound {
+				return fmt.Errorf("error actualizando popularidad en OpenSearch (status %d en el lote)", result.Status)
+			}
+		}
+	}
+	return nil
+}
+
+// UpdateAvailability reemplaza los días ocupados y el primer día libre con un update parcial
+// Una propiedad que todavía no está indexada (404) se ignora: los toma al indexarse desde el snapshot
+func (r *openSearchRepository) UpdateAvailability(ctx context.Context, propertyID string, booked []domain.DateRange, nextAvailable string) error {
+	var next interface{}
+	if nextAvailable != "" {
+		next = nextAvailable
+	}
+	doc := map[string]interface{}{"doc": map[string]interface{}{
+		"booked_ranges":       toOpenSearchRanges(booked),
+		"next_available_date": next,
+	}}
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("error serializando actualización de disponibilidad: %w", err)
+	}
+
+	path := "/" + url.PathEscape(r.options.Index) + "/_update/" + url.PathEscape(propertyID) + "?refresh=true"
+	status, respBody, err := r.do(ctx, http.MethodPost, path, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK && status != http.StatusNotFound {
+		return fmt.Errorf("error actualizando disponibilidad en OpenSearch (status %d): %s", status, string(respBody))
+	}
+	return nil
+}
+
+// Ping verifica que el índice exista y el cluster responda
+func (r *openSearchRepository) Ping(ctx context.Context) error {
+	status, _, err := r.do(ctx, http.MethodHead, "/"+url.PathEscape(r.options.Index), "", nil)
+	if err != nil {
+		return fmt.Errorf("error haciendo ping a OpenSearch: %w", err)
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("ping a OpenSearch respondió status %d", status)
+	}
+	return nil
+}
+
+// NewShadow no está soportado en OpenSearch: la reindexación en un índice sombra es solo para Solr
+func (r *openSearchRepository) NewShadow(ctx context.Context) (ShadowIndex, error) {
+	return nil, ErrShadowUnsupported
+}
+
+// ensureIndex crea el índice con su mapping si todavía no existe
+// Si falla se reintenta en la próxima operación
+func (r *openSearchRepository) ensureIndex(ctx context.Context) error {
+	r.indexMu.Lock()
+	defer r.indexMu.Unlock()
+	if r.indexReady {
+		return nil
+	}
+
+	indexPath := "/" + url.PathEscape(r.options.Index)
+	status, _, err := r.do(ctx, http.MethodHead, indexPath, "", nil)
+	if err != nil {
+		return fmt.Errorf("error verificando el índice de OpenSearch: %w", err)
+	}
+
+	if status == http.StatusNotFound {
+		status, body, err := r.do(ctx, http.MethodPut, indexPath, "application/json", strings.NewReader(openSearchMapping))
+		if err != nil {
+			return fmt.Errorf("error creando el índice de OpenSearch: %w", err)
+		}
+		// Otra instancia puede haberlo creado al mismo tiempo
+		if status != http.StatusOK && !strings.Contains(string(body), "resource_already_exists_exception") {
+			return fmt.Errorf("error creando el índice de OpenSearch (status %d): %s", status, string(body))
+		}
+		log.Printf("✅ Índice '%s' creado en OpenSearch", r.options.Index)
+	} else if status != http.StatusOK {
+		return fmt.Errorf("error verificando el índice de OpenSearch (status %d)", status)
+	}
+
+	r.indexReady = true
+	return nil
+}
+
+// doJSON envía body como JSON y decodifica la respuesta en out (si no es nil)
+// Cualquier status distinto de 2xx es un error
+func (r *openSearchRepository) doJSON(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("error serializando request a OpenSearch: %w", err)
+	}
+
+	status, respBody, err := r.do(ctx, method, path, "application/json", bytes.NewReader(jsonData))
+	if err != nil {
+		return err
+	}
+	if status < 200 || status >= 300 {
+		return fmt.Errorf("error en respuesta de OpenSearch (status %d): %s", status, string(respBody))
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("error parseando respuesta JSON de OpenSearch: %w", err)
+		}
+	}
+	return nil
+}
+
+// do realiza la petición HTTP y retorna el status y el body
+func (r *openSearchRepository) do(ctx context.Context, method, path, contentType string, body io.Reader) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, r.options.URL+path, body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("error creando request HTTP: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if r.options.Username != "" {
+		req.SetBasicAuth(r.options.Username, r.options.Password)
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("error realizando petición a OpenSearch: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("error leyendo respuesta de OpenSearch: %w", err)
+	}
+	return resp.StatusCode, respBody, nil
+}
+
+// propertyToOpenSearch convierte una domain.Property al documento del índice
+func propertyToOpenSearch(property domain.Property) openSearchDocument {
+	// Asegurar que CreatedAt tenga un valor válido
+	createdAt := property.CreatedAt.UTC()
+	if createdAt.IsZero() {
+		createdAt = time.Now().UTC()
+	}
+	updatedAt := property.UpdatedAt.UTC()
+	if updatedAt.IsZero() {
+		updatedAt = createdAt
+	}
+
+	doc := openSearchDocument{
+		ID:                 property.ID,
+		Title:              property.Title,
+		Description:        property.Description,
+		City:               property.City,
+		Country:            property.Country,
+		PropertyType:       property.PropertyType,
+		PricePerNight:      property.PricePerNight,
+		Bedrooms:           property.Bedrooms,
+		Bathrooms:          property.Bathrooms,
+		MaxGuests:          property.MaxGuests,
+		Images:             property.Images,
+		CoverImage:         property.CoverImage,
+		CoverThumbnail:     property.CoverThumbnail,
+		CoverThumbnailWebP: property.CoverThumbnailWebP,
+		OwnerID:            property.OwnerID,
+		Available:          property.Available,
+		ChildFriendly:      property.ChildFriendly,
+		InfantFriendly:     property.InfantFriendly,
+		PetsAllowed:        property.PetsAllowed,
+		SmokingAllowed:     property.SmokingAllowed,
+		EventsAllowed:      property.EventsAllowed,
+		InstantBook:        property.InstantBook,
+		CancellationPolicy: property.CancellationPolicy,
+		Popularity:         property.Popularity,
+		BookedRanges:       toOpenSearchRanges(property.BookedRanges),
+		NextAvailableDate:  property.NextAvailableDate,
+		Language:           property.Language,
+		CreatedAt:          createdAt,
+		UpdatedAt:          updatedAt,
+	}
+	// Las propiedades sin coordenadas no se indexan en el mapa
+	if property.Latitude != 0 || property.Longitude != 0 {
+		doc.Location = &openSearchGeoPoint{Lat: property.Latitude, Lon: property.Longitude}
+	}
+	for _, language := range domain.IndexedLanguages {
+		text, ok := property.TextIn(language)
+		if !ok {
+			continue
+		}
+		if doc.I18n == nil {
+			doc.I18n = make(map[string]openSearchText)
+		}
+		doc.I18n[language] = openSearchText{Title: text.Title, Description: text.Description}
+	}
+	return doc
+}
+
+// openSearchDocToProperty convierte un documento del índice a domain.Property
+func openSearchDocToProperty(doc openSearchDocument) domain.Property {
+	property := domain.Property{
+		ID:                 doc.ID,
+		Title:              doc.Title,
+		Description:        doc.Description,
+		City:               doc.City,
+		Country:            doc.Country,
+		PropertyType:       doc.PropertyType,
+		PricePerNight:      doc.PricePerNight,
+		Bedrooms:           doc.Bedrooms,
+		Bathrooms:          doc.Bathrooms,
+		MaxGuests:          doc.MaxGuests,
+		Images:             doc.Images,
+		CoverImage:         doc.CoverImage,
+		CoverThumbnail:     doc.CoverThumbnail,
+		CoverThumbnailWebP: doc.CoverThumbnailWebP,
+		OwnerID:            doc.OwnerID,
+		Available:          doc.Available,
+		ChildFriendly:      doc.ChildFriendly,
+		InfantFriendly:     doc.InfantFriendly,
+		PetsAllowed:        doc.PetsAllowed,
+		SmokingAllowed:     doc.SmokingAllowed,
+		EventsAllowed:      doc.EventsAllowed,
+		InstantBook:        doc.InstantBook,
+		CancellationPolicy: doc.CancellationPolicy,
+		Popularity:         doc.Popularity,
+		NextAvailableDate:  doc.NextAvailableDate,
+		Language:           doc.Language,
+		CreatedAt:          doc.CreatedAt.UTC(),
+		UpdatedAt:          doc.UpdatedAt.UTC(),
+	}
+	if doc.Location != nil {
+		property.Latitude, property.Longitude = doc.Location.Lat, doc.Location.Lon
+	}
+	for _, booked := range doc.BookedRanges {
+		property.BookedRanges = append(property.BookedRanges, domain.DateRange{Start: booked.Gte, End: booked.Lt})
+	}
+	// Los textos del idioma principal ya están en title y description; el resto son las traducciones
+	for language, text := range doc.I18n {
+		if _, isMain := property.TextIn(language); isMain || text.Title == "" {
+			continue
+		}
+		if property.Translations == nil {
+			property.Translations = make(map[string]domain.Translation)
+		}
+		property.Translations[language] = domain.Translation{Title: text.Title, Description: text.Description}
+	}
+	return property
+}
+
+// toOpenSearchRanges convierte los días ocupados a valores de date_range
+func toOpenSearchRanges(ranges []domain.DateRange) []openSearchRange {
+	converted := make([]openSearchRange, 0, len(ranges))
+	for _, r := range ranges {
+		converted = append(converted, openSearchRange{Gte: r.Start, Lt: r.End})
+	}
+	return converted
+}
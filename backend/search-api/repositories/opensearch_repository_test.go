@@ -0,0 +1,213 @@
+package repositories
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"search-api/domain"
+	"search-api/dto"
+)
+
+// fakeOpenSearch guarda los documentos en memoria y registra los requests
+// _search devuelve todos los documentos: los tests verifican la consulta enviada, no la evalúan
+type fakeOpenSearch struct {
+	mu          sync.Mutex
+	indexExists bool
+	docs        map[string]json.RawMessage
+	requests    []string
+	lastSearch  map[string]interface{}
+	bulkStatus  int
+}
+
+func newFakeOpenSearch(t *testing.T) (*fakeOpenSearch, SearchIndex) {
+	fake := &fakeOpenSearch{docs: make(map[string]json.RawMessage), bulkStatus: http.StatusOK}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	repo := NewOpenSearchRepository(OpenSearchOptions{URL: server.URL + "/", Index: "properties", Username: "spotly", Password: "secreto"}, server.Client())
+	return fake, repo
+}
+
+func (f *fakeOpenSearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+	if user, password, ok := r.BasicAuth(); !ok || user != "spotly" || password != "secreto" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+
+	switch {
+	case r.URL.Path == "/properties" && r.Method == http.MethodHead:
+		if !f.indexExists {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case r.URL.Path == "/properties" && r.Method == http.MethodPut:
+		f.indexExists = true
+		w.Write([]byte(`{"acknowledged":true}`))
+	case strings.HasPrefix(r.URL.Path, "/properties/_doc/"):
+		id := strings.TrimPrefix(r.URL.Path, "/properties/_doc/")
+		if r.Method == http.MethodDelete {
+			if _, ok := f.docs[id]; !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			delete(f.docs, id)
+			return
+		}
+		f.docs[id] = body
+		w.WriteHeader(http.StatusCreated)
+	case r.URL.Path == "/properties/_search":
+		json.Unmarshal(body, &f.lastSearch)
+		ids := make([]string, 0, len(f.docs))
+		for id := range f.docs {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		hits := make([]map[string]interface{}, 0, len(ids))
+		for _, id := range ids {
+			hits = append(hits, map[string]interface{}{"_score": 1.5, "_source": f.docs[id]})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"took":         3,
+			"hits":         map[string]interface{}{"total": map[string]int{"value": len(ids)}, "hits": hits},
+			"aggregations": map[string]interface{}{"type": map[string]interface{}{"buckets": []map[string]interface{}{{"key": "casa", "doc_count": len(ids)}}}},
+		})
+	case r.URL.Path == "/_bulk":
+		// Cada par de líneas es una acción y su documento; las propiedades inexistentes fallan con 404
+		var items []map[string]map[string]int
+		scanner := bufio.NewScanner(bytes.NewReader(body))
+		for scanner.Scan() {
+			var action map[string]map[string]string
+			json.Unmarshal(scanner.Bytes(), &action)
+			scanner.Scan()
+			status := f.bulkStatus
+			if _, ok := f.docs[action["update"]["_id"]]; !ok {
+				status = http.StatusNotFound
+			}
+			items = append(items, map[string]map[string]int{"update": {"status": status}})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func (f *fakeOpenSearch) recorded() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.requests...)
+}
+
+func TestOpenSearch_IndexAndSearchRoundTrip(t *testing.T) {
+	fake, repo := newFakeOpenSearch(t)
+	ctx := context.Background()
+
+	property := domain.Property{
+		ID: "p1", Title: "Cabaña en el lago", City: "Bariloche", Country: "Argentina", PropertyType: "casa",
+		Latitude: -41.13, Longitude: -71.3, PricePerNight: 90000, MaxGuests: 4, PetsAllowed: true,
+		BookedRanges: []domain.DateRange{{Start: "2099-08-10", End: "2099-08-14"}},
+	}
+	if err := repo.IndexProperty(ctx, property); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := repo.IndexProperty(ctx, domain.Property{ID: "p2", Title: "Departamento", City: "Córdoba"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// El índice se crea con su mapping una sola vez
+	if requests := strings.Join(fake.recorded(), ";"); requests != "HEAD /properties;PUT /properties;PUT /properties/_doc/p1;PUT /properties/_doc/p2" {
+		t.Fatalf("unexpected requests: %s", requests)
+	}
+	var doc map[string]interface{}
+	json.Unmarshal(fake.docs["p1"], &doc)
+	if doc["property_type"] != "casa" || doc["pets_allowed"] != true || doc["location"] == nil {
+		t.Fatalf("expected the document in the OpenSearch field names, got %v", doc)
+	}
+
+	result, err := repo.Search(ctx, dto.SearchRequest{City: "Bariloche", Type: "casa", Page: 2, PageSize: 5, Hydrate: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Total != 2 || len(result.Properties) != 2 || result.Properties[0].Score != 1.5 {
+		t.Fatalf("expected both documents with their score, got %+v", result)
+	}
+	got := result.Properties[0]
+	if got.ID != "p1" || got.PropertyType != "casa" || got.Latitude != -41.13 || !got.PetsAllowed || len(got.BookedRanges) != 1 || got.BookedRanges[0].End != "2099-08-14" {
+		t.Fatalf("expected the property back from the document, got %+v", got)
+	}
+	if facets := result.Facets["type"]; len(facets) != 1 || facets[0] != (dto.FacetValue{Value: "casa", Count: 2}) {
+		t.Fatalf("expected the type facet, got %+v", result.Facets)
+	}
+
+	// El filtro por tipo va en post_filter para que el facet cuente los demás tipos
+	search := fake.lastSearch
+	if search["from"] != float64(5) || search["size"] != float64(5) || search["post_filter"] == nil {
+		t.Fatalf("expected page 2 of 5 with the type in post_filter, got %v", search)
+	}
+	if query, _ := json.Marshal(search["query"]); !strings.Contains(string(query), `"match_phrase":{"city":"Bariloche"}`) {
+		t.Fatalf("expected the city filter in the query, got %s", query)
+	}
+	if source, _ := json.Marshal(search["_source"]); string(source) != `["id"]` {
+		t.Fatalf("expected only the id with hydrate, got %s", source)
+	}
+}
+
+func TestOpenSearch_DeletePropertyIgnoresMissingDocuments(t *testing.T) {
+	fake, repo := newFakeOpenSearch(t)
+	ctx := context.Background()
+	if err := repo.IndexProperty(ctx, domain.Property{ID: "p1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, id := range []string{"p1", "p1", "nunca-indexada"} {
+		if err := repo.DeleteProperty(ctx, id); err != nil {
+			t.Fatalf("delete %s: unexpected error: %v", id, err)
+		}
+	}
+	if len(fake.docs) != 0 {
+		t.Fatalf("expected the document deleted, got %v", fake.docs)
+	}
+}
+
+func TestOpenSearch_UpdatePopularity(t *testing.T) {
+	fake, repo := newFakeOpenSearch(t)
+	ctx := context.Background()
+	if err := repo.IndexProperty(ctx, domain.Property{ID: "p1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Una propiedad que ya no está indexada no hace fallar el lote
+	if err := repo.UpdatePopularity(ctx, map[string]int64{"p1": 10, "borrada": 3}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Cualquier otro error del lote sí es un error
+	fake.bulkStatus = http.StatusConflict
+	if err := repo.UpdatePopularity(ctx, map[string]int64{"p1": 11}); err == nil || !strings.Contains(err.Error(), "409") {
+		t.Fatalf("expected the conflict reported, got %v", err)
+	}
+}
+
+func TestOpenSearch_RejectsWrongCredentials(t *testing.T) {
+	fake := &fakeOpenSearch{docs: make(map[string]json.RawMessage)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	repo := NewOpenSearchRepository(OpenSearchOptions{URL: server.URL, Index: "properties", Username: "spotly", Password: "otra"}, server.Client())
+
+	if err := repo.Ping(context.Background()); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("expected the ping to fail with 401, got %v", err)
+	}
+	if _, err := repo.Search(context.Background(), dto.SearchRequest{}); err == nil {
+		t.Fatal("expected the search to fail without a verified index")
+	}
+}
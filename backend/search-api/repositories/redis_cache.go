@@ -0,0 +1,205 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"search-api/dto"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisScanCount es la cantidad de keys que se le pide a SCAN por iteración
+const redisScanCount = 500
+
+// redisMaxIdleConns es la cantidad máxima de conexiones ociosas que se reutilizan
+const redisMaxIdleConns = 10
+
+// redisConnMaxIdleTime es cuánto puede quedar ociosa una conexión antes de cerrarla
+// (Redis o un balanceador en el medio pueden cortar las conexiones inactivas)
+const redisConnMaxIdleTime = 5 * time.Minute
+
+// RedisOptions contiene los datos de conexión a Redis
+type RedisOptions struct {
+	// Addr es la dirección del servidor (host:puerto)
+	Addr string
+
+	// Password es la contraseña de AUTH (vacía si Redis no la requiere)
+	Password string
+
+	// DB es la base lógica a usar (SELECT)
+	DB int
+
+	// Timeout limita la conexión y cada comando
+	Timeout time.Duration
+}
+
+// redisCache es el caché remoto sobre Redis
+// Usa go-redis: el pool verifica cada conexión ociosa antes de reutilizarla y descarta las que Redis cerró,
+// así un reinicio de Redis no se traduce en errores en los requests siguientes
+type redisCache struct {
+	client *redis.Client
+}
+
+// NewRedisCache crea el caché remoto sobre Redis
+// Las conexiones se abren a demanda: si Redis no está disponible el caché local sigue funcionando
+func NewRedisCache(options RedisOptions) RemoteCache {
+	if options.Timeout <= 0 {
+		options.Timeout = 2 * time.Second
+	}
+	log.Printf("✅ Cliente de Redis inicializado para %s (db %d)", options.Addr, options.DB)
+
+	return &redisCache{
+		client: redis.NewClient(&redis.Options{
+			Addr:            options.Addr,
+			Password:        options.Password,
+			DB:              options.DB,
+			DialTimeout:     options.Timeout,
+			ReadTimeout:     options.Timeout,
+			WriteTimeout:    options.Timeout,
+			MaxIdleConns:    redisMaxIdleConns,
+			ConnMaxIdleTime: redisConnMaxIdleTime,
+		}),
+	}
+}
+
+// Name retorna el nombre del backend
+func (c *redisCache) Name() string {
+	return "redis"
+}
+
+// Get retorna el valor guardado o ErrCacheMiss
+func (c *redisCache) Get(key string) ([]byte, error) {
+	value, err := c.client.Get(context.Background(), key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrCacheMiss
+	}
+	return value, err
+}
+
+// Set guarda el valor con TTL (sin TTL la key no expira)
+func (c *redisCache) Set(key string, value []byte, ttl time.Duration) error {
+	return c.client.Set(context.Background(), key, value, positiveTTL(ttl)).Err()
+}
+
+// Add guarda el valor solo si la key no existe (SET NX)
+func (c *redisCache) Add(key string, value []byte, ttl time.Duration) error {
+	stored, err := c.client.SetNX(context.Background(), key, value, positiveTTL(ttl)).Result()
+	if err != nil {
+		return err
+	}
+	if !stored {
+		return ErrCacheNotStored
+	}
+	return nil
+}
+
+// Delete elimina la key; retorna ErrCacheMiss si no existía
+func (c *redisCache) Delete(key string) error {
+	deleted, err := c.client.Del(context.Background(), key).Result()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrCacheMiss
+	}
+	return nil
+}
+
+// DeletePattern recorre las keys con SCAN (no bloquea Redis como KEYS) y las elimina por lotes
+func (c *redisCache) DeletePattern(pattern string) (int, error) {
+	ctx := context.Background()
+	deleted := 0
+	var cursor uint64
+	for {
+		keys, next, err := c.client.Scan(ctx, cursor, pattern, redisScanCount).Result()
+		if err != nil {
+			return deleted, err
+		}
+
+		if len(keys) > 0 {
+			count, err := c.client.Del(ctx, keys...).Result()
+			if err != nil {
+				return deleted, err
+			}
+			deleted += int(count)
+		}
+
+		cursor = next
+		if cursor == 0 {
+			return deleted, nil
+		}
+	}
+}
+
+// TTL retorna el tiempo de vida restante de la key (0 si no expira) o ErrCacheMiss
+func (c *redisCache) TTL(key string) (time.Duration, error) {
+	ttl, err := c.client.PTTL(context.Background(), key).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	// PTTL retorna -2 si la key no existe y -1 si no tiene expiración
+	switch {
+	case ttl == -2:
+		return 0, ErrCacheMiss
+	case ttl < 0:
+		return 0, nil
+	default:
+		return ttl, nil
+	}
+}
+
+// Flush elimina todas las keys de la base configurada
+func (c *redisCache) Flush() error {
+	return c.client.FlushDB(context.Background()).Err()
+}
+
+// Ping verifica que Redis responda
+func (c *redisCache) Ping() error {
+	return c.client.Ping(context.Background()).Err()
+}
+
+// Stats retorna la cantidad de keys (DBSIZE), los descartes y la memoria usada (INFO)
+func (c *redisCache) Stats() (*dto.RemoteCacheStats, error) {
+	ctx := context.Background()
+	items, err := c.client.DBSize(ctx).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := c.client.Info(ctx).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	// INFO retorna líneas "campo:valor" agrupadas en secciones "# Nombre"
+	values := make(map[string]uint64)
+	for _, line := range strings.Split(info, "\n") {
+		name, value, found := strings.Cut(strings.TrimSpace(line), ":")
+		if !found {
+			continue
+		}
+		if parsed, err := strconv.ParseUint(value, 10, 64); err == nil {
+			values[name] = parsed
+		}
+	}
+
+	return &dto.RemoteCacheStats{
+		Items:     uint64(items),
+		Evictions: values["evicted_keys"],
+		BytesUsed: values["used_memory"],
+	}, nil
+}
+
+// positiveTTL convierte un TTL negativo en 0 (sin expiración): para go-redis un TTL negativo es KEEPTTL
+func positiveTTL(ttl time.Duration) time.Duration {
+	if ttl < 0 {
+		return 0
+	}
+	return ttl
+}
@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestRedisCache_ImplementsRemoteCacheSemantics(t *testing.T) {
+	server := miniredis.RunT(t)
+	cache := NewRedisCache(RedisOptions{Addr: server.Addr(), Timeout: time.Second})
+
+	if _, err := cache.Get("missing"); !errors.Is(err, ErrCacheMiss) {
+		t.Fatalf("expected ErrCacheMiss for a missing key, got %v", err)
+	}
+	if err := cache.Set("search:a", []byte("uno"), time.Minute); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value, err := cache.Get("search:a"); err != nil || string(value) != "uno" {
+		t.Fatalf("expected the stored value, got %q (%v)", value, err)
+	}
+	if ttl, err := cache.TTL("search:a"); err != nil || ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("expected a TTL of up to a minute, got %v (%v)", ttl, err)
+	}
+
+	// Add no pisa una key existente
+	if err := cache.Add("search:a", []byte("dos"), time.Minute); !errors.Is(err, ErrCacheNotStored) {
+		t.Fatalf("expected ErrCacheNotStored, got %v", err)
+	}
+	if err := cache.Add("search:b", []byte("dos"), 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ttl, err := cache.TTL("search:b"); err != nil || ttl != 0 {
+		t.Fatalf("expected a key without expiration, got %v (%v)", ttl, err)
+	}
+
+	if err := cache.Delete("search:b"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := cache.Delete("search:b"); !errors.Is(err, ErrCacheMiss) {
+		t.Fatalf("expected ErrCacheMiss deleting twice, got %v", err)
+	}
+	if _, err := cache.TTL("search:b"); !errors.Is(err, ErrCacheMiss) {
+		t.Fatalf("expected ErrCacheMiss for the TTL of a missing key, got %v", err)
+	}
+}
+
+func TestRedisCache_DeletePatternOnlyDeletesMatchingKeys(t *testing.T) {
+	server := miniredis.RunT(t)
+	cache := NewRedisCache(RedisOptions{Addr: server.Addr(), Timeout: time.Second})
+
+	for i := 0; i < 20; i++ {
+		if err := cache.Set(fmt.Sprintf("search:%d", i), []byte("x"), 0); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := cache.Set("similar:1", []byte("x"), 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	deleted, err := cache.DeletePattern("search:*")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deleted != 20 {
+		t.Fatalf("expected 20 deleted keys, got %d", deleted)
+	}
+	if !server.Exists("similar:1") {
+		t.Fatal("expected keys outside the pattern to be kept")
+	}
+
+	stats, err := cache.Stats()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Items != 1 {
+		t.Fatalf("expected 1 key left, got %d", stats.Items)
+	}
+}
+
+func TestRedisCache_ReconnectsAfterRedisRestarts(t *testing.T) {
+	server := miniredis.RunT(t)
+	cache := NewRedisCache(RedisOptions{Addr: server.Addr(), Timeout: time.Second})
+	if err := cache.Set("search:a", []byte("uno"), 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// El reinicio cierra las conexiones ociosas del pool
+	server.Close()
+	if err := server.Restart(); err != nil {
+		t.Fatalf("failed to restart redis: %v", err)
+	}
+
+	// El primer comando después del reinicio no puede fallar por una conexión vieja
+	if err := cache.Set("search:b", []byte("dos"), 0); err != nil {
+		t.Fatalf("expected the first command after the restart to succeed, got %v", err)
+	}
+	if value, err := cache.Get("search:b"); err != nil || string(value) != "dos" {
+		t.Fatalf("expected the stored value, got %q (%v)", value, err)
+	}
+}
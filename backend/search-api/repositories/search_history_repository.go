@@ -0,0 +1,167 @@
+package repositories
+
+import (
+	"container/list"
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"search-api/domain"
+)
+
+// SearchHistoryRepository define la interfaz para guardar las búsquedas recientes de cada usuario
+// Implementaciones: NewMongoSearchHistoryRepository (compartida por las réplicas) y NewSearchHistoryRepository
+type SearchHistoryRepository interface {
+	// Add agrega una búsqueda al historial del usuario
+	// Si es igual a una búsqueda anterior (mismo término y filtros) la reemplaza en lugar de duplicarla
+	Add(ctx context.Context, userID uint, event domain.SearchEvent) error
+
+	// List retorna el historial del usuario, de la búsqueda más reciente a la más antigua
+	List(ctx context.Context, userID uint) ([]domain.SearchEvent, error)
+
+	// Remove quita del historial la búsqueda con el mismo término y filtros; retorna false si no estaba
+	Remove(ctx context.Context, userID uint, event domain.SearchEvent) (bool, error)
+
+	// Clear borra el historial del usuario
+	Clear(ctx context.Context, userID uint) error
+}
+
+// userHistory es el historial de un usuario, de la búsqueda más antigua a la más reciente
+type userHistory struct {
+	userID uint
+	events []domain.SearchEvent
+}
+
+// searchHistoryRepository guarda el historial en memoria, acotado por usuario y en cantidad de usuarios
+// Cuando se supera maxUsers se descarta el historial del usuario que hace más tiempo no busca (LRU)
+// Cada réplica tiene el suyo: solo sirve con una réplica (DATA_STORE=memory)
+type searchHistoryRepository struct {
+	mu       sync.Mutex
+	size     int
+	maxUsers int
+	users    map[uint]*list.Element
+	lru      *list.List // frente = usuario que buscó más recientemente
+}
+
+// NewSearchHistoryRepository crea un repositorio en memoria que guarda size búsquedas de hasta maxUsers usuarios
+func NewSearchHistoryRepository(size, maxUsers int) SearchHistoryRepository {
+	if size <= 0 {
+		size = 1
+	}
+	if maxUsers <= 0 {
+		maxUsers = 1
+	}
+
+	return &searchHistoryRepository{
+		size:     size,
+		maxUsers: maxUsers,
+		users:    make(map[uint]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// Add agrega la búsqueda y marca al usuario como el más reciente
+func (r *searchHistoryRepository) Add(ctx context.Context, userID uint, event domain.SearchEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	element, exists := r.users[userID]
+	if !exists {
+		element = r.lru.PushFront(&userHistory{userID: userID})
+		r.users[userID] = element
+		if r.lru.Len() > r.maxUsers {
+			oldest := r.lru.Back()
+			r.lru.Remove(oldest)
+			delete(r.users, oldest.Value.(*userHistory).userID)
+		}
+	} else {
+		r.lru.MoveToFront(element)
+	}
+
+	history := element.Value.(*userHistory)
+	events := history.events[:0]
+	for _, previous := range history.events {
+		if !sameSearch(previous, event) {
+			events = append(events, previous)
+		}
+	}
+	events = append(events, event)
+	if len(events) > r.size {
+		events = events[len(events)-r.size:]
+	}
+	history.events = events
+	return nil
+}
+
+// List retorna una copia del historial, de la más reciente a la más antigua
+func (r *searchHistoryRepository) List(ctx context.Context, userID uint) ([]domain.SearchEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	element, exists := r.users[userID]
+	if !exists {
+		return nil, nil
+	}
+
+	events := element.Value.(*userHistory).events
+	ordered := make([]domain.SearchEvent, len(events))
+	for i, event := range events {
+		ordered[len(events)-1-i] = event
+	}
+	return ordered, nil
+}
+
+// Remove quita una búsqueda del historial del usuario
+func (r *searchHistoryRepository) Remove(ctx context.Context, userID uint, event domain.SearchEvent) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	element, exists := r.users[userID]
+	if !exists {
+		return false, nil
+	}
+
+	history := element.Value.(*userHistory)
+	for i, previous := range history.events {
+		if sameSearch(previous, event) {
+			history.events = append(history.events[:i], history.events[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Clear borra el historial del usuario
+func (r *searchHistoryRepository) Clear(ctx context.Context, userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if element, exists := r.users[userID]; exists {
+		r.lru.Remove(element)
+		delete(r.users, userID)
+	}
+	return nil
+}
+
+// sameSearch indica si dos búsquedas tienen el mismo término y los mismos filtros
+func sameSearch(a, b domain.SearchEvent) bool {
+	return searchKey(a) == searchKey(b)
+}
+
+// searchKey identifica una búsqueda por su término y sus filtros ordenados por nombre
+// Es la key con la que MongoDB deduplica el historial (un map no tiene orden fijo al guardarse)
+func searchKey(event domain.SearchEvent) string {
+	keys := make([]string, 0, len(event.Filters))
+	for key := range event.Filters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var builder strings.Builder
+	builder.WriteString(event.Query)
+	for _, key := range keys {
+		builder.WriteString("|" + key + "=" + event.Filters[key])
+	}
+	return builder.String()
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"search-api/domain"
+	"search-api/dto"
+)
+
+// ErrPropertyNotIndexed indica que la propiedad no está en el índice de búsqueda
+var ErrPropertyNotIndexed = errors.New("propiedad no encontrada en el índice")
+
+// ErrShadowUnsupported indica que el backend no soporta reindexar en un índice sombra
+var ErrShadowUnsupported = errors.New("el backend de búsqueda no soporta reindexar en un índice sombra")
+
+// SearchIndex define la interfaz del motor de búsqueda donde se indexan las propiedades
+// Hay una implementación sobre Solr y otra sobre OpenSearch (se elige con SEARCH_BACKEND)
+// Los nombres de campos de SortField y FieldSelection son los del esquema de Solr;
+// cada implementación los traduce a sus propios campos
+type SearchIndex interface {
+	// Name es el nombre del backend (ej: "solr"), usado en logs y health checks
+	Name() string
+
+	// Search realiza una búsqueda de propiedades con filtros, paginación y facets
+	// Si el context trae un SearchTrace registra el tiempo de la consulta y de la conversión de los documentos
+	Search(ctx context.Context, request dto.SearchRequest) (dto.SearchResult, error)
+
+	// DescribeSearch retorna la consulta que Search le enviaría al motor, sin ejecutarla (para diagnóstico)
+	DescribeSearch(request dto.SearchRequest) string
+
+	// IndexProperty indexa una nueva propiedad
+	IndexProperty(ctx context.Context, property domain.Property) error
+
+	// UpdateProperty actualiza una propiedad existente
+	UpdateProperty(ctx context.Context, property domain.Property) error
+
+	// DeleteProperty elimina una propiedad por su ID
+	DeleteProperty(ctx context.Context, propertyID string) error
+
+	// DeletePropertiesByOwner elimina todas las propiedades de un usuario
+	DeletePropertiesByOwner(ctx context.Context, ownerID uint) error
+
+	// GetProperty obtiene una propiedad indexada por su ID; retorna ErrPropertyNotIndexed si no está
+	GetProperty(ctx context.Context, propertyID string) (domain.Property, error)
+
+	// MoreLikeThis retorna hasta limit propiedades parecidas a la indicada por su texto
+	// (título, descripción, ciudad y país), de la más a la menos parecida y sin incluirla
+	MoreLikeThis(ctx context.Context, property domain.Property, limit int) ([]domain.Property, error)
+
+	// ListPropertyVersions retorna el ID de cada propiedad indexada con su fecha de actualización
+	// (valor cero si el documento se indexó antes de guardarla); la usa la reconciliación con properties-api
+	ListPropertyVersions(ctx context.Context) (map[string]time.Time, error)
+
+	// UpdatePopularity actualiza el campo popularity de las propiedades indicadas
+	UpdatePopularity(ctx context.Context, views map[string]int64) error
+
+	// UpdateAvailability reemplaza los días ocupados y el primer día libre de una propiedad
+	UpdateAvailability(ctx context.Context, propertyID string, booked []domain.DateRange, nextAvailable string) error
+
+	// Ping verifica que el índice responda (usado por el health check)
+	Ping(ctx context.Context) error
+
+	// NewShadow crea un índice sombra vacío, con el mismo esquema, para una reindexación completa
+	// Retorna ErrShadowUnsupported si el backend no lo soporta
+	NewShadow(ctx context.Context) (ShadowIndex, error)
+}
+
+// ShadowIndex es un índice paralelo al que se sirve: se llena sin que las búsquedas lo vean
+// y al terminar Swap lo pone en lugar del actual de una vez, así una reindexación completa
+// nunca responde resultados parciales. Si la reindexación falla se descarta con Discard
+type ShadowIndex interface {
+	// Name es el nombre del índice sombra (ej: la colección de Solr), para logs y reportes
+	Name() string
+
+	// IndexProperties indexa un lote de propiedades en el índice sombra
+	IndexProperties(ctx context.Context, properties []domain.Property) error
+
+	// DeleteProperties elimina propiedades del índice sombra por su ID
+	DeleteProperties(ctx context.Context, propertyIDs []string) error
+
+	// ListPropertyVersions retorna el ID y la fecha de actualización de cada propiedad del índice sombra
+	ListPropertyVersions(ctx context.Context) (map[string]time.Time, error)
+
+	// Swap reemplaza el índice que se sirve por el índice sombra y elimina el anterior
+	Swap(ctx context.Context) error
+
+	// Discard elimina el índice sombra (lo que no se llegó a reemplazar)
+	Discard(ctx context.Context) error
+}
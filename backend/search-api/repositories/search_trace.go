@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Etapas de una búsqueda medidas en SearchTrace
+const (
+	// TraceCacheLookup es la lectura del caché de dos niveles
+	TraceCacheLookup = "cacheLookup"
+
+	// TraceIndex es la consulta al índice: red, tiempo del motor y lectura de la respuesta
+	TraceIndex = "index"
+
+	// TraceIndexEngine es el tiempo que reporta el propio motor (QTime de Solr, took de OpenSearch)
+	TraceIndexEngine = "indexEngine"
+
+	// TraceMapping es la conversión de los documentos a propiedades y el armado de la respuesta
+	TraceMapping = "mapping"
+
+	// TraceHydration es la obtención de los documentos desde properties-api (hydrate=true)
+	TraceHydration = "hydration"
+)
+
+// SearchTrace acumula cuánto tardó cada etapa de una búsqueda
+// Viaja en el context para que el índice mida sus etapas sin cambiar la firma de SearchIndex
+// Los métodos aceptan un trace nil, así el código que mide no necesita chequear si hay uno
+type SearchTrace struct {
+	mu      sync.Mutex
+	timings map[string]time.Duration
+}
+
+// searchTraceKey es la key del SearchTrace en el context
+type searchTraceKey struct{}
+
+// WithSearchTrace retorna un context con un SearchTrace nuevo
+func WithSearchTrace(ctx context.Context) (context.Context, *SearchTrace) {
+	trace := &SearchTrace{timings: make(map[string]time.Duration)}
+	return context.WithValue(ctx, searchTraceKey{}, trace), trace
+}
+
+// SearchTraceFrom retorna el SearchTrace del context (nil si no hay)
+func SearchTraceFrom(ctx context.Context) *SearchTrace {
+	trace, _ := ctx.Value(searchTraceKey{}).(*SearchTrace)
+	return trace
+}
+
+// Add suma duration a la etapa
+func (t *SearchTrace) Add(stage string, duration time.Duration) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.timings[stage] += duration
+}
+
+// Since suma a la etapa el tiempo transcurrido desde start
+func (t *SearchTrace) Since(stage string, start time.Time) {
+	t.Add(stage, time.Since(start))
+}
+
+// Get retorna cuánto tardó la etapa (0 si no se midió)
+func (t *SearchTrace) Get(stage string) time.Duration {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.timings[stage]
+}
@@ -0,0 +1,259 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"search-api/dto"
+)
+
+// SolrOptions contiene la conexión a Solr y el ruteo de propiedades a colecciones
+type SolrOptions struct {
+	// URL es la URL de la colección por defecto (ej: http://localhost:8983/solr/properties)
+	URL string
+
+	// Routes mapea el país de la propiedad en minúsculas a su colección (vacío = una sola colección)
+	Routes map[string]string
+
+	// ConfigSet, NumShards y ReplicationFactor se usan al crear las colecciones que falten
+	ConfigSet         string
+	NumShards         int
+	ReplicationFactor int
+
+	// AliasMigration permite que la primera reindexación en SolrCloud elimine la colección configurada para
+	// crear un alias con su nombre (las búsquedas fallan mientras tanto); sin esto la reindexación se rechaza
+	AliasMigration bool
+}
+
+// solrCollectionRouter elige la colección de cada propiedad y las colecciones de cada consulta
+// Las consultas sobre varias colecciones usan el parámetro collection de SolrCloud
+type solrCollectionRouter struct {
+	baseURL           string
+	defaultCollection string
+	byCountry         map[string]string
+	// collections son todas las colecciones sin repetir, la de por defecto primero
+	collections []string
+}
+
+// newSolrCollectionRouter separa la URL base de Solr de la colección por defecto y arma las rutas
+func newSolrCollectionRouter(options SolrOptions) solrCollectionRouter {
+	solrURL := strings.TrimSuffix(options.URL, "/")
+	baseURL, defaultCollection := solrURL, ""
+	if i := strings.LastIndex(solrURL, "/"); i >= 0 {
+		baseURL, defaultCollection = solrURL[:i], solrURL[i+1:]
+	}
+
+	router := solrCollectionRouter{
+		baseURL:           baseURL,
+		defaultCollection: defaultCollection,
+		byCountry:         make(map[string]string, len(options.Routes)),
+		collections:       []string{defaultCollection},
+	}
+
+	countries := make([]string, 0, len(options.Routes))
+	for country := range options.Routes {
+		countries = append(countries, country)
+	}
+	sort.Strings(countries)
+	seen := map[string]bool{defaultCollection: true}
+	for _, country := range countries {
+		collection := options.Routes[country]
+		router.byCountry[strings.ToLower(strings.TrimSpace(country))] = collection
+		if !seen[collection] {
+			seen[collection] = true
+			router.collections = append(router.collections, collection)
+		}
+	}
+	return router
+}
+
+// collectionURL retorna la URL de una colección
+func (r solrCollectionRouter) collectionURL(collection string) string {
+	return r.baseURL + "/" + collection
+}
+
+// forCountry retorna la colección de las propiedades de un país (la de por defecto si no tiene ruta)
+func (r solrCollectionRouter) forCountry(country string) string {
+	if collection, ok := r.byCountry[strings.ToLower(strings.TrimSpace(country))]; ok {
+		return collection
+	}
+	return r.defaultCollection
+}
+
+// forSearch retorna las colecciones a consultar: con un país ruteado solo la suya, si no todas
+func (r solrCollectionRouter) forSearch(request dto.SearchRequest) []string {
+	if request.Country != "" {
+		if collection, ok := r.byCountry[strings.ToLower(strings.TrimSpace(request.Country))]; ok {
+			return []string{collection}
+		}
+	}
+	return r.collections
+}
+
+// all retorna todas las colecciones
+func (r solrCollectionRouter) all() []string {
+	return r.collections
+}
+
+// selectURL retorna la URL de /select para consultar las colecciones
+// Con varias se consulta la primera y el resto va en el parámetro collection
+func (r solrCollectionRouter) selectURL(collections []string, params url.Values) string {
+	if len(collections) > 1 {
+		params.Set("collection", strings.Join(collections, ","))
+	}
+	return r.collectionURL(collections[0]) + "/select?" + params.Encode()
+}
+
+// solrCollectionsListResponse es la respuesta de LIST de la Collections API
+type solrCollectionsListResponse struct {
+	Collections []string `json:"collections"`
+}
+
+// solrCoresStatusResponse es la respuesta de STATUS de la Core Admin API (Solr standalone)
+type solrCoresStatusResponse struct {
+	Status map[string]json.RawMessage `json:"status"`
+}
+
+// EnsureSolrCollections crea las colecciones que falten con el configset configurado
+// En SolrCloud usa la Collections API; en Solr standalone (sin Collections API) crea cores
+func EnsureSolrCollections(ctx context.Context, options SolrOptions, httpClient *http.Client) error {
+	router := newSolrCollectionRouter(options)
+
+	existing, cloud, err := listSolrCollections(ctx, router.baseURL, httpClient)
+	if err != nil {
+		return err
+	}
+
+	for _, collection := range router.all() {
+		if existing[collection] {
+			continue
+		}
+
+		if err := createSolrCollection(ctx, httpClient, router.baseURL, collection, options, cloud); err != nil {
+			// Otra instancia puede haberla creado al mismo tiempo
+			if strings.Contains(err.Error(), "already exists") {
+				continue
+			}
+			return err
+		}
+		log.Printf("✅ Colección '%s' de Solr creada (configset '%s')", collection, options.ConfigSet)
+	}
+	return nil
+}
+
+// createSolrCollection crea una colección con el configset de options
+// En SolrCloud con la Collections API; en Solr standalone crea un core
+func createSolrCollection(ctx context.Context, httpClient *http.Client, baseURL, collection string, options SolrOptions, cloud bool) error {
+	params := url.Values{}
+	params.Set("action", "CREATE")
+	params.Set("name", collection)
+	params.Set("wt", "json")
+	adminURL := baseURL + "/admin/cores?"
+	if cloud {
+		params.Set("collection.configName", options.ConfigSet)
+		params.Set("numShards", strconv.Itoa(options.NumShards))
+		params.Set("replicationFactor", strconv.Itoa(options.ReplicationFactor))
+		adminURL = baseURL + "/admin/collections?"
+	} else {
+		params.Set("configSet", options.ConfigSet)
+	}
+
+	if _, err := solrAdminRequest(ctx, httpClient, adminURL+params.Encode()); err != nil {
+		return fmt.Errorf("error creando la colección '%s' de Solr: %w", collection, err)
+	}
+	return nil
+}
+
+// listSolrCollections retorna las colecciones existentes e indica si Solr corre en modo SolrCloud
+// En SolrCloud incluye los alias: después de una reindexación la colección configurada es un alias
+func listSolrCollections(ctx context.Context, baseURL string, httpClient *http.Client) (map[string]bool, bool, error) {
+	existing := make(map[string]bool)
+
+	body, err := solrAdminRequest(ctx, httpClient, baseURL+"/admin/collections?action=LIST&wt=json")
+	if err == nil {
+		var list solrCollectionsListResponse
+		if err := json.Unmarshal(body, &list); err != nil {
+			return nil, false, fmt.Errorf("error parseando las colecciones de Solr: %w", err)
+		}
+		for _, collection := range list.Collections {
+			existing[collection] = true
+		}
+		aliases, err := listSolrAliases(ctx, baseURL, httpClient)
+		if err != nil {
+			return nil, false, err
+		}
+		for alias := range aliases {
+			existing[alias] = true
+		}
+		return existing, true, nil
+	}
+	if !strings.Contains(err.Error(), "SolrCloud") {
+		return nil, false, fmt.Errorf("error listando las colecciones de Solr: %w", err)
+	}
+
+	// Solr standalone: cada colección es un core
+	body, err = solrAdminRequest(ctx, httpClient, baseURL+"/admin/cores?action=STATUS&wt=json")
+	if err != nil {
+		return nil, false, fmt.Errorf("error listando los cores de Solr: %w", err)
+	}
+	var status solrCoresStatusResponse
+	if err := json.Unmarshal(body, &status); err != nil {
+		return nil, false, fmt.Errorf("error parseando los cores de Solr: %w", err)
+	}
+	for core := range status.Status {
+		existing[core] = true
+	}
+	return existing, false, nil
+}
+
+// solrAliasesResponse es la respuesta de LISTALIASES de la Collections API
+type solrAliasesResponse struct {
+	Aliases map[string]string `json:"aliases"`
+}
+
+// listSolrAliases retorna los alias de SolrCloud con la colección a la que apunta cada uno
+func listSolrAliases(ctx context.Context, baseURL string, httpClient *http.Client) (map[string]string, error) {
+	body, err := solrAdminRequest(ctx, httpClient, baseURL+"/admin/collections?action=LISTALIASES&wt=json")
+	if err != nil {
+		return nil, fmt.Errorf("error listando los alias de Solr: %w", err)
+	}
+	var aliases solrAliasesResponse
+	if err := json.Unmarshal(body, &aliases); err != nil {
+		return nil, fmt.Errorf("error parseando los alias de Solr: %w", err)
+	}
+	if aliases.Aliases == nil {
+		aliases.Aliases = make(map[string]string)
+	}
+	return aliases.Aliases, nil
+}
+
+// solrAdminRequest hace un GET a la API de administración de Solr y retorna el cuerpo de la respuesta
+func solrAdminRequest(ctx context.Context, httpClient *http.Client, adminURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, adminURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creando request HTTP: %w", err)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error realizando petición a Solr: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo respuesta de Solr: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, string(body))
+	}
+	return body, nil
+}
@@ -0,0 +1,142 @@
+package repositories
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"search-api/dto"
+)
+
+// countryRoutedOptions rutea Argentina a su colección y Brasil y Uruguay a una compartida
+func countryRoutedOptions(solrURL string) SolrOptions {
+	return SolrOptions{
+		URL:               solrURL + "/solr/properties",
+		Routes:            map[string]string{"AR": "properties_ar", "br": "properties_south", "uy": "properties_south"},
+		ConfigSet:         "spotly",
+		NumShards:         2,
+		ReplicationFactor: 1,
+	}
+}
+
+func TestSolrCollectionRouter(t *testing.T) {
+	router := newSolrCollectionRouter(countryRoutedOptions("http://solr:8983"))
+
+	if want := []string{"properties", "properties_ar", "properties_south"}; !reflect.DeepEqual(router.all(), want) {
+		t.Fatalf("expected %v (default first, no repeats), got %v", want, router.all())
+	}
+
+	countries := map[string]string{"ar": "properties_ar", " AR ": "properties_ar", "UY": "properties_south", "cl": "properties", "": "properties"}
+	for country, want := range countries {
+		if got := router.forCountry(country); got != want {
+			t.Fatalf("expected %q for country %q, got %q", want, country, got)
+		}
+	}
+
+	// Con un país ruteado se consulta solo su colección; sin país o con uno sin ruta, todas
+	if got := router.forSearch(dto.SearchRequest{Country: "Ar"}); !reflect.DeepEqual(got, []string{"properties_ar"}) {
+		t.Fatalf("expected only properties_ar, got %v", got)
+	}
+	if got := router.forSearch(dto.SearchRequest{Country: "cl"}); len(got) != 3 {
+		t.Fatalf("expected every collection for an unrouted country, got %v", got)
+	}
+}
+
+func TestSolrCollectionRouter_SelectURL(t *testing.T) {
+	router := newSolrCollectionRouter(countryRoutedOptions("http://solr:8983"))
+
+	single := router.selectURL([]string{"properties_ar"}, url.Values{"q": {"*:*"}})
+	if single != "http://solr:8983/solr/properties_ar/select?q=%2A%3A%2A" {
+		t.Fatalf("unexpected single collection URL %q", single)
+	}
+
+	multi, err := url.Parse(router.selectURL(router.all(), url.Values{"q": {"*:*"}}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if multi.Path != "/solr/properties/select" || multi.Query().Get("collection") != "properties,properties_ar,properties_south" {
+		t.Fatalf("expected the first collection with the rest in collection, got %s", multi)
+	}
+}
+
+// fakeSolrAdmin responde la Collections API (cloud) o la Core Admin API (standalone) y registra los CREATE
+type fakeSolrAdmin struct {
+	cloud    bool
+	existing string // respuesta de LIST (cloud) o de STATUS (standalone)
+	aliases  string
+
+	mu      sync.Mutex
+	created []string
+}
+
+func (f *fakeSolrAdmin) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	isCollectionsAPI := strings.HasSuffix(r.URL.Path, "/admin/collections")
+	if isCollectionsAPI && !f.cloud {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":{"msg":"Solr instance is not running in SolrCloud mode."}}`))
+		return
+	}
+
+	switch query.Get("action") {
+	case "LIST", "STATUS":
+		w.Write([]byte(f.existing))
+	case "LISTALIASES":
+		w.Write([]byte(f.aliases))
+	case "CREATE":
+		configSet := query.Get("configSet")
+		if isCollectionsAPI {
+			configSet = query.Get("collection.configName") + " shards=" + query.Get("numShards")
+		}
+		f.mu.Lock()
+		f.created = append(f.created, query.Get("name")+" "+configSet)
+		f.mu.Unlock()
+		w.Write([]byte(`{"responseHeader":{"status":0}}`))
+	default:
+		http.Error(w, "unexpected action", http.StatusBadRequest)
+	}
+}
+
+func TestEnsureSolrCollections(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	tests := []struct {
+		name string
+		solr *fakeSolrAdmin
+		want []string
+	}{
+		{
+			// properties es un alias después de una reindexación: no se vuelve a crear
+			name: "solrcloud",
+			solr: &fakeSolrAdmin{cloud: true, existing: `{"collections":["properties_reindex_1","properties_ar"]}`, aliases: `{"aliases":{"properties":"properties_reindex_1"}}`},
+			want: []string{"properties_south spotly shards=2"},
+		},
+		{
+			name: "standalone",
+			solr: &fakeSolrAdmin{existing: `{"status":{"properties":{}}}`},
+			want: []string{"properties_ar spotly", "properties_south spotly"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.solr)
+			defer server.Close()
+
+			if err := EnsureSolrCollections(context.Background(), countryRoutedOptions(server.URL), server.Client()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tt.solr.created, tt.want) {
+				t.Fatalf("expected %v created, got %v", tt.want, tt.solr.created)
+			}
+		})
+	}
+}
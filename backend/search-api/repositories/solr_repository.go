@@ -11,86 +11,262 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"search-api/domain"
 	"search-api/dto"
 )
 
-// SolrRepository define la interfaz para las operaciones de repositorio de Solr
-type SolrRepository interface {
-	// Search realiza una búsqueda de propiedades con filtros y paginación
-	Search(ctx context.Context, request dto.SearchRequest) ([]domain.Property, int, error)
-
-	// IndexProperty indexa una nueva propiedad en Solr
-	IndexProperty(ctx context.Context, property domain.Property) error
-
-	// UpdateProperty actualiza una propiedad existente en Solr
-	UpdateProperty(ctx context.Context, property domain.Property) error
-
-	// DeleteProperty elimina una propiedad de Solr por su ID
-	DeleteProperty(ctx context.Context, propertyID string) error
-}
-
-// solrRepository es la implementación concreta de SolrRepository
+// solrRepository es la implementación de SearchIndex sobre Solr
 type solrRepository struct {
-	solrURL    string
+	router     solrCollectionRouter
 	httpClient *http.Client
+
+	// options se usan para crear las colecciones de las reindexaciones (configset, shards, réplicas)
+	options SolrOptions
+
+	// schemaReady indica las colecciones en las que ya se verificó (o creó) el tipo de texto en español y sus campos
+	schemaMu    sync.Mutex
+	schemaReady map[string]bool
 }
 
 // NewSolrRepository crea una nueva instancia del repositorio de Solr
-func NewSolrRepository(solrURL string) SolrRepository {
+// httpClient es el cliente HTTP compartido (pool de conexiones y timeouts configurados)
+// Cada propiedad se indexa en la colección de su país (options.Routes) o en la de options.URL
+// Los campos de búsqueda en español se agregan al esquema de cada colección la primera vez que se usa
+func NewSolrRepository(options SolrOptions, httpClient *http.Client) SearchIndex {
 	return &solrRepository{
-		solrURL: solrURL,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		router:      newSolrCollectionRouter(options),
+		httpClient:  httpClient,
+		options:     options,
+		schemaReady: make(map[string]bool),
 	}
 }
 
+// Name retorna el nombre del backend
+func (r *solrRepository) Name() string {
+	return "solr"
+}
+
+// propertyTypeField es el campo string de Solr con el tipo de propiedad (dynamic field *_s, apto para facets)
+const propertyTypeField = "property_type_s"
+
+// coverImageField es el campo string de Solr con la URL de la foto de portada (dynamic field *_s)
+const coverImageField = "cover_image_s"
+
+// coverThumbnailField y coverThumbnailWebPField son las miniaturas de la portada (dynamic fields *_s)
+const (
+	coverThumbnailField     = "cover_thumbnail_s"
+	coverThumbnailWebPField = "cover_thumbnail_webp_s"
+)
+
+// childFriendlyField e infantFriendlyField indican si la propiedad es apta para niños y para bebés (dynamic fields *_b)
+const (
+	childFriendlyField  = "child_friendly_b"
+	infantFriendlyField = "infant_friendly_b"
+)
+
+// petsAllowedField, smokingAllowedField y eventsAllowedField son las reglas de la casa filtrables (dynamic fields *_b)
+const (
+	petsAllowedField    = "pets_allowed_b"
+	smokingAllowedField = "smoking_allowed_b"
+	eventsAllowedField  = "events_allowed_b"
+)
+
+// instantBookField indica si la propiedad se reserva sin aprobación del anfitrión y cancellationPolicyField
+// es su política de cancelación (dynamic fields *_b y *_s)
+const (
+	instantBookField        = "instant_book_b"
+	cancellationPolicyField = "cancellation_policy_s"
+)
+
+// bookedRangesField son los días ocupados de la propiedad (tipo DateRangeField, lo crea ensureSchema)
+// y nextAvailableField el primer día libre (dynamic field *_dt)
+const (
+	bookedRangesField  = "booked_ranges"
+	nextAvailableField = "next_available_date_dt"
+)
+
+// languageField es el idioma del título y la descripción (los textos por idioma van en solrLanguageField)
+const languageField = "language_s"
+
+// solrUpdatedAtField es el campo de fecha con la última modificación de la propiedad en properties-api
+const solrUpdatedAtField = "updated_at_dt"
+
+// facetFields mapea el nombre del facet en la respuesta al campo de Solr
+var facetFields = map[string]string{
+	"type": propertyTypeField,
+}
+
 // SolrResponse representa la estructura de respuesta de Solr
 type SolrResponse struct {
+	// ResponseHeader trae el tiempo que Solr tardó en resolver la consulta (QTime, en milisegundos)
+	ResponseHeader struct {
+		QTime int `json:"QTime"`
+	} `json:"responseHeader"`
+
 	Response struct {
 		NumFound int                      `json:"numFound"`
 		Start    int                      `json:"start"`
 		Docs     []map[string]interface{} `json:"docs"`
 	} `json:"response"`
+
+	// FacetCounts trae los facets como listas planas [valor, cantidad, valor, cantidad, ...]
+	FacetCounts struct {
+		FacetFields map[string][]interface{} `json:"facet_fields"`
+	} `json:"facet_counts"`
+
+	// NextCursorMark es el cursor de la página siguiente (solo en consultas con cursorMark)
+	NextCursorMark string `json:"nextCursorMark"`
 }
 
 // SolrProperty representa una propiedad en formato Solr
 type SolrProperty struct {
-	ID            string    `json:"id"`
-	Title         string    `json:"title"`
-	Description   string    `json:"description"`
-	City          string    `json:"city"`
-	Country       string    `json:"country"`
-	PricePerNight float64   `json:"price"`
-	Bedrooms      int       `json:"bedrooms"`
-	Bathrooms     int       `json:"bathrooms"`
-	MaxGuests     int       `json:"max_guests"`
-	Images        []string  `json:"images"`
-	OwnerID       uint      `json:"owner_id"`
-	Available     bool      `json:"available"`
-	CreatedAt     time.Time `json:"created_at"`
-}
-
-// Search realiza una búsqueda de propiedades con filtros y paginación
-func (r *solrRepository) Search(ctx context.Context, request dto.SearchRequest) ([]domain.Property, int, error) {
-	// Construir la URL base de búsqueda
-	baseURL := strings.TrimSuffix(r.solrURL, "/") + "/select"
-
-	// Construir parámetros de la query
+	ID                 string    `json:"id"`
+	Title              string    `json:"title"`
+	Description        string    `json:"description"`
+	City               string    `json:"city"`
+	Country            string    `json:"country"`
+	PropertyType       string    `json:"property_type_s,omitempty"`
+	GeoLocation        string    `json:"geo_p,omitempty"`
+	PricePerNight      float64   `json:"price"`
+	Bedrooms           int       `json:"bedrooms"`
+	Bathrooms          int       `json:"bathrooms"`
+	MaxGuests          int       `json:"max_guests"`
+	Images             []string  `json:"images"`
+	CoverImage         string    `json:"cover_image_s,omitempty"`
+	CoverThumbnail     string    `json:"cover_thumbnail_s,omitempty"`
+	CoverThumbnailWebP string    `json:"cover_thumbnail_webp_s,omitempty"`
+	OwnerID            uint      `json:"owner_id"`
+	Available          bool      `json:"available"`
+	ChildFriendly      bool      `json:"child_friendly_b"`
+	InfantFriendly     bool      `json:"infant_friendly_b"`
+	PetsAllowed        bool      `json:"pets_allowed_b"`
+	SmokingAllowed     bool      `json:"smoking_allowed_b"`
+	EventsAllowed      bool      `json:"events_allowed_b"`
+	InstantBook        bool      `json:"instant_book_b"`
+	CancellationPolicy string    `json:"cancellation_policy_s,omitempty"`
+	Popularity         int64     `json:"popularity"`
+	BookedRanges       []string  `json:"booked_ranges,omitempty"`
+	NextAvailableDate  string    `json:"next_available_date_dt,omitempty"`
+	Language           string    `json:"language_s,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at_dt"`
+
+	// LanguageTexts son el título y la descripción por idioma (title_txt_en...); como son dynamic
+	// fields no tienen un campo fijo y los agrega MarshalJSON
+	LanguageTexts map[string]string `json:"-"`
+}
+
+// MarshalJSON serializa el documento con los campos de texto por idioma
+func (p SolrProperty) MarshalJSON() ([]byte, error) {
+	type solrPropertyFields SolrProperty
+	data, err := json.Marshal(solrPropertyFields(p))
+	if err != nil || len(p.LanguageTexts) == 0 {
+		return data, err
+	}
+
+	// RawMessage conserva los valores tal cual (sin pasar los números por float64)
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	for field, text := range p.LanguageTexts {
+		value, err := json.Marshal(text)
+		if err != nil {
+			return nil, err
+		}
+		doc[field] = value
+	}
+	return json.Marshal(doc)
+}
+
+// Search realiza una búsqueda de propiedades con filtros, paginación y facets
+// Con un país que tiene colección propia solo se consulta esa; si no, todas las colecciones
+func (r *solrRepository) Search(ctx context.Context, request dto.SearchRequest) (dto.SearchResult, error) {
+	collections := r.router.forSearch(request)
+	for _, collection := range collections {
+		if err := r.ensureSchema(ctx, collection); err != nil {
+			return dto.SearchResult{}, err
+		}
+	}
+
+	params := buildSolrSearchParams(request)
+
+	// Construir URL completa
+	fullURL := r.router.selectURL(collections, params)
+
+	// Crear request HTTP
+	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
+	if err != nil {
+		return dto.SearchResult{}, fmt.Errorf("error creando request HTTP: %w", err)
+	}
+
+	// Realizar petición
+	trace := SearchTraceFrom(ctx)
+	start := time.Now()
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return dto.SearchResult{}, fmt.Errorf("error realizando petición a Solr: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Verificar código de estado
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return dto.SearchResult{}, fmt.Errorf("error en respuesta de Solr (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	// Leer y parsear respuesta
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return dto.SearchResult{}, fmt.Errorf("error leyendo respuesta de Solr: %w", err)
+	}
+
+	var solrResp SolrResponse
+	if err := json.Unmarshal(body, &solrResp); err != nil {
+		return dto.SearchResult{}, fmt.Errorf("error parseando respuesta JSON de Solr: %w", err)
+	}
+	trace.Since(TraceIndex, start)
+	trace.Add(TraceIndexEngine, time.Duration(solrResp.ResponseHeader.QTime)*time.Millisecond)
+
+	// Convertir documentos de Solr a domain.Property
+	defer trace.Since(TraceMapping, time.Now())
+	properties := make([]domain.Property, 0, len(solrResp.Response.Docs))
+	for i, doc := range solrResp.Response.Docs {
+		log.Printf("📥 Documento %d de Solr: %+v", i+1, doc)
+		property, err := r.solrDocToProperty(doc)
+		if err != nil {
+			// Log error pero continuar con otros documentos
+			log.Printf("❌ Error convirtiendo documento de Solr: %v", err)
+			continue
+		}
+		properties = append(properties, property)
+	}
+
+	return dto.SearchResult{
+		Properties: properties,
+		Total:      solrResp.Response.NumFound,
+		Facets:     parseFacetFields(solrResp.FacetCounts.FacetFields),
+	}, nil
+}
+
+// DescribeSearch retorna la URL de /select con la que se resolvería la búsqueda (para diagnóstico)
+func (r *solrRepository) DescribeSearch(request dto.SearchRequest) string {
+	return r.router.selectURL(r.router.forSearch(request), buildSolrSearchParams(request))
+}
+
+// buildSolrSearchParams arma los parámetros de /select para la búsqueda: texto, filtros (fq),
+// facets, paginación, orden y campos a devolver
+func buildSolrSearchParams(request dto.SearchRequest) url.Values {
 	params := url.Values{}
 	params.Set("wt", "json") // Formato de respuesta JSON
 
 	// Construir query de búsqueda por texto
 	if request.Query != "" {
-		// Búsqueda en title, city, country
-		query := fmt.Sprintf("(title:*%s* OR city:*%s* OR country:*%s*)",
-			escapeSolrQuery(request.Query),
-			escapeSolrQuery(request.Query),
-			escapeSolrQuery(request.Query))
-		params.Set("q", query)
+		// Búsqueda en title, city, country sin distinguir acentos, mayúsculas ni plurales
+		params.Set("q", buildSolrTextQuery(request.Query, request.Lang))
 	} else {
 		params.Set("q", "*:*") // Buscar todo si no hay query
 	}
@@ -108,6 +284,12 @@ func (r *solrRepository) Search(ctx context.Context, request dto.SearchRequest)
 		filters = append(filters, fmt.Sprintf("country:\"%s\"", escapeSolrQuery(request.Country)))
 	}
 
+	// Filtro por tipo de propiedad
+	// El tag permite que el facet de tipos ignore este filtro y muestre también los otros tipos
+	if request.Type != "" {
+		filters = append(filters, fmt.Sprintf("{!tag=type}%s:\"%s\"", propertyTypeField, escapeSolrQuery(request.Type)))
+	}
+
 	// Filtro por rango de precio
 	if request.MinPrice > 0 || request.MaxPrice > 0 {
 		minPrice := request.MinPrice
@@ -118,6 +300,13 @@ func (r *solrRepository) Search(ctx context.Context, request dto.SearchRequest)
 		filters = append(filters, fmt.Sprintf("price:[%f TO %f]", minPrice, maxPrice))
 	}
 
+	// Filtro por bounding box (búsqueda por mapa)
+	// geo_p es un campo LatLonPointSpatialField y acepta rangos "minLat,minLng TO maxLat,maxLng"
+	if request.HasBoundingBox() {
+		filters = append(filters, fmt.Sprintf("geo_p:[%f,%f TO %f,%f]",
+			*request.BboxMinLat, *request.BboxMinLng, *request.BboxMaxLat, *request.BboxMaxLng))
+	}
+
 	// Filtro por número de habitaciones
 	if request.Bedrooms > 0 {
 		filters = append(filters, fmt.Sprintf("bedrooms:%d", request.Bedrooms))
@@ -133,11 +322,54 @@ func (r *solrRepository) Search(ctx context.Context, request dto.SearchRequest)
 		filters = append(filters, fmt.Sprintf("max_guests:[%d TO *]", request.MinGuests))
 	}
 
+	// Filtros por propiedades aptas para niños y bebés
+	if request.ChildFriendly {
+		filters = append(filters, childFriendlyField+":true")
+	}
+	if request.InfantFriendly {
+		filters = append(filters, infantFriendlyField+":true")
+	}
+
+	// Filtros por reglas de la casa
+	if request.PetsAllowed {
+		filters = append(filters, petsAllowedField+":true")
+	}
+	if request.SmokingAllowed {
+		filters = append(filters, smokingAllowedField+":true")
+	}
+	if request.EventsAllowed {
+		filters = append(filters, eventsAllowedField+":true")
+	}
+
+	// Filtros por reserva instantánea y política de cancelación
+	if request.InstantBook {
+		filters = append(filters, instantBookField+":true")
+	}
+	if request.CancellationPolicy != "" {
+		filters = append(filters, fmt.Sprintf("%s:\"%s\"", cancellationPolicyField, escapeSolrQuery(request.CancellationPolicy)))
+	}
+
+	// Filtro por fechas: se excluyen las propiedades con algún rango ocupado que toque las noches pedidas
+	// (en un DateRangeField la consulta por rango busca intersecciones); las que no tienen el campo quedan
+	if request.HasStayDates() {
+		if stay, ok := formatSolrDateRange(domain.DateRange{Start: request.CheckIn, End: request.CheckOut}); ok {
+			filters = append(filters, "-"+bookedRangesField+":"+stay)
+		}
+	}
+
 	// Agregar filtros a los parámetros
 	for _, filter := range filters {
 		params.Add("fq", filter)
 	}
 
+	// Facets: conteo de resultados por cada valor de los filtros facetados
+	params.Set("facet", "true")
+	params.Set("facet.mincount", "1")
+	params.Set("facet.limit", strconv.Itoa(request.EffectiveFacetLimit()))
+	for name, field := range facetFields {
+		params.Add("facet.field", fmt.Sprintf("{!ex=%s key=%s}%s", name, name, field))
+	}
+
 	// Paginación
 	page := request.Page
 	if page < 1 {
@@ -151,71 +383,213 @@ func (r *solrRepository) Search(ctx context.Context, request dto.SearchRequest)
 	params.Set("start", strconv.Itoa(start))
 	params.Set("rows", strconv.Itoa(pageSize))
 
-	// Ordenamiento (opcional - solo si el usuario lo especifica)
-	sortBy := request.SortBy
-	if sortBy != "" {
-		sortOrder := request.SortOrder
-		if sortOrder == "" {
-			sortOrder = "asc"
-		}
-		if sortOrder != "asc" && sortOrder != "desc" {
-			sortOrder = "asc"
+	// Ordenamiento: los criterios ya vienen validados contra los campos ordenables
+	// Siempre se desempata por id para que la paginación sea estable entre requests
+	params.Set("sort", buildSolrSort(request.Sort))
+
+	// Campos a devolver: con fields solo se piden a Solr los campos seleccionados
+	if fl := buildSolrFieldList(request); fl != "" {
+		params.Set("fl", fl)
+	}
+
+	return params
+}
+
+// mltFields son los campos de texto que compara MoreLikeThis
+const mltFields = "title,description,city,country"
+
+// GetProperty obtiene una propiedad indexada por su ID
+func (r *solrRepository) GetProperty(ctx context.Context, propertyID string) (domain.Property, error) {
+	params := url.Values{}
+	params.Set("wt", "json")
+	params.Set("q", fmt.Sprintf("id:\"%s\"", escapeSolrQuery(propertyID)))
+	params.Set("rows", "1")
+
+	solrResp, err := r.selectDocs(ctx, r.router.all(), params)
+	if err != nil {
+		return domain.Property{}, err
+	}
+	if len(solrResp.Response.Docs) == 0 {
+		return domain.Property{}, ErrPropertyNotIndexed
+	}
+	return r.solrDocToProperty(solrResp.Response.Docs[0])
+}
+
+// MoreLikeThis busca propiedades parecidas con el query parser MLT de Solr
+// mintf y mindf en 1 porque las descripciones son cortas y el índice es chico
+func (r *solrRepository) MoreLikeThis(ctx context.Context, property domain.Property, limit int) ([]domain.Property, error) {
+	params := url.Values{}
+	params.Set("wt", "json")
+	params.Set("q", fmt.Sprintf("{!mlt qf=%s mintf=1 mindf=1}%s", mltFields, property.ID))
+	params.Add("fq", fmt.Sprintf("-id:\"%s\"", escapeSolrQuery(property.ID)))
+	params.Set("rows", strconv.Itoa(limit))
+
+	// Las parecidas se buscan en la colección de la propiedad (mismo país)
+	solrResp, err := r.selectDocs(ctx, []string{r.router.forCountry(property.Country)}, params)
+	if err != nil {
+		return nil, err
+	}
+
+	properties := make([]domain.Property, 0, len(solrResp.Response.Docs))
+	for _, doc := range solrResp.Response.Docs {
+		similar, err := r.solrDocToProperty(doc)
+		if err != nil {
+			log.Printf("❌ Error convirtiendo documento de Solr: %v", err)
+			continue
 		}
-		params.Set("sort", fmt.Sprintf("%s %s", sortBy, sortOrder))
+		properties = append(properties, similar)
 	}
+	return properties, nil
+}
 
-	// Construir URL completa
-	fullURL := baseURL + "?" + params.Encode()
+// versionsPageSize es la cantidad de documentos por página al listar las versiones del índice
+const versionsPageSize = 1000
 
-	// Crear request HTTP
-	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
+// ListPropertyVersions recorre todo el índice con cursorMark pidiendo solo id y updated_at_dt
+// El cursor exige ordenar por la clave única (id) y es estable aunque se indexe mientras se recorre
+func (r *solrRepository) ListPropertyVersions(ctx context.Context) (map[string]time.Time, error) {
+	return r.listVersionsIn(ctx, r.router.all())
+}
+
+// listVersionsIn recorre las colecciones con cursorMark y retorna la versión de cada documento
+func (r *solrRepository) listVersionsIn(ctx context.Context, collections []string) (map[string]time.Time, error) {
+	versions := make(map[string]time.Time)
+	cursorMark := "*"
+	for {
+		params := url.Values{}
+		params.Set("wt", "json")
+		params.Set("q", "*:*")
+		params.Set("fl", "id,"+solrUpdatedAtField)
+		params.Set("sort", "id asc")
+		params.Set("rows", strconv.Itoa(versionsPageSize))
+		params.Set("cursorMark", cursorMark)
+
+		solrResp, err := r.selectDocs(ctx, collections, params)
+		if err != nil {
+			return nil, fmt.Errorf("error listando versiones del índice: %w", err)
+		}
+		for _, doc := range solrResp.Response.Docs {
+			if id, ok := doc["id"].(string); ok {
+				versions[id] = parseSolrDate(doc[solrUpdatedAtField])
+			}
+		}
+
+		// Solr repite el mismo cursorMark cuando no quedan documentos
+		if solrResp.NextCursorMark == "" || solrResp.NextCursorMark == cursorMark {
+			return versions, nil
+		}
+		cursorMark = solrResp.NextCursorMark
+	}
+}
+
+// selectDocs ejecuta una consulta a /select sobre las colecciones y parsea la respuesta
+func (r *solrRepository) selectDocs(ctx context.Context, collections []string, params url.Values) (SolrResponse, error) {
+	fullURL := r.router.selectURL(collections, params)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fullURL, nil)
 	if err != nil {
-		return nil, 0, fmt.Errorf("error creando request HTTP: %w", err)
+		return SolrResponse{}, fmt.Errorf("error creando request HTTP: %w", err)
 	}
 
-	// Realizar petición
 	resp, err := r.httpClient.Do(req)
 	if err != nil {
-		return nil, 0, fmt.Errorf("error realizando petición a Solr: %w", err)
+		return SolrResponse{}, fmt.Errorf("error realizando petición a Solr: %w", err)
 	}
 	defer resp.Body.Close()
 
-	// Verificar código de estado
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, 0, fmt.Errorf("error en respuesta de Solr (status %d): %s", resp.StatusCode, string(body))
-	}
-
-	// Leer y parsear respuesta
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, 0, fmt.Errorf("error leyendo respuesta de Solr: %w", err)
+		return SolrResponse{}, fmt.Errorf("error leyendo respuesta de Solr: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return SolrResponse{}, fmt.Errorf("error en respuesta de Solr (status %d): %s", resp.StatusCode, string(body))
 	}
 
 	var solrResp SolrResponse
 	if err := json.Unmarshal(body, &solrResp); err != nil {
-		return nil, 0, fmt.Errorf("error parseando respuesta JSON de Solr: %w", err)
+		return SolrResponse{}, fmt.Errorf("error parseando respuesta JSON de Solr: %w", err)
 	}
+	return solrResp, nil
+}
 
-	// Convertir documentos de Solr a domain.Property
-	properties := make([]domain.Property, 0, len(solrResp.Response.Docs))
-	for i, doc := range solrResp.Response.Docs {
-		log.Printf("📥 Documento %d de Solr: %+v", i+1, doc)
-		property, err := r.solrDocToProperty(doc)
-		if err != nil {
-			// Log error pero continuar con otros documentos
-			log.Printf("❌ Error convirtiendo documento de Solr: %v", err)
-			continue
+// buildSolrSort arma el parámetro sort de Solr con el id como último criterio de desempate
+// Sin criterios se mantiene el orden por relevancia (score)
+func buildSolrSort(fields []dto.SortField) string {
+	if len(fields) == 0 {
+		return "score desc,id asc"
+	}
+
+	parts := make([]string, 0, len(fields)+1)
+	for _, field := range fields {
+		parts = append(parts, field.Field+" "+field.Order)
+	}
+	parts = append(parts, "id asc")
+	return strings.Join(parts, ",")
+}
+
+// buildSolrFieldList arma el parámetro fl de Solr con los campos pedidos en fields
+// Con bounding box se agrega geo_p porque los clusters del mapa usan las coordenadas
+// Con hydrate=true solo se piden el ID y el score: el resto sale de properties-api
+func buildSolrFieldList(request dto.SearchRequest) string {
+	if request.Hydrate {
+		return "id,score"
+	}
+	if len(request.Projection) == 0 {
+		return ""
+	}
+
+	fields := dto.SolrFieldNames(request.Projection)
+	if request.HasBoundingBox() && !containsField(fields, "geo_p") {
+		fields = append(fields, "geo_p")
+	}
+	// Con lang el título y la descripción se traducen: hacen falta los textos de ese idioma
+	if request.Lang != "" && (containsField(fields, "title") || containsField(fields, "description")) {
+		fields = append(fields, languageField, solrLanguageField("title", request.Lang), solrLanguageField("description", request.Lang))
+	}
+	return strings.Join(fields, ",")
+}
+
+// containsField indica si el campo está en la lista
+func containsField(fields []string, field string) bool {
+	for _, candidate := range fields {
+		if candidate == field {
+			return true
 		}
-		properties = append(properties, property)
+	}
+	return false
+}
+
+// parseFacetFields convierte las listas planas de Solr [valor, cantidad, ...] a FacetValue
+func parseFacetFields(fields map[string][]interface{}) map[string][]dto.FacetValue {
+	if len(fields) == 0 {
+		return nil
 	}
 
-	return properties, solrResp.Response.NumFound, nil
+	facets := make(map[string][]dto.FacetValue, len(fields))
+	for name, flat := range fields {
+		values := make([]dto.FacetValue, 0, len(flat)/2)
+		for i := 0; i+1 < len(flat); i += 2 {
+			value, ok := flat[i].(string)
+			count, okCount := flat[i+1].(float64)
+			if !ok || !okCount {
+				continue
+			}
+			values = append(values, dto.FacetValue{Value: value, Count: int(count)})
+		}
+		facets[name] = values
+	}
+	return facets
 }
 
-// IndexProperty indexa una nueva propiedad en Solr
+// IndexProperty indexa una nueva propiedad en la colección de su país
+// Si la propiedad cambió de país se borra de las otras colecciones
 func (r *solrRepository) IndexProperty(ctx context.Context, property domain.Property) error {
-	log.Printf("📝 Indexando propiedad en Solr - ID: %s, Title: %s", property.ID, property.Title)
+	collection := r.router.forCountry(property.Country)
+	log.Printf("📝 Indexando propiedad en Solr - ID: %s, Title: %s, Colección: %s", property.ID, property.Title, collection)
+
+	// Los campos en español se llenan por copyField: tienen que existir antes de indexar
+	if err := r.ensureSchema(ctx, collection); err != nil {
+		return err
+	}
 
 	// Convertir domain.Property a SolrProperty
 	solrProp := r.propertyToSolr(property)
@@ -233,7 +607,7 @@ func (r *solrRepository) IndexProperty(ctx context.Context, property domain.Prop
 	log.Printf("📦 JSON a enviar a Solr: %s", string(jsonData))
 
 	// Construir URL de actualización
-	updateURL := strings.TrimSuffix(r.solrURL, "/") + "/update/json/docs"
+	updateURL := r.router.collectionURL(collection) + "/update/json/docs"
 
 	// Crear request HTTP POST
 	req, err := http.NewRequestWithContext(ctx, "POST", updateURL, bytes.NewBuffer(jsonData))
@@ -260,7 +634,19 @@ func (r *solrRepository) IndexProperty(ctx context.Context, property domain.Prop
 	log.Printf("✅ Propiedad indexada exitosamente en Solr - ID: %s", property.ID)
 
 	// Hacer commit
-	return r.commit(ctx)
+	if err := r.commit(ctx, collection); err != nil {
+		return err
+	}
+
+	for _, other := range r.router.all() {
+		if other == collection {
+			continue
+		}
+		if err := r.deleteIn(ctx, other, map[string]string{"id": property.ID}); err != nil {
+			return fmt.Errorf("error borrando la propiedad de la colección '%s': %w", other, err)
+		}
+	}
+	return nil
 }
 
 // UpdateProperty actualiza una propiedad existente en Solr
@@ -269,13 +655,117 @@ func (r *solrRepository) UpdateProperty(ctx context.Context, property domain.Pro
 	return r.IndexProperty(ctx, property)
 }
 
-// DeleteProperty elimina una propiedad de Solr por su ID
+// DeleteProperty elimina una propiedad de Solr por su ID (de todas las colecciones)
 func (r *solrRepository) DeleteProperty(ctx context.Context, propertyID string) error {
+	return r.delete(ctx, map[string]string{"id": propertyID})
+}
+
+// DeletePropertiesByOwner elimina de Solr todas las propiedades de un usuario (delete by query)
+func (r *solrRepository) DeletePropertiesByOwner(ctx context.Context, ownerID uint) error {
+	return r.delete(ctx, map[string]string{"query": fmt.Sprintf("owner_id:%d", ownerID)})
+}
+
+// UpdatePopularity actualiza el campo popularity con atomic updates de Solr y hace commit
+// _version_ = 1 exige que el documento exista, así no se crean documentos parciales
+// para propiedades que todavía no se indexaron o que ya se eliminaron; por eso el mismo lote
+// se manda a todas las colecciones y cada una actualiza solo las propiedades que tiene
+func (r *solrRepository) UpdatePopularity(ctx context.Context, views map[string]int64) error {
+	docs := make([]map[string]interface{}, 0, len(views))
+	for propertyID, total := range views {
+		docs = append(docs, map[string]interface{}{
+			"id":         propertyID,
+			"popularity": map[string]int64{"set": total},
+			"_version_":  1,
+		})
+	}
+
+	jsonData, err := json.Marshal(docs)
+	if err != nil {
+		return fmt.Errorf("error serializando actualización de popularidad: %w", err)
+	}
+
+	for _, collection := range r.router.all() {
+		if err := r.atomicUpdateIn(ctx, collection, jsonData); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateAvailability reemplaza los días ocupados y el primer día libre de la propiedad con un atomic update
+// Igual que UpdatePopularity exige que el documento exista y se manda a todas las colecciones
+func (r *solrRepository) UpdateAvailability(ctx context.Context, propertyID string, booked []domain.DateRange, nextAvailable string) error {
+	// set con null elimina el campo (una propiedad sin fechas ocupadas)
+	var ranges interface{}
+	if formatted := formatSolrDateRanges(booked); len(formatted) > 0 {
+		ranges = formatted
+	}
+	var next interface{}
+	if day := formatSolrDay(nextAvailable); day != "" {
+		next = day
+	}
+
+	docs := []map[string]interface{}{{
+		"id":               propertyID,
+		bookedRangesField:  map[string]interface{}{"set": ranges},
+		nextAvailableField: map[string]interface{}{"set": next},
+		"_version_":        1,
+	}}
+	jsonData, err := json.Marshal(docs)
+	if err != nil {
+		return fmt.Errorf("error serializando actualización de disponibilidad: %w", err)
+	}
+
+	for _, collection := range r.router.all() {
+		if err := r.ensureSchema(ctx, collection); err != nil {
+			return err
+		}
+		if err := r.atomicUpdateIn(ctx, collection, jsonData); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// atomicUpdateIn manda el lote de atomic updates a una colección y hace commit
+func (r *solrRepository) atomicUpdateIn(ctx context.Context, collection string, jsonData []byte) error {
+	// failOnVersionConflicts=false saltea los documentos inexistentes sin fallar todo el lote
+	updateURL := r.router.collectionURL(collection) + "/update?failOnVersionConflicts=false"
+	req, err := http.NewRequestWithContext(ctx, "POST", updateURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("error creando request HTTP: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error realizando petición a Solr: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("error aplicando atomic update en Solr (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	return r.commit(ctx, collection)
+}
+
+// delete envía un comando de eliminación (por id o por query) a todas las colecciones
+func (r *solrRepository) delete(ctx context.Context, selector map[string]string) error {
+	for _, collection := range r.router.all() {
+		if err := r.deleteIn(ctx, collection, selector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteIn envía un comando de eliminación a una colección y hace commit
+func (r *solrRepository) deleteIn(ctx context.Context, collection string, selector map[string]string) error {
 	// Construir comando de eliminación
 	deleteCmd := map[string]interface{}{
-		"delete": map[string]string{
-			"id": propertyID,
-		},
+		"delete": selector,
 	}
 
 	// Serializar a JSON
@@ -285,7 +775,7 @@ func (r *solrRepository) DeleteProperty(ctx context.Context, propertyID string)
 	}
 
 	// Construir URL de actualización
-	updateURL := strings.TrimSuffix(r.solrURL, "/") + "/update"
+	updateURL := r.router.collectionURL(collection) + "/update"
 
 	// Crear request HTTP POST
 	req, err := http.NewRequestWithContext(ctx, "POST", updateURL, bytes.NewBuffer(jsonData))
@@ -304,15 +794,15 @@ func (r *solrRepository) DeleteProperty(ctx context.Context, propertyID string)
 	// Verificar código de estado
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("error eliminando propiedad en Solr (status %d): %s", resp.StatusCode, string(body))
+		return fmt.Errorf("error eliminando en Solr (status %d): %s", resp.StatusCode, string(body))
 	}
 
 	// Hacer commit
-	return r.commit(ctx)
+	return r.commit(ctx, collection)
 }
 
-// commit realiza un commit en Solr para hacer persistentes los cambios
-func (r *solrRepository) commit(ctx context.Context) error {
+// commit realiza un commit en una colección de Solr para hacer persistentes los cambios
+func (r *solrRepository) commit(ctx context.Context, collection string) error {
 	commitCmd := map[string]interface{}{
 		"commit": map[string]interface{}{},
 	}
@@ -322,7 +812,7 @@ func (r *solrRepository) commit(ctx context.Context) error {
 		return fmt.Errorf("error serializando comando de commit: %w", err)
 	}
 
-	updateURL := strings.TrimSuffix(r.solrURL, "/") + "/update"
+	updateURL := r.router.collectionURL(collection) + "/update"
 	req, err := http.NewRequestWithContext(ctx, "POST", updateURL, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return fmt.Errorf("error creando request HTTP: %w", err)
@@ -343,28 +833,84 @@ func (r *solrRepository) commit(ctx context.Context) error {
 	return nil
 }
 
+// Ping consulta el handler /admin/ping de cada colección de Solr
+func (r *solrRepository) Ping(ctx context.Context) error {
+	for _, collection := range r.router.all() {
+		if err := r.ping(ctx, collection); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ping consulta el handler /admin/ping de una colección
+func (r *solrRepository) ping(ctx context.Context, collection string) error {
+	pingURL := r.router.collectionURL(collection) + "/admin/ping?wt=json"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pingURL, nil)
+	if err != nil {
+		return fmt.Errorf("error creando request de ping a Solr: %w", err)
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error haciendo ping a Solr: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("ping a la colección '%s' de Solr respondió status %d", collection, resp.StatusCode)
+	}
+	return nil
+}
+
 // propertyToSolr convierte una domain.Property a SolrProperty
 func (r *solrRepository) propertyToSolr(property domain.Property) SolrProperty {
 	// Asegurar que CreatedAt tenga un valor válido
-	createdAt := property.CreatedAt
+	// Solr solo acepta fechas en UTC ("...Z"), por eso se normaliza
+	createdAt := property.CreatedAt.UTC()
 	if createdAt.IsZero() {
-		createdAt = time.Now()
+		createdAt = time.Now().UTC()
+	}
+
+	// updated_at_dt (dynamic field *_dt) es la versión que compara la reconciliación con properties-api
+	updatedAt := property.UpdatedAt.UTC()
+	if updatedAt.IsZero() {
+		updatedAt = createdAt
 	}
 
 	solrProp := SolrProperty{
-		ID:            property.ID,
-		Title:         property.Title,
-		Description:   property.Description,
-		City:          property.City,
-		Country:       property.Country,
-		PricePerNight: property.PricePerNight,
-		Bedrooms:      property.Bedrooms,
-		Bathrooms:     property.Bathrooms,
-		MaxGuests:     property.MaxGuests,
-		Images:        property.Images,
-		OwnerID:       property.OwnerID,
-		Available:     property.Available,
-		CreatedAt:     createdAt,
+		ID:                 property.ID,
+		Title:              property.Title,
+		Description:        property.Description,
+		City:               property.City,
+		Country:            property.Country,
+		PropertyType:       property.PropertyType,
+		GeoLocation:        formatGeoLocation(property.Latitude, property.Longitude),
+		PricePerNight:      property.PricePerNight,
+		Bedrooms:           property.Bedrooms,
+		Bathrooms:          property.Bathrooms,
+		MaxGuests:          property.MaxGuests,
+		Images:             property.Images,
+		CoverImage:         property.CoverImage,
+		CoverThumbnail:     property.CoverThumbnail,
+		CoverThumbnailWebP: property.CoverThumbnailWebP,
+		OwnerID:            property.OwnerID,
+		Available:          property.Available,
+		ChildFriendly:      property.ChildFriendly,
+		InfantFriendly:     property.InfantFriendly,
+		PetsAllowed:        property.PetsAllowed,
+		SmokingAllowed:     property.SmokingAllowed,
+		EventsAllowed:      property.EventsAllowed,
+		InstantBook:        property.InstantBook,
+		CancellationPolicy: property.CancellationPolicy,
+		Popularity:         property.Popularity,
+		BookedRanges:       formatSolrDateRanges(property.BookedRanges),
+		NextAvailableDate:  formatSolrDay(property.NextAvailableDate),
+		Language:           property.Language,
+		CreatedAt:          createdAt,
+		UpdatedAt:          updatedAt,
+		LanguageTexts:      solrLanguageTexts(property),
 	}
 
 	// Log para verificar que todos los campos tienen valores
@@ -463,14 +1009,32 @@ func (r *solrRepository) solrDocToProperty(doc map[string]interface{}) (domain.P
 	property.ID = getStringValue("id")
 	property.Title = getStringValue("title")
 	property.Description = getStringValue("description")
+	property.Language = getStringValue(languageField)
+	property.Translations = solrDocTranslations(getStringValue, property.Language)
 	property.City = getStringValue("city")
 	property.Country = getStringValue("country")
+	property.PropertyType = getStringValue(propertyTypeField)
 	property.PricePerNight = getFloatValue("price")
 	property.Bedrooms = int(getFloatValue("bedrooms"))
 	property.Bathrooms = int(getFloatValue("bathrooms"))
 	property.MaxGuests = int(getFloatValue("max_guests"))
 	property.Available = getBoolValue("available")
+	property.ChildFriendly = getBoolValue(childFriendlyField)
+	property.InfantFriendly = getBoolValue(infantFriendlyField)
+	property.PetsAllowed = getBoolValue(petsAllowedField)
+	property.SmokingAllowed = getBoolValue(smokingAllowedField)
+	property.EventsAllowed = getBoolValue(eventsAllowedField)
+	property.InstantBook = getBoolValue(instantBookField)
+	property.CancellationPolicy = getStringValue(cancellationPolicyField)
 	property.OwnerID = uint(getFloatValue("owner_id"))
+	property.Popularity = int64(getFloatValue("popularity"))
+	property.BookedRanges = parseSolrDateRanges(doc[bookedRangesField])
+	if next := parseSolrDate(doc[nextAvailableField]); !next.IsZero() {
+		property.NextAvailableDate = next.Format("2006-01-02")
+	}
+	// score solo viene si se pidió en fl (búsquedas con hydrate=true)
+	property.Score = getFloatValue("score")
+	property.Latitude, property.Longitude = parseGeoLocation(getStringValue("geo_p"))
 
 	// Manejar images (array de strings)
 	if imagesVal, exists := doc["images"]; exists {
@@ -484,24 +1048,13 @@ func (r *solrRepository) solrDocToProperty(doc map[string]interface{}) (domain.P
 		}
 	}
 
-	// Manejar created_at (puede venir como array o string)
-	if createdAtVal, exists := doc["created_at"]; exists {
-		var createdAtStr string
-		if arr, ok := createdAtVal.([]interface{}); ok && len(arr) > 0 {
-			if str, ok := arr[0].(string); ok {
-				createdAtStr = str
-			}
-		} else if str, ok := createdAtVal.(string); ok {
-			createdAtStr = str
-		}
-		if createdAtStr != "" {
-			if t, err := time.Parse(time.RFC3339, createdAtStr); err == nil {
-				property.CreatedAt = t
-			} else if t, err := time.Parse("2006-01-02T15:04:05Z", createdAtStr); err == nil {
-				property.CreatedAt = t
-			}
-		}
-	}
+	property.CoverImage = getStringValue(coverImageField)
+	property.CoverThumbnail = getStringValue(coverThumbnailField)
+	property.CoverThumbnailWebP = getStringValue(coverThumbnailWebPField)
+
+	// Manejar created_at y updated_at_dt (pueden venir como array o string)
+	property.CreatedAt = parseSolrDate(doc["created_at"])
+	property.UpdatedAt = parseSolrDate(doc[solrUpdatedAtField])
 
 	// LOG para verificar valores mapeados
 	log.Printf("✅ Property mapeado - ID: '%s', Title: '%s', PricePerNight: %f",
@@ -510,6 +1063,99 @@ func (r *solrRepository) solrDocToProperty(doc map[string]interface{}) (domain.P
 	return property, nil
 }
 
+// parseSolrDate convierte una fecha de Solr (array o string, RFC3339 en UTC) a time.Time
+// Retorna el valor cero si el campo no existe o no es una fecha válida
+func parseSolrDate(value interface{}) time.Time {
+	var str string
+	if arr, ok := value.([]interface{}); ok && len(arr) > 0 {
+		str, _ = arr[0].(string)
+	} else {
+		str, _ = value.(string)
+	}
+	if t, err := time.Parse(time.RFC3339, str); err == nil {
+		return t.UTC()
+	}
+	return time.Time{}
+}
+
+// formatSolrDateRange convierte un rango de días con fin exclusivo al formato de DateRangeField
+// con la última noche como fin inclusivo (ej: "[2025-01-10 TO 2025-01-11]" para 10 al 12)
+// Retorna false si las fechas no son YYYY-MM-DD o el rango no tiene noches
+func formatSolrDateRange(r domain.DateRange) (string, bool) {
+	start, errStart := time.Parse("2006-01-02", r.Start)
+	end, errEnd := time.Parse("2006-01-02", r.End)
+	if errStart != nil || errEnd != nil || !end.After(start) {
+		return "", false
+	}
+	return fmt.Sprintf("[%s TO %s]", start.Format("2006-01-02"), end.AddDate(0, 0, -1).Format("2006-01-02")), true
+}
+
+// formatSolrDateRanges convierte los rangos ocupados al formato de DateRangeField (omite los inválidos)
+func formatSolrDateRanges(ranges []domain.DateRange) []string {
+	formatted := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		if value, ok := formatSolrDateRange(r); ok {
+			formatted = append(formatted, value)
+		}
+	}
+	return formatted
+}
+
+// parseSolrDateRanges convierte los valores de DateRangeField a rangos de días con fin exclusivo
+func parseSolrDateRanges(value interface{}) []domain.DateRange {
+	values, _ := value.([]interface{})
+	var ranges []domain.DateRange
+	for _, raw := range values {
+		str, _ := raw.(string)
+		parts := strings.Split(strings.Trim(str, "[]"), " TO ")
+		if len(parts) != 2 || len(parts[0]) < 10 || len(parts[1]) < 10 {
+			continue
+		}
+		start, errStart := time.Parse("2006-01-02", parts[0][:10])
+		last, errLast := time.Parse("2006-01-02", parts[1][:10])
+		if errStart != nil || errLast != nil {
+			continue
+		}
+		ranges = append(ranges, domain.DateRange{
+			Start: start.Format("2006-01-02"),
+			End:   last.AddDate(0, 0, 1).Format("2006-01-02"),
+		})
+	}
+	return ranges
+}
+
+// formatSolrDay convierte un día YYYY-MM-DD a fecha de Solr (medianoche UTC); vacío si no es válido
+func formatSolrDay(day string) string {
+	parsed, err := time.Parse("2006-01-02", day)
+	if err != nil {
+		return ""
+	}
+	return parsed.Format(time.RFC3339)
+}
+
+// formatGeoLocation convierte coordenadas al formato "lat,lng" de Solr
+// Retorna vacío si la propiedad no tiene coordenadas
+func formatGeoLocation(latitude, longitude float64) string {
+	if latitude == 0 && longitude == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(latitude, 'f', -1, 64) + "," + strconv.FormatFloat(longitude, 'f', -1, 64)
+}
+
+// parseGeoLocation convierte el formato "lat,lng" de Solr a coordenadas
+func parseGeoLocation(value string) (float64, float64) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 {
+		return 0, 0
+	}
+	latitude, errLat := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	longitude, errLng := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if errLat != nil || errLng != nil {
+		return 0, 0
+	}
+	return latitude, longitude
+}
+
 // escapeSolrQuery escapa caracteres especiales en queries de Solr
 func escapeSolrQuery(query string) string {
 	// Escapar caracteres especiales de Solr
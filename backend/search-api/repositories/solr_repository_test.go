@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"search-api/domain"
+	"search-api/dto"
+)
+
+// ============================================
+// BENCHMARKS - construcción de la consulta a Solr
+// ============================================
+// Miden el trabajo de CPU de search-api por cada búsqueda que llega al índice (cache miss),
+// sin contar la latencia de Solr. Correr con: go test ./repositories -run '^$' -bench Solr -benchmem
+
+// discardLogs descarta la salida del log durante el benchmark
+// El formateo de los logs se sigue midiendo: es parte del costo real de cada búsqueda
+func discardLogs(b *testing.B) {
+	log.SetOutput(io.Discard)
+	b.Cleanup(func() { log.SetOutput(os.Stderr) })
+}
+
+// benchmarkBbox son las coordenadas del bounding box de las búsquedas por mapa
+var benchmarkBbox = [4]float64{-31.45, -64.22, -31.38, -64.15}
+
+// benchmarkSearchRequests son búsquedas representativas: la página inicial, una por texto
+// y una con todos los filtros, orden y selección de campos
+func benchmarkSearchRequests() map[string]dto.SearchRequest {
+	return map[string]dto.SearchRequest{
+		"broad": {Page: 1, PageSize: 10},
+		"text":  {Query: "departamento luminoso con terraza", Page: 1, PageSize: 20},
+		"filtered": {
+			Query:              "cabaña lago",
+			City:               "Bariloche",
+			Country:            "Argentina",
+			Type:               "cabaña",
+			MinPrice:           30000,
+			MaxPrice:           90000,
+			MinGuests:          4,
+			ChildFriendly:      true,
+			PetsAllowed:        true,
+			InstantBook:        true,
+			CancellationPolicy: "moderate",
+			BboxMinLat:         &benchmarkBbox[0],
+			BboxMinLng:         &benchmarkBbox[1],
+			BboxMaxLat:         &benchmarkBbox[2],
+			BboxMaxLng:         &benchmarkBbox[3],
+			Page:               3,
+			PageSize:           50,
+			Sort:               []dto.SortField{{Field: "popularity", Order: "desc"}, {Field: "price", Order: "asc"}},
+			Fields:             "id,title,price,images[0]",
+		},
+	}
+}
+
+func BenchmarkBuildSolrSearchParams(b *testing.B) {
+	for name, request := range benchmarkSearchRequests() {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = buildSolrSearchParams(request)
+			}
+		})
+	}
+}
+
+// BenchmarkSolrSelectURL incluye el armado de la URL final con una y con varias colecciones
+func BenchmarkSolrSelectURL(b *testing.B) {
+	request := benchmarkSearchRequests()["filtered"]
+	routers := map[string]solrCollectionRouter{
+		"single": newSolrCollectionRouter(SolrOptions{URL: "http://localhost:8983/solr/properties"}),
+		"routed": newSolrCollectionRouter(SolrOptions{
+			URL:    "http://localhost:8983/solr/properties",
+			Routes: map[string]string{"argentina": "properties_ar", "chile": "properties_cl", "uruguay": "properties_uy"},
+		}),
+	}
+	for name, router := range routers {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = router.selectURL(router.forSearch(request), buildSolrSearchParams(request))
+			}
+		})
+	}
+}
+
+// BenchmarkSolrDocToProperty mide la conversión de cada documento de la respuesta de Solr
+func BenchmarkSolrDocToProperty(b *testing.B) {
+	repo := &solrRepository{}
+	data, err := json.Marshal(repo.propertyToSolr(domain.Property{
+		ID:            "6650f1c2a1b2c3d4e5f60718",
+		Title:         "Cabaña acogedora en Llao Llao",
+		Description:   "Cabaña para 4 huéspedes a metros del lago.",
+		City:          "Bariloche",
+		Country:       "Argentina",
+		PropertyType:  "cabaña",
+		Latitude:      -41.1335,
+		Longitude:     -71.3103,
+		PricePerNight: 70000,
+		Bedrooms:      2,
+		Bathrooms:     1,
+		MaxGuests:     4,
+		Images:        []string{"https://cdn.spotly.local/1.jpg", "https://cdn.spotly.local/2.jpg"},
+		CoverImage:    "https://cdn.spotly.local/1.jpg",
+		OwnerID:       1001,
+		Available:     true,
+		CreatedAt:     time.Date(2024, 5, 24, 18, 30, 0, 0, time.UTC),
+		UpdatedAt:     time.Date(2024, 5, 25, 9, 15, 0, 0, time.UTC),
+	}))
+	if err != nil {
+		b.Fatalf("failed to encode solr document: %v", err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		b.Fatalf("failed to decode solr document: %v", err)
+	}
+	discardLogs(b)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := repo.solrDocToProperty(doc); err != nil {
+			b.Fatalf("solrDocToProperty failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"search-api/domain"
+)
+
+// solrFoldedTextType es el tipo de campo para búsquedas en español que ignoran mayúsculas y acentos
+// "Cordoba" encuentra "Córdoba" (ASCII folding) y "departamento" encuentra "departamentos" (stemming)
+const solrFoldedTextType = "text_es_folded"
+
+// solrFoldedTextFieldType es la definición del tipo para la Schema API
+// Las wildcards (*texto*) no pasan por el stemmer, pero Solr les aplica lowercase y folding
+var solrFoldedTextFieldType = map[string]interface{}{
+	"name":                 solrFoldedTextType,
+	"class":                "solr.TextField",
+	"positionIncrementGap": "100",
+	"analyzer": map[string]interface{}{
+		"tokenizer": map[string]string{"class": "solr.StandardTokenizerFactory"},
+		"filters": []map[string]string{
+			{"class": "solr.LowerCaseFilterFactory"},
+			{"class": "solr.ASCIIFoldingFilterFactory"},
+			{"class": "solr.SpanishLightStemFilterFactory"},
+		},
+	},
+}
+
+// solrDateRangeType es el tipo de los días ocupados: una consulta por rango encuentra los que se intersecan
+const solrDateRangeType = "date_range"
+
+// solrBookedRangesField es la definición del campo de días ocupados para la Schema API
+// Se guarda (stored) porque DateRangeField no tiene docValues y los atomic updates necesitan el valor
+var solrBookedRangesField = map[string]interface{}{
+	"name":        bookedRangesField,
+	"type":        solrDateRangeType,
+	"indexed":     true,
+	"stored":      true,
+	"multiValued": true,
+}
+
+// solrTextSearchFields son los campos de texto de la búsqueda libre con su copia analizada (copyField)
+// Los originales quedan como estaban para no tener que reindexar para ordenar, filtrar o MoreLikeThis
+var solrTextSearchFields = []struct {
+	Source string
+	Folded string
+}{
+	{Source: "title", Folded: "title_es"},
+	{Source: "city", Folded: "city_es"},
+	{Source: "country", Folded: "country_es"},
+}
+
+// solrSchemaResponse es la parte de GET /schema que se usa para ver qué falta crear
+type solrSchemaResponse struct {
+	Schema struct {
+		FieldTypes []struct {
+			Name string `json:"name"`
+		} `json:"fieldTypes"`
+		Fields []struct {
+			Name string `json:"name"`
+		} `json:"fields"`
+		CopyFields []struct {
+			Source string `json:"source"`
+			Dest   string `json:"dest"`
+		} `json:"copyFields"`
+	} `json:"schema"`
+}
+
+// ensureSchema crea con la Schema API el tipo de texto en español y los campos analizados si faltan,
+// y el campo de días ocupados (booked_ranges) con su tipo
+// Los documentos indexados antes de crearlos no tienen las copias hasta que se reindexan; la búsqueda
+// sigue consultando también los campos originales, así que no dejan de aparecer
+// Cada colección tiene su esquema, así que se verifica por colección. Si falla se reintenta en la próxima operación
+func (r *solrRepository) ensureSchema(ctx context.Context, collection string) error {
+	r.schemaMu.Lock()
+	defer r.schemaMu.Unlock()
+	if r.schemaReady[collection] {
+		return nil
+	}
+
+	schemaURL := r.router.collectionURL(collection) + "/schema"
+	current, err := r.getSchema(ctx, schemaURL)
+	if err != nil {
+		return err
+	}
+
+	commands := missingSchemaCommands(current)
+	if len(commands) == 0 {
+		r.schemaReady[collection] = true
+		return nil
+	}
+
+	jsonData, err := json.Marshal(commands)
+	if err != nil {
+		return fmt.Errorf("error serializando cambios del esquema de Solr: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, schemaURL, bytes.NewReader(jsonData))
+	if err != nil {
+		return fmt.Errorf("error creando request HTTP: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error actualizando el esquema de Solr: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	// Otra instancia puede haberlos creado al mismo tiempo
+	if resp.StatusCode != http.StatusOK && !strings.Contains(string(body), "already exists") {
+		return fmt.Errorf("error actualizando el esquema de Solr (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	log.Printf("✅ Esquema de la colección '%s' de Solr actualizado con el tipo '%s' (%d cambios); las propiedades ya indexadas usan el análisis nuevo al reindexarse", collection, solrFoldedTextType, countSchemaCommands(commands))
+	r.schemaReady[collection] = true
+	return nil
+}
+
+// getSchema obtiene los tipos, campos y copyFields actuales del core
+func (r *solrRepository) getSchema(ctx context.Context, schemaURL string) (solrSchemaResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, schemaURL+"?wt=json", nil)
+	if err != nil {
+		return solrSchemaResponse{}, fmt.Errorf("error creando request HTTP: %w", err)
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return solrSchemaResponse{}, fmt.Errorf("error obteniendo el esquema de Solr: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return solrSchemaResponse{}, fmt.Errorf("error leyendo el esquema de Solr: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return solrSchemaResponse{}, fmt.Errorf("error obteniendo el esquema de Solr (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var schema solrSchemaResponse
+	if err := json.Unmarshal(body, &schema); err != nil {
+		return solrSchemaResponse{}, fmt.Errorf("error parseando el esquema de Solr: %w", err)
+	}
+	return schema, nil
+}
+
+// missingSchemaCommands arma los comandos de la Schema API para lo que falta (vacío si está completo)
+func missingSchemaCommands(current solrSchemaResponse) map[string][]interface{} {
+	commands := map[string][]interface{}{}
+
+	fieldTypes := make(map[string]bool, len(current.Schema.FieldTypes))
+	for _, fieldType := range current.Schema.FieldTypes {
+		fieldTypes[fieldType.Name] = true
+	}
+	if !fieldTypes[solrFoldedTextType] {
+		commands["add-field-type"] = append(commands["add-field-type"], solrFoldedTextFieldType)
+	}
+	if !fieldTypes[solrDateRangeType] {
+		commands["add-field-type"] = append(commands["add-field-type"], map[string]string{
+			"name":  solrDateRangeType,
+			"class": "solr.DateRangeField",
+		})
+	}
+
+	fields := make(map[string]bool, len(current.Schema.Fields))
+	for _, field := range current.Schema.Fields {
+		fields[field.Name] = true
+	}
+	copyFields := make(map[string]bool, len(current.Schema.CopyFields))
+	for _, copyField := range current.Schema.CopyFields {
+		copyFields[copyField.Source+"->"+copyField.Dest] = true
+	}
+
+	for _, field := range solrTextSearchFields {
+		if !fields[field.Folded] {
+			// Solo se busca sobre la copia: no hace falta guardarla
+			commands["add-field"] = append(commands["add-field"], map[string]interface{}{
+				"name":    field.Folded,
+				"type":    solrFoldedTextType,
+				"indexed": true,
+				"stored":  false,
+			})
+		}
+		if !copyFields[field.Source+"->"+field.Folded] {
+			commands["add-copy-field"] = append(commands["add-copy-field"], map[string]string{
+				"source": field.Source,
+				"dest":   field.Folded,
+			})
+		}
+	}
+
+	if !fields[bookedRangesField] {
+		commands["add-field"] = append(commands["add-field"], solrBookedRangesField)
+	}
+
+	return commands
+}
+
+// countSchemaCommands cuenta los cambios de un lote de comandos (para el log)
+func countSchemaCommands(commands map[string][]interface{}) int {
+	total := 0
+	for _, list := range commands {
+		total += len(list)
+	}
+	return total
+}
+
+// buildSolrTextQuery arma la query de búsqueda libre: cada palabra tiene que aparecer en algún campo
+// Por campo se busca la palabra analizada en la copia en español (acentos, mayúsculas y plurales),
+// el texto parcial (*palabra*) en la copia y, para documentos sin reindexar, en el campo original
+// Con lang también se busca la palabra en el título en ese idioma, con el analizador del idioma
+func buildSolrTextQuery(text, lang string) string {
+	words := strings.Fields(text)
+	clauses := make([]string, 0, len(words))
+	for _, word := range words {
+		escaped := escapeSolrQuery(word)
+		options := make([]string, 0, len(solrTextSearchFields)*3)
+		for _, field := range solrTextSearchFields {
+			options = append(options,
+				fmt.Sprintf("%s:%s", field.Folded, escaped),
+				fmt.Sprintf("%s:*%s*", field.Folded, escaped),
+				fmt.Sprintf("%s:*%s*", field.Source, escaped))
+		}
+		if lang != "" {
+			options = append(options, fmt.Sprintf("%s:%s", solrLanguageField("title", lang), escaped))
+		}
+		clauses = append(clauses, "+("+strings.Join(options, " OR ")+")")
+	}
+	if len(clauses) == 0 {
+		return "*:*"
+	}
+	return strings.Join(clauses, " ")
+}
+
+// solrLanguageField es el campo de texto de un idioma: los dynamic fields *_txt_<idioma> del configset
+// _default usan el analizador del idioma (text_en, text_pt...), así que no hace falta crearlos
+func solrLanguageField(field, language string) string {
+	return field + "_txt_" + language
+}
+
+// solrLanguageTexts arma los campos de título y descripción de cada idioma indexado que tiene la propiedad
+// (el idioma principal o una traducción)
+func solrLanguageTexts(property domain.Property) map[string]string {
+	texts := make(map[string]string)
+	for _, language := range domain.IndexedLanguages {
+		text, ok := property.TextIn(language)
+		if !ok {
+			continue
+		}
+		texts[solrLanguageField("title", language)] = text.Title
+		texts[solrLanguageField("description", language)] = text.Description
+	}
+	return texts
+}
+
+// solrDocTranslations lee con get las traducciones de los campos por idioma del documento (nil si no hay)
+// Se guardan por idioma, así que una traducción "pt-BR" vuelve como "pt"
+func solrDocTranslations(get func(field string) string, mainLanguage string) map[string]domain.Translation {
+	main, _, _ := strings.Cut(mainLanguage, "-")
+	if main == "" {
+		main = domain.DefaultLanguage
+	}
+
+	var translations map[string]domain.Translation
+	for _, language := range domain.IndexedLanguages {
+		title := get(solrLanguageField("title", language))
+		if language == main || title == "" {
+			continue
+		}
+		if translations == nil {
+			translations = make(map[string]domain.Translation)
+		}
+		translations[language] = domain.Translation{Title: title, Description: get(solrLanguageField("description", language))}
+	}
+	return translations
+}
@@ -0,0 +1,194 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// completeSolrSchema es un GET /schema con todo lo que search-api crea
+const completeSolrSchema = `{"schema":{
+	"fieldTypes":[{"name":"text_es_folded"},{"name":"date_range"}],
+	"fields":[{"name":"title_es"},{"name":"city_es"},{"name":"country_es"},{"name":"booked_ranges"}],
+	"copyFields":[{"source":"title","dest":"title_es"},{"source":"city","dest":"city_es"},{"source":"country","dest":"country_es"}]}}`
+
+// fakeSolrSchema responde la Schema API con schema y registra los cambios enviados
+type fakeSolrSchema struct {
+	mu           sync.Mutex
+	schema       string
+	updateStatus int
+	updateBody   string
+	gets         int
+	updates      []map[string][]json.RawMessage
+}
+
+func (f *fakeSolrSchema) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !strings.HasSuffix(r.URL.Path, "/schema") {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method == http.MethodGet {
+		f.gets++
+		w.Write([]byte(f.schema))
+		return
+	}
+
+	var commands map[string][]json.RawMessage
+	body, _ := io.ReadAll(r.Body)
+	json.Unmarshal(body, &commands)
+	f.updates = append(f.updates, commands)
+	if f.updateStatus != 0 {
+		w.WriteHeader(f.updateStatus)
+	}
+	w.Write([]byte(f.updateBody))
+}
+
+func newSchemaTestRepository(t *testing.T, solr *fakeSolrSchema) *solrRepository {
+	t.Helper()
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	server := httptest.NewServer(solr)
+	t.Cleanup(server.Close)
+	return NewSolrRepository(SolrOptions{URL: server.URL + "/solr/properties"}, server.Client()).(*solrRepository)
+}
+
+func TestMissingSchemaCommands(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   map[string]int
+	}{
+		{name: "new collection", schema: `{"schema":{}}`, want: map[string]int{"add-field-type": 2, "add-field": 4, "add-copy-field": 3}},
+		{name: "complete schema", schema: completeSolrSchema, want: map[string]int{}},
+		{
+			name: "only the copy of the city is missing",
+			schema: `{"schema":{
+				"fieldTypes":[{"name":"text_es_folded"},{"name":"date_range"}],
+				"fields":[{"name":"title_es"},{"name":"city_es"},{"name":"country_es"},{"name":"booked_ranges"}],
+				"copyFields":[{"source":"title","dest":"title_es"},{"source":"country","dest":"country_es"}]}}`,
+			want: map[string]int{"add-copy-field": 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var current solrSchemaResponse
+			if err := json.Unmarshal([]byte(tt.schema), &current); err != nil {
+				t.Fatalf("invalid schema: %v", err)
+			}
+
+			commands := missingSchemaCommands(current)
+			if len(commands) != len(tt.want) {
+				t.Fatalf("expected commands %v, got %v", tt.want, commands)
+			}
+			for command, count := range tt.want {
+				if len(commands[command]) != count {
+					t.Fatalf("expected %d %s, got %v", count, command, commands[command])
+				}
+			}
+		})
+	}
+}
+
+func TestEnsureSchema_CreatesWhatIsMissingOncePerCollection(t *testing.T) {
+	solr := &fakeSolrSchema{schema: `{"schema":{}}`}
+	repo := newSchemaTestRepository(t, solr)
+
+	for i := 0; i < 3; i++ {
+		if err := repo.ensureSchema(context.Background(), "properties"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if solr.gets != 1 || len(solr.updates) != 1 {
+		t.Fatalf("expected the schema checked and updated once, got %d gets and %d updates", solr.gets, len(solr.updates))
+	}
+	copyFields := solr.updates[0]["add-copy-field"]
+	if len(copyFields) != 3 || !strings.Contains(string(copyFields[0]), `"dest":"title_es"`) {
+		t.Fatalf("expected the copy fields to the folded fields, got %s", copyFields)
+	}
+}
+
+func TestEnsureSchema_CompleteSchemaIsNotUpdated(t *testing.T) {
+	solr := &fakeSolrSchema{schema: completeSolrSchema}
+	repo := newSchemaTestRepository(t, solr)
+
+	if err := repo.ensureSchema(context.Background(), "properties"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(solr.updates) != 0 {
+		t.Fatalf("expected no schema changes, got %v", solr.updates)
+	}
+}
+
+func TestEnsureSchema_UpdateFailures(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantErr    bool
+		wantChecks int
+	}{
+		// Otra instancia los creó al mismo tiempo
+		{name: "created concurrently", status: http.StatusBadRequest, body: `{"error":{"msg":"Field 'title_es' already exists."}}`, wantChecks: 1},
+		// No se marca como listo: se vuelve a intentar en la próxima operación
+		{name: "solr error", status: http.StatusInternalServerError, body: `{"error":{"msg":"boom"}}`, wantErr: true, wantChecks: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			solr := &fakeSolrSchema{schema: `{"schema":{}}`, updateStatus: tt.status, updateBody: tt.body}
+			repo := newSchemaTestRepository(t, solr)
+
+			err := repo.ensureSchema(context.Background(), "properties")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error=%v, got %v", tt.wantErr, err)
+			}
+			repo.ensureSchema(context.Background(), "properties")
+			if solr.gets != tt.wantChecks {
+				t.Fatalf("expected %d schema checks, got %d", tt.wantChecks, solr.gets)
+			}
+		})
+	}
+}
+
+func TestBuildSolrTextQuery(t *testing.T) {
+	tests := []struct {
+		name         string
+		text         string
+		lang         string
+		wantClauses  int
+		wantContains []string
+	}{
+		{name: "empty", text: "  ", wantClauses: 0},
+		{name: "every word is required", text: "depto Cordoba", wantClauses: 2, wantContains: []string{"title_es:depto", "city_es:*Cordoba*", "country:*Cordoba*"}},
+		{name: "translated title", text: "beach", lang: "en", wantClauses: 1, wantContains: []string{"title_txt_en:beach"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := buildSolrTextQuery(tt.text, tt.lang)
+			if tt.wantClauses == 0 {
+				if query != "*:*" {
+					t.Fatalf("expected *:*, got %q", query)
+				}
+				return
+			}
+			if clauses := strings.Count(query, "+("); clauses != tt.wantClauses {
+				t.Fatalf("expected %d required clauses, got %d in %q", tt.wantClauses, clauses, query)
+			}
+			for _, want := range tt.wantContains {
+				if !strings.Contains(query, want) {
+					t.Fatalf("expected %q in %q", want, query)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"search-api/domain"
+)
+
+// solrShadowInfix separa el nombre de la colección del sufijo de su sombra (ej: properties_reindex_20250101120000)
+const solrShadowInfix = "_reindex_"
+
+// solrShadow es el índice sombra de Solr: una colección nueva por cada colección configurada
+// En Solr standalone Swap intercambia los cores (SWAP de la Core Admin API) y descarga el anterior.
+// En SolrCloud la colección configurada pasa a ser un alias: Swap lo apunta a la sombra y elimina
+// la colección anterior. La primera vez la colección configurada es una colección real y hay que
+// eliminarla antes de crear el alias con su nombre: durante ese instante las búsquedas fallan,
+// así que esa migración solo se hace con SolrOptions.AliasMigration (si no, NewShadow la rechaza)
+type solrShadow struct {
+	repo  *solrRepository
+	cloud bool
+	name  string
+
+	// collections mapea cada colección configurada a su sombra, en el orden del router
+	logical     []string
+	collections map[string]string
+
+	// swapped son las colecciones configuradas que ya se reemplazaron (Discard no las toca)
+	mu      sync.Mutex
+	swapped map[string]bool
+}
+
+// NewShadow crea una colección sombra por cada colección configurada, con el mismo configset y esquema
+func (r *solrRepository) NewShadow(ctx context.Context) (ShadowIndex, error) {
+	_, cloud, err := listSolrCollections(ctx, r.router.baseURL, r.httpClient)
+	if err != nil {
+		return nil, err
+	}
+	// Se verifica antes de llenar la sombra: sin el alias la reindexación fallaría recién al reemplazar
+	if cloud && !r.options.AliasMigration {
+		aliases, err := listSolrAliases(ctx, r.router.baseURL, r.httpClient)
+		if err != nil {
+			return nil, err
+		}
+		var pending []string
+		for _, collection := range r.router.all() {
+			if aliases[collection] == "" {
+				pending = append(pending, collection)
+			}
+		}
+		if len(pending) > 0 {
+			return nil, aliasMigrationError(pending)
+		}
+	}
+
+	suffix := solrShadowInfix + time.Now().UTC().Format("20060102150405")
+	shadow := &solrShadow{
+		repo:        r,
+		cloud:       cloud,
+		name:        r.router.defaultCollection + suffix,
+		collections: make(map[string]string),
+		swapped:     make(map[string]bool),
+	}
+	for _, collection := range r.router.all() {
+		name := collection + suffix
+		if err := createSolrCollection(ctx, r.httpClient, r.router.baseURL, name, r.options, cloud); err != nil {
+			shadow.Discard(ctx)
+			return nil, err
+		}
+		shadow.logical = append(shadow.logical, collection)
+		shadow.collections[collection] = name
+
+		if err := r.ensureSchema(ctx, name); err != nil {
+			shadow.Discard(ctx)
+			return nil, err
+		}
+	}
+	log.Printf("🌑 Índice sombra de Solr creado: %s", strings.Join(shadow.shadowCollections(), ", "))
+	return shadow, nil
+}
+
+// Name retorna el nombre de la sombra de la colección por defecto
+func (s *solrShadow) Name() string {
+	return s.name
+}
+
+// shadowCollections retorna las colecciones sombra en el orden del router
+func (s *solrShadow) shadowCollections() []string {
+	collections := make([]string, 0, len(s.logical))
+	for _, collection := range s.logical {
+		collections = append(collections, s.collections[collection])
+	}
+	return collections
+}
+
+// IndexProperties agrupa el lote por colección (según el país) y lo indexa con un commit por colección
+func (s *solrShadow) IndexProperties(ctx context.Context, properties []domain.Property) error {
+	byCollection := make(map[string][]SolrProperty)
+	for _, property := range properties {
+		collection := s.collections[s.repo.router.forCountry(property.Country)]
+		byCollection[collection] = append(byCollection[collection], s.repo.propertyToSolr(property))
+	}
+
+	for collection, docs := range byCollection {
+		jsonData, err := json.Marshal(docs)
+		if err != nil {
+			return fmt.Errorf("error serializando propiedades a JSON: %w", err)
+		}
+
+		updateURL := s.repo.router.collectionURL(collection) + "/update"
+		req, err := http.NewRequestWithContext(ctx, "POST", updateURL, bytes.NewBuffer(jsonData))
+		if err != nil {
+			return fmt.Errorf("error creando request HTTP: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := s.repo.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("error realizando petición a Solr: %w", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("error indexando %d propiedades en '%s' (status %d): %s", len(docs), collection, resp.StatusCode, string(body))
+		}
+
+		if err := s.repo.commit(ctx, collection); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteProperties elimina las propiedades de todas las colecciones sombra
+func (s *solrShadow) DeleteProperties(ctx context.Context, propertyIDs []string) error {
+	if len(propertyIDs) == 0 {
+		return nil
+	}
+	escaped := make([]string, 0, len(propertyIDs))
+	for _, id := range propertyIDs {
+		escaped = append(escaped, escapeSolrQuery(id))
+	}
+	selector := map[string]string{"query": "id:(" + strings.Join(escaped, " OR ") + ")"}
+
+	for _, collection := range s.shadowCollections() {
+		if err := s.repo.deleteIn(ctx, collection, selector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListPropertyVersions lista las versiones de las colecciones sombra
+func (s *solrShadow) ListPropertyVersions(ctx context.Context) (map[string]time.Time, error) {
+	return s.repo.listVersionsIn(ctx, s.shadowCollections())
+}
+
+// Swap reemplaza cada colección configurada por su sombra
+// Si falla a mitad de camino las colecciones ya reemplazadas quedan así (cada una está completa)
+// y Discard elimina el resto de las sombras
+func (s *solrShadow) Swap(ctx context.Context) error {
+	var aliases map[string]string
+	if s.cloud {
+		var err error
+		if aliases, err = listSolrAliases(ctx, s.repo.router.baseURL, s.repo.httpClient); err != nil {
+			return err
+		}
+	}
+
+	for _, collection := range s.logical {
+		shadow := s.collections[collection]
+		if err := s.repo.commit(ctx, shadow); err != nil {
+			return err
+		}
+
+		var err error
+		if s.cloud {
+			err = s.swapAlias(ctx, collection, shadow, aliases[collection])
+		} else {
+			err = s.swapCore(ctx, collection, shadow)
+		}
+		if err != nil {
+			return err
+		}
+
+		s.mu.Lock()
+		s.swapped[collection] = true
+		s.mu.Unlock()
+		log.Printf("🔀 Colección '%s' de Solr reemplazada por '%s'", collection, shadow)
+	}
+	return nil
+}
+
+// swapCore intercambia el core configurado con el core sombra y descarga el anterior
+// Después del SWAP el nombre de la sombra apunta al índice anterior
+func (s *solrShadow) swapCore(ctx context.Context, collection, shadow string) error {
+	params := url.Values{}
+	params.Set("action", "SWAP")
+	params.Set("core", collection)
+	params.Set("other", shadow)
+	params.Set("wt", "json")
+	if _, err := solrAdminRequest(ctx, s.repo.httpClient, s.repo.router.baseURL+"/admin/cores?"+params.Encode()); err != nil {
+		return fmt.Errorf("error intercambiando el core '%s' con '%s': %w", collection, shadow, err)
+	}
+
+	if err := s.dropCollection(ctx, shadow); err != nil {
+		log.Printf("⚠️ El core anterior de '%s' quedó como '%s' y no se pudo eliminar: %v", collection, shadow, err)
+	}
+	return nil
+}
+
+// swapAlias apunta el alias de la colección configurada a la sombra y elimina la colección anterior
+// current es la colección a la que apunta el alias (vacío si la colección configurada no es un alias)
+func (s *solrShadow) swapAlias(ctx context.Context, collection, shadow, current string) error {
+	if current == "" {
+		// Primera reindexación: no puede haber un alias con el nombre de una colección existente
+		if !s.repo.options.AliasMigration {
+			return aliasMigrationError([]string{collection})
+		}
+		log.Printf("⚠️ '%s' es una colección y no un alias: se elimina para crear el alias (las búsquedas fallan por un instante)", collection)
+		if err := s.dropCollection(ctx, collection); err != nil {
+			return fmt.Errorf("error eliminando la colección '%s' para reemplazarla por un alias: %w", collection, err)
+		}
+	}
+
+	params := url.Values{}
+	params.Set("action", "CREATEALIAS")
+	params.Set("name", collection)
+	params.Set("collections", shadow)
+	params.Set("wt", "json")
+	if _, err := solrAdminRequest(ctx, s.repo.httpClient, s.repo.router.baseURL+"/admin/collections?"+params.Encode()); err != nil {
+		return fmt.Errorf("error apuntando el alias '%s' a '%s': %w", collection, shadow, err)
+	}
+
+	if current != "" && current != shadow {
+		if err := s.dropCollection(ctx, current); err != nil {
+			log.Printf("⚠️ La colección anterior '%s' de '%s' no se pudo eliminar: %v", current, collection, err)
+		}
+	}
+	return nil
+}
+
+// aliasMigrationError explica cómo migrar las colecciones configuradas a alias
+func aliasMigrationError(collections []string) error {
+	return fmt.Errorf("las colecciones %s de SolrCloud no son alias: para reindexar hay que eliminarlas y crear un alias con su nombre, "+
+		"y las búsquedas fallan mientras tanto. Reindexar una vez con SOLR_ALIAS_MIGRATION=true en una ventana de mantenimiento "+
+		"o crear los alias a mano (ver README)", strings.Join(collections, ", "))
+}
+
+// Discard elimina las colecciones sombra que no se llegaron a reemplazar
+func (s *solrShadow) Discard(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var failed []string
+	for _, collection := range s.logical {
+		if s.swapped[collection] {
+			continue
+		}
+		shadow := s.collections[collection]
+		if err := s.dropCollection(ctx, shadow); err != nil {
+			log.Printf("⚠️ Error eliminando la colección sombra '%s': %v", shadow, err)
+			failed = append(failed, shadow)
+			continue
+		}
+		log.Printf("🗑️ Colección sombra '%s' descartada", shadow)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("no se pudieron eliminar las colecciones sombra: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// dropCollection elimina una colección (SolrCloud) o descarga un core borrando sus datos (standalone)
+func (s *solrShadow) dropCollection(ctx context.Context, collection string) error {
+	params := url.Values{}
+	params.Set("wt", "json")
+	adminURL := s.repo.router.baseURL + "/admin/cores?"
+	if s.cloud {
+		params.Set("action", "DELETE")
+		params.Set("name", collection)
+		adminURL = s.repo.router.baseURL + "/admin/collections?"
+	} else {
+		params.Set("action", "UNLOAD")
+		params.Set("core", collection)
+		params.Set("deleteIndex", "true")
+		params.Set("deleteDataDir", "true")
+		params.Set("deleteInstanceDir", "true")
+	}
+	_, err := solrAdminRequest(ctx, s.repo.httpClient, adminURL+params.Encode())
+	return err
+}
@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeSolrCloud responde la Collections API de SolrCloud y registra las acciones de administración
+type fakeSolrCloud struct {
+	mu      sync.Mutex
+	aliases string
+	actions []string
+}
+
+func (f *fakeSolrCloud) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	action := r.URL.Query().Get("action")
+	switch action {
+	case "LIST":
+		w.Write([]byte(`{"collections":["properties"]}`))
+	case "LISTALIASES":
+		w.Write([]byte(f.aliases))
+	default:
+		f.actions = append(f.actions, action+" "+r.URL.Query().Get("name"))
+		w.Write([]byte(`{"responseHeader":{"status":0}}`))
+	}
+}
+
+func (f *fakeSolrCloud) recorded() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.actions...)
+}
+
+func TestSolrShadow_RefusesToDropACollectionWithoutAliasMigration(t *testing.T) {
+	solr := &fakeSolrCloud{aliases: `{"aliases":{}}`}
+	server := httptest.NewServer(solr)
+	defer server.Close()
+	repo := NewSolrRepository(SolrOptions{URL: server.URL + "/solr/properties"}, server.Client()).(*solrRepository)
+
+	// NewShadow falla antes de crear nada: la sombra no se llena para después no poder reemplazarla
+	if _, err := repo.NewShadow(context.Background()); err == nil || !strings.Contains(err.Error(), "SOLR_ALIAS_MIGRATION") {
+		t.Fatalf("expected an error explaining the alias migration, got %v", err)
+	}
+	if actions := solr.recorded(); len(actions) != 0 {
+		t.Fatalf("expected no collection to be created or deleted, got %v", actions)
+	}
+
+	// Swap tampoco elimina la colección si llega a encontrarla sin alias
+	shadow := &solrShadow{repo: repo, cloud: true, swapped: make(map[string]bool)}
+	if err := shadow.swapAlias(context.Background(), "properties", "properties_reindex_1", ""); err == nil {
+		t.Fatal("expected swapAlias to refuse dropping the configured collection")
+	}
+	if actions := solr.recorded(); len(actions) != 0 {
+		t.Fatalf("expected the configured collection to be kept, got %v", actions)
+	}
+}
+
+func TestSolrShadow_SwapAlias(t *testing.T) {
+	tests := []struct {
+		name           string
+		aliasMigration bool
+		current        string
+		want           []string
+	}{
+		{
+			name:           "migration drops the collection before creating the alias",
+			aliasMigration: true,
+			want:           []string{"DELETE properties", "CREATEALIAS properties"},
+		},
+		{
+			name:    "later swaps move the alias and drop the previous collection",
+			current: "properties_reindex_1",
+			want:    []string{"CREATEALIAS properties", "DELETE properties_reindex_1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			solr := &fakeSolrCloud{}
+			server := httptest.NewServer(solr)
+			defer server.Close()
+			repo := NewSolrRepository(SolrOptions{URL: server.URL + "/solr/properties", AliasMigration: tt.aliasMigration}, server.Client()).(*solrRepository)
+			shadow := &solrShadow{repo: repo, cloud: true, swapped: make(map[string]bool)}
+
+			if err := shadow.swapAlias(context.Background(), "properties", "properties_reindex_2", tt.current); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actions := solr.recorded(); strings.Join(actions, ";") != strings.Join(tt.want, ";") {
+				t.Fatalf("expected %v, got %v", tt.want, actions)
+			}
+		})
+	}
+}
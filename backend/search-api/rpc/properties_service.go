@@ -0,0 +1,92 @@
+package rpc
+
+import (
+	"context"
+
+	"search-api/dto"
+
+	"google.golang.org/grpc"
+)
+
+// PropertiesServiceName es el nombre del servicio gRPC que expone properties-api
+const PropertiesServiceName = "properties.v1.Properties"
+
+// GetPropertyRequest pide una propiedad por ID
+type GetPropertyRequest struct {
+	ID string `json:"id"`
+}
+
+// GetPropertyResponse contiene la propiedad con el mismo formato que GET /properties/:id de properties-api
+type GetPropertyResponse struct {
+	Property dto.PropertySnapshot `json:"property"`
+}
+
+// GetPropertiesRequest pide un lote de propiedades por ID (máximo 100 por llamada)
+type GetPropertiesRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// GetPropertiesResponse contiene las propiedades encontradas; los IDs inexistentes se omiten
+type GetPropertiesResponse struct {
+	Properties []dto.PropertySnapshot `json:"properties"`
+}
+
+// ListPropertyVersionsRequest pide una página de versiones de propiedades ordenada por ID
+type ListPropertyVersionsRequest struct {
+	// AfterID es el NextAfterID de la página anterior (vacío para la primera)
+	AfterID string `json:"afterId"`
+	Limit   int    `json:"limit"`
+}
+
+// PropertyVersion es el ID de una propiedad con su dueño y su fecha de actualización (RFC3339, UTC)
+type PropertyVersion struct {
+	ID        string `json:"id"`
+	OwnerID   string `json:"ownerId"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// ListPropertyVersionsResponse contiene la página de versiones; NextAfterID vacío indica que no hay más
+type ListPropertyVersionsResponse struct {
+	Versions    []PropertyVersion `json:"versions"`
+	NextAfterID string            `json:"nextAfterId"`
+}
+
+// PropertiesServiceClient es el stub del servicio gRPC de propiedades
+type PropertiesServiceClient interface {
+	GetProperty(ctx context.Context, request *GetPropertyRequest, opts ...grpc.CallOption) (*GetPropertyResponse, error)
+	GetProperties(ctx context.Context, request *GetPropertiesRequest, opts ...grpc.CallOption) (*GetPropertiesResponse, error)
+	ListPropertyVersions(ctx context.Context, request *ListPropertyVersionsRequest, opts ...grpc.CallOption) (*ListPropertyVersionsResponse, error)
+}
+
+type propertiesServiceClient struct {
+	conn grpc.ClientConnInterface
+}
+
+// NewPropertiesServiceClient crea el stub sobre una conexión gRPC (ver utils.NewGRPCConn)
+func NewPropertiesServiceClient(conn grpc.ClientConnInterface) PropertiesServiceClient {
+	return &propertiesServiceClient{conn: conn}
+}
+
+func (c *propertiesServiceClient) GetProperty(ctx context.Context, request *GetPropertyRequest, opts ...grpc.CallOption) (*GetPropertyResponse, error) {
+	response := new(GetPropertyResponse)
+	if err := c.conn.Invoke(ctx, "/"+PropertiesServiceName+"/GetProperty", request, response, opts...); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *propertiesServiceClient) GetProperties(ctx context.Context, request *GetPropertiesRequest, opts ...grpc.CallOption) (*GetPropertiesResponse, error) {
+	response := new(GetPropertiesResponse)
+	if err := c.conn.Invoke(ctx, "/"+PropertiesServiceName+"/GetProperties", request, response, opts...); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *propertiesServiceClient) ListPropertyVersions(ctx context.Context, request *ListPropertyVersionsRequest, opts ...grpc.CallOption) (*ListPropertyVersionsResponse, error) {
+	response := new(ListPropertyVersionsResponse)
+	if err := c.conn.Invoke(ctx, "/"+PropertiesServiceName+"/ListPropertyVersions", request, response, opts...); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
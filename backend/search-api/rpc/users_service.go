@@ -0,0 +1,91 @@
+package rpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// UsersServiceName es el nombre del servicio gRPC que expone users-api
+const UsersServiceName = "users.v1.Users"
+
+// GetSearchProfileRequest pide los favoritos y las preferencias de un usuario
+type GetSearchProfileRequest struct {
+	UserID uint `json:"userId"`
+}
+
+// GetSearchProfileResponse contiene los IDs de las propiedades favoritas y las preferencias del usuario
+type GetSearchProfileResponse struct {
+	Favorites         []string `json:"favorites"`
+	PreferredCurrency string   `json:"preferredCurrency"`
+	Locale            string   `json:"locale"`
+}
+
+// GetUsersRequest pide un lote de usuarios por ID (máximo 100 por llamada)
+type GetUsersRequest struct {
+	UserIDs []uint `json:"userIds"`
+}
+
+// UserStatus son los datos de un usuario que usa search-api (users-api envía más campos, se ignoran)
+type UserStatus struct {
+	ID     uint `json:"id"`
+	Active bool `json:"active"`
+}
+
+// GetUsersResponse contiene los usuarios encontrados; los IDs inexistentes se omiten
+type GetUsersResponse struct {
+	Users []UserStatus `json:"users"`
+}
+
+// VerifyTokenRequest pide a users-api validar el JWT de un usuario, incluida la lista de revocación
+type VerifyTokenRequest struct {
+	Token string `json:"token"`
+}
+
+// VerifyTokenResponse indica si el token es válido y no fue revocado y de qué usuario es
+type VerifyTokenResponse struct {
+	Valid    bool   `json:"valid"`
+	UserID   uint   `json:"userId,omitempty"`
+	Username string `json:"username,omitempty"`
+	UserType string `json:"userType,omitempty"`
+}
+
+// UsersServiceClient es el stub del servicio gRPC de usuarios (solo los métodos que usa search-api)
+type UsersServiceClient interface {
+	GetSearchProfile(ctx context.Context, request *GetSearchProfileRequest, opts ...grpc.CallOption) (*GetSearchProfileResponse, error)
+	GetUsers(ctx context.Context, request *GetUsersRequest, opts ...grpc.CallOption) (*GetUsersResponse, error)
+	VerifyToken(ctx context.Context, request *VerifyTokenRequest, opts ...grpc.CallOption) (*VerifyTokenResponse, error)
+}
+
+type usersServiceClient struct {
+	conn grpc.ClientConnInterface
+}
+
+// NewUsersServiceClient crea el stub sobre una conexión gRPC (ver utils.NewGRPCConn)
+func NewUsersServiceClient(conn grpc.ClientConnInterface) UsersServiceClient {
+	return &usersServiceClient{conn: conn}
+}
+
+func (c *usersServiceClient) GetSearchProfile(ctx context.Context, request *GetSearchProfileRequest, opts ...grpc.CallOption) (*GetSearchProfileResponse, error) {
+	response := new(GetSearchProfileResponse)
+	if err := c.conn.Invoke(ctx, "/"+UsersServiceName+"/GetSearchProfile", request, response, opts...); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *usersServiceClient) GetUsers(ctx context.Context, request *GetUsersRequest, opts ...grpc.CallOption) (*GetUsersResponse, error) {
+	response := new(GetUsersResponse)
+	if err := c.conn.Invoke(ctx, "/"+UsersServiceName+"/GetUsers", request, response, opts...); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *usersServiceClient) VerifyToken(ctx context.Context, request *VerifyTokenRequest, opts ...grpc.CallOption) (*VerifyTokenResponse, error) {
+	response := new(VerifyTokenResponse)
+	if err := c.conn.Invoke(ctx, "/"+UsersServiceName+"/VerifyToken", request, response, opts...); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
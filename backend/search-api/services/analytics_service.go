@@ -0,0 +1,426 @@
+package services
+
+import (
+	"context"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"search-api/domain"
+	"search-api/dto"
+	"search-api/repositories"
+)
+
+// AnalyticsService define la interfaz para registrar búsquedas y generar reportes
+type AnalyticsService interface {
+	// RecordSearch registra una búsqueda (con el searchId de la respuesta) con su cantidad de resultados y latencia
+	// Un error solo se loguea: no registrar la búsqueda no hace fallar la respuesta
+	RecordSearch(ctx context.Context, searchID string, request dto.SearchRequest, resultCount int, latency time.Duration)
+
+	// TopQueries retorna los términos más buscados en la ventana indicada
+	TopQueries(ctx context.Context, window time.Duration, limit int) (dto.QueryStatsResponse, error)
+
+	// ZeroResultQueries retorna las búsquedas (término + filtros) que no devolvieron resultados
+	ZeroResultQueries(ctx context.Context, window time.Duration, limit int) (dto.QueryStatsResponse, error)
+
+	// RecordClick registra un click en un resultado de búsqueda (un error solo se loguea)
+	RecordClick(ctx context.Context, event domain.ClickEvent)
+
+	// ClickThroughRate retorna el click-through rate, los clicks por posición y las propiedades más clickeadas
+	ClickThroughRate(ctx context.Context, window time.Duration, limit int) (dto.ClickStatsResponse, error)
+
+	// DailyActivity retorna las búsquedas y los clicks por día UTC de los últimos days días (incluye hoy)
+	DailyActivity(ctx context.Context, days int) (dto.DailyActivityResponse, error)
+
+	// EraseUser desvincula al usuario de los clicks registrados (las búsquedas no guardan el usuario)
+	EraseUser(ctx context.Context, userID uint) error
+}
+
+// analyticsService es la implementación concreta de AnalyticsService
+type analyticsService struct {
+	repo repositories.AnalyticsRepository
+}
+
+// NewAnalyticsService crea una nueva instancia del servicio de analytics
+func NewAnalyticsService(repo repositories.AnalyticsRepository) AnalyticsService {
+	return &analyticsService{
+		repo: repo,
+	}
+}
+
+// RecordSearch registra una búsqueda
+func (s *analyticsService) RecordSearch(ctx context.Context, searchID string, request dto.SearchRequest, resultCount int, latency time.Duration) {
+	err := s.repo.Record(ctx, domain.SearchEvent{
+		SearchID:    searchID,
+		Query:       normalizeQuery(request.Query),
+		Filters:     searchFilters(request),
+		ResultCount: resultCount,
+		Latency:     latency,
+		SearchedAt:  time.Now().UTC(),
+	})
+	if err != nil {
+		log.Printf("⚠️ No se pudo registrar la búsqueda para analytics: %v", err)
+	}
+}
+
+// TopQueries agrupa las búsquedas por término y las ordena por cantidad
+// Las búsquedas sin término (solo filtros) no se incluyen
+func (s *analyticsService) TopQueries(ctx context.Context, window time.Duration, limit int) (dto.QueryStatsResponse, error) {
+	to := time.Now().UTC()
+	from := to.Add(-window)
+	events, err := s.repo.ListSince(ctx, from)
+	if err != nil {
+		return dto.QueryStatsResponse{}, err
+	}
+
+	stats := aggregateQueries(events, func(event domain.SearchEvent) (string, bool) {
+		return event.Query, event.Query != ""
+	})
+	for i := range stats {
+		stats[i].Filters = nil
+	}
+
+	return dto.QueryStatsResponse{
+		From:          from,
+		To:            to,
+		TotalSearches: len(events),
+		Queries:       topStats(stats, limit),
+	}, nil
+}
+
+// ZeroResultQueries agrupa las búsquedas sin resultados por término y filtros
+// Los filtros se incluyen porque muchas veces el término existe pero no en esa ciudad o rango de precio
+func (s *analyticsService) ZeroResultQueries(ctx context.Context, window time.Duration, limit int) (dto.QueryStatsResponse, error) {
+	to := time.Now().UTC()
+	from := to.Add(-window)
+	events, err := s.repo.ListSince(ctx, from)
+	if err != nil {
+		return dto.QueryStatsResponse{}, err
+	}
+
+	stats := aggregateQueries(events, func(event domain.SearchEvent) (string, bool) {
+		return event.Query + "|" + filtersKey(event.Filters), event.ResultCount == 0
+	})
+
+	return dto.QueryStatsResponse{
+		From:          from,
+		To:            to,
+		TotalSearches: len(events),
+		Queries:       topStats(stats, limit),
+	}, nil
+}
+
+// RecordClick registra un click
+func (s *analyticsService) RecordClick(ctx context.Context, event domain.ClickEvent) {
+	event.ClickedAt = time.Now().UTC()
+	if err := s.repo.RecordClick(ctx, event); err != nil {
+		log.Printf("⚠️ No se pudo registrar el click para analytics: %v", err)
+	}
+}
+
+// ClickThroughRate une los clicks con las búsquedas de la ventana por searchId
+// Una búsqueda con varios clicks cuenta una sola vez como clickeada
+func (s *analyticsService) ClickThroughRate(ctx context.Context, window time.Duration, limit int) (dto.ClickStatsResponse, error) {
+	to := time.Now().UTC()
+	from := to.Add(-window)
+	searches, err := s.repo.ListSince(ctx, from)
+	if err != nil {
+		return dto.ClickStatsResponse{}, err
+	}
+	clicks, err := s.repo.ListClicksSince(ctx, from)
+	if err != nil {
+		return dto.ClickStatsResponse{}, err
+	}
+
+	searchIDs := make(map[string]bool, len(searches))
+	for _, search := range searches {
+		if search.SearchID != "" {
+			searchIDs[search.SearchID] = false
+		}
+	}
+
+	type propertyAccumulator struct {
+		clicks        int
+		totalPosition int
+	}
+	byPosition := make(map[int]int)
+	byProperty := make(map[string]*propertyAccumulator)
+	totalPosition := 0
+	for _, click := range clicks {
+		if _, ok := searchIDs[click.SearchID]; ok {
+			searchIDs[click.SearchID] = true
+		}
+		byPosition[click.Position]++
+		totalPosition += click.Position
+
+		acc, exists := byProperty[click.PropertyID]
+		if !exists {
+			acc = &propertyAccumulator{}
+			byProperty[click.PropertyID] = acc
+		}
+		acc.clicks++
+		acc.totalPosition += click.Position
+	}
+
+	response := dto.ClickStatsResponse{
+		From:          from,
+		To:            to,
+		TotalSearches: len(searches),
+		TotalClicks:   len(clicks),
+		Positions:     make([]dto.PositionClickStat, 0, len(byPosition)),
+		Properties:    make([]dto.PropertyClickStat, 0, len(byProperty)),
+	}
+	for _, clicked := range searchIDs {
+		if clicked {
+			response.ClickedSearches++
+		}
+	}
+	if response.TotalSearches > 0 {
+		response.ClickThroughRate = roundTo(float64(response.ClickedSearches)/float64(response.TotalSearches), 4)
+	}
+	if response.TotalClicks > 0 {
+		response.AvgClickPosition = roundTo(float64(totalPosition)/float64(response.TotalClicks), 2)
+	}
+
+	for position, count := range byPosition {
+		response.Positions = append(response.Positions, dto.PositionClickStat{Position: position, Clicks: count})
+	}
+	sort.Slice(response.Positions, func(i, j int) bool {
+		return response.Positions[i].Position < response.Positions[j].Position
+	})
+
+	for propertyID, acc := range byProperty {
+		response.Properties = append(response.Properties, dto.PropertyClickStat{
+			PropertyID:  propertyID,
+			Clicks:      acc.clicks,
+			AvgPosition: roundTo(float64(acc.totalPosition)/float64(acc.clicks), 2),
+		})
+	}
+	sort.Slice(response.Properties, func(i, j int) bool {
+		if response.Properties[i].Clicks != response.Properties[j].Clicks {
+			return response.Properties[i].Clicks > response.Properties[j].Clicks
+		}
+		return response.Properties[i].PropertyID < response.Properties[j].PropertyID
+	})
+	if limit > 0 && len(response.Properties) > limit {
+		response.Properties = response.Properties[:limit]
+	}
+	return response, nil
+}
+
+// DailyActivity agrupa las búsquedas y los clicks por día UTC
+// Una búsqueda cuenta como clickeada el día en que se buscó, aunque el click llegue al día siguiente
+func (s *analyticsService) DailyActivity(ctx context.Context, days int) (dto.DailyActivityResponse, error) {
+	to := time.Now().UTC()
+	from := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -(days - 1))
+	searches, err := s.repo.ListSince(ctx, from)
+	if err != nil {
+		return dto.DailyActivityResponse{}, err
+	}
+	clicks, err := s.repo.ListClicksSince(ctx, from)
+	if err != nil {
+		return dto.DailyActivityResponse{}, err
+	}
+	completeSince, err := s.repo.CompleteSince(ctx)
+	if err != nil {
+		return dto.DailyActivityResponse{}, err
+	}
+
+	response := dto.DailyActivityResponse{
+		From:          from,
+		To:            to,
+		CompleteSince: completeSince,
+		Days:          make([]dto.DailyActivity, 0, days),
+	}
+	byDate := make(map[string]*dto.DailyActivity, days)
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		response.Days = append(response.Days, dto.DailyActivity{Date: day.Format("2006-01-02")})
+	}
+	for i := range response.Days {
+		byDate[response.Days[i].Date] = &response.Days[i]
+	}
+
+	searchDates := make(map[string]string, len(searches))
+	for _, search := range searches {
+		activity, ok := byDate[search.SearchedAt.UTC().Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+		activity.Searches++
+		if search.ResultCount == 0 {
+			activity.ZeroResultSearches++
+		}
+		if search.SearchID != "" {
+			searchDates[search.SearchID] = activity.Date
+		}
+	}
+
+	clicked := make(map[string]bool)
+	for _, click := range clicks {
+		if activity, ok := byDate[click.ClickedAt.UTC().Format("2006-01-02")]; ok {
+			activity.Clicks++
+		}
+		date, ok := searchDates[click.SearchID]
+		if !ok || clicked[click.SearchID] {
+			continue
+		}
+		clicked[click.SearchID] = true
+		byDate[date].ClickedSearches++
+	}
+	return response, nil
+}
+
+// EraseUser desvincula al usuario de sus clicks: siguen contando en los reportes, pero sin el usuario
+func (s *analyticsService) EraseUser(ctx context.Context, userID uint) error {
+	return s.repo.EraseUser(ctx, userID)
+}
+
+// aggregateQueries agrupa los eventos según la key que devuelve keyFn
+// Los eventos para los que keyFn retorna false se ignoran
+func aggregateQueries(events []domain.SearchEvent, keyFn func(domain.SearchEvent) (string, bool)) []dto.QueryStat {
+	type accumulator struct {
+		stat         dto.QueryStat
+		totalResults int
+		totalLatency time.Duration
+	}
+
+	byKey := make(map[string]*accumulator)
+	var order []string
+
+	for _, event := range events {
+		key, ok := keyFn(event)
+		if !ok {
+			continue
+		}
+
+		acc, exists := byKey[key]
+		if !exists {
+			acc = &accumulator{stat: dto.QueryStat{Query: event.Query, Filters: event.Filters}}
+			byKey[key] = acc
+			order = append(order, key)
+		}
+
+		acc.stat.Count++
+		if event.ResultCount == 0 {
+			acc.stat.ZeroResultCount++
+		}
+		acc.totalResults += event.ResultCount
+		acc.totalLatency += event.Latency
+		if event.SearchedAt.After(acc.stat.LastSearchedAt) {
+			acc.stat.LastSearchedAt = event.SearchedAt
+		}
+	}
+
+	stats := make([]dto.QueryStat, 0, len(order))
+	for _, key := range order {
+		acc := byKey[key]
+		acc.stat.AvgResults = roundTo(float64(acc.totalResults)/float64(acc.stat.Count), 2)
+		acc.stat.AvgLatencyMs = roundTo(float64(acc.totalLatency.Microseconds())/1000/float64(acc.stat.Count), 2)
+		stats = append(stats, acc.stat)
+	}
+	return stats
+}
+
+// topStats ordena por cantidad de búsquedas (y por la más reciente en caso de empate) y recorta al límite
+func topStats(stats []dto.QueryStat, limit int) []dto.QueryStat {
+	sort.SliceStable(stats, func(i, j int) bool {
+		if stats[i].Count != stats[j].Count {
+			return stats[i].Count > stats[j].Count
+		}
+		return stats[i].LastSearchedAt.After(stats[j].LastSearchedAt)
+	})
+
+	if limit > 0 && len(stats) > limit {
+		stats = stats[:limit]
+	}
+	return stats
+}
+
+// normalizeQuery pasa el término a minúsculas y colapsa los espacios
+// para que "Casa  Playa" y "casa playa" cuenten como la misma búsqueda
+func normalizeQuery(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
+
+// searchFilters extrae los filtros aplicados en la búsqueda (sin paginación ni orden)
+func searchFilters(request dto.SearchRequest) map[string]string {
+	filters := make(map[string]string)
+
+	if city := strings.TrimSpace(request.City); city != "" {
+		filters["city"] = strings.ToLower(city)
+	}
+	if country := strings.TrimSpace(request.Country); country != "" {
+		filters["country"] = strings.ToLower(country)
+	}
+	if request.MinPrice > 0 {
+		filters["minPrice"] = strconv.FormatFloat(request.MinPrice, 'f', -1, 64)
+	}
+	if request.MaxPrice > 0 {
+		filters["maxPrice"] = strconv.FormatFloat(request.MaxPrice, 'f', -1, 64)
+	}
+	if request.Bedrooms > 0 {
+		filters["bedrooms"] = strconv.Itoa(request.Bedrooms)
+	}
+	if request.Bathrooms > 0 {
+		filters["bathrooms"] = strconv.Itoa(request.Bathrooms)
+	}
+	if request.MinGuests > 0 {
+		filters["minGuests"] = strconv.Itoa(request.MinGuests)
+	}
+	if request.ChildFriendly {
+		filters["childFriendly"] = "true"
+	}
+	if request.InfantFriendly {
+		filters["infantFriendly"] = "true"
+	}
+	if request.PetsAllowed {
+		filters["petsAllowed"] = "true"
+	}
+	if request.SmokingAllowed {
+		filters["smokingAllowed"] = "true"
+	}
+	if request.EventsAllowed {
+		filters["eventsAllowed"] = "true"
+	}
+	if request.InstantBook {
+		filters["instantBook"] = "true"
+	}
+	if request.CancellationPolicy != "" {
+		filters["cancellationPolicy"] = request.CancellationPolicy
+	}
+	if request.HasStayDates() {
+		filters["dates"] = "true"
+	}
+	if request.HasBoundingBox() {
+		filters["bbox"] = "true"
+	}
+
+	if len(filters) == 0 {
+		return nil
+	}
+	return filters
+}
+
+// filtersKey arma una key estable a partir de los filtros (ordenados por nombre)
+func filtersKey(filters map[string]string) string {
+	names := make([]string, 0, len(filters))
+	for name := range filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, name+"="+filters[name])
+	}
+	return strings.Join(parts, "&")
+}
+
+// roundTo redondea un valor a la cantidad de decimales indicada
+func roundTo(value float64, decimals int) float64 {
+	factor := math.Pow(10, float64(decimals))
+	return math.Round(value*factor) / factor
+}
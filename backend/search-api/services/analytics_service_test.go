@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"search-api/domain"
+	"search-api/dto"
+	"search-api/repositories"
+)
+
+func TestClickThroughRate_JoinsClicksWithSearches(t *testing.T) {
+	ctx := context.Background()
+	service := NewAnalyticsService(repositories.NewAnalyticsRepository(100))
+	searchIDs := []string{"s1", "s2", "s3", "s4"}
+	for _, searchID := range searchIDs {
+		service.RecordSearch(ctx, searchID, dto.SearchRequest{Query: "cabaña"}, 10, time.Millisecond)
+	}
+
+	// s1 tiene dos clicks (cuenta una vez), s3 uno y "old" es de una búsqueda que ya no está registrada
+	service.RecordClick(ctx, domain.ClickEvent{SearchID: "s1", PropertyID: "p1", Position: 1})
+	service.RecordClick(ctx, domain.ClickEvent{SearchID: "s1", PropertyID: "p2", Position: 3})
+	service.RecordClick(ctx, domain.ClickEvent{SearchID: "s3", PropertyID: "p1", Position: 2})
+	service.RecordClick(ctx, domain.ClickEvent{SearchID: "old", PropertyID: "p3", Position: 2})
+
+	stats, err := service.ClickThroughRate(ctx, 24*time.Hour, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.TotalSearches != 4 || stats.ClickedSearches != 2 || stats.ClickThroughRate != 0.5 {
+		t.Fatalf("expected 2 of 4 searches clicked (CTR 0.5), got %d of %d (%v)", stats.ClickedSearches, stats.TotalSearches, stats.ClickThroughRate)
+	}
+	if stats.TotalClicks != 4 || stats.AvgClickPosition != 2 {
+		t.Fatalf("expected 4 clicks at average position 2, got %d at %v", stats.TotalClicks, stats.AvgClickPosition)
+	}
+	expectedPositions := []dto.PositionClickStat{{Position: 1, Clicks: 1}, {Position: 2, Clicks: 2}, {Position: 3, Clicks: 1}}
+	if len(stats.Positions) != len(expectedPositions) {
+		t.Fatalf("expected clicks for positions 1-3, got %+v", stats.Positions)
+	}
+	for i, expected := range expectedPositions {
+		if stats.Positions[i] != expected {
+			t.Errorf("position %d: expected %+v, got %+v", i, expected, stats.Positions[i])
+		}
+	}
+	if len(stats.Properties) != 2 || stats.Properties[0] != (dto.PropertyClickStat{PropertyID: "p1", Clicks: 2, AvgPosition: 1.5}) {
+		t.Fatalf("expected p1 first with 2 clicks and the list cut at the limit, got %+v", stats.Properties)
+	}
+}
+
+func TestDailyActivity_CountsTodayAndReportsCompleteness(t *testing.T) {
+	ctx := context.Background()
+	repo := repositories.NewAnalyticsRepository(3)
+	service := NewAnalyticsService(repo)
+	service.RecordSearch(ctx, "s1", dto.SearchRequest{Query: "loft"}, 0, time.Millisecond)
+	service.RecordSearch(ctx, "s2", dto.SearchRequest{Query: "loft"}, 5, time.Millisecond)
+	service.RecordClick(ctx, domain.ClickEvent{SearchID: "s2", PropertyID: "p1", Position: 1})
+	service.RecordClick(ctx, domain.ClickEvent{SearchID: "s2", PropertyID: "p2", Position: 2})
+
+	activity, err := service.DailyActivity(ctx, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(activity.Days) != 3 {
+		t.Fatalf("expected one row per day including empty ones, got %+v", activity.Days)
+	}
+	today := activity.Days[2]
+	if today.Date != time.Now().UTC().Format("2006-01-02") {
+		t.Fatalf("expected the last row to be today, got %s", today.Date)
+	}
+	if today.Searches != 2 || today.ZeroResultSearches != 1 || today.ClickedSearches != 1 || today.Clicks != 2 {
+		t.Fatalf("unexpected activity for today: %+v", today)
+	}
+	if activity.Days[0].Searches != 0 || activity.Days[1].Searches != 0 {
+		t.Fatalf("expected no activity on previous days, got %+v", activity.Days)
+	}
+
+	// Con el buffer lleno la cobertura empieza en la búsqueda más antigua que quedó guardada
+	before := activity.CompleteSince
+	for _, searchID := range []string{"s3", "s4"} {
+		service.RecordSearch(ctx, searchID, dto.SearchRequest{}, 1, time.Millisecond)
+	}
+	completeSince, err := repo.CompleteSince(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !completeSince.After(before) {
+		t.Fatalf("expected CompleteSince to move forward once searches are dropped, still %v", completeSince)
+	}
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"log"
+
+	"search-api/dto"
+	"search-api/repositories"
+)
+
+// ErrCacheOperationUnsupported indica que el caché remoto no soporta la operación (ej: patrones en Memcached)
+var ErrCacheOperationUnsupported = repositories.ErrCacheOperationUnsupported
+
+// CacheService expone las métricas del caché de búsquedas y permite vaciarlo
+type CacheService interface {
+	// Stats retorna los hits/misses por nivel, la cantidad de entradas y los descartes
+	Stats() dto.CacheStats
+
+	// Flush vacía el caché local y el remoto
+	Flush() error
+
+	// Invalidate elimina de ambos niveles las keys que coinciden con el patrón glob (ej: "search:*")
+	Invalidate(pattern string) (int, error)
+
+	// TTL retorna el tiempo de vida restante de una key en cada nivel
+	TTL(key string) dto.CacheEntryTTL
+
+	// WriteMetrics escribe las métricas del caché en formato de texto de Prometheus
+	WriteMetrics(w io.Writer) error
+}
+
+// cacheService es la implementación concreta de CacheService
+type cacheService struct {
+	cacheRepo repositories.CacheRepository
+}
+
+// NewCacheService crea una nueva instancia del servicio de caché
+func NewCacheService(cacheRepo repositories.CacheRepository) CacheService {
+	return &cacheService{
+		cacheRepo: cacheRepo,
+	}
+}
+
+// Stats retorna los hits/misses por nivel, la cantidad de entradas y los descartes
+func (s *cacheService) Stats() dto.CacheStats {
+	return s.cacheRepo.Stats()
+}
+
+// Flush vacía el caché local y el remoto
+func (s *cacheService) Flush() error {
+	log.Println("🧹 Vaciando caché de búsquedas...")
+	if err := s.cacheRepo.Flush(); err != nil {
+		return err
+	}
+	log.Println("✅ Caché de búsquedas vaciado")
+	return nil
+}
+
+// Invalidate elimina de ambos niveles las keys que coinciden con el patrón glob
+// Con Memcached solo se limpia el caché local y se retorna ErrCacheOperationUnsupported
+func (s *cacheService) Invalidate(pattern string) (int, error) {
+	log.Printf("🧹 Invalidando keys del caché con patrón %s...", pattern)
+	return s.cacheRepo.DeletePattern(pattern)
+}
+
+// TTL retorna el tiempo de vida restante de una key en cada nivel
+func (s *cacheService) TTL(key string) dto.CacheEntryTTL {
+	return s.cacheRepo.TTL(key)
+}
+
+// cacheMetricSeries es una serie de una métrica del caché (labels vacío si no tiene)
+type cacheMetricSeries struct {
+	labels string
+	value  float64
+}
+
+// WriteMetrics escribe las métricas del caché en formato de texto de Prometheus
+// Las métricas del servidor remoto solo se incluyen si respondió
+func (s *cacheService) WriteMetrics(w io.Writer) error {
+	stats := s.cacheRepo.Stats()
+
+	items := []cacheMetricSeries{{`{level="local"}`, float64(stats.Local.Items)}}
+	evictions := []cacheMetricSeries{{`{level="local"}`, float64(stats.Local.Evictions)}}
+	remoteLabels := fmt.Sprintf(`{level=%q}`, stats.Backend)
+	if stats.Remote != nil {
+		items = append(items, cacheMetricSeries{remoteLabels, float64(stats.Remote.Items)})
+		evictions = append(evictions, cacheMetricSeries{remoteLabels, float64(stats.Remote.Evictions)})
+	}
+
+	metrics := []struct {
+		name   string
+		kind   string
+		help   string
+		series []cacheMetricSeries
+	}{
+		{"search_cache_hits_total", "counter", "Lecturas encontradas en el caché por nivel", []cacheMetricSeries{
+			{`{level="local"}`, float64(stats.LocalHits)},
+			{remoteLabels, float64(stats.RemoteHits)},
+		}},
+		{"search_cache_misses_total", "counter", "Lecturas que no se encontraron en ningún nivel", []cacheMetricSeries{{"", float64(stats.Misses)}}},
+		{"search_cache_hit_ratio", "gauge", "Proporción de lecturas encontradas en el caché", []cacheMetricSeries{{"", stats.HitRatio}}},
+		{"search_cache_sets_total", "counter", "Resultados guardados en el caché", []cacheMetricSeries{{"", float64(stats.Sets)}}},
+		{"search_cache_flushes_total", "counter", "Vaciados completos del caché", []cacheMetricSeries{{"", float64(stats.Flushes)}}},
+		{"search_cache_remote_errors_total", "counter", "Errores leyendo o escribiendo en el caché remoto", []cacheMetricSeries{{"", float64(stats.RemoteErrors)}}},
+		{"search_cache_items", "gauge", "Entradas en el caché por nivel", items},
+		{"search_cache_evictions_total", "counter", "Entradas descartadas por falta de espacio por nivel", evictions},
+	}
+
+	for _, metric := range metrics {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", metric.name, metric.help, metric.name, metric.kind); err != nil {
+			return err
+		}
+		for _, series := range metric.series {
+			if _, err := fmt.Fprintf(w, "%s%s %g\n", metric.name, series.labels, series.value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
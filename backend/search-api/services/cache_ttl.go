@@ -0,0 +1,68 @@
+package services
+
+import (
+	"time"
+
+	"search-api/dto"
+)
+
+// CacheTTLPolicy decide cuánto tiempo se cachea el resultado de cada búsqueda
+// Las búsquedas amplias se repiten mucho y se cachean más tiempo; las muy específicas
+// casi no se repiten y se cachean menos para no ocupar el caché
+type CacheTTLPolicy struct {
+	// Default es el TTL de las búsquedas que no son amplias ni específicas
+	Default time.Duration
+
+	// Broad es el TTL de las búsquedas sin texto y con a lo sumo un filtro en la primera página
+	Broad time.Duration
+
+	// Specific es el TTL de las búsquedas por mapa o con SpecificFilters filtros o más
+	Specific time.Duration
+
+	// SpecificFilters es la cantidad de filtros a partir de la cual una búsqueda es específica
+	SpecificFilters int
+}
+
+// TTLFor retorna el TTL del caché para el request
+func (p CacheTTLPolicy) TTLFor(request dto.SearchRequest) time.Duration {
+	filters := countSearchFilters(request)
+
+	switch {
+	case request.HasBoundingBox() || (p.SpecificFilters > 0 && filters >= p.SpecificFilters):
+		return p.Specific
+	case request.Query == "" && filters <= 1:
+		return p.Broad
+	default:
+		return p.Default
+	}
+}
+
+// countSearchFilters cuenta los criterios que acotan la búsqueda
+// El texto y las páginas siguientes a la primera también cuentan: se repiten menos
+func countSearchFilters(request dto.SearchRequest) int {
+	count := 0
+	for _, set := range []bool{
+		request.Query != "",
+		request.City != "",
+		request.Country != "",
+		request.Type != "",
+		request.MinPrice > 0 || request.MaxPrice > 0,
+		request.Bedrooms > 0,
+		request.Bathrooms > 0,
+		request.MinGuests > 0,
+		request.ChildFriendly,
+		request.InfantFriendly,
+		request.PetsAllowed,
+		request.SmokingAllowed,
+		request.EventsAllowed,
+		request.InstantBook,
+		request.CancellationPolicy != "",
+		request.HasStayDates(),
+		request.Page > 1,
+	} {
+		if set {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"resilience"
+	"search-api/dto"
+	"search-api/repositories"
+)
+
+// testCacheTTLPolicy usa un TTL distinto por categoría para distinguirlas
+var testCacheTTLPolicy = CacheTTLPolicy{Default: 5 * time.Minute, Broad: 30 * time.Minute, Specific: time.Minute, SpecificFilters: 4}
+
+func TestCacheTTLPolicy_TTLFor(t *testing.T) {
+	tests := []struct {
+		name    string
+		request dto.SearchRequest
+		want    time.Duration
+	}{
+		{name: "no filters", request: dto.SearchRequest{Page: 1}, want: 30 * time.Minute},
+		{name: "a single filter", request: dto.SearchRequest{City: "Córdoba", Page: 1}, want: 30 * time.Minute},
+		{name: "text search", request: dto.SearchRequest{Query: "cabaña", Page: 1}, want: 5 * time.Minute},
+		{name: "two filters", request: dto.SearchRequest{City: "Córdoba", MinGuests: 4, Page: 1}, want: 5 * time.Minute},
+		{name: "next pages count as a filter", request: dto.SearchRequest{City: "Córdoba", Page: 2}, want: 5 * time.Minute},
+		{name: "price range counts once", request: dto.SearchRequest{MinPrice: 10, MaxPrice: 100, Page: 1}, want: 30 * time.Minute},
+		{name: "specific search", request: dto.SearchRequest{Query: "cabaña", City: "Córdoba", Bedrooms: 2, PetsAllowed: true, Page: 1}, want: time.Minute},
+		{name: "map search", request: boundingBox(1, 1), want: time.Minute},
+		{name: "stay dates", request: dto.SearchRequest{City: "Córdoba", CheckIn: "2099-08-10", CheckOut: "2099-08-14", Page: 1}, want: 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := testCacheTTLPolicy.TTLFor(tt.request); got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCacheTTLPolicy_WithoutSpecificFilters(t *testing.T) {
+	// Con SpecificFilters en 0 solo las búsquedas por mapa son específicas
+	policy := testCacheTTLPolicy
+	policy.SpecificFilters = 0
+	request := dto.SearchRequest{Query: "cabaña", City: "Córdoba", Bedrooms: 2, PetsAllowed: true, InstantBook: true, Page: 1}
+	if got := policy.TTLFor(request); got != policy.Default {
+		t.Fatalf("expected the default TTL, got %v", got)
+	}
+}
+
+// ttlRecordingCache registra el TTL con el que se guardó cada key en el caché remoto
+type ttlRecordingCache struct {
+	*benchmarkRemoteCache
+	ttls map[string]time.Duration
+}
+
+func (c *ttlRecordingCache) Set(key string, value []byte, ttl time.Duration) error {
+	c.ttls[key] = ttl
+	return c.benchmarkRemoteCache.Set(key, value, ttl)
+}
+
+func TestSearch_CachesWithThePolicyTTL(t *testing.T) {
+	remote := &ttlRecordingCache{benchmarkRemoteCache: newBenchmarkRemoteCache(), ttls: make(map[string]time.Duration)}
+	service := NewSearchService(&benchmarkIndex{result: benchmarkSearchResult()}, repositories.NewCacheRepository(remote, time.Hour), nil,
+		resilience.CircuitBreakerSettings{FailureThreshold: 5, OpenTimeout: time.Second, HalfOpenMaxRequests: 1},
+		resilience.RetryPolicy{MaxAttempts: 1},
+		testCacheTTLPolicy,
+		nil,
+		0,
+	)
+
+	for _, request := range []dto.SearchRequest{{City: "Córdoba"}, {Query: "cabaña", City: "Córdoba", Bedrooms: 2, PetsAllowed: true}} {
+		if _, err := service.Search(context.Background(), request); err != nil {
+			t.Fatalf("search failed: %v", err)
+		}
+	}
+
+	got := make(map[time.Duration]int)
+	for _, ttl := range remote.ttls {
+		got[ttl]++
+	}
+	if len(remote.ttls) != 2 || got[30*time.Minute] != 1 || got[time.Minute] != 1 {
+		t.Fatalf("expected one broad (30m) and one specific (1m) entry, got %v", remote.ttls)
+	}
+}
@@ -0,0 +1,300 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"search-api/config"
+	"search-api/dto"
+)
+
+// defaultConsumerMonitorInterval se usa si CONSUMER_MONITOR_INTERVAL no es válido
+const defaultConsumerMonitorInterval = 15 * time.Second
+
+// ConsumerDeliveryStats son los contadores de entrega del consumidor de RabbitMQ
+type ConsumerDeliveryStats struct {
+	// Processed es la cantidad de mensajes procesados desde que arrancó el consumidor
+	Processed uint64
+
+	// LastDeliveryAt es cuándo se entregó el último mensaje (cero si todavía no llegó ninguno)
+	LastDeliveryAt time.Time
+
+	// LastLag es cuánto esperó en la queue el último mensaje entregado que traía timestamp de publicación
+	LastLag time.Duration
+}
+
+// ConsumerProbe es lo que el monitor lee del consumidor (lo implementa consumers.RabbitMQConsumer)
+type ConsumerProbe interface {
+	// QueueName retorna el nombre de la queue principal
+	QueueName() string
+
+	// InspectQueues consulta en RabbitMQ la queue principal (primera) y las colas de reintento
+	InspectQueues() ([]dto.ConsumerQueueStatus, error)
+
+	// DeliveryStats retorna los contadores de entrega y procesamiento
+	DeliveryStats() ConsumerDeliveryStats
+}
+
+// ConsumerMonitorService mide periódicamente el backlog del consumidor de RabbitMQ:
+// profundidad de las queues, demora del mensaje más viejo y throughput, y alerta cuando superan los umbrales
+type ConsumerMonitorService interface {
+	// Attach empieza a medir el consumidor (se llama cuando termina de conectarse a RabbitMQ)
+	Attach(probe ConsumerProbe)
+
+	// Start mide cada CONSUMER_MONITOR_INTERVAL hasta que se llama a Stop
+	Start()
+
+	// Stop detiene las mediciones
+	Stop()
+
+	// Status retorna la última medición
+	Status() dto.ConsumerStatus
+
+	// WriteMetrics escribe la última medición en formato de texto de Prometheus
+	WriteMetrics(w io.Writer) error
+}
+
+// consumerMonitorService es la implementación concreta de ConsumerMonitorService
+type consumerMonitorService struct {
+	settings config.ConsumerConfig
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu            sync.Mutex
+	probe         ConsumerProbe
+	status        dto.ConsumerStatus
+	prevProcessed uint64
+	prevDepth     int
+	prevAt        time.Time
+	alerting      map[string]bool
+}
+
+// NewConsumerMonitorService crea el monitor con los umbrales de alerta de settings
+func NewConsumerMonitorService(settings config.ConsumerConfig) ConsumerMonitorService {
+	if settings.MonitorInterval <= 0 {
+		settings.MonitorInterval = defaultConsumerMonitorInterval
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &consumerMonitorService{
+		settings: settings,
+		ctx:      ctx,
+		cancel:   cancel,
+		status:   dto.ConsumerStatus{Queues: []dto.ConsumerQueueStatus{}, Alerts: []string{}},
+		alerting: make(map[string]bool),
+	}
+}
+
+// Attach empieza a medir el consumidor y toma la primera medición
+func (s *consumerMonitorService) Attach(probe ConsumerProbe) {
+	s.mu.Lock()
+	s.probe = probe
+	s.prevAt = time.Time{}
+	s.mu.Unlock()
+	s.check()
+}
+
+// Start mide periódicamente el backlog
+func (s *consumerMonitorService) Start() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.settings.MonitorInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+				s.check()
+			}
+		}
+	}()
+	log.Printf("📈 Monitor del consumidor midiendo cada %v (alertas: %d mensajes, %v de demora)",
+		s.settings.MonitorInterval, s.settings.AlertQueueDepth, s.settings.AlertLag)
+}
+
+// Stop detiene las mediciones
+func (s *consumerMonitorService) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// check mide las queues y actualiza el estado y las alertas
+func (s *consumerMonitorService) check() {
+	s.mu.Lock()
+	probe := s.probe
+	s.mu.Unlock()
+	if probe == nil {
+		return
+	}
+
+	// RabbitMQ se consulta fuera del lock para no frenar /metrics ni el endpoint de estado
+	queues, inspectErr := probe.InspectQueues()
+	stats := probe.DeliveryStats()
+	now := time.Now().UTC()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := dto.ConsumerStatus{
+		Connected:       true,
+		CheckedAt:       now,
+		Queue:           probe.QueueName(),
+		Queues:          []dto.ConsumerQueueStatus{},
+		ProcessedTotal:  stats.Processed,
+		Alerts:          []string{},
+		AlertQueueDepth: s.settings.AlertQueueDepth,
+		AlertLagSeconds: s.settings.AlertLag.Seconds(),
+	}
+	if inspectErr != nil {
+		// Se conservan las profundidades anteriores para no apagar una alerta por una falla puntual
+		log.Printf("⚠️ No se pudo consultar la queue del consumidor: %v", inspectErr)
+		status.Error = inspectErr.Error()
+		status.Queues = s.status.Queues
+		status.QueueDepth = s.status.QueueDepth
+		status.RetryDepth = s.status.RetryDepth
+	} else {
+		status.Queues = queues
+		for i, queue := range queues {
+			if i == 0 {
+				status.QueueDepth = queue.Messages
+			} else {
+				status.RetryDepth += queue.Messages
+			}
+		}
+	}
+
+	if !stats.LastDeliveryAt.IsZero() {
+		lastDeliveryAt := stats.LastDeliveryAt
+		status.LastDeliveryAt = &lastDeliveryAt
+		status.LastDeliveryLagSeconds = roundTo(stats.LastLag.Seconds(), 3)
+
+		// Los mensajes salen en orden: el que sigue en la queue llegó después del último entregado,
+		// así que espera como mucho lo que esperó ese más el tiempo que pasó desde la entrega.
+		// Si todavía no se entregó ningún mensaje no hay con qué estimarlo
+		if status.QueueDepth > 0 {
+			status.LagSeconds = roundTo((stats.LastLag + now.Sub(stats.LastDeliveryAt)).Seconds(), 3)
+		}
+	}
+
+	if !s.prevAt.IsZero() {
+		elapsed := now.Sub(s.prevAt).Seconds()
+		if elapsed > 0 {
+			status.ProcessedPerSecond = roundTo(float64(stats.Processed-s.prevProcessed)/elapsed, 3)
+			if drained := float64(s.prevDepth-status.QueueDepth) / elapsed; drained > 0 && status.QueueDepth > 0 {
+				status.DrainSeconds = roundTo(float64(status.QueueDepth)/drained, 1)
+			}
+		}
+	}
+	s.prevAt = now
+	s.prevProcessed = stats.Processed
+	s.prevDepth = status.QueueDepth
+
+	if s.settings.AlertQueueDepth > 0 && status.QueueDepth >= s.settings.AlertQueueDepth {
+		status.Alerts = append(status.Alerts, dto.ConsumerAlertQueueDepth)
+	}
+	if s.settings.AlertLag > 0 && status.LagSeconds >= s.settings.AlertLag.Seconds() {
+		status.Alerts = append(status.Alerts, dto.ConsumerAlertLag)
+	}
+	s.logAlertChanges(status)
+	s.status = status
+}
+
+// logAlertChanges loguea cuando se supera un umbral y cuando vuelve a la normalidad (no en cada medición)
+// El caller tiene el lock
+func (s *consumerMonitorService) logAlertChanges(status dto.ConsumerStatus) {
+	active := make(map[string]bool, len(status.Alerts))
+	for _, alert := range status.Alerts {
+		active[alert] = true
+		if s.alerting[alert] {
+			continue
+		}
+		switch alert {
+		case dto.ConsumerAlertQueueDepth:
+			log.Printf("🚨 Backlog del consumidor: %d mensajes esperando en '%s' (umbral %d)", status.QueueDepth, status.Queue, s.settings.AlertQueueDepth)
+		case dto.ConsumerAlertLag:
+			log.Printf("🚨 Demora del consumidor: el mensaje más viejo de '%s' espera hace %.0fs (umbral %v)", status.Queue, status.LagSeconds, s.settings.AlertLag)
+		}
+	}
+	for alert := range s.alerting {
+		if !active[alert] {
+			log.Printf("✅ Alerta '%s' del consumidor resuelta (%d mensajes, %.0fs de demora)", alert, status.QueueDepth, status.LagSeconds)
+		}
+	}
+	s.alerting = active
+}
+
+// Status retorna una copia de la última medición
+func (s *consumerMonitorService) Status() dto.ConsumerStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := s.status
+	status.Queues = append([]dto.ConsumerQueueStatus{}, s.status.Queues...)
+	status.Alerts = append([]string{}, s.status.Alerts...)
+	return status
+}
+
+// WriteMetrics escribe la última medición en formato de texto de Prometheus
+// consumer_backlog_alert queda en 1 mientras se supera el umbral, para usarla en las reglas de alerta
+func (s *consumerMonitorService) WriteMetrics(w io.Writer) error {
+	status := s.Status()
+	if !status.Connected {
+		return nil
+	}
+
+	var depth, consumers []cacheMetricSeries
+	for _, queue := range status.Queues {
+		labels := fmt.Sprintf(`{queue=%q}`, queue.Name)
+		depth = append(depth, cacheMetricSeries{labels, float64(queue.Messages)})
+		consumers = append(consumers, cacheMetricSeries{labels, float64(queue.Consumers)})
+	}
+	var alerts []cacheMetricSeries
+	for _, reason := range []string{dto.ConsumerAlertQueueDepth, dto.ConsumerAlertLag} {
+		active := false
+		for _, alert := range status.Alerts {
+			active = active || alert == reason
+		}
+		alerts = append(alerts, cacheMetricSeries{fmt.Sprintf(`{reason=%q}`, reason), boolMetric(active)})
+	}
+
+	metrics := []struct {
+		name   string
+		help   string
+		series []cacheMetricSeries
+	}{
+		{"consumer_queue_messages", "Mensajes esperando en cada queue de RabbitMQ", depth},
+		{"consumer_queue_consumers", "Consumidores de cada queue de RabbitMQ", consumers},
+		{"consumer_lag_seconds", "Antigüedad estimada del mensaje más viejo de la queue principal", []cacheMetricSeries{{"", status.LagSeconds}}},
+		{"consumer_last_delivery_lag_seconds", "Tiempo que esperó en la queue el último mensaje entregado", []cacheMetricSeries{{"", status.LastDeliveryLagSeconds}}},
+		{"consumer_processed_per_second", "Mensajes procesados por segundo desde la medición anterior", []cacheMetricSeries{{"", status.ProcessedPerSecond}}},
+		{"consumer_drain_seconds", "Tiempo estimado para vaciar la queue principal (0 si está vacía o no baja)", []cacheMetricSeries{{"", status.DrainSeconds}}},
+		{"consumer_backlog_alert", "1 si el backlog supera el umbral de alerta", alerts},
+		{"consumer_monitor_up", "1 si la última medición pudo consultar RabbitMQ", []cacheMetricSeries{{"", boolMetric(status.Error == "")}}},
+	}
+	for _, metric := range metrics {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", metric.name, metric.help, metric.name); err != nil {
+			return err
+		}
+		for _, series := range metric.series {
+			if _, err := fmt.Fprintf(w, "%s%s %g\n", metric.name, series.labels, series.value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// boolMetric convierte un booleano al valor de una métrica (1 o 0)
+func boolMetric(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"search-api/config"
+	"search-api/dto"
+)
+
+// fakeConsumerProbe devuelve la profundidad y los contadores que arma cada test
+type fakeConsumerProbe struct {
+	depth int
+	err   error
+	stats ConsumerDeliveryStats
+}
+
+func (p *fakeConsumerProbe) QueueName() string {
+	return "property_events"
+}
+
+func (p *fakeConsumerProbe) InspectQueues() ([]dto.ConsumerQueueStatus, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return []dto.ConsumerQueueStatus{
+		{Name: "property_events", Messages: p.depth, Consumers: 2},
+		{Name: "property_events.retry.30s", Messages: 3},
+	}, nil
+}
+
+func (p *fakeConsumerProbe) DeliveryStats() ConsumerDeliveryStats {
+	return p.stats
+}
+
+func TestConsumerMonitor_LagThroughputAndAlerts(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	monitor := NewConsumerMonitorService(config.ConsumerConfig{AlertQueueDepth: 1000, AlertLag: 5 * time.Minute})
+	if monitor.Status().Connected {
+		t.Fatal("expected status to be disconnected before attaching the consumer")
+	}
+
+	// El último mensaje esperó 4 minutos y se entregó hace 2: el siguiente espera hace unos 6
+	probe := &fakeConsumerProbe{depth: 1500, stats: ConsumerDeliveryStats{
+		Processed:      100,
+		LastDeliveryAt: time.Now().Add(-2 * time.Minute),
+		LastLag:        4 * time.Minute,
+	}}
+	monitor.Attach(probe)
+
+	status := monitor.Status()
+	if !status.Connected || status.QueueDepth != 1500 || status.RetryDepth != 3 {
+		t.Fatalf("unexpected depths: %+v", status)
+	}
+	if status.LagSeconds < 359 || status.LagSeconds > 370 {
+		t.Errorf("expected lag of about 360s, got %v", status.LagSeconds)
+	}
+	if strings.Join(status.Alerts, ",") != "queue_depth,lag" {
+		t.Errorf("expected queue_depth and lag alerts, got %v", status.Alerts)
+	}
+
+	// La queue se vacía y se procesan mensajes: sin demora ni alertas
+	probe.depth = 0
+	probe.stats.Processed = 400
+	probe.stats.LastDeliveryAt = time.Now()
+	probe.stats.LastLag = time.Second
+	monitor.(*consumerMonitorService).check()
+
+	status = monitor.Status()
+	if status.LagSeconds != 0 || len(status.Alerts) != 0 {
+		t.Errorf("expected no lag nor alerts with an empty queue, got %+v", status)
+	}
+	if status.ProcessedPerSecond <= 0 || status.ProcessedTotal != 400 {
+		t.Errorf("expected throughput from the processed delta, got %+v", status)
+	}
+
+	// Si RabbitMQ no responde se conserva la última profundidad y se reporta el error
+	probe.depth = 2000
+	monitor.(*consumerMonitorService).check()
+	probe.err = errors.New("channel closed")
+	monitor.(*consumerMonitorService).check()
+	status = monitor.Status()
+	if status.QueueDepth != 2000 || status.Error == "" || len(status.Alerts) == 0 {
+		t.Errorf("expected previous depth, error and alert on a failed inspection, got %+v", status)
+	}
+
+	var metrics bytes.Buffer
+	if err := monitor.WriteMetrics(&metrics); err != nil {
+		t.Fatalf("WriteMetrics failed: %v", err)
+	}
+	for _, line := range []string{
+		`consumer_queue_messages{queue="property_events"} 2000`,
+		`consumer_backlog_alert{reason="queue_depth"} 1`,
+		`consumer_backlog_alert{reason="lag"} 0`,
+		"consumer_monitor_up 0",
+	} {
+		if !strings.Contains(metrics.String(), line) {
+			t.Errorf("expected metric %q in:\n%s", line, metrics.String())
+		}
+	}
+}
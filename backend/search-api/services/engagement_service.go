@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"search-api/domain"
+	"search-api/repositories"
+)
+
+// ErrInvalidEngagementType se retorna cuando el tipo de interacción no es click ni booking
+var ErrInvalidEngagementType = errors.New("tipo de interacción inválido")
+
+// engagementWeights es cuánto pesa cada tipo de interacción: reservar dice más que hacer click
+var engagementWeights = map[string]float64{
+	domain.EngagementClick:   0.5,
+	domain.EngagementBooking: 1,
+}
+
+// personalizedRerankWeight es el peso de la afinidad con el usuario frente al orden original del índice
+const personalizedRerankWeight = 0.5
+
+// EngagementService define la interfaz de las interacciones de los usuarios y el ranking personalizado
+type EngagementService interface {
+	// Record registra una interacción del usuario con una propiedad indexada
+	Record(ctx context.Context, userID uint, propertyID, engagementType string) error
+
+	// Rerank reordena los resultados hacia propiedades parecidas a las que el usuario clickeó o reservó
+	// Retorna false (y los resultados sin cambios) si el usuario no tiene interacciones o no se pudieron leer
+	Rerank(ctx context.Context, userID uint, results []domain.Property) ([]domain.Property, bool)
+
+	// Clear borra las interacciones del usuario
+	Clear(ctx context.Context, userID uint) error
+}
+
+// engagementService es la implementación concreta de EngagementService
+type engagementService struct {
+	repo     repositories.EngagementRepository
+	index    repositories.SearchIndex
+	halfLife time.Duration
+}
+
+// NewEngagementService crea el servicio de interacciones
+// halfLife es el tiempo en que una interacción pasa a pesar la mitad (0 = no pierden peso)
+func NewEngagementService(repo repositories.EngagementRepository, index repositories.SearchIndex, halfLife time.Duration) EngagementService {
+	return &engagementService{
+		repo:     repo,
+		index:    index,
+		halfLife: halfLife,
+	}
+}
+
+// Record busca la propiedad en el índice y guarda la interacción con sus atributos
+func (s *engagementService) Record(ctx context.Context, userID uint, propertyID, engagementType string) error {
+	if _, ok := engagementWeights[engagementType]; !ok {
+		return fmt.Errorf("%w: '%s'", ErrInvalidEngagementType, engagementType)
+	}
+
+	property, err := s.index.GetProperty(ctx, propertyID)
+	if errors.Is(err, repositories.ErrPropertyNotIndexed) {
+		return ErrPropertyNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("error obteniendo la propiedad del índice: %w", err)
+	}
+
+	return s.repo.Add(ctx, userID, domain.Engagement{
+		Type:       engagementType,
+		Property:   property,
+		OccurredAt: time.Now().UTC(),
+	})
+}
+
+// Rerank combina la posición de cada resultado con su afinidad con las interacciones del usuario
+// Solo reordena la página recibida: la paginación y el total no cambian
+func (s *engagementService) Rerank(ctx context.Context, userID uint, results []domain.Property) ([]domain.Property, bool) {
+	engagements, err := s.repo.List(ctx, userID)
+	if err != nil {
+		log.Printf("⚠️ No se pudieron leer las interacciones del usuario %d, se usa el orden del índice: %v", userID, err)
+		return results, false
+	}
+	if len(engagements) == 0 || len(results) < 2 {
+		return results, false
+	}
+
+	type scored struct {
+		property domain.Property
+		score    float64
+	}
+
+	now := time.Now()
+	ranked := make([]scored, 0, len(results))
+	for i, result := range results {
+		positionScore := 1 - float64(i)/float64(len(results))
+		affinity := 0.0
+		for _, engagement := range engagements {
+			weight := engagementWeights[engagement.Type] * s.decay(now.Sub(engagement.OccurredAt))
+			affinity = math.Max(affinity, weight*attributeSimilarity(engagement.Property, result))
+		}
+		score := (1-personalizedRerankWeight)*positionScore + personalizedRerankWeight*affinity
+		ranked = append(ranked, scored{property: result, score: score})
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].score > ranked[j].score
+	})
+
+	reranked := make([]domain.Property, 0, len(ranked))
+	for _, entry := range ranked {
+		reranked = append(reranked, entry.property)
+	}
+	return reranked, true
+}
+
+// Clear borra las interacciones del usuario
+func (s *engagementService) Clear(ctx context.Context, userID uint) error {
+	return s.repo.Clear(ctx, userID)
+}
+
+// decay es el peso de una interacción según su antigüedad (1 = recién ocurrida)
+func (s *engagementService) decay(age time.Duration) float64 {
+	if s.halfLife <= 0 || age <= 0 {
+		return 1
+	}
+	return math.Pow(0.5, float64(age)/float64(s.halfLife))
+}
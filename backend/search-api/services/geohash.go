@@ -0,0 +1,111 @@
+package services
+
+import (
+	"sort"
+
+	"search-api/domain"
+	"search-api/dto"
+)
+
+// geohashAlphabet es el alfabeto base32 usado por geohash
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// encodeGeohash codifica coordenadas en un geohash de la precisión indicada
+func encodeGeohash(latitude, longitude float64, precision int) string {
+	latRange := [2]float64{-90, 90}
+	lngRange := [2]float64{-180, 180}
+
+	hash := make([]byte, 0, precision)
+	bit, ch := 0, 0
+	evenBit := true
+
+	for len(hash) < precision {
+		if evenBit {
+			mid := (lngRange[0] + lngRange[1]) / 2
+			if longitude >= mid {
+				ch = ch<<1 | 1
+				lngRange[0] = mid
+			} else {
+				ch <<= 1
+				lngRange[1] = mid
+			}
+		} else {
+			mid := (latRange[0] + latRange[1]) / 2
+			if latitude >= mid {
+				ch = ch<<1 | 1
+				latRange[0] = mid
+			} else {
+				ch <<= 1
+				latRange[1] = mid
+			}
+		}
+		evenBit = !evenBit
+
+		bit++
+		if bit == 5 {
+			hash = append(hash, geohashAlphabet[ch])
+			bit, ch = 0, 0
+		}
+	}
+
+	return string(hash)
+}
+
+// geohashPrecisionForBoundingBox elige la precisión del geohash según el tamaño del mapa
+// Mapas más amplios agrupan en celdas más grandes
+func geohashPrecisionForBoundingBox(request dto.SearchRequest) int {
+	span := *request.BboxMaxLat - *request.BboxMinLat
+	if lngSpan := *request.BboxMaxLng - *request.BboxMinLng; lngSpan > span {
+		span = lngSpan
+	}
+
+	switch {
+	case span > 45:
+		return 2
+	case span > 5:
+		return 3
+	case span > 1:
+		return 4
+	case span > 0.2:
+		return 5
+	case span > 0.05:
+		return 6
+	default:
+		return 7
+	}
+}
+
+// clusterByGeohash agrupa las propiedades con coordenadas por celda de geohash
+func clusterByGeohash(properties []domain.Property, request dto.SearchRequest) []dto.GeoCluster {
+	precision := geohashPrecisionForBoundingBox(request)
+	clusters := make(map[string]*dto.GeoCluster)
+
+	for _, property := range properties {
+		if property.Latitude == 0 && property.Longitude == 0 {
+			continue
+		}
+
+		hash := encodeGeohash(property.Latitude, property.Longitude, precision)
+		cluster, exists := clusters[hash]
+		if !exists {
+			cluster = &dto.GeoCluster{Geohash: hash}
+			clusters[hash] = cluster
+		}
+
+		// Promedio incremental del centro de la celda
+		cluster.Count++
+		cluster.Latitude += (property.Latitude - cluster.Latitude) / float64(cluster.Count)
+		cluster.Longitude += (property.Longitude - cluster.Longitude) / float64(cluster.Count)
+		cluster.PropertyIDs = append(cluster.PropertyIDs, property.ID)
+	}
+
+	result := make([]dto.GeoCluster, 0, len(clusters))
+	for _, cluster := range clusters {
+		result = append(result, *cluster)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Geohash < result[j].Geohash
+	})
+
+	return result
+}
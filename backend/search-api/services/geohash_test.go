@@ -0,0 +1,111 @@
+package services
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"search-api/domain"
+	"search-api/dto"
+)
+
+func TestEncodeGeohash_KnownVectors(t *testing.T) {
+	tests := []struct {
+		latitude, longitude float64
+		precision           int
+		want                string
+	}{
+		// Vectores publicados de la especificación de geohash
+		{latitude: 57.64911, longitude: 10.40744, precision: 11, want: "u4pruydqqvj"},
+		{latitude: 42.6, longitude: -5.6, precision: 5, want: "ezs42"},
+		// Un prefijo más corto es la celda que contiene a la más precisa
+		{latitude: 57.64911, longitude: 10.40744, precision: 3, want: "u4p"},
+		{latitude: -31.4201, longitude: -64.1888, precision: 7, want: "6d6m725"},
+		{latitude: -34.6037, longitude: -58.3816, precision: 7, want: "69y7pkx"},
+		// Bordes del mapa
+		{latitude: 0, longitude: 0, precision: 4, want: "s000"},
+		{latitude: 90, longitude: 180, precision: 3, want: "zzz"},
+		{latitude: -90, longitude: -180, precision: 3, want: "000"},
+		{latitude: 10, longitude: 10, precision: 0, want: ""},
+	}
+	for _, tt := range tests {
+		if got := encodeGeohash(tt.latitude, tt.longitude, tt.precision); got != tt.want {
+			t.Errorf("encodeGeohash(%v, %v, %d): expected %q, got %q", tt.latitude, tt.longitude, tt.precision, tt.want, got)
+		}
+	}
+}
+
+// boundingBox arma un request con un bounding box de latSpan × lngSpan grados desde Córdoba
+func boundingBox(latSpan, lngSpan float64) dto.SearchRequest {
+	minLat, minLng := -31.4, -64.2
+	maxLat, maxLng := minLat+latSpan, minLng+lngSpan
+	return dto.SearchRequest{BboxMinLat: &minLat, BboxMinLng: &minLng, BboxMaxLat: &maxLat, BboxMaxLng: &maxLng}
+}
+
+func TestGeohashPrecisionForBoundingBox(t *testing.T) {
+	tests := []struct {
+		name             string
+		latSpan, lngSpan float64
+		want             int
+	}{
+		{name: "continent", latSpan: 60, lngSpan: 10, want: 2},
+		{name: "exactly 45 degrees", latSpan: 45, lngSpan: 45, want: 3},
+		{name: "country", latSpan: 10, lngSpan: 10, want: 3},
+		{name: "province", latSpan: 2, lngSpan: 3, want: 4},
+		{name: "exactly 1 degree", latSpan: 1, lngSpan: 1, want: 5},
+		{name: "city", latSpan: 0.5, lngSpan: 0.3, want: 5},
+		{name: "neighbourhood", latSpan: 0.1, lngSpan: 0.1, want: 6},
+		{name: "block", latSpan: 0.01, lngSpan: 0.01, want: 7},
+		{name: "the widest side wins", latSpan: 0.01, lngSpan: 50, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := geohashPrecisionForBoundingBox(boundingBox(tt.latSpan, tt.lngSpan)); got != tt.want {
+				t.Fatalf("expected precision %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestClusterByGeohash_GroupsPropertiesByCell(t *testing.T) {
+	properties := []domain.Property{
+		{ID: "cba-1", Latitude: -31.4201, Longitude: -64.1888},
+		{ID: "bsas-1", Latitude: -34.6037, Longitude: -58.3816},
+		{ID: "sin-coordenadas", Latitude: 0, Longitude: 0},
+		{ID: "cba-2", Latitude: -31.4135, Longitude: -64.1811},
+	}
+
+	// Con un mapa de 10 grados la precisión es 3: las dos de Córdoba caen en la misma celda
+	clusters := clusterByGeohash(properties, boundingBox(10, 10))
+
+	if len(clusters) != 2 {
+		t.Fatalf("expected 2 clusters, got %+v", clusters)
+	}
+	buenosAires, cordoba := clusters[0], clusters[1]
+	if buenosAires.Geohash != "69y" || cordoba.Geohash != "6d6" {
+		t.Fatalf("expected clusters sorted by geohash (69y, 6d6), got %s and %s", buenosAires.Geohash, cordoba.Geohash)
+	}
+	if cordoba.Count != 2 || !reflect.DeepEqual(cordoba.PropertyIDs, []string{"cba-1", "cba-2"}) {
+		t.Fatalf("expected both Córdoba properties in one cluster, got %+v", cordoba)
+	}
+	if math.Abs(cordoba.Latitude-(-31.4168)) > 1e-9 || math.Abs(cordoba.Longitude-(-64.18495)) > 1e-9 {
+		t.Fatalf("expected the cluster centered on the average, got %v, %v", cordoba.Latitude, cordoba.Longitude)
+	}
+	if buenosAires.Count != 1 || buenosAires.Latitude != -34.6037 {
+		t.Fatalf("expected a single-property cluster on the property, got %+v", buenosAires)
+	}
+
+	// Con un mapa chico la precisión es 7 y cada una queda en su celda
+	if clusters := clusterByGeohash(properties, boundingBox(0.01, 0.01)); len(clusters) != 3 || len(clusters[0].Geohash) != 7 {
+		t.Fatalf("expected 3 clusters with 7-character geohashes, got %+v", clusters)
+	}
+}
+
+func TestClusterByGeohash_WithoutPropertiesReturnsAnEmptyList(t *testing.T) {
+	for _, properties := range [][]domain.Property{nil, {{ID: "sin-coordenadas"}}} {
+		clusters := clusterByGeohash(properties, boundingBox(1, 1))
+		if clusters == nil || len(clusters) != 0 {
+			t.Fatalf("expected an empty, non-nil list (serialized as []), got %#v", clusters)
+		}
+	}
+}
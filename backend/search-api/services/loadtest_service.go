@@ -0,0 +1,334 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"search-api/dto"
+	"search-api/repositories"
+)
+
+// loadTestUserAgent identifica los requests de las pruebas de carga en los logs
+const loadTestUserAgent = "spotly-loadtest"
+
+// vegetaTargetSlots es la cantidad aproximada de líneas del archivo de vegeta
+// vegeta recorre los targets en orden, así que cada búsqueda se repite según su proporción
+const vegetaTargetSlots = 100
+
+// LoadTestOptions son los parámetros del escenario de carga
+type LoadTestOptions struct {
+	// Rate es la cantidad de requests por segundo y Duration la duración de la prueba
+	Rate     int
+	Duration time.Duration
+
+	// Window es la ventana de analytics de la que se toman las búsquedas
+	Window time.Duration
+
+	// MaxTargets es la cantidad máxima de búsquedas distintas del escenario (las más frecuentes)
+	MaxTargets int
+}
+
+// LoadTestService genera escenarios de carga para k6 y vegeta con la mezcla real de búsquedas
+// Sirve para dimensionar los TTL del caché y la capacidad de Solr antes de un pico de tráfico
+type LoadTestService interface {
+	// Scenario arma el escenario con las búsquedas más frecuentes de analytics (o una mezcla por defecto)
+	// y estima cuántas consultas por segundo llegarían al índice con la política de caché actual
+	Scenario(ctx context.Context, options LoadTestOptions) (dto.LoadTestScenario, error)
+
+	// K6Script genera el script de k6 del escenario contra baseURL
+	K6Script(scenario dto.LoadTestScenario, baseURL string) string
+
+	// VegetaTargets genera el archivo de targets de vegeta (formato http) del escenario contra baseURL
+	VegetaTargets(scenario dto.LoadTestScenario, baseURL string) string
+}
+
+// loadTestService es la implementación concreta de LoadTestService
+type loadTestService struct {
+	analytics repositories.AnalyticsRepository
+	cacheTTL  CacheTTLPolicy
+}
+
+// NewLoadTestService crea el generador de escenarios de carga
+// cacheTTL tiene que ser la misma política que usa el servicio de búsqueda
+func NewLoadTestService(analytics repositories.AnalyticsRepository, cacheTTL CacheTTLPolicy) LoadTestService {
+	return &loadTestService{
+		analytics: analytics,
+		cacheTTL:  cacheTTL,
+	}
+}
+
+// loadTestSearch es una búsqueda del escenario con la cantidad de veces que se hizo
+type loadTestSearch struct {
+	path    string
+	request dto.SearchRequest
+	count   int
+}
+
+// defaultLoadTestSearches es la mezcla que se usa cuando analytics no tiene búsquedas (ej: un ambiente nuevo)
+// Predominan las búsquedas amplias, como en producción
+var defaultLoadTestSearches = []struct {
+	query   string
+	filters map[string]string
+	count   int
+}{
+	{"", nil, 40},
+	{"", map[string]string{"city": "córdoba"}, 20},
+	{"departamento", nil, 15},
+	{"casa", map[string]string{"country": "argentina", "minGuests": "4"}, 15},
+	{"cabaña lago", map[string]string{"maxPrice": "90000", "petsAllowed": "true"}, 10},
+}
+
+// Scenario arma el escenario de carga
+func (s *loadTestService) Scenario(ctx context.Context, options LoadTestOptions) (dto.LoadTestScenario, error) {
+	scenario := dto.LoadTestScenario{
+		Source:          dto.LoadTestSourceAnalytics,
+		Rate:            options.Rate,
+		DurationSeconds: int(options.Duration.Seconds()),
+		Targets:         []dto.LoadTestTarget{},
+	}
+
+	// Las búsquedas de callers privilegiados no se registran en analytics,
+	// así que las pruebas de carga (que usan un token interno) no alteran la mezcla
+	from := time.Now().UTC().Add(-options.Window)
+	events, err := s.analytics.ListSince(ctx, from)
+	if err != nil {
+		return dto.LoadTestScenario{}, err
+	}
+	searches := make(map[string]*loadTestSearch)
+	for _, event := range events {
+		addLoadTestSearch(searches, event.Query, event.Filters, 1)
+	}
+	if len(searches) == 0 {
+		scenario.Source = dto.LoadTestSourceDefault
+		for _, search := range defaultLoadTestSearches {
+			addLoadTestSearch(searches, search.query, search.filters, search.count)
+		}
+	} else {
+		scenario.From = from
+	}
+
+	ranked := make([]*loadTestSearch, 0, len(searches))
+	for _, search := range searches {
+		ranked = append(ranked, search)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].count != ranked[j].count {
+			return ranked[i].count > ranked[j].count
+		}
+		return ranked[i].path < ranked[j].path
+	})
+	if options.MaxTargets > 0 && len(ranked) > options.MaxTargets {
+		ranked = ranked[:options.MaxTargets]
+	}
+
+	total := 0
+	for _, search := range ranked {
+		total += search.count
+	}
+
+	// Cada búsqueda va al índice como mucho una vez por TTL; si se repite menos que eso, en todas
+	indexQPS := 0.0
+	for _, search := range ranked {
+		weight := float64(search.count) / float64(total)
+		ttl := s.cacheTTL.TTLFor(search.request)
+		scenario.Targets = append(scenario.Targets, dto.LoadTestTarget{
+			Path:            search.path,
+			Weight:          roundTo(weight, 4),
+			CacheTTLSeconds: ttl.Seconds(),
+		})
+
+		requestRate := float64(options.Rate) * weight
+		if ttl > 0 {
+			requestRate = math.Min(requestRate, 1/ttl.Seconds())
+		}
+		indexQPS += requestRate
+	}
+
+	scenario.EstimatedIndexQPS = roundTo(indexQPS, 3)
+	if options.Rate > 0 {
+		scenario.EstimatedCacheHitRatio = roundTo(1-indexQPS/float64(options.Rate), 3)
+	}
+	return scenario, nil
+}
+
+// addLoadTestSearch suma count a la búsqueda con ese término y filtros
+// El bounding box no se puede reproducir (analytics no guarda las coordenadas), así que se descarta
+func addLoadTestSearch(searches map[string]*loadTestSearch, query string, filters map[string]string, count int) {
+	request := dto.SearchRequest{Query: query}
+	values := url.Values{}
+	if query != "" {
+		values.Set("query", query)
+	}
+	for name, value := range filters {
+		if applyLoadTestFilter(&request, name, value) {
+			values.Set(name, value)
+		}
+	}
+
+	path := "/search"
+	if len(values) > 0 {
+		path += "?" + values.Encode()
+	}
+
+	search, exists := searches[path]
+	if !exists {
+		search = &loadTestSearch{path: path, request: request}
+		searches[path] = search
+	}
+	search.count += count
+}
+
+// applyLoadTestFilter carga en request el filtro registrado por analytics (ver searchFilters)
+// Retorna false si el filtro no se puede reproducir en el query string
+func applyLoadTestFilter(request *dto.SearchRequest, name, value string) bool {
+	number, _ := strconv.ParseFloat(value, 64)
+	switch name {
+	case "city":
+		request.City = value
+	case "country":
+		request.Country = value
+	case "minPrice":
+		request.MinPrice = number
+	case "maxPrice":
+		request.MaxPrice = number
+	case "bedrooms":
+		request.Bedrooms = int(number)
+	case "bathrooms":
+		request.Bathrooms = int(number)
+	case "minGuests":
+		request.MinGuests = int(number)
+	case "childFriendly":
+		request.ChildFriendly = true
+	case "infantFriendly":
+		request.InfantFriendly = true
+	case "petsAllowed":
+		request.PetsAllowed = true
+	case "smokingAllowed":
+		request.SmokingAllowed = true
+	case "eventsAllowed":
+		request.EventsAllowed = true
+	case "instantBook":
+		request.InstantBook = true
+	case "cancellationPolicy":
+		request.CancellationPolicy = value
+	default:
+		return false
+	}
+	return true
+}
+
+// K6Script genera el script de k6: tasa constante de requests y cada uno elige una búsqueda según su peso
+func (s *loadTestService) K6Script(scenario dto.LoadTestScenario, baseURL string) string {
+	type k6Target struct {
+		Path   string  `json:"path"`
+		Weight float64 `json:"weight"`
+	}
+	targets := make([]k6Target, 0, len(scenario.Targets))
+	for _, target := range scenario.Targets {
+		targets = append(targets, k6Target{Path: target.Path, Weight: target.Weight})
+	}
+	// Sin escapar & ni < > para que las rutas se lean igual que en el JSON del escenario
+	var targetsJSON, baseURLJSON bytes.Buffer
+	encoder := json.NewEncoder(&targetsJSON)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(targets)
+	encoder = json.NewEncoder(&baseURLJSON)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(baseURL)
+
+	// Un VU por request por segundo alcanza con respuestas de hasta 1s; si Solr se degrada k6 agrega más
+	maxVUs := scenario.Rate * 10
+	if maxVUs < 10 {
+		maxVUs = 10
+	}
+
+	var script strings.Builder
+	fmt.Fprintf(&script, `// Escenario de carga de GET /search generado por search-api (GET /admin/loadtest/scenario)
+// Búsquedas: %s. Índice estimado: %.2f consultas/s (%.1f%% de cache hits)
+// Uso: k6 run -e SEARCH_INTERNAL_TOKEN=<token> search-scenario.js
+// Con el token interno las búsquedas no pasan por el detector de bots ni se registran en analytics
+import http from 'k6/http';
+import { check } from 'k6';
+
+const BASE_URL = __ENV.BASE_URL || %s;
+const TARGETS = %s;
+
+export const options = {
+  scenarios: {
+    search: {
+      executor: 'constant-arrival-rate',
+      rate: %d,
+      timeUnit: '1s',
+      duration: '%ds',
+      preAllocatedVUs: %d,
+      maxVUs: %d,
+    },
+  },
+  thresholds: {
+    http_req_failed: ['rate<0.01'],
+    http_req_duration: ['p(95)<500'],
+  },
+};
+
+// pick elige una búsqueda según su proporción del tráfico
+function pick() {
+  let r = Math.random();
+  for (const target of TARGETS) {
+    r -= target.weight;
+    if (r <= 0) {
+      return target;
+    }
+  }
+  return TARGETS[TARGETS.length - 1];
+}
+
+export default function () {
+  const target = pick();
+  const response = http.get(BASE_URL + target.path, {
+    headers: { 'User-Agent': '%s', 'X-Internal-Token': __ENV.SEARCH_INTERNAL_TOKEN || '' },
+    tags: { name: 'GET /search' },
+  });
+  check(response, { 'status 200': (r) => r.status === 200 });
+}
+`, scenario.Source, scenario.EstimatedIndexQPS, scenario.EstimatedCacheHitRatio*100,
+		bytes.TrimSpace(baseURLJSON.Bytes()), bytes.TrimSpace(targetsJSON.Bytes()), scenario.Rate, scenario.DurationSeconds, scenario.Rate, maxVUs, loadTestUserAgent)
+	return script.String()
+}
+
+// VegetaTargets genera los targets de vegeta; el token interno se completa con envsubst al correr la prueba
+func (s *loadTestService) VegetaTargets(scenario dto.LoadTestScenario, baseURL string) string {
+	var slots []string
+	for _, target := range scenario.Targets {
+		repeat := int(math.Round(target.Weight * vegetaTargetSlots))
+		if repeat < 1 {
+			repeat = 1
+		}
+		for i := 0; i < repeat; i++ {
+			slots = append(slots, target.Path)
+		}
+	}
+	// Intercalar las búsquedas (siempre igual, para que dos archivos del mismo escenario coincidan)
+	random := rand.New(rand.NewSource(1))
+	random.Shuffle(len(slots), func(i, j int) { slots[i], slots[j] = slots[j], slots[i] })
+
+	var targets strings.Builder
+	targets.WriteString("# Escenario de carga de GET /search generado por search-api (GET /admin/loadtest/scenario)\n")
+	fmt.Fprintf(&targets, "# Búsquedas: %s. Índice estimado: %.2f consultas/s (%.1f%% de cache hits)\n",
+		scenario.Source, scenario.EstimatedIndexQPS, scenario.EstimatedCacheHitRatio*100)
+	fmt.Fprintf(&targets, "# Uso: envsubst < search-targets.txt | vegeta attack -rate=%d/s -duration=%ds | vegeta report\n\n",
+		scenario.Rate, scenario.DurationSeconds)
+	for _, path := range slots {
+		fmt.Fprintf(&targets, "GET %s%s\nUser-Agent: %s\nX-Internal-Token: ${SEARCH_INTERNAL_TOKEN}\n\n", baseURL, path, loadTestUserAgent)
+	}
+	return targets.String()
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"resilience"
+	"search-api/dto"
+	"search-api/repositories"
+)
+
+// ownerSolr es un Solr en memoria que guarda el owner_id de cada documento y resuelve los delete por owner_id
+type ownerSolr struct {
+	mu     sync.Mutex
+	owners map[string]json.Number // ID del documento -> owner_id indexado
+}
+
+func (s *ownerSolr) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.UseNumber()
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/schema"):
+		// Schema vacío: el repositorio crea los campos y se aceptan los cambios
+		w.Write([]byte(`{"schema":{}}`))
+	case strings.HasSuffix(r.URL.Path, "/update/json/docs"):
+		var doc struct {
+			ID      string      `json:"id"`
+			OwnerID json.Number `json:"owner_id"`
+		}
+		decoder.Decode(&doc)
+		s.owners[doc.ID] = doc.OwnerID
+		w.Write([]byte(`{"responseHeader":{"status":0}}`))
+	case strings.HasSuffix(r.URL.Path, "/update"):
+		var command struct {
+			Delete map[string]string `json:"delete"`
+		}
+		decoder.Decode(&command)
+		if owner, ok := strings.CutPrefix(command.Delete["query"], "owner_id:"); ok {
+			for id, indexed := range s.owners {
+				if indexed.String() == owner {
+					delete(s.owners, id)
+				}
+			}
+		}
+		w.Write([]byte(`{"responseHeader":{"status":0}}`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestDeleteOwnerProperties_RemovesIndexedSnapshots(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	solr := &ownerSolr{owners: make(map[string]json.Number)}
+	server := httptest.NewServer(solr)
+	defer server.Close()
+
+	index := repositories.NewSolrRepository(repositories.SolrOptions{URL: server.URL + "/solr/properties"}, server.Client())
+	service := NewSearchService(index, repositories.NewCacheRepository(newBenchmarkRemoteCache(), time.Hour), nil,
+		resilience.CircuitBreakerSettings{FailureThreshold: 5, OpenTimeout: time.Second, HalfOpenMaxRequests: 1},
+		resilience.RetryPolicy{MaxAttempts: 1},
+		CacheTTLPolicy{Default: time.Hour, Broad: time.Hour, Specific: time.Hour},
+		nil,
+		0,
+	)
+
+	// Los snapshots de properties-api traen el ID de users-api como string, igual que los eventos de propiedades
+	for _, snapshot := range []dto.PropertySnapshot{
+		{ID: "p1", Title: "Loft en Güemes", Location: "Córdoba, Argentina", OwnerID: "1001"},
+		{ID: "p2", Title: "Casa en Pocitos", Location: "Montevideo, Uruguay", OwnerID: "1001"},
+		{ID: "p3", Title: "Cabaña en Melipal", Location: "Bariloche, Argentina", OwnerID: "1002"},
+	} {
+		property, err := service.PropertyFromSnapshot(snapshot)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := service.IndexProperty(context.Background(), *property); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// user.deactivated y user.erased traen el ID numérico de users-api
+	if err := service.DeleteOwnerProperties(context.Background(), 1001); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(solr.owners) != 1 || solr.owners["p3"] != "1002" {
+		t.Fatalf("expected only the other host's property left, got %v", solr.owners)
+	}
+}
+
+func TestOwnerIndexID(t *testing.T) {
+	tests := []struct {
+		ownerID string
+		want    uint
+	}{
+		{ownerID: "1001", want: 1001},
+		{ownerID: " 42 ", want: 42},
+		{ownerID: "", want: 0},
+		{ownerID: "6650f1c2a1b2", want: 0},
+		{ownerID: "-1", want: 0},
+	}
+	for _, tt := range tests {
+		if got := OwnerIndexID(tt.ownerID); got != tt.want {
+			t.Fatalf("expected %d for %q, got %d", tt.want, tt.ownerID, got)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"resilience"
+	"search-api/config"
+	"search-api/dto"
+	"search-api/rpc"
+	"search-api/utils"
+)
+
+// PersonalizationService define la interfaz para personalizar las búsquedas de usuarios autenticados
+type PersonalizationService interface {
+	// Personalize obtiene los favoritos y preferencias del usuario y los aplica a la respuesta
+	// Si users-api no responde la búsqueda sale sin personalizar (nunca falla por esto)
+	Personalize(ctx context.Context, userID uint, response *dto.SearchResponse)
+
+	// Forget descarta el perfil cacheado del usuario (ej: al borrar sus datos)
+	Forget(userID uint)
+}
+
+// cachedProfile es el perfil de un usuario con su vencimiento
+type cachedProfile struct {
+	profile   *rpc.GetSearchProfileResponse
+	expiresAt time.Time
+}
+
+// personalizationService es la implementación concreta de PersonalizationService
+type personalizationService struct {
+	users    rpc.UsersServiceClient
+	settings config.PersonalizationConfig
+	breaker  *resilience.CircuitBreaker
+
+	mu       sync.Mutex
+	profiles map[uint]cachedProfile
+}
+
+// NewPersonalizationService crea el servicio de personalización
+// Los perfiles se cachean settings.ProfileTTL: un favorito nuevo puede tardar ese tiempo en marcarse
+func NewPersonalizationService(users rpc.UsersServiceClient, settings config.PersonalizationConfig) PersonalizationService {
+	return &personalizationService{
+		users:    users,
+		settings: settings,
+		breaker:  resilience.NewCircuitBreaker("users-api", resilience.DefaultCircuitBreakerSettings()),
+		profiles: make(map[uint]cachedProfile),
+	}
+}
+
+// Personalize aplica el perfil del usuario a la respuesta
+func (s *personalizationService) Personalize(ctx context.Context, userID uint, response *dto.SearchResponse) {
+	profile, err := s.profile(ctx, userID)
+	if err != nil {
+		log.Printf("⚠️ Búsqueda sin personalizar para el usuario %d: %v", userID, err)
+		return
+	}
+
+	favorites := make(map[string]bool, len(profile.Favorites))
+	for _, id := range profile.Favorites {
+		favorites[id] = true
+	}
+
+	currency, rate := s.exchangeRate(profile.PreferredCurrency)
+	response.Personalization = &dto.Personalization{
+		Currency:     currency,
+		ExchangeRate: rate,
+		Locale:       profile.Locale,
+		Favorites:    favorites,
+	}
+}
+
+// Forget descarta el perfil cacheado del usuario
+func (s *personalizationService) Forget(userID uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.profiles, userID)
+}
+
+// profile obtiene el perfil del caché o de users-api
+func (s *personalizationService) profile(ctx context.Context, userID uint) (*rpc.GetSearchProfileResponse, error) {
+	s.mu.Lock()
+	cached, ok := s.profiles[userID]
+	s.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.profile, nil
+	}
+
+	var profile *rpc.GetSearchProfileResponse
+	err := s.breaker.Execute(func() error {
+		callCtx, cancel := context.WithTimeout(ctx, s.settings.CallTimeout)
+		defer cancel()
+
+		var err error
+		profile, err = s.users.GetSearchProfile(callCtx, &rpc.GetSearchProfileRequest{UserID: userID})
+		if err != nil {
+			return utils.GRPCCallError("users-api", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Se descartan los perfiles vencidos para que el caché no crezca con usuarios inactivos
+	now := time.Now()
+	for id, entry := range s.profiles {
+		if now.After(entry.expiresAt) {
+			delete(s.profiles, id)
+		}
+	}
+	s.profiles[userID] = cachedProfile{profile: profile, expiresAt: now.Add(s.settings.ProfileTTL)}
+	return profile, nil
+}
+
+// exchangeRate retorna la moneda y la cotización a aplicar
+// Sin preferencia o sin cotización configurada los precios se muestran en la moneda base
+func (s *personalizationService) exchangeRate(preferred string) (string, float64) {
+	preferred = strings.ToUpper(preferred)
+	if preferred == "" || preferred == s.settings.BaseCurrency {
+		return s.settings.BaseCurrency, 1
+	}
+	rate, ok := s.settings.CurrencyRates[preferred]
+	if !ok {
+		return s.settings.BaseCurrency, 1
+	}
+	return preferred, rate
+}
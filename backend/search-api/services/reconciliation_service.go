@@ -0,0 +1,700 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"jobs"
+	"resilience"
+	"search-api/config"
+	"search-api/dto"
+	"search-api/repositories"
+	"search-api/rpc"
+	"search-api/utils"
+)
+
+// ErrReconciliationRunning indica que ya hay una reconciliación en curso
+var ErrReconciliationRunning = errors.New("ya hay una reconciliación en curso")
+
+// shadowDiscardTimeout es el tiempo para descartar el índice sombra de una reindexación fallida
+// (con un context propio: la reindexación puede haber fallado justamente por timeout)
+const shadowDiscardTimeout = 30 * time.Second
+
+// maxUsersBatch es el máximo de usuarios por llamada a GetUsers de users-api
+const maxUsersBatch = 100
+
+// indexLockName es el lock distribuido que comparten la reconciliación y la reindexación de todas las réplicas
+const indexLockName = "search-index-maintenance"
+
+// ReconciliationService compara el índice con properties-api y repara las diferencias
+// Protege contra eventos perdidos: propiedades sin indexar, versiones viejas, documentos de propiedades
+// borradas y de anfitriones desactivados
+type ReconciliationService interface {
+	// Trigger inicia una reconciliación en segundo plano; retorna ErrReconciliationRunning si ya hay una
+	// (en esta o en otra réplica)
+	Trigger() error
+
+	// Rebuild inicia en segundo plano una reindexación completa en un índice sombra que reemplaza al
+	// actual recién al terminar; comparte el lock con la reconciliación (ErrReconciliationRunning)
+	Rebuild() error
+
+	// RunScheduled ejecuta la reconciliación diaria y espera a que termine (la programa el runner de jobs)
+	// Retorna ErrReconciliationRunning si ya hay una reconciliación o reindexación manual en curso
+	RunScheduled(ctx context.Context) error
+
+	// Stop cancela las reconciliaciones manuales en curso
+	Stop()
+
+	// Status retorna si hay una reconciliación en curso, la próxima programada y el reporte de la última
+	Status() dto.ReconciliationStatus
+
+	// WriteMetrics escribe las métricas de drift en formato de texto de Prometheus
+	WriteMetrics(w io.Writer) error
+}
+
+// sourceVersion es la versión de una propiedad en properties-api
+type sourceVersion struct {
+	ownerID   string
+	updatedAt time.Time
+}
+
+// reconciliationService es la implementación concreta de ReconciliationService
+type reconciliationService struct {
+	index      repositories.SearchIndex
+	search     SearchService
+	cache      CacheService
+	properties rpc.PropertiesServiceClient
+	users      rpc.UsersServiceClient
+	retry      resilience.RetryPolicy
+	settings   config.ReconciliationConfig
+	locker     jobs.Locker
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu          sync.Mutex
+	running     bool
+	last        *dto.ReconciliationReport
+	lastRebuild *dto.ReconciliationReport
+	runs        map[string]int64 // "ok" o "error" -> cantidad
+	totals      map[string]int64 // reparaciones acumuladas por tipo
+}
+
+// NewReconciliationService crea el servicio de reconciliación
+// Las llamadas a properties-api y users-api se reintentan con retry (un run es largo y no debe caerse por una falla puntual)
+// cache se invalida después de que una reindexación completa reemplaza el índice
+// locker evita que otra réplica reconcilie o reindexe a la vez (nil = solo se controla en esta réplica)
+func NewReconciliationService(
+	index repositories.SearchIndex,
+	search SearchService,
+	cache CacheService,
+	properties rpc.PropertiesServiceClient,
+	users rpc.UsersServiceClient,
+	retry resilience.RetryPolicy,
+	settings config.ReconciliationConfig,
+	locker jobs.Locker,
+) ReconciliationService {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &reconciliationService{
+		index:      index,
+		search:     search,
+		cache:      cache,
+		properties: properties,
+		users:      users,
+		retry:      retry,
+		settings:   settings,
+		locker:     locker,
+		ctx:        ctx,
+		cancel:     cancel,
+		runs:       make(map[string]int64),
+		totals:     make(map[string]int64),
+	}
+}
+
+// RunScheduled reconcilia con el context del runner (que aplica RECONCILE_TIMEOUT)
+func (s *reconciliationService) RunScheduled(ctx context.Context) error {
+	lock, err := s.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.release(lock)
+
+	_, err = s.run(lock.Context(), dto.ReconciliationScheduled)
+	return err
+}
+
+// Trigger inicia una reconciliación manual en segundo plano
+func (s *reconciliationService) Trigger() error {
+	return s.start(dto.ReconciliationManual)
+}
+
+// Rebuild inicia una reindexación completa en segundo plano
+func (s *reconciliationService) Rebuild() error {
+	return s.start(dto.ReconciliationRebuild)
+}
+
+// markRunning marca la reconciliación como en curso; retorna false si ya había una
+func (s *reconciliationService) markRunning() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return false
+	}
+	s.running = true
+	return true
+}
+
+// acquire marca la reconciliación como en curso en esta réplica y toma el lock distribuido
+// El lock se renueva mientras dura; si se pierde se cancela su context y la reconciliación se corta
+func (s *reconciliationService) acquire(ctx context.Context) (*jobs.Lock, error) {
+	if !s.markRunning() {
+		return nil, ErrReconciliationRunning
+	}
+
+	lock, err := jobs.Acquire(ctx, s.locker, indexLockName, jobs.DefaultLease)
+	if err != nil {
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
+		if errors.Is(err, jobs.ErrLockHeld) {
+			return nil, fmt.Errorf("%w en otra réplica", ErrReconciliationRunning)
+		}
+		return nil, fmt.Errorf("error tomando el lock de la reconciliación: %w", err)
+	}
+	return lock, nil
+}
+
+// release suelta el lock distribuido (si falla vence solo al terminar el lease)
+func (s *reconciliationService) release(lock *jobs.Lock) {
+	if err := lock.Release(time.Time{}); err != nil {
+		log.Printf("⚠️ Error soltando el lock de la reconciliación: %v", err)
+	}
+}
+
+// start toma el lock y ejecuta la reconciliación en una goroutine
+func (s *reconciliationService) start(trigger string) error {
+	lock, err := s.acquire(s.ctx)
+	if err != nil {
+		return err
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer s.release(lock)
+		ctx, cancel := context.WithTimeout(lock.Context(), s.settings.Timeout)
+		defer cancel()
+		run := s.run
+		if trigger == dto.ReconciliationRebuild {
+			run = s.runRebuild
+		}
+		if _, err := run(ctx, trigger); err != nil {
+			log.Printf("❌ Error en la reconciliación del índice: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Stop cancela las reconciliaciones manuales en curso y espera a que terminen
+func (s *reconciliationService) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// run reconcilia y registra el reporte; el caller ya marcó running
+func (s *reconciliationService) run(ctx context.Context, trigger string) (dto.ReconciliationReport, error) {
+	report := dto.ReconciliationReport{Trigger: trigger, StartedAt: time.Now().UTC()}
+	log.Printf("🔄 Reconciliando el índice (%s) con properties-api...", trigger)
+
+	err := s.reconcile(ctx, &report)
+	report.FinishedAt = time.Now().UTC()
+	if err != nil {
+		report.Error = err.Error()
+	}
+
+	s.mu.Lock()
+	s.running = false
+	s.last = &report
+	if err != nil {
+		s.runs["error"]++
+	} else {
+		s.runs["ok"]++
+	}
+	s.totals["reindexed"] += int64(report.Reindexed)
+	s.totals["deleted"] += int64(report.Deleted)
+	s.totals["failed"] += int64(report.Failed)
+	s.mu.Unlock()
+
+	log.Printf("✅ Reconciliación terminada en %v: %d faltantes, %d desactualizadas, %d huérfanas, %d de anfitriones inactivos (%d reindexadas, %d eliminadas, %d fallidas)",
+		report.FinishedAt.Sub(report.StartedAt).Round(time.Millisecond), report.Missing, report.Stale, report.Orphans, report.InactiveOwners,
+		report.Reindexed, report.Deleted, report.Failed)
+	return report, err
+}
+
+// reconcile compara y repara
+// El índice se lista antes que properties-api: una propiedad creada en el medio aparece como faltante
+// (se reindexa sin daño) y una borrada en el medio como huérfana (se confirma antes de eliminarla)
+func (s *reconciliationService) reconcile(ctx context.Context, report *dto.ReconciliationReport) error {
+	indexed, err := s.index.ListPropertyVersions(ctx)
+	if err != nil {
+		return err
+	}
+	report.IndexedCount = len(indexed)
+
+	source, err := s.listSource(ctx)
+	if err != nil {
+		return err
+	}
+	report.SourceCount = len(source)
+
+	inactive, err := s.inactiveOwners(ctx, source)
+	if err != nil {
+		return err
+	}
+
+	var toIndex, orphans, hidden []string
+	for id, version := range source {
+		indexedAt, isIndexed := indexed[id]
+		switch {
+		case inactive[version.ownerID]:
+			if isIndexed {
+				hidden = append(hidden, id)
+			}
+		case !isIndexed:
+			report.Missing++
+			toIndex = append(toIndex, id)
+		case indexedAt.Before(version.updatedAt):
+			// Los documentos indexados antes de guardar updated_at tienen fecha cero y se reindexan una vez
+			report.Stale++
+			toIndex = append(toIndex, id)
+		}
+	}
+	for id := range indexed {
+		if _, exists := source[id]; !exists {
+			orphans = append(orphans, id)
+		}
+	}
+	report.InactiveOwners = len(hidden)
+
+	orphans, err = s.confirmOrphans(ctx, orphans)
+	if err != nil {
+		return err
+	}
+	report.Orphans = len(orphans)
+
+	s.reindex(ctx, toIndex, report)
+
+	// Si properties-api respondiera vacío por error se borraría el índice entero: con demasiados borrados no se borra nada
+	toDelete := append(orphans, hidden...)
+	if len(toDelete) > s.settings.MaxDeletes {
+		return fmt.Errorf("se encontraron %d documentos para eliminar (máximo %d): no se eliminó ninguno, revisar properties-api y RECONCILE_MAX_DELETES", len(toDelete), s.settings.MaxDeletes)
+	}
+	// Antes de borrar se confirma que el lock sigue siendo de esta réplica (la renovación corre cada lease/3)
+	if err := jobs.Verify(ctx); err != nil {
+		return err
+	}
+	for _, id := range toDelete {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := s.search.DeleteProperty(ctx, id); err != nil {
+			log.Printf("❌ Error eliminando la propiedad %s del índice: %v", id, err)
+			report.Failed++
+			continue
+		}
+		report.Deleted++
+	}
+	return ctx.Err()
+}
+
+// runRebuild reindexa todo en un índice sombra y registra el reporte; el caller ya marcó running
+// Su reporte va aparte del de la reconciliación: no cuenta en las métricas de drift ni de reparaciones
+func (s *reconciliationService) runRebuild(ctx context.Context, trigger string) (dto.ReconciliationReport, error) {
+	report := dto.ReconciliationReport{Trigger: trigger, StartedAt: time.Now().UTC()}
+	log.Printf("🔄 Reindexando todo properties-api en un índice sombra...")
+
+	err := s.rebuild(ctx, &report)
+	report.FinishedAt = time.Now().UTC()
+	if err != nil {
+		report.Error = err.Error()
+	}
+
+	s.mu.Lock()
+	s.running = false
+	s.lastRebuild = &report
+	s.mu.Unlock()
+
+	if err == nil {
+		log.Printf("✅ Reindexación completa terminada en %v: %d propiedades en '%s' (%d puestas al día, %d eliminadas)",
+			report.FinishedAt.Sub(report.StartedAt).Round(time.Millisecond), report.Reindexed, report.Shadow,
+			report.Missing+report.Stale, report.Deleted)
+	}
+	return report, err
+}
+
+// rebuild llena un índice sombra desde properties-api y lo pone en lugar del actual
+// Mientras se llena, el consumidor de eventos sigue actualizando el índice actual: antes del reemplazo
+// se compara la sombra con properties-api otra vez y se ponen al día los cambios del medio.
+// Los eventos entre esa puesta al día y el reemplazo los repara la próxima reconciliación
+// Si algo falla la sombra se descarta y el índice actual queda como estaba
+func (s *reconciliationService) rebuild(ctx context.Context, report *dto.ReconciliationReport) error {
+	live, err := s.index.ListPropertyVersions(ctx)
+	if err != nil {
+		return err
+	}
+	report.IndexedCount = len(live)
+
+	shadow, err := s.index.NewShadow(ctx)
+	if err != nil {
+		return err
+	}
+	report.Shadow = shadow.Name()
+	defer func() {
+		if report.Swapped {
+			return
+		}
+		discardCtx, cancel := context.WithTimeout(context.Background(), shadowDiscardTimeout)
+		defer cancel()
+		if err := shadow.Discard(discardCtx); err != nil {
+			log.Printf("⚠️ El índice sombra '%s' no se pudo descartar: %v", report.Shadow, err)
+		}
+	}()
+
+	source, err := s.listSource(ctx)
+	if err != nil {
+		return err
+	}
+	report.SourceCount = len(source)
+
+	inactive, err := s.inactiveOwners(ctx, source)
+	if err != nil {
+		return err
+	}
+	ids := make([]string, 0, len(source))
+	for id, version := range source {
+		if inactive[version.ownerID] {
+			report.InactiveOwners++
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	s.fillShadow(ctx, shadow, ids, report)
+	if err := s.checkShadow(ctx, report); err != nil {
+		return err
+	}
+
+	// Puesta al día: la sombra se lista antes que properties-api, igual que en la reconciliación
+	indexed, err := shadow.ListPropertyVersions(ctx)
+	if err != nil {
+		return err
+	}
+	source, err = s.listSource(ctx)
+	if err != nil {
+		return err
+	}
+	report.SourceCount = len(source)
+	inactive, err = s.inactiveOwners(ctx, source)
+	if err != nil {
+		return err
+	}
+
+	var toIndex, toDelete []string
+	for id, version := range source {
+		indexedAt, isIndexed := indexed[id]
+		switch {
+		case inactive[version.ownerID]:
+			if isIndexed {
+				toDelete = append(toDelete, id)
+			}
+		case !isIndexed:
+			report.Missing++
+			toIndex = append(toIndex, id)
+		case indexedAt.Before(version.updatedAt):
+			report.Stale++
+			toIndex = append(toIndex, id)
+		}
+	}
+	for id := range indexed {
+		if _, exists := source[id]; !exists {
+			report.Orphans++
+			toDelete = append(toDelete, id)
+		}
+	}
+
+	s.fillShadow(ctx, shadow, toIndex, report)
+	if err := s.checkShadow(ctx, report); err != nil {
+		return err
+	}
+	if err := shadow.DeleteProperties(ctx, toDelete); err != nil {
+		return fmt.Errorf("error eliminando %d propiedades del índice sombra: %w", len(toDelete), err)
+	}
+	report.Deleted = len(toDelete)
+
+	// Misma protección que la reconciliación: si properties-api respondió de menos no se reemplaza el índice
+	shadowCount := len(indexed) + report.Missing - len(toDelete)
+	if lost := report.IndexedCount - shadowCount; lost > s.settings.MaxDeletes {
+		return fmt.Errorf("el índice sombra tiene %d documentos menos que el actual (máximo %d): no se reemplazó, revisar properties-api y RECONCILE_MAX_DELETES", lost, s.settings.MaxDeletes)
+	}
+
+	if err := jobs.Verify(ctx); err != nil {
+		return err
+	}
+	if err := shadow.Swap(ctx); err != nil {
+		return fmt.Errorf("error reemplazando el índice por '%s': %w", report.Shadow, err)
+	}
+	report.Swapped = true
+
+	// Las búsquedas cacheadas son del índice anterior
+	if _, err := s.cache.Invalidate("search:*"); err != nil {
+		if errors.Is(err, ErrCacheOperationUnsupported) {
+			log.Printf("⚠️ El caché remoto no soporta invalidar por patrón: las búsquedas cacheadas vencen por TTL")
+		} else {
+			log.Printf("⚠️ Error invalidando las búsquedas cacheadas después de reindexar: %v", err)
+		}
+	}
+	return nil
+}
+
+// fillShadow obtiene las propiedades de properties-api por lotes y las indexa en el índice sombra
+// Las propiedades que properties-api ya no devuelve (borradas en el medio) no se indexan
+func (s *reconciliationService) fillShadow(ctx context.Context, shadow repositories.ShadowIndex, ids []string, report *dto.ReconciliationReport) {
+	for start := 0; start < len(ids); start += maxPropertiesBatch {
+		if ctx.Err() != nil {
+			return
+		}
+		batch := ids[start:min(start+maxPropertiesBatch, len(ids))]
+		properties, err := s.search.FetchPropertiesFromAPI(ctx, batch)
+		if err != nil {
+			log.Printf("❌ Error obteniendo %d propiedades para el índice sombra: %v", len(batch), err)
+			report.Failed += len(batch)
+			continue
+		}
+		if err := shadow.IndexProperties(ctx, properties); err != nil {
+			log.Printf("❌ Error indexando %d propiedades en el índice sombra: %v", len(properties), err)
+			report.Failed += len(properties)
+			continue
+		}
+		report.Reindexed += len(properties)
+	}
+}
+
+// checkShadow corta la reindexación si se canceló o si faltó indexar algo: una sombra incompleta
+// no reemplaza al índice actual (a diferencia de la reconciliación, que repara lo que puede)
+func (s *reconciliationService) checkShadow(ctx context.Context, report *dto.ReconciliationReport) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if report.Failed > 0 {
+		return fmt.Errorf("%d propiedades no se pudieron indexar en el índice sombra: se descartó sin reemplazar el actual", report.Failed)
+	}
+	return nil
+}
+
+// listSource recorre todas las versiones de properties-api por páginas
+func (s *reconciliationService) listSource(ctx context.Context) (map[string]sourceVersion, error) {
+	source := make(map[string]sourceVersion)
+	afterID := ""
+	for {
+		var page *rpc.ListPropertyVersionsResponse
+		err := resilience.Retry(ctx, s.retry, func(ctx context.Context) error {
+			var err error
+			page, err = s.properties.ListPropertyVersions(ctx, &rpc.ListPropertyVersionsRequest{AfterID: afterID, Limit: s.settings.PageSize})
+			if err != nil {
+				return utils.GRPCCallError("properties-api", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error listando propiedades de properties-api: %w", err)
+		}
+
+		for _, version := range page.Versions {
+			updatedAt, err := time.Parse(time.RFC3339, version.UpdatedAt)
+			if err != nil {
+				// Sin fecha válida no se puede saber si está desactualizada: se compara contra el valor cero
+				updatedAt = time.Time{}
+			}
+			source[version.ID] = sourceVersion{ownerID: version.OwnerID, updatedAt: updatedAt.UTC()}
+		}
+		if page.NextAfterID == "" {
+			return source, nil
+		}
+		afterID = page.NextAfterID
+	}
+}
+
+// inactiveOwners consulta a users-api los dueños de las propiedades y retorna los desactivados o inexistentes
+// Los dueños con un ID no numérico no se pueden consultar y se consideran activos
+func (s *reconciliationService) inactiveOwners(ctx context.Context, source map[string]sourceVersion) (map[string]bool, error) {
+	owners := make(map[uint]string)
+	for _, version := range source {
+		if id, err := strconv.ParseUint(version.ownerID, 10, 32); err == nil && id != 0 {
+			owners[uint(id)] = version.ownerID
+		}
+	}
+
+	ids := make([]uint, 0, len(owners))
+	for id := range owners {
+		ids = append(ids, id)
+	}
+
+	active := make(map[uint]bool, len(owners))
+	for start := 0; start < len(ids); start += maxUsersBatch {
+		batch := ids[start:min(start+maxUsersBatch, len(ids))]
+		var response *rpc.GetUsersResponse
+		err := resilience.Retry(ctx, s.retry, func(ctx context.Context) error {
+			var err error
+			response, err = s.users.GetUsers(ctx, &rpc.GetUsersRequest{UserIDs: batch})
+			if err != nil {
+				return utils.GRPCCallError("users-api", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error consultando los anfitriones en users-api: %w", err)
+		}
+		for _, user := range response.Users {
+			active[user.ID] = user.Active
+		}
+	}
+
+	inactive := make(map[string]bool)
+	for id, ownerID := range owners {
+		if !active[id] {
+			inactive[ownerID] = true
+		}
+	}
+	return inactive, nil
+}
+
+// confirmOrphans pide a properties-api los documentos huérfanos y descarta los que sí existen
+// (propiedades creadas entre el listado del índice y el de properties-api)
+func (s *reconciliationService) confirmOrphans(ctx context.Context, candidates []string) ([]string, error) {
+	var orphans []string
+	for start := 0; start < len(candidates); start += maxPropertiesBatch {
+		batch := candidates[start:min(start+maxPropertiesBatch, len(candidates))]
+		properties, err := s.search.FetchPropertiesFromAPI(ctx, batch)
+		if err != nil {
+			return nil, fmt.Errorf("error confirmando documentos huérfanos: %w", err)
+		}
+		existing := make(map[string]bool, len(properties))
+		for _, property := range properties {
+			existing[property.ID] = true
+		}
+		for _, id := range batch {
+			if !existing[id] {
+				orphans = append(orphans, id)
+			}
+		}
+	}
+	return orphans, nil
+}
+
+// reindex obtiene las propiedades faltantes o desactualizadas por lotes y las indexa
+// Un lote que falla se cuenta como fallido y la reconciliación sigue con el resto
+func (s *reconciliationService) reindex(ctx context.Context, ids []string, report *dto.ReconciliationReport) {
+	for start := 0; start < len(ids); start += maxPropertiesBatch {
+		if ctx.Err() != nil {
+			return
+		}
+		batch := ids[start:min(start+maxPropertiesBatch, len(ids))]
+		properties, err := s.search.FetchPropertiesFromAPI(ctx, batch)
+		if err != nil {
+			log.Printf("❌ Error obteniendo %d propiedades para reindexar: %v", len(batch), err)
+			report.Failed += len(batch)
+			continue
+		}
+		for _, property := range properties {
+			if err := s.search.UpdateProperty(ctx, property); err != nil {
+				log.Printf("❌ Error reindexando la propiedad %s: %v", property.ID, err)
+				report.Failed++
+				continue
+			}
+			report.Reindexed++
+		}
+	}
+}
+
+// Status retorna el estado de la reconciliación
+func (s *reconciliationService) Status() dto.ReconciliationStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := dto.ReconciliationStatus{Running: s.running}
+	if s.settings.Enabled {
+		next := jobs.DailyAt(s.settings.Hour).Next(time.Now().UTC())
+		status.NextRun = &next
+	}
+	if s.last != nil {
+		last := *s.last
+		status.LastRun = &last
+	}
+	if s.lastRebuild != nil {
+		lastRebuild := *s.lastRebuild
+		status.LastRebuild = &lastRebuild
+	}
+	return status
+}
+
+// WriteMetrics escribe las métricas de la reconciliación en formato de texto de Prometheus
+// El drift es el de la última reconciliación; las reparaciones se acumulan desde el arranque
+func (s *reconciliationService) WriteMetrics(w io.Writer) error {
+	s.mu.Lock()
+	var last dto.ReconciliationReport
+	if s.last != nil {
+		last = *s.last
+	}
+	lastRun, duration := 0.0, 0.0
+	if !last.FinishedAt.IsZero() {
+		lastRun = float64(last.FinishedAt.Unix())
+		duration = last.FinishedAt.Sub(last.StartedAt).Seconds()
+	}
+	runs := []cacheMetricSeries{
+		{`{result="ok"}`, float64(s.runs["ok"])},
+		{`{result="error"}`, float64(s.runs["error"])},
+	}
+	repairs := []cacheMetricSeries{
+		{`{action="reindexed"}`, float64(s.totals["reindexed"])},
+		{`{action="deleted"}`, float64(s.totals["deleted"])},
+		{`{action="failed"}`, float64(s.totals["failed"])},
+	}
+	s.mu.Unlock()
+
+	metrics := []struct {
+		name   string
+		kind   string
+		help   string
+		series []cacheMetricSeries
+	}{
+		{"search_reconcile_runs_total", "counter", "Reconciliaciones del índice por resultado", runs},
+		{"search_reconcile_last_run_timestamp_seconds", "gauge", "Momento en que terminó la última reconciliación", []cacheMetricSeries{{"", lastRun}}},
+		{"search_reconcile_last_duration_seconds", "gauge", "Duración de la última reconciliación", []cacheMetricSeries{{"", duration}}},
+		{"search_reconcile_drift", "gauge", "Diferencias entre el índice y properties-api en la última reconciliación", []cacheMetricSeries{
+			{`{kind="missing"}`, float64(last.Missing)},
+			{`{kind="stale"}`, float64(last.Stale)},
+			{`{kind="orphan"}`, float64(last.Orphans)},
+			{`{kind="inactive_owner"}`, float64(last.InactiveOwners)},
+		}},
+		{"search_reconcile_repairs_total", "counter", "Documentos reindexados, eliminados o que no se pudieron reparar", repairs},
+	}
+
+	for _, metric := range metrics {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", metric.name, metric.help, metric.name, metric.kind); err != nil {
+			return err
+		}
+		for _, series := range metric.series {
+			if _, err := fmt.Fprintf(w, "%s%s %g\n", metric.name, series.labels, series.value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
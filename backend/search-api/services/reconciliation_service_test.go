@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"resilience"
+	"search-api/config"
+	"search-api/domain"
+	"search-api/dto"
+	"search-api/repositories"
+	"search-api/rpc"
+
+	"google.golang.org/grpc"
+)
+
+// memoryShadowIndex es un índice sombra en memoria que registra si se reemplazó o se descartó
+type memoryShadowIndex struct {
+	docs      map[string]time.Time
+	swapped   bool
+	discarded bool
+
+	// onFilled se llama después del primer lote indexado (simula cambios en properties-api durante el llenado)
+	onFilled func()
+}
+
+func (s *memoryShadowIndex) Name() string {
+	return "properties_reindex_test"
+}
+
+func (s *memoryShadowIndex) IndexProperties(ctx context.Context, properties []domain.Property) error {
+	for _, property := range properties {
+		s.docs[property.ID] = property.UpdatedAt
+	}
+	if s.onFilled != nil {
+		s.onFilled()
+		s.onFilled = nil
+	}
+	return nil
+}
+
+func (s *memoryShadowIndex) DeleteProperties(ctx context.Context, propertyIDs []string) error {
+	for _, id := range propertyIDs {
+		delete(s.docs, id)
+	}
+	return nil
+}
+
+func (s *memoryShadowIndex) ListPropertyVersions(ctx context.Context) (map[string]time.Time, error) {
+	versions := make(map[string]time.Time, len(s.docs))
+	for id, updatedAt := range s.docs {
+		versions[id] = updatedAt
+	}
+	return versions, nil
+}
+
+func (s *memoryShadowIndex) Swap(ctx context.Context) error {
+	s.swapped = true
+	return nil
+}
+
+func (s *memoryShadowIndex) Discard(ctx context.Context) error {
+	s.discarded = true
+	return nil
+}
+
+// shadowSearchIndex es el índice actual: solo lista sus versiones y crea la sombra
+type shadowSearchIndex struct {
+	repositories.SearchIndex
+	live   map[string]time.Time
+	shadow *memoryShadowIndex
+}
+
+func (i *shadowSearchIndex) ListPropertyVersions(ctx context.Context) (map[string]time.Time, error) {
+	return i.live, nil
+}
+
+func (i *shadowSearchIndex) NewShadow(ctx context.Context) (repositories.ShadowIndex, error) {
+	return i.shadow, nil
+}
+
+// sourceSearchService devuelve las propiedades de versions como si vinieran de properties-api
+type sourceSearchService struct {
+	SearchService
+	versions *[]rpc.PropertyVersion
+	err      error
+}
+
+func (s *sourceSearchService) FetchPropertiesFromAPI(ctx context.Context, ids []string) ([]domain.Property, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	updated := make(map[string]string, len(*s.versions))
+	for _, version := range *s.versions {
+		updated[version.ID] = version.UpdatedAt
+	}
+	var properties []domain.Property
+	for _, id := range ids {
+		if updatedAt, ok := updated[id]; ok {
+			parsed, _ := time.Parse(time.RFC3339, updatedAt)
+			properties = append(properties, domain.Property{ID: id, UpdatedAt: parsed})
+		}
+	}
+	return properties, nil
+}
+
+// versionsPropertiesClient responde ListPropertyVersions con una sola página
+type versionsPropertiesClient struct {
+	rpc.PropertiesServiceClient
+	versions *[]rpc.PropertyVersion
+}
+
+func (c *versionsPropertiesClient) ListPropertyVersions(ctx context.Context, request *rpc.ListPropertyVersionsRequest, opts ...grpc.CallOption) (*rpc.ListPropertyVersionsResponse, error) {
+	return &rpc.ListPropertyVersionsResponse{Versions: *c.versions}, nil
+}
+
+// activeUsersClient responde que todos los usuarios están activos salvo inactive
+type activeUsersClient struct {
+	rpc.UsersServiceClient
+	inactive uint
+}
+
+func (c *activeUsersClient) GetUsers(ctx context.Context, request *rpc.GetUsersRequest, opts ...grpc.CallOption) (*rpc.GetUsersResponse, error) {
+	response := &rpc.GetUsersResponse{}
+	for _, id := range request.UserIDs {
+		response.Users = append(response.Users, rpc.UserStatus{ID: id, Active: id != c.inactive})
+	}
+	return response, nil
+}
+
+// patternCache registra los patrones invalidados
+type patternCache struct {
+	CacheService
+	invalidated []string
+}
+
+func (c *patternCache) Invalidate(pattern string) (int, error) {
+	c.invalidated = append(c.invalidated, pattern)
+	return 0, nil
+}
+
+func newRebuildService(index repositories.SearchIndex, search SearchService, cache CacheService, versions *[]rpc.PropertyVersion) *reconciliationService {
+	service := NewReconciliationService(index, search, cache,
+		&versionsPropertiesClient{versions: versions},
+		&activeUsersClient{inactive: 9},
+		resilience.RetryPolicy{MaxAttempts: 1},
+		config.ReconciliationConfig{PageSize: 100, MaxDeletes: 1, Timeout: time.Minute},
+		nil,
+	)
+	return service.(*reconciliationService)
+}
+
+func TestRebuild_CatchesUpAndSwapsShadow(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	versions := []rpc.PropertyVersion{
+		{ID: "a", OwnerID: "1", UpdatedAt: "2025-01-01T10:00:00Z"},
+		{ID: "b", OwnerID: "1", UpdatedAt: "2025-01-01T10:00:00Z"},
+		{ID: "hidden", OwnerID: "9", UpdatedAt: "2025-01-01T10:00:00Z"},
+	}
+	shadow := &memoryShadowIndex{docs: make(map[string]time.Time)}
+	// Mientras se llena la sombra se crea c, se edita a y se borra b
+	shadow.onFilled = func() {
+		versions = []rpc.PropertyVersion{
+			{ID: "a", OwnerID: "1", UpdatedAt: "2025-01-02T10:00:00Z"},
+			{ID: "c", OwnerID: "1", UpdatedAt: "2025-01-02T10:00:00Z"},
+			{ID: "hidden", OwnerID: "9", UpdatedAt: "2025-01-01T10:00:00Z"},
+		}
+	}
+	index := &shadowSearchIndex{live: map[string]time.Time{"a": {}, "b": {}}, shadow: shadow}
+	cache := &patternCache{}
+	service := newRebuildService(index, &sourceSearchService{versions: &versions}, cache, &versions)
+	service.running = true
+
+	report, err := service.runRebuild(context.Background(), dto.ReconciliationRebuild)
+	if err != nil {
+		t.Fatalf("rebuild failed: %v", err)
+	}
+	if !shadow.swapped || shadow.discarded || !report.Swapped {
+		t.Fatalf("expected the shadow to be swapped and kept, got swapped=%t discarded=%t", shadow.swapped, shadow.discarded)
+	}
+	if len(shadow.docs) != 2 || !shadow.docs["a"].Equal(time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected the shadow to hold the fresh a and c, got %v", shadow.docs)
+	}
+	if _, ok := shadow.docs["c"]; !ok {
+		t.Fatalf("expected c to be indexed during the catch-up, got %v", shadow.docs)
+	}
+	if report.Missing != 1 || report.Stale != 1 || report.Orphans != 1 || report.InactiveOwners != 1 {
+		t.Fatalf("unexpected catch-up counts: %+v", report)
+	}
+	if len(cache.invalidated) != 1 || cache.invalidated[0] != "search:*" {
+		t.Fatalf("expected the search cache to be invalidated, got %v", cache.invalidated)
+	}
+	if status := service.Status(); status.Running || status.LastRebuild == nil || status.LastRun != nil {
+		t.Fatalf("expected the rebuild report apart from the reconciliation one, got %+v", status)
+	}
+}
+
+func TestRebuild_DiscardsShadowWhenFillFails(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	versions := []rpc.PropertyVersion{{ID: "a", OwnerID: "1", UpdatedAt: "2025-01-01T10:00:00Z"}}
+	shadow := &memoryShadowIndex{docs: make(map[string]time.Time)}
+	index := &shadowSearchIndex{live: map[string]time.Time{"a": {}}, shadow: shadow}
+	cache := &patternCache{}
+	search := &sourceSearchService{versions: &versions, err: errors.New("properties-api caído")}
+	service := newRebuildService(index, search, cache, &versions)
+	service.running = true
+
+	report, err := service.runRebuild(context.Background(), dto.ReconciliationRebuild)
+	if err == nil || report.Failed != 1 {
+		t.Fatalf("expected the rebuild to fail with 1 failed property, got err=%v report=%+v", err, report)
+	}
+	if shadow.swapped || !shadow.discarded {
+		t.Fatalf("expected the shadow to be discarded without swapping, got swapped=%t discarded=%t", shadow.swapped, shadow.discarded)
+	}
+	if len(cache.invalidated) != 0 {
+		t.Fatalf("expected the cache to be untouched, got %v", cache.invalidated)
+	}
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"search-api/dto"
+)
+
+// maxListIndex es el índice más alto aceptado en los campos lista (ej: images[9])
+const maxListIndex = 49
+
+// selectableField es un campo que se puede pedir en fields
+type selectableField struct {
+	name      string // nombre en el JSON de la propiedad
+	solrField string // campo de Solr del que se lee
+	list      bool   // admite índice (ej: images[0])
+}
+
+// selectableFields mapea los nombres aceptados en fields a los campos de la propiedad
+// Solo se aceptan estos campos: el valor de fields nunca llega crudo a Solr
+var selectableFields = map[string]selectableField{
+	"id":                 {name: "id", solrField: "id"},
+	"title":              {name: "title", solrField: "title"},
+	"description":        {name: "description", solrField: "description"},
+	"city":               {name: "city", solrField: "city"},
+	"country":            {name: "country", solrField: "country"},
+	"type":               {name: "propertyType", solrField: "property_type_s"},
+	"propertyType":       {name: "propertyType", solrField: "property_type_s"},
+	"latitude":           {name: "latitude", solrField: "geo_p"},
+	"longitude":          {name: "longitude", solrField: "geo_p"},
+	"price":              {name: "pricePerNight", solrField: "price"},
+	"pricePerNight":      {name: "pricePerNight", solrField: "price"},
+	"bedrooms":           {name: "bedrooms", solrField: "bedrooms"},
+	"bathrooms":          {name: "bathrooms", solrField: "bathrooms"},
+	"maxGuests":          {name: "maxGuests", solrField: "max_guests"},
+	"max_guests":         {name: "maxGuests", solrField: "max_guests"},
+	"images":             {name: "images", solrField: "images", list: true},
+	"coverImage":         {name: "coverImage", solrField: "cover_image_s"},
+	"coverThumbnail":     {name: "coverThumbnail", solrField: "cover_thumbnail_s"},
+	"coverThumbnailWebp": {name: "coverThumbnailWebp", solrField: "cover_thumbnail_webp_s"},
+	"ownerID":            {name: "ownerID", solrField: "owner_id"},
+	"available":          {name: "available", solrField: "available"},
+	"childFriendly":      {name: "childFriendly", solrField: "child_friendly_b"},
+	"infantFriendly":     {name: "infantFriendly", solrField: "infant_friendly_b"},
+	"petsAllowed":        {name: "petsAllowed", solrField: "pets_allowed_b"},
+	"smokingAllowed":     {name: "smokingAllowed", solrField: "smoking_allowed_b"},
+	"eventsAllowed":      {name: "eventsAllowed", solrField: "events_allowed_b"},
+	"instantBook":        {name: "instantBook", solrField: "instant_book_b"},
+	"cancellationPolicy": {name: "cancellationPolicy", solrField: "cancellation_policy_s"},
+	"popularity":         {name: "popularity", solrField: "popularity"},
+	"nextAvailableDate":  {name: "nextAvailableDate", solrField: "next_available_date_dt"},
+	"createdAt":          {name: "createdAt", solrField: "created_at"},
+	"created_at":         {name: "createdAt", solrField: "created_at"},
+}
+
+// ParseResponseFields parsea fields como una lista separada por comas (ej: "id,title,price,images[0]")
+// Los campos lista aceptan un índice entre corchetes para devolver solo ese elemento
+func ParseResponseFields(fields string) ([]dto.FieldSelection, error) {
+	fields = strings.TrimSpace(fields)
+	if fields == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(fields, ",")
+	selection := make([]dto.FieldSelection, 0, len(parts))
+	// Por cada campo se recuerda si se pidió completo (-1) o los índices pedidos
+	requested := make(map[string]map[int]bool, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return nil, fmt.Errorf("fields tiene un campo vacío")
+		}
+
+		name, index, err := splitFieldIndex(part)
+		if err != nil {
+			return nil, err
+		}
+
+		field, ok := selectableFields[name]
+		if !ok {
+			return nil, fmt.Errorf("el campo '%s' no se puede seleccionar en fields", name)
+		}
+		if index >= 0 && !field.list {
+			return nil, fmt.Errorf("el campo '%s' no es una lista y no admite índice", name)
+		}
+
+		indexes := requested[field.name]
+		if indexes == nil {
+			indexes = make(map[int]bool)
+			requested[field.name] = indexes
+		}
+		if indexes[index] || (len(indexes) > 0 && (index < 0 || indexes[-1])) {
+			return nil, fmt.Errorf("el campo '%s' está repetido en fields", name)
+		}
+		indexes[index] = true
+
+		selection = append(selection, dto.FieldSelection{Name: field.name, SolrField: field.solrField, Index: index})
+	}
+	return selection, nil
+}
+
+// splitFieldIndex separa "images[0]" en ("images", 0); sin corchetes el índice es -1
+func splitFieldIndex(part string) (string, int, error) {
+	open := strings.Index(part, "[")
+	if open < 0 {
+		return part, -1, nil
+	}
+	if !strings.HasSuffix(part, "]") {
+		return "", 0, fmt.Errorf("campo inválido en fields: '%s' (formato: campo o campo[índice])", part)
+	}
+
+	index, err := strconv.Atoi(part[open+1 : len(part)-1])
+	if err != nil || index < 0 || index > maxListIndex {
+		return "", 0, fmt.Errorf("índice inválido en fields: '%s' (debe estar entre 0 y %d)", part, maxListIndex)
+	}
+	return part[:open], index, nil
+}
+
+// formatResponseFields retorna los campos de Solr de la selección (ej: "id,images,price")
+func formatResponseFields(fields []dto.FieldSelection) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.Join(dto.SolrFieldNames(fields), ",")
+}
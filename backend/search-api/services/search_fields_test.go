@@ -0,0 +1,125 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"search-api/dto"
+)
+
+func TestParseResponseFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  string
+		want    []dto.FieldSelection
+		wantErr bool
+	}{
+		{name: "empty", fields: " ", want: nil},
+		{
+			name:   "plain fields and aliases",
+			fields: "id, title ,price,type",
+			want: []dto.FieldSelection{
+				{Name: "id", SolrField: "id", Index: -1},
+				{Name: "title", SolrField: "title", Index: -1},
+				{Name: "pricePerNight", SolrField: "price", Index: -1},
+				{Name: "propertyType", SolrField: "property_type_s", Index: -1},
+			},
+		},
+		{
+			name:   "list indexes",
+			fields: "images[0],images[49]",
+			want: []dto.FieldSelection{
+				{Name: "images", SolrField: "images", Index: 0},
+				{Name: "images", SolrField: "images", Index: 49},
+			},
+		},
+		{
+			name:   "latitude and longitude share the solr field",
+			fields: "latitude,longitude",
+			want: []dto.FieldSelection{
+				{Name: "latitude", SolrField: "geo_p", Index: -1},
+				{Name: "longitude", SolrField: "geo_p", Index: -1},
+			},
+		},
+		{name: "unknown field", fields: "id,password", wantErr: true},
+		{name: "solr field name", fields: "property_type_s", wantErr: true},
+		{name: "solr pseudo field", fields: "score", wantErr: true},
+		{name: "solr function", fields: "sum(price,1)", wantErr: true},
+		{name: "empty field between commas", fields: "id,,title", wantErr: true},
+		{name: "trailing comma", fields: "id,", wantErr: true},
+		{name: "index on a non list field", fields: "title[0]", wantErr: true},
+		{name: "repeated field", fields: "title,title", wantErr: true},
+		{name: "repeated field through an alias", fields: "price,pricePerNight", wantErr: true},
+		{name: "repeated index", fields: "images[1],images[1]", wantErr: true},
+		{name: "whole list and an index", fields: "images,images[0]", wantErr: true},
+		{name: "index and then the whole list", fields: "images[0],images", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseResponseFields(tt.fields)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error for %q, got %+v", tt.fields, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSplitFieldIndex(t *testing.T) {
+	tests := []struct {
+		part      string
+		wantName  string
+		wantIndex int
+		wantErr   bool
+	}{
+		{part: "title", wantName: "title", wantIndex: -1},
+		{part: "images[0]", wantName: "images", wantIndex: 0},
+		{part: "images[49]", wantName: "images", wantIndex: maxListIndex},
+		{part: "images[50]", wantErr: true},
+		{part: "images[-1]", wantErr: true},
+		{part: "images[]", wantErr: true},
+		{part: "images[a]", wantErr: true},
+		{part: "images[1", wantErr: true},
+		{part: "images[1]x", wantErr: true},
+		{part: "images[1][2]", wantErr: true},
+		{part: "images[ 1]", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.part, func(t *testing.T) {
+			name, index, err := splitFieldIndex(tt.part)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error for %q, got %q[%d]", tt.part, name, index)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if name != tt.wantName || index != tt.wantIndex {
+				t.Fatalf("expected %q[%d], got %q[%d]", tt.wantName, tt.wantIndex, name, index)
+			}
+		})
+	}
+}
+
+func TestFormatResponseFields_AlwaysIncludesTheIDOnce(t *testing.T) {
+	fields, err := ParseResponseFields("price,latitude,longitude,images[0],images[2]")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := formatResponseFields(fields); got != "id,geo_p,images,price" {
+		t.Fatalf("expected id plus each solr field once, got %q", got)
+	}
+	if got := formatResponseFields(nil); got != "" {
+		t.Fatalf("expected no fl without fields, got %q", got)
+	}
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"resilience"
+	"search-api/dto"
+	"search-api/repositories"
+)
+
+// blockingIndex cuenta las búsquedas y las demora hasta que se cierra release
+type blockingIndex struct {
+	benchmarkIndex
+	calls   atomic.Int32
+	started chan struct{}
+	release chan struct{}
+}
+
+func (i *blockingIndex) Search(ctx context.Context, request dto.SearchRequest) (dto.SearchResult, error) {
+	if i.calls.Add(1) == 1 {
+		close(i.started)
+	}
+	select {
+	case <-i.release:
+		return i.result, nil
+	case <-ctx.Done():
+		return dto.SearchResult{}, ctx.Err()
+	}
+}
+
+func newFlightSearchService(t *testing.T) (SearchService, *blockingIndex) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	index := &blockingIndex{
+		benchmarkIndex: benchmarkIndex{result: benchmarkSearchResult()},
+		started:        make(chan struct{}),
+		release:        make(chan struct{}),
+	}
+	service := NewSearchService(index, repositories.NewCacheRepository(newBenchmarkRemoteCache(), time.Hour), nil,
+		resilience.CircuitBreakerSettings{FailureThreshold: 5, OpenTimeout: time.Second, HalfOpenMaxRequests: 1},
+		resilience.RetryPolicy{MaxAttempts: 1},
+		CacheTTLPolicy{Default: time.Hour, Broad: time.Hour, Specific: time.Hour},
+		nil,
+		0,
+	)
+	return service, index
+}
+
+func TestSearch_ConcurrentIdenticalSearchesQueryTheIndexOnce(t *testing.T) {
+	service, index := newFlightSearchService(t)
+	request := dto.SearchRequest{Query: "departamento", City: "Córdoba"}
+
+	const searches = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, searches)
+	for i := 0; i < searches; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response, err := service.Search(context.Background(), request)
+			if err == nil && response.TotalResults != 120 {
+				err = errors.New("unexpected total")
+			}
+			errs <- err
+		}()
+	}
+
+	// Se libera el índice cuando todos los requests ya están esperando la misma consulta
+	<-index.started
+	time.Sleep(50 * time.Millisecond)
+	close(index.release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("search failed: %v", err)
+		}
+	}
+	if calls := index.calls.Load(); calls != 1 {
+		t.Fatalf("expected a single index query for %d identical searches, got %d", searches, calls)
+	}
+}
+
+func TestSearch_CancelledWaiterDoesNotCancelTheSharedQuery(t *testing.T) {
+	service, index := newFlightSearchService(t)
+	request := dto.SearchRequest{Query: "departamento"}
+
+	// El primer request abandona la búsqueda mientras el índice responde
+	firstCtx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := service.Search(firstCtx, request)
+		firstErr <- err
+	}()
+	<-index.started
+
+	secondDone := make(chan error, 1)
+	go func() {
+		_, err := service.Search(context.Background(), request)
+		secondDone <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the cancelled request to return context.Canceled, got %v", err)
+	}
+
+	close(index.release)
+	if err := <-secondDone; err != nil {
+		t.Fatalf("expected the other request to get the shared result, got %v", err)
+	}
+	if calls := index.calls.Load(); calls != 1 {
+		t.Fatalf("expected a single index query, got %d", calls)
+	}
+
+	// El resultado compartido quedó en caché
+	if _, err := service.Search(context.Background(), request); err != nil || index.calls.Load() != 1 {
+		t.Fatalf("expected the next search served from cache, got %v with %d index calls", err, index.calls.Load())
+	}
+}
+
+func TestSearch_BypassDoesNotShareTheCachedFlight(t *testing.T) {
+	service, index := newFlightSearchService(t)
+	close(index.release)
+
+	// bypass no escribe el caché: con la misma key, la búsqueda normal siguiente va igual al índice
+	if _, err := service.Search(context.Background(), dto.SearchRequest{Query: "casa", CacheMode: dto.CacheModeBypass}); err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if _, err := service.Search(context.Background(), dto.SearchRequest{Query: "casa"}); err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if calls := index.calls.Load(); calls != 2 {
+		t.Fatalf("expected the bypass search not to populate the cache, got %d index calls", calls)
+	}
+}
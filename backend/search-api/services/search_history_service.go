@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"search-api/domain"
+	"search-api/dto"
+	"search-api/repositories"
+)
+
+// ErrHistoryEntryNotFound se retorna al quitar una búsqueda que no está en el historial
+var ErrHistoryEntryNotFound = errors.New("la búsqueda no está en el historial")
+
+// SearchHistoryService define la interfaz del historial de búsquedas de los usuarios autenticados
+// Lo usa la UI de "búsquedas recientes" y el motor de recomendaciones (a través de callers internos)
+type SearchHistoryService interface {
+	// Record guarda la búsqueda en el historial del usuario (solo la primera página: paginar no es buscar de nuevo)
+	// Un error solo se loguea: no guardar el historial no hace fallar la búsqueda
+	Record(ctx context.Context, userID uint, request dto.SearchRequest, resultCount int)
+
+	// List retorna las últimas limit búsquedas del usuario dentro de la retención (limit <= 0 = todas)
+	List(ctx context.Context, userID uint, limit int) (dto.SearchHistoryResponse, error)
+
+	// Remove quita una búsqueda del historial por su ID
+	Remove(ctx context.Context, userID uint, id string) error
+
+	// Clear borra el historial del usuario
+	Clear(ctx context.Context, userID uint) error
+}
+
+// searchHistoryService es la implementación concreta de SearchHistoryService
+type searchHistoryService struct {
+	repo      repositories.SearchHistoryRepository
+	retention time.Duration
+}
+
+// NewSearchHistoryService crea una nueva instancia del servicio de historial
+// Las búsquedas más viejas que retention no se devuelven (0 = sin vencimiento)
+func NewSearchHistoryService(repo repositories.SearchHistoryRepository, retention time.Duration) SearchHistoryService {
+	return &searchHistoryService{
+		repo:      repo,
+		retention: retention,
+	}
+}
+
+// Record guarda la búsqueda con los filtros necesarios para repetirla
+func (s *searchHistoryService) Record(ctx context.Context, userID uint, request dto.SearchRequest, resultCount int) {
+	if request.Page > 1 {
+		return
+	}
+
+	err := s.repo.Add(ctx, userID, domain.SearchEvent{
+		Query:       normalizeQuery(request.Query),
+		Filters:     historyFilters(request),
+		ResultCount: resultCount,
+		SearchedAt:  time.Now().UTC(),
+	})
+	if err != nil {
+		log.Printf("⚠️ No se pudo guardar la búsqueda en el historial: %v", err)
+	}
+}
+
+// List retorna el historial del usuario, de la búsqueda más reciente a la más antigua
+func (s *searchHistoryService) List(ctx context.Context, userID uint, limit int) (dto.SearchHistoryResponse, error) {
+	events, err := s.activeEvents(ctx, userID)
+	if err != nil {
+		return dto.SearchHistoryResponse{}, err
+	}
+	if limit > 0 && len(events) > limit {
+		events = events[:limit]
+	}
+
+	searches := make([]dto.SearchHistoryEntry, 0, len(events))
+	for _, event := range events {
+		searches = append(searches, dto.SearchHistoryEntry{
+			ID:          historyEntryID(event),
+			Query:       event.Query,
+			Filters:     event.Filters,
+			ResultCount: event.ResultCount,
+			SearchedAt:  event.SearchedAt,
+		})
+	}
+	return dto.SearchHistoryResponse{Searches: searches}, nil
+}
+
+// Remove quita una búsqueda del historial
+func (s *searchHistoryService) Remove(ctx context.Context, userID uint, id string) error {
+	events, err := s.repo.List(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		if historyEntryID(event) != id {
+			continue
+		}
+		removed, err := s.repo.Remove(ctx, userID, event)
+		if err != nil {
+			return err
+		}
+		if removed {
+			return nil
+		}
+	}
+	return ErrHistoryEntryNotFound
+}
+
+// Clear borra el historial del usuario
+func (s *searchHistoryService) Clear(ctx context.Context, userID uint) error {
+	return s.repo.Clear(ctx, userID)
+}
+
+// activeEvents retorna las búsquedas del usuario que no superaron la retención
+func (s *searchHistoryService) activeEvents(ctx context.Context, userID uint) ([]domain.SearchEvent, error) {
+	events, err := s.repo.List(ctx, userID)
+	if err != nil || s.retention <= 0 {
+		return events, err
+	}
+
+	cutoff := time.Now().UTC().Add(-s.retention)
+	active := events[:0]
+	for _, event := range events {
+		if event.SearchedAt.After(cutoff) {
+			active = append(active, event)
+		}
+	}
+	return active, nil
+}
+
+// historyEntryID identifica una búsqueda por su término y filtros (mismo criterio que la deduplicación)
+func historyEntryID(event domain.SearchEvent) string {
+	sum := sha1.Sum([]byte(event.Query + "|" + filtersKey(event.Filters)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// historyFilters son los filtros de analytics más el tipo y las coordenadas del bounding box,
+// así el frontend puede repetir la búsqueda tal cual
+func historyFilters(request dto.SearchRequest) map[string]string {
+	filters := searchFilters(request)
+	if filters == nil {
+		filters = make(map[string]string)
+	}
+
+	if request.Type != "" {
+		filters["type"] = request.Type
+	}
+	if request.HasBoundingBox() {
+		delete(filters, "bbox")
+		filters["bboxMinLat"] = strconv.FormatFloat(*request.BboxMinLat, 'f', -1, 64)
+		filters["bboxMinLng"] = strconv.FormatFloat(*request.BboxMinLng, 'f', -1, 64)
+		filters["bboxMaxLat"] = strconv.FormatFloat(*request.BboxMaxLat, 'f', -1, 64)
+		filters["bboxMaxLng"] = strconv.FormatFloat(*request.BboxMaxLng, 'f', -1, 64)
+	}
+
+	if len(filters) == 0 {
+		return nil
+	}
+	return filters
+}
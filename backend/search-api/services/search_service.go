@@ -3,65 +3,108 @@ package services
 import (
 	"context"
 	"crypto/md5"
+	"crypto/rand"
 	"encoding/hex"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log"
 	"math"
-	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
+	"resilience"
 	"search-api/domain"
 	"search-api/dto"
 	"search-api/repositories"
+	"search-api/rpc"
+	"search-api/utils"
+
+	"golang.org/x/sync/singleflight"
 )
 
 // SearchService define la interfaz para las operaciones de búsqueda
 type SearchService interface {
-	// Search realiza una búsqueda de propiedades con caché y Solr
+	// Search realiza una búsqueda de propiedades con caché y el índice de búsqueda
 	Search(ctx context.Context, request dto.SearchRequest) (*dto.SearchResponse, error)
 
-	// IndexProperty indexa una nueva propiedad en Solr e invalida caché
+	// IndexProperty indexa una nueva propiedad en el índice e invalida caché
 	IndexProperty(ctx context.Context, property domain.Property) error
 
-	// UpdateProperty actualiza una propiedad en Solr e invalida caché
+	// UpdateProperty actualiza una propiedad en el índice e invalida caché
 	UpdateProperty(ctx context.Context, property domain.Property) error
 
-	// DeleteProperty elimina una propiedad de Solr e invalida caché
+	// DeleteProperty elimina una propiedad del índice e invalida caché
 	DeleteProperty(ctx context.Context, propertyID string) error
 
+	// DeleteOwnerProperties elimina del índice todas las propiedades de un usuario e invalida caché
+	DeleteOwnerProperties(ctx context.Context, ownerID uint) error
+
 	// FetchPropertyFromAPI obtiene una propiedad desde la API de propiedades
 	FetchPropertyFromAPI(propertyID string) (*domain.Property, error)
+
+	// FetchPropertiesFromAPI obtiene un lote de propiedades (máximo maxPropertiesBatch); las inexistentes se omiten
+	FetchPropertiesFromAPI(ctx context.Context, propertyIDs []string) ([]domain.Property, error)
+
+	// PropertyFromSnapshot convierte el snapshot recibido en un evento a una propiedad
+	PropertyFromSnapshot(snapshot dto.PropertySnapshot) (*domain.Property, error)
+
+	// UpdatePopularity actualiza en el índice la popularidad (vistas) de un lote de propiedades
+	UpdatePopularity(ctx context.Context, views map[string]int64) error
+
+	// UpdateAvailability actualiza en el índice los días ocupados de una propiedad
+	UpdateAvailability(ctx context.Context, propertyID string, booked []domain.DateRange, nextAvailable string) error
 }
 
 // searchService es la implementación concreta de SearchService
 type searchService struct {
-	solrRepo         repositories.SolrRepository
+	index            repositories.SearchIndex
 	cacheRepo        repositories.CacheRepository
-	propertiesAPIURL string
-	httpClient       *http.Client
+	propertiesClient rpc.PropertiesServiceClient
+	apiBreaker       *resilience.CircuitBreaker
+	apiRetry         resilience.RetryPolicy
+	cacheTTL         CacheTTLPolicy
+	engagement       EngagementService
+	indexFlight      singleflight.Group
+	slowThreshold    time.Duration
 }
 
+// ErrHydrationUnavailable se retorna cuando una búsqueda con hydrate=true no pudo obtener
+// los documentos de properties-api: no se responde con los del índice porque podrían estar desactualizados
+var ErrHydrationUnavailable = errors.New("no se pudieron obtener las propiedades actualizadas de properties-api")
+
+// indexFlightTimeout limita la consulta compartida al índice, que no se cancela con el request que la inició
+const indexFlightTimeout = 30 * time.Second
+
 // NewSearchService crea una nueva instancia del servicio de búsqueda
+// engagement reordena las búsquedas con personalized=true según los clicks y reservas del usuario
+// Las búsquedas que tardan slowThreshold o más se loguean con la consulta y los tiempos (0 = no se loguean)
 func NewSearchService(
-	solrRepo repositories.SolrRepository,
+	index repositories.SearchIndex,
 	cacheRepo repositories.CacheRepository,
-	apiURL string,
+	propertiesClient rpc.PropertiesServiceClient,
+	apiBreaker resilience.CircuitBreakerSettings,
+	apiRetry resilience.RetryPolicy,
+	cacheTTL CacheTTLPolicy,
+	engagement EngagementService,
+	slowThreshold time.Duration,
 ) SearchService {
 	return &searchService{
-		solrRepo:         solrRepo,
+		index:            index,
 		cacheRepo:        cacheRepo,
-		propertiesAPIURL: strings.TrimSuffix(apiURL, "/"),
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		propertiesClient: propertiesClient,
+		apiBreaker:       resilience.NewCircuitBreaker("properties-api", apiBreaker),
+		apiRetry:         apiRetry,
+		cacheTTL:         cacheTTL,
+		engagement:       engagement,
+		slowThreshold:    slowThreshold,
 	}
 }
 
 // Search realiza una búsqueda de propiedades con estrategia de caché de dos niveles
 func (s *searchService) Search(ctx context.Context, request dto.SearchRequest) (*dto.SearchResponse, error) {
+	start := time.Now()
+
 	// Validar request
 	if err := s.validateSearchRequest(&request); err != nil {
 		return nil, fmt.Errorf("request inválido: %w", err)
@@ -71,31 +114,242 @@ func (s *searchService) Search(ctx context.Context, request dto.SearchRequest) (
 	cacheKey := s.generateCacheKey(request)
 	log.Printf("🔍 Iniciando búsqueda con cache key: %s", cacheKey)
 
-	// Consultar caché primero
-	properties, total, found := s.cacheRepo.Get(cacheKey)
-	if found {
-		log.Printf("✅ Cache hit para key: %s", cacheKey)
-		return s.buildSearchResponse(properties, total, request), nil
+	// Tiempos de cada etapa para el log de búsquedas lentas y debug=true
+	ctx, trace := repositories.WithSearchTrace(ctx)
+
+	// Consultar caché primero (salvo que un caller privilegiado pida leer del índice)
+	var result dto.SearchResult
+	source := dto.SearchSourceIndex
+	cacheStatus := dto.SearchCacheStatus{Status: dto.CacheStatusBypass}
+	if request.CacheMode == "" {
+		lookupStart := time.Now()
+		cached, level, found := s.cacheRepo.GetWithLevel(cacheKey)
+		trace.Since(repositories.TraceCacheLookup, lookupStart)
+		cacheStatus = dto.SearchCacheStatus{Status: dto.CacheStatusMiss}
+		if found {
+			log.Printf("✅ Cache hit para key: %s", cacheKey)
+			result, source = cached, dto.SearchSourceCache
+			cacheStatus = dto.SearchCacheStatus{Status: dto.CacheStatusHit, Level: level}
+		} else {
+			log.Printf("❌ Cache miss para key: %s, consultando el índice", cacheKey)
+		}
+	} else {
+		log.Printf("🔁 Caché omitido (modo %s) para key: %s, consultando el índice", request.CacheMode, cacheKey)
+	}
+
+	// Consultar el índice (una sola consulta por key aunque haya muchos requests concurrentes)
+	if source != dto.SearchSourceCache {
+		indexResult, shared, err := s.searchIndexOnce(ctx, cacheKey, request)
+		if err != nil {
+			return nil, err
+		}
+		result = indexResult
+		if shared {
+			source = dto.SearchSourceShared
+		}
+	}
+
+	// Con hydrate=true el resultado (del caché o del índice) solo tiene IDs y scores
+	if request.Hydrate {
+		hydrationStart := time.Now()
+		properties, err := s.hydrate(ctx, result.Properties)
+		trace.Since(repositories.TraceHydration, hydrationStart)
+		if err != nil {
+			return nil, err
+		}
+		result.Properties = properties
+	}
+
+	mappingStart := time.Now()
+	response := s.rerankPersonalized(ctx, s.buildSearchResponse(result, request), request)
+	trace.Since(repositories.TraceMapping, mappingStart)
+
+	elapsed := time.Since(start)
+	response.SearchID = newSearchID()
+	response.Meta = &dto.SearchMeta{
+		Query:           request.Query,
+		Filters:         appliedSearchFilters(request),
+		SortBy:          request.SortBy,
+		Lang:            request.Lang,
+		ExecutionTimeMs: durationMs(elapsed),
+		Cache:           cacheStatus,
+	}
+
+	s.diagnose(request, cacheKey, source, trace, response, elapsed)
+	return response, nil
+}
+
+// newSearchID genera el ID de una búsqueda (32 caracteres hexadecimales aleatorios)
+func newSearchID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// appliedSearchFilters son los filtros del request con el valor que se aplicó (para el eco en SearchMeta)
+// Parte de los filtros de analytics y reemplaza los que allí solo se marcan (fechas y bounding box)
+func appliedSearchFilters(request dto.SearchRequest) map[string]string {
+	filters := searchFilters(request)
+	if filters == nil {
+		filters = make(map[string]string)
+	}
+	if request.City != "" {
+		filters["city"] = request.City
+	}
+	if request.Country != "" {
+		filters["country"] = request.Country
+	}
+	if request.Type != "" {
+		filters["type"] = request.Type
+	}
+	if request.HasStayDates() {
+		delete(filters, "dates")
+		filters["checkIn"], filters["checkOut"] = request.CheckIn, request.CheckOut
+	}
+	if request.HasBoundingBox() {
+		filters["bbox"] = fmt.Sprintf("%g,%g,%g,%g", *request.BboxMinLat, *request.BboxMinLng, *request.BboxMaxLat, *request.BboxMaxLng)
+	}
+	return filters
+}
+
+// diagnose loguea las búsquedas lentas con la consulta al índice y los tiempos de cada etapa,
+// y con debug=true agrega el mismo diagnóstico a la respuesta
+func (s *searchService) diagnose(request dto.SearchRequest, cacheKey, source string, trace *repositories.SearchTrace, response *dto.SearchResponse, total time.Duration) {
+	slow := s.slowThreshold > 0 && total >= s.slowThreshold
+	if !slow && !request.Debug {
+		return
+	}
+
+	debug := &dto.SearchDebug{
+		Backend:  s.index.Name(),
+		Query:    s.index.DescribeSearch(request),
+		CacheKey: cacheKey,
+		Source:   source,
+		Timings: dto.SearchTimings{
+			CacheLookupMs: durationMs(trace.Get(repositories.TraceCacheLookup)),
+			IndexMs:       durationMs(trace.Get(repositories.TraceIndex)),
+			IndexEngineMs: durationMs(trace.Get(repositories.TraceIndexEngine)),
+			MappingMs:     durationMs(trace.Get(repositories.TraceMapping)),
+			HydrationMs:   durationMs(trace.Get(repositories.TraceHydration)),
+			TotalMs:       durationMs(total),
+		},
+		TotalResults: response.TotalResults,
+		Returned:     len(response.Results),
+	}
+
+	if slow {
+		log.Printf("🐢 Búsqueda lenta (%.1fms, %s): caché %.1fms, índice %.1fms (motor %.1fms), hidratación %.1fms, mapeo %.1fms, %d resultados (%d devueltos). Consulta: %s",
+			debug.Timings.TotalMs, debug.Source, debug.Timings.CacheLookupMs, debug.Timings.IndexMs, debug.Timings.IndexEngineMs,
+			debug.Timings.HydrationMs, debug.Timings.MappingMs, debug.TotalResults, debug.Returned, debug.Query)
+	}
+	if request.Debug {
+		response.Debug = debug
 	}
+}
 
-	log.Printf("❌ Cache miss para key: %s, consultando Solr", cacheKey)
+// hydrate reemplaza los resultados del índice (solo ID y score) por las propiedades de properties-api,
+// en un solo lote (pageSize no supera maxPropertiesBatch) y manteniendo el orden y el score del índice
+// Las propiedades que properties-api ya no devuelve (eliminadas o retenidas desde que se indexaron) se omiten;
+// totalResults sigue siendo el del índice
+func (s *searchService) hydrate(ctx context.Context, indexed []domain.Property) ([]domain.Property, error) {
+	if len(indexed) == 0 {
+		return indexed, nil
+	}
 
-	// Consultar Solr
-	properties, total, err := s.solrRepo.Search(ctx, request)
+	ids := make([]string, 0, len(indexed))
+	for _, property := range indexed {
+		ids = append(ids, property.ID)
+	}
+	fresh, err := s.FetchPropertiesFromAPI(ctx, ids)
 	if err != nil {
-		return nil, fmt.Errorf("error buscando en Solr: %w", err)
+		return nil, fmt.Errorf("%w: %v", ErrHydrationUnavailable, err)
+	}
+
+	byID := make(map[string]domain.Property, len(fresh))
+	for _, property := range fresh {
+		byID[property.ID] = property
+	}
+	// Slice nuevo: indexed puede ser el resultado guardado en el caché local
+	hydrated := make([]domain.Property, 0, len(indexed))
+	for _, property := range indexed {
+		current, found := byID[property.ID]
+		if !found {
+			log.Printf("ℹ️ La propiedad %s está en el índice pero properties-api ya no la devuelve", property.ID)
+			continue
+		}
+		current.Score = property.Score
+		hydrated = append(hydrated, current)
 	}
+	return hydrated, nil
+}
+
+// durationMs convierte una duración a milisegundos con 3 decimales
+func durationMs(duration time.Duration) float64 {
+	return roundTo(float64(duration)/float64(time.Millisecond), 3)
+}
+
+// rerankPersonalized es la etapa de reordenamiento de las búsquedas con personalized=true
+// Se aplica después del caché (que guarda el orden del índice, igual para todos los usuarios)
+// y no se aplica si el usuario pidió un orden explícito con sortBy
+func (s *searchService) rerankPersonalized(ctx context.Context, response *dto.SearchResponse, request dto.SearchRequest) *dto.SearchResponse {
+	if !request.Personalized || request.UserID == 0 || len(request.Sort) > 0 {
+		return response
+	}
+
+	results, reranked := s.engagement.Rerank(ctx, request.UserID, response.Results)
+	if reranked {
+		response.Results = results
+		response.Personalized = true
+		log.Printf("🎯 Resultados reordenados según las interacciones del usuario %d", request.UserID)
+	}
+	return response
+}
 
-	log.Printf("✅ Búsqueda en Solr completada: %d resultados encontrados", total)
+// searchIndexOnce consulta el índice y guarda el resultado en caché usando singleflight:
+// los requests idénticos que llegan mientras hay una consulta en curso esperan
+// y comparten ese resultado en lugar de ir al índice cada uno
+// shared indica si el resultado se compartió con otro request (los tiempos del índice quedan en el trace de ese request)
+func (s *searchService) searchIndexOnce(ctx context.Context, cacheKey string, request dto.SearchRequest) (dto.SearchResult, bool, error) {
+	// El modo de caché forma parte de la key: bypass no debe escribir el caché
+	flightKey := request.CacheMode + "|" + cacheKey
+
+	resultChan := s.indexFlight.DoChan(flightKey, func() (interface{}, error) {
+		// La consulta compartida no depende de la cancelación del primer request
+		indexCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), indexFlightTimeout)
+		defer cancel()
+
+		result, err := s.index.Search(indexCtx, request)
+		if err != nil {
+			return nil, fmt.Errorf("error buscando en el índice: %w", err)
+		}
+
+		log.Printf("✅ Búsqueda en el índice completada: %d resultados encontrados", result.Total)
 
-	// Guardar resultado en caché con TTL de 15 minutos
-	s.cacheRepo.Set(cacheKey, properties, total, 15*time.Minute)
-	log.Printf("✅ Resultados guardados en caché para key: %s", cacheKey)
+		// Guardar resultado en caché con el TTL de la política (en modo bypass no se toca el caché)
+		if request.CacheMode != dto.CacheModeBypass {
+			ttl := s.cacheTTL.TTLFor(request)
+			s.cacheRepo.Set(cacheKey, result, ttl)
+			log.Printf("✅ Resultados guardados en caché para key: %s (TTL: %v)", cacheKey, ttl)
+		}
 
-	return s.buildSearchResponse(properties, total, request), nil
+		return result, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return dto.SearchResult{}, false, ctx.Err()
+	case res := <-resultChan:
+		if res.Err != nil {
+			return dto.SearchResult{}, false, res.Err
+		}
+		if res.Shared {
+			log.Printf("🤝 Resultado del índice compartido para key: %s", cacheKey)
+		}
+		return res.Val.(dto.SearchResult), res.Shared, nil
+	}
 }
 
-// IndexProperty indexa una nueva propiedad en Solr e invalida caché
+// IndexProperty indexa una nueva propiedad en el índice e invalida caché
 func (s *searchService) IndexProperty(ctx context.Context, property domain.Property) error {
 	// Validar propiedad
 	if err := s.validateProperty(&property); err != nil {
@@ -104,12 +358,12 @@ func (s *searchService) IndexProperty(ctx context.Context, property domain.Prope
 
 	log.Printf("📝 Indexando propiedad ID: %s", property.ID)
 
-	// Indexar en Solr
-	if err := s.solrRepo.IndexProperty(ctx, property); err != nil {
-		return fmt.Errorf("error indexando propiedad en Solr: %w", err)
+	// Indexar
+	if err := s.index.IndexProperty(ctx, property); err != nil {
+		return fmt.Errorf("error indexando propiedad en el índice: %w", err)
 	}
 
-	log.Printf("✅ Propiedad indexada exitosamente en Solr: %s", property.ID)
+	log.Printf("✅ Propiedad indexada exitosamente en el índice: %s", property.ID)
 
 	// Invalidar caché (eliminar todas las keys relacionadas)
 	s.invalidateCache()
@@ -117,7 +371,7 @@ func (s *searchService) IndexProperty(ctx context.Context, property domain.Prope
 	return nil
 }
 
-// UpdateProperty actualiza una propiedad en Solr e invalida caché
+// UpdateProperty actualiza una propiedad en el índice e invalida caché
 func (s *searchService) UpdateProperty(ctx context.Context, property domain.Property) error {
 	// Validar propiedad
 	if err := s.validateProperty(&property); err != nil {
@@ -126,12 +380,12 @@ func (s *searchService) UpdateProperty(ctx context.Context, property domain.Prop
 
 	log.Printf("🔄 Actualizando propiedad ID: %s", property.ID)
 
-	// Actualizar en Solr
-	if err := s.solrRepo.UpdateProperty(ctx, property); err != nil {
-		return fmt.Errorf("error actualizando propiedad en Solr: %w", err)
+	// Actualizar en el índice
+	if err := s.index.UpdateProperty(ctx, property); err != nil {
+		return fmt.Errorf("error actualizando propiedad en el índice: %w", err)
 	}
 
-	log.Printf("✅ Propiedad actualizada exitosamente en Solr: %s", property.ID)
+	log.Printf("✅ Propiedad actualizada exitosamente en el índice: %s", property.ID)
 
 	// Invalidar caché
 	s.invalidateCache()
@@ -139,7 +393,7 @@ func (s *searchService) UpdateProperty(ctx context.Context, property domain.Prop
 	return nil
 }
 
-// DeleteProperty elimina una propiedad de Solr e invalida caché
+// DeleteProperty elimina una propiedad del índice e invalida caché
 func (s *searchService) DeleteProperty(ctx context.Context, propertyID string) error {
 	// Validar ID
 	if propertyID == "" {
@@ -148,20 +402,86 @@ func (s *searchService) DeleteProperty(ctx context.Context, propertyID string) e
 
 	log.Printf("🗑️ Eliminando propiedad ID: %s", propertyID)
 
-	// Eliminar de Solr
-	if err := s.solrRepo.DeleteProperty(ctx, propertyID); err != nil {
-		return fmt.Errorf("error eliminando propiedad de Solr: %w", err)
+	// Eliminar del índice
+	if err := s.index.DeleteProperty(ctx, propertyID); err != nil {
+		return fmt.Errorf("error eliminando propiedad del índice: %w", err)
 	}
 
-	log.Printf("✅ Propiedad eliminada exitosamente de Solr: %s", propertyID)
+	log.Printf("✅ Propiedad eliminada exitosamente del índice: %s", propertyID)
 
 	// Invalidar caché
 	s.invalidateCache()
+	s.invalidateSimilar()
 
 	return nil
 }
 
+// OwnerIndexID convierte el ID de owner de properties-api (el ID de users-api como string) al owner_id del índice
+// Se guarda el mismo ID de users-api para que DeleteOwnerProperties con el ID de un evento de usuario lo encuentre
+// Un ID que no es de users-api queda en 0: no es de ningún usuario que se pueda desactivar
+func OwnerIndexID(ownerID string) uint {
+	id, err := strconv.ParseUint(strings.TrimSpace(ownerID), 10, 32)
+	if err != nil {
+		return 0
+	}
+	return uint(id)
+}
+
+// DeleteOwnerProperties elimina del índice todas las propiedades de un usuario e invalida caché
+// Se usa cuando la cuenta del anfitrión se desactiva, para que sus propiedades no aparezcan en búsquedas
+func (s *searchService) DeleteOwnerProperties(ctx context.Context, ownerID uint) error {
+	if ownerID == 0 {
+		return fmt.Errorf("ID de usuario no puede ser 0")
+	}
+
+	log.Printf("🗑️ Eliminando propiedades del usuario ID: %d", ownerID)
+
+	if err := s.index.DeletePropertiesByOwner(ctx, ownerID); err != nil {
+		return fmt.Errorf("error eliminando propiedades del usuario del índice: %w", err)
+	}
+
+	log.Printf("✅ Propiedades del usuario %d eliminadas del índice", ownerID)
+
+	// Invalidar caché
+	s.invalidateCache()
+	s.invalidateSimilar()
+
+	return nil
+}
+
+// UpdatePopularity actualiza en el índice la popularidad (vistas) de un lote de propiedades
+// No invalida el caché: la popularidad cambia en cada volcado y el TTL alcanza para reflejarla
+func (s *searchService) UpdatePopularity(ctx context.Context, views map[string]int64) error {
+	if len(views) == 0 {
+		return nil
+	}
+
+	if err := s.index.UpdatePopularity(ctx, views); err != nil {
+		return fmt.Errorf("error actualizando popularidad en el índice: %w", err)
+	}
+
+	log.Printf("📈 Popularidad actualizada en el índice para %d propiedades", len(views))
+	return nil
+}
+
+// UpdateAvailability actualiza en el índice los días ocupados y el primer día libre de una propiedad
+// Invalida el caché: una búsqueda con fechas cacheada podría seguir mostrando una propiedad ya reservada
+func (s *searchService) UpdateAvailability(ctx context.Context, propertyID string, booked []domain.DateRange, nextAvailable string) error {
+	if propertyID == "" {
+		return fmt.Errorf("ID de propiedad no puede estar vacío")
+	}
+
+	if err := s.index.UpdateAvailability(ctx, propertyID, booked, nextAvailable); err != nil {
+		return fmt.Errorf("error actualizando disponibilidad en el índice: %w", err)
+	}
+
+	log.Printf("📅 Disponibilidad actualizada en el índice para %s (%d rangos ocupados)", propertyID, len(booked))
+	s.invalidateCache()
+	return nil
+}
+
 // FetchPropertyFromAPI obtiene una propiedad desde la API de propiedades
+// Los errores de red y los 5xx se reintentan con backoff; con el circuito abierto falla sin llamar a la API
 func (s *searchService) FetchPropertyFromAPI(propertyID string) (*domain.Property, error) {
 	// Validar ID
 	if propertyID == "" {
@@ -170,78 +490,95 @@ func (s *searchService) FetchPropertyFromAPI(propertyID string) (*domain.Propert
 
 	log.Printf("🌐 Obteniendo propiedad desde API: %s", propertyID)
 
-	// Construir URL
-	url := fmt.Sprintf("%s/properties/%s", s.propertiesAPIURL, propertyID)
-
-	// Crear request HTTP GET
-	req, err := http.NewRequest("GET", url, nil)
+	var property *domain.Property
+	err := resilience.CallWithResilience(context.Background(), s.apiBreaker, s.apiRetry, func(ctx context.Context) error {
+		var err error
+		property, err = s.fetchPropertyOnce(ctx, propertyID)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("error creando request HTTP: %w", err)
+		return nil, err
 	}
-	req.Header.Set("Content-Type", "application/json")
 
-	// Realizar petición
-	resp, err := s.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error realizando petición a properties-api: %w", err)
-	}
-	defer resp.Body.Close()
+	log.Printf("✅ Propiedad obtenida desde API: %s", propertyID)
+	return property, nil
+}
+
+// maxPropertiesBatch es el máximo de propiedades por llamada a GetProperties de properties-api
+const maxPropertiesBatch = 100
 
-	// Verificar código de estado
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("error en respuesta de properties-api (status %d): %s", resp.StatusCode, string(body))
+// FetchPropertiesFromAPI obtiene un lote de propiedades desde la API con el mismo circuit breaker y reintentos
+// que FetchPropertyFromAPI; los IDs que ya no existen no vienen en el resultado
+func (s *searchService) FetchPropertiesFromAPI(ctx context.Context, propertyIDs []string) ([]domain.Property, error) {
+	if len(propertyIDs) > maxPropertiesBatch {
+		return nil, fmt.Errorf("no se pueden pedir más de %d propiedades por llamada", maxPropertiesBatch)
+	}
+	if len(propertyIDs) == 0 {
+		return nil, nil
 	}
 
-	// Leer y parsear respuesta
-	body, err := io.ReadAll(resp.Body)
+	var response *rpc.GetPropertiesResponse
+	err := resilience.CallWithResilience(ctx, s.apiBreaker, s.apiRetry, func(ctx context.Context) error {
+		var err error
+		response, err = s.propertiesClient.GetProperties(ctx, &rpc.GetPropertiesRequest{IDs: propertyIDs})
+		if err != nil {
+			return utils.GRPCCallError("properties-api", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("error leyendo respuesta de properties-api: %w", err)
+		return nil, err
 	}
 
-	log.Printf("📦 Respuesta raw de Properties API: %s", string(body))
+	properties := make([]domain.Property, 0, len(response.Properties))
+	for _, snapshot := range response.Properties {
+		property, err := s.PropertyFromSnapshot(snapshot)
+		if err != nil {
+			log.Printf("⚠️ Propiedad inválida en el lote de properties-api: %v", err)
+			continue
+		}
+		properties = append(properties, *property)
+	}
+	return properties, nil
+}
 
-	// Estructura para parsear la respuesta de Properties API (sin wrapper data)
-	var apiResponse struct {
-		ID          string   `json:"id"`
-		Title       string   `json:"title"`
-		Description string   `json:"description"`
-		Price       float64  `json:"price"`
-		Location    string   `json:"location"`
-		OwnerID     string   `json:"ownerId"`
-		Amenities   []string `json:"amenities"`
-		Capacity    int      `json:"capacity"`
-		Available   bool     `json:"available"`
-		Images      []string `json:"images"`
+// fetchPropertyOnce hace un único intento de FetchPropertyFromAPI
+// La propiedad se pide por gRPC: properties-api no cuenta estas lecturas como vistas
+func (s *searchService) fetchPropertyOnce(ctx context.Context, propertyID string) (*domain.Property, error) {
+	response, err := s.propertiesClient.GetProperty(ctx, &rpc.GetPropertyRequest{ID: propertyID})
+	if err != nil {
+		return nil, utils.GRPCCallError("properties-api", err)
 	}
 
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		log.Printf("❌ Error parseando JSON: %v", err)
-		log.Printf("📄 Body completo: %s", string(body))
-		return nil, fmt.Errorf("error parseando respuesta: %v", err)
+	// Validar que el ID no esté vacío
+	if response.Property.ID == "" {
+		log.Printf("❌ ERROR: properties-api devolvió la propiedad %s sin ID", propertyID)
+		return nil, resilience.Permanent(fmt.Errorf("la API devolvió una propiedad sin ID"))
 	}
 
-	// LOG para debug
-	log.Printf("🔍 ID parseado desde JSON: '%s'", apiResponse.ID)
-	log.Printf("🔍 Title parseado: '%s'", apiResponse.Title)
-	log.Printf("🖼️ Images parseado: %v (len: %d)", apiResponse.Images, len(apiResponse.Images))
+	property, err := s.PropertyFromSnapshot(response.Property)
+	if err != nil {
+		return nil, resilience.Permanent(err)
+	}
+	return property, nil
+}
 
-	// Validar que el ID no esté vacío
+// PropertyFromSnapshot convierte un snapshot de properties-api al modelo de dominio
+func (s *searchService) PropertyFromSnapshot(apiResponse dto.PropertySnapshot) (*domain.Property, error) {
 	if apiResponse.ID == "" {
-		log.Printf("❌ ERROR: ID está vacío después del parseo")
-		log.Printf("📄 Body completo: %s", string(body))
-		return nil, fmt.Errorf("la API devolvió una propiedad sin ID")
+		return nil, fmt.Errorf("el snapshot de la propiedad no tiene ID")
 	}
 
-	// Parsear CreatedAt de string a time.Time
-	var createdAt time.Time
-	createdAt = time.Now()
-
-	// Convertir OwnerID de string a uint
-	var ownerID uint
-	if apiResponse.OwnerID != "" {
-		hash := md5.Sum([]byte(apiResponse.OwnerID))
-		ownerID = uint(hash[0]) | uint(hash[1])<<8 | uint(hash[2])<<16 | uint(hash[3])<<24
+	// Parsear CreatedAt (RFC3339) a time.Time en UTC
+	// Si no viene o es inválido se usa la hora actual
+	createdAt := time.Now().UTC()
+	if parsed, err := time.Parse(time.RFC3339, apiResponse.CreatedAt); err == nil {
+		createdAt = parsed.UTC()
+	}
+	// Los snapshots de eventos viejos no traen UpdatedAt: se toma CreatedAt
+	updatedAt := createdAt
+	if parsed, err := time.Parse(time.RFC3339, apiResponse.UpdatedAt); err == nil {
+		updatedAt = parsed.UTC()
 	}
 
 	// Extraer city y country de location
@@ -259,19 +596,38 @@ func (s *searchService) FetchPropertyFromAPI(propertyID string) (*domain.Propert
 
 	// Mapear cada campo
 	property := &domain.Property{
-		ID:            apiResponse.ID,
-		Title:         apiResponse.Title,
-		Description:   apiResponse.Description,
-		City:          city,
-		Country:       country,
-		PricePerNight: apiResponse.Price,
-		Bedrooms:      0,
-		Bathrooms:     0,
-		MaxGuests:     apiResponse.Capacity,
-		Images:        apiResponse.Images, // ✅ CORRECTO
-		OwnerID:       ownerID,
-		Available:     apiResponse.Available,
-		CreatedAt:     createdAt,
+		ID:                 apiResponse.ID,
+		Title:              apiResponse.Title,
+		Description:        apiResponse.Description,
+		City:               city,
+		Country:            country,
+		PropertyType:       strings.ToLower(strings.TrimSpace(apiResponse.PropertyType)),
+		Latitude:           apiResponse.Latitude,
+		Longitude:          apiResponse.Longitude,
+		PricePerNight:      apiResponse.Price,
+		Bedrooms:           0,
+		Bathrooms:          0,
+		MaxGuests:          apiResponse.Capacity,
+		Images:             apiResponse.ImageURLs(),
+		CoverImage:         apiResponse.CoverURL(),
+		CoverThumbnail:     apiResponse.CoverThumbnail,
+		CoverThumbnailWebP: apiResponse.CoverThumbnailWebP,
+		OwnerID:            OwnerIndexID(apiResponse.OwnerID),
+		Available:          apiResponse.Available,
+		ChildFriendly:      apiResponse.ChildFriendly,
+		InfantFriendly:     apiResponse.InfantFriendly,
+		PetsAllowed:        apiResponse.HouseRules.PetsAllowed,
+		SmokingAllowed:     apiResponse.HouseRules.SmokingAllowed,
+		EventsAllowed:      apiResponse.HouseRules.EventsAllowed,
+		InstantBook:        apiResponse.BookingMode != "request",
+		CancellationPolicy: apiResponse.CancellationPolicy,
+		Popularity:         apiResponse.Views,
+		BookedRanges:       dto.ToDomainDateRanges(apiResponse.BookedRanges),
+		NextAvailableDate:  apiResponse.NextAvailableDate,
+		Language:           apiResponse.Language,
+		Translations:       dto.ToDomainTranslations(apiResponse.Translations),
+		CreatedAt:          createdAt,
+		UpdatedAt:          updatedAt,
 	}
 	// LOG para debug - verificar valores después del mapeo
 	log.Printf("🆔 ID mapeado: '%s'", property.ID)
@@ -286,7 +642,6 @@ func (s *searchService) FetchPropertyFromAPI(propertyID string) (*domain.Propert
 		return nil, fmt.Errorf("ID de propiedad está vacío después del mapeo")
 	}
 
-	log.Printf("✅ Propiedad obtenida desde API: %s", propertyID)
 	return property, nil
 }
 
@@ -319,6 +674,26 @@ func (s *searchService) validateSearchRequest(request *dto.SearchRequest) error
 		return fmt.Errorf("sortOrder debe ser 'asc' o 'desc'")
 	}
 
+	// Parsear sortBy (lista de criterios, solo campos permitidos)
+	sortFields, err := ParseSortFields(request.SortBy, request.SortOrder)
+	if err != nil {
+		return err
+	}
+	request.Sort = sortFields
+
+	// Parsear fields (solo campos seleccionables)
+	projection, err := ParseResponseFields(request.Fields)
+	if err != nil {
+		return err
+	}
+	request.Projection = projection
+
+	// Validar bounding box
+	if request.HasBoundingBox() &&
+		(*request.BboxMinLat > *request.BboxMaxLat || *request.BboxMinLng > *request.BboxMaxLng) {
+		return fmt.Errorf("los mínimos del bounding box no pueden ser mayores que los máximos")
+	}
+
 	return nil
 }
 
@@ -347,27 +722,39 @@ func (s *searchService) generateCacheKey(request dto.SearchRequest) string {
 	if pageSize < 1 {
 		pageSize = 10
 	}
-	sortBy := request.SortBy
-	// sortBy puede estar vacío (sort opcional)
-	sortOrder := request.SortOrder
-	if sortOrder == "" {
-		sortOrder = "asc"
-	}
 
 	// Construir string con todos los parámetros
 	keyParts := []string{
 		fmt.Sprintf("query:%s", request.Query),
 		fmt.Sprintf("city:%s", request.City),
 		fmt.Sprintf("country:%s", request.Country),
+		fmt.Sprintf("type:%s", request.Type),
 		fmt.Sprintf("minPrice:%.2f", request.MinPrice),
 		fmt.Sprintf("maxPrice:%.2f", request.MaxPrice),
 		fmt.Sprintf("bedrooms:%d", request.Bedrooms),
 		fmt.Sprintf("bathrooms:%d", request.Bathrooms),
 		fmt.Sprintf("minGuests:%d", request.MinGuests),
+		fmt.Sprintf("childFriendly:%t", request.ChildFriendly),
+		fmt.Sprintf("infantFriendly:%t", request.InfantFriendly),
+		fmt.Sprintf("petsAllowed:%t", request.PetsAllowed),
+		fmt.Sprintf("smokingAllowed:%t", request.SmokingAllowed),
+		fmt.Sprintf("eventsAllowed:%t", request.EventsAllowed),
+		fmt.Sprintf("instantBook:%t", request.InstantBook),
+		fmt.Sprintf("cancellationPolicy:%s", request.CancellationPolicy),
+		fmt.Sprintf("checkIn:%s", request.CheckIn),
+		fmt.Sprintf("checkOut:%s", request.CheckOut),
+		fmt.Sprintf("lang:%s", request.Lang),
 		fmt.Sprintf("page:%d", page),
 		fmt.Sprintf("pageSize:%d", pageSize),
-		fmt.Sprintf("sortBy:%s", sortBy),
-		fmt.Sprintf("sortOrder:%s", sortOrder),
+		fmt.Sprintf("sort:%s", formatSortFields(request.Sort)),             // criterios ya normalizados
+		fmt.Sprintf("fields:%s", formatResponseFields(request.Projection)), // cambia los campos pedidos al índice
+		fmt.Sprintf("facetLimit:%d", request.EffectiveFacetLimit()),
+		fmt.Sprintf("hydrate:%t", request.Hydrate), // el índice devuelve solo IDs y scores
+	}
+
+	if request.HasBoundingBox() {
+		keyParts = append(keyParts, fmt.Sprintf("bbox:%f,%f,%f,%f",
+			*request.BboxMinLat, *request.BboxMinLng, *request.BboxMaxLat, *request.BboxMaxLng))
 	}
 
 	keyString := strings.Join(keyParts, "|")
@@ -378,7 +765,9 @@ func (s *searchService) generateCacheKey(request dto.SearchRequest) string {
 }
 
 // buildSearchResponse construye una respuesta de búsqueda
-func (s *searchService) buildSearchResponse(properties []domain.Property, total int, request dto.SearchRequest) *dto.SearchResponse {
+func (s *searchService) buildSearchResponse(result dto.SearchResult, request dto.SearchRequest) *dto.SearchResponse {
+	properties, total := result.Properties, result.Total
+
 	page := request.Page
 	if page < 1 {
 		page = 1
@@ -394,23 +783,65 @@ func (s *searchService) buildSearchResponse(properties []domain.Property, total
 		totalPages = 1
 	}
 
-	return &dto.SearchResponse{
+	// Los resultados pueden venir del caché: se traducen sobre una copia
+	if request.Lang != "" {
+		localized := make([]domain.Property, len(properties))
+		for i, property := range properties {
+			localized[i] = property.Localized(request.Lang)
+		}
+		properties = localized
+	}
+
+	response := &dto.SearchResponse{
 		Results:      properties,
 		TotalResults: total,
 		Page:         page,
 		PageSize:     pageSize,
 		TotalPages:   totalPages,
+		Facets:       result.Facets,
+		Projection:   request.Projection,
 	}
+
+	// Agrupar por geohash para los pines del mapa
+	if request.HasBoundingBox() {
+		response.Clusters = clusterByGeohash(properties, request)
+	}
+
+	return response
 }
 
-// invalidateCache invalida el caché eliminando todas las keys relacionadas
-// Nota: En una implementación más sofisticada, se podría mantener un registro de keys
-// o usar un patrón de invalidación más granular
+// SimilarCachePrefix es el prefijo de las keys de propiedades similares (se invalidan aparte de las búsquedas)
+const SimilarCachePrefix = "similar:"
+
+// invalidateSimilar elimina las recomendaciones cacheadas
+// Solo se llama al eliminar propiedades: una propiedad borrada no puede seguir recomendándose,
+// en cambio una actualización se refleja al vencer el TTL
+func (s *searchService) invalidateSimilar() {
+	deleted, err := s.cacheRepo.DeletePattern(SimilarCachePrefix + "*")
+	if errors.Is(err, repositories.ErrCacheOperationUnsupported) {
+		log.Printf("ℹ️ El caché remoto no soporta invalidación por patrón, las propiedades similares vencen por TTL (%d keys locales eliminadas)", deleted)
+		return
+	}
+	if err != nil {
+		log.Printf("⚠️ Error invalidando caché de propiedades similares: %v", err)
+		return
+	}
+	log.Printf("✅ Caché de propiedades similares invalidado (%d keys eliminadas)", deleted)
+}
+
+// invalidateCache invalida el caché eliminando todas las keys de búsquedas ("search:*")
+// Con Memcached solo se limpia el caché local (no permite recorrer keys) y el resto
+// se invalida naturalmente con su TTL
 func (s *searchService) invalidateCache() {
-	// Por simplicidad, invalidamos todas las keys que empiezan con "search:"
-	// En producción, se podría implementar un sistema más sofisticado de invalidación
 	log.Println("🔄 Invalidando caché de búsquedas")
-	// Nota: La invalidación completa del caché requeriría una implementación adicional
-	// en el CacheRepository para soportar invalidación por patrón
-	// Por ahora, el caché se invalidará naturalmente con su TTL
+	deleted, err := s.cacheRepo.DeletePattern("search:*")
+	if errors.Is(err, repositories.ErrCacheOperationUnsupported) {
+		log.Printf("ℹ️ El caché remoto no soporta invalidación por patrón, se espera al TTL (%d keys locales eliminadas)", deleted)
+		return
+	}
+	if err != nil {
+		log.Printf("⚠️ Error invalidando caché de búsquedas: %v", err)
+		return
+	}
+	log.Printf("✅ Caché de búsquedas invalidado (%d keys eliminadas)", deleted)
 }
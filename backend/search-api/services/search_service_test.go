@@ -0,0 +1,448 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"resilience"
+	"search-api/domain"
+	"search-api/dto"
+	"search-api/repositories"
+	"search-api/rpc"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ============================================
+// BENCHMARKS - SearchService con caché
+// ============================================
+// Miden el costo de search-api en cada camino del caché con un índice y un caché remoto en memoria,
+// así los números no dependen de la red. Correr con: go test ./services -run '^$' -bench Search -benchmem
+
+// benchmarkIndex responde todas las búsquedas con el mismo resultado; el resto de SearchIndex no se usa
+type benchmarkIndex struct {
+	repositories.SearchIndex
+	result dto.SearchResult
+}
+
+func (i *benchmarkIndex) Search(ctx context.Context, request dto.SearchRequest) (dto.SearchResult, error) {
+	repositories.SearchTraceFrom(ctx).Add(repositories.TraceIndexEngine, 2*time.Millisecond)
+	return i.result, nil
+}
+
+func (i *benchmarkIndex) Name() string {
+	return "memory"
+}
+
+func (i *benchmarkIndex) DescribeSearch(request dto.SearchRequest) string {
+	return "q=" + request.Query
+}
+
+// benchmarkRemoteCache es un caché remoto en memoria (sin TTL ni red)
+type benchmarkRemoteCache struct {
+	repositories.RemoteCache
+	mu     sync.RWMutex
+	values map[string][]byte
+}
+
+func newBenchmarkRemoteCache() *benchmarkRemoteCache {
+	return &benchmarkRemoteCache{values: make(map[string][]byte)}
+}
+
+func (c *benchmarkRemoteCache) Name() string {
+	return "memory"
+}
+
+func (c *benchmarkRemoteCache) Get(key string) ([]byte, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	value, ok := c.values[key]
+	if !ok {
+		return nil, repositories.ErrCacheMiss
+	}
+	return value, nil
+}
+
+func (c *benchmarkRemoteCache) Set(key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[key] = value
+	return nil
+}
+
+func (c *benchmarkRemoteCache) DeletePattern(pattern string) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	deleted := 0
+	for key := range c.values {
+		if matched, _ := path.Match(pattern, key); matched {
+			delete(c.values, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// benchmarkSearchResult es una página de 10 propiedades con un facet, como la que devuelve Solr
+func benchmarkSearchResult() dto.SearchResult {
+	properties := make([]domain.Property, 10)
+	for i := range properties {
+		properties[i] = domain.Property{
+			ID:            fmt.Sprintf("6650f1c2a1b2c3d4e5f607%02d", i),
+			Title:         fmt.Sprintf("Departamento luminoso %d en Nueva Córdoba", i),
+			Description:   "Departamento para 3 huéspedes cerca de restaurantes, transporte y los principales paseos.",
+			City:          "Córdoba",
+			Country:       "Argentina",
+			PropertyType:  "apartamento",
+			PricePerNight: 45000,
+			MaxGuests:     3,
+			Images:        []string{"https://cdn.spotly.local/1.jpg", "https://cdn.spotly.local/2.jpg"},
+			OwnerID:       1001,
+			Available:     true,
+			CreatedAt:     time.Date(2024, 5, 24, 18, 30, 0, 0, time.UTC),
+		}
+	}
+	return dto.SearchResult{
+		Properties: properties,
+		Total:      120,
+		Facets:     map[string][]dto.FacetValue{"type": {{Value: "apartamento", Count: 80}, {Value: "casa", Count: 40}}},
+	}
+}
+
+// newBenchmarkSearchService arma el servicio con el caché de dos niveles real sobre el caché remoto en memoria
+// Con localTTL mínimo las entradas vencen enseguida en el nivel local y las lecturas van al remoto
+func newBenchmarkSearchService(b testing.TB, localTTL time.Duration) SearchService {
+	log.SetOutput(io.Discard)
+	b.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	cacheRepo := repositories.NewCacheRepository(newBenchmarkRemoteCache(), localTTL)
+	return NewSearchService(&benchmarkIndex{result: benchmarkSearchResult()}, cacheRepo, nil,
+		resilience.CircuitBreakerSettings{FailureThreshold: 5, OpenTimeout: time.Second, HalfOpenMaxRequests: 1},
+		resilience.RetryPolicy{MaxAttempts: 1},
+		CacheTTLPolicy{Default: time.Hour, Broad: time.Hour, Specific: time.Hour},
+		nil,
+		0,
+	)
+}
+
+func BenchmarkSearch_CacheHitLocal(b *testing.B) {
+	service := newBenchmarkSearchService(b, time.Hour)
+	request := dto.SearchRequest{Query: "departamento", City: "Córdoba"}
+	if _, err := service.Search(context.Background(), request); err != nil {
+		b.Fatalf("warm-up search failed: %v", err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := service.Search(context.Background(), request); err != nil {
+			b.Fatalf("search failed: %v", err)
+		}
+	}
+}
+
+func BenchmarkSearch_CacheHitRemote(b *testing.B) {
+	service := newBenchmarkSearchService(b, time.Nanosecond)
+	request := dto.SearchRequest{Query: "departamento", City: "Córdoba"}
+	if _, err := service.Search(context.Background(), request); err != nil {
+		b.Fatalf("warm-up search failed: %v", err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := service.Search(context.Background(), request); err != nil {
+			b.Fatalf("search failed: %v", err)
+		}
+	}
+}
+
+// BenchmarkSearch_CacheMiss usa un término distinto en cada búsqueda: consulta el índice y escribe ambos niveles
+func BenchmarkSearch_CacheMiss(b *testing.B) {
+	service := newBenchmarkSearchService(b, time.Hour)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		request := dto.SearchRequest{Query: fmt.Sprintf("departamento %d", i), City: "Córdoba"}
+		if _, err := service.Search(context.Background(), request); err != nil {
+			b.Fatalf("search failed: %v", err)
+		}
+	}
+}
+
+// BenchmarkSearch_CacheHitLocalParallel mide la contención del caché local con búsquedas concurrentes
+func BenchmarkSearch_CacheHitLocalParallel(b *testing.B) {
+	service := newBenchmarkSearchService(b, time.Hour)
+	requests := []dto.SearchRequest{
+		{Query: "departamento", City: "Córdoba"},
+		{City: "Buenos Aires"},
+		{Country: "Uruguay", MinGuests: 4},
+		{Query: "cabaña lago", MaxPrice: 90000},
+	}
+	for _, request := range requests {
+		if _, err := service.Search(context.Background(), request); err != nil {
+			b.Fatalf("warm-up search failed: %v", err)
+		}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			if _, err := service.Search(context.Background(), requests[i%len(requests)]); err != nil {
+				b.Errorf("search failed: %v", err)
+				return
+			}
+			i++
+		}
+	})
+}
+
+func TestSearch_DebugReturnsQueryAndTimings(t *testing.T) {
+	service := newBenchmarkSearchService(t, time.Hour)
+	request := dto.SearchRequest{Query: "departamento", City: "Córdoba"}
+
+	response, err := service.Search(context.Background(), request)
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if response.Debug != nil {
+		t.Fatalf("expected no debug without debug=true, got %+v", response.Debug)
+	}
+
+	request.Debug = true
+	response, err = service.Search(context.Background(), request)
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if response.Debug == nil {
+		t.Fatal("expected debug diagnostics with debug=true")
+	}
+	if response.Debug.Source != dto.SearchSourceCache || response.Debug.Backend != "memory" || response.Debug.Query != "q=departamento" {
+		t.Fatalf("unexpected debug diagnostics: %+v", response.Debug)
+	}
+	if response.Debug.TotalResults != 120 || response.Debug.Returned != 10 {
+		t.Fatalf("expected 120 total and 10 returned, got %d and %d", response.Debug.TotalResults, response.Debug.Returned)
+	}
+
+	// debug no forma parte de la cache key, así que se fuerza la consulta al índice
+	request.CacheMode = "bypass"
+	response, err = service.Search(context.Background(), request)
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if response.Debug.Source != dto.SearchSourceIndex || response.Debug.Timings.IndexEngineMs != 2 {
+		t.Fatalf("expected index source with 2ms engine time, got %+v", response.Debug)
+	}
+}
+
+// hydrationPropertiesClient responde GetProperties con precios nuevos, en orden inverso y sin la propiedad missing
+type hydrationPropertiesClient struct {
+	rpc.PropertiesServiceClient
+	missing string
+	err     error
+}
+
+func (c *hydrationPropertiesClient) GetProperties(ctx context.Context, request *rpc.GetPropertiesRequest, opts ...grpc.CallOption) (*rpc.GetPropertiesResponse, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	response := &rpc.GetPropertiesResponse{}
+	for i := len(request.IDs) - 1; i >= 0; i-- {
+		if request.IDs[i] != c.missing {
+			response.Properties = append(response.Properties, dto.PropertySnapshot{ID: request.IDs[i], Title: "Actualizada", Price: 99000})
+		}
+	}
+	return response, nil
+}
+
+func TestSearch_HydrateReturnsFreshPropertiesInIndexOrder(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	indexed := dto.SearchResult{Total: 3, Properties: []domain.Property{
+		{ID: "a", Score: 3.5}, {ID: "b", Score: 2.1}, {ID: "c", Score: 1.4},
+	}}
+	client := &hydrationPropertiesClient{missing: "b"}
+	service := NewSearchService(&benchmarkIndex{result: indexed}, repositories.NewCacheRepository(newBenchmarkRemoteCache(), time.Hour), client,
+		resilience.CircuitBreakerSettings{FailureThreshold: 5, OpenTimeout: time.Second, HalfOpenMaxRequests: 1},
+		resilience.RetryPolicy{MaxAttempts: 1},
+		CacheTTLPolicy{Default: time.Hour, Broad: time.Hour, Specific: time.Hour},
+		nil,
+		0,
+	)
+	request := dto.SearchRequest{Query: "departamento", Hydrate: true}
+
+	response, err := service.Search(context.Background(), request)
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if len(response.Results) != 2 || response.Results[0].ID != "a" || response.Results[1].ID != "c" {
+		t.Fatalf("expected a and c in index order, got %+v", response.Results)
+	}
+	if response.Results[0].PricePerNight != 99000 || response.Results[0].Score != 3.5 {
+		t.Fatalf("expected the fresh price with the index score, got %+v", response.Results[0])
+	}
+
+	// El caché guarda solo el resultado del índice: la hidratación se repite en cada request
+	client.err = errors.New("properties-api caído")
+	if _, err := service.Search(context.Background(), request); !errors.Is(err, ErrHydrationUnavailable) {
+		t.Fatalf("expected ErrHydrationUnavailable, got %v", err)
+	}
+}
+
+// deadlinePropertiesClient responde GetProperties con DeadlineExceeded si el context del caller ya terminó
+type deadlinePropertiesClient struct {
+	rpc.PropertiesServiceClient
+	calls int
+}
+
+func (c *deadlinePropertiesClient) GetProperties(ctx context.Context, request *rpc.GetPropertiesRequest, opts ...grpc.CallOption) (*rpc.GetPropertiesResponse, error) {
+	c.calls++
+	if ctx.Err() != nil {
+		return nil, status.Error(codes.DeadlineExceeded, ctx.Err().Error())
+	}
+	return &rpc.GetPropertiesResponse{Properties: []dto.PropertySnapshot{{ID: request.IDs[0], Title: "Depto"}}}, nil
+}
+
+func TestFetchPropertiesFromAPI_CancelledCallerDoesNotOpenTheCircuit(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	client := &deadlinePropertiesClient{}
+	service := NewSearchService(&benchmarkIndex{}, repositories.NewCacheRepository(newBenchmarkRemoteCache(), time.Hour), client,
+		resilience.CircuitBreakerSettings{FailureThreshold: 1, OpenTimeout: time.Hour, HalfOpenMaxRequests: 1},
+		resilience.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond},
+		CacheTTLPolicy{Default: time.Hour, Broad: time.Hour, Specific: time.Hour},
+		nil,
+		0,
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := service.FetchPropertiesFromAPI(ctx, []string{"p1"}); err == nil {
+		t.Fatal("expected an error for a cancelled caller")
+	}
+	if client.calls != 1 {
+		t.Fatalf("expected no retries for a cancelled caller, got %d calls", client.calls)
+	}
+
+	// Con FailureThreshold 1, contar la cancelación como falla habría abierto el circuito
+	properties, err := service.FetchPropertiesFromAPI(context.Background(), []string{"p1"})
+	if err != nil || len(properties) != 1 {
+		t.Fatalf("expected the circuit still closed, got %v and %v", properties, err)
+	}
+}
+
+func TestMatchesSearchRequest_ExcludesBookedStayDates(t *testing.T) {
+	property := domain.Property{
+		ID:           "p1",
+		BookedRanges: []domain.DateRange{{Start: "2099-08-10", End: "2099-08-14"}},
+	}
+
+	cases := []struct {
+		checkIn, checkOut string
+		expected          bool
+	}{
+		{"2099-08-05", "2099-08-10", true},  // sale el día que entra la reserva
+		{"2099-08-14", "2099-08-16", true},  // entra el día que sale la reserva
+		{"2099-08-13", "2099-08-15", false}, // se superpone la noche del 13
+		{"2099-08-01", "2099-08-20", false}, // contiene la reserva
+	}
+	for _, c := range cases {
+		request := dto.SearchRequest{CheckIn: c.checkIn, CheckOut: c.checkOut}
+		if got := matchesSearchRequest(request, property); got != c.expected {
+			t.Errorf("stay %s..%s: expected match=%t, got %t", c.checkIn, c.checkOut, c.expected, got)
+		}
+	}
+
+	if !matchesSearchRequest(dto.SearchRequest{}, property) {
+		t.Errorf("expected a search without dates to ignore the booked ranges")
+	}
+}
+
+func TestSearch_LangLocalizesResultsWithoutTouchingCache(t *testing.T) {
+	indexed := dto.SearchResult{Total: 2, Properties: []domain.Property{
+		{ID: "a", Title: "Departamento céntrico", Description: "Luminoso", Language: "es",
+			Translations: map[string]domain.Translation{"en-US": {Title: "Downtown apartment", Description: "Bright"}}},
+		{ID: "b", Title: "Casa con pileta", Language: "es"},
+	}}
+	service := NewSearchService(&benchmarkIndex{result: indexed}, repositories.NewCacheRepository(newBenchmarkRemoteCache(), time.Hour), nil,
+		resilience.CircuitBreakerSettings{FailureThreshold: 5, OpenTimeout: time.Second, HalfOpenMaxRequests: 1},
+		resilience.RetryPolicy{MaxAttempts: 1},
+		CacheTTLPolicy{Default: time.Hour, Broad: time.Hour, Specific: time.Hour},
+		nil,
+		0,
+	)
+
+	response, err := service.Search(context.Background(), dto.SearchRequest{Query: "departamento", Lang: "en"})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if got := response.Results[0]; got.Title != "Downtown apartment" || got.Description != "Bright" || got.Language != "en" {
+		t.Fatalf("expected the en-US translation for a regional match, got %+v", got)
+	}
+	if got := response.Results[1]; got.Title != "Casa con pileta" || got.Language != "es" {
+		t.Fatalf("expected the main language when there is no translation, got %+v", got)
+	}
+
+	response, err = service.Search(context.Background(), dto.SearchRequest{Query: "departamento"})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if response.Results[0].Title != "Departamento céntrico" {
+		t.Fatalf("expected the main language without lang, got %q", response.Results[0].Title)
+	}
+}
+
+func TestSearch_MetaEchoesRequestAndCacheStatus(t *testing.T) {
+	service := newBenchmarkSearchService(t, time.Hour)
+	request := dto.SearchRequest{Query: "departamento", City: "Córdoba", CheckIn: "2099-08-10", CheckOut: "2099-08-14", SortBy: "price"}
+
+	first, err := service.Search(context.Background(), request)
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if first.Meta == nil || first.Meta.Query != "departamento" || first.Meta.SortBy != "price" {
+		t.Fatalf("expected the query and sort echoed in meta, got %+v", first.Meta)
+	}
+	filters := first.Meta.Filters
+	if filters["city"] != "Córdoba" || filters["checkIn"] != "2099-08-10" || filters["checkOut"] != "2099-08-14" {
+		t.Fatalf("expected the applied filter values in meta, got %v", filters)
+	}
+	if first.Meta.Cache != (dto.SearchCacheStatus{Status: dto.CacheStatusMiss}) {
+		t.Fatalf("expected a cache miss on the first search, got %+v", first.Meta.Cache)
+	}
+
+	second, err := service.Search(context.Background(), request)
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if second.Meta.Cache != (dto.SearchCacheStatus{Status: dto.CacheStatusHit, Level: dto.CacheLevelLocal}) {
+		t.Fatalf("expected a local cache hit on the repeated search, got %+v", second.Meta.Cache)
+	}
+	if len(first.SearchID) != 32 || first.SearchID == second.SearchID {
+		t.Fatalf("expected a new 32-char searchId per search, got %q and %q", first.SearchID, second.SearchID)
+	}
+
+	request.CacheMode = dto.CacheModeBypass
+	bypassed, err := service.Search(context.Background(), request)
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if bypassed.Meta.Cache.Status != dto.CacheStatusBypass {
+		t.Fatalf("expected bypass status with cache=bypass, got %+v", bypassed.Meta.Cache)
+	}
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"search-api/dto"
+)
+
+// maxSortFields es la cantidad máxima de criterios de orden en sortBy
+const maxSortFields = 3
+
+// sortableFields mapea los nombres aceptados en sortBy al campo de Solr
+// Solo se ordena por estos campos: el valor de sortBy nunca llega crudo a Solr
+var sortableFields = map[string]string{
+	"price":      "price",
+	"created_at": "created_at",
+	"createdAt":  "created_at",
+	"bedrooms":   "bedrooms",
+	"bathrooms":  "bathrooms",
+	"max_guests": "max_guests",
+	"maxGuests":  "max_guests",
+	"popularity": "popularity",
+}
+
+// descendingByDefault son los campos que sin orden explícito se ordenan de mayor a menor
+var descendingByDefault = map[string]bool{
+	"popularity": true, // Las más vistas primero
+}
+
+// ParseSortFields parsea sortBy como una lista separada por comas (ej: "popularity desc,price asc")
+// Cada criterio sin orden explícito usa defaultOrder o, si está vacío, el orden por defecto del campo
+func ParseSortFields(sortBy, defaultOrder string) ([]dto.SortField, error) {
+	sortBy = strings.TrimSpace(sortBy)
+	if sortBy == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(sortBy, ",")
+	if len(parts) > maxSortFields {
+		return nil, fmt.Errorf("sortBy admite como máximo %d criterios", maxSortFields)
+	}
+
+	fields := make([]dto.SortField, 0, len(parts))
+	seen := make(map[string]bool, len(parts))
+	for _, part := range parts {
+		tokens := strings.Fields(part)
+		if len(tokens) == 0 || len(tokens) > 2 {
+			return nil, fmt.Errorf("criterio de orden inválido: '%s' (formato: campo [asc|desc])", strings.TrimSpace(part))
+		}
+
+		field, ok := sortableFields[tokens[0]]
+		if !ok {
+			return nil, fmt.Errorf("no se puede ordenar por '%s'. Campos válidos: price, created_at, bedrooms, bathrooms, max_guests, popularity", tokens[0])
+		}
+		if seen[field] {
+			return nil, fmt.Errorf("el campo '%s' está repetido en sortBy", tokens[0])
+		}
+		seen[field] = true
+
+		order := defaultOrder
+		if len(tokens) == 2 {
+			order = strings.ToLower(tokens[1])
+		}
+		if order == "" {
+			order = "asc"
+			if descendingByDefault[field] {
+				order = "desc"
+			}
+		}
+		if order != "asc" && order != "desc" {
+			return nil, fmt.Errorf("orden inválido para '%s': debe ser 'asc' o 'desc'", tokens[0])
+		}
+
+		fields = append(fields, dto.SortField{Field: field, Order: order})
+	}
+	return fields, nil
+}
+
+// formatSortFields retorna los criterios normalizados (ej: "popularity desc,price asc")
+func formatSortFields(fields []dto.SortField) string {
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		parts[i] = field.Field + " " + field.Order
+	}
+	return strings.Join(parts, ",")
+}
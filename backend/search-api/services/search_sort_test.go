@@ -0,0 +1,66 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"search-api/dto"
+)
+
+func TestParseSortFields(t *testing.T) {
+	tests := []struct {
+		name         string
+		sortBy       string
+		defaultOrder string
+		want         []dto.SortField
+		wantErr      bool
+	}{
+		{name: "empty", sortBy: "  ", want: nil},
+		{name: "single field ascending by default", sortBy: "price", want: []dto.SortField{{Field: "price", Order: "asc"}}},
+		{name: "popularity descending by default", sortBy: "popularity", want: []dto.SortField{{Field: "popularity", Order: "desc"}}},
+		{name: "default order from the request", sortBy: "price,popularity", defaultOrder: "desc", want: []dto.SortField{{Field: "price", Order: "desc"}, {Field: "popularity", Order: "desc"}}},
+		{name: "explicit order wins over the default", sortBy: "price asc", defaultOrder: "desc", want: []dto.SortField{{Field: "price", Order: "asc"}}},
+		{name: "uppercase direction", sortBy: "price DESC", want: []dto.SortField{{Field: "price", Order: "desc"}}},
+		{name: "camelCase aliases", sortBy: " createdAt desc , maxGuests ", want: []dto.SortField{{Field: "created_at", Order: "desc"}, {Field: "max_guests", Order: "asc"}}},
+		{name: "up to the field limit", sortBy: "popularity,price,bedrooms", want: []dto.SortField{{Field: "popularity", Order: "desc"}, {Field: "price", Order: "asc"}, {Field: "bedrooms", Order: "asc"}}},
+		{name: "over the field limit", sortBy: "popularity,price,bedrooms,bathrooms", wantErr: true},
+		{name: "unknown field", sortBy: "owner_id", wantErr: true},
+		{name: "solr function", sortBy: "geodist() asc", wantErr: true},
+		{name: "semicolon in the field", sortBy: "price;rows=1000", wantErr: true},
+		{name: "semicolon in the direction", sortBy: "price asc;rows=1000", wantErr: true},
+		{name: "extra tokens", sortBy: "price asc bedrooms", wantErr: true},
+		{name: "empty criterion between commas", sortBy: "price,,bedrooms", wantErr: true},
+		{name: "trailing comma", sortBy: "price,", wantErr: true},
+		{name: "invalid direction", sortBy: "price up", wantErr: true},
+		{name: "invalid default order", sortBy: "price", defaultOrder: "sideways", wantErr: true},
+		{name: "repeated field", sortBy: "price asc,price desc", wantErr: true},
+		{name: "repeated field through an alias", sortBy: "createdAt,created_at", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSortFields(tt.sortBy, tt.defaultOrder)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error for %q, got %+v", tt.sortBy, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFormatSortFields(t *testing.T) {
+	fields, err := ParseSortFields("popularity,createdAt asc", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := formatSortFields(fields); got != "popularity desc,created_at asc" {
+		t.Fatalf("expected the normalized sort, got %q", got)
+	}
+}
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"search-api/domain"
+	"search-api/dto"
+)
+
+// ErrTooManySubscriptions indica que se alcanzó el máximo de suscripciones abiertas
+var ErrTooManySubscriptions = errors.New("se alcanzó el máximo de suscripciones abiertas")
+
+// ErrStreamClosed indica que el servicio de suscripciones está cerrando (shutdown)
+var ErrStreamClosed = errors.New("el servicio de suscripciones está cerrado")
+
+// SearchStreamService mantiene las búsquedas suscritas por GET /search/stream
+// y les envía las propiedades recién indexadas que coinciden con ellas
+type SearchStreamService interface {
+	// Subscribe registra una búsqueda y retorna la suscripción por la que llegan los eventos
+	Subscribe(request dto.SearchRequest) (*SearchSubscription, error)
+
+	// Unsubscribe cancela la suscripción y cierra su canal de eventos
+	Unsubscribe(subscription *SearchSubscription)
+
+	// Notify reevalúa las búsquedas suscritas contra una propiedad recién indexada
+	// eventType es dto.SearchStreamPropertyCreated o dto.SearchStreamPropertyUpdated
+	Notify(eventType string, property domain.Property)
+
+	// Close cancela todas las suscripciones (los streams abiertos terminan)
+	Close()
+
+	// WriteMetrics escribe las métricas de las suscripciones en formato de texto de Prometheus
+	WriteMetrics(w io.Writer) error
+}
+
+// SearchSubscription es una búsqueda suscrita por un cliente
+type SearchSubscription struct {
+	request dto.SearchRequest
+	events  chan dto.SearchStreamEvent
+	dropped atomic.Int64
+}
+
+// Events retorna el canal de eventos; se cierra al cancelar la suscripción
+func (s *SearchSubscription) Events() <-chan dto.SearchStreamEvent {
+	return s.events
+}
+
+// Dropped retorna la cantidad de eventos descartados porque el cliente no los leía a tiempo
+func (s *SearchSubscription) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// searchStreamService es la implementación concreta de SearchStreamService
+type searchStreamService struct {
+	maxSubscriptions int
+	bufferSize       int
+
+	mu            sync.RWMutex
+	subscriptions map[*SearchSubscription]struct{}
+	closed        bool
+
+	sent    atomic.Int64
+	dropped atomic.Int64
+}
+
+// NewSearchStreamService crea el servicio de suscripciones a búsquedas
+// maxSubscriptions limita los streams abiertos y bufferSize los eventos en espera de cada uno
+func NewSearchStreamService(maxSubscriptions, bufferSize int) SearchStreamService {
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+	return &searchStreamService{
+		maxSubscriptions: maxSubscriptions,
+		bufferSize:       bufferSize,
+		subscriptions:    make(map[*SearchSubscription]struct{}),
+	}
+}
+
+// Subscribe registra una búsqueda y retorna la suscripción por la que llegan los eventos
+func (s *searchStreamService) Subscribe(request dto.SearchRequest) (*SearchSubscription, error) {
+	projection, err := ParseResponseFields(request.Fields)
+	if err != nil {
+		return nil, err
+	}
+	request.Projection = projection
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, ErrStreamClosed
+	}
+	if s.maxSubscriptions > 0 && len(s.subscriptions) >= s.maxSubscriptions {
+		return nil, ErrTooManySubscriptions
+	}
+
+	subscription := &SearchSubscription{
+		request: request,
+		events:  make(chan dto.SearchStreamEvent, s.bufferSize),
+	}
+	s.subscriptions[subscription] = struct{}{}
+	return subscription, nil
+}
+
+// Unsubscribe cancela la suscripción y cierra su canal de eventos
+// Es idempotente: una suscripción ya cancelada (ej: por Close) se ignora
+func (s *searchStreamService) Unsubscribe(subscription *SearchSubscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subscriptions[subscription]; !ok {
+		return
+	}
+	delete(s.subscriptions, subscription)
+	close(subscription.events)
+}
+
+// Notify reevalúa las búsquedas suscritas contra una propiedad recién indexada
+// El envío no bloquea: si un cliente no lee a tiempo sus eventos se descartan,
+// así un stream lento no frena al consumidor de RabbitMQ
+func (s *searchStreamService) Notify(eventType string, property domain.Property) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	matched := 0
+	for subscription := range s.subscriptions {
+		if !matchesSearchRequest(subscription.request, property) {
+			continue
+		}
+		matched++
+
+		event := dto.SearchStreamEvent{
+			Type:       eventType,
+			Property:   property,
+			Projection: subscription.request.Projection,
+		}
+		select {
+		case subscription.events <- event:
+			s.sent.Add(1)
+		default:
+			subscription.dropped.Add(1)
+			s.dropped.Add(1)
+		}
+	}
+
+	if matched > 0 {
+		log.Printf("📡 Propiedad %s notificada a %d suscripciones de búsqueda", property.ID, matched)
+	}
+}
+
+// Close cancela todas las suscripciones (los streams abiertos terminan)
+// Se llama al iniciar el shutdown: los streams no terminan solos y frenarían el cierre del servidor
+func (s *searchStreamService) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	for subscription := range s.subscriptions {
+		delete(s.subscriptions, subscription)
+		close(subscription.events)
+	}
+	log.Println("🔌 Suscripciones de búsqueda cerradas")
+}
+
+// WriteMetrics escribe las métricas de las suscripciones en formato de texto de Prometheus
+func (s *searchStreamService) WriteMetrics(w io.Writer) error {
+	s.mu.RLock()
+	subscriptions := len(s.subscriptions)
+	s.mu.RUnlock()
+
+	metrics := []struct {
+		name  string
+		kind  string
+		help  string
+		value float64
+	}{
+		{"search_stream_subscriptions", "gauge", "Streams de búsqueda abiertos", float64(subscriptions)},
+		{"search_stream_events_sent_total", "counter", "Eventos enviados a los streams de búsqueda", float64(s.sent.Load())},
+		{"search_stream_events_dropped_total", "counter", "Eventos descartados porque el cliente no los leía a tiempo", float64(s.dropped.Load())},
+	}
+
+	for _, metric := range metrics {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %g\n",
+			metric.name, metric.help, metric.name, metric.kind, metric.name, metric.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchesSearchRequest indica si la propiedad cumple los filtros de la búsqueda
+// Replica en memoria la consulta que los backends arman para el índice (ver solrRepository.Search):
+// el texto se busca en título (también traducido al idioma de lang), ciudad y país, y el resto son
+// filtros exactos o rangos
+func matchesSearchRequest(request dto.SearchRequest, property domain.Property) bool {
+	if request.Query != "" {
+		query := strings.ToLower(request.Query)
+		if !strings.Contains(strings.ToLower(property.Title), query) &&
+			!strings.Contains(strings.ToLower(property.Localized(request.Lang).Title), query) &&
+			!strings.Contains(strings.ToLower(property.City), query) &&
+			!strings.Contains(strings.ToLower(property.Country), query) {
+			return false
+		}
+	}
+
+	if request.City != "" && !strings.EqualFold(property.City, request.City) {
+		return false
+	}
+	if request.Country != "" && !strings.EqualFold(property.Country, request.Country) {
+		return false
+	}
+	if request.Type != "" && !strings.EqualFold(property.PropertyType, request.Type) {
+		return false
+	}
+
+	if request.MinPrice > 0 && property.PricePerNight < request.MinPrice {
+		return false
+	}
+	if request.MaxPrice > 0 && property.PricePerNight > request.MaxPrice {
+		return false
+	}
+
+	// Las propiedades sin coordenadas (0,0) no se indexan con ubicación y nunca caen en un bounding box
+	if request.HasBoundingBox() {
+		if property.Latitude == 0 && property.Longitude == 0 {
+			return false
+		}
+		if property.Latitude < *request.BboxMinLat || property.Latitude > *request.BboxMaxLat ||
+			property.Longitude < *request.BboxMinLng || property.Longitude > *request.BboxMaxLng {
+			return false
+		}
+	}
+
+	if request.Bedrooms > 0 && property.Bedrooms != request.Bedrooms {
+		return false
+	}
+	if request.Bathrooms > 0 && property.Bathrooms != request.Bathrooms {
+		return false
+	}
+	if request.MinGuests > 0 && property.MaxGuests < request.MinGuests {
+		return false
+	}
+	if (request.ChildFriendly && !property.ChildFriendly) || (request.InfantFriendly && !property.InfantFriendly) {
+		return false
+	}
+	if (request.PetsAllowed && !property.PetsAllowed) ||
+		(request.SmokingAllowed && !property.SmokingAllowed) ||
+		(request.EventsAllowed && !property.EventsAllowed) {
+		return false
+	}
+	if request.InstantBook && !property.InstantBook {
+		return false
+	}
+	if request.CancellationPolicy != "" && property.CancellationPolicy != request.CancellationPolicy {
+		return false
+	}
+	if request.HasStayDates() {
+		for _, booked := range property.BookedRanges {
+			if booked.Overlaps(request.CheckIn, request.CheckOut) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"search-api/domain"
+	"search-api/dto"
+)
+
+func TestSearchStream_NotifiesOnlyMatchingSubscriptions(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	stream := NewSearchStreamService(0, 4)
+	cordoba, err := stream.Subscribe(dto.SearchRequest{City: "Córdoba", MaxPrice: 100})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mendoza, err := stream.Subscribe(dto.SearchRequest{City: "Mendoza"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stream.Notify(dto.SearchStreamPropertyCreated, domain.Property{ID: "p1", City: "córdoba", PricePerNight: 80})
+	// Fuera del rango de precio de la búsqueda de Córdoba
+	stream.Notify(dto.SearchStreamPropertyUpdated, domain.Property{ID: "p2", City: "Córdoba", PricePerNight: 150})
+
+	select {
+	case event := <-cordoba.Events():
+		if event.Type != dto.SearchStreamPropertyCreated || event.Property.ID != "p1" {
+			t.Fatalf("expected property.created for p1, got %s for %s", event.Type, event.Property.ID)
+		}
+	default:
+		t.Fatal("expected an event for the Córdoba subscription")
+	}
+	select {
+	case event := <-cordoba.Events():
+		t.Fatalf("expected no more events for Córdoba, got %s for %s", event.Type, event.Property.ID)
+	default:
+	}
+	select {
+	case event := <-mendoza.Events():
+		t.Fatalf("expected no events for Mendoza, got %s for %s", event.Type, event.Property.ID)
+	default:
+	}
+}
+
+func TestSearchStream_DropsEventsForSlowClients(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	stream := NewSearchStreamService(0, 2)
+	subscription, err := stream.Subscribe(dto.SearchRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Nadie lee el canal: Notify no puede bloquear al consumidor, descarta lo que no entra en el buffer
+	for i := 0; i < 5; i++ {
+		stream.Notify(dto.SearchStreamPropertyCreated, domain.Property{ID: "p1"})
+	}
+	if len(subscription.Events()) != 2 || subscription.Dropped() != 3 {
+		t.Fatalf("expected 2 buffered and 3 dropped events, got %d and %d", len(subscription.Events()), subscription.Dropped())
+	}
+
+	var metrics strings.Builder
+	if err := stream.WriteMetrics(&metrics); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, line := range []string{"search_stream_subscriptions 1", "search_stream_events_sent_total 2", "search_stream_events_dropped_total 3"} {
+		if !strings.Contains(metrics.String(), line+"\n") {
+			t.Fatalf("expected %q in the metrics, got:\n%s", line, metrics.String())
+		}
+	}
+}
+
+func TestSearchStream_LimitsSubscriptions(t *testing.T) {
+	stream := NewSearchStreamService(1, 1)
+	first, err := stream.Subscribe(dto.SearchRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := stream.Subscribe(dto.SearchRequest{}); !errors.Is(err, ErrTooManySubscriptions) {
+		t.Fatalf("expected ErrTooManySubscriptions, got %v", err)
+	}
+
+	// Al cancelar una suscripción se libera su lugar
+	stream.Unsubscribe(first)
+	if _, err := stream.Subscribe(dto.SearchRequest{}); err != nil {
+		t.Fatalf("expected the freed slot to be reused, got %v", err)
+	}
+}
+
+func TestSearchStream_CloseEndsEveryStream(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	stream := NewSearchStreamService(0, 1)
+	subscription, err := stream.Subscribe(dto.SearchRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stream.Close()
+	if _, ok := <-subscription.Events(); ok {
+		t.Fatal("expected the events channel to be closed")
+	}
+	// El controlador hace Unsubscribe al terminar el stream: no puede cerrar el canal otra vez
+	stream.Unsubscribe(subscription)
+
+	if _, err := stream.Subscribe(dto.SearchRequest{}); !errors.Is(err, ErrStreamClosed) {
+		t.Fatalf("expected ErrStreamClosed after Close, got %v", err)
+	}
+}
+
+func TestSearchStream_RejectsInvalidFields(t *testing.T) {
+	stream := NewSearchStreamService(0, 1)
+	if _, err := stream.Subscribe(dto.SearchRequest{Fields: "password"}); err == nil {
+		t.Fatal("expected an error for an unknown field")
+	}
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+
+	"search-api/dto"
+)
+
+// ValidationError son los parámetros de búsqueda que no pasaron la validación, uno por campo
+type ValidationError struct {
+	Fields []dto.FieldError
+}
+
+// Error junta los mensajes de todos los campos
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// searchValidator valida SearchRequest con los tags validate del DTO
+// Los nombres de los errores son los de los query parameters (tag form)
+var searchValidator = newSearchValidator()
+
+// newSearchValidator registra las reglas propias de la búsqueda además de las de validator
+func newSearchValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.Split(field.Tag.Get("form"), ",")[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	// sortfields y responsefields aceptan solo los campos ordenables y seleccionables
+	v.RegisterValidation("sortfields", func(fl validator.FieldLevel) bool {
+		_, err := ParseSortFields(fl.Field().String(), "")
+		return err == nil
+	})
+	v.RegisterValidation("responsefields", func(fl validator.FieldLevel) bool {
+		_, err := ParseResponseFields(fl.Field().String())
+		return err == nil
+	})
+
+	v.RegisterStructValidation(validateSearchRanges, dto.SearchRequest{})
+	return v
+}
+
+// validateSearchRanges valida las reglas entre campos: rango de precio y bounding box completo y ordenado
+func validateSearchRanges(sl validator.StructLevel) {
+	request := sl.Current().Interface().(dto.SearchRequest)
+
+	if request.MinPrice > 0 && request.MaxPrice > 0 && request.MinPrice > request.MaxPrice {
+		sl.ReportError(request.MinPrice, "minPrice", "MinPrice", "pricerange", "")
+	}
+
+	provided := 0
+	for _, value := range []*float64{request.BboxMinLat, request.BboxMinLng, request.BboxMaxLat, request.BboxMaxLng} {
+		if value != nil {
+			provided++
+		}
+	}
+	if provided > 0 && provided < 4 {
+		sl.ReportError(request.BboxMinLat, "bbox", "BboxMinLat", "bboxcomplete", "")
+	}
+	if request.HasBoundingBox() &&
+		(*request.BboxMinLat > *request.BboxMaxLat || *request.BboxMinLng > *request.BboxMaxLng) {
+		sl.ReportError(request.BboxMinLat, "bbox", "BboxMinLat", "bboxorder", "")
+	}
+
+	// Las fechas YYYY-MM-DD se comparan como texto (el formato lo valida el tag datetime)
+	if (request.CheckIn == "") != (request.CheckOut == "") {
+		sl.ReportError(request.CheckIn, "checkIn", "CheckIn", "staycomplete", "")
+	}
+	if request.HasStayDates() && request.CheckOut <= request.CheckIn {
+		sl.ReportError(request.CheckOut, "checkOut", "CheckOut", "stayorder", "")
+	}
+}
+
+// SanitizeSearchRequest limpia los textos del request antes de validarlo:
+// saca caracteres de control, recorta los espacios y junta los repetidos
+func SanitizeSearchRequest(request *dto.SearchRequest) {
+	request.Query = sanitizeSearchText(request.Query)
+	request.City = sanitizeSearchText(request.City)
+	request.Country = sanitizeSearchText(request.Country)
+	request.Type = sanitizeSearchText(request.Type)
+	request.CancellationPolicy = sanitizeSearchText(request.CancellationPolicy)
+	request.CheckIn = strings.TrimSpace(request.CheckIn)
+	request.CheckOut = strings.TrimSpace(request.CheckOut)
+	request.Lang = strings.ToLower(strings.TrimSpace(request.Lang))
+	request.SortBy = strings.TrimSpace(request.SortBy)
+	request.Fields = strings.TrimSpace(request.Fields)
+}
+
+// sanitizeSearchText deja solo caracteres imprimibles con un espacio entre palabras
+func sanitizeSearchText(text string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == unicode.ReplacementChar {
+			return ' '
+		}
+		return r
+	}, text)
+	return strings.Join(strings.Fields(cleaned), " ")
+}
+
+// ValidateSearchRequest valida los parámetros de búsqueda contra los tags validate de dto.SearchRequest
+// Retorna un *ValidationError con el detalle de cada parámetro inválido
+func ValidateSearchRequest(request *dto.SearchRequest) error {
+	err := searchValidator.Struct(*request)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+
+	fields := make([]dto.FieldError, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		fields = append(fields, dto.FieldError{
+			Field:   fieldErr.Field(),
+			Rule:    fieldErr.Tag(),
+			Message: validationMessage(fieldErr),
+		})
+	}
+	return &ValidationError{Fields: fields}
+}
+
+// validationMessage arma el mensaje en castellano de una regla que falló
+func validationMessage(fieldErr validator.FieldError) string {
+	field := fieldErr.Field()
+	isText := fieldErr.Kind() == reflect.String
+
+	switch fieldErr.Tag() {
+	case "max":
+		if isText {
+			return fmt.Sprintf("%s no puede tener más de %s caracteres", field, fieldErr.Param())
+		}
+		return fmt.Sprintf("%s no puede ser mayor a %s", field, fieldErr.Param())
+	case "lte":
+		return fmt.Sprintf("%s no puede ser mayor a %s", field, fieldErr.Param())
+	case "min", "gte":
+		return fmt.Sprintf("%s debe ser mayor o igual a %s", field, fieldErr.Param())
+	case "oneof":
+		return fmt.Sprintf("%s debe ser '%s'", field, strings.Join(strings.Fields(fieldErr.Param()), "' o '"))
+	case "sortfields":
+		if _, err := ParseSortFields(fmt.Sprint(fieldErr.Value()), ""); err != nil {
+			return err.Error()
+		}
+	case "responsefields":
+		if _, err := ParseResponseFields(fmt.Sprint(fieldErr.Value())); err != nil {
+			return err.Error()
+		}
+	case "pricerange":
+		return "minPrice no puede ser mayor que maxPrice"
+	case "bboxcomplete":
+		return "el bounding box requiere bboxMinLat, bboxMinLng, bboxMaxLat y bboxMaxLng"
+	case "bboxorder":
+		return "los mínimos del bounding box no pueden ser mayores que los máximos"
+	case "datetime":
+		return fmt.Sprintf("%s debe ser una fecha YYYY-MM-DD", field)
+	case "staycomplete":
+		return "el filtro por fechas requiere checkIn y checkOut"
+	case "stayorder":
+		return "checkOut debe ser posterior a checkIn"
+	}
+	return fmt.Sprintf("%s es inválido", field)
+}
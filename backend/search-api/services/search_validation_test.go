@@ -0,0 +1,133 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"search-api/dto"
+)
+
+func float(value float64) *float64 {
+	return &value
+}
+
+func TestValidateSearchRequest_RejectsInvalidParameters(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(request *dto.SearchRequest)
+		field  string
+		rule   string
+	}{
+		{name: "query too long", modify: func(r *dto.SearchRequest) { r.Query = strings.Repeat("a", 201) }, field: "query", rule: "max"},
+		{name: "city too long", modify: func(r *dto.SearchRequest) { r.City = strings.Repeat("a", 101) }, field: "city", rule: "max"},
+		{name: "negative min price", modify: func(r *dto.SearchRequest) { r.MinPrice = -1 }, field: "minPrice", rule: "gte"},
+		{name: "negative max price", modify: func(r *dto.SearchRequest) { r.MaxPrice = -1 }, field: "maxPrice", rule: "gte"},
+		{name: "min price over max price", modify: func(r *dto.SearchRequest) { r.MinPrice, r.MaxPrice = 200, 100 }, field: "minPrice", rule: "pricerange"},
+		{name: "too many bedrooms", modify: func(r *dto.SearchRequest) { r.Bedrooms = 51 }, field: "bedrooms", rule: "lte"},
+		{name: "negative bathrooms", modify: func(r *dto.SearchRequest) { r.Bathrooms = -1 }, field: "bathrooms", rule: "gte"},
+		{name: "too many guests", modify: func(r *dto.SearchRequest) { r.MinGuests = 101 }, field: "minGuests", rule: "lte"},
+		{name: "page zero", modify: func(r *dto.SearchRequest) { r.Page = 0 }, field: "page", rule: "min"},
+		{name: "page too deep", modify: func(r *dto.SearchRequest) { r.Page = 1001 }, field: "page", rule: "max"},
+		{name: "page size zero", modify: func(r *dto.SearchRequest) { r.PageSize = 0 }, field: "pageSize", rule: "min"},
+		{name: "page size too big", modify: func(r *dto.SearchRequest) { r.PageSize = 101 }, field: "pageSize", rule: "max"},
+		{name: "facet limit too big", modify: func(r *dto.SearchRequest) { r.FacetLimit = 51 }, field: "facetLimit", rule: "max"},
+		{name: "negative facet limit", modify: func(r *dto.SearchRequest) { r.FacetLimit = -1 }, field: "facetLimit", rule: "min"},
+		{name: "unknown sort field", modify: func(r *dto.SearchRequest) { r.SortBy = "owner_id desc" }, field: "sortBy", rule: "sortfields"},
+		{name: "invalid sort order", modify: func(r *dto.SearchRequest) { r.SortOrder = "up" }, field: "sortOrder", rule: "oneof"},
+		{name: "unknown response field", modify: func(r *dto.SearchRequest) { r.Fields = "id,password" }, field: "fields", rule: "responsefields"},
+		{name: "unknown cancellation policy", modify: func(r *dto.SearchRequest) { r.CancellationPolicy = "never" }, field: "cancellationPolicy", rule: "oneof"},
+		{name: "unsupported language", modify: func(r *dto.SearchRequest) { r.Lang = "xx" }, field: "lang", rule: "oneof"},
+		{name: "unknown cache mode", modify: func(r *dto.SearchRequest) { r.CacheMode = "skip" }, field: "cache", rule: "oneof"},
+		{name: "malformed check in", modify: func(r *dto.SearchRequest) { r.CheckIn, r.CheckOut = "01/03/2025", "2025-03-05" }, field: "checkIn", rule: "datetime"},
+		{name: "check in without check out", modify: func(r *dto.SearchRequest) { r.CheckIn = "2025-03-01" }, field: "checkIn", rule: "staycomplete"},
+		{name: "check out before check in", modify: func(r *dto.SearchRequest) { r.CheckIn, r.CheckOut = "2025-03-05", "2025-03-01" }, field: "checkOut", rule: "stayorder"},
+		{name: "same day stay", modify: func(r *dto.SearchRequest) { r.CheckIn, r.CheckOut = "2025-03-05", "2025-03-05" }, field: "checkOut", rule: "stayorder"},
+		{name: "latitude out of range", modify: func(r *dto.SearchRequest) {
+			r.BboxMinLat, r.BboxMinLng, r.BboxMaxLat, r.BboxMaxLng = float(-91), float(-65), float(-31), float(-64)
+		}, field: "bboxMinLat", rule: "gte"},
+		{name: "incomplete bounding box", modify: func(r *dto.SearchRequest) { r.BboxMinLat, r.BboxMaxLat = float(-32), float(-31) }, field: "bbox", rule: "bboxcomplete"},
+		{name: "inverted bounding box", modify: func(r *dto.SearchRequest) {
+			r.BboxMinLat, r.BboxMinLng, r.BboxMaxLat, r.BboxMaxLng = float(-31), float(-65), float(-32), float(-64)
+		}, field: "bbox", rule: "bboxorder"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := &dto.SearchRequest{Page: 1, PageSize: 10}
+			tt.modify(request)
+
+			err := ValidateSearchRequest(request)
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("expected a *ValidationError, got %v", err)
+			}
+			for _, field := range validationErr.Fields {
+				if field.Field == tt.field && field.Rule == tt.rule {
+					if field.Message == "" {
+						t.Fatalf("expected a message for %s/%s", tt.field, tt.rule)
+					}
+					return
+				}
+			}
+			t.Fatalf("expected a %s error on %s, got %+v", tt.rule, tt.field, validationErr.Fields)
+		})
+	}
+}
+
+func TestValidateSearchRequest_AcceptsAValidRequest(t *testing.T) {
+	request := &dto.SearchRequest{
+		Query:              "cabaña con pileta",
+		City:               "Córdoba",
+		MinPrice:           50,
+		MaxPrice:           150,
+		Bedrooms:           2,
+		CancellationPolicy: "flexible",
+		CheckIn:            "2025-03-01",
+		CheckOut:           "2025-03-05",
+		Lang:               "es",
+		BboxMinLat:         float(-32),
+		BboxMinLng:         float(-65),
+		BboxMaxLat:         float(-31),
+		BboxMaxLng:         float(-64),
+		Page:               1000,
+		PageSize:           100,
+		SortBy:             "popularity desc,price",
+		SortOrder:          "asc",
+		Fields:             "id,title,images[0]",
+		FacetLimit:         50,
+	}
+	if err := ValidateSearchRequest(request); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestValidateSearchRequest_ReportsEveryInvalidField(t *testing.T) {
+	err := ValidateSearchRequest(&dto.SearchRequest{Page: 0, PageSize: 500, SortBy: "price;drop"})
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.Fields) != 3 {
+		t.Fatalf("expected one error for page, pageSize and sortBy, got %v", err)
+	}
+	if !strings.Contains(validationErr.Error(), "; ") {
+		t.Fatalf("expected the messages joined in the error, got %q", validationErr.Error())
+	}
+}
+
+func TestSanitizeSearchRequest_CleansTexts(t *testing.T) {
+	request := &dto.SearchRequest{
+		Query:  "  casa\x00 con\t\tpileta\n ",
+		City:   "Villa\u0007 Carlos  Paz",
+		Lang:   " EN ",
+		SortBy: " price desc ",
+	}
+	SanitizeSearchRequest(request)
+
+	if request.Query != "casa con pileta" {
+		t.Fatalf("expected control characters and repeated spaces removed, got %q", request.Query)
+	}
+	if request.City != "Villa Carlos Paz" {
+		t.Fatalf("expected a clean city, got %q", request.City)
+	}
+	if request.Lang != "en" || request.SortBy != "price desc" {
+		t.Fatalf("expected lang and sortBy trimmed, got %q and %q", request.Lang, request.SortBy)
+	}
+}
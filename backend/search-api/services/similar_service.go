@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"search-api/domain"
+	"search-api/dto"
+	"search-api/repositories"
+)
+
+// ErrPropertyNotFound se retorna cuando la propiedad de referencia no está indexada
+var ErrPropertyNotFound = errors.New("propiedad no encontrada")
+
+// similarCandidatesFactor es cuántos candidatos se piden al índice por cada resultado,
+// para que el reordenamiento por atributos tenga de dónde elegir
+const similarCandidatesFactor = 3
+
+// Pesos de cada atributo en la similitud (suman 1)
+const (
+	similarLocationWeight = 0.30
+	similarPriceWeight    = 0.30
+	similarTypeWeight     = 0.15
+	similarBedroomsWeight = 0.125
+	similarGuestsWeight   = 0.125
+)
+
+// similarTextWeight es el peso de la posición en MoreLikeThis frente a la similitud de atributos
+const similarTextWeight = 0.4
+
+// similarDistanceScale es la distancia (km) a la que la similitud por ubicación cae a ~37%
+const similarDistanceScale = 25.0
+
+// SimilarService define la interfaz de las recomendaciones de propiedades similares
+type SimilarService interface {
+	// Similar retorna hasta limit propiedades parecidas a la indicada (página de detalle)
+	Similar(ctx context.Context, propertyID string, limit int) (*dto.SimilarPropertiesResponse, error)
+}
+
+// similarService es la implementación concreta de SimilarService
+type similarService struct {
+	index     repositories.SearchIndex
+	cacheRepo repositories.CacheRepository
+	ttl       time.Duration
+}
+
+// NewSimilarService crea el servicio de propiedades similares
+// Los resultados se cachean ttl con su propia key ("similar:<id>:<limit>"), aparte de las búsquedas
+func NewSimilarService(index repositories.SearchIndex, cacheRepo repositories.CacheRepository, ttl time.Duration) SimilarService {
+	return &similarService{
+		index:     index,
+		cacheRepo: cacheRepo,
+		ttl:       ttl,
+	}
+}
+
+// Similar busca candidatos por texto con MoreLikeThis y los reordena por precio, ubicación, tipo y tamaño
+func (s *similarService) Similar(ctx context.Context, propertyID string, limit int) (*dto.SimilarPropertiesResponse, error) {
+	cacheKey := fmt.Sprintf("%s%s:%d", SimilarCachePrefix, propertyID, limit)
+	if cached, found := s.cacheRepo.Get(cacheKey); found {
+		log.Printf("✅ Cache hit de similares para key: %s", cacheKey)
+		return &dto.SimilarPropertiesResponse{PropertyID: propertyID, Results: cached.Properties}, nil
+	}
+
+	source, err := s.index.GetProperty(ctx, propertyID)
+	if errors.Is(err, repositories.ErrPropertyNotIndexed) {
+		return nil, ErrPropertyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo la propiedad del índice: %w", err)
+	}
+
+	candidates, err := s.index.MoreLikeThis(ctx, source, limit*similarCandidatesFactor)
+	if err != nil {
+		return nil, fmt.Errorf("error buscando propiedades similares en el índice: %w", err)
+	}
+
+	results := rankSimilar(source, candidates, limit)
+	s.cacheRepo.Set(cacheKey, dto.SearchResult{Properties: results, Total: len(results)}, s.ttl)
+	log.Printf("✅ %d propiedades similares a %s (de %d candidatos)", len(results), propertyID, len(candidates))
+
+	return &dto.SimilarPropertiesResponse{PropertyID: propertyID, Results: results}, nil
+}
+
+// rankSimilar combina la posición de cada candidato en MoreLikeThis con la similitud de sus atributos
+// Las propiedades no disponibles se descartan: no tiene sentido recomendar algo que no se puede reservar
+func rankSimilar(source domain.Property, candidates []domain.Property, limit int) []domain.Property {
+	type scored struct {
+		property domain.Property
+		score    float64
+	}
+
+	ranked := make([]scored, 0, len(candidates))
+	for i, candidate := range candidates {
+		if candidate.ID == source.ID || !candidate.Available {
+			continue
+		}
+		textScore := 1 - float64(i)/float64(len(candidates))
+		score := similarTextWeight*textScore + (1-similarTextWeight)*attributeSimilarity(source, candidate)
+		ranked = append(ranked, scored{property: candidate, score: score})
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].score > ranked[j].score
+	})
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	results := make([]domain.Property, 0, len(ranked))
+	for _, entry := range ranked {
+		results = append(results, entry.property)
+	}
+	return results
+}
+
+// attributeSimilarity compara precio, ubicación, tipo, habitaciones y capacidad (0 = nada parecidas, 1 = iguales)
+func attributeSimilarity(a, b domain.Property) float64 {
+	typeScore := 0.0
+	if a.PropertyType != "" && a.PropertyType == b.PropertyType {
+		typeScore = 1
+	}
+
+	return similarLocationWeight*locationSimilarity(a, b) +
+		similarPriceWeight*ratioSimilarity(a.PricePerNight, b.PricePerNight) +
+		similarTypeWeight*typeScore +
+		similarBedroomsWeight*countSimilarity(a.Bedrooms, b.Bedrooms) +
+		similarGuestsWeight*countSimilarity(a.MaxGuests, b.MaxGuests)
+}
+
+// locationSimilarity usa la distancia si ambas tienen coordenadas; si no, compara ciudad y país
+func locationSimilarity(a, b domain.Property) float64 {
+	if hasCoordinates(a) && hasCoordinates(b) {
+		return math.Exp(-haversineKm(a.Latitude, a.Longitude, b.Latitude, b.Longitude) / similarDistanceScale)
+	}
+	switch {
+	case a.City != "" && strings.EqualFold(a.City, b.City):
+		return 1
+	case a.Country != "" && strings.EqualFold(a.Country, b.Country):
+		return 0.5
+	default:
+		return 0
+	}
+}
+
+// ratioSimilarity compara dos valores positivos por su cociente (ej: 100 y 80 = 0.8)
+func ratioSimilarity(a, b float64) float64 {
+	if a <= 0 || b <= 0 {
+		if a == b {
+			return 1
+		}
+		return 0
+	}
+	return math.Min(a, b) / math.Max(a, b)
+}
+
+// countSimilarity compara cantidades chicas (habitaciones, huéspedes): 1 si son iguales, 0.5 si difieren en 1...
+func countSimilarity(a, b int) float64 {
+	diff := a - b
+	if diff < 0 {
+		diff = -diff
+	}
+	return 1 / float64(1+diff)
+}
+
+// hasCoordinates indica si la propiedad tiene coordenadas (0,0 = sin coordenadas)
+func hasCoordinates(property domain.Property) bool {
+	return property.Latitude != 0 || property.Longitude != 0
+}
+
+// haversineKm calcula la distancia en km entre dos coordenadas
+func haversineKm(lat1, lng1, lat2, lng2 float64) float64 {
+	const earthRadiusKm = 6371.0
+	toRad := math.Pi / 180
+	dLat := (lat2 - lat1) * toRad
+	dLng := (lng2 - lng1) * toRad
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*toRad)*math.Cos(lat2*toRad)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"search-api/domain"
+	"search-api/repositories"
+)
+
+// similarIndex responde GetProperty y MoreLikeThis con propiedades fijas y cuenta las llamadas
+type similarIndex struct {
+	repositories.SearchIndex
+	properties map[string]domain.Property
+	candidates []domain.Property
+
+	lookups        int
+	requestedLimit int
+}
+
+func (i *similarIndex) GetProperty(ctx context.Context, propertyID string) (domain.Property, error) {
+	i.lookups++
+	property, ok := i.properties[propertyID]
+	if !ok {
+		return domain.Property{}, repositories.ErrPropertyNotIndexed
+	}
+	return property, nil
+}
+
+func (i *similarIndex) MoreLikeThis(ctx context.Context, property domain.Property, limit int) ([]domain.Property, error) {
+	i.requestedLimit = limit
+	if len(i.candidates) > limit {
+		return i.candidates[:limit], nil
+	}
+	return i.candidates, nil
+}
+
+// newSimilarIndex arma un índice con un departamento en Córdoba y candidatos en el orden de MoreLikeThis
+func newSimilarIndex() *similarIndex {
+	source := domain.Property{ID: "p0", City: "Córdoba", Latitude: -31.42, Longitude: -64.18, PricePerNight: 100, PropertyType: "apartment", Bedrooms: 2, MaxGuests: 4, Available: true}
+	return &similarIndex{
+		properties: map[string]domain.Property{"p0": source},
+		candidates: []domain.Property{
+			// El texto más parecido, pero en Buenos Aires, cuatro veces más cara y de otro tipo
+			{ID: "far", City: "Buenos Aires", Latitude: -34.60, Longitude: -58.38, PricePerNight: 400, PropertyType: "house", Bedrooms: 5, MaxGuests: 10, Available: true},
+			source,
+			{ID: "unavailable", City: "Córdoba", Latitude: -31.42, Longitude: -64.18, PricePerNight: 100, PropertyType: "apartment", Bedrooms: 2, MaxGuests: 4},
+			// A un par de cuadras y con los mismos atributos
+			{ID: "close", City: "Córdoba", Latitude: -31.41, Longitude: -64.19, PricePerNight: 95, PropertyType: "apartment", Bedrooms: 2, MaxGuests: 4, Available: true},
+		},
+	}
+}
+
+func newTestSimilarService(index repositories.SearchIndex) SimilarService {
+	return NewSimilarService(index, repositories.NewCacheRepository(newBenchmarkRemoteCache(), time.Hour), time.Hour)
+}
+
+func TestSimilar_RanksByAttributesAndSkipsUnbookable(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	index := newSimilarIndex()
+	response, err := newTestSimilarService(index).Similar(context.Background(), "p0", 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if response.PropertyID != "p0" || len(response.Results) != 2 {
+		t.Fatalf("expected 2 results for p0, got %+v", response)
+	}
+	// La propiedad de referencia y la no disponible no se recomiendan; la parecida le gana al mejor texto
+	if response.Results[0].ID != "close" || response.Results[1].ID != "far" {
+		t.Fatalf("expected close before far, got %s and %s", response.Results[0].ID, response.Results[1].ID)
+	}
+	if index.requestedLimit != 5*similarCandidatesFactor {
+		t.Fatalf("expected %d candidates requested, got %d", 5*similarCandidatesFactor, index.requestedLimit)
+	}
+}
+
+func TestSimilar_TruncatesToTheLimit(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	// Con limit 1 MoreLikeThis trae 3 candidatos reservables y se recomienda solo el primero
+	index := newSimilarIndex()
+	index.candidates = []domain.Property{
+		{ID: "c1", City: "Córdoba", PricePerNight: 100, Available: true},
+		{ID: "c2", City: "Córdoba", PricePerNight: 100, Available: true},
+		{ID: "c3", City: "Córdoba", PricePerNight: 100, Available: true},
+	}
+	response, err := newTestSimilarService(index).Similar(context.Background(), "p0", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(response.Results) != 1 || response.Results[0].ID != "c1" {
+		t.Fatalf("expected only c1, got %+v", response.Results)
+	}
+}
+
+func TestSimilar_CachesPerPropertyAndLimit(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	index := newSimilarIndex()
+	service := newTestSimilarService(index)
+
+	for i := 0; i < 3; i++ {
+		if _, err := service.Similar(context.Background(), "p0", 5); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if index.lookups != 1 {
+		t.Fatalf("expected the index queried once, got %d", index.lookups)
+	}
+
+	// Otro limit es otra key de caché
+	if _, err := service.Similar(context.Background(), "p0", 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if index.lookups != 2 {
+		t.Fatalf("expected a new query for another limit, got %d", index.lookups)
+	}
+}
+
+func TestSimilar_UnknownProperty(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	_, err := newTestSimilarService(newSimilarIndex()).Similar(context.Background(), "p9", 5)
+	if !errors.Is(err, ErrPropertyNotFound) {
+		t.Fatalf("expected ErrPropertyNotFound, got %v", err)
+	}
+}
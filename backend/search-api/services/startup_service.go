@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"resilience"
+	"search-api/config"
+)
+
+// StartupDependency es una dependencia a la que hay que conectarse al arrancar
+// Connect se reintenta con backoff hasta que no falle; después no se vuelve a llamar
+// Si Optional es true y vence la espera, el servicio sigue sin ella (degradado)
+type StartupDependency struct {
+	Name     string
+	Connect  func(ctx context.Context) error
+	Optional bool
+}
+
+// StartupService conecta las dependencias en segundo plano para no depender del orden de arranque
+type StartupService interface {
+	// Wait conecta todas las dependencias en paralelo y retorna cuando terminaron
+	// Retorna error si alguna obligatoria no conectó antes de StartupConfig.MaxWait
+	Wait(ctx context.Context) error
+
+	// Check es el chequeo de readiness: falla mientras falte conectar alguna dependencia obligatoria
+	Check(ctx context.Context) error
+}
+
+// startupService es la implementación concreta de StartupService
+type startupService struct {
+	settings     config.StartupConfig
+	dependencies []StartupDependency
+
+	// pending son las dependencias obligatorias sin conectar con el último error de cada una
+	mu      sync.RWMutex
+	pending map[string]error
+}
+
+// NewStartupService crea el servicio de arranque con las dependencias a conectar
+func NewStartupService(settings config.StartupConfig, dependencies ...StartupDependency) StartupService {
+	pending := make(map[string]error)
+	for _, dependency := range dependencies {
+		if !dependency.Optional {
+			pending[dependency.Name] = fmt.Errorf("conectando")
+		}
+	}
+	return &startupService{
+		settings:     settings,
+		dependencies: dependencies,
+		pending:      pending,
+	}
+}
+
+// Wait conecta todas las dependencias en paralelo
+func (s *startupService) Wait(ctx context.Context) error {
+	errs := make([]error, len(s.dependencies))
+
+	var wg sync.WaitGroup
+	for i, dependency := range s.dependencies {
+		wg.Add(1)
+		go func(i int, dependency StartupDependency) {
+			defer wg.Done()
+			errs[i] = s.connect(ctx, dependency)
+		}(i, dependency)
+	}
+	wg.Wait()
+
+	var failed []string
+	for i, dependency := range s.dependencies {
+		if errs[i] != nil && !dependency.Optional {
+			failed = append(failed, fmt.Sprintf("%s (%v)", dependency.Name, errs[i]))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("dependencias sin conectar después de %v: %s", s.settings.MaxWait, strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// Check falla mientras falte conectar alguna dependencia obligatoria
+func (s *startupService) Check(ctx context.Context) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.pending) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(s.pending))
+	for name := range s.pending {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	waiting := make([]string, 0, len(names))
+	for _, name := range names {
+		waiting = append(waiting, fmt.Sprintf("%s: %v", name, s.pending[name]))
+	}
+	return fmt.Errorf("esperando dependencias (%s)", strings.Join(waiting, "; "))
+}
+
+// connect reintenta Connect con backoff exponencial hasta que conecte o venza MaxWait
+func (s *startupService) connect(ctx context.Context, dependency StartupDependency) error {
+	policy := resilience.RetryPolicy{BaseDelay: s.settings.RetryBaseDelay, MaxDelay: s.settings.RetryMaxDelay}
+	deadline := time.Now().Add(s.settings.MaxWait)
+
+	for attempt := 1; ; attempt++ {
+		err := s.attempt(ctx, dependency)
+		if err == nil {
+			s.markConnected(dependency.Name)
+			if attempt > 1 {
+				log.Printf("✅ %s conectado después de %d intentos", dependency.Name, attempt)
+			}
+			return nil
+		}
+		s.markPending(dependency, err)
+
+		delay := resilience.BackoffDelay(policy, attempt)
+		if time.Now().Add(delay).After(deadline) {
+			if dependency.Optional {
+				log.Printf("⚠️ %s no disponible después de %v, se sigue sin esa dependencia: %v", dependency.Name, s.settings.MaxWait, err)
+			}
+			return err
+		}
+		log.Printf("⏳ Esperando a %s (intento %d), reintentando en %v: %v", dependency.Name, attempt, delay, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt ejecuta un intento de conexión con su propio timeout
+func (s *startupService) attempt(ctx context.Context, dependency StartupDependency) error {
+	if s.settings.AttemptTimeout <= 0 {
+		return dependency.Connect(ctx)
+	}
+	attemptCtx, cancel := context.WithTimeout(ctx, s.settings.AttemptTimeout)
+	defer cancel()
+	return dependency.Connect(attemptCtx)
+}
+
+// markConnected saca la dependencia de las pendientes
+func (s *startupService) markConnected(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pending, name)
+}
+
+// markPending guarda el último error de una dependencia obligatoria pendiente
+func (s *startupService) markPending(dependency StartupDependency, err error) {
+	if dependency.Optional {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending[dependency.Name] = err
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"search-api/config"
+)
+
+// testStartupConfig espera poco entre intentos para que los tests no tarden
+var testStartupConfig = config.StartupConfig{MaxWait: 200 * time.Millisecond, RetryBaseDelay: time.Millisecond, RetryMaxDelay: 5 * time.Millisecond}
+
+// flakyDependency falla las primeras failures veces y después conecta
+func flakyDependency(name string, failures int32, optional bool) (StartupDependency, *atomic.Int32) {
+	attempts := &atomic.Int32{}
+	return StartupDependency{Name: name, Optional: optional, Connect: func(ctx context.Context) error {
+		if attempts.Add(1) <= failures {
+			return errors.New(name + " todavía no levantó")
+		}
+		return nil
+	}}, attempts
+}
+
+func TestStartup_RetriesUntilConnected(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	solr, solrAttempts := flakyDependency("solr", 3, false)
+	rabbit, rabbitAttempts := flakyDependency("rabbitmq", 0, false)
+	startup := NewStartupService(testStartupConfig, solr, rabbit)
+
+	if err := startup.Check(context.Background()); err == nil {
+		t.Fatal("expected not ready before connecting")
+	}
+	if err := startup.Wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if solrAttempts.Load() != 4 || rabbitAttempts.Load() != 1 {
+		t.Fatalf("expected 4 attempts for solr and 1 for rabbitmq, got %d and %d", solrAttempts.Load(), rabbitAttempts.Load())
+	}
+	if err := startup.Check(context.Background()); err != nil {
+		t.Fatalf("expected ready after connecting, got %v", err)
+	}
+}
+
+func TestStartup_GivesUpAfterMaxWait(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	tests := []struct {
+		name      string
+		optional  bool
+		wantErr   bool
+		wantReady bool
+	}{
+		{name: "required dependency", wantErr: true},
+		// Una opcional no frena el arranque ni la readiness: el servicio sigue degradado
+		{name: "optional dependency", optional: true, wantReady: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, _ := flakyDependency("memcached", 1<<30, tt.optional)
+			startup := NewStartupService(testStartupConfig, cache)
+
+			err := startup.Wait(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error=%v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr && !strings.Contains(err.Error(), "memcached") {
+				t.Fatalf("expected the dependency in the error, got %v", err)
+			}
+
+			ready := startup.Check(context.Background())
+			if (ready == nil) != tt.wantReady {
+				t.Fatalf("expected ready=%v, got %v", tt.wantReady, ready)
+			}
+			if ready != nil && !strings.Contains(ready.Error(), "memcached todavía no levantó") {
+				t.Fatalf("expected the last error in the readiness check, got %v", ready)
+			}
+		})
+	}
+}
+
+func TestStartup_StopsOnShutdown(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	solr, _ := flakyDependency("solr", 1<<30, false)
+	settings := testStartupConfig
+	settings.MaxWait, settings.RetryBaseDelay, settings.RetryMaxDelay = time.Hour, time.Minute, time.Minute
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	if err := NewStartupService(settings, solr).Wait(ctx); err == nil {
+		t.Fatal("expected an error after the shutdown")
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Fatalf("expected Wait to return on shutdown, took %v", elapsed)
+	}
+}
+
+func TestStartup_AttemptTimeout(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	// Un intento colgado se corta con AttemptTimeout y se reintenta
+	var attempts atomic.Int32
+	hanging := StartupDependency{Name: "mongo", Connect: func(ctx context.Context) error {
+		if attempts.Add(1) == 1 {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	}}
+	settings := testStartupConfig
+	settings.AttemptTimeout = 10 * time.Millisecond
+
+	if err := NewStartupService(settings, hanging).Wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if attempts.Load() != 2 {
+		t.Fatalf("expected 2 attempts, got %d", attempts.Load())
+	}
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	"resilience"
+	"search-api/rpc"
+	"signing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/status"
+)
+
+// APIKeyMetadataKey es el metadata gRPC con el que un servicio interno envía su API key
+const APIKeyMetadataKey = "x-api-key"
+
+// InternalCallAuth son las credenciales con las que search-api se identifica en las llamadas gRPC internas
+type InternalCallAuth struct {
+	Caller        string // Nombre del servicio, va firmado en cada llamada
+	APIKey        string // API key emitida en users-api (vacía = las llamadas van sin key)
+	SigningSecret string // Secreto compartido para firmar con HMAC (vacío = las llamadas van sin firma)
+}
+
+// NewGRPCConn crea la conexión gRPC hacia otro servicio interno (ej: "properties-api:9091")
+// La conexión es perezosa: se abre con la primera llamada y se reconecta sola si se cae
+// Se crea una sola vez al arrancar y se comparte entre las llamadas (multiplexadas sobre HTTP/2)
+func NewGRPCConn(addr string, auth InternalCallAuth) (*grpc.ClientConn, error) {
+	options := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.CallContentSubtype(rpc.CodecName)),
+	}
+	if auth.APIKey != "" {
+		options = append(options, grpc.WithPerRPCCredentials(apiKeyCredentials{key: auth.APIKey}))
+	}
+	if auth.SigningSecret != "" {
+		options = append(options, grpc.WithUnaryInterceptor(signing.UnaryClientInterceptor(auth.Caller, auth.SigningSecret, encoding.GetCodec(rpc.CodecName))))
+	}
+	conn, err := grpc.NewClient(addr, options...)
+	if err != nil {
+		return nil, fmt.Errorf("error creando conexión gRPC a %s: %w", addr, err)
+	}
+	return conn, nil
+}
+
+// GRPCCallError envuelve el error de una llamada gRPC
+// Solo los errores transitorios (servicio caído, timeout, sobrecarga) se reintentan; el resto es Permanent
+func GRPCCallError(service string, err error) error {
+	wrapped := fmt.Errorf("error llamando a %s por gRPC: %w", service, err)
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted, codes.Internal:
+		return wrapped
+	default:
+		return resilience.Permanent(wrapped)
+	}
+}
+
+// apiKeyCredentials agrega la API key al metadata de cada llamada gRPC
+type apiKeyCredentials struct {
+	key string
+}
+
+// GetRequestMetadata retorna el metadata que se envía en cada llamada
+func (c apiKeyCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{APIKeyMetadataKey: c.key}, nil
+}
+
+// RequireTransportSecurity es false porque la red interna usa conexiones sin TLS
+func (c apiKeyCredentials) RequireTransportSecurity() bool {
+	return false
+}
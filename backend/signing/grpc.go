@@ -0,0 +1,157 @@
+package signing
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryClientInterceptor firma cada llamada gRPC saliente con HMAC (ver InternalSignature)
+// codec es el que serializa los mensajes en la conexión: la firma cubre el SHA-256 de los bytes que se envían
+// Cada intento lleva su propio nonce, así los reintentos no se rechazan como repetidos
+func UnaryClientInterceptor(caller, secret string, codec encoding.Codec) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		body, err := codec.Marshal(req)
+		if err != nil {
+			return fmt.Errorf("error serializando el request a %s: %w", method, err)
+		}
+		nonce, err := NewInternalNonce()
+		if err != nil {
+			return err
+		}
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		ctx = metadata.AppendToOutgoingContext(ctx,
+			strings.ToLower(InternalCallerHeader), caller,
+			strings.ToLower(InternalTimestampHeader), timestamp,
+			strings.ToLower(InternalNonceHeader), nonce,
+			strings.ToLower(InternalSignatureHeader), InternalSignature(secret, caller, method, timestamp, nonce, HashInternalBody(body)),
+		)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// ServerVerifier valida la firma de las llamadas gRPC entrantes
+// Se usan juntos Codec (grpc.ForceServerCodec) y UnaryInterceptor: el codec guarda el SHA-256 de los bytes
+// recibidos de cada request y el interceptor lo toma al validar la firma, así se firma exactamente lo que
+// llegó por la red y no una re-serialización que dependa de los structs de cada servicio
+type ServerVerifier struct {
+	secret   string
+	required bool
+	maxSkew  time.Duration
+	codec    encoding.Codec
+	nonces   *NonceCache
+
+	// digests tiene el SHA-256 de cada request decodificado todavía no validado (la key es el puntero al request)
+	digests sync.Map
+	// warned tiene los métodos que ya recibieron una llamada sin firma (se loguea una vez por método)
+	warned sync.Map
+}
+
+// NewServerVerifier crea el verificador de firmas del servidor gRPC
+// codec es el que decodifica los mensajes del servicio (ej: el codec JSON de rpc)
+// Con required en false se aceptan las llamadas sin firma, para poder configurar los clientes de a uno
+func NewServerVerifier(secret string, required bool, maxSkew time.Duration, codec encoding.Codec) *ServerVerifier {
+	return &ServerVerifier{
+		secret:   secret,
+		required: required,
+		maxSkew:  maxSkew,
+		codec:    codec,
+		nonces:   NewNonceCache(2 * maxSkew),
+	}
+}
+
+// Codec retorna el codec que registra el SHA-256 de cada request recibido (se instala con grpc.ForceServerCodec)
+func (v *ServerVerifier) Codec() encoding.Codec {
+	return digestCodec{Codec: v.codec, digests: &v.digests}
+}
+
+// UnaryInterceptor exige que las llamadas gRPC internas vengan firmadas con el secreto compartido
+// La firma cubre el servicio que llama, el método, el timestamp, un nonce y el request (ver InternalSignature):
+// se rechazan las firmas inválidas, las que están fuera de la ventana de maxSkew y los nonces repetidos
+func (v *ServerVerifier) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		// El digest se retira siempre, también en las llamadas que no se validan, para no acumularlos
+		digest, found := v.digests.LoadAndDelete(req)
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		signature := firstMetadata(md, InternalSignatureHeader)
+
+		if signature == "" || v.secret == "" {
+			if v.required {
+				return nil, status.Error(codes.Unauthenticated, "firma interna requerida")
+			}
+			if signature == "" {
+				if _, loaded := v.warned.LoadOrStore(info.FullMethod, true); !loaded {
+					log.Printf("⚠️ Llamada gRPC sin firma a %s (se acepta porque INTERNAL_SIGNATURE_REQUIRED=false)", info.FullMethod)
+				}
+			}
+			return handler(ctx, req)
+		}
+
+		caller := firstMetadata(md, InternalCallerHeader)
+		timestamp := firstMetadata(md, InternalTimestampHeader)
+		nonce := firstMetadata(md, InternalNonceHeader)
+		now := time.Now()
+
+		if caller == "" || nonce == "" {
+			return nil, status.Error(codes.Unauthenticated, "firma interna incompleta")
+		}
+		if err := CheckInternalTimestamp(timestamp, now, v.maxSkew); err != nil {
+			return nil, status.Errorf(codes.Unauthenticated, "firma interna inválida: %v", err)
+		}
+		// Sin el codec instalado el request se vuelve a serializar (coincide si los dos servicios comparten el contrato)
+		if !found {
+			body, err := v.codec.Marshal(req)
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "error serializando el request: %v", err)
+			}
+			digest = HashInternalBody(body)
+		}
+		if !VerifyInternalSignature(v.secret, signature, caller, info.FullMethod, timestamp, nonce, digest.(string)) {
+			log.Printf("⚠️ Firma interna inválida en una llamada a %s (caller '%s')", info.FullMethod, caller)
+			return nil, status.Error(codes.Unauthenticated, "firma interna inválida")
+		}
+		// El nonce se registra después de validar la firma para que no se pueda llenar la caché sin el secreto
+		if !v.nonces.Remember(nonce, now) {
+			return nil, status.Error(codes.Unauthenticated, "llamada interna repetida")
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// digestCodec decodifica con el codec del servicio y guarda el SHA-256 de los bytes de cada request
+// gRPC decodifica cada request en un puntero nuevo, que es el mismo req que después recibe el interceptor
+type digestCodec struct {
+	encoding.Codec
+	digests *sync.Map
+}
+
+func (c digestCodec) Unmarshal(data []byte, v any) error {
+	if err := c.Codec.Unmarshal(data, v); err != nil {
+		return err
+	}
+	if reflect.ValueOf(v).Kind() == reflect.Pointer {
+		c.digests.Store(v, HashInternalBody(data))
+	}
+	return nil
+}
+
+// firstMetadata retorna el primer valor de un header del metadata gRPC (las claves van en minúsculas)
+func firstMetadata(md metadata.MD, header string) string {
+	if values := md.Get(strings.ToLower(header)); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
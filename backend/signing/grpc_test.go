@@ -0,0 +1,178 @@
+package signing
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const testSecret = "un-secreto-compartido-de-32-caracteres"
+
+// testCodec serializa como JSON, igual que el codec de los paquetes rpc de los servicios
+type testCodec struct{}
+
+func (testCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (testCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+func (testCodec) Name() string                       { return "json" }
+
+func init() {
+	encoding.RegisterCodec(testCodec{})
+}
+
+type echoRequest struct {
+	ID string `json:"id"`
+}
+
+type echoResponse struct {
+	ID string `json:"id"`
+}
+
+const echoMethod = "/test.Echo/Get"
+
+// echoServiceDesc es un servicio de prueba escrito a mano, como los ServiceDesc de los paquetes rpc
+var echoServiceDesc = grpc.ServiceDesc{
+	ServiceName: "test.Echo",
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Get",
+		Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+			request := new(echoRequest)
+			if err := dec(request); err != nil {
+				return nil, err
+			}
+			info := &grpc.UnaryServerInfo{Server: srv, FullMethod: echoMethod}
+			return interceptor(ctx, request, info, func(ctx context.Context, req any) (any, error) {
+				return &echoResponse{ID: req.(*echoRequest).ID}, nil
+			})
+		},
+	}},
+}
+
+// startEchoServer levanta el servidor con el verificador y retorna una conexión con los interceptores dados
+func startEchoServer(t *testing.T, verifier *ServerVerifier, interceptors ...grpc.UnaryClientInterceptor) *grpc.ClientConn {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.ForceServerCodec(verifier.Codec()), grpc.UnaryInterceptor(verifier.UnaryInterceptor()))
+	server.RegisterService(&echoServiceDesc, struct{}{})
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.CallContentSubtype("json")),
+		grpc.WithChainUnaryInterceptor(interceptors...),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestSignedGRPCCall_IsAccepted(t *testing.T) {
+	verifier := NewServerVerifier(testSecret, true, 2*time.Minute, testCodec{})
+	conn := startEchoServer(t, verifier, UnaryClientInterceptor("search-api", testSecret, testCodec{}))
+
+	var response echoResponse
+	if err := conn.Invoke(context.Background(), echoMethod, &echoRequest{ID: "p1"}, &response); err != nil {
+		t.Fatalf("expected the signed call to be accepted, got %v", err)
+	}
+	if response.ID != "p1" {
+		t.Fatalf("expected p1, got %q", response.ID)
+	}
+	// Cada request validado retira su digest
+	verifier.digests.Range(func(key, _ any) bool {
+		t.Fatalf("expected no pending digests, got one for %v", key)
+		return false
+	})
+}
+
+func TestSignedGRPCCall_RejectsATamperedRequest(t *testing.T) {
+	verifier := NewServerVerifier(testSecret, true, 2*time.Minute, testCodec{})
+	// Un intermediario cambia el request después de firmado: la firma sigue siendo la del request original
+	tamper := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(ctx, method, &echoRequest{ID: "p2"}, reply, cc, opts...)
+	}
+	conn := startEchoServer(t, verifier, UnaryClientInterceptor("search-api", testSecret, testCodec{}), tamper)
+
+	err := conn.Invoke(context.Background(), echoMethod, &echoRequest{ID: "p1"}, &echoResponse{})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated for a tampered request, got %v", err)
+	}
+}
+
+func TestSignedGRPCCall_RejectsAnotherSecret(t *testing.T) {
+	verifier := NewServerVerifier(testSecret, true, 2*time.Minute, testCodec{})
+	conn := startEchoServer(t, verifier, UnaryClientInterceptor("search-api", "otro-secreto", testCodec{}))
+
+	err := conn.Invoke(context.Background(), echoMethod, &echoRequest{ID: "p1"}, &echoResponse{})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated for a signature with another secret, got %v", err)
+	}
+}
+
+func TestUnsignedGRPCCall_DependsOnRequired(t *testing.T) {
+	for _, required := range []bool{false, true} {
+		verifier := NewServerVerifier(testSecret, required, 2*time.Minute, testCodec{})
+		conn := startEchoServer(t, verifier)
+
+		err := conn.Invoke(context.Background(), echoMethod, &echoRequest{ID: "p1"}, &echoResponse{})
+		if required && status.Code(err) != codes.Unauthenticated {
+			t.Fatalf("expected Unauthenticated for an unsigned call when required, got %v", err)
+		}
+		if !required && err != nil {
+			t.Fatalf("expected the unsigned call to be accepted when not required, got %v", err)
+		}
+	}
+}
+
+// signedContext arma el metadata de una llamada firmada, como la recibe el servidor
+func signedContext(request *echoRequest, timestamp time.Time, nonce string) context.Context {
+	body, _ := json.Marshal(request)
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		strings.ToLower(InternalCallerHeader), "graphql-api",
+		strings.ToLower(InternalTimestampHeader), unix,
+		strings.ToLower(InternalNonceHeader), nonce,
+		strings.ToLower(InternalSignatureHeader), InternalSignature(testSecret, "graphql-api", echoMethod, unix, nonce, HashInternalBody(body)),
+	))
+}
+
+func TestServerVerifier_RejectsReplaysAndStaleTimestamps(t *testing.T) {
+	interceptor := NewServerVerifier(testSecret, true, 2*time.Minute, testCodec{}).UnaryInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: echoMethod}
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+	request := &echoRequest{ID: "p1"}
+
+	// Sin el codec instalado el interceptor vuelve a serializar el request para validar la firma
+	ctx := signedContext(request, time.Now(), "nonce-1")
+	if _, err := interceptor(ctx, request, info, handler); err != nil {
+		t.Fatalf("expected the first call to be accepted, got %v", err)
+	}
+	if _, err := interceptor(ctx, request, info, handler); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated for a replayed call, got %v", err)
+	}
+
+	stale := signedContext(request, time.Now().Add(-3*time.Minute), "nonce-2")
+	if _, err := interceptor(stale, request, info, handler); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated for a stale timestamp, got %v", err)
+	}
+
+	other := signedContext(request, time.Now(), "nonce-3")
+	if _, err := interceptor(other, &echoRequest{ID: "p2"}, info, handler); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated for a request other than the signed one, got %v", err)
+	}
+}
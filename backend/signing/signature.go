@@ -0,0 +1,101 @@
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Headers de las llamadas internas firmadas con HMAC
+// En gRPC viajan como metadata con el mismo nombre en minúsculas
+const (
+	InternalCallerHeader    = "X-Internal-Caller"
+	InternalTimestampHeader = "X-Internal-Timestamp"
+	InternalNonceHeader     = "X-Internal-Nonce"
+	InternalSignatureHeader = "X-Internal-Signature"
+)
+
+// InternalSignature calcula la firma HMAC-SHA256 (en hexadecimal) de una llamada interna
+// parts son los campos firmados en orden, unidos con "\n":
+//   - gRPC: caller, método completo, timestamp, nonce, SHA-256 del request serializado
+//   - HTTP: caller, método, path con query, timestamp, nonce, SHA-256 del body
+func InternalSignature(secret string, parts ...string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyInternalSignature compara la firma recibida con la esperada en tiempo constante
+func VerifyInternalSignature(secret, signature string, parts ...string) bool {
+	expected := InternalSignature(secret, parts...)
+	return hmac.Equal([]byte(expected), []byte(strings.ToLower(signature)))
+}
+
+// NewInternalNonce genera el nonce de una llamada firmada (128 bits en hexadecimal)
+func NewInternalNonce() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("error generando nonce: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// HashInternalBody retorna el SHA-256 en hexadecimal del body que se firma en HTTP
+func HashInternalBody(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// CheckInternalTimestamp valida que el timestamp (segundos Unix) esté a menos de maxSkew de now
+func CheckInternalTimestamp(raw string, now time.Time, maxSkew time.Duration) error {
+	seconds, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return fmt.Errorf("timestamp inválido '%s'", raw)
+	}
+	skew := now.Sub(time.Unix(seconds, 0))
+	if skew > maxSkew || skew < -maxSkew {
+		return fmt.Errorf("timestamp fuera de la ventana de %s", maxSkew)
+	}
+	return nil
+}
+
+// NonceCache recuerda los nonces de las llamadas firmadas para rechazar las repetidas
+// Un nonce solo hace falta recordarlo mientras su timestamp está dentro de la ventana
+type NonceCache struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	seen      map[string]time.Time
+	lastSweep time.Time
+}
+
+// NewNonceCache crea la caché; ttl debe cubrir la ventana completa del timestamp (2 × maxSkew)
+func NewNonceCache(ttl time.Duration) *NonceCache {
+	return &NonceCache{ttl: ttl, seen: make(map[string]time.Time)}
+}
+
+// Remember registra el nonce; retorna false si ya se había visto (llamada repetida)
+func (c *NonceCache) Remember(nonce string, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Los vencidos se barren cada ttl para no recorrer el mapa en cada llamada
+	if now.Sub(c.lastSweep) >= c.ttl {
+		for seen, expiresAt := range c.seen {
+			if now.After(expiresAt) {
+				delete(c.seen, seen)
+			}
+		}
+		c.lastSweep = now
+	}
+	if expiresAt, found := c.seen[nonce]; found && !now.After(expiresAt) {
+		return false
+	}
+	c.seen[nonce] = now.Add(c.ttl)
+	return true
+}
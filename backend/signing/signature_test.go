@@ -0,0 +1,132 @@
+package signing
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInternalSignature_MatchesHMACOverTheJoinedParts(t *testing.T) {
+	// Vector calculado aparte con HMAC-SHA256("secreto", partes unidas con "\n")
+	const expected = "16e76c542bd68f3828b931f2bae6db5303dbdb61f007d18ffa899980533d09ad"
+	got := InternalSignature("secreto", "search-api", "/properties.v1.Properties/GetProperty", "1700000000", "abc", HashInternalBody([]byte(`{"id":"p1"}`)))
+	if got != expected {
+		t.Fatalf("expected %s, got %s", expected, got)
+	}
+	if empty := HashInternalBody(nil); empty != "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" {
+		t.Fatalf("expected the SHA-256 of an empty body, got %s", empty)
+	}
+}
+
+func TestVerifyInternalSignature(t *testing.T) {
+	parts := []string{"search-api", "/properties.v1.Properties/GetProperty", "1700000000", "abc", HashInternalBody([]byte(`{"id":"p1"}`))}
+	signature := InternalSignature("secreto", parts...)
+
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		parts     []string
+		want      bool
+	}{
+		{name: "valid", secret: "secreto", signature: signature, parts: parts, want: true},
+		{name: "uppercase hex", secret: "secreto", signature: strings.ToUpper(signature), parts: parts, want: true},
+		{name: "wrong secret", secret: "otro", signature: signature, parts: parts, want: false},
+		{name: "other caller", secret: "secreto", signature: signature, parts: []string{"graphql-api", parts[1], parts[2], parts[3], parts[4]}, want: false},
+		{name: "other method", secret: "secreto", signature: signature, parts: []string{parts[0], "/users.v1.Users/GetUser", parts[2], parts[3], parts[4]}, want: false},
+		{name: "tampered request", secret: "secreto", signature: signature, parts: []string{parts[0], parts[1], parts[2], parts[3], HashInternalBody([]byte(`{"id":"p2"}`))}, want: false},
+		{name: "truncated signature", secret: "secreto", signature: signature[:32], parts: parts, want: false},
+		{name: "empty signature", secret: "secreto", signature: "", parts: parts, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifyInternalSignature(tt.secret, tt.signature, tt.parts...); got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNewInternalNonce_IsRandomHex(t *testing.T) {
+	first, err := NewInternalNonce()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := NewInternalNonce()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(first) != 32 || strings.Trim(first, "0123456789abcdef") != "" {
+		t.Fatalf("expected 32 hex characters, got %q", first)
+	}
+	if first == second {
+		t.Fatalf("expected two different nonces, got %q twice", first)
+	}
+}
+
+func TestCheckInternalTimestamp(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr bool
+	}{
+		{name: "now", raw: "1700000000"},
+		{name: "at the past edge", raw: "1699999880"},
+		{name: "at the future edge", raw: "1700000120"},
+		{name: "too old", raw: "1699999879", wantErr: true},
+		{name: "too far ahead", raw: "1700000121", wantErr: true},
+		{name: "not a number", raw: "ayer", wantErr: true},
+		{name: "empty", raw: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckInternalTimestamp(tt.raw, now, 2*time.Minute)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestNonceCache_RejectsRepeatedNoncesWhileTheyAreInTheWindow(t *testing.T) {
+	cache := NewNonceCache(4 * time.Minute)
+	now := time.Unix(1700000000, 0)
+
+	if !cache.Remember("abc", now) {
+		t.Fatal("expected a new nonce to be accepted")
+	}
+	if cache.Remember("abc", now.Add(time.Minute)) {
+		t.Fatal("expected a repeated nonce to be rejected")
+	}
+	if !cache.Remember("def", now.Add(time.Minute)) {
+		t.Fatal("expected a different nonce to be accepted")
+	}
+	// Pasada la ventana el timestamp ya no es válido, así que el nonce se puede olvidar
+	if !cache.Remember("abc", now.Add(5*time.Minute)) {
+		t.Fatal("expected the nonce to be forgotten after the window")
+	}
+}
+
+func TestNonceCache_SweepsExpiredNonces(t *testing.T) {
+	cache := NewNonceCache(time.Minute)
+	now := time.Unix(1700000000, 0)
+	for _, nonce := range []string{"a", "b", "c"} {
+		cache.Remember(nonce, now)
+	}
+
+	// Dentro del ttl no se barre
+	cache.Remember("d", now.Add(30*time.Second))
+	if len(cache.seen) != 4 {
+		t.Fatalf("expected 4 nonces remembered, got %d", len(cache.seen))
+	}
+
+	// El barrido quita los vencidos y conserva los que siguen en la ventana
+	cache.Remember("e", now.Add(80*time.Second))
+	if len(cache.seen) != 2 {
+		t.Fatalf("expected only d and e after the sweep, got %v", cache.seen)
+	}
+	if _, found := cache.seen["d"]; !found {
+		t.Fatal("expected d to be kept until it expires")
+	}
+}
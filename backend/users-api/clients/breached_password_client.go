@@ -0,0 +1,88 @@
+package clients
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// breachedPrefixLength es la cantidad de caracteres del SHA-1 que se envían a la API de rango
+const breachedPrefixLength = 5
+
+// BreachedPasswordChecker consulta si una contraseña aparece en filtraciones conocidas
+type BreachedPasswordChecker interface {
+	// BreachCount retorna cuántas veces aparece la contraseña en filtraciones (0 = no aparece)
+	BreachCount(password string) (int, error)
+}
+
+type rangeBreachedPasswordChecker struct {
+	rangeURL   string
+	timeout    time.Duration
+	httpClient *http.Client
+}
+
+// NewBreachedPasswordChecker crea el cliente de una API de rango con k-anonymity (formato de Pwned Passwords)
+// rangeURL es la URL a la que se le agrega el prefijo del hash (ej: https://api.pwnedpasswords.com/range/)
+// httpClient es el cliente HTTP compartido (ver NewHTTPClient); timeout limita cada consulta
+func NewBreachedPasswordChecker(rangeURL string, timeout time.Duration, httpClient *http.Client) BreachedPasswordChecker {
+	return &rangeBreachedPasswordChecker{
+		rangeURL:   rangeURL,
+		timeout:    timeout,
+		httpClient: httpClient,
+	}
+}
+
+// BreachCount hace GET {rangeURL}{prefijo} con los primeros 5 caracteres del SHA-1 de la contraseña
+// La API responde los sufijos de todos los hashes con ese prefijo ("SUFIJO:cantidad" por línea) y la
+// comparación se hace acá: ni la contraseña ni su hash completo salen del servicio
+func (c *rangeBreachedPasswordChecker) BreachCount(password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:breachedPrefixLength], hash[breachedPrefixLength:]
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.rangeURL+prefix, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error creando request a la API de contraseñas filtradas: %w", err)
+	}
+	// Add-Padding completa la respuesta con sufijos falsos (cantidad 0) para que su tamaño no revele el prefijo
+	req.Header.Set("Add-Padding", "true")
+	req.Header.Set("User-Agent", "users-api")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("error haciendo request a la API de contraseñas filtradas: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return 0, fmt.Errorf("la API de contraseñas filtradas respondió status %d: %s", resp.StatusCode, string(body))
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		candidate, count, found := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !found || !strings.EqualFold(candidate, suffix) {
+			continue
+		}
+		n, err := strconv.Atoi(count)
+		if err != nil {
+			return 0, fmt.Errorf("respuesta inválida de la API de contraseñas filtradas: '%s'", scanner.Text())
+		}
+		return n, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("error leyendo respuesta de la API de contraseñas filtradas: %w", err)
+	}
+	return 0, nil
+}
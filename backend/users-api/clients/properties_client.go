@@ -0,0 +1,68 @@
+package clients
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// PropertiesUserData son los datos que properties-api guarda de un usuario
+// Se mantienen como JSON crudo para exportarlos tal cual los devuelve properties-api
+type PropertiesUserData struct {
+	Properties json.RawMessage `json:"properties"`
+	Bookings   json.RawMessage `json:"bookings"`
+}
+
+// PropertiesClient se comunica con properties-api
+type PropertiesClient interface {
+	// GetUserData obtiene las propiedades y reservas de un usuario
+	// authorization es el header Authorization del request original (el caller debe ser el usuario o un admin)
+	GetUserData(userID uint, authorization string) (*PropertiesUserData, error)
+}
+
+type propertiesClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewPropertiesClient crea el cliente de properties-api
+// baseURL incluye el prefijo /api (ej: http://properties-api:8081/api)
+// httpClient es el cliente HTTP compartido (ver NewHTTPClient)
+func NewPropertiesClient(baseURL string, httpClient *http.Client) PropertiesClient {
+	return &propertiesClient{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: httpClient,
+	}
+}
+
+// GetUserData hace GET {baseURL}/users/{userID}/export
+func (c *propertiesClient) GetUserData(userID uint, authorization string) (*PropertiesUserData, error) {
+	url := fmt.Sprintf("%s/users/%d/export", c.baseURL, userID)
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creando request a properties-api: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", authorization)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error haciendo request a properties-api: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("properties-api respondió status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var data PropertiesUserData
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("error decodificando respuesta de properties-api: %w", err)
+	}
+
+	return &data, nil
+}
@@ -0,0 +1,86 @@
+package clients
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// securityAlertsQueue es la cola que consume el servicio de notificaciones
+const securityAlertsQueue = "security_alerts"
+
+// SecurityAlertEvent es el evento que se publica ante un login sospechoso
+type SecurityAlertEvent struct {
+	// Type indica el tipo de alerta (por ahora solo "suspicious_login")
+	Type string `json:"type"`
+
+	UserID    uint   `json:"userId"`
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	IP        string `json:"ip"`
+	UserAgent string `json:"userAgent"`
+	Country   string `json:"country,omitempty"`
+
+	// Reasons indica por qué se marcó el login: "new_device", "new_country"
+	Reasons []string `json:"reasons"`
+
+	OccurredAt time.Time `json:"occurredAt"`
+}
+
+// SecurityAlertPublisher publica alertas de seguridad para el servicio de notificaciones
+type SecurityAlertPublisher interface {
+	PublishSecurityAlert(alert SecurityAlertEvent) error
+}
+
+// rabbitMQSecurityAlertPublisher publica las alertas en RabbitMQ
+type rabbitMQSecurityAlertPublisher struct {
+	conn    *amqp.Connection
+	channel *amqp.Channel
+}
+
+// NewRabbitMQSecurityAlertPublisher se conecta a RabbitMQ y declara la cola "security_alerts"
+func NewRabbitMQSecurityAlertPublisher(url string) (SecurityAlertPublisher, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, fmt.Errorf("error conectando a RabbitMQ: %w", err)
+	}
+
+	channel, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error abriendo canal de RabbitMQ: %w", err)
+	}
+
+	// durable=true para que las alertas sobrevivan a reinicios del servidor
+	if _, err := channel.QueueDeclare(securityAlertsQueue, true, false, false, false, nil); err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("error declarando cola '%s': %w", securityAlertsQueue, err)
+	}
+
+	return &rabbitMQSecurityAlertPublisher{
+		conn:    conn,
+		channel: channel,
+	}, nil
+}
+
+// PublishSecurityAlert serializa la alerta a JSON y la publica en la cola
+func (p *rabbitMQSecurityAlertPublisher) PublishSecurityAlert(alert SecurityAlertEvent) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("error serializando alerta de seguridad: %w", err)
+	}
+
+	err = p.channel.Publish("", securityAlertsQueue, false, false, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Body:         body,
+	})
+	if err != nil {
+		return fmt.Errorf("error publicando alerta en la cola '%s': %w", securityAlertsQueue, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package clients
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// DefaultUserExchange es el topic exchange donde se publican los eventos de usuarios
+const DefaultUserExchange = "users_exchange"
+
+// UserEventDeactivated se publica cuando una cuenta se desactiva
+// Los consumidores (ej: search-api) ocultan las propiedades del usuario
+const UserEventDeactivated = "user.deactivated"
+
+// UserEventErased se publica cuando se borran los datos personales de una cuenta (GDPR)
+// Cada servicio anonimiza los datos que guarda de ese usuario
+const UserEventErased = "user.erased"
+
+// UserEvent es el evento que se publica ante cambios de estado de una cuenta
+type UserEvent struct {
+	// Type es el tipo de evento y se usa como routing key (ej: "user.deactivated", "user.erased")
+	Type string `json:"type"`
+
+	UserID     uint      `json:"userId"`
+	OccurredAt time.Time `json:"occurredAt"`
+}
+
+// UserEventPublisher publica eventos de usuarios para el resto de los servicios
+type UserEventPublisher interface {
+	PublishUserEvent(event UserEvent) error
+
+	// Ping retorna error si la conexión con RabbitMQ está cerrada (usado por el health check)
+	Ping() error
+}
+
+// rabbitMQUserEventPublisher publica los eventos en un topic exchange de RabbitMQ
+type rabbitMQUserEventPublisher struct {
+	conn     *amqp.Connection
+	channel  *amqp.Channel
+	exchange string
+}
+
+// NewRabbitMQUserEventPublisher se conecta a RabbitMQ y declara el topic exchange de usuarios
+func NewRabbitMQUserEventPublisher(url, exchange string) (UserEventPublisher, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, fmt.Errorf("error conectando a RabbitMQ: %w", err)
+	}
+
+	channel, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error abriendo canal de RabbitMQ: %w", err)
+	}
+
+	// durable=true para que el exchange sobreviva a reinicios del servidor
+	if err := channel.ExchangeDeclare(exchange, "topic", true, false, false, false, nil); err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("error declarando exchange '%s': %w", exchange, err)
+	}
+
+	return &rabbitMQUserEventPublisher{
+		conn:     conn,
+		channel:  channel,
+		exchange: exchange,
+	}, nil
+}
+
+// PublishUserEvent serializa el evento a JSON y lo publica con su tipo como routing key
+func (p *rabbitMQUserEventPublisher) PublishUserEvent(event UserEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("error serializando evento de usuario: %w", err)
+	}
+
+	err = p.channel.Publish(p.exchange, event.Type, false, false, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Timestamp:    time.Now().UTC(), // search-api mide con esto la demora de su consumidor
+		Body:         body,
+	})
+	if err != nil {
+		return fmt.Errorf("error publicando evento '%s' en el exchange '%s': %w", event.Type, p.exchange, err)
+	}
+
+	return nil
+}
+
+// Ping retorna error si la conexión con RabbitMQ está cerrada
+func (p *rabbitMQUserEventPublisher) Ping() error {
+	if p.conn.IsClosed() {
+		return fmt.Errorf("conexión con RabbitMQ cerrada")
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"users-api/config"
+	"users-api/migrations"
+	"users-api/repositories"
+	"users-api/services"
+)
+
+// runCommand ejecuta un comando de administración en lugar de levantar el servidor
+// Uso: users-api migrate up | down [n] | status | force <versión>
+//
+//	users-api seed
+func runCommand(cfg *config.Config, args []string) error {
+	switch args[0] {
+	case "migrate":
+		return runMigrateCommand(cfg.Database, args[1:])
+	case "seed":
+		return runSeedCommand(cfg)
+	default:
+		return fmt.Errorf("comando desconocido '%s' (disponibles: migrate, seed)", args[0])
+	}
+}
+
+// runSeedCommand aplica las migraciones pendientes y carga los usuarios de demo
+func runSeedCommand(cfg *config.Config) error {
+	if err := migrations.Up(cfg.Database); err != nil {
+		return err
+	}
+	db, err := config.ConnectMySQL(cfg.Database)
+	if err != nil {
+		return err
+	}
+
+	userRepo := repositories.NewUserRepository(db)
+	auditService := services.NewAuditService(repositories.NewAuditRepository(db))
+	report, err := services.NewSeedService(userRepo, auditService).Seed()
+	if err != nil {
+		return err
+	}
+	log.Printf("🌱 Usuarios de demo: %d creados, %d ya existían (contraseña '%s')", report.Created, report.Skipped, services.SeedPassword)
+	log.Printf("   - Admin: demo_admin (ID %d)", services.SeedAdminID)
+	log.Printf("   - Anfitriones: host01..host%02d (IDs %d a %d)", services.SeedHostCount,
+		services.SeedFirstHostID, services.SeedFirstHostID+services.SeedHostCount-1)
+	log.Printf("   - Huéspedes: guest01..guest%02d (IDs %d a %d)", services.SeedGuestCount,
+		services.SeedFirstHostID+services.SeedHostCount, services.SeedFirstHostID+services.SeedHostCount+services.SeedGuestCount-1)
+	return nil
+}
+
+// runMigrateCommand aplica, revierte o muestra las migraciones versionadas
+func runMigrateCommand(database config.DatabaseConfig, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("uso: users-api migrate up | down [n] | status | force <versión>")
+	}
+
+	switch args[0] {
+	case "up":
+		if err := migrations.Up(database); err != nil {
+			return err
+		}
+		log.Println("✅ Migraciones aplicadas")
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil {
+				return fmt.Errorf("cantidad de migraciones inválida '%s'", args[1])
+			}
+			steps = n
+		}
+		if err := migrations.Down(database, steps); err != nil {
+			return err
+		}
+		log.Printf("✅ %d migración(es) revertida(s)", steps)
+	case "force":
+		if len(args) < 2 {
+			return fmt.Errorf("uso: users-api migrate force <versión>")
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("versión inválida '%s'", args[1])
+		}
+		if err := migrations.Force(database, version); err != nil {
+			return err
+		}
+		log.Printf("✅ Base marcada en la versión %d", version)
+	case "status":
+		status, err := migrations.GetStatus(database)
+		if err != nil {
+			return err
+		}
+		log.Printf("🗄️  Versión %d de %d (pendientes: %d, dirty: %t)", status.Version, status.Latest, status.Pending, status.Dirty)
+		for _, migration := range status.Migrations {
+			mark := "⏳"
+			if migration.Applied {
+				mark = "✅"
+			}
+			log.Printf("   %s %06d %s", mark, migration.Version, migration.Name)
+		}
+	default:
+		return fmt.Errorf("subcomando de migrate desconocido '%s' (up, down, status, force)", args[0])
+	}
+	return nil
+}
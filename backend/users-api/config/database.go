@@ -0,0 +1,115 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// maxConnectRetryDelay es la espera máxima entre reintentos de conexión al arrancar
+const maxConnectRetryDelay = 10 * time.Second
+
+// pingTimeout es el timeout de cada ping a MySQL
+const pingTimeout = 5 * time.Second
+
+// ConnectMySQL abre la conexión con MySQL y configura el pool
+// Si MySQL todavía no responde (ej: el contenedor está arrancando) reintenta con backoff
+// exponencial hasta ConnectMaxWait; recién ahí retorna el último error
+func ConnectMySQL(cfg DatabaseConfig) (*gorm.DB, error) {
+	deadline := time.Now().Add(cfg.ConnectMaxWait)
+	delay := cfg.ConnectRetryDelay
+
+	for attempt := 1; ; attempt++ {
+		db, err := openMySQL(cfg)
+		if err == nil {
+			if attempt > 1 {
+				log.Printf("✅ MySQL conectado después de %d intentos", attempt)
+			}
+			return db, nil
+		}
+
+		if time.Now().Add(delay).After(deadline) {
+			return nil, fmt.Errorf("MySQL no disponible después de %d intentos: %w", attempt, err)
+		}
+		log.Printf("⏳ Esperando a MySQL (intento %d), reintentando en %v: %v", attempt, delay, err)
+		time.Sleep(delay)
+
+		delay *= 2
+		if delay > maxConnectRetryDelay {
+			delay = maxConnectRetryDelay
+		}
+	}
+}
+
+// openMySQL hace un intento de conexión: abre el pool, lo configura y verifica con un ping
+func openMySQL(cfg DatabaseConfig) (*gorm.DB, error) {
+	// Todos los timestamps se guardan en UTC
+	db, err := gorm.Open(mysql.Open(cfg.DSN()), &gorm.Config{
+		NowFunc: func() time.Time { return time.Now().UTC() },
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+	if err := sqlDB.PingContext(ctx); err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// MonitorMySQL hace ping a MySQL cada PingInterval hasta que se cancele ctx
+// database/sql vuelve a abrir conexiones solo, pero las ociosas del pool quedan rotas después
+// de un reinicio de MySQL: cuando el ping falla se descartan para que las próximas consultas
+// usen conexiones nuevas, y se loguea cuando MySQL vuelve a responder
+func MonitorMySQL(ctx context.Context, sqlDB *sql.DB, cfg DatabaseConfig) {
+	if cfg.PingInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(cfg.PingInterval)
+	defer ticker.Stop()
+
+	down := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+		err := sqlDB.PingContext(pingCtx)
+		cancel()
+
+		if err != nil {
+			if !down {
+				log.Printf("⚠️ MySQL no responde, descartando conexiones ociosas: %v", err)
+			}
+			down = true
+			// Con 0 ociosas el pool cierra las que tiene; después se restaura el límite
+			sqlDB.SetMaxIdleConns(0)
+			sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+			continue
+		}
+		if down {
+			log.Println("✅ MySQL reconectado")
+			down = false
+		}
+	}
+}
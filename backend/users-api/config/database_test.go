@@ -0,0 +1,158 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyDriver es un driver de database/sql cuyo ping falla mientras down sea true
+// Cuenta las conexiones abiertas y cerradas para ver qué hace el pool
+type flakyDriver struct {
+	down           atomic.Bool
+	opened, closed atomic.Int32
+}
+
+func (d *flakyDriver) Open(name string) (driver.Conn, error) {
+	d.opened.Add(1)
+	return &flakyConn{driver: d}, nil
+}
+
+type flakyConn struct {
+	driver *flakyDriver
+}
+
+func (c *flakyConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("no soportado")
+}
+
+func (c *flakyConn) Close() error {
+	c.driver.closed.Add(1)
+	return nil
+}
+
+func (c *flakyConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("no soportado")
+}
+
+func (c *flakyConn) Ping(ctx context.Context) error {
+	if c.driver.down.Load() {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+var testDriver = &flakyDriver{}
+
+func init() {
+	sql.Register("flakymysql", testDriver)
+}
+
+func TestConnectMySQL_GivesUpAfterMaxWait(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	// Un puerto donde no escucha nadie, como MySQL mientras el contenedor arranca
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	cfg := DatabaseConfig{
+		Host: "127.0.0.1", Port: strconv.Itoa(port), User: "root", Name: "users",
+		ConnectMaxWait: 100 * time.Millisecond, ConnectRetryDelay: 10 * time.Millisecond,
+	}
+	started := time.Now()
+	_, err = ConnectMySQL(cfg)
+	if err == nil {
+		t.Fatalf("expected an error with nothing listening on %d", port)
+	}
+	if !strings.Contains(err.Error(), "MySQL no disponible") || strings.Contains(err.Error(), "después de 1 intentos") {
+		t.Fatalf("expected several attempts before giving up, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > 2*time.Second {
+		t.Fatalf("expected to give up around ConnectMaxWait, took %v", elapsed)
+	}
+}
+
+func TestMonitorMySQL_DropsIdleConnectionsWhileDown(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	sqlDB, err := sql.Open("flakymysql", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer sqlDB.Close()
+	cfg := DatabaseConfig{MaxIdleConns: 2, PingInterval: 5 * time.Millisecond}
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+
+	// Dos conexiones ociosas en el pool, abiertas antes de que MySQL se reinicie
+	first, _ := sqlDB.Conn(context.Background())
+	second, _ := sqlDB.Conn(context.Background())
+	first.Close()
+	second.Close()
+	if idle := sqlDB.Stats().Idle; idle != 2 {
+		t.Fatalf("expected 2 idle connections, got %d", idle)
+	}
+
+	testDriver.down.Store(true)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		MonitorMySQL(ctx, sqlDB, cfg)
+		close(done)
+	}()
+
+	waitFor(t, "the idle connections to be closed", func() bool { return testDriver.closed.Load() >= 2 })
+
+	// Cuando vuelve, las consultas usan conexiones nuevas y el pool conserva su límite
+	testDriver.down.Store(false)
+	waitFor(t, "a new idle connection", func() bool {
+		return sqlDB.PingContext(context.Background()) == nil && sqlDB.Stats().Idle > 0
+	})
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the monitor to stop when the context is cancelled")
+	}
+}
+
+func TestMonitorMySQL_DisabledWithoutInterval(t *testing.T) {
+	done := make(chan struct{})
+	go func() {
+		MonitorMySQL(context.Background(), nil, DatabaseConfig{})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the monitor disabled with PingInterval 0")
+	}
+}
+
+// waitFor espera hasta un segundo a que se cumpla condition
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
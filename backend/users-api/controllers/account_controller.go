@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"users-api/dto"
+	"users-api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccountController struct {
+	service services.AccountService
+}
+
+func NewAccountController(service services.AccountService) *AccountController {
+	return &AccountController{service: service}
+}
+
+// Deactivate desactiva una cuenta (el propio usuario o un admin)
+func (ctrl *AccountController) Deactivate(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "ID inválido"})
+		return
+	}
+
+	actor, actorType := actorFromContext(c)
+
+	user, err := ctrl.service.DeactivateUser(actor, actorType, uint(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrForbidden):
+			c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, services.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, services.ErrLastAdmin):
+			c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// ChangePassword cambia la contraseña del usuario autenticado
+// Responde un token nuevo: el del request deja de servir, igual que los de las otras sesiones
+func (ctrl *AccountController) ChangePassword(c *gin.Context) {
+	var req dto.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	actor, _ := actorFromContext(c)
+
+	response, err := ctrl.service.ChangePassword(actor, req)
+	if respondPasswordPolicyError(c, err) {
+		return
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrWrongPassword):
+			c.JSON(http.StatusBadRequest, dto.ValidationErrorResponse{
+				Error:  err.Error(),
+				Fields: []dto.FieldError{{Field: "currentPassword", Code: "incorrect", Message: err.Error()}},
+			})
+		case errors.Is(err, services.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, services.ErrUserInactive):
+			c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"users-api/dto"
+	"users-api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyController struct {
+	service services.APIKeyService
+}
+
+func NewAPIKeyController(service services.APIKeyService) *APIKeyController {
+	return &APIKeyController{service: service}
+}
+
+// Create emite una API key para un servicio interno (solo admin)
+// La respuesta es la única vez que se muestra la key completa
+func (ctrl *APIKeyController) Create(c *gin.Context) {
+	var req dto.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	actorID, _ := c.Get("user_id")
+	actor, _ := actorID.(uint)
+
+	key, err := ctrl.service.Issue(actor, req)
+	if err != nil {
+		writeAPIKeyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+// List lista las API keys emitidas (solo admin)
+func (ctrl *APIKeyController) List(c *gin.Context) {
+	keys, err := ctrl.service.List()
+	if err != nil {
+		writeAPIKeyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// Revoke revoca una API key (solo admin)
+func (ctrl *APIKeyController) Revoke(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "ID inválido"})
+		return
+	}
+
+	actorID, _ := c.Get("user_id")
+	actor, _ := actorID.(uint)
+
+	key, err := ctrl.service.Revoke(actor, uint(id))
+	if err != nil {
+		writeAPIKeyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, key)
+}
+
+// writeAPIKeyError mapea los errores del servicio de API keys a status HTTP
+func writeAPIKeyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrAPIKeyNotFound):
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrInvalidAPIKeyScopes):
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+}
@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"users-api/dto"
+	"users-api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditController struct {
+	service services.AuditService
+}
+
+func NewAuditController(service services.AuditService) *AuditController {
+	return &AuditController{service: service}
+}
+
+// List consulta el log de auditoría (solo admin)
+// Query params opcionales: userId (quién hizo la operación), from, to, page y limit (máx 100)
+func (ctrl *AuditController) List(c *gin.Context) {
+	var query dto.AuditQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	entries, err := ctrl.service.List(query)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidDateRange) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
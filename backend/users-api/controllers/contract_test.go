@@ -0,0 +1,210 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"users-api/dto"
+	"users-api/rpc"
+	"users-api/services"
+	"users-api/utils"
+)
+
+// ============================================
+// Contratos consumer-driven (backend/contracts)
+// ============================================
+// Los consumidores de users-api dejan en backend/contracts/<consumer>/users-api los requests que
+// mandan y los campos que leen de la respuesta. Estos tests verifican que users-api acepte esos
+// requests y responda con todos esos campos y los mismos tipos JSON
+
+// contractsDir es backend/contracts relativo al paquete
+const contractsDir = "../../contracts"
+
+// contract es un contrato consumer-driven: el request que manda el consumidor y la respuesta que espera
+type contract struct {
+	Consumer    string          `json:"consumer"`
+	Provider    string          `json:"provider"`
+	Description string          `json:"description"`
+	Request     json.RawMessage `json:"request"`
+	Response    json.RawMessage `json:"response"`
+}
+
+func loadContract(t *testing.T, name string) contract {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(contractsDir, name))
+	if err != nil {
+		t.Fatalf("failed to read contract %s: %v", name, err)
+	}
+	var c contract
+	if err := json.Unmarshal(data, &c); err != nil {
+		t.Fatalf("failed to decode contract %s: %v", name, err)
+	}
+	if c.Provider != "users-api" {
+		t.Fatalf("contract %s is for provider %q, not users-api", name, c.Provider)
+	}
+	return c
+}
+
+// assertShape verifica que got tenga todos los campos de want con el mismo tipo JSON
+// Los valores no se comparan: el contrato fija la forma, no los datos
+func assertShape(t *testing.T, want json.RawMessage, got interface{}) {
+	t.Helper()
+	data, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("failed to encode response: %v", err)
+	}
+	var wantValue, gotValue interface{}
+	if err := json.Unmarshal(want, &wantValue); err != nil {
+		t.Fatalf("failed to decode contract body: %v", err)
+	}
+	if err := json.Unmarshal(data, &gotValue); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	for _, problem := range compareShape("$", wantValue, gotValue) {
+		t.Error(problem)
+	}
+}
+
+// compareShape compara recursivamente la forma de dos valores JSON decodificados
+// Un null en el contrato acepta cualquier valor; en los arrays se compara cada elemento contra el primero del contrato
+func compareShape(path string, want, got interface{}) []string {
+	if want == nil {
+		return nil
+	}
+	if jsonKind(want) != jsonKind(got) {
+		return []string{fmt.Sprintf("%s: expected %s, got %s", path, jsonKind(want), jsonKind(got))}
+	}
+
+	var problems []string
+	switch want := want.(type) {
+	case map[string]interface{}:
+		got := got.(map[string]interface{})
+		keys := make([]string, 0, len(want))
+		for key := range want {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value, ok := got[key]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s.%s: missing field", path, key))
+				continue
+			}
+			problems = append(problems, compareShape(path+"."+key, want[key], value)...)
+		}
+	case []interface{}:
+		got := got.([]interface{})
+		if len(want) > 0 && len(got) == 0 {
+			return []string{fmt.Sprintf("%s: empty array, elements cannot be verified", path)}
+		}
+		for i, value := range got {
+			if len(want) > 0 {
+				problems = append(problems, compareShape(fmt.Sprintf("%s[%d]", path, i), want[0], value)...)
+			}
+		}
+	}
+	return problems
+}
+
+// jsonKind retorna el tipo JSON de un valor decodificado con encoding/json
+func jsonKind(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// contractUserService responde GetUserByID con usuarios fijos; el resto de UserService no se usa
+type contractUserService struct {
+	services.UserService
+	users map[uint]dto.UserResponse
+}
+
+func (s contractUserService) GetUserByID(id uint) (dto.UserResponse, error) {
+	user, ok := s.users[id]
+	if !ok {
+		return dto.UserResponse{}, errors.New("user not found")
+	}
+	return user, nil
+}
+
+// contractTokenService acepta solo el token del contrato, como un JWT vigente de host01
+type contractTokenService struct {
+	token string
+}
+
+func (s contractTokenService) Verify(token string) (*utils.Claims, bool, error) {
+	if token != s.token {
+		return nil, false, nil
+	}
+	return &utils.Claims{UserID: 1001, Username: "host01", UserType: "normal"}, true, nil
+}
+
+// ============================================
+// TESTS
+// ============================================
+
+func TestContract_PropertiesAPI_ValidateUser(t *testing.T) {
+	c := loadContract(t, "properties-api/users-api/validate_user.json")
+
+	var request rpc.ValidateUserRequest
+	if err := json.Unmarshal(c.Request, &request); err != nil {
+		t.Fatalf("contract request does not decode into ValidateUserRequest: %v", err)
+	}
+	if request.UserID == 0 {
+		t.Fatalf("contract request has no userId: %s", c.Request)
+	}
+
+	service := contractUserService{users: map[uint]dto.UserResponse{
+		request.UserID: {ID: request.UserID, Username: "host01", UserType: "normal", Active: true},
+	}}
+	controller := NewUserGRPCController(service, nil, nil, nil, nil)
+
+	response, err := controller.ValidateUser(context.Background(), &request)
+	if err != nil {
+		t.Fatalf("ValidateUser failed: %v", err)
+	}
+	assertShape(t, c.Response, response)
+}
+
+func TestContract_VerifyToken(t *testing.T) {
+	// properties-api y search-api leen los mismos campos: cualquier diferencia deja a sus usuarios como anónimos
+	for _, name := range []string{"properties-api/users-api/verify_token.json", "search-api/users-api/verify_token.json"} {
+		t.Run(name, func(t *testing.T) {
+			c := loadContract(t, name)
+
+			var request rpc.VerifyTokenRequest
+			if err := json.Unmarshal(c.Request, &request); err != nil {
+				t.Fatalf("contract request does not decode into VerifyTokenRequest: %v", err)
+			}
+			if request.Token == "" {
+				t.Fatalf("contract request has no token: %s", c.Request)
+			}
+
+			controller := NewUserGRPCController(nil, nil, nil, nil, contractTokenService{token: request.Token})
+
+			response, err := controller.VerifyToken(context.Background(), &request)
+			if err != nil {
+				t.Fatalf("VerifyToken failed: %v", err)
+			}
+			assertShape(t, c.Response, response)
+		})
+	}
+}
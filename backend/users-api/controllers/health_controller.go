@@ -0,0 +1,32 @@
+package controllers
+
+import (
+	"net/http"
+
+	"users-api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthController struct {
+	service services.HealthService
+}
+
+func NewHealthController(service services.HealthService) *HealthController {
+	return &HealthController{service: service}
+}
+
+// Live maneja el liveness probe: responde 200 mientras el proceso esté vivo
+func (ctrl *HealthController) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, ctrl.service.Live())
+}
+
+// Ready maneja el readiness probe: responde 503 si alguna dependencia no responde
+func (ctrl *HealthController) Ready(c *gin.Context) {
+	response, ready := ctrl.service.Ready(c.Request.Context())
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, response)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
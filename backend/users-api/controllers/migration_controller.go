@@ -0,0 +1,28 @@
+package controllers
+
+import (
+	"net/http"
+
+	"users-api/dto"
+	"users-api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MigrationController struct {
+	service services.MigrationService
+}
+
+func NewMigrationController(service services.MigrationService) *MigrationController {
+	return &MigrationController{service: service}
+}
+
+// Status muestra la versión de la base y las migraciones pendientes (solo admin)
+func (ctrl *MigrationController) Status(c *gin.Context) {
+	status, err := ctrl.service.Status()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
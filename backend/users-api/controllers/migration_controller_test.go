@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"users-api/dto"
+
+	"github.com/gin-gonic/gin"
+)
+
+// stubMigrationService responde con status o con err
+type stubMigrationService struct {
+	status dto.MigrationStatusResponse
+	err    error
+}
+
+func (s *stubMigrationService) Status() (dto.MigrationStatusResponse, error) {
+	return s.status, s.err
+}
+
+func TestMigrationStatus_Endpoint(t *testing.T) {
+	tests := []struct {
+		name       string
+		service    *stubMigrationService
+		wantStatus int
+	}{
+		{
+			name: "pending migration",
+			service: &stubMigrationService{status: dto.MigrationStatusResponse{Version: 1, Latest: 2, Pending: 1, Migrations: []dto.MigrationDTO{
+				{Version: 1, Name: "initial_schema", Applied: true},
+				{Version: 2, Name: "users_verified_and_role_index"},
+			}}},
+			wantStatus: http.StatusOK,
+		},
+		{name: "mysql down", service: &stubMigrationService{err: errors.New("error conectando a MySQL para migrar")}, wantStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/admin/migrations", NewMigrationController(tt.service).Status)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/admin/migrations", nil))
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, recorder.Code, recorder.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var response dto.MigrationStatusResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+				t.Fatalf("invalid JSON body: %v", err)
+			}
+			if response.Pending != 1 || len(response.Migrations) != 2 || response.Migrations[1].Applied {
+				t.Fatalf("expected the second migration pending, got %+v", response)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"users-api/dto"
+	"users-api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PreferenceController struct {
+	service services.PreferenceService
+}
+
+func NewPreferenceController(service services.PreferenceService) *PreferenceController {
+	return &PreferenceController{service: service}
+}
+
+// GetPreferences obtiene las preferencias de búsqueda del usuario autenticado
+func (ctrl *PreferenceController) GetPreferences(c *gin.Context) {
+	userID, ok := authenticatedUserID(c)
+	if !ok {
+		return
+	}
+
+	preferences, err := ctrl.service.GetPreferences(userID)
+	if err != nil {
+		writePreferenceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+// UpdatePreferences actualiza la moneda preferida y el locale del usuario autenticado
+func (ctrl *PreferenceController) UpdatePreferences(c *gin.Context) {
+	userID, ok := authenticatedUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdatePreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	preferences, err := ctrl.service.UpdatePreferences(userID, req)
+	if err != nil {
+		writePreferenceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+// ListFavorites obtiene las propiedades favoritas del usuario autenticado
+func (ctrl *PreferenceController) ListFavorites(c *gin.Context) {
+	userID, ok := authenticatedUserID(c)
+	if !ok {
+		return
+	}
+
+	favorites, err := ctrl.service.ListFavorites(userID)
+	if err != nil {
+		writePreferenceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, favorites)
+}
+
+// AddFavorite marca una propiedad como favorita (idempotente)
+func (ctrl *PreferenceController) AddFavorite(c *gin.Context) {
+	userID, ok := authenticatedUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.service.AddFavorite(userID, c.Param("propertyId")); err != nil {
+		writePreferenceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{Message: "Propiedad agregada a favoritos"})
+}
+
+// RemoveFavorite desmarca una propiedad favorita (idempotente)
+func (ctrl *PreferenceController) RemoveFavorite(c *gin.Context) {
+	userID, ok := authenticatedUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.service.RemoveFavorite(userID, c.Param("propertyId")); err != nil {
+		writePreferenceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{Message: "Propiedad quitada de favoritos"})
+}
+
+// authenticatedUserID obtiene el usuario del JWT; si no hay responde 401
+func authenticatedUserID(c *gin.Context) (uint, bool) {
+	userID, exists := c.Get("user_id")
+	id, ok := userID.(uint)
+	if !exists || !ok {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{Error: "usuario no autenticado"})
+		return 0, false
+	}
+	return id, true
+}
+
+// writePreferenceError mapea los errores del servicio de preferencias a status HTTP
+func writePreferenceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrInvalidCurrency), errors.Is(err, services.ErrInvalidLocale), errors.Is(err, services.ErrInvalidPropertyID):
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrTooManyFavorites):
+		c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+}
@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"users-api/dto"
+	"users-api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PrivacyController struct {
+	service services.PrivacyService
+}
+
+func NewPrivacyController(service services.PrivacyService) *PrivacyController {
+	return &PrivacyController{service: service}
+}
+
+// Export devuelve todos los datos del usuario (el propio usuario o un admin)
+// Por defecto responde JSON; con ?format=zip devuelve un ZIP con un archivo por sección
+func (ctrl *PrivacyController) Export(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "ID inválido"})
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "zip" {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "format debe ser 'json' o 'zip'"})
+		return
+	}
+
+	actor, actorType := actorFromContext(c)
+	export, err := ctrl.service.ExportUserData(actor, actorType, uint(id), c.GetHeader("Authorization"))
+	if err != nil {
+		writePrivacyError(c, err)
+		return
+	}
+
+	if format == "json" {
+		c.JSON(http.StatusOK, export)
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"user-%d-export.zip\"", id))
+	c.Status(http.StatusOK)
+	if err := writeExportZip(c.Writer, export); err != nil {
+		// El status ya fue enviado, solo se puede loguear
+		log.Printf("❌ Error escribiendo export ZIP del usuario %d: %v", id, err)
+	}
+}
+
+// Erase anonimiza y elimina la cuenta del usuario (el propio usuario o un admin)
+func (ctrl *PrivacyController) Erase(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "ID inválido"})
+		return
+	}
+
+	actor, actorType := actorFromContext(c)
+	if err := ctrl.service.EraseUser(actor, actorType, uint(id)); err != nil {
+		writePrivacyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{Message: "Datos personales borrados exitosamente"})
+}
+
+// actorFromContext obtiene el usuario autenticado que hace el request
+func actorFromContext(c *gin.Context) (uint, string) {
+	actorID, _ := c.Get("user_id")
+	actor, _ := actorID.(uint)
+	return actor, c.GetString("user_type")
+}
+
+// writePrivacyError mapea los errores del servicio de privacidad a status HTTP
+func writePrivacyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrForbidden):
+		c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrLastAdmin):
+		c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrExportUnavailable):
+		c.JSON(http.StatusBadGateway, dto.ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+}
+
+// writeExportZip escribe el export como un ZIP con un JSON por sección
+func writeExportZip(w http.ResponseWriter, export dto.UserDataExport) error {
+	archive := zip.NewWriter(w)
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", gin.H{"profile": export.Profile, "createdAt": export.CreatedAt, "exportedAt": export.ExportedAt}},
+		{"logins.json", export.Logins},
+		{"favorites.json", export.Favorites},
+		{"properties.json", export.Properties},
+		{"bookings.json", export.Bookings},
+	}
+
+	for _, file := range files {
+		entry, err := archive.Create(file.name)
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(entry)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"users-api/dto"
+	"users-api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProfileController struct {
+	service        services.ProfileService
+	maxUploadBytes int
+}
+
+// NewProfileController crea el controller de perfiles
+// maxUploadBytes es el tamaño máximo de la foto de perfil subida
+func NewProfileController(service services.ProfileService, maxUploadBytes int) *ProfileController {
+	return &ProfileController{service: service, maxUploadBytes: maxUploadBytes}
+}
+
+// GetMe obtiene el perfil completo del usuario autenticado
+func (ctrl *ProfileController) GetMe(c *gin.Context) {
+	userID, ok := authenticatedUserID(c)
+	if !ok {
+		return
+	}
+
+	profile, err := ctrl.service.GetProfile(userID)
+	if err != nil {
+		writeProfileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// UpdateMe edita el perfil del usuario autenticado (solo los campos enviados)
+func (ctrl *ProfileController) UpdateMe(c *gin.Context) {
+	userID, ok := authenticatedUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	profile, err := ctrl.service.UpdateProfile(userID, req)
+	if err != nil {
+		writeProfileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// UploadAvatar sube la foto de perfil del usuario autenticado
+// Body multipart: "file" con la imagen (JPEG, PNG o GIF)
+func (ctrl *ProfileController) UploadAvatar(c *gin.Context) {
+	userID, ok := authenticatedUserID(c)
+	if !ok {
+		return
+	}
+
+	// Margen para los encabezados del multipart
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, int64(ctrl.maxUploadBytes)+64<<10)
+	file, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, dto.ErrorResponse{Error: services.ErrAvatarTooLarge.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Falta el archivo 'file' con la foto de perfil"})
+		return
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	defer src.Close()
+	data, err := io.ReadAll(io.LimitReader(src, int64(ctrl.maxUploadBytes)+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	profile, err := ctrl.service.UploadAvatar(userID, data)
+	if err != nil {
+		writeProfileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// GetHostProfile obtiene el perfil público de un anfitrión (no requiere autenticación)
+func (ctrl *ProfileController) GetHostProfile(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "ID inválido"})
+		return
+	}
+
+	profile, err := ctrl.service.GetHostProfile(uint(id))
+	if err != nil {
+		writeProfileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// writeProfileError mapea los errores del servicio de perfiles a status HTTP
+func writeProfileError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrInvalidPhone), errors.Is(err, services.ErrInvalidAvatarURL),
+		errors.Is(err, services.ErrBioTooLong), errors.Is(err, services.ErrInvalidLanguages),
+		errors.Is(err, services.ErrInvalidLocale), errors.Is(err, services.ErrInvalidEmergencyContact),
+		errors.Is(err, services.ErrInvalidAvatarImage):
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrAvatarTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, dto.ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+	}
+}
@@ -0,0 +1,38 @@
+package controllers
+
+import (
+	"net/http"
+
+	"users-api/dto"
+	"users-api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SetupController struct {
+	service services.AdminBootstrapService
+}
+
+func NewSetupController(service services.AdminBootstrapService) *SetupController {
+	return &SetupController{service: service}
+}
+
+// SetupAdmin crea el primer administrador usando el token de setup (header X-Setup-Token)
+func (ctrl *SetupController) SetupAdmin(c *gin.Context) {
+	var req dto.CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	user, err := ctrl.service.SetupAdmin(c.GetHeader("X-Setup-Token"), req)
+	if respondPasswordPolicyError(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, user)
+}
@@ -1,21 +1,24 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"users-api/dto"
+	"users-api/middleware"
 	"users-api/services"
 
 	"github.com/gin-gonic/gin"
 )
 
 type UserController struct {
-	service services.UserService
+	service      services.UserService
+	loginHistory services.LoginHistoryService
 }
 
-func NewUserController(service services.UserService) *UserController {
-	return &UserController{service: service}
+func NewUserController(service services.UserService, loginHistory services.LoginHistoryService) *UserController {
+	return &UserController{service: service, loginHistory: loginHistory}
 }
 
 // CreateUser maneja la creación de un nuevo usuario
@@ -27,6 +30,9 @@ func (ctrl *UserController) CreateUser(c *gin.Context) {
 	}
 
 	user, err := ctrl.service.CreateUser(req)
+	if respondPasswordPolicyError(c, err) {
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
 		return
@@ -43,8 +49,17 @@ func (ctrl *UserController) Login(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
+	req.Client = dto.LoginClientInfo{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Country:   c.GetString(middleware.ClientCountryKey),
+	}
 
 	response, err := ctrl.service.Login(req)
+	if errors.Is(err, services.ErrUserInactive) {
+		c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{Error: err.Error()})
 		return
@@ -53,6 +68,24 @@ func (ctrl *UserController) Login(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// GetMyLogins obtiene el historial de logins del usuario autenticado
+func (ctrl *UserController) GetMyLogins(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	id, ok := userID.(uint)
+	if !exists || !ok {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{Error: "usuario no autenticado"})
+		return
+	}
+
+	logins, err := ctrl.loginHistory.GetUserLogins(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, logins)
+}
+
 // GetUserByID obtiene un usuario por ID
 func (ctrl *UserController) GetUserByID(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -84,7 +117,13 @@ func (ctrl *UserController) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	err = ctrl.service.UpdateUser(uint(id), req)
+	actorID, _ := c.Get("user_id")
+	actor, _ := actorID.(uint)
+
+	err = ctrl.service.UpdateUser(actor, uint(id), req)
+	if respondPasswordPolicyError(c, err) {
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
 		return
@@ -101,7 +140,10 @@ func (ctrl *UserController) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	err = ctrl.service.DeleteUser(uint(id))
+	actorID, _ := c.Get("user_id")
+	actor, _ := actorID.(uint)
+
+	err = ctrl.service.DeleteUser(actor, uint(id))
 	if err != nil {
 		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
 		return
@@ -110,9 +152,16 @@ func (ctrl *UserController) DeleteUser(c *gin.Context) {
 	c.JSON(http.StatusOK, dto.SuccessResponse{Message: "Usuario eliminado exitosamente"})
 }
 
-// GetAllUsers obtiene todos los usuarios (solo admin)
-func (ctrl *UserController) GetAllUsers(c *gin.Context) {
-	users, err := ctrl.service.GetAllUsers()
+// ListUsers obtiene los usuarios paginados y filtrados (solo admin)
+// Query params opcionales: page, limit (máx 100), userType (normal|admin) y search
+func (ctrl *UserController) ListUsers(c *gin.Context) {
+	var query dto.ListUsersQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	users, err := ctrl.service.ListUsers(query)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
 		return
@@ -120,9 +169,17 @@ func (ctrl *UserController) GetAllUsers(c *gin.Context) {
 
 	c.JSON(http.StatusOK, users)
 }
-func (ctrl *UserController) HealthCheck(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"status":  "OK",
-		"service": "users-api",
+
+// respondPasswordPolicyError responde 400 con las reglas que no cumple la contraseña, una por campo
+// Retorna false si err no es de la política de contraseñas (el caller responde como siempre)
+func respondPasswordPolicyError(c *gin.Context, err error) bool {
+	var policyErr *services.PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		return false
+	}
+	c.JSON(http.StatusBadRequest, dto.ValidationErrorResponse{
+		Error:  services.ErrWeakPassword.Error(),
+		Fields: policyErr.Violations,
 	})
+	return true
 }
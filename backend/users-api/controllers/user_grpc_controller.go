@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+
+	"users-api/dto"
+	"users-api/rpc"
+	"users-api/services"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UserGRPCController atiende las llamadas gRPC de los servicios internos (ej: properties-api)
+// Expone las mismas operaciones de lectura que GET /users/:id sin pasar por HTTP/JSON
+type UserGRPCController struct {
+	service     services.UserService
+	preferences services.PreferenceService
+	profiles    services.ProfileService
+	apiKeys     services.APIKeyService
+	tokens      services.TokenService
+}
+
+func NewUserGRPCController(service services.UserService, preferences services.PreferenceService, profiles services.ProfileService, apiKeys services.APIKeyService, tokens services.TokenService) *UserGRPCController {
+	return &UserGRPCController{service: service, preferences: preferences, profiles: profiles, apiKeys: apiKeys, tokens: tokens}
+}
+
+// ValidateUser indica si el usuario existe y si su cuenta está activa
+func (ctrl *UserGRPCController) ValidateUser(ctx context.Context, request *rpc.ValidateUserRequest) (*rpc.ValidateUserResponse, error) {
+	if request.UserID == 0 {
+		return nil, status.Error(codes.InvalidArgument, "ID inválido")
+	}
+
+	// Igual que en GET /users/:id, cualquier error del servicio se trata como usuario inexistente
+	user, err := ctrl.service.GetUserByID(request.UserID)
+	if err != nil {
+		return &rpc.ValidateUserResponse{Exists: false}, nil
+	}
+
+	return &rpc.ValidateUserResponse{Exists: true, Active: user.Active}, nil
+}
+
+// GetUser obtiene un usuario por ID
+func (ctrl *UserGRPCController) GetUser(ctx context.Context, request *rpc.GetUserRequest) (*rpc.GetUserResponse, error) {
+	if request.UserID == 0 {
+		return nil, status.Error(codes.InvalidArgument, "ID inválido")
+	}
+
+	user, err := ctrl.service.GetUserByID(request.UserID)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
+	return &rpc.GetUserResponse{User: user}, nil
+}
+
+// maxBatchUsers limita el tamaño de un lote de GetUsers
+const maxBatchUsers = 100
+
+// GetUsers obtiene un lote de usuarios; los IDs inexistentes se omiten de la respuesta
+func (ctrl *UserGRPCController) GetUsers(ctx context.Context, request *rpc.GetUsersRequest) (*rpc.GetUsersResponse, error) {
+	if len(request.UserIDs) > maxBatchUsers {
+		return nil, status.Errorf(codes.InvalidArgument, "no se pueden pedir más de %d usuarios por llamada", maxBatchUsers)
+	}
+
+	response := &rpc.GetUsersResponse{Users: []dto.UserResponse{}}
+	for _, id := range request.UserIDs {
+		if err := ctx.Err(); err != nil {
+			return nil, status.FromContextError(err).Err()
+		}
+		user, err := ctrl.service.GetUserByID(id)
+		if err != nil {
+			continue
+		}
+		response.Users = append(response.Users, user)
+	}
+	return response, nil
+}
+
+// GetSearchProfile obtiene los favoritos y las preferencias del usuario (personalización de search-api)
+func (ctrl *UserGRPCController) GetSearchProfile(ctx context.Context, request *rpc.GetSearchProfileRequest) (*rpc.GetSearchProfileResponse, error) {
+	if request.UserID == 0 {
+		return nil, status.Error(codes.InvalidArgument, "ID inválido")
+	}
+
+	preferences, favorites, err := ctrl.preferences.GetSearchProfile(request.UserID)
+	if errors.Is(err, services.ErrUserNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &rpc.GetSearchProfileResponse{
+		Favorites:         favorites,
+		PreferredCurrency: preferences.PreferredCurrency,
+		Locale:            preferences.Locale,
+	}, nil
+}
+
+// GetHostProfile obtiene el perfil público de un anfitrión (properties-api lo muestra en cada propiedad)
+func (ctrl *UserGRPCController) GetHostProfile(ctx context.Context, request *rpc.GetHostProfileRequest) (*rpc.GetHostProfileResponse, error) {
+	if request.UserID == 0 {
+		return nil, status.Error(codes.InvalidArgument, "ID inválido")
+	}
+
+	profile, err := ctrl.profiles.GetHostProfile(request.UserID)
+	if errors.Is(err, services.ErrUserNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &rpc.GetHostProfileResponse{Profile: profile}, nil
+}
+
+// VerifyAPIKey valida la API key con la que otro servicio recibió una llamada interna
+// No requiere API key: es la llamada con la que los demás servicios validan las suyas
+func (ctrl *UserGRPCController) VerifyAPIKey(ctx context.Context, request *rpc.VerifyAPIKeyRequest) (*rpc.VerifyAPIKeyResponse, error) {
+	identity, ok, err := ctrl.apiKeys.Verify(request.Key)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	if !ok {
+		return &rpc.VerifyAPIKeyResponse{Valid: false}, nil
+	}
+
+	return &rpc.VerifyAPIKeyResponse{Valid: true, Name: identity.Name, Scopes: identity.Scopes}, nil
+}
+
+// VerifyToken valida el JWT con el que un usuario llamó a otro servicio, incluida la lista de revocación
+// Así un logout en todos lados o un admin degradado también se aplican en properties-api y search-api
+func (ctrl *UserGRPCController) VerifyToken(ctx context.Context, request *rpc.VerifyTokenRequest) (*rpc.VerifyTokenResponse, error) {
+	claims, ok, err := ctrl.tokens.Verify(request.Token)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	if !ok {
+		return &rpc.VerifyTokenResponse{Valid: false}, nil
+	}
+
+	return &rpc.VerifyTokenResponse{Valid: true, UserID: claims.UserID, Username: claims.Username, UserType: claims.UserType}, nil
+}
+
+// CountSignups cuenta los usuarios registrados por día (métricas del panel de admin de properties-api)
+func (ctrl *UserGRPCController) CountSignups(ctx context.Context, request *rpc.CountSignupsRequest) (*rpc.CountSignupsResponse, error) {
+	days, err := ctrl.service.CountSignupsByDay(request.From, request.To)
+	if errors.Is(err, services.ErrInvalidDateRange) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &rpc.CountSignupsResponse{Days: days}, nil
+}
@@ -0,0 +1,220 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"users-api/dto"
+	"users-api/rpc"
+	"users-api/services"
+	"users-api/utils"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// grpcUserService responde GetUserByID con usuarios fijos; el resto de UserService no se usa
+type grpcUserService struct {
+	services.UserService
+	users map[uint]dto.UserResponse
+}
+
+func (s grpcUserService) GetUserByID(id uint) (dto.UserResponse, error) {
+	user, ok := s.users[id]
+	if !ok {
+		return dto.UserResponse{}, errors.New("user not found")
+	}
+	return user, nil
+}
+
+// grpcProfileService responde GetHostProfile con el error configurado
+type grpcProfileService struct {
+	services.ProfileService
+	err error
+}
+
+func (s grpcProfileService) GetHostProfile(id uint) (dto.HostProfileResponse, error) {
+	return dto.HostProfileResponse{}, s.err
+}
+
+// grpcTokenService valida solo el token "vigente"; con err simula la lista de revocación caída
+type grpcTokenService struct {
+	err error
+}
+
+func (s grpcTokenService) Verify(token string) (*utils.Claims, bool, error) {
+	if s.err != nil {
+		return nil, false, s.err
+	}
+	if token != "vigente" {
+		return nil, false, nil
+	}
+	return &utils.Claims{UserID: 7, Username: "maria", UserType: "admin"}, true, nil
+}
+
+// startUsersServer levanta el servidor gRPC de usuarios en memoria y retorna una conexión con el codec JSON
+func startUsersServer(t *testing.T, controller *UserGRPCController) *grpc.ClientConn {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	rpc.RegisterUsersServer(server, controller)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.CallContentSubtype(rpc.CodecName)),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func usersMethod(name string) string {
+	return "/" + rpc.UsersServiceName + "/" + name
+}
+
+func TestUsersGRPC_ValidateUser(t *testing.T) {
+	service := grpcUserService{users: map[uint]dto.UserResponse{
+		7: {ID: 7, Username: "host07", Active: true},
+		8: {ID: 8, Username: "host08", Active: false},
+	}}
+	conn := startUsersServer(t, NewUserGRPCController(service, nil, nil, nil, nil))
+
+	tests := []struct {
+		name     string
+		userID   uint
+		want     rpc.ValidateUserResponse
+		wantCode codes.Code
+	}{
+		{name: "active user", userID: 7, want: rpc.ValidateUserResponse{Exists: true, Active: true}},
+		{name: "inactive user", userID: 8, want: rpc.ValidateUserResponse{Exists: true, Active: false}},
+		{name: "missing user", userID: 9, want: rpc.ValidateUserResponse{Exists: false}},
+		{name: "zero id", userID: 0, wantCode: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var response rpc.ValidateUserResponse
+			err := conn.Invoke(context.Background(), usersMethod("ValidateUser"), &rpc.ValidateUserRequest{UserID: tt.userID}, &response)
+
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("expected code %v, got %v (%v)", tt.wantCode, got, err)
+			}
+			if err == nil && response != tt.want {
+				t.Fatalf("expected %+v, got %+v", tt.want, response)
+			}
+		})
+	}
+}
+
+func TestUsersGRPC_GetUserReturnsNotFound(t *testing.T) {
+	service := grpcUserService{users: map[uint]dto.UserResponse{7: {ID: 7, Username: "host07"}}}
+	conn := startUsersServer(t, NewUserGRPCController(service, nil, nil, nil, nil))
+
+	var response rpc.GetUserResponse
+	if err := conn.Invoke(context.Background(), usersMethod("GetUser"), &rpc.GetUserRequest{UserID: 7}, &response); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response.User.Username != "host07" {
+		t.Fatalf("expected host07, got %+v", response.User)
+	}
+
+	err := conn.Invoke(context.Background(), usersMethod("GetUser"), &rpc.GetUserRequest{UserID: 9}, &response)
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound, got %v", err)
+	}
+}
+
+func TestUsersGRPC_GetUsersSkipsMissingIDs(t *testing.T) {
+	service := grpcUserService{users: map[uint]dto.UserResponse{
+		1: {ID: 1, Username: "a"},
+		3: {ID: 3, Username: "c"},
+	}}
+	conn := startUsersServer(t, NewUserGRPCController(service, nil, nil, nil, nil))
+
+	var response rpc.GetUsersResponse
+	if err := conn.Invoke(context.Background(), usersMethod("GetUsers"), &rpc.GetUsersRequest{UserIDs: []uint{1, 2, 3}}, &response); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(response.Users) != 2 || response.Users[0].ID != 1 || response.Users[1].ID != 3 {
+		t.Fatalf("expected users 1 and 3 in order, got %+v", response.Users)
+	}
+
+	// Un lote vacío responde una lista vacía, no null
+	response = rpc.GetUsersResponse{}
+	if err := conn.Invoke(context.Background(), usersMethod("GetUsers"), &rpc.GetUsersRequest{}, &response); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response.Users == nil {
+		t.Fatal("expected an empty list, got nil")
+	}
+
+	tooMany := make([]uint, maxBatchUsers+1)
+	err := conn.Invoke(context.Background(), usersMethod("GetUsers"), &rpc.GetUsersRequest{UserIDs: tooMany}, &response)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument for %d ids, got %v", len(tooMany), err)
+	}
+}
+
+func TestUsersGRPC_GetHostProfileMapsErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+	}{
+		{name: "found", err: nil, wantCode: codes.OK},
+		{name: "user not found", err: services.ErrUserNotFound, wantCode: codes.NotFound},
+		{name: "database down", err: errors.New("connection refused"), wantCode: codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := startUsersServer(t, NewUserGRPCController(grpcUserService{}, nil, grpcProfileService{err: tt.err}, nil, nil))
+
+			var response rpc.GetHostProfileResponse
+			err := conn.Invoke(context.Background(), usersMethod("GetHostProfile"), &rpc.GetHostProfileRequest{UserID: 7}, &response)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("expected code %v, got %v (%v)", tt.wantCode, got, err)
+			}
+		})
+	}
+}
+
+func TestUsersGRPC_VerifyToken(t *testing.T) {
+	tests := []struct {
+		name      string
+		token     string
+		tokens    grpcTokenService
+		wantCode  codes.Code
+		wantValid bool
+	}{
+		{name: "valid", token: "vigente", wantValid: true},
+		// Revocado, vencido o con otra firma: no es un error, el servicio que llama lo trata como anónimo
+		{name: "revoked", token: "revocado"},
+		{name: "revocation list down", token: "vigente", tokens: grpcTokenService{err: errors.New("connection refused")}, wantCode: codes.Unavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := startUsersServer(t, NewUserGRPCController(grpcUserService{}, nil, nil, nil, tt.tokens))
+
+			var response rpc.VerifyTokenResponse
+			err := conn.Invoke(context.Background(), usersMethod("VerifyToken"), &rpc.VerifyTokenRequest{Token: tt.token}, &response)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("expected code %v, got %v (%v)", tt.wantCode, got, err)
+			}
+			if response.Valid != tt.wantValid {
+				t.Fatalf("expected valid=%v, got %+v", tt.wantValid, response)
+			}
+			if tt.wantValid && (response.UserID != 7 || response.UserType != "admin") {
+				t.Fatalf("expected the claims of user 7, got %+v", response)
+			}
+		})
+	}
+}
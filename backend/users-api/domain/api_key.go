@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Scopes de las API keys: qué servidor gRPC interno puede llamar el servicio dueño de la key
+const (
+	APIKeyScopeUsersRead      = "users:read"      // Lectura de usuarios y perfiles (users.v1.Users)
+	APIKeyScopePropertiesRead = "properties:read" // Lectura de propiedades y disponibilidad (properties.v1.Properties)
+)
+
+// APIKeyScopes son los scopes que se pueden asignar al emitir una key
+var APIKeyScopes = []string{APIKeyScopeUsersRead, APIKeyScopePropertiesRead}
+
+// APIKey es una credencial de máquina para las llamadas entre servicios internos (ej: search-api → properties-api)
+// Solo se guarda el hash SHA-256 de la key: el valor completo se muestra una única vez al emitirla
+type APIKey struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	Name       string     `gorm:"size:100;not null" json:"name"`              // Servicio dueño de la key (ej: search-api)
+	Prefix     string     `gorm:"size:16;uniqueIndex;not null" json:"prefix"` // Parte visible de la key, identifica cuál se usó
+	KeyHash    string     `gorm:"size:64;not null" json:"-"`                  // SHA-256 en hexadecimal de la key completa
+	Scopes     string     `gorm:"size:255;not null" json:"scopes"`            // Separados por coma
+	CreatedBy  uint       `gorm:"not null" json:"created_by"`                 // Admin que la emitió
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`                       // nil = no vence
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`                     // Se actualiza como mucho una vez por minuto
+	RevokedAt  *time.Time `gorm:"index" json:"revoked_at,omitempty"`          // nil = vigente
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// TableName especifica el nombre de la tabla en MySQL
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// ScopeList retorna los scopes de la key como lista
+func (k APIKey) ScopeList() []string {
+	if k.Scopes == "" {
+		return []string{}
+	}
+	return strings.Split(k.Scopes, ",")
+}
+
+// IsUsable indica si la key no está revocada ni vencida en el momento now
+func (k APIKey) IsUsable(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
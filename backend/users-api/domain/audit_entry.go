@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// AuditEntry es un registro del log de auditoría de operaciones que modifican datos
+// El log es append-only: los registros nunca se actualizan ni se eliminan
+type AuditEntry struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ActorID    uint      `gorm:"index:idx_audit_actor_created,priority:1;not null" json:"actor_id"` // 0 = sistema
+	Action     string    `gorm:"size:50;not null" json:"action"`
+	EntityType string    `gorm:"size:50;not null" json:"entity_type"`
+	EntityID   string    `gorm:"size:64;index" json:"entity_id"`
+	Changes    string    `gorm:"type:text" json:"changes"` // JSON con el valor anterior y el nuevo de cada campo modificado
+	CreatedAt  time.Time `gorm:"index:idx_audit_actor_created,priority:2;index" json:"created_at"`
+}
+
+// TableName especifica el nombre de la tabla en MySQL
+func (AuditEntry) TableName() string {
+	return "audit_log"
+}
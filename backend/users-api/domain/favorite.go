@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// Favorite es una propiedad marcada como favorita por un usuario
+// PropertyID es el ObjectID de la propiedad en properties-api (no hay FK entre servicios)
+type Favorite struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	UserID     uint      `gorm:"not null;uniqueIndex:idx_favorites_user_property" json:"user_id"`
+	PropertyID string    `gorm:"size:64;not null;uniqueIndex:idx_favorites_user_property" json:"property_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName especifica el nombre de la tabla en MySQL
+func (Favorite) TableName() string {
+	return "favorites"
+}
@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// LoginEvent registra un intento de login de un usuario
+type LoginEvent struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	UserID     uint      `gorm:"index;not null" json:"user_id"`
+	IP         string    `gorm:"size:64" json:"ip"`
+	UserAgent  string    `gorm:"size:512" json:"user_agent"`
+	DeviceHash string    `gorm:"size:64;index" json:"-"` // Hash del user agent para detectar dispositivos nuevos
+	Country    string    `gorm:"size:8" json:"country"`  // País estimado (vacío si no se pudo estimar)
+	Success    bool      `json:"success"`
+	Suspicious bool      `json:"suspicious"`
+	Reasons    string    `json:"reasons"` // Motivos separados por coma (ej: "new_device,new_country")
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+}
+
+// TableName especifica el nombre de la tabla en MySQL
+func (LoginEvent) TableName() string {
+	return "login_events"
+}
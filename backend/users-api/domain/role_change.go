@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// RoleChange es el registro de auditoría de un cambio de rol de usuario
+type RoleChange struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"index;not null" json:"user_id"`
+	ChangedBy uint      `gorm:"not null" json:"changed_by"`
+	OldRole   string    `gorm:"not null" json:"old_role"`
+	NewRole   string    `gorm:"not null" json:"new_role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName especifica el nombre de la tabla en MySQL
+func (RoleChange) TableName() string {
+	return "role_changes"
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// TokenRevocation marca como inválidos todos los tokens de un usuario emitidos antes de RevokedAt
+// Se usa cuando cambian los permisos del usuario y sus tokens ya no reflejan su rol
+type TokenRevocation struct {
+	UserID    uint      `gorm:"primaryKey" json:"user_id"`
+	RevokedAt time.Time `gorm:"not null" json:"revoked_at"`
+	Reason    string    `json:"reason"`
+}
+
+// TableName especifica el nombre de la tabla en MySQL
+func (TokenRevocation) TableName() string {
+	return "token_revocations"
+}
@@ -1,6 +1,10 @@
 package domain
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // UserType define los tipos de usuario que existen
 type UserType string
@@ -21,6 +25,38 @@ type User struct {
 	UserType  string    `gorm:"default:'normal'"` // ← Cambiar a string
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// Active en false indica una cuenta desactivada (no puede hacer login)
+	Active bool `gorm:"not null;default:true" json:"active"`
+
+	// Verified indica que el usuario confirmó su email (migración 000002)
+	Verified bool `gorm:"not null;default:false" json:"verified"`
+
+	// PreferredCurrency (ISO 4217, ej: "ARS") y Locale (ej: "es-AR") personalizan las búsquedas
+	// Vacíos = sin preferencia (search-api usa la moneda de cada propiedad)
+	PreferredCurrency string `gorm:"size:3" json:"preferred_currency"`
+	Locale            string `gorm:"size:16" json:"locale"`
+
+	// Perfil público: foto, presentación, idiomas (ISO 639-1 separados por coma) y ciudad
+	// Es lo que ven los huéspedes del anfitrión de una propiedad (GET /users/:id/host-profile)
+	AvatarURL string `gorm:"size:512" json:"avatar_url"`
+	Bio       string `gorm:"size:1000" json:"bio"`
+	Languages string `gorm:"size:64" json:"languages"`
+	City      string `gorm:"size:100" json:"city"`
+
+	// AvatarKey es la clave en AvatarStorage de la foto subida con POST /users/me/avatar
+	// Vacía si no hay foto subida (avatarUrl puede apuntar a una URL externa)
+	AvatarKey string `gorm:"size:255" json:"-"`
+
+	// Datos de contacto privados: el teléfono (E.164) y el contacto de emergencia del huésped
+	// Solo los ve el propio usuario (GET /users/me)
+	Phone                 string `gorm:"size:20" json:"phone"`
+	EmergencyContactName  string `gorm:"size:100" json:"emergency_contact_name"`
+	EmergencyContactPhone string `gorm:"size:20" json:"emergency_contact_phone"`
+
+	// DeletedAt habilita el soft delete de GORM: Delete completa la fecha
+	// y las consultas ignoran automáticamente a los usuarios eliminados
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 }
 
 // TableName especifica el nombre de la tabla en MySQL
@@ -0,0 +1,46 @@
+package dto
+
+import "time"
+
+// CreateAPIKeyRequest DTO para emitir una API key de un servicio interno (solo admin)
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" binding:"required,max=100"`                  // Servicio dueño de la key (ej: search-api)
+	Scopes        []string `json:"scopes" binding:"required,min=1"`                  // Ej: ["properties:read"]
+	ExpiresInDays int      `json:"expiresInDays" binding:"omitempty,min=1,max=3650"` // 0 = no vence
+}
+
+// APIKeyResponse DTO de una API key (nunca incluye la key completa)
+type APIKeyResponse struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  uint       `json:"createdBy"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+// CreatedAPIKeyResponse DTO de la key recién emitida: es la única respuesta que trae el valor completo
+type CreatedAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+// APIKeyIdentity es el servicio autenticado por una API key válida
+type APIKeyIdentity struct {
+	KeyID  uint     `json:"keyId"`
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// HasScope indica si la key tiene el scope indicado
+func (i APIKeyIdentity) HasScope(scope string) bool {
+	for _, s := range i.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
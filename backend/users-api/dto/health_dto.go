@@ -0,0 +1,24 @@
+package dto
+
+// Estados reportados por los health checks
+// "degraded" indica que falla una dependencia opcional pero el servicio sigue recibiendo tráfico
+const (
+	HealthStatusUp       = "up"
+	HealthStatusDown     = "down"
+	HealthStatusDegraded = "degraded"
+)
+
+// DependencyHealth es el resultado del chequeo de una dependencia
+type DependencyHealth struct {
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latencyMs"`
+	Error     string `json:"error,omitempty"`
+}
+
+// HealthResponse es la respuesta de /health/live y /health/ready
+// Dependencies solo se incluye en readiness
+type HealthResponse struct {
+	Status       string                      `json:"status"`
+	Service      string                      `json:"service"`
+	Dependencies map[string]DependencyHealth `json:"dependencies,omitempty"`
+}
@@ -0,0 +1,18 @@
+package dto
+
+// MigrationDTO es una migración versionada de la base y si ya está aplicada
+type MigrationDTO struct {
+	Version uint   `json:"version"`
+	Name    string `json:"name"`
+	Applied bool   `json:"applied"`
+}
+
+// MigrationStatusResponse es la respuesta de GET /admin/migrations
+// Dirty indica que una migración falló a mitad de camino y hay que revisarla a mano
+type MigrationStatusResponse struct {
+	Version    uint           `json:"version"`
+	Latest     uint           `json:"latest"`
+	Pending    int            `json:"pending"`
+	Dirty      bool           `json:"dirty"`
+	Migrations []MigrationDTO `json:"migrations"`
+}
@@ -1,10 +1,15 @@
 package dto
 
+import (
+	"encoding/json"
+	"time"
+)
+
 // CreateUserRequest DTO para crear un nuevo usuario
 type CreateUserRequest struct {
 	Username  string `json:"username" binding:"required,min=3,max=50"`
 	Email     string `json:"email" binding:"required,email"`
-	Password  string `json:"password" binding:"required,min=6"`
+	Password  string `json:"password" binding:"required"` // Las reglas las aplica la política de contraseñas
 	FirstName string `json:"firstName" binding:"required"`
 	LastName  string `json:"lastName" binding:"required"`
 }
@@ -13,6 +18,16 @@ type CreateUserRequest struct {
 type LoginRequest struct {
 	UsernameOrEmail string `json:"usernameOrEmail" binding:"required"`
 	Password        string `json:"password" binding:"required"`
+
+	// Client lo completa el controller con los datos de la conexión (no viene en el body)
+	Client LoginClientInfo `json:"-"`
+}
+
+// LoginClientInfo datos del cliente que intenta hacer login
+type LoginClientInfo struct {
+	IP        string
+	UserAgent string
+	Country   string // País estimado por el gateway (vacío si se desconoce)
 }
 
 // UpdateUserRequest DTO para actualizar usuario
@@ -23,6 +38,12 @@ type UpdateUserRequest struct {
 	Password  *string `json:"password"`
 }
 
+// ChangePasswordRequest DTO para que el usuario cambie su propia contraseña
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	NewPassword     string `json:"newPassword" binding:"required"` // Las reglas las aplica la política de contraseñas
+}
+
 // UserResponse DTO de respuesta
 type UserResponse struct {
 	ID        uint   `json:"id"`
@@ -31,6 +52,26 @@ type UserResponse struct {
 	FirstName string `json:"firstName"`
 	LastName  string `json:"lastName"`
 	UserType  string `json:"userType"`
+	Active    bool   `json:"active"`
+	Verified  bool   `json:"verified"`
+	AvatarURL string `json:"avatarUrl,omitempty"`
+}
+
+// ListUsersQuery DTO con los query params del listado de usuarios (solo admin)
+type ListUsersQuery struct {
+	Page     int    `form:"page" binding:"omitempty,min=1"`
+	Limit    int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	UserType string `form:"userType" binding:"omitempty,oneof=normal admin"`
+	Search   string `form:"search" binding:"omitempty,max=100"`
+}
+
+// UserListResponse DTO de respuesta del listado paginado de usuarios
+type UserListResponse struct {
+	Users      []UserResponse `json:"users"`
+	Page       int            `json:"page"`
+	Limit      int            `json:"limit"`
+	Total      int64          `json:"total"`
+	TotalPages int            `json:"totalPages"`
 }
 
 // LoginResponse DTO de respuesta del login
@@ -44,7 +85,154 @@ type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
+// FieldError es un error de validación de un campo del request
+type FieldError struct {
+	Field   string `json:"field"`   // Campo del body (ej: "password")
+	Code    string `json:"code"`    // Regla que no se cumple (ej: "min_length"), para que el frontend la traduzca
+	Message string `json:"message"` // Descripción para mostrar al usuario
+}
+
+// ValidationErrorResponse DTO de respuesta de error con los errores de cada campo
+type ValidationErrorResponse struct {
+	Error  string       `json:"error"`
+	Fields []FieldError `json:"fields"`
+}
+
 // SuccessResponse DTO de respuesta exitosa
 type SuccessResponse struct {
 	Message string `json:"message"`
 }
+
+// ChangeRoleRequest DTO para cambiar el rol de un usuario
+type ChangeRoleRequest struct {
+	UserType string `json:"userType" binding:"required,oneof=normal admin"`
+}
+
+// LoginEventResponse DTO de un login del historial
+type LoginEventResponse struct {
+	ID         uint      `json:"id"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"userAgent"`
+	Country    string    `json:"country,omitempty"`
+	Success    bool      `json:"success"`
+	Suspicious bool      `json:"suspicious"`
+	Reasons    []string  `json:"reasons,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// UserDataExport DTO con todos los datos de un usuario (exportación GDPR)
+type UserDataExport struct {
+	Profile    UserResponse         `json:"profile"`
+	Details    ProfileResponse      `json:"profileDetails"`
+	CreatedAt  time.Time            `json:"createdAt"`
+	Logins     []LoginEventResponse `json:"logins"`
+	Favorites  []FavoriteResponse   `json:"favorites"`
+	Properties json.RawMessage      `json:"properties"`
+	Bookings   json.RawMessage      `json:"bookings"`
+	ExportedAt time.Time            `json:"exportedAt"`
+}
+
+// AuditQuery DTO con los query params de la consulta del log de auditoría (solo admin)
+// From y To aceptan una fecha (2006-01-02) o un timestamp RFC3339
+type AuditQuery struct {
+	UserID uint   `form:"userId"`
+	From   string `form:"from"`
+	To     string `form:"to"`
+	Page   int    `form:"page" binding:"omitempty,min=1"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// AuditChange DTO con el valor anterior y el nuevo de un campo modificado
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditEntryResponse DTO de un registro del log de auditoría
+type AuditEntryResponse struct {
+	ID         uint                   `json:"id"`
+	ActorID    uint                   `json:"actorId"`
+	Action     string                 `json:"action"`
+	EntityType string                 `json:"entityType"`
+	EntityID   string                 `json:"entityId"`
+	Changes    map[string]AuditChange `json:"changes"`
+	CreatedAt  time.Time              `json:"createdAt"`
+}
+
+// AuditListResponse DTO de respuesta de la consulta paginada del log de auditoría
+type AuditListResponse struct {
+	Entries    []AuditEntryResponse `json:"entries"`
+	Page       int                  `json:"page"`
+	Limit      int                  `json:"limit"`
+	Total      int64                `json:"total"`
+	TotalPages int                  `json:"totalPages"`
+}
+
+// PreferencesResponse DTO con las preferencias de búsqueda del usuario
+type PreferencesResponse struct {
+	PreferredCurrency string `json:"preferredCurrency"`
+	Locale            string `json:"locale"`
+}
+
+// UpdatePreferencesRequest DTO para actualizar las preferencias (string vacío = sin preferencia)
+// El formato (ISO 4217 y tag de idioma) lo valida el servicio
+type UpdatePreferencesRequest struct {
+	PreferredCurrency *string `json:"preferredCurrency"`
+	Locale            *string `json:"locale"`
+}
+
+// ProfileResponse DTO con el perfil completo del usuario autenticado (GET /users/me)
+type ProfileResponse struct {
+	ID               uint              `json:"id"`
+	Username         string            `json:"username"`
+	Email            string            `json:"email"`
+	FirstName        string            `json:"firstName"`
+	LastName         string            `json:"lastName"`
+	Phone            string            `json:"phone"`
+	AvatarURL        string            `json:"avatarUrl"`
+	Bio              string            `json:"bio"`
+	Locale           string            `json:"locale"`
+	Languages        []string          `json:"languages"`
+	City             string            `json:"city"`
+	EmergencyContact *EmergencyContact `json:"emergencyContact,omitempty"`
+	CreatedAt        time.Time         `json:"createdAt"`
+}
+
+// EmergencyContact DTO con el contacto de emergencia del huésped
+type EmergencyContact struct {
+	Name  string `json:"name"`
+	Phone string `json:"phone"`
+}
+
+// UpdateProfileRequest DTO para editar el propio perfil (PATCH /users/me)
+// Solo se modifican los campos enviados; string vacío borra el dato. Los formatos los valida el servicio
+type UpdateProfileRequest struct {
+	FirstName        *string           `json:"firstName" binding:"omitempty,max=100"`
+	LastName         *string           `json:"lastName" binding:"omitempty,max=100"`
+	Phone            *string           `json:"phone"`
+	AvatarURL        *string           `json:"avatarUrl"`
+	Bio              *string           `json:"bio"`
+	Locale           *string           `json:"locale"`
+	Languages        *[]string         `json:"languages"`
+	City             *string           `json:"city" binding:"omitempty,max=100"`
+	EmergencyContact *EmergencyContact `json:"emergencyContact"`
+}
+
+// HostProfileResponse DTO con el perfil público de un anfitrión (lo muestra properties-api en cada propiedad)
+// No incluye email ni teléfono: solo si el anfitrión tiene un teléfono cargado
+type HostProfileResponse struct {
+	ID          uint      `json:"id"`
+	DisplayName string    `json:"displayName"`
+	AvatarURL   string    `json:"avatarUrl,omitempty"`
+	Bio         string    `json:"bio,omitempty"`
+	Languages   []string  `json:"languages"`
+	City        string    `json:"city,omitempty"`
+	HasPhone    bool      `json:"hasPhone"`
+	MemberSince time.Time `json:"memberSince"`
+}
+
+// FavoriteResponse DTO de una propiedad favorita
+type FavoriteResponse struct {
+	PropertyID string    `json:"propertyId"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
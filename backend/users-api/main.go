@@ -1,59 +1,81 @@
 package main
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"log"
+	"net"
 	"os"
+	"reporting"
+	"users-api/clients"
+	"users-api/config"
 	"users-api/controllers"
 	"users-api/domain"
 	"users-api/middleware"
+	"users-api/migrations"
 	"users-api/repositories"
+	"users-api/rpc"
 	"users-api/services"
+	"users-api/utils"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/driver/mysql"
-	"gorm.io/gorm"
+	"google.golang.org/grpc"
 )
 
 func main() {
 	// ============================================
 	// 1. CONFIGURACIÓN - Leer variables de entorno
 	// ============================================
-	dbHost := getEnv("DB_HOST", "localhost")
-	dbPort := getEnv("DB_PORT", "3306")
-	dbUser := getEnv("DB_USER", "spotly_user")
-	dbPassword := getEnv("DB_PASSWORD", "spotly_password")
-	dbName := getEnv("DB_NAME", "users_db")
+	// Valores por defecto para desarrollo; la configuración inválida detiene el arranque
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+	utils.SetJWTSecret(cfg.JWTSecret)
+	utils.SetArgon2Params(utils.Argon2Params{
+		Memory:      uint32(cfg.PasswordHashing.Argon2Memory),
+		Iterations:  uint32(cfg.PasswordHashing.Argon2Iterations),
+		Parallelism: uint8(cfg.PasswordHashing.Argon2Parallelism),
+		SaltLength:  utils.DefaultArgon2Params.SaltLength,
+		KeyLength:   utils.DefaultArgon2Params.KeyLength,
+	})
+
+	// Comandos de administración (ej: users-api migrate status): se ejecutan y el proceso termina
+	if len(os.Args) > 1 {
+		if err := runCommand(cfg, os.Args[1:]); err != nil {
+			log.Fatal("❌ ", err)
+		}
+		return
+	}
 
 	log.Println("🔧 Configuración cargada:")
-	log.Printf("   - DB Host: %s:%s", dbHost, dbPort)
-	log.Printf("   - DB Name: %s", dbName)
+	for _, line := range cfg.Dump() {
+		log.Printf("   - %s", line)
+	}
 
 	// ============================================
 	// 2. CONECTAR A MYSQL
 	// ============================================
-	// DSN = Data Source Name (string de conexión)
-	// Formato: usuario:password@tcp(host:puerto)/base_de_datos?opciones
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		dbUser, dbPassword, dbHost, dbPort, dbName)
-
 	log.Println("📡 Conectando a MySQL...")
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	// Reintenta hasta DB_CONNECT_MAX_WAIT por si MySQL todavía está arrancando
+	db, err := config.ConnectMySQL(cfg.Database)
 	if err != nil {
 		log.Fatal("❌ Failed to connect to database:", err)
 	}
 	log.Println("✅ Conexión a MySQL exitosa")
 
 	// ============================================
-	// 3. AUTO-MIGRAR LAS TABLAS
+	// 3. MIGRACIONES VERSIONADAS
 	// ============================================
-	// GORM crea automáticamente la tabla "users" si no existe
-	log.Println("🔄 Ejecutando migraciones...")
-	err = db.AutoMigrate(&domain.User{})
-	if err != nil {
-		log.Fatal("❌ Failed to migrate database:", err)
+	// Los archivos .sql de migrations/ van embebidos en el binario; con DB_MIGRATE_ON_STARTUP=false
+	// se aplican antes del deploy con "users-api migrate up"
+	if cfg.Database.MigrateOnStartup {
+		log.Println("🔄 Ejecutando migraciones...")
+		if err := migrations.Up(cfg.Database); err != nil {
+			log.Fatal("❌ Failed to migrate database:", err)
+		}
+		log.Println("✅ Tablas creadas/actualizadas")
 	}
-	log.Println("✅ Tablas creadas/actualizadas")
 
 	// ============================================
 	// 4. INICIALIZAR CAPAS (Patrón MVC)
@@ -62,12 +84,110 @@ func main() {
 
 	// Repository: acceso a datos
 	userRepo := repositories.NewUserRepository(db)
+	revocationRepo := repositories.NewTokenRevocationRepository(db)
+	roleChangeRepo := repositories.NewRoleChangeRepository(db)
+	loginEventRepo := repositories.NewLoginEventRepository(db)
+	auditRepo := repositories.NewAuditRepository(db)
+	favoriteRepo := repositories.NewFavoriteRepository(db)
+	apiKeyRepo := repositories.NewAPIKeyRepository(db)
+
+	// Publisher de alertas de seguridad (servicio de notificaciones vía RabbitMQ)
+	// Si RabbitMQ no está disponible las alertas solo se loguean
+	var securityAlerts clients.SecurityAlertPublisher
+	if publisher, err := clients.NewRabbitMQSecurityAlertPublisher(cfg.RabbitMQ.URL); err != nil {
+		log.Printf("⚠️ No se pudo conectar a RabbitMQ, las alertas de seguridad no se publicarán: %v", err)
+	} else {
+		securityAlerts = publisher
+	}
+
+	// Publisher de eventos de usuarios (ej: user.deactivated para ocultar propiedades en search-api)
+	var userEvents clients.UserEventPublisher
+	if publisher, err := clients.NewRabbitMQUserEventPublisher(cfg.RabbitMQ.URL, cfg.RabbitMQ.UsersExchange); err != nil {
+		log.Printf("⚠️ No se pudo conectar a RabbitMQ, los eventos de usuarios no se publicarán: %v", err)
+	} else {
+		userEvents = publisher
+	}
+
+	// Almacenamiento de las fotos de perfil subidas (servidas como estáticos en /media)
+	avatarStorage := repositories.NewLocalAvatarStorage(cfg.Avatars.StorageDir, cfg.Avatars.BaseURL)
+
+	// Cliente HTTP compartido por los clientes salientes (pool de conexiones y timeouts)
+	httpClient := clients.NewHTTPClient(cfg.HTTPClient)
+
+	// Reporte de panics y errores 5xx (sin ERROR_REPORTING_DSN solo quedan en los logs)
+	errorReporter := reporting.NewErrorReporter(cfg.ErrorReporting, "users-api", httpClient)
+
+	// Cliente de properties-api (export de datos del usuario)
+	propertiesClient := clients.NewPropertiesClient(cfg.PropertiesAPI.BaseURL, httpClient)
+
+	// Política de contraseñas; con PASSWORD_BREACH_CHECK se rechazan además las filtradas
+	var breachedPasswords clients.BreachedPasswordChecker
+	if cfg.PasswordPolicy.BreachCheck {
+		breachedPasswords = clients.NewBreachedPasswordChecker(cfg.PasswordPolicy.BreachAPIURL, cfg.PasswordPolicy.BreachTimeout, httpClient)
+	}
+	passwordPolicy := services.NewPasswordPolicy(services.PasswordPolicyConfig{
+		MinLength:     cfg.PasswordPolicy.MinLength,
+		MaxLength:     cfg.PasswordPolicy.MaxLength,
+		RequireUpper:  cfg.PasswordPolicy.RequireUpper,
+		RequireLower:  cfg.PasswordPolicy.RequireLower,
+		RequireDigit:  cfg.PasswordPolicy.RequireDigit,
+		RequireSymbol: cfg.PasswordPolicy.RequireSymbol,
+	}, breachedPasswords)
 
 	// Service: lógica de negocio
-	userService := services.NewUserService(userRepo)
+	auditService := services.NewAuditService(auditRepo)
+	loginHistoryService := services.NewLoginHistoryService(loginEventRepo, securityAlerts)
+	userService := services.NewUserService(userRepo, loginHistoryService, auditService, passwordPolicy)
+	roleService := services.NewRoleService(userRepo, roleChangeRepo, revocationRepo, auditService)
+	accountService := services.NewAccountService(userRepo, revocationRepo, userEvents, auditService, passwordPolicy)
+	privacyService := services.NewPrivacyService(userRepo, loginEventRepo, favoriteRepo, revocationRepo, propertiesClient, userEvents, avatarStorage, auditService)
+	preferenceService := services.NewPreferenceService(userRepo, favoriteRepo)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo, auditService)
+	profileService := services.NewProfileService(userRepo, avatarStorage, auditService, cfg.Avatars.MaxUploadBytes)
+
+	// Bootstrap del admin inicial (desde el entorno o con token de setup)
+	adminBootstrap := services.NewAdminBootstrapService(userRepo, auditService, passwordPolicy, services.AdminBootstrapConfig{
+		Username:   cfg.Admin.Username,
+		Email:      cfg.Admin.Email,
+		Password:   cfg.Admin.Password,
+		SetupToken: cfg.Admin.SetupToken,
+	})
+	if err := adminBootstrap.EnsureInitialAdmin(); err != nil {
+		log.Printf("⚠️ Error en bootstrap del administrador inicial: %v", err)
+	}
+
+	// Health checks: MySQL es obligatorio; sin RabbitMQ el servicio funciona sin publicar eventos
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatal("❌ Failed to get database handle:", err)
+	}
+	// Ping periódico: después de un reinicio de MySQL descarta las conexiones rotas del pool
+	go config.MonitorMySQL(context.Background(), sqlDB, cfg.Database)
+	healthService := services.NewHealthService("users-api", cfg.Health.CheckTimeout,
+		services.HealthCheck{Name: "mysql", Check: sqlDB.PingContext},
+		services.HealthCheck{Name: "rabbitmq", Optional: true, Check: func(ctx context.Context) error {
+			if userEvents == nil {
+				return errors.New("sin conexión con RabbitMQ")
+			}
+			return userEvents.Ping()
+		}},
+	)
 
 	// Controller: maneja HTTP
-	userController := controllers.NewUserController(userService)
+	userController := controllers.NewUserController(userService, loginHistoryService)
+	setupController := controllers.NewSetupController(adminBootstrap)
+	roleController := controllers.NewRoleController(roleService)
+	accountController := controllers.NewAccountController(accountService)
+	privacyController := controllers.NewPrivacyController(privacyService)
+	auditController := controllers.NewAuditController(auditService)
+	apiKeyController := controllers.NewAPIKeyController(apiKeyService)
+	preferenceController := controllers.NewPreferenceController(preferenceService)
+	profileController := controllers.NewProfileController(profileService, cfg.Avatars.MaxUploadBytes)
+	healthController := controllers.NewHealthController(healthService)
+	migrationController := controllers.NewMigrationController(services.NewMigrationService(cfg.Database))
+
+	// Controller gRPC: llamadas internas de otros servicios
+	userGRPCController := controllers.NewUserGRPCController(userService, preferenceService, profileService, apiKeyService, services.NewTokenService(revocationRepo))
 
 	log.Println("✅ Capas inicializadas")
 
@@ -75,21 +195,23 @@ func main() {
 	// 5. CONFIGURAR GIN (Framework web)
 	// ============================================
 	// Gin es como Express en Node.js
-	router := gin.Default()
+	// gin.New en lugar de gin.Default: el recovery propio reporta los panics con el request ID y el usuario
+	router := gin.New()
 
-	// CORS - Permitir requests desde el frontend
-	router.Use(func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
+	// X-Forwarded-For solo se acepta de los proxies de TRUSTED_PROXIES (c.ClientIP toma el último salto no confiable)
+	if err := router.SetTrustedProxies(cfg.Proxy.TrustedProxies); err != nil {
+		log.Fatal("❌ TRUSTED_PROXIES inválido: ", err)
+	}
+	clientCountry, err := middleware.ClientCountry(cfg.Proxy)
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+	router.Use(gin.Logger(), middleware.RequestID(), middleware.Recovery(errorReporter))
 
-		c.Next()
-	})
+	// Headers de seguridad y CORS - Solo los orígenes de CORS_ALLOWED_ORIGINS (ej: el frontend)
+	router.Use(middleware.SecurityHeaders(cfg.Security))
+	router.Use(middleware.CORS(cfg.CORS))
+	router.Use(clientCountry)
 
 	// ============================================
 	// 6. DEFINIR RUTAS (Endpoints)
@@ -97,49 +219,113 @@ func main() {
 	log.Println("🛣️  Configurando rutas...")
 
 	// Rutas PÚBLICAS (sin autenticación)
-	router.GET("/health", userController.HealthCheck)
-	router.POST("/users", userController.CreateUser)     // Registro
-	router.POST("/users/login", userController.Login)    // Login
-	router.GET("/users/:id", userController.GetUserByID) // Obtener usuario
+	router.GET("/health/live", healthController.Live)                       // Liveness probe
+	router.GET("/health/ready", healthController.Ready)                     // Readiness probe (MySQL y RabbitMQ)
+	router.POST("/users", userController.CreateUser)                        // Registro
+	router.POST("/users/login", userController.Login)                       // Login
+	router.GET("/users/:id", userController.GetUserByID)                    // Obtener usuario
+	router.GET("/users/:id/host-profile", profileController.GetHostProfile) // Perfil público de anfitrión
+	router.POST("/setup/admin", setupController.SetupAdmin)                 // Crear primer admin (token de setup)
+	router.Static("/media", cfg.Avatars.StorageDir)                         // Fotos de perfil subidas
+
+	// Rutas del usuario autenticado (requieren JWT)
+	me := router.Group("/users/me")
+	me.Use(middleware.AuthMiddleware(revocationRepo))
+	{
+		me.GET("", profileController.GetMe)                                      // Perfil completo
+		me.PATCH("", profileController.UpdateMe)                                 // Editar perfil
+		me.POST("/avatar", profileController.UploadAvatar)                       // Subir foto de perfil
+		me.POST("/password", accountController.ChangePassword)                   // Cambiar contraseña (cierra las otras sesiones)
+		me.GET("/logins", userController.GetMyLogins)                            // Historial de logins
+		me.GET("/preferences", preferenceController.GetPreferences)              // Moneda y locale preferidos
+		me.PUT("/preferences", preferenceController.UpdatePreferences)           // Actualizar preferencias
+		me.GET("/favorites", preferenceController.ListFavorites)                 // Propiedades favoritas
+		me.PUT("/favorites/:propertyId", preferenceController.AddFavorite)       // Marcar favorita
+		me.DELETE("/favorites/:propertyId", preferenceController.RemoveFavorite) // Desmarcar favorita
+	}
+
+	// Desactivar cuenta (el propio usuario o un admin)
+	router.POST("/users/:id/deactivate", middleware.AuthMiddleware(revocationRepo), accountController.Deactivate)
+
+	// Datos personales - GDPR (el propio usuario o un admin)
+	router.GET("/users/:id/export", middleware.AuthMiddleware(revocationRepo), privacyController.Export)
+	router.DELETE("/users/:id/erase", middleware.AuthMiddleware(revocationRepo), privacyController.Erase)
 
 	// Rutas PROTEGIDAS (requieren JWT - solo admin)
 	// Importar middleware aquí si no está importado
 	admin := router.Group("/admin")
-	admin.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+	admin.Use(middleware.AuthMiddleware(revocationRepo), middleware.AdminMiddleware(), middleware.AdminAuditMiddleware())
 	{
-		admin.GET("/users", userController.GetAllUsers)       // Listar todos
-		admin.PUT("/users/:id", userController.UpdateUser)    // Actualizar
-		admin.DELETE("/users/:id", userController.DeleteUser) // Eliminar
+		admin.GET("/users", userController.ListUsers)           // Listar (paginado y filtrado)
+		admin.PUT("/users/:id", userController.UpdateUser)      // Actualizar
+		admin.DELETE("/users/:id", userController.DeleteUser)   // Eliminar
+		admin.PUT("/users/:id/role", roleController.ChangeRole) // Cambiar rol
+		admin.GET("/audit", auditController.List)               // Log de auditoría
+		admin.POST("/api-keys", apiKeyController.Create)        // Emitir API key de servicio
+		admin.GET("/api-keys", apiKeyController.List)           // Listar API keys
+		admin.DELETE("/api-keys/:id", apiKeyController.Revoke)  // Revocar API key
+		admin.GET("/migrations", migrationController.Status)    // Estado de las migraciones de MySQL
 	}
 
 	log.Println("✅ Rutas configuradas:")
-	log.Println("   - GET  /health")
+	log.Println("   - GET  /health/live")
+	log.Println("   - GET  /health/ready")
 	log.Println("   - POST /users (registro)")
 	log.Println("   - POST /users/login")
 	log.Println("   - GET  /users/:id")
-	log.Println("   - GET  /admin/users (admin)")
+	log.Println("   - GET  /users/:id/host-profile")
+	log.Println("   - POST /setup/admin (token de setup)")
+	log.Println("   - GET  /users/me, PATCH /users/me (autenticado)")
+	log.Println("   - POST /users/me/avatar (autenticado, multipart)")
+	log.Println("   - GET  /media/* (fotos de perfil)")
+	log.Println("   - POST /users/me/password (autenticado)")
+	log.Println("   - GET  /users/me/logins (autenticado)")
+	log.Println("   - GET  /users/me/preferences, PUT /users/me/preferences (autenticado)")
+	log.Println("   - GET  /users/me/favorites, PUT|DELETE /users/me/favorites/:propertyId (autenticado)")
+	log.Println("   - POST /users/:id/deactivate (autenticado, propio usuario o admin)")
+	log.Println("   - GET  /users/:id/export (autenticado, propio usuario o admin)")
+	log.Println("   - DELETE /users/:id/erase (autenticado, propio usuario o admin)")
+	log.Println("   - GET  /admin/users?page=&limit=&userType=&search= (admin)")
 	log.Println("   - PUT  /admin/users/:id (admin)")
 	log.Println("   - DELETE /admin/users/:id (admin)")
+	log.Println("   - PUT  /admin/users/:id/role (admin)")
+	log.Println("   - GET  /admin/audit?userId=&from=&to=&page=&limit= (admin)")
+	log.Println("   - POST /admin/api-keys, GET /admin/api-keys, DELETE /admin/api-keys/:id (admin)")
+	log.Println("   - GET  /admin/migrations (admin)")
 
 	// ============================================
-	// 7. ARRANCAR EL SERVIDOR
+	// 7. ARRANCAR EL SERVIDOR gRPC (llamadas internas)
 	// ============================================
-	port := getEnv("SERVER_PORT", "8080")
+	// properties-api valida los owners por gRPC en lugar de GET /users/:id
+	// Los servicios se autentican con una API key con scope users:read; VerifyAPIKey no la requiere
+	// porque es la llamada con la que properties-api valida las keys que recibe
+	grpcListener, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+	if err != nil {
+		log.Fatal("❌ Failed to listen for gRPC:", err)
+	}
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(middleware.APIKeyUnaryInterceptor(
+		apiKeyService, domain.APIKeyScopeUsersRead, cfg.InternalAuth.APIKeysRequired,
+		"/"+rpc.UsersServiceName+"/VerifyAPIKey",
+	)))
+	rpc.RegisterUsersServer(grpcServer, userGRPCController)
+	go func() {
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			log.Printf("❌ Servidor gRPC detenido: %v", err)
+		}
+	}()
+	log.Printf("✅ Servidor gRPC escuchando en puerto %s (%s: ValidateUser, GetUser, GetUsers, GetSearchProfile, GetHostProfile, VerifyAPIKey, VerifyToken)", cfg.GRPCPort, rpc.UsersServiceName)
+	if !cfg.InternalAuth.APIKeysRequired {
+		log.Println("⚠️ INTERNAL_API_KEYS_REQUIRED=false: se aceptan llamadas gRPC sin API key")
+	}
 
+	// ============================================
+	// 8. ARRANCAR EL SERVIDOR
+	// ============================================
 	log.Println("🚀 =======================================")
-	log.Printf("🚀 Users API corriendo en puerto %s", port)
+	log.Printf("🚀 Users API corriendo en puerto %s", cfg.ServerPort)
 	log.Println("🚀 =======================================")
 
-	if err := router.Run(":" + port); err != nil {
+	if err := router.Run(":" + cfg.ServerPort); err != nil {
 		log.Fatal("❌ Failed to start server:", err)
 	}
 }
-
-// getEnv obtiene una variable de entorno o retorna un valor por defecto
-func getEnv(key, defaultValue string) string {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
-	}
-	return value
-}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminAuditMiddleware registra en el log cada acción de un administrador:
+// quién la hizo, qué endpoint y sobre qué usuario, y con qué resultado
+// Este middleware se usa DESPUÉS de AuthMiddleware y AdminMiddleware
+func AdminAuditMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		actorID, _ := c.Get("user_id")
+		actorName, _ := c.Get("username")
+		target := c.Param("id")
+		if target == "" {
+			target = "-"
+		}
+
+		log.Printf("📝 [AUDIT] admin=%v (%v) action=%s %s target=%s status=%d ip=%s duration=%s",
+			actorID, actorName, c.Request.Method, c.FullPath(), target,
+			c.Writer.Status(), c.ClientIP(), time.Since(start).Round(time.Millisecond))
+	}
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"users-api/dto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// APIKeyMetadataKey es el metadata gRPC con el que los servicios internos envían su API key
+// Es el equivalente al header X-API-Key de HTTP
+const APIKeyMetadataKey = "x-api-key"
+
+// APIKeyVerifier valida las API keys de los servicios internos (ver services.APIKeyService)
+type APIKeyVerifier interface {
+	Verify(key string) (identity dto.APIKeyIdentity, ok bool, err error)
+}
+
+// apiKeyIdentityKey es la clave del contexto con el servicio autenticado
+type apiKeyIdentityKey struct{}
+
+// APIKeyIdentityFromContext retorna el servicio que hizo la llamada gRPC (ok es false si llamó sin key)
+func APIKeyIdentityFromContext(ctx context.Context) (dto.APIKeyIdentity, bool) {
+	identity, ok := ctx.Value(apiKeyIdentityKey{}).(dto.APIKeyIdentity)
+	return identity, ok
+}
+
+// APIKeyUnaryInterceptor exige a las llamadas gRPC una API key con el scope indicado
+// exempt son los métodos (FullMethod) que no la requieren
+// Con required en false se aceptan las llamadas sin key, para poder configurar los clientes de a uno;
+// una key inválida o sin el scope se rechaza siempre
+func APIKeyUnaryInterceptor(verifier APIKeyVerifier, scope string, required bool, exempt ...string) grpc.UnaryServerInterceptor {
+	exempted := make(map[string]bool, len(exempt))
+	for _, method := range exempt {
+		exempted[method] = true
+	}
+	// Las llamadas sin key se loguean una vez por método para no inundar el log
+	var warned sync.Map
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if exempted[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		var key string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(APIKeyMetadataKey); len(values) > 0 {
+				key = values[0]
+			}
+		}
+
+		if key == "" {
+			if required {
+				return nil, status.Error(codes.Unauthenticated, "API key requerida")
+			}
+			if _, loaded := warned.LoadOrStore(info.FullMethod, true); !loaded {
+				log.Printf("⚠️ Llamada gRPC sin API key a %s (se acepta porque INTERNAL_API_KEYS_REQUIRED=false)", info.FullMethod)
+			}
+			return handler(ctx, req)
+		}
+
+		identity, ok, err := verifier.Verify(key)
+		if err != nil {
+			return nil, status.Error(codes.Unavailable, "no se pudo validar la API key")
+		}
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "API key inválida")
+		}
+		if !identity.HasScope(scope) {
+			return nil, status.Errorf(codes.PermissionDenied, "la API key de '%s' no tiene el scope %s", identity.Name, scope)
+		}
+
+		return handler(context.WithValue(ctx, apiKeyIdentityKey{}, identity), req)
+	}
+}
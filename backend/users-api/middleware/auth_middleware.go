@@ -1,8 +1,10 @@
 package middleware
 
 import (
+	"log"
 	"net/http"
 	"strings"
+	"users-api/repositories"
 	"users-api/utils"
 
 	"github.com/gin-gonic/gin"
@@ -11,7 +13,8 @@ import (
 // AuthMiddleware valida el JWT token en cada request
 // Si el token es válido, permite continuar
 // Si no, devuelve error 401 (Unauthorized)
-func AuthMiddleware() gin.HandlerFunc {
+// También rechaza tokens emitidos antes de una revocación del usuario (ej: cambio de rol)
+func AuthMiddleware(revocations repositories.TokenRevocationRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Obtener el header "Authorization"
 		authHeader := c.GetHeader("Authorization")
@@ -48,6 +51,26 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// Verificar que el token no haya sido revocado
+		if revocations != nil {
+			revokedAt, err := revocations.GetRevokedAt(claims.UserID)
+			if err != nil {
+				log.Printf("⚠️ Error consultando revocación de tokens: %v", err)
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "could not verify token",
+				})
+				c.Abort()
+				return
+			}
+			if utils.IsTokenRevoked(claims, revokedAt) {
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"error": "token has been revoked",
+				})
+				c.Abort()
+				return
+			}
+		}
+
 		// Guardar la info del usuario en el contexto
 		// Así los endpoints pueden saber quién hizo la request
 		c.Set("user_id", claims.UserID)
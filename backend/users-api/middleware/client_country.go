@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net"
+	"strings"
+
+	"users-api/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClientCountryKey es la key del contexto de gin con el país del cliente (vacío si se desconoce)
+const ClientCountryKey = "client_country"
+
+// ClientCountry guarda en el contexto el país del cliente que informa el gateway en cfg.CountryHeader
+// Solo se acepta si el request llega desde un proxy confiable: un cliente que llama directo al servicio
+// podría mandar el header para esconder un login desde un país nuevo
+// (nginx borra el header que manda el cliente, ver nginx/nginx.conf)
+func ClientCountry(cfg config.ProxyConfig) (gin.HandlerFunc, error) {
+	trusted, err := cfg.TrustedNetworks()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(c *gin.Context) {
+		if cfg.CountryHeader == "" || !isTrustedProxy(trusted, c.RemoteIP()) {
+			c.Next()
+			return
+		}
+		if country := strings.ToUpper(strings.TrimSpace(c.GetHeader(cfg.CountryHeader))); country != "" && country != "XX" {
+			c.Set(ClientCountryKey, country)
+		}
+		c.Next()
+	}, nil
+}
+
+// isTrustedProxy indica si la IP de la conexión pertenece a un proxy confiable
+func isTrustedProxy(trusted []*net.IPNet, remoteIP string) bool {
+	ip := net.ParseIP(remoteIP)
+	if ip == nil {
+		return false
+	}
+	for _, network := range trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
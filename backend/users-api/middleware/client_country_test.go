@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"users-api/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestClientCountryAndIP_OnlyTrustGatewayHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := config.ProxyConfig{TrustedProxies: []string{"172.16.0.0/12"}, CountryHeader: "X-Country-Code"}
+
+	clientCountry, err := ClientCountry(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	router := gin.New()
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	router.Use(clientCountry)
+	router.GET("/whoami", func(c *gin.Context) {
+		c.String(http.StatusOK, c.ClientIP()+" "+c.GetString(ClientCountryKey))
+	})
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		country    string
+		want       string
+	}{
+		{"gateway forwards client and country", "172.18.0.5:5000", "203.0.113.10", "ar", "203.0.113.10 AR"},
+		{"spoofed leftmost hop is ignored", "172.18.0.5:5000", "1.2.3.4, 203.0.113.10", "AR", "203.0.113.10 AR"},
+		{"unknown country is ignored", "172.18.0.5:5000", "203.0.113.10", "XX", "203.0.113.10 "},
+		{"direct client cannot forge ip or country", "198.51.100.7:5000", "1.2.3.4", "AR", "198.51.100.7 "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+			request.RemoteAddr = tt.remoteAddr
+			request.Header.Set("X-Forwarded-For", tt.forwarded)
+			request.Header.Set("X-Country-Code", tt.country)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+			if got := recorder.Body.String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := ClientCountry(config.ProxyConfig{TrustedProxies: []string{"nope"}}); err == nil {
+		t.Error("expected an invalid trusted proxy to be rejected")
+	}
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+
+	"reporting"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Recovery reemplaza al recovery de gin: además de loguear el panic y responder 500 lo reporta,
+// y también reporta las respuestas 5xx. Los eventos van taggeados con el request ID y con el usuario
+// que AuthMiddleware deja en el contexto, así que tiene que ir después de RequestID
+func Recovery(reporter reporting.ErrorReporter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				if status := c.Writer.Status(); status >= http.StatusInternalServerError {
+					message := fmt.Sprintf("%s %s respondió %d", c.Request.Method, c.FullPath(), status)
+					if len(c.Errors) > 0 {
+						message += ": " + c.Errors.Last().Error()
+					}
+					reporter.Report(reporting.ErrorEvent{
+						Message: message,
+						Level:   reporting.ErrorLevelError,
+						Tags:    errorTags(c, status),
+					})
+				}
+				return
+			}
+			// ErrAbortHandler es la forma de cortar un request a propósito; net/http no lo loguea
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			stack := string(debug.Stack())
+			log.Printf("💥 Panic en %s %s (request %s): %v\n%s", c.Request.Method, c.Request.URL.Path, c.GetString("request_id"), recovered, stack)
+			reporter.Report(reporting.ErrorEvent{
+				Message:    fmt.Sprintf("panic: %v", recovered),
+				Level:      reporting.ErrorLevelFatal,
+				Stacktrace: stack,
+				Tags:       errorTags(c, http.StatusInternalServerError),
+			})
+
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		}()
+		c.Next()
+	}
+}
+
+// errorTags son los tags de un evento del request
+// path es la ruta registrada (ej: /users/:id) para que Sentry agrupe los errores de un mismo endpoint
+func errorTags(c *gin.Context, status int) map[string]string {
+	tags := map[string]string{
+		"request_id": c.GetString("request_id"),
+		"method":     c.Request.Method,
+		"path":       c.FullPath(),
+		"status":     strconv.Itoa(status),
+	}
+	if userID, exists := c.Get("user_id"); exists {
+		tags["user_id"] = fmt.Sprint(userID)
+	}
+	return tags
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"fmt"
+
+	"users-api/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SecurityHeaders agrega los headers de seguridad a todas las respuestas:
+//   - X-Content-Type-Options: nosniff evita que el navegador interprete un JSON como HTML o script
+//   - X-Frame-Options: DENY evita que la API se embeba en un iframe (clickjacking)
+//   - Strict-Transport-Security obliga a usar HTTPS (el navegador lo ignora si la respuesta llegó por HTTP)
+func SecurityHeaders(cfg config.SecurityConfig) gin.HandlerFunc {
+	hsts := ""
+	if cfg.HSTSMaxAge > 0 {
+		hsts = fmt.Sprintf("max-age=%d; includeSubDomains", int(cfg.HSTSMaxAge.Seconds()))
+	}
+
+	return func(c *gin.Context) {
+		c.Header("X-Content-Type-Options", "nosniff")
+		c.Header("X-Frame-Options", "DENY")
+		if hsts != "" {
+			c.Header("Strict-Transport-Security", hsts)
+		}
+		c.Next()
+	}
+}
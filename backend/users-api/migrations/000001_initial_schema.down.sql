@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS favorites;
+DROP TABLE IF EXISTS audit_log;
+DROP TABLE IF EXISTS login_events;
+DROP TABLE IF EXISTS role_changes;
+DROP TABLE IF EXISTS token_revocations;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,104 @@
+-- Esquema inicial: las tablas que hasta ahora creaba AutoMigrate
+-- IF NOT EXISTS permite aplicarla sobre una base creada por AutoMigrate sin tocar los datos
+
+CREATE TABLE IF NOT EXISTS users (
+    id bigint unsigned NOT NULL AUTO_INCREMENT,
+    username varchar(191) NOT NULL,
+    email varchar(191) NOT NULL,
+    password longtext NOT NULL,
+    first_name longtext,
+    last_name longtext,
+    user_type varchar(191) DEFAULT 'normal',
+    created_at datetime(3) NULL,
+    updated_at datetime(3) NULL,
+    active boolean NOT NULL DEFAULT true,
+    preferred_currency varchar(3),
+    locale varchar(16),
+    avatar_url varchar(512),
+    bio varchar(1000),
+    languages varchar(64),
+    city varchar(100),
+    avatar_key varchar(255),
+    phone varchar(20),
+    emergency_contact_name varchar(100),
+    emergency_contact_phone varchar(20),
+    deleted_at datetime(3) NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY uni_users_username (username),
+    UNIQUE KEY uni_users_email (email),
+    KEY idx_users_deleted_at (deleted_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS token_revocations (
+    user_id bigint unsigned NOT NULL,
+    revoked_at datetime(3) NOT NULL,
+    reason longtext,
+    PRIMARY KEY (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS role_changes (
+    id bigint unsigned NOT NULL AUTO_INCREMENT,
+    user_id bigint unsigned NOT NULL,
+    changed_by bigint unsigned NOT NULL,
+    old_role longtext NOT NULL,
+    new_role longtext NOT NULL,
+    created_at datetime(3) NULL,
+    PRIMARY KEY (id),
+    KEY idx_role_changes_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS login_events (
+    id bigint unsigned NOT NULL AUTO_INCREMENT,
+    user_id bigint unsigned NOT NULL,
+    ip varchar(64),
+    user_agent varchar(512),
+    device_hash varchar(64),
+    country varchar(8),
+    success boolean,
+    suspicious boolean,
+    reasons longtext,
+    created_at datetime(3) NULL,
+    PRIMARY KEY (id),
+    KEY idx_login_events_user_id (user_id),
+    KEY idx_login_events_device_hash (device_hash),
+    KEY idx_login_events_created_at (created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id bigint unsigned NOT NULL AUTO_INCREMENT,
+    actor_id bigint unsigned NOT NULL,
+    action varchar(50) NOT NULL,
+    entity_type varchar(50) NOT NULL,
+    entity_id varchar(64),
+    changes text,
+    created_at datetime(3) NULL,
+    PRIMARY KEY (id),
+    KEY idx_audit_actor_created (actor_id, created_at),
+    KEY idx_audit_log_entity_id (entity_id),
+    KEY idx_audit_log_created_at (created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS favorites (
+    id bigint unsigned NOT NULL AUTO_INCREMENT,
+    user_id bigint unsigned NOT NULL,
+    property_id varchar(64) NOT NULL,
+    created_at datetime(3) NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY idx_favorites_user_property (user_id, property_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id bigint unsigned NOT NULL AUTO_INCREMENT,
+    name varchar(100) NOT NULL,
+    prefix varchar(16) NOT NULL,
+    key_hash varchar(64) NOT NULL,
+    scopes varchar(255) NOT NULL,
+    created_by bigint unsigned NOT NULL,
+    expires_at datetime(3) NULL,
+    last_used_at datetime(3) NULL,
+    revoked_at datetime(3) NULL,
+    created_at datetime(3) NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY idx_api_keys_prefix (prefix),
+    KEY idx_api_keys_revoked_at (revoked_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,2 @@
+DROP INDEX idx_users_user_type_active ON users;
+ALTER TABLE users DROP COLUMN verified;
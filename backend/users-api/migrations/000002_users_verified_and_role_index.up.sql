@@ -0,0 +1,5 @@
+-- verified: el usuario confirmó su email (las cuentas existentes quedan sin verificar)
+ALTER TABLE users ADD COLUMN verified boolean NOT NULL DEFAULT false AFTER active;
+
+-- El listado de admin filtra por rol (user_type) y los chequeos de login por cuenta activa
+CREATE INDEX idx_users_user_type_active ON users (user_type, active);
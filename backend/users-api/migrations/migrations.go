@@ -0,0 +1,163 @@
+package migrations
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/url"
+
+	"users-api/config"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/mysql" // Driver "mysql://" de golang-migrate
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// files son las migraciones versionadas: NNNNNN_nombre.up.sql y NNNNNN_nombre.down.sql
+//
+//go:embed *.sql
+var files embed.FS
+
+// Migration es una migración conocida y si ya está aplicada en la base
+type Migration struct {
+	Version uint
+	Name    string
+	Applied bool
+}
+
+// Status es el estado de las migraciones de la base
+// Dirty indica que la última migración falló a mitad de camino y hay que revisarla a mano (ver Force)
+type Status struct {
+	Version    uint // 0 = ninguna aplicada
+	Dirty      bool
+	Latest     uint // Última migración embebida en el binario
+	Pending    int
+	Migrations []Migration
+}
+
+// Up aplica todas las migraciones pendientes
+func Up(cfg config.DatabaseConfig) error {
+	return run(cfg, func(m *migrate.Migrate) error {
+		if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+			return err
+		}
+		return nil
+	})
+}
+
+// Down revierte las últimas steps migraciones aplicadas
+func Down(cfg config.DatabaseConfig, steps int) error {
+	if steps <= 0 {
+		return fmt.Errorf("la cantidad de migraciones a revertir tiene que ser positiva, se recibió %d", steps)
+	}
+	return run(cfg, func(m *migrate.Migrate) error {
+		return m.Steps(-steps)
+	})
+}
+
+// Force marca la base en la versión dada sin ejecutar nada y limpia el estado dirty
+// Se usa después de arreglar a mano una migración que falló
+func Force(cfg config.DatabaseConfig, version int) error {
+	return run(cfg, func(m *migrate.Migrate) error {
+		return m.Force(version)
+	})
+}
+
+// GetStatus retorna la versión actual de la base y las migraciones aplicadas y pendientes
+func GetStatus(cfg config.DatabaseConfig) (Status, error) {
+	var status Status
+	err := run(cfg, func(m *migrate.Migrate) error {
+		version, dirty, err := m.Version()
+		if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+			return err
+		}
+		status.Version = version
+		status.Dirty = dirty
+		return nil
+	})
+	if err != nil {
+		return Status{}, err
+	}
+
+	known, err := list()
+	if err != nil {
+		return Status{}, err
+	}
+	status.Migrations = make([]Migration, len(known))
+	for i, migration := range known {
+		migration.Applied = migration.Version <= status.Version
+		if !migration.Applied {
+			status.Pending++
+		}
+		status.Migrations[i] = migration
+		status.Latest = migration.Version
+	}
+	return status, nil
+}
+
+// run abre golang-migrate con una conexión propia, ejecuta fn y la cierra
+// Los logs de golang-migrate se muestran con el prefijo de la aplicación
+func run(cfg config.DatabaseConfig, fn func(m *migrate.Migrate) error) error {
+	sourceDriver, err := iofs.New(files, ".")
+	if err != nil {
+		return fmt.Errorf("error leyendo las migraciones: %w", err)
+	}
+	m, err := migrate.NewWithSourceInstance("iofs", sourceDriver, databaseURL(cfg))
+	if err != nil {
+		return fmt.Errorf("error conectando a MySQL para migrar: %w", err)
+	}
+	defer m.Close()
+	m.Log = migrateLogger{}
+
+	if err := fn(m); err != nil {
+		return fmt.Errorf("error en las migraciones: %w", err)
+	}
+	return nil
+}
+
+// list retorna las migraciones embebidas ordenadas por versión
+func list() ([]Migration, error) {
+	sourceDriver, err := iofs.New(files, ".")
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo las migraciones: %w", err)
+	}
+	defer sourceDriver.Close()
+
+	var migrations []Migration
+	version, err := sourceDriver.First()
+	for err == nil {
+		reader, name, readErr := sourceDriver.ReadUp(version)
+		if readErr != nil {
+			return nil, fmt.Errorf("error leyendo la migración %d: %w", version, readErr)
+		}
+		reader.Close()
+		migrations = append(migrations, Migration{Version: version, Name: name})
+		version, err = sourceDriver.Next(version)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("error leyendo las migraciones: %w", err)
+	}
+	return migrations, nil
+}
+
+// databaseURL arma la URL "mysql://" de golang-migrate a partir de la configuración
+// El usuario y el password van escapados porque golang-migrate los decodifica
+func databaseURL(cfg config.DatabaseConfig) string {
+	return fmt.Sprintf("mysql://%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=UTC&multiStatements=true",
+		url.QueryEscape(cfg.User), url.QueryEscape(cfg.Password), cfg.Host, cfg.Port, cfg.Name)
+}
+
+// migrateLogger adapta el logger de golang-migrate al log estándar
+type migrateLogger struct{}
+
+// Printf loguea cada migración aplicada o revertida
+func (migrateLogger) Printf(format string, v ...interface{}) {
+	log.Printf("🗄️  "+format, v...)
+}
+
+// Verbose deshabilita los logs detallados de golang-migrate
+func (migrateLogger) Verbose() bool {
+	return false
+}
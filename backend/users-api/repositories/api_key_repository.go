@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+	"users-api/domain"
+
+	"gorm.io/gorm"
+)
+
+// APIKeyRepository maneja las API keys de los servicios internos
+type APIKeyRepository interface {
+	Create(key *domain.APIKey) error
+	GetByID(id uint) (*domain.APIKey, error)
+	GetByPrefix(prefix string) (*domain.APIKey, error)
+	List() ([]domain.APIKey, error)
+	Revoke(id uint, at time.Time) error
+	TouchLastUsed(id uint, at time.Time) error
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository crea una nueva instancia del repositorio
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+// Create guarda una key nueva
+func (r *apiKeyRepository) Create(key *domain.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+// GetByID obtiene una key por ID; retorna nil si no existe
+func (r *apiKeyRepository) GetByID(id uint) (*domain.APIKey, error) {
+	var key domain.APIKey
+	if err := r.db.First(&key, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+// GetByPrefix obtiene una key por su prefijo visible; retorna nil si no existe
+func (r *apiKeyRepository) GetByPrefix(prefix string) (*domain.APIKey, error) {
+	var key domain.APIKey
+	if err := r.db.First(&key, "prefix = ?", prefix).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+// List obtiene todas las keys, de la más reciente a la más antigua
+func (r *apiKeyRepository) List() ([]domain.APIKey, error) {
+	var keys []domain.APIKey
+	err := r.db.Order("created_at DESC").Find(&keys).Error
+	return keys, err
+}
+
+// Revoke marca la key como revocada; una key ya revocada conserva la fecha original
+func (r *apiKeyRepository) Revoke(id uint, at time.Time) error {
+	return r.db.Model(&domain.APIKey{}).Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", at).Error
+}
+
+// TouchLastUsed registra el último uso de la key
+func (r *apiKeyRepository) TouchLastUsed(id uint, at time.Time) error {
+	return r.db.Model(&domain.APIKey{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"time"
+
+	"users-api/domain"
+
+	"gorm.io/gorm"
+)
+
+// AuditFilter son los filtros y la paginación de la consulta del log de auditoría
+type AuditFilter struct {
+	ActorID uint      // Filtra por el usuario que hizo la operación (0 = todos)
+	From    time.Time // Desde (inclusive, cero = sin límite)
+	To      time.Time // Hasta (exclusive, cero = sin límite)
+	Page    int
+	Limit   int
+}
+
+// AuditRepository guarda y consulta el log de auditoría
+// No tiene Update ni Delete: el log es append-only
+type AuditRepository interface {
+	Create(entry *domain.AuditEntry) error
+	Find(filter AuditFilter) ([]domain.AuditEntry, int64, error)
+}
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditRepository crea una nueva instancia del repositorio
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+// Create inserta un registro de auditoría
+func (r *auditRepository) Create(entry *domain.AuditEntry) error {
+	return r.db.Create(entry).Error
+}
+
+// Find obtiene una página de registros (los más recientes primero) y el total sin paginar
+func (r *auditRepository) Find(filter AuditFilter) ([]domain.AuditEntry, int64, error) {
+	query := r.db.Model(&domain.AuditEntry{})
+
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []domain.AuditEntry
+	err := query.Order("created_at DESC, id DESC").
+		Offset((filter.Page - 1) * filter.Limit).
+		Limit(filter.Limit).
+		Find(&entries).Error
+	return entries, total, err
+}
@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AvatarStorage guarda los archivos de las fotos de perfil subidas
+// Las claves son rutas relativas con "/" (ej: avatars/<id>/<nombre>.jpg)
+// La implementación local es la única por ahora; un bucket (S3, GCS) implementaría la misma interfaz
+type AvatarStorage interface {
+	// Save guarda el archivo y retorna la URL pública con la que se sirve
+	Save(key string, data []byte) (string, error)
+	// Delete elimina un archivo; no es un error si no existe
+	Delete(key string) error
+}
+
+// localAvatarStorage guarda las fotos en un directorio local servido como estático en baseURL
+type localAvatarStorage struct {
+	dir     string
+	baseURL string
+}
+
+// NewLocalAvatarStorage crea el almacenamiento en dir; las URLs se arman como baseURL + "/" + clave
+func NewLocalAvatarStorage(dir, baseURL string) AvatarStorage {
+	return &localAvatarStorage{
+		dir:     dir,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// Save escribe el archivo en un temporal y lo renombra, así nunca se sirve un archivo a medio escribir
+func (s *localAvatarStorage) Save(key string, data []byte) (string, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("error creando directorio de avatares: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return "", fmt.Errorf("error guardando avatar '%s': %w", key, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("error guardando avatar '%s': %w", key, err)
+	}
+
+	return s.baseURL + "/" + key, nil
+}
+
+// Delete elimina un archivo guardado
+func (s *localAvatarStorage) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error eliminando avatar '%s': %w", key, err)
+	}
+	return nil
+}
+
+// path convierte la clave en una ruta dentro de dir; rechaza claves que salen del directorio
+func (s *localAvatarStorage) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("clave de avatar inválida '%s'", key)
+	}
+	return filepath.Join(s.dir, clean), nil
+}
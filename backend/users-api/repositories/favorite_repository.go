@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"users-api/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FavoriteRepository maneja las propiedades favoritas de los usuarios
+type FavoriteRepository interface {
+	Add(favorite *domain.Favorite) error
+	Remove(userID uint, propertyID string) (bool, error)
+	GetByUserID(userID uint) ([]domain.Favorite, error)
+	CountByUserID(userID uint) (int64, error)
+	DeleteByUserID(userID uint) error
+}
+
+type favoriteRepository struct {
+	db *gorm.DB
+}
+
+// NewFavoriteRepository crea una nueva instancia del repositorio
+func NewFavoriteRepository(db *gorm.DB) FavoriteRepository {
+	return &favoriteRepository{db: db}
+}
+
+// Add marca una propiedad como favorita; si ya lo era no hace nada
+func (r *favoriteRepository) Add(favorite *domain.Favorite) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(favorite).Error
+}
+
+// Remove desmarca una propiedad favorita; retorna false si no estaba marcada
+func (r *favoriteRepository) Remove(userID uint, propertyID string) (bool, error) {
+	result := r.db.Where("user_id = ? AND property_id = ?", userID, propertyID).Delete(&domain.Favorite{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetByUserID obtiene los favoritos de un usuario, del más reciente al más antiguo
+func (r *favoriteRepository) GetByUserID(userID uint) ([]domain.Favorite, error) {
+	var favorites []domain.Favorite
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&favorites).Error
+	return favorites, err
+}
+
+// CountByUserID cuenta los favoritos de un usuario
+func (r *favoriteRepository) CountByUserID(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.Favorite{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// DeleteByUserID elimina todos los favoritos de un usuario
+func (r *favoriteRepository) DeleteByUserID(userID uint) error {
+	return r.db.Where("user_id = ?", userID).Delete(&domain.Favorite{}).Error
+}
@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"users-api/domain"
+
+	"gorm.io/gorm"
+)
+
+// LoginEventRepository maneja el historial de logins de los usuarios
+type LoginEventRepository interface {
+	Create(event *domain.LoginEvent) error
+	GetByUserID(userID uint, limit int) ([]domain.LoginEvent, error)
+	CountSuccessful(userID uint) (int64, error)
+	HasSuccessfulFromDevice(userID uint, deviceHash string) (bool, error)
+	HasSuccessfulFromCountry(userID uint, country string) (bool, error)
+	DeleteByUserID(userID uint) error
+}
+
+type loginEventRepository struct {
+	db *gorm.DB
+}
+
+// NewLoginEventRepository crea una nueva instancia del repositorio
+func NewLoginEventRepository(db *gorm.DB) LoginEventRepository {
+	return &loginEventRepository{db: db}
+}
+
+// Create inserta un evento de login
+func (r *loginEventRepository) Create(event *domain.LoginEvent) error {
+	return r.db.Create(event).Error
+}
+
+// GetByUserID obtiene los últimos logins de un usuario, del más reciente al más antiguo
+// Con limit -1 se obtienen todos
+func (r *loginEventRepository) GetByUserID(userID uint, limit int) ([]domain.LoginEvent, error) {
+	var events []domain.LoginEvent
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Limit(limit).Find(&events).Error
+	return events, err
+}
+
+// CountSuccessful cuenta los logins exitosos de un usuario
+func (r *loginEventRepository) CountSuccessful(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.LoginEvent{}).Where("user_id = ? AND success = ?", userID, true).Count(&count).Error
+	return count, err
+}
+
+// HasSuccessfulFromDevice indica si el usuario ya hizo login exitoso desde el dispositivo
+func (r *loginEventRepository) HasSuccessfulFromDevice(userID uint, deviceHash string) (bool, error) {
+	var count int64
+	err := r.db.Model(&domain.LoginEvent{}).
+		Where("user_id = ? AND success = ? AND device_hash = ?", userID, true, deviceHash).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// HasSuccessfulFromCountry indica si el usuario ya hizo login exitoso desde el país
+func (r *loginEventRepository) HasSuccessfulFromCountry(userID uint, country string) (bool, error) {
+	var count int64
+	err := r.db.Model(&domain.LoginEvent{}).
+		Where("user_id = ? AND success = ? AND country = ?", userID, true, country).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// DeleteByUserID elimina todo el historial de logins de un usuario (IPs, user agents, países)
+func (r *loginEventRepository) DeleteByUserID(userID uint) error {
+	return r.db.Where("user_id = ?", userID).Delete(&domain.LoginEvent{}).Error
+}
@@ -0,0 +1,26 @@
+package repositories
+
+import (
+	"users-api/domain"
+
+	"gorm.io/gorm"
+)
+
+// RoleChangeRepository guarda la auditoría de cambios de rol
+type RoleChangeRepository interface {
+	Create(change *domain.RoleChange) error
+}
+
+type roleChangeRepository struct {
+	db *gorm.DB
+}
+
+// NewRoleChangeRepository crea una nueva instancia del repositorio
+func NewRoleChangeRepository(db *gorm.DB) RoleChangeRepository {
+	return &roleChangeRepository{db: db}
+}
+
+// Create inserta un registro de auditoría
+func (r *roleChangeRepository) Create(change *domain.RoleChange) error {
+	return r.db.Create(change).Error
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+	"users-api/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TokenRevocationRepository maneja la lista de revocación de tokens por usuario
+type TokenRevocationRepository interface {
+	Revoke(userID uint, reason string) error
+	GetRevokedAt(userID uint) (*time.Time, error)
+}
+
+type tokenRevocationRepository struct {
+	db *gorm.DB
+}
+
+// NewTokenRevocationRepository crea una nueva instancia del repositorio
+func NewTokenRevocationRepository(db *gorm.DB) TokenRevocationRepository {
+	return &tokenRevocationRepository{db: db}
+}
+
+// Revoke invalida todos los tokens emitidos hasta ahora para el usuario
+// Si ya existía una revocación se actualiza la fecha (INSERT ... ON DUPLICATE KEY UPDATE)
+func (r *tokenRevocationRepository) Revoke(userID uint, reason string) error {
+	// Los tokens guardan iat en milisegundos (ver utils.GenerateToken), igual que la columna datetime(3)
+	revocation := domain.TokenRevocation{
+		UserID:    userID,
+		RevokedAt: time.Now().Truncate(time.Millisecond),
+		Reason:    reason,
+	}
+	err := r.db.Clauses(clause.OnConflict{
+		UpdateAll: true,
+	}).Create(&revocation).Error
+	if err != nil {
+		return err
+	}
+
+	// Un token con iat igual a RevokedAt cuenta como revocado y al leerlo iat puede perder un milisegundo
+	// (ver utils.IsTokenRevoked): se esperan dos para que el token que se emita después de Revoke
+	// (ej: el nuevo del cambio de contraseña) sea válido
+	time.Sleep(time.Until(revocation.RevokedAt.Add(2 * time.Millisecond)))
+	return nil
+}
+
+// GetRevokedAt retorna desde cuándo están revocados los tokens del usuario
+// Retorna nil si el usuario no tiene revocaciones
+func (r *tokenRevocationRepository) GetRevokedAt(userID uint) (*time.Time, error) {
+	var revocation domain.TokenRevocation
+	err := r.db.First(&revocation, "user_id = ?", userID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &revocation.RevokedAt, nil
+}
@@ -2,11 +2,21 @@ package repositories
 
 import (
 	"errors"
+	"strings"
+	"time"
 	"users-api/domain"
 
 	"gorm.io/gorm"
 )
 
+// UserFilter son los filtros y la paginación del listado de usuarios
+type UserFilter struct {
+	Page     int    // Página (empieza en 1)
+	Limit    int    // Usuarios por página
+	UserType string // Filtra por tipo de usuario (vacío = todos)
+	Search   string // Busca en username, email, nombre y apellido (vacío = sin búsqueda)
+}
+
 // UserRepository define la interfaz del repositorio
 // Es como un "contrato" que dice qué operaciones debe tener
 type UserRepository interface {
@@ -16,7 +26,9 @@ type UserRepository interface {
 	GetByEmail(email string) (*domain.User, error)
 	Update(user *domain.User) error
 	Delete(id uint) error
-	GetAll() ([]domain.User, error)
+	List(filter UserFilter) ([]domain.User, int64, error)
+	CountByUserType(userType string) (int64, error)
+	CountCreatedByDay(from, to time.Time) (map[string]int64, error)
 }
 
 // userRepository es la implementación real del repositorio
@@ -85,16 +97,77 @@ func (r *userRepository) Update(user *domain.User) error {
 	return r.db.Save(user).Error
 }
 
-// Delete elimina un usuario por su ID
-// GORM hace DELETE FROM users WHERE id = ?
+// Delete elimina (soft delete) un usuario por su ID
+// Como User tiene DeletedAt, GORM hace UPDATE users SET deleted_at = NOW() WHERE id = ?
 func (r *userRepository) Delete(id uint) error {
 	return r.db.Delete(&domain.User{}, id).Error
 }
 
-// GetAll obtiene todos los usuarios
-// GORM hace SELECT * FROM users
-func (r *userRepository) GetAll() ([]domain.User, error) {
+// List obtiene una página de usuarios con los filtros indicados y el total sin paginar
+// GORM hace SELECT count(*) y SELECT * ... LIMIT ? OFFSET ? con los mismos WHERE
+func (r *userRepository) List(filter UserFilter) ([]domain.User, int64, error) {
+	query := r.db.Model(&domain.User{})
+
+	if filter.UserType != "" {
+		query = query.Where("user_type = ?", filter.UserType)
+	}
+	if filter.Search != "" {
+		like := "%" + escapeLike(filter.Search) + "%"
+		query = query.Where(
+			"username LIKE ? OR email LIKE ? OR first_name LIKE ? OR last_name LIKE ?",
+			like, like, like, like,
+		)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
 	var users []domain.User
-	err := r.db.Find(&users).Error
-	return users, err
+	err := query.Order("id ASC").
+		Offset((filter.Page - 1) * filter.Limit).
+		Limit(filter.Limit).
+		Find(&users).Error
+	return users, total, err
+}
+
+// escapeLike escapa los comodines de LIKE para buscar el texto literal
+func escapeLike(value string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(value)
+}
+
+// CountByUserType cuenta los usuarios activos de un tipo
+// GORM hace SELECT count(*) FROM users WHERE user_type = ? AND active = true
+func (r *userRepository) CountByUserType(userType string) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.User{}).Where("user_type = ? AND active = ?", userType, true).Count(&count).Error
+	return count, err
+}
+
+// dailyCount es una fila del GROUP BY por día de CountCreatedByDay
+type dailyCount struct {
+	Day   string
+	Count int64
+}
+
+// CountCreatedByDay cuenta los usuarios registrados por día (UTC, YYYY-MM-DD) en [from, to)
+// GORM hace SELECT DATE_FORMAT(created_at, '%Y-%m-%d') AS day, count(*) ... GROUP BY day
+// Los días sin registros no aparecen en el mapa
+func (r *userRepository) CountCreatedByDay(from, to time.Time) (map[string]int64, error) {
+	var rows []dailyCount
+	err := r.db.Model(&domain.User{}).
+		Select("DATE_FORMAT(created_at, '%Y-%m-%d') AS day, COUNT(*) AS count").
+		Where("created_at >= ? AND created_at < ?", from.UTC(), to.UTC()).
+		Group("day").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Day] = row.Count
+	}
+	return counts, nil
 }
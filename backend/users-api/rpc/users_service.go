@@ -0,0 +1,256 @@
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"users-api/dto"
+
+	"google.golang.org/grpc"
+)
+
+// UsersServiceName es el nombre del servicio gRPC de usuarios (lo usan properties-api y otros servicios internos)
+const UsersServiceName = "users.v1.Users"
+
+// ValidateUserRequest pide validar la existencia de un usuario
+type ValidateUserRequest struct {
+	UserID uint `json:"userId"`
+}
+
+// ValidateUserResponse indica si el usuario existe y si su cuenta está activa
+type ValidateUserResponse struct {
+	Exists bool `json:"exists"`
+	Active bool `json:"active"`
+}
+
+// GetUserRequest pide los datos públicos de un usuario
+type GetUserRequest struct {
+	UserID uint `json:"userId"`
+}
+
+// GetUserResponse contiene el usuario con el mismo formato que GET /users/:id
+type GetUserResponse struct {
+	User dto.UserResponse `json:"user"`
+}
+
+// GetUsersRequest pide un lote de usuarios por ID (lo usan los dataloaders de graphql-api)
+type GetUsersRequest struct {
+	UserIDs []uint `json:"userIds"`
+}
+
+// GetUsersResponse contiene los usuarios encontrados; los IDs inexistentes se omiten
+type GetUsersResponse struct {
+	Users []dto.UserResponse `json:"users"`
+}
+
+// GetSearchProfileRequest pide lo necesario para personalizar las búsquedas de un usuario (lo usa search-api)
+type GetSearchProfileRequest struct {
+	UserID uint `json:"userId"`
+}
+
+// GetSearchProfileResponse contiene los favoritos y las preferencias del usuario
+type GetSearchProfileResponse struct {
+	Favorites         []string `json:"favorites"`
+	PreferredCurrency string   `json:"preferredCurrency"`
+	Locale            string   `json:"locale"`
+}
+
+// GetHostProfileRequest pide el perfil público de un anfitrión (lo usa properties-api)
+type GetHostProfileRequest struct {
+	UserID uint `json:"userId"`
+}
+
+// GetHostProfileResponse contiene el perfil con el mismo formato que GET /users/:id/host-profile
+type GetHostProfileResponse struct {
+	Profile dto.HostProfileResponse `json:"profile"`
+}
+
+// VerifyAPIKeyRequest pide validar la API key con la que llamó un servicio interno (lo usa properties-api)
+type VerifyAPIKeyRequest struct {
+	Key string `json:"key"`
+}
+
+// VerifyAPIKeyResponse indica si la key es válida y, si lo es, de qué servicio es y qué puede llamar
+type VerifyAPIKeyResponse struct {
+	Valid  bool     `json:"valid"`
+	Name   string   `json:"name,omitempty"`
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// VerifyTokenRequest pide validar el JWT de un usuario recibido por otro servicio (properties-api, search-api)
+type VerifyTokenRequest struct {
+	Token string `json:"token"`
+}
+
+// VerifyTokenResponse indica si el token es válido y no fue revocado y, si lo es, de qué usuario es
+type VerifyTokenResponse struct {
+	Valid    bool   `json:"valid"`
+	UserID   uint   `json:"userId,omitempty"`
+	Username string `json:"username,omitempty"`
+	UserType string `json:"userType,omitempty"`
+}
+
+// CountSignupsRequest pide la cantidad de registros por día UTC en [From, To) (lo usa properties-api)
+type CountSignupsRequest struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// CountSignupsResponse tiene los registros por día (YYYY-MM-DD); los días sin registros se omiten
+type CountSignupsResponse struct {
+	Days map[string]int64 `json:"days"`
+}
+
+// UsersServer es la interfaz que implementa el servidor gRPC de usuarios
+type UsersServer interface {
+	// ValidateUser indica si el usuario existe (un usuario inexistente no es un error)
+	ValidateUser(ctx context.Context, request *ValidateUserRequest) (*ValidateUserResponse, error)
+	// GetUser obtiene un usuario; retorna codes.NotFound si no existe
+	GetUser(ctx context.Context, request *GetUserRequest) (*GetUserResponse, error)
+	// GetUsers obtiene un lote de usuarios en una sola llamada
+	GetUsers(ctx context.Context, request *GetUsersRequest) (*GetUsersResponse, error)
+	// GetSearchProfile obtiene favoritos y preferencias; retorna codes.NotFound si el usuario no existe
+	GetSearchProfile(ctx context.Context, request *GetSearchProfileRequest) (*GetSearchProfileResponse, error)
+	// GetHostProfile obtiene el perfil público; retorna codes.NotFound si no existe o está desactivado
+	GetHostProfile(ctx context.Context, request *GetHostProfileRequest) (*GetHostProfileResponse, error)
+	// VerifyAPIKey valida una API key de servicio (una key inválida no es un error, Valid es false)
+	VerifyAPIKey(ctx context.Context, request *VerifyAPIKeyRequest) (*VerifyAPIKeyResponse, error)
+	// VerifyToken valida el JWT de un usuario incluida la revocación (un token inválido no es un error, Valid es false)
+	VerifyToken(ctx context.Context, request *VerifyTokenRequest) (*VerifyTokenResponse, error)
+	// CountSignups cuenta los registros por día; retorna codes.InvalidArgument si el rango es inválido
+	CountSignups(ctx context.Context, request *CountSignupsRequest) (*CountSignupsResponse, error)
+}
+
+// RegisterUsersServer registra la implementación del servicio de usuarios en el servidor gRPC
+func RegisterUsersServer(server *grpc.Server, impl UsersServer) {
+	server.RegisterService(&usersServiceDesc, impl)
+}
+
+// usersServiceDesc describe los métodos del servicio (equivalente a lo que generaría protoc)
+var usersServiceDesc = grpc.ServiceDesc{
+	ServiceName: UsersServiceName,
+	HandlerType: (*UsersServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "ValidateUser", Handler: validateUserHandler},
+		{MethodName: "GetUser", Handler: getUserHandler},
+		{MethodName: "GetUsers", Handler: getUsersHandler},
+		{MethodName: "GetSearchProfile", Handler: getSearchProfileHandler},
+		{MethodName: "GetHostProfile", Handler: getHostProfileHandler},
+		{MethodName: "VerifyAPIKey", Handler: verifyAPIKeyHandler},
+		{MethodName: "VerifyToken", Handler: verifyTokenHandler},
+		{MethodName: "CountSignups", Handler: countSignupsHandler},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+func validateUserHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	request := new(ValidateUserRequest)
+	if err := dec(request); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServer).ValidateUser(ctx, request)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + UsersServiceName + "/ValidateUser"}
+	return interceptor(ctx, request, info, func(ctx context.Context, req any) (any, error) {
+		return srv.(UsersServer).ValidateUser(ctx, req.(*ValidateUserRequest))
+	})
+}
+
+func getUserHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	request := new(GetUserRequest)
+	if err := dec(request); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServer).GetUser(ctx, request)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + UsersServiceName + "/GetUser"}
+	return interceptor(ctx, request, info, func(ctx context.Context, req any) (any, error) {
+		return srv.(UsersServer).GetUser(ctx, req.(*GetUserRequest))
+	})
+}
+
+func getUsersHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	request := new(GetUsersRequest)
+	if err := dec(request); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServer).GetUsers(ctx, request)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + UsersServiceName + "/GetUsers"}
+	return interceptor(ctx, request, info, func(ctx context.Context, req any) (any, error) {
+		return srv.(UsersServer).GetUsers(ctx, req.(*GetUsersRequest))
+	})
+}
+
+func getSearchProfileHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	request := new(GetSearchProfileRequest)
+	if err := dec(request); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServer).GetSearchProfile(ctx, request)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + UsersServiceName + "/GetSearchProfile"}
+	return interceptor(ctx, request, info, func(ctx context.Context, req any) (any, error) {
+		return srv.(UsersServer).GetSearchProfile(ctx, req.(*GetSearchProfileRequest))
+	})
+}
+
+func getHostProfileHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	request := new(GetHostProfileRequest)
+	if err := dec(request); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServer).GetHostProfile(ctx, request)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + UsersServiceName + "/GetHostProfile"}
+	return interceptor(ctx, request, info, func(ctx context.Context, req any) (any, error) {
+		return srv.(UsersServer).GetHostProfile(ctx, req.(*GetHostProfileRequest))
+	})
+}
+
+func verifyAPIKeyHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	request := new(VerifyAPIKeyRequest)
+	if err := dec(request); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServer).VerifyAPIKey(ctx, request)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + UsersServiceName + "/VerifyAPIKey"}
+	return interceptor(ctx, request, info, func(ctx context.Context, req any) (any, error) {
+		return srv.(UsersServer).VerifyAPIKey(ctx, req.(*VerifyAPIKeyRequest))
+	})
+}
+
+func verifyTokenHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	request := new(VerifyTokenRequest)
+	if err := dec(request); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServer).VerifyToken(ctx, request)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + UsersServiceName + "/VerifyToken"}
+	return interceptor(ctx, request, info, func(ctx context.Context, req any) (any, error) {
+		return srv.(UsersServer).VerifyToken(ctx, req.(*VerifyTokenRequest))
+	})
+}
+
+func countSignupsHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	request := new(CountSignupsRequest)
+	if err := dec(request); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServer).CountSignups(ctx, request)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + UsersServiceName + "/CountSignups"}
+	return interceptor(ctx, request, info, func(ctx context.Context, req any) (any, error) {
+		return srv.(UsersServer).CountSignups(ctx, req.(*CountSignupsRequest))
+	})
+}
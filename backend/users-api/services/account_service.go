@@ -0,0 +1,179 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"users-api/clients"
+	"users-api/domain"
+	"users-api/dto"
+	"users-api/repositories"
+	"users-api/utils"
+)
+
+var (
+	// ErrUserInactive se retorna cuando una cuenta desactivada intenta iniciar sesión
+	ErrUserInactive = errors.New("la cuenta está desactivada")
+
+	// ErrForbidden se retorna cuando el usuario no puede operar sobre otra cuenta
+	ErrForbidden = errors.New("no tenés permiso para realizar esta acción")
+
+	// ErrWrongPassword se retorna cuando la contraseña actual no coincide al cambiarla
+	ErrWrongPassword = errors.New("la contraseña actual es incorrecta")
+)
+
+// AccountService maneja el estado de las cuentas (activación/desactivación)
+type AccountService interface {
+	// DeactivateUser desactiva una cuenta: bloquea el login, revoca sus tokens y
+	// publica "user.deactivated" para que se oculten sus propiedades de la búsqueda.
+	// Solo puede hacerlo el propio usuario o un admin
+	DeactivateUser(actorID uint, actorType string, userID uint) (dto.UserResponse, error)
+
+	// ChangePassword cambia la contraseña del propio usuario verificando la actual y aplicando la política.
+	// Revoca todos sus tokens (las otras sesiones se cierran) y retorna uno nuevo para la sesión actual
+	ChangePassword(userID uint, req dto.ChangePasswordRequest) (dto.LoginResponse, error)
+}
+
+type accountService struct {
+	repo        repositories.UserRepository
+	revocations repositories.TokenRevocationRepository
+	events      clients.UserEventPublisher
+	audit       AuditService
+	passwords   PasswordPolicy
+}
+
+// NewAccountService crea el servicio de cuentas
+// events puede ser nil: en ese caso los eventos solo se loguean
+func NewAccountService(
+	repo repositories.UserRepository,
+	revocations repositories.TokenRevocationRepository,
+	events clients.UserEventPublisher,
+	audit AuditService,
+	passwords PasswordPolicy,
+) AccountService {
+	return &accountService{
+		repo:        repo,
+		revocations: revocations,
+		events:      events,
+		audit:       audit,
+		passwords:   passwords,
+	}
+}
+
+// DeactivateUser desactiva la cuenta de un usuario
+func (s *accountService) DeactivateUser(actorID uint, actorType string, userID uint) (dto.UserResponse, error) {
+	if !canManageAccount(actorID, actorType, userID) {
+		return dto.UserResponse{}, ErrForbidden
+	}
+
+	user, err := s.repo.GetByID(userID)
+	if err != nil || user == nil {
+		return dto.UserResponse{}, ErrUserNotFound
+	}
+
+	// Desactivar dos veces no es un error
+	if !user.Active {
+		return toUserResponse(*user), nil
+	}
+
+	// Nunca dejar el sistema sin administradores activos
+	if user.UserType == string(domain.UserTypeAdmin) {
+		admins, err := s.repo.CountByUserType(string(domain.UserTypeAdmin))
+		if err != nil {
+			return dto.UserResponse{}, err
+		}
+		if admins <= 1 {
+			return dto.UserResponse{}, ErrLastAdmin
+		}
+	}
+
+	before := userAuditState(*user)
+	user.Active = false
+	if err := s.repo.Update(user); err != nil {
+		return dto.UserResponse{}, err
+	}
+	s.audit.Record(actorID, AuditActionUserDeactivate, auditEntityUser, auditUserID(userID), before, userAuditState(*user))
+	log.Printf("🚫 Usuario %d desactivado por usuario %d", userID, actorID)
+
+	// Los tokens ya emitidos dejan de servir
+	if err := s.revocations.Revoke(userID, "cuenta desactivada"); err != nil {
+		return dto.UserResponse{}, err
+	}
+
+	// Un fallo al publicar no revierte la desactivación
+	s.publish(clients.UserEvent{
+		Type:       clients.UserEventDeactivated,
+		UserID:     userID,
+		OccurredAt: time.Now().UTC(),
+	})
+
+	return toUserResponse(*user), nil
+}
+
+// ChangePassword cambia la contraseña del usuario y cierra sus otras sesiones
+func (s *accountService) ChangePassword(userID uint, req dto.ChangePasswordRequest) (dto.LoginResponse, error) {
+	user, err := s.repo.GetByID(userID)
+	if err != nil || user == nil {
+		return dto.LoginResponse{}, ErrUserNotFound
+	}
+	if !user.Active {
+		return dto.LoginResponse{}, ErrUserInactive
+	}
+
+	// Un token robado no alcanza para cambiar la contraseña: hay que conocer la actual
+	if !utils.CheckPasswordHash(req.CurrentPassword, user.Password) {
+		return dto.LoginResponse{}, ErrWrongPassword
+	}
+	if req.NewPassword == req.CurrentPassword {
+		return dto.LoginResponse{}, &PasswordPolicyError{Violations: []dto.FieldError{{
+			Field: "newPassword", Code: PasswordRuleReused, Message: "debe ser distinta de la contraseña actual",
+		}}}
+	}
+	if err := s.passwords.Validate(req.NewPassword); err != nil {
+		return dto.LoginResponse{}, withPasswordField(err, "newPassword")
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return dto.LoginResponse{}, errors.New("error hasheando nueva contraseña")
+	}
+
+	before := userAuditState(*user)
+	user.Password = hashedPassword
+	if err := s.repo.Update(user); err != nil {
+		return dto.LoginResponse{}, err
+	}
+	s.audit.Record(userID, AuditActionPasswordChange, auditEntityUser, auditUserID(userID), before, userAuditState(*user))
+	log.Printf("🔑 Usuario %d cambió su contraseña, se cierran sus otras sesiones", userID)
+
+	// Se revocan todos los tokens emitidos hasta ahora, incluido el de este request;
+	// el token nuevo se emite después de la revocación, así que sigue siendo válido
+	if err := s.revocations.Revoke(userID, "cambio de contraseña"); err != nil {
+		return dto.LoginResponse{}, err
+	}
+	token, err := utils.GenerateToken(user.ID, user.Username, user.UserType)
+	if err != nil {
+		return dto.LoginResponse{}, errors.New("error generando token")
+	}
+
+	return dto.LoginResponse{Token: token, User: toUserResponse(*user)}, nil
+}
+
+// publish publica un evento de usuario, logueando si no hay publisher o si falla
+func (s *accountService) publish(event clients.UserEvent) {
+	if s.events == nil {
+		log.Printf("⚠️ Evento '%s' del usuario %d no publicado: RabbitMQ no disponible", event.Type, event.UserID)
+		return
+	}
+	if err := s.events.PublishUserEvent(event); err != nil {
+		log.Printf("⚠️ Error publicando evento '%s' del usuario %d: %v", event.Type, event.UserID, err)
+		return
+	}
+	log.Printf("📤 Evento '%s' publicado para usuario %d", event.Type, event.UserID)
+}
+
+// canManageAccount indica si el actor puede operar sobre la cuenta (es el propio usuario o un admin)
+func canManageAccount(actorID uint, actorType string, userID uint) bool {
+	return actorID == userID || actorType == string(domain.UserTypeAdmin)
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"log"
+	"sync"
+
+	"users-api/domain"
+	"users-api/dto"
+	"users-api/repositories"
+	"users-api/utils"
+)
+
+// AdminBootstrapConfig contiene las credenciales del admin inicial leídas del entorno
+type AdminBootstrapConfig struct {
+	Username   string
+	Email      string
+	Password   string
+	SetupToken string
+}
+
+// AdminBootstrapService provisiona el primer administrador del sistema
+// Se usa porque la API no permite crear admins de ninguna otra forma
+type AdminBootstrapService interface {
+	// EnsureInitialAdmin crea el admin desde el entorno si todavía no existe ninguno
+	// Si no hay credenciales configuradas, habilita el flujo con token de setup
+	EnsureInitialAdmin() error
+
+	// SetupAdmin crea el primer admin usando el token de setup de un solo uso
+	SetupAdmin(token string, req dto.CreateUserRequest) (dto.UserResponse, error)
+}
+
+type adminBootstrapService struct {
+	repo      repositories.UserRepository
+	audit     AuditService
+	passwords PasswordPolicy
+	cfg       AdminBootstrapConfig
+
+	mu         sync.Mutex
+	setupToken string
+}
+
+func NewAdminBootstrapService(repo repositories.UserRepository, audit AuditService, passwords PasswordPolicy, cfg AdminBootstrapConfig) AdminBootstrapService {
+	return &adminBootstrapService{
+		repo:      repo,
+		audit:     audit,
+		passwords: passwords,
+		cfg:       cfg,
+	}
+}
+
+// EnsureInitialAdmin crea el admin inicial si no existe ningún usuario admin
+func (s *adminBootstrapService) EnsureInitialAdmin() error {
+	hasAdmin, err := s.hasAdmin()
+	if err != nil {
+		return err
+	}
+	if hasAdmin {
+		log.Println("✅ Ya existe al menos un administrador, se omite el bootstrap")
+		return nil
+	}
+
+	// Opción 1: credenciales desde variables de entorno
+	if s.cfg.Username != "" && s.cfg.Email != "" && s.cfg.Password != "" {
+		_, err := s.createAdmin(dto.CreateUserRequest{
+			Username:  s.cfg.Username,
+			Email:     s.cfg.Email,
+			Password:  s.cfg.Password,
+			FirstName: "Admin",
+			LastName:  "Admin",
+		})
+		if err != nil {
+			return err
+		}
+		log.Printf("✅ Administrador inicial '%s' creado desde el entorno", s.cfg.Username)
+		return nil
+	}
+
+	// Opción 2: token de setup de un solo uso
+	token := s.cfg.SetupToken
+	if token == "" {
+		token, err = generateSetupToken()
+		if err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	s.setupToken = token
+	s.mu.Unlock()
+
+	log.Println("⚠️ No existe ningún administrador. Creá uno con POST /setup/admin")
+	if s.cfg.SetupToken == "" {
+		log.Printf("🔑 Token de setup (un solo uso): %s", token)
+	}
+	return nil
+}
+
+// SetupAdmin crea el primer admin si el token es válido y todavía no hay admins
+func (s *adminBootstrapService) SetupAdmin(token string, req dto.CreateUserRequest) (dto.UserResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.setupToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.setupToken)) != 1 {
+		return dto.UserResponse{}, errors.New("token de setup inválido")
+	}
+
+	hasAdmin, err := s.hasAdmin()
+	if err != nil {
+		return dto.UserResponse{}, err
+	}
+	if hasAdmin {
+		s.setupToken = ""
+		return dto.UserResponse{}, errors.New("ya existe un administrador")
+	}
+
+	user, err := s.createAdmin(req)
+	if err != nil {
+		return dto.UserResponse{}, err
+	}
+
+	// El token se consume al crear el admin
+	s.setupToken = ""
+	log.Printf("✅ Administrador inicial '%s' creado con token de setup", user.Username)
+
+	return user, nil
+}
+
+// hasAdmin indica si existe al menos un usuario admin
+func (s *adminBootstrapService) hasAdmin() (bool, error) {
+	count, err := s.repo.CountByUserType(string(domain.UserTypeAdmin))
+	if err != nil {
+		return false, errors.New("error verificando administradores existentes")
+	}
+	return count > 0, nil
+}
+
+// createAdmin crea un usuario con user_type admin
+func (s *adminBootstrapService) createAdmin(req dto.CreateUserRequest) (dto.UserResponse, error) {
+	if existing, _ := s.repo.GetByUsername(req.Username); existing != nil {
+		return dto.UserResponse{}, errors.New("el username ya existe")
+	}
+	if existing, _ := s.repo.GetByEmail(req.Email); existing != nil {
+		return dto.UserResponse{}, errors.New("el email ya existe")
+	}
+
+	// El admin inicial también cumple la política (incluso el que viene de ADMIN_PASSWORD)
+	if err := s.passwords.Validate(req.Password); err != nil {
+		return dto.UserResponse{}, err
+	}
+
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return dto.UserResponse{}, errors.New("error hasheando contraseña")
+	}
+
+	user := domain.User{
+		Username:  req.Username,
+		Email:     req.Email,
+		Password:  hashedPassword,
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		UserType:  string(domain.UserTypeAdmin),
+		Active:    true,
+	}
+	if err := s.repo.Create(&user); err != nil {
+		return dto.UserResponse{}, err
+	}
+
+	// El admin inicial lo crea el sistema (actor 0)
+	s.audit.Record(0, AuditActionUserCreate, auditEntityUser, auditUserID(user.ID), nil, userAuditState(user))
+
+	return toUserResponse(user), nil
+}
+
+// generateSetupToken genera un token aleatorio de 32 bytes en hexadecimal
+func generateSetupToken() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", errors.New("error generando token de setup")
+	}
+	return hex.EncodeToString(bytes), nil
+}
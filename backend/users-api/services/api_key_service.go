@@ -0,0 +1,249 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"users-api/domain"
+	"users-api/dto"
+	"users-api/repositories"
+)
+
+const (
+	// apiKeyPrefix identifica a simple vista una API key de Spotly (ej: en un escáner de secretos)
+	// Formato completo: spk_<prefijo>_<secreto>
+	apiKeyPrefix = "spk_"
+
+	// apiKeyTouchInterval evita escribir last_used_at en cada llamada interna
+	apiKeyTouchInterval = time.Minute
+)
+
+var (
+	// ErrInvalidAPIKey se retorna cuando la key no existe, no coincide, está revocada o vencida
+	ErrInvalidAPIKey = errors.New("API key inválida")
+
+	// ErrAPIKeyNotFound se retorna cuando no existe la key con el ID indicado
+	ErrAPIKeyNotFound = errors.New("API key no encontrada")
+
+	// ErrInvalidAPIKeyScopes se retorna cuando se pide un scope desconocido
+	ErrInvalidAPIKeyScopes = fmt.Errorf("scopes inválidos, los permitidos son: %s", strings.Join(domain.APIKeyScopes, ", "))
+)
+
+// APIKeyService emite y valida las credenciales de máquina de los servicios internos
+type APIKeyService interface {
+	// Issue emite una key nueva; el valor completo solo viene en esta respuesta
+	Issue(actorID uint, request dto.CreateAPIKeyRequest) (dto.CreatedAPIKeyResponse, error)
+
+	// List lista las keys emitidas (sin el valor)
+	List() ([]dto.APIKeyResponse, error)
+
+	// Revoke revoca una key; las llamadas que la usen se rechazan desde ese momento
+	Revoke(actorID, id uint) (dto.APIKeyResponse, error)
+
+	// Verify valida una key; ok es false si no es válida (no existe, no coincide, revocada o vencida)
+	// err solo se retorna si no se pudo consultar la base
+	Verify(key string) (identity dto.APIKeyIdentity, ok bool, err error)
+}
+
+type apiKeyService struct {
+	repo  repositories.APIKeyRepository
+	audit AuditService
+}
+
+// NewAPIKeyService crea el servicio de API keys
+func NewAPIKeyService(repo repositories.APIKeyRepository, audit AuditService) APIKeyService {
+	return &apiKeyService{repo: repo, audit: audit}
+}
+
+// Issue emite una key nueva para un servicio interno
+func (s *apiKeyService) Issue(actorID uint, request dto.CreateAPIKeyRequest) (dto.CreatedAPIKeyResponse, error) {
+	scopes, err := normalizeAPIKeyScopes(request.Scopes)
+	if err != nil {
+		return dto.CreatedAPIKeyResponse{}, err
+	}
+
+	prefix, secret, err := generateAPIKeyParts()
+	if err != nil {
+		return dto.CreatedAPIKeyResponse{}, err
+	}
+	rawKey := apiKeyPrefix + prefix + "_" + secret
+
+	key := domain.APIKey{
+		Name:      strings.TrimSpace(request.Name),
+		Prefix:    prefix,
+		KeyHash:   hashAPIKey(rawKey),
+		Scopes:    strings.Join(scopes, ","),
+		CreatedBy: actorID,
+	}
+	if request.ExpiresInDays > 0 {
+		expiresAt := time.Now().UTC().AddDate(0, 0, request.ExpiresInDays)
+		key.ExpiresAt = &expiresAt
+	}
+	if err := s.repo.Create(&key); err != nil {
+		return dto.CreatedAPIKeyResponse{}, err
+	}
+
+	s.audit.Record(actorID, AuditActionAPIKeyIssue, auditEntityAPIKey, strconv.FormatUint(uint64(key.ID), 10), nil, apiKeyAuditState(key))
+	log.Printf("🔑 API key %s emitida para '%s' (%s) por usuario %d", key.Prefix, key.Name, key.Scopes, actorID)
+	return dto.CreatedAPIKeyResponse{APIKeyResponse: toAPIKeyResponse(key), Key: rawKey}, nil
+}
+
+// List lista las keys emitidas
+func (s *apiKeyService) List() ([]dto.APIKeyResponse, error) {
+	keys, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	response := make([]dto.APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		response = append(response, toAPIKeyResponse(key))
+	}
+	return response, nil
+}
+
+// Revoke revoca una key (revocar dos veces no es un error)
+func (s *apiKeyService) Revoke(actorID, id uint) (dto.APIKeyResponse, error) {
+	key, err := s.repo.GetByID(id)
+	if err != nil {
+		return dto.APIKeyResponse{}, err
+	}
+	if key == nil {
+		return dto.APIKeyResponse{}, ErrAPIKeyNotFound
+	}
+	if key.RevokedAt != nil {
+		return toAPIKeyResponse(*key), nil
+	}
+
+	before := apiKeyAuditState(*key)
+	now := time.Now().UTC()
+	if err := s.repo.Revoke(id, now); err != nil {
+		return dto.APIKeyResponse{}, err
+	}
+	key.RevokedAt = &now
+
+	s.audit.Record(actorID, AuditActionAPIKeyRevoke, auditEntityAPIKey, strconv.FormatUint(uint64(id), 10), before, apiKeyAuditState(*key))
+	log.Printf("🔒 API key %s de '%s' revocada por usuario %d", key.Prefix, key.Name, actorID)
+	return toAPIKeyResponse(*key), nil
+}
+
+// Verify valida una key comparando su hash en tiempo constante
+func (s *apiKeyService) Verify(rawKey string) (dto.APIKeyIdentity, bool, error) {
+	prefix, ok := parseAPIKeyPrefix(rawKey)
+	if !ok {
+		return dto.APIKeyIdentity{}, false, nil
+	}
+
+	key, err := s.repo.GetByPrefix(prefix)
+	if err != nil {
+		return dto.APIKeyIdentity{}, false, err
+	}
+	if key == nil || subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(hashAPIKey(rawKey))) != 1 {
+		return dto.APIKeyIdentity{}, false, nil
+	}
+
+	now := time.Now().UTC()
+	if !key.IsUsable(now) {
+		return dto.APIKeyIdentity{}, false, nil
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.repo.TouchLastUsed(key.ID, now); err != nil {
+			log.Printf("⚠️ Error registrando el uso de la API key %s: %v", key.Prefix, err)
+		}
+	}
+
+	return dto.APIKeyIdentity{KeyID: key.ID, Name: key.Name, Scopes: key.ScopeList()}, true, nil
+}
+
+// normalizeAPIKeyScopes valida los scopes pedidos y los retorna sin repetir
+func normalizeAPIKeyScopes(requested []string) ([]string, error) {
+	known := make(map[string]bool, len(domain.APIKeyScopes))
+	for _, scope := range domain.APIKeyScopes {
+		known[scope] = true
+	}
+
+	scopes := make([]string, 0, len(requested))
+	seen := make(map[string]bool, len(requested))
+	for _, scope := range requested {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if !known[scope] {
+			return nil, ErrInvalidAPIKeyScopes
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		return nil, ErrInvalidAPIKeyScopes
+	}
+	return scopes, nil
+}
+
+// generateAPIKeyParts genera el prefijo visible (8 caracteres hex) y el secreto (256 bits)
+func generateAPIKeyParts() (string, string, error) {
+	prefix := make([]byte, 4)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(prefix); err != nil {
+		return "", "", fmt.Errorf("error generando API key: %w", err)
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", fmt.Errorf("error generando API key: %w", err)
+	}
+	return hex.EncodeToString(prefix), base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// parseAPIKeyPrefix extrae el prefijo de una key con formato spk_<prefijo>_<secreto>
+func parseAPIKeyPrefix(rawKey string) (string, bool) {
+	rest, ok := strings.CutPrefix(rawKey, apiKeyPrefix)
+	if !ok {
+		return "", false
+	}
+	prefix, secret, ok := strings.Cut(rest, "_")
+	if !ok || len(prefix) != 8 || secret == "" {
+		return "", false
+	}
+	return prefix, true
+}
+
+// hashAPIKey calcula el hash que se guarda de la key
+// Un hash rápido alcanza (a diferencia de las contraseñas) porque la key tiene 256 bits aleatorios
+func hashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// toAPIKeyResponse convierte una key al DTO de respuesta
+func toAPIKeyResponse(key domain.APIKey) dto.APIKeyResponse {
+	return dto.APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.ScopeList(),
+		CreatedBy:  key.CreatedBy,
+		CreatedAt:  key.CreatedAt,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+	}
+}
+
+// apiKeyAuditState es el estado de la key que se compara en la auditoría (sin el hash)
+func apiKeyAuditState(key domain.APIKey) map[string]interface{} {
+	return map[string]interface{}{
+		"name":      key.Name,
+		"prefix":    key.Prefix,
+		"scopes":    key.Scopes,
+		"expiresAt": key.ExpiresAt,
+		"revokedAt": key.RevokedAt,
+	}
+}
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"reflect"
+	"strconv"
+	"time"
+
+	"users-api/domain"
+	"users-api/dto"
+	"users-api/repositories"
+)
+
+// Acciones registradas en el log de auditoría
+const (
+	AuditActionUserCreate     = "user.create"
+	AuditActionUserUpdate     = "user.update"
+	AuditActionUserDelete     = "user.delete"
+	AuditActionUserRoleChange = "user.role_change"
+	AuditActionUserDeactivate = "user.deactivate"
+	AuditActionUserErase      = "user.erase"
+	AuditActionPasswordChange = "user.password_change"
+	AuditActionProfileUpdate  = "user.profile_update"
+	AuditActionAPIKeyIssue    = "api_key.issue"
+	AuditActionAPIKeyRevoke   = "api_key.revoke"
+)
+
+// auditEntityUser es el tipo de entidad de los registros sobre usuarios
+const auditEntityUser = "user"
+
+// auditEntityAPIKey es el tipo de entidad de los registros sobre API keys de servicios internos
+const auditEntityAPIKey = "api_key"
+
+// defaultAuditPageSize es la cantidad de registros por página si no se indica limit
+const defaultAuditPageSize = 50
+
+// auditRedactedValue reemplaza el valor de los campos sensibles en el diff
+const auditRedactedValue = "[redacted]"
+
+// auditRedactedFields son los campos de los que solo se registra que cambiaron, nunca su valor
+// Incluye los datos personales: el log es append-only y no debe conservar datos borrados (GDPR)
+var auditRedactedFields = map[string]bool{
+	"password":  true,
+	"username":  true,
+	"email":     true,
+	"firstName": true,
+	"lastName":  true,
+
+	"phone":                 true,
+	"emergencyContactName":  true,
+	"emergencyContactPhone": true,
+}
+
+// ErrInvalidDateRange se retorna cuando from/to no son fechas válidas o from no es anterior a to
+var ErrInvalidDateRange = errors.New("rango de fechas inválido, usar YYYY-MM-DD o RFC3339 con from anterior a to")
+
+// AuditService registra y consulta el log de auditoría de las operaciones que modifican datos
+type AuditService interface {
+	// Record guarda el diff entre before y after de una entidad
+	// before es nil en una creación y after es nil en una eliminación
+	// Un error al guardar solo se loguea: nunca hace fallar la operación auditada
+	Record(actorID uint, action, entityType, entityID string, before, after interface{})
+
+	// List consulta el log filtrando por usuario y rango de fechas (solo admin)
+	List(query dto.AuditQuery) (dto.AuditListResponse, error)
+}
+
+type auditService struct {
+	repo repositories.AuditRepository
+}
+
+func NewAuditService(repo repositories.AuditRepository) AuditService {
+	return &auditService{repo: repo}
+}
+
+// Record guarda el diff entre before y after de una entidad
+func (s *auditService) Record(actorID uint, action, entityType, entityID string, before, after interface{}) {
+	changes, err := json.Marshal(diffAuditStates(before, after))
+	if err != nil {
+		log.Printf("⚠️ Error serializando auditoría de %s %s/%s: %v", action, entityType, entityID, err)
+		return
+	}
+
+	entry := domain.AuditEntry{
+		ActorID:    actorID,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Changes:    string(changes),
+	}
+	if err := s.repo.Create(&entry); err != nil {
+		log.Printf("⚠️ Error guardando auditoría de %s %s/%s: %v", action, entityType, entityID, err)
+	}
+}
+
+// List consulta el log filtrando por usuario y rango de fechas
+func (s *auditService) List(query dto.AuditQuery) (dto.AuditListResponse, error) {
+	from, err := parseAuditTime(query.From)
+	if err != nil {
+		return dto.AuditListResponse{}, ErrInvalidDateRange
+	}
+	to, err := parseAuditTime(query.To)
+	if err != nil {
+		return dto.AuditListResponse{}, ErrInvalidDateRange
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return dto.AuditListResponse{}, ErrInvalidDateRange
+	}
+
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.Limit < 1 {
+		query.Limit = defaultAuditPageSize
+	}
+
+	entries, total, err := s.repo.Find(repositories.AuditFilter{
+		ActorID: query.UserID,
+		From:    from,
+		To:      to,
+		Page:    query.Page,
+		Limit:   query.Limit,
+	})
+	if err != nil {
+		return dto.AuditListResponse{}, err
+	}
+
+	responses := make([]dto.AuditEntryResponse, len(entries))
+	for i, entry := range entries {
+		responses[i] = toAuditEntryResponse(entry)
+	}
+
+	return dto.AuditListResponse{
+		Entries:    responses,
+		Page:       query.Page,
+		Limit:      query.Limit,
+		Total:      total,
+		TotalPages: int((total + int64(query.Limit) - 1) / int64(query.Limit)),
+	}, nil
+}
+
+// parseAuditTime parsea una fecha (YYYY-MM-DD, UTC) o un timestamp RFC3339; vacío es sin límite
+func parseAuditTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	return t.UTC(), err
+}
+
+// toAuditEntryResponse convierte un domain.AuditEntry a dto.AuditEntryResponse
+func toAuditEntryResponse(entry domain.AuditEntry) dto.AuditEntryResponse {
+	changes := make(map[string]dto.AuditChange)
+	if entry.Changes != "" {
+		if err := json.Unmarshal([]byte(entry.Changes), &changes); err != nil {
+			log.Printf("⚠️ Auditoría %d con cambios inválidos: %v", entry.ID, err)
+		}
+	}
+
+	return dto.AuditEntryResponse{
+		ID:         entry.ID,
+		ActorID:    entry.ActorID,
+		Action:     entry.Action,
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		Changes:    changes,
+		CreatedAt:  entry.CreatedAt,
+	}
+}
+
+// diffAuditStates compara los estados (serializados como JSON) campo por campo
+// y retorna solo los campos que cambiaron, con los sensibles redactados
+func diffAuditStates(before, after interface{}) map[string]dto.AuditChange {
+	beforeFields := auditFields(before)
+	afterFields := auditFields(after)
+
+	changes := make(map[string]dto.AuditChange)
+	for field, oldValue := range beforeFields {
+		if newValue, ok := afterFields[field]; !ok || !reflect.DeepEqual(oldValue, newValue) {
+			changes[field] = dto.AuditChange{Before: oldValue, After: afterFields[field]}
+		}
+	}
+	for field, newValue := range afterFields {
+		if _, ok := beforeFields[field]; !ok {
+			changes[field] = dto.AuditChange{Before: nil, After: newValue}
+		}
+	}
+
+	for field, change := range changes {
+		if !auditRedactedFields[field] {
+			continue
+		}
+		if change.Before != nil {
+			change.Before = auditRedactedValue
+		}
+		if change.After != nil {
+			change.After = auditRedactedValue
+		}
+		changes[field] = change
+	}
+
+	return changes
+}
+
+// auditFields convierte un estado a un mapa campo -> valor usando su representación JSON
+func auditFields(state interface{}) map[string]interface{} {
+	fields := make(map[string]interface{})
+	if state == nil || reflect.ValueOf(state).Kind() == reflect.Ptr && reflect.ValueOf(state).IsNil() {
+		return fields
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		log.Printf("⚠️ Error serializando estado para auditoría: %v", err)
+		return fields
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		log.Printf("⚠️ Error deserializando estado para auditoría: %v", err)
+	}
+	return fields
+}
+
+// userAuditState es el estado de un usuario que se compara en la auditoría
+// Incluye el hash del password y los datos personales solo para detectar cambios: sus valores se redactan
+func userAuditState(user domain.User) map[string]interface{} {
+	return map[string]interface{}{
+		"username":  user.Username,
+		"email":     user.Email,
+		"firstName": user.FirstName,
+		"lastName":  user.LastName,
+		"userType":  user.UserType,
+		"active":    user.Active,
+		"password":  user.Password,
+	}
+}
+
+// auditUserID formatea el ID de un usuario como ID de entidad de auditoría
+func auditUserID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"users-api/dto"
+)
+
+// HealthCheck es el chequeo de una dependencia externa (base de datos, broker, caché)
+// Si Optional es true, una falla degrada el estado pero no saca al servicio de readiness
+type HealthCheck struct {
+	Name     string
+	Check    func(ctx context.Context) error
+	Optional bool
+}
+
+// HealthService reporta el estado del servicio para los probes de Kubernetes
+type HealthService interface {
+	// Live indica que el proceso está vivo; no chequea dependencias
+	Live() dto.HealthResponse
+
+	// Ready chequea todas las dependencias en paralelo y retorna si el servicio puede recibir tráfico
+	Ready(ctx context.Context) (dto.HealthResponse, bool)
+}
+
+// healthService es la implementación concreta de HealthService
+type healthService struct {
+	service string
+	timeout time.Duration
+	checks  []HealthCheck
+}
+
+// NewHealthService crea el servicio de health checks
+// timeout limita cada chequeo para que un probe nunca quede colgado
+func NewHealthService(service string, timeout time.Duration, checks ...HealthCheck) HealthService {
+	return &healthService{
+		service: service,
+		timeout: timeout,
+		checks:  checks,
+	}
+}
+
+// Live indica que el proceso está vivo
+func (s *healthService) Live() dto.HealthResponse {
+	return dto.HealthResponse{
+		Status:  dto.HealthStatusUp,
+		Service: s.service,
+	}
+}
+
+// Ready chequea todas las dependencias en paralelo
+func (s *healthService) Ready(ctx context.Context) (dto.HealthResponse, bool) {
+	results := make([]dto.DependencyHealth, len(s.checks))
+
+	var wg sync.WaitGroup
+	for i, check := range s.checks {
+		wg.Add(1)
+		go func(i int, check HealthCheck) {
+			defer wg.Done()
+			results[i] = s.runCheck(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	response := dto.HealthResponse{
+		Status:       dto.HealthStatusUp,
+		Service:      s.service,
+		Dependencies: make(map[string]dto.DependencyHealth, len(s.checks)),
+	}
+	ready := true
+	for i, check := range s.checks {
+		response.Dependencies[check.Name] = results[i]
+		if results[i].Status == dto.HealthStatusUp {
+			continue
+		}
+		if check.Optional {
+			if ready {
+				response.Status = dto.HealthStatusDegraded
+			}
+			continue
+		}
+		ready = false
+		response.Status = dto.HealthStatusDown
+	}
+
+	return response, ready
+}
+
+// runCheck ejecuta un chequeo con timeout
+// El chequeo corre en su propia goroutine para respetar el timeout aunque ignore el contexto
+func (s *healthService) runCheck(ctx context.Context, check HealthCheck) dto.DependencyHealth {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- check.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := dto.DependencyHealth{
+		Status:    dto.HealthStatusUp,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = dto.HealthStatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"strings"
+	"time"
+
+	"users-api/clients"
+	"users-api/domain"
+	"users-api/dto"
+	"users-api/repositories"
+)
+
+const (
+	// ReasonNewDevice se usa cuando el login viene de un dispositivo nunca visto
+	ReasonNewDevice = "new_device"
+
+	// ReasonNewCountry se usa cuando el login viene de un país nunca visto
+	ReasonNewCountry = "new_country"
+
+	// maxLoginHistory es la cantidad máxima de logins que se devuelven en el historial
+	maxLoginHistory = 50
+)
+
+// LoginHistoryService registra los logins y detecta los sospechosos
+type LoginHistoryService interface {
+	// RecordLogin guarda el intento de login; si es exitoso y viene de un
+	// dispositivo o país nuevo publica una alerta de seguridad
+	RecordLogin(user domain.User, client dto.LoginClientInfo, success bool)
+
+	// GetUserLogins obtiene el historial de logins del usuario
+	GetUserLogins(userID uint) ([]dto.LoginEventResponse, error)
+}
+
+type loginHistoryService struct {
+	repo   repositories.LoginEventRepository
+	alerts clients.SecurityAlertPublisher
+}
+
+// NewLoginHistoryService crea el servicio de historial de logins
+// alerts puede ser nil si no hay conexión con el servicio de notificaciones
+func NewLoginHistoryService(repo repositories.LoginEventRepository, alerts clients.SecurityAlertPublisher) LoginHistoryService {
+	return &loginHistoryService{
+		repo:   repo,
+		alerts: alerts,
+	}
+}
+
+// RecordLogin guarda el intento de login
+// Los errores se loguean pero nunca hacen fallar el login
+func (s *loginHistoryService) RecordLogin(user domain.User, client dto.LoginClientInfo, success bool) {
+	event := domain.LoginEvent{
+		UserID:     user.ID,
+		IP:         client.IP,
+		UserAgent:  client.UserAgent,
+		DeviceHash: deviceHash(client.UserAgent),
+		Country:    client.Country,
+		Success:    success,
+		CreatedAt:  time.Now(),
+	}
+
+	var reasons []string
+	if success {
+		reasons = s.detectSuspicious(event)
+		event.Suspicious = len(reasons) > 0
+		event.Reasons = strings.Join(reasons, ",")
+	}
+
+	if err := s.repo.Create(&event); err != nil {
+		log.Printf("⚠️ Error guardando evento de login del usuario %d: %v", user.ID, err)
+	}
+
+	if !event.Suspicious {
+		return
+	}
+
+	log.Printf("🚨 Login sospechoso del usuario %d (%s) desde IP %s: %v", user.ID, user.Username, client.IP, reasons)
+
+	if s.alerts == nil {
+		return
+	}
+	alert := clients.SecurityAlertEvent{
+		Type:       "suspicious_login",
+		UserID:     user.ID,
+		Username:   user.Username,
+		Email:      user.Email,
+		IP:         client.IP,
+		UserAgent:  client.UserAgent,
+		Country:    client.Country,
+		Reasons:    reasons,
+		OccurredAt: event.CreatedAt,
+	}
+	if err := s.alerts.PublishSecurityAlert(alert); err != nil {
+		log.Printf("⚠️ Error publicando alerta de seguridad del usuario %d: %v", user.ID, err)
+	}
+}
+
+// detectSuspicious compara el login con los logins exitosos anteriores del usuario
+// El primer login de un usuario nunca se considera sospechoso
+func (s *loginHistoryService) detectSuspicious(event domain.LoginEvent) []string {
+	previous, err := s.repo.CountSuccessful(event.UserID)
+	if err != nil {
+		log.Printf("⚠️ Error consultando historial de logins del usuario %d: %v", event.UserID, err)
+		return nil
+	}
+	if previous == 0 {
+		return nil
+	}
+
+	var reasons []string
+
+	knownDevice, err := s.repo.HasSuccessfulFromDevice(event.UserID, event.DeviceHash)
+	if err == nil && !knownDevice {
+		reasons = append(reasons, ReasonNewDevice)
+	}
+
+	if event.Country != "" {
+		knownCountry, err := s.repo.HasSuccessfulFromCountry(event.UserID, event.Country)
+		if err == nil && !knownCountry {
+			reasons = append(reasons, ReasonNewCountry)
+		}
+	}
+
+	return reasons
+}
+
+// GetUserLogins obtiene los últimos logins del usuario
+func (s *loginHistoryService) GetUserLogins(userID uint) ([]dto.LoginEventResponse, error) {
+	events, err := s.repo.GetByUserID(userID, maxLoginHistory)
+	if err != nil {
+		return nil, err
+	}
+
+	return toLoginEventResponses(events), nil
+}
+
+// toLoginEventResponses convierte los eventos de login a DTOs de respuesta
+func toLoginEventResponses(events []domain.LoginEvent) []dto.LoginEventResponse {
+	response := make([]dto.LoginEventResponse, len(events))
+	for i, event := range events {
+		var reasons []string
+		if event.Reasons != "" {
+			reasons = strings.Split(event.Reasons, ",")
+		}
+		response[i] = dto.LoginEventResponse{
+			ID:         event.ID,
+			IP:         event.IP,
+			UserAgent:  event.UserAgent,
+			Country:    event.Country,
+			Success:    event.Success,
+			Suspicious: event.Suspicious,
+			Reasons:    reasons,
+			CreatedAt:  event.CreatedAt,
+		}
+	}
+
+	return response
+}
+
+// deviceHash identifica un dispositivo a partir de su user agent normalizado
+func deviceHash(userAgent string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(userAgent))))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"users-api/config"
+	"users-api/dto"
+	"users-api/migrations"
+)
+
+// MigrationService consulta el estado de las migraciones versionadas de MySQL
+type MigrationService interface {
+	// Status retorna la versión aplicada y las migraciones pendientes
+	Status() (dto.MigrationStatusResponse, error)
+}
+
+// migrationService es la implementación concreta de MigrationService
+type migrationService struct {
+	database config.DatabaseConfig
+}
+
+// NewMigrationService crea el servicio de migraciones
+func NewMigrationService(database config.DatabaseConfig) MigrationService {
+	return &migrationService{database: database}
+}
+
+// Status retorna la versión aplicada y las migraciones pendientes
+func (s *migrationService) Status() (dto.MigrationStatusResponse, error) {
+	status, err := migrations.GetStatus(s.database)
+	if err != nil {
+		return dto.MigrationStatusResponse{}, err
+	}
+
+	response := dto.MigrationStatusResponse{
+		Version:    status.Version,
+		Latest:     status.Latest,
+		Pending:    status.Pending,
+		Dirty:      status.Dirty,
+		Migrations: make([]dto.MigrationDTO, len(status.Migrations)),
+	}
+	for i, migration := range status.Migrations {
+		response.Migrations[i] = dto.MigrationDTO{
+			Version: migration.Version,
+			Name:    migration.Name,
+			Applied: migration.Applied,
+		}
+	}
+	return response, nil
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"users-api/clients"
+	"users-api/dto"
+)
+
+// passwordField es el campo del body al que se asocian los errores de la política
+const passwordField = "password"
+
+// Reglas de la política de contraseñas (Code de cada dto.FieldError)
+const (
+	PasswordRuleMinLength = "min_length"
+	PasswordRuleMaxLength = "max_length"
+	PasswordRuleUpper     = "uppercase"
+	PasswordRuleLower     = "lowercase"
+	PasswordRuleDigit     = "digit"
+	PasswordRuleSymbol    = "symbol"
+	PasswordRuleBreached  = "breached"
+	PasswordRuleReused    = "same_as_current"
+)
+
+// ErrWeakPassword indica que la contraseña no cumple la política (el detalle está en PasswordPolicyError)
+var ErrWeakPassword = errors.New("la contraseña no cumple la política de seguridad")
+
+// PasswordPolicyError contiene todas las reglas que no cumple una contraseña, no solo la primera
+type PasswordPolicyError struct {
+	Violations []dto.FieldError
+}
+
+func (e *PasswordPolicyError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.Message
+	}
+	return ErrWeakPassword.Error() + ": " + strings.Join(messages, "; ")
+}
+
+// Unwrap permite usar errors.Is(err, ErrWeakPassword)
+func (e *PasswordPolicyError) Unwrap() error {
+	return ErrWeakPassword
+}
+
+// withPasswordField asocia los errores de la política a otro campo del body (ej: "newPassword")
+// Los errores que no son de la política se retornan sin cambios
+func withPasswordField(err error, field string) error {
+	var policyErr *PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		return err
+	}
+	violations := make([]dto.FieldError, len(policyErr.Violations))
+	for i, violation := range policyErr.Violations {
+		violation.Field = field
+		violations[i] = violation
+	}
+	return &PasswordPolicyError{Violations: violations}
+}
+
+// PasswordPolicyConfig contiene las reglas de las contraseñas nuevas (ver config.PasswordPolicyConfig)
+type PasswordPolicyConfig struct {
+	MinLength     int
+	MaxLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+// PasswordPolicy valida las contraseñas al crear un usuario o cambiar su contraseña
+type PasswordPolicy interface {
+	// Validate retorna un *PasswordPolicyError si la contraseña no cumple alguna regla
+	Validate(password string) error
+}
+
+type passwordPolicy struct {
+	cfg      PasswordPolicyConfig
+	breached clients.BreachedPasswordChecker
+}
+
+// NewPasswordPolicy crea la política de contraseñas
+// breached puede ser nil: en ese caso no se consultan las filtraciones conocidas
+func NewPasswordPolicy(cfg PasswordPolicyConfig, breached clients.BreachedPasswordChecker) PasswordPolicy {
+	return &passwordPolicy{
+		cfg:      cfg,
+		breached: breached,
+	}
+}
+
+// Validate aplica las reglas de largo y de tipos de caracteres y después consulta las filtraciones
+// La consulta externa se hace solo si la contraseña cumple el resto, y si falla la contraseña se acepta:
+// la API caída no puede bloquear los registros
+func (p *passwordPolicy) Validate(password string) error {
+	var violations []dto.FieldError
+	violate := func(code, message string) {
+		violations = append(violations, dto.FieldError{Field: passwordField, Code: code, Message: message})
+	}
+
+	length := utf8.RuneCountInString(password)
+	if length < p.cfg.MinLength {
+		violate(PasswordRuleMinLength, fmt.Sprintf("debe tener al menos %d caracteres", p.cfg.MinLength))
+	}
+	if p.cfg.MaxLength > 0 && length > p.cfg.MaxLength {
+		violate(PasswordRuleMaxLength, fmt.Sprintf("no puede tener más de %d caracteres", p.cfg.MaxLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case !unicode.IsLetter(r) && !unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if p.cfg.RequireUpper && !hasUpper {
+		violate(PasswordRuleUpper, "debe tener al menos una mayúscula")
+	}
+	if p.cfg.RequireLower && !hasLower {
+		violate(PasswordRuleLower, "debe tener al menos una minúscula")
+	}
+	if p.cfg.RequireDigit && !hasDigit {
+		violate(PasswordRuleDigit, "debe tener al menos un número")
+	}
+	if p.cfg.RequireSymbol && !hasSymbol {
+		violate(PasswordRuleSymbol, "debe tener al menos un símbolo (ej: !, #, $)")
+	}
+
+	if len(violations) == 0 && p.breached != nil {
+		count, err := p.breached.BreachCount(password)
+		if err != nil {
+			log.Printf("⚠️ No se pudo verificar si la contraseña está filtrada, se acepta: %v", err)
+		} else if count > 0 {
+			violate(PasswordRuleBreached, "aparece en filtraciones de datos conocidas, elegí otra")
+		}
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+
+	"users-api/domain"
+	"users-api/dto"
+	"users-api/repositories"
+)
+
+// maxFavoritesPerUser limita los favoritos de un usuario (search-api los recibe todos en cada perfil)
+const maxFavoritesPerUser = 500
+
+var (
+	// ErrInvalidCurrency se retorna cuando la moneda no es un código ISO 4217
+	ErrInvalidCurrency = errors.New("moneda inválida, debe ser un código ISO 4217 (ej: ARS, USD)")
+
+	// ErrInvalidLocale se retorna cuando el locale no tiene el formato "es" o "es-AR"
+	ErrInvalidLocale = errors.New("locale inválido, debe tener el formato 'es' o 'es-AR'")
+
+	// ErrInvalidPropertyID se retorna cuando el ID no es un ObjectID de properties-api
+	ErrInvalidPropertyID = errors.New("ID de propiedad inválido")
+
+	// ErrTooManyFavorites se retorna al superar maxFavoritesPerUser
+	ErrTooManyFavorites = fmt.Errorf("no se pueden tener más de %d propiedades favoritas", maxFavoritesPerUser)
+)
+
+var (
+	currencyPattern   = regexp.MustCompile(`^[A-Z]{3}$`)
+	localePattern     = regexp.MustCompile(`^[a-z]{2}(-[A-Z]{2})?$`)
+	propertyIDPattern = regexp.MustCompile(`^[0-9a-f]{24}$`)
+)
+
+// PreferenceService maneja las preferencias de búsqueda y los favoritos del usuario
+type PreferenceService interface {
+	GetPreferences(userID uint) (dto.PreferencesResponse, error)
+	UpdatePreferences(userID uint, request dto.UpdatePreferencesRequest) (dto.PreferencesResponse, error)
+	ListFavorites(userID uint) ([]dto.FavoriteResponse, error)
+
+	// AddFavorite marca la propiedad como favorita; marcarla dos veces no es un error
+	AddFavorite(userID uint, propertyID string) error
+
+	// RemoveFavorite desmarca la propiedad; desmarcar una que no era favorita no es un error
+	RemoveFavorite(userID uint, propertyID string) error
+
+	// GetSearchProfile junta preferencias y favoritos para personalizar las búsquedas de search-api
+	GetSearchProfile(userID uint) (dto.PreferencesResponse, []string, error)
+}
+
+type preferenceService struct {
+	repo      repositories.UserRepository
+	favorites repositories.FavoriteRepository
+}
+
+// NewPreferenceService crea el servicio de preferencias y favoritos
+func NewPreferenceService(repo repositories.UserRepository, favorites repositories.FavoriteRepository) PreferenceService {
+	return &preferenceService{repo: repo, favorites: favorites}
+}
+
+// GetPreferences obtiene las preferencias del usuario
+func (s *preferenceService) GetPreferences(userID uint) (dto.PreferencesResponse, error) {
+	user, err := s.repo.GetByID(userID)
+	if err != nil || user == nil {
+		return dto.PreferencesResponse{}, ErrUserNotFound
+	}
+	return toPreferencesResponse(*user), nil
+}
+
+// UpdatePreferences actualiza solo los campos enviados
+func (s *preferenceService) UpdatePreferences(userID uint, request dto.UpdatePreferencesRequest) (dto.PreferencesResponse, error) {
+	if request.PreferredCurrency != nil && *request.PreferredCurrency != "" && !currencyPattern.MatchString(*request.PreferredCurrency) {
+		return dto.PreferencesResponse{}, ErrInvalidCurrency
+	}
+	if request.Locale != nil && *request.Locale != "" && !localePattern.MatchString(*request.Locale) {
+		return dto.PreferencesResponse{}, ErrInvalidLocale
+	}
+
+	user, err := s.repo.GetByID(userID)
+	if err != nil || user == nil {
+		return dto.PreferencesResponse{}, ErrUserNotFound
+	}
+
+	if request.PreferredCurrency != nil {
+		user.PreferredCurrency = *request.PreferredCurrency
+	}
+	if request.Locale != nil {
+		user.Locale = *request.Locale
+	}
+	if err := s.repo.Update(user); err != nil {
+		return dto.PreferencesResponse{}, err
+	}
+
+	log.Printf("⚙️ Preferencias del usuario %d actualizadas (moneda: '%s', locale: '%s')", userID, user.PreferredCurrency, user.Locale)
+	return toPreferencesResponse(*user), nil
+}
+
+// ListFavorites obtiene los favoritos del usuario, del más reciente al más antiguo
+func (s *preferenceService) ListFavorites(userID uint) ([]dto.FavoriteResponse, error) {
+	favorites, err := s.favorites.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toFavoriteResponses(favorites), nil
+}
+
+// AddFavorite marca la propiedad como favorita
+// No se consulta properties-api: un favorito de una propiedad borrada simplemente no aparece en las búsquedas
+func (s *preferenceService) AddFavorite(userID uint, propertyID string) error {
+	if !propertyIDPattern.MatchString(propertyID) {
+		return ErrInvalidPropertyID
+	}
+
+	count, err := s.favorites.CountByUserID(userID)
+	if err != nil {
+		return err
+	}
+	if count >= maxFavoritesPerUser {
+		return ErrTooManyFavorites
+	}
+
+	return s.favorites.Add(&domain.Favorite{UserID: userID, PropertyID: propertyID})
+}
+
+// RemoveFavorite desmarca la propiedad
+func (s *preferenceService) RemoveFavorite(userID uint, propertyID string) error {
+	if !propertyIDPattern.MatchString(propertyID) {
+		return ErrInvalidPropertyID
+	}
+
+	_, err := s.favorites.Remove(userID, propertyID)
+	return err
+}
+
+// GetSearchProfile obtiene las preferencias y los IDs de las propiedades favoritas
+func (s *preferenceService) GetSearchProfile(userID uint) (dto.PreferencesResponse, []string, error) {
+	preferences, err := s.GetPreferences(userID)
+	if err != nil {
+		return dto.PreferencesResponse{}, nil, err
+	}
+
+	favorites, err := s.favorites.GetByUserID(userID)
+	if err != nil {
+		return dto.PreferencesResponse{}, nil, err
+	}
+	ids := make([]string, 0, len(favorites))
+	for _, favorite := range favorites {
+		ids = append(ids, favorite.PropertyID)
+	}
+	return preferences, ids, nil
+}
+
+// toPreferencesResponse arma el DTO de preferencias del usuario
+func toPreferencesResponse(user domain.User) dto.PreferencesResponse {
+	return dto.PreferencesResponse{PreferredCurrency: user.PreferredCurrency, Locale: user.Locale}
+}
+
+// toFavoriteResponses arma los DTOs de los favoritos
+func toFavoriteResponses(favorites []domain.Favorite) []dto.FavoriteResponse {
+	response := make([]dto.FavoriteResponse, 0, len(favorites))
+	for _, favorite := range favorites {
+		response = append(response, dto.FavoriteResponse{PropertyID: favorite.PropertyID, CreatedAt: favorite.CreatedAt})
+	}
+	return response
+}
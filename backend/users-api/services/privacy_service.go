@@ -0,0 +1,196 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"users-api/clients"
+	"users-api/domain"
+	"users-api/dto"
+	"users-api/repositories"
+)
+
+// erasedPasswordHash reemplaza la contraseña de una cuenta borrada
+// No es un hash bcrypt válido, así que ninguna contraseña coincide
+const erasedPasswordHash = "!erased"
+
+// ErrExportUnavailable se retorna cuando no se pudieron obtener los datos de otro servicio
+var ErrExportUnavailable = errors.New("no se pudieron obtener los datos de properties-api")
+
+// PrivacyService implementa los derechos de acceso y borrado de datos personales (GDPR)
+type PrivacyService interface {
+	// ExportUserData junta todos los datos del usuario: perfil, historial de logins, favoritos y
+	// las propiedades y reservas que guarda properties-api
+	ExportUserData(actorID uint, actorType string, userID uint, authorization string) (dto.UserDataExport, error)
+
+	// EraseUser anonimiza los datos personales del usuario, borra su historial de logins y sus favoritos,
+	// elimina la cuenta y publica "user.erased" para que el resto de los servicios anonimicen los suyos
+	EraseUser(actorID uint, actorType string, userID uint) error
+}
+
+type privacyService struct {
+	repo        repositories.UserRepository
+	loginEvents repositories.LoginEventRepository
+	favorites   repositories.FavoriteRepository
+	revocations repositories.TokenRevocationRepository
+	properties  clients.PropertiesClient
+	events      clients.UserEventPublisher
+	avatars     repositories.AvatarStorage
+	audit       AuditService
+}
+
+// NewPrivacyService crea el servicio de privacidad
+// events puede ser nil: en ese caso el borrado en otros servicios no se propaga y solo se loguea
+// avatars puede ser nil: en ese caso la foto de perfil subida queda en el almacenamiento
+func NewPrivacyService(
+	repo repositories.UserRepository,
+	loginEvents repositories.LoginEventRepository,
+	favorites repositories.FavoriteRepository,
+	revocations repositories.TokenRevocationRepository,
+	properties clients.PropertiesClient,
+	events clients.UserEventPublisher,
+	avatars repositories.AvatarStorage,
+	audit AuditService,
+) PrivacyService {
+	return &privacyService{
+		repo:        repo,
+		loginEvents: loginEvents,
+		favorites:   favorites,
+		revocations: revocations,
+		properties:  properties,
+		events:      events,
+		avatars:     avatars,
+		audit:       audit,
+	}
+}
+
+// ExportUserData obtiene todos los datos del usuario
+func (s *privacyService) ExportUserData(actorID uint, actorType string, userID uint, authorization string) (dto.UserDataExport, error) {
+	if !canManageAccount(actorID, actorType, userID) {
+		return dto.UserDataExport{}, ErrForbidden
+	}
+
+	user, err := s.repo.GetByID(userID)
+	if err != nil || user == nil {
+		return dto.UserDataExport{}, ErrUserNotFound
+	}
+
+	// -1 = todo el historial, sin el límite del endpoint de logins
+	events, err := s.loginEvents.GetByUserID(userID, -1)
+	if err != nil {
+		return dto.UserDataExport{}, err
+	}
+
+	favorites, err := s.favorites.GetByUserID(userID)
+	if err != nil {
+		return dto.UserDataExport{}, err
+	}
+
+	// Un export incompleto no cumple con el derecho de acceso, por eso se falla en lugar de omitir datos
+	propertiesData, err := s.properties.GetUserData(userID, authorization)
+	if err != nil {
+		log.Printf("⚠️ Error obteniendo datos de properties-api para el usuario %d: %v", userID, err)
+		return dto.UserDataExport{}, fmt.Errorf("%w: %v", ErrExportUnavailable, err)
+	}
+
+	return dto.UserDataExport{
+		Profile:    toUserResponse(*user),
+		Details:    toProfileResponse(*user),
+		CreatedAt:  user.CreatedAt,
+		Logins:     toLoginEventResponses(events),
+		Favorites:  toFavoriteResponses(favorites),
+		Properties: propertiesData.Properties,
+		Bookings:   propertiesData.Bookings,
+		ExportedAt: time.Now().UTC(),
+	}, nil
+}
+
+// EraseUser anonimiza y elimina la cuenta del usuario
+func (s *privacyService) EraseUser(actorID uint, actorType string, userID uint) error {
+	if !canManageAccount(actorID, actorType, userID) {
+		return ErrForbidden
+	}
+
+	user, err := s.repo.GetByID(userID)
+	if err != nil || user == nil {
+		return ErrUserNotFound
+	}
+
+	// Nunca dejar el sistema sin administradores activos
+	if user.UserType == string(domain.UserTypeAdmin) && user.Active {
+		admins, err := s.repo.CountByUserType(string(domain.UserTypeAdmin))
+		if err != nil {
+			return err
+		}
+		if admins <= 1 {
+			return ErrLastAdmin
+		}
+	}
+
+	before := userAuditState(*user)
+	user.Username = fmt.Sprintf("erased-user-%d", userID)
+	user.Email = fmt.Sprintf("erased-user-%d@erased.invalid", userID)
+	user.FirstName = ""
+	user.LastName = ""
+	user.Phone = ""
+	avatarKey := user.AvatarKey
+	user.AvatarURL = ""
+	user.AvatarKey = ""
+	user.Bio = ""
+	user.Languages = ""
+	user.City = ""
+	user.EmergencyContactName = ""
+	user.EmergencyContactPhone = ""
+	user.Password = erasedPasswordHash
+	user.Active = false
+	if err := s.repo.Update(user); err != nil {
+		return err
+	}
+
+	if avatarKey != "" && s.avatars != nil {
+		if err := s.avatars.Delete(avatarKey); err != nil {
+			log.Printf("⚠️ %v", err)
+		}
+	}
+
+	if err := s.loginEvents.DeleteByUserID(userID); err != nil {
+		return err
+	}
+
+	if err := s.favorites.DeleteByUserID(userID); err != nil {
+		return err
+	}
+
+	if err := s.revocations.Revoke(userID, "datos personales borrados"); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(userID); err != nil {
+		return err
+	}
+	s.audit.Record(actorID, AuditActionUserErase, auditEntityUser, auditUserID(userID), before, userAuditState(*user))
+	log.Printf("🧹 Datos personales del usuario %d borrados por usuario %d", userID, actorID)
+
+	s.publish(clients.UserEvent{
+		Type:       clients.UserEventErased,
+		UserID:     userID,
+		OccurredAt: time.Now().UTC(),
+	})
+
+	return nil
+}
+
+// publish publica un evento de usuario, logueando si no hay publisher o si falla
+func (s *privacyService) publish(event clients.UserEvent) {
+	if s.events == nil {
+		log.Printf("⚠️ Evento '%s' del usuario %d no publicado: RabbitMQ no disponible", event.Type, event.UserID)
+		return
+	}
+	if err := s.events.PublishUserEvent(event); err != nil {
+		log.Printf("⚠️ Error publicando evento '%s' del usuario %d: %v", event.Type, event.UserID, err)
+		return
+	}
+	log.Printf("📤 Evento '%s' publicado para usuario %d", event.Type, event.UserID)
+}
@@ -0,0 +1,399 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"log"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"users-api/domain"
+	"users-api/dto"
+	"users-api/repositories"
+
+	"golang.org/x/image/draw"
+)
+
+const (
+	// maxBioLength es el largo máximo de la presentación, en caracteres
+	maxBioLength = 500
+
+	// maxProfileLanguages limita los idiomas que se muestran en el perfil
+	maxProfileLanguages = 10
+
+	// maxAvatarURLLength es el largo de la columna avatar_url
+	maxAvatarURLLength = 512
+
+	// avatarSide es el lado en píxeles de la foto de perfil guardada (cuadrada)
+	avatarSide = 512
+
+	// avatarJPEGQuality es la calidad con la que se recodifica la foto de perfil
+	avatarJPEGQuality = 85
+
+	// maxAvatarPixels limita la resolución de la foto subida (decodificarla ocupa 4 bytes por píxel)
+	maxAvatarPixels = 40_000_000
+)
+
+var (
+	// ErrInvalidPhone se retorna cuando el teléfono no está en formato E.164
+	ErrInvalidPhone = errors.New("teléfono inválido, debe estar en formato internacional E.164 (ej: +5493511234567)")
+
+	// ErrInvalidAvatarURL se retorna cuando la foto de perfil no es una URL http(s) absoluta
+	ErrInvalidAvatarURL = fmt.Errorf("avatarUrl inválida, debe ser una URL http(s) de hasta %d caracteres", maxAvatarURLLength)
+
+	// ErrBioTooLong se retorna cuando la presentación supera maxBioLength
+	ErrBioTooLong = fmt.Errorf("bio no puede tener más de %d caracteres", maxBioLength)
+
+	// ErrInvalidLanguages se retorna cuando algún idioma no es un código ISO 639-1 o hay demasiados
+	ErrInvalidLanguages = fmt.Errorf("languages inválido, se esperan hasta %d códigos ISO 639-1 (ej: es, en)", maxProfileLanguages)
+
+	// ErrInvalidEmergencyContact se retorna cuando el contacto de emergencia está incompleto
+	ErrInvalidEmergencyContact = errors.New("el contacto de emergencia requiere nombre (hasta 100 caracteres) y teléfono E.164")
+
+	// ErrInvalidAvatarImage se retorna cuando el archivo subido no es una imagen JPEG, PNG o GIF
+	ErrInvalidAvatarImage = errors.New("foto de perfil inválida, se esperaba una imagen JPEG, PNG o GIF")
+
+	// ErrAvatarTooLarge se retorna cuando la foto subida supera el tamaño máximo en bytes o en píxeles
+	ErrAvatarTooLarge = errors.New("la foto de perfil es demasiado grande")
+)
+
+var (
+	phonePattern    = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+	languagePattern = regexp.MustCompile(`^[a-z]{2}$`)
+)
+
+// ProfileService maneja el perfil del usuario: datos de contacto, presentación y perfil de anfitrión
+type ProfileService interface {
+	// GetProfile obtiene el perfil completo del usuario (incluye los datos privados)
+	GetProfile(userID uint) (dto.ProfileResponse, error)
+
+	// UpdateProfile actualiza solo los campos enviados y registra el cambio en la auditoría
+	UpdateProfile(userID uint, request dto.UpdateProfileRequest) (dto.ProfileResponse, error)
+
+	// GetHostProfile obtiene el perfil público de un anfitrión; retorna ErrUserNotFound
+	// si el usuario no existe o su cuenta está desactivada
+	GetHostProfile(userID uint) (dto.HostProfileResponse, error)
+
+	// UploadAvatar valida la imagen subida, la guarda recortada en un cuadrado de avatarSide píxeles
+	// y la deja como foto de perfil; la foto subida anterior se elimina
+	UploadAvatar(userID uint, data []byte) (dto.ProfileResponse, error)
+}
+
+type profileService struct {
+	repo           repositories.UserRepository
+	avatars        repositories.AvatarStorage
+	audit          AuditService
+	maxUploadBytes int
+}
+
+// NewProfileService crea el servicio de perfiles
+// maxUploadBytes es el tamaño máximo de la foto de perfil subida
+func NewProfileService(repo repositories.UserRepository, avatars repositories.AvatarStorage, audit AuditService, maxUploadBytes int) ProfileService {
+	return &profileService{repo: repo, avatars: avatars, audit: audit, maxUploadBytes: maxUploadBytes}
+}
+
+// GetProfile obtiene el perfil del usuario autenticado
+func (s *profileService) GetProfile(userID uint) (dto.ProfileResponse, error) {
+	user, err := s.repo.GetByID(userID)
+	if err != nil || user == nil {
+		return dto.ProfileResponse{}, ErrUserNotFound
+	}
+	return toProfileResponse(*user), nil
+}
+
+// UpdateProfile valida y aplica los cambios del propio perfil
+func (s *profileService) UpdateProfile(userID uint, request dto.UpdateProfileRequest) (dto.ProfileResponse, error) {
+	languages, err := validateProfileRequest(&request)
+	if err != nil {
+		return dto.ProfileResponse{}, err
+	}
+
+	user, err := s.repo.GetByID(userID)
+	if err != nil || user == nil {
+		return dto.ProfileResponse{}, ErrUserNotFound
+	}
+	before := profileAuditState(*user)
+
+	if request.FirstName != nil {
+		user.FirstName = *request.FirstName
+	}
+	if request.LastName != nil {
+		user.LastName = *request.LastName
+	}
+	if request.Phone != nil {
+		user.Phone = *request.Phone
+	}
+	// Si se reemplaza la foto subida por otra URL (o se borra), el archivo deja de usarse
+	var unusedAvatarKey string
+	if request.AvatarURL != nil && *request.AvatarURL != user.AvatarURL {
+		user.AvatarURL = *request.AvatarURL
+		unusedAvatarKey, user.AvatarKey = user.AvatarKey, ""
+	}
+	if request.Bio != nil {
+		user.Bio = *request.Bio
+	}
+	if request.Locale != nil {
+		user.Locale = *request.Locale
+	}
+	if request.Languages != nil {
+		user.Languages = strings.Join(languages, ",")
+	}
+	if request.City != nil {
+		user.City = *request.City
+	}
+	if request.EmergencyContact != nil {
+		user.EmergencyContactName = request.EmergencyContact.Name
+		user.EmergencyContactPhone = request.EmergencyContact.Phone
+	}
+
+	if err := s.repo.Update(user); err != nil {
+		return dto.ProfileResponse{}, err
+	}
+
+	s.deleteAvatarFile(unusedAvatarKey)
+
+	s.audit.Record(userID, AuditActionProfileUpdate, auditEntityUser, auditUserID(userID), before, profileAuditState(*user))
+	log.Printf("👤 Perfil del usuario %d actualizado", userID)
+	return toProfileResponse(*user), nil
+}
+
+// UploadAvatar guarda la foto de perfil subida
+// La imagen se recodifica en JPEG: además de normalizar el tamaño, descarta los metadatos (EXIF, ubicación)
+func (s *profileService) UploadAvatar(userID uint, data []byte) (dto.ProfileResponse, error) {
+	if len(data) > s.maxUploadBytes {
+		return dto.ProfileResponse{}, fmt.Errorf("%w: pesa %d bytes y el máximo es %d", ErrAvatarTooLarge, len(data), s.maxUploadBytes)
+	}
+
+	// Se lee primero el encabezado para no decodificar imágenes enormes
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return dto.ProfileResponse{}, ErrInvalidAvatarImage
+	}
+	if config.Width*config.Height > maxAvatarPixels {
+		return dto.ProfileResponse{}, fmt.Errorf("%w: %dx%d píxeles supera el máximo de %d", ErrAvatarTooLarge, config.Width, config.Height, maxAvatarPixels)
+	}
+	original, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return dto.ProfileResponse{}, ErrInvalidAvatarImage
+	}
+
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, cropToSquare(original, avatarSide), &jpeg.Options{Quality: avatarJPEGQuality}); err != nil {
+		return dto.ProfileResponse{}, fmt.Errorf("error codificando la foto de perfil: %w", err)
+	}
+
+	user, err := s.repo.GetByID(userID)
+	if err != nil || user == nil {
+		return dto.ProfileResponse{}, ErrUserNotFound
+	}
+	before := profileAuditState(*user)
+
+	name, err := randomAvatarName()
+	if err != nil {
+		return dto.ProfileResponse{}, err
+	}
+	key := fmt.Sprintf("avatars/%d/%s.jpg", userID, name)
+	avatarURL, err := s.avatars.Save(key, encoded.Bytes())
+	if err != nil {
+		return dto.ProfileResponse{}, err
+	}
+
+	previousKey := user.AvatarKey
+	user.AvatarURL = avatarURL
+	user.AvatarKey = key
+	if err := s.repo.Update(user); err != nil {
+		s.deleteAvatarFile(key)
+		return dto.ProfileResponse{}, err
+	}
+	s.deleteAvatarFile(previousKey)
+
+	s.audit.Record(userID, AuditActionProfileUpdate, auditEntityUser, auditUserID(userID), before, profileAuditState(*user))
+	log.Printf("🖼️ Foto de perfil del usuario %d actualizada", userID)
+	return toProfileResponse(*user), nil
+}
+
+// deleteAvatarFile elimina una foto subida que ya no se usa; los errores solo se loguean
+func (s *profileService) deleteAvatarFile(key string) {
+	if key == "" {
+		return
+	}
+	if err := s.avatars.Delete(key); err != nil {
+		log.Printf("⚠️ %v", err)
+	}
+}
+
+// GetHostProfile obtiene los datos públicos del anfitrión
+func (s *profileService) GetHostProfile(userID uint) (dto.HostProfileResponse, error) {
+	user, err := s.repo.GetByID(userID)
+	if err != nil || user == nil || !user.Active {
+		return dto.HostProfileResponse{}, ErrUserNotFound
+	}
+	return toHostProfileResponse(*user), nil
+}
+
+// validateProfileRequest normaliza los textos del request y valida sus formatos
+// Retorna los idiomas normalizados (minúsculas y sin repetir)
+func validateProfileRequest(request *dto.UpdateProfileRequest) ([]string, error) {
+	for _, field := range []*string{request.FirstName, request.LastName, request.Phone, request.AvatarURL, request.Bio, request.Locale, request.City} {
+		if field != nil {
+			*field = strings.TrimSpace(*field)
+		}
+	}
+
+	if request.Phone != nil && *request.Phone != "" && !phonePattern.MatchString(*request.Phone) {
+		return nil, ErrInvalidPhone
+	}
+	if request.AvatarURL != nil && *request.AvatarURL != "" && !isValidAvatarURL(*request.AvatarURL) {
+		return nil, ErrInvalidAvatarURL
+	}
+	if request.Bio != nil && utf8.RuneCountInString(*request.Bio) > maxBioLength {
+		return nil, ErrBioTooLong
+	}
+	if request.Locale != nil && *request.Locale != "" && !localePattern.MatchString(*request.Locale) {
+		return nil, ErrInvalidLocale
+	}
+
+	if contact := request.EmergencyContact; contact != nil {
+		contact.Name = strings.TrimSpace(contact.Name)
+		contact.Phone = strings.TrimSpace(contact.Phone)
+		// Vacío borra el contacto; si se carga tiene que estar completo
+		if contact.Name != "" || contact.Phone != "" {
+			if contact.Name == "" || utf8.RuneCountInString(contact.Name) > 100 || !phonePattern.MatchString(contact.Phone) {
+				return nil, ErrInvalidEmergencyContact
+			}
+		}
+	}
+
+	if request.Languages == nil {
+		return nil, nil
+	}
+	if len(*request.Languages) > maxProfileLanguages {
+		return nil, ErrInvalidLanguages
+	}
+	languages := make([]string, 0, len(*request.Languages))
+	seen := make(map[string]bool, len(*request.Languages))
+	for _, language := range *request.Languages {
+		language = strings.ToLower(strings.TrimSpace(language))
+		if !languagePattern.MatchString(language) {
+			return nil, ErrInvalidLanguages
+		}
+		if !seen[language] {
+			seen[language] = true
+			languages = append(languages, language)
+		}
+	}
+	return languages, nil
+}
+
+// isValidAvatarURL indica si la foto de perfil es una URL http(s) absoluta que entra en la columna
+func isValidAvatarURL(value string) bool {
+	if len(value) > maxAvatarURLLength {
+		return false
+	}
+	parsed, err := url.Parse(value)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+// cropToSquare recorta el centro de la imagen en un cuadrado y lo escala a side píxeles de lado
+// Una imagen más chica no se agranda; se dibuja sobre fondo blanco porque JPEG no tiene transparencia
+func cropToSquare(src image.Image, side int) image.Image {
+	bounds := src.Bounds()
+	crop := min(bounds.Dx(), bounds.Dy())
+	origin := image.Pt(bounds.Min.X+(bounds.Dx()-crop)/2, bounds.Min.Y+(bounds.Dy()-crop)/2)
+	source := image.Rectangle{Min: origin, Max: origin.Add(image.Pt(crop, crop))}
+
+	side = max(1, min(side, crop))
+	dst := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, source, draw.Over, nil)
+	return dst
+}
+
+// randomAvatarName genera un nombre de archivo no adivinable para una foto de perfil
+func randomAvatarName() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("error generando nombre de la foto de perfil: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// splitLanguages convierte la columna de idiomas en una lista (vacía si no hay)
+func splitLanguages(languages string) []string {
+	if languages == "" {
+		return []string{}
+	}
+	return strings.Split(languages, ",")
+}
+
+// toProfileResponse convierte un usuario al perfil privado
+func toProfileResponse(user domain.User) dto.ProfileResponse {
+	profile := dto.ProfileResponse{
+		ID:        user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Phone:     user.Phone,
+		AvatarURL: user.AvatarURL,
+		Bio:       user.Bio,
+		Locale:    user.Locale,
+		Languages: splitLanguages(user.Languages),
+		City:      user.City,
+		CreatedAt: user.CreatedAt,
+	}
+	if user.EmergencyContactName != "" {
+		profile.EmergencyContact = &dto.EmergencyContact{Name: user.EmergencyContactName, Phone: user.EmergencyContactPhone}
+	}
+	return profile
+}
+
+// toHostProfileResponse convierte un usuario al perfil público de anfitrión
+// El nombre se muestra como "Nombre I." para no exponer el apellido completo
+func toHostProfileResponse(user domain.User) dto.HostProfileResponse {
+	displayName := strings.TrimSpace(user.FirstName)
+	if lastName := []rune(strings.TrimSpace(user.LastName)); len(lastName) > 0 {
+		displayName = strings.TrimSpace(displayName + " " + strings.ToUpper(string(lastName[0])) + ".")
+	}
+	if displayName == "" {
+		displayName = user.Username
+	}
+
+	return dto.HostProfileResponse{
+		ID:          user.ID,
+		DisplayName: displayName,
+		AvatarURL:   user.AvatarURL,
+		Bio:         user.Bio,
+		Languages:   splitLanguages(user.Languages),
+		City:        user.City,
+		HasPhone:    user.Phone != "",
+		MemberSince: user.CreatedAt,
+	}
+}
+
+// profileAuditState es el estado del perfil que se compara en la auditoría
+// Los datos de contacto se redactan (ver auditRedactedFields)
+func profileAuditState(user domain.User) map[string]interface{} {
+	return map[string]interface{}{
+		"firstName":             user.FirstName,
+		"lastName":              user.LastName,
+		"phone":                 user.Phone,
+		"avatarUrl":             user.AvatarURL,
+		"bio":                   user.Bio,
+		"locale":                user.Locale,
+		"languages":             user.Languages,
+		"city":                  user.City,
+		"emergencyContactName":  user.EmergencyContactName,
+		"emergencyContactPhone": user.EmergencyContactPhone,
+	}
+}
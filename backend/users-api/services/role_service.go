@@ -0,0 +1,123 @@
+package services
+
+import (
+	"errors"
+	"log"
+
+	"users-api/domain"
+	"users-api/dto"
+	"users-api/repositories"
+)
+
+var (
+	// ErrInvalidRole se retorna cuando el rol pedido no existe
+	ErrInvalidRole = errors.New("rol inválido, debe ser 'normal' o 'admin'")
+
+	// ErrLastAdmin se retorna al intentar quitarle el rol al último administrador
+	ErrLastAdmin = errors.New("no se puede quitar el rol al último administrador")
+
+	// ErrUserNotFound se retorna cuando el usuario no existe
+	ErrUserNotFound = errors.New("usuario no encontrado")
+)
+
+// RoleService maneja los cambios de rol (user_type) de los usuarios
+type RoleService interface {
+	// ChangeUserRole cambia el rol de un usuario, audita el cambio y
+	// revoca los tokens del usuario si pierde privilegios de admin
+	ChangeUserRole(actorID, userID uint, newRole string) (dto.UserResponse, error)
+}
+
+type roleService struct {
+	repo        repositories.UserRepository
+	auditRepo   repositories.RoleChangeRepository
+	revocations repositories.TokenRevocationRepository
+	audit       AuditService
+}
+
+func NewRoleService(
+	repo repositories.UserRepository,
+	auditRepo repositories.RoleChangeRepository,
+	revocations repositories.TokenRevocationRepository,
+	audit AuditService,
+) RoleService {
+	return &roleService{
+		repo:        repo,
+		auditRepo:   auditRepo,
+		revocations: revocations,
+		audit:       audit,
+	}
+}
+
+// ChangeUserRole cambia el rol de un usuario
+func (s *roleService) ChangeUserRole(actorID, userID uint, newRole string) (dto.UserResponse, error) {
+	if newRole != string(domain.UserTypeNormal) && newRole != string(domain.UserTypeAdmin) {
+		return dto.UserResponse{}, ErrInvalidRole
+	}
+
+	user, err := s.repo.GetByID(userID)
+	if err != nil || user == nil {
+		return dto.UserResponse{}, ErrUserNotFound
+	}
+
+	oldRole := user.UserType
+	if oldRole == newRole {
+		return toUserResponse(*user), nil
+	}
+
+	demoted := oldRole == string(domain.UserTypeAdmin)
+
+	// Nunca dejar el sistema sin administradores
+	if demoted {
+		admins, err := s.repo.CountByUserType(string(domain.UserTypeAdmin))
+		if err != nil {
+			return dto.UserResponse{}, err
+		}
+		if admins <= 1 {
+			return dto.UserResponse{}, ErrLastAdmin
+		}
+	}
+
+	before := userAuditState(*user)
+	user.UserType = newRole
+	if err := s.repo.Update(user); err != nil {
+		return dto.UserResponse{}, err
+	}
+	s.audit.Record(actorID, AuditActionUserRoleChange, auditEntityUser, auditUserID(userID), before, userAuditState(*user))
+
+	// Auditoría del cambio (un fallo acá no revierte el cambio de rol)
+	change := domain.RoleChange{
+		UserID:    userID,
+		ChangedBy: actorID,
+		OldRole:   oldRole,
+		NewRole:   newRole,
+	}
+	if err := s.auditRepo.Create(&change); err != nil {
+		log.Printf("⚠️ Error guardando auditoría de cambio de rol: %v", err)
+	}
+	log.Printf("🛡️ Rol de usuario %d cambiado de '%s' a '%s' por usuario %d", userID, oldRole, newRole, actorID)
+
+	// Un usuario degradado no puede seguir usando tokens que dicen que es admin
+	if demoted {
+		if err := s.revocations.Revoke(userID, "rol cambiado de admin a "+newRole); err != nil {
+			return dto.UserResponse{}, err
+		}
+		log.Printf("🔒 Tokens del usuario %d revocados", userID)
+	}
+
+	return toUserResponse(*user), nil
+}
+
+// toUserResponse convierte un domain.User a dto.UserResponse
+func toUserResponse(user domain.User) dto.UserResponse {
+	return dto.UserResponse{
+		ID:        user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		UserType:  user.UserType,
+		Active:    user.Active,
+		Verified:  user.Verified,
+		AvatarURL: user.AvatarURL,
+	}
+}
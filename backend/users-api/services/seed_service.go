@@ -0,0 +1,153 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"users-api/domain"
+	"users-api/repositories"
+	"users-api/utils"
+)
+
+// Usuarios de demo con IDs fijos: el seed de properties-api usa los anfitriones como owners de las
+// propiedades y los huéspedes para las reservas, así los datos de los dos servicios quedan enlazados
+const (
+	SeedAdminID     uint = 1000
+	SeedFirstHostID uint = 1001 // Anfitriones: 1001 a 1010
+	SeedHostCount        = 10
+	SeedGuestCount       = 20 // Huéspedes: 1011 a 1030
+	SeedPassword         = "Demo1234!"
+)
+
+// seedFirstNames y seedLastNames arman los nombres de los usuarios de demo
+var seedFirstNames = []string{"Lucía", "Mateo", "Sofía", "Benjamín", "Valentina", "Santiago", "Martina", "Joaquín", "Catalina", "Tomás"}
+var seedLastNames = []string{"González", "Rodríguez", "Fernández", "López", "Martínez", "García", "Pérez", "Sánchez", "Romero", "Díaz"}
+
+// seedProfiles son la ciudad, la moneda y el locale de cada usuario de demo (se rotan)
+var seedProfiles = []struct {
+	city     string
+	currency string
+	locale   string
+}{
+	{"Córdoba", "ARS", "es-AR"},
+	{"Buenos Aires", "ARS", "es-AR"},
+	{"Santiago", "CLP", "es-CL"},
+	{"Montevideo", "UYU", "es-UY"},
+	{"Bogotá", "COP", "es-CO"},
+	{"Madrid", "EUR", "es-ES"},
+	{"Miami", "USD", "en-US"},
+}
+
+// SeedReport resume lo que hizo el seed
+type SeedReport struct {
+	Created int
+	Skipped int
+}
+
+// SeedService carga usuarios de demo para desarrollo local y ambientes de demo
+type SeedService interface {
+	// Seed crea el admin, los anfitriones y los huéspedes de demo que falten
+	// Es idempotente: los usuarios que ya existen (por ID o username) se saltean
+	Seed() (SeedReport, error)
+}
+
+// seedService es la implementación concreta de SeedService
+type seedService struct {
+	repo  repositories.UserRepository
+	audit AuditService
+}
+
+// NewSeedService crea el servicio de datos de demo
+func NewSeedService(repo repositories.UserRepository, audit AuditService) SeedService {
+	return &seedService{repo: repo, audit: audit}
+}
+
+// Seed crea los usuarios de demo que falten
+func (s *seedService) Seed() (SeedReport, error) {
+	password, err := utils.HashPassword(SeedPassword)
+	if err != nil {
+		return SeedReport{}, errors.New("error hasheando contraseña")
+	}
+
+	var report SeedReport
+	for _, user := range seedUsers(password) {
+		created, err := s.ensureUser(user)
+		if err != nil {
+			return report, err
+		}
+		if created {
+			report.Created++
+		} else {
+			report.Skipped++
+		}
+	}
+	return report, nil
+}
+
+// ensureUser crea el usuario si no existe ninguno con su ID o su username
+func (s *seedService) ensureUser(user domain.User) (bool, error) {
+	if existing, err := s.repo.GetByID(user.ID); err == nil {
+		if existing.Username != user.Username {
+			log.Printf("⚠️ El ID %d ya es de '%s', se saltea el usuario de demo '%s'", user.ID, existing.Username, user.Username)
+		}
+		return false, nil
+	}
+	if existing, _ := s.repo.GetByUsername(user.Username); existing != nil {
+		return false, nil
+	}
+
+	if err := s.repo.Create(&user); err != nil {
+		return false, fmt.Errorf("error creando el usuario de demo '%s': %w", user.Username, err)
+	}
+	s.audit.Record(0, AuditActionUserCreate, auditEntityUser, auditUserID(user.ID), nil, userAuditState(user))
+	return true, nil
+}
+
+// seedUsers arma el admin, los anfitriones y los huéspedes de demo con la contraseña ya hasheada
+func seedUsers(password string) []domain.User {
+	users := []domain.User{{
+		ID:        SeedAdminID,
+		Username:  "demo_admin",
+		Email:     "admin@demo.local",
+		Password:  password,
+		FirstName: "Admin",
+		LastName:  "Demo",
+		UserType:  string(domain.UserTypeAdmin),
+		Active:    true,
+		Verified:  true,
+	}}
+
+	for i := 0; i < SeedHostCount+SeedGuestCount; i++ {
+		username := fmt.Sprintf("host%02d", i+1)
+		if i >= SeedHostCount {
+			username = fmt.Sprintf("guest%02d", i-SeedHostCount+1)
+		}
+		firstName := seedFirstNames[i%len(seedFirstNames)]
+		lastName := seedLastNames[(i/len(seedFirstNames)+i)%len(seedLastNames)]
+		profile := seedProfiles[i%len(seedProfiles)]
+
+		user := domain.User{
+			ID:                SeedFirstHostID + uint(i),
+			Username:          username,
+			Email:             username + "@demo.local",
+			Password:          password,
+			FirstName:         firstName,
+			LastName:          lastName,
+			UserType:          string(domain.UserTypeNormal),
+			Active:            true,
+			Verified:          i%4 != 3, // Algunos sin verificar para probar ese estado
+			PreferredCurrency: profile.currency,
+			Locale:            profile.locale,
+			City:              profile.city,
+			Languages:         "es",
+		}
+		if i < SeedHostCount {
+			user.Bio = fmt.Sprintf("Hola, soy %s. Me encanta recibir viajeros en %s.", firstName, profile.city)
+			user.Languages = "es,en"
+			user.Phone = fmt.Sprintf("+54935100000%02d", i+1)
+		}
+		users = append(users, user)
+	}
+	return users
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"users-api/domain"
+	"users-api/utils"
+)
+
+// seedUserRepository guarda los usuarios con el ID que traen, como el INSERT con ID explícito del seed
+type seedUserRepository struct {
+	*mockUserRepository
+	createErr error
+}
+
+func (r *seedUserRepository) Create(user *domain.User) error {
+	if r.createErr != nil {
+		return r.createErr
+	}
+	r.users[user.ID] = user
+	return nil
+}
+
+func TestSeed_CreatesDemoUsersOnce(t *testing.T) {
+	repo := &seedUserRepository{mockUserRepository: newMockUserRepository()}
+	service := NewSeedService(repo, newTestAuditService())
+
+	report, err := service.Seed()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	total := 1 + SeedHostCount + SeedGuestCount
+	if report.Created != total || report.Skipped != 0 {
+		t.Fatalf("expected %d users created, got %+v", total, report)
+	}
+
+	// Los IDs fijos son los que usa el seed de properties-api como owners y huéspedes
+	admin, host, guest := repo.users[SeedAdminID], repo.users[SeedFirstHostID], repo.users[SeedFirstHostID+SeedHostCount]
+	if admin == nil || admin.UserType != string(domain.UserTypeAdmin) {
+		t.Fatalf("expected the admin with ID %d, got %+v", SeedAdminID, admin)
+	}
+	if host == nil || host.Username != "host01" || host.Bio == "" {
+		t.Fatalf("expected host01 with a bio at %d, got %+v", SeedFirstHostID, host)
+	}
+	if guest == nil || guest.Username != "guest01" || guest.Bio != "" {
+		t.Fatalf("expected guest01 without a bio after the hosts, got %+v", guest)
+	}
+	if !utils.CheckPasswordHash(SeedPassword, guest.Password) {
+		t.Fatal("expected the demo users to log in with the demo password")
+	}
+
+	// Correrlo de nuevo no duplica ni pisa nada
+	report, err = service.Seed()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Created != 0 || report.Skipped != total {
+		t.Fatalf("expected every user skipped, got %+v", report)
+	}
+}
+
+func TestSeed_SkipsTakenIDsAndUsernames(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	repo := &seedUserRepository{mockUserRepository: newMockUserRepository()}
+	// Un usuario real ocupa el ID del admin de demo y otro ya se llama host02
+	repo.users[SeedAdminID] = &domain.User{ID: SeedAdminID, Username: "maria"}
+	repo.users[7] = &domain.User{ID: 7, Username: "host02"}
+
+	report, err := NewSeedService(repo, newTestAuditService()).Seed()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Skipped != 2 || report.Created != SeedHostCount+SeedGuestCount-1 {
+		t.Fatalf("expected 2 users skipped, got %+v", report)
+	}
+	if repo.users[SeedAdminID].Username != "maria" || repo.users[SeedFirstHostID+1] != nil {
+		t.Fatal("expected the existing users untouched")
+	}
+}
+
+func TestSeed_StopsOnCreateError(t *testing.T) {
+	repo := &seedUserRepository{mockUserRepository: newMockUserRepository(), createErr: errors.New("deadlock")}
+
+	report, err := NewSeedService(repo, newTestAuditService()).Seed()
+	if err == nil || !strings.Contains(err.Error(), "demo_admin") {
+		t.Fatalf("expected the failing user in the error, got %v", err)
+	}
+	if report.Created != 0 {
+		t.Fatalf("expected nothing created, got %+v", report)
+	}
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"users-api/repositories"
+	"users-api/utils"
+)
+
+// TokenService valida los JWT de users-api para los demás servicios (ver el método gRPC VerifyToken)
+// Así la lista de revocación se aplica en todos los servicios que aceptan el token, no solo en users-api
+type TokenService interface {
+	// Verify valida firma, vencimiento y revocación; ok es false si el token no es válido o fue revocado
+	// err solo se retorna si no se pudo consultar la lista de revocación
+	Verify(token string) (claims *utils.Claims, ok bool, err error)
+}
+
+type tokenService struct {
+	revocations repositories.TokenRevocationRepository
+}
+
+// NewTokenService crea el servicio de validación de tokens
+func NewTokenService(revocations repositories.TokenRevocationRepository) TokenService {
+	return &tokenService{revocations: revocations}
+}
+
+// Verify valida el token igual que AuthMiddleware
+func (s *tokenService) Verify(token string) (*utils.Claims, bool, error) {
+	claims, err := utils.ValidateToken(token)
+	if err != nil {
+		return nil, false, nil
+	}
+
+	revokedAt, err := s.revocations.GetRevokedAt(claims.UserID)
+	if err != nil {
+		return nil, false, err
+	}
+	if utils.IsTokenRevoked(claims, revokedAt) {
+		return nil, false, nil
+	}
+	return claims, true, nil
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"users-api/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// failingRevocationRepository simula MySQL caído al consultar la lista de revocación
+type failingRevocationRepository struct {
+	mockTokenRevocationRepository
+}
+
+func (m *failingRevocationRepository) GetRevokedAt(userID uint) (*time.Time, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestTokenService_Verify(t *testing.T) {
+	revocations := &mockTokenRevocationRepository{revoked: make(map[uint]time.Time)}
+	service := NewTokenService(revocations)
+
+	before, _ := utils.GenerateToken(7, "maria", "admin")
+	if claims, ok, err := service.Verify(before); err != nil || !ok || claims.UserType != "admin" {
+		t.Fatalf("expected a valid admin token, got %+v, %v, %v", claims, ok, err)
+	}
+
+	// Logout en todos lados (o el admin pasó a normal): el token anterior deja de valer y el nuevo sí
+	revocations.Revoke(7, "rol cambiado de admin a normal")
+	after, _ := utils.GenerateToken(7, "maria", "normal")
+	if _, ok, err := service.Verify(before); err != nil || ok {
+		t.Fatalf("expected the token issued before the revocation rejected, got ok=%v, %v", ok, err)
+	}
+	if claims, ok, err := service.Verify(after); err != nil || !ok || claims.UserType != "normal" {
+		t.Fatalf("expected the token issued after the revocation accepted, got %+v, %v, %v", claims, ok, err)
+	}
+
+	if _, ok, err := service.Verify("no-es-un-jwt"); err != nil || ok {
+		t.Fatalf("expected an invalid token rejected without error, got ok=%v, %v", ok, err)
+	}
+	if _, _, err := NewTokenService(&failingRevocationRepository{}).Verify(after); err == nil {
+		t.Fatal("expected an error when the revocation list can't be read")
+	}
+}
+
+func TestIsTokenRevoked(t *testing.T) {
+	revokedAt := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		issuedAt *jwt.NumericDate
+		revoked  *time.Time
+		want     bool
+	}{
+		{name: "no revocation", issuedAt: jwt.NewNumericDate(revokedAt), want: false},
+		{name: "issued before", issuedAt: jwt.NewNumericDate(revokedAt.Add(-time.Second)), revoked: &revokedAt, want: true},
+		// Los tokens de versiones anteriores tienen iat en segundos: el del mismo segundo no se distingue
+		{name: "issued at the same instant", issuedAt: jwt.NewNumericDate(revokedAt), revoked: &revokedAt, want: true},
+		{name: "issued after", issuedAt: jwt.NewNumericDate(revokedAt.Add(time.Millisecond)), revoked: &revokedAt, want: false},
+		{name: "without iat", revoked: &revokedAt, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := &utils.Claims{RegisteredClaims: jwt.RegisteredClaims{IssuedAt: tt.issuedAt}}
+			if got := utils.IsTokenRevoked(claims, tt.revoked); got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
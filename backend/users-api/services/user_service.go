@@ -2,28 +2,41 @@ package services
 
 import (
 	"errors"
+	"log"
+	"strings"
+	"time"
 	"users-api/domain"
 	"users-api/dto"
 	"users-api/repositories"
 	"users-api/utils"
 )
 
+// defaultUsersPageSize es la cantidad de usuarios por página si no se indica limit
+const defaultUsersPageSize = 20
+
 type UserService interface {
 	CreateUser(userDTO dto.CreateUserRequest) (dto.UserResponse, error)
 	Login(loginDTO dto.LoginRequest) (dto.LoginResponse, error)
 	GetUserByID(id uint) (dto.UserResponse, error)
-	UpdateUser(id uint, updateDTO dto.UpdateUserRequest) error
-	DeleteUser(id uint) error
-	GetAllUsers() ([]dto.UserResponse, error)
+	UpdateUser(actorID, id uint, updateDTO dto.UpdateUserRequest) error
+	DeleteUser(actorID, id uint) error
+	ListUsers(query dto.ListUsersQuery) (dto.UserListResponse, error)
+	CountSignupsByDay(from, to time.Time) (map[string]int64, error)
 }
 
 type userService struct {
-	repo repositories.UserRepository
+	repo         repositories.UserRepository
+	loginHistory LoginHistoryService
+	audit        AuditService
+	passwords    PasswordPolicy
 }
 
-func NewUserService(repo repositories.UserRepository) UserService {
+func NewUserService(repo repositories.UserRepository, loginHistory LoginHistoryService, audit AuditService, passwords PasswordPolicy) UserService {
 	return &userService{
-		repo: repo,
+		repo:         repo,
+		loginHistory: loginHistory,
+		audit:        audit,
+		passwords:    passwords,
 	}
 }
 
@@ -41,6 +54,11 @@ func (s *userService) CreateUser(userDTO dto.CreateUserRequest) (dto.UserRespons
 		return dto.UserResponse{}, errors.New("el email ya existe")
 	}
 
+	// Validar la contraseña contra la política (largo, tipos de caracteres y filtraciones)
+	if err := s.passwords.Validate(userDTO.Password); err != nil {
+		return dto.UserResponse{}, err
+	}
+
 	// Hashear la contraseña
 	hashedPassword, err := utils.HashPassword(userDTO.Password)
 	if err != nil {
@@ -55,6 +73,7 @@ func (s *userService) CreateUser(userDTO dto.CreateUserRequest) (dto.UserRespons
 		FirstName: userDTO.FirstName,
 		LastName:  userDTO.LastName,
 		UserType:  "normal", // Por defecto todos los usuarios son normales
+		Active:    true,
 	}
 
 	// Guardar en la base de datos
@@ -63,6 +82,9 @@ func (s *userService) CreateUser(userDTO dto.CreateUserRequest) (dto.UserRespons
 		return dto.UserResponse{}, err
 	}
 
+	// El registro lo hace el propio usuario
+	s.audit.Record(user.ID, AuditActionUserCreate, auditEntityUser, auditUserID(user.ID), nil, userAuditState(user))
+
 	// Retornar el DTO de respuesta (sin la contraseña)
 	return s.toDTO(user), nil
 }
@@ -85,15 +107,29 @@ func (s *userService) Login(loginDTO dto.LoginRequest) (dto.LoginResponse, error
 
 	// Verificar la contraseña
 	if !utils.CheckPasswordHash(loginDTO.Password, user.Password) {
+		s.loginHistory.RecordLogin(*user, loginDTO.Client, false)
 		return dto.LoginResponse{}, errors.New("credenciales inválidas")
 	}
 
+	// Las cuentas desactivadas no pueden iniciar sesión
+	// (se valida después de la contraseña para no revelar el estado de la cuenta)
+	if !user.Active {
+		s.loginHistory.RecordLogin(*user, loginDTO.Client, false)
+		return dto.LoginResponse{}, ErrUserInactive
+	}
+
+	// Migrar el hash (bcrypt o parámetros de Argon2id viejos) ahora que se tiene la contraseña
+	s.rehashPassword(user, loginDTO.Password)
+
 	// Generar token JWT
 	token, err := utils.GenerateToken(user.ID, user.Username, user.UserType)
 	if err != nil {
 		return dto.LoginResponse{}, errors.New("error generando token")
 	}
 
+	// Registrar el login (y detectar si es sospechoso)
+	s.loginHistory.RecordLogin(*user, loginDTO.Client, true)
+
 	// Retornar respuesta con token y datos del usuario
 	return dto.LoginResponse{
 		Token: token,
@@ -101,6 +137,27 @@ func (s *userService) Login(loginDTO dto.LoginRequest) (dto.LoginResponse, error
 	}, nil
 }
 
+// rehashPassword guarda la contraseña con los parámetros vigentes de Argon2id si el hash es de otro formato
+// Un error no impide el login: se vuelve a intentar en el próximo
+func (s *userService) rehashPassword(user *domain.User, password string) {
+	if !utils.NeedsRehash(user.Password) {
+		return
+	}
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		log.Printf("⚠️ Error rehasheando la contraseña del usuario %d: %v", user.ID, err)
+		return
+	}
+	previous := user.Password
+	user.Password = hashedPassword
+	if err := s.repo.Update(user); err != nil {
+		user.Password = previous
+		log.Printf("⚠️ Error guardando la contraseña rehasheada del usuario %d: %v", user.ID, err)
+		return
+	}
+	log.Printf("🔐 Contraseña del usuario %d migrada a Argon2id", user.ID)
+}
+
 // GetUserByID obtiene un usuario por su ID
 func (s *userService) GetUserByID(id uint) (dto.UserResponse, error) {
 	user, err := s.repo.GetByID(id)
@@ -115,12 +172,13 @@ func (s *userService) GetUserByID(id uint) (dto.UserResponse, error) {
 }
 
 // UpdateUser actualiza los datos de un usuario
-func (s *userService) UpdateUser(id uint, updateDTO dto.UpdateUserRequest) error {
+func (s *userService) UpdateUser(actorID, id uint, updateDTO dto.UpdateUserRequest) error {
 	// Obtener usuario existente
 	user, err := s.repo.GetByID(id)
 	if err != nil || user == nil {
 		return errors.New("usuario no encontrado")
 	}
+	before := userAuditState(*user)
 
 	// Actualizar solo los campos que vienen en el DTO
 	if updateDTO.Email != nil {
@@ -141,6 +199,10 @@ func (s *userService) UpdateUser(id uint, updateDTO dto.UpdateUserRequest) error
 	}
 
 	if updateDTO.Password != nil {
+		if err := s.passwords.Validate(*updateDTO.Password); err != nil {
+			return err
+		}
+
 		// Hashear la nueva contraseña
 		hashedPassword, err := utils.HashPassword(*updateDTO.Password)
 		if err != nil {
@@ -150,25 +212,48 @@ func (s *userService) UpdateUser(id uint, updateDTO dto.UpdateUserRequest) error
 	}
 
 	// Guardar cambios
-	return s.repo.Update(user)
+	if err := s.repo.Update(user); err != nil {
+		return err
+	}
+
+	s.audit.Record(actorID, AuditActionUserUpdate, auditEntityUser, auditUserID(id), before, userAuditState(*user))
+	return nil
 }
 
 // DeleteUser elimina un usuario por su ID
-func (s *userService) DeleteUser(id uint) error {
+func (s *userService) DeleteUser(actorID, id uint) error {
 	// Verificar que el usuario existe
 	user, err := s.repo.GetByID(id)
 	if err != nil || user == nil {
 		return errors.New("usuario no encontrado")
 	}
 
-	return s.repo.Delete(id)
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+
+	s.audit.Record(actorID, AuditActionUserDelete, auditEntityUser, auditUserID(id), userAuditState(*user), nil)
+	return nil
 }
 
-// GetAllUsers obtiene todos los usuarios (solo para admin)
-func (s *userService) GetAllUsers() ([]dto.UserResponse, error) {
-	users, err := s.repo.GetAll()
+// ListUsers obtiene una página de usuarios filtrada por tipo y búsqueda (solo para admin)
+func (s *userService) ListUsers(query dto.ListUsersQuery) (dto.UserListResponse, error) {
+	// Valores por defecto de la paginación
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.Limit < 1 {
+		query.Limit = defaultUsersPageSize
+	}
+
+	users, total, err := s.repo.List(repositories.UserFilter{
+		Page:     query.Page,
+		Limit:    query.Limit,
+		UserType: query.UserType,
+		Search:   strings.TrimSpace(query.Search),
+	})
 	if err != nil {
-		return nil, err
+		return dto.UserListResponse{}, err
 	}
 
 	// Convertir cada usuario a DTO
@@ -177,7 +262,24 @@ func (s *userService) GetAllUsers() ([]dto.UserResponse, error) {
 		userDTOs[i] = s.toDTO(user)
 	}
 
-	return userDTOs, nil
+	return dto.UserListResponse{
+		Users:      userDTOs,
+		Page:       query.Page,
+		Limit:      query.Limit,
+		Total:      total,
+		TotalPages: int((total + int64(query.Limit) - 1) / int64(query.Limit)),
+	}, nil
+}
+
+// maxSignupsRange es el período más largo que se puede contar en una llamada a CountSignupsByDay
+const maxSignupsRange = 366 * 24 * time.Hour
+
+// CountSignupsByDay cuenta los registros por día UTC en [from, to) (lo usan las métricas de properties-api)
+func (s *userService) CountSignupsByDay(from, to time.Time) (map[string]int64, error) {
+	if !from.Before(to) || to.Sub(from) > maxSignupsRange {
+		return nil, ErrInvalidDateRange
+	}
+	return s.repo.CountCreatedByDay(from, to)
 }
 
 // toDTO convierte un domain.User a dto.UserResponse
@@ -189,5 +291,8 @@ func (s *userService) toDTO(user domain.User) dto.UserResponse {
 		FirstName: user.FirstName,
 		LastName:  user.LastName,
 		UserType:  user.UserType,
+		Active:    user.Active,
+		Verified:  user.Verified,
+		AvatarURL: user.AvatarURL,
 	}
 }
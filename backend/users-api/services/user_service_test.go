@@ -1,10 +1,23 @@
 package services
 
 import (
+	"bytes"
 	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"sort"
+	"strings"
 	"testing"
+	"time"
+	"users-api/clients"
 	"users-api/domain"
 	"users-api/dto"
+	"users-api/repositories"
+	"users-api/utils"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
 // ============================================
@@ -23,6 +36,8 @@ func newMockUserRepository() *mockUserRepository {
 func (m *mockUserRepository) Create(user *domain.User) error {
 	// Simular auto-increment del ID
 	user.ID = uint(len(m.users) + 1)
+	// Simular el default de la columna active (GORM no inserta el valor cero)
+	user.Active = true
 	m.users[user.ID] = user
 	return nil
 }
@@ -61,6 +76,50 @@ func (m *mockUserRepository) Update(user *domain.User) error {
 	return nil
 }
 
+func (m *mockUserRepository) List(filter repositories.UserFilter) ([]domain.User, int64, error) {
+	var matched []domain.User
+	for _, user := range m.users {
+		if filter.UserType != "" && user.UserType != filter.UserType {
+			continue
+		}
+		if filter.Search != "" && !strings.Contains(user.Username, filter.Search) && !strings.Contains(user.Email, filter.Search) {
+			continue
+		}
+		matched = append(matched, *user)
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID < matched[j].ID })
+
+	start := (filter.Page - 1) * filter.Limit
+	if start > len(matched) {
+		start = len(matched)
+	}
+	end := start + filter.Limit
+	if end > len(matched) {
+		end = len(matched)
+	}
+	return matched[start:end], int64(len(matched)), nil
+}
+
+func (m *mockUserRepository) CountByUserType(userType string) (int64, error) {
+	var count int64
+	for _, user := range m.users {
+		if user.UserType == userType {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *mockUserRepository) CountCreatedByDay(from, to time.Time) (map[string]int64, error) {
+	counts := make(map[string]int64)
+	for _, user := range m.users {
+		if !user.CreatedAt.Before(from) && user.CreatedAt.Before(to) {
+			counts[user.CreatedAt.UTC().Format("2006-01-02")]++
+		}
+	}
+	return counts, nil
+}
+
 func (m *mockUserRepository) Delete(id uint) error {
 	if _, exists := m.users[id]; !exists {
 		return errors.New("user not found")
@@ -69,6 +128,286 @@ func (m *mockUserRepository) Delete(id uint) error {
 	return nil
 }
 
+type mockRoleChangeRepository struct {
+	changes []domain.RoleChange
+}
+
+func (m *mockRoleChangeRepository) Create(change *domain.RoleChange) error {
+	m.changes = append(m.changes, *change)
+	return nil
+}
+
+type mockTokenRevocationRepository struct {
+	revoked map[uint]time.Time
+}
+
+func (m *mockTokenRevocationRepository) Revoke(userID uint, reason string) error {
+	// Igual que el repositorio real: milisegundos y los tokens emitidos después de Revoke son válidos
+	revokedAt := time.Now().Truncate(time.Millisecond)
+	m.revoked[userID] = revokedAt
+	time.Sleep(time.Until(revokedAt.Add(2 * time.Millisecond)))
+	return nil
+}
+
+func (m *mockTokenRevocationRepository) GetRevokedAt(userID uint) (*time.Time, error) {
+	revokedAt, exists := m.revoked[userID]
+	if !exists {
+		return nil, nil
+	}
+	return &revokedAt, nil
+}
+
+type mockLoginEventRepository struct {
+	events []domain.LoginEvent
+}
+
+func (m *mockLoginEventRepository) Create(event *domain.LoginEvent) error {
+	event.ID = uint(len(m.events) + 1)
+	m.events = append(m.events, *event)
+	return nil
+}
+
+func (m *mockLoginEventRepository) GetByUserID(userID uint, limit int) ([]domain.LoginEvent, error) {
+	var events []domain.LoginEvent
+	for i := len(m.events) - 1; i >= 0 && (limit < 0 || len(events) < limit); i-- {
+		if m.events[i].UserID == userID {
+			events = append(events, m.events[i])
+		}
+	}
+	return events, nil
+}
+
+func (m *mockLoginEventRepository) CountSuccessful(userID uint) (int64, error) {
+	var count int64
+	for _, event := range m.events {
+		if event.UserID == userID && event.Success {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *mockLoginEventRepository) HasSuccessfulFromDevice(userID uint, deviceHash string) (bool, error) {
+	for _, event := range m.events {
+		if event.UserID == userID && event.Success && event.DeviceHash == deviceHash {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockLoginEventRepository) HasSuccessfulFromCountry(userID uint, country string) (bool, error) {
+	for _, event := range m.events {
+		if event.UserID == userID && event.Success && event.Country == country {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockLoginEventRepository) DeleteByUserID(userID uint) error {
+	kept := m.events[:0]
+	for _, event := range m.events {
+		if event.UserID != userID {
+			kept = append(kept, event)
+		}
+	}
+	m.events = kept
+	return nil
+}
+
+type mockFavoriteRepository struct {
+	favorites []domain.Favorite
+}
+
+func (m *mockFavoriteRepository) Add(favorite *domain.Favorite) error {
+	for _, existing := range m.favorites {
+		if existing.UserID == favorite.UserID && existing.PropertyID == favorite.PropertyID {
+			return nil
+		}
+	}
+	favorite.ID = uint(len(m.favorites) + 1)
+	m.favorites = append(m.favorites, *favorite)
+	return nil
+}
+
+func (m *mockFavoriteRepository) Remove(userID uint, propertyID string) (bool, error) {
+	for i, favorite := range m.favorites {
+		if favorite.UserID == userID && favorite.PropertyID == propertyID {
+			m.favorites = append(m.favorites[:i], m.favorites[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockFavoriteRepository) GetByUserID(userID uint) ([]domain.Favorite, error) {
+	var favorites []domain.Favorite
+	for i := len(m.favorites) - 1; i >= 0; i-- {
+		if m.favorites[i].UserID == userID {
+			favorites = append(favorites, m.favorites[i])
+		}
+	}
+	return favorites, nil
+}
+
+func (m *mockFavoriteRepository) CountByUserID(userID uint) (int64, error) {
+	favorites, _ := m.GetByUserID(userID)
+	return int64(len(favorites)), nil
+}
+
+func (m *mockFavoriteRepository) DeleteByUserID(userID uint) error {
+	kept := m.favorites[:0]
+	for _, favorite := range m.favorites {
+		if favorite.UserID != userID {
+			kept = append(kept, favorite)
+		}
+	}
+	m.favorites = kept
+	return nil
+}
+
+type mockSecurityAlertPublisher struct {
+	alerts []clients.SecurityAlertEvent
+}
+
+func (m *mockSecurityAlertPublisher) PublishSecurityAlert(alert clients.SecurityAlertEvent) error {
+	m.alerts = append(m.alerts, alert)
+	return nil
+}
+
+type mockUserEventPublisher struct {
+	events []clients.UserEvent
+}
+
+func (m *mockUserEventPublisher) PublishUserEvent(event clients.UserEvent) error {
+	m.events = append(m.events, event)
+	return nil
+}
+
+func (m *mockUserEventPublisher) Ping() error {
+	return nil
+}
+
+// mockBreachedPasswordChecker simula la API de contraseñas filtradas
+type mockBreachedPasswordChecker struct {
+	breached map[string]int
+	err      error
+	calls    int
+}
+
+func (m *mockBreachedPasswordChecker) BreachCount(password string) (int, error) {
+	m.calls++
+	if m.err != nil {
+		return 0, m.err
+	}
+	return m.breached[password], nil
+}
+
+type mockAvatarStorage struct {
+	files map[string][]byte
+}
+
+func (m *mockAvatarStorage) Save(key string, data []byte) (string, error) {
+	m.files[key] = data
+	return "http://localhost:8081/media/" + key, nil
+}
+
+func (m *mockAvatarStorage) Delete(key string) error {
+	delete(m.files, key)
+	return nil
+}
+
+type mockAPIKeyRepository struct {
+	keys []domain.APIKey
+}
+
+func (m *mockAPIKeyRepository) Create(key *domain.APIKey) error {
+	key.ID = uint(len(m.keys) + 1)
+	key.CreatedAt = time.Now().UTC()
+	m.keys = append(m.keys, *key)
+	return nil
+}
+
+func (m *mockAPIKeyRepository) GetByID(id uint) (*domain.APIKey, error) {
+	for i := range m.keys {
+		if m.keys[i].ID == id {
+			key := m.keys[i]
+			return &key, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockAPIKeyRepository) GetByPrefix(prefix string) (*domain.APIKey, error) {
+	for i := range m.keys {
+		if m.keys[i].Prefix == prefix {
+			key := m.keys[i]
+			return &key, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockAPIKeyRepository) List() ([]domain.APIKey, error) {
+	return m.keys, nil
+}
+
+func (m *mockAPIKeyRepository) Revoke(id uint, at time.Time) error {
+	for i := range m.keys {
+		if m.keys[i].ID == id && m.keys[i].RevokedAt == nil {
+			m.keys[i].RevokedAt = &at
+		}
+	}
+	return nil
+}
+
+func (m *mockAPIKeyRepository) TouchLastUsed(id uint, at time.Time) error {
+	for i := range m.keys {
+		if m.keys[i].ID == id {
+			m.keys[i].LastUsedAt = &at
+		}
+	}
+	return nil
+}
+
+type mockAuditRepository struct {
+	entries []domain.AuditEntry
+}
+
+func (m *mockAuditRepository) Create(entry *domain.AuditEntry) error {
+	entry.ID = uint(len(m.entries) + 1)
+	entry.CreatedAt = time.Now().UTC()
+	m.entries = append(m.entries, *entry)
+	return nil
+}
+
+func (m *mockAuditRepository) Find(filter repositories.AuditFilter) ([]domain.AuditEntry, int64, error) {
+	var matched []domain.AuditEntry
+	for _, entry := range m.entries {
+		if filter.ActorID != 0 && entry.ActorID != filter.ActorID {
+			continue
+		}
+		matched = append(matched, entry)
+	}
+	return matched, int64(len(matched)), nil
+}
+
+// newTestAuditService crea un AuditService con el log en memoria
+func newTestAuditService() AuditService {
+	return NewAuditService(&mockAuditRepository{})
+}
+
+// newTestUserService crea un UserService con historial de logins en memoria
+func newTestUserService(repo *mockUserRepository) UserService {
+	return NewUserService(repo, NewLoginHistoryService(&mockLoginEventRepository{}, nil), newTestAuditService(), newTestPasswordPolicy())
+}
+
+// newTestPasswordPolicy crea una política que solo exige el largo y no consulta filtraciones
+func newTestPasswordPolicy() PasswordPolicy {
+	return NewPasswordPolicy(PasswordPolicyConfig{MinLength: 8, MaxLength: 64}, nil)
+}
+
 // ============================================
 // TESTS
 // ============================================
@@ -76,7 +415,7 @@ func (m *mockUserRepository) Delete(id uint) error {
 // Test: Crear usuario exitosamente
 func TestCreateUser_Success(t *testing.T) {
 	repo := newMockUserRepository()
-	service := NewUserService(repo)
+	service := newTestUserService(repo)
 
 	req := dto.CreateUserRequest{
 		Username:  "testuser",
@@ -93,8 +432,8 @@ func TestCreateUser_Success(t *testing.T) {
 		t.Errorf("Expected no error, got %v", err)
 	}
 
-	if user == nil {
-		t.Fatal("Expected user, got nil")
+	if user.ID == 0 {
+		t.Fatal("Expected user, got empty response")
 	}
 
 	if user.Username != req.Username {
@@ -105,12 +444,12 @@ func TestCreateUser_Success(t *testing.T) {
 		t.Errorf("Expected email %s, got %s", req.Email, user.Email)
 	}
 
-	if user.UserType != domain.UserTypeNormal {
+	if user.UserType != string(domain.UserTypeNormal) {
 		t.Errorf("Expected user type %s, got %s", domain.UserTypeNormal, user.UserType)
 	}
 
 	// Verificar que la contraseña fue hasheada (no es la original)
-	if user.Password == req.Password {
+	if repo.users[user.ID].Password == req.Password {
 		t.Error("Password should be hashed, not plain text")
 	}
 }
@@ -118,7 +457,7 @@ func TestCreateUser_Success(t *testing.T) {
 // Test: Error al crear usuario con username duplicado
 func TestCreateUser_DuplicateUsername(t *testing.T) {
 	repo := newMockUserRepository()
-	service := NewUserService(repo)
+	service := newTestUserService(repo)
 
 	// Crear primer usuario
 	req1 := dto.CreateUserRequest{
@@ -146,19 +485,19 @@ func TestCreateUser_DuplicateUsername(t *testing.T) {
 		t.Error("Expected error for duplicate username, got nil")
 	}
 
-	if user != nil {
-		t.Error("Expected nil user, got user")
+	if user.ID != 0 {
+		t.Error("Expected empty user, got user")
 	}
 
-	if err.Error() != "username already exists" {
-		t.Errorf("Expected 'username already exists' error, got %v", err)
+	if err.Error() != "el username ya existe" {
+		t.Errorf("Expected 'el username ya existe' error, got %v", err)
 	}
 }
 
 // Test: Error al crear usuario con email duplicado
 func TestCreateUser_DuplicateEmail(t *testing.T) {
 	repo := newMockUserRepository()
-	service := NewUserService(repo)
+	service := newTestUserService(repo)
 
 	// Crear primer usuario
 	req1 := dto.CreateUserRequest{
@@ -186,19 +525,19 @@ func TestCreateUser_DuplicateEmail(t *testing.T) {
 		t.Error("Expected error for duplicate email, got nil")
 	}
 
-	if user != nil {
-		t.Error("Expected nil user, got user")
+	if user.ID != 0 {
+		t.Error("Expected empty user, got user")
 	}
 
-	if err.Error() != "email already exists" {
-		t.Errorf("Expected 'email already exists' error, got %v", err)
+	if err.Error() != "el email ya existe" {
+		t.Errorf("Expected 'el email ya existe' error, got %v", err)
 	}
 }
 
 // Test: Login exitoso con username
 func TestLogin_SuccessWithUsername(t *testing.T) {
 	repo := newMockUserRepository()
-	service := NewUserService(repo)
+	service := newTestUserService(repo)
 
 	// Crear usuario
 	createReq := dto.CreateUserRequest{
@@ -223,8 +562,8 @@ func TestLogin_SuccessWithUsername(t *testing.T) {
 		t.Errorf("Expected no error, got %v", err)
 	}
 
-	if response == nil {
-		t.Fatal("Expected login response, got nil")
+	if response.User.ID == 0 {
+		t.Fatal("Expected login response, got empty response")
 	}
 
 	if response.Token == "" {
@@ -239,7 +578,7 @@ func TestLogin_SuccessWithUsername(t *testing.T) {
 // Test: Login exitoso con email
 func TestLogin_SuccessWithEmail(t *testing.T) {
 	repo := newMockUserRepository()
-	service := NewUserService(repo)
+	service := newTestUserService(repo)
 
 	// Crear usuario
 	createReq := dto.CreateUserRequest{
@@ -264,8 +603,8 @@ func TestLogin_SuccessWithEmail(t *testing.T) {
 		t.Errorf("Expected no error, got %v", err)
 	}
 
-	if response == nil {
-		t.Fatal("Expected login response, got nil")
+	if response.User.ID == 0 {
+		t.Fatal("Expected login response, got empty response")
 	}
 
 	if response.Token == "" {
@@ -276,7 +615,7 @@ func TestLogin_SuccessWithEmail(t *testing.T) {
 // Test: Login fallido - usuario no existe
 func TestLogin_UserNotFound(t *testing.T) {
 	repo := newMockUserRepository()
-	service := NewUserService(repo)
+	service := newTestUserService(repo)
 
 	loginReq := dto.LoginRequest{
 		UsernameOrEmail: "nonexistent",
@@ -290,19 +629,19 @@ func TestLogin_UserNotFound(t *testing.T) {
 		t.Error("Expected error for non-existent user, got nil")
 	}
 
-	if response != nil {
-		t.Error("Expected nil response, got response")
+	if response.Token != "" {
+		t.Error("Expected empty response, got response")
 	}
 
-	if err.Error() != "invalid credentials" {
-		t.Errorf("Expected 'invalid credentials' error, got %v", err)
+	if err.Error() != "credenciales inválidas" {
+		t.Errorf("Expected 'credenciales inválidas' error, got %v", err)
 	}
 }
 
 // Test: Login fallido - contraseña incorrecta
 func TestLogin_WrongPassword(t *testing.T) {
 	repo := newMockUserRepository()
-	service := NewUserService(repo)
+	service := newTestUserService(repo)
 
 	// Crear usuario
 	createReq := dto.CreateUserRequest{
@@ -327,19 +666,61 @@ func TestLogin_WrongPassword(t *testing.T) {
 		t.Error("Expected error for wrong password, got nil")
 	}
 
-	if response != nil {
-		t.Error("Expected nil response, got response")
+	if response.Token != "" {
+		t.Error("Expected empty response, got response")
 	}
 
-	if err.Error() != "invalid credentials" {
-		t.Errorf("Expected 'invalid credentials' error, got %v", err)
+	if err.Error() != "credenciales inválidas" {
+		t.Errorf("Expected 'credenciales inválidas' error, got %v", err)
+	}
+}
+
+// Test: El login migra a Argon2id los hashes de bcrypt y los de parámetros viejos
+func TestLogin_RehashesLegacyPasswordHashes(t *testing.T) {
+	repo := newMockUserRepository()
+	service := newTestUserService(repo)
+
+	legacy, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	repo.Create(&domain.User{Username: "john", Email: "john@example.com", Password: string(legacy), UserType: "normal"})
+
+	// Una contraseña incorrecta no toca el hash
+	service.Login(dto.LoginRequest{UsernameOrEmail: "john", Password: "wrongpassword"})
+	if repo.users[1].Password != string(legacy) {
+		t.Fatal("Expected a failed login to keep the bcrypt hash")
+	}
+
+	if _, err := service.Login(dto.LoginRequest{UsernameOrEmail: "john", Password: "password123"}); err != nil {
+		t.Fatalf("Expected login with a bcrypt hash, got %v", err)
+	}
+	migrated := repo.users[1].Password
+	if !strings.HasPrefix(migrated, "$argon2id$v=19$") || utils.NeedsRehash(migrated) {
+		t.Fatalf("Expected the hash to be migrated to argon2id, got %q", migrated)
+	}
+	if _, err := service.Login(dto.LoginRequest{UsernameOrEmail: "john", Password: "password123"}); err != nil {
+		t.Fatalf("Expected login with the argon2id hash, got %v", err)
+	}
+	if repo.users[1].Password != migrated {
+		t.Error("Expected an up to date hash not to be rehashed")
+	}
+
+	// Al cambiar los parámetros el hash se vuelve a generar con los nuevos
+	params := utils.DefaultArgon2Params
+	params.Iterations = 3
+	utils.SetArgon2Params(params)
+	defer utils.SetArgon2Params(utils.DefaultArgon2Params)
+
+	if _, err := service.Login(dto.LoginRequest{UsernameOrEmail: "john", Password: "password123"}); err != nil {
+		t.Fatalf("Expected login with the previous parameters, got %v", err)
+	}
+	if hash := repo.users[1].Password; !strings.Contains(hash, ",t=3,") {
+		t.Errorf("Expected the hash to use the new parameters, got %q", hash)
 	}
 }
 
 // Test: Obtener usuario por ID exitosamente
 func TestGetUserByID_Success(t *testing.T) {
 	repo := newMockUserRepository()
-	service := NewUserService(repo)
+	service := newTestUserService(repo)
 
 	// Crear usuario
 	createReq := dto.CreateUserRequest{
@@ -359,8 +740,8 @@ func TestGetUserByID_Success(t *testing.T) {
 		t.Errorf("Expected no error, got %v", err)
 	}
 
-	if user == nil {
-		t.Fatal("Expected user, got nil")
+	if user.ID == 0 {
+		t.Fatal("Expected user, got empty response")
 	}
 
 	if user.ID != createdUser.ID {
@@ -371,7 +752,7 @@ func TestGetUserByID_Success(t *testing.T) {
 // Test: Error al obtener usuario que no existe
 func TestGetUserByID_NotFound(t *testing.T) {
 	repo := newMockUserRepository()
-	service := NewUserService(repo)
+	service := newTestUserService(repo)
 
 	// Intentar obtener usuario con ID inexistente
 	user, err := service.GetUserByID(999)
@@ -381,7 +762,705 @@ func TestGetUserByID_NotFound(t *testing.T) {
 		t.Error("Expected error for non-existent user, got nil")
 	}
 
-	if user != nil {
-		t.Error("Expected nil user, got user")
+	if user.ID != 0 {
+		t.Error("Expected empty user, got user")
+	}
+}
+
+// Test: Bootstrap crea el admin inicial desde el entorno
+func TestEnsureInitialAdmin_FromEnv(t *testing.T) {
+	repo := newMockUserRepository()
+	bootstrap := NewAdminBootstrapService(repo, newTestAuditService(), newTestPasswordPolicy(), AdminBootstrapConfig{
+		Username: "admin",
+		Email:    "admin@example.com",
+		Password: "password123",
+	})
+
+	if err := bootstrap.EnsureInitialAdmin(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	admin, err := repo.GetByUsername("admin")
+	if err != nil {
+		t.Fatalf("Expected admin to be created, got %v", err)
+	}
+	if admin.UserType != string(domain.UserTypeAdmin) {
+		t.Errorf("Expected user type %s, got %s", domain.UserTypeAdmin, admin.UserType)
+	}
+
+	// Un segundo bootstrap no debe crear otro admin
+	if err := bootstrap.EnsureInitialAdmin(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(repo.users) != 1 {
+		t.Errorf("Expected 1 user, got %d", len(repo.users))
+	}
+}
+
+// Test: El token de setup solo sirve una vez
+func TestSetupAdmin_TokenIsSingleUse(t *testing.T) {
+	repo := newMockUserRepository()
+	bootstrap := NewAdminBootstrapService(repo, newTestAuditService(), newTestPasswordPolicy(), AdminBootstrapConfig{SetupToken: "setup-token"})
+
+	if err := bootstrap.EnsureInitialAdmin(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	req := dto.CreateUserRequest{
+		Username:  "admin",
+		Email:     "admin@example.com",
+		Password:  "password123",
+		FirstName: "Admin",
+		LastName:  "User",
+	}
+
+	if _, err := bootstrap.SetupAdmin("wrong-token", req); err == nil {
+		t.Error("Expected error for wrong setup token, got nil")
+	}
+
+	user, err := bootstrap.SetupAdmin("setup-token", req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if user.UserType != string(domain.UserTypeAdmin) {
+		t.Errorf("Expected user type %s, got %s", domain.UserTypeAdmin, user.UserType)
+	}
+
+	req.Username = "admin2"
+	req.Email = "admin2@example.com"
+	if _, err := bootstrap.SetupAdmin("setup-token", req); err == nil {
+		t.Error("Expected error when reusing setup token, got nil")
+	}
+}
+
+// Test: No se puede degradar al último admin
+func TestChangeUserRole_CannotDemoteLastAdmin(t *testing.T) {
+	repo := newMockUserRepository()
+	repo.Create(&domain.User{Username: "admin", Email: "admin@example.com", UserType: "admin"})
+	audit := &mockRoleChangeRepository{}
+	revocations := &mockTokenRevocationRepository{revoked: make(map[uint]time.Time)}
+	service := NewRoleService(repo, audit, revocations, newTestAuditService())
+
+	_, err := service.ChangeUserRole(1, 1, "normal")
+	if !errors.Is(err, ErrLastAdmin) {
+		t.Fatalf("Expected ErrLastAdmin, got %v", err)
+	}
+	if repo.users[1].UserType != "admin" {
+		t.Errorf("Expected user to remain admin, got %s", repo.users[1].UserType)
+	}
+	if len(audit.changes) != 0 {
+		t.Errorf("Expected no audit records, got %d", len(audit.changes))
+	}
+}
+
+// Test: Degradar un admin audita el cambio y revoca sus tokens
+func TestChangeUserRole_DemoteAuditsAndRevokes(t *testing.T) {
+	repo := newMockUserRepository()
+	repo.Create(&domain.User{Username: "admin1", Email: "admin1@example.com", UserType: "admin"})
+	repo.Create(&domain.User{Username: "admin2", Email: "admin2@example.com", UserType: "admin"})
+	audit := &mockRoleChangeRepository{}
+	revocations := &mockTokenRevocationRepository{revoked: make(map[uint]time.Time)}
+	service := NewRoleService(repo, audit, revocations, newTestAuditService())
+
+	user, err := service.ChangeUserRole(1, 2, "normal")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if user.UserType != "normal" {
+		t.Errorf("Expected user type normal, got %s", user.UserType)
+	}
+
+	if len(audit.changes) != 1 {
+		t.Fatalf("Expected 1 audit record, got %d", len(audit.changes))
+	}
+	change := audit.changes[0]
+	if change.UserID != 2 || change.ChangedBy != 1 || change.OldRole != "admin" || change.NewRole != "normal" {
+		t.Errorf("Unexpected audit record: %+v", change)
+	}
+
+	if _, revoked := revocations.revoked[2]; !revoked {
+		t.Error("Expected tokens of demoted user to be revoked")
+	}
+}
+
+// Test: Promover a admin no revoca tokens
+func TestChangeUserRole_PromoteDoesNotRevoke(t *testing.T) {
+	repo := newMockUserRepository()
+	repo.Create(&domain.User{Username: "john", Email: "john@example.com", UserType: "normal"})
+	audit := &mockRoleChangeRepository{}
+	revocations := &mockTokenRevocationRepository{revoked: make(map[uint]time.Time)}
+	service := NewRoleService(repo, audit, revocations, newTestAuditService())
+
+	if _, err := service.ChangeUserRole(99, 1, "admin"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if repo.users[1].UserType != "admin" {
+		t.Errorf("Expected user type admin, got %s", repo.users[1].UserType)
+	}
+	if len(revocations.revoked) != 0 {
+		t.Error("Expected no token revocations on promotion")
+	}
+
+	if _, err := service.ChangeUserRole(99, 1, "superuser"); !errors.Is(err, ErrInvalidRole) {
+		t.Errorf("Expected ErrInvalidRole, got %v", err)
+	}
+}
+
+// Test: Se registran los logins y se alerta ante un dispositivo o país nuevo
+func TestLogin_RecordsHistoryAndFlagsNewDevice(t *testing.T) {
+	repo := newMockUserRepository()
+	hashedPassword, _ := utils.HashPassword("password123")
+	repo.Create(&domain.User{Username: "john", Email: "john@example.com", Password: hashedPassword, UserType: "normal"})
+
+	loginEvents := &mockLoginEventRepository{}
+	alerts := &mockSecurityAlertPublisher{}
+	history := NewLoginHistoryService(loginEvents, alerts)
+	service := NewUserService(repo, history, newTestAuditService(), newTestPasswordPolicy())
+
+	login := func(password string, client dto.LoginClientInfo) {
+		service.Login(dto.LoginRequest{UsernameOrEmail: "john", Password: password, Client: client})
+	}
+
+	laptop := dto.LoginClientInfo{IP: "10.0.0.1", UserAgent: "Firefox/120", Country: "AR"}
+	phone := dto.LoginClientInfo{IP: "10.0.0.2", UserAgent: "Safari/17", Country: "BR"}
+
+	login("password123", laptop)   // primer login: nunca es sospechoso
+	login("wrong-password", phone) // fallido: se registra sin alertar
+	login("password123", laptop)   // dispositivo conocido
+	login("password123", phone)    // dispositivo y país nuevos
+
+	if len(loginEvents.events) != 4 {
+		t.Fatalf("Expected 4 login events, got %d", len(loginEvents.events))
+	}
+	if loginEvents.events[1].Success {
+		t.Error("Expected second login to be recorded as failed")
+	}
+	for i := 0; i < 3; i++ {
+		if loginEvents.events[i].Suspicious {
+			t.Errorf("Expected login %d not to be suspicious", i+1)
+		}
+	}
+
+	if len(alerts.alerts) != 1 {
+		t.Fatalf("Expected 1 security alert, got %d", len(alerts.alerts))
+	}
+	reasons := alerts.alerts[0].Reasons
+	if len(reasons) != 2 || reasons[0] != ReasonNewDevice || reasons[1] != ReasonNewCountry {
+		t.Errorf("Expected reasons [new_device new_country], got %v", reasons)
+	}
+
+	logins, err := history.GetUserLogins(1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(logins) != 4 || !logins[0].Suspicious {
+		t.Errorf("Expected most recent login first and flagged, got %+v", logins)
+	}
+}
+
+// Test: Desactivar una cuenta bloquea el login, revoca tokens y publica el evento
+func TestDeactivateUser_BlocksLoginAndPublishesEvent(t *testing.T) {
+	repo := newMockUserRepository()
+	hashedPassword, _ := utils.HashPassword("password123")
+	repo.Create(&domain.User{Username: "john", Email: "john@example.com", Password: hashedPassword, UserType: "normal"})
+	repo.Create(&domain.User{Username: "jane", Email: "jane@example.com", Password: hashedPassword, UserType: "normal"})
+
+	revocations := &mockTokenRevocationRepository{revoked: make(map[uint]time.Time)}
+	events := &mockUserEventPublisher{}
+	service := NewAccountService(repo, revocations, events, newTestAuditService(), newTestPasswordPolicy())
+
+	// Un usuario normal no puede desactivar a otro
+	if _, err := service.DeactivateUser(2, "normal", 1); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("Expected ErrForbidden, got %v", err)
+	}
+
+	user, err := service.DeactivateUser(1, "normal", 1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if user.Active || repo.users[1].Active {
+		t.Error("Expected user to be inactive")
+	}
+	if _, revoked := revocations.revoked[1]; !revoked {
+		t.Error("Expected tokens of deactivated user to be revoked")
+	}
+	if len(events.events) != 1 || events.events[0].Type != clients.UserEventDeactivated || events.events[0].UserID != 1 {
+		t.Errorf("Expected one user.deactivated event for user 1, got %+v", events.events)
+	}
+
+	// Desactivar de nuevo es idempotente y no publica otro evento
+	if _, err := service.DeactivateUser(99, "admin", 1); err != nil {
+		t.Fatalf("Expected no error on second deactivation, got %v", err)
+	}
+	if len(events.events) != 1 {
+		t.Errorf("Expected no new events, got %d", len(events.events))
+	}
+
+	_, err = newTestUserService(repo).Login(dto.LoginRequest{UsernameOrEmail: "john", Password: "password123"})
+	if !errors.Is(err, ErrUserInactive) {
+		t.Errorf("Expected ErrUserInactive, got %v", err)
+	}
+}
+
+// Test: Cambiar la contraseña exige la actual, aplica la política y cierra las otras sesiones
+func TestChangePassword_VerifiesCurrentAndRevokesOtherSessions(t *testing.T) {
+	repo := newMockUserRepository()
+	hashedPassword, _ := utils.HashPassword("password123")
+	repo.Create(&domain.User{Username: "john", Email: "john@example.com", Password: hashedPassword, UserType: "normal"})
+
+	revocations := &mockTokenRevocationRepository{revoked: make(map[uint]time.Time)}
+	service := NewAccountService(repo, revocations, nil, newTestAuditService(), newTestPasswordPolicy())
+
+	_, err := service.ChangePassword(1, dto.ChangePasswordRequest{CurrentPassword: "wrongpassword", NewPassword: "newpassword123"})
+	if !errors.Is(err, ErrWrongPassword) {
+		t.Fatalf("Expected ErrWrongPassword, got %v", err)
+	}
+
+	// Los errores de la política se asocian al campo newPassword
+	var policyErr *PasswordPolicyError
+	_, err = service.ChangePassword(1, dto.ChangePasswordRequest{CurrentPassword: "password123", NewPassword: "corta"})
+	if !errors.As(err, &policyErr) || policyErr.Violations[0].Field != "newPassword" || policyErr.Violations[0].Code != PasswordRuleMinLength {
+		t.Fatalf("Expected a min_length violation on newPassword, got %v", err)
+	}
+	_, err = service.ChangePassword(1, dto.ChangePasswordRequest{CurrentPassword: "password123", NewPassword: "password123"})
+	if !errors.As(err, &policyErr) || policyErr.Violations[0].Code != PasswordRuleReused {
+		t.Fatalf("Expected a same_as_current violation, got %v", err)
+	}
+	if len(revocations.revoked) != 0 || repo.users[1].Password != hashedPassword {
+		t.Fatal("Expected rejected changes to keep the password and the sessions")
+	}
+
+	response, err := service.ChangePassword(1, dto.ChangePasswordRequest{CurrentPassword: "password123", NewPassword: "newpassword123"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, revoked := revocations.revoked[1]; !revoked {
+		t.Error("Expected the tokens issued before the change to be revoked")
+	}
+	if claims, err := utils.ValidateToken(response.Token); err != nil || claims.UserID != 1 {
+		t.Errorf("Expected a new token for user 1, got claims=%+v err=%v", claims, err)
+	}
+
+	login := newTestUserService(repo)
+	if _, err := login.Login(dto.LoginRequest{UsernameOrEmail: "john", Password: "password123"}); err == nil {
+		t.Error("Expected the old password to stop working")
+	}
+	if _, err := login.Login(dto.LoginRequest{UsernameOrEmail: "john", Password: "newpassword123"}); err != nil {
+		t.Errorf("Expected login with the new password, got %v", err)
+	}
+}
+
+// Test: Borrar los datos de un usuario lo anonimiza, borra sus logins y favoritos y publica el evento
+func TestEraseUser_AnonymizesAndPublishesEvent(t *testing.T) {
+	repo := newMockUserRepository()
+	hashedPassword, _ := utils.HashPassword("password123")
+	repo.Create(&domain.User{Username: "john", Email: "john@example.com", Password: hashedPassword, FirstName: "John", UserType: "normal"})
+	erased := repo.users[1]
+
+	loginEvents := &mockLoginEventRepository{}
+	loginEvents.Create(&domain.LoginEvent{UserID: 1, IP: "10.0.0.1", Success: true})
+	loginEvents.Create(&domain.LoginEvent{UserID: 2, IP: "10.0.0.2", Success: true})
+
+	favorites := &mockFavoriteRepository{}
+	favorites.Add(&domain.Favorite{UserID: 1, PropertyID: "64b7f0c2a1b2c3d4e5f60718"})
+
+	revocations := &mockTokenRevocationRepository{revoked: make(map[uint]time.Time)}
+	events := &mockUserEventPublisher{}
+	service := NewPrivacyService(repo, loginEvents, favorites, revocations, nil, events, nil, newTestAuditService())
+
+	if err := service.EraseUser(2, "normal", 1); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("Expected ErrForbidden, got %v", err)
+	}
+
+	if err := service.EraseUser(1, "normal", 1); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if erased.Username == "john" || erased.Email == "john@example.com" || erased.FirstName != "" || erased.Active {
+		t.Errorf("Expected personal data to be anonymized, got %+v", erased)
+	}
+	if utils.CheckPasswordHash("password123", erased.Password) {
+		t.Error("Expected old password to stop working")
+	}
+	if _, exists := repo.users[1]; exists {
+		t.Error("Expected user to be deleted")
+	}
+	if len(loginEvents.events) != 1 || loginEvents.events[0].UserID != 2 {
+		t.Errorf("Expected only other users' logins to remain, got %+v", loginEvents.events)
+	}
+	if len(favorites.favorites) != 0 {
+		t.Errorf("Expected favorites of erased user to be deleted, got %+v", favorites.favorites)
+	}
+	if _, revoked := revocations.revoked[1]; !revoked {
+		t.Error("Expected tokens of erased user to be revoked")
+	}
+	if len(events.events) != 1 || events.events[0].Type != clients.UserEventErased || events.events[0].UserID != 1 {
+		t.Errorf("Expected one user.erased event for user 1, got %+v", events.events)
+	}
+}
+
+func TestListUsers_PaginatesAndFilters(t *testing.T) {
+	repo := newMockUserRepository()
+	service := newTestUserService(repo)
+
+	for _, username := range []string{"ana", "bruno", "carla", "anabel"} {
+		if _, err := service.CreateUser(dto.CreateUserRequest{
+			Username: username, Email: username + "@test.com", Password: "password123", FirstName: "Test", LastName: "User",
+		}); err != nil {
+			t.Fatalf("Expected no error creating %s, got %v", username, err)
+		}
+	}
+
+	page, err := service.ListUsers(dto.ListUsersQuery{Page: 2, Limit: 3})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if page.Total != 4 || page.TotalPages != 2 || len(page.Users) != 1 {
+		t.Errorf("Expected total=4, totalPages=2 and 1 user on page 2, got total=%d totalPages=%d users=%d",
+			page.Total, page.TotalPages, len(page.Users))
+	}
+
+	filtered, err := service.ListUsers(dto.ListUsersQuery{Search: "ana"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if filtered.Total != 2 || filtered.Limit != defaultUsersPageSize {
+		t.Errorf("Expected 2 users with the default limit, got total=%d limit=%d", filtered.Total, filtered.Limit)
+	}
+}
+
+func TestUpdateUser_RecordsRedactedAuditDiff(t *testing.T) {
+	repo := newMockUserRepository()
+	auditRepo := &mockAuditRepository{}
+	audit := NewAuditService(auditRepo)
+	service := NewUserService(repo, NewLoginHistoryService(&mockLoginEventRepository{}, nil), audit, newTestPasswordPolicy())
+
+	user, err := service.CreateUser(dto.CreateUserRequest{
+		Username: "ana", Email: "ana@test.com", Password: "password123", FirstName: "Ana", LastName: "Test",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	newEmail := "nueva@test.com"
+	newPassword := "otherpassword"
+	if err := service.UpdateUser(99, user.ID, dto.UpdateUserRequest{Email: &newEmail, Password: &newPassword}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	page, err := audit.List(dto.AuditQuery{UserID: 99})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if page.Total != 1 {
+		t.Fatalf("Expected 1 audit entry for actor 99, got %d", page.Total)
+	}
+
+	entry := page.Entries[0]
+	if entry.Action != AuditActionUserUpdate || entry.EntityID != auditUserID(user.ID) {
+		t.Errorf("Expected user.update on user %d, got %s on %s", user.ID, entry.Action, entry.EntityID)
+	}
+	if len(entry.Changes) != 2 {
+		t.Errorf("Expected only email and password in the diff, got %v", entry.Changes)
+	}
+	if change := entry.Changes["password"]; change.Before != auditRedactedValue || change.After != auditRedactedValue {
+		t.Errorf("Expected password change to be redacted, got %v", change)
+	}
+	if change := entry.Changes["email"]; change.After == newEmail {
+		t.Errorf("Expected email change to be redacted, got %v", change)
+	}
+}
+
+func TestPasswordPolicy_RejectsWeakAndBreachedPasswords(t *testing.T) {
+	repo := newMockUserRepository()
+	checker := &mockBreachedPasswordChecker{breached: map[string]int{"Password123": 52000}}
+	policy := NewPasswordPolicy(PasswordPolicyConfig{
+		MinLength: 8, MaxLength: 64, RequireUpper: true, RequireLower: true, RequireDigit: true,
+	}, checker)
+	service := NewUserService(repo, NewLoginHistoryService(&mockLoginEventRepository{}, nil), newTestAuditService(), policy)
+
+	// Se informan todas las reglas que no se cumplen y no se consulta la API de filtraciones
+	_, err := service.CreateUser(dto.CreateUserRequest{
+		Username: "ana", Email: "ana@test.com", Password: "corta", FirstName: "Ana", LastName: "Test",
+	})
+	var policyErr *PasswordPolicyError
+	if !errors.As(err, &policyErr) || !errors.Is(err, ErrWeakPassword) {
+		t.Fatalf("Expected a PasswordPolicyError, got %v", err)
+	}
+	var codes []string
+	for _, violation := range policyErr.Violations {
+		if violation.Field != "password" {
+			t.Errorf("Expected violations on the password field, got %q", violation.Field)
+		}
+		codes = append(codes, violation.Code)
+	}
+	if want := []string{PasswordRuleMinLength, PasswordRuleUpper, PasswordRuleDigit}; strings.Join(codes, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected violations %v, got %v", want, codes)
+	}
+	if checker.calls != 0 {
+		t.Errorf("Expected no breach lookup for an invalid password, got %d", checker.calls)
+	}
+	if len(repo.users) != 0 {
+		t.Fatalf("Expected no user to be created, got %d", len(repo.users))
+	}
+
+	// Una contraseña filtrada se rechaza aunque cumpla el resto de las reglas
+	_, err = service.CreateUser(dto.CreateUserRequest{
+		Username: "ana", Email: "ana@test.com", Password: "Password123", FirstName: "Ana", LastName: "Test",
+	})
+	if !errors.As(err, &policyErr) || len(policyErr.Violations) != 1 || policyErr.Violations[0].Code != PasswordRuleBreached {
+		t.Fatalf("Expected a breached password violation, got %v", err)
+	}
+
+	user, err := service.CreateUser(dto.CreateUserRequest{
+		Username: "ana", Email: "ana@test.com", Password: "Segura2024", FirstName: "Ana", LastName: "Test",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// El cambio de contraseña aplica la misma política y no modifica la guardada
+	hashed := repo.users[user.ID].Password
+	weak := "sinnumeros"
+	if err := service.UpdateUser(user.ID, user.ID, dto.UpdateUserRequest{Password: &weak}); !errors.Is(err, ErrWeakPassword) {
+		t.Fatalf("Expected ErrWeakPassword on update, got %v", err)
+	}
+	if repo.users[user.ID].Password != hashed {
+		t.Error("Expected the stored password to stay unchanged")
+	}
+
+	// Si la API de filtraciones no responde la contraseña se acepta
+	checker.err = errors.New("timeout")
+	other := "OtraSegura2024"
+	if err := service.UpdateUser(user.ID, user.ID, dto.UpdateUserRequest{Password: &other}); err != nil {
+		t.Fatalf("Expected the password to be accepted when the breach API fails, got %v", err)
+	}
+}
+
+func TestAuditList_RejectsInvalidDateRange(t *testing.T) {
+	audit := newTestAuditService()
+
+	if _, err := audit.List(dto.AuditQuery{From: "2024-02-01", To: "2024-01-01"}); !errors.Is(err, ErrInvalidDateRange) {
+		t.Errorf("Expected ErrInvalidDateRange for from after to, got %v", err)
+	}
+	if _, err := audit.List(dto.AuditQuery{From: "ayer"}); !errors.Is(err, ErrInvalidDateRange) {
+		t.Errorf("Expected ErrInvalidDateRange for an invalid date, got %v", err)
+	}
+}
+
+func TestPreferences_FavoritesAndSearchProfile(t *testing.T) {
+	repo := newMockUserRepository()
+	repo.Create(&domain.User{Username: "john", Email: "john@example.com", UserType: "normal"})
+	favorites := &mockFavoriteRepository{}
+	service := NewPreferenceService(repo, favorites)
+
+	currency, locale := "ars", "es-AR"
+	if _, err := service.UpdatePreferences(1, dto.UpdatePreferencesRequest{PreferredCurrency: &currency}); !errors.Is(err, ErrInvalidCurrency) {
+		t.Errorf("Expected ErrInvalidCurrency for a lowercase code, got %v", err)
+	}
+	currency = "ARS"
+	if _, err := service.UpdatePreferences(1, dto.UpdatePreferencesRequest{PreferredCurrency: &currency, Locale: &locale}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Solo se actualizan los campos enviados
+	empty := ""
+	preferences, err := service.UpdatePreferences(1, dto.UpdatePreferencesRequest{Locale: &empty})
+	if err != nil {
+		t.Fatalf("Expected no error clearing the locale, got %v", err)
+	}
+	if preferences.PreferredCurrency != "ARS" || preferences.Locale != "" {
+		t.Errorf("Expected currency ARS and no locale, got %+v", preferences)
+	}
+
+	if err := service.AddFavorite(1, "not-an-id"); !errors.Is(err, ErrInvalidPropertyID) {
+		t.Errorf("Expected ErrInvalidPropertyID, got %v", err)
+	}
+	for _, id := range []string{"64b7f0c2a1b2c3d4e5f60718", "64b7f0c2a1b2c3d4e5f60719", "64b7f0c2a1b2c3d4e5f60718"} {
+		if err := service.AddFavorite(1, id); err != nil {
+			t.Fatalf("Expected no error adding %s, got %v", id, err)
+		}
+	}
+	if err := service.RemoveFavorite(1, "64b7f0c2a1b2c3d4e5f60719"); err != nil {
+		t.Fatalf("Expected no error removing a favorite, got %v", err)
+	}
+	if err := service.RemoveFavorite(1, "64b7f0c2a1b2c3d4e5f60719"); err != nil {
+		t.Errorf("Expected removing twice to be a no-op, got %v", err)
+	}
+
+	profile, ids, err := service.GetSearchProfile(1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if profile.PreferredCurrency != "ARS" || len(ids) != 1 || ids[0] != "64b7f0c2a1b2c3d4e5f60718" {
+		t.Errorf("Expected ARS and one favorite, got %+v %v", profile, ids)
+	}
+
+	if _, _, err := service.GetSearchProfile(42); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("Expected ErrUserNotFound for an unknown user, got %v", err)
+	}
+}
+
+func TestProfile_UpdateMeAndPublicHostProfile(t *testing.T) {
+	repo := newMockUserRepository()
+	repo.Create(&domain.User{Username: "john", Email: "john@example.com", FirstName: "John", LastName: "doe", UserType: "normal"})
+	auditRepo := &mockAuditRepository{}
+	service := NewProfileService(repo, &mockAvatarStorage{files: map[string][]byte{}}, NewAuditService(auditRepo), 5<<20)
+
+	phone := "351-1234567"
+	if _, err := service.UpdateProfile(1, dto.UpdateProfileRequest{Phone: &phone}); !errors.Is(err, ErrInvalidPhone) {
+		t.Errorf("Expected ErrInvalidPhone for a local number, got %v", err)
+	}
+	avatar := "javascript:alert(1)"
+	if _, err := service.UpdateProfile(1, dto.UpdateProfileRequest{AvatarURL: &avatar}); !errors.Is(err, ErrInvalidAvatarURL) {
+		t.Errorf("Expected ErrInvalidAvatarURL, got %v", err)
+	}
+	languages := []string{"es", "spanish"}
+	if _, err := service.UpdateProfile(1, dto.UpdateProfileRequest{Languages: &languages}); !errors.Is(err, ErrInvalidLanguages) {
+		t.Errorf("Expected ErrInvalidLanguages, got %v", err)
+	}
+	if _, err := service.UpdateProfile(1, dto.UpdateProfileRequest{EmergencyContact: &dto.EmergencyContact{Name: "Jane"}}); !errors.Is(err, ErrInvalidEmergencyContact) {
+		t.Errorf("Expected ErrInvalidEmergencyContact without a phone, got %v", err)
+	}
+
+	phone, avatar, bio, city := "+5493511234567", "https://cdn.example.com/john.jpg", "  Anfitrión en Córdoba  ", "Córdoba"
+	languages = []string{"ES", "en", "es"}
+	profile, err := service.UpdateProfile(1, dto.UpdateProfileRequest{
+		Phone:            &phone,
+		AvatarURL:        &avatar,
+		Bio:              &bio,
+		Languages:        &languages,
+		City:             &city,
+		EmergencyContact: &dto.EmergencyContact{Name: "Jane Doe", Phone: "+5493517654321"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if profile.Bio != "Anfitrión en Córdoba" || strings.Join(profile.Languages, ",") != "es,en" || profile.EmergencyContact == nil {
+		t.Errorf("Expected a trimmed bio, deduplicated languages and an emergency contact, got %+v", profile)
+	}
+
+	// Los datos de contacto no quedan en el log de auditoría
+	if len(auditRepo.entries) != 1 || auditRepo.entries[0].Action != AuditActionProfileUpdate || strings.Contains(auditRepo.entries[0].Changes, phone) {
+		t.Errorf("Expected one redacted profile audit entry, got %+v", auditRepo.entries)
+	}
+
+	host, err := service.GetHostProfile(1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if host.DisplayName != "John D." || !host.HasPhone || host.AvatarURL != avatar || host.City != "Córdoba" {
+		t.Errorf("Expected the public host profile, got %+v", host)
+	}
+
+	repo.users[1].Active = false
+	if _, err := service.GetHostProfile(1); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("Expected ErrUserNotFound for a deactivated host, got %v", err)
+	}
+}
+
+func TestProfile_UploadAvatarCropsAndReplacesPreviousFile(t *testing.T) {
+	repo := newMockUserRepository()
+	repo.Create(&domain.User{Username: "john", Email: "john@example.com", FirstName: "John", UserType: "normal"})
+	storage := &mockAvatarStorage{files: map[string][]byte{}}
+	service := NewProfileService(repo, storage, newTestAuditService(), 1<<20)
+
+	if _, err := service.UploadAvatar(1, []byte("not an image")); !errors.Is(err, ErrInvalidAvatarImage) {
+		t.Errorf("Expected ErrInvalidAvatarImage, got %v", err)
+	}
+	if _, err := service.UploadAvatar(1, make([]byte, 1<<20+1)); !errors.Is(err, ErrAvatarTooLarge) {
+		t.Errorf("Expected ErrAvatarTooLarge, got %v", err)
+	}
+
+	// PNG apaisado de 1200x800: se recorta el centro y se escala a 512x512 en JPEG
+	var upload bytes.Buffer
+	img := image.NewRGBA(image.Rect(0, 0, 1200, 800))
+	for x := 0; x < 1200; x++ {
+		for y := 0; y < 800; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 200, A: 255})
+		}
+	}
+	if err := png.Encode(&upload, img); err != nil {
+		t.Fatalf("Expected no error encoding the test image, got %v", err)
+	}
+
+	profile, err := service.UploadAvatar(1, upload.Bytes())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	firstKey := repo.users[1].AvatarKey
+	if len(storage.files) != 1 || profile.AvatarURL != "http://localhost:8081/media/"+firstKey || !strings.HasPrefix(firstKey, "avatars/1/") {
+		t.Fatalf("Expected one stored avatar under avatars/1/, got %v (%s)", profile.AvatarURL, firstKey)
+	}
+	saved, err := jpeg.DecodeConfig(bytes.NewReader(storage.files[firstKey]))
+	if err != nil || saved.Width != 512 || saved.Height != 512 {
+		t.Errorf("Expected a 512x512 JPEG, got %+v (%v)", saved, err)
+	}
+
+	// Una foto nueva reemplaza a la anterior y UserResponse expone la URL
+	if _, err := service.UploadAvatar(1, upload.Bytes()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, ok := storage.files[firstKey]; ok || len(storage.files) != 1 {
+		t.Errorf("Expected the previous avatar to be deleted, got %d files", len(storage.files))
+	}
+	user, err := newTestUserService(repo).GetUserByID(1)
+	if err != nil || user.AvatarURL != repo.users[1].AvatarURL {
+		t.Errorf("Expected UserResponse to include the avatar URL, got %+v (%v)", user, err)
+	}
+
+	// Cambiar la foto por una URL externa libera el archivo subido
+	external := "https://cdn.example.com/john.jpg"
+	if _, err := service.UpdateProfile(1, dto.UpdateProfileRequest{AvatarURL: &external}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(storage.files) != 0 || repo.users[1].AvatarKey != "" {
+		t.Errorf("Expected the uploaded avatar to be released, got %d files and key %q", len(storage.files), repo.users[1].AvatarKey)
+	}
+}
+
+func TestAPIKeys_IssueVerifyAndRevoke(t *testing.T) {
+	repo := &mockAPIKeyRepository{}
+	auditRepo := &mockAuditRepository{}
+	service := NewAPIKeyService(repo, NewAuditService(auditRepo))
+
+	if _, err := service.Issue(1, dto.CreateAPIKeyRequest{Name: "search-api", Scopes: []string{"properties:write"}}); !errors.Is(err, ErrInvalidAPIKeyScopes) {
+		t.Errorf("Expected ErrInvalidAPIKeyScopes for an unknown scope, got %v", err)
+	}
+
+	created, err := service.Issue(1, dto.CreateAPIKeyRequest{Name: "search-api", Scopes: []string{"properties:read", "PROPERTIES:READ"}, ExpiresInDays: 30})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(created.Key, "spk_"+created.Prefix+"_") || len(created.Scopes) != 1 || created.ExpiresAt == nil {
+		t.Errorf("Expected a spk_ key with one scope and an expiration, got %+v", created)
+	}
+	if repo.keys[0].KeyHash == created.Key || strings.Contains(auditRepo.entries[0].Changes, created.Key) {
+		t.Error("Expected the raw key not to be stored nor audited")
+	}
+
+	identity, ok, err := service.Verify(created.Key)
+	if err != nil || !ok || identity.Name != "search-api" || !identity.HasScope("properties:read") || identity.HasScope("users:read") {
+		t.Errorf("Expected a valid key for search-api with properties:read, got %+v ok=%v err=%v", identity, ok, err)
+	}
+	if repo.keys[0].LastUsedAt == nil {
+		t.Error("Expected the key usage to be recorded")
+	}
+
+	for _, invalid := range []string{"", "spk_nothing", created.Key + "x", "spk_" + created.Prefix + "_guess"} {
+		if _, ok, _ := service.Verify(invalid); ok {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+
+	if _, err := service.Revoke(1, 99); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Errorf("Expected ErrAPIKeyNotFound, got %v", err)
+	}
+	revoked, err := service.Revoke(1, created.ID)
+	if err != nil || revoked.RevokedAt == nil {
+		t.Fatalf("Expected the key to be revoked, got %+v (%v)", revoked, err)
+	}
+	if _, ok, _ := service.Verify(created.Key); ok {
+		t.Error("Expected a revoked key to be rejected")
 	}
 }
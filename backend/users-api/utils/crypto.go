@@ -1,23 +1,126 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
-// HashPassword hashea una contraseña usando bcrypt
+// argon2idPrefix identifica los hashes de Argon2id en formato PHC
+const argon2idPrefix = "$argon2id$"
+
+// Argon2Params son los parámetros de Argon2id con los que se hashean las contraseñas nuevas
+type Argon2Params struct {
+	Memory      uint32 // Memoria en KiB
+	Iterations  uint32 // Pasadas sobre la memoria
+	Parallelism uint8  // Hilos
+	SaltLength  uint32 // Bytes del salt aleatorio
+	KeyLength   uint32 // Bytes del hash
+}
+
+// DefaultArgon2Params son los parámetros mínimos recomendados por OWASP (19 MiB, 2 pasadas, 1 hilo)
+var DefaultArgon2Params = Argon2Params{
+	Memory:      19 * 1024,
+	Iterations:  2,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// argon2Params son los parámetros vigentes (ver SetArgon2Params)
+var argon2Params = DefaultArgon2Params
+
+// SetArgon2Params reemplaza los parámetros con los de la configuración cargada al arrancar
+// Los hashes guardados con otros parámetros se siguen verificando y se rehashean en el login (ver NeedsRehash)
+func SetArgon2Params(params Argon2Params) {
+	argon2Params = params
+}
+
+// HashPassword hashea una contraseña usando Argon2id
 // Recibe: "mipassword123"
-// Devuelve: "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy"
+// Devuelve: "$argon2id$v=19$m=19456,t=2,p=1$<salt en base64>$<hash en base64>"
+// Los parámetros y el salt viajan en el hash, así que cambiar la configuración no invalida los existentes
 func HashPassword(password string) (string, error) {
-	// bcrypt.DefaultCost = 10 (nivel de seguridad)
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	return string(bytes), err
+	params := argon2Params
+	salt := make([]byte, params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("error generando salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2.Version,
+		params.Memory, params.Iterations, params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
 }
 
 // CheckPasswordHash verifica si una contraseña coincide con el hash
 // Se usa en el login para verificar que la contraseña sea correcta
-// Recibe: "mipassword123" y el hash guardado en la BD
+// Acepta hashes de Argon2id y los de bcrypt de las cuentas creadas antes de la migración
 // Devuelve: true si coincide, false si no
 func CheckPasswordHash(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
+	if !strings.HasPrefix(hash, argon2idPrefix) {
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		return err == nil
+	}
+
+	params, salt, key, err := decodeArgon2Hash(hash)
+	if err != nil {
+		return false
+	}
+	candidate := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	return subtle.ConstantTimeCompare(key, candidate) == 1
+}
+
+// NeedsRehash indica si el hash se generó con bcrypt o con parámetros de Argon2id distintos a los vigentes
+// El login lo usa para migrar el hash cuando tiene la contraseña en texto plano (después de verificarla)
+func NeedsRehash(hash string) bool {
+	if !strings.HasPrefix(hash, argon2idPrefix) {
+		return true
+	}
+	params, salt, _, err := decodeArgon2Hash(hash)
+	if err != nil {
+		return false
+	}
+	current := argon2Params
+	return params.Memory != current.Memory || params.Iterations != current.Iterations ||
+		params.Parallelism != current.Parallelism || params.KeyLength != current.KeyLength ||
+		uint32(len(salt)) != current.SaltLength
+}
+
+// decodeArgon2Hash separa los parámetros, el salt y el hash de un hash de Argon2id en formato PHC
+func decodeArgon2Hash(hash string) (Argon2Params, []byte, []byte, error) {
+	// "", "argon2id", "v=19", "m=19456,t=2,p=1", salt, hash
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return Argon2Params{}, nil, nil, errors.New("hash de argon2id con formato inválido")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return Argon2Params{}, nil, nil, fmt.Errorf("versión de argon2 no soportada: '%s'", parts[2])
+	}
+
+	var params Argon2Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return Argon2Params{}, nil, nil, fmt.Errorf("parámetros de argon2id inválidos: '%s'", parts[3])
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return Argon2Params{}, nil, nil, errors.New("salt de argon2id inválido")
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return Argon2Params{}, nil, nil, errors.New("hash de argon2id inválido")
+	}
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+
+	return params, salt, key, nil
 }
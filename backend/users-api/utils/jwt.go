@@ -7,6 +7,12 @@ import (
 	"time"
 )
 
+// Los tokens guardan iat y exp con precisión de milisegundos (JSON con decimales, como admite el estándar)
+// Así un token emitido después de una revocación en el mismo segundo se distingue de los revocados
+func init() {
+	jwt.TimePrecision = time.Millisecond
+}
+
 // Esta es la "llave secreta" para firmar los tokens
 // En producción debe estar en variables de entorno
 var jwtSecret = []byte(getJWTSecret())
@@ -30,6 +36,11 @@ func getJWTSecret() string {
 	return secret
 }
 
+// SetJWTSecret reemplaza la llave secreta con la de la configuración cargada al arrancar
+func SetJWTSecret(secret string) {
+	jwtSecret = []byte(secret)
+}
+
 // GenerateToken genera un nuevo JWT token para un usuario
 // Se llama después del login exitoso
 // Incluye is_admin en los claims basado en el user_type
@@ -74,6 +85,13 @@ func ValidateToken(tokenString string) (*Claims, error) {
 	return claims, nil
 }
 
+// IsTokenRevoked indica si el token quedó invalidado por la revocación del usuario en revokedAt
+// Un token emitido en el mismo instante que la revocación (o sin iat) cuenta como revocado
+// iat se lee como float y puede quedar hasta un milisegundo antes del real, nunca después
+func IsTokenRevoked(claims *Claims, revokedAt *time.Time) bool {
+	return revokedAt != nil && (claims.IssuedAt == nil || !claims.IssuedAt.Time.After(*revokedAt))
+}
+
 // IsAdmin es una función helper que verifica si un user_type es admin
 func IsAdmin(userType string) bool {
 	return userType == "admin"
@@ -135,9 +135,9 @@ function PropertyDetail() {
           <div className="bg-white rounded-2xl shadow-xl overflow-hidden">
             {/* Image */}
             <div className="aspect-[21/9] bg-gray-200 relative">
-              {property.images && property.images[0] ? (
+              {property.coverImage ? (
                   <img
-                      src={property.images[0]}
+                      src={property.coverImage}
                       alt={property.title}
                       className="w-full h-full object-cover"
                   />
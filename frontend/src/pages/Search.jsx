@@ -130,12 +130,18 @@ function Search() {
                     >
                       {/* Image */}
                       <div className="aspect-[4/3] bg-gray-200 relative overflow-hidden">
-                        {property.images && property.images.length > 0 && property.images[0] ? (
-                            <img
-                                src={property.images[0]}
-                                alt={property.title}
-                                className="w-full h-full object-cover group-hover:scale-105 transition duration-300"
-                            />
+                        {property.coverImage || (property.images && property.images.length > 0 && property.images[0]) ? (
+                            <picture>
+                              {property.coverThumbnailWebp && (
+                                  <source srcSet={property.coverThumbnailWebp} type="image/webp" />
+                              )}
+                              <img
+                                  src={property.coverThumbnail || property.coverImage || property.images[0]}
+                                  alt={property.title}
+                                  loading="lazy"
+                                  className="w-full h-full object-cover group-hover:scale-105 transition duration-300"
+                              />
+                            </picture>
                         ) : (
                             <div className="w-full h-full flex items-center justify-center text-gray-400">
                               <MapPin size={48} />